@@ -55,12 +55,14 @@ func main() {
 		}
 		log.SetFormatter(logFmt)
 
-		switch err {
-		case actions.ErrDiffFound:
-			os.Exit(10)
-		default:
-			log.Error(err.Error())
-			os.Exit(1)
+		if err == actions.ErrDiffFound {
+			os.Exit(actions.DiffExitCodeDifferences)
 		}
+
+		log.Error(err.Error())
+		if exitErr, ok := err.(*actions.ExitError); ok {
+			os.Exit(exitErr.Code)
+		}
+		os.Exit(1)
 	}
 }
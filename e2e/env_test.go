@@ -123,7 +123,7 @@ var _ = Describe("ks env", func() {
 			o := a.envAdd("prod", false)
 			assertExitStatus(o, 0)
 
-			o = a.runKs("env", "rm", "prod")
+			o = a.runKs("env", "rm", "prod", "--yes")
 			assertExitStatus(o, 0)
 
 			a.checkEnvs(genEnvList(e.serverVersion()))
@@ -138,7 +138,7 @@ var _ = Describe("ks env", func() {
 					"default", e.serverVersion(), "default", "*", "http://example.com")
 				a.checkEnvs(expected)
 
-				o = a.runKs("env", "rm", "-o", "default")
+				o = a.runKs("env", "rm", "-o", "default", "--yes")
 				assertExitStatus(o, 0)
 
 				a.checkEnvs(genEnvList(e.serverVersion()))
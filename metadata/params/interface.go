@@ -146,6 +146,17 @@ func SetEnvironmentParams(component, snippet string, params Params) (string, err
 	return setEnvironmentParams(component, snippet, params)
 }
 
+// EnvironmentParamLines takes
+//
+//   component: the name of the component.
+//   snippet: a jsonnet snippet resembling the current environment parameters (not expanded).
+//
+// and returns the line each of the component's parameters is set on. The map
+// is empty if the environment does not set parameters for 'component'.
+func EnvironmentParamLines(component, snippet string) (map[string]int, error) {
+	return environmentParamLines(component, snippet)
+}
+
 // DeleteEnvironmentParam deletes a parameter for an environment param file. It returns
 // the updated snippet.
 func DeleteEnvironmentParam(componentName, paramName, snippet string) (string, error) {
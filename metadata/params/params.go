@@ -342,6 +342,39 @@ func getEnvironmentParams(component, snippet string) (Params, *ast.LocationRange
 	return make(Params), &loc, false, nil
 }
 
+func environmentParamLines(component, snippet string) (map[string]int, error) {
+	n, err := componentsObj(component, snippet)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make(map[string]int)
+	for _, f := range n.Fields {
+		hasComponent, err := hasComponent(component, f)
+		if err != nil {
+			return nil, err
+		}
+		if !hasComponent {
+			continue
+		}
+
+		obj, isObj := f.Expr2.(*ast.Object)
+		if !isObj {
+			return nil, errors.Errorf("expected component node type to be object, it was a %T", f.Expr2)
+		}
+
+		for _, field := range obj.Fields {
+			key, err := getFieldID(field)
+			if err != nil {
+				return nil, err
+			}
+			lines[key] = field.Expr2.Loc().Begin.Line
+		}
+	}
+
+	return lines, nil
+}
+
 func getAllEnvironmentParams(snippet string) (map[string]Params, error) {
 	componentsNode, err := componentsObj("", snippet)
 	if err != nil {
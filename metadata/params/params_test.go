@@ -869,6 +869,42 @@ params + {
 	}
 }
 
+func TestEnvironmentParamLines(t *testing.T) {
+	snippet := `
+local params = import "/fake/path";
+params + {
+  components +: {
+    bar +: {
+      name: "bar",
+      replicas: 1,
+    },
+    "foo-bar" +: {
+      replicas: 2,
+    },
+  },
+}`
+
+	tests := []struct {
+		component string
+		expected  map[string]int
+	}{
+		{"bar", map[string]int{"name": 6, "replicas": 7}},
+		{"foo-bar", map[string]int{"replicas": 10}},
+		{"baz", map[string]int{}},
+	}
+
+	for _, s := range tests {
+		lines, err := EnvironmentParamLines(s.component, snippet)
+		if err != nil {
+			t.Errorf("Unexpected error\n  component: %v\n  error: %v", s.component, err)
+		}
+
+		if !reflect.DeepEqual(lines, s.expected) {
+			t.Errorf("Wrong lines\n  expected:%v\n  got:%v", s.expected, lines)
+		}
+	}
+}
+
 func TestSetEnvironmentParams(t *testing.T) {
 	tests := []struct {
 		componentName string
@@ -18,6 +18,7 @@ package actions
 import (
 	"crypto/tls"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"time"
@@ -29,68 +30,175 @@ import (
 )
 
 const (
+	// OptionAddress is address option. The address local ports listen on.
+	OptionAddress = "address"
+	// OptionAllEnvs is allEnvs option. Used to act on every environment.
+	OptionAllEnvs = "all-envs"
 	// OptionApp is app option.
 	OptionApp = "app"
 	// OptionArguments is arguments option. Used for passing arguments to prototypes.
 	OptionArguments = "arguments"
+	// OptionAssumeYes is assumeYes option. Used to confirm destructive actions
+	// without a prompt.
+	OptionAssumeYes = "assume-yes"
 	// OptionAsString is asString. Used for setting values as strings.
 	OptionAsString = "as-string"
+	// OptionBatchSize is batchSize option. The number of objects applied
+	// before the next objects are started.
+	OptionBatchSize = "batch-size"
+	// OptionBurst is burst option. The maximum burst of Kubernetes API queries.
+	OptionBurst = "burst"
+	// OptionCheck is check option. Used to report files instead of rewriting them.
+	OptionCheck = "check"
 	// OptionClientConfig is clientConfig option.
 	OptionClientConfig = "client-config"
 	// OptionComponentName is a componentName option.
 	OptionComponentName = "component-name"
 	// OptionComponentNames is componentNames option.
 	OptionComponentNames = "component-names"
+	// OptionContainer is container option. Used to select a container of a pod.
+	OptionContainer = "container"
+	// OptionContexts is contexts option. Used to select kubeconfig contexts.
+	OptionContexts = "contexts"
 	// OptionCreate is create option.
 	OptionCreate = "create"
+	// OptionDebounce is debounce option. Used to batch file changes.
+	OptionDebounce = "debounce"
+	// OptionDefault is default option. It makes a registry the app's default registry.
+	OptionDefault = "default"
+	// OptionDisableCommandHooks is disableCommandHooks option. Used to refuse
+	// running local command hooks.
+	OptionDisableCommandHooks = "disable-command-hooks"
+	// OptionDisable is disable option. It stops recording registry stats.
+	OptionDisable = "disable"
 	// OptionDryRun is dryRun option.
 	OptionDryRun = "dry-run"
+	// OptionDiffProgram is diffProgram option. Used to compare manifests with an external program.
+	OptionDiffProgram = "diff-program"
+	// OptionDiffStrategy is diffStrategy option.
+	OptionDiffStrategy = "diff-strategy"
+	// OptionEnable is enable option. It starts recording registry stats.
+	OptionEnable = "enable"
 	// OptionEnvName is envName option.
 	OptionEnvName = "env-name"
 	// OptionEnvName1 is envName1. Used for param diff.
 	OptionEnvName1 = "env-name-1"
 	// OptionEnvName2 is envName1. Used for param diff.
 	OptionEnvName2 = "env-name-2"
+	// OptionEnvNames is envNames option. Used to scope packages to environments.
+	OptionEnvNames = "env-names"
+	// OptionExpression is expression option. Used for jsonnet to evaluate.
+	OptionExpression = "expression"
 	// OptionExtVarFiles is jsonnet ext var files.
 	OptionExtVarFiles = "ext-vars-files"
 	// OptionExtVars is jsonnet ext vars.
 	OptionExtVars = "ext-vars"
+	// OptionFollow is follow option. Used to stream logs.
+	OptionFollow = "follow"
 	// OptionForce is force option.
 	OptionForce = "force"
 	// OptionFormat is format option.
 	OptionFormat = "format"
+	// OptionFromCluster is fromCluster option. Used to initialize an app from kubeconfig contexts.
+	OptionFromCluster = "from-cluster"
+	// OptionFromStdin is fromStdin option. Used to read pre-rendered manifests
+	// from stdin instead of evaluating components.
+	OptionFromStdin = "from-stdin"
+	// OptionFromVersion is from version option. Used to compare package versions.
+	OptionFromVersion = "from-version"
 	// OptionFs is fs option.
 	OptionFs = "fs"
+	// OptionGcAppLabel is gcAppLabel option. The label which holds the application.
+	OptionGcAppLabel = "gc-app-label"
+	// OptionGcEnvLabel is gcEnvLabel option. The label which holds the environment.
+	OptionGcEnvLabel = "gc-env-label"
+	// OptionGcLabels is gcLabels option. Used to garbage collect objects by label.
+	OptionGcLabels = "gc-labels"
 	// OptionGcTag is gcTag option.
 	OptionGcTag = "gc-tag"
+	// OptionGitOps is gitOps option. Used to export environments in a layout
+	// for GitOps tools.
+	OptionGitOps = "gitops"
 	// OptionGlobal is global option.
 	OptionGlobal = "global"
 	// OptionGracePeriod is gracePeriod option.
 	OptionGracePeriod = "grace-period"
+	// OptionIfExists is ifExists option. Used to ignore missing targets.
+	OptionIfExists = "if-exists"
+	// OptionImage is image option. A container image reference.
+	OptionImage = "image"
+	// OptionImportKinds is importKinds option. Used to select the kinds of objects to import.
+	OptionImportKinds = "import-kinds"
+	// OptionImportSelector is importSelector option. A label selector of objects to import.
+	OptionImportSelector = "import-selector"
 	// OptionInstalled is for listing installed packages.
 	OptionInstalled = "only-installed"
 	// OptionJPaths is jsonnet paths.
 	OptionJPaths = "jpaths"
+	// OptionJUnit is junit option. Used to write test results as JUnit XML.
+	OptionJUnit = "junit"
+	// OptionKustomize is kustomize option. Used to write kustomizations for
+	// exported environments.
+	OptionKustomize = "kustomize"
+	// OptionLive is live option. Used to read objects from the cluster
+	// instead of rendering them.
+	OptionLive = "live"
 	// OptionPkgName is (an optionally qualified) name of a package.
 	OptionPkgName = "pkg-name"
 	// OptionName is name option.
 	OptionName = "name"
+	// OptionMaxUnavailableClusters is maxUnavailableClusters option. The number of
+	// clusters of a multi-cluster environment which may fail.
+	OptionMaxUnavailableClusters = "max-unavailable-clusters"
+	// OptionMessage is message option. Used to describe why a change was made.
+	OptionMessage = "message"
+	// OptionMetricsAddr is metricsAddr option. Used to serve Prometheus metrics.
+	OptionMetricsAddr = "metrics-addr"
 	// OptionModule is component module option.
 	OptionModule = "module"
 	// OptionNamespace is a cluster namespace option
 	OptionNamespace = "namespace"
 	// OptionNewEnvName is newEnvName option. Used for renaming environments.
 	OptionNewEnvName = "new-env-name"
+	// OptionObject is object option. Used to select a rendered object by
+	// kind and name.
+	OptionObject = "object"
+	// OptionObjects is objects option. Used to include rendered objects.
+	OptionObjects = "objects"
+	// OptionOffline is offline option. Used to work without cluster access.
+	OptionOffline = "offline"
+	// OptionOut is out option. Used to write output to a writer other than stdout.
+	OptionOut = "out"
 	// OptionOutput is output option.
 	OptionOutput = "output"
 	// OptionOverride is override option.
 	OptionOverride = "override"
 	// OptionPackageName is packageName option.
 	OptionPackageName = "package-name"
+	// OptionParallelism is parallelism option. The number of objects applied
+	// concurrently.
+	OptionParallelism = "parallelism"
+	// OptionPart is part option. Used to set the directory of a part.
+	OptionPart = "part"
 	// OptionPath is path option.
 	OptionPath = "path"
+	// OptionPaths is paths option. Used when an action accepts several paths.
+	OptionPaths = "paths"
+	// OptionPorts is ports option. Used to forward ports.
+	OptionPorts = "ports"
+	// OptionPreserveCustom is preserve custom option. Used to keep the custom
+	// regions of regenerated files.
+	OptionPreserveCustom = "preserve-custom"
+	// OptionPruneNamespaces is pruneNamespaces option. Used to delete empty namespaces.
+	OptionPruneNamespaces = "prune-namespaces"
+	// OptionQPS is qps option. The maximum Kubernetes API queries per second.
+	OptionQPS = "qps"
 	// OptionQuery is query option.
 	OptionQuery = "query"
+	// OptionRegistry is registry option. It selects the registry a package is installed from.
+	OptionRegistry = "registry"
+	// OptionRemote is remote option. It contacts registries to check the state of their caches.
+	OptionRemote = "remote"
 	// OptionResolveImage is resolve image option. It is used to resolve docker image references
 	// when setting parameters.
 	OptionResolveImage = "resolve-image"
@@ -98,8 +206,15 @@ const (
 	OptionRootPath = "root-path"
 	// OptionServer is server option.
 	OptionServer = "server"
+	// OptionServerDryRun is serverDryRun option. Used to submit objects with dryRun=All.
+	OptionServerDryRun = "server-dry-run"
 	// OptionServerURI is serverURI option.
 	OptionServerURI = "server-uri"
+	// OptionSince is since option. Used to only show recent logs.
+	OptionSince = "since"
+	// OptionShowSecrets is showSecrets option. Used to show values which are
+	// redacted by default.
+	OptionShowSecrets = "show-secrets"
 	// OptionSkipDefaultRegistries is skipDefaultRegistries option. Used by init.
 	OptionSkipDefaultRegistries = "skip-default-registries"
 	// OptionSkipGc is skipGc option.
@@ -110,22 +225,49 @@ const (
 	OptionSrc1 = "src-1"
 	// OptionSrc2 is src2 option.
 	OptionSrc2 = "src-2"
+	// OptionStrict is strict option. Used to reject unknown fields when validating.
+	OptionStrict = "strict"
+	// OptionTail is tail option. The number of recent log lines to show.
+	OptionTail = "tail"
 	// OptionTlaVarFiles is jsonnet tla var files.
 	OptionTlaVarFiles = "tla-var-files"
 	// OptionTlaVars is jsonnet tla vars.
 	OptionTlaVars = "tla-vars"
 	// OptionTLSSkipVerify specifies that tls server certifactes should not be verified.
 	OptionTLSSkipVerify = "tls-skip-verify"
+	// OptionToVersion is to version option. Used to compare package versions.
+	OptionToVersion = "to-version"
 	// OptionUnset is unset option.
 	OptionUnset = "unset"
+	// OptionUpdate is update option. Used to update test snapshots.
+	OptionUpdate = "update"
 	// OptionURI is uri option. Used for setting registry URI.
 	OptionURI = "URI"
 	// OptionWithoutModules is without modules option.
 	OptionWithoutModules = "without-modules"
 	// OptionValue is value option.
 	OptionValue = "value"
+	// OptionVerifyReproducible is verify reproducible option. Used to render an
+	// environment twice, and fail if the renderings differ.
+	OptionVerifyReproducible = "verify-reproducible"
 	// OptionVersion is version option.
 	OptionVersion = "version"
+	// OptionWatch is watch option. Used to re-run a command periodically.
+	OptionWatch = "watch"
+	// OptionWatchInterval is watch interval option.
+	OptionWatchInterval = "watch-interval"
+	// OptionWait is wait option. Used to wait for applied objects to become ready.
+	OptionWait = "wait"
+	// OptionWaitTimeout is wait timeout option.
+	OptionWaitTimeout = "wait-timeout"
+	// OptionWithDependencies is with dependencies option. Used to include the
+	// components that selected components depend on.
+	OptionWithDependencies = "with-dependencies"
+	// OptionWithDependents is with dependents option. Used to include the
+	// components that depend on selected components.
+	OptionWithDependents = "with-dependents"
+	// OptionWrite is write option. Used to rewrite files in place.
+	OptionWrite = "write"
 )
 
 const (
@@ -133,6 +275,10 @@ const (
 	OutputWide = "wide"
 	// OutputJSON is JSON output
 	OutputJSON = "json"
+	// OutputText is text output
+	OutputText = "text"
+	// OutputSemantic is semantic output. It prints differences field by field.
+	OutputSemantic = "semantic"
 )
 
 var (
@@ -259,6 +405,35 @@ func (o *optionLoader) LoadInt64(name string) int64 {
 	return a
 }
 
+func (o *optionLoader) LoadDuration(name string) time.Duration {
+	i := o.load(name)
+	if i == nil {
+		return 0
+	}
+
+	a, ok := i.(time.Duration)
+	if !ok {
+		o.err = newInvalidOptionError(name)
+		return 0
+	}
+
+	return a
+}
+
+func (o *optionLoader) LoadOptionalDuration(name string) time.Duration {
+	i := o.loadOptional(name)
+	if i == nil {
+		return 0
+	}
+
+	a, ok := i.(time.Duration)
+	if !ok {
+		return 0
+	}
+
+	return a
+}
+
 func (o *optionLoader) LoadOptionalInt(name string) int {
 	i := o.loadOptional(name)
 	if i == nil {
@@ -273,6 +448,20 @@ func (o *optionLoader) LoadOptionalInt(name string) int {
 	return a
 }
 
+func (o *optionLoader) LoadOptionalFloat32(name string) float32 {
+	i := o.loadOptional(name)
+	if i == nil {
+		return 0
+	}
+
+	a, ok := i.(float32)
+	if !ok {
+		return 0
+	}
+
+	return a
+}
+
 func (o *optionLoader) LoadString(name string) string {
 	i := o.load(name)
 	if i == nil {
@@ -317,6 +506,20 @@ func (o *optionLoader) LoadStringSlice(name string) []string {
 	return a
 }
 
+func (o *optionLoader) LoadOptionalStringSlice(name string) []string {
+	i := o.loadOptional(name)
+	if i == nil {
+		return nil
+	}
+
+	a, ok := i.([]string)
+	if !ok {
+		return nil
+	}
+
+	return a
+}
+
 func (o *optionLoader) LoadClientConfig() *client.Config {
 	i := o.load(OptionClientConfig)
 	if i == nil {
@@ -348,10 +551,41 @@ func (o *optionLoader) LoadApp() app.App {
 	return a
 }
 
-// LoadHTTPClient loads an HTTP client based on common configuration for certificates, tls verification, timeouts, etc.
-func (o *optionLoader) LoadHTTPClient() *http.Client {
-	tlsSkipVerify := o.LoadOptionalBool(OptionTLSSkipVerify)
+// LoadOptionalApp loads the app, if there is one.
+func (o *optionLoader) LoadOptionalApp() app.App {
+	i := o.loadOptional(OptionApp)
+	if i == nil {
+		return nil
+	}
+
+	a, ok := i.(app.App)
+	if !ok {
+		o.err = newInvalidOptionError(OptionApp)
+		return nil
+	}
+
+	return a
+}
+
+// LoadOptionalWriter loads a writer, if there is one.
+func (o *optionLoader) LoadOptionalWriter(name string) io.Writer {
+	i := o.loadOptional(name)
+	if i == nil {
+		return nil
+	}
+
+	w, ok := i.(io.Writer)
+	if !ok {
+		o.err = newInvalidOptionError(name)
+		return nil
+	}
+
+	return w
+}
 
+// newHTTPClient creates an HTTP client with the common configuration for
+// certificates, tls verification, timeouts, etc.
+func newHTTPClient(tlsSkipVerify bool) *http.Client {
 	tlsConfig := &tls.Config{
 		InsecureSkipVerify: tlsSkipVerify,
 	}
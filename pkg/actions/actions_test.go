@@ -186,6 +186,13 @@ func Test_optionLoader_optional_types(t *testing.T) {
 			expected: "",
 			keyName:  OptionApp,
 		},
+		{
+			name:     "StringSlice",
+			valid:    []string{"valid"},
+			invalid:  "invalid",
+			expected: []string(nil),
+			keyName:  OptionApp,
+		},
 	}
 
 	for _, tc := range cases {
@@ -276,6 +283,7 @@ func stageFile(t *testing.T, fs afero.Fs, src, dest string) {
 func mockNsWithName(name string) *cmocks.Module {
 	m := &cmocks.Module{}
 	m.On("Name").Return(name)
+	m.On("Dir").Return("/components/" + name)
 	return m
 }
 
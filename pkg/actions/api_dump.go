@@ -0,0 +1,357 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	"github.com/ksonnet/ksonnet/pkg/prototype"
+	"github.com/ksonnet/ksonnet/pkg/registry"
+	"github.com/pkg/errors"
+)
+
+// apiDumpVersion is the version of the format written by `api dump`. Fields
+// are only added to a version of the format.
+const apiDumpVersion = "ksonnet.io/api/v1"
+
+// RunAPIDump runs `api dump`.
+func RunAPIDump(m map[string]interface{}) error {
+	var o APIDumpOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunAPIDumpWithOptions(o)
+}
+
+// RunAPIDumpWithOptions runs `api dump` with typed options.
+func RunAPIDumpWithOptions(o APIDumpOptions) error {
+	ad, err := newAPIDumpWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return ad.run()
+}
+
+type apiDumpOpt func(*APIDump)
+
+// APIDump writes a JSON description of an app, so editors and other tools
+// can complete components, parameters, environments, and prototypes without
+// running several commands.
+type APIDump struct {
+	app app.App
+	out io.Writer
+
+	cm             component.Manager
+	packageManager registry.PackageManager
+}
+
+// APIDumpOptions are the options for APIDump.
+type APIDumpOptions struct {
+	App           app.App   `option:"app"`
+	Out           io.Writer `option:"out,optional"`
+	TLSSkipVerify bool      `option:"tls-skip-verify,optional"`
+}
+
+func newAPIDump(m map[string]interface{}, opts ...apiDumpOpt) (*APIDump, error) {
+	var o APIDumpOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return newAPIDumpWithOptions(o, opts...)
+}
+
+func newAPIDumpWithOptions(o APIDumpOptions, opts ...apiDumpOpt) (*APIDump, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	httpClientOpt := registry.HTTPClientOpt(newHTTPClient(o.TLSSkipVerify))
+
+	ad := &APIDump{
+		app: o.App,
+		out: os.Stdout,
+
+		cm:             component.DefaultManager,
+		packageManager: registry.NewPackageManager(o.App, httpClientOpt),
+	}
+
+	if o.Out != nil {
+		ad.out = o.Out
+	}
+
+	for _, opt := range opts {
+		opt(ad)
+	}
+
+	return ad, nil
+}
+
+// apiDescription is the description of an app written by `api dump`.
+type apiDescription struct {
+	APIVersion         string           `json:"apiVersion"`
+	Root               string           `json:"root"`
+	CurrentEnvironment string           `json:"currentEnvironment,omitempty"`
+	Components         []apiComponent   `json:"components"`
+	Environments       []apiEnvironment `json:"environments"`
+	Registries         []apiRegistry    `json:"registries"`
+	Prototypes         []apiPrototype   `json:"prototypes"`
+}
+
+type apiComponent struct {
+	Name   string     `json:"name"`
+	Module string     `json:"module"`
+	Type   string     `json:"type"`
+	Params []apiParam `json:"params"`
+}
+
+type apiParam struct {
+	Name string `json:"name"`
+	// Type is inferred from the value. It is empty when the value is an
+	// expression.
+	Type  string `json:"type,omitempty"`
+	Value string `json:"value"`
+}
+
+type apiEnvironment struct {
+	Name              string   `json:"name"`
+	KubernetesVersion string   `json:"kubernetesVersion,omitempty"`
+	Server            string   `json:"server,omitempty"`
+	Namespace         string   `json:"namespace,omitempty"`
+	Targets           []string `json:"targets,omitempty"`
+	Override          bool     `json:"override,omitempty"`
+}
+
+type apiRegistry struct {
+	Name     string `json:"name"`
+	Protocol string `json:"protocol"`
+	URI      string `json:"uri"`
+}
+
+type apiPrototype struct {
+	Name             string              `json:"name"`
+	ShortDescription string              `json:"shortDescription,omitempty"`
+	Description      string              `json:"description,omitempty"`
+	Params           []apiPrototypeParam `json:"params"`
+}
+
+type apiPrototypeParam struct {
+	Name        string  `json:"name"`
+	Type        string  `json:"type"`
+	Description string  `json:"description,omitempty"`
+	Default     *string `json:"default,omitempty"`
+	Required    bool    `json:"required"`
+}
+
+func (ad *APIDump) run() error {
+	d := apiDescription{
+		APIVersion:         apiDumpVersion,
+		Root:               ad.app.Root(),
+		CurrentEnvironment: ad.app.CurrentEnvironment(),
+	}
+
+	var err error
+	if d.Components, err = ad.components(); err != nil {
+		return errors.Wrap(err, "describing components")
+	}
+	if d.Environments, err = ad.environments(); err != nil {
+		return errors.Wrap(err, "describing environments")
+	}
+	if d.Registries, err = ad.registries(); err != nil {
+		return errors.Wrap(err, "describing registries")
+	}
+	if d.Prototypes, err = ad.prototypes(); err != nil {
+		return errors.Wrap(err, "describing prototypes")
+	}
+
+	enc := json.NewEncoder(ad.out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(d)
+}
+
+func (ad *APIDump) components() ([]apiComponent, error) {
+	components, err := ad.cm.Components(ad.app, "")
+	if err != nil {
+		return nil, err
+	}
+
+	list := []apiComponent{}
+	for _, c := range components {
+		name := c.Name(true)
+		module := "/"
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			module = name[:i]
+		}
+
+		params, err := c.Params("")
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading params of %s", name)
+		}
+
+		ac := apiComponent{
+			Name:   name,
+			Module: module,
+			Type:   c.Type(),
+			Params: []apiParam{},
+		}
+		for _, p := range params {
+			ac.Params = append(ac.Params, apiParam{
+				Name:  p.Key,
+				Type:  paramValueType(p.Value),
+				Value: p.Value,
+			})
+		}
+		sort.Slice(ac.Params, func(i, j int) bool {
+			return ac.Params[i].Name < ac.Params[j].Name
+		})
+
+		list = append(list, ac)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	return list, nil
+}
+
+// paramValueType infers the type of a parameter from its jsonnet value.
+func paramValueType(value string) string {
+	value = strings.TrimSpace(value)
+	switch {
+	case value == "":
+		return ""
+	case value == "true", value == "false":
+		return "boolean"
+	case value == "null":
+		return "null"
+	case value[0] == '"', value[0] == '\'':
+		return string(prototype.String)
+	case value[0] == '[':
+		return string(prototype.Array)
+	case value[0] == '{':
+		return string(prototype.Object)
+	}
+
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return string(prototype.Number)
+	}
+
+	return ""
+}
+
+func (ad *APIDump) environments() ([]apiEnvironment, error) {
+	environments, err := ad.app.Environments()
+	if err != nil {
+		return nil, err
+	}
+
+	list := []apiEnvironment{}
+	for name, env := range environments {
+		ae := apiEnvironment{
+			Name:              name,
+			KubernetesVersion: env.KubernetesVersion,
+			Targets:           env.Targets,
+			Override:          env.IsOverride(),
+		}
+		destination := env.PrimaryDestination()
+		ae.Server = destination.Server
+		ae.Namespace = destination.Namespace
+
+		list = append(list, ae)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	return list, nil
+}
+
+func (ad *APIDump) registries() ([]apiRegistry, error) {
+	registries, err := ad.app.Registries()
+	if err != nil {
+		return nil, err
+	}
+
+	list := []apiRegistry{}
+	for name, r := range registries {
+		list = append(list, apiRegistry{
+			Name:     name,
+			Protocol: r.Protocol,
+			URI:      r.URI,
+		})
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	return list, nil
+}
+
+func (ad *APIDump) prototypes() ([]apiPrototype, error) {
+	prototypes, err := ad.packageManager.Prototypes()
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := prototype.NewIndex(prototypes, prototype.DefaultBuilder)
+	if err != nil {
+		return nil, err
+	}
+
+	prototypes, err = index.List()
+	if err != nil {
+		return nil, err
+	}
+
+	list := []apiPrototype{}
+	for _, p := range prototypes {
+		ap := apiPrototype{
+			Name:             p.Name,
+			ShortDescription: p.Template.ShortDescription,
+			Description:      p.Template.Description,
+			Params:           []apiPrototypeParam{},
+		}
+		for _, param := range p.Params {
+			ap.Params = append(ap.Params, apiPrototypeParam{
+				Name:        param.Name,
+				Type:        string(param.Type),
+				Description: param.Description,
+				Default:     param.Default,
+				Required:    param.Default == nil,
+			})
+		}
+
+		list = append(list, ap)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	return list, nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	cmocks "github.com/ksonnet/ksonnet/pkg/component/mocks"
+	"github.com/ksonnet/ksonnet/pkg/prototype"
+	rmocks "github.com/ksonnet/ksonnet/pkg/registry/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIDump(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("CurrentEnvironment").Return("default")
+		appMock.On("Environments").Return(app.EnvironmentConfigs{
+			"default": &app.EnvironmentConfig{
+				KubernetesVersion: "v1.10.0",
+				Destination: &app.EnvironmentDestinationSpec{
+					Server:    "https://cluster",
+					Namespace: "default",
+				},
+			},
+			"prod": &app.EnvironmentConfig{
+				Targets: []string{"/"},
+			},
+		}, nil)
+		appMock.On("Registries").Return(app.RegistryConfigs{
+			"incubator": &app.RegistryConfig{
+				Protocol: "github",
+				URI:      "github.com/ksonnet/parts/tree/master/incubator",
+			},
+		}, nil)
+
+		guestbook := &cmocks.Component{}
+		guestbook.On("Name", true).Return("guestbook")
+		guestbook.On("Type").Return("jsonnet")
+		guestbook.On("Params", "").Return([]component.ModuleParameter{
+			{Component: "guestbook", Key: "replicas", Value: "1"},
+			{Component: "guestbook", Key: "image", Value: `"nginx"`},
+			{Component: "guestbook", Key: "ports", Value: "[80]"},
+			{Component: "guestbook", Key: "name", Value: "std.extVar('name')"},
+		}, nil)
+
+		redis := &cmocks.Component{}
+		redis.On("Name", true).Return("db.redis")
+		redis.On("Type").Return("yaml")
+		redis.On("Params", "").Return([]component.ModuleParameter{
+			{Component: "redis", Key: "enabled", Value: "true"},
+		}, nil)
+
+		cm := &cmocks.Manager{}
+		cm.On("Components", mock.Anything, "").Return([]component.Component{redis, guestbook}, nil)
+
+		pm := &rmocks.PackageManager{}
+		pm.On("Prototypes").Return(prototype.Prototypes{}, nil)
+
+		in := map[string]interface{}{
+			OptionApp: appMock,
+		}
+
+		var buf bytes.Buffer
+		ad, err := newAPIDump(in, func(ad *APIDump) {
+			ad.out = &buf
+			ad.cm = cm
+			ad.packageManager = pm
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, ad.run())
+		assertOutput(t, "api/dump/output.json", buf.String())
+	})
+}
+
+func Test_paramValueType(t *testing.T) {
+	cases := map[string]string{
+		`"nginx"`:         "string",
+		`'nginx'`:         "string",
+		"80":              "number",
+		"-1.5":            "number",
+		"true":            "boolean",
+		"null":            "null",
+		"[1, 2]":          "array",
+		"{ a: 1 }":        "object",
+		"std.extVar('x')": "",
+		"":                "",
+	}
+
+	for value, expected := range cases {
+		assert.Equal(t, expected, paramValueType(value), value)
+	}
+}
+
+func TestAPIDump_requires_app(t *testing.T) {
+	_, err := newAPIDump(map[string]interface{}{})
+	require.Error(t, err)
+}
@@ -16,16 +16,40 @@
 package actions
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/client"
 	"github.com/ksonnet/ksonnet/pkg/cluster"
+	"github.com/ksonnet/ksonnet/pkg/util/k8s"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
+type fanOutFn func(cluster.FanOutConfig, cluster.FanOutFn) error
+
 type runApplyFn func(cluster.ApplyConfig, ...cluster.ApplyOpts) error
 
+type runApplyResultFn func(cluster.ApplyConfig, ...cluster.ApplyOpts) (*cluster.ApplyResult, error)
+
 // RunApply runs `apply`.
 func RunApply(m map[string]interface{}) error {
-	a, err := newApply(m)
+	var o ApplyOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunApplyWithOptions(o)
+}
+
+// RunApplyWithOptions runs `apply` with typed options.
+func RunApplyWithOptions(o ApplyOptions) error {
+	a, err := newApplyWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -37,43 +61,154 @@ type applyOpt func(*Apply)
 
 // Apply collects options for applying objects to a cluster.
 type Apply struct {
-	app            app.App
-	clientConfig   *client.Config
-	componentNames []string
-	create         bool
-	dryRun         bool
-	envName        string
-	gcTag          string
-	skipGc         bool
-
-	runApplyFn runApplyFn
+	app              app.App
+	batchSize        int
+	burst            int
+	clientConfig     *client.Config
+	componentNames   []string
+	create           bool
+	dryRun           bool
+	disableHooks     bool
+	envName          string
+	fromStdin        bool
+	gcTag            string
+	gcLabels         bool
+	gcAppLabel       string
+	gcEnvLabel       string
+	maxUnavailable   int
+	output           string
+	parallelism      int
+	qps              float32
+	serverDryRun     bool
+	skipGc           bool
+	wait             bool
+	waitTimeout      time.Duration
+	withDependencies bool
+	withDependents   bool
+	confirmer        *confirmer
+
+	fanOutFn   fanOutFn
+	runApplyFn runApplyResultFn
+
+	in     io.Reader
+	out    io.Writer
+	errOut io.Writer
+}
+
+// ApplyOptions are the options for Apply.
+type ApplyOptions struct {
+	App              app.App        `option:"app"`
+	AssumeYes        bool           `option:"assume-yes,optional"`
+	BatchSize        int            `option:"batch-size,optional"`
+	Burst            int            `option:"burst,optional"`
+	ClientConfig     *client.Config `option:"client-config"`
+	ComponentNames   []string       `option:"component-names"`
+	Create           bool           `option:"create"`
+	DryRun           bool           `option:"dry-run"`
+	DisableHooks     bool           `option:"disable-command-hooks,optional"`
+	EnvName          string         `option:"env-name,optional"`
+	FromStdin        bool           `option:"from-stdin,optional"`
+	GcTag            string         `option:"gc-tag"`
+	GcLabels         bool           `option:"gc-labels"`
+	GcAppLabel       string         `option:"gc-app-label,optional"`
+	GcEnvLabel       string         `option:"gc-env-label,optional"`
+	MaxUnavailable   int            `option:"max-unavailable-clusters"`
+	Output           string         `option:"output,optional"`
+	Parallelism      int            `option:"parallelism,optional"`
+	QPS              float32        `option:"qps,optional"`
+	ServerDryRun     bool           `option:"server-dry-run"`
+	SkipGc           bool           `option:"skip-gc"`
+	Wait             bool           `option:"wait"`
+	WaitTimeout      time.Duration  `option:"wait-timeout"`
+	WithDependencies bool           `option:"with-dependencies,optional"`
+	WithDependents   bool           `option:"with-dependents,optional"`
+	Out              io.Writer      `option:"out,optional"`
+}
+
+// Validate checks that the options are valid together.
+func (o *ApplyOptions) Validate() error {
+	if o.Parallelism < 0 || o.BatchSize < 0 || o.Burst < 0 || o.QPS < 0 {
+		return errors.New("parallelism, batch size, qps, and burst can not be negative")
+	}
+
+	switch o.Output {
+	default:
+		return errors.Errorf("invalid output format %q; valid formats are text and json", o.Output)
+	case "", OutputText:
+	case OutputJSON:
+		if o.ServerDryRun {
+			return errors.New("json output is not supported with a server dry run")
+		}
+	}
+
+	if (o.WithDependencies || o.WithDependents) && len(o.ComponentNames) == 0 {
+		return errors.New("dependencies and dependents can only be included when components are specified")
+	}
+
+	return nil
 }
 
-// RunApply runs `apply`
 func newApply(m map[string]interface{}, opts ...applyOpt) (*Apply, error) {
-	ol := newOptionLoader(m)
+	var o ApplyOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return newApplyWithOptions(o, opts...)
+}
+
+func newApplyWithOptions(o ApplyOptions, opts ...applyOpt) (*Apply, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	if err := prepareOptions(&o); err != nil {
+		return nil, err
+	}
 
 	a := &Apply{
-		app:            ol.LoadApp(),
-		clientConfig:   ol.LoadClientConfig(),
-		componentNames: ol.LoadStringSlice(OptionComponentNames),
-		create:         ol.LoadBool(OptionCreate),
-		dryRun:         ol.LoadBool(OptionDryRun),
-		gcTag:          ol.LoadString(OptionGcTag),
-		skipGc:         ol.LoadBool(OptionSkipGc),
+		app:              o.App,
+		batchSize:        o.BatchSize,
+		burst:            o.Burst,
+		clientConfig:     o.ClientConfig,
+		componentNames:   o.ComponentNames,
+		create:           o.Create,
+		dryRun:           o.DryRun,
+		disableHooks:     o.DisableHooks,
+		fromStdin:        o.FromStdin,
+		gcTag:            o.GcTag,
+		gcLabels:         o.GcLabels,
+		gcAppLabel:       o.GcAppLabel,
+		gcEnvLabel:       o.GcEnvLabel,
+		maxUnavailable:   o.MaxUnavailable,
+		output:           o.Output,
+		parallelism:      o.Parallelism,
+		qps:              o.QPS,
+		serverDryRun:     o.ServerDryRun,
+		skipGc:           o.SkipGc,
+		wait:             o.Wait,
+		waitTimeout:      o.WaitTimeout,
+		withDependencies: o.WithDependencies,
+		withDependents:   o.WithDependents,
+		confirmer:        newConfirmer(o.AssumeYes),
 
-		runApplyFn: cluster.RunApply,
+		fanOutFn:   cluster.FanOut,
+		runApplyFn: cluster.RunApplyWithResult,
+
+		in:     os.Stdin,
+		out:    os.Stdout,
+		errOut: os.Stderr,
 	}
 
-	if ol.err != nil {
-		return nil, ol.err
+	if o.Out != nil {
+		a.out = o.Out
 	}
 
 	for _, opt := range opts {
 		opt(a)
 	}
 
-	if err := setCurrentEnv(a.app, a, ol); err != nil {
+	if err := setCurrentEnv(a.app, a, o.EnvName); err != nil {
 		return nil, err
 	}
 
@@ -89,12 +224,107 @@ func (a *Apply) run() error {
 		DryRun:         a.dryRun,
 		EnvName:        a.envName,
 		GcTag:          a.gcTag,
+		GcLabels:       a.gcLabels,
+		GcAppLabel:     a.gcAppLabel,
+		GcEnvLabel:     a.gcEnvLabel,
+		ServerDryRun:   a.serverDryRun,
 		SkipGc:         a.skipGc,
+		Wait:           a.wait,
+		WaitTimeout:    a.waitTimeout,
+		Parallelism:    a.parallelism,
+		QPS:            a.qps,
+		Burst:          a.burst,
+		BatchSize:      a.batchSize,
+
+		WithDependencies:    a.withDependencies,
+		WithDependents:      a.withDependents,
+		DisableCommandHooks: a.disableHooks,
+		ConfirmGc:           a.confirmGc,
+	}
+
+	if a.fromStdin {
+		objects, err := readStdinObjects(a.in, a.componentNames)
+		if err != nil {
+			return err
+		}
+		config.Objects = objects
 	}
 
-	return a.runApplyFn(config)
+	fanOutConfig := cluster.FanOutConfig{
+		App:            a.app,
+		ClientConfig:   a.clientConfig,
+		EnvName:        a.envName,
+		MaxUnavailable: a.maxUnavailable,
+	}
+
+	if a.output != OutputJSON {
+		return a.fanOutFn(fanOutConfig, func(_ string, clientConfig *client.Config) error {
+			config.ClientConfig = clientConfig
+			_, err := a.runApplyFn(config)
+			return err
+		})
+	}
+
+	// The cluster summary, policy violations, and hook output go to stderr,
+	// so stdout only holds the results.
+	fanOutConfig.Out = a.errOut
+	config.Out = a.errOut
+
+	results := []*cluster.ApplyResult{}
+	err := a.fanOutFn(fanOutConfig, func(destination string, clientConfig *client.Config) error {
+		config.ClientConfig = clientConfig
+		result, err := a.runApplyFn(config)
+		if result != nil {
+			result.Cluster = destination
+			results = append(results, result)
+		}
+		return err
+	})
+
+	// Results are written even if the apply failed, so the objects which
+	// were applied before the failure are reported.
+	enc := json.NewEncoder(a.out)
+	enc.SetIndent("", "  ")
+	if encErr := enc.Encode(results); encErr != nil {
+		return encErr
+	}
+
+	return err
+}
+
+// confirmGc confirms the deletion of objects by garbage collection. It is
+// only confirmed on a terminal, so scripts which garbage collect keep working
+// without --yes.
+func (a *Apply) confirmGc(objects []string) error {
+	if !a.confirmer.isTerminalFn() {
+		return nil
+	}
+
+	action := fmt.Sprintf("Garbage collect %d object(s) from environment %q: %s",
+		len(objects), a.envName, strings.Join(objects, ", "))
+	return a.confirmer.confirmEnv(a.app, a.envName, action)
 }
 
 func (a *Apply) setCurrentEnv(name string) {
 	a.envName = name
 }
+
+// readStdinObjects reads pre-rendered objects, e.g. the output of `ks show`,
+// from stdin. Components can not be selected, since the objects have already
+// been rendered.
+func readStdinObjects(r io.Reader, componentNames []string) ([]*unstructured.Unstructured, error) {
+	if len(componentNames) > 0 {
+		return nil, errors.New("components can not be selected when manifests are read from stdin")
+	}
+
+	objects, err := k8s.ReadObjects(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading manifests from stdin")
+	}
+
+	if len(objects) == 0 {
+		return nil, errors.New("no objects were read from stdin")
+	}
+
+	return objects, nil
+}
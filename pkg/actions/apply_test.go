@@ -16,11 +16,18 @@
 package actions
 
 import (
+	"bytes"
+	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/ksonnet/ksonnet/pkg/app"
 	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
 	"github.com/ksonnet/ksonnet/pkg/client"
 	"github.com/ksonnet/ksonnet/pkg/cluster"
+	"github.com/ksonnet/ksonnet/pkg/policy"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -50,16 +57,30 @@ func TestApply(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			withApp(t, func(appMock *amocks.App) {
 				appMock.On("CurrentEnvironment").Return(tc.currentName)
+				appMock.On("Environment", "default").Return(&app.EnvironmentConfig{
+					Destination: &app.EnvironmentDestinationSpec{Server: "http://example.com", Namespace: "default"},
+				}, nil)
 
 				in := map[string]interface{}{
-					OptionApp:            appMock,
-					OptionClientConfig:   &client.Config{},
-					OptionComponentNames: []string{},
-					OptionCreate:         true,
-					OptionDryRun:         true,
-					OptionEnvName:        tc.envName,
-					OptionGcTag:          "gc-tag",
-					OptionSkipGc:         true,
+					OptionApp:                    appMock,
+					OptionClientConfig:           &client.Config{},
+					OptionComponentNames:         []string{},
+					OptionCreate:                 true,
+					OptionDryRun:                 true,
+					OptionEnvName:                tc.envName,
+					OptionGcTag:                  "gc-tag",
+					OptionGcLabels:               true,
+					OptionGcAppLabel:             "app",
+					OptionGcEnvLabel:             "env",
+					OptionMaxUnavailableClusters: 1,
+					OptionParallelism:            4,
+					OptionQPS:                    float32(20),
+					OptionBurst:                  40,
+					OptionBatchSize:              100,
+					OptionServerDryRun:           false,
+					OptionSkipGc:                 true,
+					OptionWait:                   true,
+					OptionWaitTimeout:            time.Minute,
 				}
 
 				expected := cluster.ApplyConfig{
@@ -70,13 +91,24 @@ func TestApply(t *testing.T) {
 					DryRun:         true,
 					EnvName:        "default",
 					GcTag:          "gc-tag",
+					GcLabels:       true,
+					GcAppLabel:     "app",
+					GcEnvLabel:     "env",
 					SkipGc:         true,
+					Wait:           true,
+					WaitTimeout:    time.Minute,
+					Parallelism:    4,
+					QPS:            20,
+					Burst:          40,
+					BatchSize:      100,
 				}
 
 				runApplyOpt := func(a *Apply) {
-					a.runApplyFn = func(config cluster.ApplyConfig, opts ...cluster.ApplyOpts) error {
+					a.runApplyFn = func(config cluster.ApplyConfig, opts ...cluster.ApplyOpts) (*cluster.ApplyResult, error) {
+						assert.NotNil(t, config.ConfirmGc)
+						config.ConfirmGc = nil
 						assert.Equal(t, expected, config)
-						return nil
+						return &cluster.ApplyResult{}, nil
 					}
 				}
 
@@ -94,6 +126,376 @@ func TestApply(t *testing.T) {
 	}
 }
 
+func TestApply_multiple_destinations(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("Environment", "prod").Return(&app.EnvironmentConfig{
+			Destinations: []*app.EnvironmentDestinationSpec{
+				{Name: "east", Context: "east"},
+				{Name: "west", Context: "west"},
+			},
+		}, nil)
+
+		in := map[string]interface{}{
+			OptionApp:                    appMock,
+			OptionClientConfig:           &client.Config{},
+			OptionComponentNames:         []string{},
+			OptionCreate:                 true,
+			OptionDryRun:                 false,
+			OptionEnvName:                "prod",
+			OptionGcTag:                  "",
+			OptionGcLabels:               false,
+			OptionMaxUnavailableClusters: 0,
+			OptionServerDryRun:           false,
+			OptionSkipGc:                 false,
+			OptionWait:                   false,
+			OptionWaitTimeout:            time.Minute,
+		}
+
+		var contexts []string
+		runApplyOpt := func(a *Apply) {
+			a.runApplyFn = func(config cluster.ApplyConfig, opts ...cluster.ApplyOpts) (*cluster.ApplyResult, error) {
+				contexts = append(contexts, config.ClientConfig.Overrides.CurrentContext)
+				return &cluster.ApplyResult{}, nil
+			}
+		}
+
+		a, err := newApply(in, runApplyOpt)
+		require.NoError(t, err)
+
+		err = a.run()
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"east", "west"}, contexts)
+	})
+}
+
+func TestApply_json_output(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("Environment", "prod").Return(&app.EnvironmentConfig{
+			Destinations: []*app.EnvironmentDestinationSpec{
+				{Name: "east", Context: "east"},
+				{Name: "west", Context: "west"},
+			},
+		}, nil)
+
+		var buf bytes.Buffer
+		in := map[string]interface{}{
+			OptionApp:                    appMock,
+			OptionClientConfig:           &client.Config{},
+			OptionComponentNames:         []string{},
+			OptionCreate:                 true,
+			OptionDryRun:                 false,
+			OptionEnvName:                "prod",
+			OptionGcTag:                  "",
+			OptionGcLabels:               false,
+			OptionMaxUnavailableClusters: 0,
+			OptionOutput:                 OutputJSON,
+			OptionOut:                    &buf,
+			OptionServerDryRun:           false,
+			OptionSkipGc:                 false,
+			OptionWait:                   false,
+			OptionWaitTimeout:            time.Minute,
+		}
+
+		runApplyOpt := func(a *Apply) {
+			a.runApplyFn = func(config cluster.ApplyConfig, opts ...cluster.ApplyOpts) (*cluster.ApplyResult, error) {
+				result := &cluster.ApplyResult{
+					Environment: config.EnvName,
+					Objects: []cluster.ObjectResult{
+						{APIVersion: "v1", Kind: "Service", Name: "guestbook-ui", Action: cluster.ApplyCreated},
+					},
+				}
+
+				if config.ClientConfig.Overrides.CurrentContext == "west" {
+					result.Objects[0].Action = cluster.ApplyFailed
+					result.Objects[0].Message = "Invalid: bad port"
+					return result, errors.New("apply failed")
+				}
+
+				return result, nil
+			}
+		}
+
+		a, err := newApply(in, runApplyOpt)
+		require.NoError(t, err)
+
+		err = a.run()
+		require.Error(t, err)
+
+		var results []cluster.ApplyResult
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &results))
+		require.Len(t, results, 2)
+		assert.Equal(t, "east", results[0].Cluster)
+		assert.Equal(t, cluster.ApplyCreated, results[0].Objects[0].Action)
+		assert.Equal(t, "west", results[1].Cluster)
+		assert.Equal(t, []cluster.ObjectResult{results[1].Objects[0]}, results[1].Failed())
+	})
+}
+
+func TestApply_json_output_with_warnings(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("Environment", "prod").Return(&app.EnvironmentConfig{
+			Destination: &app.EnvironmentDestinationSpec{Server: "http://example.com", Namespace: "default"},
+		}, nil)
+
+		var stdout, stderr bytes.Buffer
+		in := map[string]interface{}{
+			OptionApp:                    appMock,
+			OptionClientConfig:           &client.Config{},
+			OptionComponentNames:         []string{},
+			OptionCreate:                 true,
+			OptionDryRun:                 false,
+			OptionEnvName:                "prod",
+			OptionGcTag:                  "",
+			OptionGcLabels:               false,
+			OptionMaxUnavailableClusters: 0,
+			OptionOutput:                 OutputJSON,
+			OptionOut:                    &stdout,
+			OptionServerDryRun:           false,
+			OptionSkipGc:                 false,
+			OptionWait:                   false,
+			OptionWaitTimeout:            time.Minute,
+		}
+
+		runApplyOpt := func(a *Apply) {
+			a.errOut = &stderr
+			a.runApplyFn = func(config cluster.ApplyConfig, opts ...cluster.ApplyOpts) (*cluster.ApplyResult, error) {
+				// Warn-level violations are reported, and the apply continues.
+				result := &policy.Result{Violations: []policy.Violation{
+					{Policy: "policies/labels.rego", Level: policy.LevelWarn, Kind: "Service", Name: "guestbook-ui", Message: "missing team label"},
+				}}
+				require.NoError(t, result.Render(config.Out, ""))
+
+				return &cluster.ApplyResult{
+					Environment: config.EnvName,
+					Objects: []cluster.ObjectResult{
+						{APIVersion: "v1", Kind: "Service", Name: "guestbook-ui", Action: cluster.ApplyCreated},
+					},
+				}, nil
+			}
+		}
+
+		a, err := newApply(in, runApplyOpt)
+		require.NoError(t, err)
+
+		require.NoError(t, a.run())
+
+		var results []cluster.ApplyResult
+		require.NoError(t, json.Unmarshal(stdout.Bytes(), &results))
+		require.Len(t, results, 1)
+		assert.Contains(t, stderr.String(), "missing team label")
+	})
+}
+
+func TestApply_confirm_gc(t *testing.T) {
+	cases := []struct {
+		name       string
+		assumeYes  bool
+		isTerminal bool
+		protected  bool
+		answer     string
+		isErr      bool
+	}{
+		{name: "assume yes", assumeYes: true},
+		{name: "not a terminal"},
+		{name: "not a terminal, protected", protected: true},
+		{name: "confirmed", isTerminal: true, answer: "y\n"},
+		{name: "not confirmed", isTerminal: true, answer: "n\n", isErr: true},
+		{name: "protected, confirmed by name", isTerminal: true, protected: true, answer: "prod\n"},
+		{name: "protected, confirmed with yes", isTerminal: true, protected: true, answer: "y\n", isErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				appMock.On("Environment", "prod").Return(&app.EnvironmentConfig{
+					Destination: &app.EnvironmentDestinationSpec{Server: "http://example.com", Namespace: "default"},
+					Protected:   tc.protected,
+				}, nil)
+
+				in := map[string]interface{}{
+					OptionApp:                    appMock,
+					OptionAssumeYes:              tc.assumeYes,
+					OptionClientConfig:           &client.Config{},
+					OptionComponentNames:         []string{},
+					OptionCreate:                 true,
+					OptionDryRun:                 false,
+					OptionEnvName:                "prod",
+					OptionGcTag:                  "",
+					OptionGcLabels:               true,
+					OptionMaxUnavailableClusters: 0,
+					OptionServerDryRun:           false,
+					OptionSkipGc:                 false,
+					OptionWait:                   false,
+					OptionWaitTimeout:            time.Minute,
+				}
+
+				var out bytes.Buffer
+				runApplyOpt := func(a *Apply) {
+					a.confirmer.in = strings.NewReader(tc.answer)
+					a.confirmer.out = &out
+					a.confirmer.isTerminalFn = func() bool { return tc.isTerminal }
+					a.runApplyFn = func(config cluster.ApplyConfig, opts ...cluster.ApplyOpts) (*cluster.ApplyResult, error) {
+						return &cluster.ApplyResult{}, config.ConfirmGc([]string{"services default/old (v1)"})
+					}
+				}
+
+				a, err := newApply(in, runApplyOpt)
+				require.NoError(t, err)
+
+				err = a.run()
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+
+				if tc.isTerminal {
+					assert.Contains(t, out.String(), "services default/old (v1)")
+				}
+			})
+		})
+	}
+}
+
+func TestApply_invalid_output(t *testing.T) {
+	cases := []struct {
+		name         string
+		output       string
+		serverDryRun bool
+	}{
+		{name: "unknown format", output: "yaml"},
+		{name: "json with server dry run", output: OutputJSON, serverDryRun: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				in := map[string]interface{}{
+					OptionApp:                    appMock,
+					OptionClientConfig:           &client.Config{},
+					OptionComponentNames:         []string{},
+					OptionCreate:                 true,
+					OptionDryRun:                 false,
+					OptionEnvName:                "default",
+					OptionGcTag:                  "",
+					OptionGcLabels:               false,
+					OptionMaxUnavailableClusters: 0,
+					OptionOutput:                 tc.output,
+					OptionServerDryRun:           tc.serverDryRun,
+					OptionSkipGc:                 false,
+					OptionWait:                   false,
+					OptionWaitTimeout:            time.Minute,
+				}
+
+				_, err := newApply(in)
+				require.Error(t, err)
+			})
+		})
+	}
+}
+
+func TestApplyOptions_Validate(t *testing.T) {
+	cases := []struct {
+		name  string
+		opts  ApplyOptions
+		isErr bool
+	}{
+		{name: "valid", opts: ApplyOptions{Output: OutputJSON, ComponentNames: []string{"a"}, WithDependencies: true}},
+		{name: "negative parallelism", opts: ApplyOptions{Parallelism: -1}, isErr: true},
+		{name: "unknown format", opts: ApplyOptions{Output: "yaml"}, isErr: true},
+		{name: "json with server dry run", opts: ApplyOptions{Output: OutputJSON, ServerDryRun: true}, isErr: true},
+		{name: "dependents without components", opts: ApplyOptions{WithDependents: true}, isErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.opts.Validate()
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestApply_from_stdin(t *testing.T) {
+	cases := []struct {
+		name           string
+		stdin          string
+		componentNames []string
+		expected       []string
+		isErr          bool
+	}{
+		{
+			name:     "manifests",
+			stdin:    "---\napiVersion: v1\nkind: Service\nmetadata:\n  name: guestbook-ui\n",
+			expected: []string{"guestbook-ui"},
+		},
+		{
+			name:  "no manifests",
+			isErr: true,
+		},
+		{
+			name:           "with components",
+			stdin:          "---\napiVersion: v1\nkind: Service\nmetadata:\n  name: guestbook-ui\n",
+			componentNames: []string{"guestbook-ui"},
+			isErr:          true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				appMock.On("Environment", "default").Return(&app.EnvironmentConfig{
+					Destination: &app.EnvironmentDestinationSpec{Server: "http://example.com", Namespace: "default"},
+				}, nil)
+
+				in := map[string]interface{}{
+					OptionApp:                    appMock,
+					OptionClientConfig:           &client.Config{},
+					OptionComponentNames:         tc.componentNames,
+					OptionCreate:                 true,
+					OptionDryRun:                 false,
+					OptionEnvName:                "default",
+					OptionFromStdin:              true,
+					OptionGcTag:                  "",
+					OptionGcLabels:               false,
+					OptionMaxUnavailableClusters: 0,
+					OptionServerDryRun:           false,
+					OptionSkipGc:                 false,
+					OptionWait:                   false,
+					OptionWaitTimeout:            time.Minute,
+				}
+
+				var names []string
+				runApplyOpt := func(a *Apply) {
+					a.in = strings.NewReader(tc.stdin)
+					a.runApplyFn = func(config cluster.ApplyConfig, opts ...cluster.ApplyOpts) (*cluster.ApplyResult, error) {
+						for _, obj := range config.Objects {
+							names = append(names, obj.GetName())
+						}
+						return &cluster.ApplyResult{}, nil
+					}
+				}
+
+				a, err := newApply(in, runApplyOpt)
+				require.NoError(t, err)
+
+				err = a.run()
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+
+				assert.Equal(t, tc.expected, names)
+			})
+		})
+	}
+}
+
 func TestApply_invalid_input(t *testing.T) {
 	withApp(t, func(appMock *amocks.App) {
 		in := map[string]interface{}{
@@ -105,6 +507,87 @@ func TestApply_invalid_input(t *testing.T) {
 	})
 }
 
+func TestApply_negative_parallelism(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionApp:                    appMock,
+			OptionClientConfig:           &client.Config{},
+			OptionComponentNames:         []string{},
+			OptionCreate:                 true,
+			OptionDryRun:                 false,
+			OptionEnvName:                "default",
+			OptionGcTag:                  "",
+			OptionGcLabels:               false,
+			OptionMaxUnavailableClusters: 0,
+			OptionParallelism:            -1,
+			OptionServerDryRun:           false,
+			OptionSkipGc:                 false,
+			OptionWait:                   false,
+			OptionWaitTimeout:            time.Minute,
+		}
+
+		_, err := newApply(in)
+		require.Error(t, err)
+	})
+}
+
+func TestApply_with_dependencies(t *testing.T) {
+	cases := []struct {
+		name           string
+		componentNames []string
+		isErr          bool
+	}{
+		{name: "with components", componentNames: []string{"guestbook-ui"}},
+		{name: "without components", componentNames: []string{}, isErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				appMock.On("Environment", "default").Return(&app.EnvironmentConfig{}, nil)
+
+				in := map[string]interface{}{
+					OptionApp:                    appMock,
+					OptionClientConfig:           &client.Config{},
+					OptionComponentNames:         tc.componentNames,
+					OptionCreate:                 true,
+					OptionDryRun:                 false,
+					OptionEnvName:                "default",
+					OptionGcTag:                  "",
+					OptionGcLabels:               false,
+					OptionMaxUnavailableClusters: 0,
+					OptionServerDryRun:           false,
+					OptionSkipGc:                 false,
+					OptionWait:                   false,
+					OptionWaitTimeout:            time.Minute,
+					OptionWithDependencies:       true,
+					OptionWithDependents:         true,
+				}
+
+				runApplyOpt := func(a *Apply) {
+					a.fanOutFn = func(_ cluster.FanOutConfig, fn cluster.FanOutFn) error {
+						return fn("", &client.Config{})
+					}
+					a.runApplyFn = func(config cluster.ApplyConfig, opts ...cluster.ApplyOpts) (*cluster.ApplyResult, error) {
+						assert.True(t, config.WithDependencies)
+						assert.True(t, config.WithDependents)
+						return &cluster.ApplyResult{}, nil
+					}
+				}
+
+				a, err := newApply(in, runApplyOpt)
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+
+				require.NoError(t, a.run())
+			})
+		})
+	}
+}
+
 func TestApply_requires_app(t *testing.T) {
 	in := make(map[string]interface{})
 	_, err := newApply(in)
@@ -0,0 +1,103 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/bundle"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// RunBundle runs `bundle`.
+func RunBundle(m map[string]interface{}) error {
+	var o BundleOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunBundleWithOptions(o)
+}
+
+// RunBundleWithOptions runs `bundle` with typed options.
+func RunBundleWithOptions(o BundleOptions) error {
+	b, err := newBundleWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return b.run()
+}
+
+type bundleOpt func(*Bundle)
+
+// Bundle packs an app into a single archive.
+type Bundle struct {
+	app  app.App
+	path string
+}
+
+// BundleOptions are the options for Bundle.
+type BundleOptions struct {
+	App  app.App `option:"app"`
+	Path string  `option:"path"`
+}
+
+func newBundle(m map[string]interface{}, opts ...bundleOpt) (*Bundle, error) {
+	var o BundleOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return newBundleWithOptions(o, opts...)
+}
+
+func newBundleWithOptions(o BundleOptions, opts ...bundleOpt) (*Bundle, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	b := &Bundle{
+		app:  o.App,
+		path: o.Path,
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b, nil
+}
+
+func (b *Bundle) run() error {
+	f, err := b.app.Fs().Create(b.path)
+	if err != nil {
+		return errors.Wrap(err, "creating bundle")
+	}
+
+	if err := bundle.Write(b.app.Fs(), b.app.Root(), f); err != nil {
+		f.Close()
+		b.app.Fs().Remove(b.path)
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	log.Infof("bundled app to %s", b.path)
+	return nil
+}
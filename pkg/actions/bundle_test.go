@@ -0,0 +1,76 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"testing"
+
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/bundle"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundle(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		fs := appMock.Fs()
+		require.NoError(t, afero.WriteFile(fs, "/app.yaml", []byte("apiVersion: 0.3.0"), 0644))
+		require.NoError(t, afero.WriteFile(fs, "/components/web.jsonnet", []byte("{}"), 0644))
+
+		in := map[string]interface{}{
+			OptionApp:  appMock,
+			OptionPath: "/app.tgz",
+		}
+
+		b, err := newBundle(in)
+		require.NoError(t, err)
+		require.NoError(t, b.run())
+
+		data, err := afero.ReadFile(fs, "/app.tgz")
+		require.NoError(t, err)
+
+		require.NoError(t, bundle.Extract(fs, bytes.NewReader(data), "/extracted"))
+
+		exists, err := afero.Exists(fs, "/extracted/components/web.jsonnet")
+		require.NoError(t, err)
+		assert.True(t, exists)
+	})
+}
+
+func TestBundle_not_an_app(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionApp:  appMock,
+			OptionPath: "/app.tgz",
+		}
+
+		b, err := newBundle(in)
+		require.NoError(t, err)
+		require.Error(t, b.run())
+
+		exists, err := afero.Exists(appMock.Fs(), "/app.tgz")
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+}
+
+func TestBundle_requires_app(t *testing.T) {
+	in := make(map[string]interface{})
+	_, err := newBundle(in)
+	require.Error(t, err)
+}
@@ -0,0 +1,202 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	"github.com/ksonnet/ksonnet/pkg/pkg"
+	"github.com/ksonnet/ksonnet/pkg/registry"
+	"github.com/pkg/errors"
+)
+
+const (
+	// CompleteEnvironments completes environment names.
+	CompleteEnvironments = "environments"
+	// CompleteComponents completes component names.
+	CompleteComponents = "components"
+	// CompleteRegistries completes registry names.
+	CompleteRegistries = "registries"
+	// CompletePackages completes installed and remote package names.
+	CompletePackages = "packages"
+)
+
+// RunComplete runs `__complete`
+func RunComplete(m map[string]interface{}) error {
+	var o CompleteOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunCompleteWithOptions(o)
+}
+
+// RunCompleteWithOptions runs `__complete` with typed options.
+func RunCompleteWithOptions(o CompleteOptions) error {
+	c, err := newCompleteWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return c.run()
+}
+
+type completeOpt func(*Complete)
+
+// Complete writes the values of a kind, e.g. environment names, which start
+// with a prefix. It is used by shell completion scripts.
+type Complete struct {
+	app    app.App
+	kind   string
+	prefix string
+	out    io.Writer
+
+	componentsFn     func(a app.App, module string) ([]component.Component, error)
+	remotePackagesFn func() ([]pkg.Package, error)
+}
+
+// CompleteOptions are the options for Complete.
+type CompleteOptions struct {
+	App           app.App   `option:"app,optional"`
+	Kind          string    `option:"name"`
+	Prefix        string    `option:"query,optional"`
+	TLSSkipVerify bool      `option:"tls-skip-verify,optional"`
+	Out           io.Writer `option:"out,optional"`
+}
+
+func newComplete(m map[string]interface{}, opts ...completeOpt) (*Complete, error) {
+	var o CompleteOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return newCompleteWithOptions(o, opts...)
+}
+
+func newCompleteWithOptions(o CompleteOptions, opts ...completeOpt) (*Complete, error) {
+	c := &Complete{
+		app:    o.App,
+		kind:   o.Kind,
+		prefix: o.Prefix,
+		out:    os.Stdout,
+
+		componentsFn: component.DefaultManager.Components,
+	}
+
+	if o.Out != nil {
+		c.out = o.Out
+	}
+
+	if c.app != nil {
+		pm := registry.NewPackageManager(c.app, registry.HTTPClientOpt(newHTTPClient(o.TLSSkipVerify)))
+		c.remotePackagesFn = pm.RemotePackages
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, nil
+}
+
+func (c *Complete) run() error {
+	// Nothing can be completed outside of an app.
+	if c.app == nil {
+		return nil
+	}
+
+	values, err := c.values()
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	var matches []string
+	for _, v := range values {
+		if seen[v] || !strings.HasPrefix(v, c.prefix) {
+			continue
+		}
+		seen[v] = true
+		matches = append(matches, v)
+	}
+
+	sort.Strings(matches)
+
+	for _, v := range matches {
+		fmt.Fprintln(c.out, v)
+	}
+
+	return nil
+}
+
+func (c *Complete) values() ([]string, error) {
+	var values []string
+
+	switch c.kind {
+	case CompleteEnvironments:
+		envs, err := c.app.Environments()
+		if err != nil {
+			return nil, err
+		}
+		for name := range envs {
+			values = append(values, name)
+		}
+	case CompleteComponents:
+		components, err := c.componentsFn(c.app, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, comp := range components {
+			values = append(values, comp.Name(true))
+		}
+	case CompleteRegistries:
+		registries, err := c.app.Registries()
+		if err != nil {
+			return nil, err
+		}
+		for name := range registries {
+			values = append(values, name)
+		}
+	case CompletePackages:
+		libraries, err := c.app.Libraries()
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range libraries {
+			values = append(values, l.Registry+"/"+l.Name)
+		}
+
+		// Remote packages are best effort, since registries may not be
+		// reachable while completing.
+		remote, err := c.remotePackagesFn()
+		if err != nil {
+			return values, nil
+		}
+		for _, p := range remote {
+			values = append(values, p.RegistryName()+"/"+p.Name())
+		}
+	default:
+		return nil, errors.Errorf("unable to complete %q", c.kind)
+	}
+
+	return values, nil
+}
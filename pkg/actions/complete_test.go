@@ -0,0 +1,141 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	cmocks "github.com/ksonnet/ksonnet/pkg/component/mocks"
+	"github.com/ksonnet/ksonnet/pkg/pkg"
+	pmocks "github.com/ksonnet/ksonnet/pkg/pkg/mocks"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComplete(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("Environments").Return(app.EnvironmentConfigs{
+			"default": &app.EnvironmentConfig{},
+			"dev":     &app.EnvironmentConfig{},
+			"prod":    &app.EnvironmentConfig{},
+		}, nil)
+		appMock.On("Registries").Return(app.RegistryConfigs{
+			"incubator": &app.RegistryConfig{},
+			"helm":      &app.RegistryConfig{},
+		}, nil)
+		appMock.On("Libraries").Return(app.LibraryConfigs{
+			"redis": &app.LibraryConfig{Name: "redis", Registry: "incubator"},
+		}, nil)
+
+		c1 := &cmocks.Component{}
+		c1.On("Name", true).Return("guestbook")
+		c2 := &cmocks.Component{}
+		c2.On("Name", true).Return("nested.redis")
+
+		remote := &pmocks.Package{}
+		remote.On("RegistryName").Return("incubator")
+		remote.On("Name").Return("mysql")
+
+		cases := []struct {
+			name     string
+			kind     string
+			prefix   string
+			expected string
+			isErr    bool
+		}{
+			{name: "environments", kind: CompleteEnvironments, prefix: "d", expected: "default\ndev\n"},
+			{name: "components", kind: CompleteComponents, expected: "guestbook\nnested.redis\n"},
+			{name: "registries", kind: CompleteRegistries, prefix: "in", expected: "incubator\n"},
+			{name: "packages", kind: CompletePackages, prefix: "incubator/", expected: "incubator/mysql\nincubator/redis\n"},
+			{name: "unknown kind", kind: "bogus", isErr: true},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				in := map[string]interface{}{
+					OptionApp:   appMock,
+					OptionName:  tc.kind,
+					OptionQuery: tc.prefix,
+				}
+
+				var out bytes.Buffer
+				c, err := newComplete(in, func(c *Complete) {
+					c.out = &out
+					c.componentsFn = func(a app.App, module string) ([]component.Component, error) {
+						return []component.Component{c1, c2}, nil
+					}
+					c.remotePackagesFn = func() ([]pkg.Package, error) {
+						return []pkg.Package{remote}, nil
+					}
+				})
+				require.NoError(t, err)
+
+				err = c.run()
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+
+				assert.Equal(t, tc.expected, out.String())
+			})
+		}
+	})
+}
+
+func TestComplete_remote_packages_unavailable(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("Libraries").Return(app.LibraryConfigs{
+			"redis": &app.LibraryConfig{Name: "redis", Registry: "incubator"},
+		}, nil)
+
+		in := map[string]interface{}{
+			OptionApp:  appMock,
+			OptionName: CompletePackages,
+		}
+
+		var out bytes.Buffer
+		c, err := newComplete(in, func(c *Complete) {
+			c.out = &out
+			c.remotePackagesFn = func() ([]pkg.Package, error) {
+				return nil, errors.New("offline")
+			}
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, c.run())
+		assert.Equal(t, "incubator/redis\n", out.String())
+	})
+}
+
+func TestComplete_without_app(t *testing.T) {
+	var out bytes.Buffer
+	in := map[string]interface{}{
+		OptionName: CompleteEnvironments,
+		OptionOut:  &out,
+	}
+
+	c, err := newComplete(in)
+	require.NoError(t, err)
+
+	require.NoError(t, c.run())
+	assert.Empty(t, out.String())
+}
@@ -28,7 +28,17 @@ import (
 
 // RunComponentList runs `component list`
 func RunComponentList(m map[string]interface{}) error {
-	cl, err := NewComponentList(m)
+	var o ComponentListOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunComponentListWithOptions(o)
+}
+
+// RunComponentListWithOptions runs `component list` with typed options.
+func RunComponentListWithOptions(o ComponentListOptions) error {
+	cl, err := NewComponentListWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -45,21 +55,41 @@ type ComponentList struct {
 	out    io.Writer
 }
 
-// NewComponentList creates an instance of ComponentList.
+// ComponentListOptions are the options for ComponentList.
+type ComponentListOptions struct {
+	App    app.App   `option:"app"`
+	Module string    `option:"module"`
+	Output string    `option:"output"`
+	Out    io.Writer `option:"out,optional"`
+}
+
+// NewComponentList creates an instance of ComponentList from an option map.
 func NewComponentList(m map[string]interface{}) (*ComponentList, error) {
-	ol := newOptionLoader(m)
+	var o ComponentListOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return NewComponentListWithOptions(o)
+}
+
+// NewComponentListWithOptions creates an instance of ComponentList.
+func NewComponentListWithOptions(o ComponentListOptions) (*ComponentList, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
 
 	cl := &ComponentList{
-		app:    ol.LoadApp(),
-		module: ol.LoadString(OptionModule),
-		output: ol.LoadString(OptionOutput),
+		app:    o.App,
+		module: o.Module,
+		output: o.Output,
 
 		cm:  component.DefaultManager,
 		out: os.Stdout,
 	}
 
-	if ol.err != nil {
-		return nil, ol.err
+	if o.Out != nil {
+		cl.out = o.Out
 	}
 
 	return cl, nil
@@ -20,9 +20,19 @@ import (
 	"github.com/ksonnet/ksonnet/pkg/component"
 )
 
-// RunComponentRm runs `component list`
+// RunComponentRm runs `component rm`
 func RunComponentRm(m map[string]interface{}) error {
-	cr, err := NewComponentRm(m)
+	var o ComponentRmOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunComponentRmWithOptions(o)
+}
+
+// RunComponentRmWithOptions runs `component rm` with typed options.
+func RunComponentRmWithOptions(o ComponentRmOptions) error {
+	cr, err := NewComponentRmWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -38,19 +48,33 @@ type ComponentRm struct {
 	componentDeleteFn func(app.App, string) error
 }
 
-// NewComponentRm creates an instance of ComponentRm.
+// ComponentRmOptions are the options for ComponentRm.
+type ComponentRmOptions struct {
+	App           app.App `option:"app"`
+	ComponentName string  `option:"component-name"`
+}
+
+// NewComponentRm creates an instance of ComponentRm from an option map.
 func NewComponentRm(m map[string]interface{}) (*ComponentRm, error) {
-	ol := newOptionLoader(m)
+	var o ComponentRmOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
 
-	cr := &ComponentRm{
-		app:  ol.LoadApp(),
-		name: ol.LoadString(OptionComponentName),
+	return NewComponentRmWithOptions(o)
+}
 
-		componentDeleteFn: component.Delete,
+// NewComponentRmWithOptions creates an instance of ComponentRm.
+func NewComponentRmWithOptions(o ComponentRmOptions) (*ComponentRm, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
 	}
 
-	if ol.err != nil {
-		return nil, ol.err
+	cr := &ComponentRm{
+		app:  o.App,
+		name: o.ComponentName,
+
+		componentDeleteFn: component.Delete,
 	}
 
 	return cr, nil
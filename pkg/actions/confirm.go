@@ -0,0 +1,119 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// confirmer asks the user to confirm destructive actions. Actions are
+// confirmed without asking if the user assumed yes. Otherwise, they can only be
+// confirmed on a terminal, so scripts must assume yes.
+type confirmer struct {
+	assumeYes bool
+
+	in           io.Reader
+	out          io.Writer
+	isTerminalFn func() bool
+}
+
+func newConfirmer(assumeYes bool) *confirmer {
+	return &confirmer{
+		assumeYes: assumeYes,
+		in:        os.Stdin,
+		out:       os.Stderr,
+		isTerminalFn: func() bool {
+			return terminal.IsTerminal(int(os.Stdin.Fd()))
+		},
+	}
+}
+
+// confirm asks the user to answer yes to an action, e.g. "Remove environment
+// \"dev\"".
+func (c *confirmer) confirm(action string) error {
+	answer, err := c.ask(action, fmt.Sprintf("%s? [y/N]: ", action))
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(answer) {
+	case "y", "yes":
+		return nil
+	default:
+		return errors.Errorf("%s: not confirmed", action)
+	}
+}
+
+// confirmName asks the user to type the name of a protected resource to
+// confirm an action on it.
+func (c *confirmer) confirmName(action, name string) error {
+	answer, err := c.ask(action, fmt.Sprintf("%s? %q is protected; type its name to confirm: ", action, name))
+	if err != nil {
+		return err
+	}
+
+	if answer != name {
+		return errors.Errorf("%s: not confirmed; %q does not match %q", action, answer, name)
+	}
+
+	return nil
+}
+
+// confirmEnv confirms an action on an environment. Protected environments are
+// confirmed by name.
+func (c *confirmer) confirmEnv(a app.App, envName, action string) error {
+	if c.assumeYes {
+		return nil
+	}
+
+	env, err := a.Environment(envName)
+	if err != nil {
+		return err
+	}
+
+	if env.Protected {
+		return c.confirmName(action, envName)
+	}
+
+	return c.confirm(action)
+}
+
+func (c *confirmer) ask(action, prompt string) (string, error) {
+	if c.assumeYes {
+		return "yes", nil
+	}
+
+	if !c.isTerminalFn() {
+		return "", errors.Errorf("%s: confirmation is required; run with --yes to confirm without a prompt", action)
+	}
+
+	fmt.Fprint(c.out, prompt)
+
+	answer, err := bufio.NewReader(c.in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+
+	return strings.TrimSpace(answer), nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfirmer_confirm(t *testing.T) {
+	cases := []struct {
+		name       string
+		assumeYes  bool
+		isTerminal bool
+		input      string
+		prompt     string
+		isErr      bool
+	}{
+		{name: "assume yes", assumeYes: true},
+		{name: "yes", isTerminal: true, input: "y\n", prompt: "Remove it? [y/N]: "},
+		{name: "no", isTerminal: true, input: "n\n", prompt: "Remove it? [y/N]: ", isErr: true},
+		{name: "no answer", isTerminal: true, prompt: "Remove it? [y/N]: ", isErr: true},
+		{name: "not a terminal", input: "y\n", isErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			c := &confirmer{
+				assumeYes:    tc.assumeYes,
+				in:           strings.NewReader(tc.input),
+				out:          &out,
+				isTerminalFn: func() bool { return tc.isTerminal },
+			}
+
+			err := c.confirm("Remove it")
+			if tc.isErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, tc.prompt, out.String())
+		})
+	}
+}
+
+func TestConfirmer_confirmEnv(t *testing.T) {
+	cases := []struct {
+		name      string
+		protected bool
+		input     string
+		isErr     bool
+	}{
+		{name: "unprotected", input: "yes\n"},
+		{name: "protected", protected: true, input: "prod\n"},
+		{name: "protected without its name", protected: true, input: "yes\n", isErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				appMock.On("Environment", "prod").Return(&app.EnvironmentConfig{Protected: tc.protected}, nil)
+
+				var out bytes.Buffer
+				c := &confirmer{
+					in:           strings.NewReader(tc.input),
+					out:          &out,
+					isTerminalFn: func() bool { return true },
+				}
+
+				err := c.confirmEnv(appMock, "prod", "Remove environment")
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+			})
+		})
+	}
+}
+
+func TestEnvRm_not_confirmed(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("Environment", "prod").Return(&app.EnvironmentConfig{}, nil)
+
+		in := map[string]interface{}{
+			OptionApp:      appMock,
+			OptionEnvName:  "prod",
+			OptionOverride: false,
+		}
+
+		a, err := NewEnvRm(in)
+		require.NoError(t, err)
+
+		a.confirmer.isTerminalFn = func() bool { return false }
+		a.envDeleteFn = func(a app.App, name string, override bool) error {
+			t.Fatal("environment was removed without confirmation")
+			return nil
+		}
+
+		err = a.Run()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--yes")
+	})
+}
@@ -25,8 +25,9 @@ type currentEnver interface {
 	setCurrentEnv(name string)
 }
 
-func setCurrentEnv(em environmentMetadata, ce currentEnver, ol *optionLoader) error {
-	envName := ol.LoadOptionalString(OptionEnvName)
+// setCurrentEnv sets the environment an action runs against. If no
+// environment name is given, the app's current environment is used.
+func setCurrentEnv(em environmentMetadata, ce currentEnver, envName string) error {
 	if envName == "" {
 		envName = em.CurrentEnvironment()
 	}
@@ -16,6 +16,10 @@
 package actions
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/client"
 	"github.com/ksonnet/ksonnet/pkg/cluster"
@@ -25,7 +29,17 @@ type runDeleteFn func(cluster.DeleteConfig, ...cluster.DeleteOpts) error
 
 // RunDelete runs `delete`.
 func RunDelete(m map[string]interface{}) error {
-	a, err := newDelete(m)
+	var o DeleteOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunDeleteWithOptions(o)
+}
+
+// RunDeleteWithOptions runs `delete` with typed options.
+func RunDeleteWithOptions(o DeleteOptions) error {
+	a, err := newDeleteWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -37,37 +51,69 @@ type deleteOpt func(*Delete)
 
 // Delete collects options for applying objects to a cluster.
 type Delete struct {
-	app            app.App
-	clientConfig   *client.Config
-	componentNames []string
-	envName        string
-	gracePeriod    int64
-
+	app             app.App
+	clientConfig    *client.Config
+	componentNames  []string
+	envName         string
+	gracePeriod     int64
+	maxUnavailable  int
+	pruneNamespaces bool
+	wait            bool
+	waitTimeout     time.Duration
+	confirmer       *confirmer
+
+	fanOutFn    fanOutFn
 	runDeleteFn runDeleteFn
 }
 
-// RunDelete runs `apply`
+// DeleteOptions are the options for Delete.
+type DeleteOptions struct {
+	App             app.App        `option:"app"`
+	ClientConfig    *client.Config `option:"client-config"`
+	ComponentNames  []string       `option:"component-names"`
+	EnvName         string         `option:"env-name,optional"`
+	GracePeriod     int64          `option:"grace-period"`
+	MaxUnavailable  int            `option:"max-unavailable-clusters"`
+	PruneNamespaces bool           `option:"prune-namespaces"`
+	Wait            bool           `option:"wait"`
+	WaitTimeout     time.Duration  `option:"wait-timeout"`
+	AssumeYes       bool           `option:"assume-yes,optional"`
+}
+
 func newDelete(m map[string]interface{}, opts ...deleteOpt) (*Delete, error) {
-	ol := newOptionLoader(m)
+	var o DeleteOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
 
-	d := &Delete{
-		app:            ol.LoadApp(),
-		clientConfig:   ol.LoadClientConfig(),
-		componentNames: ol.LoadStringSlice(OptionComponentNames),
-		gracePeriod:    ol.LoadInt64(OptionGracePeriod),
+	return newDeleteWithOptions(o, opts...)
+}
 
-		runDeleteFn: cluster.RunDelete,
+func newDeleteWithOptions(o DeleteOptions, opts ...deleteOpt) (*Delete, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
 	}
 
-	if ol.err != nil {
-		return nil, ol.err
+	d := &Delete{
+		app:             o.App,
+		clientConfig:    o.ClientConfig,
+		componentNames:  o.ComponentNames,
+		gracePeriod:     o.GracePeriod,
+		maxUnavailable:  o.MaxUnavailable,
+		pruneNamespaces: o.PruneNamespaces,
+		wait:            o.Wait,
+		waitTimeout:     o.WaitTimeout,
+		confirmer:       newConfirmer(o.AssumeYes),
+
+		fanOutFn:    cluster.FanOut,
+		runDeleteFn: cluster.RunDelete,
 	}
 
 	for _, opt := range opts {
 		opt(d)
 	}
 
-	if err := setCurrentEnv(d.app, d, ol); err != nil {
+	if err := setCurrentEnv(d.app, d, o.EnvName); err != nil {
 		return nil, err
 	}
 
@@ -75,15 +121,47 @@ func newDelete(m map[string]interface{}, opts ...deleteOpt) (*Delete, error) {
 }
 
 func (d *Delete) run() error {
+	if err := d.confirmer.confirmEnv(d.app, d.envName, d.action()); err != nil {
+		return err
+	}
+
 	config := cluster.DeleteConfig{
+		App:             d.app,
+		ClientConfig:    d.clientConfig,
+		ComponentNames:  d.componentNames,
+		EnvName:         d.envName,
+		GracePeriod:     d.gracePeriod,
+		PruneNamespaces: d.pruneNamespaces,
+		Wait:            d.wait,
+		WaitTimeout:     d.waitTimeout,
+	}
+
+	fanOutConfig := cluster.FanOutConfig{
 		App:            d.app,
 		ClientConfig:   d.clientConfig,
-		ComponentNames: d.componentNames,
 		EnvName:        d.envName,
-		GracePeriod:    d.gracePeriod,
+		MaxUnavailable: d.maxUnavailable,
+	}
+
+	return d.fanOutFn(fanOutConfig, func(_ string, clientConfig *client.Config) error {
+		config.ClientConfig = clientConfig
+		return d.runDeleteFn(config)
+	})
+}
+
+// action describes what is deleted, for confirmation.
+func (d *Delete) action() string {
+	what := "all components"
+	if len(d.componentNames) > 0 {
+		what = "components " + strings.Join(d.componentNames, ", ")
+	}
+
+	action := fmt.Sprintf("Delete the objects of %s from environment %q", what, d.envName)
+	if d.pruneNamespaces {
+		action += ", and their empty namespaces"
 	}
 
-	return d.runDeleteFn(config)
+	return action
 }
 
 func (d *Delete) setCurrentEnv(name string) {
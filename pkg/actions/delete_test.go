@@ -17,7 +17,9 @@ package actions
 
 import (
 	"testing"
+	"time"
 
+	"github.com/ksonnet/ksonnet/pkg/app"
 	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
 	"github.com/ksonnet/ksonnet/pkg/client"
 	"github.com/ksonnet/ksonnet/pkg/cluster"
@@ -50,21 +52,32 @@ func TestDelete(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			withApp(t, func(appMock *amocks.App) {
 				appMock.On("CurrentEnvironment").Return(tc.currentName)
+				appMock.On("Environment", "default").Return(&app.EnvironmentConfig{
+					Destination: &app.EnvironmentDestinationSpec{Server: "http://example.com", Namespace: "default"},
+				}, nil)
 
 				in := map[string]interface{}{
-					OptionApp:            appMock,
-					OptionClientConfig:   &client.Config{},
-					OptionComponentNames: []string{},
-					OptionEnvName:        tc.envName,
-					OptionGracePeriod:    int64(3),
+					OptionApp:                    appMock,
+					OptionAssumeYes:              true,
+					OptionClientConfig:           &client.Config{},
+					OptionComponentNames:         []string{},
+					OptionEnvName:                tc.envName,
+					OptionGracePeriod:            int64(3),
+					OptionMaxUnavailableClusters: 1,
+					OptionPruneNamespaces:        true,
+					OptionWait:                   true,
+					OptionWaitTimeout:            time.Minute,
 				}
 
 				expected := cluster.DeleteConfig{
-					App:            appMock,
-					ClientConfig:   &client.Config{},
-					ComponentNames: []string{},
-					EnvName:        "default",
-					GracePeriod:    3,
+					App:             appMock,
+					ClientConfig:    &client.Config{},
+					ComponentNames:  []string{},
+					EnvName:         "default",
+					GracePeriod:     3,
+					PruneNamespaces: true,
+					Wait:            true,
+					WaitTimeout:     time.Minute,
 				}
 
 				runDeleteOpt := func(a *Delete) {
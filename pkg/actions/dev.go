@@ -0,0 +1,242 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/cluster"
+	"github.com/ksonnet/ksonnet/pkg/dev"
+	"github.com/ksonnet/ksonnet/pkg/diff"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// devWatcher sends batches of changed files.
+type devWatcher interface {
+	Run(stopCh <-chan struct{}, changes chan<- []string) error
+}
+
+// RunDev runs `dev`.
+func RunDev(m map[string]interface{}) error {
+	var o DevOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunDevWithOptions(o)
+}
+
+// RunDevWithOptions runs `dev` with typed options.
+func RunDevWithOptions(o DevOptions) error {
+	d, err := newDevWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return d.run()
+}
+
+type devOpt func(*Dev)
+
+// Dev re-renders, diffs, and applies an environment each time the files of
+// an app change.
+type Dev struct {
+	app          app.App
+	clientConfig *client.Config
+	envName      string
+	debounce     time.Duration
+	stopCh       <-chan struct{}
+
+	reportFn     func(diff.Config, *diff.Location, *diff.Location) (*diff.Report, error)
+	runApplyFn   runApplyFn
+	newWatcherFn func(root string, debounce time.Duration) (devWatcher, error)
+	handleFn     func(dev.Cycle) error
+}
+
+// DevOptions are the options for Dev.
+type DevOptions struct {
+	App          app.App        `option:"app"`
+	ClientConfig *client.Config `option:"client-config"`
+	EnvName      string         `option:"env-name,optional"`
+	// Debounce defaults to dev.DefaultDebounce.
+	Debounce time.Duration `option:"debounce,optional"`
+}
+
+// Defaults defaults the debounce to dev.DefaultDebounce.
+func (o *DevOptions) Defaults() {
+	if o.Debounce == 0 {
+		o.Debounce = dev.DefaultDebounce
+	}
+}
+
+func newDev(m map[string]interface{}, opts ...devOpt) (*Dev, error) {
+	var o DevOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return newDevWithOptions(o, opts...)
+}
+
+func newDevWithOptions(o DevOptions, opts ...devOpt) (*Dev, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	if err := prepareOptions(&o); err != nil {
+		return nil, err
+	}
+
+	d := &Dev{
+		app:          o.App,
+		clientConfig: o.ClientConfig,
+		debounce:     o.Debounce,
+
+		reportFn:   diff.DefaultReport,
+		runApplyFn: cluster.RunApply,
+		newWatcherFn: func(root string, debounce time.Duration) (devWatcher, error) {
+			return dev.NewWatcher(root, debounce)
+		},
+		handleFn: logDevCycle,
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	if err := setCurrentEnv(d.app, d, o.EnvName); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func (d *Dev) setCurrentEnv(name string) {
+	d.envName = name
+}
+
+func (d *Dev) run() error {
+	env, err := d.app.Environment(d.envName)
+	if err != nil {
+		return err
+	}
+
+	if len(env.Destinations) > 0 {
+		return errors.Errorf("environment %q has multiple clusters; dev only supports environments with a single cluster", d.envName)
+	}
+
+	// Watch before the first cycle, so edits made while it runs are not missed.
+	w, err := d.newWatcherFn(d.app.Root(), d.debounce)
+	if err != nil {
+		return err
+	}
+
+	stopCh := d.stopCh
+	if stopCh == nil {
+		stopCh = interruptCh()
+	}
+
+	changes := make(chan []string)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- w.Run(stopCh, changes)
+		close(changes)
+	}()
+
+	loop := dev.NewLoop(d.app.Fs(), d.app.Root(), d.report, d.apply)
+
+	log.Infof("watching %s for changes to deploy to %s", d.app.Root(), d.envName)
+	if err = loop.Run(stopCh, changes, d.handleFn); err != nil {
+		return err
+	}
+
+	return <-errCh
+}
+
+// report compares the rendered components with the environment's cluster.
+func (d *Dev) report(components []string) (*diff.Report, error) {
+	config := diff.Config{
+		App:                d.app,
+		ClientConfig:       d.clientConfig,
+		Components:         components,
+		IgnoreServerFields: true,
+	}
+
+	local := diff.NewLocation(fmt.Sprintf("local:%s", d.envName))
+	remote := diff.NewLocation(fmt.Sprintf("remote:%s", d.envName))
+
+	return d.reportFn(config, local, remote)
+}
+
+// apply applies the components to the environment's cluster. Objects are
+// never garbage collected, since a cycle may only render some components.
+func (d *Dev) apply(components []string) error {
+	return d.runApplyFn(cluster.ApplyConfig{
+		App:            d.app,
+		ClientConfig:   d.clientConfig,
+		ComponentNames: components,
+		Create:         true,
+		EnvName:        d.envName,
+		SkipGc:         true,
+	})
+}
+
+// logDevCycle logs a summary of a dev cycle.
+func logDevCycle(c dev.Cycle) error {
+	components := "all"
+	if len(c.Components) > 0 {
+		components = strings.Join(c.Components, ",")
+	}
+
+	fields := log.Fields{
+		"cycle":      c.Number,
+		"components": components,
+		"duration":   c.Duration.Round(time.Millisecond),
+	}
+	if len(c.Files) > 0 {
+		fields["files"] = strings.Join(c.Files, ",")
+	}
+
+	if c.Err != nil {
+		log.WithFields(fields).WithField("error", c.Err.Error()).Error("unable to deploy changes")
+		return nil
+	}
+
+	counts := make(map[string]int)
+	for _, oc := range c.Report.Objects {
+		counts[oc.Type]++
+	}
+
+	if removed := counts[diff.ChangeRemoved]; removed > 0 {
+		log.WithFields(fields).Warnf("%d object(s) are no longer rendered; dev does not delete them", removed)
+	}
+
+	if !c.Applied {
+		log.WithFields(fields).Info("cluster is up to date")
+		return nil
+	}
+
+	fields[diff.ChangeAdded] = counts[diff.ChangeAdded]
+	fields[diff.ChangeChanged] = counts[diff.ChangeChanged]
+	log.WithFields(fields).Info("applied changes")
+
+	return nil
+}
@@ -0,0 +1,140 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/cluster"
+	"github.com/ksonnet/ksonnet/pkg/dev"
+	"github.com/ksonnet/ksonnet/pkg/diff"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDevWatcher sends batches of changed files, and then stops.
+type fakeDevWatcher struct {
+	batches [][]string
+}
+
+func (w *fakeDevWatcher) Run(stopCh <-chan struct{}, changes chan<- []string) error {
+	for _, batch := range w.batches {
+		changes <- batch
+	}
+	return nil
+}
+
+func TestDev(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("Environment", "dev").Return(&app.EnvironmentConfig{
+			Destination: &app.EnvironmentDestinationSpec{Server: "http://example.com", Namespace: "default"},
+		}, nil)
+
+		require.NoError(t, afero.WriteFile(appMock.Fs(), "/components/guestbook.jsonnet", []byte("{}"), 0644))
+
+		in := map[string]interface{}{
+			OptionApp:          appMock,
+			OptionClientConfig: &client.Config{},
+			OptionEnvName:      "dev",
+			OptionDebounce:     time.Second,
+		}
+
+		var locations []string
+		var applied []cluster.ApplyConfig
+		var cycles []dev.Cycle
+
+		d, err := newDev(in, func(d *Dev) {
+			d.stopCh = make(chan struct{})
+			d.reportFn = func(config diff.Config, l1, l2 *diff.Location) (*diff.Report, error) {
+				locations = append(locations, l1.String(), l2.String())
+				assert.True(t, config.IgnoreServerFields)
+				return &diff.Report{Objects: []diff.ObjectChange{{Type: diff.ChangeAdded, Kind: "Service", Name: "guestbook"}}}, nil
+			}
+			d.runApplyFn = func(config cluster.ApplyConfig, opts ...cluster.ApplyOpts) error {
+				applied = append(applied, config)
+				return nil
+			}
+			d.newWatcherFn = func(root string, debounce time.Duration) (devWatcher, error) {
+				assert.Equal(t, "/", root)
+				assert.Equal(t, time.Second, debounce)
+				return &fakeDevWatcher{batches: [][]string{{"components/guestbook.jsonnet"}}}, nil
+			}
+			d.handleFn = func(c dev.Cycle) error {
+				cycles = append(cycles, c)
+				return nil
+			}
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, d.run())
+
+		assert.Equal(t, []string{"local:dev", "remote:dev", "local:dev", "remote:dev"}, locations)
+
+		require.Len(t, applied, 2)
+		assert.Nil(t, applied[0].ComponentNames)
+		assert.Equal(t, []string{"guestbook"}, applied[1].ComponentNames)
+		for _, config := range applied {
+			assert.Equal(t, "dev", config.EnvName)
+			assert.True(t, config.Create)
+			assert.True(t, config.SkipGc)
+		}
+
+		require.Len(t, cycles, 2)
+		assert.Equal(t, []string{"components/guestbook.jsonnet"}, cycles[1].Files)
+	})
+}
+
+func TestDev_multiple_destinations(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("Environment", "prod").Return(&app.EnvironmentConfig{
+			Destinations: []*app.EnvironmentDestinationSpec{
+				{Name: "east", Context: "east"},
+				{Name: "west", Context: "west"},
+			},
+		}, nil)
+
+		in := map[string]interface{}{
+			OptionApp:          appMock,
+			OptionClientConfig: &client.Config{},
+			OptionEnvName:      "prod",
+		}
+
+		d, err := newDev(in)
+		require.NoError(t, err)
+		assert.Equal(t, dev.DefaultDebounce, d.debounce)
+
+		require.Error(t, d.run())
+	})
+}
+
+func TestDev_requires_env(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("CurrentEnvironment").Return("")
+
+		in := map[string]interface{}{
+			OptionApp:          appMock,
+			OptionClientConfig: &client.Config{},
+		}
+
+		_, err := newDev(in)
+		require.Error(t, err)
+	})
+}
@@ -18,34 +18,83 @@ package actions
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/cluster"
 	"github.com/ksonnet/ksonnet/pkg/diff"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// DiffExitCodeClean is the exit code for diff when no differences are found.
+	DiffExitCodeClean = 0
+	// DiffExitCodeDifferences is the exit code for diff when differences are found.
+	DiffExitCodeDifferences = 1
+	// DiffExitCodeError is the exit code for diff when the differences could not be
+	// determined.
+	DiffExitCodeError = 2
 )
 
 var (
 	// ErrDiffFound is an error returned when differences are found.
-	ErrDiffFound = errors.New("differences found")
+	ErrDiffFound = &ExitError{Code: DiffExitCodeDifferences, Err: errors.New("differences found")}
 
 	diffAddColor    = color.New(color.FgGreen)
 	diffRemoveColor = color.New(color.FgRed)
 )
 
-// RunDiff runs `diff`
+// ExitError is an error which sets the exit code of ks.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+// Cause returns the underlying error.
+func (e *ExitError) Cause() error {
+	return e.Err
+}
+
+// RunDiff runs `diff`. Errors other than ErrDiffFound exit with DiffExitCodeError,
+// so CI pipelines can tell differences apart from failures.
 func RunDiff(m map[string]interface{}) error {
-	d, err := NewDiff(m)
+	var o DiffOptions
+	if err := loadOptions(m, &o); err != nil {
+		return &ExitError{Code: DiffExitCodeError, Err: err}
+	}
+
+	return RunDiffWithOptions(o)
+}
+
+// RunDiffWithOptions runs `diff` with typed options.
+func RunDiffWithOptions(o DiffOptions) error {
+	d, err := NewDiffWithOptions(o)
 	if err != nil {
-		return err
+		return &ExitError{Code: DiffExitCodeError, Err: err}
+	}
+
+	if err = d.Run(); err != nil && err != ErrDiffFound {
+		return &ExitError{Code: DiffExitCodeError, Err: err}
 	}
 
-	return d.Run()
+	return err
 }
 
 // Diff sets targets for an environment.
@@ -55,30 +104,114 @@ type Diff struct {
 	src1         string
 	src2         string
 	components   []string
+	strategy     string
+	output       string
+	program      string
+	fromStdin    bool
+	showSecrets  bool
+	objects      []*unstructured.Unstructured
+
+	maxUnavailable int
+
+	watch         bool
+	watchInterval time.Duration
+	metricsAddr   string
+	stopCh        <-chan struct{}
 
-	diffFn func(app.App, *client.Config, []string, *diff.Location, *diff.Location) (io.Reader, error)
+	diffFn     func(diff.Config, *diff.Location, *diff.Location) (io.Reader, error)
+	reportFn   func(diff.Config, *diff.Location, *diff.Location) (*diff.Report, error)
+	externalFn func(diff.Config, string, *diff.Location, *diff.Location, io.Writer, io.Writer) (bool, error)
+	fanOutFn   fanOutFn
 
+	in  io.Reader
 	out io.Writer
+	err io.Writer
+}
+
+// DiffOptions are the options for Diff.
+type DiffOptions struct {
+	App            app.App        `option:"app"`
+	ClientConfig   *client.Config `option:"client-config"`
+	Src1           string         `option:"src-1"`
+	Src2           string         `option:"src-2,optional"`
+	ComponentNames []string       `option:"component-names"`
+	Strategy       string         `option:"diff-strategy,optional"`
+	Output         string         `option:"output,optional"`
+	// Program defaults to the program named by the diff.EnvDiffProgram
+	// environment variable.
+	Program        string `option:"diff-program,optional"`
+	FromStdin      bool   `option:"from-stdin,optional"`
+	ShowSecrets    bool   `option:"show-secrets,optional"`
+	MaxUnavailable int    `option:"max-unavailable-clusters,optional"`
+	Watch          bool   `option:"watch,optional"`
+	// WatchInterval defaults to diff.DefaultWatchInterval.
+	WatchInterval time.Duration `option:"watch-interval,optional"`
+	MetricsAddr   string        `option:"metrics-addr,optional"`
+	Out           io.Writer     `option:"out,optional"`
+}
+
+// Defaults defaults the diff program to $KS_DIFF, and the watch
+// interval to diff.DefaultWatchInterval.
+func (o *DiffOptions) Defaults() {
+	if o.Program == "" {
+		o.Program = os.Getenv(diff.EnvDiffProgram)
+	}
+
+	if o.WatchInterval == 0 {
+		o.WatchInterval = diff.DefaultWatchInterval
+	}
 }
 
-// NewDiff creates an instance of Diff.
+// NewDiff creates an instance of Diff from an option map.
 func NewDiff(m map[string]interface{}) (*Diff, error) {
-	ol := newOptionLoader(m)
+	var o DiffOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return NewDiffWithOptions(o)
+}
+
+// NewDiffWithOptions creates an instance of Diff.
+func NewDiffWithOptions(o DiffOptions) (*Diff, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	if err := prepareOptions(&o); err != nil {
+		return nil, err
+	}
 
 	d := &Diff{
-		app:          ol.LoadApp(),
-		clientConfig: ol.LoadClientConfig(),
-		src1:         ol.LoadString(OptionSrc1),
-		src2:         ol.LoadOptionalString(OptionSrc2),
-		components:   ol.LoadStringSlice(OptionComponentNames),
+		app:          o.App,
+		clientConfig: o.ClientConfig,
+		src1:         o.Src1,
+		src2:         o.Src2,
+		components:   o.ComponentNames,
+		strategy:     o.Strategy,
+		output:       o.Output,
+		program:      o.Program,
+		fromStdin:    o.FromStdin,
+		showSecrets:  o.ShowSecrets,
+
+		maxUnavailable: o.MaxUnavailable,
+
+		watch:         o.Watch,
+		watchInterval: o.WatchInterval,
+		metricsAddr:   o.MetricsAddr,
 
-		diffFn: diff.DefaultDiff,
+		diffFn:     diff.DefaultDiff,
+		reportFn:   diff.DefaultReport,
+		externalFn: diff.DefaultExternal,
+		fanOutFn:   cluster.FanOut,
 
+		in:  os.Stdin,
 		out: os.Stdout,
+		err: os.Stderr,
 	}
 
-	if ol.err != nil {
-		return nil, ol.err
+	if o.Out != nil {
+		d.out = o.Out
 	}
 
 	return d, nil
@@ -86,6 +219,14 @@ func NewDiff(m map[string]interface{}) (*Diff, error) {
 
 // Run assigns targets to an environment.
 func (d *Diff) Run() error {
+	if d.fromStdin {
+		objects, err := readStdinObjects(d.in, d.components)
+		if err != nil {
+			return err
+		}
+		d.objects = objects
+	}
+
 	location1 := diff.NewLocation(d.src1)
 
 	if d.src2 == "" {
@@ -93,7 +234,107 @@ func (d *Diff) Run() error {
 	}
 	location2 := diff.NewLocation(d.src2)
 
-	r, err := d.diffFn(d.app, d.clientConfig, d.components, location1, location2)
+	if d.watch {
+		return d.runWatch(location1, location2)
+	}
+
+	envName, err := d.fanOutEnv(location1, location2)
+	if err != nil {
+		return err
+	}
+	if envName != "" {
+		return d.runFanOut(envName, location1, location2)
+	}
+
+	return d.run(location1, location2)
+}
+
+// fanOutEnv returns the name of the remote environment to compare against each
+// of its clusters, or an empty string if the environment has a single cluster.
+// Environments can only be fanned out if all remote locations refer to them.
+func (d *Diff) fanOutEnv(locations ...*diff.Location) (string, error) {
+	envName := ""
+	for _, l := range locations {
+		if l.Destination() != "remote" {
+			continue
+		}
+		if envName != "" && envName != l.EnvName() {
+			return "", nil
+		}
+		envName = l.EnvName()
+	}
+
+	if envName == "" {
+		return "", nil
+	}
+
+	env, err := d.app.Environment(envName)
+	if err != nil {
+		return "", err
+	}
+
+	if len(env.Destinations) == 0 {
+		return "", nil
+	}
+
+	return envName, nil
+}
+
+// runFanOut compares the locations once for each cluster of a multi-cluster
+// environment. Differences are found if any cluster differs.
+func (d *Diff) runFanOut(envName string, location1, location2 *diff.Location) error {
+	config := cluster.FanOutConfig{
+		App:            d.app,
+		ClientConfig:   d.clientConfig,
+		EnvName:        envName,
+		MaxUnavailable: d.maxUnavailable,
+		Out:            d.err,
+	}
+
+	found := false
+	err := d.fanOutFn(config, func(destination string, clientConfig *client.Config) error {
+		if d.output != OutputJSON {
+			fmt.Fprintf(d.out, "# cluster: %s\n", destination)
+		}
+
+		cd := *d
+		cd.clientConfig = clientConfig
+
+		err := cd.run(location1, location2)
+		if err == ErrDiffFound {
+			found = true
+			return nil
+		}
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if found {
+		return ErrDiffFound
+	}
+
+	return nil
+}
+
+func (d *Diff) run(location1, location2 *diff.Location) error {
+	switch d.output {
+	default:
+		return errors.Errorf("invalid output format %q; valid formats are text, semantic, and json", d.output)
+	case "", OutputText:
+	case OutputJSON:
+		return d.runReport(location1, location2)
+	case OutputSemantic:
+		return d.runSemantic(location1, location2)
+	}
+
+	if d.program != "" {
+		return d.runExternal(location1, location2)
+	}
+
+	r, err := d.diffFn(d.config(), location1, location2)
 	if err != nil {
 		return err
 	}
@@ -131,3 +372,226 @@ func (d *Diff) Run() error {
 
 	return nil
 }
+
+func (d *Diff) config() diff.Config {
+	return diff.Config{
+		App:          d.app,
+		ClientConfig: d.clientConfig,
+		Components:   d.components,
+		Strategy:     d.strategy,
+		Objects:      d.objects,
+		ShowSecrets:  d.showSecrets,
+	}
+}
+
+func (d *Diff) runExternal(location1, location2 *diff.Location) error {
+	found, err := d.externalFn(d.config(), d.program, location1, location2, d.out, d.err)
+	if err != nil {
+		return err
+	}
+
+	if found {
+		return ErrDiffFound
+	}
+
+	return nil
+}
+
+// runSemantic prints the differences field by field, ignoring field ordering and
+// metadata managed by the server.
+func (d *Diff) runSemantic(location1, location2 *diff.Location) error {
+	config := d.config()
+	config.IgnoreServerFields = true
+
+	report, err := d.reportFn(config, location1, location2)
+	if err != nil {
+		return err
+	}
+
+	for _, oc := range report.Objects {
+		name := oc.Name
+		if oc.Namespace != "" {
+			name = oc.Namespace + "/" + oc.Name
+		}
+
+		if err = printSemanticLine(d.out, oc.Type, "", fmt.Sprintf("%s %s", oc.Kind, name)); err != nil {
+			return err
+		}
+
+		for _, fc := range oc.Fields {
+			var text string
+			switch fc.Type {
+			case diff.ChangeAdded:
+				text = fmt.Sprintf("%s: %s", fc.Path, semanticValue(fc.To))
+			case diff.ChangeRemoved:
+				text = fmt.Sprintf("%s: %s", fc.Path, semanticValue(fc.From))
+			default:
+				text = fmt.Sprintf("%s: %s -> %s", fc.Path, semanticValue(fc.From), semanticValue(fc.To))
+			}
+
+			if err = printSemanticLine(d.out, fc.Type, "    ", text); err != nil {
+				return err
+			}
+		}
+	}
+
+	if report.HasChanges() {
+		return ErrDiffFound
+	}
+
+	return nil
+}
+
+func printSemanticLine(w io.Writer, changeType, indent, text string) error {
+	var err error
+	switch changeType {
+	case diff.ChangeAdded:
+		_, err = diffAddColor.Fprintf(w, "%s+ %s\n", indent, text)
+	case diff.ChangeRemoved:
+		_, err = diffRemoveColor.Fprintf(w, "%s- %s\n", indent, text)
+	default:
+		_, err = fmt.Fprintf(w, "%s~ %s\n", indent, text)
+	}
+
+	return err
+}
+
+// semanticValue formats a field value on a single line.
+func semanticValue(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+
+	return string(b)
+}
+
+func (d *Diff) runReport(location1, location2 *diff.Location) error {
+	report, err := d.reportFn(d.config(), location1, location2)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(d.out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+
+	if report.HasChanges() {
+		return ErrDiffFound
+	}
+
+	return nil
+}
+
+// runWatch re-evaluates the differences between two locations once per watch
+// interval until interrupted, and reports each evaluation as a drift event.
+func (d *Diff) runWatch(location1, location2 *diff.Location) error {
+	var emit func(diff.DriftEvent) error
+	switch d.output {
+	default:
+		return errors.Errorf("invalid output format %q for watch; valid formats are text and json", d.output)
+	case "", OutputText:
+		emit = logDriftEvent
+	case OutputJSON:
+		enc := json.NewEncoder(d.out)
+		emit = func(event diff.DriftEvent) error {
+			return enc.Encode(event)
+		}
+	}
+
+	config := d.config()
+	config.IgnoreServerFields = true
+
+	w, err := diff.NewWatcher(d.watchInterval, func() (*diff.Report, error) {
+		return d.reportFn(config, location1, location2)
+	})
+	if err != nil {
+		return err
+	}
+
+	handle := emit
+	if d.metricsAddr != "" {
+		metrics := diff.NewDriftMetrics()
+		closeFn, err := serveMetrics(d.metricsAddr, metrics)
+		if err != nil {
+			return err
+		}
+		defer closeFn()
+
+		handle = func(event diff.DriftEvent) error {
+			metrics.Observe(location1.String(), location2.String(), event)
+			return emit(event)
+		}
+	}
+
+	stopCh := d.stopCh
+	if stopCh == nil {
+		stopCh = interruptCh()
+	}
+
+	log.Infof("watching %s and %s for drift every %s", location1, location2, d.watchInterval)
+	return w.Run(stopCh, handle)
+}
+
+func logDriftEvent(event diff.DriftEvent) error {
+	if event.Error != "" {
+		log.WithField("error", event.Error).Error("unable to evaluate drift")
+		return nil
+	}
+
+	if !event.Drifted {
+		log.Info("no drift detected")
+		return nil
+	}
+
+	for _, oc := range event.Report.Objects {
+		log.WithFields(log.Fields{
+			"kind":      oc.Kind,
+			"namespace": oc.Namespace,
+			"name":      oc.Name,
+			"change":    oc.Type,
+			"fields":    len(oc.Fields),
+		}).Warn("drift detected")
+	}
+
+	return nil
+}
+
+// serveMetrics serves metrics at /metrics on addr in the background. It
+// returns a function which stops the server.
+func serveMetrics(addr string, metrics *diff.DriftMetrics) (func() error, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listening on %s", addr)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics)
+	srv := &http.Server{Handler: mux}
+
+	go func() {
+		if err := srv.Serve(l); err != nil && err != http.ErrServerClosed {
+			log.WithError(err).Error("serving metrics")
+		}
+	}()
+
+	log.Infof("serving drift metrics at http://%s/metrics", l.Addr())
+	return srv.Close, nil
+}
+
+// interruptCh returns a channel which is closed on SIGINT or SIGTERM.
+func interruptCh() <-chan struct{} {
+	stopCh := make(chan struct{})
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-sigCh
+		signal.Stop(sigCh)
+		close(stopCh)
+	}()
+
+	return stopCh
+}
@@ -17,14 +17,17 @@ package actions
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ksonnet/ksonnet/pkg/app"
 	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
 	"github.com/ksonnet/ksonnet/pkg/client"
 	"github.com/ksonnet/ksonnet/pkg/diff"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -36,6 +39,7 @@ func TestDiff(t *testing.T) {
 		src2       string
 		eLocation1 string
 		eLocation2 string
+		strategy   string
 		diffText   string
 		isNewError bool
 		isRunError bool
@@ -53,6 +57,13 @@ func TestDiff(t *testing.T) {
 			eLocation1: "local:default",
 			eLocation2: "remote:default",
 		},
+		{
+			name:       "server strategy",
+			src1:       "default",
+			eLocation1: "local:default",
+			eLocation2: "remote:default",
+			strategy:   diff.StrategyServer,
+		},
 		{
 			name:       "diff detected",
 			src1:       "local:default",
@@ -67,12 +78,14 @@ func TestDiff(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			withApp(t, func(appMock *amocks.App) {
+				appMock.On("Environment", "default").Return(&app.EnvironmentConfig{}, nil)
 				in := map[string]interface{}{
 					OptionApp:            appMock,
 					OptionClientConfig:   &client.Config{},
 					OptionComponentNames: []string{},
 					OptionSrc1:           tc.src1,
 					OptionSrc2:           tc.src2,
+					OptionDiffStrategy:   tc.strategy,
 				}
 
 				d, err := NewDiff(in)
@@ -86,7 +99,8 @@ func TestDiff(t *testing.T) {
 				var buf bytes.Buffer
 				d.out = &buf
 
-				d.diffFn = func(a app.App, c *client.Config, components []string, l1 *diff.Location, l2 *diff.Location) (io.Reader, error) {
+				d.diffFn = func(config diff.Config, l1 *diff.Location, l2 *diff.Location) (io.Reader, error) {
+					assert.Equal(t, tc.strategy, config.Strategy, "strategy")
 					assert.Equal(t, tc.eLocation1, l1.String(), "location1")
 					assert.Equal(t, tc.eLocation2, l2.String(), "location2")
 
@@ -107,8 +121,314 @@ func TestDiff(t *testing.T) {
 	}
 }
 
+func TestDiff_from_stdin(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("Environment", "default").Return(&app.EnvironmentConfig{}, nil)
+		in := map[string]interface{}{
+			OptionApp:            appMock,
+			OptionClientConfig:   &client.Config{},
+			OptionComponentNames: []string{},
+			OptionSrc1:           "default",
+			OptionFromStdin:      true,
+		}
+
+		d, err := NewDiff(in)
+		require.NoError(t, err)
+
+		d.in = strings.NewReader("---\napiVersion: v1\nkind: Service\nmetadata:\n  name: guestbook-ui\n")
+
+		d.diffFn = func(config diff.Config, l1 *diff.Location, l2 *diff.Location) (io.Reader, error) {
+			require.Len(t, config.Objects, 1)
+			assert.Equal(t, "guestbook-ui", config.Objects[0].GetName())
+			return strings.NewReader(""), nil
+		}
+
+		err = d.Run()
+		require.NoError(t, err)
+	})
+}
+
 func TestDiff_requires_app(t *testing.T) {
 	in := make(map[string]interface{})
 	_, err := NewDiff(in)
 	require.Error(t, err)
 }
+
+func TestDiff_json(t *testing.T) {
+	cases := []struct {
+		name     string
+		report   *diff.Report
+		expected string
+		isErr    bool
+	}{
+		{
+			name:     "no differences",
+			report:   &diff.Report{From: "remote:default", To: "local:default", Objects: []diff.ObjectChange{}},
+			expected: "{\n  \"from\": \"remote:default\",\n  \"to\": \"local:default\",\n  \"objects\": []\n}\n",
+		},
+		{
+			name: "differences",
+			report: &diff.Report{
+				From: "remote:default",
+				To:   "local:default",
+				Objects: []diff.ObjectChange{
+					{APIVersion: "v1", Kind: "Service", Name: "svc", Type: diff.ChangeAdded},
+				},
+			},
+			isErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				appMock.On("Environment", "default").Return(&app.EnvironmentConfig{}, nil)
+				in := map[string]interface{}{
+					OptionApp:            appMock,
+					OptionClientConfig:   &client.Config{},
+					OptionComponentNames: []string{},
+					OptionSrc1:           "default",
+					OptionOutput:         OutputJSON,
+				}
+
+				d, err := NewDiff(in)
+				require.NoError(t, err)
+
+				var buf bytes.Buffer
+				d.out = &buf
+
+				d.reportFn = func(config diff.Config, l1 *diff.Location, l2 *diff.Location) (*diff.Report, error) {
+					return tc.report, nil
+				}
+
+				err = d.Run()
+				if tc.isErr {
+					require.Equal(t, ErrDiffFound, err)
+					return
+				}
+				require.NoError(t, err)
+
+				assert.Equal(t, tc.expected, buf.String())
+			})
+		})
+	}
+}
+
+func TestDiff_semantic(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("Environment", "default").Return(&app.EnvironmentConfig{}, nil)
+		in := map[string]interface{}{
+			OptionApp:            appMock,
+			OptionClientConfig:   &client.Config{},
+			OptionComponentNames: []string{},
+			OptionSrc1:           "default",
+			OptionOutput:         OutputSemantic,
+		}
+
+		d, err := NewDiff(in)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		d.out = &buf
+
+		d.reportFn = func(config diff.Config, l1 *diff.Location, l2 *diff.Location) (*diff.Report, error) {
+			assert.True(t, config.IgnoreServerFields)
+
+			return &diff.Report{
+				Objects: []diff.ObjectChange{
+					{
+						Kind:      "Deployment",
+						Namespace: "default",
+						Name:      "app",
+						Type:      diff.ChangeChanged,
+						Fields: []diff.FieldChange{
+							{Path: "spec.replicas", Type: diff.ChangeChanged, From: 1, To: 2},
+							{Path: "metadata.labels", Type: diff.ChangeAdded, To: map[string]interface{}{"app": "app"}},
+						},
+					},
+					{Kind: "Service", Name: "svc", Type: diff.ChangeRemoved},
+				},
+			}, nil
+		}
+
+		err = d.Run()
+		require.Equal(t, ErrDiffFound, err)
+
+		expected := "~ Deployment default/app\n" +
+			"    ~ spec.replicas: 1 -> 2\n" +
+			"    + metadata.labels: {\"app\":\"app\"}\n" +
+			"- Service svc\n"
+		assert.Equal(t, expected, buf.String())
+	})
+}
+
+func TestDiff_external(t *testing.T) {
+	cases := []struct {
+		name  string
+		found bool
+		err   error
+	}{
+		{name: "no differences"},
+		{name: "differences", found: true},
+		{name: "program failed", err: errors.New("fail")},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				appMock.On("Environment", "default").Return(&app.EnvironmentConfig{}, nil)
+				in := map[string]interface{}{
+					OptionApp:            appMock,
+					OptionClientConfig:   &client.Config{},
+					OptionComponentNames: []string{},
+					OptionSrc1:           "default",
+					OptionDiffProgram:    "dyff between",
+				}
+
+				d, err := NewDiff(in)
+				require.NoError(t, err)
+
+				d.externalFn = func(config diff.Config, program string, l1, l2 *diff.Location, stdout, stderr io.Writer) (bool, error) {
+					assert.Equal(t, "dyff between", program)
+					assert.Equal(t, "local:default", l1.String())
+					assert.Equal(t, "remote:default", l2.String())
+					return tc.found, tc.err
+				}
+
+				err = d.Run()
+				switch {
+				case tc.err != nil:
+					require.Error(t, err)
+					assert.NotEqual(t, ErrDiffFound, err)
+				case tc.found:
+					require.Equal(t, ErrDiffFound, err)
+				default:
+					require.NoError(t, err)
+				}
+			})
+		})
+	}
+}
+
+func TestDiff_multiple_destinations(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("Environment", "prod").Return(&app.EnvironmentConfig{
+			Destinations: []*app.EnvironmentDestinationSpec{
+				{Name: "east", Context: "east"},
+				{Name: "west", Context: "west"},
+			},
+		}, nil)
+
+		in := map[string]interface{}{
+			OptionApp:                    appMock,
+			OptionClientConfig:           &client.Config{},
+			OptionComponentNames:         []string{},
+			OptionSrc1:                   "prod",
+			OptionMaxUnavailableClusters: 0,
+		}
+
+		d, err := NewDiff(in)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		d.out = &buf
+		d.err = &bytes.Buffer{}
+
+		d.diffFn = func(config diff.Config, l1 *diff.Location, l2 *diff.Location) (io.Reader, error) {
+			if config.ClientConfig.Overrides.CurrentContext == "west" {
+				return strings.NewReader("+foo\n"), nil
+			}
+			return strings.NewReader(""), nil
+		}
+
+		err = d.Run()
+		require.Equal(t, ErrDiffFound, err)
+
+		assert.Contains(t, buf.String(), "# cluster: east\n# cluster: west\n")
+		assert.Contains(t, buf.String(), "+foo")
+	})
+}
+
+func TestRunDiff_exit_codes(t *testing.T) {
+	err := RunDiff(map[string]interface{}{})
+	require.Error(t, err)
+
+	exitErr, ok := err.(*ExitError)
+	require.True(t, ok)
+	assert.Equal(t, DiffExitCodeError, exitErr.Code)
+	assert.Equal(t, DiffExitCodeDifferences, ErrDiffFound.Code)
+}
+
+func TestDiff_watch(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionApp:            appMock,
+			OptionClientConfig:   &client.Config{},
+			OptionComponentNames: []string{},
+			OptionSrc1:           "default",
+			OptionOutput:         OutputJSON,
+			OptionWatch:          true,
+			OptionWatchInterval:  time.Millisecond,
+		}
+
+		d, err := NewDiff(in)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		d.out = &buf
+
+		stopCh := make(chan struct{})
+		d.stopCh = stopCh
+
+		calls := 0
+		d.reportFn = func(config diff.Config, l1 *diff.Location, l2 *diff.Location) (*diff.Report, error) {
+			assert.True(t, config.IgnoreServerFields)
+
+			calls++
+			if calls == 2 {
+				close(stopCh)
+				return &diff.Report{
+					From:    l1.String(),
+					To:      l2.String(),
+					Objects: []diff.ObjectChange{{APIVersion: "v1", Kind: "Service", Name: "svc", Type: diff.ChangeAdded}},
+				}, nil
+			}
+			return &diff.Report{From: l1.String(), To: l2.String(), Objects: []diff.ObjectChange{}}, nil
+		}
+
+		err = d.Run()
+		require.NoError(t, err)
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 2)
+
+		var events []diff.DriftEvent
+		for _, line := range lines {
+			var event diff.DriftEvent
+			require.NoError(t, json.Unmarshal([]byte(line), &event))
+			events = append(events, event)
+		}
+
+		assert.False(t, events[0].Drifted)
+		assert.True(t, events[1].Drifted)
+		assert.Equal(t, "svc", events[1].Report.Objects[0].Name)
+	})
+}
+
+func TestDiff_watch_invalid_output(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionApp:            appMock,
+			OptionClientConfig:   &client.Config{},
+			OptionComponentNames: []string{},
+			OptionSrc1:           "default",
+			OptionOutput:         OutputSemantic,
+			OptionWatch:          true,
+		}
+
+		d, err := NewDiff(in)
+		require.NoError(t, err)
+
+		require.Error(t, d.Run())
+	})
+}
@@ -0,0 +1,382 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/blang/semver"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/env"
+	"github.com/ksonnet/ksonnet/pkg/pkg"
+	"github.com/ksonnet/ksonnet/pkg/registry"
+	"github.com/ksonnet/ksonnet/pkg/util/table"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/version"
+)
+
+const (
+	// doctorOK is the status of a check which passed.
+	doctorOK = "ok"
+	// doctorWarn is the status of a check which found a problem that does
+	// not stop the app from working.
+	doctorWarn = "warn"
+	// doctorFail is the status of a check which failed.
+	doctorFail = "fail"
+)
+
+// ksonnetLibFiles are the files of an environment's ksonnet-lib.
+var ksonnetLibFiles = []string{"k.libsonnet", "k8s.libsonnet"}
+
+type serverVersionFn func(a app.App, clientConfig *client.Config, envName string) (*version.Info, error)
+
+// RunDoctor runs `doctor`.
+func RunDoctor(m map[string]interface{}) error {
+	var o DoctorOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunDoctorWithOptions(o)
+}
+
+// RunDoctorWithOptions runs `doctor` with typed options.
+func RunDoctorWithOptions(o DoctorOptions) error {
+	d, err := newDoctorWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return d.run()
+}
+
+type doctorOpt func(*Doctor)
+
+// Doctor checks the health of an app, and suggests fixes for the problems it
+// finds.
+type Doctor struct {
+	app          app.App
+	envName      string
+	clientConfig *client.Config
+	output       string
+
+	out             io.Writer
+	registriesFn    func(a app.App) ([]registry.Registry, error)
+	packageManager  registry.PackageManager
+	serverVersionFn serverVersionFn
+}
+
+// DoctorOptions are the options for Doctor.
+type DoctorOptions struct {
+	App app.App `option:"app"`
+	// EnvName limits the checks of environments to a single environment.
+	EnvName       string         `option:"env-name,optional"`
+	ClientConfig  *client.Config `option:"client-config"`
+	Output        string         `option:"output,optional"`
+	TLSSkipVerify bool           `option:"tls-skip-verify,optional"`
+}
+
+func newDoctor(m map[string]interface{}, opts ...doctorOpt) (*Doctor, error) {
+	var o DoctorOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return newDoctorWithOptions(o, opts...)
+}
+
+func newDoctorWithOptions(o DoctorOptions, opts ...doctorOpt) (*Doctor, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	httpClient := newHTTPClient(o.TLSSkipVerify)
+
+	d := &Doctor{
+		app:          o.App,
+		envName:      o.EnvName,
+		clientConfig: o.ClientConfig,
+		output:       o.Output,
+
+		out: os.Stdout,
+		registriesFn: func(a app.App) ([]registry.Registry, error) {
+			return registry.List(a, httpClient)
+		},
+		packageManager:  registry.NewPackageManager(o.App, registry.HTTPClientOpt(httpClient)),
+		serverVersionFn: loadServerVersion,
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d, nil
+}
+
+// doctorReport collects the results of the checks.
+type doctorReport struct {
+	rows   [][]string
+	failed int
+}
+
+func (r *doctorReport) add(check, subject, status, message, fix string) {
+	if status == doctorFail {
+		r.failed++
+	}
+
+	r.rows = append(r.rows, []string{check, subject, status, message, fix})
+}
+
+func (d *Doctor) run() error {
+	f, err := table.DetectFormat(d.output)
+	if err != nil {
+		return errors.Wrap(err, "detecting output format")
+	}
+
+	r := &doctorReport{}
+
+	// The other checks read app.yaml, so they are skipped if it is invalid.
+	if d.checkApp(r) {
+		d.checkRegistries(r)
+		d.checkPackages(r)
+		if err = d.checkEnvironments(r); err != nil {
+			return err
+		}
+	}
+
+	t := table.New("doctor", d.out)
+	t.SetHeader([]string{"check", "subject", "status", "message", "fix"})
+	t.SetFormat(f)
+	t.AppendBulk(r.rows)
+
+	if err = t.Render(); err != nil {
+		return err
+	}
+
+	if r.failed > 0 {
+		return errors.Errorf("%d of %d checks failed", r.failed, len(r.rows))
+	}
+
+	return nil
+}
+
+// checkApp checks that app.yaml and app.override.yaml can be read, and that
+// the app doesn't need an upgrade. It returns false if app.yaml is invalid.
+func (d *Doctor) checkApp(r *doctorReport) bool {
+	if _, err := d.app.Environments(); err != nil {
+		r.add("app", "app.yaml", doctorFail, err.Error(), "Fix the errors in app.yaml and app.override.yaml")
+		return false
+	}
+
+	needsUpgrade, err := d.app.CheckUpgrade()
+	switch {
+	case err != nil:
+		r.add("app", "app.yaml", doctorFail, err.Error(), "Fix the errors in app.yaml and app.override.yaml")
+		return false
+	case needsUpgrade:
+		r.add("app", "app.yaml", doctorWarn, "app uses an old version of app.yaml", "Run `ks upgrade`")
+	default:
+		r.add("app", "app.yaml", doctorOK, "app.yaml is valid", "")
+	}
+
+	return true
+}
+
+// checkRegistries checks that each registry can be reached.
+func (d *Doctor) checkRegistries(r *doctorReport) {
+	registries, err := d.registriesFn(d.app)
+	if err != nil {
+		r.add("registry", "", doctorFail, err.Error(), "Fix the registries in app.yaml")
+		return
+	}
+
+	sort.Slice(registries, func(i, j int) bool {
+		return registries[i].Name() < registries[j].Name()
+	})
+
+	for _, reg := range registries {
+		if _, err := reg.Status(); err != nil {
+			r.add("registry", reg.Name(), doctorFail, fmt.Sprintf("%s is unreachable: %v", reg.URI(), err),
+				fmt.Sprintf("Check the network, or change the URI with `ks registry set %s --uri <uri>`", reg.Name()))
+			continue
+		}
+
+		r.add("registry", reg.Name(), doctorOK, fmt.Sprintf("%s is reachable", reg.URI()), "")
+	}
+}
+
+// checkPackages checks that each package in app.yaml is vendored.
+func (d *Doctor) checkPackages(r *doctorReport) {
+	packages, err := d.packageManager.Packages()
+	if err != nil {
+		r.add("package", "", doctorFail, err.Error(), "Fix the libraries in app.yaml")
+		return
+	}
+
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].String() < packages[j].String()
+	})
+
+	for _, p := range packages {
+		desc := pkg.Descriptor{Registry: p.RegistryName(), Name: p.Name(), Version: p.Version()}
+
+		path, err := d.packageManager.VendorPath(desc)
+		if err != nil {
+			r.add("package", desc.String(), doctorFail, err.Error(), "Fix the library in app.yaml")
+			continue
+		}
+
+		ok, err := afero.DirExists(d.app.Fs(), path)
+		if err != nil || !ok {
+			r.add("package", desc.String(), doctorFail, "package is not in the vendor directory",
+				fmt.Sprintf("Run `ks pkg install --force %s`", desc))
+			continue
+		}
+
+		r.add("package", desc.String(), doctorOK, "package is vendored", "")
+	}
+}
+
+// checkEnvironments checks the cluster, ksonnet-lib and jsonnet paths of each
+// environment, or only the selected one.
+func (d *Doctor) checkEnvironments(r *doctorReport) error {
+	envs, err := d.app.Environments()
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for name := range envs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if d.envName != "" {
+		if _, ok := envs[d.envName]; !ok {
+			return errors.Errorf("environment %q does not exist", d.envName)
+		}
+		names = []string{d.envName}
+	}
+
+	for _, name := range names {
+		serverVersion := d.checkCluster(r, name)
+		d.checkKsonnetLib(r, envs[name], serverVersion)
+		d.checkJsonnetPaths(r, name)
+	}
+
+	return nil
+}
+
+// checkCluster checks that the environment's cluster can be reached, and
+// returns its version.
+func (d *Doctor) checkCluster(r *doctorReport, envName string) *version.Info {
+	info, err := d.serverVersionFn(d.app, d.clientConfig, envName)
+	if err != nil {
+		r.add("cluster", envName, doctorFail, fmt.Sprintf("cluster is unreachable: %v", err),
+			fmt.Sprintf("Check the environment's server with `ks env describe %s` and your kubeconfig", envName))
+		return nil
+	}
+
+	r.add("cluster", envName, doctorOK, fmt.Sprintf("cluster runs Kubernetes %s", info.GitVersion), "")
+	return info
+}
+
+// checkKsonnetLib checks that the environment's ksonnet-lib was generated,
+// and that it is for the same minor version of Kubernetes as the cluster.
+func (d *Doctor) checkKsonnetLib(r *doctorReport, e *app.EnvironmentConfig, serverVersion *version.Info) {
+	libPath, err := d.app.LibPath(e.Name)
+	if err != nil {
+		r.add("ksonnet-lib", e.Name, doctorFail, err.Error(), fmt.Sprintf("Run `ks env update-lib %s`", e.Name))
+		return
+	}
+
+	for _, name := range ksonnetLibFiles {
+		ok, err := afero.Exists(d.app.Fs(), filepath.Join(libPath, name))
+		if err != nil || !ok {
+			r.add("ksonnet-lib", e.Name, doctorFail, fmt.Sprintf("%s is missing from %s", name, libPath),
+				fmt.Sprintf("Run `ks env update-lib %s`", e.Name))
+			return
+		}
+	}
+
+	if serverVersion != nil {
+		libVersion, err := semver.ParseTolerant(e.KubernetesVersion)
+		clusterVersion, clusterErr := semver.ParseTolerant(serverVersion.GitVersion)
+		if err == nil && clusterErr == nil &&
+			(libVersion.Major != clusterVersion.Major || libVersion.Minor != clusterVersion.Minor) {
+			r.add("ksonnet-lib", e.Name, doctorWarn,
+				fmt.Sprintf("ksonnet-lib is for Kubernetes %s, but the cluster runs %s", e.KubernetesVersion, serverVersion.GitVersion),
+				fmt.Sprintf("Run `ks env update-lib %s --api-spec=version:%s`", e.Name, serverVersion.GitVersion))
+			return
+		}
+	}
+
+	r.add("ksonnet-lib", e.Name, doctorOK, fmt.Sprintf("ksonnet-lib is for Kubernetes %s", e.KubernetesVersion), "")
+}
+
+// checkJsonnetPaths checks that the directories jsonnet imports files from
+// exist. The vendor and lib directories are optional, and ksonnet-lib is
+// checked by checkKsonnetLib.
+func (d *Doctor) checkJsonnetPaths(r *doctorReport, envName string) {
+	jPaths, err := env.JPaths(d.app, envName, "")
+	if err != nil {
+		r.add("jsonnet-paths", envName, doctorFail, err.Error(), "Fix the environment in app.yaml")
+		return
+	}
+
+	optional := map[string]bool{
+		filepath.Join(d.app.Root(), "vendor"):       true,
+		filepath.Join(d.app.Root(), app.LibDirName): true,
+	}
+	if libPath, err := d.app.LibPath(envName); err == nil {
+		optional[libPath] = true
+	}
+
+	var missing bool
+	for _, path := range jPaths {
+		if optional[path] {
+			continue
+		}
+
+		ok, err := afero.DirExists(d.app.Fs(), path)
+		if err != nil || !ok {
+			r.add("jsonnet-paths", envName, doctorFail, fmt.Sprintf("%s does not exist", path),
+				"Create the directory, or fix the environment's path in app.yaml")
+			missing = true
+		}
+	}
+
+	if missing {
+		return
+	}
+
+	r.add("jsonnet-paths", envName, doctorOK, fmt.Sprintf("%d jsonnet paths exist", len(jPaths)), "")
+}
+
+func loadServerVersion(a app.App, clientConfig *client.Config, envName string) (*version.Info, error) {
+	disc, err := loadDiscovery(a, clientConfig, envName)
+	if err != nil {
+		return nil, err
+	}
+
+	return disc.ServerVersion()
+}
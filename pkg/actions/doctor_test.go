@@ -0,0 +1,182 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/pkg"
+	pkgmocks "github.com/ksonnet/ksonnet/pkg/pkg/mocks"
+	"github.com/ksonnet/ksonnet/pkg/registry"
+	rmocks "github.com/ksonnet/ksonnet/pkg/registry/mocks"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/version"
+)
+
+func mockDoctorRegistry(name, uri string, statusErr error) *rmocks.Registry {
+	r := &rmocks.Registry{}
+	r.On("Name").Return(name)
+	r.On("URI").Return(uri)
+	if statusErr != nil {
+		r.On("Status").Return(nil, statusErr)
+	} else {
+		r.On("Status").Return(&registry.Status{}, nil)
+	}
+
+	return r
+}
+
+func mockDoctorPackage(registryName, name, version string) *pkgmocks.Package {
+	p := &pkgmocks.Package{}
+	p.On("RegistryName").Return(registryName)
+	p.On("Name").Return(name)
+	p.On("Version").Return(version)
+	p.On("String").Return(registryName + "/" + name + "@" + version)
+
+	return p
+}
+
+func withDoctorApp(t *testing.T, fn func(*amocks.App, afero.Fs)) {
+	withApp(t, func(appMock *amocks.App) {
+		fs := appMock.Fs()
+
+		envs := app.EnvironmentConfigs{
+			"default": &app.EnvironmentConfig{Name: "default", Path: "default", KubernetesVersion: "v1.10.0"},
+			"prod":    &app.EnvironmentConfig{Name: "prod", Path: "prod", KubernetesVersion: "v1.8.0"},
+		}
+		appMock.On("Environments").Return(envs, nil)
+		for name, e := range envs {
+			appMock.On("Environment", name).Return(e, nil)
+			appMock.On("LibPath", name).Return("/lib/ksonnet-lib/"+e.KubernetesVersion, nil)
+		}
+
+		for _, dir := range []string{"/environments/default", "/components", "/vendor/incubator/redis"} {
+			require.NoError(t, fs.MkdirAll(dir, 0755))
+		}
+		for _, name := range []string{"k.libsonnet", "k8s.libsonnet"} {
+			require.NoError(t, afero.WriteFile(fs, "/lib/ksonnet-lib/v1.10.0/"+name, []byte("{}"), 0644))
+		}
+
+		fn(appMock, fs)
+	})
+}
+
+func TestDoctor(t *testing.T) {
+	cases := []struct {
+		name     string
+		output   string
+		envName  string
+		expected string
+	}{
+		{
+			name:     "table",
+			expected: "doctor/output.txt",
+		},
+		{
+			name:     "json",
+			output:   "json",
+			expected: "doctor/output.json",
+		},
+		{
+			name:     "single environment",
+			envName:  "default",
+			expected: "doctor/env.txt",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withDoctorApp(t, func(appMock *amocks.App, fs afero.Fs) {
+				appMock.On("CheckUpgrade").Return(false, nil)
+
+				in := map[string]interface{}{
+					OptionApp:          appMock,
+					OptionEnvName:      tc.envName,
+					OptionClientConfig: &client.Config{},
+					OptionOutput:       tc.output,
+				}
+
+				d, err := newDoctor(in)
+				require.NoError(t, err)
+
+				var buf bytes.Buffer
+				d.out = &buf
+
+				d.registriesFn = func(a app.App) ([]registry.Registry, error) {
+					return []registry.Registry{
+						mockDoctorRegistry("incubator", "github.com/ksonnet/parts/tree/master/incubator", nil),
+						mockDoctorRegistry("helm-stable", "https://charts.example.com", errors.New("connection refused")),
+					}, nil
+				}
+
+				pm := &rmocks.PackageManager{}
+				pm.On("Packages").Return([]pkg.Package{
+					mockDoctorPackage("incubator", "redis", "1.0.0"),
+					mockDoctorPackage("incubator", "mysql", "2.0.0"),
+				}, nil)
+				pm.On("VendorPath", pkg.Descriptor{Registry: "incubator", Name: "redis", Version: "1.0.0"}).Return("/vendor/incubator/redis", nil)
+				pm.On("VendorPath", pkg.Descriptor{Registry: "incubator", Name: "mysql", Version: "2.0.0"}).Return("/vendor/incubator/mysql", nil)
+				d.packageManager = pm
+
+				d.serverVersionFn = func(a app.App, clientConfig *client.Config, envName string) (*version.Info, error) {
+					if envName == "prod" {
+						return nil, errors.New("no route to host")
+					}
+					return &version.Info{GitVersion: "v1.11.2"}, nil
+				}
+
+				err = d.run()
+				require.Error(t, err)
+
+				assertOutput(t, tc.expected, buf.String())
+			})
+		})
+	}
+}
+
+func TestDoctor_invalid_app(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("Environments").Return(nil, errors.New("invalid app.yaml"))
+
+		in := map[string]interface{}{
+			OptionApp:          appMock,
+			OptionClientConfig: &client.Config{},
+		}
+
+		d, err := newDoctor(in)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		d.out = &buf
+
+		err = d.run()
+		require.EqualError(t, err, "1 of 1 checks failed")
+
+		assertOutput(t, "doctor/invalid.txt", buf.String())
+	})
+}
+
+func TestDoctor_requires_app(t *testing.T) {
+	in := make(map[string]interface{})
+	_, err := newDoctor(in)
+	require.Error(t, err)
+}
@@ -0,0 +1,88 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/dryrun"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+type loadAppFn func(fs afero.Fs, httpClient *http.Client, root string) (app.App, error)
+
+// WithDryRun wraps an action which edits the app. If the dry-run option is
+// set, the action runs against an in-memory overlay of the app's files, and
+// the edits it would make are printed as a unified diff instead of being
+// written.
+func WithDryRun(fn func(map[string]interface{}) error) func(map[string]interface{}) error {
+	return func(m map[string]interface{}) error {
+		return runDryRun(m, fn, loadApp)
+	}
+}
+
+func loadApp(fs afero.Fs, httpClient *http.Client, root string) (app.App, error) {
+	return app.Load(fs, httpClient, root, true)
+}
+
+func runDryRun(m map[string]interface{}, fn func(map[string]interface{}) error, loadAppFn loadAppFn) error {
+	ol := newOptionLoader(m)
+
+	if !ol.LoadOptionalBool(OptionDryRun) {
+		return fn(m)
+	}
+
+	ksApp := ol.LoadApp()
+	var out io.Writer = os.Stdout
+	if w := ol.LoadOptionalWriter(OptionOut); w != nil {
+		out = w
+	}
+
+	if ol.err != nil {
+		return ol.err
+	}
+
+	overlay := dryrun.NewFs(ksApp.Fs())
+	overlayApp, err := loadAppFn(overlay, ksApp.HTTPClient(), ksApp.Root())
+	if err != nil {
+		return err
+	}
+
+	options := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		options[k] = v
+	}
+	options[OptionApp] = overlayApp
+
+	if err := fn(options); err != nil {
+		return err
+	}
+
+	n, err := overlay.Diff(out, ksApp.Root())
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		log.Info("dry run: no files would change")
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDryRun(t *testing.T) {
+	cases := []struct {
+		name     string
+		dryRun   bool
+		expected string
+	}{
+		{
+			name:     "writes files",
+			expected: "name: updated\n",
+		},
+		{
+			name:     "dry run",
+			dryRun:   true,
+			expected: "name: app\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				fs := appMock.Fs()
+				require.NoError(t, afero.WriteFile(fs, "/app.yaml", []byte("name: app\n"), app.DefaultFilePermissions))
+
+				appMock.On("HTTPClient").Return(&http.Client{})
+
+				fn := func(m map[string]interface{}) error {
+					ol := newOptionLoader(m)
+					a := ol.LoadApp()
+					return afero.WriteFile(a.Fs(), "/app.yaml", []byte("name: updated\n"), app.DefaultFilePermissions)
+				}
+
+				loadAppFn := func(overlay afero.Fs, _ *http.Client, root string) (app.App, error) {
+					assert.Equal(t, "/", root)
+
+					overlayApp := &amocks.App{}
+					overlayApp.On("Fs").Return(overlay)
+					return overlayApp, nil
+				}
+
+				var buf bytes.Buffer
+				in := map[string]interface{}{
+					OptionApp:    appMock,
+					OptionDryRun: tc.dryRun,
+					OptionOut:    &buf,
+				}
+
+				err := runDryRun(in, fn, loadAppFn)
+				require.NoError(t, err)
+
+				data, err := afero.ReadFile(fs, "/app.yaml")
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, string(data))
+
+				if !tc.dryRun {
+					assert.Empty(t, buf.String())
+					return
+				}
+
+				assert.Contains(t, buf.String(), "--- a/app.yaml\n+++ b/app.yaml\n")
+				assert.Contains(t, buf.String(), "-name: app\n+name: updated\n")
+			})
+		})
+	}
+}
@@ -22,7 +22,17 @@ import (
 
 // RunEnvAdd runs `env add`
 func RunEnvAdd(m map[string]interface{}) error {
-	ea, err := NewEnvAdd(m)
+	var o EnvAddOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunEnvAddWithOptions(o)
+}
+
+// RunEnvAddWithOptions runs `env add` with typed options.
+func RunEnvAddWithOptions(o EnvAddOptions) error {
+	ea, err := NewEnvAddWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -42,23 +52,41 @@ type EnvAdd struct {
 	envCreateFn func(a app.App, d env.Destination, name, k8sSpecFlag string, overrideData, paramsData []byte, isOverride bool) error
 }
 
-// NewEnvAdd creates an instance of EnvAdd.
+// EnvAddOptions are the options for EnvAdd.
+type EnvAddOptions struct {
+	App       app.App `option:"app"`
+	EnvName   string  `option:"env-name"`
+	Server    string  `option:"server"`
+	Namespace string  `option:"module"`
+	SpecFlag  string  `option:"spec-flag"`
+	Override  bool    `option:"override"`
+}
+
+// NewEnvAdd creates an instance of EnvAdd from an option map.
 func NewEnvAdd(m map[string]interface{}) (*EnvAdd, error) {
-	ol := newOptionLoader(m)
+	var o EnvAddOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
 
-	ea := &EnvAdd{
-		app:         ol.LoadApp(),
-		envName:     ol.LoadString(OptionEnvName),
-		server:      ol.LoadString(OptionServer),
-		namespace:   ol.LoadString(OptionModule),
-		k8sSpecFlag: ol.LoadString(OptionSpecFlag),
-		isOverride:  ol.LoadBool(OptionOverride),
+	return NewEnvAddWithOptions(o)
+}
 
-		envCreateFn: env.Create,
+// NewEnvAddWithOptions creates an instance of EnvAdd.
+func NewEnvAddWithOptions(o EnvAddOptions) (*EnvAdd, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
 	}
 
-	if ol.err != nil {
-		return nil, ol.err
+	ea := &EnvAdd{
+		app:         o.App,
+		envName:     o.EnvName,
+		server:      o.Server,
+		namespace:   o.Namespace,
+		k8sSpecFlag: o.SpecFlag,
+		isOverride:  o.Override,
+
+		envCreateFn: env.Create,
 	}
 
 	return ea, nil
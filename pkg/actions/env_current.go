@@ -26,7 +26,17 @@ import (
 
 // RunEnvCurrent runs `env current`.
 func RunEnvCurrent(m map[string]interface{}) error {
-	a, err := newEnvCurrent(m)
+	var o EnvCurrentOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunEnvCurrentWithOptions(o)
+}
+
+// RunEnvCurrentWithOptions runs `env current` with typed options.
+func RunEnvCurrentWithOptions(o EnvCurrentOptions) error {
+	a, err := newEnvCurrentWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -43,20 +53,33 @@ type EnvCurrent struct {
 	out io.Writer
 }
 
-// RunEnvCurrent runs `env current`
+// EnvCurrentOptions are the options for EnvCurrent.
+type EnvCurrentOptions struct {
+	App     app.App `option:"app"`
+	EnvName string  `option:"env-name,optional"`
+	Unset   bool    `option:"unset"`
+}
+
 func newEnvCurrent(m map[string]interface{}) (*EnvCurrent, error) {
-	ol := newOptionLoader(m)
+	var o EnvCurrentOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
 
-	d := &EnvCurrent{
-		app:     ol.LoadApp(),
-		envName: ol.LoadOptionalString(OptionEnvName),
-		unset:   ol.LoadBool(OptionUnset),
+	return newEnvCurrentWithOptions(o)
+}
 
-		out: os.Stdout,
+func newEnvCurrentWithOptions(o EnvCurrentOptions) (*EnvCurrent, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
 	}
 
-	if ol.err != nil {
-		return nil, ol.err
+	d := &EnvCurrent{
+		app:     o.App,
+		envName: o.EnvName,
+		unset:   o.Unset,
+
+		out: os.Stdout,
 	}
 
 	return d, nil
@@ -20,13 +20,24 @@ import (
 	"os"
 
 	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/util/table"
+	"github.com/pkg/errors"
 	yaml "gopkg.in/yaml.v2"
 )
 
 // RunEnvDescribe runs `env describe`
-// func RunEnvDescribe(ksApp app.App, envName string) error {
 func RunEnvDescribe(m map[string]interface{}) error {
-	ed, err := NewEnvDescribe(m)
+	var o EnvDescribeOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunEnvDescribeWithOptions(o)
+}
+
+// RunEnvDescribeWithOptions runs `env describe` with typed options.
+func RunEnvDescribeWithOptions(o EnvDescribeOptions) error {
+	ed, err := NewEnvDescribeWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -36,24 +47,41 @@ func RunEnvDescribe(m map[string]interface{}) error {
 
 // EnvDescribe describes an environment by printing its configuration.
 type EnvDescribe struct {
-	app     app.App
-	envName string
-	out     io.Writer
+	app        app.App
+	envName    string
+	outputType string
+	out        io.Writer
 }
 
-// NewEnvDescribe creates an instance of EnvDescribe.
+// EnvDescribeOptions are the options for EnvDescribe.
+type EnvDescribeOptions struct {
+	App     app.App `option:"app"`
+	EnvName string  `option:"env-name"`
+	Output  string  `option:"output,optional"`
+}
+
+// NewEnvDescribe creates an instance of EnvDescribe from an option map.
 func NewEnvDescribe(m map[string]interface{}) (*EnvDescribe, error) {
-	ol := newOptionLoader(m)
+	var o EnvDescribeOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
 
-	ed := &EnvDescribe{
-		app:     ol.LoadApp(),
-		envName: ol.LoadString(OptionEnvName),
+	return NewEnvDescribeWithOptions(o)
+}
 
-		out: os.Stdout,
+// NewEnvDescribeWithOptions creates an instance of EnvDescribe.
+func NewEnvDescribeWithOptions(o EnvDescribeOptions) (*EnvDescribe, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
 	}
 
-	if ol.err != nil {
-		return nil, ol.err
+	ed := &EnvDescribe{
+		app:        o.App,
+		envName:    o.EnvName,
+		outputType: o.Output,
+
+		out: os.Stdout,
 	}
 
 	return ed, nil
@@ -68,6 +96,15 @@ func (ed *EnvDescribe) Run() error {
 
 	env.Name = ed.envName
 
+	f, err := table.DetectFormat(ed.outputType)
+	if err != nil {
+		return errors.Wrap(err, "detecting output format")
+	}
+
+	if f != table.FormatTable {
+		return table.Encode(ed.out, f, env)
+	}
+
 	b, err := yaml.Marshal(env)
 	if err != nil {
 		return err
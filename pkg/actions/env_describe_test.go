@@ -52,6 +52,47 @@ func TestEnvDescribe(t *testing.T) {
 	})
 }
 
+func TestEnvDescribe_output(t *testing.T) {
+	cases := []struct {
+		output   string
+		expected string
+	}{
+		{output: "json", expected: "env/describe/output.json"},
+		{output: "yaml", expected: "env/describe/output.yaml"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.output, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				env := &app.EnvironmentConfig{
+					KubernetesVersion: "v1.7.0",
+					Destination: &app.EnvironmentDestinationSpec{
+						Namespace: "default",
+						Server:    "http://example.com",
+					},
+				}
+
+				appMock.On("Environment", "default").Return(env, nil)
+
+				var buf bytes.Buffer
+				in := map[string]interface{}{
+					OptionApp:     appMock,
+					OptionEnvName: "default",
+					OptionOutput:  tc.output,
+				}
+
+				a, err := NewEnvDescribe(in)
+				require.NoError(t, err)
+				a.out = &buf
+
+				require.NoError(t, a.Run())
+
+				assertOutput(t, tc.expected, buf.String())
+			})
+		})
+	}
+}
+
 func TestEnvDescribe_requires_app(t *testing.T) {
 	in := make(map[string]interface{})
 	_, err := NewEnvDescribe(in)
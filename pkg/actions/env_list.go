@@ -27,7 +27,17 @@ import (
 
 // RunEnvList runs `env list`
 func RunEnvList(m map[string]interface{}) error {
-	nl, err := NewEnvList(m)
+	var o EnvListOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunEnvListWithOptions(o)
+}
+
+// RunEnvListWithOptions runs `env list` with typed options.
+func RunEnvListWithOptions(o EnvListOptions) error {
+	nl, err := NewEnvListWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -43,23 +53,39 @@ type EnvList struct {
 	out        io.Writer
 }
 
-// NewEnvList creates an instance of EnvList
+// EnvListOptions are the options for EnvList.
+type EnvListOptions struct {
+	App    app.App   `option:"app"`
+	Output string    `option:"output,optional"`
+	Out    io.Writer `option:"out,optional"`
+}
+
+// NewEnvList creates an instance of EnvList from an option map.
 func NewEnvList(m map[string]interface{}) (*EnvList, error) {
-	ol := newOptionLoader(m)
+	var o EnvListOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
 
-	a := ol.LoadApp()
-	outputType := ol.LoadOptionalString(OptionOutput)
+	return NewEnvListWithOptions(o)
+}
 
-	if ol.err != nil {
-		return nil, ol.err
+// NewEnvListWithOptions creates an instance of EnvList.
+func NewEnvListWithOptions(o EnvListOptions) (*EnvList, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
 	}
 
 	el := &EnvList{
-		outputType: outputType,
-		envListFn:  a.Environments,
+		outputType: o.Output,
+		envListFn:  o.App.Environments,
 		out:        os.Stdout,
 	}
 
+	if o.Out != nil {
+		el.out = o.Out
+	}
+
 	return el, nil
 }
 
@@ -87,12 +113,13 @@ func (el *EnvList) Run() error {
 			override = "*"
 		}
 
+		destination := env.PrimaryDestination()
 		rows = append(rows, []string{
 			name,
 			override,
 			env.KubernetesVersion,
-			env.Destination.Namespace,
-			env.Destination.Server,
+			destination.Namespace,
+			destination.Server,
 		})
 	}
 
@@ -54,6 +54,21 @@ func TestEnvList(t *testing.T) {
 		appMock.On("Environments").Return(envs, nil)
 	}
 
+	// Multi-cluster environments may only have destinations.
+	setupMultiClusterApp := func(appMock *amocks.App) {
+		envs := app.EnvironmentConfigs{
+			"prod": &app.EnvironmentConfig{
+				KubernetesVersion: "v1.7.0",
+				Destinations: []*app.EnvironmentDestinationSpec{
+					{Name: "us", Namespace: "prod", Server: "http://us.example.com"},
+					{Name: "eu", Namespace: "prod", Server: "http://eu.example.com"},
+				},
+			},
+		}
+
+		appMock.On("Environments").Return(envs, nil)
+	}
+
 	envListFail := func(appMock *amocks.App) {
 		appMock.On("Environments").Return(nil, errors.New("failed"))
 	}
@@ -82,6 +97,11 @@ func TestEnvList(t *testing.T) {
 			outputType:   "json",
 			expectedFile: filepath.Join("env", "list", "output.json"),
 		},
+		{
+			name:         "multi-cluster environment",
+			initApp:      setupMultiClusterApp,
+			expectedFile: filepath.Join("env", "list", "multi_cluster.txt"),
+		},
 		{
 			name:       "invalid output format",
 			initApp:    setupValidApp,
@@ -0,0 +1,104 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"fmt"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/env"
+)
+
+// RunEnvRegenerate runs `env regenerate`
+func RunEnvRegenerate(m map[string]interface{}) error {
+	var o EnvRegenerateOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunEnvRegenerateWithOptions(o)
+}
+
+// RunEnvRegenerateWithOptions runs `env regenerate` with typed options.
+func RunEnvRegenerateWithOptions(o EnvRegenerateOptions) error {
+	er, err := NewEnvRegenerateWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return er.Run()
+}
+
+type envRegenerateFn func(a app.App, name string, preserveCustom bool) error
+
+// EnvRegenerate rewrites the generated files of an environment.
+type EnvRegenerate struct {
+	app            app.App
+	envName        string
+	preserveCustom bool
+	confirmer      *confirmer
+
+	envRegenerateFn envRegenerateFn
+}
+
+// EnvRegenerateOptions are the options for EnvRegenerate.
+type EnvRegenerateOptions struct {
+	App            app.App `option:"app"`
+	EnvName        string  `option:"env-name"`
+	PreserveCustom bool    `option:"preserve-custom,optional"`
+	AssumeYes      bool    `option:"assume-yes,optional"`
+}
+
+// NewEnvRegenerate creates an instance of EnvRegenerate from an option map.
+func NewEnvRegenerate(m map[string]interface{}) (*EnvRegenerate, error) {
+	var o EnvRegenerateOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return NewEnvRegenerateWithOptions(o)
+}
+
+// NewEnvRegenerateWithOptions creates an instance of EnvRegenerate.
+func NewEnvRegenerateWithOptions(o EnvRegenerateOptions) (*EnvRegenerate, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	er := &EnvRegenerate{
+		app:            o.App,
+		envName:        o.EnvName,
+		preserveCustom: o.PreserveCustom,
+		confirmer:      newConfirmer(o.AssumeYes),
+
+		envRegenerateFn: env.Regenerate,
+	}
+
+	return er, nil
+}
+
+// Run regenerates the files of an environment. Discarding edits must be
+// confirmed, unless custom regions are preserved.
+func (er *EnvRegenerate) Run() error {
+	if !er.preserveCustom {
+		action := fmt.Sprintf("Regenerate environment %q, discarding changes to its files", er.envName)
+		if err := er.confirmer.confirmEnv(er.app, er.envName, action); err != nil {
+			return err
+		}
+	}
+
+	return er.envRegenerateFn(er.app, er.envName, er.preserveCustom)
+}
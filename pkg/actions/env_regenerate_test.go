@@ -0,0 +1,95 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvRegenerate(t *testing.T) {
+	cases := []struct {
+		name           string
+		preserveCustom bool
+		assumeYes      bool
+		isErr          bool
+	}{
+		{
+			name:      "confirmed",
+			assumeYes: true,
+		},
+		{
+			name:  "not confirmed",
+			isErr: true,
+		},
+		{
+			name:           "preserve custom regions without confirmation",
+			preserveCustom: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				appMock.On("Environment", "default").Return(&app.EnvironmentConfig{}, nil)
+
+				in := map[string]interface{}{
+					OptionApp:            appMock,
+					OptionEnvName:        "default",
+					OptionPreserveCustom: tc.preserveCustom,
+					OptionAssumeYes:      tc.assumeYes,
+				}
+
+				a, err := NewEnvRegenerate(in)
+				require.NoError(t, err)
+
+				a.confirmer.in = &bytes.Buffer{}
+				a.confirmer.out = &bytes.Buffer{}
+				a.confirmer.isTerminalFn = func() bool { return true }
+
+				var regenerated bool
+				a.envRegenerateFn = func(a app.App, name string, preserveCustom bool) error {
+					assert.Equal(t, appMock, a)
+					assert.Equal(t, "default", name)
+					assert.Equal(t, tc.preserveCustom, preserveCustom)
+
+					regenerated = true
+					return nil
+				}
+
+				err = a.Run()
+				if tc.isErr {
+					require.Error(t, err)
+					assert.False(t, regenerated)
+					return
+				}
+				require.NoError(t, err)
+				assert.True(t, regenerated)
+			})
+		})
+	}
+}
+
+func TestEnvRegenerate_requires_app(t *testing.T) {
+	in := make(map[string]interface{})
+	_, err := NewEnvRegenerate(in)
+	require.Error(t, err)
+}
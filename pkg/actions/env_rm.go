@@ -16,13 +16,25 @@
 package actions
 
 import (
+	"fmt"
+
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/env"
 )
 
 // RunEnvRm runs `env rm`
 func RunEnvRm(m map[string]interface{}) error {
-	ea, err := NewEnvRm(m)
+	var o EnvRmOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunEnvRmWithOptions(o)
+}
+
+// RunEnvRmWithOptions runs `env rm` with typed options.
+func RunEnvRmWithOptions(o EnvRmOptions) error {
+	ea, err := NewEnvRmWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -37,24 +49,42 @@ type EnvRm struct {
 	app        app.App
 	envName    string
 	isOverride bool
+	confirmer  *confirmer
 
 	envDeleteFn envDeleteFn
 }
 
-// NewEnvRm creates an instance of EnvRm.
+// EnvRmOptions are the options for EnvRm.
+type EnvRmOptions struct {
+	App       app.App `option:"app"`
+	EnvName   string  `option:"env-name"`
+	Override  bool    `option:"override"`
+	AssumeYes bool    `option:"assume-yes,optional"`
+}
+
+// NewEnvRm creates an instance of EnvRm from an option map.
 func NewEnvRm(m map[string]interface{}) (*EnvRm, error) {
-	ol := newOptionLoader(m)
+	var o EnvRmOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
 
-	ea := &EnvRm{
-		app:        ol.LoadApp(),
-		envName:    ol.LoadString(OptionEnvName),
-		isOverride: ol.LoadBool(OptionOverride),
+	return NewEnvRmWithOptions(o)
+}
 
-		envDeleteFn: env.Delete,
+// NewEnvRmWithOptions creates an instance of EnvRm.
+func NewEnvRmWithOptions(o EnvRmOptions) (*EnvRm, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
 	}
 
-	if ol.err != nil {
-		return nil, ol.err
+	ea := &EnvRm{
+		app:        o.App,
+		envName:    o.EnvName,
+		isOverride: o.Override,
+		confirmer:  newConfirmer(o.AssumeYes),
+
+		envDeleteFn: env.Delete,
 	}
 
 	return ea, nil
@@ -62,6 +92,11 @@ func NewEnvRm(m map[string]interface{}) (*EnvRm, error) {
 
 // Run assigns targets to an environment.
 func (er *EnvRm) Run() error {
+	action := fmt.Sprintf("Remove environment %q", er.envName)
+	if err := er.confirmer.confirmEnv(er.app, er.envName, action); err != nil {
+		return err
+	}
+
 	return er.envDeleteFn(
 		er.app,
 		er.envName,
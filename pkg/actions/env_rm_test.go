@@ -30,9 +30,10 @@ func TestEnvRm(t *testing.T) {
 		aIsOverride := false
 
 		in := map[string]interface{}{
-			OptionApp:      appMock,
-			OptionEnvName:  aName,
-			OptionOverride: aIsOverride,
+			OptionApp:       appMock,
+			OptionAssumeYes: true,
+			OptionEnvName:   aName,
+			OptionOverride:  aIsOverride,
 		}
 
 		a, err := NewEnvRm(in)
@@ -39,9 +39,18 @@ func EnvSetName(name string) EnvSetOpt {
 type EnvSetOpt func(*EnvSet)
 
 // RunEnvSet runs `env set`
-// func RunEnvSet(ksApp app.App, envName string, opts ...EnvSetOpt) error {
 func RunEnvSet(m map[string]interface{}) error {
-	et, err := NewEnvSet(m)
+	var o EnvSetOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunEnvSetWithOptions(o)
+}
+
+// RunEnvSetWithOptions runs `env set` with typed options.
+func RunEnvSetWithOptions(o EnvSetOptions) error {
+	et, err := NewEnvSetWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -66,26 +75,44 @@ type EnvSet struct {
 	saveFn      saveFn
 }
 
-// NewEnvSet creates an instance of EnvSet.
+// EnvSetOptions are the options for EnvSet.
+type EnvSetOptions struct {
+	App          app.App `option:"app"`
+	EnvName      string  `option:"env-name"`
+	NewEnvName   string  `option:"new-env-name,optional"`
+	NewNamespace string  `option:"namespace,optional"`
+	NewServer    string  `option:"server,optional"`
+	NewSpecFlag  string  `option:"spec-flag,optional"`
+}
+
+// NewEnvSet creates an instance of EnvSet from an option map.
 func NewEnvSet(m map[string]interface{}) (*EnvSet, error) {
-	ol := newOptionLoader(m)
+	var o EnvSetOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return NewEnvSetWithOptions(o)
+}
+
+// NewEnvSetWithOptions creates an instance of EnvSet.
+func NewEnvSetWithOptions(o EnvSetOptions) (*EnvSet, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
 
 	es := &EnvSet{
-		app:        ol.LoadApp(),
-		envName:    ol.LoadString(OptionEnvName),
-		newName:    ol.LoadOptionalString(OptionNewEnvName),
-		newNsName:  ol.LoadOptionalString(OptionNamespace),
-		newServer:  ol.LoadOptionalString(OptionServer),
-		newAPISpec: ol.LoadOptionalString(OptionSpecFlag),
+		app:        o.App,
+		envName:    o.EnvName,
+		newName:    o.NewEnvName,
+		newNsName:  o.NewNamespace,
+		newServer:  o.NewServer,
+		newAPISpec: o.NewSpecFlag,
 
 		envRenameFn: env.Rename,
 		saveFn:      save,
 	}
 
-	if ol.err != nil {
-		return nil, ol.err
-	}
-
 	return es, nil
 }
 
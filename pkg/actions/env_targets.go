@@ -22,7 +22,17 @@ import (
 
 // RunEnvTargets runs `env targets`
 func RunEnvTargets(m map[string]interface{}) error {
-	et, err := NewEnvTargets(m)
+	var o EnvTargetsOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunEnvTargetsWithOptions(o)
+}
+
+// RunEnvTargetsWithOptions runs `env targets` with typed options.
+func RunEnvTargetsWithOptions(o EnvTargetsOptions) error {
+	et, err := NewEnvTargetsWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -38,20 +48,35 @@ type EnvTargets struct {
 	cm      component.Manager
 }
 
-// NewEnvTargets creates an instance of EnvTargets.
+// EnvTargetsOptions are the options for EnvTargets.
+type EnvTargetsOptions struct {
+	App     app.App  `option:"app"`
+	EnvName string   `option:"env-name"`
+	Modules []string `option:"module"`
+}
+
+// NewEnvTargets creates an instance of EnvTargets from an option map.
 func NewEnvTargets(m map[string]interface{}) (*EnvTargets, error) {
-	ol := newOptionLoader(m)
+	var o EnvTargetsOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
 
-	et := &EnvTargets{
-		app:     ol.LoadApp(),
-		envName: ol.LoadString(OptionEnvName),
-		modules: ol.LoadStringSlice(OptionModule),
+	return NewEnvTargetsWithOptions(o)
+}
 
-		cm: component.DefaultManager,
+// NewEnvTargetsWithOptions creates an instance of EnvTargets.
+func NewEnvTargetsWithOptions(o EnvTargetsOptions) (*EnvTargets, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
 	}
 
-	if ol.err != nil {
-		return nil, ol.err
+	et := &EnvTargets{
+		app:     o.App,
+		envName: o.EnvName,
+		modules: o.Modules,
+
+		cm: component.DefaultManager,
 	}
 
 	return et, nil
@@ -65,10 +90,14 @@ func (et *EnvTargets) Run() error {
 	}
 
 	for _, module := range et.modules {
-		_, err := et.cm.Module(et.app, module)
+		m, err := et.cm.Module(et.app, module)
 		if err != nil {
 			return err
 		}
+
+		if err = component.CheckVisible(et.app.Fs(), m, et.envName); err != nil {
+			return err
+		}
 	}
 
 	return et.app.UpdateTargets(et.envName, et.modules)
@@ -23,7 +23,9 @@ import (
 
 	"github.com/ksonnet/ksonnet/pkg/app"
 	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/component"
 	cmocks "github.com/ksonnet/ksonnet/pkg/component/mocks"
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
 )
 
@@ -46,6 +48,7 @@ func TestEnvTargets(t *testing.T) {
 		require.NoError(t, err)
 
 		ns := &cmocks.Module{}
+		ns.On("Dir").Return("/components/foo")
 
 		cm := &cmocks.Manager{}
 		cm.On("Module", mock.Anything, "foo").Return(ns, nil)
@@ -57,6 +60,41 @@ func TestEnvTargets(t *testing.T) {
 	})
 }
 
+func TestEnvTargets_module_not_visible(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		envName := "default"
+		modules := []string{"foo"}
+
+		env := &app.EnvironmentConfig{}
+		appMock.On("Environment", "default").Return(env, nil)
+
+		manifest := []byte("environments:\n- prod\n")
+		require.NoError(t, afero.WriteFile(appMock.Fs(), "/components/foo/module.yaml", manifest, 0644))
+
+		in := map[string]interface{}{
+			OptionApp:     appMock,
+			OptionEnvName: envName,
+			OptionModule:  modules,
+		}
+
+		a, err := NewEnvTargets(in)
+		require.NoError(t, err)
+
+		ns := &cmocks.Module{}
+		ns.On("Dir").Return("/components/foo")
+		ns.On("Name").Return("foo")
+
+		cm := &cmocks.Manager{}
+		cm.On("Module", mock.Anything, "foo").Return(ns, nil)
+
+		a.cm = cm
+
+		err = a.Run()
+		require.IsType(t, &component.ModuleNotVisibleError{}, err)
+		appMock.AssertNotCalled(t, "UpdateTargets", envName, modules)
+	})
+}
+
 func TestEnvTargets_invalid_module(t *testing.T) {
 	withApp(t, func(appMock *amocks.App) {
 		envName := "default"
@@ -25,7 +25,17 @@ import (
 
 // RunEnvUpdate runs `env update`.
 func RunEnvUpdate(m map[string]interface{}) error {
-	a, err := newEnvUpdate(m)
+	var o EnvUpdateOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunEnvUpdateWithOptions(o)
+}
+
+// RunEnvUpdateWithOptions runs `env update` with typed options.
+func RunEnvUpdateWithOptions(o EnvUpdateOptions) error {
+	a, err := newEnvUpdateWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -42,20 +52,33 @@ type EnvUpdate struct {
 	genLibFn   func(app.App, string, string, *http.Client) error
 }
 
-// RunEnvUpdate runs `env update`
+// EnvUpdateOptions are the options for EnvUpdate.
+type EnvUpdateOptions struct {
+	App           app.App `option:"app"`
+	EnvName       string  `option:"env-name"`
+	TLSSkipVerify bool    `option:"tls-skip-verify,optional"`
+}
+
 func newEnvUpdate(m map[string]interface{}) (*EnvUpdate, error) {
-	ol := newOptionLoader(m)
+	var o EnvUpdateOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
 
-	eu := &EnvUpdate{
-		app:     ol.LoadApp(),
-		envName: ol.LoadString(OptionEnvName),
+	return newEnvUpdateWithOptions(o)
+}
 
-		httpClient: ol.LoadHTTPClient(),
-		genLibFn:   genLib,
+func newEnvUpdateWithOptions(o EnvUpdateOptions) (*EnvUpdate, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
 	}
 
-	if ol.err != nil {
-		return nil, ol.err
+	eu := &EnvUpdate{
+		app:     o.App,
+		envName: o.EnvName,
+
+		httpClient: newHTTPClient(o.TLSSkipVerify),
+		genLibFn:   genLib,
 	}
 
 	return eu, nil
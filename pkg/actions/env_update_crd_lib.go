@@ -0,0 +1,146 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"path/filepath"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/lib"
+	"github.com/ksonnet/ksonnet/pkg/openapi"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// RunEnvUpdateCRDLib runs `env update-crd-lib`.
+func RunEnvUpdateCRDLib(m map[string]interface{}) error {
+	var o EnvUpdateCRDLibOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunEnvUpdateCRDLibWithOptions(o)
+}
+
+// RunEnvUpdateCRDLibWithOptions runs `env update-crd-lib` with typed options.
+func RunEnvUpdateCRDLibWithOptions(o EnvUpdateCRDLibOptions) error {
+	euc, err := NewEnvUpdateCRDLibWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return euc.Run()
+}
+
+// EnvUpdateCRDLib generates helpers for the custom resources of an
+// environment's cluster, and of local CustomResourceDefinitions.
+type EnvUpdateCRDLib struct {
+	app          app.App
+	envName      string
+	paths        []string
+	clientConfig *client.Config
+	offline      bool
+
+	crdFn crdFn
+}
+
+// EnvUpdateCRDLibOptions are the options for EnvUpdateCRDLib.
+type EnvUpdateCRDLibOptions struct {
+	App          app.App        `option:"app"`
+	EnvName      string         `option:"env-name"`
+	Paths        []string       `option:"paths,optional"`
+	ClientConfig *client.Config `option:"client-config"`
+	Offline      bool           `option:"offline,optional"`
+}
+
+// NewEnvUpdateCRDLib creates an instance of EnvUpdateCRDLib from an option map.
+func NewEnvUpdateCRDLib(m map[string]interface{}) (*EnvUpdateCRDLib, error) {
+	var o EnvUpdateCRDLibOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return NewEnvUpdateCRDLibWithOptions(o)
+}
+
+// NewEnvUpdateCRDLibWithOptions creates an instance of EnvUpdateCRDLib.
+func NewEnvUpdateCRDLibWithOptions(o EnvUpdateCRDLibOptions) (*EnvUpdateCRDLib, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	euc := &EnvUpdateCRDLib{
+		app:          o.App,
+		envName:      o.EnvName,
+		paths:        o.Paths,
+		clientConfig: o.ClientConfig,
+		offline:      o.Offline,
+
+		crdFn: loadClusterCRDs,
+	}
+
+	return euc, nil
+}
+
+// Run generates the custom resource library. CustomResourceDefinitions are
+// collected from the cluster, the app's schemas directory, and the given
+// files, in increasing order of precedence.
+func (euc *EnvUpdateCRDLib) Run() error {
+	if _, err := euc.app.Environment(euc.envName); err != nil {
+		return err
+	}
+
+	crds := openapi.NewCRDSchemas()
+
+	if !euc.offline {
+		clusterCRDs, err := euc.crdFn(euc.app, euc.clientConfig, euc.envName)
+		if err != nil {
+			return errors.Wrap(err, "fetching CustomResourceDefinitions from the cluster")
+		}
+
+		for _, crd := range clusterCRDs {
+			if err = crds.AddCRD(crd); err != nil {
+				log.Warnf("Ignoring schema of CustomResourceDefinition %s: %v", crd.GetName(), err)
+			}
+		}
+	}
+
+	dir := filepath.Join(euc.app.Root(), openapi.SchemaDirName)
+	if err := crds.AddDir(euc.app.Fs(), dir); err != nil {
+		return err
+	}
+
+	for _, path := range euc.paths {
+		if err := crds.AddFile(euc.app.Fs(), path); err != nil {
+			return err
+		}
+	}
+
+	schemas := crds.Schemas()
+	if len(schemas) == 0 {
+		return errors.New("no CustomResourceDefinitions with schemas were found")
+	}
+
+	libPath := filepath.Join(euc.app.Root(), app.LibDirName, lib.CRDLibFilename)
+	if err := afero.WriteFile(euc.app.Fs(), libPath, lib.GenerateCRDLib(schemas), app.DefaultFilePermissions); err != nil {
+		return errors.Wrapf(err, "writing %s", libPath)
+	}
+
+	log.Infof("Generated helpers for %d custom resources in %s", len(schemas), libPath)
+	return nil
+}
@@ -0,0 +1,160 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const envUpdateCRDLibCRD = `
+apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: crontabs.stable.example.com
+spec:
+  group: stable.example.com
+  version: v1
+  names:
+    kind: CronTab
+  validation:
+    openAPIV3Schema:
+      properties:
+        spec:
+          properties:
+            cronSpec:
+              type: string
+`
+
+func newClusterCRD() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1beta1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": "certificates.certmanager.k8s.io"},
+		"spec": map[string]interface{}{
+			"group":   "certmanager.k8s.io",
+			"version": "v1alpha1",
+			"names":   map[string]interface{}{"kind": "Certificate"},
+			"validation": map[string]interface{}{
+				"openAPIV3Schema": map[string]interface{}{
+					"properties": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"properties": map[string]interface{}{
+								"dnsName": map[string]interface{}{"type": "string"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}}
+}
+
+func TestEnvUpdateCRDLib(t *testing.T) {
+	cases := []struct {
+		name       string
+		offline    bool
+		paths      []string
+		clusterErr error
+		expected   []string
+		isErr      bool
+	}{
+		{
+			name:     "cluster and files",
+			paths:    []string{"/crontab.yaml"},
+			expected: []string{`"certmanager.k8s.io"::`, `"stable.example.com"::`, "withCronSpec(cronSpec)", "withDnsName(dnsName)"},
+		},
+		{
+			name:     "offline",
+			offline:  true,
+			paths:    []string{"/crontab.yaml"},
+			expected: []string{`"stable.example.com"::`},
+		},
+		{
+			name:       "cluster error",
+			clusterErr: errors.New("unreachable"),
+			isErr:      true,
+		},
+		{
+			name:    "no CustomResourceDefinitions",
+			offline: true,
+			isErr:   true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				appMock.On("Environment", "default").Return(&app.EnvironmentConfig{}, nil)
+
+				fs := appMock.Fs()
+				require.NoError(t, afero.WriteFile(fs, "/crontab.yaml", []byte(envUpdateCRDLibCRD), 0644))
+
+				in := map[string]interface{}{
+					OptionApp:          appMock,
+					OptionEnvName:      "default",
+					OptionPaths:        tc.paths,
+					OptionClientConfig: &client.Config{},
+					OptionOffline:      tc.offline,
+				}
+
+				a, err := NewEnvUpdateCRDLib(in)
+				require.NoError(t, err)
+
+				var fetched bool
+				a.crdFn = func(_ app.App, _ *client.Config, envName string) ([]*unstructured.Unstructured, error) {
+					fetched = true
+					assert.Equal(t, "default", envName)
+					return []*unstructured.Unstructured{newClusterCRD()}, tc.clusterErr
+				}
+
+				err = a.Run()
+				if tc.isErr {
+					require.Error(t, err)
+					test.AssertNotExists(t, fs, "/lib/crds.libsonnet")
+					return
+				}
+				require.NoError(t, err)
+
+				assert.Equal(t, !tc.offline, fetched)
+
+				b, err := afero.ReadFile(fs, "/lib/crds.libsonnet")
+				require.NoError(t, err)
+				for _, s := range tc.expected {
+					assert.Contains(t, string(b), s)
+				}
+				if tc.offline {
+					assert.NotContains(t, string(b), "certmanager.k8s.io")
+				}
+			})
+		})
+	}
+}
+
+func TestEnvUpdateCRDLib_requires_app(t *testing.T) {
+	in := make(map[string]interface{})
+	_, err := NewEnvUpdateCRDLib(in)
+	require.Error(t, err)
+}
@@ -0,0 +1,206 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/lib"
+	"github.com/ksonnet/ksonnet/pkg/util/table"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// RunEnvUpdateLib runs `env update-lib`.
+func RunEnvUpdateLib(m map[string]interface{}) error {
+	var o EnvUpdateLibOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunEnvUpdateLibWithOptions(o)
+}
+
+// RunEnvUpdateLibWithOptions runs `env update-lib` with typed options.
+func RunEnvUpdateLibWithOptions(o EnvUpdateLibOptions) error {
+	eul, err := NewEnvUpdateLibWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return eul.Run()
+}
+
+// EnvUpdateLib regenerates ksonnet-lib for an environment against a
+// Kubernetes version, and shows the API changes from the version the
+// environment used before.
+type EnvUpdateLib struct {
+	app         app.App
+	envName     string
+	k8sSpecFlag string
+	dryRun      bool
+	outputType  string
+
+	httpClient    *http.Client
+	out           io.Writer
+	clusterSpecFn func(string, afero.Fs, *http.Client) (lib.ClusterSpec, error)
+}
+
+// EnvUpdateLibOptions are the options for EnvUpdateLib.
+type EnvUpdateLibOptions struct {
+	App           app.App `option:"app"`
+	EnvName       string  `option:"env-name"`
+	SpecFlag      string  `option:"spec-flag,optional"`
+	DryRun        bool    `option:"dry-run,optional"`
+	Output        string  `option:"output,optional"`
+	TLSSkipVerify bool    `option:"tls-skip-verify,optional"`
+}
+
+// NewEnvUpdateLib creates an instance of EnvUpdateLib from an option map.
+func NewEnvUpdateLib(m map[string]interface{}) (*EnvUpdateLib, error) {
+	var o EnvUpdateLibOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return NewEnvUpdateLibWithOptions(o)
+}
+
+// NewEnvUpdateLibWithOptions creates an instance of EnvUpdateLib.
+func NewEnvUpdateLibWithOptions(o EnvUpdateLibOptions) (*EnvUpdateLib, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	eul := &EnvUpdateLib{
+		app:         o.App,
+		envName:     o.EnvName,
+		k8sSpecFlag: o.SpecFlag,
+		dryRun:      o.DryRun,
+		outputType:  o.Output,
+
+		httpClient:    newHTTPClient(o.TLSSkipVerify),
+		out:           os.Stdout,
+		clusterSpecFn: lib.ParseClusterSpec,
+	}
+
+	return eul, nil
+}
+
+// Run regenerates ksonnet-lib for the environment.
+func (eul *EnvUpdateLib) Run() error {
+	f, err := table.DetectFormat(eul.outputType)
+	if err != nil {
+		return errors.Wrap(err, "detecting output format")
+	}
+
+	envSpec, err := eul.app.Environment(eul.envName)
+	if err != nil {
+		return err
+	}
+
+	// Without an API spec, ksonnet-lib is regenerated for the version the
+	// environment already uses.
+	k8sSpecFlag := eul.k8sSpecFlag
+	if k8sSpecFlag == "" {
+		k8sSpecFlag = fmt.Sprintf("version:%s", envSpec.KubernetesVersion)
+	}
+
+	current, err := eul.currentSchema()
+	if err != nil {
+		return err
+	}
+
+	spec, err := eul.clusterSpecFn(k8sSpecFlag, eul.app.Fs(), eul.httpClient)
+	if err != nil {
+		return err
+	}
+
+	version, err := spec.Version()
+	if err != nil {
+		return err
+	}
+
+	schema, err := spec.OpenAPI()
+	if err != nil {
+		return errors.Wrapf(err, "retrieving OpenAPI spec for %s", version)
+	}
+
+	changes, err := lib.DiffAPI(current, schema)
+	if err != nil {
+		return err
+	}
+
+	if err = eul.print(f, envSpec.KubernetesVersion, version, changes); err != nil {
+		return err
+	}
+
+	if eul.dryRun {
+		return nil
+	}
+
+	if err = eul.app.AddEnvironment(envSpec, k8sSpecFlag, envSpec.IsOverride()); err != nil {
+		return errors.Wrapf(err, "updating environment %s", eul.envName)
+	}
+
+	log.Infof("Updated ksonnet-lib for environment %q to Kubernetes %s", eul.envName, version)
+	return nil
+}
+
+// currentSchema reads the OpenAPI spec the environment's ksonnet-lib was
+// generated from. It is nil if the environment doesn't have one.
+func (eul *EnvUpdateLib) currentSchema() ([]byte, error) {
+	libPath, err := eul.app.LibPath(eul.envName)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := afero.ReadFile(eul.app.Fs(), filepath.Join(libPath, lib.SchemaFilename))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "reading current OpenAPI spec")
+	}
+
+	return data, nil
+}
+
+func (eul *EnvUpdateLib) print(f table.Format, from, to string, changes []lib.APIChange) error {
+	if f != table.FormatTable {
+		return table.Encode(eul.out, f, changes)
+	}
+
+	if len(changes) == 0 {
+		_, err := fmt.Fprintf(eul.out, "No API changes between Kubernetes %s and %s\n", from, to)
+		return err
+	}
+
+	t := table.New("envUpdateLib", eul.out)
+	t.SetHeader([]string{"definition", "change", "properties"})
+	for _, c := range changes {
+		t.Append([]string{c.Definition, c.Change, strings.Join(c.Properties, ", ")})
+	}
+
+	return t.Render()
+}
@@ -0,0 +1,157 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/lib"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClusterSpec struct {
+	version string
+	openAPI []byte
+}
+
+var _ lib.ClusterSpec = (*fakeClusterSpec)(nil)
+
+func (cs *fakeClusterSpec) OpenAPI() ([]byte, error) {
+	return cs.openAPI, nil
+}
+
+func (cs *fakeClusterSpec) Resource() string {
+	return cs.version
+}
+
+func (cs *fakeClusterSpec) Version() (string, error) {
+	return cs.version, nil
+}
+
+func TestEnvUpdateLib(t *testing.T) {
+	current := `{"definitions": {
+  "io.k8s.api.core.v1.PodSpec": {"properties": {"containers": {}}},
+  "io.k8s.api.extensions.v1beta1.Deployment": {"properties": {"spec": {}}}
+}}`
+
+	updated := `{"definitions": {
+  "io.k8s.api.apps.v1.Deployment": {"properties": {"spec": {}}},
+  "io.k8s.api.core.v1.PodSpec": {"properties": {"containers": {}, "shareProcessNamespace": {}}}
+}}`
+
+	cases := []struct {
+		name        string
+		k8sSpecFlag string
+		dryRun      bool
+		outputType  string
+		outputName  string
+		expectFlag  string
+		isErr       bool
+	}{
+		{
+			name:        "update version",
+			k8sSpecFlag: "version:v1.11.0",
+			outputName:  filepath.Join("env", "update-lib", "output.txt"),
+			expectFlag:  "version:v1.11.0",
+		},
+		{
+			name:        "dry run",
+			k8sSpecFlag: "version:v1.11.0",
+			dryRun:      true,
+			outputType:  "json",
+			outputName:  filepath.Join("env", "update-lib", "output.json"),
+			expectFlag:  "version:v1.11.0",
+		},
+		{
+			name:       "regenerate current version",
+			outputName: filepath.Join("env", "update-lib", "unchanged.txt"),
+			expectFlag: "version:v1.8.9",
+		},
+		{
+			name:        "invalid output type",
+			k8sSpecFlag: "version:v1.11.0",
+			outputType:  "invalid",
+			isErr:       true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				envSpec := &app.EnvironmentConfig{
+					Name:              "default",
+					KubernetesVersion: "v1.8.9",
+				}
+				appMock.On("Environment", "default").Return(envSpec, nil)
+				appMock.On("LibPath", "default").Return("/app/lib/ksonnet-lib/v1.8.9", nil)
+
+				fs := appMock.Fs()
+				require.NoError(t, afero.WriteFile(fs, "/app/lib/ksonnet-lib/v1.8.9/swagger.json", []byte(current), 0644))
+
+				in := map[string]interface{}{
+					OptionApp:      appMock,
+					OptionEnvName:  "default",
+					OptionSpecFlag: tc.k8sSpecFlag,
+					OptionDryRun:   tc.dryRun,
+					OptionOutput:   tc.outputType,
+				}
+
+				a, err := NewEnvUpdateLib(in)
+				require.NoError(t, err)
+
+				a.clusterSpecFn = func(k8sSpecFlag string, _ afero.Fs, _ *http.Client) (lib.ClusterSpec, error) {
+					assert.Equal(t, tc.expectFlag, k8sSpecFlag)
+					if k8sSpecFlag == "version:v1.8.9" {
+						return &fakeClusterSpec{version: "v1.8.9", openAPI: []byte(current)}, nil
+					}
+					return &fakeClusterSpec{version: "v1.11.0", openAPI: []byte(updated)}, nil
+				}
+
+				appMock.On("AddEnvironment", envSpec, tc.expectFlag, false).Return(nil)
+
+				var buf bytes.Buffer
+				a.out = &buf
+
+				err = a.Run()
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+
+				if tc.dryRun {
+					appMock.AssertNotCalled(t, "AddEnvironment", envSpec, tc.expectFlag, false)
+				} else {
+					appMock.AssertCalled(t, "AddEnvironment", envSpec, tc.expectFlag, false)
+				}
+				assertOutput(t, tc.outputName, buf.String())
+			})
+		})
+	}
+}
+
+func TestEnvUpdateLib_requires_app(t *testing.T) {
+	in := make(map[string]interface{})
+	_, err := NewEnvUpdateLib(in)
+	require.Error(t, err)
+}
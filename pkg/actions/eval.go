@@ -0,0 +1,165 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/pipeline"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+// evalSnippetName is the file name of expressions in evaluation errors.
+const evalSnippetName = "<eval>"
+
+type evalFn func(a app.App, envName, filename, snippet string) (string, error)
+
+// RunEval runs `eval`.
+func RunEval(m map[string]interface{}) error {
+	var o EvalOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunEvalWithOptions(o)
+}
+
+// RunEvalWithOptions runs `eval` with typed options.
+func RunEvalWithOptions(o EvalOptions) error {
+	e, err := newEvalWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return e.run()
+}
+
+type evalOpt func(*Eval)
+
+// Eval evaluates jsonnet in the context of an app's environment.
+type Eval struct {
+	app        app.App
+	envName    string
+	expression string
+	format     string
+
+	out    io.Writer
+	evalFn evalFn
+}
+
+// EvalOptions are the options for Eval.
+type EvalOptions struct {
+	App        app.App `option:"app"`
+	EnvName    string  `option:"env-name,optional"`
+	Expression string  `option:"expression"`
+	Format     string  `option:"format,optional"`
+}
+
+func newEval(m map[string]interface{}, opts ...evalOpt) (*Eval, error) {
+	var o EvalOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return newEvalWithOptions(o, opts...)
+}
+
+func newEvalWithOptions(o EvalOptions, opts ...evalOpt) (*Eval, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	e := &Eval{
+		app:        o.App,
+		expression: o.Expression,
+		format:     o.Format,
+
+		out: os.Stdout,
+		evalFn: func(a app.App, envName, filename, snippet string) (string, error) {
+			return pipeline.New(a, envName).EvaluateSnippet(filename, snippet)
+		},
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	if err := setCurrentEnv(e.app, e, o.EnvName); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+func (e *Eval) run() error {
+	filename, snippet, err := e.source()
+	if err != nil {
+		return err
+	}
+
+	evaluated, err := e.evalFn(e.app, e.envName, filename, snippet)
+	if err != nil {
+		return err
+	}
+
+	switch e.format {
+	case "", "json":
+		_, err = fmt.Fprintln(e.out, evaluated)
+		return err
+	case "yaml":
+		b, err := yaml.JSONToYAML([]byte(evaluated))
+		if err != nil {
+			return errors.Wrap(err, "converting to YAML")
+		}
+		_, err = e.out.Write(b)
+		return err
+	default:
+		return errors.Errorf("unknown format %q; valid formats are json and yaml", e.format)
+	}
+}
+
+// source returns the jsonnet to evaluate. If the expression is the path of a
+// file, the file is evaluated, so its relative imports can be resolved.
+func (e *Eval) source() (string, string, error) {
+	fs := e.app.Fs()
+
+	fi, err := fs.Stat(e.expression)
+	if err != nil || fi.IsDir() {
+		return evalSnippetName, e.expression, nil
+	}
+
+	path, err := filepath.Abs(e.expression)
+	if err != nil {
+		return "", "", err
+	}
+
+	b, err := afero.ReadFile(fs, e.expression)
+	if err != nil {
+		return "", "", err
+	}
+
+	return path, string(b), nil
+}
+
+func (e *Eval) setCurrentEnv(name string) {
+	e.envName = name
+}
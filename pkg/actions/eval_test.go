@@ -0,0 +1,144 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEval(t *testing.T) {
+	cases := []struct {
+		name             string
+		expression       string
+		format           string
+		expectedFilename string
+		expectedSnippet  string
+		expected         string
+		isErr            bool
+	}{
+		{
+			name:             "expression",
+			expression:       `std.extVar("__ksonnet/params").components`,
+			expectedFilename: "<eval>",
+			expectedSnippet:  `std.extVar("__ksonnet/params").components`,
+			expected:         "{\n   \"replicas\": 1\n}\n",
+		},
+		{
+			name:             "file",
+			expression:       "/debug.jsonnet",
+			expectedFilename: "/debug.jsonnet",
+			expectedSnippet:  `import "k.libsonnet"`,
+			expected:         "{\n   \"replicas\": 1\n}\n",
+		},
+		{
+			name:             "yaml",
+			expression:       "1 + 1",
+			format:           "yaml",
+			expectedFilename: "<eval>",
+			expectedSnippet:  "1 + 1",
+			expected:         "replicas: 1\n",
+		},
+		{
+			name:             "invalid format",
+			expression:       "1 + 1",
+			format:           "toml",
+			expectedFilename: "<eval>",
+			expectedSnippet:  "1 + 1",
+			isErr:            true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				require.NoError(t, afero.WriteFile(appMock.Fs(), "/debug.jsonnet", []byte(`import "k.libsonnet"`), 0644))
+
+				in := map[string]interface{}{
+					OptionApp:        appMock,
+					OptionEnvName:    "default",
+					OptionExpression: tc.expression,
+					OptionFormat:     tc.format,
+				}
+
+				var buf bytes.Buffer
+				evalOpt := func(e *Eval) {
+					e.out = &buf
+					e.evalFn = func(a app.App, envName, filename, snippet string) (string, error) {
+						assert.Equal(t, "default", envName)
+						assert.Equal(t, tc.expectedFilename, filename)
+						assert.Equal(t, tc.expectedSnippet, snippet)
+						return "{\n   \"replicas\": 1\n}", nil
+					}
+				}
+
+				e, err := newEval(in, evalOpt)
+				require.NoError(t, err)
+
+				err = e.run()
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, buf.String())
+			})
+		})
+	}
+}
+
+func TestEval_eval_error(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionApp:        appMock,
+			OptionEnvName:    "default",
+			OptionExpression: "error 'failed'",
+		}
+
+		evalOpt := func(e *Eval) {
+			e.evalFn = func(a app.App, envName, filename, snippet string) (string, error) {
+				return "", errors.New("failed")
+			}
+		}
+
+		e, err := newEval(in, evalOpt)
+		require.NoError(t, err)
+
+		require.Error(t, e.run())
+	})
+}
+
+func TestEval_requires_env(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("CurrentEnvironment").Return("")
+
+		in := map[string]interface{}{
+			OptionApp:        appMock,
+			OptionExpression: "1 + 1",
+		}
+
+		_, err := newEval(in)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,223 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/ksonnet/ksonnet/pkg/pipeline"
+	"github.com/ksonnet/ksonnet/pkg/util/table"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type sourceObjectsFn func(a app.App, envName string) ([]*unstructured.Unstructured, error)
+
+// sourceMappedObjects renders an environment with its objects annotated
+// with their sources.
+func sourceMappedObjects(a app.App, envName string) ([]*unstructured.Unstructured, error) {
+	return pipeline.New(a, envName, pipeline.SourceMap()).Objects(nil)
+}
+
+// RunExplain runs `explain`.
+func RunExplain(m map[string]interface{}) error {
+	var o ExplainOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunExplainWithOptions(o)
+}
+
+// RunExplainWithOptions runs `explain` with typed options.
+func RunExplainWithOptions(o ExplainOptions) error {
+	e, err := newExplainWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return e.run()
+}
+
+type explainOpt func(*Explain)
+
+// Explain shows the component, prototype and package which rendered an
+// object. For a live object, it also shows the component parameters which
+// set its fields.
+type Explain struct {
+	app          app.App
+	clientConfig *client.Config
+	envName      string
+	kind         string
+	name         string
+	namespace    string
+	live         bool
+	output       string
+
+	objectsFn    sourceObjectsFn
+	liveObjectFn liveObjectFn
+	paramsFn     componentParamsFn
+	out          io.Writer
+}
+
+// ExplainOptions are the options for Explain.
+type ExplainOptions struct {
+	App          app.App        `option:"app"`
+	ClientConfig *client.Config `option:"client-config"`
+	EnvName      string         `option:"env-name,optional"`
+	Object       string         `option:"object"`
+	Namespace    string         `option:"namespace,optional"`
+	Live         bool           `option:"live,optional"`
+	Output       string         `option:"output,optional"`
+	Out          io.Writer      `option:"out,optional"`
+}
+
+func newExplain(m map[string]interface{}, opts ...explainOpt) (*Explain, error) {
+	var o ExplainOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return newExplainWithOptions(o, opts...)
+}
+
+func newExplainWithOptions(o ExplainOptions, opts ...explainOpt) (*Explain, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	kind, name, err := parseObjectSelector(o.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Explain{
+		app:          o.App,
+		clientConfig: o.ClientConfig,
+		kind:         kind,
+		name:         name,
+		namespace:    o.Namespace,
+		live:         o.Live,
+		output:       o.Output,
+
+		objectsFn:    sourceMappedObjects,
+		liveObjectFn: getLiveObject,
+		paramsFn:     componentParams,
+		out:          os.Stdout,
+	}
+
+	if o.Out != nil {
+		e.out = o.Out
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	if err := setCurrentEnv(e.app, e, o.EnvName); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+func (e *Explain) setCurrentEnv(name string) {
+	e.envName = name
+}
+
+// parseObjectSelector parses a selector in the form <kind>/<name>.
+func parseObjectSelector(s string) (string, string, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", errors.Errorf("invalid object %q; objects are selected with <kind>/<name>", s)
+	}
+
+	return parts[0], parts[1], nil
+}
+
+// matches is true if an object has the kind and name, and the namespace if
+// one was given. The kind matches the object's kind, or its kind and group,
+// ignoring case.
+func (e *Explain) matches(obj *unstructured.Unstructured) bool {
+	if obj.GetName() != e.name {
+		return false
+	}
+
+	if e.namespace != "" && obj.GetNamespace() != e.namespace {
+		return false
+	}
+
+	kind := obj.GetKind()
+	if strings.EqualFold(e.kind, kind) {
+		return true
+	}
+
+	group := obj.GroupVersionKind().Group
+	return group != "" && strings.EqualFold(e.kind, kind+"."+group)
+}
+
+func (e *Explain) run() error {
+	f, err := table.DetectFormat(e.output)
+	if err != nil {
+		return errors.Wrap(err, "detecting output format")
+	}
+
+	if e.live {
+		return e.runLive(f)
+	}
+
+	objects, err := e.objectsFn(e.app, e.envName)
+	if err != nil {
+		return errors.Wrapf(err, "rendering environment %s", e.envName)
+	}
+
+	var rows [][]string
+	for _, obj := range objects {
+		if !e.matches(obj) {
+			continue
+		}
+
+		annotations := obj.GetAnnotations()
+		rows = append(rows, []string{
+			obj.GetKind() + "/" + obj.GetName(),
+			obj.GetNamespace(),
+			obj.GetLabels()[metadata.LabelComponent],
+			annotations[metadata.AnnotationSourceComponent],
+			annotations[metadata.AnnotationSourcePrototype],
+			annotations[metadata.AnnotationSourcePackage],
+		})
+	}
+
+	if len(rows) == 0 {
+		return errors.Errorf("environment %s doesn't render %s/%s", e.envName, e.kind, e.name)
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i][1] < rows[j][1]
+	})
+
+	t := table.New("explain", e.out)
+	t.SetFormat(f)
+	t.SetHeader([]string{"object", "namespace", "component", "path", "prototype", "package"})
+	t.AppendBulk(rows)
+	return t.Render()
+}
@@ -0,0 +1,266 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/ksonnet/ksonnet/pkg/util/table"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type liveObjectFn func(a app.App, clientConfig *client.Config, envName, kind, name, namespace string) (*unstructured.Unstructured, error)
+
+type componentParamsFn func(a app.App, envName, componentName string) ([]component.ModuleParameter, error)
+
+// liveExplanation is where a live object came from, and the component
+// parameters which set its fields.
+type liveExplanation struct {
+	Object    string       `json:"object"`
+	Namespace string       `json:"namespace,omitempty"`
+	Component string       `json:"component"`
+	Path      string       `json:"path,omitempty"`
+	Prototype string       `json:"prototype,omitempty"`
+	Package   string       `json:"package,omitempty"`
+	Fields    []paramField `json:"fields"`
+}
+
+// paramField is a field of an object whose value is set by a component
+// parameter.
+type paramField struct {
+	Field string `json:"field"`
+	Value string `json:"value"`
+	Param string `json:"param"`
+}
+
+// runLive explains an object in the environment's cluster. The object's
+// source is read from the annotations it was applied with, so it is only
+// complete if the environment enables its source map.
+func (e *Explain) runLive(f table.Format) error {
+	obj, err := e.liveObjectFn(e.app, e.clientConfig, e.envName, e.kind, e.name, e.namespace)
+	if err != nil {
+		return errors.Wrapf(err, "getting %s/%s", e.kind, e.name)
+	}
+
+	componentName := obj.GetLabels()[metadata.LabelComponent]
+	if componentName == "" {
+		return errors.Errorf("%s/%s isn't labeled with a component of the app", e.kind, e.name)
+	}
+
+	params, err := e.paramsFn(e.app, e.envName, componentName)
+	if err != nil {
+		return err
+	}
+
+	annotations := obj.GetAnnotations()
+	explanation := liveExplanation{
+		Object:    obj.GetKind() + "/" + obj.GetName(),
+		Namespace: obj.GetNamespace(),
+		Component: componentName,
+		Path:      annotations[metadata.AnnotationSourceComponent],
+		Prototype: annotations[metadata.AnnotationSourcePrototype],
+		Package:   annotations[metadata.AnnotationSourcePackage],
+		Fields:    paramFields(obj, params),
+	}
+
+	if f != table.FormatTable {
+		if explanation.Fields == nil {
+			explanation.Fields = []paramField{}
+		}
+		return table.Encode(e.out, f, explanation)
+	}
+
+	t := table.New("explain", e.out)
+	t.SetHeader([]string{"object", "namespace", "component", "path", "prototype", "package"})
+	t.Append([]string{explanation.Object, explanation.Namespace, explanation.Component,
+		explanation.Path, explanation.Prototype, explanation.Package})
+	if err := t.Render(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(e.out)
+
+	t = table.New("explainFields", e.out)
+	t.SetHeader([]string{"field", "value", "param"})
+	for _, field := range explanation.Fields {
+		t.Append([]string{field.Field, field.Value, field.Param})
+	}
+
+	return t.Render()
+}
+
+// paramFields finds the fields of an object whose values are the values of
+// component parameters. The object's metadata other than its name, namespace
+// and labels, its component label, and its status, are left out.
+func paramFields(obj *unstructured.Unstructured, params []component.ModuleParameter) []paramField {
+	byValue := make(map[string][]string)
+	for _, p := range params {
+		value := paramValue(p.Value)
+		if value == "" {
+			continue
+		}
+		byValue[value] = append(byValue[value], p.Key)
+	}
+
+	var fields []paramField
+	visit := func(path, value string) {
+		if path == "metadata.labels."+metadata.LabelComponent {
+			return
+		}
+
+		for _, key := range byValue[value] {
+			fields = append(fields, paramField{Field: path, Value: value, Param: key})
+		}
+	}
+
+	for key, v := range obj.Object {
+		switch key {
+		case "apiVersion", "kind", "status":
+		case "metadata":
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for _, field := range []string{"name", "namespace", "labels"} {
+				if fv, ok := m[field]; ok {
+					walkFields("metadata."+field, fv, visit)
+				}
+			}
+		default:
+			walkFields(key, v, visit)
+		}
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		if fields[i].Field != fields[j].Field {
+			return fields[i].Field < fields[j].Field
+		}
+		return fields[i].Param < fields[j].Param
+	})
+
+	return fields
+}
+
+// walkFields calls visit with the path and value of each scalar field in v.
+func walkFields(path string, v interface{}, visit func(path, value string)) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for key, child := range t {
+			walkFields(path+"."+key, child, visit)
+		}
+	case []interface{}:
+		for i, child := range t {
+			walkFields(fmt.Sprintf("%s[%d]", path, i), child, visit)
+		}
+	case string:
+		visit(path, t)
+	case bool:
+		visit(path, strconv.FormatBool(t))
+	case int64:
+		visit(path, strconv.FormatInt(t, 10))
+	case float64:
+		visit(path, strconv.FormatFloat(t, 'f', -1, 64))
+	}
+}
+
+// paramValue converts the Jsonnet source of a scalar parameter to the text
+// of its value.
+func paramValue(s string) string {
+	if v, err := strconv.Unquote(s); err == nil {
+		return v
+	}
+
+	if len(s) >= 2 && strings.HasPrefix(s, "'") && strings.HasSuffix(s, "'") {
+		return s[1 : len(s)-1]
+	}
+
+	return s
+}
+
+// componentParams returns the parameters of a component in an environment.
+func componentParams(a app.App, envName, componentName string) ([]component.ModuleParameter, error) {
+	_, c, err := component.ResolvePath(a, componentName)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not find component")
+	}
+
+	if c == nil {
+		return nil, errors.Errorf("unable to find component %s", componentName)
+	}
+
+	return c.Params(envName)
+}
+
+// getLiveObject gets an object from the environment's cluster. The kind can
+// be a resource's name, singular name, kind or short name, and can include
+// its group. Namespaced objects are in the environment's namespace unless
+// another one is given.
+func getLiveObject(a app.App, clientConfig *client.Config, envName, kind, name, namespace string) (*unstructured.Unstructured, error) {
+	pool, disc, defaultNamespace, err := clientConfig.RestClient(a, &envName)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceLists, err := disc.ServerPreferredResources()
+	if err != nil && len(resourceLists) == 0 {
+		return nil, errors.Wrap(err, "discovering resources")
+	}
+
+	if i := strings.Index(kind, "."); i != -1 {
+		var group string
+		kind, group = kind[:i], kind[i+1:]
+		resourceLists = resourceListsInGroup(resourceLists, group)
+	}
+
+	gvk, resource, err := findAPIResource(resourceLists, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	dynamic, err := pool.ClientForGroupVersionKind(gvk)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating client for resource: %s", gvk)
+	}
+
+	if !resource.Namespaced {
+		namespace = ""
+	} else if namespace == "" {
+		namespace = defaultNamespace
+	}
+
+	return dynamic.Resource(resource, namespace).Get(name, metav1.GetOptions{})
+}
+
+// resourceListsInGroup returns the resource lists of an API group.
+func resourceListsInGroup(resourceLists []*metav1.APIResourceList, group string) []*metav1.APIResourceList {
+	var lists []*metav1.APIResourceList
+	for _, list := range resourceLists {
+		if strings.SplitN(list.GroupVersion, "/", 2)[0] == group {
+			lists = append(lists, list)
+		}
+	}
+
+	return lists
+}
@@ -0,0 +1,290 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func explainObject(apiVersion, kind, namespace, name string, annotations map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata": map[string]interface{}{
+				"name":        name,
+				"namespace":   namespace,
+				"labels":      map[string]interface{}{"ksonnet.io/component": "redis"},
+				"annotations": annotations,
+			},
+		},
+	}
+}
+
+func explainObjects(a app.App, envName string) ([]*unstructured.Unstructured, error) {
+	annotations := map[string]interface{}{
+		"ksonnet.io/source-component": "components/redis.jsonnet",
+		"ksonnet.io/source-prototype": "io.ksonnet.pkg.redis-stateless",
+		"ksonnet.io/source-package":   "incubator/redis@1.0.0",
+	}
+
+	return []*unstructured.Unstructured{
+		explainObject("apps/v1", "Deployment", "default", "redis", annotations),
+		explainObject("v1", "Service", "default", "redis", annotations),
+		explainObject("v1", "Service", "cache", "redis", annotations),
+	}, nil
+}
+
+func TestExplain(t *testing.T) {
+	cases := []struct {
+		name      string
+		object    string
+		namespace string
+		output    string
+		expected  string
+		isErr     bool
+	}{
+		{
+			name:     "table",
+			object:   "deployment/redis",
+			expected: "explain/table.txt",
+		},
+		{
+			name:     "kind and group",
+			object:   "Deployment.apps/redis",
+			expected: "explain/table.txt",
+		},
+		{
+			name:     "in several namespaces",
+			object:   "service/redis",
+			expected: "explain/namespaces.txt",
+		},
+		{
+			name:      "namespace",
+			object:    "service/redis",
+			namespace: "cache",
+			output:    "json",
+			expected:  "explain/output.json",
+		},
+		{
+			name:   "not rendered",
+			object: "deployment/web",
+			isErr:  true,
+		},
+		{
+			name:   "invalid output",
+			object: "deployment/redis",
+			output: "xml",
+			isErr:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				var buf bytes.Buffer
+
+				in := map[string]interface{}{
+					OptionApp:          appMock,
+					OptionClientConfig: &client.Config{},
+					OptionEnvName:      "default",
+					OptionObject:       tc.object,
+					OptionNamespace:    tc.namespace,
+					OptionOutput:       tc.output,
+					OptionOut:          &buf,
+				}
+
+				e, err := newExplain(in, func(e *Explain) {
+					e.objectsFn = explainObjects
+				})
+				require.NoError(t, err)
+
+				err = e.run()
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+
+				assertOutput(t, tc.expected, buf.String())
+			})
+		})
+	}
+}
+
+func liveRedis(a app.App, clientConfig *client.Config, envName, kind, name, namespace string) (*unstructured.Unstructured, error) {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":      "redis",
+				"namespace": "default",
+				"labels": map[string]interface{}{
+					"app":                  "redis",
+					"ksonnet.io/component": "redis",
+				},
+				"annotations": map[string]interface{}{
+					"ksonnet.io/source-component": "components/redis.jsonnet",
+					"ksonnet.io/source-prototype": "io.ksonnet.pkg.redis-stateless",
+					"ksonnet.io/source-package":   "incubator/redis@1.0.0",
+				},
+			},
+			"spec": map[string]interface{}{
+				"replicas": int64(3),
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{"name": "redis", "image": "redis:4.0"},
+						},
+					},
+				},
+			},
+			"status": map[string]interface{}{
+				"replicas": int64(3),
+			},
+		},
+	}, nil
+}
+
+func redisParams(a app.App, envName, componentName string) ([]component.ModuleParameter, error) {
+	return []component.ModuleParameter{
+		{Component: "redis", Key: "name", Value: `"redis"`},
+		{Component: "redis", Key: "image", Value: `'redis:4.0'`},
+		{Component: "redis", Key: "replicas", Value: "3"},
+		{Component: "redis", Key: "debug", Value: "false"},
+	}, nil
+}
+
+func TestExplain_live(t *testing.T) {
+	cases := []struct {
+		name     string
+		output   string
+		objectFn liveObjectFn
+		expected string
+		isErr    bool
+	}{
+		{
+			name:     "table",
+			objectFn: liveRedis,
+			expected: "explain/live.txt",
+		},
+		{
+			name:     "json",
+			output:   "json",
+			objectFn: liveRedis,
+			expected: "explain/live.json",
+		},
+		{
+			name: "not managed by a component",
+			objectFn: func(a app.App, clientConfig *client.Config, envName, kind, name, namespace string) (*unstructured.Unstructured, error) {
+				obj, err := liveRedis(a, clientConfig, envName, kind, name, namespace)
+				obj.SetLabels(nil)
+				return obj, err
+			},
+			isErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				var buf bytes.Buffer
+
+				in := map[string]interface{}{
+					OptionApp:          appMock,
+					OptionClientConfig: &client.Config{},
+					OptionEnvName:      "default",
+					OptionObject:       "deployment/redis",
+					OptionLive:         true,
+					OptionOutput:       tc.output,
+					OptionOut:          &buf,
+				}
+
+				e, err := newExplain(in, func(e *Explain) {
+					e.liveObjectFn = tc.objectFn
+					e.paramsFn = redisParams
+				})
+				require.NoError(t, err)
+
+				err = e.run()
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+
+				assertOutput(t, tc.expected, buf.String())
+			})
+		})
+	}
+}
+
+func Test_paramValue(t *testing.T) {
+	cases := []struct {
+		in       string
+		expected string
+	}{
+		{in: `"redis"`, expected: "redis"},
+		{in: `'redis'`, expected: "redis"},
+		{in: "3", expected: "3"},
+		{in: "true", expected: "true"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.in, func(t *testing.T) {
+			assert.Equal(t, tc.expected, paramValue(tc.in))
+		})
+	}
+}
+
+func Test_parseObjectSelector(t *testing.T) {
+	cases := []struct {
+		name  string
+		in    string
+		kind  string
+		obj   string
+		isErr bool
+	}{
+		{name: "kind and name", in: "deployment/redis", kind: "deployment", obj: "redis"},
+		{name: "no kind", in: "redis", isErr: true},
+		{name: "blank name", in: "deployment/", isErr: true},
+		{name: "too many parts", in: "apps/deployment/redis", isErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			kind, name, err := parseObjectSelector(tc.in)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.kind, kind)
+			assert.Equal(t, tc.obj, name)
+		})
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"sort"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/export"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// RunExport runs `export`.
+func RunExport(m map[string]interface{}) error {
+	var o ExportOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunExportWithOptions(o)
+}
+
+// RunExportWithOptions runs `export` with typed options.
+func RunExportWithOptions(o ExportOptions) error {
+	e, err := newExportWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return e.run()
+}
+
+type exportOpt func(*Export)
+
+// Export writes the manifests of every environment of an app to a directory.
+type Export struct {
+	app       app.App
+	dir       string
+	gitops    bool
+	kustomize bool
+
+	objectsFn snapshotObjectsFn
+}
+
+// ExportOptions are the options for Export.
+type ExportOptions struct {
+	App       app.App `option:"app"`
+	Dir       string  `option:"path"`
+	GitOps    bool    `option:"gitops,optional"`
+	Kustomize bool    `option:"kustomize,optional"`
+}
+
+func newExport(m map[string]interface{}, opts ...exportOpt) (*Export, error) {
+	var o ExportOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return newExportWithOptions(o, opts...)
+}
+
+func newExportWithOptions(o ExportOptions, opts ...exportOpt) (*Export, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	e := &Export{
+		app:       o.App,
+		dir:       o.Dir,
+		gitops:    o.GitOps,
+		kustomize: o.Kustomize,
+
+		objectsFn: pipelineObjects,
+	}
+
+	if e.kustomize && !e.gitops {
+		return nil, errors.New("kustomizations can only be written with the GitOps layout")
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e, nil
+}
+
+func (e *Export) run() error {
+	configs, err := e.app.Environments()
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for name := range configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var envs []export.Environment
+	for _, name := range names {
+		objects, err := e.objectsFn(e.app, name)
+		if err != nil {
+			return errors.Wrapf(err, "rendering environment %s", name)
+		}
+
+		envs = append(envs, export.Environment{
+			Name:    name,
+			Config:  configs[name],
+			Objects: objects,
+		})
+	}
+
+	var opts []export.Opt
+	if e.gitops {
+		opts = append(opts, export.GitOps(e.kustomize))
+	}
+
+	written, err := export.New(e.app.Fs(), e.dir, opts...).Export(envs)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("exported %d environment(s) to %s in %d file(s)", len(envs), e.dir, len(written))
+	return nil
+}
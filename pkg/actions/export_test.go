@@ -0,0 +1,138 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestExport(t *testing.T) {
+	cases := []struct {
+		name      string
+		gitops    bool
+		kustomize bool
+		expected  []string
+	}{
+		{
+			name:     "plain",
+			expected: []string{"/export/default.yaml", "/export/prod.yaml"},
+		},
+		{
+			name:   "gitops",
+			gitops: true,
+			expected: []string{
+				"/export/default/service-guestbook-ui.yaml",
+				"/export/prod/service-guestbook-ui.yaml",
+				"/export/index.yaml",
+			},
+		},
+		{
+			name:      "gitops with kustomizations",
+			gitops:    true,
+			kustomize: true,
+			expected: []string{
+				"/export/default/kustomization.yaml",
+				"/export/prod/kustomization.yaml",
+				"/export/index.yaml",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				appMock.On("Environments").Return(app.EnvironmentConfigs{
+					"prod":    &app.EnvironmentConfig{},
+					"default": &app.EnvironmentConfig{},
+				}, nil)
+
+				in := map[string]interface{}{
+					OptionApp:       appMock,
+					OptionPath:      "/export",
+					OptionGitOps:    tc.gitops,
+					OptionKustomize: tc.kustomize,
+				}
+
+				var rendered []string
+				e, err := newExport(in, func(e *Export) {
+					objectsFn := snapshotObjects(1)
+					e.objectsFn = func(a app.App, envName string) ([]*unstructured.Unstructured, error) {
+						rendered = append(rendered, envName)
+						return objectsFn(a, envName)
+					}
+				})
+				require.NoError(t, err)
+				require.NoError(t, e.run())
+
+				assert.Equal(t, []string{"default", "prod"}, rendered)
+
+				for _, path := range tc.expected {
+					exists, err := afero.Exists(appMock.Fs(), path)
+					require.NoError(t, err)
+					assert.True(t, exists, "expected %s to exist", path)
+				}
+			})
+		})
+	}
+}
+
+func TestExport_kustomize_requires_gitops(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionApp:       appMock,
+			OptionPath:      "/export",
+			OptionKustomize: true,
+		}
+
+		_, err := newExport(in)
+		require.Error(t, err)
+	})
+}
+
+func TestExport_objects_error(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("Environments").Return(app.EnvironmentConfigs{
+			"default": &app.EnvironmentConfig{},
+		}, nil)
+
+		in := map[string]interface{}{
+			OptionApp:  appMock,
+			OptionPath: "/export",
+		}
+
+		e, err := newExport(in, func(e *Export) {
+			e.objectsFn = func(a app.App, envName string) ([]*unstructured.Unstructured, error) {
+				return nil, errors.New("failed")
+			}
+		})
+		require.NoError(t, err)
+		require.Error(t, e.run())
+	})
+}
+
+func TestExport_requires_app(t *testing.T) {
+	in := make(map[string]interface{})
+	_, err := newExport(in)
+	require.Error(t, err)
+}
@@ -0,0 +1,191 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	"github.com/ksonnet/ksonnet/pkg/params"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// RunFmt runs `fmt`.
+func RunFmt(m map[string]interface{}) error {
+	var o FmtOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunFmtWithOptions(o)
+}
+
+// RunFmtWithOptions runs `fmt` with typed options.
+func RunFmtWithOptions(o FmtOptions) error {
+	f, err := newFmtWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return f.run()
+}
+
+type fmtOpt func(*Fmt)
+
+// Fmt normalizes the params.libsonnet files of an app's modules and
+// environments.
+type Fmt struct {
+	app   app.App
+	check bool
+
+	out io.Writer
+}
+
+// FmtOptions are the options for Fmt.
+type FmtOptions struct {
+	App   app.App `option:"app"`
+	Check bool    `option:"check,optional"`
+}
+
+func newFmt(m map[string]interface{}, opts ...fmtOpt) (*Fmt, error) {
+	var o FmtOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return newFmtWithOptions(o, opts...)
+}
+
+func newFmtWithOptions(o FmtOptions, opts ...fmtOpt) (*Fmt, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	f := &Fmt{
+		app:   o.App,
+		check: o.Check,
+
+		out: os.Stdout,
+	}
+
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	return f, nil
+}
+
+func (f *Fmt) run() error {
+	paths, err := paramsPaths(f.app)
+	if err != nil {
+		return err
+	}
+
+	fs := f.app.Fs()
+	root := f.app.Root()
+
+	var unformatted []string
+	for _, path := range paths {
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		src, err := afero.ReadFile(fs, path)
+		if err != nil {
+			return err
+		}
+
+		formatted, err := params.Format(string(src))
+		if errors.Cause(err) == params.ErrFormatDropsComments {
+			log.Warnf("skipping %s: %v", rel, err)
+			continue
+		}
+		if err != nil {
+			return errors.Wrapf(err, "formatting %s", rel)
+		}
+
+		if strings.TrimSpace(formatted) == strings.TrimSpace(string(src)) {
+			continue
+		}
+
+		unformatted = append(unformatted, rel)
+		if f.check {
+			continue
+		}
+
+		if err = afero.WriteFile(fs, path, []byte(formatted+"\n"), app.DefaultFilePermissions); err != nil {
+			return err
+		}
+	}
+
+	for _, rel := range unformatted {
+		fmt.Fprintln(f.out, rel)
+	}
+
+	if f.check && len(unformatted) > 0 {
+		return errors.Errorf("%d params file(s) are not formatted", len(unformatted))
+	}
+
+	return nil
+}
+
+// paramsPaths returns the paths of the params files of the app's modules and
+// environments which exist.
+func paramsPaths(a app.App) ([]string, error) {
+	modules, err := component.Modules(a)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []string
+	for _, m := range modules {
+		candidates = append(candidates, m.ParamsPath())
+	}
+
+	envs, err := a.Environments()
+	if err != nil {
+		return nil, err
+	}
+
+	envPaths := make([]string, 0, len(envs))
+	for _, env := range envs {
+		envPaths = append(envPaths, filepath.Join(env.MakePath(a.Root()), "params.libsonnet"))
+	}
+	sort.Strings(envPaths)
+	candidates = append(candidates, envPaths...)
+
+	var paths []string
+	for _, path := range candidates {
+		exists, err := afero.Exists(a.Fs(), path)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths, nil
+}
@@ -0,0 +1,152 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	"github.com/ksonnet/ksonnet/pkg/params"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	unformattedModuleParams = `{
+  global: {},
+  components: {
+    web: { replicas: 2, image: "nginx" },
+    empty: {},
+  },
+}
+`
+
+	formattedModuleParams = `{
+  global: {},
+  components: {
+    web: { image: 'nginx', replicas: 2 },
+  },
+}
+`
+
+	templateEnvParams = `local params = std.extVar('__ksonnet/params');
+
+params + {
+  components+: {
+    // Insert component parameter overrides here.
+  },
+}
+`
+)
+
+func TestFmt(t *testing.T) {
+	cases := []struct {
+		name     string
+		check    bool
+		expected string
+		isErr    bool
+	}{
+		{
+			name:     "write",
+			expected: formattedModuleParams,
+		},
+		{
+			name:     "check",
+			check:    true,
+			expected: unformattedModuleParams,
+			isErr:    true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				fs := appMock.Fs()
+				require.NoError(t, afero.WriteFile(fs, "/components/params.libsonnet", []byte(unformattedModuleParams), 0644))
+				require.NoError(t, afero.WriteFile(fs, "/environments/default/params.libsonnet", []byte(templateEnvParams), 0644))
+
+				appMock.On("Environments").Return(app.EnvironmentConfigs{
+					"default": &app.EnvironmentConfig{Path: "default"},
+					"prod":    &app.EnvironmentConfig{Path: "prod"},
+				}, nil)
+
+				in := map[string]interface{}{
+					OptionApp:   appMock,
+					OptionCheck: tc.check,
+				}
+
+				var buf bytes.Buffer
+				f, err := newFmt(in, func(f *Fmt) {
+					f.out = &buf
+				})
+				require.NoError(t, err)
+
+				err = f.run()
+				if tc.isErr {
+					require.Error(t, err)
+				} else {
+					require.NoError(t, err)
+				}
+
+				assert.Equal(t, "components/params.libsonnet\n", buf.String())
+
+				b, err := afero.ReadFile(fs, "/components/params.libsonnet")
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, string(b))
+
+				b, err = afero.ReadFile(fs, "/environments/default/params.libsonnet")
+				require.NoError(t, err)
+				assert.Equal(t, templateEnvParams, string(b))
+			})
+		})
+	}
+}
+
+func TestFmt_generated_params(t *testing.T) {
+	// The params of new apps have comments in empty objects, and are
+	// formatted, not skipped.
+	generated := component.GenParamsContent()
+
+	formatted, err := params.Format(string(generated))
+	require.NoError(t, err)
+	assert.Equal(t, strings.TrimSpace(string(generated)), formatted)
+
+	withApp(t, func(appMock *amocks.App) {
+		fs := appMock.Fs()
+		require.NoError(t, afero.WriteFile(fs, "/components/params.libsonnet", generated, 0644))
+
+		appMock.On("Environments").Return(app.EnvironmentConfigs{}, nil)
+
+		in := map[string]interface{}{
+			OptionApp:   appMock,
+			OptionCheck: true,
+		}
+
+		var buf bytes.Buffer
+		f, err := newFmt(in, func(f *Fmt) {
+			f.out = &buf
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, f.run())
+		assert.Empty(t, buf.String())
+	})
+}
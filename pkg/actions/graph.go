@@ -0,0 +1,295 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	"github.com/ksonnet/ksonnet/pkg/graph"
+	"github.com/ksonnet/ksonnet/pkg/inventory"
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// RunGraph runs `graph`.
+func RunGraph(m map[string]interface{}) error {
+	var o GraphOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunGraphWithOptions(o)
+}
+
+// RunGraphWithOptions runs `graph` with typed options.
+func RunGraphWithOptions(o GraphOptions) error {
+	g, err := NewGraphWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return g.Run()
+}
+
+// Graph writes a graph of an app's modules, components, the prototypes and
+// packages they use, and the modules environments target.
+type Graph struct {
+	app     app.App
+	envName string
+	format  string
+	objects bool
+
+	modulesFn func(app.App) ([]component.Module, error)
+	objectsFn componentObjectsFn
+	sourcesFn componentSourcesFn
+	out       io.Writer
+}
+
+// GraphOptions are the options for Graph.
+type GraphOptions struct {
+	App     app.App `option:"app"`
+	EnvName string  `option:"env-name,optional"`
+	// Format defaults to graph.FormatDOT.
+	Format  string    `option:"format,optional"`
+	Objects bool      `option:"objects,optional"`
+	Out     io.Writer `option:"out,optional"`
+}
+
+// NewGraph creates an instance of Graph from an option map.
+func NewGraph(m map[string]interface{}) (*Graph, error) {
+	var o GraphOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return NewGraphWithOptions(o)
+}
+
+// NewGraphWithOptions creates an instance of Graph.
+func NewGraphWithOptions(o GraphOptions) (*Graph, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	g := &Graph{
+		app:     o.App,
+		envName: o.EnvName,
+		format:  o.Format,
+		objects: o.Objects,
+
+		modulesFn: component.Modules,
+		objectsFn: componentObjects,
+		sourcesFn: componentSources,
+		out:       os.Stdout,
+	}
+
+	if o.Out != nil {
+		g.out = o.Out
+	}
+
+	switch g.format {
+	case "":
+		g.format = graph.FormatDOT
+	case graph.FormatDOT, graph.FormatMermaid:
+	default:
+		return nil, errors.Errorf("unknown graph format %q; use %s or %s", g.format, graph.FormatDOT, graph.FormatMermaid)
+	}
+
+	if g.objects && g.envName == "" {
+		if err := setCurrentEnv(g.app, g, g.envName); err != nil {
+			return nil, errors.Wrap(err, "rendered objects need an environment")
+		}
+	}
+
+	return g, nil
+}
+
+func (g *Graph) setCurrentEnv(name string) {
+	g.envName = name
+}
+
+// Run writes the graph.
+func (g *Graph) Run() error {
+	out := graph.New()
+
+	modules, err := g.modulesFn(g.app)
+	if err != nil {
+		return errors.Wrap(err, "loading modules")
+	}
+
+	libraries, err := g.app.Libraries()
+	if err != nil {
+		return errors.Wrap(err, "loading libraries")
+	}
+	libs := inventory.EffectiveLibraries(libraries, nil)
+
+	for _, m := range modules {
+		moduleName := graphModuleName(m.Name())
+		moduleID := out.AddNode(graph.KindModule, moduleName)
+		if parent, ok := graphModuleParent(moduleName); ok {
+			out.AddEdge(out.AddNode(graph.KindModule, parent), moduleID, "contains")
+		}
+
+		components, err := m.Components()
+		if err != nil {
+			return errors.Wrapf(err, "loading components of module %s", moduleName)
+		}
+
+		// Sources are looked up by path, e.g. db/redis for db.redis.
+		var paths []string
+		for _, c := range components {
+			paths = append(paths, graphComponentPath(moduleName, c.Name(false)))
+		}
+
+		sources, err := g.sourcesFn(g.app, paths)
+		if err != nil {
+			return err
+		}
+
+		for i, c := range components {
+			componentID := out.AddNode(graph.KindComponent, c.Name(true))
+			out.AddEdge(moduleID, componentID, "contains")
+
+			src := sources[paths[i]]
+			prototypeName, packageName := inventory.Provenance(src.Data)
+			if prototypeName != "" {
+				prototypeID := out.AddNode(graph.KindPrototype, prototypeName)
+				out.AddEdge(componentID, prototypeID, "generated from")
+				if packageName != "" {
+					out.AddEdge(prototypeID, out.AddNode(graph.KindPackage, packageName), "from")
+				}
+			}
+
+			for _, lib := range inventory.ImportedLibraries(src.Data, libs) {
+				out.AddEdge(componentID, out.AddNode(graph.KindPackage, lib.Registry+"/"+lib.Name), "imports")
+			}
+		}
+	}
+
+	if err = g.addEnvironments(out); err != nil {
+		return err
+	}
+
+	if g.objects {
+		objects, err := g.objectsFn(g.app, g.envName, nil)
+		if err != nil {
+			return errors.Wrapf(err, "rendering environment %s", g.envName)
+		}
+		addObjects(out, objects)
+	}
+
+	return out.Write(g.out, g.format)
+}
+
+// addEnvironments adds the environments, or the selected environment, and
+// the modules they target. Environments without targets target every module.
+func (g *Graph) addEnvironments(out *graph.Graph) error {
+	envs, err := g.app.Environments()
+	if err != nil {
+		return errors.Wrap(err, "loading environments")
+	}
+
+	var names []string
+	for name := range envs {
+		if g.envName == "" || name == g.envName {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	if g.envName != "" && len(names) == 0 {
+		return errors.Errorf("environment %q does not exist", g.envName)
+	}
+
+	for _, name := range names {
+		envID := out.AddNode(graph.KindEnvironment, name)
+
+		targets := envs[name].Targets
+		if len(targets) == 0 {
+			targets = []string{"/"}
+		}
+
+		for _, target := range targets {
+			out.AddEdge(envID, out.AddNode(graph.KindModule, graphModuleName(target)), "targets")
+		}
+	}
+
+	return nil
+}
+
+// addObjects adds rendered objects, with edges from the components which
+// render them and to the objects which own them.
+func addObjects(out *graph.Graph, objects []*unstructured.Unstructured) {
+	ids := make(map[string]string)
+	for _, obj := range objects {
+		id := out.AddNode(graph.KindObject, graphObjectName(obj.GetNamespace(), obj.GetKind(), obj.GetName()))
+		ids[obj.GetNamespace()+"/"+obj.GetKind()+"/"+obj.GetName()] = id
+
+		if name := obj.GetLabels()[metadata.LabelComponent]; name != "" {
+			out.AddEdge(out.AddNode(graph.KindComponent, name), id, "renders")
+		}
+	}
+
+	for _, obj := range objects {
+		id := ids[obj.GetNamespace()+"/"+obj.GetKind()+"/"+obj.GetName()]
+		for _, ref := range obj.GetOwnerReferences() {
+			owner, ok := ids[obj.GetNamespace()+"/"+ref.Kind+"/"+ref.Name]
+			if !ok {
+				continue
+			}
+			out.AddEdge(id, owner, "owned by")
+		}
+	}
+}
+
+func graphObjectName(namespace, kind, name string) string {
+	if namespace == "" {
+		return kind + "/" + name
+	}
+	return namespace + "/" + kind + "/" + name
+}
+
+// graphModuleName normalizes module names, which may use either separator.
+func graphModuleName(name string) string {
+	if name == "" || name == "/" {
+		return "/"
+	}
+	return strings.Replace(name, "/", ".", -1)
+}
+
+func graphComponentPath(moduleName, name string) string {
+	if moduleName == "/" {
+		return name
+	}
+	return strings.Replace(moduleName, ".", "/", -1) + "/" + name
+}
+
+// graphModuleParent returns the module containing a module.
+func graphModuleParent(name string) (string, bool) {
+	if name == "/" {
+		return "", false
+	}
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return name[:i], true
+	}
+	return "/", true
+}
@@ -0,0 +1,147 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func graphObjects(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+	return []*unstructured.Unstructured{
+		{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":      "guestbook",
+				"namespace": "default",
+				"labels":    map[string]interface{}{"ksonnet.io/component": "guestbook"},
+			},
+		}},
+		{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":      "guestbook-config",
+				"namespace": "default",
+				"labels":    map[string]interface{}{"ksonnet.io/component": "guestbook"},
+				"ownerReferences": []interface{}{
+					map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment", "name": "guestbook", "uid": "1"},
+				},
+			},
+		}},
+	}, nil
+}
+
+func TestGraph(t *testing.T) {
+	cases := []struct {
+		name     string
+		format   string
+		envName  string
+		objects  bool
+		expected string
+		isErr    bool
+	}{
+		{
+			name:     "dot",
+			expected: "graph/output.dot",
+		},
+		{
+			name:     "mermaid",
+			format:   "mermaid",
+			expected: "graph/output.mmd",
+		},
+		{
+			name:     "objects",
+			envName:  "prod",
+			objects:  true,
+			expected: "graph/objects.dot",
+		},
+		{
+			name:    "unknown environment",
+			envName: "missing",
+			isErr:   true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				files := map[string]string{
+					"/components/params.libsonnet":    "{}",
+					"/components/guestbook.jsonnet":   "// @prototype io.ksonnet.pkg.guestbook\n// @package incubator/guestbook\nlocal guestbook = import \"incubator/guestbook/guestbook.libsonnet\";\n{}\n",
+					"/components/db/params.libsonnet": "{}",
+					"/components/db/redis.jsonnet":    "local redis = import \"incubator/redis/redis.libsonnet\";\n{}\n",
+				}
+				for path, content := range files {
+					require.NoError(t, afero.WriteFile(appMock.Fs(), path, []byte(content), 0644))
+				}
+
+				envs := app.EnvironmentConfigs{
+					"dev":  {Name: "dev"},
+					"prod": {Name: "prod", Targets: []string{"db"}},
+				}
+				appMock.On("Environments").Return(envs, nil)
+
+				libraries := app.LibraryConfigs{
+					"guestbook": {Name: "guestbook", Registry: "incubator", Version: "0.2.0"},
+					"redis":     {Name: "redis", Registry: "incubator", Version: "0.1.0"},
+				}
+				appMock.On("Libraries").Return(libraries, nil)
+
+				var buf bytes.Buffer
+				in := map[string]interface{}{
+					OptionApp:     appMock,
+					OptionEnvName: tc.envName,
+					OptionFormat:  tc.format,
+					OptionObjects: tc.objects,
+					OptionOut:     &buf,
+				}
+
+				g, err := NewGraph(in)
+				require.NoError(t, err)
+				g.objectsFn = graphObjects
+
+				err = g.Run()
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+
+				assertOutput(t, tc.expected, buf.String())
+			})
+		})
+	}
+}
+
+func TestGraph_invalid_format(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionApp:    appMock,
+			OptionFormat: "svg",
+		}
+
+		_, err := NewGraph(in)
+		require.Error(t, err)
+	})
+}
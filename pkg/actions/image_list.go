@@ -0,0 +1,203 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	"github.com/ksonnet/ksonnet/pkg/image"
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/ksonnet/ksonnet/pkg/pipeline"
+	"github.com/ksonnet/ksonnet/pkg/util/table"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type componentObjectsFn func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error)
+
+type imageParamsFn func(a app.App, envName, componentName string) (map[string]string, error)
+
+func componentObjects(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+	return pipeline.New(a, envName).Objects(componentNames)
+}
+
+// componentStringParams returns the string parameters of a component in an
+// environment.
+func componentStringParams(a app.App, envName, componentName string) (map[string]string, error) {
+	_, c, err := component.ResolvePath(a, componentName)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not find component")
+	}
+
+	if c == nil {
+		return nil, errors.Errorf("unable to find component %s", componentName)
+	}
+
+	params, err := c.Params(envName)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]string)
+	for _, p := range params {
+		if s, err := strconv.Unquote(p.Value); err == nil {
+			m[p.Key] = s
+		}
+	}
+
+	return m, nil
+}
+
+// findImages renders components, and finds their images and the parameters
+// which set them.
+func findImages(a app.App, envName string, componentNames []string, objectsFn componentObjectsFn, paramsFn imageParamsFn) ([]image.Reference, error) {
+	objects, err := objectsFn(a, envName, componentNames)
+	if err != nil {
+		return nil, errors.Wrapf(err, "rendering environment %s", envName)
+	}
+
+	params := make(map[string]map[string]string)
+	for _, obj := range objects {
+		name := obj.GetLabels()[metadata.LabelComponent]
+		if _, ok := params[name]; ok || name == "" {
+			continue
+		}
+
+		if params[name], err = paramsFn(a, envName, name); err != nil {
+			return nil, err
+		}
+	}
+
+	return image.Find(objects, params), nil
+}
+
+// RunImageList runs `image list`.
+func RunImageList(m map[string]interface{}) error {
+	var o ImageListOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunImageListWithOptions(o)
+}
+
+// RunImageListWithOptions runs `image list` with typed options.
+func RunImageListWithOptions(o ImageListOptions) error {
+	il, err := newImageListWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return il.run()
+}
+
+type imageListOpt func(*ImageList)
+
+// ImageList lists the container images used by an environment's components.
+type ImageList struct {
+	app            app.App
+	envName        string
+	componentNames []string
+	output         string
+
+	objectsFn componentObjectsFn
+	paramsFn  imageParamsFn
+	out       io.Writer
+}
+
+// ImageListOptions are the options for ImageList.
+type ImageListOptions struct {
+	App            app.App   `option:"app"`
+	EnvName        string    `option:"env-name,optional"`
+	ComponentNames []string  `option:"component-names"`
+	Output         string    `option:"output,optional"`
+	Out            io.Writer `option:"out,optional"`
+}
+
+func newImageList(m map[string]interface{}, opts ...imageListOpt) (*ImageList, error) {
+	var o ImageListOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return newImageListWithOptions(o, opts...)
+}
+
+func newImageListWithOptions(o ImageListOptions, opts ...imageListOpt) (*ImageList, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	il := &ImageList{
+		app:            o.App,
+		componentNames: o.ComponentNames,
+		output:         o.Output,
+
+		objectsFn: componentObjects,
+		paramsFn:  componentStringParams,
+		out:       os.Stdout,
+	}
+
+	if o.Out != nil {
+		il.out = o.Out
+	}
+
+	for _, opt := range opts {
+		opt(il)
+	}
+
+	if err := setCurrentEnv(il.app, il, o.EnvName); err != nil {
+		return nil, err
+	}
+
+	return il, nil
+}
+
+func (il *ImageList) setCurrentEnv(name string) {
+	il.envName = name
+}
+
+func (il *ImageList) run() error {
+	f, err := table.DetectFormat(il.output)
+	if err != nil {
+		return errors.Wrap(err, "detecting output format")
+	}
+
+	refs, err := findImages(il.app, il.envName, il.componentNames, il.objectsFn, il.paramsFn)
+	if err != nil {
+		return err
+	}
+
+	if f != table.FormatTable {
+		if refs == nil {
+			refs = []image.Reference{}
+		}
+		return table.Encode(il.out, f, refs)
+	}
+
+	t := table.New("imageList", il.out)
+	t.SetHeader([]string{"component", "object", "container", "image", "param"})
+
+	for _, ref := range refs {
+		t.Append([]string{ref.Component, ref.Object(), ref.Container, ref.Image, ref.Param})
+	}
+
+	return t.Render()
+}
@@ -0,0 +1,119 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func imageObjects(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+	deployment := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":   "guestbook",
+				"labels": map[string]interface{}{"ksonnet.io/component": "guestbook"},
+			},
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{"name": "web", "image": "gcr.io/heptio-images/guestbook:0.1"},
+							map[string]interface{}{"name": "proxy", "image": "envoyproxy/envoy:v1.8.0"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	return []*unstructured.Unstructured{deployment}, nil
+}
+
+func imageParams(a app.App, envName, componentName string) (map[string]string, error) {
+	return map[string]string{
+		"image":    "gcr.io/heptio-images/guestbook:0.1",
+		"replicas": "1",
+	}, nil
+}
+
+func TestImageList(t *testing.T) {
+	cases := []struct {
+		name     string
+		output   string
+		expected string
+	}{
+		{
+			name:     "table",
+			expected: "image/list/table.txt",
+		},
+		{
+			name:     "json",
+			output:   "json",
+			expected: "image/list/output.json",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				var buf bytes.Buffer
+
+				in := map[string]interface{}{
+					OptionApp:            appMock,
+					OptionEnvName:        "default",
+					OptionComponentNames: []string{},
+					OptionOutput:         tc.output,
+					OptionOut:            &buf,
+				}
+
+				il, err := newImageList(in, func(il *ImageList) {
+					il.objectsFn = imageObjects
+					il.paramsFn = imageParams
+				})
+				require.NoError(t, err)
+
+				require.NoError(t, il.run())
+
+				assertOutput(t, tc.expected, buf.String())
+			})
+		})
+	}
+}
+
+func TestImageList_invalid_output(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionApp:            appMock,
+			OptionEnvName:        "default",
+			OptionComponentNames: []string{},
+			OptionOutput:         "xml",
+		}
+
+		il, err := newImageList(in)
+		require.NoError(t, err)
+
+		assert.Error(t, il.run())
+	})
+}
@@ -0,0 +1,170 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"io"
+	"os"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/image"
+	"github.com/ksonnet/ksonnet/pkg/util/dockerregistry"
+	"github.com/ksonnet/ksonnet/pkg/util/table"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// outdatedImage is an image with a newer tag in its repository.
+type outdatedImage struct {
+	image.Reference
+	Latest string `json:"latest"`
+}
+
+// RunImageOutdated runs `image outdated`.
+func RunImageOutdated(m map[string]interface{}) error {
+	var o ImageOutdatedOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunImageOutdatedWithOptions(o)
+}
+
+// RunImageOutdatedWithOptions runs `image outdated` with typed options.
+func RunImageOutdatedWithOptions(o ImageOutdatedOptions) error {
+	od, err := newImageOutdatedWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return od.run()
+}
+
+type imageOutdatedOpt func(*ImageOutdated)
+
+// ImageOutdated lists the images of an environment's components which have
+// newer tags in their registries.
+type ImageOutdated struct {
+	app            app.App
+	envName        string
+	componentNames []string
+	output         string
+
+	objectsFn componentObjectsFn
+	paramsFn  imageParamsFn
+	tagsFn    func(image string) ([]string, error)
+	out       io.Writer
+}
+
+// ImageOutdatedOptions are the options for ImageOutdated.
+type ImageOutdatedOptions struct {
+	App            app.App   `option:"app"`
+	EnvName        string    `option:"env-name,optional"`
+	ComponentNames []string  `option:"component-names"`
+	Output         string    `option:"output,optional"`
+	Out            io.Writer `option:"out,optional"`
+}
+
+func newImageOutdated(m map[string]interface{}, opts ...imageOutdatedOpt) (*ImageOutdated, error) {
+	var o ImageOutdatedOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return newImageOutdatedWithOptions(o, opts...)
+}
+
+func newImageOutdatedWithOptions(o ImageOutdatedOptions, opts ...imageOutdatedOpt) (*ImageOutdated, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	od := &ImageOutdated{
+		app:            o.App,
+		componentNames: o.ComponentNames,
+		output:         o.Output,
+
+		objectsFn: componentObjects,
+		paramsFn:  componentStringParams,
+		tagsFn:    dockerregistry.ImageTags,
+		out:       os.Stdout,
+	}
+
+	if o.Out != nil {
+		od.out = o.Out
+	}
+
+	for _, opt := range opts {
+		opt(od)
+	}
+
+	if err := setCurrentEnv(od.app, od, o.EnvName); err != nil {
+		return nil, err
+	}
+
+	return od, nil
+}
+
+func (od *ImageOutdated) setCurrentEnv(name string) {
+	od.envName = name
+}
+
+func (od *ImageOutdated) run() error {
+	f, err := table.DetectFormat(od.output)
+	if err != nil {
+		return errors.Wrap(err, "detecting output format")
+	}
+
+	refs, err := findImages(od.app, od.envName, od.componentNames, od.objectsFn, od.paramsFn)
+	if err != nil {
+		return err
+	}
+
+	outdated := []outdatedImage{}
+	tags := make(map[string][]string)
+
+	for _, ref := range refs {
+		parsed := image.ParseReference(ref.Image)
+		if parsed.Tag == "" {
+			continue
+		}
+
+		repoTags, ok := tags[parsed.Repository]
+		if !ok {
+			if repoTags, err = od.tagsFn(ref.Image); err != nil {
+				log.Warnf("unable to check %s for newer tags: %v", ref.Image, err)
+			}
+			tags[parsed.Repository] = repoTags
+		}
+
+		if latest, ok := image.Latest(parsed.Tag, repoTags); ok {
+			outdated = append(outdated, outdatedImage{Reference: ref, Latest: latest})
+		}
+	}
+
+	if f != table.FormatTable {
+		return table.Encode(od.out, f, outdated)
+	}
+
+	t := table.New("imageOutdated", od.out)
+	t.SetHeader([]string{"component", "object", "container", "image", "latest", "param"})
+
+	for _, o := range outdated {
+		t.Append([]string{o.Component, o.Object(), o.Container, o.Image, o.Latest, o.Param})
+	}
+
+	return t.Render()
+}
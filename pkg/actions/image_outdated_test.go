@@ -0,0 +1,98 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"testing"
+
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func imageTags(image string) ([]string, error) {
+	switch image {
+	case "gcr.io/heptio-images/guestbook:0.1":
+		return []string{"0.1", "0.2", "0.3", "latest"}, nil
+	case "envoyproxy/envoy:v1.8.0":
+		return []string{"v1.7.0", "v1.8.0", "v1.9.0", "v1.9.0-dev"}, nil
+	default:
+		return nil, errors.Errorf("unexpected image %s", image)
+	}
+}
+
+func TestImageOutdated(t *testing.T) {
+	cases := []struct {
+		name     string
+		output   string
+		expected string
+	}{
+		{
+			name:     "table",
+			expected: "image/outdated/table.txt",
+		},
+		{
+			name:     "json",
+			output:   "json",
+			expected: "image/outdated/output.json",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				var buf bytes.Buffer
+
+				in := map[string]interface{}{
+					OptionApp:            appMock,
+					OptionEnvName:        "default",
+					OptionComponentNames: []string{},
+					OptionOutput:         tc.output,
+					OptionOut:            &buf,
+				}
+
+				od, err := newImageOutdated(in, func(od *ImageOutdated) {
+					od.objectsFn = imageObjects
+					od.paramsFn = imageParams
+					od.tagsFn = imageTags
+				})
+				require.NoError(t, err)
+
+				require.NoError(t, od.run())
+
+				assertOutput(t, tc.expected, buf.String())
+			})
+		})
+	}
+}
+
+func TestImageOutdated_invalid_output(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionApp:            appMock,
+			OptionEnvName:        "default",
+			OptionComponentNames: []string{},
+			OptionOutput:         "xml",
+		}
+
+		od, err := newImageOutdated(in)
+		require.NoError(t, err)
+
+		assert.Error(t, od.run())
+	})
+}
@@ -0,0 +1,137 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	"github.com/ksonnet/ksonnet/pkg/image"
+	"github.com/ksonnet/ksonnet/pkg/util/dockerregistry"
+	log "github.com/sirupsen/logrus"
+)
+
+// RunImagePin runs `image pin`.
+func RunImagePin(m map[string]interface{}) error {
+	var o ImagePinOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunImagePinWithOptions(o)
+}
+
+// RunImagePinWithOptions runs `image pin` with typed options.
+func RunImagePinWithOptions(o ImagePinOptions) error {
+	ip, err := newImagePinWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return ip.run()
+}
+
+type imagePinOpt func(*ImagePin)
+
+// ImagePin pins the images of components to the digests of their tags, by
+// setting the parameters which hold them.
+type ImagePin struct {
+	app            app.App
+	componentNames []string
+	// envName is the environment to set the parameters in. If it is empty,
+	// the components' parameters are set.
+	envName string
+
+	objectsFn      componentObjectsFn
+	paramsFn       imageParamsFn
+	resolvePathFn  func(a app.App, path string) (component.Module, component.Component, error)
+	setEnvFn       func(ksApp app.App, envName, name, pName, value string) error
+	resolveImageFn func(image string) (string, error)
+}
+
+// ImagePinOptions are the options for ImagePin.
+type ImagePinOptions struct {
+	App            app.App  `option:"app"`
+	ComponentNames []string `option:"component-names"`
+	// EnvName is the environment to set the parameters in. If it is empty,
+	// the components' parameters are set.
+	EnvName string `option:"env-name,optional"`
+}
+
+func newImagePin(m map[string]interface{}, opts ...imagePinOpt) (*ImagePin, error) {
+	var o ImagePinOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return newImagePinWithOptions(o, opts...)
+}
+
+func newImagePinWithOptions(o ImagePinOptions, opts ...imagePinOpt) (*ImagePin, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	ip := &ImagePin{
+		app:            o.App,
+		componentNames: o.ComponentNames,
+		envName:        o.EnvName,
+
+		objectsFn:      componentObjects,
+		paramsFn:       componentStringParams,
+		resolvePathFn:  component.ResolvePath,
+		setEnvFn:       setEnv,
+		resolveImageFn: dockerregistry.ResolveImage,
+	}
+
+	for _, opt := range opts {
+		opt(ip)
+	}
+
+	return ip, nil
+}
+
+func (ip *ImagePin) run() error {
+	renderEnv, err := imageRenderEnv(ip.app, ip.envName)
+	if err != nil {
+		return err
+	}
+
+	refs, err := findImages(ip.app, renderEnv, ip.componentNames, ip.objectsFn, ip.paramsFn)
+	if err != nil {
+		return err
+	}
+
+	for _, ref := range refs {
+		if image.ParseReference(ref.Image).Digest != "" {
+			continue
+		}
+		if err := image.CanPin(ref); err != nil {
+			log.Warnf("skipping image: %v", err)
+		}
+	}
+
+	updates, err := image.Pins(refs, ip.resolveImageFn)
+	if err != nil {
+		return err
+	}
+
+	if len(updates) == 0 {
+		log.Info("no images to pin")
+		return nil
+	}
+
+	return setImageParams(ip.app, ip.envName, updates, ip.resolvePathFn, ip.setEnvFn)
+}
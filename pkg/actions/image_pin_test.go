@@ -0,0 +1,86 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	cmocks "github.com/ksonnet/ksonnet/pkg/component/mocks"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImagePin(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("CurrentEnvironment").Return("default")
+
+		c := &cmocks.Component{}
+		c.On("SetParam", []string{"image"}, "gcr.io/heptio-images/guestbook@sha256:abc").Return(nil)
+
+		in := map[string]interface{}{
+			OptionApp:            appMock,
+			OptionComponentNames: []string{},
+		}
+
+		ip, err := newImagePin(in, func(ip *ImagePin) {
+			ip.objectsFn = imageObjects
+			ip.paramsFn = imageParams
+			ip.resolveImageFn = func(image string) (string, error) {
+				// The envoy image is not set by a parameter, so only the
+				// guestbook image is resolved.
+				assert.Equal(t, "gcr.io/heptio-images/guestbook:0.1", image)
+				return "gcr.io/heptio-images/guestbook@sha256:abc", nil
+			}
+			ip.resolvePathFn = func(a app.App, path string) (component.Module, component.Component, error) {
+				assert.Equal(t, "guestbook", path)
+				return nil, c, nil
+			}
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, ip.run())
+		c.AssertExpectations(t)
+	})
+}
+
+func TestImagePin_resolve_error(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionApp:            appMock,
+			OptionComponentNames: []string{},
+			OptionEnvName:        "prod",
+		}
+
+		ip, err := newImagePin(in, func(ip *ImagePin) {
+			ip.objectsFn = imageObjects
+			ip.paramsFn = imageParams
+			ip.resolveImageFn = func(image string) (string, error) {
+				return "", errors.New("unauthorized")
+			}
+			ip.setEnvFn = func(ksApp app.App, envName, name, pName, value string) error {
+				t.Fatalf("unexpected set of %s", pName)
+				return nil
+			}
+		})
+		require.NoError(t, err)
+
+		require.Error(t, ip.run())
+	})
+}
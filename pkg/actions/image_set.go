@@ -0,0 +1,190 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	"github.com/ksonnet/ksonnet/pkg/image"
+	"github.com/ksonnet/ksonnet/pkg/util/dockerregistry"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// RunImageSet runs `image set`.
+func RunImageSet(m map[string]interface{}) error {
+	var o ImageSetOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunImageSetWithOptions(o)
+}
+
+// RunImageSetWithOptions runs `image set` with typed options.
+func RunImageSetWithOptions(o ImageSetOptions) error {
+	is, err := newImageSetWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return is.run()
+}
+
+type imageSetOpt func(*ImageSet)
+
+// ImageSet sets the image of a component by setting the parameters which
+// hold it.
+type ImageSet struct {
+	app           app.App
+	componentName string
+	image         string
+	// envName is the environment to set the parameters in. If it is empty,
+	// the component's parameters are set.
+	envName string
+	// pin resolves the image's tag to a digest before it is set.
+	pin bool
+
+	objectsFn      componentObjectsFn
+	paramsFn       imageParamsFn
+	resolvePathFn  func(a app.App, path string) (component.Module, component.Component, error)
+	setEnvFn       func(ksApp app.App, envName, name, pName, value string) error
+	resolveImageFn func(image string) (string, error)
+}
+
+// ImageSetOptions are the options for ImageSet.
+type ImageSetOptions struct {
+	App           app.App `option:"app"`
+	ComponentName string  `option:"component-name"`
+	Image         string  `option:"image"`
+	// EnvName is the environment to set the parameters in. If it is empty,
+	// the component's parameters are set.
+	EnvName string `option:"env-name,optional"`
+	// ResolveImage resolves the image's tag to a digest before it is set.
+	ResolveImage bool `option:"resolve-image,optional"`
+}
+
+func newImageSet(m map[string]interface{}, opts ...imageSetOpt) (*ImageSet, error) {
+	var o ImageSetOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return newImageSetWithOptions(o, opts...)
+}
+
+func newImageSetWithOptions(o ImageSetOptions, opts ...imageSetOpt) (*ImageSet, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	is := &ImageSet{
+		app:           o.App,
+		componentName: o.ComponentName,
+		image:         o.Image,
+		envName:       o.EnvName,
+		pin:           o.ResolveImage,
+
+		objectsFn:      componentObjects,
+		paramsFn:       componentStringParams,
+		resolvePathFn:  component.ResolvePath,
+		setEnvFn:       setEnv,
+		resolveImageFn: dockerregistry.ResolveImage,
+	}
+
+	for _, opt := range opts {
+		opt(is)
+	}
+
+	return is, nil
+}
+
+func (is *ImageSet) run() error {
+	renderEnv, err := imageRenderEnv(is.app, is.envName)
+	if err != nil {
+		return err
+	}
+
+	refs, err := findImages(is.app, renderEnv, []string{is.componentName}, is.objectsFn, is.paramsFn)
+	if err != nil {
+		return err
+	}
+
+	value := is.image
+	if is.pin && image.ParseReference(value).Digest == "" {
+		if value, err = is.resolveImageFn(value); err != nil {
+			return errors.Wrapf(err, "resolving digest of %s", is.image)
+		}
+	}
+
+	updates, err := image.Updates(refs, is.componentName, value)
+	if err != nil {
+		return err
+	}
+
+	return setImageParams(is.app, is.envName, updates, is.resolvePathFn, is.setEnvFn)
+}
+
+// imageRenderEnv returns the environment components are rendered in to find
+// their images. The parameters are the same in every environment, unless
+// they are overridden by the environment, so the current environment is used
+// if envName is empty.
+func imageRenderEnv(a app.App, envName string) (string, error) {
+	if envName == "" {
+		envName = a.CurrentEnvironment()
+	}
+	if envName == "" {
+		return "", errors.New("environment is not set; use --env or set the current environment to find the component's images")
+	}
+
+	return envName, nil
+}
+
+// setImageParams sets the parameters which hold images. They are set in
+// envName, or in the components if envName is empty.
+func setImageParams(a app.App, envName string, updates []image.Update,
+	resolvePathFn func(a app.App, path string) (component.Module, component.Component, error),
+	setEnvFn func(ksApp app.App, envName, name, pName, value string) error) error {
+	for _, u := range updates {
+		var err error
+		if envName != "" {
+			err = setEnvFn(a, envName, u.Component, u.Param, u.Value)
+		} else {
+			err = setComponentParam(a, u, resolvePathFn)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "setting parameter %s of component %s", u.Param, u.Component)
+		}
+
+		log.Infof("set parameter %s of component %s to %s", u.Param, u.Component, u.Value)
+	}
+
+	return nil
+}
+
+func setComponentParam(a app.App, u image.Update,
+	resolvePathFn func(a app.App, path string) (component.Module, component.Component, error)) error {
+	_, c, err := resolvePathFn(a, u.Component)
+	if err != nil {
+		return errors.Wrap(err, "could not find component")
+	}
+
+	if c == nil {
+		return errors.Errorf("unable to find component %s", u.Component)
+	}
+
+	return c.SetParam([]string{u.Param}, u.Value)
+}
@@ -0,0 +1,151 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	cmocks "github.com/ksonnet/ksonnet/pkg/component/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestImageSet(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("CurrentEnvironment").Return("default")
+
+		c := &cmocks.Component{}
+		c.On("SetParam", []string{"image"}, "gcr.io/heptio-images/guestbook:0.2").Return(nil)
+
+		in := map[string]interface{}{
+			OptionApp:           appMock,
+			OptionComponentName: "guestbook",
+			OptionImage:         "gcr.io/heptio-images/guestbook:0.2",
+		}
+
+		is, err := newImageSet(in, func(is *ImageSet) {
+			is.objectsFn = imageObjects
+			is.paramsFn = imageParams
+			is.resolvePathFn = func(a app.App, path string) (component.Module, component.Component, error) {
+				assert.Equal(t, "guestbook", path)
+				return nil, c, nil
+			}
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, is.run())
+		c.AssertExpectations(t)
+	})
+}
+
+func TestImageSet_env(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionApp:           appMock,
+			OptionComponentName: "guestbook",
+			OptionImage:         "gcr.io/heptio-images/guestbook@sha256:abc",
+			OptionEnvName:       "prod",
+		}
+
+		var set []string
+		is, err := newImageSet(in, func(is *ImageSet) {
+			is.objectsFn = func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+				assert.Equal(t, "prod", envName)
+				assert.Equal(t, []string{"guestbook"}, componentNames)
+				return imageObjects(a, envName, componentNames)
+			}
+			is.paramsFn = imageParams
+			is.setEnvFn = func(ksApp app.App, envName, name, pName, value string) error {
+				set = append(set, envName, name, pName, value)
+				return nil
+			}
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, is.run())
+		assert.Equal(t, []string{"prod", "guestbook", "image", "gcr.io/heptio-images/guestbook@sha256:abc"}, set)
+	})
+}
+
+func TestImageSet_not_parameterized(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionApp:           appMock,
+			OptionComponentName: "guestbook",
+			OptionImage:         "envoyproxy/envoy:v1.9.0",
+			OptionEnvName:       "prod",
+		}
+
+		is, err := newImageSet(in, func(is *ImageSet) {
+			is.objectsFn = imageObjects
+			is.paramsFn = imageParams
+		})
+		require.NoError(t, err)
+
+		require.Error(t, is.run())
+	})
+}
+
+func TestImageSet_requires_env(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("CurrentEnvironment").Return("")
+
+		in := map[string]interface{}{
+			OptionApp:           appMock,
+			OptionComponentName: "guestbook",
+			OptionImage:         "nginx:1.25",
+		}
+
+		is, err := newImageSet(in)
+		require.NoError(t, err)
+
+		require.Error(t, is.run())
+	})
+}
+
+func TestImageSet_pin(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionApp:           appMock,
+			OptionComponentName: "guestbook",
+			OptionImage:         "gcr.io/heptio-images/guestbook:0.2",
+			OptionEnvName:       "prod",
+			OptionResolveImage:  true,
+		}
+
+		var set []string
+		is, err := newImageSet(in, func(is *ImageSet) {
+			is.objectsFn = imageObjects
+			is.paramsFn = imageParams
+			is.resolveImageFn = func(image string) (string, error) {
+				assert.Equal(t, "gcr.io/heptio-images/guestbook:0.2", image)
+				return "gcr.io/heptio-images/guestbook@sha256:abc", nil
+			}
+			is.setEnvFn = func(ksApp app.App, envName, name, pName, value string) error {
+				set = append(set, pName, value)
+				return nil
+			}
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, is.run())
+		assert.Equal(t, []string{"image", "gcr.io/heptio-images/guestbook@sha256:abc"}, set)
+	})
+}
@@ -40,7 +40,17 @@ import (
 
 // RunImport runs `import`
 func RunImport(m map[string]interface{}) error {
-	i, err := NewImport(m)
+	var o ImportOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunImportWithOptions(o)
+}
+
+// RunImportWithOptions runs `import` with typed options.
+func RunImportWithOptions(o ImportOptions) error {
+	i, err := NewImportWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -57,21 +67,37 @@ type Import struct {
 	createComponentFn func(a app.App, module, name, text string, p params.Params, templateType prototype.TemplateType) (string, error)
 }
 
-// NewImport creates an instance of Import. `module` is the name of the component and
-// entity is the file or directory to import.
+// ImportOptions are the options for Import.
+type ImportOptions struct {
+	App    app.App `option:"app"`
+	Module string  `option:"module"`
+	// Path is the file or directory to import.
+	Path string `option:"path"`
+}
+
+// NewImport creates an instance of Import from an option map. `module` is the
+// name of the component and entity is the file or directory to import.
 func NewImport(m map[string]interface{}) (*Import, error) {
-	ol := newOptionLoader(m)
+	var o ImportOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
 
-	i := &Import{
-		app:    ol.LoadApp(),
-		module: ol.LoadString(OptionModule),
-		path:   ol.LoadString(OptionPath),
+	return NewImportWithOptions(o)
+}
 
-		createComponentFn: component.Create,
+// NewImportWithOptions creates an instance of Import.
+func NewImportWithOptions(o ImportOptions) (*Import, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
 	}
 
-	if ol.err != nil {
-		return nil, ol.err
+	i := &Import{
+		app:    o.App,
+		module: o.Module,
+		path:   o.Path,
+
+		createComponentFn: component.Create,
 	}
 
 	return i, nil
@@ -18,10 +18,16 @@ package actions
 import (
 	"net/http"
 
+	"github.com/ksonnet/ksonnet/metadata/params"
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/appinit"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	"github.com/ksonnet/ksonnet/pkg/env"
+	"github.com/ksonnet/ksonnet/pkg/prototype"
 	"github.com/ksonnet/ksonnet/pkg/registry"
 	"github.com/ksonnet/ksonnet/pkg/util/github"
+	"github.com/pkg/errors"
 	"github.com/spf13/afero"
 )
 
@@ -32,7 +38,17 @@ const (
 
 // RunInit initializes an app.
 func RunInit(m map[string]interface{}) error {
-	i, err := NewInit(m)
+	var o InitOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunInitWithOptions(o)
+}
+
+// RunInitWithOptions initializes an app with typed options.
+func RunInitWithOptions(o InitOptions) error {
+	i, err := NewInitWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -56,37 +72,92 @@ type Init struct {
 	serverURI             string
 	namespace             string
 	skipDefaultRegistries bool
+	fromCluster           bool
+	contexts              []string
+	importKinds           []string
+	importSelector        string
+	clientConfig          *client.Config
 
 	appInitFn       appInitFn
 	appLoadFn       appLoadFn
 	initIncubatorFn initIncubatorFn
 
+	kubeContextsFn    func() ([]string, error)
+	resolveContextFn  func(context string) (server, namespace string, err error)
+	apiSpecFn         func(context string) string
+	envCreateFn       func(a app.App, d env.Destination, name, k8sSpecFlag string, overrideData, paramsData []byte, isOverride bool) error
+	clusterObjectsFn  clusterObjectsFn
+	createComponentFn func(a app.App, module, name, text string, p params.Params, templateType prototype.TemplateType) (string, error)
+
 	httpClient *http.Client
 }
 
-// NewInit creates an instance of Init.
+// InitOptions are the options for Init.
+type InitOptions struct {
+	Fs                    afero.Fs `option:"fs"`
+	Name                  string   `option:"name"`
+	RootPath              string   `option:"root-path"`
+	EnvName               string   `option:"env-name"`
+	SpecFlag              string   `option:"spec-flag"`
+	ServerURI             string   `option:"server,optional"`
+	Namespace             string   `option:"namespace"`
+	SkipDefaultRegistries bool     `option:"skip-default-registries"`
+	TLSSkipVerify         bool     `option:"tls-skip-verify,optional"`
+	// FromCluster creates an environment for each kubeconfig context in
+	// Contexts, or for every context if it is empty.
+	FromCluster bool `option:"from-cluster,optional"`
+	// Contexts are kubeconfig contexts, optionally followed by =<namespace>.
+	Contexts []string `option:"contexts,optional"`
+	// ImportKinds are the kinds of objects imported as components from the
+	// first environment's namespace.
+	ImportKinds    []string       `option:"import-kinds,optional"`
+	ImportSelector string         `option:"import-selector,optional"`
+	ClientConfig   *client.Config `option:"client-config"`
+}
+
+// NewInit creates an instance of Init from an option map.
 func NewInit(m map[string]interface{}) (*Init, error) {
-	ol := newOptionLoader(m)
+	var o InitOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return NewInitWithOptions(o)
+}
+
+// NewInitWithOptions creates an instance of Init.
+func NewInitWithOptions(o InitOptions) (*Init, error) {
+	clientConfig := o.ClientConfig
 
 	i := &Init{
-		fs:                    ol.LoadFs(OptionFs),
-		name:                  ol.LoadString(OptionName),
-		rootPath:              ol.LoadString(OptionRootPath),
-		envName:               ol.LoadString(OptionEnvName),
-		k8sSpecFlag:           ol.LoadString(OptionSpecFlag),
-		serverURI:             ol.LoadOptionalString(OptionServer),
-		namespace:             ol.LoadString(OptionNamespace),
-		skipDefaultRegistries: ol.LoadBool(OptionSkipDefaultRegistries),
+		fs:                    o.Fs,
+		name:                  o.Name,
+		rootPath:              o.RootPath,
+		envName:               o.EnvName,
+		k8sSpecFlag:           o.SpecFlag,
+		serverURI:             o.ServerURI,
+		namespace:             o.Namespace,
+		skipDefaultRegistries: o.SkipDefaultRegistries,
+		fromCluster:           o.FromCluster,
+		contexts:              o.Contexts,
+		importKinds:           o.ImportKinds,
+		importSelector:        o.ImportSelector,
+		clientConfig:          clientConfig,
 
 		appInitFn:       appinit.Init,
 		appLoadFn:       app.Load,
 		initIncubatorFn: initIncubator,
 
-		httpClient: ol.LoadHTTPClient(),
-	}
+		kubeContextsFn:   func() ([]string, error) { return kubeContexts(clientConfig) },
+		resolveContextFn: clientConfig.ResolveContext,
+		apiSpecFn: func(context string) string {
+			return clientConfig.ForDestination(app.EnvironmentDestinationSpec{Context: context}).GetAPISpec()
+		},
+		envCreateFn:       env.Create,
+		clusterObjectsFn:  listClusterObjects,
+		createComponentFn: component.Create,
 
-	if ol.err != nil {
-		return nil, ol.err
+		httpClient: newHTTPClient(o.TLSSkipVerify),
 	}
 
 	return i, nil
@@ -94,6 +165,20 @@ func NewInit(m map[string]interface{}) (*Init, error) {
 
 // Run runs that ns create action.
 func (i *Init) Run() error {
+	if !i.fromCluster && (len(i.contexts) > 0 || len(i.importKinds) > 0) {
+		return errors.New("contexts and imports can only be used when initializing from a cluster")
+	}
+
+	serverURI := i.serverURI
+	if i.fromCluster {
+		if i.envName != "" {
+			return errors.New("environments are named after their contexts when initializing from a cluster")
+		}
+
+		// Environments are created for the contexts once the app exists.
+		serverURI = ""
+	}
+
 	var registries []registry.Registry
 
 	if !i.skipDefaultRegistries {
@@ -110,17 +195,22 @@ func (i *Init) Run() error {
 		registries = append(registries, gh)
 	}
 
-	return i.appInitFn(
+	err := i.appInitFn(
 		i.fs,
 		i.httpClient,
 		i.name,
 		i.rootPath,
 		i.envName,
 		i.k8sSpecFlag,
-		i.serverURI,
+		serverURI,
 		i.namespace,
 		registries,
 	)
+	if err != nil || !i.fromCluster {
+		return err
+	}
+
+	return i.initFromCluster()
 }
 
 func initIncubator(a app.App, httpClient *http.Client) (registry.Registry, error) {
@@ -0,0 +1,295 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/ksonnet/ksonnet/metadata/params"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/cluster"
+	"github.com/ksonnet/ksonnet/pkg/env"
+	"github.com/ksonnet/ksonnet/pkg/prototype"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	// reEnvNameInvalid matches the characters of a context name which are
+	// not allowed in environment names.
+	reEnvNameInvalid = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+type clusterObjectsFn func(a app.App, clientConfig *client.Config, t clusterTarget, kinds []string, selector string) ([]*unstructured.Unstructured, error)
+
+// clusterTarget is a kubeconfig context, and the namespace an environment
+// created for it deploys to.
+type clusterTarget struct {
+	context   string
+	namespace string
+	envName   string
+}
+
+// parseClusterTarget parses a context, optionally followed by =<namespace>.
+// The environment is named after the context and namespace.
+func parseClusterTarget(s string) (clusterTarget, error) {
+	parts := strings.SplitN(s, "=", 2)
+
+	t := clusterTarget{context: parts[0]}
+	if len(parts) == 2 {
+		t.namespace = parts[1]
+	}
+
+	if t.context == "" || (len(parts) == 2 && t.namespace == "") {
+		return clusterTarget{}, errors.Errorf("invalid context %q; use <context> or <context>=<namespace>", s)
+	}
+
+	name := t.context
+	if t.namespace != "" {
+		name += "-" + t.namespace
+	}
+	t.envName = strings.Trim(reEnvNameInvalid.ReplaceAllString(name, "-"), "-")
+
+	return t, nil
+}
+
+// initFromCluster creates an environment for each selected kubeconfig
+// context, with a ksonnet-lib for the Kubernetes version of its cluster, and
+// imports the selected objects in the first environment's namespace as
+// components.
+func (i *Init) initFromCluster() error {
+	targets, err := i.clusterTargets()
+	if err != nil {
+		return err
+	}
+
+	a, err := i.appLoadFn(i.fs, i.httpClient, i.rootPath, false)
+	if err != nil {
+		return err
+	}
+
+	for n, t := range targets {
+		server, namespace, err := i.resolveContextFn(t.context)
+		if err != nil {
+			return err
+		}
+
+		if t.namespace == "" {
+			t.namespace = namespace
+		}
+		if t.namespace == "" {
+			t.namespace = metav1.NamespaceDefault
+		}
+		targets[n] = t
+
+		specFlag := i.k8sSpecFlag
+		if specFlag == "" {
+			specFlag = i.apiSpecFn(t.context)
+		}
+
+		log.Infof("Creating environment %q for context %q with %s", t.envName, t.context, specFlag)
+
+		d := env.NewDestination(server, t.namespace)
+		err = i.envCreateFn(a, d, t.envName, specFlag, env.DefaultOverrideData, env.DefaultParamsData, false)
+		if err != nil {
+			return errors.Wrapf(err, "creating environment for context %q", t.context)
+		}
+	}
+
+	if len(i.importKinds) == 0 {
+		return nil
+	}
+
+	objects, err := i.clusterObjectsFn(a, i.clientConfig, targets[0], i.importKinds, i.importSelector)
+	if err != nil {
+		return errors.Wrapf(err, "importing objects from context %q", targets[0].context)
+	}
+
+	return i.importObjects(a, objects)
+}
+
+// clusterTargets returns the selected contexts, or every context in the
+// kubeconfig if none are selected.
+func (i *Init) clusterTargets() ([]clusterTarget, error) {
+	contexts := i.contexts
+	if len(contexts) == 0 {
+		var err error
+		contexts, err = i.kubeContextsFn()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(contexts) == 0 {
+		return nil, errors.New("no contexts found. Make sure a kubeconfig file is present")
+	}
+
+	var targets []clusterTarget
+	seen := make(map[string]string)
+	for _, s := range contexts {
+		t, err := parseClusterTarget(s)
+		if err != nil {
+			return nil, err
+		}
+
+		if other, ok := seen[t.envName]; ok {
+			return nil, errors.Errorf("contexts %q and %q would both create environment %q", other, s, t.envName)
+		}
+		seen[t.envName] = s
+
+		targets = append(targets, t)
+	}
+
+	return targets, nil
+}
+
+// importObjects creates a YAML component for each object. Objects owned by
+// other objects are left out, since their owners create them.
+func (i *Init) importObjects(a app.App, objects []*unstructured.Unstructured) error {
+	for _, obj := range objects {
+		if len(obj.GetOwnerReferences()) > 0 {
+			log.Debugf("Skipping %s %s, which is owned by another object", obj.GetKind(), obj.GetName())
+			continue
+		}
+
+		obj = obj.DeepCopy()
+		cluster.StripServerFields(obj)
+		// Objects are deployed to the namespace of each environment.
+		unstructured.RemoveNestedField(obj.Object, "metadata", "namespace")
+		if obj.GetKind() == "Service" {
+			if ip, _, _ := unstructured.NestedString(obj.Object, "spec", "clusterIP"); ip != "None" {
+				unstructured.RemoveNestedField(obj.Object, "spec", "clusterIP")
+			}
+		}
+
+		data, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return err
+		}
+
+		name := importedComponentName(obj)
+		if _, err = i.createComponentFn(a, "", name, string(data), params.Params{}, prototype.YAML); err != nil {
+			return errors.Wrapf(err, "creating component for %s %s", obj.GetKind(), obj.GetName())
+		}
+
+		log.Infof("Imported %s %s as component %q", obj.GetKind(), obj.GetName(), name)
+	}
+
+	return nil
+}
+
+// importedComponentName names the component of an imported object after its
+// kind and name.
+func importedComponentName(obj *unstructured.Unstructured) string {
+	name := fmt.Sprintf("%s-%s", strings.ToLower(obj.GetKind()), obj.GetName())
+	return strings.Trim(reEnvNameInvalid.ReplaceAllString(strings.Replace(name, ".", "-", -1), "-"), "-")
+}
+
+// kubeContexts returns the names of the contexts in the kubeconfig.
+func kubeContexts(clientConfig *client.Config) ([]string, error) {
+	rawConfig, err := clientConfig.Config.RawConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	var contexts []string
+	for name := range rawConfig.Contexts {
+		contexts = append(contexts, name)
+	}
+	sort.Strings(contexts)
+
+	return contexts, nil
+}
+
+// listClusterObjects lists the objects of the selected kinds in a target's
+// namespace.
+func listClusterObjects(a app.App, clientConfig *client.Config, t clusterTarget, kinds []string, selector string) ([]*unstructured.Unstructured, error) {
+	destination := app.EnvironmentDestinationSpec{Context: t.context, Namespace: t.namespace}
+	envName := t.envName
+	pool, disc, _, err := clientConfig.ForDestination(destination).RestClient(a, &envName)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceLists, err := disc.ServerPreferredNamespacedResources()
+	if err != nil && len(resourceLists) == 0 {
+		return nil, errors.Wrap(err, "discovering resources")
+	}
+
+	var objects []*unstructured.Unstructured
+	for _, kind := range kinds {
+		gvk, resource, err := findAPIResource(resourceLists, kind)
+		if err != nil {
+			return nil, err
+		}
+
+		dynamic, err := pool.ClientForGroupVersionKind(gvk)
+		if err != nil {
+			return nil, errors.Wrapf(err, "creating client for resource: %s", gvk)
+		}
+
+		obj, err := dynamic.Resource(resource, t.namespace).List(metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing %s", resource.Name)
+		}
+
+		ul, ok := obj.(*unstructured.UnstructuredList)
+		if !ok {
+			return nil, errors.Errorf("unexpected list type %T", obj)
+		}
+
+		for n := range ul.Items {
+			objects = append(objects, &ul.Items[n])
+		}
+	}
+
+	return objects, nil
+}
+
+// findAPIResource finds a resource by its name, singular name,
+// kind or short name.
+func findAPIResource(resourceLists []*metav1.APIResourceList, name string) (schema.GroupVersionKind, *metav1.APIResource, error) {
+	for _, list := range resourceLists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+
+		for n := range list.APIResources {
+			r := list.APIResources[n]
+			if strings.Contains(r.Name, "/") {
+				continue
+			}
+
+			names := append([]string{r.Name, r.SingularName, r.Kind}, r.ShortNames...)
+			for _, candidate := range names {
+				if strings.EqualFold(candidate, name) {
+					return gv.WithKind(r.Kind), &r, nil
+				}
+			}
+		}
+	}
+
+	return schema.GroupVersionKind{}, nil, errors.Errorf("unknown resource %q", name)
+}
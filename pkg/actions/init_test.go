@@ -19,13 +19,18 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/ksonnet/ksonnet/metadata/params"
 	"github.com/ksonnet/ksonnet/pkg/app"
 	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/env"
+	"github.com/ksonnet/ksonnet/pkg/prototype"
 	"github.com/ksonnet/ksonnet/pkg/registry"
 	rmocks "github.com/ksonnet/ksonnet/pkg/registry/mocks"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 func TestInit(t *testing.T) {
@@ -67,6 +72,7 @@ func TestInit(t *testing.T) {
 					OptionNamespace:             aNamespace,
 					OptionSkipDefaultRegistries: tc.skipRegistries,
 					OptionTLSSkipVerify:         false,
+					OptionClientConfig:          &client.Config{},
 				}
 
 				a, err := NewInit(in)
@@ -114,3 +120,204 @@ func TestInit(t *testing.T) {
 
 	})
 }
+
+func TestInit_from_cluster(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionFs:                    appMock.Fs(),
+			OptionName:                  "my-app",
+			OptionRootPath:              appMock.Root(),
+			OptionEnvName:               "",
+			OptionSpecFlag:              "",
+			OptionServer:                "",
+			OptionNamespace:             "",
+			OptionSkipDefaultRegistries: true,
+			OptionFromCluster:           true,
+			OptionImportKinds:           []string{"deployments", "services"},
+			OptionImportSelector:        "app=guestbook",
+			OptionClientConfig:          &client.Config{},
+		}
+
+		a, err := NewInit(in)
+		require.NoError(t, err)
+
+		a.appInitFn = func(fs afero.Fs, httpClient *http.Client, name, rootPath, envName, k8sSpecFlag, serverURI, namespace string, registries []registry.Registry) error {
+			assert.Empty(t, envName)
+			assert.Empty(t, serverURI)
+			return nil
+		}
+
+		a.appLoadFn = func(fs afero.Fs, httpClient *http.Client, root string, skipFindRoot bool) (app.App, error) {
+			return appMock, nil
+		}
+
+		a.kubeContextsFn = func() ([]string, error) {
+			return []string{"dev", "gke_project_zone_prod"}, nil
+		}
+
+		a.resolveContextFn = func(context string) (string, string, error) {
+			if context == "dev" {
+				return "http://dev", "dev-ns", nil
+			}
+			return "http://prod", "", nil
+		}
+
+		a.apiSpecFn = func(context string) string {
+			return "version:v1.10.0"
+		}
+
+		created := make(map[string]env.Destination)
+		a.envCreateFn = func(a app.App, d env.Destination, name, k8sSpecFlag string, overrideData, paramsData []byte, isOverride bool) error {
+			assert.Equal(t, "version:v1.10.0", k8sSpecFlag)
+			created[name] = d
+			return nil
+		}
+
+		a.clusterObjectsFn = func(a app.App, clientConfig *client.Config, target clusterTarget, kinds []string, selector string) ([]*unstructured.Unstructured, error) {
+			assert.Equal(t, clusterTarget{context: "dev", namespace: "dev-ns", envName: "dev"}, target)
+			assert.Equal(t, []string{"deployments", "services"}, kinds)
+			assert.Equal(t, "app=guestbook", selector)
+
+			return []*unstructured.Unstructured{
+				{
+					Object: map[string]interface{}{
+						"apiVersion": "v1",
+						"kind":       "Service",
+						"metadata": map[string]interface{}{
+							"name":            "guestbook",
+							"namespace":       "dev-ns",
+							"resourceVersion": "1",
+						},
+						"spec": map[string]interface{}{
+							"clusterIP": "10.0.0.1",
+						},
+					},
+				},
+				{
+					Object: map[string]interface{}{
+						"apiVersion": "apps/v1",
+						"kind":       "ReplicaSet",
+						"metadata": map[string]interface{}{
+							"name": "guestbook-1234",
+							"ownerReferences": []interface{}{
+								map[string]interface{}{"kind": "Deployment", "name": "guestbook"},
+							},
+						},
+					},
+				},
+			}, nil
+		}
+
+		components := make(map[string]string)
+		a.createComponentFn = func(a app.App, module, name, text string, p params.Params, templateType prototype.TemplateType) (string, error) {
+			assert.Equal(t, prototype.YAML, templateType)
+			components[name] = text
+			return name, nil
+		}
+
+		err = a.Run()
+		require.NoError(t, err)
+
+		expectedEnvs := map[string]env.Destination{
+			"dev":                   env.NewDestination("http://dev", "dev-ns"),
+			"gke_project_zone_prod": env.NewDestination("http://prod", "default"),
+		}
+		assert.Equal(t, expectedEnvs, created)
+
+		expectedComponents := map[string]string{
+			"service-guestbook": "apiVersion: v1\nkind: Service\nmetadata:\n  name: guestbook\nspec: {}\n",
+		}
+		assert.Equal(t, expectedComponents, components)
+	})
+}
+
+func TestInit_from_cluster_invalid(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		cases := []struct {
+			name        string
+			fromCluster bool
+			envName     string
+			contexts    []string
+		}{
+			{
+				name:     "contexts without from cluster",
+				contexts: []string{"dev"},
+			},
+			{
+				name:        "env name with from cluster",
+				fromCluster: true,
+				envName:     "default",
+			},
+			{
+				name:        "invalid context",
+				fromCluster: true,
+				contexts:    []string{"dev="},
+			},
+			{
+				name:        "duplicate environment",
+				fromCluster: true,
+				contexts:    []string{"dev:1", "dev/1"},
+			},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				in := map[string]interface{}{
+					OptionFs:                    appMock.Fs(),
+					OptionName:                  "my-app",
+					OptionRootPath:              appMock.Root(),
+					OptionEnvName:               tc.envName,
+					OptionSpecFlag:              "version:v1.10.0",
+					OptionServer:                "",
+					OptionNamespace:             "",
+					OptionSkipDefaultRegistries: true,
+					OptionFromCluster:           tc.fromCluster,
+					OptionContexts:              tc.contexts,
+					OptionClientConfig:          &client.Config{},
+				}
+
+				a, err := NewInit(in)
+				require.NoError(t, err)
+
+				a.appInitFn = func(fs afero.Fs, httpClient *http.Client, name, rootPath, envName, k8sSpecFlag, serverURI, namespace string, registries []registry.Registry) error {
+					return nil
+				}
+
+				a.appLoadFn = func(fs afero.Fs, httpClient *http.Client, root string, skipFindRoot bool) (app.App, error) {
+					return appMock, nil
+				}
+
+				err = a.Run()
+				require.Error(t, err)
+			})
+		}
+	})
+}
+
+func Test_parseClusterTarget(t *testing.T) {
+	cases := []struct {
+		in       string
+		expected clusterTarget
+		isErr    bool
+	}{
+		{in: "dev", expected: clusterTarget{context: "dev", envName: "dev"}},
+		{in: "dev=web", expected: clusterTarget{context: "dev", namespace: "web", envName: "dev-web"}},
+		{in: "arn:aws:eks:cluster/prod", expected: clusterTarget{context: "arn:aws:eks:cluster/prod", envName: "arn-aws-eks-cluster-prod"}},
+		{in: "", isErr: true},
+		{in: "=web", isErr: true},
+		{in: "dev=", isErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.in, func(t *testing.T) {
+			got, err := parseClusterTarget(tc.in)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
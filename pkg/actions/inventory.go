@@ -0,0 +1,222 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	"github.com/ksonnet/ksonnet/pkg/inventory"
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/ksonnet/ksonnet/pkg/util/table"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+type componentSourcesFn func(a app.App, componentNames []string) (map[string]inventory.Source, error)
+
+// componentSources reads the files of components.
+func componentSources(a app.App, componentNames []string) (map[string]inventory.Source, error) {
+	sources := make(map[string]inventory.Source)
+
+	for _, name := range componentNames {
+		path, err := component.Path(a, name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "finding component %s", name)
+		}
+
+		data, err := afero.ReadFile(a.Fs(), path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading component %s", name)
+		}
+
+		rel, err := filepath.Rel(a.Root(), path)
+		if err != nil {
+			rel = path
+		}
+
+		sources[name] = inventory.Source{Path: filepath.ToSlash(rel), Data: data}
+	}
+
+	return sources, nil
+}
+
+// RunInventory runs `inventory`.
+func RunInventory(m map[string]interface{}) error {
+	var o InventoryOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunInventoryWithOptions(o)
+}
+
+// RunInventoryWithOptions runs `inventory` with typed options.
+func RunInventoryWithOptions(o InventoryOptions) error {
+	i, err := newInventoryWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return i.run()
+}
+
+type inventoryOpt func(*Inventory)
+
+// Inventory reports what an environment deploys.
+type Inventory struct {
+	app     app.App
+	envName string
+	output  string
+
+	objectsFn componentObjectsFn
+	sourcesFn componentSourcesFn
+	out       io.Writer
+}
+
+// InventoryOptions are the options for Inventory.
+type InventoryOptions struct {
+	App     app.App   `option:"app"`
+	EnvName string    `option:"env-name,optional"`
+	Output  string    `option:"output,optional"`
+	Out     io.Writer `option:"out,optional"`
+}
+
+func newInventory(m map[string]interface{}, opts ...inventoryOpt) (*Inventory, error) {
+	var o InventoryOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return newInventoryWithOptions(o, opts...)
+}
+
+func newInventoryWithOptions(o InventoryOptions, opts ...inventoryOpt) (*Inventory, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	i := &Inventory{
+		app:    o.App,
+		output: o.Output,
+
+		objectsFn: componentObjects,
+		sourcesFn: componentSources,
+		out:       os.Stdout,
+	}
+
+	if o.Out != nil {
+		i.out = o.Out
+	}
+
+	for _, opt := range opts {
+		opt(i)
+	}
+
+	if err := setCurrentEnv(i.app, i, o.EnvName); err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+func (i *Inventory) setCurrentEnv(name string) {
+	i.envName = name
+}
+
+func (i *Inventory) run() error {
+	f, err := table.DetectFormat(i.output)
+	if err != nil {
+		return errors.Wrap(err, "detecting output format")
+	}
+
+	env, err := i.app.Environment(i.envName)
+	if err != nil {
+		return err
+	}
+
+	libraries, err := i.app.Libraries()
+	if err != nil {
+		return errors.Wrap(err, "loading libraries")
+	}
+
+	objects, err := i.objectsFn(i.app, i.envName, nil)
+	if err != nil {
+		return errors.Wrapf(err, "rendering environment %s", i.envName)
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+	for _, obj := range objects {
+		name := obj.GetLabels()[metadata.LabelComponent]
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+
+	sources, err := i.sourcesFn(i.app, names)
+	if err != nil {
+		return err
+	}
+
+	report := inventory.New(env, libraries, objects, sources)
+
+	if f != table.FormatTable {
+		return table.Encode(i.out, f, report)
+	}
+
+	return i.renderTable(report)
+}
+
+func (i *Inventory) renderTable(report *inventory.Report) error {
+	prototypes := make(map[string]string)
+	for _, c := range report.Components {
+		prototypes[c.Name] = c.Prototype
+	}
+
+	t := table.New("inventory", i.out)
+	t.SetHeader([]string{"component", "prototype", "object", "namespace", "images"})
+
+	for _, r := range report.Resources {
+		t.Append([]string{r.Component, prototypes[r.Component], r.Kind + "/" + r.Name, r.Namespace, strings.Join(r.Images, ",")})
+	}
+
+	if err := t.Render(); err != nil {
+		return err
+	}
+
+	if len(report.Packages) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(i.out)
+
+	t = table.New("inventoryPackages", i.out)
+	t.SetHeader([]string{"package", "version", "components"})
+
+	for _, p := range report.Packages {
+		t.Append([]string{p.String(), p.Version, strings.Join(p.Components, ",")})
+	}
+
+	return t.Render()
+}
@@ -0,0 +1,128 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/inventory"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func inventorySources(a app.App, componentNames []string) (map[string]inventory.Source, error) {
+	sources := make(map[string]inventory.Source)
+	for _, name := range componentNames {
+		sources[name] = inventory.Source{
+			Path: "components/" + name + ".jsonnet",
+			Data: []byte(`// @prototype io.ksonnet.pkg.guestbook
+local guestbook = import "incubator/guestbook/guestbook.libsonnet";
+guestbook.parts.deployment(params)
+`),
+		}
+	}
+
+	return sources, nil
+}
+
+func TestInventory(t *testing.T) {
+	cases := []struct {
+		name     string
+		output   string
+		expected string
+	}{
+		{
+			name:     "table",
+			expected: "inventory/table.txt",
+		},
+		{
+			name:     "json",
+			output:   "json",
+			expected: "inventory/output.json",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				env := &app.EnvironmentConfig{Name: "prod", KubernetesVersion: "v1.10.0"}
+				appMock.On("Environment", "prod").Return(env, nil)
+
+				libraries := app.LibraryConfigs{
+					"guestbook": {Name: "guestbook", Registry: "incubator", Version: "0.2.0"},
+					"redis":     {Name: "redis", Registry: "incubator", Version: "0.1.0"},
+				}
+				appMock.On("Libraries").Return(libraries, nil)
+
+				var buf bytes.Buffer
+
+				in := map[string]interface{}{
+					OptionApp:     appMock,
+					OptionEnvName: "prod",
+					OptionOutput:  tc.output,
+					OptionOut:     &buf,
+				}
+
+				i, err := newInventory(in, func(i *Inventory) {
+					i.objectsFn = imageObjects
+					i.sourcesFn = inventorySources
+				})
+				require.NoError(t, err)
+
+				require.NoError(t, i.run())
+
+				assertOutput(t, tc.expected, buf.String())
+			})
+		})
+	}
+}
+
+func TestInventory_invalid_output(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionApp:     appMock,
+			OptionEnvName: "prod",
+			OptionOutput:  "xml",
+		}
+
+		i, err := newInventory(in)
+		require.NoError(t, err)
+
+		assert.Error(t, i.run())
+	})
+}
+
+func Test_componentSources(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		data := []byte("// @prototype io.ksonnet.pkg.guestbook\n{}\n")
+		require.NoError(t, afero.WriteFile(appMock.Fs(), "/components/guestbook.jsonnet", data, 0644))
+
+		sources, err := componentSources(appMock, []string{"guestbook"})
+		require.NoError(t, err)
+
+		expected := map[string]inventory.Source{
+			"guestbook": {Path: "components/guestbook.jsonnet", Data: data},
+		}
+		assert.Equal(t, expected, sources)
+
+		_, err = componentSources(appMock, []string{"missing"})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,107 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"sort"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/jb"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+type jbSyncFn func(fs afero.Fs, root string, reserved []string) ([]jb.Dependency, error)
+
+// RunJbSync runs `jb sync`.
+func RunJbSync(m map[string]interface{}) error {
+	var o JbSyncOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunJbSyncWithOptions(o)
+}
+
+// RunJbSyncWithOptions runs `jb sync` with typed options.
+func RunJbSyncWithOptions(o JbSyncOptions) error {
+	js, err := newJbSyncWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return js.run()
+}
+
+type jbSyncOpt func(*JbSync)
+
+// JbSync installs the jsonnet-bundler dependencies of an app.
+type JbSync struct {
+	app app.App
+
+	syncFn jbSyncFn
+}
+
+// JbSyncOptions are the options for JbSync.
+type JbSyncOptions struct {
+	App app.App `option:"app"`
+}
+
+func newJbSync(m map[string]interface{}, opts ...jbSyncOpt) (*JbSync, error) {
+	var o JbSyncOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return newJbSyncWithOptions(o, opts...)
+}
+
+func newJbSyncWithOptions(o JbSyncOptions, opts ...jbSyncOpt) (*JbSync, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	js := &JbSync{
+		app: o.App,
+
+		syncFn: func(fs afero.Fs, root string, reserved []string) ([]jb.Dependency, error) {
+			return jb.NewSyncer(fs, root, reserved).Sync()
+		},
+	}
+
+	for _, opt := range opts {
+		opt(js)
+	}
+
+	return js, nil
+}
+
+func (js *JbSync) run() error {
+	registries, err := js.app.Registries()
+	if err != nil {
+		return errors.Wrap(err, "retrieving registries")
+	}
+
+	// Registries are vendored in directories named after them.
+	var reserved []string
+	for name := range registries {
+		reserved = append(reserved, name)
+	}
+	sort.Strings(reserved)
+
+	_, err = js.syncFn(js.app.Fs(), js.app.Root(), reserved)
+	return err
+}
@@ -0,0 +1,79 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/jb"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJbSync(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		registries := app.RegistryConfigs{
+			"incubator":   &app.RegistryConfig{Name: "incubator"},
+			"helm-stable": &app.RegistryConfig{Name: "helm-stable"},
+		}
+		appMock.On("Registries").Return(registries, nil)
+
+		in := map[string]interface{}{
+			OptionApp: appMock,
+		}
+
+		var synced bool
+		syncOpt := func(js *JbSync) {
+			js.syncFn = func(fs afero.Fs, root string, reserved []string) ([]jb.Dependency, error) {
+				synced = true
+				assert.Equal(t, "/", root)
+				assert.Equal(t, []string{"helm-stable", "incubator"}, reserved)
+				return nil, nil
+			}
+		}
+
+		js, err := newJbSync(in, syncOpt)
+		require.NoError(t, err)
+
+		require.NoError(t, js.run())
+		assert.True(t, synced)
+	})
+}
+
+func TestJbSync_registries_error(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("Registries").Return(nil, errors.New("failed"))
+
+		in := map[string]interface{}{
+			OptionApp: appMock,
+		}
+
+		js, err := newJbSync(in)
+		require.NoError(t, err)
+
+		require.Error(t, js.run())
+	})
+}
+
+func TestJbSync_requires_app(t *testing.T) {
+	in := make(map[string]interface{})
+	_, err := newJbSync(in)
+	require.Error(t, err)
+}
@@ -0,0 +1,135 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/lint"
+	"github.com/spf13/afero"
+)
+
+type lintFn func(fs afero.Fs, root string, jPaths []string, write bool) (*lint.Result, error)
+
+// RunLint runs `lint`.
+func RunLint(m map[string]interface{}) error {
+	var o LintOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunLintWithOptions(o)
+}
+
+// RunLintWithOptions runs `lint` with typed options.
+func RunLintWithOptions(o LintOptions) error {
+	l, err := newLintWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return l.run()
+}
+
+type lintOpt func(*Lint)
+
+// Lint checks the formatting of an app's jsonnet, and lints it.
+type Lint struct {
+	app     app.App
+	envName string
+	write   bool
+	output  string
+
+	out    io.Writer
+	lintFn lintFn
+}
+
+// LintOptions are the options for Lint.
+type LintOptions struct {
+	App     app.App `option:"app"`
+	EnvName string  `option:"env-name,optional"`
+	Write   bool    `option:"write,optional"`
+	Output  string  `option:"output,optional"`
+}
+
+func newLint(m map[string]interface{}, opts ...lintOpt) (*Lint, error) {
+	var o LintOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return newLintWithOptions(o, opts...)
+}
+
+func newLintWithOptions(o LintOptions, opts ...lintOpt) (*Lint, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	l := &Lint{
+		app:     o.App,
+		envName: o.EnvName,
+		write:   o.Write,
+		output:  o.Output,
+
+		out: os.Stdout,
+		lintFn: func(fs afero.Fs, root string, jPaths []string, write bool) (*lint.Result, error) {
+			return lint.NewLinter(fs, root, jPaths, lint.NewJsonnetTools()).Run(write)
+		},
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l, nil
+}
+
+func (l *Lint) run() error {
+	root := l.app.Root()
+	jPaths := []string{
+		filepath.Join(root, "lib"),
+		l.app.VendorPath(),
+		filepath.Join(root, "components"),
+	}
+
+	// Components import k.libsonnet from the environment's ksonnet-lib.
+	envName := l.envName
+	if envName == "" {
+		envName = l.app.CurrentEnvironment()
+	}
+	if envName != "" {
+		libPath, err := l.app.LibPath(envName)
+		if err != nil {
+			return err
+		}
+		jPaths = append(jPaths, libPath)
+	}
+
+	result, err := l.lintFn(l.app.Fs(), root, jPaths, l.write)
+	if err != nil {
+		return err
+	}
+
+	if err = result.Render(l.out, l.output); err != nil {
+		return err
+	}
+
+	return result.Err()
+}
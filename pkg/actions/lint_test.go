@@ -0,0 +1,130 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"testing"
+
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/lint"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	cases := []struct {
+		name           string
+		envName        string
+		currentEnv     string
+		diagnostics    []lint.Diagnostic
+		expectedJPaths []string
+		isErr          bool
+	}{
+		{
+			name:           "no problems",
+			expectedJPaths: []string{"/lib", "/vendor", "/components"},
+		},
+		{
+			name:           "env",
+			envName:        "default",
+			expectedJPaths: []string{"/lib", "/vendor", "/components", "/lib/v1.10.3"},
+		},
+		{
+			name:           "current env",
+			currentEnv:     "default",
+			expectedJPaths: []string{"/lib", "/vendor", "/components", "/lib/v1.10.3"},
+		},
+		{
+			name: "problems",
+			diagnostics: []lint.Diagnostic{
+				{File: "components/a.jsonnet", Line: 1, Column: 7, Check: lint.CheckLint, Message: "Unused variable: x"},
+			},
+			expectedJPaths: []string{"/lib", "/vendor", "/components"},
+			isErr:          true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				appMock.On("CurrentEnvironment").Return(tc.currentEnv)
+				appMock.On("LibPath", "default").Return("/lib/v1.10.3", nil)
+
+				in := map[string]interface{}{
+					OptionApp:     appMock,
+					OptionEnvName: tc.envName,
+					OptionWrite:   true,
+				}
+
+				var buf bytes.Buffer
+				lintOpt := func(l *Lint) {
+					l.out = &buf
+					l.lintFn = func(fs afero.Fs, root string, jPaths []string, write bool) (*lint.Result, error) {
+						assert.Equal(t, "/", root)
+						assert.Equal(t, tc.expectedJPaths, jPaths)
+						assert.True(t, write)
+
+						result := lint.NewResult()
+						result.Diagnostics = append(result.Diagnostics, tc.diagnostics...)
+						return result, nil
+					}
+				}
+
+				l, err := newLint(in, lintOpt)
+				require.NoError(t, err)
+
+				err = l.run()
+				if tc.isErr {
+					require.Error(t, err)
+					assert.Contains(t, buf.String(), "Unused variable: x")
+					return
+				}
+
+				require.NoError(t, err)
+			})
+		})
+	}
+}
+
+func TestLint_lint_error(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("CurrentEnvironment").Return("")
+
+		in := map[string]interface{}{
+			OptionApp: appMock,
+		}
+
+		lintOpt := func(l *Lint) {
+			l.lintFn = func(fs afero.Fs, root string, jPaths []string, write bool) (*lint.Result, error) {
+				return nil, errors.New("failed")
+			}
+		}
+
+		l, err := newLint(in, lintOpt)
+		require.NoError(t, err)
+
+		require.Error(t, l.run())
+	})
+}
+
+func TestLint_requires_app(t *testing.T) {
+	in := make(map[string]interface{})
+	_, err := newLint(in)
+	require.Error(t, err)
+}
@@ -0,0 +1,140 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/cluster"
+)
+
+type runLogsFn func(cluster.LogsConfig, ...cluster.LogsOpts) error
+
+// RunLogs runs `logs`.
+func RunLogs(m map[string]interface{}) error {
+	var o LogsOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunLogsWithOptions(o)
+}
+
+// RunLogsWithOptions runs `logs` with typed options.
+func RunLogsWithOptions(o LogsOptions) error {
+	l, err := newLogsWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return l.run()
+}
+
+type logsOpt func(*Logs)
+
+// Logs shows the logs of the pods of a component.
+type Logs struct {
+	app           app.App
+	clientConfig  *client.Config
+	componentName string
+	envName       string
+	container     string
+	follow        bool
+	since         time.Duration
+	tail          int64
+
+	runLogsFn runLogsFn
+	out       io.Writer
+}
+
+// LogsOptions are the options for Logs.
+type LogsOptions struct {
+	App           app.App        `option:"app"`
+	ClientConfig  *client.Config `option:"client-config"`
+	ComponentName string         `option:"component-name"`
+	EnvName       string         `option:"env-name,optional"`
+	Container     string         `option:"container,optional"`
+	Follow        bool           `option:"follow,optional"`
+	Since         time.Duration  `option:"since,optional"`
+	Tail          int64          `option:"tail"`
+	Out           io.Writer      `option:"out,optional"`
+}
+
+func newLogs(m map[string]interface{}, opts ...logsOpt) (*Logs, error) {
+	var o LogsOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return newLogsWithOptions(o, opts...)
+}
+
+func newLogsWithOptions(o LogsOptions, opts ...logsOpt) (*Logs, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	l := &Logs{
+		app:           o.App,
+		clientConfig:  o.ClientConfig,
+		componentName: o.ComponentName,
+		container:     o.Container,
+		follow:        o.Follow,
+		since:         o.Since,
+		tail:          o.Tail,
+
+		runLogsFn: cluster.RunLogs,
+		out:       os.Stdout,
+	}
+
+	if o.Out != nil {
+		l.out = o.Out
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	if err := setCurrentEnv(l.app, l, o.EnvName); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func (l *Logs) setCurrentEnv(name string) {
+	l.envName = name
+}
+
+func (l *Logs) run() error {
+	config := cluster.LogsConfig{
+		App:           l.app,
+		ClientConfig:  l.clientConfig,
+		ComponentName: l.componentName,
+		EnvName:       l.envName,
+		Container:     l.container,
+		Follow:        l.follow,
+		Since:         l.since,
+		Tail:          l.tail,
+		Out:           l.out,
+	}
+
+	return l.runLogsFn(config)
+}
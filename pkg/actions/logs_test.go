@@ -0,0 +1,84 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/cluster"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogs(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		var buf bytes.Buffer
+
+		in := map[string]interface{}{
+			OptionApp:           appMock,
+			OptionClientConfig:  &client.Config{},
+			OptionComponentName: "guestbook",
+			OptionEnvName:       "default",
+			OptionContainer:     "web",
+			OptionFollow:        true,
+			OptionSince:         time.Hour,
+			OptionTail:          int64(-1),
+			OptionOut:           &buf,
+		}
+
+		expected := cluster.LogsConfig{
+			App:           appMock,
+			ClientConfig:  &client.Config{},
+			ComponentName: "guestbook",
+			EnvName:       "default",
+			Container:     "web",
+			Follow:        true,
+			Since:         time.Hour,
+			Tail:          -1,
+			Out:           &buf,
+		}
+
+		runLogsOpt := func(l *Logs) {
+			l.runLogsFn = func(config cluster.LogsConfig, opts ...cluster.LogsOpts) error {
+				assert.Equal(t, expected, config)
+				return nil
+			}
+		}
+
+		l, err := newLogs(in, runLogsOpt)
+		require.NoError(t, err)
+
+		require.NoError(t, l.run())
+	})
+}
+
+func TestLogs_requires_component(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionApp:          appMock,
+			OptionClientConfig: &client.Config{},
+			OptionEnvName:      "default",
+			OptionTail:         int64(-1),
+		}
+
+		_, err := newLogs(in)
+		require.Error(t, err)
+	})
+}
@@ -23,7 +23,17 @@ import (
 
 // RunModuleCreate creates a module.
 func RunModuleCreate(m map[string]interface{}) error {
-	mc, err := NewModuleCreate(m)
+	var o ModuleCreateOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunModuleCreateWithOptions(o)
+}
+
+// RunModuleCreateWithOptions creates a module with typed options.
+func RunModuleCreateWithOptions(o ModuleCreateOptions) error {
+	mc, err := NewModuleCreateWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -38,19 +48,33 @@ type ModuleCreate struct {
 	cm     component.Manager
 }
 
-// NewModuleCreate creates an instance of ModuleCreate.
+// ModuleCreateOptions are the options for ModuleCreate.
+type ModuleCreateOptions struct {
+	App    app.App `option:"app"`
+	Module string  `option:"module"`
+}
+
+// NewModuleCreate creates an instance of ModuleCreate from an option map.
 func NewModuleCreate(m map[string]interface{}) (*ModuleCreate, error) {
-	ol := newOptionLoader(m)
+	var o ModuleCreateOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
 
-	mc := &ModuleCreate{
-		app:    ol.LoadApp(),
-		module: ol.LoadString(OptionModule),
+	return NewModuleCreateWithOptions(o)
+}
 
-		cm: component.DefaultManager,
+// NewModuleCreateWithOptions creates an instance of ModuleCreate.
+func NewModuleCreateWithOptions(o ModuleCreateOptions) (*ModuleCreate, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
 	}
 
-	if ol.err != nil {
-		return nil, ol.err
+	mc := &ModuleCreate{
+		app:    o.App,
+		module: o.Module,
+
+		cm: component.DefaultManager,
 	}
 
 	return mc, nil
@@ -0,0 +1,189 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	"github.com/ksonnet/ksonnet/pkg/util/table"
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// RunModuleDescribe runs `module describe`
+func RunModuleDescribe(m map[string]interface{}) error {
+	var o ModuleDescribeOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunModuleDescribeWithOptions(o)
+}
+
+// RunModuleDescribeWithOptions runs `module describe` with typed options.
+func RunModuleDescribeWithOptions(o ModuleDescribeOptions) error {
+	md, err := NewModuleDescribeWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return md.Run()
+}
+
+// ModuleDescribe describes a module by printing its manifest and components.
+type ModuleDescribe struct {
+	app        app.App
+	module     string
+	outputType string
+	out        io.Writer
+	cm         component.Manager
+}
+
+// ModuleDescribeOptions are the options for ModuleDescribe.
+type ModuleDescribeOptions struct {
+	App    app.App `option:"app"`
+	Module string  `option:"module"`
+	Output string  `option:"output,optional"`
+}
+
+// NewModuleDescribe creates an instance of ModuleDescribe from an option map.
+func NewModuleDescribe(m map[string]interface{}) (*ModuleDescribe, error) {
+	var o ModuleDescribeOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return NewModuleDescribeWithOptions(o)
+}
+
+// NewModuleDescribeWithOptions creates an instance of ModuleDescribe.
+func NewModuleDescribeWithOptions(o ModuleDescribeOptions) (*ModuleDescribe, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	md := &ModuleDescribe{
+		app:        o.App,
+		module:     o.Module,
+		outputType: o.Output,
+
+		out: os.Stdout,
+		cm:  component.DefaultManager,
+	}
+
+	return md, nil
+}
+
+type moduleDescription struct {
+	Name         string                 `json:"name" yaml:"name"`
+	Path         string                 `json:"path" yaml:"path"`
+	Description  string                 `json:"description,omitempty" yaml:"description,omitempty"`
+	Owners       []string               `json:"owners,omitempty" yaml:"owners,omitempty"`
+	Environments []string               `json:"environments,omitempty" yaml:"environments,omitempty"`
+	TargetedBy   []string               `json:"targetedBy,omitempty" yaml:"targetedBy,omitempty"`
+	Params       map[string]interface{} `json:"params,omitempty" yaml:"params,omitempty"`
+	Components   []string               `json:"components" yaml:"components"`
+}
+
+// Run runs the ModuleDescribe action.
+func (md *ModuleDescribe) Run() error {
+	f, err := table.DetectFormat(md.outputType)
+	if err != nil {
+		return errors.Wrap(err, "detecting output format")
+	}
+
+	m, err := md.cm.Module(md.app, md.module)
+	if err != nil {
+		return err
+	}
+
+	mm, err := component.ReadModuleManifest(md.app.Fs(), m.Dir())
+	if err != nil {
+		return err
+	}
+
+	path, err := filepath.Rel(md.app.Root(), m.Dir())
+	if err != nil {
+		path = m.Dir()
+	}
+
+	d := moduleDescription{
+		Name:         m.Name(),
+		Path:         filepath.ToSlash(path),
+		Description:  mm.Description,
+		Owners:       mm.Owners,
+		Environments: mm.Environments,
+		Params:       mm.Params,
+		Components:   []string{},
+	}
+
+	components, err := m.Components()
+	if err != nil {
+		return err
+	}
+	for _, c := range components {
+		d.Components = append(d.Components, c.Name(false))
+	}
+	sort.Strings(d.Components)
+
+	if d.TargetedBy, err = md.targetedBy(m.Name()); err != nil {
+		return err
+	}
+
+	if f != table.FormatTable {
+		return table.Encode(md.out, f, d)
+	}
+
+	b, err := yaml.Marshal(d)
+	if err != nil {
+		return err
+	}
+
+	_, err = md.out.Write(b)
+	return err
+}
+
+// targetedBy returns the environments which target a module. Environments
+// without targets target the root module.
+func (md *ModuleDescribe) targetedBy(moduleName string) ([]string, error) {
+	envs, err := md.app.Environments()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name, env := range envs {
+		targets := env.Targets
+		if len(targets) == 0 {
+			targets = []string{"/"}
+		}
+
+		for _, target := range targets {
+			if target == moduleName {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
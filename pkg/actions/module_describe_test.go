@@ -0,0 +1,107 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	cmocks "github.com/ksonnet/ksonnet/pkg/component/mocks"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModuleDescribe(t *testing.T) {
+	cases := []struct {
+		output   string
+		expected string
+	}{
+		{output: "", expected: "module/describe/output.txt"},
+		{output: "json", expected: "module/describe/output.json"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.expected, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				manifest := []byte("description: Databases\nowners:\n- data-team\nenvironments:\n- prod\nparams:\n  replicas: 3\n")
+				require.NoError(t, afero.WriteFile(appMock.Fs(), "/components/db/module.yaml", manifest, 0644))
+
+				envs := app.EnvironmentConfigs{
+					"default": &app.EnvironmentConfig{},
+					"prod":    &app.EnvironmentConfig{Targets: []string{"db"}},
+				}
+				appMock.On("Environments").Return(envs, nil)
+
+				c1 := &cmocks.Component{}
+				c1.On("Name", false).Return("redis")
+				c2 := &cmocks.Component{}
+				c2.On("Name", false).Return("postgres")
+
+				m := mockNsWithName("db")
+				m.On("Components").Return([]component.Component{c1, c2}, nil)
+
+				cm := &cmocks.Manager{}
+				cm.On("Module", mock.Anything, "db").Return(m, nil)
+
+				in := map[string]interface{}{
+					OptionApp:    appMock,
+					OptionModule: "db",
+					OptionOutput: tc.output,
+				}
+
+				a, err := NewModuleDescribe(in)
+				require.NoError(t, err)
+
+				var buf bytes.Buffer
+				a.out = &buf
+				a.cm = cm
+
+				require.NoError(t, a.Run())
+
+				assertOutput(t, tc.expected, buf.String())
+			})
+		})
+	}
+}
+
+func TestModuleDescribe_invalid_module(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionApp:    appMock,
+			OptionModule: "missing",
+		}
+
+		a, err := NewModuleDescribe(in)
+		require.NoError(t, err)
+
+		cm := &cmocks.Manager{}
+		cm.On("Module", mock.Anything, "missing").Return(nil, errors.New("not found"))
+		a.cm = cm
+
+		require.Error(t, a.Run())
+	})
+}
+
+func TestModuleDescribe_requires_app(t *testing.T) {
+	in := make(map[string]interface{})
+	_, err := NewModuleDescribe(in)
+	require.Error(t, err)
+}
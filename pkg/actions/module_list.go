@@ -19,6 +19,7 @@ import (
 	"io"
 	"os"
 	"sort"
+	"strings"
 
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/component"
@@ -28,7 +29,17 @@ import (
 
 // RunModuleList runs `module list`
 func RunModuleList(m map[string]interface{}) error {
-	nl, err := NewModuleList(m)
+	var o ModuleListOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunModuleListWithOptions(o)
+}
+
+// RunModuleListWithOptions runs `module list` with typed options.
+func RunModuleListWithOptions(o ModuleListOptions) error {
+	nl, err := NewModuleListWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -45,23 +56,38 @@ type ModuleList struct {
 	cm         component.Manager
 }
 
-// NewModuleList creates an instance of ModuleList.
+// ModuleListOptions are the options for ModuleList.
+type ModuleListOptions struct {
+	App     app.App `option:"app"`
+	EnvName string  `option:"env-name"`
+	Output  string  `option:"output,optional"`
+}
+
+// NewModuleList creates an instance of ModuleList from an option map.
 func NewModuleList(m map[string]interface{}) (*ModuleList, error) {
-	ol := newOptionLoader(m)
+	var o ModuleListOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return NewModuleListWithOptions(o)
+}
+
+// NewModuleListWithOptions creates an instance of ModuleList.
+func NewModuleListWithOptions(o ModuleListOptions) (*ModuleList, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
 
 	nl := &ModuleList{
-		app:        ol.LoadApp(),
-		envName:    ol.LoadString(OptionEnvName),
-		outputType: ol.LoadOptionalString(OptionOutput),
+		app:        o.App,
+		envName:    o.EnvName,
+		outputType: o.Output,
 
 		out: os.Stdout,
 		cm:  component.DefaultManager,
 	}
 
-	if ol.err != nil {
-		return nil, ol.err
-	}
-
 	return nl, nil
 }
 
@@ -73,7 +99,7 @@ func (nl *ModuleList) Run() error {
 	}
 
 	t := table.New("moduleList", nl.out)
-	t.SetHeader([]string{"module"})
+	t.SetHeader([]string{"module", "description", "owners"})
 
 	f, err := table.DetectFormat(nl.outputType)
 	if err != nil {
@@ -81,15 +107,17 @@ func (nl *ModuleList) Run() error {
 	}
 	t.SetFormat(f)
 
-	names := make([]string, len(modules))
-	for i := range modules {
-		names[i] = modules[i].Name()
-	}
+	sort.Slice(modules, func(i, j int) bool {
+		return modules[i].Name() < modules[j].Name()
+	})
 
-	sort.Strings(names)
+	for _, m := range modules {
+		mm, err := component.ReadModuleManifest(nl.app.Fs(), m.Dir())
+		if err != nil {
+			return err
+		}
 
-	for _, name := range names {
-		t.Append([]string{name})
+		t.Append([]string{m.Name(), mm.Description, strings.Join(mm.Owners, ",")})
 	}
 
 	return t.Render()
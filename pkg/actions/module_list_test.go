@@ -23,6 +23,7 @@ import (
 	"github.com/ksonnet/ksonnet/pkg/component"
 	cmocks "github.com/ksonnet/ksonnet/pkg/component/mocks"
 	"github.com/pkg/errors"
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
 )
 
@@ -83,6 +84,8 @@ func TestModuleList(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			withApp(t, func(appMock *amocks.App) {
+				manifest := []byte("description: Databases\nowners:\n- data-team\n- sre\n")
+				require.NoError(t, afero.WriteFile(appMock.Fs(), "/components/a/module.yaml", manifest, 0644))
 
 				in := map[string]interface{}{
 					OptionApp:     appMock,
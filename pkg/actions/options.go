@@ -0,0 +1,205 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+var (
+	appType          = reflect.TypeOf((*app.App)(nil)).Elem()
+	fsType           = reflect.TypeOf((*afero.Fs)(nil)).Elem()
+	writerType       = reflect.TypeOf((*io.Writer)(nil)).Elem()
+	clientConfigType = reflect.TypeOf((*client.Config)(nil))
+	durationType     = reflect.TypeOf(time.Duration(0))
+	stringSliceType  = reflect.TypeOf([]string(nil))
+)
+
+// optionsDefaulter is implemented by options structs which default options
+// that were not set.
+type optionsDefaulter interface {
+	Defaults()
+}
+
+// optionsValidator is implemented by options structs which check that their
+// options are valid together.
+type optionsValidator interface {
+	Validate() error
+}
+
+// prepareOptions defaults and validates an options struct, if it implements
+// optionsDefaulter or optionsValidator. Both the map and the typed
+// constructors of an action call it, so Defaults and Validate must be safe to
+// call more than once.
+func prepareOptions(opts interface{}) error {
+	if d, ok := opts.(optionsDefaulter); ok {
+		d.Defaults()
+	}
+
+	if v, ok := opts.(optionsValidator); ok {
+		return v.Validate()
+	}
+
+	return nil
+}
+
+// loadOptions loads an option map into an action's options struct. Every
+// field with an `option` tag is loaded from the option it names, e.g.
+// `option:"env-name"`. Options are required unless the tag is marked
+// `optional`, e.g. `option:"output,optional"`. Missing required options and
+// options of the wrong type are reported the same way as by the optionLoader.
+// The loaded options are then defaulted and validated with prepareOptions.
+func loadOptions(m map[string]interface{}, opts interface{}) error {
+	v := reflect.ValueOf(opts)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return errors.Errorf("options must be a pointer to a struct, got %T", opts)
+	}
+	v = v.Elem()
+
+	ol := newOptionLoader(m)
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		tag, ok := field.Tag.Lookup("option")
+		if !ok {
+			continue
+		}
+
+		name, optional := parseOptionTag(tag)
+		value, err := loadOption(ol, field.Type, name, optional)
+		if err != nil {
+			return errors.Wrapf(err, "loading field %s", field.Name)
+		}
+
+		if ol.err != nil {
+			return ol.err
+		}
+
+		if value == nil {
+			continue
+		}
+
+		rv := reflect.ValueOf(value)
+		if !rv.Type().AssignableTo(field.Type) {
+			rv = rv.Convert(field.Type)
+		}
+		v.Field(i).Set(rv)
+	}
+
+	return prepareOptions(opts)
+}
+
+func parseOptionTag(tag string) (string, bool) {
+	parts := strings.Split(tag, ",")
+	optional := false
+	for _, flag := range parts[1:] {
+		if flag == "optional" {
+			optional = true
+		}
+	}
+
+	return parts[0], optional
+}
+
+// loadOption loads a single option with the optionLoader method for its type.
+// A nil value leaves the field at its zero value.
+func loadOption(ol *optionLoader, t reflect.Type, name string, optional bool) (interface{}, error) {
+	var value interface{}
+
+	switch {
+	case t == appType:
+		if name != OptionApp {
+			return nil, errors.Errorf("app must be loaded from the %s option", OptionApp)
+		}
+		if optional {
+			value = ol.LoadOptionalApp()
+		} else {
+			value = ol.LoadApp()
+		}
+	case t == fsType && !optional:
+		value = ol.LoadFs(name)
+	case t == writerType && optional:
+		value = ol.LoadOptionalWriter(name)
+	case t == clientConfigType && !optional:
+		if name != OptionClientConfig {
+			return nil, errors.Errorf("client config must be loaded from the %s option", OptionClientConfig)
+		}
+		value = ol.LoadClientConfig()
+	case t == durationType:
+		if optional {
+			value = ol.LoadOptionalDuration(name)
+		} else {
+			value = ol.LoadDuration(name)
+		}
+	case t == stringSliceType:
+		if optional {
+			value = ol.LoadOptionalStringSlice(name)
+		} else {
+			value = ol.LoadStringSlice(name)
+		}
+	case t.Kind() == reflect.Bool:
+		if optional {
+			value = ol.LoadOptionalBool(name)
+		} else {
+			value = ol.LoadBool(name)
+		}
+	case t.Kind() == reflect.Int:
+		if optional {
+			value = ol.LoadOptionalInt(name)
+		} else {
+			value = ol.LoadInt(name)
+		}
+	case t.Kind() == reflect.Int64 && !optional:
+		value = ol.LoadInt64(name)
+	case t.Kind() == reflect.Float32 && optional:
+		value = ol.LoadOptionalFloat32(name)
+	case t.Kind() == reflect.String:
+		if optional {
+			value = ol.LoadOptionalString(name)
+		} else {
+			value = ol.LoadString(name)
+		}
+	default:
+		return nil, errors.Errorf("unsupported type %s for option %s", t, name)
+	}
+
+	if isNil(value) {
+		return nil, nil
+	}
+
+	return value, nil
+}
+
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		return v.IsNil()
+	}
+
+	return false
+}
@@ -0,0 +1,220 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testOptions struct {
+	App       app.App       `option:"app"`
+	Name      string        `option:"name"`
+	Paths     []string      `option:"paths,optional"`
+	Force     bool          `option:"force,optional"`
+	Timeout   time.Duration `option:"wait-timeout,optional"`
+	Untracked string
+}
+
+func Test_loadOptions(t *testing.T) {
+	a := &mocks.App{}
+
+	cases := []struct {
+		name     string
+		m        map[string]interface{}
+		expected testOptions
+		isErr    bool
+		errType  interface{}
+	}{
+		{
+			name: "required and optional options",
+			m: map[string]interface{}{
+				OptionApp:         a,
+				OptionName:        "name",
+				OptionPaths:       []string{"a", "b"},
+				OptionForce:       true,
+				OptionWaitTimeout: time.Second,
+			},
+			expected: testOptions{
+				App:     a,
+				Name:    "name",
+				Paths:   []string{"a", "b"},
+				Force:   true,
+				Timeout: time.Second,
+			},
+		},
+		{
+			name: "missing optional options",
+			m: map[string]interface{}{
+				OptionApp:  a,
+				OptionName: "name",
+			},
+			expected: testOptions{
+				App:  a,
+				Name: "name",
+			},
+		},
+		{
+			name: "missing app",
+			m: map[string]interface{}{
+				OptionName: "name",
+			},
+			isErr: true,
+		},
+		{
+			name: "missing required option",
+			m: map[string]interface{}{
+				OptionApp: a,
+			},
+			isErr:   true,
+			errType: &missingOptionError{},
+		},
+		{
+			name: "invalid option type",
+			m: map[string]interface{}{
+				OptionApp:  a,
+				OptionName: 1,
+			},
+			isErr:   true,
+			errType: &invalidOptionError{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var o testOptions
+			err := loadOptions(tc.m, &o)
+			if tc.isErr {
+				require.Error(t, err)
+				if tc.errType != nil {
+					assert.IsType(t, tc.errType, err)
+				}
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, o)
+		})
+	}
+}
+
+// preparedOptions defaults and validates its options.
+type preparedOptions struct {
+	App   app.App `option:"app"`
+	Name  string  `option:"name,optional"`
+	Count int     `option:"batch-size,optional"`
+}
+
+func (o *preparedOptions) Defaults() {
+	if o.Name == "" {
+		o.Name = "default"
+	}
+}
+
+func (o *preparedOptions) Validate() error {
+	if o.Count < 0 {
+		return errors.New("count can not be negative")
+	}
+
+	return nil
+}
+
+func Test_loadOptions_prepare(t *testing.T) {
+	a := &mocks.App{}
+
+	var o preparedOptions
+	require.NoError(t, loadOptions(map[string]interface{}{OptionApp: a}, &o))
+	assert.Equal(t, preparedOptions{App: a, Name: "default"}, o)
+
+	o = preparedOptions{}
+	require.NoError(t, loadOptions(map[string]interface{}{OptionApp: a, OptionName: "name", OptionBatchSize: 2}, &o))
+	assert.Equal(t, preparedOptions{App: a, Name: "name", Count: 2}, o)
+
+	o = preparedOptions{}
+	err := loadOptions(map[string]interface{}{OptionApp: a, OptionBatchSize: -1}, &o)
+	require.Error(t, err)
+	assert.Equal(t, "count can not be negative", err.Error())
+}
+
+func Test_loadOptions_missing_app(t *testing.T) {
+	var o testOptions
+	err := loadOptions(map[string]interface{}{OptionName: "name"}, &o)
+	require.Equal(t, ErrNotInApp, err)
+}
+
+func Test_loadOptions_invalid_target(t *testing.T) {
+	var o testOptions
+	err := loadOptions(map[string]interface{}{}, o)
+	require.Error(t, err)
+
+	type unsupported struct {
+		Value map[string]string `option:"value"`
+	}
+	err = loadOptions(map[string]interface{}{}, &unsupported{})
+	require.Error(t, err)
+}
+
+// Test_loadOptions_actions ensures every action's options struct only uses
+// field types loadOptions knows how to load.
+func Test_loadOptions_actions(t *testing.T) {
+	opts := []interface{}{
+		&APIDumpOptions{}, &ApplyOptions{}, &BundleOptions{}, &CompleteOptions{}, &ComponentListOptions{}, &ComponentRmOptions{},
+		&DeleteOptions{}, &DevOptions{}, &DiffOptions{}, &DoctorOptions{}, &EnvAddOptions{},
+		&EnvCurrentOptions{},
+		&EnvDescribeOptions{}, &EnvListOptions{}, &EnvRegenerateOptions{}, &EnvRmOptions{},
+		&EnvSetOptions{}, &EnvTargetsOptions{}, &EnvUpdateCRDLibOptions{}, &EnvUpdateLibOptions{},
+		&EnvUpdateOptions{}, &EvalOptions{}, &ExplainOptions{}, &ExportOptions{}, &FmtOptions{}, &GraphOptions{},
+		&ImageListOptions{},
+		&ImageOutdatedOptions{}, &ImagePinOptions{}, &ImageSetOptions{}, &ImportOptions{},
+		&InitOptions{}, &InventoryOptions{}, &JbSyncOptions{}, &LintOptions{}, &LogsOptions{},
+		&ModuleCreateOptions{}, &ModuleDescribeOptions{}, &ModuleListOptions{},
+		&ParamDeleteOptions{}, &ParamDiffOptions{}, &ParamHistoryOptions{}, &ParamListOptions{},
+		&ParamSetOptions{}, &PkgDescribeOptions{}, &PkgDiffOptions{}, &PkgInstallOptions{},
+		&PkgListOptions{}, &PkgRemoveOptions{}, &PluginRunOptions{}, &PortForwardOptions{},
+		&PrototypeCreateOptions{}, &PrototypeDescribeOptions{}, &PrototypeLintOptions{},
+		&PrototypeListOptions{}, &PrototypePreviewOptions{}, &PrototypeSearchOptions{},
+		&PrototypeUseOptions{}, &RegistryAddOptions{}, &RegistryDescribeOptions{},
+		&RegistryListOptions{}, &RegistrySetOptions{}, &RegistryStatsOptions{},
+		&RenderForArgoCDOptions{}, &ShowOptions{}, &SnapshotRecordOptions{},
+		&SnapshotVerifyOptions{}, &StatusOptions{}, &TestOptions{},
+		&ToolJsonnetPathsOptions{}, &UIOptions{}, &UpgradeOptions{}, &ValidateOptions{},
+	}
+
+	for _, o := range opts {
+		rt := reflect.TypeOf(o).Elem()
+		t.Run(rt.Name(), func(t *testing.T) {
+			ol := newOptionLoader(map[string]interface{}{})
+			for i := 0; i < rt.NumField(); i++ {
+				field := rt.Field(i)
+				tag, ok := field.Tag.Lookup("option")
+				if !ok {
+					continue
+				}
+
+				name, optional := parseOptionTag(tag)
+				_, err := loadOption(ol, field.Type, name, optional)
+				require.NoError(t, err, "field %s", field.Name)
+			}
+		})
+	}
+}
@@ -16,21 +16,97 @@
 package actions
 
 import (
+	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/component"
 	"github.com/ksonnet/ksonnet/pkg/env"
+	"github.com/ksonnet/ksonnet/pkg/params"
+	strutil "github.com/ksonnet/ksonnet/pkg/util/strings"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
 )
 
 type getModuleFn func(ksApp app.App, moduleName string) (component.Module, error)
 type deleteEnvFn func(ksApp app.App, envName, componentName, paramName string) error
 type deleteEnvGlobalFn func(a app.App, envName, paramName string) error
 
-// RunParamDelete runs `param set`
+// UnknownComponentError is returned when deleting a parameter from a
+// component which does not exist.
+type UnknownComponentError struct {
+	Name        string
+	Suggestions []string
+}
+
+func (e *UnknownComponentError) Error() string {
+	return withSuggestions(fmt.Sprintf("unknown component %q", e.Name), e.Suggestions)
+}
+
+// UnknownModuleError is returned when deleting a global parameter from a
+// module which does not exist.
+type UnknownModuleError struct {
+	Name        string
+	Suggestions []string
+}
+
+func (e *UnknownModuleError) Error() string {
+	return withSuggestions(fmt.Sprintf("unknown module %q", e.Name), e.Suggestions)
+}
+
+// UnknownParamError is returned when deleting a parameter path which does not
+// exist in a component or module.
+type UnknownParamError struct {
+	// Owner is the component or module the parameter was deleted from.
+	Owner       string
+	Path        string
+	Suggestions []string
+}
+
+func (e *UnknownParamError) Error() string {
+	return withSuggestions(fmt.Sprintf("unknown parameter %q in %q", e.Path, e.Owner), e.Suggestions)
+}
+
+func withSuggestions(msg string, suggestions []string) string {
+	if len(suggestions) == 0 {
+		return msg
+	}
+
+	return fmt.Sprintf("%s; did you mean %s?", msg, strings.Join(quoteAll(suggestions), " or "))
+}
+
+func quoteAll(sl []string) []string {
+	var out []string
+	for _, s := range sl {
+		out = append(out, fmt.Sprintf("%q", s))
+	}
+	return out
+}
+
+func isUnknownTarget(err error) bool {
+	switch errors.Cause(err).(type) {
+	case *UnknownComponentError, *UnknownModuleError, *UnknownParamError:
+		return true
+	default:
+		return false
+	}
+}
+
+// RunParamDelete runs `param delete`
 func RunParamDelete(m map[string]interface{}) error {
-	pd, err := NewParamDelete(m)
+	var o ParamDeleteOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunParamDeleteWithOptions(o)
+}
+
+// RunParamDeleteWithOptions runs `param delete` with typed options.
+func RunParamDeleteWithOptions(o ParamDeleteOptions) error {
+	pd, err := NewParamDeleteWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -40,90 +116,357 @@ func RunParamDelete(m map[string]interface{}) error {
 
 // ParamDelete sets a parameter for a component.
 type ParamDelete struct {
-	app     app.App
-	name    string
-	rawPath string
-	global  bool
-	envName string
+	app      app.App
+	name     string
+	paths    []string
+	global   bool
+	envName  string
+	allEnvs  bool
+	ifExists bool
+	message  string
+
+	// changes are the environment params which were deleted. They are
+	// recorded once every deletion has succeeded.
+	changes map[string][]env.ParamChange
 
 	deleteEnvFn       deleteEnvFn
 	deleteEnvGlobalFn deleteEnvGlobalFn
 	getModuleFn       getModuleFn
 	resolvePathFn     func(a app.App, path string) (component.Module, component.Component, error)
+	modulesFn         func(a app.App) ([]component.Module, error)
+	paramsPathsFn     func(a app.App) ([]string, error)
+	recordFn          func(a app.App, envName string, change env.ParamChange) error
 }
 
-// NewParamDelete creates an instance of ParamDelete.
+// ParamDeleteOptions are the options for ParamDelete.
+type ParamDeleteOptions struct {
+	App  app.App `option:"app"`
+	Name string  `option:"name,optional"`
+	// Path and Paths are the params to delete. At least one is required.
+	Path     string   `option:"path"`
+	Paths    []string `option:"paths,optional"`
+	Global   bool     `option:"global,optional"`
+	EnvName  string   `option:"env-name,optional"`
+	AllEnvs  bool     `option:"all-envs,optional"`
+	IfExists bool     `option:"if-exists,optional"`
+	Message  string   `option:"message,optional"`
+}
+
+// NewParamDelete creates an instance of ParamDelete from an option map.
 func NewParamDelete(m map[string]interface{}) (*ParamDelete, error) {
-	ol := newOptionLoader(m)
+	var o ParamDeleteOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return NewParamDeleteWithOptions(o)
+}
+
+// NewParamDeleteWithOptions creates an instance of ParamDelete.
+func NewParamDeleteWithOptions(o ParamDeleteOptions) (*ParamDelete, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
 
 	pd := &ParamDelete{
-		app:     ol.LoadApp(),
-		name:    ol.LoadOptionalString(OptionName),
-		rawPath: ol.LoadString(OptionPath),
-		global:  ol.LoadOptionalBool(OptionGlobal),
-		envName: ol.LoadOptionalString(OptionEnvName),
+		app:      o.App,
+		name:     o.Name,
+		global:   o.Global,
+		envName:  o.EnvName,
+		allEnvs:  o.AllEnvs,
+		ifExists: o.IfExists,
+		message:  o.Message,
+		changes:  make(map[string][]env.ParamChange),
 
 		deleteEnvFn:       env.DeleteParam,
 		deleteEnvGlobalFn: env.UnsetGlobalParams,
 		resolvePathFn:     component.ResolvePath,
 		getModuleFn:       component.GetModule,
+		modulesFn:         component.Modules,
+		paramsPathsFn:     paramsPaths,
+		recordFn:          env.RecordParamChange,
+	}
+
+	if o.Path != "" {
+		pd.paths = append(pd.paths, o.Path)
 	}
+	pd.paths = append(pd.paths, o.Paths...)
 
-	if ol.err != nil {
-		return nil, ol.err
+	if len(pd.paths) == 0 {
+		return nil, errors.New("at least one param path is required")
 	}
 
 	if pd.envName != "" && pd.global {
 		return nil, errors.New("unable to delete global param for environments")
 	}
 
+	if pd.allEnvs {
+		switch {
+		case pd.envName != "":
+			return nil, errors.New("unable to delete params from a single environment and all environments")
+		case pd.global:
+			return nil, errors.New("unable to delete global params from all environments")
+		case pd.name == "":
+			return nil, errors.New("a component is required to delete params from all environments")
+		}
+	}
+
 	return pd, nil
 }
 
-// Run runs the action.
+// Run runs the action. If ifExists is set, deleting a parameter from a
+// missing component or module, or a missing parameter, is not an error.
+//
+// When deleting several paths, or deleting from all environments, either
+// every deletion succeeds or the params files are left unchanged.
 func (pd *ParamDelete) Run() error {
+	if len(pd.paths) == 1 && !pd.allEnvs {
+		if err := pd.deletePath(pd.paths[0]); err != nil {
+			return err
+		}
+		return pd.recordChanges()
+	}
+
+	files, err := pd.readParams()
+	if err != nil {
+		return err
+	}
+
+	if err := pd.deleteAll(); err != nil {
+		if rerr := pd.restoreParams(files); rerr != nil {
+			return errors.Wrapf(rerr, "restoring params after failed delete (%v)", err)
+		}
+		return err
+	}
+
+	return pd.recordChanges()
+}
+
+// deleted notes that a param was deleted from an environment.
+func (pd *ParamDelete) deleted(envName, rawPath string) {
+	pd.changes[envName] = append(pd.changes[envName], env.ParamChange{
+		Component: pd.name,
+		Param:     rawPath,
+		Deleted:   true,
+		Message:   pd.message,
+	})
+}
+
+// recordChanges records the deleted environment params in the environments'
+// param history.
+func (pd *ParamDelete) recordChanges() error {
+	var envNames []string
+	for envName := range pd.changes {
+		envNames = append(envNames, envName)
+	}
+	sort.Strings(envNames)
+
+	for _, envName := range envNames {
+		for _, change := range pd.changes[envName] {
+			if err := pd.recordFn(pd.app, envName, change); err != nil {
+				return errors.Wrap(err, "record param history")
+			}
+		}
+	}
+
+	return nil
+}
+
+func (pd *ParamDelete) deleteAll() error {
+	for _, rawPath := range pd.paths {
+		if err := pd.deletePath(rawPath); err != nil {
+			return err
+		}
+	}
+
+	if !pd.allEnvs {
+		return nil
+	}
+
+	envs, err := pd.app.Environments()
+	if err != nil {
+		return errors.Wrap(err, "retrieve environments")
+	}
+
+	var envNames []string
+	for envName := range envs {
+		envNames = append(envNames, envName)
+	}
+	sort.Strings(envNames)
+
+	for _, envName := range envNames {
+		for _, rawPath := range pd.paths {
+			err := pd.deleteEnvFn(pd.app, envName, pd.name, rawPath)
+			if errors.Cause(err) == params.ErrComponentNotOverridden {
+				// The environment has no params for the component.
+				break
+			}
+			if err != nil {
+				return errors.Wrapf(err, "delete param %q from environment %q", rawPath, envName)
+			}
+			pd.deleted(envName, rawPath)
+		}
+	}
+
+	return nil
+}
+
+// readParams reads the params files which could be changed by the delete.
+func (pd *ParamDelete) readParams() (map[string][]byte, error) {
+	paths, err := pd.paramsPathsFn(pd.app)
+	if err != nil {
+		return nil, errors.Wrap(err, "find params files")
+	}
+
+	files := make(map[string][]byte)
+	for _, path := range paths {
+		data, err := afero.ReadFile(pd.app.Fs(), path)
+		if err != nil {
+			return nil, err
+		}
+		files[path] = data
+	}
+
+	return files, nil
+}
+
+func (pd *ParamDelete) restoreParams(files map[string][]byte) error {
+	for path, data := range files {
+		if err := afero.WriteFile(pd.app.Fs(), path, data, app.DefaultFilePermissions); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (pd *ParamDelete) deletePath(rawPath string) error {
+	err := pd.delete(rawPath)
+	if err != nil && pd.ifExists && isUnknownTarget(err) {
+		log.Debugf("skipping param delete: %v", err)
+		return nil
+	}
+
+	return err
+}
+
+func (pd *ParamDelete) delete(rawPath string) error {
 	if pd.envName != "" {
+		var err error
 		if pd.name != "" {
-			return pd.deleteEnvFn(pd.app, pd.envName, pd.name, pd.rawPath)
+			err = pd.deleteEnvFn(pd.app, pd.envName, pd.name, rawPath)
+		} else {
+			err = pd.deleteEnvGlobalFn(pd.app, pd.envName, rawPath)
+		}
+		if err != nil {
+			return err
 		}
-		return pd.deleteEnvGlobalFn(pd.app, pd.envName, pd.rawPath)
-	}
 
-	path := strings.Split(pd.rawPath, ".")
+		pd.deleted(pd.envName, rawPath)
+		return nil
+	}
 
 	if pd.global {
-		return pd.deleteGlobal(path)
+		return pd.deleteGlobal(rawPath)
 	}
 
-	return pd.deleteLocal(path)
+	return pd.deleteLocal(rawPath)
 }
 
-func (pd *ParamDelete) deleteGlobal(path []string) error {
+func (pd *ParamDelete) deleteGlobal(rawPath string) error {
 	module, err := pd.getModuleFn(pd.app, pd.name)
 	if err != nil {
-		return errors.Wrap(err, "retrieve module")
+		return &UnknownModuleError{
+			Name:        pd.name,
+			Suggestions: strutil.Closest(pd.name, pd.moduleNames()),
+		}
 	}
 
-	if err := module.DeleteParam(path); err != nil {
-		return errors.Wrap(err, "delete global param")
+	if err := module.DeleteParam(strings.Split(rawPath, ".")); err != nil {
+		return paramError(err, pd.name, rawPath, "delete global param")
 	}
 
 	return nil
 }
 
-func (pd *ParamDelete) deleteLocal(path []string) error {
+func (pd *ParamDelete) deleteLocal(rawPath string) error {
 	_, c, err := pd.resolvePathFn(pd.app, pd.name)
 	if err != nil {
-		return errors.Wrap(err, "could not find component")
+		return &UnknownComponentError{
+			Name:        pd.name,
+			Suggestions: strutil.Closest(pd.name, pd.componentNames()),
+		}
 	}
 
 	if c == nil {
 		return errors.New("invalid component or param key")
 	}
 
-	if err := c.DeleteParam(path); err != nil {
-		return errors.Wrap(err, "delete param")
+	if err := c.DeleteParam(strings.Split(rawPath, ".")); err != nil {
+		return paramError(err, pd.name, rawPath, "delete param")
 	}
 
 	return nil
 }
+
+// paramError converts a missing path error from a params file into an
+// UnknownParamError with suggestions from the keys which do exist.
+func paramError(err error, owner, rawPath, msg string) error {
+	pnf, ok := errors.Cause(err).(*params.PathNotFoundError)
+	if !ok {
+		return errors.Wrap(err, msg)
+	}
+
+	prefix := pnf.Path[:len(pnf.Path)-1]
+	missing := pnf.Path[len(pnf.Path)-1]
+
+	var suggestions []string
+	for _, s := range strutil.Closest(missing, pnf.Siblings) {
+		suggestions = append(suggestions, strings.Join(append(append([]string{}, prefix...), s), "."))
+	}
+
+	return &UnknownParamError{
+		Owner:       owner,
+		Path:        rawPath,
+		Suggestions: suggestions,
+	}
+}
+
+// moduleNames returns the names of the modules in the app. Errors are
+// ignored since the names are only used for suggestions.
+func (pd *ParamDelete) moduleNames() []string {
+	modules, err := pd.modulesFn(pd.app)
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, m := range modules {
+		names = append(names, m.Name())
+	}
+
+	return names
+}
+
+// componentNames returns the names of the components in the app. Errors are
+// ignored since the names are only used for suggestions.
+func (pd *ParamDelete) componentNames() []string {
+	modules, err := pd.modulesFn(pd.app)
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, m := range modules {
+		components, err := m.Components()
+		if err != nil {
+			continue
+		}
+
+		for _, c := range components {
+			names = append(names, c.Name(true))
+		}
+	}
+
+	return names
+}
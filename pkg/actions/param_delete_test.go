@@ -22,7 +22,12 @@ import (
 	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
 	"github.com/ksonnet/ksonnet/pkg/component"
 	cmocks "github.com/ksonnet/ksonnet/pkg/component/mocks"
+	"github.com/ksonnet/ksonnet/pkg/env"
+	"github.com/ksonnet/ksonnet/pkg/params"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -132,8 +137,20 @@ func TestParamDelete_env(t *testing.T) {
 		}
 		a.deleteEnvFn = envDelete
 
+		var recorded []env.ParamChange
+		a.recordFn = func(_ app.App, envName string, change env.ParamChange) error {
+			assert.Equal(t, "default", envName)
+			recorded = append(recorded, change)
+			return nil
+		}
+
 		err = a.Run()
 		require.NoError(t, err)
+
+		expected := []env.ParamChange{
+			{Component: "deployment", Param: "replicas", Deleted: true},
+		}
+		assert.Equal(t, expected, recorded)
 	})
 }
 
@@ -156,6 +173,9 @@ func TestParamDelete_env_global(t *testing.T) {
 			return nil
 		}
 		a.deleteEnvGlobalFn = envDelete
+		a.recordFn = func(app.App, string, env.ParamChange) error {
+			return nil
+		}
 
 		err = a.Run()
 		require.NoError(t, err)
@@ -167,3 +187,279 @@ func TestParamDelete_requires_app(t *testing.T) {
 	_, err := NewParamDelete(in)
 	require.Error(t, err)
 }
+
+func TestParamDelete_unknown_targets(t *testing.T) {
+	cases := []struct {
+		name     string
+		global   bool
+		ifExists bool
+		target   string
+		path     string
+		expected error
+	}{
+		{
+			name:   "unknown component",
+			target: "deploymnt",
+			path:   "replicas",
+			expected: &UnknownComponentError{
+				Name:        "deploymnt",
+				Suggestions: []string{"deployment"},
+			},
+		},
+		{
+			name:   "unknown module",
+			global: true,
+			target: "nested",
+			path:   "replicas",
+			expected: &UnknownModuleError{
+				Name:        "nested",
+				Suggestions: []string{"nested2"},
+			},
+		},
+		{
+			name:   "unknown param",
+			target: "deployment",
+			path:   "spec.replcas",
+			expected: &UnknownParamError{
+				Owner:       "deployment",
+				Path:        "spec.replcas",
+				Suggestions: []string{"spec.replicas"},
+			},
+		},
+		{
+			name:     "unknown param if exists",
+			target:   "deployment",
+			path:     "spec.replcas",
+			ifExists: true,
+		},
+		{
+			name:     "unknown component if exists",
+			target:   "missing",
+			path:     "replicas",
+			ifExists: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				pnf := &params.PathNotFoundError{
+					Path:     []string{"spec", "replcas"},
+					Siblings: []string{"replicas", "template"},
+				}
+
+				c := &cmocks.Component{}
+				c.On("Name", true).Return("deployment")
+				c.On("DeleteParam", []string{"spec", "replcas"}).Return(pnf)
+
+				m := &cmocks.Module{}
+				m.On("Name").Return("nested2")
+				m.On("Components").Return([]component.Component{c}, nil)
+
+				in := map[string]interface{}{
+					OptionApp:      appMock,
+					OptionName:     tc.target,
+					OptionPath:     tc.path,
+					OptionGlobal:   tc.global,
+					OptionIfExists: tc.ifExists,
+				}
+
+				a, err := NewParamDelete(in)
+				require.NoError(t, err)
+
+				a.modulesFn = func(app.App) ([]component.Module, error) {
+					return []component.Module{m}, nil
+				}
+				a.getModuleFn = func(app.App, string) (component.Module, error) {
+					return nil, errors.New("not found")
+				}
+				a.resolvePathFn = func(_ app.App, name string) (component.Module, component.Component, error) {
+					if name == "deployment" {
+						return m, c, nil
+					}
+					return nil, nil, errors.New("not found")
+				}
+
+				err = a.Run()
+				if tc.expected == nil {
+					require.NoError(t, err)
+					return
+				}
+
+				require.Equal(t, tc.expected, err)
+			})
+		})
+	}
+}
+
+func TestUnknownParamError(t *testing.T) {
+	err := &UnknownParamError{
+		Owner:       "deployment",
+		Path:        "replcas",
+		Suggestions: []string{"replicas", "replica"},
+	}
+
+	expected := `unknown parameter "replcas" in "deployment"; did you mean "replicas" or "replica"?`
+	assert.Equal(t, expected, err.Error())
+}
+
+func TestParamDelete_all_envs(t *testing.T) {
+	cases := []struct {
+		name             string
+		prodErr          error
+		expectedCalls    []string
+		expectedRecorded []string
+		isErr            bool
+	}{
+		{
+			name: "deletes from component and environments",
+			expectedCalls: []string{
+				"default:replicas", "default:image",
+				"dev:replicas",
+				"prod:replicas", "prod:image",
+			},
+			expectedRecorded: []string{
+				"default:replicas", "default:image",
+				"prod:replicas", "prod:image",
+			},
+		},
+		{
+			name:    "restores params on failure",
+			prodErr: errors.New("failed"),
+			expectedCalls: []string{
+				"default:replicas", "default:image",
+				"dev:replicas",
+				"prod:replicas",
+			},
+			isErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				fs := appMock.Fs()
+				paramsPath := "/components/params.libsonnet"
+				require.NoError(t, afero.WriteFile(fs, paramsPath, []byte("original"), app.DefaultFilePermissions))
+
+				envs := app.EnvironmentConfigs{
+					"prod":    &app.EnvironmentConfig{},
+					"default": &app.EnvironmentConfig{},
+					"dev":     &app.EnvironmentConfig{},
+				}
+				appMock.On("Environments").Return(envs, nil)
+
+				c := &cmocks.Component{}
+				c.On("DeleteParam", []string{"replicas"}).Return(nil).Run(func(mock.Arguments) {
+					require.NoError(t, afero.WriteFile(fs, paramsPath, []byte("updated"), app.DefaultFilePermissions))
+				})
+				c.On("DeleteParam", []string{"image"}).Return(nil)
+
+				in := map[string]interface{}{
+					OptionApp:     appMock,
+					OptionName:    "deployment",
+					OptionPath:    "replicas",
+					OptionPaths:   []string{"image"},
+					OptionAllEnvs: true,
+				}
+
+				a, err := NewParamDelete(in)
+				require.NoError(t, err)
+
+				a.resolvePathFn = func(app.App, string) (component.Module, component.Component, error) {
+					return nil, c, nil
+				}
+				a.paramsPathsFn = func(app.App) ([]string, error) {
+					return []string{paramsPath}, nil
+				}
+
+				var calls []string
+				a.deleteEnvFn = func(_ app.App, envName, componentName, paramName string) error {
+					assert.Equal(t, "deployment", componentName)
+					calls = append(calls, envName+":"+paramName)
+
+					switch envName {
+					case "dev":
+						return errors.Wrap(params.ErrComponentNotOverridden, "unable to find component")
+					case "prod":
+						return tc.prodErr
+					default:
+						return nil
+					}
+				}
+
+				var recorded []string
+				a.recordFn = func(_ app.App, envName string, change env.ParamChange) error {
+					assert.True(t, change.Deleted)
+					recorded = append(recorded, envName+":"+change.Param)
+					return nil
+				}
+
+				err = a.Run()
+				assert.Equal(t, tc.expectedCalls, calls)
+				assert.Equal(t, tc.expectedRecorded, recorded)
+				c.AssertExpectations(t)
+
+				data, rerr := afero.ReadFile(fs, paramsPath)
+				require.NoError(t, rerr)
+
+				if tc.isErr {
+					require.Error(t, err)
+					assert.Equal(t, "original", string(data))
+					return
+				}
+
+				require.NoError(t, err)
+				assert.Equal(t, "updated", string(data))
+			})
+		})
+	}
+}
+
+func TestNewParamDelete_invalid(t *testing.T) {
+	cases := []struct {
+		name string
+		in   map[string]interface{}
+	}{
+		{
+			name: "no paths",
+			in:   map[string]interface{}{OptionPath: ""},
+		},
+		{
+			name: "all envs and env",
+			in: map[string]interface{}{
+				OptionName:    "deployment",
+				OptionPath:    "replicas",
+				OptionEnvName: "default",
+				OptionAllEnvs: true,
+			},
+		},
+		{
+			name: "all envs and global",
+			in: map[string]interface{}{
+				OptionName:    "/",
+				OptionPath:    "replicas",
+				OptionGlobal:  true,
+				OptionAllEnvs: true,
+			},
+		},
+		{
+			name: "all envs without component",
+			in: map[string]interface{}{
+				OptionPath:    "replicas",
+				OptionAllEnvs: true,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				tc.in[OptionApp] = appMock
+
+				_, err := NewParamDelete(tc.in)
+				require.Error(t, err)
+			})
+		})
+	}
+}
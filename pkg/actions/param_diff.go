@@ -27,7 +27,17 @@ import (
 
 // RunParamDiff runs `param diff`.
 func RunParamDiff(m map[string]interface{}) error {
-	pd, err := NewParamDiff(m)
+	var o ParamDiffOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunParamDiffWithOptions(o)
+}
+
+// RunParamDiffWithOptions runs `param diff` with typed options.
+func RunParamDiffWithOptions(o ParamDiffOptions) error {
+	pd, err := NewParamDiffWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -47,25 +57,42 @@ type ParamDiff struct {
 	out              io.Writer
 }
 
-// NewParamDiff creates an instance of ParamDiff.
+// ParamDiffOptions are the options for ParamDiff.
+type ParamDiffOptions struct {
+	App           app.App `option:"app"`
+	EnvName1      string  `option:"env-name-1"`
+	EnvName2      string  `option:"env-name-2"`
+	ComponentName string  `option:"component-name,optional"`
+	Output        string  `option:"output,optional"`
+}
+
+// NewParamDiff creates an instance of ParamDiff from an option map.
 func NewParamDiff(m map[string]interface{}) (*ParamDiff, error) {
-	ol := newOptionLoader(m)
+	var o ParamDiffOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return NewParamDiffWithOptions(o)
+}
+
+// NewParamDiffWithOptions creates an instance of ParamDiff.
+func NewParamDiffWithOptions(o ParamDiffOptions) (*ParamDiff, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
 
 	pd := &ParamDiff{
-		app:           ol.LoadApp(),
-		envName1:      ol.LoadString(OptionEnvName1),
-		envName2:      ol.LoadString(OptionEnvName2),
-		componentName: ol.LoadOptionalString(OptionComponentName),
-		outputType:    ol.LoadOptionalString(OptionOutput),
+		app:           o.App,
+		envName1:      o.EnvName1,
+		envName2:      o.EnvName2,
+		componentName: o.ComponentName,
+		outputType:    o.Output,
 
 		modulesFromEnvFn: component.ModulesFromEnv,
 		out:              os.Stdout,
 	}
 
-	if ol.err != nil {
-		return nil, ol.err
-	}
-
 	return pd, nil
 }
 
@@ -0,0 +1,166 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/env"
+	"github.com/ksonnet/ksonnet/pkg/util/table"
+	"github.com/pkg/errors"
+)
+
+// RunParamHistory runs `param history`.
+func RunParamHistory(m map[string]interface{}) error {
+	var o ParamHistoryOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunParamHistoryWithOptions(o)
+}
+
+// RunParamHistoryWithOptions runs `param history` with typed options.
+func RunParamHistoryWithOptions(o ParamHistoryOptions) error {
+	ph, err := NewParamHistoryWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return ph.Run()
+}
+
+// ParamHistory lists the changes made to an environment's params.
+type ParamHistory struct {
+	app           app.App
+	componentName string
+	envName       string
+	outputType    string
+
+	out       io.Writer
+	historyFn func(a app.App, envName, component string) ([]env.ParamChange, error)
+	blameFn   func(a app.App, envName, component string) ([]env.ParamChange, error)
+}
+
+// ParamHistoryOptions are the options for ParamHistory.
+type ParamHistoryOptions struct {
+	App           app.App `option:"app"`
+	ComponentName string  `option:"component-name,optional"`
+	EnvName       string  `option:"env-name"`
+	Output        string  `option:"output,optional"`
+}
+
+// NewParamHistory creates an instance of ParamHistory from an option map.
+func NewParamHistory(m map[string]interface{}) (*ParamHistory, error) {
+	var o ParamHistoryOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return NewParamHistoryWithOptions(o)
+}
+
+// NewParamHistoryWithOptions creates an instance of ParamHistory.
+func NewParamHistoryWithOptions(o ParamHistoryOptions) (*ParamHistory, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	ph := &ParamHistory{
+		app:           o.App,
+		componentName: o.ComponentName,
+		envName:       o.EnvName,
+		outputType:    o.Output,
+
+		out:       os.Stdout,
+		historyFn: env.ParamHistory,
+		blameFn:   env.BlameParams,
+	}
+
+	return ph, nil
+}
+
+// Run runs the ParamHistory action.
+func (ph *ParamHistory) Run() error {
+	f, err := table.DetectFormat(ph.outputType)
+	if err != nil {
+		return errors.Wrap(err, "detecting output format")
+	}
+
+	changes, err := ph.historyFn(ph.app, ph.envName, ph.componentName)
+	if err != nil {
+		return err
+	}
+
+	if ph.componentName != "" {
+		if changes, err = ph.withBlame(changes); err != nil {
+			return err
+		}
+	}
+
+	t := table.New("paramHistory", ph.out)
+	t.SetFormat(f)
+	t.SetHeader([]string{"time", "author", "component", "param", "change", "message", "commit"})
+
+	for _, change := range changes {
+		desc := "set " + change.Value
+		if change.Deleted {
+			desc = "deleted"
+		}
+
+		t.Append([]string{
+			change.Time.Format(time.RFC3339),
+			change.Author,
+			change.Component,
+			change.Param,
+			desc,
+			change.Message,
+			shortCommit(change.Commit),
+		})
+	}
+
+	return t.Render()
+}
+
+// withBlame adds changes from git blame for params which have no recorded
+// changes, e.g. params which were edited by hand.
+func (ph *ParamHistory) withBlame(changes []env.ParamChange) ([]env.ParamChange, error) {
+	blamed, err := ph.blameFn(ph.app, ph.envName, ph.componentName)
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := make(map[string]bool)
+	for _, change := range changes {
+		recorded[change.Param] = true
+	}
+
+	for _, change := range blamed {
+		if !recorded[change.Param] {
+			changes = append(changes, change)
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Time.Before(changes[j].Time)
+	})
+
+	return changes, nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/env"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParamHistory(t *testing.T) {
+	t1 := time.Date(2018, 6, 1, 10, 0, 0, 0, time.UTC)
+	t2 := time.Date(2018, 6, 2, 10, 0, 0, 0, time.UTC)
+	t3 := time.Date(2018, 6, 3, 10, 0, 0, 0, time.UTC)
+
+	recorded := []env.ParamChange{
+		{Component: "deployment", Param: "replicas", Value: "3", Author: "alice", Time: t1},
+		{Component: "deployment", Param: "replicas", Deleted: true, Author: "bob", Time: t3, Message: "use autoscaler"},
+	}
+	blamed := []env.ParamChange{
+		{Component: "deployment", Param: "replicas", Value: "3", Author: "alice", Time: t1, Commit: "0123456789abcdef0123456789abcdef01234567"},
+		{Component: "deployment", Param: "image", Value: "'nginx:1.15'", Author: "carol", Time: t2, Message: "bump nginx", Commit: "89abcdef0123456789abcdef0123456789abcdef"},
+	}
+
+	cases := []struct {
+		name          string
+		componentName string
+		output        string
+		expected      string
+	}{
+		{name: "component", componentName: "deployment", expected: "param/history/component.txt"},
+		{name: "component json", componentName: "deployment", output: "json", expected: "param/history/component.json"},
+		{name: "all components", expected: "param/history/all.txt"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				in := map[string]interface{}{
+					OptionApp:           appMock,
+					OptionComponentName: tc.componentName,
+					OptionEnvName:       "prod",
+					OptionOutput:        tc.output,
+				}
+
+				a, err := NewParamHistory(in)
+				require.NoError(t, err)
+
+				var buf bytes.Buffer
+				a.out = &buf
+				a.historyFn = func(_ app.App, envName, component string) ([]env.ParamChange, error) {
+					assert.Equal(t, "prod", envName)
+					assert.Equal(t, tc.componentName, component)
+					return recorded, nil
+				}
+				a.blameFn = func(_ app.App, envName, component string) ([]env.ParamChange, error) {
+					assert.Equal(t, "deployment", component)
+					return blamed, nil
+				}
+
+				require.NoError(t, a.Run())
+
+				assertOutput(t, tc.expected, buf.String())
+			})
+		})
+	}
+}
+
+func TestParamHistory_requires_env(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionApp: appMock,
+		}
+
+		_, err := NewParamHistory(in)
+		require.Error(t, err)
+	})
+}
@@ -33,7 +33,17 @@ type findModuleFn func(a app.App, moduleName string) (component.Module, error)
 
 // RunParamList runs `param list`.
 func RunParamList(m map[string]interface{}) error {
-	pl, err := NewParamList(m)
+	var o ParamListOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunParamListWithOptions(o)
+}
+
+// RunParamListWithOptions runs `param list` with typed options.
+func RunParamListWithOptions(o ParamListOptions) error {
+	pl, err := NewParamListWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -64,24 +74,47 @@ type ParamList struct {
 	lister          paramsLister
 }
 
-// NewParamList creates an instances of ParamList.
+// ParamListOptions are the options for ParamList.
+type ParamListOptions struct {
+	App            app.App   `option:"app"`
+	Module         string    `option:"module,optional"`
+	ComponentName  string    `option:"component-name,optional"`
+	EnvName        string    `option:"env-name,optional"`
+	Output         string    `option:"output,optional"`
+	WithoutModules bool      `option:"without-modules,optional"`
+	Out            io.Writer `option:"out,optional"`
+}
+
+// NewParamList creates an instances of ParamList from an option map.
 func NewParamList(m map[string]interface{}) (*ParamList, error) {
-	ol := newOptionLoader(m)
+	var o ParamListOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return NewParamListWithOptions(o)
+}
+
+// NewParamListWithOptions creates an instances of ParamList.
+func NewParamListWithOptions(o ParamListOptions) (*ParamList, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
 
 	pl := &ParamList{
-		app:            ol.LoadApp(),
-		moduleName:     ol.LoadOptionalString(OptionModule),
-		componentName:  ol.LoadOptionalString(OptionComponentName),
-		envName:        ol.LoadOptionalString(OptionEnvName),
-		outputType:     ol.LoadOptionalString(OptionOutput),
-		withoutModules: ol.LoadOptionalBool(OptionWithoutModules),
+		app:            o.App,
+		moduleName:     o.Module,
+		componentName:  o.ComponentName,
+		envName:        o.EnvName,
+		outputType:     o.Output,
+		withoutModules: o.WithoutModules,
 
 		out:          os.Stdout,
 		findModuleFn: component.GetModule,
 	}
 
-	if ol.err != nil {
-		return nil, ol.err
+	if o.Out != nil {
+		pl.out = o.Out
 	}
 
 	p := pipeline.New(pl.app, pl.envName)
@@ -29,7 +29,17 @@ import (
 
 // RunParamSet runs `param set`
 func RunParamSet(m map[string]interface{}) error {
-	ps, err := NewParamSet(m)
+	var o ParamSetOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunParamSetWithOptions(o)
+}
+
+// RunParamSetWithOptions runs `param set` with typed options.
+func RunParamSetWithOptions(o ParamSetOptions) error {
+	ps, err := NewParamSetWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -47,37 +57,62 @@ type ParamSet struct {
 	envName      string
 	asString     bool
 	resolveImage bool
+	message      string
 
 	getModuleFn    getModuleFn
 	resolvePathFn  func(a app.App, path string) (component.Module, component.Component, error)
 	setEnvFn       func(ksApp app.App, envName, name, pName, value string) error
 	setGlobalEnvFn func(ksApp app.App, envName, pName, value string) error
 	resolveImageFn func(image string) (string, error)
+	recordFn       func(a app.App, envName string, change env.ParamChange) error
 }
 
-// NewParamSet creates an instance of ParamSet.
+// ParamSetOptions are the options for ParamSet.
+type ParamSetOptions struct {
+	App          app.App `option:"app"`
+	Name         string  `option:"name,optional"`
+	Path         string  `option:"path"`
+	Value        string  `option:"value"`
+	Global       bool    `option:"global,optional"`
+	EnvName      string  `option:"env-name,optional"`
+	AsString     bool    `option:"as-string,optional"`
+	ResolveImage bool    `option:"resolve-image,optional"`
+	Message      string  `option:"message,optional"`
+}
+
+// NewParamSet creates an instance of ParamSet from an option map.
 func NewParamSet(m map[string]interface{}) (*ParamSet, error) {
-	ol := newOptionLoader(m)
+	var o ParamSetOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return NewParamSetWithOptions(o)
+}
+
+// NewParamSetWithOptions creates an instance of ParamSet.
+func NewParamSetWithOptions(o ParamSetOptions) (*ParamSet, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
 
 	ps := &ParamSet{
-		app:          ol.LoadApp(),
-		name:         ol.LoadOptionalString(OptionName),
-		rawPath:      ol.LoadString(OptionPath),
-		rawValue:     ol.LoadString(OptionValue),
-		global:       ol.LoadOptionalBool(OptionGlobal),
-		envName:      ol.LoadOptionalString(OptionEnvName),
-		asString:     ol.LoadOptionalBool(OptionAsString),
-		resolveImage: ol.LoadOptionalBool(OptionResolveImage),
+		app:          o.App,
+		name:         o.Name,
+		rawPath:      o.Path,
+		rawValue:     o.Value,
+		global:       o.Global,
+		envName:      o.EnvName,
+		asString:     o.AsString,
+		resolveImage: o.ResolveImage,
+		message:      o.Message,
 
 		getModuleFn:    component.GetModule,
 		resolvePathFn:  component.ResolvePath,
 		setEnvFn:       setEnv,
 		setGlobalEnvFn: setGlobalEnv,
 		resolveImageFn: dockerregistry.ResolveImage,
-	}
-
-	if ol.err != nil {
-		return nil, ol.err
+		recordFn:       env.RecordParamChange,
 	}
 
 	if ps.envName != "" && ps.global {
@@ -113,9 +148,21 @@ func (ps *ParamSet) Run() error {
 		}
 
 		if ps.name != "" {
-			return ps.setEnvFn(ps.app, ps.envName, ps.name, ps.rawPath, value)
+			err = ps.setEnvFn(ps.app, ps.envName, ps.name, ps.rawPath, value)
+		} else {
+			err = ps.setGlobalEnvFn(ps.app, ps.envName, ps.rawPath, value)
+		}
+		if err != nil {
+			return err
+		}
+
+		change := env.ParamChange{
+			Component: ps.name,
+			Param:     ps.rawPath,
+			Value:     value,
+			Message:   ps.message,
 		}
-		return ps.setGlobalEnvFn(ps.app, ps.envName, ps.rawPath, value)
+		return errors.Wrap(ps.recordFn(ps.app, ps.envName, change), "record param history")
 	}
 
 	path := strings.Split(ps.rawPath, ".")
@@ -22,6 +22,7 @@ import (
 	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
 	"github.com/ksonnet/ksonnet/pkg/component"
 	cmocks "github.com/ksonnet/ksonnet/pkg/component/mocks"
+	"github.com/ksonnet/ksonnet/pkg/env"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -135,6 +136,9 @@ func TestParamSet_resolveImage(t *testing.T) {
 		a.resolveImageFn = func(string) (string, error) {
 			return "foo/bar@sha256:abcde", nil
 		}
+		a.recordFn = func(app.App, string, env.ParamChange) error {
+			return nil
+		}
 
 		err = a.Run()
 		require.NoError(t, err)
@@ -182,6 +186,7 @@ func TestParamSet_env(t *testing.T) {
 			OptionPath:    path,
 			OptionValue:   value,
 			OptionEnvName: "default",
+			OptionMessage: "scale up",
 		}
 
 		a, err := NewParamSet(in)
@@ -196,8 +201,20 @@ func TestParamSet_env(t *testing.T) {
 		}
 		a.setEnvFn = envSetter
 
+		var recorded []env.ParamChange
+		a.recordFn = func(_ app.App, envName string, change env.ParamChange) error {
+			assert.Equal(t, "default", envName)
+			recorded = append(recorded, change)
+			return nil
+		}
+
 		err = a.Run()
 		require.NoError(t, err)
+
+		expected := []env.ParamChange{
+			{Component: "deployment", Param: "replicas", Value: "3", Message: "scale up"},
+		}
+		assert.Equal(t, expected, recorded)
 	})
 }
 
@@ -230,6 +247,9 @@ func TestParamSet_env_resolveImage(t *testing.T) {
 		a.resolveImageFn = func(string) (string, error) {
 			return "foo/bar@sha256:abcde", nil
 		}
+		a.recordFn = func(app.App, string, env.ParamChange) error {
+			return nil
+		}
 
 		err = a.Run()
 		require.NoError(t, err)
@@ -258,6 +278,9 @@ func TestParamSet_envGlobal(t *testing.T) {
 			return nil
 		}
 		a.setGlobalEnvFn = envSetter
+		a.recordFn = func(app.App, string, env.ParamChange) error {
+			return nil
+		}
 
 		err = a.Run()
 		require.NoError(t, err)
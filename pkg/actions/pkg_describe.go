@@ -22,12 +22,25 @@ import (
 
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/pkg"
+	"github.com/ksonnet/ksonnet/pkg/prototype"
 	"github.com/ksonnet/ksonnet/pkg/registry"
+	"github.com/ksonnet/ksonnet/pkg/util/table"
+	"github.com/pkg/errors"
 )
 
 // RunPkgDescribe runs `pkg install`
 func RunPkgDescribe(m map[string]interface{}) error {
-	pd, err := NewPkgDescribe(m)
+	var o PkgDescribeOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunPkgDescribeWithOptions(o)
+}
+
+// RunPkgDescribeWithOptions runs `pkg install` with typed options.
+func RunPkgDescribeWithOptions(o PkgDescribeOptions) error {
+	pd, err := NewPkgDescribeWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -37,32 +50,49 @@ func RunPkgDescribe(m map[string]interface{}) error {
 
 // PkgDescribe describes a package.
 type PkgDescribe struct {
-	app     app.App
-	pkgName string
+	app        app.App
+	pkgName    string
+	outputType string
 
 	templateSrc    string
 	out            io.Writer
 	packageManager registry.PackageManager
 }
 
-// NewPkgDescribe creates an instance of PkgDescribe.
+// PkgDescribeOptions are the options for PkgDescribe.
+type PkgDescribeOptions struct {
+	App           app.App `option:"app"`
+	PackageName   string  `option:"package-name"`
+	Output        string  `option:"output,optional"`
+	TLSSkipVerify bool    `option:"tls-skip-verify,optional"`
+}
+
+// NewPkgDescribe creates an instance of PkgDescribe from an option map.
 func NewPkgDescribe(m map[string]interface{}) (*PkgDescribe, error) {
-	ol := newOptionLoader(m)
+	var o PkgDescribeOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return NewPkgDescribeWithOptions(o)
+}
+
+// NewPkgDescribeWithOptions creates an instance of PkgDescribe.
+func NewPkgDescribeWithOptions(o PkgDescribeOptions) (*PkgDescribe, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
 
-	httpClientOpt := registry.HTTPClientOpt(ol.LoadHTTPClient())
+	httpClientOpt := registry.HTTPClientOpt(newHTTPClient(o.TLSSkipVerify))
 
-	app := ol.LoadApp()
 	pd := &PkgDescribe{
-		app:     app,
-		pkgName: ol.LoadString(OptionPackageName),
+		app:        o.App,
+		pkgName:    o.PackageName,
+		outputType: o.Output,
 
 		templateSrc:    pkgDescribeTemplate,
 		out:            os.Stdout,
-		packageManager: registry.NewPackageManager(app, httpClientOpt),
-	}
-
-	if ol.err != nil {
-		return nil, ol.err
+		packageManager: registry.NewPackageManager(o.App, httpClientOpt),
 	}
 
 	return pd, nil
@@ -80,9 +110,9 @@ func (pd *PkgDescribe) Run() error {
 		return err
 	}
 
-	data := map[string]interface{}{
-		"Name":        pd.pkgName,
-		"Description": p.Description(),
+	f, err := table.DetectFormat(pd.outputType)
+	if err != nil {
+		return errors.Wrap(err, "detecting output format")
 	}
 
 	isInstalled, err := p.IsInstalled()
@@ -90,15 +120,22 @@ func (pd *PkgDescribe) Run() error {
 		return err
 	}
 
-	data["IsInstalled"] = isInstalled
-
+	var prototypes prototype.Prototypes
 	if isInstalled {
-		prototypes, err := p.Prototypes()
-		if err != nil {
+		if prototypes, err = p.Prototypes(); err != nil {
 			return err
 		}
+	}
 
-		data["Prototypes"] = prototypes
+	if f != table.FormatTable {
+		return table.Encode(pd.out, f, newPkgDescription(pd.pkgName, p.Description(), isInstalled, prototypes))
+	}
+
+	data := map[string]interface{}{
+		"Name":        pd.pkgName,
+		"Description": p.Description(),
+		"IsInstalled": isInstalled,
+		"Prototypes":  prototypes,
 	}
 
 	t, err := template.New("pkg-describe").Parse(pd.templateSrc)
@@ -113,6 +150,38 @@ func (pd *PkgDescribe) Run() error {
 	return nil
 }
 
+// pkgDescription is the description of a package in the structured output
+// formats.
+type pkgDescription struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Installed   bool                   `json:"installed"`
+	Prototypes  []prototypeDescription `json:"prototypes,omitempty"`
+}
+
+// prototypeDescription is a prototype in a package description.
+type prototypeDescription struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func newPkgDescription(name, description string, installed bool, prototypes prototype.Prototypes) pkgDescription {
+	d := pkgDescription{
+		Name:        name,
+		Description: description,
+		Installed:   installed,
+	}
+
+	for _, p := range prototypes {
+		d.Prototypes = append(d.Prototypes, prototypeDescription{
+			Name:        p.Name,
+			Description: p.Template.ShortDescription,
+		})
+	}
+
+	return d
+}
+
 const pkgDescribeTemplate = `LIBRARY NAME:
 {{.Name}}
 
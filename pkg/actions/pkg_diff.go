@@ -0,0 +1,416 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/pkg"
+	"github.com/ksonnet/ksonnet/pkg/prototype"
+	"github.com/ksonnet/ksonnet/pkg/registry"
+	"github.com/ksonnet/ksonnet/pkg/util/table"
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+const (
+	pkgDiffAdded    = "added"
+	pkgDiffRemoved  = "removed"
+	pkgDiffModified = "modified"
+)
+
+type packageFilesResolver func(a app.App, d pkg.Descriptor) (map[string][]byte, string, error)
+
+// RunPkgDiff runs `pkg diff`
+func RunPkgDiff(m map[string]interface{}) error {
+	var o PkgDiffOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunPkgDiffWithOptions(o)
+}
+
+// RunPkgDiffWithOptions runs `pkg diff` with typed options.
+func RunPkgDiffWithOptions(o PkgDiffOptions) error {
+	pd, err := NewPkgDiffWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return pd.Run()
+}
+
+// PkgDiff shows the differences between two versions of a package.
+type PkgDiff struct {
+	app         app.App
+	pkgName     string
+	fromVersion string
+	toVersion   string
+	outputType  string
+
+	out                   io.Writer
+	resolveDescriptorFn   descriptorResolver
+	resolvePackageFilesFn packageFilesResolver
+}
+
+// PkgDiffOptions are the options for PkgDiff.
+type PkgDiffOptions struct {
+	App           app.App `option:"app"`
+	PkgName       string  `option:"pkg-name"`
+	FromVersion   string  `option:"from-version"`
+	ToVersion     string  `option:"to-version"`
+	Output        string  `option:"output,optional"`
+	TLSSkipVerify bool    `option:"tls-skip-verify,optional"`
+}
+
+// NewPkgDiff creates an instance of PkgDiff from an option map.
+func NewPkgDiff(m map[string]interface{}) (*PkgDiff, error) {
+	var o PkgDiffOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return NewPkgDiffWithOptions(o)
+}
+
+// NewPkgDiffWithOptions creates an instance of PkgDiff.
+func NewPkgDiffWithOptions(o PkgDiffOptions) (*PkgDiff, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	httpClient := newHTTPClient(o.TLSSkipVerify)
+
+	pd := &PkgDiff{
+		app:         o.App,
+		pkgName:     o.PkgName,
+		fromVersion: o.FromVersion,
+		toVersion:   o.ToVersion,
+		outputType:  o.Output,
+
+		out: os.Stdout,
+		resolveDescriptorFn: func(a app.App, d pkg.Descriptor, registryName string) (pkg.Descriptor, error) {
+			return registry.ResolveDescriptor(a, d, registryName, httpClient)
+		},
+		resolvePackageFilesFn: func(a app.App, d pkg.Descriptor) (map[string][]byte, string, error) {
+			return registry.ResolvePackageFiles(a, d, httpClient)
+		},
+	}
+
+	return pd, nil
+}
+
+// Run shows the differences between two versions of a package.
+func (pd *PkgDiff) Run() error {
+	d, err := pkg.Parse(pd.pkgName)
+	if err != nil {
+		return err
+	}
+	if d.Version != "" {
+		return errors.Errorf("package %q should not include a version; versions are given separately", pd.pkgName)
+	}
+
+	d, err = pd.resolveDescriptorFn(pd.app, d, "")
+	if err != nil {
+		return err
+	}
+
+	f, err := table.DetectFormat(pd.outputType)
+	if err != nil {
+		return errors.Wrap(err, "detecting output format")
+	}
+
+	from, err := pd.fetch(d, pd.fromVersion)
+	if err != nil {
+		return err
+	}
+
+	to, err := pd.fetch(d, pd.toVersion)
+	if err != nil {
+		return err
+	}
+
+	diff, err := newPkgDiffResult(d, from, to)
+	if err != nil {
+		return err
+	}
+
+	if f != table.FormatTable {
+		return table.Encode(pd.out, f, diff)
+	}
+
+	return pd.print(diff)
+}
+
+// pkgVersion is the contents of a package at a version.
+type pkgVersion struct {
+	version string
+	files   map[string][]byte
+}
+
+func (pd *PkgDiff) fetch(d pkg.Descriptor, version string) (*pkgVersion, error) {
+	d.Version = version
+
+	files, resolved, err := pd.resolvePackageFilesFn(pd.app, d)
+	if err != nil {
+		return nil, err
+	}
+
+	if resolved == "" {
+		resolved = version
+	}
+
+	return &pkgVersion{version: resolved, files: files}, nil
+}
+
+func (pd *PkgDiff) print(diff *pkgDiffResult) error {
+	if len(diff.Files) == 0 {
+		_, err := fmt.Fprintf(pd.out, "No differences between %s and %s\n", diff.From, diff.To)
+		return err
+	}
+
+	t := table.New("pkgDiffFiles", pd.out)
+	t.SetHeader([]string{"file", "change"})
+	for _, file := range diff.Files {
+		t.Append([]string{file.Path, file.Change})
+	}
+	if err := t.Render(); err != nil {
+		return err
+	}
+
+	if len(diff.Prototypes) > 0 {
+		fmt.Fprintln(pd.out)
+
+		t = table.New("pkgDiffPrototypes", pd.out)
+		t.SetHeader([]string{"prototype", "change", "params"})
+		for _, p := range diff.Prototypes {
+			t.Append([]string{p.Name, p.Change, strings.Join(p.Params, ", ")})
+		}
+		if err := t.Render(); err != nil {
+			return err
+		}
+	}
+
+	for _, file := range diff.Files {
+		if file.Diff == "" {
+			continue
+		}
+		fmt.Fprintln(pd.out)
+		fmt.Fprint(pd.out, file.Diff)
+	}
+
+	return nil
+}
+
+// pkgDiffResult is the difference between two versions of a package.
+type pkgDiffResult struct {
+	Package    string          `json:"package"`
+	From       string          `json:"from"`
+	To         string          `json:"to"`
+	Files      []fileDiff      `json:"files"`
+	Prototypes []prototypeDiff `json:"prototypes"`
+}
+
+// fileDiff is a file which changed between two versions of a package.
+type fileDiff struct {
+	Path   string `json:"path"`
+	Change string `json:"change"`
+	Diff   string `json:"diff,omitempty"`
+}
+
+// prototypeDiff is a prototype which changed between two versions of a
+// package. Params lists the parameters which were added (+), removed (-) or
+// modified (~).
+type prototypeDiff struct {
+	Name   string   `json:"name"`
+	Change string   `json:"change"`
+	Params []string `json:"params,omitempty"`
+}
+
+func newPkgDiffResult(d pkg.Descriptor, from, to *pkgVersion) (*pkgDiffResult, error) {
+	d.Version = ""
+
+	result := &pkgDiffResult{
+		Package:    d.String(),
+		From:       from.version,
+		To:         to.version,
+		Files:      []fileDiff{},
+		Prototypes: []prototypeDiff{},
+	}
+
+	for _, path := range unionKeys(from.files, to.files) {
+		before, inFrom := from.files[path]
+		after, inTo := to.files[path]
+
+		var change string
+		switch {
+		case !inFrom:
+			change = pkgDiffAdded
+		case !inTo:
+			change = pkgDiffRemoved
+		case string(before) != string(after):
+			change = pkgDiffModified
+		default:
+			continue
+		}
+
+		text, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        diffLines(before),
+			B:        diffLines(after),
+			FromFile: from.version + "/" + path,
+			ToFile:   to.version + "/" + path,
+			Context:  3,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "comparing %s", path)
+		}
+
+		result.Files = append(result.Files, fileDiff{Path: path, Change: change, Diff: text})
+	}
+
+	prototypes, err := diffPrototypes(from.files, to.files)
+	if err != nil {
+		return nil, err
+	}
+	result.Prototypes = prototypes
+
+	return result, nil
+}
+
+func diffPrototypes(from, to map[string][]byte) ([]prototypeDiff, error) {
+	fromPrototypes, err := prototypesByName(from)
+	if err != nil {
+		return nil, err
+	}
+
+	toPrototypes, err := prototypesByName(to)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name := range fromPrototypes {
+		names = append(names, name)
+	}
+	for name := range toPrototypes {
+		if _, ok := fromPrototypes[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	diffs := []prototypeDiff{}
+	for _, name := range names {
+		before, inFrom := fromPrototypes[name]
+		after, inTo := toPrototypes[name]
+
+		switch {
+		case !inFrom:
+			diffs = append(diffs, prototypeDiff{Name: name, Change: pkgDiffAdded})
+		case !inTo:
+			diffs = append(diffs, prototypeDiff{Name: name, Change: pkgDiffRemoved})
+		default:
+			params := diffParams(before.Params, after.Params)
+			if len(params) == 0 && reflect.DeepEqual(before.Template, after.Template) {
+				continue
+			}
+			diffs = append(diffs, prototypeDiff{Name: name, Change: pkgDiffModified, Params: params})
+		}
+	}
+
+	return diffs, nil
+}
+
+func prototypesByName(files map[string][]byte) (map[string]*prototype.Prototype, error) {
+	prototypes, err := registry.PackagePrototypes(files)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]*prototype.Prototype)
+	for _, p := range prototypes {
+		m[p.Name] = p
+	}
+
+	return m, nil
+}
+
+func diffParams(from, to prototype.ParamSchemas) []string {
+	fromParams := make(map[string]*prototype.ParamSchema)
+	for _, p := range from {
+		fromParams[p.Name] = p
+	}
+
+	toParams := make(map[string]*prototype.ParamSchema)
+	for _, p := range to {
+		toParams[p.Name] = p
+	}
+
+	var changes []string
+	for _, p := range from {
+		q, ok := toParams[p.Name]
+		switch {
+		case !ok:
+			changes = append(changes, "-"+p.Name)
+		case !reflect.DeepEqual(p, q):
+			changes = append(changes, "~"+p.Name)
+		}
+	}
+	for _, p := range to {
+		if _, ok := fromParams[p.Name]; !ok {
+			changes = append(changes, "+"+p.Name)
+		}
+	}
+
+	return changes
+}
+
+// diffLines splits contents into lines, keeping their line endings.
+func diffLines(contents []byte) []string {
+	if len(contents) == 0 {
+		return nil
+	}
+
+	lines := strings.SplitAfter(string(contents), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+func unionKeys(a, b map[string][]byte) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, m := range []map[string][]byte{a, b} {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
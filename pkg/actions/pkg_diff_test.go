@@ -0,0 +1,182 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/pkg"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const pkgDiffPrototypeV1 = `// @apiVersion 0.0.1
+// @name io.ksonnet.pkg.redis-stateless
+// @description Redis without persistence.
+// @shortDescription Redis without persistence.
+// @param name string Name of the deployment
+// @optionalParam image string redis:4.0 Image to deploy
+
+local redis = import 'incubator/redis/redis.libsonnet';
+redis.parts.deployment(import 'param://name', import 'param://image')
+`
+
+const pkgDiffPrototypeV2 = `// @apiVersion 0.0.1
+// @name io.ksonnet.pkg.redis-stateless
+// @description Redis without persistence.
+// @shortDescription Redis without persistence.
+// @param name string Name of the deployment
+// @optionalParam image string redis:5.0 Image to deploy
+// @optionalParam replicas number 1 Number of replicas
+
+local redis = import 'incubator/redis/redis.libsonnet';
+redis.parts.deployment(import 'param://name', import 'param://image', import 'param://replicas')
+`
+
+const pkgDiffPrototypePersistent = `// @apiVersion 0.0.1
+// @name io.ksonnet.pkg.redis-persistent
+// @description Redis with persistence.
+// @shortDescription Redis with persistence.
+// @param name string Name of the deployment
+
+local redis = import 'incubator/redis/redis.libsonnet';
+redis.parts.statefulSet(import 'param://name')
+`
+
+func TestPkgDiff(t *testing.T) {
+	versions := map[string]map[string][]byte{
+		"0.1.0": {
+			"README.md":                          []byte("# redis\n"),
+			"parts.yaml":                         []byte("name: redis\nversion: 0.1.0\n"),
+			"prototypes/redis-stateless.jsonnet": []byte(pkgDiffPrototypeV1),
+		},
+		"0.2.0": {
+			"README.md":                           []byte("# redis\n"),
+			"parts.yaml":                          []byte("name: redis\nversion: 0.2.0\n"),
+			"prototypes/redis-stateless.jsonnet":  []byte(pkgDiffPrototypeV2),
+			"prototypes/redis-persistent.jsonnet": []byte(pkgDiffPrototypePersistent),
+		},
+	}
+
+	cases := []struct {
+		name       string
+		pkgName    string
+		toVersion  string
+		outputType string
+		outputName string
+		isErr      bool
+	}{
+		{
+			name:       "output table",
+			pkgName:    "redis",
+			toVersion:  "0.2.0",
+			outputName: filepath.Join("pkg", "diff", "output.txt"),
+		},
+		{
+			name:       "output json",
+			pkgName:    "incubator/redis",
+			toVersion:  "0.2.0",
+			outputType: "json",
+			outputName: filepath.Join("pkg", "diff", "output.json"),
+		},
+		{
+			name:       "no differences",
+			pkgName:    "redis",
+			toVersion:  "0.1.0",
+			outputName: filepath.Join("pkg", "diff", "same.txt"),
+		},
+		{
+			name:      "unknown version",
+			pkgName:   "redis",
+			toVersion: "0.3.0",
+			isErr:     true,
+		},
+		{
+			name:      "versioned package name",
+			pkgName:   "redis@0.1.0",
+			toVersion: "0.2.0",
+			isErr:     true,
+		},
+		{
+			name:       "invalid output type",
+			pkgName:    "redis",
+			toVersion:  "0.2.0",
+			outputType: "invalid",
+			isErr:      true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				in := map[string]interface{}{
+					OptionApp:         appMock,
+					OptionPkgName:     tc.pkgName,
+					OptionFromVersion: "0.1.0",
+					OptionToVersion:   tc.toVersion,
+					OptionOutput:      tc.outputType,
+				}
+
+				a, err := NewPkgDiff(in)
+				require.NoError(t, err)
+
+				a.resolveDescriptorFn = func(_ app.App, d pkg.Descriptor, registryName string) (pkg.Descriptor, error) {
+					d.Registry = "incubator"
+					return d, nil
+				}
+
+				var fetched []pkg.Descriptor
+				a.resolvePackageFilesFn = func(_ app.App, d pkg.Descriptor) (map[string][]byte, string, error) {
+					fetched = append(fetched, d)
+					files, ok := versions[d.Version]
+					if !ok {
+						return nil, "", errors.Errorf("unknown version %s", d.Version)
+					}
+					return files, d.Version, nil
+				}
+
+				var buf bytes.Buffer
+				a.out = &buf
+
+				err = a.Run()
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+
+				expected := []pkg.Descriptor{
+					{Registry: "incubator", Name: "redis", Version: "0.1.0"},
+					{Registry: "incubator", Name: "redis", Version: tc.toVersion},
+				}
+				assert.Equal(t, expected, fetched)
+
+				assertOutput(t, tc.outputName, buf.String())
+			})
+		})
+	}
+}
+
+func TestPkgDiff_requires_app(t *testing.T) {
+	in := make(map[string]interface{})
+	_, err := NewPkgDiff(in)
+	require.Error(t, err)
+}
@@ -17,6 +17,7 @@ package actions
 
 import (
 	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/env"
 	"github.com/ksonnet/ksonnet/pkg/pkg"
 	"github.com/ksonnet/ksonnet/pkg/registry"
 	"github.com/pkg/errors"
@@ -28,9 +29,21 @@ type libUpdater func(name string, env string, spec *app.LibraryConfig) (*app.Lib
 
 type envChecker func(name string) (bool, error)
 
+type descriptorResolver func(a app.App, d pkg.Descriptor, registryName string) (pkg.Descriptor, error)
+
 // RunPkgInstall runs `pkg install`
 func RunPkgInstall(m map[string]interface{}) error {
-	pi, err := NewPkgInstall(m)
+	var o PkgInstallOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunPkgInstallWithOptions(o)
+}
+
+// RunPkgInstallWithOptions runs `pkg install` with typed options.
+func RunPkgInstallWithOptions(o PkgInstallOptions) error {
+	pi, err := NewPkgInstallWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -40,39 +53,66 @@ func RunPkgInstall(m map[string]interface{}) error {
 
 // PkgInstall installs packages.
 type PkgInstall struct {
-	app          app.App
-	libName      string
-	customName   string
-	envName      string
-	force        bool
-	checker      registry.InstalledChecker
-	gc           registry.GarbageCollector
-	libCacherFn  libCacher
-	libUpdateFn  libUpdater
-	envCheckerFn envChecker
+	app                 app.App
+	libName             string
+	customName          string
+	envNames            []string
+	registryName        string
+	force               bool
+	checker             registry.InstalledChecker
+	gc                  registry.GarbageCollector
+	libCacherFn         libCacher
+	libUpdateFn         libUpdater
+	envCheckerFn        envChecker
+	resolveDescriptorFn descriptorResolver
+	refreshPackagesFn   func() error
+	migrateFn           func(d pkg.Descriptor) error
+}
+
+// PkgInstallOptions are the options for PkgInstall.
+type PkgInstallOptions struct {
+	App        app.App `option:"app"`
+	PkgName    string  `option:"pkg-name"`
+	CustomName string  `option:"name"`
+	Force      bool    `option:"force"`
+	// EnvNames scope the package to environments. If it is empty, the package
+	// is installed for the app.
+	EnvNames      []string `option:"env-names,optional"`
+	Registry      string   `option:"registry,optional"`
+	TLSSkipVerify bool     `option:"tls-skip-verify,optional"`
 }
 
-// NewPkgInstall creates an instance of PkgInstall.
+// NewPkgInstall creates an instance of PkgInstall from an option map.
 func NewPkgInstall(m map[string]interface{}) (*PkgInstall, error) {
-	ol := newOptionLoader(m)
+	var o PkgInstallOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return NewPkgInstallWithOptions(o)
+}
 
-	a := ol.LoadApp()
-	if ol.err != nil {
-		return nil, ol.err
+// NewPkgInstallWithOptions creates an instance of PkgInstall.
+func NewPkgInstallWithOptions(o PkgInstallOptions) (*PkgInstall, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
 	}
-	httpClient := ol.LoadHTTPClient()
+
+	a := o.App
+	httpClient := newHTTPClient(o.TLSSkipVerify)
 	httpClientOpt := registry.HTTPClientOpt(httpClient)
 
 	pm := registry.NewPackageManager(a, httpClientOpt)
 
 	nl := &PkgInstall{
-		app:        a,
-		libName:    ol.LoadString(OptionPkgName),
-		customName: ol.LoadString(OptionName),
-		force:      ol.LoadBool(OptionForce),
-		envName:    ol.LoadOptionalString(OptionEnvName),
-		checker:    pm,
-		gc:         registry.NewGarbageCollector(a.Fs(), pm, a.VendorPath()),
+		app:          a,
+		libName:      o.PkgName,
+		customName:   o.CustomName,
+		force:        o.Force,
+		envNames:     o.EnvNames,
+		registryName: o.Registry,
+		checker:      pm,
+		gc:           registry.NewGarbageCollector(a.Fs(), pm, a.VendorPath()),
 
 		libCacherFn: func(a app.App, checker registry.InstalledChecker, d pkg.Descriptor, customName string, force bool) (*app.LibraryConfig, error) {
 			return registry.CacheDependency(a, checker, d, customName, force, httpClient)
@@ -86,10 +126,15 @@ func NewPkgInstall(m map[string]interface{}) (*PkgInstall, error) {
 			exists := (env != nil)
 			return exists, nil
 		},
-	}
-
-	if ol.err != nil {
-		return nil, ol.err
+		resolveDescriptorFn: func(a app.App, d pkg.Descriptor, registryName string) (pkg.Descriptor, error) {
+			return registry.ResolveDescriptor(a, d, registryName, httpClient)
+		},
+		refreshPackagesFn: func() error {
+			return refreshJsonnetPackages(a, pm, env.VendorPackages)
+		},
+		migrateFn: func(d pkg.Descriptor) error {
+			return migrateComponentParams(a, d)
+		},
 	}
 
 	return nl, nil
@@ -102,14 +147,19 @@ func (pi *PkgInstall) Run() error {
 		return err
 	}
 
+	d, err = pi.resolveDescriptorFn(pi.app, d, pi.registryName)
+	if err != nil {
+		return err
+	}
+
 	// Environment validation
-	if pi.envName != "" {
-		ok, err := pi.envCheckerFn(pi.envName)
+	for _, envName := range pi.envNames {
+		ok, err := pi.envCheckerFn(envName)
 		if err != nil {
 			return errors.Wrap(err, "checking environment")
 		}
 		if !ok {
-			return errors.Errorf("invalid environment: %s", pi.envName)
+			return errors.Errorf("invalid environment: %s", envName)
 		}
 	}
 
@@ -118,25 +168,48 @@ func (pi *PkgInstall) Run() error {
 		return err
 	}
 
-	oldCfg, err := pi.libUpdateFn(d.Name, pi.envName, libCfg)
-	if err != nil {
-		return err
+	// Without environments, the package is installed globally. Otherwise
+	// the version applies only to the selected environments, which is how
+	// library upgrades are staged.
+	envNames := pi.envNames
+	if len(envNames) == 0 {
+		envNames = []string{""}
 	}
 
-	if oldCfg == nil {
-		return nil
+	var oldCfgs []*app.LibraryConfig
+	for _, envName := range envNames {
+		cfg := *libCfg
+		oldCfg, err := pi.libUpdateFn(d.Name, envName, &cfg)
+		if err != nil {
+			return err
+		}
+
+		if oldCfg != nil {
+			oldCfgs = append(oldCfgs, oldCfg)
+		}
+	}
+
+	// Components generated from older versions of the package have their
+	// params migrated when the package is upgraded for the app.
+	if len(pi.envNames) == 0 && libCfg.Version != "" {
+		installed := pkg.Descriptor{Registry: d.Registry, Name: d.Name, Version: libCfg.Version}
+		if err := pi.migrateFn(installed); err != nil {
+			return errors.Wrap(err, "migrating component params")
+		}
 	}
 
 	// Optionally remove any orphaned vendor directories
-	if err := pi.gc.RemoveOrphans(pkg.Descriptor{
-		Registry: oldCfg.Registry,
-		Name:     oldCfg.Name,
-		Version:  oldCfg.Version,
-	}); err != nil {
-		return errors.Wrapf(err, "garbage collection for package %v", oldCfg)
+	for _, oldCfg := range oldCfgs {
+		if err := pi.gc.RemoveOrphans(pkg.Descriptor{
+			Registry: oldCfg.Registry,
+			Name:     oldCfg.Name,
+			Version:  oldCfg.Version,
+		}); err != nil {
+			return errors.Wrapf(err, "garbage collection for package %v", oldCfg)
+		}
 	}
 
-	return nil
+	return pi.refreshPackagesFn()
 }
 
 func (pi *PkgInstall) parseDepSpec() (pkg.Descriptor, string, error) {
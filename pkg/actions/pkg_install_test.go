@@ -22,7 +22,9 @@ import (
 	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
 	"github.com/ksonnet/ksonnet/pkg/pkg"
 	"github.com/ksonnet/ksonnet/pkg/registry"
+	rmocks "github.com/ksonnet/ksonnet/pkg/registry/mocks"
 	"github.com/pkg/errors"
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -64,7 +66,7 @@ func TestPkgInstall(t *testing.T) {
 		fakeUpdater := func(name string, env string, spec *app.LibraryConfig) (*app.LibraryConfig, error) {
 			updaterCalled = true
 			assert.Equal(t, newLibCfg.Name, name, "unexpected library name")
-			assert.Equal(t, a.envName, env, "unexpected environment name")
+			assert.Equal(t, "", env, "unexpected environment name")
 			assert.Equal(t, newLibCfg, spec, "unexpected library configuration object")
 			if spec != nil {
 				assert.Equal(t, expectedD.Name, spec.Name, "unexpected library name in configuration object")
@@ -93,6 +95,117 @@ func TestPkgInstall(t *testing.T) {
 	})
 }
 
+func TestPkgInstall_environments(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionApp:           appMock,
+			OptionPkgName:       "incubator/apache@2.0.0",
+			OptionName:          "",
+			OptionEnvNames:      []string{"staging", "canary"},
+			OptionForce:         false,
+			OptionTLSSkipVerify: false,
+		}
+
+		a, err := NewPkgInstall(in)
+		require.NoError(t, err)
+
+		newLibCfg := &app.LibraryConfig{
+			Registry: "incubator",
+			Name:     "apache",
+			Version:  "2.0.0",
+		}
+
+		var checked []string
+		a.envCheckerFn = func(name string) (bool, error) {
+			checked = append(checked, name)
+			return true, nil
+		}
+
+		a.libCacherFn = func(a app.App, checker registry.InstalledChecker, d pkg.Descriptor, cn string, force bool) (*app.LibraryConfig, error) {
+			return newLibCfg, nil
+		}
+
+		oldLibCfg := &app.LibraryConfig{
+			Registry: "incubator",
+			Name:     "apache",
+			Version:  "1.0.0",
+		}
+
+		var updated []string
+		a.libUpdateFn = func(name string, env string, spec *app.LibraryConfig) (*app.LibraryConfig, error) {
+			updated = append(updated, env)
+			assert.Equal(t, newLibCfg, spec, "unexpected library configuration object")
+			if env == "staging" {
+				return oldLibCfg, nil
+			}
+			return nil, nil
+		}
+
+		oldD := pkg.Descriptor{Registry: "incubator", Name: "apache", Version: "1.0.0"}
+		oldPath := "/app/vendor/incubator/apache@1.0.0"
+		fs := afero.NewMemMapFs()
+		require.NoError(t, fs.MkdirAll(oldPath, 0755))
+
+		pm := new(rmocks.PackageManager)
+		pm.On("IsInstalled", oldD).Return(false, nil)
+		pm.On("VendorPath", oldD).Return(oldPath, nil)
+		a.gc = registry.NewGarbageCollector(fs, pm, "/app/vendor")
+
+		err = a.Run()
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"staging", "canary"}, checked)
+		assert.Equal(t, []string{"staging", "canary"}, updated)
+
+		exists, err := afero.Exists(fs, oldPath)
+		require.NoError(t, err)
+		assert.False(t, exists, "orphaned version was not removed")
+	})
+}
+
+func TestPkgInstall_migrates_component_params(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionApp:           appMock,
+			OptionPkgName:       "incubator/apache@2.0.0",
+			OptionName:          "",
+			OptionForce:         true,
+			OptionTLSSkipVerify: false,
+		}
+
+		a, err := NewPkgInstall(in)
+		require.NoError(t, err)
+
+		a.resolveDescriptorFn = func(a app.App, d pkg.Descriptor, registryName string) (pkg.Descriptor, error) {
+			return d, nil
+		}
+
+		a.libCacherFn = func(a app.App, checker registry.InstalledChecker, d pkg.Descriptor, cn string, force bool) (*app.LibraryConfig, error) {
+			return &app.LibraryConfig{Registry: "incubator", Name: "apache", Version: "2.0.0"}, nil
+		}
+
+		a.libUpdateFn = func(name string, env string, spec *app.LibraryConfig) (*app.LibraryConfig, error) {
+			return nil, nil
+		}
+
+		a.refreshPackagesFn = func() error {
+			return nil
+		}
+
+		var migrated []pkg.Descriptor
+		a.migrateFn = func(d pkg.Descriptor) error {
+			migrated = append(migrated, d)
+			return nil
+		}
+
+		err = a.Run()
+		require.NoError(t, err)
+
+		expected := []pkg.Descriptor{{Registry: "incubator", Name: "apache", Version: "2.0.0"}}
+		assert.Equal(t, expected, migrated)
+	})
+}
+
 func TestPkgInstall_requires_app(t *testing.T) {
 	in := make(map[string]interface{})
 	_, err := NewPkgInstall(in)
@@ -108,7 +221,7 @@ func TestPkgInstall_invalid_env(t *testing.T) {
 			OptionApp:           appMock,
 			OptionPkgName:       libName,
 			OptionName:          customName,
-			OptionEnvName:       "invalid-env",
+			OptionEnvNames:      []string{"invalid-env"},
 			OptionForce:         false,
 			OptionTLSSkipVerify: false,
 		}
@@ -140,3 +253,74 @@ func TestPkgInstall_invalid_env(t *testing.T) {
 		assert.False(t, updaterCalled, "library reference updater called unexpectedly")
 	})
 }
+
+func TestPkgInstall_unqualified_name(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionApp:           appMock,
+			OptionPkgName:       "redis",
+			OptionName:          "",
+			OptionRegistry:      "stable",
+			OptionForce:         false,
+			OptionTLSSkipVerify: false,
+		}
+
+		a, err := NewPkgInstall(in)
+		require.NoError(t, err)
+
+		expectedD := pkg.Descriptor{
+			Registry: "stable",
+			Name:     "redis",
+		}
+
+		a.resolveDescriptorFn = func(_ app.App, d pkg.Descriptor, registryName string) (pkg.Descriptor, error) {
+			assert.Equal(t, "stable", registryName)
+			d.Registry = registryName
+			return d, nil
+		}
+
+		var cacherCalled bool
+		a.libCacherFn = func(a app.App, checker registry.InstalledChecker, d pkg.Descriptor, cn string, force bool) (*app.LibraryConfig, error) {
+			cacherCalled = true
+			require.Equal(t, expectedD, d)
+			require.Equal(t, "redis", cn)
+			return &app.LibraryConfig{Registry: "stable", Name: "redis"}, nil
+		}
+		a.libUpdateFn = func(name string, env string, spec *app.LibraryConfig) (*app.LibraryConfig, error) {
+			return nil, nil
+		}
+
+		err = a.Run()
+		require.NoError(t, err)
+		assert.True(t, cacherCalled, "dependency cacher not called")
+	})
+}
+
+func TestPkgInstall_unresolved_name(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionApp:           appMock,
+			OptionPkgName:       "redis",
+			OptionName:          "",
+			OptionForce:         false,
+			OptionTLSSkipVerify: false,
+		}
+
+		a, err := NewPkgInstall(in)
+		require.NoError(t, err)
+
+		a.resolveDescriptorFn = func(app.App, pkg.Descriptor, string) (pkg.Descriptor, error) {
+			return pkg.Descriptor{}, errors.New("ambiguous")
+		}
+
+		var cacherCalled bool
+		a.libCacherFn = func(a app.App, checker registry.InstalledChecker, d pkg.Descriptor, cn string, force bool) (*app.LibraryConfig, error) {
+			cacherCalled = true
+			return nil, errors.New("not implemented")
+		}
+
+		err = a.Run()
+		require.Error(t, err)
+		assert.False(t, cacherCalled, "dependency cacher called unexpectedly")
+	})
+}
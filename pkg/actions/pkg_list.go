@@ -26,6 +26,7 @@ import (
 	"github.com/ksonnet/ksonnet/pkg/registry"
 	"github.com/ksonnet/ksonnet/pkg/util/table"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 )
 
 const (
@@ -35,7 +36,17 @@ const (
 
 // RunPkgList runs `pkg list`
 func RunPkgList(m map[string]interface{}) error {
-	rl, err := NewPkgList(m)
+	var o PkgListOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunPkgListWithOptions(o)
+}
+
+// RunPkgListWithOptions runs `pkg list` with typed options.
+func RunPkgListWithOptions(o PkgListOptions) error {
+	rl, err := NewPkgListWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -54,19 +65,38 @@ type PkgList struct {
 	out            io.Writer
 }
 
-// NewPkgList creates an instance of PkgList
+// PkgListOptions are the options for PkgList.
+type PkgListOptions struct {
+	App           app.App `option:"app"`
+	OnlyInstalled bool    `option:"only-installed"`
+	Output        string  `option:"output,optional"`
+	TLSSkipVerify bool    `option:"tls-skip-verify,optional"`
+}
+
+// NewPkgList creates an instance of PkgList from an option map.
 func NewPkgList(m map[string]interface{}) (*PkgList, error) {
-	ol := newOptionLoader(m)
+	var o PkgListOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return NewPkgListWithOptions(o)
+}
 
-	a := ol.LoadApp()
-	httpClient := ol.LoadHTTPClient()
+// NewPkgListWithOptions creates an instance of PkgList.
+func NewPkgListWithOptions(o PkgListOptions) (*PkgList, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	httpClient := newHTTPClient(o.TLSSkipVerify)
 	httpClientOpt := registry.HTTPClientOpt(httpClient)
 
 	rl := &PkgList{
-		app:           a,
-		pm:            registry.NewPackageManager(a, httpClientOpt),
-		onlyInstalled: ol.LoadBool(OptionInstalled),
-		outputType:    ol.LoadOptionalString(OptionOutput),
+		app:           o.App,
+		pm:            registry.NewPackageManager(o.App, httpClientOpt),
+		onlyInstalled: o.OnlyInstalled,
+		outputType:    o.Output,
 
 		registryListFn: func(ksApp app.App) ([]registry.Registry, error) {
 			return registry.List(ksApp, httpClient)
@@ -74,10 +104,6 @@ func NewPkgList(m map[string]interface{}) (*PkgList, error) {
 		out: os.Stdout,
 	}
 
-	if ol.err != nil {
-		return nil, ol.err
-	}
-
 	return rl, nil
 }
 
@@ -111,11 +137,15 @@ func (pl *PkgList) Run() error {
 
 	// Build output
 	var rows [][]string
+	var installed []pkg.Descriptor
 	for _, p := range index {
 		isInstalled, err := p.IsInstalled()
 		if err != nil {
 			return err
 		}
+		if isInstalled {
+			installed = append(installed, pkg.Descriptor{Registry: p.RegistryName(), Name: p.Name(), Version: p.Version()})
+		}
 		envs, err := envListForPackage(pl.pm, p)
 		if err != nil {
 			return err
@@ -141,7 +171,30 @@ func (pl *PkgList) Run() error {
 
 	t.SetHeader([]string{"registry", "name", "version", "installed", "environments"})
 	t.AppendBulk(rows)
-	return t.Render()
+	if err := t.Render(); err != nil {
+		return err
+	}
+
+	warnDeprecated(pl.app, pl.registryListFn, installed)
+	return nil
+}
+
+// warnDeprecated logs a warning for each installed package which its
+// registry has deprecated.
+func warnDeprecated(a app.App, registryListFn func(app.App) ([]registry.Registry, error), installed []pkg.Descriptor) {
+	if len(installed) == 0 {
+		return
+	}
+
+	registries, err := registryListFn(a)
+	if err != nil {
+		log.WithError(err).Debug("listing registries to check for deprecated packages")
+		return
+	}
+
+	for _, warning := range registry.DeprecationWarnings(registries, installed) {
+		log.Warn(warning)
+	}
 }
 
 func (pl *PkgList) addRow(regName, libName, version string, isInstalled bool, envs string) []string {
@@ -25,6 +25,7 @@ import (
 	"github.com/ksonnet/ksonnet/pkg/pkg"
 	pmocks "github.com/ksonnet/ksonnet/pkg/pkg/mocks"
 	"github.com/ksonnet/ksonnet/pkg/prototype"
+	"github.com/ksonnet/ksonnet/pkg/registry"
 	rmocks "github.com/ksonnet/ksonnet/pkg/registry/mocks"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
@@ -162,6 +163,23 @@ func TestPkgList(t *testing.T) {
 				require.NoError(t, err)
 				a.pm = &pmMock
 
+				var checkedDeprecations bool
+				a.registryListFn = func(app.App) ([]registry.Registry, error) {
+					checkedDeprecations = true
+
+					incubator := mockRegistry("incubator", false)
+					incubator.On("FetchRegistrySpec").Return(&registry.Spec{
+						Libraries: registry.LibraryConfigs{
+							"lib1": &registry.LibraryConfig{
+								Version:     "0.0.2",
+								Deprecated:  true,
+								Replacement: "incubator/lib2",
+							},
+						},
+					}, nil)
+					return []registry.Registry{incubator}, nil
+				}
+
 				var buf bytes.Buffer
 				a.out = &buf
 
@@ -173,6 +191,7 @@ func TestPkgList(t *testing.T) {
 				require.NoError(t, err)
 
 				assertOutput(t, tc.outputName, buf.String())
+				assert.True(t, checkedDeprecations, "installed packages not checked for deprecations")
 			})
 		}
 
@@ -0,0 +1,423 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	param "github.com/ksonnet/ksonnet/metadata/params"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	"github.com/ksonnet/ksonnet/pkg/env"
+	"github.com/ksonnet/ksonnet/pkg/params"
+	"github.com/ksonnet/ksonnet/pkg/parts"
+	"github.com/ksonnet/ksonnet/pkg/pkg"
+	"github.com/ksonnet/ksonnet/pkg/util/jsonnet"
+	"github.com/ksonnet/ksonnet/pkg/util/version"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// jsonnetValue is an environment param whose Jsonnet value can't be decoded.
+// It can be renamed or removed, but not converted.
+type jsonnetValue string
+
+// componentOrigin is the prototype, and the package and version of the
+// prototype, a component was generated from.
+type componentOrigin struct {
+	prototype string
+	pkg       pkg.Descriptor
+}
+
+// readComponentOrigin reads the directives at the top of a component. It
+// returns false if the component doesn't record the package it came from.
+func readComponentOrigin(text string) (componentOrigin, bool) {
+	o := component.ReadOrigin(text)
+	if o.Package == "" {
+		return componentOrigin{}, false
+	}
+
+	d, err := pkg.Parse(o.Package)
+	if err != nil {
+		return componentOrigin{}, false
+	}
+
+	return componentOrigin{prototype: o.Prototype, pkg: d}, true
+}
+
+// migrateComponentParams rewrites the params of components generated from
+// an older version of a package, with the migrations the package declares
+// for the versions since. Environment overrides of the params are rewritten
+// too, and the components then record the new version.
+func migrateComponentParams(a app.App, d pkg.Descriptor) error {
+	l, err := pkg.NewLocal(a, d.Name, d.Registry, d.Version, nil)
+	if err != nil {
+		log.WithError(err).Debugf("Not migrating params for package %s", d)
+		return nil
+	}
+
+	migrations := l.Migrations()
+	if len(migrations) == 0 {
+		return nil
+	}
+
+	envs, err := a.Environments()
+	if err != nil {
+		return err
+	}
+
+	var envNames []string
+	for name := range envs {
+		envNames = append(envNames, name)
+	}
+	sort.Strings(envNames)
+
+	modules, err := component.Modules(a)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range modules {
+		components, err := m.Components()
+		if err != nil {
+			return err
+		}
+
+		for _, c := range components {
+			if err := migrateComponent(a, m, c, d, migrations, envNames); err != nil {
+				return errors.Wrapf(err, "migrating params of component %q", c.Name(true))
+			}
+		}
+	}
+
+	return nil
+}
+
+func migrateComponent(a app.App, m component.Module, c component.Component, d pkg.Descriptor, migrations parts.MigrationSpecs, envNames []string) error {
+	path := filepath.Join(m.Dir(), c.Name(false)+"."+c.Type())
+	b, err := afero.ReadFile(a.Fs(), path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	origin, ok := readComponentOrigin(string(b))
+	if !ok || origin.pkg.Registry != d.Registry || origin.pkg.Name != d.Name || origin.pkg.Version == d.Version {
+		return nil
+	}
+
+	ms, err := migrationsBetween(migrations, origin.prototype, origin.pkg.Version, d.Version)
+	if err != nil {
+		log.Warnf("Not migrating params of component %q: %v", c.Name(true), err)
+		return nil
+	}
+	if len(ms) == 0 {
+		return nil
+	}
+
+	if err = migrateLocalParams(c, ms); err != nil {
+		return err
+	}
+
+	for _, envName := range envNames {
+		if err = migrateEnvParams(a, envName, c.Name(true), ms); err != nil {
+			return errors.Wrapf(err, "environment %q", envName)
+		}
+	}
+
+	pinned := pkg.Descriptor{Registry: d.Registry, Name: d.Name, Version: d.Version}
+	text := strings.Replace(string(b), "@package "+origin.pkg.String(), "@package "+pinned.String(), 1)
+	if err = afero.WriteFile(a.Fs(), path, []byte(text), app.DefaultFilePermissions); err != nil {
+		return err
+	}
+
+	log.Infof("Migrated params of component %q from %s to %s", c.Name(true), origin.pkg, pinned)
+	return nil
+}
+
+// migrateLocalParams migrates the params of a component.
+func migrateLocalParams(c component.Component, ms parts.MigrationSpecs) error {
+	componentParams, err := c.Params("")
+	if err != nil {
+		return err
+	}
+
+	values := make(map[string]interface{})
+	for _, p := range componentParams {
+		var v interface{}
+		if err := json.Unmarshal([]byte(p.Value), &v); err != nil {
+			return errors.Wrapf(err, "decoding param %q", p.Key)
+		}
+		values[p.Key] = v
+	}
+
+	migrated, err := migrateValues(values, ms)
+	if err != nil {
+		return err
+	}
+
+	deleted, changed := diffValues(values, migrated)
+	for _, k := range deleted {
+		if err := c.DeleteParam([]string{k}); err != nil {
+			return err
+		}
+	}
+	for _, k := range changed {
+		if err := c.SetParam([]string{k}, migrated[k]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateEnvParams migrates the params an environment overrides for a
+// component.
+func migrateEnvParams(a app.App, envName, componentName string, ms parts.MigrationSpecs) error {
+	path, err := env.Path(a, envName, "params.libsonnet")
+	if err != nil {
+		return err
+	}
+
+	b, err := afero.ReadFile(a.Fs(), path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	overrides, err := params.NewEnvParamGet().Get(componentName, string(b))
+	if err != nil {
+		return err
+	}
+
+	// Overrides which are literals are decoded. Others, such as references
+	// to global params, are kept as Jsonnet.
+	vm := jsonnet.NewVM()
+	values := make(map[string]interface{})
+	for k, s := range overrides {
+		values[k] = jsonnetValue(s)
+
+		out, err := vm.EvaluateSnippet(k, s)
+		if err != nil {
+			continue
+		}
+
+		var v interface{}
+		if err = json.Unmarshal([]byte(out), &v); err == nil {
+			values[k] = v
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	migrated, err := migrateValues(values, ms)
+	if err != nil {
+		return err
+	}
+
+	deleted, changed := diffValues(values, migrated)
+	for _, k := range deleted {
+		if err := env.DeleteParam(a, envName, componentName, k); err != nil {
+			return err
+		}
+	}
+
+	if len(changed) == 0 {
+		return nil
+	}
+
+	p := param.Params{}
+	for _, k := range changed {
+		s, err := encodeJsonnetValue(migrated[k])
+		if err != nil {
+			return err
+		}
+		p[k] = s
+	}
+
+	return env.SetParams(envName, componentName, p, env.SetParamsConfig{App: a})
+}
+
+// migrationsBetween returns the migrations for a prototype from versions
+// after from, up to and including to, in version order.
+func migrationsBetween(migrations parts.MigrationSpecs, prototypeName, from, to string) (parts.MigrationSpecs, error) {
+	fromVersion, err := version.Make(from)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid version %q", from)
+	}
+	toVersion, err := version.Make(to)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid version %q", to)
+	}
+
+	var ms parts.MigrationSpecs
+	versions := make(map[*parts.MigrationSpec]version.Version)
+	for _, m := range migrations {
+		if m.Prototype != "" && m.Prototype != prototypeName {
+			continue
+		}
+
+		v, err := version.Make(m.Version)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid migration version %q", m.Version)
+		}
+
+		if fromVersion.LT(v) && !toVersion.LT(v) {
+			ms = append(ms, m)
+			versions[m] = v
+		}
+	}
+
+	sort.SliceStable(ms, func(i, j int) bool {
+		return versions[ms[i]].LT(versions[ms[j]])
+	})
+
+	return ms, nil
+}
+
+// migrateValues applies migrations to a copy of params. In each migration,
+// params are renamed, then converted, then removed.
+func migrateValues(values map[string]interface{}, ms parts.MigrationSpecs) (map[string]interface{}, error) {
+	migrated := make(map[string]interface{})
+	for k, v := range values {
+		migrated[k] = v
+	}
+
+	for _, m := range ms {
+		var oldNames []string
+		for oldName := range m.Rename {
+			oldNames = append(oldNames, oldName)
+		}
+		sort.Strings(oldNames)
+
+		for _, oldName := range oldNames {
+			v, ok := migrated[oldName]
+			if !ok {
+				continue
+			}
+
+			newName := m.Rename[oldName]
+			if _, exists := migrated[newName]; exists {
+				return nil, errors.Errorf("can't rename param %q to %q, which is already set", oldName, newName)
+			}
+
+			delete(migrated, oldName)
+			migrated[newName] = v
+		}
+
+		for name, t := range m.Convert {
+			v, ok := migrated[name]
+			if !ok {
+				continue
+			}
+
+			converted, err := convertValue(v, t)
+			if err != nil {
+				return nil, errors.Wrapf(err, "converting param %q", name)
+			}
+			migrated[name] = converted
+		}
+
+		for _, name := range m.Remove {
+			delete(migrated, name)
+		}
+	}
+
+	return migrated, nil
+}
+
+// convertValue converts a param value to a string, a number, or an array.
+func convertValue(v interface{}, t string) (interface{}, error) {
+	if s, ok := v.(jsonnetValue); ok {
+		return nil, errors.Errorf("%s is not a literal", string(s))
+	}
+
+	switch t {
+	case "string":
+		switch v := v.(type) {
+		case string:
+			return v, nil
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		case bool:
+			return strconv.FormatBool(v), nil
+		}
+	case "number":
+		switch v := v.(type) {
+		case float64:
+			return v, nil
+		case string:
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, errors.Errorf("%q is not a number", v)
+			}
+			return f, nil
+		}
+	case "array":
+		if _, ok := v.([]interface{}); ok {
+			return v, nil
+		}
+		return []interface{}{v}, nil
+	default:
+		return nil, errors.Errorf("unknown type %q", t)
+	}
+
+	return nil, errors.Errorf("%v can't be converted to a %s", v, t)
+}
+
+// diffValues returns the params which were deleted and changed by a
+// migration, sorted by name.
+func diffValues(values, migrated map[string]interface{}) (deleted, changed []string) {
+	for k := range values {
+		if _, ok := migrated[k]; !ok {
+			deleted = append(deleted, k)
+		}
+	}
+
+	for k, v := range migrated {
+		if old, ok := values[k]; !ok || !reflect.DeepEqual(old, v) {
+			changed = append(changed, k)
+		}
+	}
+
+	sort.Strings(deleted)
+	sort.Strings(changed)
+	return deleted, changed
+}
+
+func encodeJsonnetValue(v interface{}) (string, error) {
+	if s, ok := v.(jsonnetValue); ok {
+		return string(s), nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,215 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/parts"
+	"github.com/ksonnet/ksonnet/pkg/pkg"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_migrateComponentParams(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		fs := appMock.Fs()
+
+		stageFile(t, fs, "pkg/migrate/parts.yaml", "/vendor/incubator/apache@2.0.0/parts.yaml")
+		stageFile(t, fs, "pkg/migrate/web.jsonnet", "/components/web.jsonnet")
+		stageFile(t, fs, "pkg/migrate/params.libsonnet", "/components/params.libsonnet")
+		stageFile(t, fs, "pkg/migrate/env-params.libsonnet", "/environments/default/params.libsonnet")
+		require.NoError(t, afero.WriteFile(fs, "/environments/default/main.jsonnet", []byte("{}"), 0644))
+		require.NoError(t, afero.WriteFile(fs, "/components/other.jsonnet", []byte("{}"), 0644))
+
+		envs := app.EnvironmentConfigs{
+			"default": &app.EnvironmentConfig{Path: "default"},
+		}
+		appMock.On("Environments").Return(envs, nil)
+		appMock.On("Environment", "default").Return(envs["default"], nil)
+
+		d := pkg.Descriptor{Registry: "incubator", Name: "apache", Version: "2.0.0"}
+		err := migrateComponentParams(appMock, d)
+		require.NoError(t, err)
+
+		for path, golden := range map[string]string{
+			"/components/web.jsonnet":                "pkg/migrate/web.jsonnet.out",
+			"/components/params.libsonnet":           "pkg/migrate/params.libsonnet.out",
+			"/environments/default/params.libsonnet": "pkg/migrate/env-params.libsonnet.out",
+		} {
+			b, err := afero.ReadFile(fs, path)
+			require.NoError(t, err)
+			assertOutput(t, golden, string(b))
+		}
+	})
+}
+
+func Test_readComponentOrigin(t *testing.T) {
+	cases := []struct {
+		name     string
+		text     string
+		expected componentOrigin
+		ok       bool
+	}{
+		{
+			name: "jsonnet",
+			text: "// @prototype io.ksonnet.pkg.redis\n// @package incubator/redis@1.0.0\n{}",
+			expected: componentOrigin{
+				prototype: "io.ksonnet.pkg.redis",
+				pkg:       pkg.Descriptor{Registry: "incubator", Name: "redis", Version: "1.0.0"},
+			},
+			ok: true,
+		},
+		{
+			name: "yaml",
+			text: "# @prototype io.ksonnet.pkg.redis\n# @package incubator/redis@1.0.0\nkind: Service",
+			expected: componentOrigin{
+				prototype: "io.ksonnet.pkg.redis",
+				pkg:       pkg.Descriptor{Registry: "incubator", Name: "redis", Version: "1.0.0"},
+			},
+			ok: true,
+		},
+		{
+			name: "system prototype",
+			text: "// @prototype io.ksonnet.pkg.deployed-service\n{}",
+		},
+		{
+			name: "directive after the header",
+			text: "{}\n// @package incubator/redis@1.0.0",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := readComponentOrigin(tc.text)
+			require.Equal(t, tc.ok, ok)
+			if tc.ok {
+				assert.Equal(t, tc.expected, got)
+			}
+		})
+	}
+}
+
+func Test_migrationsBetween(t *testing.T) {
+	migrations := parts.MigrationSpecs{
+		{Version: "3.0.0"},
+		{Version: "1.0.0"},
+		{Version: "2.0.0", Prototype: "io.ksonnet.pkg.other"},
+		{Version: "1.5.0"},
+	}
+
+	got, err := migrationsBetween(migrations, "io.ksonnet.pkg.redis", "1.0.0", "3.0.0")
+	require.NoError(t, err)
+
+	var versions []string
+	for _, m := range got {
+		versions = append(versions, m.Version)
+	}
+	assert.Equal(t, []string{"1.5.0", "3.0.0"}, versions)
+
+	_, err = migrationsBetween(migrations, "io.ksonnet.pkg.redis", "master", "3.0.0")
+	require.Error(t, err)
+}
+
+func Test_migrateValues(t *testing.T) {
+	cases := []struct {
+		name       string
+		values     map[string]interface{}
+		migrations parts.MigrationSpecs
+		expected   map[string]interface{}
+		isErr      bool
+	}{
+		{
+			name:   "rename, convert, and remove",
+			values: map[string]interface{}{"port": "80", "debug": true, "image": "nginx"},
+			migrations: parts.MigrationSpecs{
+				{
+					Rename:  map[string]string{"port": "containerPort"},
+					Convert: map[string]string{"containerPort": "number", "image": "array"},
+					Remove:  []string{"debug"},
+				},
+			},
+			expected: map[string]interface{}{"containerPort": 80.0, "image": []interface{}{"nginx"}},
+		},
+		{
+			name:   "rename to existing param",
+			values: map[string]interface{}{"port": 80.0, "containerPort": 80.0},
+			migrations: parts.MigrationSpecs{
+				{Rename: map[string]string{"port": "containerPort"}},
+			},
+			isErr: true,
+		},
+		{
+			name:   "convert expression",
+			values: map[string]interface{}{"port": jsonnetValue("std.extVar('port')")},
+			migrations: parts.MigrationSpecs{
+				{Convert: map[string]string{"port": "number"}},
+			},
+			isErr: true,
+		},
+		{
+			name:   "rename expression",
+			values: map[string]interface{}{"port": jsonnetValue("std.extVar('port')")},
+			migrations: parts.MigrationSpecs{
+				{Rename: map[string]string{"port": "containerPort"}},
+			},
+			expected: map[string]interface{}{"containerPort": jsonnetValue("std.extVar('port')")},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := migrateValues(tc.values, tc.migrations)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func Test_convertValue(t *testing.T) {
+	cases := []struct {
+		value    interface{}
+		t        string
+		expected interface{}
+		isErr    bool
+	}{
+		{value: 80.0, t: "string", expected: "80"},
+		{value: true, t: "string", expected: "true"},
+		{value: "80", t: "number", expected: 80.0},
+		{value: "http", t: "number", isErr: true},
+		{value: []interface{}{80.0}, t: "array", expected: []interface{}{80.0}},
+		{value: map[string]interface{}{}, t: "string", isErr: true},
+	}
+
+	for _, tc := range cases {
+		got, err := convertValue(tc.value, tc.t)
+		if tc.isErr {
+			require.Error(t, err)
+			continue
+		}
+
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, got)
+	}
+}
@@ -16,7 +16,10 @@
 package actions
 
 import (
+	"fmt"
+
 	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/env"
 	"github.com/ksonnet/ksonnet/pkg/pkg"
 	"github.com/ksonnet/ksonnet/pkg/registry"
 	"github.com/pkg/errors"
@@ -28,40 +31,70 @@ type PkgRemove struct {
 	app         app.App
 	pkgName     string
 	envName     string
+	confirmer   *confirmer
 	checker     registry.InstalledChecker
 	gc          registry.GarbageCollector
 	libUpdateFn libUpdater
+
+	refreshPackagesFn func() error
 }
 
-// NewPkgRemove creates an instance of PkgInstall
+// PkgRemoveOptions are the options for PkgRemove.
+type PkgRemoveOptions struct {
+	App       app.App `option:"app"`
+	PkgName   string  `option:"pkg-name"`
+	EnvName   string  `option:"env-name,optional"`
+	AssumeYes bool    `option:"assume-yes,optional"`
+}
+
+// NewPkgRemove creates an instance of PkgRemove from an option map.
 func NewPkgRemove(m map[string]interface{}) (*PkgRemove, error) {
-	ol := newOptionLoader(m)
+	var o PkgRemoveOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
 
-	a := ol.LoadApp()
-	if ol.err != nil {
-		return nil, ol.err
+	return NewPkgRemoveWithOptions(o)
+}
+
+// NewPkgRemoveWithOptions creates an instance of PkgRemove.
+func NewPkgRemoveWithOptions(o PkgRemoveOptions) (*PkgRemove, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
 	}
 
+	a := o.App
 	pm := registry.NewPackageManager(a)
 
 	pr := &PkgRemove{
 		app:         a,
-		pkgName:     ol.LoadString(OptionPkgName),
-		envName:     ol.LoadOptionalString(OptionEnvName),
+		pkgName:     o.PkgName,
+		envName:     o.EnvName,
+		confirmer:   newConfirmer(o.AssumeYes),
 		libUpdateFn: a.UpdateLib,
 		gc:          registry.NewGarbageCollector(a.Fs(), pm, a.VendorPath()),
-	}
 
-	if ol.err != nil {
-		return nil, ol.err
+		refreshPackagesFn: func() error {
+			return refreshJsonnetPackages(a, pm, env.VendorPackages)
+		},
 	}
 
 	return pr, nil
 }
 
-// RunPkgRemove runs `pkg list`
+// RunPkgRemove runs `pkg remove`
 func RunPkgRemove(m map[string]interface{}) error {
-	pr, err := NewPkgRemove(m)
+	var o PkgRemoveOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunPkgRemoveWithOptions(o)
+}
+
+// RunPkgRemoveWithOptions runs `pkg remove` with typed options.
+func RunPkgRemoveWithOptions(o PkgRemoveOptions) error {
+	pr, err := NewPkgRemoveWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -76,6 +109,14 @@ func (pr *PkgRemove) Run() error {
 		return err
 	}
 
+	action := fmt.Sprintf("Remove package %s", pr.pkgName)
+	if pr.envName != "" {
+		action += fmt.Sprintf(" from environment %q", pr.envName)
+	}
+	if err := pr.confirmer.confirm(action); err != nil {
+		return err
+	}
+
 	oldCfg, err := pr.libUpdateFn(desc.Name, pr.envName, nil)
 	if err != nil {
 		return err
@@ -96,5 +137,5 @@ func (pr *PkgRemove) Run() error {
 		return errors.Wrapf(err, "garbage collection for package %v", oldCfg)
 	}
 
-	return nil
+	return pr.refreshPackagesFn()
 }
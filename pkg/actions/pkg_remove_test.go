@@ -28,8 +28,9 @@ func TestPkgRemove(t *testing.T) {
 		libName := "incubator/apache"
 
 		in := map[string]interface{}{
-			OptionApp:     appMock,
-			OptionPkgName: libName,
+			OptionApp:       appMock,
+			OptionAssumeYes: true,
+			OptionPkgName:   libName,
 		}
 
 		a, err := NewPkgRemove(in)
@@ -0,0 +1,191 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/plugin"
+	"github.com/ksonnet/ksonnet/pkg/util/event"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+// RunPluginRun runs a plugin.
+func RunPluginRun(m map[string]interface{}) error {
+	var o PluginRunOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunPluginRunWithOptions(o)
+}
+
+// RunPluginRunWithOptions runs a plugin with typed options.
+func RunPluginRunWithOptions(o PluginRunOptions) error {
+	pr, err := newPluginRunWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return pr.run()
+}
+
+type pluginRunOpt func(*PluginRun)
+
+// PluginRun runs a plugin with the context of the app it was run in.
+type PluginRun struct {
+	// app is nil outside of an app.
+	app  app.App
+	fs   afero.Fs
+	name string
+	args []string
+
+	findFn    func(afero.Fs, string) (plugin.Plugin, error)
+	objectsFn snapshotObjectsFn
+	runFn     func(*exec.Cmd) error
+}
+
+// PluginRunOptions are the options for PluginRun.
+type PluginRunOptions struct {
+	// App is nil outside of an app.
+	App       app.App  `option:"app,optional"`
+	Fs        afero.Fs `option:"fs"`
+	Name      string   `option:"name"`
+	Arguments []string `option:"arguments"`
+}
+
+func newPluginRun(m map[string]interface{}, opts ...pluginRunOpt) (*PluginRun, error) {
+	var o PluginRunOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return newPluginRunWithOptions(o, opts...)
+}
+
+func newPluginRunWithOptions(o PluginRunOptions, opts ...pluginRunOpt) (*PluginRun, error) {
+	pr := &PluginRun{
+		app:  o.App,
+		fs:   o.Fs,
+		name: o.Name,
+		args: o.Arguments,
+
+		findFn:    plugin.Find,
+		objectsFn: pipelineObjects,
+		runFn: func(cmd *exec.Cmd) error {
+			return cmd.Run()
+		},
+	}
+
+	for _, opt := range opts {
+		opt(pr)
+	}
+
+	return pr, nil
+}
+
+func (pr *PluginRun) run() error {
+	p, err := pr.findFn(pr.fs, pr.name)
+	if err != nil {
+		return err
+	}
+
+	if err = plugin.CheckAPIVersion(p.Config.APIVersion); err != nil {
+		return errors.Wrapf(err, "plugin %s", p.Config.Name)
+	}
+
+	req, err := pr.request(p)
+	if err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile("", "ks-plugin-request")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err = req.Write(f); err != nil {
+		f.Close()
+		return errors.Wrap(err, "writing plugin request")
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	env := []string{
+		fmt.Sprintf("KS_PLUGIN_DIR=%s", p.RootDir),
+		fmt.Sprintf("KS_PLUGIN_NAME=%s", p.Config.Name),
+		fmt.Sprintf("%s=%s", plugin.EnvAPIVersion, plugin.APIVersion),
+		fmt.Sprintf("%s=%s", plugin.EnvRequest, f.Name()),
+		fmt.Sprintf("HOME=%s", os.Getenv("HOME")),
+		fmt.Sprintf("PATH=%s", os.Getenv("PATH")),
+	}
+
+	if req.App != nil {
+		env = append(env, fmt.Sprintf("KS_APP_DIR=%s", req.App.Root))
+		if req.App.Environment != "" {
+			env = append(env, fmt.Sprintf("KS_ENV=%s", req.App.Environment))
+		}
+	}
+
+	return pr.runFn(p.BuildRunCmd(env, pr.args))
+}
+
+// request creates the request for a plugin. The manifests of the current
+// environment are only rendered if the plugin needs them.
+func (pr *PluginRun) request(p plugin.Plugin) (*plugin.Request, error) {
+	req := plugin.NewRequest(p.Config.Name, pr.args)
+
+	if pr.app == nil {
+		if p.Config.Manifests {
+			return nil, errors.Errorf("plugin %s must be run in a ksonnet app", p.Config.Name)
+		}
+		return req, nil
+	}
+
+	req.App = &plugin.AppContext{
+		Root:        pr.app.Root(),
+		Environment: pr.app.CurrentEnvironment(),
+	}
+
+	if !p.Config.Manifests {
+		return req, nil
+	}
+
+	if req.App.Environment == "" {
+		return nil, errors.Errorf("plugin %s needs the manifests of the current environment; set it with `ks env current --set <env>`", p.Config.Name)
+	}
+
+	collect := event.Collect()
+	objects, err := pr.objectsFn(pr.app, req.App.Environment)
+	req.Events = collect()
+	if err != nil {
+		return nil, errors.Wrapf(err, "rendering manifests for plugin %s", p.Config.Name)
+	}
+
+	req.App.Manifests = make([]map[string]interface{}, 0, len(objects))
+	for _, obj := range objects {
+		req.App.Manifests = append(req.App.Manifests, obj.Object)
+	}
+
+	return req, nil
+}
@@ -0,0 +1,188 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/plugin"
+	"github.com/ksonnet/ksonnet/pkg/util/event"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func findPlugin(config plugin.Config) func(afero.Fs, string) (plugin.Plugin, error) {
+	return func(afero.Fs, string) (plugin.Plugin, error) {
+		return plugin.Plugin{RootDir: "/plugins/hello", Config: config}, nil
+	}
+}
+
+// captureRequest returns a runFn which records a plugin's command, and the
+// request it was given.
+func captureRequest(t *testing.T, cmd **exec.Cmd, req *string) func(*exec.Cmd) error {
+	return func(c *exec.Cmd) error {
+		*cmd = c
+		for _, e := range c.Env {
+			if strings.HasPrefix(e, plugin.EnvRequest+"=") {
+				b, err := ioutil.ReadFile(strings.TrimPrefix(e, plugin.EnvRequest+"="))
+				require.NoError(t, err)
+				*req = string(b)
+			}
+		}
+		return nil
+	}
+}
+
+func TestPluginRun(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("CurrentEnvironment").Return("default")
+
+		in := map[string]interface{}{
+			OptionApp:       appMock,
+			OptionFs:        appMock.Fs(),
+			OptionName:      "hello",
+			OptionArguments: []string{"--greeting", "hi"},
+		}
+
+		var cmd *exec.Cmd
+		var req string
+
+		pr, err := newPluginRun(in, func(pr *PluginRun) {
+			pr.findFn = findPlugin(plugin.Config{
+				Name:      "hello",
+				Command:   "$KS_PLUGIN_DIR/hello.sh",
+				Manifests: true,
+			})
+			pr.objectsFn = snapshotObjects(1)
+			pr.runFn = captureRequest(t, &cmd, &req)
+		})
+		require.NoError(t, err)
+		require.NoError(t, pr.run())
+
+		require.NotNil(t, cmd)
+		assert.Equal(t, []string{"/plugins/hello/hello.sh", "--greeting", "hi"}, cmd.Args)
+		assert.Contains(t, cmd.Env, "KS_PLUGIN_NAME=hello")
+		assert.Contains(t, cmd.Env, "KS_PLUGIN_API_VERSION="+plugin.APIVersion)
+		assert.Contains(t, cmd.Env, "KS_APP_DIR=/")
+		assert.Contains(t, cmd.Env, "KS_ENV=default")
+
+		assertOutput(t, "plugin/run/request.json", req)
+	})
+}
+
+func TestPluginRun_events(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("CurrentEnvironment").Return("default")
+
+		in := map[string]interface{}{
+			OptionApp:       appMock,
+			OptionFs:        appMock.Fs(),
+			OptionName:      "hello",
+			OptionArguments: []string{},
+		}
+
+		var cmd *exec.Cmd
+		var req string
+
+		pr, err := newPluginRun(in, func(pr *PluginRun) {
+			pr.findFn = findPlugin(plugin.Config{Name: "hello", Command: "/bin/hello", Manifests: true})
+			pr.objectsFn = func(a app.App, envName string) ([]*unstructured.Unstructured, error) {
+				event.Publish(event.EvaluationStarted{Module: "/", Env: envName})
+				return snapshotObjects(1)(a, envName)
+			}
+			pr.runFn = captureRequest(t, &cmd, &req)
+		})
+		require.NoError(t, err)
+		require.NoError(t, pr.run())
+
+		assert.Contains(t, req, `"type": "EvaluationStarted"`)
+		assert.Contains(t, req, `"env": "default"`)
+	})
+}
+
+func TestPluginRun_outside_app(t *testing.T) {
+	in := map[string]interface{}{
+		OptionApp:       nil,
+		OptionFs:        afero.NewMemMapFs(),
+		OptionName:      "hello",
+		OptionArguments: []string{},
+	}
+
+	var cmd *exec.Cmd
+	var req string
+
+	pr, err := newPluginRun(in, func(pr *PluginRun) {
+		pr.findFn = findPlugin(plugin.Config{Name: "hello", Command: "/bin/hello"})
+		pr.runFn = captureRequest(t, &cmd, &req)
+	})
+	require.NoError(t, err)
+	require.NoError(t, pr.run())
+
+	assert.NotContains(t, req, "\"app\"")
+	for _, e := range cmd.Env {
+		assert.False(t, strings.HasPrefix(e, "KS_APP_DIR="), "unexpected %s", e)
+	}
+}
+
+func TestPluginRun_errors(t *testing.T) {
+	cases := []struct {
+		name   string
+		env    string
+		config plugin.Config
+	}{
+		{
+			name:   "unsupported api version",
+			env:    "default",
+			config: plugin.Config{Name: "hello", APIVersion: "ksonnet.io/plugin/v2"},
+		},
+		{
+			name:   "manifests without a current environment",
+			config: plugin.Config{Name: "hello", Manifests: true},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				appMock.On("CurrentEnvironment").Return(tc.env)
+
+				in := map[string]interface{}{
+					OptionApp:       appMock,
+					OptionFs:        appMock.Fs(),
+					OptionName:      "hello",
+					OptionArguments: []string{},
+				}
+
+				pr, err := newPluginRun(in, func(pr *PluginRun) {
+					pr.findFn = findPlugin(tc.config)
+					pr.runFn = func(*exec.Cmd) error {
+						t.Fatal("plugin should not run")
+						return nil
+					}
+				})
+				require.NoError(t, err)
+				require.Error(t, pr.run())
+			})
+		})
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"io"
+	"os"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/cluster"
+)
+
+type runPortForwardFn func(cluster.PortForwardConfig, ...cluster.PortForwardOpts) error
+
+// RunPortForward runs `port-forward`.
+func RunPortForward(m map[string]interface{}) error {
+	var o PortForwardOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunPortForwardWithOptions(o)
+}
+
+// RunPortForwardWithOptions runs `port-forward` with typed options.
+func RunPortForwardWithOptions(o PortForwardOptions) error {
+	pf, err := newPortForwardWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return pf.run()
+}
+
+type portForwardOpt func(*PortForward)
+
+// PortForward forwards local ports to a pod of a component.
+type PortForward struct {
+	app           app.App
+	clientConfig  *client.Config
+	componentName string
+	envName       string
+	ports         []string
+	address       string
+	stopCh        <-chan struct{}
+
+	runPortForwardFn runPortForwardFn
+	out              io.Writer
+}
+
+// PortForwardOptions are the options for PortForward.
+type PortForwardOptions struct {
+	App           app.App        `option:"app"`
+	ClientConfig  *client.Config `option:"client-config"`
+	ComponentName string         `option:"component-name"`
+	EnvName       string         `option:"env-name,optional"`
+	Ports         []string       `option:"ports"`
+	Address       string         `option:"address,optional"`
+	Out           io.Writer      `option:"out,optional"`
+}
+
+func newPortForward(m map[string]interface{}, opts ...portForwardOpt) (*PortForward, error) {
+	var o PortForwardOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return newPortForwardWithOptions(o, opts...)
+}
+
+func newPortForwardWithOptions(o PortForwardOptions, opts ...portForwardOpt) (*PortForward, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	pf := &PortForward{
+		app:           o.App,
+		clientConfig:  o.ClientConfig,
+		componentName: o.ComponentName,
+		ports:         o.Ports,
+		address:       o.Address,
+
+		runPortForwardFn: cluster.RunPortForward,
+		out:              os.Stdout,
+	}
+
+	if o.Out != nil {
+		pf.out = o.Out
+	}
+
+	for _, opt := range opts {
+		opt(pf)
+	}
+
+	if err := setCurrentEnv(pf.app, pf, o.EnvName); err != nil {
+		return nil, err
+	}
+
+	return pf, nil
+}
+
+func (pf *PortForward) setCurrentEnv(name string) {
+	pf.envName = name
+}
+
+// run forwards ports until ks is interrupted.
+func (pf *PortForward) run() error {
+	stopCh := pf.stopCh
+	if stopCh == nil {
+		stopCh = interruptCh()
+	}
+
+	config := cluster.PortForwardConfig{
+		App:           pf.app,
+		ClientConfig:  pf.clientConfig,
+		ComponentName: pf.componentName,
+		EnvName:       pf.envName,
+		Ports:         pf.ports,
+		Address:       pf.address,
+		Out:           pf.out,
+		StopCh:        stopCh,
+	}
+
+	return pf.runPortForwardFn(config)
+}
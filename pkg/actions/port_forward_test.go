@@ -0,0 +1,63 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"testing"
+
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/cluster"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPortForward(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("CurrentEnvironment").Return("default")
+
+		var buf bytes.Buffer
+		stopCh := make(chan struct{})
+
+		in := map[string]interface{}{
+			OptionApp:           appMock,
+			OptionClientConfig:  &client.Config{},
+			OptionComponentName: "guestbook",
+			OptionPorts:         []string{"8080:80"},
+			OptionAddress:       "0.0.0.0",
+			OptionOut:           &buf,
+		}
+
+		runPortForwardOpt := func(pf *PortForward) {
+			pf.stopCh = stopCh
+			pf.runPortForwardFn = func(config cluster.PortForwardConfig, opts ...cluster.PortForwardOpts) error {
+				assert.Equal(t, "guestbook", config.ComponentName)
+				assert.Equal(t, "default", config.EnvName)
+				assert.Equal(t, []string{"8080:80"}, config.Ports)
+				assert.Equal(t, "0.0.0.0", config.Address)
+				assert.Equal(t, &buf, config.Out)
+				assert.Equal(t, (<-chan struct{})(stopCh), config.StopCh)
+				return nil
+			}
+		}
+
+		pf, err := newPortForward(in, runPortForwardOpt)
+		require.NoError(t, err)
+
+		require.NoError(t, pf.run())
+	})
+}
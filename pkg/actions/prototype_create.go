@@ -0,0 +1,252 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/parts"
+	"github.com/ksonnet/ksonnet/pkg/prototype"
+	"github.com/ksonnet/ksonnet/pkg/registry"
+	strutil "github.com/ksonnet/ksonnet/pkg/util/strings"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+const (
+	partsFileName    = "parts.yaml"
+	registryFileName = "registry.yaml"
+	// defaultPartVersion is the version of parts which are created for
+	// generated prototypes.
+	defaultPartVersion = "0.0.1"
+)
+
+// RunPrototypeCreate runs `prototype create`.
+func RunPrototypeCreate(m map[string]interface{}) error {
+	var o PrototypeCreateOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunPrototypeCreateWithOptions(o)
+}
+
+// RunPrototypeCreateWithOptions runs `prototype create` with typed options.
+func RunPrototypeCreateWithOptions(o PrototypeCreateOptions) error {
+	pc, err := newPrototypeCreateWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return pc.run()
+}
+
+type prototypeCreateOpt func(*PrototypeCreate)
+
+// PrototypeCreate generates a prototype from existing Kubernetes manifests,
+// and places it in a local part.
+type PrototypeCreate struct {
+	app      app.App
+	name     string
+	manifest string
+	partDir  string
+	force    bool
+
+	generateFn func(fs afero.Fs, name, manifest string) (string, error)
+}
+
+// PrototypeCreateOptions are the options for PrototypeCreate.
+type PrototypeCreateOptions struct {
+	App  app.App `option:"app"`
+	Name string  `option:"name"`
+	// Manifest is the path of the manifest the prototype is created from.
+	Manifest string `option:"path"`
+	// PartDir is the directory of the part the prototype is created in.
+	PartDir string `option:"part"`
+	Force   bool   `option:"force,optional"`
+}
+
+func newPrototypeCreate(m map[string]interface{}, opts ...prototypeCreateOpt) (*PrototypeCreate, error) {
+	var o PrototypeCreateOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return newPrototypeCreateWithOptions(o, opts...)
+}
+
+func newPrototypeCreateWithOptions(o PrototypeCreateOptions, opts ...prototypeCreateOpt) (*PrototypeCreate, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	pc := &PrototypeCreate{
+		app:      o.App,
+		name:     o.Name,
+		manifest: o.Manifest,
+		partDir:  o.PartDir,
+		force:    o.Force,
+
+		generateFn: generatePrototype,
+	}
+
+	if pc.manifest == "" {
+		return nil, errors.New("a manifest to create the prototype from is required")
+	}
+	if pc.partDir == "" {
+		return nil, errors.New("a part directory to create the prototype in is required")
+	}
+
+	for _, opt := range opts {
+		opt(pc)
+	}
+
+	return pc, nil
+}
+
+func (pc *PrototypeCreate) run() error {
+	fs := pc.app.Fs()
+
+	shortName := pc.name[strings.LastIndex(pc.name, ".")+1:]
+	if shortName == "" {
+		return errors.Errorf("prototype name %q is invalid", pc.name)
+	}
+
+	path := filepath.Join(pc.partDir, "prototypes", shortName+".jsonnet")
+	exists, err := afero.Exists(fs, path)
+	if err != nil {
+		return err
+	}
+	if exists && !pc.force {
+		return errors.Errorf("prototype %s already exists; use --force to overwrite it", path)
+	}
+
+	src, err := pc.generateFn(fs, pc.name, pc.manifest)
+	if err != nil {
+		return err
+	}
+
+	if err = fs.MkdirAll(filepath.Dir(path), app.DefaultFolderPermissions); err != nil {
+		return err
+	}
+	if err = afero.WriteFile(fs, path, []byte(src), app.DefaultFilePermissions); err != nil {
+		return err
+	}
+
+	if err = pc.updatePart(); err != nil {
+		return err
+	}
+
+	if err = pc.updateRegistry(); err != nil {
+		return err
+	}
+
+	log.Infof("created prototype %s in %s", pc.name, path)
+	return nil
+}
+
+// updatePart adds the prototype to the part's parts.yaml, which is created if
+// it does not exist.
+func (pc *PrototypeCreate) updatePart() error {
+	fs := pc.app.Fs()
+	path := filepath.Join(pc.partDir, partsFileName)
+
+	spec := &parts.Spec{
+		APIVersion:  parts.DefaultAPIVersion,
+		Kind:        parts.DefaultKind,
+		Name:        filepath.Base(pc.partDir),
+		Version:     defaultPartVersion,
+		Description: "Prototypes generated from existing manifests",
+	}
+
+	exists, err := afero.Exists(fs, path)
+	if err != nil {
+		return err
+	}
+	if exists {
+		b, err := afero.ReadFile(fs, path)
+		if err != nil {
+			return err
+		}
+		if spec, err = parts.Unmarshal(b); err != nil {
+			return errors.Wrapf(err, "reading %s", path)
+		}
+	}
+
+	if strutil.InSlice(pc.name, spec.Prototypes) {
+		return nil
+	}
+	spec.Prototypes = append(spec.Prototypes, pc.name)
+
+	b, err := spec.Marshal()
+	if err != nil {
+		return err
+	}
+
+	return afero.WriteFile(fs, path, b, app.DefaultFilePermissions)
+}
+
+// updateRegistry adds the part to the registry.yaml of the directory which
+// contains it, if there is one, so it can be installed from a file system
+// registry.
+func (pc *PrototypeCreate) updateRegistry() error {
+	fs := pc.app.Fs()
+	path := filepath.Join(filepath.Dir(pc.partDir), registryFileName)
+
+	exists, err := afero.Exists(fs, path)
+	if err != nil || !exists {
+		return err
+	}
+
+	b, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return err
+	}
+
+	spec, err := registry.Unmarshal(b)
+	if err != nil {
+		return errors.Wrapf(err, "reading %s", path)
+	}
+
+	name := filepath.Base(pc.partDir)
+	if _, ok := spec.Libraries[name]; ok {
+		return nil
+	}
+
+	if spec.Libraries == nil {
+		spec.Libraries = registry.LibraryConfigs{}
+	}
+	spec.Libraries[name] = &registry.LibraryConfig{Path: name}
+
+	if b, err = spec.Marshal(); err != nil {
+		return err
+	}
+
+	return afero.WriteFile(fs, path, b, app.DefaultFilePermissions)
+}
+
+func generatePrototype(fs afero.Fs, name, manifest string) (string, error) {
+	f, err := fs.Open(manifest)
+	if err != nil {
+		return "", errors.Wrap(err, "opening manifest")
+	}
+	defer f.Close()
+
+	return prototype.Generate(name, "", f)
+}
@@ -0,0 +1,119 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"testing"
+
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/parts"
+	"github.com/ksonnet/ksonnet/pkg/prototype"
+	"github.com/ksonnet/ksonnet/pkg/registry"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrototypeCreate(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		fs := appMock.Fs()
+		stageFile(t, fs, "prototype/create/manifest.yaml", "/manifest.yaml")
+		require.NoError(t, afero.WriteFile(fs, "/registry/registry.yaml", []byte("apiVersion: 0.2.0\nkind: ksonnet.io/registry\n"), 0644))
+
+		in := map[string]interface{}{
+			OptionApp:  appMock,
+			OptionName: "io.example.web",
+			OptionPath: "/manifest.yaml",
+			OptionPart: "/registry/web",
+		}
+
+		a, err := newPrototypeCreate(in)
+		require.NoError(t, err)
+
+		err = a.run()
+		require.NoError(t, err)
+
+		b, err := afero.ReadFile(fs, "/registry/web/prototypes/web.jsonnet")
+		require.NoError(t, err)
+		p, err := prototype.JsonnetParse(string(b))
+		require.NoError(t, err)
+		assert.Equal(t, "io.example.web", p.Name)
+		assert.Empty(t, prototype.Lint(string(b)))
+
+		b, err = afero.ReadFile(fs, "/registry/web/parts.yaml")
+		require.NoError(t, err)
+		part, err := parts.Unmarshal(b)
+		require.NoError(t, err)
+		assert.Equal(t, "web", part.Name)
+		assert.Equal(t, parts.PrototypeRefSpecs{"io.example.web"}, part.Prototypes)
+
+		b, err = afero.ReadFile(fs, "/registry/registry.yaml")
+		require.NoError(t, err)
+		spec, err := registry.Unmarshal(b)
+		require.NoError(t, err)
+		require.Contains(t, spec.Libraries, "web")
+		assert.Equal(t, "web", spec.Libraries["web"].Path)
+
+		// the prototype is not overwritten without --force.
+		err = a.run()
+		require.Error(t, err)
+
+		a.force = true
+		err = a.run()
+		require.NoError(t, err)
+
+		b, err = afero.ReadFile(fs, "/registry/web/parts.yaml")
+		require.NoError(t, err)
+		part, err = parts.Unmarshal(b)
+		require.NoError(t, err)
+		assert.Equal(t, parts.PrototypeRefSpecs{"io.example.web"}, part.Prototypes)
+	})
+}
+
+func TestPrototypeCreate_missing_manifest(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionApp:  appMock,
+			OptionName: "io.example.web",
+			OptionPath: "/manifest.yaml",
+			OptionPart: "/parts/web",
+		}
+
+		a, err := newPrototypeCreate(in)
+		require.NoError(t, err)
+
+		err = a.run()
+		require.Error(t, err)
+	})
+}
+
+func TestPrototypeCreate_requires_manifest_and_part(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		for _, in := range []map[string]interface{}{
+			{OptionApp: appMock, OptionName: "io.example.web", OptionPath: "", OptionPart: "/parts/web"},
+			{OptionApp: appMock, OptionName: "io.example.web", OptionPath: "/manifest.yaml", OptionPart: ""},
+		} {
+			_, err := newPrototypeCreate(in)
+			require.Error(t, err)
+		}
+	})
+}
+
+func TestPrototypeCreate_requires_app(t *testing.T) {
+	in := make(map[string]interface{})
+	_, err := newPrototypeCreate(in)
+	require.Error(t, err)
+}
@@ -25,12 +25,23 @@ import (
 	"github.com/ksonnet/ksonnet/pkg/pkg"
 	"github.com/ksonnet/ksonnet/pkg/prototype"
 	"github.com/ksonnet/ksonnet/pkg/registry"
+	"github.com/ksonnet/ksonnet/pkg/util/table"
 	"github.com/pkg/errors"
 )
 
 // RunPrototypeDescribe runs `prototype describe`
 func RunPrototypeDescribe(m map[string]interface{}) error {
-	pd, err := NewPrototypeDescribe(m)
+	var o PrototypeDescribeOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunPrototypeDescribeWithOptions(o)
+}
+
+// RunPrototypeDescribeWithOptions runs `prototype describe` with typed options.
+func RunPrototypeDescribeWithOptions(o PrototypeDescribeOptions) error {
+	pd, err := NewPrototypeDescribeWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -43,26 +54,43 @@ type PrototypeDescribe struct {
 	app            app.App
 	out            io.Writer
 	query          string
+	outputType     string
 	packageManager registry.PackageManager
 }
 
-// NewPrototypeDescribe creates an instance of PrototypeDescribe
+// PrototypeDescribeOptions are the options for PrototypeDescribe.
+type PrototypeDescribeOptions struct {
+	App           app.App `option:"app"`
+	Query         string  `option:"query"`
+	Output        string  `option:"output,optional"`
+	TLSSkipVerify bool    `option:"tls-skip-verify,optional"`
+}
+
+// NewPrototypeDescribe creates an instance of PrototypeDescribe from an option map.
 func NewPrototypeDescribe(m map[string]interface{}) (*PrototypeDescribe, error) {
-	ol := newOptionLoader(m)
+	var o PrototypeDescribeOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return NewPrototypeDescribeWithOptions(o)
+}
 
-	app := ol.LoadApp()
-	httpClientOpt := registry.HTTPClientOpt(ol.LoadHTTPClient())
+// NewPrototypeDescribeWithOptions creates an instance of PrototypeDescribe.
+func NewPrototypeDescribeWithOptions(o PrototypeDescribeOptions) (*PrototypeDescribe, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	httpClientOpt := registry.HTTPClientOpt(newHTTPClient(o.TLSSkipVerify))
 
 	pd := &PrototypeDescribe{
-		app:   app,
-		query: ol.LoadString(OptionQuery),
+		app:        o.App,
+		query:      o.Query,
+		outputType: o.Output,
 
 		out:            os.Stdout,
-		packageManager: registry.NewPackageManager(app, httpClientOpt),
-	}
-
-	if ol.err != nil {
-		return nil, ol.err
+		packageManager: registry.NewPackageManager(o.App, httpClientOpt),
 	}
 
 	return pd, nil
@@ -90,6 +118,21 @@ func (pd *PrototypeDescribe) Run() error {
 		return err
 	}
 
+	f, err := table.DetectFormat(pd.outputType)
+	if err != nil {
+		return errors.Wrap(err, "detecting output format")
+	}
+
+	if f != table.FormatTable {
+		return table.Encode(pd.out, f, prototypeDetails{
+			Name:           p.Name,
+			Description:    p.Template.Description,
+			RequiredParams: p.RequiredParams(),
+			OptionalParams: p.OptionalParams(),
+			TemplateTypes:  p.Template.AvailableTemplates(),
+		})
+	}
+
 	fmt.Fprintln(pd.out, `PROTOTYPE NAME:`)
 	fmt.Fprintln(pd.out, p.Name)
 	fmt.Fprintln(pd.out)
@@ -108,6 +151,16 @@ func (pd *PrototypeDescribe) Run() error {
 	return nil
 }
 
+// prototypeDetails is the description of a prototype in the structured output
+// formats.
+type prototypeDetails struct {
+	Name           string                   `json:"name"`
+	Description    string                   `json:"description"`
+	RequiredParams prototype.ParamSchemas   `json:"requiredParams"`
+	OptionalParams prototype.ParamSchemas   `json:"optionalParams"`
+	TemplateTypes  []prototype.TemplateType `json:"templateTypes"`
+}
+
 type prototypeFn func(app.App, pkg.Descriptor) (prototype.Prototypes, error)
 
 func findUniquePrototype(query string, prototypes prototype.Prototypes) (*prototype.Prototype, error) {
@@ -0,0 +1,175 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/prototype"
+	"github.com/ksonnet/ksonnet/pkg/util/table"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+// RunPrototypeLint runs `prototype lint`.
+func RunPrototypeLint(m map[string]interface{}) error {
+	var o PrototypeLintOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunPrototypeLintWithOptions(o)
+}
+
+// RunPrototypeLintWithOptions runs `prototype lint` with typed options.
+func RunPrototypeLintWithOptions(o PrototypeLintOptions) error {
+	pl, err := newPrototypeLintWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return pl.run()
+}
+
+type prototypeLintOpt func(*PrototypeLint)
+
+// PrototypeLint checks the directives of prototype files.
+type PrototypeLint struct {
+	app    app.App
+	paths  []string
+	output string
+
+	out    io.Writer
+	lintFn func(src string) []prototype.Problem
+}
+
+// PrototypeLintOptions are the options for PrototypeLint.
+type PrototypeLintOptions struct {
+	App    app.App  `option:"app"`
+	Paths  []string `option:"arguments"`
+	Output string   `option:"output,optional"`
+}
+
+func newPrototypeLint(m map[string]interface{}, opts ...prototypeLintOpt) (*PrototypeLint, error) {
+	var o PrototypeLintOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return newPrototypeLintWithOptions(o, opts...)
+}
+
+func newPrototypeLintWithOptions(o PrototypeLintOptions, opts ...prototypeLintOpt) (*PrototypeLint, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	pl := &PrototypeLint{
+		app:    o.App,
+		paths:  o.Paths,
+		output: o.Output,
+
+		out:    os.Stdout,
+		lintFn: prototype.Lint,
+	}
+
+	for _, opt := range opts {
+		opt(pl)
+	}
+
+	return pl, nil
+}
+
+func (pl *PrototypeLint) run() error {
+	files, err := pl.files()
+	if err != nil {
+		return err
+	}
+
+	t := table.New("prototypeLint", pl.out)
+	t.SetHeader([]string{"location", "problem"})
+
+	f, err := table.DetectFormat(pl.output)
+	if err != nil {
+		return errors.Wrap(err, "detecting output format")
+	}
+	t.SetFormat(f)
+
+	count := 0
+	for _, file := range files {
+		b, err := afero.ReadFile(pl.app.Fs(), file)
+		if err != nil {
+			return err
+		}
+
+		for _, problem := range pl.lintFn(string(b)) {
+			location := file
+			if problem.Line > 0 {
+				location += ":" + strconv.Itoa(problem.Line)
+			}
+			t.Append([]string{location, problem.Message})
+			count++
+		}
+	}
+
+	if err = t.Render(); err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return errors.Errorf("%d problem(s) found in %d prototype(s)", count, len(files))
+	}
+
+	return nil
+}
+
+// files returns the prototype files in the paths. Directories are searched
+// for jsonnet files.
+func (pl *PrototypeLint) files() ([]string, error) {
+	fs := pl.app.Fs()
+
+	var files []string
+	for _, path := range pl.paths {
+		fi, err := fs.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !fi.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		err = afero.Walk(fs, path, func(p string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !fi.IsDir() && filepath.Ext(p) == ".jsonnet" {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"testing"
+
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrototypeLint(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		valid := `// @name io.ksonnet.pkg.valid
+// @shortDescription A valid prototype
+// @param name string Name
+{ name: params.name }`
+		invalid := `// @name io.ksonnet.pkg.invalid
+// @shortDescription An invalid prototype
+// @param name string Name
+{ image: params.image }`
+
+		fs := appMock.Fs()
+		require.NoError(t, afero.WriteFile(fs, "/prototypes/valid.jsonnet", []byte(valid), 0644))
+		require.NoError(t, afero.WriteFile(fs, "/prototypes/invalid.jsonnet", []byte(invalid), 0644))
+		require.NoError(t, afero.WriteFile(fs, "/prototypes/README.md", []byte("# prototypes"), 0644))
+
+		cases := []struct {
+			name     string
+			paths    []string
+			expected string
+			isErr    bool
+		}{
+			{
+				name:     "valid file",
+				paths:    []string{"/prototypes/valid.jsonnet"},
+				expected: "LOCATION PROBLEM\n======== =======\n",
+			},
+			{
+				name:  "directory",
+				paths: []string{"/prototypes"},
+				expected: `LOCATION                      PROBLEM
+========                      =======
+/prototypes/invalid.jsonnet:3 parameter "name" is declared, but the template does not use it
+/prototypes/invalid.jsonnet:4 parameter "image" is used, but not declared
+`,
+				isErr: true,
+			},
+			{
+				name:  "missing file",
+				paths: []string{"/prototypes/missing.jsonnet"},
+				isErr: true,
+			},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				in := map[string]interface{}{
+					OptionApp:       appMock,
+					OptionArguments: tc.paths,
+				}
+
+				a, err := newPrototypeLint(in)
+				require.NoError(t, err)
+
+				var buf bytes.Buffer
+				a.out = &buf
+
+				err = a.run()
+				if tc.isErr {
+					require.Error(t, err)
+				} else {
+					require.NoError(t, err)
+				}
+
+				if tc.expected != "" {
+					assert.Equal(t, tc.expected, buf.String())
+				}
+			})
+		}
+	})
+}
+
+func TestPrototypeLint_requires_app(t *testing.T) {
+	in := make(map[string]interface{})
+	_, err := newPrototypeLint(in)
+	require.Error(t, err)
+}
@@ -29,7 +29,17 @@ import (
 
 // RunPrototypeList runs `prototype list`
 func RunPrototypeList(m map[string]interface{}) error {
-	pl, err := NewPrototypeList(m)
+	var o PrototypeListOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunPrototypeListWithOptions(o)
+}
+
+// RunPrototypeListWithOptions runs `prototype list` with typed options.
+func RunPrototypeListWithOptions(o PrototypeListOptions) error {
+	pl, err := NewPrototypeListWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -46,23 +56,37 @@ type PrototypeList struct {
 	packageManager registry.PackageManager
 }
 
-// NewPrototypeList creates an instance of PrototypeList
+// PrototypeListOptions are the options for PrototypeList.
+type PrototypeListOptions struct {
+	App           app.App `option:"app"`
+	Output        string  `option:"output,optional"`
+	TLSSkipVerify bool    `option:"tls-skip-verify,optional"`
+}
+
+// NewPrototypeList creates an instance of PrototypeList from an option map.
 func NewPrototypeList(m map[string]interface{}) (*PrototypeList, error) {
-	ol := newOptionLoader(m)
+	var o PrototypeListOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return NewPrototypeListWithOptions(o)
+}
 
-	app := ol.LoadApp()
-	httpClientOpt := registry.HTTPClientOpt(ol.LoadHTTPClient())
+// NewPrototypeListWithOptions creates an instance of PrototypeList.
+func NewPrototypeListWithOptions(o PrototypeListOptions) (*PrototypeList, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	httpClientOpt := registry.HTTPClientOpt(newHTTPClient(o.TLSSkipVerify))
 
 	pl := &PrototypeList{
-		app:        app,
+		app:        o.App,
 		out:        os.Stdout,
-		outputType: ol.LoadOptionalString(OptionOutput),
-
-		packageManager: registry.NewPackageManager(app, httpClientOpt),
-	}
+		outputType: o.Output,
 
-	if ol.err != nil {
-		return nil, ol.err
+		packageManager: registry.NewPackageManager(o.App, httpClientOpt),
 	}
 
 	return pl, nil
@@ -16,12 +16,16 @@
 package actions
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strings"
 
+	"github.com/ghodss/yaml"
 	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/env"
 	"github.com/ksonnet/ksonnet/pkg/ksonnet"
 	"github.com/ksonnet/ksonnet/pkg/pkg"
 	"github.com/ksonnet/ksonnet/pkg/prototype"
@@ -34,9 +38,27 @@ import (
 	"github.com/spf13/pflag"
 )
 
+const (
+	// previewComponentName is the name of the component a prototype is
+	// previewed as.
+	previewComponentName = "preview"
+	// previewSnippetName is the file name of previews in evaluation errors.
+	previewSnippetName = "<preview>"
+)
+
 // RunPrototypePreview runs `prototype describe`
 func RunPrototypePreview(m map[string]interface{}) error {
-	pp, err := NewPrototypePreview(m)
+	var o PrototypePreviewOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunPrototypePreviewWithOptions(o)
+}
+
+// RunPrototypePreviewWithOptions runs `prototype describe` with typed options.
+func RunPrototypePreviewWithOptions(o PrototypePreviewOptions) error {
+	pp, err := NewPrototypePreviewWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -55,28 +77,45 @@ type PrototypePreview struct {
 	bindFlagsFn         func(p *prototype.Prototype) (*pflag.FlagSet, error)
 	packageManager      registry.PackageManager
 	extractParametersFn func(fs afero.Fs, p *prototype.Prototype, f *pflag.FlagSet) (map[string]string, error)
+	evaluateFn          func(a app.App, envName, filename, snippet, paramsStr string) (string, error)
+}
+
+// PrototypePreviewOptions are the options for PrototypePreview.
+type PrototypePreviewOptions struct {
+	App           app.App  `option:"app"`
+	Query         string   `option:"query"`
+	Arguments     []string `option:"arguments"`
+	TLSSkipVerify bool     `option:"tls-skip-verify,optional"`
 }
 
-// NewPrototypePreview creates an instance of PrototypePreview
+// NewPrototypePreview creates an instance of PrototypePreview from an option map.
 func NewPrototypePreview(m map[string]interface{}) (*PrototypePreview, error) {
-	ol := newOptionLoader(m)
+	var o PrototypePreviewOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return NewPrototypePreviewWithOptions(o)
+}
+
+// NewPrototypePreviewWithOptions creates an instance of PrototypePreview.
+func NewPrototypePreviewWithOptions(o PrototypePreviewOptions) (*PrototypePreview, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
 
-	app := ol.LoadApp()
-	httpClientOpt := registry.HTTPClientOpt(ol.LoadHTTPClient())
+	httpClientOpt := registry.HTTPClientOpt(newHTTPClient(o.TLSSkipVerify))
 
 	pp := &PrototypePreview{
-		app:   app,
-		query: ol.LoadString(OptionQuery),
-		args:  ol.LoadStringSlice(OptionArguments),
+		app:   o.App,
+		query: o.Query,
+		args:  o.Arguments,
 
 		out:                 os.Stdout,
-		packageManager:      registry.NewPackageManager(app, httpClientOpt),
+		packageManager:      registry.NewPackageManager(o.App, httpClientOpt),
 		bindFlagsFn:         prototype.BindFlags,
 		extractParametersFn: prototype.ExtractParameters,
-	}
-
-	if ol.err != nil {
-		return nil, ol.err
+		evaluateFn:          evaluatePreview,
 	}
 
 	return pp, nil
@@ -109,6 +148,8 @@ func (pp *PrototypePreview) Run() error {
 		return errors.Wrap(err, "binding prototype flags")
 	}
 
+	bindPreviewFlags(flags)
+
 	if err = flags.Parse(pp.args); err != nil {
 		if strings.Contains(err.Error(), "help request") {
 			return nil
@@ -116,6 +157,10 @@ func (pp *PrototypePreview) Run() error {
 		return errors.Wrap(err, "parse preview args")
 	}
 
+	if err = setParamFlags(p, flags); err != nil {
+		return err
+	}
+
 	// NOTE: only supporting jsonnet templates
 	templateType := prototype.Jsonnet
 
@@ -124,21 +169,155 @@ func (pp *PrototypePreview) Run() error {
 		return err
 	}
 
-	text, err := expandPrototype(p, templateType, params, "preview")
+	text, err := expandPrototype(p, templateType, params, previewComponentName)
+	if err != nil {
+		return err
+	}
+
+	format := commandFlag(flags, "format")
+	if format == "" || format == "jsonnet" {
+		fmt.Fprintln(pp.out, text)
+		return nil
+	}
+
+	envName := commandFlag(flags, "env")
+	if envName == "" {
+		envName = pp.app.CurrentEnvironment()
+	}
+	if envName == "" {
+		return errors.New("rendering a prototype requires an environment; set one with --env or `ks env current`")
+	}
+
+	evaluated, err := pp.evaluateFn(pp.app, envName, previewSnippetName, text, previewParams(params))
 	if err != nil {
 		return err
 	}
 
-	fmt.Fprintln(pp.out, text)
+	return writePreview(pp.out, format, evaluated)
+}
+
+func evaluatePreview(a app.App, envName, filename, snippet, paramsStr string) (string, error) {
+	return env.EvaluateSnippet(a, envName, filename, snippet, paramsStr)
+}
+
+// bindPreviewFlags adds the flags of `prototype preview` to a prototype's
+// flags. A prototype parameter with the same name takes precedence.
+func bindPreviewFlags(flags *pflag.FlagSet) {
+	if flags.Lookup("param") == nil {
+		flags.StringArray("param", nil, "Prototype parameter as <name>=<value>. May be given multiple times.")
+	}
+	if flags.Lookup("format") == nil {
+		flags.StringP("format", "o", "jsonnet", "Output format. Supported values are: jsonnet, json, yaml")
+	}
+	if flags.Lookup("env") == nil {
+		flags.String("env", "", "Environment to render the prototype in")
+	}
+}
+
+// commandFlag returns the value of a string flag, or an empty string if the
+// flag is not a string.
+func commandFlag(flags *pflag.FlagSet, name string) string {
+	f := flags.Lookup(name)
+	if f == nil || f.Value.Type() != "string" {
+		return ""
+	}
+
+	return f.Value.String()
+}
+
+// setParamFlags sets the parameter flags given as --param <name>=<value>.
+func setParamFlags(p *prototype.Prototype, flags *pflag.FlagSet) error {
+	values, err := flags.GetStringArray("param")
+	if err != nil {
+		// A prototype parameter is named param.
+		return nil
+	}
+
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 {
+			return errors.Errorf("parameter %q is not formatted as <name>=<value>", value)
+		}
+
+		if !p.HasParam(parts[0]) {
+			return errors.Errorf("prototype %q does not have a parameter %q", p.Name, parts[0])
+		}
+
+		if err := flags.Set(parts[0], parts[1]); err != nil {
+			return errors.Wrapf(err, "setting parameter %q", parts[0])
+		}
+	}
+
 	return nil
 }
 
+// previewParams creates the params of the preview component. The values of
+// params are jsonnet.
+func previewParams(params map[string]string) string {
+	var names []string
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var fields []string
+	for _, name := range names {
+		fields = append(fields, fmt.Sprintf("%q: %s", name, params[name]))
+	}
+
+	return fmt.Sprintf("{global: {}, components: {%q: {%s}}}",
+		previewComponentName, strings.Join(fields, ", "))
+}
+
+// writePreview writes an evaluated prototype as JSON, or as YAML documents.
+func writePreview(w io.Writer, format, evaluated string) error {
+	switch format {
+	case "json":
+		_, err := fmt.Fprintln(w, evaluated)
+		return err
+	case "yaml":
+		var v interface{}
+		if err := json.Unmarshal([]byte(evaluated), &v); err != nil {
+			return err
+		}
+
+		objects, ok := v.([]interface{})
+		if !ok {
+			objects = []interface{}{v}
+		}
+
+		for _, object := range objects {
+			b, err := yaml.Marshal(object)
+			if err != nil {
+				return errors.Wrap(err, "converting to YAML")
+			}
+			if _, err = fmt.Fprintf(w, "---\n%s", b); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	default:
+		return errors.Errorf("unknown format %q; valid formats are jsonnet, json, and yaml", format)
+	}
+}
+
 // TODO: this doesn't belong here. Needs to be closer to where other jsonnet processing happens.
 func expandPrototype(proto *prototype.Prototype, templateType prototype.TemplateType, params map[string]string, componentName string) (string, error) {
 	template, err := proto.Template.Body(templateType)
 	if err != nil {
 		return "", err
 	}
+	if proto.Template.Engine == prototype.GoEngine {
+		text, err := prototype.RenderTemplate(proto.Name, strings.Join(template, "\n"), params)
+		if err != nil {
+			return "", err
+		}
+		if templateType != prototype.Jsonnet {
+			return text, nil
+		}
+		template = strings.Split(text, "\n")
+	}
 	if templateType == prototype.Jsonnet {
 		componentsText := "components." + componentName
 		if !strutil.IsASCIIIdentifier(componentName) {
@@ -148,7 +327,11 @@ func expandPrototype(proto *prototype.Prototype, templateType prototype.Template
 			`local env = std.extVar("` + ksonnet.EnvExtCodeKey + `");`,
 			`local params = std.extVar("` + ksonnet.ParamsExtCodeKey + `").` + componentsText + ";"},
 			template...)
-		return jsonnet.Parse(componentName, strings.Join(template, "\n"))
+		text, err := jsonnet.InlineImportStr(strings.Join(template, "\n"), params)
+		if err != nil {
+			return "", err
+		}
+		return jsonnet.Parse(componentName, text)
 	}
 
 	tm := snippet.Parse(strings.Join(template, "\n"))
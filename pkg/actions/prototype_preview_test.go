@@ -19,11 +19,13 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/ksonnet/ksonnet/pkg/app"
 	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
 	"github.com/ksonnet/ksonnet/pkg/prototype"
 	registrymocks "github.com/ksonnet/ksonnet/pkg/registry/mocks"
 	"github.com/pkg/errors"
 	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -62,6 +64,104 @@ func TestPrototypePreview(t *testing.T) {
 	})
 }
 
+func TestPrototypePreview_render(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		manager := &registrymocks.PackageManager{}
+		manager.On("Prototypes").Return(prototype.Prototypes{}, nil)
+
+		args := []string{
+			"--param", "name=myDeployment",
+			"--param", "image=nginx",
+			"--containerPort", "80",
+			"--env", "default",
+			"-o", "yaml",
+		}
+
+		in := map[string]interface{}{
+			OptionApp:           appMock,
+			OptionQuery:         "single-port-deployment",
+			OptionArguments:     args,
+			OptionTLSSkipVerify: false,
+		}
+
+		a, err := NewPrototypePreview(in)
+		require.NoError(t, err)
+
+		a.packageManager = manager
+		a.evaluateFn = func(_ app.App, envName, filename, snippet, paramsStr string) (string, error) {
+			assert.Equal(t, "default", envName)
+			assert.Equal(t, previewSnippetName, filename)
+			assert.Contains(t, snippet, "params.containerPort")
+			assert.Equal(t, `{global: {}, components: {"preview": {"containerPort": 80, "image": "nginx", "name": "myDeployment", "replicas": 1}}}`, paramsStr)
+			return `[{"kind": "Deployment"}, {"kind": "Service"}]`, nil
+		}
+
+		var buf bytes.Buffer
+		a.out = &buf
+
+		err = a.Run()
+		require.NoError(t, err)
+
+		assert.Equal(t, "---\nkind: Deployment\n---\nkind: Service\n", buf.String())
+	})
+}
+
+func TestPrototypePreview_render_errors(t *testing.T) {
+	cases := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "unknown parameter",
+			args: []string{"--name", "myDeployment", "--image", "nginx", "--param", "unknown=value"},
+		},
+		{
+			name: "invalid parameter",
+			args: []string{"--name", "myDeployment", "--image", "nginx", "--param", "replicas"},
+		},
+		{
+			name: "no environment",
+			args: []string{"--name", "myDeployment", "--image", "nginx", "-o", "json"},
+		},
+		{
+			name: "unknown format",
+			args: []string{"--name", "myDeployment", "--image", "nginx", "-o", "xml", "--env", "default"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				appMock.On("CurrentEnvironment").Return("")
+
+				manager := &registrymocks.PackageManager{}
+				manager.On("Prototypes").Return(prototype.Prototypes{}, nil)
+
+				in := map[string]interface{}{
+					OptionApp:           appMock,
+					OptionQuery:         "single-port-deployment",
+					OptionArguments:     tc.args,
+					OptionTLSSkipVerify: false,
+				}
+
+				a, err := NewPrototypePreview(in)
+				require.NoError(t, err)
+
+				a.packageManager = manager
+				a.evaluateFn = func(app.App, string, string, string, string) (string, error) {
+					return `{}`, nil
+				}
+
+				var buf bytes.Buffer
+				a.out = &buf
+
+				err = a.Run()
+				require.Error(t, err)
+			})
+		})
+	}
+}
+
 func TestPrototypePreview_bind_flags_failed(t *testing.T) {
 	withApp(t, func(appMock *amocks.App) {
 		prototypes := prototype.Prototypes{}
@@ -104,3 +204,30 @@ func TestPrototypePreview_requires_app(t *testing.T) {
 	_, err := NewPrototypePreview(in)
 	require.Error(t, err)
 }
+
+func Test_expandPrototype_go_template(t *testing.T) {
+	p, err := prototype.JsonnetParse(`// @name io.ksonnet.pkg.service
+// @templateEngine go
+// @param name string Name of the service
+// @optionalParam ports array [80] Ports of the service
+{
+  name: import 'param://name',
+  ports: [
+  {{- range .ports }}
+    {port: {{ . }}},
+  {{- end }}
+  ],
+}`)
+	require.NoError(t, err)
+
+	params := map[string]string{
+		"name":  `"guestbook"`,
+		"ports": `[80, 443]`,
+	}
+
+	got, err := expandPrototype(p, prototype.Jsonnet, params, "guestbook")
+	require.NoError(t, err)
+
+	assert.Contains(t, got, "name: params.name")
+	assert.Contains(t, got, "{port: 80},\n    {port: 443},")
+}
@@ -19,7 +19,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"sort"
 
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/prototype"
@@ -30,7 +29,17 @@ import (
 
 // RunPrototypeSearch runs `prototype search`
 func RunPrototypeSearch(m map[string]interface{}) error {
-	ps, err := NewPrototypeSearch(m)
+	var o PrototypeSearchOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunPrototypeSearchWithOptions(o)
+}
+
+// RunPrototypeSearchWithOptions runs `prototype search` with typed options.
+func RunPrototypeSearchWithOptions(o PrototypeSearchOptions) error {
+	ps, err := NewPrototypeSearchWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -38,7 +47,8 @@ func RunPrototypeSearch(m map[string]interface{}) error {
 	return ps.Run()
 }
 
-// PrototypeSearch searches for prototypes by name.
+// PrototypeSearch searches for prototypes by name, description, and
+// parameters, and lists them from the best match to the worst.
 type PrototypeSearch struct {
 	app        app.App
 	query      string
@@ -49,27 +59,42 @@ type PrototypeSearch struct {
 	protoSearchFn  func(string, prototype.Prototypes) (prototype.Prototypes, error)
 }
 
-// NewPrototypeSearch creates an instance of PrototypeSearch
+// PrototypeSearchOptions are the options for PrototypeSearch.
+type PrototypeSearchOptions struct {
+	App           app.App `option:"app"`
+	Query         string  `option:"query"`
+	Output        string  `option:"output,optional"`
+	TLSSkipVerify bool    `option:"tls-skip-verify,optional"`
+}
+
+// NewPrototypeSearch creates an instance of PrototypeSearch from an option map.
 func NewPrototypeSearch(m map[string]interface{}) (*PrototypeSearch, error) {
-	ol := newOptionLoader(m)
+	var o PrototypeSearchOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
 
-	app := ol.LoadApp()
-	httpClientOpt := registry.HTTPClientOpt(ol.LoadHTTPClient())
+	return NewPrototypeSearchWithOptions(o)
+}
+
+// NewPrototypeSearchWithOptions creates an instance of PrototypeSearch.
+func NewPrototypeSearchWithOptions(o PrototypeSearchOptions) (*PrototypeSearch, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	httpClientOpt := registry.HTTPClientOpt(newHTTPClient(o.TLSSkipVerify))
 
 	ps := &PrototypeSearch{
-		app:        app,
-		query:      ol.LoadString(OptionQuery),
-		outputType: ol.LoadOptionalString(OptionOutput),
+		app:        o.App,
+		query:      o.Query,
+		outputType: o.Output,
 
 		out:            os.Stdout,
-		packageManager: registry.NewPackageManager(app, httpClientOpt),
+		packageManager: registry.NewPackageManager(o.App, httpClientOpt),
 		protoSearchFn:  protoSearch,
 	}
 
-	if ol.err != nil {
-		return nil, ol.err
-	}
-
 	return ps, nil
 }
 
@@ -85,7 +110,7 @@ func (ps *PrototypeSearch) Run() error {
 		return err
 	}
 
-	if len(results) == 1 {
+	if len(results) == 0 {
 		return fmt.Errorf("failed to find any search results for query %q", ps.query)
 	}
 
@@ -103,19 +128,23 @@ func (ps *PrototypeSearch) Run() error {
 	}
 	t.SetFormat(f)
 
-	sort.Slice(rows, func(i, j int) bool {
-		return rows[i][0] < rows[j][0]
-	})
-
 	t.AppendBulk(rows)
 
 	return t.Render()
 }
 
+// protoSearch ranks the system prototypes, and the prototypes of the
+// installed packages, which match a query.
 func protoSearch(query string, prototypes prototype.Prototypes) (prototype.Prototypes, error) {
 	index, err := prototype.NewIndex(prototypes, prototype.DefaultBuilder)
 	if err != nil {
 		return nil, err
 	}
-	return index.SearchNames(query, prototype.Substring)
+
+	all, err := index.List()
+	if err != nil {
+		return nil, err
+	}
+
+	return prototype.Search(all, query), nil
 }
@@ -95,6 +95,53 @@ func TestPrototypeSearch(t *testing.T) {
 
 }
 
+func TestPrototypeSearch_ranked(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		manager := &registrymocks.PackageManager{}
+		manager.On("Prototypes").Return(prototype.Prototypes{}, nil)
+
+		in := map[string]interface{}{
+			OptionApp:           appMock,
+			OptionQuery:         "service",
+			OptionTLSSkipVerify: false,
+		}
+
+		a, err := NewPrototypeSearch(in)
+		require.NoError(t, err)
+
+		a.packageManager = manager
+
+		var buf bytes.Buffer
+		a.out = &buf
+
+		err = a.Run()
+		require.NoError(t, err)
+
+		assertOutput(t, "prototype/search/ranked.txt", buf.String())
+	})
+}
+
+func TestPrototypeSearch_no_results(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		manager := &registrymocks.PackageManager{}
+		manager.On("Prototypes").Return(prototype.Prototypes{}, nil)
+
+		in := map[string]interface{}{
+			OptionApp:           appMock,
+			OptionQuery:         "missing",
+			OptionTLSSkipVerify: false,
+		}
+
+		a, err := NewPrototypeSearch(in)
+		require.NoError(t, err)
+
+		a.packageManager = manager
+
+		err = a.Run()
+		require.Error(t, err)
+	})
+}
+
 func TestProtoptypeSearch_requires_app(t *testing.T) {
 	in := make(map[string]interface{})
 	_, err := NewPrototypeSearch(in)
@@ -16,6 +16,7 @@
 package actions
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"strings"
@@ -23,6 +24,7 @@ import (
 	param "github.com/ksonnet/ksonnet/metadata/params"
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/component"
+	"github.com/ksonnet/ksonnet/pkg/pkg"
 	"github.com/ksonnet/ksonnet/pkg/prototype"
 	"github.com/ksonnet/ksonnet/pkg/registry"
 	"github.com/pkg/errors"
@@ -33,7 +35,17 @@ import (
 
 // RunPrototypeUse runs `prototype use`
 func RunPrototypeUse(m map[string]interface{}) error {
-	pl, err := NewPrototypeUse(m)
+	var o PrototypeUseOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunPrototypeUseWithOptions(o)
+}
+
+// RunPrototypeUseWithOptions runs `prototype use` with typed options.
+func RunPrototypeUseWithOptions(o PrototypeUseOptions) error {
+	pl, err := NewPrototypeUseWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -45,33 +57,67 @@ func RunPrototypeUse(m map[string]interface{}) error {
 type PrototypeUse struct {
 	app                 app.App
 	args                []string
+	in                  io.Reader
 	out                 io.Writer
 	packageManager      registry.PackageManager
 	createComponentFn   func(app.App, string, string, string, param.Params, prototype.TemplateType) (string, error)
 	bindFlagsFn         func(p *prototype.Prototype) (*pflag.FlagSet, error)
 	extractParametersFn func(fs afero.Fs, p *prototype.Prototype, f *pflag.FlagSet) (map[string]string, error)
+	resolvePrototypeFn  func(d pkg.Descriptor, name string) (*prototype.Prototype, string, error)
+	installFn           func(d pkg.Descriptor) error
 }
 
-// NewPrototypeUse creates an instance of PrototypeUse
+// PrototypeUseOptions are the options for PrototypeUse.
+type PrototypeUseOptions struct {
+	App           app.App  `option:"app"`
+	Arguments     []string `option:"arguments"`
+	TLSSkipVerify bool     `option:"tls-skip-verify,optional"`
+}
+
+// NewPrototypeUse creates an instance of PrototypeUse from an option map.
 func NewPrototypeUse(m map[string]interface{}) (*PrototypeUse, error) {
-	ol := newOptionLoader(m)
+	var o PrototypeUseOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return NewPrototypeUseWithOptions(o)
+}
+
+// NewPrototypeUseWithOptions creates an instance of PrototypeUse.
+func NewPrototypeUseWithOptions(o PrototypeUseOptions) (*PrototypeUse, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
 
-	app := ol.LoadApp()
-	httpClientOpt := registry.HTTPClientOpt(ol.LoadHTTPClient())
+	app := o.App
+	httpClient := newHTTPClient(o.TLSSkipVerify)
+	httpClientOpt := registry.HTTPClientOpt(httpClient)
+	pm := registry.NewPackageManager(app, httpClientOpt)
 
 	pl := &PrototypeUse{
 		app:  app,
-		args: ol.LoadStringSlice(OptionArguments),
+		args: o.Arguments,
 
+		in:                  os.Stdin,
 		out:                 os.Stdout,
-		packageManager:      registry.NewPackageManager(app, httpClientOpt),
+		packageManager:      pm,
 		createComponentFn:   component.Create,
 		bindFlagsFn:         prototype.BindFlags,
 		extractParametersFn: prototype.ExtractParameters,
-	}
 
-	if ol.err != nil {
-		return nil, ol.err
+		resolvePrototypeFn: func(d pkg.Descriptor, name string) (*prototype.Prototype, string, error) {
+			return registry.ResolvePrototype(app, d, name, httpClient)
+		},
+		installFn: func(d pkg.Descriptor) error {
+			libCfg, err := registry.CacheDependency(app, pm, d, d.Name, false, httpClient)
+			if err != nil {
+				return err
+			}
+
+			_, err = app.UpdateLib(d.Name, "", libCfg)
+			return err
+		},
 	}
 
 	return pl, nil
@@ -79,28 +125,24 @@ func NewPrototypeUse(m map[string]interface{}) (*PrototypeUse, error) {
 
 // Run runs the env list action.
 func (pl *PrototypeUse) Run() error {
-	prototypes, err := pl.packageManager.Prototypes()
-	if err != nil {
-		return err
-	}
-
-	index, err := prototype.NewIndex(prototypes, prototype.DefaultBuilder)
-	if err != nil {
-		return err
-	}
-
-	prototypes, err = index.List()
-	if err != nil {
-		return err
-	}
-
 	if len(pl.args) == 0 {
 		return errors.New("prototype name was not supplied as an argument")
 	}
 
 	query := pl.args[0]
 
-	p, err := findUniquePrototype(query, prototypes)
+	var (
+		p       *prototype.Prototype
+		remote  *pkg.Descriptor
+		version string
+		err     error
+	)
+
+	if isRemotePrototype(query) {
+		remote, p, version, err = pl.resolveRemote(query)
+	} else {
+		p, err = pl.findPrototype(query)
+	}
 	if err != nil {
 		return err
 	}
@@ -110,6 +152,8 @@ func (pl *PrototypeUse) Run() error {
 		return errors.Wrap(err, "binding prototype flags")
 	}
 
+	bindUseFlags(flags)
+
 	if err = flags.Parse(pl.args); err != nil {
 		if strings.Contains(err.Error(), "help requested") {
 			return nil
@@ -154,7 +198,32 @@ func (pl *PrototypeUse) Run() error {
 	}
 
 	if name == "" {
-		if err = flags.Set("name", prototypeName); err != nil {
+		// The component name is the default, so it is still prompted for.
+		if err = flags.Lookup("name").Value.Set(prototypeName); err != nil {
+			return err
+		}
+	}
+
+	if answersFile := commandFlag(flags, "answers-file"); answersFile != "" {
+		answers, err := prototype.ReadAnswers(pl.app.Fs(), answersFile)
+		if err != nil {
+			return err
+		}
+
+		if err = prototype.SetAnswers(p, flags, answers); err != nil {
+			return err
+		}
+	}
+
+	// A prototype parameter named install takes precedence over the flag.
+	install, err := flags.GetBool("install")
+	install = err == nil && install
+	if install && remote == nil {
+		return errors.New("--install can only be used with a prototype from a registry, e.g. <registry>/<package>/<prototype>")
+	}
+
+	if interactive, err := flags.GetBool("interactive"); err == nil && interactive {
+		if err = prototype.Prompt(p, flags, pl.in, pl.out); err != nil {
 			return err
 		}
 	}
@@ -169,6 +238,8 @@ func (pl *PrototypeUse) Run() error {
 		return err
 	}
 
+	text = recordPrototype(text, templateType, p, remote, version)
+
 	ps := param.Params{}
 	for k, v := range rawParams {
 		ps[k] = v
@@ -179,5 +250,96 @@ func (pl *PrototypeUse) Run() error {
 		return errors.Wrap(err, "create component")
 	}
 
+	if install {
+		d := *remote
+		if err = pl.installFn(d); err != nil {
+			return errors.Wrapf(err, "installing package %v", d)
+		}
+	}
+
 	return nil
 }
+
+// findPrototype finds a prototype in the app's installed packages.
+func (pl *PrototypeUse) findPrototype(query string) (*prototype.Prototype, error) {
+	prototypes, err := pl.packageManager.Prototypes()
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := prototype.NewIndex(prototypes, prototype.DefaultBuilder)
+	if err != nil {
+		return nil, err
+	}
+
+	prototypes, err = index.List()
+	if err != nil {
+		return nil, err
+	}
+
+	return findUniquePrototype(query, prototypes)
+}
+
+// resolveRemote fetches a prototype named `<registry>/<package>[@version]/<prototype>`
+// from its registry. It returns the package, the prototype, and the version of
+// the package the prototype was fetched from.
+func (pl *PrototypeUse) resolveRemote(query string) (*pkg.Descriptor, *prototype.Prototype, string, error) {
+	i := strings.LastIndex(query, "/")
+	name := query[i+1:]
+
+	d, err := pkg.Parse(query[:i])
+	if err != nil || d.Registry == "" || name == "" {
+		return nil, nil, "", errors.Errorf("prototype %q should be in the form `<registry>/<package>[@version]/<prototype>`", query)
+	}
+
+	p, version, err := pl.resolvePrototypeFn(d, name)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	return &d, p, version, nil
+}
+
+// isRemotePrototype returns true if a prototype is named with its registry
+// and package. Prototype names do not contain slashes.
+func isRemotePrototype(query string) bool {
+	return strings.Contains(query, "/")
+}
+
+// recordPrototype records the prototype a component was generated from at
+// the top of the component. If the prototype came from a registry, the
+// version of its package is recorded too.
+func recordPrototype(text string, templateType prototype.TemplateType, p *prototype.Prototype, d *pkg.Descriptor, version string) string {
+	var comment string
+	switch templateType {
+	case prototype.Jsonnet:
+		comment = "//"
+	case prototype.YAML:
+		comment = "#"
+	default:
+		// JSON does not have comments.
+		return text
+	}
+
+	header := fmt.Sprintf("%s @prototype %s\n", comment, p.Name)
+	if d != nil {
+		pinned := pkg.Descriptor{Registry: d.Registry, Name: d.Name, Version: version}
+		header += fmt.Sprintf("%s @package %s\n", comment, pinned)
+	}
+
+	return header + text
+}
+
+// bindUseFlags adds the flags of `prototype use` to a prototype's flags. A
+// prototype parameter with the same name takes precedence.
+func bindUseFlags(flags *pflag.FlagSet) {
+	if flags.Lookup("interactive") == nil {
+		flags.BoolP("interactive", "i", false, "Prompt for the prototype's parameters which are not given as flags")
+	}
+	if flags.Lookup("answers-file") == nil {
+		flags.String("answers-file", "", "YAML or JSON file with the values of the prototype's parameters")
+	}
+	if flags.Lookup("install") == nil {
+		flags.Bool("install", false, "Install the package of a prototype from a registry after generating the component")
+	}
+}
@@ -16,14 +16,18 @@
 package actions
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 
 	param "github.com/ksonnet/ksonnet/metadata/params"
 	"github.com/ksonnet/ksonnet/pkg/app"
 	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/pkg"
 	"github.com/ksonnet/ksonnet/pkg/prototype"
 	registrymocks "github.com/ksonnet/ksonnet/pkg/registry/mocks"
 	"github.com/pkg/errors"
+	"github.com/spf13/afero"
 	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -178,6 +182,154 @@ func TestPrototypeUse_with_module_in_name(t *testing.T) {
 	})
 }
 
+func TestPrototypeUse_interactive(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		manager := &registrymocks.PackageManager{}
+		manager.On("Prototypes").Return(prototype.Prototypes{}, nil)
+
+		require.NoError(t, afero.WriteFile(appMock.Fs(), "/answers.yaml", []byte("containerPort: 8080\n"), 0644))
+
+		args := []string{
+			"single-port-deployment",
+			"deployment",
+			"--answers-file", "/answers.yaml",
+			"-i",
+		}
+
+		in := map[string]interface{}{
+			OptionApp:           appMock,
+			OptionArguments:     args,
+			OptionTLSSkipVerify: false,
+		}
+
+		a, err := NewPrototypeUse(in)
+		require.NoError(t, err)
+
+		a.packageManager = manager
+		// name defaults to the component name, and replicas to its default.
+		a.in = strings.NewReader("\nnginx\n\n")
+		var out bytes.Buffer
+		a.out = &out
+
+		a.createComponentFn = func(_ app.App, moduleName, name string, text string, params param.Params, template prototype.TemplateType) (string, error) {
+			expectedParams := param.Params{
+				"name":          `"deployment"`,
+				"image":         `"nginx"`,
+				"replicas":      "1",
+				"containerPort": "8080",
+			}
+
+			assert.Equal(t, expectedParams, params)
+			return "", nil
+		}
+
+		err = a.Run()
+		require.NoError(t, err)
+
+		assert.Contains(t, out.String(), "name (string) Name of the deployment [deployment]: ")
+		assert.NotContains(t, out.String(), "containerPort")
+	})
+}
+
+func TestPrototypeUse_remote(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		manager := &registrymocks.PackageManager{}
+		manager.On("Prototypes").Return(prototype.Prototypes{}, nil)
+
+		args := []string{
+			"incubator/apps@0.1.0/single-port-deployment",
+			"deployment",
+			"--image", "nginx",
+			"--containerPort", "80",
+			"--install",
+		}
+
+		in := map[string]interface{}{
+			OptionApp:           appMock,
+			OptionArguments:     args,
+			OptionTLSSkipVerify: false,
+		}
+
+		a, err := NewPrototypeUse(in)
+		require.NoError(t, err)
+
+		a.packageManager = manager
+
+		expected := pkg.Descriptor{Registry: "incubator", Name: "apps", Version: "0.1.0"}
+
+		a.resolvePrototypeFn = func(d pkg.Descriptor, name string) (*prototype.Prototype, string, error) {
+			assert.Equal(t, expected, d)
+			assert.Equal(t, "single-port-deployment", name)
+
+			p, err := a.findPrototype(name)
+			return p, "abc123", err
+		}
+
+		var installed []pkg.Descriptor
+		a.installFn = func(d pkg.Descriptor) error {
+			installed = append(installed, d)
+			return nil
+		}
+
+		a.createComponentFn = func(_ app.App, moduleName, name string, text string, params param.Params, template prototype.TemplateType) (string, error) {
+			assert.Equal(t, "deployment", name)
+			assertOutput(t, "prototype/use/remote.txt", text)
+			return "", nil
+		}
+
+		err = a.Run()
+		require.NoError(t, err)
+
+		assert.Equal(t, []pkg.Descriptor{expected}, installed)
+	})
+}
+
+func TestPrototypeUse_remote_invalid(t *testing.T) {
+	cases := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "missing registry",
+			args: []string{"/single-port-deployment", "deployment"},
+		},
+		{
+			name: "missing prototype",
+			args: []string{"incubator/apps/", "deployment"},
+		},
+		{
+			name: "install without a registry",
+			args: []string{"single-port-deployment", "deployment", "--image", "nginx", "--install"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				manager := &registrymocks.PackageManager{}
+				manager.On("Prototypes").Return(prototype.Prototypes{}, nil)
+
+				in := map[string]interface{}{
+					OptionApp:           appMock,
+					OptionArguments:     tc.args,
+					OptionTLSSkipVerify: false,
+				}
+
+				a, err := NewPrototypeUse(in)
+				require.NoError(t, err)
+
+				a.packageManager = manager
+				a.createComponentFn = func(app.App, string, string, string, param.Params, prototype.TemplateType) (string, error) {
+					return "", errors.New("unexpected component")
+				}
+
+				err = a.Run()
+				require.Error(t, err)
+			})
+		})
+	}
+}
+
 func TestPrototypeUse_requires_app(t *testing.T) {
 	in := make(map[string]interface{})
 	_, err := NewPrototypeUse(in)
@@ -16,18 +16,28 @@
 package actions
 
 import (
+	"fmt"
 	"net/http"
-	"net/url"
-	"strings"
 
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/registry"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 )
 
 // RunRegistryAdd runs `registry add`
 func RunRegistryAdd(m map[string]interface{}) error {
-	ra, err := NewRegistryAdd(m)
+	var o RegistryAddOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunRegistryAddWithOptions(o)
+}
+
+// RunRegistryAddWithOptions runs `registry add` with typed options.
+func RunRegistryAddWithOptions(o RegistryAddOptions) error {
+	ra, err := NewRegistryAddWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -42,26 +52,49 @@ type RegistryAdd struct {
 	uri        string
 	isOverride bool
 	httpClient *http.Client
+	confirmer  *confirmer
 
-	registryAddFn func(a app.App, protocol registry.Protocol, name string, uri string, isOverride bool, httpClient *http.Client) (*registry.Spec, error)
+	registryAddFn    func(a app.App, protocol registry.Protocol, name string, uri string, isOverride bool, httpClient *http.Client) (*registry.Spec, error)
+	detectProtocolFn func(uri string) (registry.Protocol, string, error)
+	defaultBranchFn  func(uri string, httpClient *http.Client) (string, error)
 }
 
-// NewRegistryAdd creates an instance of RegistryAdd.
+// RegistryAddOptions are the options for RegistryAdd.
+type RegistryAddOptions struct {
+	App           app.App `option:"app"`
+	Name          string  `option:"name"`
+	URI           string  `option:"URI"`
+	Override      bool    `option:"override"`
+	TLSSkipVerify bool    `option:"tls-skip-verify,optional"`
+}
+
+// NewRegistryAdd creates an instance of RegistryAdd from an option map.
 func NewRegistryAdd(m map[string]interface{}) (*RegistryAdd, error) {
-	ol := newOptionLoader(m)
+	var o RegistryAddOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
 
-	ra := &RegistryAdd{
-		app:        ol.LoadApp(),
-		name:       ol.LoadString(OptionName),
-		uri:        ol.LoadString(OptionURI),
-		isOverride: ol.LoadBool(OptionOverride),
-		httpClient: ol.LoadHTTPClient(),
+	return NewRegistryAddWithOptions(o)
+}
 
-		registryAddFn: registry.Add,
+// NewRegistryAddWithOptions creates an instance of RegistryAdd.
+func NewRegistryAddWithOptions(o RegistryAddOptions) (*RegistryAdd, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
 	}
 
-	if ol.err != nil {
-		return nil, ol.err
+	ra := &RegistryAdd{
+		app:        o.App,
+		name:       o.Name,
+		uri:        o.URI,
+		isOverride: o.Override,
+		httpClient: newHTTPClient(o.TLSSkipVerify),
+		confirmer:  newConfirmer(false),
+
+		registryAddFn:    registry.Add,
+		detectProtocolFn: registry.DetectProtocol,
+		defaultBranchFn:  registry.ResolveDefaultBranch,
 	}
 
 	return ra, nil
@@ -83,54 +116,37 @@ type registryDetails struct {
 	Protocol registry.Protocol
 }
 
+// protocol detects the registry's protocol from its URI. If the URI has a
+// common mistake, e.g. it is a git clone URL, the user is asked whether to use
+// the suggested correction instead.
 func (ra *RegistryAdd) protocol() (registryDetails, error) {
-	if ra.isGitHub() {
-		rd := registryDetails{
-			URI:      ra.uri,
-			Protocol: registry.ProtocolGitHub,
-		}
-
-		return rd, nil
+	protocol, uri, err := ra.detectProtocolFn(ra.uri)
+	if err == nil {
+		return registryDetails{URI: uri, Protocol: protocol}, nil
 	}
 
-	if strings.HasPrefix(ra.uri, "file://") {
-		u, err := url.Parse(ra.uri)
-		if err != nil {
-			return registryDetails{}, err
-		}
-
-		rd := registryDetails{
-			URI:      u.Path,
-			Protocol: registry.ProtocolFilesystem,
-		}
-
-		return rd, nil
+	uerr, ok := errors.Cause(err).(*registry.InvalidURIError)
+	if !ok || uerr.Suggestion == "" || !ra.confirmer.isTerminalFn() {
+		return registryDetails{}, err
 	}
 
-	if strings.HasPrefix(ra.uri, "/") || strings.HasPrefix(ra.uri, ".") {
-		rd := registryDetails{
-			URI:      ra.uri,
-			Protocol: registry.ProtocolFilesystem,
-		}
-
-		return rd, nil
+	// Suggestions for paths without a branch use the repository's default
+	// branch, which is only known to GitHub.
+	suggestion, rerr := ra.defaultBranchFn(uerr.Suggestion, ra.httpClient)
+	if rerr != nil {
+		log.WithError(rerr).Debug("resolving suggested registry URI")
+		return registryDetails{}, err
 	}
 
-	_, err := url.Parse(ra.uri)
-	if err == nil {
-		// assuming uri is a helm repository URL
-		rd := registryDetails{
-			URI:      ra.uri,
-			Protocol: registry.ProtocolHelm,
-		}
-
-		return rd, nil
+	action := fmt.Sprintf("Registry URI %q is invalid: %s. Use %q instead", uerr.URI, uerr.Reason, suggestion)
+	if cerr := ra.confirmer.confirm(action); cerr != nil {
+		return registryDetails{}, err
 	}
 
-	return registryDetails{}, errors.Errorf("could not detect registry type for %s", ra.uri)
-}
+	protocol, uri, err = ra.detectProtocolFn(suggestion)
+	if err != nil {
+		return registryDetails{}, err
+	}
 
-func (ra *RegistryAdd) isGitHub() bool {
-	return strings.HasPrefix(ra.uri, "github") ||
-		strings.HasPrefix(ra.uri, "https://github")
+	return registryDetails{URI: uri, Protocol: protocol}, nil
 }
@@ -16,7 +16,10 @@
 package actions
 
 import (
+	"bytes"
+	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/ksonnet/ksonnet/pkg/app"
@@ -46,8 +49,8 @@ func TestRegistryAdd(t *testing.T) {
 			},
 			{
 				name:        "github enterprise",
-				uri:         "github.foo.com/foo/bar",
-				expectedURI: "github.foo.com/foo/bar",
+				uri:         "https://github.foo.com/api/v3/repos/foo/bar?ref=master",
+				expectedURI: "https://github.foo.com/api/v3/repos/foo/bar?ref=master",
 				protocol:    registry.ProtocolGitHub,
 			},
 			{
@@ -114,6 +117,94 @@ func TestRegistryAdd(t *testing.T) {
 	})
 }
 
+func TestRegistryAdd_suggestion(t *testing.T) {
+	cases := []struct {
+		name        string
+		uri         string
+		isTerminal  bool
+		answer      string
+		expectedURI string
+		isErr       bool
+	}{
+		{
+			name:        "accepted",
+			uri:         "git@github.com:foo/bar.git",
+			isTerminal:  true,
+			answer:      "y\n",
+			expectedURI: "github.com/foo/bar",
+		},
+		{
+			name:        "accepted with the default branch",
+			uri:         "github.com/foo/bar/incubator",
+			isTerminal:  true,
+			answer:      "y\n",
+			expectedURI: "github.com/foo/bar/tree/main/incubator",
+		},
+		{
+			name:       "declined",
+			uri:        "git@github.com:foo/bar.git",
+			isTerminal: true,
+			answer:     "n\n",
+			isErr:      true,
+		},
+		{
+			name:  "not a terminal",
+			uri:   "git@github.com:foo/bar.git",
+			isErr: true,
+		},
+		{
+			name:       "no suggestion",
+			uri:        "https://gitlab.com/foo/bar",
+			isTerminal: true,
+			answer:     "y\n",
+			isErr:      true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				in := map[string]interface{}{
+					OptionApp:           appMock,
+					OptionName:          "new",
+					OptionURI:           tc.uri,
+					OptionOverride:      false,
+					OptionTLSSkipVerify: false,
+				}
+
+				a, err := NewRegistryAdd(in)
+				require.NoError(t, err)
+
+				var prompt bytes.Buffer
+				a.confirmer.in = strings.NewReader(tc.answer)
+				a.confirmer.out = &prompt
+				a.confirmer.isTerminalFn = func() bool { return tc.isTerminal }
+				a.defaultBranchFn = func(uri string, httpClient *http.Client) (string, error) {
+					return strings.Replace(uri, "<default-branch>", "main", 1), nil
+				}
+
+				var added string
+				a.registryAddFn = func(a app.App, protocol registry.Protocol, name string, uri string, isOverride bool, httpClient *http.Client) (*registry.Spec, error) {
+					assert.Equal(t, registry.ProtocolGitHub, protocol)
+					added = uri
+					return &registry.Spec{}, nil
+				}
+
+				err = a.Run()
+				if tc.isErr {
+					require.Error(t, err)
+					assert.Empty(t, added)
+					return
+				}
+
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedURI, added)
+				assert.Contains(t, prompt.String(), fmt.Sprintf("Use %q instead? [y/N]", tc.expectedURI))
+			})
+		})
+	}
+}
+
 func TestRegistryAdd_requires_app(t *testing.T) {
 	in := make(map[string]interface{})
 	_, err := NewRegistryAdd(in)
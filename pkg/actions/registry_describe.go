@@ -24,12 +24,23 @@ import (
 
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/registry"
+	"github.com/ksonnet/ksonnet/pkg/util/table"
 	"github.com/pkg/errors"
 )
 
 // RunRegistryDescribe runs `prototype list`
 func RunRegistryDescribe(m map[string]interface{}) error {
-	rd, err := NewRegistryDescribe(m)
+	var o RegistryDescribeOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunRegistryDescribeWithOptions(o)
+}
+
+// RunRegistryDescribeWithOptions runs `prototype list` with typed options.
+func RunRegistryDescribeWithOptions(o RegistryDescribeOptions) error {
+	rd, err := NewRegistryDescribeWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -41,18 +52,40 @@ func RunRegistryDescribe(m map[string]interface{}) error {
 type RegistryDescribe struct {
 	app                 app.App
 	name                string
+	outputType          string
 	out                 io.Writer
 	fetchRegistrySpecFn func(a app.App, name string) (*registry.Spec, *app.RegistryConfig, error)
 }
 
-// NewRegistryDescribe creates an instance of RegistryDescribe
+// RegistryDescribeOptions are the options for RegistryDescribe.
+type RegistryDescribeOptions struct {
+	App           app.App `option:"app"`
+	Name          string  `option:"name"`
+	Output        string  `option:"output,optional"`
+	TLSSkipVerify bool    `option:"tls-skip-verify,optional"`
+}
+
+// NewRegistryDescribe creates an instance of RegistryDescribe from an option map.
 func NewRegistryDescribe(m map[string]interface{}) (*RegistryDescribe, error) {
-	ol := newOptionLoader(m)
+	var o RegistryDescribeOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
 
-	httpClient := ol.LoadHTTPClient()
+	return NewRegistryDescribeWithOptions(o)
+}
+
+// NewRegistryDescribeWithOptions creates an instance of RegistryDescribe.
+func NewRegistryDescribeWithOptions(o RegistryDescribeOptions) (*RegistryDescribe, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	httpClient := newHTTPClient(o.TLSSkipVerify)
 	rd := &RegistryDescribe{
-		app:  ol.LoadApp(),
-		name: ol.LoadString(OptionName),
+		app:        o.App,
+		name:       o.Name,
+		outputType: o.Output,
 
 		out: os.Stdout,
 		fetchRegistrySpecFn: func(a app.App, name string) (*registry.Spec, *app.RegistryConfig, error) {
@@ -60,10 +93,6 @@ func NewRegistryDescribe(m map[string]interface{}) (*RegistryDescribe, error) {
 		},
 	}
 
-	if ol.err != nil {
-		return nil, ol.err
-	}
-
 	return rd, nil
 }
 
@@ -74,6 +103,26 @@ func (rd *RegistryDescribe) Run() error {
 		return err
 	}
 
+	libs := make([]string, 0, len(spec.Libraries))
+	for _, lib := range spec.Libraries {
+		libs = append(libs, lib.Path)
+	}
+	sort.Strings(libs)
+
+	f, err := table.DetectFormat(rd.outputType)
+	if err != nil {
+		return errors.Wrap(err, "detecting output format")
+	}
+
+	if f != table.FormatTable {
+		return table.Encode(rd.out, f, registryDescription{
+			Name:     regRef.Name,
+			URI:      regRef.URI,
+			Protocol: regRef.Protocol,
+			Packages: libs,
+		})
+	}
+
 	fmt.Fprintln(rd.out, `REGISTRY NAME:`)
 	fmt.Fprintln(rd.out, regRef.Name)
 	fmt.Fprintln(rd.out)
@@ -84,12 +133,6 @@ func (rd *RegistryDescribe) Run() error {
 	fmt.Fprintln(rd.out, regRef.Protocol)
 	fmt.Fprintln(rd.out)
 	fmt.Fprintln(rd.out, `PACKAGES:`)
-
-	libs := make([]string, 0, len(spec.Libraries))
-	for _, lib := range spec.Libraries {
-		libs = append(libs, lib.Path)
-	}
-	sort.Strings(libs)
 	for _, libPath := range libs {
 		fmt.Fprintf(rd.out, "  %s\n", libPath)
 	}
@@ -97,6 +140,15 @@ func (rd *RegistryDescribe) Run() error {
 	return nil
 }
 
+// registryDescription is the description of a registry in the structured
+// output formats.
+type registryDescription struct {
+	Name     string   `json:"name"`
+	URI      string   `json:"uri"`
+	Protocol string   `json:"protocol"`
+	Packages []string `json:"packages"`
+}
+
 func fetchRegistrySpec(a app.App, name string, httpClient *http.Client) (*registry.Spec, *app.RegistryConfig, error) {
 	appRegistries, err := a.Registries()
 	if err != nil {
@@ -74,6 +74,43 @@ func TestRegistryDescribe(t *testing.T) {
 	})
 }
 
+func TestRegistryDescribe_json(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionApp:    appMock,
+			OptionName:   "incubator",
+			OptionOutput: "json",
+		}
+
+		a, err := NewRegistryDescribe(in)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		a.out = &buf
+
+		a.fetchRegistrySpecFn = func(a app.App, name string) (*registry.Spec, *app.RegistryConfig, error) {
+			spec := &registry.Spec{
+				Libraries: registry.LibraryConfigs{
+					"nginx":  &registry.LibraryConfig{Path: "nginx"},
+					"apache": &registry.LibraryConfig{Path: "apache"},
+				},
+			}
+
+			regRef := &app.RegistryConfig{
+				Name:     "incubator",
+				URI:      "github.com/ksonnet/parts/tree/master/incubator",
+				Protocol: "github",
+			}
+
+			return spec, regRef, nil
+		}
+
+		require.NoError(t, a.Run())
+
+		assertOutput(t, "registry/describe/output.json", buf.String())
+	})
+}
+
 func TestRegistryDescribe_requires_app(t *testing.T) {
 	in := make(map[string]interface{})
 	_, err := NewRegistryDescribe(in)
@@ -24,11 +24,22 @@ import (
 	"github.com/ksonnet/ksonnet/pkg/registry"
 	"github.com/ksonnet/ksonnet/pkg/util/table"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 )
 
 // RunRegistryList runs `env list`
 func RunRegistryList(m map[string]interface{}) error {
-	rl, err := NewRegistryList(m)
+	var o RegistryListOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunRegistryListWithOptions(o)
+}
+
+// RunRegistryListWithOptions runs `env list` with typed options.
+func RunRegistryListWithOptions(o RegistryListOptions) error {
+	rl, err := NewRegistryListWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -40,19 +51,42 @@ func RunRegistryList(m map[string]interface{}) error {
 type RegistryList struct {
 	app        app.App
 	outputType string
+	remote     bool
 
 	registryListFn func(ksApp app.App) ([]registry.Registry, error)
 	out            io.Writer
 }
 
-// NewRegistryList creates an instance of RegistryList
+// RegistryListOptions are the options for RegistryList.
+type RegistryListOptions struct {
+	App    app.App `option:"app"`
+	Output string  `option:"output,optional"`
+	// Remote contacts registries to check the state of their caches.
+	Remote        bool `option:"remote,optional"`
+	TLSSkipVerify bool `option:"tls-skip-verify,optional"`
+}
+
+// NewRegistryList creates an instance of RegistryList from an option map.
 func NewRegistryList(m map[string]interface{}) (*RegistryList, error) {
-	ol := newOptionLoader(m)
+	var o RegistryListOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
 
-	httpClient := ol.LoadHTTPClient()
+	return NewRegistryListWithOptions(o)
+}
+
+// NewRegistryListWithOptions creates an instance of RegistryList.
+func NewRegistryListWithOptions(o RegistryListOptions) (*RegistryList, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	httpClient := newHTTPClient(o.TLSSkipVerify)
 	rl := &RegistryList{
-		app:        ol.LoadApp(),
-		outputType: ol.LoadOptionalString(OptionOutput),
+		app:        o.App,
+		outputType: o.Output,
+		remote:     o.Remote,
 
 		registryListFn: func(ksApp app.App) ([]registry.Registry, error) {
 			return registry.List(ksApp, httpClient)
@@ -60,10 +94,6 @@ func NewRegistryList(m map[string]interface{}) (*RegistryList, error) {
 		out: os.Stdout,
 	}
 
-	if ol.err != nil {
-		return nil, ol.err
-	}
-
 	return rl, nil
 }
 
@@ -75,7 +105,11 @@ func (rl *RegistryList) Run() error {
 	}
 
 	t := table.New("registryList", rl.out)
-	t.SetHeader([]string{"name", "override", "protocol", "uri"})
+	header := []string{"name", "override", "protocol", "uri"}
+	if rl.remote {
+		header = append(header, "refspec", "version", "latest", "stale")
+	}
+	t.SetHeader(header)
 
 	f, err := table.DetectFormat(rl.outputType)
 	if err != nil {
@@ -91,12 +125,17 @@ func (rl *RegistryList) Run() error {
 			override = "*"
 		}
 
-		rows = append(rows, []string{
+		row := []string{
 			r.Name(),
 			override,
 			r.Protocol().String(),
 			r.URI(),
-		})
+		}
+		if rl.remote {
+			row = append(row, rl.status(r)...)
+		}
+
+		rows = append(rows, row)
 	}
 
 	sort.Slice(rows, func(i, j int) bool {
@@ -107,3 +146,34 @@ func (rl *RegistryList) Run() error {
 
 	return t.Render()
 }
+
+// status returns the refspec, cached version, latest version, and staleness
+// columns for a registry.
+func (rl *RegistryList) status(r registry.Registry) []string {
+	status, err := r.Status()
+	if err != nil {
+		log.WithError(err).Warnf("checking status of registry %s", r.Name())
+		return []string{"", "", "unavailable", ""}
+	}
+
+	stale := ""
+	if status.IsStale() {
+		stale = "*"
+	}
+
+	return []string{
+		status.RefSpec,
+		shortCommit(status.Version),
+		shortCommit(status.Latest),
+		stale,
+	}
+}
+
+// shortCommit abbreviates a commit SHA.
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+
+	return commit
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/ksonnet/ksonnet/pkg/app"
 	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
 	"github.com/ksonnet/ksonnet/pkg/registry"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -30,6 +31,7 @@ func TestRegistryList(t *testing.T) {
 		name       string
 		outputType string
 		outputFile string
+		remote     bool
 		isErr      bool
 	}{
 		{
@@ -42,6 +44,12 @@ func TestRegistryList(t *testing.T) {
 			outputType: "json",
 			outputFile: "registry/list/output.json",
 		},
+		{
+			name:       "remote status",
+			outputType: "table",
+			outputFile: "registry/list/remote.txt",
+			remote:     true,
+		},
 		{
 			name:       "invalid output",
 			outputType: "invalid",
@@ -55,6 +63,7 @@ func TestRegistryList(t *testing.T) {
 				in := map[string]interface{}{
 					OptionApp:           appMock,
 					OptionOutput:        tc.outputType,
+					OptionRemote:        tc.remote,
 					OptionTLSSkipVerify: false,
 				}
 
@@ -65,10 +74,17 @@ func TestRegistryList(t *testing.T) {
 				a.out = &buf
 
 				a.registryListFn = func(app.App) ([]registry.Registry, error) {
-					registries := []registry.Registry{
-						mockRegistry("override", true),
-						mockRegistry("incubator", false),
-					}
+					override := mockRegistry("override", true)
+					override.On("Status").Return(nil, errors.New("unavailable"))
+
+					incubator := mockRegistry("incubator", false)
+					incubator.On("Status").Return(&registry.Status{
+						RefSpec: "master",
+						Version: "40285d8a14f1ac5787e405e1023cf0c07f6aa28c",
+						Latest:  "1bc7da5b3f4cb4b4e1d8e4c2c4c0b2a3d7f2a0e1",
+					}, nil)
+
+					registries := []registry.Registry{override, incubator}
 					return registries, nil
 				}
 
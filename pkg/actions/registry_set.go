@@ -24,22 +24,24 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-// RunRegistrySet runs `env list`
+// RunRegistrySet runs `registry set`
 func RunRegistrySet(m map[string]interface{}) error {
-	ru, err := NewRegistrySet(m)
-	if err != nil {
+	var o RegistrySetOptions
+	if err := loadOptions(m, &o); err != nil {
 		return err
 	}
 
-	ol := newOptionLoader(m)
-	name := ol.LoadString(OptionName)
-	uri := ol.LoadString(OptionURI)
+	return RunRegistrySetWithOptions(o)
+}
 
-	if ol.err != nil {
-		return ol.err
+// RunRegistrySetWithOptions runs `registry set` with typed options.
+func RunRegistrySetWithOptions(o RegistrySetOptions) error {
+	ru, err := NewRegistrySetWithOptions(o)
+	if err != nil {
+		return err
 	}
 
-	return ru.run(name, uri)
+	return ru.run(o.Name, o.URI, o.Default)
 }
 
 type locateFn func(app.App, *app.RegistryConfig) (registry.Setter, error)
@@ -50,22 +52,40 @@ type RegistrySet struct {
 	locateFn locateFn
 }
 
-// NewRegistrySet creates an instance of RegistrySet
+// RegistrySetOptions are the options for RegistrySet.
+type RegistrySetOptions struct {
+	App  app.App `option:"app"`
+	Name string  `option:"name"`
+	URI  string  `option:"URI"`
+	// Default makes the registry the app's default registry.
+	Default       bool `option:"default,optional"`
+	TLSSkipVerify bool `option:"tls-skip-verify,optional"`
+}
+
+// NewRegistrySet creates an instance of RegistrySet from an option map.
 func NewRegistrySet(m map[string]interface{}) (*RegistrySet, error) {
-	ol := newOptionLoader(m)
+	var o RegistrySetOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return NewRegistrySetWithOptions(o)
+}
+
+// NewRegistrySetWithOptions creates an instance of RegistrySet.
+func NewRegistrySetWithOptions(o RegistrySetOptions) (*RegistrySet, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
 
-	httpClient := ol.LoadHTTPClient()
+	httpClient := newHTTPClient(o.TLSSkipVerify)
 	rs := &RegistrySet{
-		app: ol.LoadApp(),
+		app: o.App,
 		locateFn: func(ksApp app.App, spec *app.RegistryConfig) (registry.Setter, error) {
 			return defaultLocate(ksApp, spec, httpClient)
 		},
 	}
 
-	if ol.err != nil {
-		return nil, ol.err
-	}
-
 	return rs, nil
 }
 
@@ -100,7 +120,7 @@ func registryConfig(a app.App, name string) (*app.RegistryConfig, error) {
 }
 
 // run runs the registry set command.
-func (rs *RegistrySet) run(name string, uri string) error {
+func (rs *RegistrySet) run(name string, uri string, makeDefault bool) error {
 	if rs == nil {
 		return errors.Errorf("nil receiver")
 	}
@@ -110,7 +130,23 @@ func (rs *RegistrySet) run(name string, uri string) error {
 		return err
 	}
 
-	return doSetURI(rs.app, rs.locateFn, cfg, uri)
+	if uri == "" && !makeDefault {
+		return errors.Errorf("nothing to set")
+	}
+
+	if uri != "" {
+		if err := doSetURI(rs.app, rs.locateFn, cfg, uri); err != nil {
+			return err
+		}
+	}
+
+	if makeDefault {
+		if err := rs.app.SetDefaultRegistry(name); err != nil {
+			return errors.Wrapf(err, "setting default registry to %v", name)
+		}
+	}
+
+	return nil
 }
 
 // doSetURI sets the URI for the specified registry.
@@ -214,3 +214,31 @@ func TestRegistryUpdate_doSetURI(t *testing.T) {
 		}
 	}
 }
+
+func TestRegistrySet_run_default(t *testing.T) {
+	a := new(amocks.App)
+	registries := app.RegistryConfigs{
+		"incubator": &app.RegistryConfig{
+			Name:     "incubator",
+			Protocol: string(registry.ProtocolGitHub),
+			URI:      "github.com/ksonnet/parts/tree/master/incubator",
+		},
+	}
+	a.On("Registries").Return(registries, nil)
+	a.On("SetDefaultRegistry", "incubator").Return(nil)
+
+	rs := &RegistrySet{
+		app: a,
+		locateFn: func(app.App, *app.RegistryConfig) (registry.Setter, error) {
+			t.Fatal("unexpected registry lookup")
+			return nil, nil
+		},
+	}
+
+	err := rs.run("incubator", "", true)
+	require.NoError(t, err)
+	a.AssertExpectations(t)
+
+	err = rs.run("incubator", "", false)
+	require.Error(t, err)
+}
@@ -0,0 +1,182 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/registry"
+	"github.com/ksonnet/ksonnet/pkg/util/table"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// RunRegistryStats runs `registry stats`
+func RunRegistryStats(m map[string]interface{}) error {
+	var o RegistryStatsOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunRegistryStatsWithOptions(o)
+}
+
+// RunRegistryStatsWithOptions runs `registry stats` with typed options.
+func RunRegistryStatsWithOptions(o RegistryStatsOptions) error {
+	rs, err := NewRegistryStatsWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return rs.Run()
+}
+
+// RegistryStats shows the stats recorded for registries, and enables or
+// disables recording them.
+type RegistryStats struct {
+	app        app.App
+	outputType string
+	enable     bool
+	disable    bool
+
+	enableFn  func(app.App) error
+	disableFn func(app.App) error
+	loadFn    func(app.App) (map[string]*registry.Stats, error)
+	now       func() time.Time
+	out       io.Writer
+}
+
+// RegistryStatsOptions are the options for RegistryStats.
+type RegistryStatsOptions struct {
+	App     app.App `option:"app"`
+	Output  string  `option:"output,optional"`
+	Enable  bool    `option:"enable,optional"`
+	Disable bool    `option:"disable,optional"`
+}
+
+// NewRegistryStats creates an instance of RegistryStats from an option map.
+func NewRegistryStats(m map[string]interface{}) (*RegistryStats, error) {
+	var o RegistryStatsOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return NewRegistryStatsWithOptions(o)
+}
+
+// NewRegistryStatsWithOptions creates an instance of RegistryStats.
+func NewRegistryStatsWithOptions(o RegistryStatsOptions) (*RegistryStats, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	rs := &RegistryStats{
+		app:        o.App,
+		outputType: o.Output,
+		enable:     o.Enable,
+		disable:    o.Disable,
+
+		enableFn:  registry.EnableStats,
+		disableFn: registry.DisableStats,
+		loadFn:    registry.LoadStats,
+		now:       time.Now,
+		out:       os.Stdout,
+	}
+
+	return rs, nil
+}
+
+// Run runs the registry stats action.
+func (rs *RegistryStats) Run() error {
+	switch {
+	case rs.enable && rs.disable:
+		return errors.New("registry stats can't be enabled and disabled at the same time")
+	case rs.enable:
+		if err := rs.enableFn(rs.app); err != nil {
+			return errors.Wrap(err, "enabling registry stats")
+		}
+		log.Info("Registry stats enabled")
+		return nil
+	case rs.disable:
+		if err := rs.disableFn(rs.app); err != nil {
+			return errors.Wrap(err, "disabling registry stats")
+		}
+		log.Info("Registry stats disabled")
+		return nil
+	}
+
+	stats, err := rs.loadFn(rs.app)
+	if err != nil {
+		return err
+	}
+
+	if stats == nil {
+		return errors.New("registry stats are not enabled")
+	}
+
+	t := table.New("registryStats", rs.out)
+	t.SetHeader([]string{"name", "fetches", "avg-fetch-time", "cache-hit-rate", "requests", "rate-limit-used", "rate-limit-reset"})
+
+	f, err := table.DetectFormat(rs.outputType)
+	if err != nil {
+		return errors.Wrap(err, "detecting output format")
+	}
+	t.SetFormat(f)
+
+	var rows [][]string
+	for name, s := range stats {
+		rows = append(rows, rs.row(name, s))
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i][0] < rows[j][0]
+	})
+
+	t.AppendBulk(rows)
+
+	return t.Render()
+}
+
+func (rs *RegistryStats) row(name string, s *registry.Stats) []string {
+	hitRate := ""
+	if rate, ok := s.CacheHitRate(); ok {
+		hitRate = fmt.Sprintf("%.0f%%", rate*100)
+	}
+
+	used, reset := "", ""
+	if n, ok := s.RateLimitUsed(rs.now()); ok {
+		used = fmt.Sprintf("%d/%d", n, s.RateLimit)
+		if !s.RateReset.IsZero() {
+			reset = s.RateReset.Format(time.RFC3339)
+		}
+	}
+
+	return []string{
+		name,
+		strconv.FormatInt(s.Fetches, 10),
+		s.AverageFetchTime().Round(time.Millisecond).String(),
+		hitRate,
+		strconv.FormatInt(s.Requests, 10),
+		used,
+		reset,
+	}
+}
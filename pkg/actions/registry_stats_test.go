@@ -0,0 +1,145 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryStats(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionApp:    appMock,
+			OptionOutput: "table",
+		}
+
+		a, err := NewRegistryStats(in)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		a.out = &buf
+		a.now = func() time.Time {
+			return time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+		}
+
+		a.loadFn = func(app.App) (map[string]*registry.Stats, error) {
+			return map[string]*registry.Stats{
+				"incubator": {
+					Fetches:       4,
+					FetchTime:     2 * time.Second,
+					CacheHits:     3,
+					CacheMisses:   1,
+					Requests:      12,
+					RateLimit:     5000,
+					RateRemaining: 4988,
+					RateReset:     time.Date(2018, 6, 1, 12, 30, 0, 0, time.UTC),
+				},
+				"helm": {
+					Fetches:     1,
+					FetchTime:   250 * time.Millisecond,
+					CacheMisses: 1,
+					Requests:    1,
+				},
+			}, nil
+		}
+
+		err = a.Run()
+		require.NoError(t, err)
+
+		assertOutput(t, "registry/stats/output.txt", buf.String())
+	})
+}
+
+func TestRegistryStats_enable(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		cases := []struct {
+			name    string
+			enable  bool
+			disable bool
+			isErr   bool
+		}{
+			{name: "enable", enable: true},
+			{name: "disable", disable: true},
+			{name: "enable and disable", enable: true, disable: true, isErr: true},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				in := map[string]interface{}{
+					OptionApp:     appMock,
+					OptionEnable:  tc.enable,
+					OptionDisable: tc.disable,
+				}
+
+				a, err := NewRegistryStats(in)
+				require.NoError(t, err)
+
+				var enabled, disabled bool
+				a.enableFn = func(app.App) error {
+					enabled = true
+					return nil
+				}
+				a.disableFn = func(app.App) error {
+					disabled = true
+					return nil
+				}
+
+				err = a.Run()
+				if tc.isErr {
+					require.Error(t, err)
+					assert.False(t, enabled || disabled)
+					return
+				}
+
+				require.NoError(t, err)
+				assert.Equal(t, tc.enable, enabled)
+				assert.Equal(t, tc.disable, disabled)
+			})
+		}
+	})
+}
+
+func TestRegistryStats_not_enabled(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionApp: appMock,
+		}
+
+		a, err := NewRegistryStats(in)
+		require.NoError(t, err)
+
+		a.loadFn = func(app.App) (map[string]*registry.Stats, error) {
+			return nil, nil
+		}
+
+		err = a.Run()
+		require.Error(t, err)
+	})
+}
+
+func TestRegistryStats_requires_app(t *testing.T) {
+	in := make(map[string]interface{})
+	_, err := NewRegistryStats(in)
+	require.Error(t, err)
+}
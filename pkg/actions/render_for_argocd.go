@@ -0,0 +1,128 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/cluster"
+	"github.com/ksonnet/ksonnet/pkg/pipeline"
+	"github.com/pkg/errors"
+)
+
+// RunRenderForArgoCD runs `render-for-argocd`.
+func RunRenderForArgoCD(m map[string]interface{}) error {
+	var o RenderForArgoCDOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunRenderForArgoCDWithOptions(o)
+}
+
+// RunRenderForArgoCDWithOptions runs `render-for-argocd` with typed options.
+func RunRenderForArgoCDWithOptions(o RenderForArgoCDOptions) error {
+	r, err := newRenderForArgoCDWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return r.run()
+}
+
+type renderForArgoCDOpt func(*RenderForArgoCD)
+
+// RenderForArgoCD renders the manifests of an environment for an Argo CD
+// config management plugin. Nothing but manifests is written to out.
+type RenderForArgoCD struct {
+	app     app.App
+	envName string
+
+	objectsFn snapshotObjectsFn
+	out       io.Writer
+}
+
+// RenderForArgoCDOptions are the options for RenderForArgoCD.
+type RenderForArgoCDOptions struct {
+	App     app.App   `option:"app"`
+	EnvName string    `option:"env-name"`
+	Out     io.Writer `option:"out,optional"`
+}
+
+func newRenderForArgoCD(m map[string]interface{}, opts ...renderForArgoCDOpt) (*RenderForArgoCD, error) {
+	var o RenderForArgoCDOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return newRenderForArgoCDWithOptions(o, opts...)
+}
+
+func newRenderForArgoCDWithOptions(o RenderForArgoCDOptions, opts ...renderForArgoCDOpt) (*RenderForArgoCD, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	r := &RenderForArgoCD{
+		app:     o.App,
+		envName: o.EnvName,
+
+		objectsFn: pipelineObjects,
+		out:       os.Stdout,
+	}
+
+	if o.Out != nil {
+		r.out = o.Out
+	}
+
+	if r.envName == "" {
+		return nil, errors.New("an environment is required")
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r, nil
+}
+
+func (r *RenderForArgoCD) run() error {
+	if _, err := r.app.Environment(r.envName); err != nil {
+		return err
+	}
+
+	objects, err := r.objectsFn(r.app, r.envName)
+	if err != nil {
+		return errors.Wrapf(err, "rendering environment %s", r.envName)
+	}
+
+	// components are rendered in no particular order. Sorting the objects
+	// keeps the output stable, so Argo CD does not see spurious changes.
+	cluster.UnstructuredSlice(objects).Sort()
+
+	// the manifests are buffered, so nothing is written if any of them can
+	// not be rendered.
+	var buf bytes.Buffer
+	if err = pipeline.Fprint(&buf, objects, "yaml"); err != nil {
+		return err
+	}
+
+	_, err = buf.WriteTo(r.out)
+	return err
+}
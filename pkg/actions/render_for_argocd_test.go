@@ -0,0 +1,116 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func argoCDObjects(a app.App, envName string) ([]*unstructured.Unstructured, error) {
+	object := func(kind, name string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       kind,
+				"metadata": map[string]interface{}{
+					"name": name,
+				},
+			},
+		}
+	}
+
+	return []*unstructured.Unstructured{
+		object("Service", "guestbook-ui"),
+		object("ConfigMap", "guestbook-ui"),
+	}, nil
+}
+
+func TestRenderForArgoCD(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("Environment", "default").Return(&app.EnvironmentConfig{}, nil)
+
+		var buf bytes.Buffer
+		in := map[string]interface{}{
+			OptionApp:     appMock,
+			OptionEnvName: "default",
+			OptionOut:     &buf,
+		}
+
+		r, err := newRenderForArgoCD(in, func(r *RenderForArgoCD) {
+			r.objectsFn = argoCDObjects
+		})
+		require.NoError(t, err)
+		require.NoError(t, r.run())
+
+		assertOutput(t, "render_for_argocd/output.yaml", buf.String())
+	})
+}
+
+func TestRenderForArgoCD_render_error(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("Environment", "default").Return(&app.EnvironmentConfig{}, nil)
+
+		var buf bytes.Buffer
+		in := map[string]interface{}{
+			OptionApp:     appMock,
+			OptionEnvName: "default",
+			OptionOut:     &buf,
+		}
+
+		r, err := newRenderForArgoCD(in, func(r *RenderForArgoCD) {
+			r.objectsFn = func(a app.App, envName string) ([]*unstructured.Unstructured, error) {
+				return nil, errors.New("failed")
+			}
+		})
+		require.NoError(t, err)
+		require.Error(t, r.run())
+		require.Empty(t, buf.String())
+	})
+}
+
+func TestRenderForArgoCD_invalid_env(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("Environment", "missing").Return(nil, errors.New("not found"))
+
+		in := map[string]interface{}{
+			OptionApp:     appMock,
+			OptionEnvName: "missing",
+		}
+
+		r, err := newRenderForArgoCD(in)
+		require.NoError(t, err)
+		require.Error(t, r.run())
+	})
+}
+
+func TestRenderForArgoCD_requires_env(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionApp:     appMock,
+			OptionEnvName: "",
+		}
+
+		_, err := newRenderForArgoCD(in)
+		require.Error(t, err)
+	})
+}
@@ -28,7 +28,17 @@ type runShowFn func(cluster.ShowConfig, ...cluster.ShowOpts) error
 
 // RunShow runs `show`.
 func RunShow(m map[string]interface{}) error {
-	a, err := newShow(m)
+	var o ShowOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunShowWithOptions(o)
+}
+
+// RunShowWithOptions runs `show` with typed options.
+func RunShowWithOptions(o ShowOptions) error {
+	a, err := newShowWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -40,38 +50,63 @@ type showOpt func(*Show)
 
 // Show shows objects.
 type Show struct {
-	app            app.App
-	clientConfig   *client.Config
-	componentNames []string
-	envName        string
-	format         string
+	app                app.App
+	clientConfig       *client.Config
+	componentNames     []string
+	envName            string
+	format             string
+	showSecrets        bool
+	verifyReproducible bool
 
 	out       io.Writer
 	runShowFn runShowFn
 }
 
-// RunShow runs `show`
+// ShowOptions are the options for Show.
+type ShowOptions struct {
+	App                app.App   `option:"app"`
+	ComponentNames     []string  `option:"component-names"`
+	EnvName            string    `option:"env-name,optional"`
+	Format             string    `option:"format"`
+	ShowSecrets        bool      `option:"show-secrets,optional"`
+	VerifyReproducible bool      `option:"verify-reproducible,optional"`
+	Out                io.Writer `option:"out,optional"`
+}
+
 func newShow(m map[string]interface{}, opts ...showOpt) (*Show, error) {
-	ol := newOptionLoader(m)
+	var o ShowOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return newShowWithOptions(o, opts...)
+}
+
+func newShowWithOptions(o ShowOptions, opts ...showOpt) (*Show, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
 
 	s := &Show{
-		app:            ol.LoadApp(),
-		componentNames: ol.LoadStringSlice(OptionComponentNames),
-		format:         ol.LoadString(OptionFormat),
+		app:                o.App,
+		componentNames:     o.ComponentNames,
+		format:             o.Format,
+		showSecrets:        o.ShowSecrets,
+		verifyReproducible: o.VerifyReproducible,
 
 		out:       os.Stdout,
 		runShowFn: cluster.RunShow,
 	}
 
-	if ol.err != nil {
-		return nil, ol.err
+	if o.Out != nil {
+		s.out = o.Out
 	}
 
 	for _, opt := range opts {
 		opt(s)
 	}
 
-	if err := setCurrentEnv(s.app, s, ol); err != nil {
+	if err := setCurrentEnv(s.app, s, o.EnvName); err != nil {
 		return nil, err
 	}
 
@@ -80,11 +115,13 @@ func newShow(m map[string]interface{}, opts ...showOpt) (*Show, error) {
 
 func (s *Show) run() error {
 	config := cluster.ShowConfig{
-		App:            s.app,
-		ComponentNames: s.componentNames,
-		EnvName:        s.envName,
-		Format:         s.format,
-		Out:            s.out,
+		App:                s.app,
+		ComponentNames:     s.componentNames,
+		EnvName:            s.envName,
+		Format:             s.format,
+		ShowSecrets:        s.showSecrets,
+		VerifyReproducible: s.verifyReproducible,
+		Out:                s.out,
 	}
 
 	return s.runShowFn(config)
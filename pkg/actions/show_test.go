@@ -16,6 +16,7 @@
 package actions
 
 import (
+	"bytes"
 	"os"
 	"testing"
 
@@ -52,18 +53,22 @@ func TestShow(t *testing.T) {
 				appMock.On("CurrentEnvironment").Return(tc.currentName)
 
 				in := map[string]interface{}{
-					OptionApp:            appMock,
-					OptionComponentNames: []string{},
-					OptionEnvName:        tc.envName,
-					OptionFormat:         "yaml",
+					OptionApp:                appMock,
+					OptionComponentNames:     []string{},
+					OptionEnvName:            tc.envName,
+					OptionFormat:             "yaml",
+					OptionShowSecrets:        true,
+					OptionVerifyReproducible: true,
 				}
 
 				expected := cluster.ShowConfig{
-					App:            appMock,
-					ComponentNames: []string{},
-					EnvName:        "default",
-					Format:         "yaml",
-					Out:            os.Stdout,
+					App:                appMock,
+					ComponentNames:     []string{},
+					EnvName:            "default",
+					Format:             "yaml",
+					ShowSecrets:        true,
+					VerifyReproducible: true,
+					Out:                os.Stdout,
 				}
 
 				runShowOpt := func(a *Show) {
@@ -87,6 +92,29 @@ func TestShow(t *testing.T) {
 	}
 }
 
+func TestShow_out(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		var buf bytes.Buffer
+
+		in := map[string]interface{}{
+			OptionApp:            appMock,
+			OptionComponentNames: []string{},
+			OptionEnvName:        "default",
+			OptionFormat:         "yaml",
+			OptionOut:            &buf,
+		}
+
+		a, err := newShow(in, func(a *Show) {
+			a.runShowFn = func(config cluster.ShowConfig, opts ...cluster.ShowOpts) error {
+				assert.Equal(t, &buf, config.Out)
+				return nil
+			}
+		})
+		require.NoError(t, err)
+		require.NoError(t, a.run())
+	})
+}
+
 func TestShow_invalid_input(t *testing.T) {
 	withApp(t, func(appMock *amocks.App) {
 		in := map[string]interface{}{
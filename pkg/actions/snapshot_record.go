@@ -0,0 +1,118 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/pipeline"
+	"github.com/ksonnet/ksonnet/pkg/snapshot"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type snapshotObjectsFn func(a app.App, envName string) ([]*unstructured.Unstructured, error)
+
+// pipelineObjects renders the objects of an environment.
+func pipelineObjects(a app.App, envName string) ([]*unstructured.Unstructured, error) {
+	return pipeline.New(a, envName).Objects(nil)
+}
+
+// RunSnapshotRecord runs `snapshot record`.
+func RunSnapshotRecord(m map[string]interface{}) error {
+	var o SnapshotRecordOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunSnapshotRecordWithOptions(o)
+}
+
+// RunSnapshotRecordWithOptions runs `snapshot record` with typed options.
+func RunSnapshotRecordWithOptions(o SnapshotRecordOptions) error {
+	sr, err := newSnapshotRecordWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return sr.run()
+}
+
+type snapshotRecordOpt func(*SnapshotRecord)
+
+// SnapshotRecord records the manifests of an environment.
+type SnapshotRecord struct {
+	app     app.App
+	envName string
+
+	objectsFn snapshotObjectsFn
+}
+
+// SnapshotRecordOptions are the options for SnapshotRecord.
+type SnapshotRecordOptions struct {
+	App     app.App `option:"app"`
+	EnvName string  `option:"env-name,optional"`
+}
+
+func newSnapshotRecord(m map[string]interface{}, opts ...snapshotRecordOpt) (*SnapshotRecord, error) {
+	var o SnapshotRecordOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return newSnapshotRecordWithOptions(o, opts...)
+}
+
+func newSnapshotRecordWithOptions(o SnapshotRecordOptions, opts ...snapshotRecordOpt) (*SnapshotRecord, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	sr := &SnapshotRecord{
+		app: o.App,
+
+		objectsFn: pipelineObjects,
+	}
+
+	for _, opt := range opts {
+		opt(sr)
+	}
+
+	if err := setCurrentEnv(sr.app, sr, o.EnvName); err != nil {
+		return nil, err
+	}
+
+	return sr, nil
+}
+
+func (sr *SnapshotRecord) run() error {
+	objects, err := sr.objectsFn(sr.app, sr.envName)
+	if err != nil {
+		return err
+	}
+
+	s := snapshot.New(sr.app.Fs(), sr.app.Root(), sr.envName)
+	written, err := s.Record(objects)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("recorded %d manifest(s) of %s", len(written), sr.envName)
+	return nil
+}
+
+func (sr *SnapshotRecord) setCurrentEnv(name string) {
+	sr.envName = name
+}
@@ -0,0 +1,119 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func snapshotObjects(replicas int64) snapshotObjectsFn {
+	return func(a app.App, envName string) ([]*unstructured.Unstructured, error) {
+		o := &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Service",
+				"metadata": map[string]interface{}{
+					"name": "guestbook-ui",
+					"labels": map[string]interface{}{
+						"ksonnet.io/component": "guestbook-ui",
+					},
+				},
+				"spec": map[string]interface{}{
+					"replicas": replicas,
+				},
+			},
+		}
+		return []*unstructured.Unstructured{o}, nil
+	}
+}
+
+func TestSnapshotRecord_and_verify(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionApp:     appMock,
+			OptionEnvName: "default",
+		}
+
+		sr, err := newSnapshotRecord(in, func(sr *SnapshotRecord) {
+			sr.objectsFn = snapshotObjects(1)
+		})
+		require.NoError(t, err)
+		require.NoError(t, sr.run())
+
+		exists, err := afero.Exists(appMock.Fs(), "/snapshots/default/guestbook-ui/service-guestbook-ui.yaml")
+		require.NoError(t, err)
+		assert.True(t, exists)
+
+		var buf bytes.Buffer
+		sv, err := newSnapshotVerify(in, func(sv *SnapshotVerify) {
+			sv.out = &buf
+			sv.objectsFn = snapshotObjects(1)
+		})
+		require.NoError(t, err)
+		require.NoError(t, sv.run())
+		assert.Empty(t, buf.String())
+
+		sv, err = newSnapshotVerify(in, func(sv *SnapshotVerify) {
+			sv.out = &buf
+			sv.objectsFn = snapshotObjects(2)
+		})
+		require.NoError(t, err)
+		require.Error(t, sv.run())
+		assert.Contains(t, buf.String(), "changed: snapshots/default/guestbook-ui/service-guestbook-ui.yaml")
+	})
+}
+
+func TestSnapshotVerify_objects_error(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		in := map[string]interface{}{
+			OptionApp:     appMock,
+			OptionEnvName: "default",
+		}
+
+		sv, err := newSnapshotVerify(in, func(sv *SnapshotVerify) {
+			sv.objectsFn = func(a app.App, envName string) ([]*unstructured.Unstructured, error) {
+				return nil, errors.New("failed")
+			}
+		})
+		require.NoError(t, err)
+		require.Error(t, sv.run())
+	})
+}
+
+func TestSnapshot_requires_env(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("CurrentEnvironment").Return("")
+
+		in := map[string]interface{}{
+			OptionApp: appMock,
+		}
+
+		_, err := newSnapshotRecord(in)
+		require.Error(t, err)
+
+		_, err = newSnapshotVerify(in)
+		require.Error(t, err)
+	})
+}
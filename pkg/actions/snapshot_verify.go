@@ -0,0 +1,117 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"io"
+	"os"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/snapshot"
+)
+
+// RunSnapshotVerify runs `snapshot verify`.
+func RunSnapshotVerify(m map[string]interface{}) error {
+	var o SnapshotVerifyOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunSnapshotVerifyWithOptions(o)
+}
+
+// RunSnapshotVerifyWithOptions runs `snapshot verify` with typed options.
+func RunSnapshotVerifyWithOptions(o SnapshotVerifyOptions) error {
+	sv, err := newSnapshotVerifyWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return sv.run()
+}
+
+type snapshotVerifyOpt func(*SnapshotVerify)
+
+// SnapshotVerify verifies the manifests of an environment match its
+// snapshot.
+type SnapshotVerify struct {
+	app     app.App
+	envName string
+
+	out       io.Writer
+	objectsFn snapshotObjectsFn
+}
+
+// SnapshotVerifyOptions are the options for SnapshotVerify.
+type SnapshotVerifyOptions struct {
+	App     app.App `option:"app"`
+	EnvName string  `option:"env-name,optional"`
+}
+
+func newSnapshotVerify(m map[string]interface{}, opts ...snapshotVerifyOpt) (*SnapshotVerify, error) {
+	var o SnapshotVerifyOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return newSnapshotVerifyWithOptions(o, opts...)
+}
+
+func newSnapshotVerifyWithOptions(o SnapshotVerifyOptions, opts ...snapshotVerifyOpt) (*SnapshotVerify, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	sv := &SnapshotVerify{
+		app: o.App,
+
+		out:       os.Stdout,
+		objectsFn: pipelineObjects,
+	}
+
+	for _, opt := range opts {
+		opt(sv)
+	}
+
+	if err := setCurrentEnv(sv.app, sv, o.EnvName); err != nil {
+		return nil, err
+	}
+
+	return sv, nil
+}
+
+func (sv *SnapshotVerify) run() error {
+	objects, err := sv.objectsFn(sv.app, sv.envName)
+	if err != nil {
+		return err
+	}
+
+	s := snapshot.New(sv.app.Fs(), sv.app.Root(), sv.envName)
+	result, err := s.Verify(objects)
+	if err != nil {
+		return err
+	}
+
+	if err = result.Render(sv.out); err != nil {
+		return err
+	}
+
+	return result.Err()
+}
+
+func (sv *SnapshotVerify) setCurrentEnv(name string) {
+	sv.envName = name
+}
@@ -0,0 +1,198 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/cluster"
+	"github.com/ksonnet/ksonnet/pkg/util/table"
+	"github.com/pkg/errors"
+)
+
+type runStatusFn func(cluster.StatusConfig, ...cluster.StatusOpts) ([]cluster.ResourceStatus, error)
+
+// RunStatus runs `status`.
+func RunStatus(m map[string]interface{}) error {
+	var o StatusOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunStatusWithOptions(o)
+}
+
+// RunStatusWithOptions runs `status` with typed options.
+func RunStatusWithOptions(o StatusOptions) error {
+	s, err := newStatusWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return s.run()
+}
+
+type statusOpt func(*Status)
+
+// Status reports the live status of the resources an environment manages.
+type Status struct {
+	app            app.App
+	clientConfig   *client.Config
+	componentNames []string
+	envName        string
+	maxUnavailable int
+	output         string
+
+	fanOutFn    fanOutFn
+	runStatusFn runStatusFn
+	out         io.Writer
+}
+
+// StatusOptions are the options for Status.
+type StatusOptions struct {
+	App            app.App        `option:"app"`
+	ClientConfig   *client.Config `option:"client-config"`
+	ComponentNames []string       `option:"component-names"`
+	EnvName        string         `option:"env-name,optional"`
+	MaxUnavailable int            `option:"max-unavailable-clusters,optional"`
+	Output         string         `option:"output,optional"`
+	Out            io.Writer      `option:"out,optional"`
+}
+
+func newStatus(m map[string]interface{}, opts ...statusOpt) (*Status, error) {
+	var o StatusOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return newStatusWithOptions(o, opts...)
+}
+
+func newStatusWithOptions(o StatusOptions, opts ...statusOpt) (*Status, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	s := &Status{
+		app:            o.App,
+		clientConfig:   o.ClientConfig,
+		componentNames: o.ComponentNames,
+		maxUnavailable: o.MaxUnavailable,
+		output:         o.Output,
+
+		fanOutFn:    cluster.FanOut,
+		runStatusFn: cluster.RunStatus,
+		out:         os.Stdout,
+	}
+
+	if o.Out != nil {
+		s.out = o.Out
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if err := setCurrentEnv(s.app, s, o.EnvName); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *Status) run() error {
+	env, err := s.app.Environment(s.envName)
+	if err != nil {
+		return err
+	}
+	multiCluster := len(env.Destinations) > 0
+
+	config := cluster.StatusConfig{
+		App:            s.app,
+		ClientConfig:   s.clientConfig,
+		ComponentNames: s.componentNames,
+		EnvName:        s.envName,
+	}
+
+	fanOutConfig := cluster.FanOutConfig{
+		App:            s.app,
+		ClientConfig:   s.clientConfig,
+		EnvName:        s.envName,
+		MaxUnavailable: s.maxUnavailable,
+		Out:            os.Stderr,
+	}
+
+	var rows [][]string
+	err = s.fanOutFn(fanOutConfig, func(destination string, clientConfig *client.Config) error {
+		config.ClientConfig = clientConfig
+
+		statuses, err := s.runStatusFn(config)
+		if err != nil {
+			return err
+		}
+
+		for _, status := range statuses {
+			name := status.Name
+			if status.Namespace != "" {
+				name = status.Namespace + "/" + status.Name
+			}
+
+			row := []string{
+				status.Component,
+				status.Kind,
+				name,
+				strconv.FormatBool(status.Exists),
+				strconv.FormatBool(status.Ready),
+				strconv.FormatBool(status.Drifted),
+				status.Message,
+			}
+			if multiCluster {
+				row = append([]string{destination}, row...)
+			}
+
+			rows = append(rows, row)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	f, err := table.DetectFormat(s.output)
+	if err != nil {
+		return errors.Wrap(err, "detecting output format")
+	}
+
+	header := []string{"component", "kind", "name", "exists", "ready", "drifted", "message"}
+	if multiCluster {
+		header = append([]string{"cluster"}, header...)
+	}
+
+	t := table.New("status", s.out)
+	t.SetFormat(f)
+	t.SetHeader(header)
+	t.AppendBulk(rows)
+	return t.Render()
+}
+
+func (s *Status) setCurrentEnv(name string) {
+	s.envName = name
+}
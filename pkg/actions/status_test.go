@@ -0,0 +1,100 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/cluster"
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatus(t *testing.T) {
+	cases := []struct {
+		name         string
+		output       string
+		destinations []*app.EnvironmentDestinationSpec
+		expected     string
+	}{
+		{
+			name:     "table",
+			expected: "status/table.txt",
+		},
+		{
+			name:     "json",
+			output:   "json",
+			expected: "status/json.txt",
+		},
+		{
+			name: "multiple clusters",
+			destinations: []*app.EnvironmentDestinationSpec{
+				{Name: "us-east", Server: "http://us-east.example.com", Namespace: "default"},
+				{Name: "us-west", Server: "http://us-west.example.com", Namespace: "default"},
+			},
+			expected: "status/multi.txt",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				appMock.On("Environment", "default").Return(&app.EnvironmentConfig{
+					Destination:  &app.EnvironmentDestinationSpec{Server: "http://example.com", Namespace: "default"},
+					Destinations: tc.destinations,
+				}, nil)
+
+				in := map[string]interface{}{
+					OptionApp:            appMock,
+					OptionClientConfig:   &client.Config{},
+					OptionComponentNames: []string{},
+					OptionEnvName:        "default",
+					OptionOutput:         tc.output,
+				}
+
+				var buf bytes.Buffer
+				runStatusOpt := func(s *Status) {
+					s.out = &buf
+					s.runStatusFn = func(config cluster.StatusConfig, opts ...cluster.StatusOpts) ([]cluster.ResourceStatus, error) {
+						assert.Equal(t, "default", config.EnvName)
+						return []cluster.ResourceStatus{
+							{Component: "guestbook", Kind: "Service", Namespace: "default", Name: "guestbook", Exists: true, Ready: true},
+							{Component: "guestbook", Kind: "Deployment", Namespace: "default", Name: "guestbook", Exists: true, Drifted: true, Message: "not ready"},
+							{Component: "redis", Kind: "Deployment", Name: "redis", Message: "not found"},
+						}, nil
+					}
+				}
+
+				s, err := newStatus(in, runStatusOpt)
+				require.NoError(t, err)
+
+				require.NoError(t, s.run())
+				test.AssertOutput(t, tc.expected, buf.String())
+			})
+		})
+	}
+}
+
+func TestStatus_requires_app(t *testing.T) {
+	in := make(map[string]interface{})
+	_, err := newStatus(in)
+	require.Error(t, err)
+}
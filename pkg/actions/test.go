@@ -0,0 +1,160 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"io"
+	"os"
+	"sort"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/apptest"
+	"github.com/pkg/errors"
+)
+
+type testFn func(a app.App, envNames []string, update bool) (*apptest.Report, error)
+
+// RunTest runs `test`.
+func RunTest(m map[string]interface{}) error {
+	var o TestOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunTestWithOptions(o)
+}
+
+// RunTestWithOptions runs `test` with typed options.
+func RunTestWithOptions(o TestOptions) error {
+	t, err := newTestWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return t.run()
+}
+
+type testOpt func(*Test)
+
+// Test runs the tests of an app's components.
+type Test struct {
+	app       app.App
+	envName   string
+	update    bool
+	junitPath string
+
+	out    io.Writer
+	testFn testFn
+}
+
+// TestOptions are the options for Test.
+type TestOptions struct {
+	App app.App `option:"app"`
+	// EnvName selects the environment to test. If it is empty, every
+	// environment is tested.
+	EnvName string `option:"env-name,optional"`
+	// Update rewrites the snapshots instead of comparing them.
+	Update    bool   `option:"update,optional"`
+	JUnitPath string `option:"junit,optional"`
+}
+
+func newTest(m map[string]interface{}, opts ...testOpt) (*Test, error) {
+	var o TestOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return newTestWithOptions(o, opts...)
+}
+
+func newTestWithOptions(o TestOptions, opts ...testOpt) (*Test, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	t := &Test{
+		app:       o.App,
+		envName:   o.EnvName,
+		update:    o.Update,
+		junitPath: o.JUnitPath,
+
+		out: os.Stdout,
+		testFn: func(a app.App, envNames []string, update bool) (*apptest.Report, error) {
+			renderer := apptest.NewPipelineRenderer(a)
+			return apptest.NewRunner(a.Fs(), a.Root(), envNames, renderer, update).Run()
+		},
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t, nil
+}
+
+func (t *Test) run() error {
+	envNames, err := t.envNames()
+	if err != nil {
+		return err
+	}
+
+	report, err := t.testFn(t.app, envNames, t.update)
+	if err != nil {
+		return err
+	}
+
+	if err = report.Render(t.out); err != nil {
+		return err
+	}
+
+	if t.junitPath != "" {
+		f, err := t.app.Fs().Create(t.junitPath)
+		if err != nil {
+			return errors.Wrap(err, "creating JUnit report")
+		}
+		defer f.Close()
+
+		if err = report.WriteJUnit(f); err != nil {
+			return errors.Wrap(err, "writing JUnit report")
+		}
+	}
+
+	return report.Err()
+}
+
+// envNames returns the environments to run tests in. Tests run in every
+// environment unless one is given.
+func (t *Test) envNames() ([]string, error) {
+	if t.envName != "" {
+		if _, err := t.app.Environment(t.envName); err != nil {
+			return nil, err
+		}
+		return []string{t.envName}, nil
+	}
+
+	envs, err := t.app.Environments()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name := range envs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
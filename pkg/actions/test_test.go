@@ -0,0 +1,137 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/apptest"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTest(t *testing.T) {
+	cases := []struct {
+		name             string
+		envName          string
+		failure          string
+		junitPath        string
+		expectedEnvNames []string
+		isErr            bool
+	}{
+		{
+			name:             "all environments",
+			expectedEnvNames: []string{"default", "prod"},
+		},
+		{
+			name:             "environment",
+			envName:          "prod",
+			expectedEnvNames: []string{"prod"},
+		},
+		{
+			name:             "failure",
+			failure:          "Service guestbook-ui was not rendered",
+			junitPath:        "/report.xml",
+			expectedEnvNames: []string{"default", "prod"},
+			isErr:            true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				envs := app.EnvironmentConfigs{
+					"prod":    &app.EnvironmentConfig{Name: "prod"},
+					"default": &app.EnvironmentConfig{Name: "default"},
+				}
+				appMock.On("Environments").Return(envs, nil)
+				appMock.On("Environment", "prod").Return(envs["prod"], nil)
+
+				in := map[string]interface{}{
+					OptionApp:     appMock,
+					OptionEnvName: tc.envName,
+					OptionUpdate:  true,
+					OptionJUnit:   tc.junitPath,
+				}
+
+				var buf bytes.Buffer
+				testOpt := func(a *Test) {
+					a.out = &buf
+					a.testFn = func(a app.App, envNames []string, update bool) (*apptest.Report, error) {
+						assert.Equal(t, tc.expectedEnvNames, envNames)
+						assert.True(t, update)
+
+						report := &apptest.Report{
+							Suites: []apptest.SuiteResult{
+								{
+									File:        "tests/guestbook_test.jsonnet",
+									Environment: "default",
+									Cases:       []apptest.CaseResult{{Name: "service", Failure: tc.failure}},
+								},
+							},
+						}
+						return report, nil
+					}
+				}
+
+				a, err := newTest(in, testOpt)
+				require.NoError(t, err)
+
+				err = a.run()
+				if tc.isErr {
+					require.Error(t, err)
+				} else {
+					require.NoError(t, err)
+				}
+
+				assert.Contains(t, buf.String(), "tests/guestbook_test.jsonnet [default] service")
+
+				if tc.junitPath != "" {
+					b, err := afero.ReadFile(appMock.Fs(), tc.junitPath)
+					require.NoError(t, err)
+					assert.Contains(t, string(b), "<testsuites>")
+				}
+			})
+		})
+	}
+}
+
+func TestTest_unknown_environment(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("Environment", "missing").Return(nil, errors.New("not found"))
+
+		in := map[string]interface{}{
+			OptionApp:     appMock,
+			OptionEnvName: "missing",
+		}
+
+		a, err := newTest(in)
+		require.NoError(t, err)
+
+		require.Error(t, a.run())
+	})
+}
+
+func TestTest_requires_app(t *testing.T) {
+	in := make(map[string]interface{})
+	_, err := newTest(in)
+	require.Error(t, err)
+}
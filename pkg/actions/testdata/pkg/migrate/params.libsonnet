@@ -0,0 +1,13 @@
+{
+  global: {},
+  components: {
+    web: {
+      name: "web",
+      port: "80",
+      debug: true,
+    },
+    other: {
+      port: 8080,
+    },
+  },
+}
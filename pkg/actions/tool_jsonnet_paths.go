@@ -0,0 +1,276 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/env"
+	"github.com/ksonnet/ksonnet/pkg/registry"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+const (
+	// vscodeLibPathsKey is the settings key for library paths read by the
+	// jsonnet VS Code extension.
+	vscodeLibPathsKey = "jsonnet.libPaths"
+	// vscodeJPathKey is the settings key for library paths read by
+	// jsonnet-language-server.
+	vscodeJPathKey = "jsonnet.languageServer.jpath"
+)
+
+// jsonnetPackagesPath is the directory `tool jsonnet-paths` vendors the
+// versioned packages of an environment to. Language servers can't follow
+// the temporary directory used during evaluation.
+func jsonnetPackagesPath(a app.App, envName string) string {
+	return filepath.Join(a.Root(), ".ksonnet", "packages", envName)
+}
+
+// vscodeSettingsPath is the path of the VS Code workspace settings of an app.
+func vscodeSettingsPath(a app.App) string {
+	return filepath.Join(a.Root(), ".vscode", "settings.json")
+}
+
+type packageVendorer func(a app.App, pm registry.PackageManager, envName, dir string) error
+
+// RunToolJsonnetPaths runs `tool jsonnet-paths`.
+func RunToolJsonnetPaths(m map[string]interface{}) error {
+	var o ToolJsonnetPathsOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunToolJsonnetPathsWithOptions(o)
+}
+
+// RunToolJsonnetPathsWithOptions runs `tool jsonnet-paths` with typed options.
+func RunToolJsonnetPathsWithOptions(o ToolJsonnetPathsOptions) error {
+	tjp, err := newToolJsonnetPathsWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return tjp.run()
+}
+
+type toolJsonnetPathsOpt func(*ToolJsonnetPaths)
+
+// ToolJsonnetPaths writes the library paths of an environment in formats read
+// by jsonnet language servers, so editors resolve imports the way ksonnet
+// does.
+type ToolJsonnetPaths struct {
+	app     app.App
+	envName string
+	format  string
+	write   bool
+	out     io.Writer
+
+	packageManager   registry.PackageManager
+	vendorPackagesFn packageVendorer
+}
+
+// ToolJsonnetPathsOptions are the options for ToolJsonnetPaths.
+type ToolJsonnetPathsOptions struct {
+	App     app.App `option:"app"`
+	EnvName string  `option:"env-name,optional"`
+	Format  string  `option:"format,optional"`
+	// Write updates the app's VS Code settings instead of printing the paths.
+	Write         bool      `option:"write,optional"`
+	Out           io.Writer `option:"out,optional"`
+	TLSSkipVerify bool      `option:"tls-skip-verify,optional"`
+}
+
+func newToolJsonnetPaths(m map[string]interface{}, opts ...toolJsonnetPathsOpt) (*ToolJsonnetPaths, error) {
+	var o ToolJsonnetPathsOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return newToolJsonnetPathsWithOptions(o, opts...)
+}
+
+func newToolJsonnetPathsWithOptions(o ToolJsonnetPathsOptions, opts ...toolJsonnetPathsOpt) (*ToolJsonnetPaths, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	httpClientOpt := registry.HTTPClientOpt(newHTTPClient(o.TLSSkipVerify))
+
+	tjp := &ToolJsonnetPaths{
+		app:    o.App,
+		format: o.Format,
+		write:  o.Write,
+		out:    os.Stdout,
+
+		packageManager:   registry.NewPackageManager(o.App, httpClientOpt),
+		vendorPackagesFn: env.VendorPackages,
+	}
+
+	if o.Out != nil {
+		tjp.out = o.Out
+	}
+
+	for _, opt := range opts {
+		opt(tjp)
+	}
+
+	if err := setCurrentEnv(tjp.app, tjp, o.EnvName); err != nil {
+		return nil, err
+	}
+
+	return tjp, nil
+}
+
+func (tjp *ToolJsonnetPaths) setCurrentEnv(name string) {
+	tjp.envName = name
+}
+
+func (tjp *ToolJsonnetPaths) run() error {
+	dir := jsonnetPackagesPath(tjp.app, tjp.envName)
+	if err := tjp.vendorPackagesFn(tjp.app, tjp.packageManager, tjp.envName, dir); err != nil {
+		return errors.Wrapf(err, "vendoring packages for environment %q", tjp.envName)
+	}
+
+	paths, err := env.JPaths(tjp.app, tjp.envName, dir)
+	if err != nil {
+		return err
+	}
+
+	if tjp.write {
+		return writeVSCodeSettings(tjp.app, paths)
+	}
+
+	switch tjp.format {
+	case "", "text":
+		for _, path := range paths {
+			if _, err := fmt.Fprintln(tjp.out, path); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "json":
+		return writeIndentedJSON(tjp.out, paths)
+	case "env":
+		_, err := fmt.Fprintf(tjp.out, "JSONNET_PATH=%s\n", jsonnetPathEnv(paths))
+		return err
+	case "vscode":
+		return writeIndentedJSON(tjp.out, map[string]interface{}{
+			vscodeLibPathsKey: paths,
+			vscodeJPathKey:    paths,
+		})
+	default:
+		return errors.Errorf("unknown format %q; valid formats are text, json, env, and vscode", tjp.format)
+	}
+}
+
+// jsonnetPathEnv returns paths as a JSONNET_PATH value. The first entry of
+// JSONNET_PATH takes precedence, so paths are reversed.
+func jsonnetPathEnv(paths []string) string {
+	reversed := make([]string, len(paths))
+	for i := range paths {
+		reversed[len(paths)-1-i] = paths[i]
+	}
+
+	return strings.Join(reversed, string(os.PathListSeparator))
+}
+
+func writeIndentedJSON(w io.Writer, v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
+
+// writeVSCodeSettings sets the library paths in the app's VS Code settings.
+// Other settings are kept.
+func writeVSCodeSettings(a app.App, paths []string) error {
+	fs := a.Fs()
+	path := vscodeSettingsPath(a)
+
+	settings := make(map[string]interface{})
+
+	b, err := afero.ReadFile(fs, path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(b, &settings); err != nil {
+			return errors.Wrapf(err, "reading %s", path)
+		}
+	case os.IsNotExist(err):
+	default:
+		return err
+	}
+
+	settings[vscodeLibPathsKey] = paths
+	settings[vscodeJPathKey] = paths
+
+	if b, err = json.MarshalIndent(settings, "", "  "); err != nil {
+		return err
+	}
+
+	if err := fs.MkdirAll(filepath.Dir(path), app.DefaultFolderPermissions); err != nil {
+		return err
+	}
+
+	if err := afero.WriteFile(fs, path, append(b, '\n'), app.DefaultFilePermissions); err != nil {
+		return err
+	}
+
+	log.Infof("Updated %s", path)
+	return nil
+}
+
+// refreshJsonnetPackages re-vendors the packages of environments previously
+// written by `tool jsonnet-paths`, so language servers see package changes.
+func refreshJsonnetPackages(a app.App, pm registry.PackageManager, vendorPackagesFn packageVendorer) error {
+	exists, err := afero.DirExists(a.Fs(), jsonnetPackagesPath(a, ""))
+	if err != nil || !exists {
+		return err
+	}
+
+	envs, err := a.Environments()
+	if err != nil {
+		return err
+	}
+
+	for envName := range envs {
+		dir := jsonnetPackagesPath(a, envName)
+
+		exists, err := afero.DirExists(a.Fs(), dir)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+
+		if err := vendorPackagesFn(a, pm, envName, dir); err != nil {
+			return errors.Wrapf(err, "vendoring packages for environment %q", envName)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/registry"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withJsonnetPathsApp(t *testing.T, fn func(*amocks.App)) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("CurrentEnvironment").Return("default")
+		appMock.On("LibPath", "default").Return("/lib/v1.10.0", nil)
+		appMock.On("Environment", "default").Return(&app.EnvironmentConfig{Path: "default"}, nil)
+
+		fn(appMock)
+	})
+}
+
+func TestToolJsonnetPaths(t *testing.T) {
+	cases := []struct {
+		name     string
+		format   string
+		expected string
+		isErr    bool
+	}{
+		{
+			name:     "text",
+			expected: "tool/jsonnet-paths/text.txt",
+		},
+		{
+			name:     "json",
+			format:   "json",
+			expected: "tool/jsonnet-paths/json.json",
+		},
+		{
+			name:     "env",
+			format:   "env",
+			expected: "tool/jsonnet-paths/env.txt",
+		},
+		{
+			name:     "vscode",
+			format:   "vscode",
+			expected: "tool/jsonnet-paths/vscode.json",
+		},
+		{
+			name:   "invalid format",
+			format: "toml",
+			isErr:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withJsonnetPathsApp(t, func(appMock *amocks.App) {
+				in := map[string]interface{}{
+					OptionApp:    appMock,
+					OptionFormat: tc.format,
+				}
+
+				var vendoredDir string
+				var buf bytes.Buffer
+				tjp, err := newToolJsonnetPaths(in, func(tjp *ToolJsonnetPaths) {
+					tjp.out = &buf
+					tjp.vendorPackagesFn = func(a app.App, pm registry.PackageManager, envName, dir string) error {
+						vendoredDir = dir
+						return nil
+					}
+				})
+				require.NoError(t, err)
+
+				err = tjp.run()
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+
+				assert.Equal(t, filepath.FromSlash("/.ksonnet/packages/default"), vendoredDir)
+				assertOutput(t, tc.expected, buf.String())
+			})
+		})
+	}
+}
+
+func TestToolJsonnetPaths_write(t *testing.T) {
+	withJsonnetPathsApp(t, func(appMock *amocks.App) {
+		stageFile(t, appMock.Fs(), "tool/jsonnet-paths/settings.json", "/.vscode/settings.json")
+
+		in := map[string]interface{}{
+			OptionApp:   appMock,
+			OptionWrite: true,
+		}
+
+		var buf bytes.Buffer
+		tjp, err := newToolJsonnetPaths(in, func(tjp *ToolJsonnetPaths) {
+			tjp.out = &buf
+			tjp.vendorPackagesFn = func(a app.App, pm registry.PackageManager, envName, dir string) error {
+				return nil
+			}
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, tjp.run())
+		assert.Empty(t, buf.String())
+
+		b, err := afero.ReadFile(appMock.Fs(), "/.vscode/settings.json")
+		require.NoError(t, err)
+		assertOutput(t, "tool/jsonnet-paths/settings-written.json", string(b))
+	})
+}
+
+func TestToolJsonnetPaths_requires_app(t *testing.T) {
+	_, err := newToolJsonnetPaths(map[string]interface{}{})
+	require.Error(t, err)
+}
+
+func Test_refreshJsonnetPackages(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("Environments").Return(app.EnvironmentConfigs{
+			"default": &app.EnvironmentConfig{},
+			"prod":    &app.EnvironmentConfig{},
+		}, nil)
+
+		var refreshed []string
+		vendorFn := func(a app.App, pm registry.PackageManager, envName, dir string) error {
+			refreshed = append(refreshed, envName)
+			return nil
+		}
+
+		// Nothing was written by `tool jsonnet-paths` yet.
+		require.NoError(t, refreshJsonnetPackages(appMock, nil, vendorFn))
+		assert.Empty(t, refreshed)
+
+		require.NoError(t, appMock.Fs().MkdirAll("/.ksonnet/packages/prod", app.DefaultFolderPermissions))
+
+		require.NoError(t, refreshJsonnetPackages(appMock, nil, vendorFn))
+		assert.Equal(t, []string{"prod"}, refreshed)
+	})
+}
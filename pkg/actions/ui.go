@@ -0,0 +1,280 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/util/event"
+	"github.com/pkg/errors"
+)
+
+const uiHelp = `Commands:
+  envs                            List environments
+  env <name>                      Select an environment
+  components                      List components
+  params [component]              List parameters in the selected environment
+  set <component> <path> <value>  Set a parameter in the selected environment
+  diff                            Compare the selected environment with its cluster
+  status                          Show the live status of the selected environment
+  help                            Show this help
+  quit                            Exit
+`
+
+type uiActionFn func(map[string]interface{}) error
+
+// RunUI runs `ui`.
+func RunUI(m map[string]interface{}) error {
+	var o UIOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunUIWithOptions(o)
+}
+
+// RunUIWithOptions runs `ui` with typed options.
+func RunUIWithOptions(o UIOptions) error {
+	u, err := newUIWithOptions(o)
+	if err != nil {
+		return err
+	}
+
+	return u.run()
+}
+
+type uiOpt func(*UI)
+
+// UI is an interactive shell for browsing an app's components, parameters,
+// and environments.
+type UI struct {
+	app          app.App
+	clientConfig *client.Config
+	envName      string
+
+	in  io.Reader
+	out io.Writer
+
+	componentListFn uiActionFn
+	diffFn          uiActionFn
+	envListFn       uiActionFn
+	paramListFn     uiActionFn
+	paramSetFn      uiActionFn
+	statusFn        uiActionFn
+}
+
+// UIOptions are the options for UI.
+type UIOptions struct {
+	App          app.App        `option:"app"`
+	ClientConfig *client.Config `option:"client-config"`
+	// EnvName defaults to the app's current environment.
+	EnvName string `option:"env-name,optional"`
+}
+
+func newUI(m map[string]interface{}, opts ...uiOpt) (*UI, error) {
+	var o UIOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return newUIWithOptions(o, opts...)
+}
+
+func newUIWithOptions(o UIOptions, opts ...uiOpt) (*UI, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
+
+	u := &UI{
+		app:          o.App,
+		clientConfig: o.ClientConfig,
+		envName:      o.EnvName,
+
+		in:  os.Stdin,
+		out: os.Stdout,
+
+		componentListFn: RunComponentList,
+		diffFn:          RunDiff,
+		envListFn:       RunEnvList,
+		paramListFn:     RunParamList,
+		paramSetFn:      RunParamSet,
+		statusFn:        RunStatus,
+	}
+
+	for _, opt := range opts {
+		opt(u)
+	}
+
+	if u.envName == "" {
+		u.envName = u.app.CurrentEnvironment()
+	}
+
+	return u, nil
+}
+
+func (u *UI) run() error {
+	fmt.Fprintln(u.out, "Type help for a list of commands.")
+
+	scanner := bufio.NewScanner(u.in)
+	for {
+		fmt.Fprint(u.out, u.prompt())
+		if !scanner.Scan() {
+			fmt.Fprintln(u.out)
+			return scanner.Err()
+		}
+
+		args := strings.Fields(scanner.Text())
+		if len(args) == 0 {
+			continue
+		}
+
+		if args[0] == "quit" || args[0] == "exit" {
+			return nil
+		}
+
+		stopEvents := event.Subscribe(u.renderEvent)
+		err := u.exec(args)
+		stopEvents()
+		if err != nil {
+			fmt.Fprintf(u.out, "error: %v\n", err)
+		}
+	}
+}
+
+func (u *UI) prompt() string {
+	if u.envName == "" {
+		return "ks> "
+	}
+
+	return fmt.Sprintf("ks (%s)> ", u.envName)
+}
+
+// exec runs a command.
+func (u *UI) exec(args []string) error {
+	switch args[0] {
+	case "help":
+		fmt.Fprint(u.out, uiHelp)
+		return nil
+	case "envs":
+		return u.envListFn(map[string]interface{}{
+			OptionApp: u.app,
+			OptionOut: u.out,
+		})
+	case "env":
+		if len(args) != 2 {
+			return errors.New("usage: env <name>")
+		}
+		env, err := u.app.Environment(args[1])
+		if err != nil || env == nil {
+			return errors.Errorf("environment %q does not exist", args[1])
+		}
+		u.envName = args[1]
+		return nil
+	case "components":
+		return u.componentListFn(map[string]interface{}{
+			OptionApp:    u.app,
+			OptionModule: "",
+			OptionOutput: "",
+			OptionOut:    u.out,
+		})
+	case "params":
+		if len(args) > 2 {
+			return errors.New("usage: params [component]")
+		}
+		m := map[string]interface{}{
+			OptionApp:     u.app,
+			OptionEnvName: u.envName,
+			OptionOut:     u.out,
+		}
+		if len(args) == 2 {
+			m[OptionComponentName] = args[1]
+		}
+		return u.paramListFn(m)
+	case "set":
+		if len(args) < 4 {
+			return errors.New("usage: set <component> <path> <value>")
+		}
+		if err := u.paramSetFn(map[string]interface{}{
+			OptionApp:     u.app,
+			OptionName:    args[1],
+			OptionPath:    args[2],
+			OptionValue:   strings.Join(args[3:], " "),
+			OptionEnvName: u.envName,
+		}); err != nil {
+			return err
+		}
+		fmt.Fprintf(u.out, "set %s %s\n", args[1], args[2])
+		return nil
+	case "diff":
+		if err := u.requireEnv(); err != nil {
+			return err
+		}
+		err := u.diffFn(map[string]interface{}{
+			OptionApp:            u.app,
+			OptionClientConfig:   u.clientConfig,
+			OptionSrc1:           "local:" + u.envName,
+			OptionComponentNames: []string{},
+			OptionOut:            u.out,
+		})
+		switch err {
+		case nil:
+			fmt.Fprintln(u.out, "no differences")
+			return nil
+		case ErrDiffFound:
+			return nil
+		default:
+			if exitErr, ok := err.(*ExitError); ok {
+				return exitErr.Err
+			}
+			return err
+		}
+	case "status":
+		if err := u.requireEnv(); err != nil {
+			return err
+		}
+		return u.statusFn(map[string]interface{}{
+			OptionApp:            u.app,
+			OptionClientConfig:   u.clientConfig,
+			OptionComponentNames: []string{},
+			OptionEnvName:        u.envName,
+			OptionOut:            u.out,
+		})
+	default:
+		return errors.Errorf("unknown command %q; type help for a list of commands", args[0])
+	}
+}
+
+// renderEvent shows the progress of a command. Only evaluations are shown,
+// since the log renderer already shows the other events.
+func (u *UI) renderEvent(r event.Record) {
+	if _, ok := r.Event.(event.EvaluationStarted); ok {
+		fmt.Fprintln(u.out, event.Message(r.Event))
+	}
+}
+
+func (u *UI) requireEnv() error {
+	if u.envName == "" {
+		return errors.New("select an environment with env <name>")
+	}
+
+	return nil
+}
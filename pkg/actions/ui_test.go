@@ -0,0 +1,116 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package actions
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/util/event"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUI(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("CurrentEnvironment").Return("")
+		appMock.On("Environment", "prod").Return(&app.EnvironmentConfig{}, nil)
+		appMock.On("Environment", "missing").Return(nil, errors.New("not found"))
+
+		in := map[string]interface{}{
+			OptionApp:          appMock,
+			OptionClientConfig: &client.Config{},
+		}
+
+		var calls []string
+		record := func(name string) uiActionFn {
+			return func(m map[string]interface{}) error {
+				calls = append(calls, fmt.Sprintf("%s env=%v", name, m[OptionEnvName]))
+				return nil
+			}
+		}
+
+		var set map[string]interface{}
+		var out bytes.Buffer
+
+		u, err := newUI(in, func(u *UI) {
+			u.in = strings.NewReader(strings.Join([]string{
+				"diff",
+				"env missing",
+				"env prod",
+				"envs",
+				"components",
+				"params guestbook",
+				"set guestbook image \"nginx:1.15\"",
+				"diff",
+				"status",
+				"bogus",
+				"quit",
+				"envs",
+			}, "\n"))
+			u.out = &out
+
+			u.envListFn = record("envs")
+			u.componentListFn = record("components")
+			u.paramListFn = record("params")
+			u.paramSetFn = func(m map[string]interface{}) error {
+				set = m
+				return nil
+			}
+			u.diffFn = func(m map[string]interface{}) error {
+				calls = append(calls, fmt.Sprintf("diff src=%v", m[OptionSrc1]))
+				event.Publish(event.EvaluationStarted{Module: "/", Env: "prod"})
+				return ErrDiffFound
+			}
+			u.statusFn = record("status")
+		})
+		require.NoError(t, err)
+		require.NoError(t, u.run())
+
+		expected := []string{
+			"envs env=<nil>",
+			"components env=<nil>",
+			"params env=prod",
+			"diff src=local:prod",
+			"status env=prod",
+		}
+		assert.Equal(t, expected, calls)
+
+		assert.Equal(t, "guestbook", set[OptionName])
+		assert.Equal(t, "image", set[OptionPath])
+		assert.Equal(t, "\"nginx:1.15\"", set[OptionValue])
+		assert.Equal(t, "prod", set[OptionEnvName])
+
+		output := out.String()
+		assert.Contains(t, output, "error: select an environment with env <name>")
+		assert.Contains(t, output, "error: environment \"missing\" does not exist")
+		assert.Contains(t, output, "ks (prod)> ")
+		assert.Contains(t, output, "set guestbook image")
+		assert.Contains(t, output, "evaluating module / for environment prod")
+		assert.Contains(t, output, "error: unknown command \"bogus\"")
+	})
+}
+
+func TestUI_requires_app(t *testing.T) {
+	_, err := newUI(map[string]interface{}{})
+	require.Error(t, err)
+}
@@ -20,13 +20,25 @@ import (
 	"os"
 
 	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/pkg"
 	"github.com/ksonnet/ksonnet/pkg/registry"
 	"github.com/ksonnet/ksonnet/pkg/upgrade"
+	log "github.com/sirupsen/logrus"
 )
 
 // RunUpgrade runs `upgrade`.
 func RunUpgrade(m map[string]interface{}) error {
-	a, err := newUpgrade(m)
+	var o UpgradeOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunUpgradeWithOptions(o)
+}
+
+// RunUpgradeWithOptions runs `upgrade` with typed options.
+func RunUpgradeWithOptions(o UpgradeOptions) error {
+	a, err := newUpgradeWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -40,33 +52,67 @@ type Upgrade struct {
 	pm        registry.PackageManager
 	upgradeFn func(a app.App, out io.Writer, pl upgrade.PackageLister, dryRun bool) error
 	dryRun    bool
+
+	registryListFn func(ksApp app.App) ([]registry.Registry, error)
+}
+
+// UpgradeOptions are the options for Upgrade.
+type UpgradeOptions struct {
+	App           app.App `option:"app"`
+	DryRun        bool    `option:"dry-run"`
+	TLSSkipVerify bool    `option:"tls-skip-verify,optional"`
 }
 
 func newUpgrade(m map[string]interface{}) (*Upgrade, error) {
-	ol := newOptionLoader(m)
+	var o UpgradeOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return newUpgradeWithOptions(o)
+}
 
-	a := ol.LoadApp()
-	if ol.err != nil {
-		return nil, ol.err
+func newUpgradeWithOptions(o UpgradeOptions) (*Upgrade, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
 	}
-	httpClientOpt := registry.HTTPClientOpt(ol.LoadHTTPClient())
-	pm := registry.NewPackageManager(a, httpClientOpt)
+
+	httpClient := newHTTPClient(o.TLSSkipVerify)
+	httpClientOpt := registry.HTTPClientOpt(httpClient)
+	pm := registry.NewPackageManager(o.App, httpClientOpt)
 
 	u := &Upgrade{
-		app:       a,
+		app:       o.App,
 		pm:        pm,
 		upgradeFn: upgrade.Upgrade,
-		dryRun:    ol.LoadBool(OptionDryRun),
-	}
+		dryRun:    o.DryRun,
 
-	if ol.err != nil {
-		return nil, ol.err
+		registryListFn: func(ksApp app.App) ([]registry.Registry, error) {
+			return registry.List(ksApp, httpClient)
+		},
 	}
 
 	return u, nil
 }
 
-// Upgrade upgrades a ksonnet application.
+// Upgrade upgrades a ksonnet application. Installed packages which their
+// registries have deprecated are reported afterwards.
 func (u *Upgrade) run() error {
-	return u.upgradeFn(u.app, os.Stdout, u.pm, u.dryRun)
+	if err := u.upgradeFn(u.app, os.Stdout, u.pm, u.dryRun); err != nil {
+		return err
+	}
+
+	pkgs, err := u.pm.Packages()
+	if err != nil {
+		log.WithError(err).Debug("listing packages to check for deprecated packages")
+		return nil
+	}
+
+	var installed []pkg.Descriptor
+	for _, p := range pkgs {
+		installed = append(installed, pkg.Descriptor{Registry: p.RegistryName(), Name: p.Name(), Version: p.Version()})
+	}
+
+	warnDeprecated(u.app, u.registryListFn, installed)
+	return nil
 }
@@ -21,6 +21,9 @@ import (
 
 	"github.com/ksonnet/ksonnet/pkg/app"
 	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/pkg"
+	"github.com/ksonnet/ksonnet/pkg/registry"
+	rmocks "github.com/ksonnet/ksonnet/pkg/registry/mocks"
 	"github.com/ksonnet/ksonnet/pkg/upgrade"
 	"github.com/stretchr/testify/require"
 )
@@ -42,9 +45,22 @@ func TestUpgrade(t *testing.T) {
 
 		require.NoError(t, err)
 
+		pmMock := &rmocks.PackageManager{}
+		pmMock.On("Packages").Return([]pkg.Package{
+			myPkg{name: "lib1", version: "0.0.1", registry: "incubator", isInstalled: true},
+		}, nil)
+		u.pm = pmMock
+
+		var checkedDeprecations bool
+		u.registryListFn = func(app.App) ([]registry.Registry, error) {
+			checkedDeprecations = true
+			return nil, nil
+		}
+
 		err = u.run()
 		require.NoError(t, err)
 		require.True(t, called)
+		require.True(t, checkedDeprecations, "installed packages not checked for deprecations")
 	})
 }
 
@@ -19,21 +19,35 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/client"
 	"github.com/ksonnet/ksonnet/pkg/openapi"
 	"github.com/ksonnet/ksonnet/pkg/pipeline"
+	"github.com/ksonnet/ksonnet/pkg/policy"
 	"github.com/ksonnet/ksonnet/utils"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/discovery"
 )
 
 // RunValidate runs `ns list`
 func RunValidate(m map[string]interface{}) error {
-	v, err := NewValidate(m)
+	var o ValidateOptions
+	if err := loadOptions(m, &o); err != nil {
+		return err
+	}
+
+	return RunValidateWithOptions(o)
+}
+
+// RunValidateWithOptions runs `ns list` with typed options.
+func RunValidateWithOptions(o ValidateOptions) error {
+	v, err := NewValidateWithOptions(o)
 	if err != nil {
 		return err
 	}
@@ -48,6 +62,10 @@ type validateObjectFn func(
 	obj *unstructured.Unstructured,
 	envName string) []error
 
+type crdFn func(a app.App, clientConfig *client.Config, envName string) ([]*unstructured.Unstructured, error)
+
+type policyFn func(a app.App, envName string, objects []*unstructured.Unstructured) (*policy.Result, error)
+
 type findObjectsFn func(a app.App, envName string,
 	componentNames []string) ([]*unstructured.Unstructured, error)
 
@@ -58,35 +76,70 @@ type Validate struct {
 	module         string
 	componentNames []string
 	clientConfig   *client.Config
+	offline        bool
+	strict         bool
+	output         string
 	out            io.Writer
 
 	discoveryFn      discoveryFn
+	crdFn            crdFn
+	policyFn         policyFn
 	validateObjectFn validateObjectFn
 	findObjectsFn    findObjectsFn
 }
 
-// NewValidate creates an instance of Validate.
+// ValidateOptions are the options for Validate.
+type ValidateOptions struct {
+	App app.App `option:"app"`
+	// EnvName defaults to the app's current environment.
+	EnvName        string         `option:"env-name"`
+	Module         string         `option:"module"`
+	ComponentNames []string       `option:"component-names"`
+	ClientConfig   *client.Config `option:"client-config"`
+	Offline        bool           `option:"offline,optional"`
+	Output         string         `option:"output,optional"`
+	// Strict rejects fields which are not described by the schema.
+	Strict bool `option:"strict,optional"`
+}
+
+// NewValidate creates an instance of Validate from an option map.
 func NewValidate(m map[string]interface{}) (*Validate, error) {
-	ol := newOptionLoader(m)
+	var o ValidateOptions
+	if err := loadOptions(m, &o); err != nil {
+		return nil, err
+	}
+
+	return NewValidateWithOptions(o)
+}
+
+// NewValidateWithOptions creates an instance of Validate.
+func NewValidateWithOptions(o ValidateOptions) (*Validate, error) {
+	if o.App == nil {
+		return nil, ErrNotInApp
+	}
 
 	v := &Validate{
-		app:            ol.LoadApp(),
-		envName:        ol.LoadString(OptionEnvName),
-		module:         ol.LoadString(OptionModule),
-		componentNames: ol.LoadStringSlice(OptionComponentNames),
-		clientConfig:   ol.LoadClientConfig(),
+		app:            o.App,
+		module:         o.Module,
+		componentNames: o.ComponentNames,
+		clientConfig:   o.ClientConfig,
+		offline:        o.Offline,
+		strict:         o.Strict,
+		output:         o.Output,
 
 		out:              os.Stdout,
 		discoveryFn:      loadDiscovery,
+		crdFn:            loadClusterCRDs,
+		policyFn:         policy.Check,
 		validateObjectFn: openapi.ValidateAgainstSchema,
 		findObjectsFn:    findObjects,
 	}
 
-	if ol.err != nil {
-		return nil, ol.err
+	if v.strict {
+		v.validateObjectFn = openapi.ValidateAgainstSchemaStrict
 	}
 
-	if err := setCurrentEnv(v.app, v, ol); err != nil {
+	if err := setCurrentEnv(v.app, v, o.EnvName); err != nil {
 		return nil, err
 	}
 
@@ -100,24 +153,58 @@ func (v *Validate) Run() error {
 		return err
 	}
 
-	disc, err := v.discoveryFn(v.app, v.clientConfig, v.envName)
+	// Objects are validated against the OpenAPI schema cached in the app's
+	// lib directory for the environment's Kubernetes version, so the cluster is
+	// only used to describe the objects.
+	var disc discovery.DiscoveryInterface
+	if !v.offline {
+		disc, err = v.discoveryFn(v.app, v.clientConfig, v.envName)
+		if err != nil {
+			return err
+		}
+	}
+
+	crds, err := v.crdSchemas(objects)
 	if err != nil {
 		return err
 	}
 
+	validateCRD := crds.Validate
+	if v.strict {
+		validateCRD = crds.ValidateStrict
+	}
+
 	var hasError bool
 
 	for _, obj := range objects {
-		desc := fmt.Sprintf("%s %s", utils.ResourceNameFor(disc, obj), utils.FqName(obj))
+		desc := fmt.Sprintf("%s %s", resourceName(disc, obj), utils.FqName(obj))
 		log.Info("Validating ", desc)
 
-		errs := v.validateObjectFn(v.app, obj, v.envName)
+		errs, ok := validateCRD(obj)
+		if !ok {
+			errs = v.validateObjectFn(v.app, obj, v.envName)
+		}
 		for _, err := range errs {
 			log.Errorf("Error in %s: %v", desc, err)
 			hasError = true
 		}
 	}
 
+	result, err := v.policyFn(v.app, v.envName, objects)
+	if err != nil {
+		return err
+	}
+
+	if len(result.Violations) > 0 || v.output == OutputJSON {
+		if err = result.Render(v.out, v.output); err != nil {
+			return err
+		}
+	}
+
+	if result.Denied() {
+		hasError = true
+	}
+
 	if hasError {
 		return errors.Errorf("validation failed")
 	}
@@ -125,11 +212,89 @@ func (v *Validate) Run() error {
 	return nil
 }
 
+// crdSchemas collects the schemas of custom resources from the cluster, the
+// app's schemas directory, and the CustomResourceDefinitions being validated,
+// in increasing order of precedence.
+func (v *Validate) crdSchemas(objects []*unstructured.Unstructured) (*openapi.CRDSchemas, error) {
+	crds := openapi.NewCRDSchemas()
+
+	if !v.offline {
+		clusterCRDs, err := v.crdFn(v.app, v.clientConfig, v.envName)
+		if err != nil {
+			log.Warnf("Unable to fetch CustomResourceDefinitions from the cluster: %v", err)
+		}
+
+		for _, crd := range clusterCRDs {
+			if err = crds.AddCRD(crd); err != nil {
+				log.Warnf("Ignoring schema of CustomResourceDefinition %s: %v", crd.GetName(), err)
+			}
+		}
+	}
+
+	dir := filepath.Join(v.app.Root(), openapi.SchemaDirName)
+	if err := crds.AddDir(v.app.Fs(), dir); err != nil {
+		return nil, err
+	}
+
+	for _, obj := range objects {
+		if obj.GetKind() != "CustomResourceDefinition" {
+			continue
+		}
+
+		if err := crds.AddCRD(obj); err != nil {
+			return nil, err
+		}
+	}
+
+	return crds, nil
+}
+
+// resourceName returns the resource name of an object, or its lowercased kind
+// without a discovery client.
+func resourceName(disc discovery.DiscoveryInterface, obj *unstructured.Unstructured) string {
+	if disc == nil {
+		return utils.GroupVersionKindFor(obj)
+	}
+
+	return utils.ResourceNameFor(disc, obj)
+}
+
 func loadDiscovery(a app.App, clientConfig *client.Config, envName string) (discovery.DiscoveryInterface, error) {
 	_, d, _, err := clientConfig.RestClient(a, &envName)
 	return d, err
 }
 
+func loadClusterCRDs(a app.App, clientConfig *client.Config, envName string) ([]*unstructured.Unstructured, error) {
+	pool, _, _, err := clientConfig.RestClient(a, &envName)
+	if err != nil {
+		return nil, err
+	}
+
+	gvk := schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1beta1", Kind: "CustomResourceDefinition"}
+	dynamic, err := pool.ClientForGroupVersionKind(gvk)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating client for resource: %s", gvk)
+	}
+
+	resource := &metav1.APIResource{Name: "customresourcedefinitions", Kind: gvk.Kind}
+	obj, err := dynamic.Resource(resource, "").List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "listing CustomResourceDefinitions")
+	}
+
+	ul, ok := obj.(*unstructured.UnstructuredList)
+	if !ok {
+		return nil, errors.Errorf("unexpected list type %T", obj)
+	}
+
+	var crds []*unstructured.Unstructured
+	for i := range ul.Items {
+		crds = append(crds, &ul.Items[i])
+	}
+
+	return crds, nil
+}
+
 func findObjects(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
 	p := pipeline.New(a, envName)
 	return p.Objects(componentNames)
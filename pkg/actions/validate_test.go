@@ -90,6 +90,10 @@ func TestValidate(t *testing.T) {
 				objects := []*unstructured.Unstructured{
 					{},
 				}
+				a.crdFn = func(a app.App, clientConfig *client.Config, envName string) ([]*unstructured.Unstructured, error) {
+					return nil, nil
+				}
+
 				a.findObjectsFn = func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
 					assert.Equal(t, "default", envName)
 					assert.Equal(t, aComponentNames, componentNames)
@@ -108,6 +112,182 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+func TestValidate_offline(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("Environment", "default").Return(&app.EnvironmentConfig{}, nil)
+
+		in := map[string]interface{}{
+			OptionApp:            appMock,
+			OptionEnvName:        "default",
+			OptionModule:         "",
+			OptionComponentNames: []string{},
+			OptionClientConfig:   &client.Config{},
+			OptionOffline:        true,
+		}
+
+		a, err := NewValidate(in)
+		require.NoError(t, err)
+
+		a.discoveryFn = func(a app.App, clientConfig *client.Config, envName string) (discovery.DiscoveryInterface, error) {
+			return nil, errors.New("cluster is not reachable")
+		}
+
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Service",
+			"metadata":   map[string]interface{}{"name": "guestbook"},
+		}}
+		a.findObjectsFn = func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+			return []*unstructured.Unstructured{obj}, nil
+		}
+
+		var validated []string
+		a.validateObjectFn = func(a app.App, obj *unstructured.Unstructured, envName string) []error {
+			validated = append(validated, obj.GetName())
+			return nil
+		}
+
+		err = a.Run()
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"guestbook"}, validated)
+	})
+}
+
+func TestValidate_custom_resources(t *testing.T) {
+	withApp(t, func(appMock *amocks.App) {
+		appMock.On("Environment", "default").Return(&app.EnvironmentConfig{}, nil)
+
+		in := map[string]interface{}{
+			OptionApp:            appMock,
+			OptionEnvName:        "default",
+			OptionModule:         "",
+			OptionComponentNames: []string{},
+			OptionClientConfig:   &client.Config{},
+			OptionOffline:        true,
+		}
+
+		a, err := NewValidate(in)
+		require.NoError(t, err)
+
+		crd := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apiextensions.k8s.io/v1beta1",
+			"kind":       "CustomResourceDefinition",
+			"metadata":   map[string]interface{}{"name": "certificates.example.com"},
+			"spec": map[string]interface{}{
+				"group":   "example.com",
+				"version": "v1",
+				"names":   map[string]interface{}{"kind": "Certificate"},
+				"validation": map[string]interface{}{
+					"openAPIV3Schema": map[string]interface{}{
+						"required": []interface{}{"spec"},
+					},
+				},
+			},
+		}}
+		cert := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "example.com/v1",
+			"kind":       "Certificate",
+			"metadata":   map[string]interface{}{"name": "cert"},
+		}}
+		a.findObjectsFn = func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+			return []*unstructured.Unstructured{crd, cert}, nil
+		}
+
+		var validated []string
+		a.validateObjectFn = func(a app.App, obj *unstructured.Unstructured, envName string) []error {
+			validated = append(validated, obj.GetKind())
+			return nil
+		}
+
+		err = a.Run()
+		require.Error(t, err)
+
+		assert.Equal(t, []string{"CustomResourceDefinition"}, validated)
+	})
+}
+
+func TestValidate_strict(t *testing.T) {
+	crd := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1beta1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": "certificates.example.com"},
+		"spec": map[string]interface{}{
+			"group":   "example.com",
+			"version": "v1",
+			"names":   map[string]interface{}{"kind": "Certificate"},
+			"validation": map[string]interface{}{
+				"openAPIV3Schema": map[string]interface{}{
+					"properties": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"properties": map[string]interface{}{
+								"dnsName": map[string]interface{}{"type": "string"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}}
+	cert := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Certificate",
+		"metadata":   map[string]interface{}{"name": "cert"},
+		"spec":       map[string]interface{}{"dnsNme": "example.com"},
+	}}
+
+	cases := []struct {
+		name   string
+		strict bool
+		isErr  bool
+	}{
+		{
+			name: "unknown fields are allowed",
+		},
+		{
+			name:   "unknown fields are rejected",
+			strict: true,
+			isErr:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withApp(t, func(appMock *amocks.App) {
+				appMock.On("Environment", "default").Return(&app.EnvironmentConfig{}, nil)
+
+				in := map[string]interface{}{
+					OptionApp:            appMock,
+					OptionEnvName:        "default",
+					OptionModule:         "",
+					OptionComponentNames: []string{},
+					OptionClientConfig:   &client.Config{},
+					OptionOffline:        true,
+					OptionStrict:         tc.strict,
+				}
+
+				a, err := NewValidate(in)
+				require.NoError(t, err)
+
+				a.findObjectsFn = func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+					return []*unstructured.Unstructured{crd, cert}, nil
+				}
+
+				a.validateObjectFn = func(a app.App, obj *unstructured.Unstructured, envName string) []error {
+					return nil
+				}
+
+				err = a.Run()
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+			})
+		})
+	}
+}
+
 func TestValidate_requires_app(t *testing.T) {
 	in := make(map[string]interface{})
 	_, err := NewValidate(in)
@@ -42,6 +42,11 @@ const (
 
 	// currentEnvName is the file which selects the current environment.
 	currentEnvName = ".ks_environment"
+
+	// EnvPartsCache is the environment variable which names a directory
+	// packages are cached in. It takes precedence over the partsCache
+	// setting in app.yaml.
+	EnvPartsCache = "KS_PARTS_CACHE"
 )
 
 var (
@@ -59,6 +64,9 @@ type App interface {
 	AddRegistry(spec *RegistryConfig, isOverride bool) error
 	// CurrentEnvironment returns the current environment name or an empty string.
 	CurrentEnvironment() string
+	// DefaultRegistry returns the registry packages are installed from when
+	// their names don't include a registry.
+	DefaultRegistry() (string, error)
 	// Environment finds an environment by name.
 	Environment(name string) (*EnvironmentConfig, error)
 	// Environments returns all environments.
@@ -75,6 +83,13 @@ type App interface {
 	LibPath(envName string) (string, error)
 	// Libraries returns all environments.
 	Libraries() (LibraryConfigs, error)
+	// LogLevels returns the log levels of subsystems set in app.yaml.
+	LogLevels() ([]string, error)
+	// Name returns the name of the app set in app.yaml.
+	Name() (string, error)
+	// PartsCache returns the directory packages are cached in, so apps can
+	// share downloads. It is empty if packages are not cached.
+	PartsCache() (string, error)
 	// Registries returns all registries.
 	Registries() (RegistryConfigs, error)
 	// RemoveEnvironment removes an environment from the main configuration or an override.
@@ -85,6 +100,8 @@ type App interface {
 	Root() string
 	// SetCurrentEnvironment sets the current environment.
 	SetCurrentEnvironment(name string) error
+	// SetDefaultRegistry sets the default registry. An empty name clears it.
+	SetDefaultRegistry(name string) error
 	// UpdateTargets sets the targets for an environment.
 	UpdateTargets(envName string, targets []string) error
 	// UpdateLib adds, updates or removes a library reference.
@@ -108,7 +125,7 @@ func Load(fs afero.Fs, httpClient *http.Client, cwd string, skipFindRoot bool) (
 	appRoot := cwd
 	if !skipFindRoot {
 		var err error
-		appRoot, err = findRoot(fs, cwd)
+		appRoot, err = Find(fs, cwd)
 		if err != nil {
 			return nil, err
 		}
@@ -232,7 +249,13 @@ func cleanEnv(fs afero.Fs, root string) error {
 	return nil
 }
 
-func findRoot(fs afero.Fs, cwd string) (string, error) {
+// Find returns the root of the ksonnet application containing dir. Like git,
+// it walks up from dir until it finds a directory with an app.yaml.
+func Find(fs afero.Fs, dir string) (string, error) {
+	cwd, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
 	prev := cwd
 
 	for {
@@ -252,7 +275,7 @@ func findRoot(fs afero.Fs, cwd string) (string, error) {
 		}
 
 		if cwd == prev {
-			return "", errors.Errorf("unable to find ksonnet project")
+			return "", errors.Errorf("unable to find ksonnet project in %s or any parent directory", dir)
 		}
 
 		prev = cwd
@@ -17,6 +17,7 @@ package app
 
 import (
 	"net/http"
+	"os"
 	"path/filepath"
 	"sync"
 
@@ -335,6 +336,73 @@ func (ba *baseApp) UpdateRegistry(spec *RegistryConfig) error {
 	return ba.save()
 }
 
+// DefaultRegistry returns the registry packages are installed from when
+// their names don't include a registry.
+func (ba *baseApp) DefaultRegistry() (string, error) {
+	if err := ba.load(); err != nil {
+		return "", errors.Wrap(err, "load configuration")
+	}
+
+	return ba.config.DefaultRegistry, nil
+}
+
+// SetDefaultRegistry sets the default registry. An empty name clears it.
+func (ba *baseApp) SetDefaultRegistry(name string) error {
+	if err := ba.load(); err != nil {
+		return errors.Wrap(err, "load configuration")
+	}
+
+	if name != "" {
+		var ok, okOverride bool
+		_, ok = ba.config.Registries[name]
+		if ba.overrides != nil {
+			_, okOverride = ba.overrides.Registries[name]
+		}
+		if !ok && !okOverride {
+			return errors.Errorf("registry not found: %v", name)
+		}
+	}
+
+	ba.config.DefaultRegistry = name
+	return ba.save()
+}
+
+// LogLevels returns the log levels of subsystems set in app.yaml.
+func (ba *baseApp) LogLevels() ([]string, error) {
+	if err := ba.load(); err != nil {
+		return nil, errors.Wrap(err, "load configuration")
+	}
+
+	return ba.config.LogLevels, nil
+}
+
+// Name returns the name of the app set in app.yaml.
+func (ba *baseApp) Name() (string, error) {
+	if err := ba.load(); err != nil {
+		return "", errors.Wrap(err, "load configuration")
+	}
+
+	return ba.config.Name, nil
+}
+
+// PartsCache returns the directory packages are cached in. $KS_PARTS_CACHE
+// takes precedence over app.yaml.
+func (ba *baseApp) PartsCache() (string, error) {
+	dir := os.Getenv(EnvPartsCache)
+	if dir == "" {
+		if err := ba.load(); err != nil {
+			return "", errors.Wrap(err, "load configuration")
+		}
+		dir = ba.config.PartsCache
+	}
+
+	if dir == "" || filepath.IsAbs(dir) {
+		return dir, nil
+	}
+
+	return filepath.Join(ba.root, dir), nil
+}
+
 func (ba *baseApp) Fs() afero.Fs {
 	return ba.fs
 }
@@ -390,6 +458,95 @@ func deepCopyLibraries(src LibraryConfigs) LibraryConfigs {
 	return lc
 }
 
+func deepCopyDestinations(src []*EnvironmentDestinationSpec) []*EnvironmentDestinationSpec {
+	destinations := make([]*EnvironmentDestinationSpec, 0, len(src))
+	for _, d := range src {
+		if d == nil {
+			continue
+		}
+		c := *d
+		destinations = append(destinations, &c)
+	}
+	return destinations
+}
+
+func deepCopyPolicies(src []*PolicyConfig) []*PolicyConfig {
+	policies := make([]*PolicyConfig, 0, len(src))
+	for _, p := range src {
+		if p == nil {
+			continue
+		}
+		c := *p
+		policies = append(policies, &c)
+	}
+	return policies
+}
+
+func deepCopyHooks(src []*HookConfig) []*HookConfig {
+	hooks := make([]*HookConfig, 0, len(src))
+	for _, h := range src {
+		if h == nil {
+			continue
+		}
+		c := *h
+		c.Command = append([]string(nil), h.Command...)
+		c.Components = append([]string(nil), h.Components...)
+		hooks = append(hooks, &c)
+	}
+	return hooks
+}
+
+func deepCopyDiffIgnore(src []*DiffIgnoreConfig) []*DiffIgnoreConfig {
+	rules := make([]*DiffIgnoreConfig, 0, len(src))
+	for _, r := range src {
+		if r == nil {
+			continue
+		}
+		c := *r
+		c.JSONPointers = append([]string(nil), r.JSONPointers...)
+		c.JSONPaths = append([]string(nil), r.JSONPaths...)
+		rules = append(rules, &c)
+	}
+	return rules
+}
+
+func deepCopyRedact(src []*RedactConfig) []*RedactConfig {
+	rules := make([]*RedactConfig, 0, len(src))
+	for _, r := range src {
+		if r == nil {
+			continue
+		}
+		c := *r
+		c.JSONPointers = append([]string(nil), r.JSONPointers...)
+		rules = append(rules, &c)
+	}
+	return rules
+}
+
+func deepCopyWebhooks(src []*WebhookConfig) []*WebhookConfig {
+	webhooks := make([]*WebhookConfig, 0, len(src))
+	for _, w := range src {
+		if w == nil {
+			continue
+		}
+		c := *w
+		c.Kinds = append([]string(nil), w.Kinds...)
+		webhooks = append(webhooks, &c)
+	}
+	return webhooks
+}
+
+func deepCopyNamespacePolicy(src *NamespacePolicyConfig) *NamespacePolicyConfig {
+	c := *src
+	if src.Labels != nil {
+		c.Labels = make(map[string]string, len(src.Labels))
+		for k, v := range src.Labels {
+			c.Labels[k] = v
+		}
+	}
+	return &c
+}
+
 func deepCopyEnvironmentConfig(src EnvironmentConfig) *EnvironmentConfig {
 	e := src
 
@@ -397,6 +554,9 @@ func deepCopyEnvironmentConfig(src EnvironmentConfig) *EnvironmentConfig {
 		d := *src.Destination
 		e.Destination = &d
 	}
+	if src.Destinations != nil {
+		e.Destinations = deepCopyDestinations(src.Destinations)
+	}
 	if src.Targets != nil {
 		t := make([]string, len(src.Targets))
 		copy(t, src.Targets)
@@ -405,6 +565,36 @@ func deepCopyEnvironmentConfig(src EnvironmentConfig) *EnvironmentConfig {
 	if src.Libraries != nil {
 		e.Libraries = deepCopyLibraries(src.Libraries)
 	}
+	if src.Policies != nil {
+		e.Policies = deepCopyPolicies(src.Policies)
+	}
+	if src.Hooks != nil {
+		e.Hooks = deepCopyHooks(src.Hooks)
+	}
+	if src.Namespaces != nil {
+		e.Namespaces = deepCopyNamespacePolicy(src.Namespaces)
+	}
+	if src.Apply != nil {
+		a := *src.Apply
+		e.Apply = &a
+	}
+	if src.DiffIgnore != nil {
+		e.DiffIgnore = deepCopyDiffIgnore(src.DiffIgnore)
+	}
+	if src.Redact != nil {
+		e.Redact = deepCopyRedact(src.Redact)
+	}
+	if src.Webhooks != nil {
+		e.Webhooks = deepCopyWebhooks(src.Webhooks)
+	}
+	if src.Budget != nil {
+		b := *src.Budget
+		e.Budget = &b
+	}
+	if src.Secrets != nil {
+		s := *src.Secrets
+		e.Secrets = &s
+	}
 
 	return &e
 }
@@ -445,11 +635,50 @@ func (ba *baseApp) mergedEnvironment(name string) *EnvironmentConfig {
 			d := *override.Destination
 			combined.Destination = &d
 		}
+		if override.Destinations != nil {
+			combined.Destinations = deepCopyDestinations(override.Destinations)
+		}
 		if override.Targets != nil {
 			t := make([]string, len(override.Targets))
 			copy(t, override.Targets)
 			combined.Targets = t
 		}
+		if override.Policies != nil {
+			combined.Policies = deepCopyPolicies(override.Policies)
+		}
+		if override.Hooks != nil {
+			combined.Hooks = deepCopyHooks(override.Hooks)
+		}
+		if override.Namespaces != nil {
+			combined.Namespaces = deepCopyNamespacePolicy(override.Namespaces)
+		}
+		if override.Apply != nil {
+			a := *override.Apply
+			combined.Apply = &a
+		}
+		if override.DiffIgnore != nil {
+			combined.DiffIgnore = deepCopyDiffIgnore(override.DiffIgnore)
+		}
+		if override.Webhooks != nil {
+			combined.Webhooks = deepCopyWebhooks(override.Webhooks)
+		}
+		if override.Budget != nil {
+			b := *override.Budget
+			combined.Budget = &b
+		}
+		if override.Secrets != nil {
+			s := *override.Secrets
+			combined.Secrets = &s
+		}
+		if override.DuplicateObjects != "" {
+			combined.DuplicateObjects = override.DuplicateObjects
+		}
+		combined.SourceMap = combined.SourceMap || override.SourceMap
+		if override.Redact != nil {
+			combined.Redact = deepCopyRedact(override.Redact)
+		}
+		// An override can protect an environment, but not unprotect it.
+		combined.Protected = combined.Protected || override.Protected
 		combined.isOverride = true
 		return combined
 	case hasOverride:
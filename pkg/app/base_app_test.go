@@ -16,6 +16,7 @@
 package app
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -189,6 +190,96 @@ func Test_baseApp_UpdateRegistry(t *testing.T) {
 
 }
 
+func Test_baseApp_SetDefaultRegistry(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	stageFile(t, fs, "app010_app.yaml", "/app.yaml")
+
+	ba := newBaseApp(fs, "/", nil)
+
+	name, err := ba.DefaultRegistry()
+	require.NoError(t, err)
+	assert.Equal(t, "", name)
+
+	err = ba.SetDefaultRegistry("incubator")
+	require.NoError(t, err)
+
+	assertContents(t, fs, "default-registry.yaml", ba.configPath())
+
+	name, err = ba.DefaultRegistry()
+	require.NoError(t, err)
+	assert.Equal(t, "incubator", name)
+
+	err = ba.SetDefaultRegistry("missing")
+	require.Error(t, err)
+
+	err = ba.SetDefaultRegistry("")
+	require.NoError(t, err)
+
+	name, err = ba.DefaultRegistry()
+	require.NoError(t, err)
+	assert.Equal(t, "", name)
+}
+
+func Test_baseApp_PartsCache(t *testing.T) {
+	cases := []struct {
+		name     string
+		env      string
+		config   string
+		expected string
+	}{
+		{
+			name: "not configured",
+		},
+		{
+			name:     "relative to app",
+			config:   "cache",
+			expected: "/app/cache",
+		},
+		{
+			name:     "absolute",
+			config:   "/var/cache/ks",
+			expected: "/var/cache/ks",
+		},
+		{
+			name:     "environment takes precedence",
+			env:      "/ci/cache",
+			config:   "cache",
+			expected: "/ci/cache",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ba := newBaseApp(afero.NewMemMapFs(), "/app", nil)
+			ba.load = func() error {
+				ba.config = &Spec{PartsCache: tc.config}
+				return nil
+			}
+
+			old := os.Getenv(EnvPartsCache)
+			defer os.Setenv(EnvPartsCache, old)
+			os.Setenv(EnvPartsCache, tc.env)
+
+			dir, err := ba.PartsCache()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, dir)
+		})
+	}
+}
+
+func Test_baseApp_LogLevels(t *testing.T) {
+	ba := newBaseApp(afero.NewMemMapFs(), "/app", nil)
+	ba.load = func() error {
+		ba.config = &Spec{LogLevels: []string{"registry=debug", "cluster=warn"}}
+		return nil
+	}
+
+	levels, err := ba.LogLevels()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"registry=debug", "cluster=warn"}, levels)
+}
+
 func Test_baseApp_UpdateLibrary(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -456,8 +547,30 @@ func Test_baseApp_environment_override_is_merged(t *testing.T) {
 			Server:    "http://override.com",
 			Namespace: "override",
 		},
-		Path:    "overrides/path",
-		Targets: []string{"override1", "override2"},
+		Path:      "overrides/path",
+		Targets:   []string{"override1", "override2"},
+		Protected: true,
+		Namespaces: &NamespacePolicyConfig{
+			Policy: "create",
+			Labels: map[string]string{"team": "guestbook"},
+		},
+		Apply: &ApplySettingsConfig{
+			Parallelism: 8,
+			QPS:         20,
+		},
+		DiffIgnore: []*DiffIgnoreConfig{
+			{Kind: "Deployment", JSONPointers: []string{"/spec/replicas"}},
+		},
+		Webhooks: []*WebhookConfig{
+			{Name: "images", URL: "https://policy.example.com/validate", Kinds: []string{"Deployment"}},
+		},
+		Budget:           &BudgetConfig{MaxObjects: 500, Level: "warn"},
+		DuplicateObjects: "warn",
+		SourceMap:        true,
+		Redact: []*RedactConfig{
+			{Kind: "ConfigMap", JSONPointers: []string{"/data/password"}},
+		},
+		Secrets: &SecretsConfig{Transform: "sealed"},
 	}
 
 	expected := &EnvironmentConfig{
@@ -470,8 +583,30 @@ func Test_baseApp_environment_override_is_merged(t *testing.T) {
 			Server:    "http://override.com",
 			Namespace: "override",
 		},
-		Path:       "overrides/path",
-		Targets:    []string{"override1", "override2"},
+		Path:      "overrides/path",
+		Targets:   []string{"override1", "override2"},
+		Protected: true,
+		Namespaces: &NamespacePolicyConfig{
+			Policy: "create",
+			Labels: map[string]string{"team": "guestbook"},
+		},
+		Apply: &ApplySettingsConfig{
+			Parallelism: 8,
+			QPS:         20,
+		},
+		DiffIgnore: []*DiffIgnoreConfig{
+			{Kind: "Deployment", JSONPointers: []string{"/spec/replicas"}},
+		},
+		Webhooks: []*WebhookConfig{
+			{Name: "images", URL: "https://policy.example.com/validate", Kinds: []string{"Deployment"}},
+		},
+		Budget:           &BudgetConfig{MaxObjects: 500, Level: "warn"},
+		DuplicateObjects: "warn",
+		SourceMap:        true,
+		Redact: []*RedactConfig{
+			{Kind: "ConfigMap", JSONPointers: []string{"/data/password"}},
+		},
+		Secrets: &SecretsConfig{Transform: "sealed"},
 		isOverride: true,
 	}
 
@@ -517,4 +652,3 @@ func Test_baseApp_environment_just_override(t *testing.T) {
 
 	assert.Equal(t, expected, e)
 }
-
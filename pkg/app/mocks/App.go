@@ -89,6 +89,27 @@ func (_m *App) CurrentEnvironment() string {
 	return r0
 }
 
+// DefaultRegistry provides a mock function with given fields:
+func (_m *App) DefaultRegistry() (string, error) {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Environment provides a mock function with given fields: name
 func (_m *App) Environment(name string) (*app.EnvironmentConfig, error) {
 	ret := _m.Called(name)
@@ -232,6 +253,71 @@ func (_m *App) Libraries() (app.LibraryConfigs, error) {
 	return r0, r1
 }
 
+// LogLevels provides a mock function with given fields:
+func (_m *App) LogLevels() ([]string, error) {
+	ret := _m.Called()
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func() []string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Name provides a mock function with given fields:
+func (_m *App) Name() (string, error) {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PartsCache provides a mock function with given fields:
+func (_m *App) PartsCache() (string, error) {
+	ret := _m.Called()
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Registries provides a mock function with given fields:
 func (_m *App) Registries() (app.RegistryConfigs, error) {
 	ret := _m.Called()
@@ -311,6 +397,20 @@ func (_m *App) SetCurrentEnvironment(name string) error {
 	return r0
 }
 
+// SetDefaultRegistry provides a mock function with given fields: name
+func (_m *App) SetDefaultRegistry(name string) error {
+	ret := _m.Called(name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateLib provides a mock function with given fields: name, env, spec
 func (_m *App) UpdateLib(name string, env string, spec *app.LibraryConfig) (*app.LibraryConfig, error) {
 	ret := _m.Called(name, env, spec)
@@ -81,6 +81,14 @@ type Spec struct {
 	Environments EnvironmentConfigs `json:"environments,omitempty"`
 	Libraries    LibraryConfigs     `json:"libraries,omitempty"`
 	License      string             `json:"license,omitempty"`
+	// DefaultRegistry is the registry packages are installed from when
+	// their names don't include a registry.
+	DefaultRegistry string `json:"defaultRegistry,omitempty"`
+	// PartsCache is the directory packages are cached in. Relative paths
+	// are relative to the app's root.
+	PartsCache string `json:"partsCache,omitempty"`
+	// LogLevels are the log levels of subsystems, e.g. registry=debug.
+	LogLevels []string `json:"logLevels,omitempty"`
 }
 
 // Read will return the specification for a ksonnet application. It will navigate up directories
@@ -306,11 +314,52 @@ type EnvironmentConfig struct {
 	Path string `json:"path"`
 	// Destination stores the cluster address that this environment points to.
 	Destination *EnvironmentDestinationSpec `json:"destination"`
+	// Destinations stores the cluster addresses of an environment which is
+	// deployed to multiple clusters, e.g. for active-active deployments. When
+	// set, cluster operations fan out across all of them.
+	Destinations []*EnvironmentDestinationSpec `json:"destinations,omitempty"`
 	// Targets contain the relative component paths that this environment
 	// wishes to deploy on it's destination.
 	Targets []string `json:"targets,omitempty"`
 	// Libraries specifies versioned libraries specifically used by this environment.
 	Libraries LibraryConfigs `json:"libraries,omitempty"`
+	// Policies are the policies that rendered manifests are checked against
+	// when they are validated or applied.
+	Policies []*PolicyConfig `json:"policies,omitempty"`
+	// Hooks are local commands run before or after the environment is applied.
+	Hooks []*HookConfig `json:"hooks,omitempty"`
+	// Protected environments must be confirmed by name before they, or
+	// their objects, are deleted.
+	Protected bool `json:"protected,omitempty"`
+	// Namespaces is the policy for the namespaces of the objects the
+	// environment applies. By default, namespaces are not checked.
+	Namespaces *NamespacePolicyConfig `json:"namespaces,omitempty"`
+	// Apply configures how the environment's objects are sent to the
+	// cluster. Command line flags take precedence.
+	Apply *ApplySettingsConfig `json:"apply,omitempty"`
+	// DiffIgnore are rules for differences which are left out when the
+	// environment is diffed.
+	DiffIgnore []*DiffIgnoreConfig `json:"diffIgnore,omitempty"`
+	// Webhooks are validating admission webhooks which rendered manifests
+	// are replayed against before they are applied.
+	Webhooks []*WebhookConfig `json:"webhooks,omitempty"`
+	// Budget limits the number and size of the environment's rendered
+	// objects, to catch components which generate far more objects than
+	// intended.
+	Budget *BudgetConfig `json:"budget,omitempty"`
+	// DuplicateObjects is the level of objects rendered more than once by
+	// the environment's components. Set it to warn to report them without
+	// failing. Defaults to deny.
+	DuplicateObjects string `json:"duplicateObjects,omitempty"`
+	// SourceMap annotates each rendered object with the component, and the
+	// prototype and package, which produced it.
+	SourceMap bool `json:"sourceMap,omitempty"`
+	// Redact are rules for fields, in addition to the data of Secrets, whose
+	// values are redacted when objects are shown or diffed.
+	Redact []*RedactConfig `json:"redact,omitempty"`
+	// Secrets converts the Secrets the environment renders, so their data is
+	// never written to rendered manifests.
+	Secrets *SecretsConfig `json:"secrets,omitempty"`
 
 	isOverride bool
 }
@@ -328,9 +377,49 @@ func (e *EnvironmentConfig) IsOverride() bool {
 	return e.isOverride
 }
 
+// ClusterDestinations returns the destinations that cluster operations
+// target. Environments without Destinations target Destination.
+func (e *EnvironmentConfig) ClusterDestinations() []EnvironmentDestinationSpec {
+	var destinations []EnvironmentDestinationSpec
+	for _, d := range e.Destinations {
+		if d != nil {
+			destinations = append(destinations, *d)
+		}
+	}
+
+	if len(destinations) == 0 && e.Destination != nil {
+		destinations = append(destinations, *e.Destination)
+	}
+
+	return destinations
+}
+
+// PrimaryDestination returns the destination used where an environment is
+// treated as a single cluster, e.g. the destination passed to components:
+// Destination, or the first of Destinations if it is not set. Environments
+// without destinations return an empty destination.
+func (e *EnvironmentConfig) PrimaryDestination() EnvironmentDestinationSpec {
+	if e.Destination != nil {
+		return *e.Destination
+	}
+
+	for _, d := range e.Destinations {
+		if d != nil {
+			return *d
+		}
+	}
+
+	return EnvironmentDestinationSpec{}
+}
+
 // EnvironmentDestinationSpec contains the specification for the cluster
 // address that the environment points to.
 type EnvironmentDestinationSpec struct {
+	// Name identifies the destination in output. Optional.
+	Name string `json:"name,omitempty"`
+	// Context is the kubeconfig context of the cluster. When set, it is used
+	// instead of Server.
+	Context string `json:"context,omitempty"`
 	// Server is the Kubernetes server that the cluster is running on.
 	Server string `json:"server"`
 	// Namespace is the namespace of the Kubernetes server that targets should
@@ -338,6 +427,175 @@ type EnvironmentDestinationSpec struct {
 	Namespace string `json:"namespace"`
 }
 
+// String returns the name of the destination, or its cluster address if it
+// is unnamed.
+func (d EnvironmentDestinationSpec) String() string {
+	switch {
+	case d.Name != "":
+		return d.Name
+	case d.Context != "":
+		return d.Context
+	default:
+		return d.Server
+	}
+}
+
+// PolicyConfig is the specification for a policy that an environment's
+// manifests are checked against.
+type PolicyConfig struct {
+	// Path is the path of the Rego policy, relative to the app root.
+	Path string `json:"path"`
+	// Level is the level of violations of the policy's deny rules. Set it to
+	// warn to report violations without failing. Defaults to deny.
+	Level string `json:"level,omitempty"`
+}
+
+// HookConfig is the specification for a local command which is run before or
+// after an environment is applied.
+type HookConfig struct {
+	// Name is the name of the hook.
+	Name string `json:"name"`
+	// Phase is when the hook runs: pre-apply or post-apply.
+	Phase string `json:"phase"`
+	// Command is the command and its arguments. It runs in the app root.
+	Command []string `json:"command"`
+	// Components limits the hook to applies which include one of the
+	// components. By default, the hook runs for every apply.
+	Components []string `json:"components,omitempty"`
+	// Weight orders the hooks of a phase. Hooks with lower weights run first.
+	Weight int `json:"weight,omitempty"`
+	// Timeout is how long the hook may run, e.g. 30s. Defaults to 5m.
+	Timeout string `json:"timeout,omitempty"`
+	// FailurePolicy is what happens when the hook fails: fail stops the apply,
+	// and ignore only logs the failure. Defaults to fail.
+	FailurePolicy string `json:"failurePolicy,omitempty"`
+}
+
+// NamespacePolicyConfig is the specification for how the namespaces of an
+// environment's objects are handled before they are applied.
+type NamespacePolicyConfig struct {
+	// Policy is create to create namespaces which do not exist, require to
+	// fail the apply if any do not exist, or fail to fail the apply if
+	// objects use namespaces other than the environment's.
+	Policy string `json:"policy"`
+	// Labels are set on the namespaces which are created.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// ApplySettingsConfig is the specification for how fast an environment's
+// objects are applied.
+type ApplySettingsConfig struct {
+	// Parallelism is how many objects of the same dependency tier are
+	// applied concurrently.
+	Parallelism int `json:"parallelism,omitempty"`
+	// QPS is the maximum queries per second sent to the Kubernetes API.
+	QPS float32 `json:"qps,omitempty"`
+	// Burst is the maximum burst of queries sent to the Kubernetes API.
+	Burst int `json:"burst,omitempty"`
+	// BatchSize is how many objects of a dependency tier are applied before
+	// the next objects are started. Zero applies a tier in one batch.
+	BatchSize int `json:"batchSize,omitempty"`
+}
+
+// DiffIgnoreConfig is the specification for differences which are left out
+// of diffs, such as fields managed by controllers in the cluster. The rule
+// applies to objects which match all of its selectors; empty selectors match
+// every object. Objects which match a rule without paths are left out
+// entirely.
+type DiffIgnoreConfig struct {
+	// Group is the API group of the objects, e.g. apps.
+	Group string `json:"group,omitempty"`
+	// Kind is the kind of the objects, e.g. HorizontalPodAutoscaler.
+	Kind string `json:"kind,omitempty"`
+	// Namespace is the namespace of the objects.
+	Namespace string `json:"namespace,omitempty"`
+	// Name is the name of the objects.
+	Name string `json:"name,omitempty"`
+	// Component is the component the objects are created from.
+	Component string `json:"component,omitempty"`
+	// JSONPointers are JSON6902 paths of fields to ignore, e.g. /spec/replicas.
+	JSONPointers []string `json:"jsonPointers,omitempty"`
+	// JSONPaths are JSONPath expressions of fields to ignore, e.g.
+	// .spec.template.spec.containers[?(@.name=="istio-proxy")].
+	JSONPaths []string `json:"jsonPaths,omitempty"`
+}
+
+// RedactConfig is the specification for fields whose values are redacted
+// when objects are shown or diffed. The rule applies to objects which match
+// all of its selectors; empty selectors match every object.
+type RedactConfig struct {
+	// Group is the API group of the objects, e.g. apps.
+	Group string `json:"group,omitempty"`
+	// Kind is the kind of the objects, e.g. ConfigMap.
+	Kind string `json:"kind,omitempty"`
+	// JSONPointers are JSON6902 paths of fields to redact. A * segment
+	// selects every member of an object or array, e.g.
+	// /spec/template/spec/containers/*/env/*/value.
+	JSONPointers []string `json:"jsonPointers"`
+}
+
+// WebhookConfig is the specification for a validating admission webhook that
+// an environment's manifests are sent to before they are applied. Objects
+// are sent in an AdmissionReview, as the API server would send them, so
+// rejections are caught before the cluster is changed.
+type WebhookConfig struct {
+	// Name is the name of the webhook. It is reported with rejections.
+	Name string `json:"name"`
+	// URL is the endpoint of the webhook, e.g.
+	// https://policy.example.com/validate.
+	URL string `json:"url"`
+	// CABundle is the path of a PEM file, relative to the app root, with the
+	// certificate authorities that verify the webhook. Defaults to the
+	// system's certificate authorities.
+	CABundle string `json:"caBundle,omitempty"`
+	// Kinds limits the webhook to objects of these kinds. By default,
+	// every object is sent.
+	Kinds []string `json:"kinds,omitempty"`
+	// Timeout is how long to wait for the webhook, e.g. 5s. Defaults to 10s.
+	Timeout string `json:"timeout,omitempty"`
+	// FailurePolicy is what happens when the webhook can not be reached:
+	// fail stops the apply, and ignore only logs the failure. Defaults to
+	// fail.
+	FailurePolicy string `json:"failurePolicy,omitempty"`
+}
+
+// BudgetConfig is the specification for limits on an environment's rendered
+// objects. Sizes are quantities of bytes of the objects' JSON, e.g. 512Ki.
+// Limits which are not set are not checked.
+type BudgetConfig struct {
+	// MaxObjects is the maximum number of objects.
+	MaxObjects int `json:"maxObjects,omitempty"`
+	// MaxObjectSize is the maximum size of a single object.
+	MaxObjectSize string `json:"maxObjectSize,omitempty"`
+	// MaxTotalSize is the maximum size of all objects.
+	MaxTotalSize string `json:"maxTotalSize,omitempty"`
+	// Level is the level of exceeded limits. Set it to warn to report them
+	// without failing. Defaults to deny.
+	Level string `json:"level,omitempty"`
+}
+
+// SecretsConfig is the specification for converting the Secrets an
+// environment renders into objects which don't hold their data.
+type SecretsConfig struct {
+	// Transform is sealed to convert Secrets into SealedSecrets, or
+	// external to convert them into ExternalSecrets.
+	Transform string `json:"transform"`
+	// Certificate is the path, relative to the app root, of the PEM
+	// certificate of the cluster's sealed secrets controller. Defaults to
+	// sealed-secrets.pem in the environment's directory.
+	Certificate string `json:"certificate,omitempty"`
+	// Store is the name of the secret store ExternalSecrets read from.
+	Store string `json:"store,omitempty"`
+	// StoreKind is the kind of the store. Defaults to SecretStore.
+	StoreKind string `json:"storeKind,omitempty"`
+	// KeyPrefix is prepended to the name of a Secret to create the key of
+	// the ExternalSecret's data in the store.
+	KeyPrefix string `json:"keyPrefix,omitempty"`
+	// RefreshInterval is how often ExternalSecrets are refreshed from the
+	// store. Defaults to 1h.
+	RefreshInterval string `json:"refreshInterval,omitempty"`
+}
+
 // LibraryConfig is the specification for a library part.
 type LibraryConfig struct {
 	Name     string `json:"name"`
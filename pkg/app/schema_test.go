@@ -462,6 +462,37 @@ func TestEnvironmentSpec_MakePath(t *testing.T) {
 	require.Equal(t, expected, got)
 }
 
+func TestEnvironmentConfig_PrimaryDestination(t *testing.T) {
+	us := &EnvironmentDestinationSpec{Name: "us", Namespace: "prod", Server: "http://us.example.com"}
+	eu := &EnvironmentDestinationSpec{Name: "eu", Namespace: "prod", Server: "http://eu.example.com"}
+
+	cases := []struct {
+		name     string
+		env      EnvironmentConfig
+		expected EnvironmentDestinationSpec
+	}{
+		{
+			name:     "destination",
+			env:      EnvironmentConfig{Destination: eu, Destinations: []*EnvironmentDestinationSpec{us}},
+			expected: *eu,
+		},
+		{
+			name:     "only destinations",
+			env:      EnvironmentConfig{Destinations: []*EnvironmentDestinationSpec{nil, us, eu}},
+			expected: *us,
+		},
+		{
+			name: "no destinations",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, tc.env.PrimaryDestination())
+		})
+	}
+}
+
 // Test that RegistryConfigs are properly deserialized, specifically
 // their Name fields, which are handler by custom UnmarshalJSON code.
 func TestUnmarshalRegistryConfigs(t *testing.T) {
@@ -0,0 +1,38 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package app
+
+// untrustedApp is an app whose contents are not trusted.
+type untrustedApp struct {
+	App
+}
+
+// Untrusted marks an app whose contents are not trusted, such as an app a
+// server checks out from git. Untrusted apps are evaluated without importing
+// jsonnet from URLs, or reading files into generated objects.
+func Untrusted(a App) App {
+	if IsUntrusted(a) {
+		return a
+	}
+
+	return &untrustedApp{App: a}
+}
+
+// IsUntrusted reports if an app was marked by Untrusted.
+func IsUntrusted(a App) bool {
+	_, ok := a.(*untrustedApp)
+	return ok
+}
@@ -0,0 +1,35 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package app
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUntrusted(t *testing.T) {
+	a := NewApp010(afero.NewMemMapFs(), "/app", nil)
+	assert.False(t, IsUntrusted(a))
+
+	untrusted := Untrusted(a)
+	assert.True(t, IsUntrusted(untrusted))
+	assert.Equal(t, "/app", untrusted.Root())
+
+	// Marking an app again doesn't wrap it again.
+	assert.Equal(t, untrusted, Untrusted(untrusted))
+}
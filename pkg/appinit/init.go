@@ -235,6 +235,7 @@ func (i *initApp) createAppDirTree() error {
 
 var ignoreData = []byte(`/lib
 /.ksonnet/registries
+/.ksonnet/packages
 /app.override.yaml
 /.ks_environment
 `)
@@ -0,0 +1,45 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package apptest
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/pipeline"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// PipelineRenderer evaluates test files and renders components with the ks
+// pipeline, so tests see the objects `ks show` would print.
+type PipelineRenderer struct {
+	app app.App
+}
+
+var _ Renderer = (*PipelineRenderer)(nil)
+
+// NewPipelineRenderer creates an instance of PipelineRenderer.
+func NewPipelineRenderer(a app.App) *PipelineRenderer {
+	return &PipelineRenderer{app: a}
+}
+
+// Evaluate evaluates a test file with the environment's parameters.
+func (pr *PipelineRenderer) Evaluate(envName, filename, snippet string) (string, error) {
+	return pipeline.New(pr.app, envName).EvaluateSnippet(filename, snippet)
+}
+
+// Objects renders components in an environment.
+func (pr *PipelineRenderer) Objects(envName string, components []string) ([]*unstructured.Unstructured, error) {
+	return pipeline.New(pr.app, envName).Objects(components)
+}
@@ -0,0 +1,216 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package apptest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Report is the result of running tests.
+type Report struct {
+	Suites []SuiteResult
+}
+
+// SuiteResult is the result of running a test file in an environment.
+type SuiteResult struct {
+	File        string
+	Environment string
+	// Error is set if the suite could not be run.
+	Error string
+	Cases []CaseResult
+}
+
+// Name is the name of the suite.
+func (sr *SuiteResult) Name() string {
+	return fmt.Sprintf("%s [%s]", sr.File, sr.Environment)
+}
+
+// CaseResult is the result of a test case.
+type CaseResult struct {
+	Name string
+	// Failure is set if the test failed.
+	Failure string
+	// Updated is true if the test updated a snapshot.
+	Updated  bool
+	Duration time.Duration
+}
+
+// Tests returns the number of tests. A suite which could not be run counts
+// as one test.
+func (r *Report) Tests() int {
+	count := 0
+	for _, sr := range r.Suites {
+		if sr.Error != "" {
+			count++
+			continue
+		}
+		count += len(sr.Cases)
+	}
+
+	return count
+}
+
+// Failures returns the number of failed tests.
+func (r *Report) Failures() int {
+	count := 0
+	for _, sr := range r.Suites {
+		if sr.Error != "" {
+			count++
+			continue
+		}
+		for _, cr := range sr.Cases {
+			if cr.Failure != "" {
+				count++
+			}
+		}
+	}
+
+	return count
+}
+
+// Err returns an error if tests failed.
+func (r *Report) Err() error {
+	if failures := r.Failures(); failures > 0 {
+		return errors.Errorf("%d of %d tests failed", failures, r.Tests())
+	}
+
+	return nil
+}
+
+// Render writes the result of each test.
+func (r *Report) Render(w io.Writer) error {
+	for _, sr := range r.Suites {
+		if sr.Error != "" {
+			fmt.Fprintf(w, "FAIL %s\n%s\n", sr.Name(), indent(sr.Error))
+			continue
+		}
+
+		for _, cr := range sr.Cases {
+			switch {
+			case cr.Failure != "":
+				fmt.Fprintf(w, "FAIL %s %s\n%s\n", sr.Name(), cr.Name, indent(cr.Failure))
+			case cr.Updated:
+				fmt.Fprintf(w, "ok   %s %s (snapshot updated)\n", sr.Name(), cr.Name)
+			default:
+				fmt.Fprintf(w, "ok   %s %s\n", sr.Name(), cr.Name)
+			}
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "\n%d tests, %d failed\n", r.Tests(), r.Failures())
+	return err
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+}
+
+type junitMessage struct {
+	Message  string `xml:"message,attr"`
+	Contents string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report as JUnit XML.
+func (r *Report) WriteJUnit(w io.Writer) error {
+	var suites junitTestSuites
+
+	for _, sr := range r.Suites {
+		suite := junitTestSuite{Name: sr.Name()}
+
+		if sr.Error != "" {
+			suite.Tests = 1
+			suite.Errors = 1
+			suite.Time = seconds(0)
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name:      sr.File,
+				ClassName: sr.Name(),
+				Time:      seconds(0),
+				Error:     &junitMessage{Message: firstLine(sr.Error), Contents: sr.Error},
+			})
+			suites.Suites = append(suites.Suites, suite)
+			continue
+		}
+
+		var total time.Duration
+		for _, cr := range sr.Cases {
+			tc := junitTestCase{
+				Name:      cr.Name,
+				ClassName: sr.Name(),
+				Time:      seconds(cr.Duration),
+			}
+			if cr.Failure != "" {
+				suite.Failures++
+				tc.Failure = &junitMessage{Message: firstLine(cr.Failure), Contents: cr.Failure}
+			}
+
+			total += cr.Duration
+			suite.Cases = append(suite.Cases, tc)
+		}
+		suite.Tests = len(sr.Cases)
+		suite.Time = seconds(total)
+
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+func firstLine(s string) string {
+	return strings.SplitN(s, "\n", 2)[0]
+}
+
+func indent(s string) string {
+	return "    " + strings.Replace(s, "\n", "\n    ", -1)
+}
@@ -0,0 +1,87 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package apptest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleReport() *Report {
+	return &Report{
+		Suites: []SuiteResult{
+			{
+				File:        "tests/guestbook_test.jsonnet",
+				Environment: "default",
+				Cases: []CaseResult{
+					{Name: "deployment exists", Duration: 1500 * time.Millisecond},
+					{Name: "replicas", Failure: "Deployment guestbook-ui: spec.replicas is 3; expected 1\nmore"},
+					{Name: "snapshot", Updated: true},
+				},
+			},
+			{
+				File:        "tests/invalid_test.jsonnet",
+				Environment: "default",
+				Error:       "RUNTIME ERROR: invalid",
+			},
+		},
+	}
+}
+
+func TestReport_Render(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, sampleReport().Render(&buf))
+
+	expected := `ok   tests/guestbook_test.jsonnet [default] deployment exists
+FAIL tests/guestbook_test.jsonnet [default] replicas
+    Deployment guestbook-ui: spec.replicas is 3; expected 1
+    more
+ok   tests/guestbook_test.jsonnet [default] snapshot (snapshot updated)
+FAIL tests/invalid_test.jsonnet [default]
+    RUNTIME ERROR: invalid
+
+4 tests, 2 failed
+`
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestReport_WriteJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, sampleReport().WriteJUnit(&buf))
+
+	var got junitTestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &got))
+
+	require.Len(t, got.Suites, 2)
+
+	suite := got.Suites[0]
+	assert.Equal(t, "tests/guestbook_test.jsonnet [default]", suite.Name)
+	assert.Equal(t, 3, suite.Tests)
+	assert.Equal(t, 1, suite.Failures)
+	assert.Equal(t, "1.500", suite.Time)
+	require.NotNil(t, suite.Cases[1].Failure)
+	assert.Equal(t, "Deployment guestbook-ui: spec.replicas is 3; expected 1", suite.Cases[1].Failure.Message)
+
+	suite = got.Suites[1]
+	assert.Equal(t, 1, suite.Errors)
+	require.NotNil(t, suite.Cases[0].Error)
+	assert.Equal(t, "RUNTIME ERROR: invalid", suite.Cases[0].Error.Message)
+}
@@ -0,0 +1,291 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package apptest
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	gostrings "strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/util/strings"
+	"github.com/pkg/errors"
+	godiff "github.com/shazow/go-diff"
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// TestsDir is the directory in an app which contains test files.
+	TestsDir = "tests"
+	// SnapshotsDir is the directory in TestsDir which contains snapshots.
+	SnapshotsDir = "__snapshots__"
+
+	testFileSuffix = "_test.jsonnet"
+)
+
+var reSnapshotName = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Renderer evaluates jsonnet and renders components in an environment.
+type Renderer interface {
+	// Evaluate evaluates a test file.
+	Evaluate(envName, filename, snippet string) (string, error)
+	// Objects renders components. Every component is rendered if components
+	// is empty.
+	Objects(envName string, components []string) ([]*unstructured.Unstructured, error)
+}
+
+// Runner runs the tests in an app's tests directory.
+type Runner struct {
+	fs       afero.Fs
+	root     string
+	envNames []string
+	renderer Renderer
+	update   bool
+
+	objects map[string][]*unstructured.Unstructured
+}
+
+// NewRunner creates an instance of Runner for the app in root. Tests are run
+// in each of envNames. If update is true, snapshots are written instead of
+// being compared with objects.
+func NewRunner(fs afero.Fs, root string, envNames []string, renderer Renderer, update bool) *Runner {
+	return &Runner{
+		fs:       fs,
+		root:     root,
+		envNames: envNames,
+		renderer: renderer,
+		update:   update,
+		objects:  make(map[string][]*unstructured.Unstructured),
+	}
+}
+
+// Run runs the tests.
+func (r *Runner) Run() (*Report, error) {
+	paths, err := r.files()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+
+	for _, path := range paths {
+		rel, err := filepath.Rel(r.root, path)
+		if err != nil {
+			return nil, err
+		}
+
+		b, err := afero.ReadFile(r.fs, path)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, envName := range r.envNames {
+			result, ok := r.runSuite(path, rel, envName, string(b))
+			if ok {
+				report.Suites = append(report.Suites, result)
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// runSuite runs a test file in an environment. It returns false if the
+// suite does not run in the environment.
+func (r *Runner) runSuite(path, rel, envName, snippet string) (SuiteResult, bool) {
+	result := SuiteResult{File: rel, Environment: envName}
+
+	evaluated, err := r.renderer.Evaluate(envName, path, snippet)
+	if err != nil {
+		result.Error = err.Error()
+		return result, true
+	}
+
+	var suite Suite
+	if err = json.Unmarshal([]byte(evaluated), &suite); err != nil {
+		result.Error = errors.Wrap(err, "test file is not a test suite").Error()
+		return result, true
+	}
+
+	if len(suite.Environments) > 0 && !strings.InSlice(envName, suite.Environments) {
+		return result, false
+	}
+
+	objects, err := r.render(envName, suite.Components)
+	if err != nil {
+		result.Error = err.Error()
+		return result, true
+	}
+
+	for _, c := range suite.Tests {
+		start := time.Now()
+		updated, err := r.check(rel, envName, c, objects)
+
+		cr := CaseResult{
+			Name:     c.Name,
+			Updated:  updated,
+			Duration: time.Since(start),
+		}
+		if err != nil {
+			cr.Failure = err.Error()
+		}
+
+		result.Cases = append(result.Cases, cr)
+	}
+
+	return result, true
+}
+
+// render renders components, caching the objects for suites which render
+// the same components in the same environment.
+func (r *Runner) render(envName string, components []string) ([]*unstructured.Unstructured, error) {
+	key := envName + "\x00" + gostrings.Join(components, ",")
+	if objects, ok := r.objects[key]; ok {
+		return objects, nil
+	}
+
+	objects, err := r.renderer.Objects(envName, components)
+	if err != nil {
+		return nil, err
+	}
+
+	r.objects[key] = objects
+	return objects, nil
+}
+
+// check checks a test case. It returns true if the case updated a snapshot.
+func (r *Runner) check(rel, envName string, c Case, objects []*unstructured.Unstructured) (bool, error) {
+	assertions := 0
+	for _, set := range []bool{c.Exists != nil, c.Equals != nil, c.Snapshot != nil} {
+		if set {
+			assertions++
+		}
+	}
+	if assertions != 1 {
+		return false, errors.New("test must have one of exists, equals, or snapshot")
+	}
+
+	switch {
+	case c.Exists != nil:
+		_, err := c.Exists.find(objects)
+		return false, err
+	case c.Equals != nil:
+		return false, c.Equals.check(objects)
+	default:
+		return r.checkSnapshot(rel, envName, c, objects)
+	}
+}
+
+func (r *Runner) checkSnapshot(rel, envName string, c Case, objects []*unstructured.Unstructured) (bool, error) {
+	o, err := c.Snapshot.find(objects)
+	if err != nil {
+		return false, err
+	}
+
+	actual, err := yaml.Marshal(o.Object)
+	if err != nil {
+		return false, err
+	}
+
+	path := r.snapshotPath(rel, envName, c.Name)
+	snapshotRel, err := filepath.Rel(r.root, path)
+	if err != nil {
+		return false, err
+	}
+
+	if r.update {
+		if err = r.fs.MkdirAll(filepath.Dir(path), app.DefaultFolderPermissions); err != nil {
+			return false, err
+		}
+		if err = afero.WriteFile(r.fs, path, actual, app.DefaultFilePermissions); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	expected, err := afero.ReadFile(r.fs, path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, errors.Errorf("snapshot %s does not exist; run `ks test --update` to create it", snapshotRel)
+		}
+		return false, err
+	}
+
+	if bytes.Equal(expected, actual) {
+		return false, nil
+	}
+
+	var buf bytes.Buffer
+	if err = godiff.DefaultDiffer().Diff(&buf, bytes.NewReader(expected), bytes.NewReader(actual)); err != nil {
+		return false, err
+	}
+
+	return false, errors.Errorf("%s does not match snapshot %s; run `ks test --update` to update it\n%s",
+		c.Snapshot, snapshotRel, gostrings.TrimRight(buf.String(), "\n"))
+}
+
+// snapshotPath returns the path of a snapshot, e.g.
+// `tests/__snapshots__/guestbook/default/service.yaml` for the test case
+// "service" in tests/guestbook_test.jsonnet.
+func (r *Runner) snapshotPath(rel, envName, name string) string {
+	testsRel, err := filepath.Rel(TestsDir, rel)
+	if err != nil {
+		testsRel = rel
+	}
+	suite := gostrings.TrimSuffix(testsRel, testFileSuffix)
+
+	snapshot := gostrings.Trim(reSnapshotName.ReplaceAllString(gostrings.ToLower(name), "-"), "-")
+
+	return filepath.Join(r.root, TestsDir, SnapshotsDir, suite, envName, snapshot+".yaml")
+}
+
+// files returns the test files in the tests directory.
+func (r *Runner) files() ([]string, error) {
+	dir := filepath.Join(r.root, TestsDir)
+
+	var paths []string
+	err := afero.Walk(r.fs, dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		if fi.IsDir() {
+			if fi.Name() == SnapshotsDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if gostrings.HasSuffix(path, testFileSuffix) {
+			paths = append(paths, path)
+		}
+
+		return nil
+	})
+
+	sort.Strings(paths)
+	return paths, err
+}
@@ -0,0 +1,180 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package apptest
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// fakeRenderer evaluates test files which are JSON, and renders a
+// deployment whose replicas depend on the environment.
+type fakeRenderer struct {
+	rendered []string
+}
+
+func (fr *fakeRenderer) Evaluate(envName, filename, snippet string) (string, error) {
+	if snippet == "invalid" {
+		return "", errors.New("RUNTIME ERROR: invalid")
+	}
+	return snippet, nil
+}
+
+func (fr *fakeRenderer) Objects(envName string, components []string) ([]*unstructured.Unstructured, error) {
+	fr.rendered = append(fr.rendered, envName)
+
+	replicas := int64(1)
+	if envName == "prod" {
+		replicas = 3
+	}
+
+	return []*unstructured.Unstructured{
+		{
+			Object: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata": map[string]interface{}{
+					"name":      "guestbook-ui",
+					"namespace": "default",
+				},
+				"spec": map[string]interface{}{
+					"replicas": replicas,
+					"template": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"containers": []interface{}{
+								map[string]interface{}{"image": "nginx"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+const guestbookTest = `{
+  "tests": [
+    {"name": "deployment exists", "exists": {"kind": "Deployment", "name": "guestbook-ui"}},
+    {"name": "service exists", "exists": {"kind": "Service", "name": "guestbook-ui"}},
+    {"name": "replicas", "equals": {"kind": "Deployment", "name": "guestbook-ui", "path": "spec.replicas", "value": 1}},
+    {"name": "image", "equals": {"kind": "Deployment", "name": "guestbook-ui", "namespace": "default", "path": "spec.template.spec.containers.0.image", "value": "nginx"}},
+    {"name": "Deployment snapshot", "snapshot": {"kind": "Deployment", "name": "guestbook-ui"}},
+    {"name": "no assertion"}
+  ]
+}`
+
+func stageTests(t *testing.T) afero.Fs {
+	fs := afero.NewMemMapFs()
+
+	files := map[string]string{
+		"/app/tests/guestbook_test.jsonnet":       guestbookTest,
+		"/app/tests/prod/only_prod_test.jsonnet":  `{"environments": ["prod"], "tests": [{"name": "replicas", "equals": {"kind": "Deployment", "name": "guestbook-ui", "path": "spec.replicas", "value": 3}}]}`,
+		"/app/tests/invalid_test.jsonnet":         "invalid",
+		"/app/tests/helpers.libsonnet":            "{}",
+		"/app/tests/__snapshots__/x_test.jsonnet": "invalid",
+	}
+
+	for path, content := range files {
+		require.NoError(t, afero.WriteFile(fs, path, []byte(content), 0644))
+	}
+
+	return fs
+}
+
+func caseResults(sr SuiteResult) map[string]string {
+	m := make(map[string]string)
+	for _, cr := range sr.Cases {
+		m[cr.Name] = cr.Failure
+	}
+	return m
+}
+
+func TestRunner_Run(t *testing.T) {
+	fs := stageTests(t)
+	renderer := &fakeRenderer{}
+
+	r := NewRunner(fs, "/app", []string{"default", "prod"}, renderer, false)
+	report, err := r.Run()
+	require.NoError(t, err)
+
+	var names []string
+	for _, sr := range report.Suites {
+		names = append(names, sr.Name())
+	}
+	require.Equal(t, []string{
+		"tests/guestbook_test.jsonnet [default]",
+		"tests/guestbook_test.jsonnet [prod]",
+		"tests/invalid_test.jsonnet [default]",
+		"tests/invalid_test.jsonnet [prod]",
+		"tests/prod/only_prod_test.jsonnet [prod]",
+	}, names)
+
+	// objects are rendered once per environment.
+	assert.Equal(t, []string{"default", "prod"}, renderer.rendered)
+
+	results := caseResults(report.Suites[0])
+	assert.Empty(t, results["deployment exists"])
+	assert.Equal(t, "Service guestbook-ui was not rendered", results["service exists"])
+	assert.Empty(t, results["replicas"])
+	assert.Empty(t, results["image"])
+	assert.Equal(t, "snapshot tests/__snapshots__/guestbook/default/deployment-snapshot.yaml does not exist; run `ks test --update` to create it", results["Deployment snapshot"])
+	assert.Equal(t, "test must have one of exists, equals, or snapshot", results["no assertion"])
+
+	results = caseResults(report.Suites[1])
+	assert.Equal(t, "Deployment guestbook-ui: spec.replicas is 3; expected 1", results["replicas"])
+
+	assert.Equal(t, "RUNTIME ERROR: invalid", report.Suites[2].Error)
+	assert.Empty(t, caseResults(report.Suites[4])["replicas"])
+
+	assert.Equal(t, 15, report.Tests())
+	assert.Equal(t, 9, report.Failures())
+	assert.EqualError(t, report.Err(), "9 of 15 tests failed")
+}
+
+func TestRunner_Run_snapshots(t *testing.T) {
+	fs := stageTests(t)
+
+	r := NewRunner(fs, "/app", []string{"default"}, &fakeRenderer{}, true)
+	report, err := r.Run()
+	require.NoError(t, err)
+
+	assert.True(t, report.Suites[0].Cases[4].Updated)
+
+	b, err := afero.ReadFile(fs, "/app/tests/__snapshots__/guestbook/default/deployment-snapshot.yaml")
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "replicas: 1")
+
+	r = NewRunner(fs, "/app", []string{"default"}, &fakeRenderer{}, false)
+	report, err = r.Run()
+	require.NoError(t, err)
+	assert.Empty(t, report.Suites[0].Cases[4].Failure)
+
+	require.NoError(t, afero.WriteFile(fs, "/app/tests/__snapshots__/guestbook/default/deployment-snapshot.yaml",
+		[]byte(string(b)+"status: {}\n"), 0644))
+
+	r = NewRunner(fs, "/app", []string{"default"}, &fakeRenderer{}, false)
+	report, err = r.Run()
+	require.NoError(t, err)
+
+	failure := report.Suites[0].Cases[4].Failure
+	assert.Contains(t, failure, "Deployment guestbook-ui does not match snapshot tests/__snapshots__/guestbook/default/deployment-snapshot.yaml")
+	assert.Contains(t, failure, "-status: {}")
+}
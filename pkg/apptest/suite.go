@@ -0,0 +1,168 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package apptest runs tests which assert on the objects an app's components
+// render to in its environments.
+package apptest
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Suite is the result of evaluating a test file.
+type Suite struct {
+	// Environments are the environments the suite runs in. It runs in every
+	// environment if it is empty.
+	Environments []string `json:"environments"`
+	// Components are the components rendered for the suite. Every component
+	// is rendered if it is empty.
+	Components []string `json:"components"`
+	// Tests are the suite's test cases.
+	Tests []Case `json:"tests"`
+}
+
+// Case is a test case. It has one assertion.
+type Case struct {
+	Name string `json:"name"`
+	// Exists asserts an object is rendered.
+	Exists *Selector `json:"exists,omitempty"`
+	// Equals asserts a field of an object has a value.
+	Equals *Equals `json:"equals,omitempty"`
+	// Snapshot asserts an object matches its snapshot.
+	Snapshot *Selector `json:"snapshot,omitempty"`
+}
+
+// Selector selects an object by its kind, name, and, optionally, namespace.
+type Selector struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// Equals asserts the field at Path, e.g. `spec.template.spec.containers.0.image`,
+// equals Value.
+type Equals struct {
+	Selector
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+func (s *Selector) String() string {
+	if s.Namespace != "" {
+		return fmt.Sprintf("%s %s/%s", s.Kind, s.Namespace, s.Name)
+	}
+	return fmt.Sprintf("%s %s", s.Kind, s.Name)
+}
+
+// find returns the first object the selector matches.
+func (s *Selector) find(objects []*unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	for _, o := range objects {
+		if o.GetKind() != s.Kind || o.GetName() != s.Name {
+			continue
+		}
+		if s.Namespace != "" && o.GetNamespace() != s.Namespace {
+			continue
+		}
+		return o, nil
+	}
+
+	return nil, errors.Errorf("%s was not rendered", s)
+}
+
+// check checks the field equals the expected value.
+func (e *Equals) check(objects []*unstructured.Unstructured) error {
+	o, err := e.find(objects)
+	if err != nil {
+		return err
+	}
+
+	actual, err := field(o.Object, e.Path)
+	if err != nil {
+		return err
+	}
+
+	expected, err := normalize(e.Value)
+	if err != nil {
+		return err
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		return errors.Errorf("%s: %s is %s; expected %s", e.Selector.String(), e.Path, toJSON(actual), toJSON(expected))
+	}
+
+	return nil
+}
+
+// field returns the value of a field of an object. Path elements which are
+// numbers index arrays.
+func field(object map[string]interface{}, path string) (interface{}, error) {
+	normalized, err := normalize(object)
+	if err != nil {
+		return nil, err
+	}
+
+	var cur interface{} = normalized
+	for _, elem := range strings.Split(path, ".") {
+		switch t := cur.(type) {
+		case map[string]interface{}:
+			v, ok := t[elem]
+			if !ok {
+				return nil, errors.Errorf("field %s does not exist", path)
+			}
+			cur = v
+		case []interface{}:
+			i, err := strconv.Atoi(elem)
+			if err != nil || i < 0 || i >= len(t) {
+				return nil, errors.Errorf("field %s does not exist", path)
+			}
+			cur = t[i]
+		default:
+			return nil, errors.Errorf("field %s does not exist", path)
+		}
+	}
+
+	return cur, nil
+}
+
+// normalize converts a value to the types decoded from JSON, so values from
+// objects and test files can be compared.
+func normalize(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var out interface{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func toJSON(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
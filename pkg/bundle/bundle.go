@@ -0,0 +1,209 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package bundle packs an app into a single archive, which can be rendered
+// and applied without the app's source or access to its registries.
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/util/archive"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+// contents are the files and directories of an app which are bundled. Local
+// overrides and the current environment are left out.
+var contents = []string{
+	"app.yaml",
+	"components",
+	app.EnvironmentDirName,
+	app.LibDirName,
+	"vendor",
+}
+
+// extractedName is the file which marks a bundle as extracted.
+const extractedName = ".ks_bundle"
+
+// Write writes a bundle of the app in root to w. Bundles are gzip'd tar
+// archives. Their entries are sorted and have no timestamps, so bundling
+// the same app twice writes the same bundle.
+func Write(fs afero.Fs, root string, w io.Writer) error {
+	ok, err := afero.Exists(fs, filepath.Join(root, "app.yaml"))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.Errorf("%s is not a ksonnet app", root)
+	}
+
+	gzw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gzw)
+
+	for _, name := range contents {
+		path := filepath.Join(root, name)
+
+		ok, err := afero.Exists(fs, path)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		err = afero.Walk(fs, path, func(path string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if !fi.Mode().IsRegular() {
+				return nil
+			}
+
+			return writeFile(fs, tw, root, path, fi)
+		})
+		if err != nil {
+			return errors.Wrapf(err, "bundling %s", name)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return gzw.Close()
+}
+
+func writeFile(fs afero.Fs, tw *tar.Writer, root, path string, fi os.FileInfo) error {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return err
+	}
+
+	b, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return err
+	}
+
+	header := &tar.Header{
+		Name:     filepath.ToSlash(rel),
+		Typeflag: tar.TypeReg,
+		Mode:     int64(app.DefaultFilePermissions),
+		Size:     int64(len(b)),
+	}
+
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+
+	_, err = tw.Write(b)
+	return err
+}
+
+// Extract extracts the bundle in r into dir.
+func Extract(fs afero.Fs, r io.Reader, dir string) error {
+	handler := func(f *archive.File) error {
+		path, err := within(dir, f.Name)
+		if err != nil {
+			return err
+		}
+
+		if err := fs.MkdirAll(filepath.Dir(path), app.DefaultFolderPermissions); err != nil {
+			return err
+		}
+
+		b, err := ioutil.ReadAll(f.Reader)
+		if err != nil {
+			return err
+		}
+
+		return afero.WriteFile(fs, path, b, app.DefaultFilePermissions)
+	}
+
+	if err := (&archive.Tgz{}).Unarchive(r, handler); err != nil {
+		return errors.Wrap(err, "extracting bundle")
+	}
+
+	ok, err := afero.Exists(fs, filepath.Join(dir, "app.yaml"))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("bundle does not contain a ksonnet app")
+	}
+
+	return nil
+}
+
+// within joins the name of a bundled file to dir, and returns an error if it
+// is outside of dir.
+func within(dir, name string) (string, error) {
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", errors.Errorf("bundled file %q is outside of the app", name)
+	}
+
+	return path, nil
+}
+
+// Open extracts the bundle at path, and returns the directory of its app.
+// Bundles are extracted into a temporary directory named after their
+// content, so commands run from the same bundle share one extracted copy.
+func Open(fs afero.Fs, path string) (string, error) {
+	b, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return "", errors.Wrap(err, "reading bundle")
+	}
+
+	sum := sha256.Sum256(b)
+	dir := filepath.Join(os.TempDir(), fmt.Sprintf("ks-bundle-%x", sum[:8]))
+	marker := filepath.Join(dir, extractedName)
+
+	ok, err := afero.Exists(fs, marker)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return dir, nil
+	}
+
+	// The marker is only written once the bundle is fully extracted, so an
+	// interrupted extraction is started over.
+	if err := fs.RemoveAll(dir); err != nil {
+		return "", err
+	}
+
+	if err := Extract(fs, bytes.NewReader(b), dir); err != nil {
+		return "", errors.Wrapf(err, "opening bundle %s", path)
+	}
+
+	if err := afero.WriteFile(fs, marker, []byte(path), app.DefaultFilePermissions); err != nil {
+		return "", err
+	}
+
+	return dir, nil
+}
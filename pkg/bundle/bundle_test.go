@@ -0,0 +1,150 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var appFiles = map[string]string{
+	"app.yaml":                                  "apiVersion: 0.3.0",
+	"components/params.libsonnet":               "{}",
+	"components/web.jsonnet":                    "{}",
+	"environments/default/main.jsonnet":         "{}",
+	"lib/ksonnet-lib/v1.10.3/k8s.libsonnet":     "{}",
+	"vendor/incubator/redis/redis.libsonnet":    "{}",
+	"app.override.yaml":                         "environments: {}",
+	".ks_environment":                           "default",
+	"snapshots/default/web/deployment-web.yaml": "kind: Deployment",
+}
+
+func stageApp(t *testing.T, fs afero.Fs, root string) {
+	for name, content := range appFiles {
+		require.NoError(t, afero.WriteFile(fs, root+"/"+name, []byte(content), 0644))
+	}
+}
+
+func TestWrite_Extract(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	stageApp(t, fs, "/app")
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(fs, "/app", &buf))
+
+	var again bytes.Buffer
+	require.NoError(t, Write(fs, "/app", &again))
+	assert.Equal(t, buf.Bytes(), again.Bytes(), "bundles are reproducible")
+
+	require.NoError(t, Extract(fs, &buf, "/extracted"))
+
+	for name, content := range appFiles {
+		b, err := afero.ReadFile(fs, "/extracted/"+name)
+		switch name {
+		case "app.override.yaml", ".ks_environment", "snapshots/default/web/deployment-web.yaml":
+			assert.Error(t, err, name)
+		default:
+			require.NoError(t, err, name)
+			assert.Equal(t, content, string(b), name)
+		}
+	}
+}
+
+func TestWrite_not_an_app(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	var buf bytes.Buffer
+	require.Error(t, Write(fs, "/app", &buf))
+}
+
+func tgz(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Typeflag: tar.TypeReg,
+			Mode:     0644,
+			Size:     int64(len(content)),
+		}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+	require.NoError(t, gzw.Close())
+	return buf.Bytes()
+}
+
+func TestExtract_invalid(t *testing.T) {
+	cases := []struct {
+		name  string
+		files map[string]string
+	}{
+		{
+			name:  "outside of the app",
+			files: map[string]string{"app.yaml": "", "../evil": ""},
+		},
+		{
+			name:  "not an app",
+			files: map[string]string{"components/web.jsonnet": "{}"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+
+			err := Extract(fs, bytes.NewReader(tgz(t, tc.files)), "/extracted")
+			require.Error(t, err)
+
+			ok, err := afero.Exists(fs, "/evil")
+			require.NoError(t, err)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestOpen(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	stageApp(t, fs, "/app")
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(fs, "/app", &buf))
+	require.NoError(t, afero.WriteFile(fs, "/app.tgz", buf.Bytes(), 0644))
+
+	dir, err := Open(fs, "/app.tgz")
+	require.NoError(t, err)
+
+	b, err := afero.ReadFile(fs, dir+"/components/web.jsonnet")
+	require.NoError(t, err)
+	assert.Equal(t, "{}", string(b))
+
+	again, err := Open(fs, "/app.tgz")
+	require.NoError(t, err)
+	assert.Equal(t, dir, again)
+
+	_, err = Open(fs, "/missing.tgz")
+	require.Error(t, err)
+}
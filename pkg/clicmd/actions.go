@@ -18,38 +18,67 @@ package clicmd
 import (
 	"github.com/ksonnet/ksonnet/pkg/actions"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 )
 
 type initName int
 
 const (
-	actionApply initName = iota
+	actionAPIDump initName = iota
+	actionApply
+	actionBundle
+	actionComplete
 	actionComponentList
 	actionComponentRm
 	actionDelete
+	actionDev
 	actionDiff
+	actionDoctor
 	actionEnvAdd
 	actionEnvCurrent
 	actionEnvDescribe
 	actionEnvList
+	actionEnvRegenerate
 	actionEnvRm
 	actionEnvSet
 	actionEnvTargets
 	actionEnvUpdate
+	actionEnvUpdateCRDLib
+	actionEnvUpdateLib
+	actionEval
+	actionExplain
+	actionExport
+	actionFmt
+	actionGraph
+	actionImageList
+	actionImageOutdated
+	actionImagePin
+	actionImageSet
 	actionImport
 	actionInit
+	actionInventory
+	actionJbSync
+	actionLint
+	actionLogs
 	actionModuleCreate
+	actionModuleDescribe
 	actionModuleList
 	actionParamDelete
 	actionParamDiff
+	actionParamHistory
 	actionParamList
 	actionParamSet
 	actionParamUnset
 	actionPkgDescribe
+	actionPkgDiff
 	actionPkgInstall
 	actionPkgList
 	actionPkgRemove
+	actionPluginRun
+	actionPortForward
+	actionPrototypeCreate
 	actionPrototypeDescribe
+	actionPrototypeLint
 	actionPrototypeList
 	actionPrototypePreview
 	actionPrototypeSearch
@@ -58,7 +87,15 @@ const (
 	actionRegistryDescribe
 	actionRegistryList
 	actionRegistrySet
+	actionRegistryStats
+	actionRenderForArgoCD
 	actionShow
+	actionSnapshotRecord
+	actionSnapshotVerify
+	actionStatus
+	actionTest
+	actionToolJsonnetPaths
+	actionUI
 	actionUpgrade
 	actionValidate
 )
@@ -67,42 +104,78 @@ type actionFn func(map[string]interface{}) error
 
 var (
 	actionFns = map[initName]actionFn{
+		actionAPIDump:           actions.RunAPIDump,
 		actionApply:             actions.RunApply,
+		actionBundle:            actions.RunBundle,
+		actionComplete:          actions.RunComplete,
 		actionComponentList:     actions.RunComponentList,
-		actionComponentRm:       actions.RunComponentRm,
+		actionComponentRm:       actions.WithDryRun(actions.RunComponentRm),
 		actionDelete:            actions.RunDelete,
+		actionDev:               actions.RunDev,
 		actionDiff:              actions.RunDiff,
+		actionDoctor:            actions.RunDoctor,
 		actionEnvAdd:            actions.RunEnvAdd,
 		actionEnvCurrent:        actions.RunEnvCurrent,
 		actionEnvDescribe:       actions.RunEnvDescribe,
 		actionEnvList:           actions.RunEnvList,
+		actionEnvRegenerate:     actions.RunEnvRegenerate,
 		actionEnvRm:             actions.RunEnvRm,
-		actionEnvSet:            actions.RunEnvSet,
+		actionEnvSet:            actions.WithDryRun(actions.RunEnvSet),
 		actionEnvTargets:        actions.RunEnvTargets,
 		actionEnvUpdate:         actions.RunEnvUpdate,
+		actionEnvUpdateCRDLib:   actions.RunEnvUpdateCRDLib,
+		actionEnvUpdateLib:      actions.RunEnvUpdateLib,
+		actionEval:              actions.RunEval,
+		actionExplain:           actions.RunExplain,
+		actionExport:            actions.RunExport,
+		actionFmt:               actions.RunFmt,
+		actionGraph:             actions.RunGraph,
+		actionImageList:         actions.RunImageList,
+		actionImageOutdated:     actions.RunImageOutdated,
+		actionImagePin:          actions.RunImagePin,
+		actionImageSet:          actions.RunImageSet,
 		actionImport:            actions.RunImport,
 		actionInit:              actions.RunInit,
+		actionInventory:         actions.RunInventory,
+		actionJbSync:            actions.RunJbSync,
+		actionLint:              actions.RunLint,
+		actionLogs:              actions.RunLogs,
 		actionModuleCreate:      actions.RunModuleCreate,
+		actionModuleDescribe:    actions.RunModuleDescribe,
 		actionModuleList:        actions.RunModuleList,
 		actionParamDiff:         actions.RunParamDiff,
-		actionParamDelete:       actions.RunParamDelete,
+		actionParamDelete:       actions.WithDryRun(actions.RunParamDelete),
+		actionParamHistory:      actions.RunParamHistory,
 		actionParamUnset:        actions.RunParamDelete,
 		actionParamList:         actions.RunParamList,
-		actionParamSet:          actions.RunParamSet,
+		actionParamSet:          actions.WithDryRun(actions.RunParamSet),
 		actionPkgDescribe:       actions.RunPkgDescribe,
-		actionPkgInstall:        actions.RunPkgInstall,
+		actionPkgDiff:           actions.RunPkgDiff,
+		actionPkgInstall:        actions.WithDryRun(actions.RunPkgInstall),
 		actionPkgList:           actions.RunPkgList,
 		actionPkgRemove:         actions.RunPkgRemove,
+		actionPluginRun:         actions.RunPluginRun,
+		actionPortForward:       actions.RunPortForward,
+		actionPrototypeCreate:   actions.RunPrototypeCreate,
 		actionPrototypeDescribe: actions.RunPrototypeDescribe,
+		actionPrototypeLint:     actions.RunPrototypeLint,
 		actionPrototypeList:     actions.RunPrototypeList,
 		actionPrototypePreview:  actions.RunPrototypePreview,
 		actionPrototypeSearch:   actions.RunPrototypeSearch,
 		actionPrototypeUse:      actions.RunPrototypeUse,
-		actionRegistryAdd:       actions.RunRegistryAdd,
+		actionRegistryAdd:       actions.WithDryRun(actions.RunRegistryAdd),
 		actionRegistryDescribe:  actions.RunRegistryDescribe,
 		actionRegistryList:      actions.RunRegistryList,
 		actionRegistrySet:       actions.RunRegistrySet,
+		actionRegistryStats:     actions.RunRegistryStats,
+		actionRenderForArgoCD:   actions.RunRenderForArgoCD,
 		actionShow:              actions.RunShow,
+		actionSnapshotRecord:    actions.RunSnapshotRecord,
+		actionSnapshotVerify:    actions.RunSnapshotVerify,
+		actionStatus:            actions.RunStatus,
+		actionTest:              actions.RunTest,
+		actionToolJsonnetPaths:  actions.RunToolJsonnetPaths,
+		actionUI:                actions.RunUI,
 		actionUpgrade:           actions.RunUpgrade,
 		actionValidate:          actions.RunValidate,
 	}
@@ -114,5 +187,10 @@ func runAction(name initName, args map[string]interface{}) error {
 		return errors.Errorf("invalid action %q", name)
 	}
 
-	return fn(args)
+	err := fn(args)
+	if traceErr := finishTrace(err); traceErr != nil {
+		log.WithError(traceErr).Warn("unable to write trace")
+	}
+
+	return err
 }
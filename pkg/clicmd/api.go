@@ -0,0 +1,34 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/spf13/cobra"
+)
+
+func newAPICmd(a app.App) *cobra.Command {
+	apiCmd := &cobra.Command{
+		Use:   "api",
+		Short: "Describe the app for editors and other tools",
+		Long: `Describe the app in a machine-readable form, so editor extensions and web UIs
+can offer completion without running several commands.`,
+	}
+
+	apiCmd.AddCommand(newAPIDumpCmd(a))
+
+	return apiCmd
+}
@@ -0,0 +1,69 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	apiDumpLong = `
+The ` + "`dump`" + ` command writes a JSON description of the app: its components and
+their parameters, environments, registries, and the prototypes which are
+available, with the metadata of their parameters. The types of component
+parameters are inferred from their values.
+
+The ` + "`apiVersion`" + ` of the description is ` + "`ksonnet.io/api/v1`" + `. Fields are only
+added to a version, so tools should ignore fields they do not know.
+
+### Related Commands
+
+* ` + "`ks component list` " + `— List known components
+* ` + "`ks prototype list` " + `— ` + protoShortDesc["list"] + `
+
+### Syntax
+`
+	apiDumpExample = `
+# Write a description of the app for an editor extension.
+ks api dump > ks-api.json`
+)
+
+func newAPIDumpCmd(a app.App) *cobra.Command {
+	apiDumpCmd := &cobra.Command{
+		Use:     "dump",
+		Short:   "Write a JSON description of the app",
+		Long:    apiDumpLong,
+		Example: apiDumpExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return errors.New("'api dump' does not take any arguments")
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:           a,
+				actions.OptionTLSSkipVerify: viper.GetBool(flagTLSSkipVerify),
+			}
+
+			return runAction(actionAPIDump, m)
+		},
+	}
+
+	return apiDumpCmd
+}
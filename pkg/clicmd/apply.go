@@ -19,17 +19,38 @@ import (
 	"github.com/ksonnet/ksonnet/pkg/actions"
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/cluster"
+	"github.com/ksonnet/ksonnet/pkg/metadata"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 const (
-	vApplyComponent = "apply-components"
-	vApplyCreate    = "apply-create"
-	vApplyGcTag     = "apply-gc-tag"
-	vApplyDryRun    = "apply-dry-run"
-	vApplySkipGc    = "apply-skip-gc"
+	vApplyComponent      = "apply-components"
+	vApplyCreate         = "apply-create"
+	vApplyGcTag          = "apply-gc-tag"
+	vApplyGcLabels       = "apply-gc-labels"
+	vApplyGcAppLabel     = "apply-gc-app-label"
+	vApplyGcEnvLabel     = "apply-gc-env-label"
+	vApplyDryRun         = "apply-dry-run"
+	vApplyFromStdin      = "apply-from-stdin"
+	vApplySkipGc         = "apply-skip-gc"
+	vApplyWait           = "apply-wait"
+	vApplyWaitTimeout    = "apply-wait-timeout"
+	vApplyMaxUnavailable = "apply-max-unavailable-clusters"
+	vApplyOutput         = "apply-output"
+	vApplyParallelism    = "apply-parallelism"
+	vApplyQPS            = "apply-qps"
+	vApplyBurst          = "apply-burst"
+	vApplyBatchSize      = "apply-batch-size"
+	vApplyWithDeps       = "apply-with-dependencies"
+	vApplyWithDependents = "apply-with-dependents"
+	vApplyYes            = "apply-yes"
+
+	dryRunNone   = "none"
+	dryRunClient = "client"
+	dryRunServer = "server"
 
 	applyShortDesc = "Apply local Kubernetes manifests (components) to remote clusters"
 	applyLong      = `
@@ -44,6 +65,59 @@ expanded using the parameters of the specified environment.
 By default, all component manifests are applied. To apply a subset of components,
 use the ` + "`--component` " + `flag, as seen in the examples below.
 
+Before anything is applied, the manifests are checked against the Rego policies
+listed under the environment's ` + "`policies`" + ` in ` + "`app.yaml`" + `. A violation of a
+` + "`deny`" + ` rule stops the apply; see ` + "`ks validate`" + ` for details.
+
+The manifests are also replayed against the validating admission webhooks
+listed under the environment's ` + "`webhooks`" + `, which receive each object in an
+` + "`AdmissionReview`" + ` as if the API server had sent it. A webhook that denies an
+object stops the apply before the cluster is changed. A webhook that can not
+be reached stops the apply, unless its ` + "`failurePolicy`" + ` is ` + "`ignore`" + `.
+
+Hooks run before and after the manifests are applied. Local commands are
+declared under the environment's ` + "`hooks`" + ` in ` + "`app.yaml`" + `, with a ` + "`phase`" + ` of
+` + "`pre-apply`" + ` or ` + "`post-apply`" + `. Jobs in components become hooks when annotated
+with ` + "`ksonnet.io/hook`" + `; they are recreated on each apply and waited on until
+they complete. Hooks run in order of their weight (` + "`ksonnet.io/hook-weight`" + `),
+may set a timeout (` + "`ksonnet.io/hook-timeout`" + `, default 5m), and stop the apply
+when they fail unless their failure policy (` + "`ksonnet.io/hook-failure-policy`" + `)
+is ` + "`ignore`" + `. Post-apply hooks run after objects are ready when ` + "`--wait`" + ` is set.
+
+Namespaces the manifests target are checked before they are applied, when the
+environment sets a ` + "`namespaces`" + ` policy in ` + "`app.yaml`" + `. With ` + "`create`" + `, missing
+namespaces are created with the policy's ` + "`labels`" + `; with ` + "`require`" + `, the apply
+stops if any are missing; with ` + "`fail`" + `, objects may only target the
+environment's namespace or namespaces the environment itself creates.
+
+Objects are applied one at a time, in dependency order. To apply objects of
+the same dependency tier concurrently, set ` + "`--parallelism`" + `; use ` + "`--batch-size`" + ` to
+finish a number of objects before starting the next. ` + "`--qps`" + ` and ` + "`--burst`" + ` limit
+the requests sent to the Kubernetes API. Each can also be set under the
+environment's ` + "`apply`" + ` in ` + "`app.yaml`" + `; the flags take precedence.
+
+With ` + "`--output json`" + `, the outcome of each object is written to stdout as a
+JSON report once the apply finishes, including when it fails. Each object is
+listed as ` + "`created`" + `, ` + "`configured`" + `, ` + "`unchanged`" + `, ` + "`deleted`" + `, or ` + "`failed`" + `, along with
+the server's message for failed objects. Logs are written to stderr.
+
+With ` + "`--gc-tag`" + ` or ` + "`--gc-labels`" + `, objects which are no longer in the manifests
+are deleted after the apply. When running in a terminal, the deletion must be
+confirmed, by typing the environment's name if it is protected, unless
+` + "`--yes`" + ` is set. Otherwise, objects are deleted without confirmation.
+
+When applying a subset of components with ` + "`--component`" + `, ` + "`--with-dependencies`" + ` also
+applies the components they depend on, and ` + "`--with-dependents`" + ` the components
+which depend on them. A component depends on the components which create the
+namespaces of its objects, the components which define the custom resources it
+creates, and the components listed, separated by commas, in the
+` + "`ksonnet.io/depends-on`" + ` annotation of its objects.
+
+To apply manifests which have already been rendered, e.g. by ` + "`ks show`" + ` or
+another tool, pipe them to ` + "`ks apply <env-name> --from-stdin`" + `. The YAML or JSON
+manifests are applied as they are, without evaluating the app's components, so
+` + "`--component`" + ` can not be used.
+
 Note that this command needs to be run *within* a ksonnet app directory.
 
 ### Related Commands
@@ -66,6 +140,11 @@ ks apply dev
 # see a preview of the cluster-changing actions.
 ks apply dev --dry-run
 
+# Submit all resources in the 'dev' environment to the API server with
+# dryRun=All. Reports whether each resource would be accepted, mutated by
+# defaulting or admission webhooks, or rejected, without changing the cluster.
+ks apply dev --dry-run=server
+
 # Create or update the single 'guestbook-ui' component of a ksonnet app, specifically
 # the instance running in the 'dev' environment.
 #
@@ -79,6 +158,41 @@ ks apply dev -c guestbook-ui
 # This essentially deploys 'components/guestbook-ui.jsonnet' and
 # 'components/nginx-depl.jsonnet'.
 ks apply dev -c guestbook-ui -c nginx-depl --create false
+
+# Create or update the 'guestbook-ui' component in the 'dev' environment, along
+# with the components it depends on, such as the component which creates its
+# namespace.
+ks apply dev -c guestbook-ui --with-dependencies
+
+# Create or update all resources in the 'dev' environment, then wait up to
+# ten minutes for Deployments, StatefulSets, DaemonSets, Jobs, and
+# CustomResourceDefinitions to become ready.
+ks apply dev --wait --wait-timeout 10m
+
+# Create or update all resources in the 'dev' environment, labeling them with
+# the application and environment, and delete resources with those labels which
+# are no longer in the manifests, without a confirmation prompt. The same
+# resources can be pruned by kubectl, e.g.
+# 'kubectl apply --prune -l ksonnet.io/application=guestbook,ksonnet.io/environment=dev'.
+ks apply dev --gc-labels --yes
+
+# Create or update all resources in the 'prod' environment on each of the
+# clusters listed in its 'destinations', continuing as long as at most one
+# cluster fails.
+ks apply prod --max-unavailable-clusters 1
+
+# Create or update all resources in the 'prod' environment, applying up to
+# eight objects at a time while sending at most 20 requests per second to
+# the cluster.
+ks apply prod --parallelism 8 --qps 20 --burst 40
+
+# Create or update all resources in the 'prod' environment, and write a JSON
+# report of what happened to each object.
+ks apply prod -o json > report.json
+
+# Render the 'prod' environment once, then apply the exact same manifests.
+ks show prod > prod.yaml
+ks apply prod --from-stdin < prod.yaml
 `
 )
 
@@ -86,7 +200,7 @@ func newApplyCmd(a app.App) *cobra.Command {
 	applyClientConfig := client.NewDefaultClientConfig(a)
 
 	applyCmd := &cobra.Command{
-		Use:   "apply <env-name> [-c <component-name>] [--dry-run]",
+		Use:   "apply <env-name> [-c <component-name>] [--dry-run[=client|server]]",
 		Short: applyShortDesc,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var envName string
@@ -94,15 +208,36 @@ func newApplyCmd(a app.App) *cobra.Command {
 				envName = args[0]
 			}
 
+			dryRun, serverDryRun, err := parseDryRun(viper.GetString(vApplyDryRun))
+			if err != nil {
+				return err
+			}
+
 			m := map[string]interface{}{
-				actions.OptionApp:            a,
-				actions.OptionClientConfig:   applyClientConfig,
-				actions.OptionComponentNames: viper.GetStringSlice(vApplyComponent),
-				actions.OptionCreate:         viper.GetBool(vApplyCreate),
-				actions.OptionDryRun:         viper.GetBool(vApplyDryRun),
-				actions.OptionEnvName:        envName,
-				actions.OptionGcTag:          viper.GetString(vApplyGcTag),
-				actions.OptionSkipGc:         viper.GetBool(vApplySkipGc),
+				actions.OptionApp:                    a,
+				actions.OptionAssumeYes:              viper.GetBool(vApplyYes),
+				actions.OptionClientConfig:           applyClientConfig,
+				actions.OptionComponentNames:         viper.GetStringSlice(vApplyComponent),
+				actions.OptionCreate:                 viper.GetBool(vApplyCreate),
+				actions.OptionDryRun:                 dryRun,
+				actions.OptionEnvName:                envName,
+				actions.OptionFromStdin:              viper.GetBool(vApplyFromStdin),
+				actions.OptionGcTag:                  viper.GetString(vApplyGcTag),
+				actions.OptionGcLabels:               viper.GetBool(vApplyGcLabels),
+				actions.OptionGcAppLabel:             viper.GetString(vApplyGcAppLabel),
+				actions.OptionGcEnvLabel:             viper.GetString(vApplyGcEnvLabel),
+				actions.OptionMaxUnavailableClusters: viper.GetInt(vApplyMaxUnavailable),
+				actions.OptionOutput:                 viper.GetString(vApplyOutput),
+				actions.OptionParallelism:            viper.GetInt(vApplyParallelism),
+				actions.OptionQPS:                    float32(viper.GetFloat64(vApplyQPS)),
+				actions.OptionBurst:                  viper.GetInt(vApplyBurst),
+				actions.OptionBatchSize:              viper.GetInt(vApplyBatchSize),
+				actions.OptionServerDryRun:           serverDryRun,
+				actions.OptionSkipGc:                 viper.GetBool(vApplySkipGc),
+				actions.OptionWait:                   viper.GetBool(vApplyWait),
+				actions.OptionWaitTimeout:            viper.GetDuration(vApplyWaitTimeout),
+				actions.OptionWithDependencies:       viper.GetBool(vApplyWithDeps),
+				actions.OptionWithDependents:         viper.GetBool(vApplyWithDependents),
 			}
 
 			if err := extractJsonnetFlags(a, "apply"); err != nil {
@@ -121,6 +256,12 @@ func newApplyCmd(a app.App) *cobra.Command {
 	applyCmd.Flags().StringSliceP(flagComponent, shortComponent, nil, "Name of a specific component (multiple -c flags accepted, allows YAML, JSON, and Jsonnet)")
 	viper.BindPFlag(vApplyComponent, applyCmd.Flags().Lookup(flagComponent))
 
+	applyCmd.Flags().Bool(flagWithDependencies, false, "Also apply the components the specified components depend on, transitively")
+	viper.BindPFlag(vApplyWithDeps, applyCmd.Flags().Lookup(flagWithDependencies))
+
+	applyCmd.Flags().Bool(flagWithDependents, false, "Also apply the components which depend on the specified components, transitively")
+	viper.BindPFlag(vApplyWithDependents, applyCmd.Flags().Lookup(flagWithDependents))
+
 	applyCmd.Flags().Bool(flagCreate, true, "Option to create resources if they do not already exist on the cluster")
 	viper.BindPFlag(vApplyCreate, applyCmd.Flags().Lookup(flagCreate))
 
@@ -130,8 +271,65 @@ func newApplyCmd(a app.App) *cobra.Command {
 	applyCmd.Flags().String(flagGcTag, "", "A tag that's (1) added to all updated objects (2) used to garbage collect existing objects that are no longer in the manifest")
 	viper.BindPFlag(vApplyGcTag, applyCmd.Flags().Lookup(flagGcTag))
 
-	applyCmd.Flags().Bool(flagDryRun, false, "Option to preview the list of operations without changing the cluster state")
+	applyCmd.Flags().Bool(flagGcLabels, false, "Label objects with their application and environment, and garbage collect objects by those labels. Compatible with kubectl apply --prune -l")
+	viper.BindPFlag(vApplyGcLabels, applyCmd.Flags().Lookup(flagGcLabels))
+
+	applyCmd.Flags().String(flagGcAppLabel, metadata.LabelApplication, "Label which holds the application name when --"+flagGcLabels+" is specified")
+	viper.BindPFlag(vApplyGcAppLabel, applyCmd.Flags().Lookup(flagGcAppLabel))
+
+	applyCmd.Flags().String(flagGcEnvLabel, metadata.LabelEnvironment, "Label which holds the environment name when --"+flagGcLabels+" is specified")
+	viper.BindPFlag(vApplyGcEnvLabel, applyCmd.Flags().Lookup(flagGcEnvLabel))
+
+	applyCmd.Flags().Int(flagMaxUnavailable, 0, "Number of clusters of a multi-cluster environment which may fail before the remaining clusters are skipped and the command fails")
+	viper.BindPFlag(vApplyMaxUnavailable, applyCmd.Flags().Lookup(flagMaxUnavailable))
+
+	applyCmd.Flags().StringP(flagOutput, shortOutput, "", "Output format. Valid options: text|json")
+	viper.BindPFlag(vApplyOutput, applyCmd.Flags().Lookup(flagOutput))
+
+	applyCmd.Flags().Int(flagParallelism, 0, "Number of objects of the same dependency tier to apply concurrently. Defaults to the environment's apply settings, or 1")
+	viper.BindPFlag(vApplyParallelism, applyCmd.Flags().Lookup(flagParallelism))
+
+	applyCmd.Flags().Float32(flagQPS, 0, "Maximum queries per second sent to the Kubernetes API. Defaults to the environment's apply settings, or the client default")
+	viper.BindPFlag(vApplyQPS, applyCmd.Flags().Lookup(flagQPS))
+
+	applyCmd.Flags().Int(flagBurst, 0, "Maximum burst of queries sent to the Kubernetes API. Defaults to the environment's apply settings, or the client default")
+	viper.BindPFlag(vApplyBurst, applyCmd.Flags().Lookup(flagBurst))
+
+	applyCmd.Flags().Int(flagBatchSize, 0, "Number of objects of a dependency tier to apply before the next objects are started. Defaults to the environment's apply settings, or the whole tier")
+	viper.BindPFlag(vApplyBatchSize, applyCmd.Flags().Lookup(flagBatchSize))
+
+	applyCmd.Flags().String(flagDryRun, dryRunNone, "Option to preview the list of operations without changing the cluster state. Valid options: none|client|server")
+	applyCmd.Flags().Lookup(flagDryRun).NoOptDefVal = dryRunClient
 	viper.BindPFlag(vApplyDryRun, applyCmd.Flags().Lookup(flagDryRun))
 
+	applyCmd.Flags().Bool(flagFromStdin, false, "Apply pre-rendered YAML or JSON manifests read from stdin instead of evaluating components")
+	viper.BindPFlag(vApplyFromStdin, applyCmd.Flags().Lookup(flagFromStdin))
+
+	applyCmd.Flags().Bool(flagWait, false, "Wait for applied resources to become ready")
+	viper.BindPFlag(vApplyWait, applyCmd.Flags().Lookup(flagWait))
+
+	applyCmd.Flags().Duration(flagWaitTimeout, cluster.DefaultWaitTimeout, "Maximum time to wait for resources to become ready when --"+flagWait+" is specified")
+	viper.BindPFlag(vApplyWaitTimeout, applyCmd.Flags().Lookup(flagWaitTimeout))
+
+	// Unlike other commands, apply does not require --yes without a terminal.
+	applyCmd.Flags().BoolP(flagYes, shortYes, false, "Confirm garbage collection without a prompt")
+	viper.BindPFlag(vApplyYes, applyCmd.Flags().Lookup(flagYes))
+
 	return applyCmd
 }
+
+// parseDryRun converts a --dry-run value to client and server dry-run settings.
+// "true" and "false" are accepted since --dry-run used to be a boolean flag.
+func parseDryRun(value string) (bool, bool, error) {
+	switch value {
+	case "", dryRunNone, "false":
+		return false, false, nil
+	case dryRunClient, "true":
+		return true, false, nil
+	case dryRunServer:
+		return false, true, nil
+	default:
+		return false, false, errors.Errorf("invalid --%s value %q; valid options are %s, %s, and %s",
+			flagDryRun, value, dryRunNone, dryRunClient, dryRunServer)
+	}
+}
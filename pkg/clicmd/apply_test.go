@@ -17,6 +17,7 @@ package clicmd
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 
@@ -30,16 +31,285 @@ func Test_applyCmd(t *testing.T) {
 			args:   []string{"apply", "default"},
 			action: actionApply,
 			expected: map[string]interface{}{
-				actions.OptionApp:            mock.AnythingOfType("*app.App"),
-				actions.OptionEnvName:        "default",
-				actions.OptionGcTag:          "",
-				actions.OptionSkipGc:         false,
-				actions.OptionComponentNames: make([]string, 0),
-				actions.OptionCreate:         true,
-				actions.OptionDryRun:         false,
-				actions.OptionClientConfig:   mock.AnythingOfType("*client.Config"),
+				actions.OptionApp:                    mock.AnythingOfType("*app.App"),
+				actions.OptionAssumeYes:              false,
+				actions.OptionEnvName:                "default",
+				actions.OptionFromStdin:              false,
+				actions.OptionGcTag:                  "",
+				actions.OptionGcLabels:               false,
+				actions.OptionGcAppLabel:             "ksonnet.io/application",
+				actions.OptionGcEnvLabel:             "ksonnet.io/environment",
+				actions.OptionMaxUnavailableClusters: 0,
+				actions.OptionOutput:                 "",
+				actions.OptionParallelism:            0,
+				actions.OptionQPS:                    float32(0),
+				actions.OptionBurst:                  0,
+				actions.OptionBatchSize:              0,
+				actions.OptionSkipGc:                 false,
+				actions.OptionComponentNames:         make([]string, 0),
+				actions.OptionCreate:                 true,
+				actions.OptionDryRun:                 false,
+				actions.OptionServerDryRun:           false,
+				actions.OptionClientConfig:           mock.AnythingOfType("*client.Config"),
+				actions.OptionWait:                   false,
+				actions.OptionWaitTimeout:            5 * time.Minute,
+				actions.OptionWithDependencies:       false,
+				actions.OptionWithDependents:         false,
 			},
 		},
+		{
+			name:   "from stdin",
+			args:   []string{"apply", "default", "--from-stdin"},
+			action: actionApply,
+			expected: map[string]interface{}{
+				actions.OptionApp:                    mock.AnythingOfType("*app.App"),
+				actions.OptionAssumeYes:              false,
+				actions.OptionEnvName:                "default",
+				actions.OptionFromStdin:              true,
+				actions.OptionGcTag:                  "",
+				actions.OptionGcLabels:               false,
+				actions.OptionGcAppLabel:             "ksonnet.io/application",
+				actions.OptionGcEnvLabel:             "ksonnet.io/environment",
+				actions.OptionMaxUnavailableClusters: 0,
+				actions.OptionOutput:                 "",
+				actions.OptionParallelism:            0,
+				actions.OptionQPS:                    float32(0),
+				actions.OptionBurst:                  0,
+				actions.OptionBatchSize:              0,
+				actions.OptionSkipGc:                 false,
+				actions.OptionComponentNames:         make([]string, 0),
+				actions.OptionCreate:                 true,
+				actions.OptionDryRun:                 false,
+				actions.OptionServerDryRun:           false,
+				actions.OptionClientConfig:           mock.AnythingOfType("*client.Config"),
+				actions.OptionWait:                   false,
+				actions.OptionWaitTimeout:            5 * time.Minute,
+				actions.OptionWithDependencies:       false,
+				actions.OptionWithDependents:         false,
+			},
+		},
+		{
+			name:   "with wait",
+			args:   []string{"apply", "default", "--wait", "--wait-timeout", "30s"},
+			action: actionApply,
+			expected: map[string]interface{}{
+				actions.OptionApp:                    mock.AnythingOfType("*app.App"),
+				actions.OptionAssumeYes:              false,
+				actions.OptionEnvName:                "default",
+				actions.OptionFromStdin:              false,
+				actions.OptionGcTag:                  "",
+				actions.OptionGcLabels:               false,
+				actions.OptionGcAppLabel:             "ksonnet.io/application",
+				actions.OptionGcEnvLabel:             "ksonnet.io/environment",
+				actions.OptionMaxUnavailableClusters: 0,
+				actions.OptionOutput:                 "",
+				actions.OptionParallelism:            0,
+				actions.OptionQPS:                    float32(0),
+				actions.OptionBurst:                  0,
+				actions.OptionBatchSize:              0,
+				actions.OptionSkipGc:                 false,
+				actions.OptionComponentNames:         make([]string, 0),
+				actions.OptionCreate:                 true,
+				actions.OptionDryRun:                 false,
+				actions.OptionServerDryRun:           false,
+				actions.OptionClientConfig:           mock.AnythingOfType("*client.Config"),
+				actions.OptionWait:                   true,
+				actions.OptionWaitTimeout:            30 * time.Second,
+				actions.OptionWithDependencies:       false,
+				actions.OptionWithDependents:         false,
+			},
+		},
+		{
+			name:   "with client dry run",
+			args:   []string{"apply", "default", "--dry-run"},
+			action: actionApply,
+			expected: map[string]interface{}{
+				actions.OptionApp:                    mock.AnythingOfType("*app.App"),
+				actions.OptionAssumeYes:              false,
+				actions.OptionEnvName:                "default",
+				actions.OptionFromStdin:              false,
+				actions.OptionGcTag:                  "",
+				actions.OptionGcLabels:               false,
+				actions.OptionGcAppLabel:             "ksonnet.io/application",
+				actions.OptionGcEnvLabel:             "ksonnet.io/environment",
+				actions.OptionMaxUnavailableClusters: 0,
+				actions.OptionOutput:                 "",
+				actions.OptionParallelism:            0,
+				actions.OptionQPS:                    float32(0),
+				actions.OptionBurst:                  0,
+				actions.OptionBatchSize:              0,
+				actions.OptionSkipGc:                 false,
+				actions.OptionComponentNames:         make([]string, 0),
+				actions.OptionCreate:                 true,
+				actions.OptionDryRun:                 true,
+				actions.OptionServerDryRun:           false,
+				actions.OptionClientConfig:           mock.AnythingOfType("*client.Config"),
+				actions.OptionWait:                   false,
+				actions.OptionWaitTimeout:            5 * time.Minute,
+				actions.OptionWithDependencies:       false,
+				actions.OptionWithDependents:         false,
+			},
+		},
+		{
+			name:   "with server dry run",
+			args:   []string{"apply", "default", "--dry-run=server"},
+			action: actionApply,
+			expected: map[string]interface{}{
+				actions.OptionApp:                    mock.AnythingOfType("*app.App"),
+				actions.OptionAssumeYes:              false,
+				actions.OptionEnvName:                "default",
+				actions.OptionFromStdin:              false,
+				actions.OptionGcTag:                  "",
+				actions.OptionGcLabels:               false,
+				actions.OptionGcAppLabel:             "ksonnet.io/application",
+				actions.OptionGcEnvLabel:             "ksonnet.io/environment",
+				actions.OptionMaxUnavailableClusters: 0,
+				actions.OptionOutput:                 "",
+				actions.OptionParallelism:            0,
+				actions.OptionQPS:                    float32(0),
+				actions.OptionBurst:                  0,
+				actions.OptionBatchSize:              0,
+				actions.OptionSkipGc:                 false,
+				actions.OptionComponentNames:         make([]string, 0),
+				actions.OptionCreate:                 true,
+				actions.OptionDryRun:                 false,
+				actions.OptionServerDryRun:           true,
+				actions.OptionClientConfig:           mock.AnythingOfType("*client.Config"),
+				actions.OptionWait:                   false,
+				actions.OptionWaitTimeout:            5 * time.Minute,
+				actions.OptionWithDependencies:       false,
+				actions.OptionWithDependents:         false,
+			},
+		},
+		{
+			name:   "with concurrency and rate limits",
+			args:   []string{"apply", "default", "--parallelism", "8", "--qps", "20", "--burst", "40", "--batch-size", "100"},
+			action: actionApply,
+			expected: map[string]interface{}{
+				actions.OptionApp:                    mock.AnythingOfType("*app.App"),
+				actions.OptionAssumeYes:              false,
+				actions.OptionEnvName:                "default",
+				actions.OptionFromStdin:              false,
+				actions.OptionGcTag:                  "",
+				actions.OptionGcLabels:               false,
+				actions.OptionGcAppLabel:             "ksonnet.io/application",
+				actions.OptionGcEnvLabel:             "ksonnet.io/environment",
+				actions.OptionMaxUnavailableClusters: 0,
+				actions.OptionOutput:                 "",
+				actions.OptionParallelism:            8,
+				actions.OptionQPS:                    float32(20),
+				actions.OptionBurst:                  40,
+				actions.OptionBatchSize:              100,
+				actions.OptionSkipGc:                 false,
+				actions.OptionComponentNames:         make([]string, 0),
+				actions.OptionCreate:                 true,
+				actions.OptionDryRun:                 false,
+				actions.OptionServerDryRun:           false,
+				actions.OptionClientConfig:           mock.AnythingOfType("*client.Config"),
+				actions.OptionWait:                   false,
+				actions.OptionWaitTimeout:            5 * time.Minute,
+				actions.OptionWithDependencies:       false,
+				actions.OptionWithDependents:         false,
+			},
+		},
+		{
+			name:   "with json output",
+			args:   []string{"apply", "default", "-o", "json"},
+			action: actionApply,
+			expected: map[string]interface{}{
+				actions.OptionApp:                    mock.AnythingOfType("*app.App"),
+				actions.OptionAssumeYes:              false,
+				actions.OptionEnvName:                "default",
+				actions.OptionFromStdin:              false,
+				actions.OptionGcTag:                  "",
+				actions.OptionGcLabels:               false,
+				actions.OptionGcAppLabel:             "ksonnet.io/application",
+				actions.OptionGcEnvLabel:             "ksonnet.io/environment",
+				actions.OptionMaxUnavailableClusters: 0,
+				actions.OptionOutput:                 "json",
+				actions.OptionParallelism:            0,
+				actions.OptionQPS:                    float32(0),
+				actions.OptionBurst:                  0,
+				actions.OptionBatchSize:              0,
+				actions.OptionSkipGc:                 false,
+				actions.OptionComponentNames:         make([]string, 0),
+				actions.OptionCreate:                 true,
+				actions.OptionDryRun:                 false,
+				actions.OptionServerDryRun:           false,
+				actions.OptionClientConfig:           mock.AnythingOfType("*client.Config"),
+				actions.OptionWait:                   false,
+				actions.OptionWaitTimeout:            5 * time.Minute,
+				actions.OptionWithDependencies:       false,
+				actions.OptionWithDependents:         false,
+			},
+		},
+		{
+			name:   "with dependencies",
+			args:   []string{"apply", "default", "-c", "guestbook-ui", "--with-dependencies", "--with-dependents"},
+			action: actionApply,
+			expected: map[string]interface{}{
+				actions.OptionApp:                    mock.AnythingOfType("*app.App"),
+				actions.OptionAssumeYes:              false,
+				actions.OptionEnvName:                "default",
+				actions.OptionFromStdin:              false,
+				actions.OptionGcTag:                  "",
+				actions.OptionGcLabels:               false,
+				actions.OptionGcAppLabel:             "ksonnet.io/application",
+				actions.OptionGcEnvLabel:             "ksonnet.io/environment",
+				actions.OptionMaxUnavailableClusters: 0,
+				actions.OptionOutput:                 "",
+				actions.OptionParallelism:            0,
+				actions.OptionQPS:                    float32(0),
+				actions.OptionBurst:                  0,
+				actions.OptionBatchSize:              0,
+				actions.OptionSkipGc:                 false,
+				actions.OptionComponentNames:         []string{"guestbook-ui"},
+				actions.OptionCreate:                 true,
+				actions.OptionDryRun:                 false,
+				actions.OptionServerDryRun:           false,
+				actions.OptionClientConfig:           mock.AnythingOfType("*client.Config"),
+				actions.OptionWait:                   false,
+				actions.OptionWaitTimeout:            5 * time.Minute,
+				actions.OptionWithDependencies:       true,
+				actions.OptionWithDependents:         true,
+			},
+		},
+		{
+			name:   "with gc labels and yes",
+			args:   []string{"apply", "default", "--gc-labels", "--yes"},
+			action: actionApply,
+			expected: map[string]interface{}{
+				actions.OptionApp:                    mock.AnythingOfType("*app.App"),
+				actions.OptionAssumeYes:              true,
+				actions.OptionEnvName:                "default",
+				actions.OptionFromStdin:              false,
+				actions.OptionGcTag:                  "",
+				actions.OptionGcLabels:               true,
+				actions.OptionGcAppLabel:             "ksonnet.io/application",
+				actions.OptionGcEnvLabel:             "ksonnet.io/environment",
+				actions.OptionMaxUnavailableClusters: 0,
+				actions.OptionOutput:                 "",
+				actions.OptionParallelism:            0,
+				actions.OptionQPS:                    float32(0),
+				actions.OptionBurst:                  0,
+				actions.OptionBatchSize:              0,
+				actions.OptionSkipGc:                 false,
+				actions.OptionComponentNames:         make([]string, 0),
+				actions.OptionCreate:                 true,
+				actions.OptionDryRun:                 false,
+				actions.OptionServerDryRun:           false,
+				actions.OptionClientConfig:           mock.AnythingOfType("*client.Config"),
+				actions.OptionWait:                   false,
+				actions.OptionWaitTimeout:            5 * time.Minute,
+				actions.OptionWithDependencies:       false,
+				actions.OptionWithDependents:         false,
+			},
+		},
+		{
+			name:  "invalid dry run",
+			args:  []string{"apply", "default", "--dry-run=everything"},
+			isErr: true,
+		},
 		{
 			name:  "invalid jsonnet flag",
 			args:  []string{"apply", "default", "--ext-str", "foo"},
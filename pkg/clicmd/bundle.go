@@ -0,0 +1,82 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	bundleShortDesc = "Pack the app into a single archive, to render or apply without its source"
+)
+
+var (
+	bundleLong = `
+The ` + "`bundle`" + ` command packs the app into a single gzip'd tar archive: ` + "`app.yaml`" + `, and
+the ` + "`components/`" + `, ` + "`environments/`" + `, ` + "`lib/`" + `, and ` + "`vendor/`" + ` directories. Local
+overrides in ` + "`app.override.yaml`" + ` and the current environment are left out. Since
+packages are vendored, the bundle can be rendered without access to registries,
+so it can be handed to operators as a deployable artifact, without the app's
+repository.
+
+Any command can be run from a bundle with ` + "`--bundle <file>`" + `, in place of an app
+directory. The bundle is extracted into a temporary directory named after its
+content, which commands run from the same bundle share.
+
+### Related Commands
+
+* ` + "`ks show` " + `— ` + showShortDesc + `
+* ` + "`ks apply` " + `— ` + applyShortDesc + `
+* ` + "`ks export` " + `— ` + exportShortDesc + `
+
+### Syntax
+`
+	bundleExample = `
+# Pack the app into 'guestbook.tgz'.
+ks bundle guestbook.tgz
+
+# Show the manifests of the 'prod' environment of the bundle.
+ks show prod --bundle guestbook.tgz
+
+# Apply the 'prod' environment of the bundle.
+ks apply prod --bundle guestbook.tgz`
+)
+
+func newBundleCmd(a app.App) *cobra.Command {
+	bundleCmd := &cobra.Command{
+		Use:     "bundle <file>",
+		Short:   bundleShortDesc,
+		Long:    bundleLong,
+		Example: bundleExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("'bundle' requires a file to write the bundle to")
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:  a,
+				actions.OptionPath: args[0],
+			}
+
+			return runAction(actionBundle, m)
+		},
+	}
+
+	return bundleCmd
+}
@@ -0,0 +1,206 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	// completeCmdName is the name of the hidden command shell completion
+	// scripts run to complete a command line.
+	completeCmdName = "__complete"
+)
+
+var (
+	// completeArgs are the kinds of values which complete the first argument
+	// of a command.
+	completeArgs = map[string]string{
+		"ks apply":             actions.CompleteEnvironments,
+		"ks delete":            actions.CompleteEnvironments,
+		"ks diff":              actions.CompleteEnvironments,
+		"ks inventory":         actions.CompleteEnvironments,
+		"ks show":              actions.CompleteEnvironments,
+		"ks status":            actions.CompleteEnvironments,
+		"ks ui":                actions.CompleteEnvironments,
+		"ks validate":          actions.CompleteEnvironments,
+		"ks env describe":      actions.CompleteEnvironments,
+		"ks env rm":            actions.CompleteEnvironments,
+		"ks env set":           actions.CompleteEnvironments,
+		"ks env update":        actions.CompleteEnvironments,
+		"ks snapshot record":   actions.CompleteEnvironments,
+		"ks snapshot verify":   actions.CompleteEnvironments,
+		"ks component rm":      actions.CompleteComponents,
+		"ks logs":              actions.CompleteComponents,
+		"ks param delete":      actions.CompleteComponents,
+		"ks param list":        actions.CompleteComponents,
+		"ks param set":         actions.CompleteComponents,
+		"ks port-forward":      actions.CompleteComponents,
+		"ks registry describe": actions.CompleteRegistries,
+		"ks registry set":      actions.CompleteRegistries,
+		"ks pkg describe":      actions.CompletePackages,
+		"ks pkg install":       actions.CompletePackages,
+		"ks pkg remove":        actions.CompletePackages,
+	}
+
+	// completeFlags are the kinds of values which complete flags.
+	completeFlags = map[string]string{
+		flagComponent: actions.CompleteComponents,
+		flagEnv:       actions.CompleteEnvironments,
+	}
+)
+
+func newCompleteCmd(a app.App) *cobra.Command {
+	return &cobra.Command{
+		Use:                completeCmdName + " [words...] <partial-word>",
+		Short:              "Complete a command line",
+		Hidden:             true,
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var words []string
+			partial := ""
+			if len(args) > 0 {
+				words, partial = args[:len(args)-1], args[len(args)-1]
+			}
+
+			return complete(cmd.Root(), a, words, partial, cmd.OutOrStdout())
+		},
+	}
+}
+
+// complete writes the candidates for the partial word which follows words on
+// a command line. Commands and flags are completed from the command tree.
+// Values, e.g. environment names, are completed by querying the app.
+func complete(rootCmd *cobra.Command, a app.App, words []string, partial string, w io.Writer) error {
+	target, rest, _ := rootCmd.Find(words)
+	if target == nil || target.DisableFlagParsing {
+		return nil
+	}
+
+	var args []string
+	var flagKind string
+	for i := 0; i < len(rest); i++ {
+		word := rest[i]
+		flagKind = ""
+		if !strings.HasPrefix(word, "-") || word == "-" {
+			args = append(args, word)
+			continue
+		}
+		if strings.Contains(word, "=") {
+			continue
+		}
+
+		f := lookupFlag(target, strings.TrimLeft(word, "-"))
+		if f == nil || f.NoOptDefVal != "" {
+			continue
+		}
+		if i == len(rest)-1 {
+			flagKind = completeFlags[f.Name]
+			if flagKind == "" {
+				// The value of the flag can't be completed.
+				return nil
+			}
+		}
+		i++
+	}
+
+	switch {
+	case flagKind != "":
+		return completeValues(a, flagKind, partial, w)
+	case strings.HasPrefix(partial, "-"):
+		return writeCandidates(w, partial, flagNames(target))
+	case len(args) == 0 && target.HasAvailableSubCommands():
+		var names []string
+		for _, cmd := range target.Commands() {
+			if cmd.IsAvailableCommand() {
+				names = append(names, cmd.Name())
+			}
+		}
+		return writeCandidates(w, partial, names)
+	case len(args) == 0 && len(target.ValidArgs) > 0:
+		return writeCandidates(w, partial, target.ValidArgs)
+	case len(args) == 0:
+		if kind, ok := completeArgs[target.CommandPath()]; ok {
+			return completeValues(a, kind, partial, w)
+		}
+	}
+
+	return nil
+}
+
+func completeValues(a app.App, kind, partial string, w io.Writer) error {
+	m := map[string]interface{}{
+		actions.OptionApp:   a,
+		actions.OptionName:  kind,
+		actions.OptionQuery: partial,
+		actions.OptionOut:   w,
+	}
+
+	return runAction(actionComplete, m)
+}
+
+// lookupFlag finds a flag of a command by its name or shorthand.
+func lookupFlag(cmd *cobra.Command, name string) *pflag.Flag {
+	for _, fs := range []*pflag.FlagSet{cmd.Flags(), cmd.InheritedFlags()} {
+		if f := fs.Lookup(name); f != nil {
+			return f
+		}
+		if len(name) == 1 {
+			if f := fs.ShorthandLookup(name); f != nil {
+				return f
+			}
+		}
+	}
+
+	return nil
+}
+
+// flagNames returns the long names of a command's flags.
+func flagNames(cmd *cobra.Command) []string {
+	var names []string
+	visit := func(f *pflag.Flag) {
+		if !f.Hidden {
+			names = append(names, "--"+f.Name)
+		}
+	}
+
+	cmd.Flags().VisitAll(visit)
+	cmd.InheritedFlags().VisitAll(visit)
+
+	return names
+}
+
+func writeCandidates(w io.Writer, partial string, candidates []string) error {
+	sort.Strings(candidates)
+
+	for _, c := range candidates {
+		if strings.HasPrefix(c, partial) {
+			if _, err := fmt.Fprintln(w, c); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_completeCmd(t *testing.T) {
+	// The stubbed action writes the kind and prefix of the values it completes.
+	stub := func(m map[string]interface{}) error {
+		w := m[actions.OptionOut].(*bytes.Buffer)
+		_, err := fmt.Fprintf(w, "<%s:%s>\n", m[actions.OptionName], m[actions.OptionQuery])
+		return err
+	}
+
+	cases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "commands",
+			args:     []string{"st"},
+			expected: "status\n",
+		},
+		{
+			name:     "subcommands",
+			args:     []string{"env", "r"},
+			expected: "regenerate\nrm\n",
+		},
+		{
+			name:     "flags",
+			args:     []string{"apply", "--dr"},
+			expected: "--dry-run\n",
+		},
+		{
+			name:     "environment argument",
+			args:     []string{"apply", "d"},
+			expected: "<environments:d>\n",
+		},
+		{
+			name:     "component argument",
+			args:     []string{"param", "set", ""},
+			expected: "<components:>\n",
+		},
+		{
+			name:     "registry argument",
+			args:     []string{"registry", "describe", "in"},
+			expected: "<registries:in>\n",
+		},
+		{
+			name:     "package argument",
+			args:     []string{"pkg", "install", "incubator/"},
+			expected: "<packages:incubator/>\n",
+		},
+		{
+			name:     "component flag",
+			args:     []string{"apply", "default", "-c", "gu"},
+			expected: "<components:gu>\n",
+		},
+		{
+			name:     "environment flag",
+			args:     []string{"param", "list", "--env", ""},
+			expected: "<environments:>\n",
+		},
+		{
+			name: "value of a flag which is not completed",
+			args: []string{"apply", "default", "--context", ""},
+		},
+		{
+			name: "second argument",
+			args: []string{"apply", "default", ""},
+		},
+		{
+			name:     "valid arguments",
+			args:     []string{"completion", "z"},
+			expected: "zsh\n",
+		},
+		{
+			name: "hidden command",
+			args: []string{"__"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withCmd(actionComplete, stub, func() {
+				fs := afero.NewMemMapFs()
+				test.StageFile(t, fs, "app.yaml", "/app/app.yaml")
+
+				args := append([]string{completeCmdName}, tc.args...)
+				root, err := NewRoot(fs, "/app", args)
+				require.NoError(t, err)
+
+				var out bytes.Buffer
+				root.SetOutput(&out)
+
+				require.NoError(t, root.Execute())
+				assert.Equal(t, tc.expected, out.String())
+			})
+		})
+	}
+}
+
+func Test_completeCmd_outside_app(t *testing.T) {
+	root, err := NewRoot(afero.NewMemMapFs(), "/", []string{completeCmdName, "apply", ""})
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	root.SetOutput(&out)
+
+	require.NoError(t, root.Execute())
+	assert.Empty(t, out.String())
+}
+
+func Test_completionCmd(t *testing.T) {
+	for _, shell := range []string{"bash", "fish", "zsh"} {
+		t.Run(shell, func(t *testing.T) {
+			root, err := NewRoot(afero.NewMemMapFs(), "/", []string{"completion", shell})
+			require.NoError(t, err)
+
+			var out bytes.Buffer
+			root.SetOutput(&out)
+
+			require.NoError(t, root.Execute())
+			assert.Contains(t, out.String(), "ks "+completeCmdName)
+		})
+	}
+
+	root, err := NewRoot(afero.NewMemMapFs(), "/", []string{"completion", "tcsh"})
+	require.NoError(t, err)
+	require.Error(t, root.Execute())
+}
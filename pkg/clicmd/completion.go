@@ -0,0 +1,129 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	completionShortDesc = "Output shell completion code for bash, zsh, or fish"
+	completionLong      = `
+The ` + "`completion`" + ` command outputs a script which completes ` + "`ks`" + ` commands,
+flags, and values in a shell. Values are found in the app when completing, so
+environment names, component names, registry names, and package names are
+completed from the app in the current directory.
+
+Load the script in your shell's configuration to enable completion.
+
+### Syntax
+`
+	completionExample = `# Load completion in bash, e.g. in ~/.bashrc.
+source <(ks completion bash)
+
+# Load completion in zsh, e.g. in ~/.zshrc.
+source <(ks completion zsh)
+
+# Load completion in fish, e.g. in ~/.config/fish/config.fish.
+ks completion fish | source`
+
+	bashCompletion = `# bash completion for ks
+
+_ks_complete() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local IFS=$'\n'
+    COMPREPLY=( $(ks __complete "${COMP_WORDS[@]:1:COMP_CWORD-1}" "${cur}" 2>/dev/null) )
+}
+
+complete -o default -F _ks_complete ks
+`
+
+	zshCompletion = `#compdef ks
+
+# zsh completion for ks
+
+_ks() {
+    local -a candidates
+    candidates=("${(@f)$(ks __complete "${(@)words[2,CURRENT-1]}" "${words[CURRENT]}" 2>/dev/null)}")
+    if [[ -n "${candidates[1]}" ]]; then
+        compadd -a candidates
+    else
+        _files
+    fi
+}
+
+compdef _ks ks
+`
+
+	fishCompletion = `# fish completion for ks
+
+function __ks_complete
+    set -l words (commandline -opc)
+    set -e words[1]
+    ks __complete $words (commandline -ct) 2>/dev/null
+end
+
+complete -c ks -f -a '(__ks_complete)'
+`
+)
+
+var (
+	completionScripts = map[string]string{
+		"bash": bashCompletion,
+		"fish": fishCompletion,
+		"zsh":  zshCompletion,
+	}
+)
+
+func newCompletionCmd() *cobra.Command {
+	var shells []string
+	for shell := range completionScripts {
+		shells = append(shells, shell)
+	}
+	sort.Strings(shells)
+
+	completionCmd := &cobra.Command{
+		Use:       "completion <" + strings.Join(shells, "|") + ">",
+		Short:     completionShortDesc,
+		Long:      completionLong,
+		Example:   completionExample,
+		ValidArgs: shells,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("completion requires a shell, e.g. bash")
+			}
+
+			return writeCompletion(cmd.OutOrStdout(), args[0])
+		},
+	}
+
+	return completionCmd
+}
+
+func writeCompletion(w io.Writer, shell string) error {
+	script, ok := completionScripts[shell]
+	if !ok {
+		return errors.Errorf("unsupported shell %q", shell)
+	}
+
+	_, err := io.WriteString(w, script)
+	return err
+}
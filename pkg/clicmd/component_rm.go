@@ -21,12 +21,18 @@ import (
 	"github.com/ksonnet/ksonnet/pkg/actions"
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 var (
+	vComponentRmDryRun = "component-rm-dry-run"
+
 	componentRmLong = `Delete a component from the ksonnet application. This is equivalent to deleting the
 component file in the components directory and cleaning up all component
-references throughout the project.`
+references throughout the project.
+
+Use ` + "`--dry-run`" + ` to print the files that would be removed or edited as a
+unified diff without changing them.`
 	componentRmExample = `# Remove the component 'guestbook'. This is equivalent to deleting guestbook.jsonnet
 # in the components directory, and cleaning up references to the component
 # throughout the ksonnet application.
@@ -47,12 +53,16 @@ func newComponentRmCmd(a app.App) *cobra.Command {
 			m := map[string]interface{}{
 				actions.OptionApp:           a,
 				actions.OptionComponentName: args[0],
+				actions.OptionDryRun:        viper.GetBool(vComponentRmDryRun),
 			}
 
 			return runAction(actionComponentRm, m)
 		},
 	}
 
+	componentRmCmd.Flags().Bool(flagDryRun, false, "Print the file edits as a unified diff without writing them")
+	viper.BindPFlag(vComponentRmDryRun, componentRmCmd.Flags().Lookup(flagDryRun))
+
 	return componentRmCmd
 
 }
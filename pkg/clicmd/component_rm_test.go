@@ -30,6 +30,17 @@ func Test_componentRmCmd(t *testing.T) {
 			expected: map[string]interface{}{
 				actions.OptionApp:           nil,
 				actions.OptionComponentName: "name",
+				actions.OptionDryRun:        false,
+			},
+		},
+		{
+			name:   "dry run",
+			args:   []string{"component", "rm", "name", "--dry-run"},
+			action: actionComponentRm,
+			expected: map[string]interface{}{
+				actions.OptionApp:           nil,
+				actions.OptionComponentName: "name",
+				actions.OptionDryRun:        true,
 			},
 		},
 		{
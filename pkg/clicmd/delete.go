@@ -19,14 +19,20 @@ import (
 	"github.com/ksonnet/ksonnet/pkg/actions"
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/cluster"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 const (
-	vDeleteComponent   = "delete-components"
-	vDeleteGracePeriod = "delete-grace-period"
+	vDeleteComponent       = "delete-components"
+	vDeleteGracePeriod     = "delete-grace-period"
+	vDeletePruneNamespaces = "delete-prune-namespaces"
+	vDeleteYes             = "delete-yes"
+	vDeleteWait            = "delete-wait"
+	vDeleteWaitTimeout     = "delete-wait-timeout"
+	vDeleteMaxUnavailable  = "delete-max-unavailable-clusters"
 
 	deleteShortDesc = "Remove component-specified Kubernetes resources from remote clusters"
 	deleteLong      = `
@@ -39,6 +45,19 @@ components.
 
 **This command can be considered the inverse of the ` + "`ks apply`" + ` command.**
 
+Resources are deleted in reverse dependency order: workloads first, then other
+resources such as custom resources, then CustomResourceDefinitions, and
+Namespaces last. With ` + "`--wait`" + `, each group of resources must be gone, i.e. its
+finalizers must have completed, before the next group is deleted.
+
+With ` + "`--prune-namespaces`" + `, the environment namespace and the namespaces that
+contained deleted resources are also deleted once they are empty. Namespaces
+still terminating resources are not empty, so this works best with ` + "`--wait`" + `.
+
+The deletion must be confirmed at a prompt, or with ` + "`--yes`" + ` when not running in
+a terminal. Environments with ` + "`protected: true`" + ` in ` + "`app.yaml`" + ` are confirmed by
+typing their name.
+
 ### Related Commands
 
 * ` + "`ks diff` " + `— Compare manifests, based on environment or location (local or remote)
@@ -54,7 +73,14 @@ ks delete dev
 # Delete resources described by the 'nginx' component. $KUBECONFIG is overridden by
 # the CLI-specified './kubeconfig', so these changes are deployed to the current
 # context's cluster (not the 'default' environment)
-ks delete --kubeconfig=./kubeconfig -c nginx`
+ks delete --kubeconfig=./kubeconfig -c nginx
+
+# Delete all resources from the 'dev' environment, waiting for finalizers to
+# complete, and then delete the environment namespace if it is empty.
+ks delete dev --wait --prune-namespaces
+
+# Delete all resources from the 'dev' environment in a script, without a prompt.
+ks delete dev --yes`
 )
 
 func newDeleteCmd(a app.App) *cobra.Command {
@@ -72,11 +98,16 @@ func newDeleteCmd(a app.App) *cobra.Command {
 			}
 
 			m := map[string]interface{}{
-				actions.OptionApp:            a,
-				actions.OptionClientConfig:   deleteClientConfig,
-				actions.OptionComponentNames: viper.GetStringSlice(vDeleteComponent),
-				actions.OptionEnvName:        envName,
-				actions.OptionGracePeriod:    viper.GetInt64(vDeleteGracePeriod),
+				actions.OptionApp:                    a,
+				actions.OptionAssumeYes:              viper.GetBool(vDeleteYes),
+				actions.OptionClientConfig:           deleteClientConfig,
+				actions.OptionComponentNames:         viper.GetStringSlice(vDeleteComponent),
+				actions.OptionEnvName:                envName,
+				actions.OptionGracePeriod:            viper.GetInt64(vDeleteGracePeriod),
+				actions.OptionMaxUnavailableClusters: viper.GetInt(vDeleteMaxUnavailable),
+				actions.OptionPruneNamespaces:        viper.GetBool(vDeletePruneNamespaces),
+				actions.OptionWait:                   viper.GetBool(vDeleteWait),
+				actions.OptionWaitTimeout:            viper.GetDuration(vDeleteWaitTimeout),
 			}
 
 			if err := extractJsonnetFlags(a, "delete"); err != nil {
@@ -96,5 +127,19 @@ func newDeleteCmd(a app.App) *cobra.Command {
 	deleteCmd.Flags().Int64(flagGracePeriod, -1, "Number of seconds given to resources to terminate gracefully. A negative value is ignored")
 	viper.BindPFlag(vDeleteGracePeriod, deleteCmd.Flags().Lookup(flagGracePeriod))
 
+	deleteCmd.Flags().Int(flagMaxUnavailable, 0, "Number of clusters of a multi-cluster environment which may fail before the remaining clusters are skipped and the command fails")
+	viper.BindPFlag(vDeleteMaxUnavailable, deleteCmd.Flags().Lookup(flagMaxUnavailable))
+
+	deleteCmd.Flags().Bool(flagPruneNamespaces, false, "Delete the environment namespace and namespaces of deleted resources once they are empty")
+	viper.BindPFlag(vDeletePruneNamespaces, deleteCmd.Flags().Lookup(flagPruneNamespaces))
+
+	addCmdAssumeYes(deleteCmd, vDeleteYes)
+
+	deleteCmd.Flags().Bool(flagWait, false, "Wait for deleted resources to be removed before deleting the resources they depend on")
+	viper.BindPFlag(vDeleteWait, deleteCmd.Flags().Lookup(flagWait))
+
+	deleteCmd.Flags().Duration(flagWaitTimeout, cluster.DefaultWaitTimeout, "Maximum time to wait for each group of resources to be removed when --"+flagWait+" is specified")
+	viper.BindPFlag(vDeleteWaitTimeout, deleteCmd.Flags().Lookup(flagWaitTimeout))
+
 	return deleteCmd
 }
@@ -17,6 +17,7 @@ package clicmd
 
 import (
 	"testing"
+	"time"
 
 	"github.com/ksonnet/ksonnet/pkg/actions"
 )
@@ -28,11 +29,33 @@ func Test_deleteCmd(t *testing.T) {
 			args:   []string{"delete", "default"},
 			action: actionDelete,
 			expected: map[string]interface{}{
-				actions.OptionApp:            nil,
-				actions.OptionEnvName:        "default",
-				actions.OptionComponentNames: make([]string, 0),
-				actions.OptionClientConfig:   nil,
-				actions.OptionGracePeriod:    int64(-1),
+				actions.OptionApp:                    nil,
+				actions.OptionAssumeYes:              false,
+				actions.OptionEnvName:                "default",
+				actions.OptionComponentNames:         make([]string, 0),
+				actions.OptionClientConfig:           nil,
+				actions.OptionGracePeriod:            int64(-1),
+				actions.OptionMaxUnavailableClusters: 0,
+				actions.OptionPruneNamespaces:        false,
+				actions.OptionWait:                   false,
+				actions.OptionWaitTimeout:            5 * time.Minute,
+			},
+		},
+		{
+			name:   "with wait, prune namespaces, and yes",
+			args:   []string{"delete", "default", "--wait", "--wait-timeout", "30s", "--prune-namespaces", "--yes"},
+			action: actionDelete,
+			expected: map[string]interface{}{
+				actions.OptionApp:                    nil,
+				actions.OptionAssumeYes:              true,
+				actions.OptionEnvName:                "default",
+				actions.OptionComponentNames:         make([]string, 0),
+				actions.OptionClientConfig:           nil,
+				actions.OptionGracePeriod:            int64(-1),
+				actions.OptionMaxUnavailableClusters: 0,
+				actions.OptionPruneNamespaces:        true,
+				actions.OptionWait:                   true,
+				actions.OptionWaitTimeout:            30 * time.Second,
 			},
 		},
 		{
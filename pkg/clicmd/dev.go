@@ -0,0 +1,107 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/dev"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vDevDebounce = "dev-debounce"
+)
+
+var (
+	devShortDesc = "Continuously deploy an environment as its files change"
+	devLong      = `
+The ` + "`dev`" + ` command is a development loop for an environment. It applies the
+environment, and then watches the app's components, params, libraries,
+environments, and vendored packages. Each time files change, and stop changing
+for the debounce period, it:
+
+1. Re-renders the affected components. If only components changed, only those
+   components are rendered; otherwise the whole environment is.
+2. Diffs them with the cluster, ignoring fields managed by the server.
+3. Applies them if any object was added or changed.
+
+A summary of each cycle is logged. Errors, such as invalid jsonnet, are logged
+and the loop keeps watching, so they can be fixed without restarting it. Objects
+which are no longer rendered are reported, but not deleted.
+
+` + "`dev`" + ` runs until it is interrupted. It only supports environments with a single
+cluster, and is meant for development environments.
+
+### Related Commands
+
+* ` + "`ks apply` " + `— ` + applyShortDesc + `
+* ` + "`ks diff` " + `— ` + diffShortDesc + `
+
+### Syntax
+`
+	devExample = `
+# Deploy the 'dev' environment each time the app changes.
+ks dev dev
+
+# Wait for files to stop changing for two seconds before deploying.
+ks dev dev --debounce 2s`
+)
+
+func newDevCmd(a app.App) *cobra.Command {
+	devClientConfig := client.NewDefaultClientConfig(a)
+
+	devCmd := &cobra.Command{
+		Use:     "dev <env-name> [--debounce <duration>]",
+		Short:   devShortDesc,
+		Long:    devLong,
+		Example: devExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				return errors.New("'dev' takes at most one argument, the name of the environment")
+			}
+
+			var envName string
+			if len(args) == 1 {
+				envName = args[0]
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:          a,
+				actions.OptionClientConfig: devClientConfig,
+				actions.OptionDebounce:     viper.GetDuration(vDevDebounce),
+				actions.OptionEnvName:      envName,
+			}
+
+			if err := extractJsonnetFlags(a, "dev"); err != nil {
+				return errors.Wrap(err, "handle jsonnet flags")
+			}
+
+			return runAction(actionDev, m)
+		},
+	}
+
+	devClientConfig.BindClientGoFlags(devCmd)
+	bindJsonnetFlags(devCmd, "dev")
+
+	devCmd.Flags().Duration(flagDebounce, dev.DefaultDebounce, "Period files must stop changing for before they are deployed")
+	viper.BindPFlag(vDevDebounce, devCmd.Flags().Lookup(flagDebounce))
+
+	return devCmd
+}
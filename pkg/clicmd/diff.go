@@ -17,6 +17,7 @@ package clicmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -25,10 +26,20 @@ import (
 	"github.com/ksonnet/ksonnet/pkg/actions"
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/diff"
 )
 
 const (
 	vDiffComponentNames = "diff-component-names"
+	vDiffStrategy       = "diff-strategy"
+	vDiffOutput         = "diff-output"
+	vDiffProgram        = "diff-program"
+	vDiffFromStdin      = "diff-from-stdin"
+	vDiffWatch          = "diff-watch"
+	vDiffWatchInterval  = "diff-watch-interval"
+	vDiffMetricsAddr    = "diff-metrics-addr"
+	vDiffMaxUnavailable = "diff-max-unavailable-clusters"
+	vDiffShowSecrets    = "diff-show-secrets"
 
 	diffShortDesc = "Compare manifests, based on environment or location (local or remote)"
 )
@@ -55,6 +66,44 @@ the files in the ` + "`components/`" + ` directory.
 When a component IS specified via the ` + "`-c`" + ` flag, this command only checks
 the manifest for that particular component.
 
+Use ` + "`-o json`" + ` to print a machine-readable report listing each added, changed,
+or removed object and the paths of the fields that differ. Use ` + "`-o semantic`" + `
+to print the same report for humans; it ignores field ordering and metadata
+managed by the server, such as ` + "`resourceVersion`" + ` and ` + "`managedFields`" + `.
+
+Differences which are expected, such as replicas managed by a
+HorizontalPodAutoscaler or sidecars injected by a webhook, can be left out.
+Rules are listed under the environment's ` + "`diffIgnore`" + ` in ` + "`app.yaml`" + `; each
+selects objects by ` + "`group`" + `, ` + "`kind`" + `, ` + "`namespace`" + `, ` + "`name`" + `, and ` + "`component`" + `, and
+ignores the fields at its ` + "`jsonPointers`" + ` (e.g. ` + "`/spec/replicas`" + `) and
+` + "`jsonPaths`" + ` (e.g. ` + "`.spec.template.spec.containers[?(@.name==\"istio-proxy\")]`" + `).
+A rule without paths ignores the objects entirely. A component can also list
+JSON pointers, separated by commas, in an object's ` + "`ksonnet.io/diff-ignore`" + `
+annotation.
+
+The data of Secrets, and the fields selected by the environment's ` + "`redact`" + `
+rules in ` + "`app.yaml`" + `, are redacted. Each value is replaced with a hash, which
+is the same for equal values on both sides, so changes are still found without
+revealing the values. Use ` + "`--show-secrets`" + ` to compare them in the clear.
+
+To compare manifests with an external tool (e.g. dyff or difftastic), set
+` + "`--diff-program`" + ` or the ` + "`KS_DIFF`" + ` environment variable. The program is called
+with two directories containing one YAML file per object, and follows the
+convention of diff(1): it exits with 1 if differences are found.
+
+To compare manifests which have already been rendered, e.g. by ` + "`ks show`" + ` or
+another tool, pipe them to ` + "`ks diff`" + ` with ` + "`--from-stdin`" + `. The YAML or JSON
+manifests are used for *local* locations instead of evaluating the app's
+components, so ` + "`-c`" + ` can not be used.
+
+The exit code is 0 when no differences are found, 1 when differences are found,
+and 2 when the differences could not be determined.
+
+Use ` + "`--watch`" + ` to run as a drift detection daemon. The differences are
+re-evaluated every ` + "`--watch-interval`" + ` until ks is interrupted, and each
+evaluation is logged, or printed as a line of JSON with ` + "`-o json`" + `. Set
+` + "`--metrics-addr`" + ` to also serve drift metrics for Prometheus at ` + "`/metrics`" + `.
+
 ### Related Commands
 
 * ` + "`ks param diff` " + `— ` + paramShortDesc["diff"] + `
@@ -82,6 +131,26 @@ ks diff local:us-west/dev remote:us-west/prod
 # Show diff between what's in the local manifest and what's actually running in the
 # 'dev' environment, but for the Redis component ONLY
 ks diff dev -c redis
+
+# Show diff between the live resources in the 'dev' environment and what the
+# server would store if the local manifests were applied. Fields set by
+# defaulting and admission controllers are not reported as differences.
+ks diff dev --diff-strategy server
+
+# Print a JSON report of the differences for the 'dev' environment, e.g. to gate
+# a CI pipeline on drift.
+ks diff dev -o json
+
+# Show the differences for the 'dev' environment with dyff.
+KS_DIFF="dyff between" ks diff dev
+
+# Show diff between the manifests rendered by another tool and what's running
+# in the 'dev' environment.
+render-manifests | ks diff dev --from-stdin
+
+# Check the 'prod' environment for drift every five minutes, printing one JSON
+# event per evaluation and serving Prometheus metrics on port 9090.
+ks diff prod --watch --watch-interval 5m -o json --metrics-addr :9090
 `
 )
 
@@ -102,10 +171,19 @@ func newDiffCmd(a app.App) *cobra.Command {
 			}
 
 			m := map[string]interface{}{
-				actions.OptionApp:            a,
-				actions.OptionClientConfig:   diffClientConfig,
-				actions.OptionSrc1:           args[0],
-				actions.OptionComponentNames: viper.GetStringSlice(vDiffComponentNames),
+				actions.OptionApp:                    a,
+				actions.OptionClientConfig:           diffClientConfig,
+				actions.OptionSrc1:                   args[0],
+				actions.OptionComponentNames:         viper.GetStringSlice(vDiffComponentNames),
+				actions.OptionDiffStrategy:           viper.GetString(vDiffStrategy),
+				actions.OptionOutput:                 viper.GetString(vDiffOutput),
+				actions.OptionDiffProgram:            viper.GetString(vDiffProgram),
+				actions.OptionFromStdin:              viper.GetBool(vDiffFromStdin),
+				actions.OptionWatch:                  viper.GetBool(vDiffWatch),
+				actions.OptionWatchInterval:          viper.GetDuration(vDiffWatchInterval),
+				actions.OptionMetricsAddr:            viper.GetString(vDiffMetricsAddr),
+				actions.OptionMaxUnavailableClusters: viper.GetInt(vDiffMaxUnavailable),
+				actions.OptionShowSecrets:            viper.GetBool(vDiffShowSecrets),
 			}
 
 			if len(args) == 2 {
@@ -126,5 +204,32 @@ func newDiffCmd(a app.App) *cobra.Command {
 	diffCmd.Flags().StringSliceP(flagComponent, shortComponent, nil, "Name of a specific component")
 	viper.BindPFlag(vDiffComponentNames, diffCmd.Flags().Lookup(flagComponent))
 
+	diffCmd.Flags().String(flagDiffStrategy, diff.StrategyClient, "Diff strategy. Valid options: "+strings.Join(diff.Strategies, "|"))
+	viper.BindPFlag(vDiffStrategy, diffCmd.Flags().Lookup(flagDiffStrategy))
+
+	diffCmd.Flags().StringP(flagOutput, shortOutput, "", "Output format. Valid options: text|semantic|json")
+	viper.BindPFlag(vDiffOutput, diffCmd.Flags().Lookup(flagOutput))
+
+	diffCmd.Flags().String(flagDiffProgram, "", "External program used to compare manifests. Defaults to $"+diff.EnvDiffProgram)
+	viper.BindPFlag(vDiffProgram, diffCmd.Flags().Lookup(flagDiffProgram))
+
+	diffCmd.Flags().Bool(flagFromStdin, false, "Use pre-rendered YAML or JSON manifests read from stdin for local locations instead of evaluating components")
+	viper.BindPFlag(vDiffFromStdin, diffCmd.Flags().Lookup(flagFromStdin))
+
+	diffCmd.Flags().Bool(flagShowSecrets, false, "Show the data of Secrets, and the fields selected by redact rules, instead of redacting them")
+	viper.BindPFlag(vDiffShowSecrets, diffCmd.Flags().Lookup(flagShowSecrets))
+
+	diffCmd.Flags().Int(flagMaxUnavailable, 0, "Number of clusters of a multi-cluster environment which may fail before the remaining clusters are skipped and the command fails")
+	viper.BindPFlag(vDiffMaxUnavailable, diffCmd.Flags().Lookup(flagMaxUnavailable))
+
+	diffCmd.Flags().Bool(flagWatch, false, "Re-evaluate the differences periodically and report drift until interrupted")
+	viper.BindPFlag(vDiffWatch, diffCmd.Flags().Lookup(flagWatch))
+
+	diffCmd.Flags().Duration(flagWatchInterval, diff.DefaultWatchInterval, "Interval between drift evaluations in watch mode")
+	viper.BindPFlag(vDiffWatchInterval, diffCmd.Flags().Lookup(flagWatchInterval))
+
+	diffCmd.Flags().String(flagMetricsAddr, "", "Address to serve Prometheus drift metrics on in watch mode, e.g. :9090")
+	viper.BindPFlag(vDiffMetricsAddr, diffCmd.Flags().Lookup(flagMetricsAddr))
+
 	return diffCmd
 }
@@ -17,6 +17,7 @@ package clicmd
 
 import (
 	"testing"
+	"time"
 
 	"github.com/ksonnet/ksonnet/pkg/actions"
 )
@@ -28,11 +29,101 @@ func Test_diffCmd(t *testing.T) {
 			args:   []string{"diff", "env1", "env2"},
 			action: actionDiff,
 			expected: map[string]interface{}{
-				actions.OptionApp:            nil,
-				actions.OptionClientConfig:   nil,
-				actions.OptionSrc1:           "env1",
-				actions.OptionSrc2:           "env2",
-				actions.OptionComponentNames: []string{},
+				actions.OptionApp:                    nil,
+				actions.OptionClientConfig:           nil,
+				actions.OptionSrc1:                   "env1",
+				actions.OptionSrc2:                   "env2",
+				actions.OptionComponentNames:         []string{},
+				actions.OptionDiffStrategy:           "client",
+				actions.OptionOutput:                 "",
+				actions.OptionDiffProgram:            "",
+				actions.OptionFromStdin:              false,
+				actions.OptionWatch:                  false,
+				actions.OptionWatchInterval:          time.Minute,
+				actions.OptionMetricsAddr:            "",
+				actions.OptionMaxUnavailableClusters: 0,
+				actions.OptionShowSecrets:            false,
+			},
+		},
+		{
+			name:   "from stdin",
+			args:   []string{"diff", "env1", "env2", "--from-stdin"},
+			action: actionDiff,
+			expected: map[string]interface{}{
+				actions.OptionApp:                    nil,
+				actions.OptionClientConfig:           nil,
+				actions.OptionSrc1:                   "env1",
+				actions.OptionSrc2:                   "env2",
+				actions.OptionComponentNames:         []string{},
+				actions.OptionDiffStrategy:           "client",
+				actions.OptionOutput:                 "",
+				actions.OptionDiffProgram:            "",
+				actions.OptionFromStdin:              true,
+				actions.OptionWatch:                  false,
+				actions.OptionWatchInterval:          time.Minute,
+				actions.OptionMetricsAddr:            "",
+				actions.OptionMaxUnavailableClusters: 0,
+				actions.OptionShowSecrets:            false,
+			},
+		},
+		{
+			name:   "server diff strategy",
+			args:   []string{"diff", "env1", "--diff-strategy", "server"},
+			action: actionDiff,
+			expected: map[string]interface{}{
+				actions.OptionApp:                    nil,
+				actions.OptionClientConfig:           nil,
+				actions.OptionSrc1:                   "env1",
+				actions.OptionComponentNames:         []string{},
+				actions.OptionDiffStrategy:           "server",
+				actions.OptionOutput:                 "",
+				actions.OptionDiffProgram:            "",
+				actions.OptionFromStdin:              false,
+				actions.OptionWatch:                  false,
+				actions.OptionWatchInterval:          time.Minute,
+				actions.OptionMetricsAddr:            "",
+				actions.OptionMaxUnavailableClusters: 0,
+				actions.OptionShowSecrets:            false,
+			},
+		},
+		{
+			name:   "json output",
+			args:   []string{"diff", "env1", "-o", "json"},
+			action: actionDiff,
+			expected: map[string]interface{}{
+				actions.OptionApp:                    nil,
+				actions.OptionClientConfig:           nil,
+				actions.OptionSrc1:                   "env1",
+				actions.OptionComponentNames:         []string{},
+				actions.OptionDiffStrategy:           "client",
+				actions.OptionOutput:                 "json",
+				actions.OptionDiffProgram:            "",
+				actions.OptionFromStdin:              false,
+				actions.OptionWatch:                  false,
+				actions.OptionWatchInterval:          time.Minute,
+				actions.OptionMetricsAddr:            "",
+				actions.OptionMaxUnavailableClusters: 0,
+				actions.OptionShowSecrets:            false,
+			},
+		},
+		{
+			name:   "external diff program",
+			args:   []string{"diff", "env1", "--diff-program", "dyff between"},
+			action: actionDiff,
+			expected: map[string]interface{}{
+				actions.OptionApp:                    nil,
+				actions.OptionClientConfig:           nil,
+				actions.OptionSrc1:                   "env1",
+				actions.OptionComponentNames:         []string{},
+				actions.OptionDiffStrategy:           "client",
+				actions.OptionOutput:                 "",
+				actions.OptionDiffProgram:            "dyff between",
+				actions.OptionFromStdin:              false,
+				actions.OptionWatch:                  false,
+				actions.OptionWatchInterval:          time.Minute,
+				actions.OptionMetricsAddr:            "",
+				actions.OptionMaxUnavailableClusters: 0,
+				actions.OptionShowSecrets:            false,
 			},
 		},
 		{
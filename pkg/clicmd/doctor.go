@@ -0,0 +1,97 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vDoctorEnv      = "doctor-env"
+	vDoctorOutput   = "doctor-output"
+	doctorShortDesc = "Check the health of the app and suggest fixes"
+)
+
+var (
+	doctorLong = `
+The ` + "`doctor`" + ` command checks the health of an app, and suggests a fix for each
+problem it finds. It checks that:
+
+* ` + "`app.yaml`" + ` and ` + "`app.override.yaml`" + ` are valid, and don't need ` + "`ks upgrade`" + `
+* each registry can be reached
+* each package in ` + "`app.yaml`" + ` is in the ` + "`vendor/`" + ` directory
+* each environment's cluster can be reached
+* each environment's ksonnet-lib was generated, for the same minor version of
+  Kubernetes as its cluster
+* the directories jsonnet imports files from exist
+
+Use ` + "`--env`" + ` to check a single environment. Failed checks make the command fail,
+so it can be run in CI; warnings don't.
+
+### Related Commands
+
+* ` + "`ks validate` " + `— ` + valShortDesc + `
+* ` + "`ks lint` " + `— ` + lintShortDesc + `
+
+### Syntax
+`
+	doctorExample = `
+# Check the health of the app and all of its environments.
+ks doctor
+
+# Check the app and the 'prod' environment, and write the results as JSON.
+ks doctor --env prod -o json`
+)
+
+func newDoctorCmd(a app.App) *cobra.Command {
+	doctorClientConfig := client.NewDefaultClientConfig(a)
+
+	doctorCmd := &cobra.Command{
+		Use:     "doctor",
+		Short:   doctorShortDesc,
+		Long:    doctorLong,
+		Example: doctorExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return errors.New("'doctor' takes no arguments")
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:           a,
+				actions.OptionEnvName:       viper.GetString(vDoctorEnv),
+				actions.OptionClientConfig:  doctorClientConfig,
+				actions.OptionOutput:        viper.GetString(vDoctorOutput),
+				actions.OptionTLSSkipVerify: viper.GetBool(flagTLSSkipVerify),
+			}
+
+			return runAction(actionDoctor, m)
+		},
+	}
+
+	doctorClientConfig.BindClientGoFlags(doctorCmd)
+
+	doctorCmd.Flags().String(flagEnv, "", "Environment to check. Defaults to all environments")
+	viper.BindPFlag(vDoctorEnv, doctorCmd.Flags().Lookup(flagEnv))
+
+	addCmdOutput(doctorCmd, vDoctorOutput)
+
+	return doctorCmd
+}
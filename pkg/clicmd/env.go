@@ -34,12 +34,15 @@ const (
 
 var (
 	envShortDesc = map[string]string{
-		"add":     "Add a new environment to a ksonnet application",
-		"current": "Sets the current environment",
-		"list":    "List all environments in a ksonnet application",
-		"rm":      "Delete an environment from a ksonnet application",
-		"set":     "Set environment-specific fields (name, namespace, server)",
-		"update":  "Updates the libs for an environment",
+		"add":            "Add a new environment to a ksonnet application",
+		"current":        "Sets the current environment",
+		"list":           "List all environments in a ksonnet application",
+		"regenerate":     "Regenerate an environment's main.jsonnet and params.libsonnet",
+		"rm":             "Delete an environment from a ksonnet application",
+		"set":            "Set environment-specific fields (name, namespace, server)",
+		"update":         "Updates the libs for an environment",
+		"update-crd-lib": "Generate jsonnet helpers for an environment's custom resources",
+		"update-lib":     "Regenerate an environment's ksonnet-lib for a Kubernetes version",
 	}
 
 	envLong = `
@@ -89,10 +92,13 @@ func newEnvCmd(a app.App) *cobra.Command {
 	envCmd.AddCommand(newEnvCurrentCmd(a))
 	envCmd.AddCommand(newEnvDescribeCmd(a))
 	envCmd.AddCommand(newEnvListCmd(a))
+	envCmd.AddCommand(newEnvRegenerateCmd(a))
 	envCmd.AddCommand(newEnvRmCmd(a))
 	envCmd.AddCommand(newEnvSetCmd(a))
 	envCmd.AddCommand(newEnvTargetsCmd(a))
 	envCmd.AddCommand(newEnvUpdateCmd(a))
+	envCmd.AddCommand(newEnvUpdateCRDLibCmd(a))
+	envCmd.AddCommand(newEnvUpdateLibCmd(a))
 
 	return envCmd
 
@@ -20,6 +20,11 @@ import (
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vEnvDescribeOutput = "env-describe-output"
 )
 
 func newEnvDescribeCmd(a app.App) *cobra.Command {
@@ -35,12 +40,15 @@ func newEnvDescribeCmd(a app.App) *cobra.Command {
 			m := map[string]interface{}{
 				actions.OptionApp:     a,
 				actions.OptionEnvName: args[0],
+				actions.OptionOutput:  viper.GetString(vEnvDescribeOutput),
 			}
 
 			return runAction(actionEnvDescribe, m)
 		},
 	}
 
+	addCmdOutput(envDescribeCmd, vEnvDescribeOutput)
+
 	return envDescribeCmd
 
 }
@@ -30,6 +30,17 @@ func Test_envDescribeCmd(t *testing.T) {
 			expected: map[string]interface{}{
 				actions.OptionApp:     nil,
 				actions.OptionEnvName: "prod",
+				actions.OptionOutput:  "",
+			},
+		},
+		{
+			name:   "with output",
+			args:   []string{"env", "describe", "prod", "-o", "json"},
+			action: actionEnvDescribe,
+			expected: map[string]interface{}{
+				actions.OptionApp:     nil,
+				actions.OptionEnvName: "prod",
+				actions.OptionOutput:  "json",
 			},
 		},
 		{
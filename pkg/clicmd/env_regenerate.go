@@ -0,0 +1,95 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"fmt"
+
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vEnvRegeneratePreserveCustom = "env-regenerate-preserve-custom"
+	vEnvRegenerateYes            = "env-regenerate-yes"
+)
+
+var (
+	envRegenerateLong = `
+The ` + "`regenerate`" + ` command rewrites an environment's ` + "`main.jsonnet`" + ` and
+` + "`params.libsonnet`" + ` from the templates of this version of ksonnet.
+
+Code between custom region markers is kept with ` + "`--preserve-custom`" + `. A region
+starts with a ` + "`// ks:begin-custom <name>`" + ` line and ends with a ` + "`// ks:end-custom`" + `
+line, and replaces the region with the same name in the template. New
+environments have these regions:
+
+* ` + "`imports`" + ` and ` + "`overrides`" + ` in ` + "`main.jsonnet`" + `
+* ` + "`components`" + ` in ` + "`params.libsonnet`" + `
+
+Files without custom regions are left unchanged, as are all files if a region
+is not in the template. Commands which rewrite ` + "`params.libsonnet`" + `, such as
+` + "`ks param set --env`" + `, remove comments, including region markers.
+
+Without ` + "`--preserve-custom`" + `, changes to the files are discarded, which must be
+confirmed at a prompt, or with ` + "`--yes`" + ` when not running in a terminal.
+
+### Related Commands
+
+* ` + "`ks env add` " + `— ` + envShortDesc["add"] + `
+* ` + "`ks upgrade` " + `— ` + `Upgrade ks configuration` + `
+
+### Syntax
+`
+	envRegenerateExample = `
+# Regenerate the files of the 'dev' environment, keeping custom regions.
+ks env regenerate dev --preserve-custom
+
+# Replace the files of the 'dev' environment with the templates.
+ks env regenerate dev --yes`
+)
+
+func newEnvRegenerateCmd(a app.App) *cobra.Command {
+	envRegenerateCmd := &cobra.Command{
+		Use:     "regenerate <env-name>",
+		Short:   envShortDesc["regenerate"],
+		Long:    envRegenerateLong,
+		Example: envRegenerateExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("'env regenerate' takes a single argument, that is the name of the environment")
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:            a,
+				actions.OptionAssumeYes:      viper.GetBool(vEnvRegenerateYes),
+				actions.OptionEnvName:        args[0],
+				actions.OptionPreserveCustom: viper.GetBool(vEnvRegeneratePreserveCustom),
+			}
+
+			return runAction(actionEnvRegenerate, m)
+		},
+	}
+
+	envRegenerateCmd.Flags().Bool("preserve-custom", false, "Keep the custom regions of the existing files")
+	viper.BindPFlag(vEnvRegeneratePreserveCustom, envRegenerateCmd.Flags().Lookup("preserve-custom"))
+
+	addCmdAssumeYes(envRegenerateCmd, vEnvRegenerateYes)
+
+	return envRegenerateCmd
+}
@@ -26,6 +26,7 @@ import (
 
 const (
 	vEnvRmOverride = "env-rm-override"
+	vEnvRmYes      = "env-rm-yes"
 )
 
 var (
@@ -37,6 +38,10 @@ contained. All empty parent directories are also subsequently deleted.
 NOTE: This does *NOT* delete the components running in ` + "`<env-name>`" + `. To do that, you
 need to use the ` + "`ks delete`" + ` command.
 
+The removal must be confirmed at a prompt, or with ` + "`--yes`" + ` when not running in
+a terminal. Environments with ` + "`protected: true`" + ` in ` + "`app.yaml`" + ` are confirmed by
+typing their name.
+
 ### Related Commands
 
 * ` + "`ks env list` " + `— ` + envShortDesc["list"] + `
@@ -64,9 +69,10 @@ func newEnvRmCmd(a app.App) *cobra.Command {
 			}
 
 			m := map[string]interface{}{
-				actions.OptionApp:      a,
-				actions.OptionEnvName:  args[0],
-				actions.OptionOverride: viper.GetBool(vEnvRmOverride),
+				actions.OptionApp:       a,
+				actions.OptionAssumeYes: viper.GetBool(vEnvRmYes),
+				actions.OptionEnvName:   args[0],
+				actions.OptionOverride:  viper.GetBool(vEnvRmOverride),
 			}
 
 			return runAction(actionEnvRm, m)
@@ -76,6 +82,8 @@ func newEnvRmCmd(a app.App) *cobra.Command {
 	envRmCmd.Flags().BoolP(flagOverride, shortOverride, false, "Remove the overridden environment")
 	viper.BindPFlag(vEnvRmOverride, envRmCmd.Flags().Lookup(flagOverride))
 
+	addCmdAssumeYes(envRmCmd, vEnvRmYes)
+
 	return envRmCmd
 
 }
@@ -28,9 +28,21 @@ func Test_envRmCmd(t *testing.T) {
 			args:   []string{"env", "rm", "prod"},
 			action: actionEnvRm,
 			expected: map[string]interface{}{
-				actions.OptionApp:      nil,
-				actions.OptionEnvName:  "prod",
-				actions.OptionOverride: false,
+				actions.OptionApp:       nil,
+				actions.OptionAssumeYes: false,
+				actions.OptionEnvName:   "prod",
+				actions.OptionOverride:  false,
+			},
+		},
+		{
+			name:   "with yes",
+			args:   []string{"env", "rm", "prod", "-y"},
+			action: actionEnvRm,
+			expected: map[string]interface{}{
+				actions.OptionApp:       nil,
+				actions.OptionAssumeYes: true,
+				actions.OptionEnvName:   "prod",
+				actions.OptionOverride:  false,
 			},
 		},
 		{
@@ -29,6 +29,7 @@ const (
 	vEnvSetNamespace = "env-set-namespace"
 	vEnvSetServer    = "env-set-server"
 	vEnvSetAPISpec   = "env-set-spec-flag"
+	vEnvSetDryRun    = "env-set-dry-run"
 )
 
 var (
@@ -39,6 +40,9 @@ You can currently only update your environment's name.
 Note that changing the name of an environment will also update the corresponding
 directory structure in ` + "`environments/`" + `.
 
+Use ` + "`--dry-run`" + ` to see the changes to ` + "`app.yaml`" + ` and ` + "`environments/`" + ` as a
+unified diff without making them.
+
 ### Related Commands
 
 * ` + "`ks env list` " + `— ` + envShortDesc["list"] + `
@@ -75,6 +79,7 @@ func newEnvSetCmd(a app.App) *cobra.Command {
 				actions.OptionNamespace:  viper.GetString(vEnvSetNamespace),
 				actions.OptionServer:     viper.GetString(vEnvSetServer),
 				actions.OptionSpecFlag:   viper.GetString(vEnvSetAPISpec),
+				actions.OptionDryRun:     viper.GetBool(vEnvSetDryRun),
 			}
 
 			return runAction(actionEnvSet, m)
@@ -96,5 +101,9 @@ func newEnvSetCmd(a app.App) *cobra.Command {
 	envSetCmd.Flags().String(flagAPISpec, "",
 		"Kubernetes version for environment")
 	viper.BindPFlag(vEnvSetAPISpec, envSetCmd.Flags().Lookup(flagAPISpec))
+
+	envSetCmd.Flags().Bool(flagDryRun, false, "Print the file edits as a unified diff without writing them")
+	viper.BindPFlag(vEnvSetDryRun, envSetCmd.Flags().Lookup(flagDryRun))
+
 	return envSetCmd
 }
@@ -34,6 +34,7 @@ func Test_envSetCmd(t *testing.T) {
 				actions.OptionNamespace:  "new-namespace",
 				actions.OptionServer:     "new-server",
 				actions.OptionSpecFlag:   "new-api-spec",
+				actions.OptionDryRun:     false,
 			},
 		},
 		{
@@ -0,0 +1,101 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"fmt"
+
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vEnvUpdateCRDLibFilename = "env-update-crd-lib-filename"
+	vEnvUpdateCRDLibOffline  = "env-update-crd-lib-offline"
+)
+
+var (
+	envUpdateCRDLibLong = `
+The ` + "`update-crd-lib`" + ` command generates jsonnet helpers for custom resources,
+similar to the ones ksonnet-lib provides for the built-in Kubernetes types. The
+helpers are written to ` + "`lib/crds.libsonnet`" + `, and are derived from the OpenAPI
+validation schemas of CustomResourceDefinitions.
+
+CustomResourceDefinitions are collected from the environment's cluster, the app's
+` + "`schemas`" + ` directory, and the files given with ` + "`--filename`" + `. Later sources
+take precedence. Use ` + "`--offline`" + ` to skip the cluster.
+
+Import the helpers in a component with:
+
+    local crds = import "crds.libsonnet";
+    local certificate = crds["example.com"].v1.Certificate;
+
+    certificate.new("web") + certificate.mixin.spec.withDnsNames(["example.com"])
+
+### Related Commands
+
+* ` + "`ks env update-lib` " + `— ` + envShortDesc["update-lib"] + `
+* ` + "`ks validate` " + `— ` + valShortDesc + `
+
+### Syntax
+`
+	envUpdateCRDLibExample = `
+# Generate helpers for the custom resources of the 'prod' environment's cluster.
+ks env update-crd-lib prod
+
+# Generate helpers from local CustomResourceDefinitions only.
+ks env update-crd-lib prod --offline -f crds/certificate.yaml`
+)
+
+func newEnvUpdateCRDLibCmd(a app.App) *cobra.Command {
+	envUpdateCRDLibClientConfig := client.NewDefaultClientConfig(a)
+
+	envUpdateCRDLibCmd := &cobra.Command{
+		Use:     "update-crd-lib <env-name>",
+		Short:   envShortDesc["update-crd-lib"],
+		Long:    envUpdateCRDLibLong,
+		Example: envUpdateCRDLibExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("'env update-crd-lib' takes a single argument, that is the name of the environment")
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:          a,
+				actions.OptionEnvName:      args[0],
+				actions.OptionPaths:        viper.GetStringSlice(vEnvUpdateCRDLibFilename),
+				actions.OptionClientConfig: envUpdateCRDLibClientConfig,
+				actions.OptionOffline:      viper.GetBool(vEnvUpdateCRDLibOffline),
+			}
+
+			return runAction(actionEnvUpdateCRDLib, m)
+		},
+	}
+
+	envUpdateCRDLibClientConfig.BindClientGoFlags(envUpdateCRDLibCmd)
+
+	envUpdateCRDLibCmd.Flags().StringSliceP(flagFilename, shortFilename, nil,
+		"File or directory containing CustomResourceDefinitions")
+	viper.BindPFlag(vEnvUpdateCRDLibFilename, envUpdateCRDLibCmd.Flags().Lookup(flagFilename))
+
+	envUpdateCRDLibCmd.Flags().Bool(flagOffline, false, "Do not fetch CustomResourceDefinitions from the cluster")
+	viper.BindPFlag(vEnvUpdateCRDLibOffline, envUpdateCRDLibCmd.Flags().Lookup(flagOffline))
+
+	return envUpdateCRDLibCmd
+}
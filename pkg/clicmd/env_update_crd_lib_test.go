@@ -0,0 +1,59 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_envUpdateCRDLibCmd(t *testing.T) {
+	cases := []cmdTestCase{
+		{
+			name:   "in general",
+			args:   []string{"env", "update-crd-lib", "prod"},
+			action: actionEnvUpdateCRDLib,
+			expected: map[string]interface{}{
+				actions.OptionApp:          nil,
+				actions.OptionEnvName:      "prod",
+				actions.OptionPaths:        []string{},
+				actions.OptionClientConfig: mock.AnythingOfType("*client.Config"),
+				actions.OptionOffline:      false,
+			},
+		},
+		{
+			name:   "offline with files",
+			args:   []string{"env", "update-crd-lib", "prod", "--offline", "-f", "crds/a.yaml", "-f", "crds/b.yaml"},
+			action: actionEnvUpdateCRDLib,
+			expected: map[string]interface{}{
+				actions.OptionApp:          nil,
+				actions.OptionEnvName:      "prod",
+				actions.OptionPaths:        []string{"crds/a.yaml", "crds/b.yaml"},
+				actions.OptionClientConfig: mock.AnythingOfType("*client.Config"),
+				actions.OptionOffline:      true,
+			},
+		},
+		{
+			name:  "no environment",
+			args:  []string{"env", "update-crd-lib"},
+			isErr: true,
+		},
+	}
+
+	runTestCmd(t, cases)
+}
@@ -0,0 +1,100 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"fmt"
+
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vEnvUpdateLibAPISpec = "env-update-lib-spec-flag"
+	vEnvUpdateLibDryRun  = "env-update-lib-dry-run"
+	vEnvUpdateLibOutput  = "env-update-lib-output"
+)
+
+var (
+	envUpdateLibLong = `
+The ` + "`update-lib`" + ` command regenerates an environment's ksonnet-lib
+(` + "`k.libsonnet`" + `) against the OpenAPI spec of a Kubernetes version, and records
+the version in ` + "`app.yaml`" + `. Use it to keep an environment in step with the
+cluster it deploys to when the cluster is upgraded.
+
+The API changes between the environment's current Kubernetes version and the new
+one are printed first: API definitions which were added or removed, and the
+properties which were added (` + "`+`" + `) or removed (` + "`-`" + `) from the others.
+Use ` + "`--dry-run`" + ` to review the changes without updating the environment.
+
+Without ` + "`--api-spec`" + `, ksonnet-lib is regenerated for the environment's current
+Kubernetes version.
+
+### Related Commands
+
+* ` + "`ks env set` " + `— ` + envShortDesc["set"] + `
+* ` + "`ks env update` " + `— ` + envShortDesc["update"] + `
+
+### Syntax
+`
+	envUpdateLibExample = `
+# Review the API changes from upgrading 'us-west/staging' to Kubernetes 1.11.
+ks env update-lib us-west/staging --api-spec=version:v1.11.0 --dry-run
+
+# Regenerate the ksonnet-lib of 'us-west/staging' for Kubernetes 1.11.
+ks env update-lib us-west/staging --api-spec=version:v1.11.0
+
+# Regenerate the ksonnet-lib of 'us-west/staging' from a cluster's OpenAPI spec.
+ks env update-lib us-west/staging --api-spec=file:swagger.json`
+)
+
+func newEnvUpdateLibCmd(a app.App) *cobra.Command {
+	envUpdateLibCmd := &cobra.Command{
+		Use:     "update-lib <env-name>",
+		Short:   envShortDesc["update-lib"],
+		Long:    envUpdateLibLong,
+		Example: envUpdateLibExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("'env update-lib' takes a single argument, that is the name of the environment")
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:           a,
+				actions.OptionEnvName:       args[0],
+				actions.OptionSpecFlag:      viper.GetString(vEnvUpdateLibAPISpec),
+				actions.OptionDryRun:        viper.GetBool(vEnvUpdateLibDryRun),
+				actions.OptionOutput:        viper.GetString(vEnvUpdateLibOutput),
+				actions.OptionTLSSkipVerify: viper.GetBool(flagTLSSkipVerify),
+			}
+
+			return runAction(actionEnvUpdateLib, m)
+		},
+	}
+
+	envUpdateLibCmd.Flags().String(flagAPISpec, "",
+		"Kubernetes version to generate ksonnet-lib for, e.g. 'version:v1.11.0' or 'file:swagger.json'")
+	viper.BindPFlag(vEnvUpdateLibAPISpec, envUpdateLibCmd.Flags().Lookup(flagAPISpec))
+
+	envUpdateLibCmd.Flags().Bool(flagDryRun, false, "Print the API changes without updating the environment")
+	viper.BindPFlag(vEnvUpdateLibDryRun, envUpdateLibCmd.Flags().Lookup(flagDryRun))
+
+	addCmdOutput(envUpdateLibCmd, vEnvUpdateLibOutput)
+
+	return envUpdateLibCmd
+}
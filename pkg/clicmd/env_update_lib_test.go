@@ -0,0 +1,60 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/actions"
+)
+
+func Test_envUpdateLibCmd(t *testing.T) {
+	cases := []cmdTestCase{
+		{
+			name:   "in general",
+			args:   []string{"env", "update-lib", "prod"},
+			action: actionEnvUpdateLib,
+			expected: map[string]interface{}{
+				actions.OptionApp:           nil,
+				actions.OptionEnvName:       "prod",
+				actions.OptionSpecFlag:      "",
+				actions.OptionDryRun:        false,
+				actions.OptionOutput:        "",
+				actions.OptionTLSSkipVerify: false,
+			},
+		},
+		{
+			name:   "with api spec",
+			args:   []string{"env", "update-lib", "prod", "--api-spec", "version:v1.11.0", "--dry-run", "-o", "json"},
+			action: actionEnvUpdateLib,
+			expected: map[string]interface{}{
+				actions.OptionApp:           nil,
+				actions.OptionEnvName:       "prod",
+				actions.OptionSpecFlag:      "version:v1.11.0",
+				actions.OptionDryRun:        true,
+				actions.OptionOutput:        "json",
+				actions.OptionTLSSkipVerify: false,
+			},
+		},
+		{
+			name:  "no environment",
+			args:  []string{"env", "update-lib"},
+			isErr: true,
+		},
+	}
+
+	runTestCmd(t, cases)
+}
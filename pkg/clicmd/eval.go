@@ -0,0 +1,98 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vEvalEnv    = "eval-env"
+	vEvalFormat = "eval-format"
+)
+
+var (
+	evalLong = `
+The ` + "`eval`" + ` command evaluates a jsonnet expression, or a jsonnet file, as if it
+were a component of the app. It has the same import paths, external variables,
+and environment parameters as components, so it can be used to debug parameters
+and libraries without creating a component:
+
+* ` + "`std.extVar(\"__ksonnet/params\")`" + ` is the environment's parameters
+* ` + "`std.extVar(\"__ksonnet/environments\")`" + ` is the environment's destination
+* ` + "`k.libsonnet`" + `, ` + "`lib/`" + `, and ` + "`vendor/`" + ` can be imported
+
+If the argument is the path of a file, the file is evaluated. Otherwise the
+argument is evaluated as an expression. The environment is the one given with
+` + "`--env`" + `, or the current environment.
+
+### Related Commands
+
+* ` + "`ks show` " + `— ` + showShortDesc + `
+* ` + "`ks param list` " + `— ` + paramShortDesc["list"] + `
+
+### Syntax
+`
+	evalExample = `
+# Show the parameters of the guestbook component in the 'dev' environment.
+ks eval 'std.extVar("__ksonnet/params").components.guestbook' --env dev
+
+# Evaluate a file which uses a library, and print it as YAML.
+ks eval debug.jsonnet -o yaml
+
+# Evaluate an expression with an external variable.
+ks eval 'std.extVar("name")' -V name=guestbook`
+)
+
+func newEvalCmd(a app.App) *cobra.Command {
+	evalCmd := &cobra.Command{
+		Use:     "eval <expr-or-file>",
+		Short:   "Evaluate jsonnet with the app's import paths and parameters",
+		Long:    evalLong,
+		Example: evalExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("'eval' requires an expression or a file")
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:        a,
+				actions.OptionEnvName:    viper.GetString(vEvalEnv),
+				actions.OptionExpression: args[0],
+				actions.OptionFormat:     viper.GetString(vEvalFormat),
+			}
+
+			if err := extractJsonnetFlags(a, "eval"); err != nil {
+				return errors.Wrap(err, "handle jsonnet flags")
+			}
+
+			return runAction(actionEval, m)
+		},
+	}
+	bindJsonnetFlags(evalCmd, "eval")
+
+	evalCmd.Flags().String(flagEnv, "", "Environment to evaluate in")
+	viper.BindPFlag(vEvalEnv, evalCmd.Flags().Lookup(flagEnv))
+
+	evalCmd.Flags().StringP(flagFormat, shortFormat, "json", "Output format.  Supported values are: json, yaml")
+	viper.BindPFlag(vEvalFormat, evalCmd.Flags().Lookup(flagFormat))
+
+	return evalCmd
+}
@@ -0,0 +1,61 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/actions"
+)
+
+func Test_evalCmd(t *testing.T) {
+	cases := []cmdTestCase{
+		{
+			name:   "with no options",
+			args:   []string{"eval", "1 + 1"},
+			action: actionEval,
+			expected: map[string]interface{}{
+				actions.OptionApp:        nil,
+				actions.OptionEnvName:    "",
+				actions.OptionExpression: "1 + 1",
+				actions.OptionFormat:     "json",
+			},
+		},
+		{
+			name:   "with options",
+			args:   []string{"eval", "debug.jsonnet", "--env", "dev", "-o", "yaml"},
+			action: actionEval,
+			expected: map[string]interface{}{
+				actions.OptionApp:        nil,
+				actions.OptionEnvName:    "dev",
+				actions.OptionExpression: "debug.jsonnet",
+				actions.OptionFormat:     "yaml",
+			},
+		},
+		{
+			name:  "without an expression",
+			args:  []string{"eval"},
+			isErr: true,
+		},
+		{
+			name:  "invalid jsonnet flag",
+			args:  []string{"eval", "1 + 1", "--ext-str", "foo"},
+			isErr: true,
+		},
+	}
+
+	runTestCmd(t, cases)
+}
@@ -0,0 +1,57 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"io"
+
+	"github.com/ksonnet/ksonnet/pkg/log"
+	"github.com/ksonnet/ksonnet/pkg/util/event"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+// stopEvents unsubscribes the event renderer of the running command.
+var stopEvents func()
+
+// startEvents subscribes the renderer for the requested log format to the
+// event bus. With the json format, logs and events are both written as JSON.
+func startEvents(w io.Writer) error {
+	if stopEvents != nil {
+		stopEvents()
+		stopEvents = nil
+	}
+
+	format := viper.GetString(flagLogFormat)
+	switch format {
+	case "", logFormatText:
+		stopEvents = event.Subscribe(event.NewLogRenderer(logrus.StandardLogger()))
+	case logFormatJSON:
+		log.UseJSON()
+		stopEvents = event.Subscribe(event.NewJSONRenderer(w))
+	default:
+		return errors.Errorf("invalid log format %q; valid formats are %s and %s",
+			format, logFormatText, logFormatJSON)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/util/event"
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_events(t *testing.T) {
+	cases := []struct {
+		name     string
+		args     []string
+		expected string
+		isErr    bool
+	}{
+		{
+			name:     "text",
+			args:     []string{"env", "list"},
+			expected: `msg="created Service guestbook" event=ResourceApplied`,
+		},
+		{
+			name:     "json",
+			args:     []string{"env", "list", "--log-format", "json"},
+			expected: `{"type":"ResourceApplied","time":`,
+		},
+		{
+			name:  "invalid log format",
+			args:  []string{"env", "list", "--log-format", "yaml"},
+			isErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			override := func(map[string]interface{}) error {
+				event.Publish(event.ResourceApplied{Kind: "Service", Name: "guestbook", Action: "created"})
+				return nil
+			}
+
+			withCmd(actionEnvList, override, func() {
+				fs := afero.NewMemMapFs()
+				test.StageFile(t, fs, "app.yaml", "/app/app.yaml")
+
+				root, err := NewRoot(fs, "/app", tc.args)
+				require.NoError(t, err)
+
+				var buf bytes.Buffer
+				root.SetOutput(&buf)
+
+				err = root.Execute()
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+
+				assert.Contains(t, buf.String(), tc.expected)
+			})
+		})
+	}
+}
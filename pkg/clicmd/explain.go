@@ -0,0 +1,117 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vExplainEnv       = "explain-env"
+	vExplainLive      = "explain-live"
+	vExplainNamespace = "explain-namespace"
+	vExplainOutput    = "explain-output"
+	explainShortDesc  = "Show where a rendered object came from"
+)
+
+var (
+	explainLong = `
+The ` + "`explain`" + ` command renders an environment, and shows where an object it
+renders came from: the component which rendered it, the path of the component's
+file, and the prototype and package version the component was generated from.
+
+The object is given as ` + "`<kind>/<name>`" + `. The kind is matched ignoring case, and
+can include the API group, such as ` + "`deployment.apps`" + `. Every object with the
+kind and name is shown, unless ` + "`--namespace`" + ` selects one.
+
+With ` + "`--live`" + `, the object is read from the environment's cluster instead. Its
+source is read from the annotations it was applied with, and its fields are
+matched with the component's parameters, to show which parameter sets each
+field. A field is matched with a parameter when they have the same value.
+
+To annotate the objects an environment renders with their sources for every
+command, set ` + "`sourceMap: true`" + ` on the environment in ` + "`app.yaml`" + `.
+
+### Related Commands
+
+* ` + "`ks show` " + `— ` + showShortDesc + `
+* ` + "`ks component list` " + `— List known components
+
+### Syntax
+`
+	explainExample = `
+# Show where the Deployment 'redis' of the current environment came from.
+ks explain deployment/redis
+
+# Show where the Service 'redis' in the 'cache' namespace of the 'prod'
+# environment came from, as JSON.
+ks explain service/redis --env prod --namespace cache -o json
+
+# Show which parameters set the fields of the live Deployment 'redis'.
+ks explain deployment.apps/redis --live`
+)
+
+func newExplainCmd(a app.App) *cobra.Command {
+	explainClientConfig := client.NewDefaultClientConfig(a)
+
+	explainCmd := &cobra.Command{
+		Use:     "explain <kind>/<name> [--env <env-name>] [--live]",
+		Short:   explainShortDesc,
+		Long:    explainLong,
+		Example: explainExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("'explain' requires an object in the form <kind>/<name>")
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:          a,
+				actions.OptionClientConfig: explainClientConfig,
+				actions.OptionObject:       args[0],
+				actions.OptionEnvName:      viper.GetString(vExplainEnv),
+				actions.OptionNamespace:    viper.GetString(vExplainNamespace),
+				actions.OptionLive:         viper.GetBool(vExplainLive),
+				actions.OptionOutput:       viper.GetString(vExplainOutput),
+			}
+
+			if err := extractJsonnetFlags(a, "explain"); err != nil {
+				return errors.Wrap(err, "handle jsonnet flags")
+			}
+
+			return runAction(actionExplain, m)
+		},
+	}
+	bindJsonnetFlags(explainCmd, "explain")
+	explainClientConfig.BindClientGoFlags(explainCmd)
+
+	explainCmd.Flags().String(flagEnv, "", "Environment to render")
+	viper.BindPFlag(vExplainEnv, explainCmd.Flags().Lookup(flagEnv))
+
+	explainCmd.Flags().Bool(flagLive, false, "Read the object from the environment's cluster, and show the parameters which set its fields")
+	viper.BindPFlag(vExplainLive, explainCmd.Flags().Lookup(flagLive))
+
+	explainCmd.Flags().String(flagNamespace, "", "Namespace of the object")
+	viper.BindPFlag(vExplainNamespace, explainCmd.Flags().Lookup(flagNamespace))
+
+	addCmdOutput(explainCmd, vExplainOutput)
+
+	return explainCmd
+}
@@ -0,0 +1,62 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/actions"
+)
+
+func Test_explainCmd(t *testing.T) {
+	cases := []cmdTestCase{
+		{
+			name:   "with an object",
+			args:   []string{"explain", "deployment/redis"},
+			action: actionExplain,
+			expected: map[string]interface{}{
+				actions.OptionApp:          nil,
+				actions.OptionClientConfig: nil,
+				actions.OptionObject:       "deployment/redis",
+				actions.OptionEnvName:      "",
+				actions.OptionNamespace:    "",
+				actions.OptionLive:         false,
+				actions.OptionOutput:       "",
+			},
+		},
+		{
+			name:   "with options",
+			args:   []string{"explain", "service/redis", "--env", "prod", "--namespace", "cache", "--live", "-o", "json"},
+			action: actionExplain,
+			expected: map[string]interface{}{
+				actions.OptionApp:          nil,
+				actions.OptionClientConfig: nil,
+				actions.OptionObject:       "service/redis",
+				actions.OptionEnvName:      "prod",
+				actions.OptionNamespace:    "cache",
+				actions.OptionLive:         true,
+				actions.OptionOutput:       "json",
+			},
+		},
+		{
+			name:  "without an object",
+			args:  []string{"explain"},
+			isErr: true,
+		},
+	}
+
+	runTestCmd(t, cases)
+}
@@ -0,0 +1,97 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vExportGitOps    = "export-gitops"
+	vExportKustomize = "export-kustomize"
+	exportShortDesc  = "Export the manifests of every environment to a directory"
+)
+
+var (
+	exportLong = `
+The ` + "`export`" + ` command renders the manifests of every environment of the app, and
+writes them to a directory. By default, each environment is written to a single
+YAML file named after it, e.g. ` + "`<dir>/us-west/dev.yaml`" + `, which can be applied
+with ` + "`ks apply --from-stdin`" + `.
+
+With ` + "`--gitops`" + `, the manifests are written in a layout for GitOps tools such as
+Argo CD and Flux. Each environment gets its own directory, with one file per
+object named after its kind, namespace, and name, so files are stable between
+exports. The directories of environments are replaced, and the directories of
+environments which no longer exist are removed. ` + "`index.yaml`" + ` lists the
+environments, their destinations, and their objects. With ` + "`--kustomize`" + `, each
+directory also contains a ` + "`kustomization.yaml`" + ` listing its objects.
+
+### Related Commands
+
+* ` + "`ks show` " + `— ` + showShortDesc + `
+* ` + "`ks apply` " + `— ` + applyShortDesc + `
+
+### Syntax
+`
+	exportExample = `
+# Write the manifests of each environment to a YAML file in 'manifests/'.
+ks export manifests
+
+# Write each environment to a directory of 'deploy/', for Argo CD or Flux to
+# sync, with a kustomization in each directory.
+ks export deploy --gitops --kustomize`
+)
+
+func newExportCmd(a app.App) *cobra.Command {
+	exportCmd := &cobra.Command{
+		Use:     "export <dir> [--gitops [--kustomize]]",
+		Short:   exportShortDesc,
+		Long:    exportLong,
+		Example: exportExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("'export' requires a directory to export to")
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:       a,
+				actions.OptionPath:      args[0],
+				actions.OptionGitOps:    viper.GetBool(vExportGitOps),
+				actions.OptionKustomize: viper.GetBool(vExportKustomize),
+			}
+
+			if err := extractJsonnetFlags(a, "export"); err != nil {
+				return errors.Wrap(err, "handle jsonnet flags")
+			}
+
+			return runAction(actionExport, m)
+		},
+	}
+	bindJsonnetFlags(exportCmd, "export")
+
+	exportCmd.Flags().Bool(flagGitOps, false, "Write each environment to its own directory, with one file per object and an index, for GitOps tools")
+	viper.BindPFlag(vExportGitOps, exportCmd.Flags().Lookup(flagGitOps))
+
+	exportCmd.Flags().Bool(flagKustomize, false, "Write a kustomization.yaml to each environment's directory when --"+flagGitOps+" is specified")
+	viper.BindPFlag(vExportKustomize, exportCmd.Flags().Lookup(flagKustomize))
+
+	return exportCmd
+}
@@ -0,0 +1,61 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/actions"
+)
+
+func Test_exportCmd(t *testing.T) {
+	cases := []cmdTestCase{
+		{
+			name:   "with no options",
+			args:   []string{"export", "manifests"},
+			action: actionExport,
+			expected: map[string]interface{}{
+				actions.OptionApp:       nil,
+				actions.OptionPath:      "manifests",
+				actions.OptionGitOps:    false,
+				actions.OptionKustomize: false,
+			},
+		},
+		{
+			name:   "gitops",
+			args:   []string{"export", "deploy", "--gitops", "--kustomize"},
+			action: actionExport,
+			expected: map[string]interface{}{
+				actions.OptionApp:       nil,
+				actions.OptionPath:      "deploy",
+				actions.OptionGitOps:    true,
+				actions.OptionKustomize: true,
+			},
+		},
+		{
+			name:  "without a directory",
+			args:  []string{"export"},
+			isErr: true,
+		},
+		{
+			name:  "invalid jsonnet flag",
+			args:  []string{"export", "deploy", "--ext-str", "foo"},
+			isErr: true,
+		},
+	}
+
+	runTestCmd(t, cases)
+}
@@ -23,49 +23,120 @@ import (
 const (
 	// For use in the commands (e.g., diff, apply, delete) that require either an
 	// environment or the -f flag.
+	flagAddr                  = "addr"
+	flagAddress               = "address"
+	flagAllEnvs               = "all-envs"
+	flagAllowGitRemote        = "allow-git-remote"
+	flagAppDir                = "app-dir"
 	flagAPISpec               = "api-spec"
 	flagAsString              = "as-string"
+	flagBatchSize             = "batch-size"
+	flagBundle                = "bundle"
+	flagBurst                 = "burst"
 	flagComponent             = "component"
+	flagContainer             = "container"
 	flagCreate                = "create"
+	flagDebounce              = "debounce"
+	flagDefault               = "default"
+	flagDiffProgram           = "diff-program"
+	flagDiffStrategy          = "diff-strategy"
 	flagDir                   = "dir"
+	flagDisable               = "disable"
 	flagDryRun                = "dry-run"
+	flagEnable                = "enable"
 	flagEnv                   = "env"
 	flagExtVar                = "ext-str"
 	flagExtVarFile            = "ext-str-file"
 	flagFilename              = "filename"
+	flagFollow                = "follow"
 	flagForce                 = "force"
 	flagFormat                = "format"
+	flagFromCluster           = "from-cluster"
+	flagFromContext           = "from-context"
+	flagFromStdin             = "from-stdin"
+	flagGcAppLabel            = "gc-app-label"
+	flagGcEnvLabel            = "gc-env-label"
+	flagGcLabels              = "gc-labels"
 	flagGcTag                 = "gc-tag"
+	flagGitOps                = "gitops"
 	flagGracePeriod           = "grace-period"
+	flagIfExists              = "if-exists"
+	flagImport                = "import"
+	flagImportSelector        = "import-selector"
 	flagInstalled             = "installed"
 	flagJpath                 = "jpath"
+	flagKustomize             = "kustomize"
+	flagLive                  = "live"
+	flagLogFormat             = "log-format"
+	flagLogLevel              = "log-level"
+	flagMaxUnavailable        = "max-unavailable-clusters"
+	flagMessage               = "message"
+	flagMetricsAddr           = "metrics-addr"
+	flagMetricsFile           = "metrics-file"
 	flagModule                = "module"
 	flagNamespace             = "namespace"
+	flagParallelism           = "parallelism"
+	flagPart                  = "part"
+	flagPath                  = "path"
+	flagPin                   = "pin"
+	flagPruneNamespaces       = "prune-namespaces"
+	flagQPS                   = "qps"
+	flagRegistry              = "registry"
+	flagRemote                = "remote"
 	flagResolveImage          = "resolve-image"
+	flagRoot                  = "root"
+	flagSince                 = "since"
 	flagServer                = "server"
 	flagSet                   = "set"
 	flagSkipDefaultRegistries = "skip-default-registries"
+	flagShowSecrets           = "show-secrets"
 	flagSkipGc                = "skip-gc"
+	flagStrict                = "strict"
+	flagTail                  = "tail"
 	flagTlaVar                = "tla-str"
 	flagTlaVarFile            = "tla-str-file"
 	flagTLSSkipVerify         = "tls-skip-verify"
+	flagToken                 = "token"
+	flagTraceEndpoint         = "trace-endpoint"
+	flagTraceFile             = "trace-file"
+	flagTraceFormat           = "trace-format"
+	flagObjects               = "objects"
+	flagOffline               = "offline"
 	flagOutput                = "output"
 	flagOverride              = "override"
 	flagUnset                 = "unset"
 	flagVerbose               = "verbose"
+	flagVerifyReproducible    = "verify-reproducible"
 	flagVersion               = "version"
+	flagWait                  = "wait"
+	flagWaitTimeout           = "wait-timeout"
+	flagWatch                 = "watch"
+	flagWatchInterval         = "watch-interval"
+	flagWithDependencies      = "with-dependencies"
+	flagWithDependents        = "with-dependents"
 	flagWithoutModules        = "without-modules"
+	flagYes                   = "yes"
 
 	shortComponent = "c"
+	shortContainer = "c"
 	shortFilename  = "f"
+	shortFollow    = "f"
 	shortFormat    = "o"
 	shortOutput    = "o"
 	shortOverride  = "o"
+	shortYes       = "y"
 )
 
+// addCmdAssumeYes adds a flag to a destructive command, which confirms it
+// without a prompt. `name` is the name of the viper assignment.
+func addCmdAssumeYes(cmd *cobra.Command, name string) {
+	cmd.Flags().BoolP(flagYes, shortYes, false, "Confirm without a prompt. Required when not running in a terminal")
+	viper.BindPFlag(name, cmd.Flags().Lookup(flagYes))
+}
+
 // addCmdOutput adds an output flag to a command. `name` is the name
 // of the viper assignment.
 func addCmdOutput(cmd *cobra.Command, name string) {
-	cmd.Flags().StringP(flagOutput, shortOutput, "", "Output format. Valid options: table|json")
+	cmd.Flags().StringP(flagOutput, shortOutput, "", "Output format. Valid options: table|json|yaml")
 	viper.BindPFlag(name, cmd.Flags().Lookup(flagOutput))
 }
@@ -0,0 +1,85 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vFmtCheck    = "fmt-check"
+	fmtShortDesc = "Normalize the formatting of params.libsonnet files"
+)
+
+var (
+	fmtLong = `
+The ` + "`fmt`" + ` command normalizes the ` + "`params.libsonnet`" + ` files of the app's modules and
+environments, so that edits to parameters produce small diffs. The parameters
+of each component and the global parameters are sorted by key, components
+without parameters are removed, and strings are quoted consistently. The names
+of the files which are reformatted are printed.
+
+Parameter changes made with ` + "`ks param set`" + ` and ` + "`ks param delete`" + ` are formatted
+automatically. Files with comments which can not be kept, such as comments in
+empty objects, are skipped with a warning.
+
+With the ` + "`--check`" + ` flag, files are not changed. The command fails if any are
+not formatted, so it can be run in CI.
+
+### Related Commands
+
+* ` + "`ks param set` " + `— ` + paramShortDesc["set"] + `
+* ` + "`ks lint` " + `— ` + lintShortDesc + `
+
+### Syntax
+`
+	fmtExample = `
+# Format the app's params.libsonnet files.
+ks fmt
+
+# List the params.libsonnet files which are not formatted, and fail if there are any.
+ks fmt --check`
+)
+
+func newFmtCmd(a app.App) *cobra.Command {
+	fmtCmd := &cobra.Command{
+		Use:     "fmt",
+		Short:   fmtShortDesc,
+		Long:    fmtLong,
+		Example: fmtExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return errors.New("'fmt' takes no arguments")
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:   a,
+				actions.OptionCheck: viper.GetBool(vFmtCheck),
+			}
+
+			return runAction(actionFmt, m)
+		},
+	}
+
+	fmtCmd.Flags().Bool("check", false, "List files which are not formatted instead of reformatting them")
+	viper.BindPFlag(vFmtCheck, fmtCmd.Flags().Lookup("check"))
+
+	return fmtCmd
+}
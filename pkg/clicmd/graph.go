@@ -0,0 +1,105 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vGraphFormat  = "graph-format"
+	vGraphObjects = "graph-objects"
+)
+
+var (
+	graphLong = `
+The ` + "`graph`" + ` command writes a graph of the app, to visualize how a large app
+fits together. The graph has:
+
+* Each module, the modules it contains, and its components
+* The prototype each component was generated from, and the package the
+  prototype came from
+* The packages each component imports
+* Each environment, and the modules it targets
+
+With an environment argument, only that environment is included. With
+` + "`--objects`" + `, the environment (or the current environment) is rendered,
+and the graph includes the objects each component renders, and the objects
+which own them.
+
+The graph is written in the Graphviz DOT language, or with ` + "`--format mermaid`" + `
+as a Mermaid flowchart, which can be embedded in Markdown.
+
+### Related Commands
+
+* ` + "`ks inventory` " + `— Report the objects, images, prototypes, and packages an environment deploys
+* ` + "`ks module list` " + `— List modules
+
+### Syntax
+`
+	graphExample = `
+# Draw the app with Graphviz.
+ks graph | dot -Tsvg > app.svg
+
+# Write a Mermaid flowchart of the 'prod' environment and the objects it
+# renders.
+ks graph prod --format mermaid --objects`
+)
+
+func newGraphCmd(a app.App) *cobra.Command {
+	graphCmd := &cobra.Command{
+		Use:     "graph [<env-name>]",
+		Short:   "Write a graph of modules, components, packages, and environments",
+		Long:    graphLong,
+		Example: graphExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				return errors.New("'graph' takes at most one environment")
+			}
+
+			var envName string
+			if len(args) == 1 {
+				envName = args[0]
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:     a,
+				actions.OptionEnvName: envName,
+				actions.OptionFormat:  viper.GetString(vGraphFormat),
+				actions.OptionObjects: viper.GetBool(vGraphObjects),
+			}
+
+			if err := extractJsonnetFlags(a, "graph"); err != nil {
+				return errors.Wrap(err, "handle jsonnet flags")
+			}
+
+			return runAction(actionGraph, m)
+		},
+	}
+	bindJsonnetFlags(graphCmd, "graph")
+
+	graphCmd.Flags().String(flagFormat, "dot", "Graph format. Supported values are: dot, mermaid")
+	viper.BindPFlag(vGraphFormat, graphCmd.Flags().Lookup(flagFormat))
+
+	graphCmd.Flags().Bool(flagObjects, false, "Render the environment and include its objects")
+	viper.BindPFlag(vGraphObjects, graphCmd.Flags().Lookup(flagObjects))
+
+	return graphCmd
+}
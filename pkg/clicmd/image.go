@@ -0,0 +1,39 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/spf13/cobra"
+)
+
+func newImageCmd(a app.App) *cobra.Command {
+	imageCmd := &cobra.Command{
+		Use:   "image",
+		Short: "List, set, and pin the container images of components",
+		Long: `List the container images components render to, and the parameters which set
+them. Images are set by setting those parameters, so CD systems can bump images
+without knowing how each component lays out its parameters. Images can be pinned
+to the digests of their tags, and checked for newer tags, in their registries.`,
+	}
+
+	imageCmd.AddCommand(newImageListCmd(a))
+	imageCmd.AddCommand(newImageOutdatedCmd(a))
+	imageCmd.AddCommand(newImagePinCmd(a))
+	imageCmd.AddCommand(newImageSetCmd(a))
+
+	return imageCmd
+}
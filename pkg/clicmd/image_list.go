@@ -0,0 +1,94 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vImageListComponent = "image-list-component"
+	vImageListEnv       = "image-list-env"
+	vImageListOutput    = "image-list-output"
+)
+
+var (
+	imageListLong = `
+The ` + "`list`" + ` command renders the components of an environment, and lists the
+image of each of their containers and init containers, with the component
+parameter which sets it. A parameter sets an image if its value is the image,
+or the image's tag or digest. Images without a parameter are hard coded in the
+component.
+
+The environment is the one given with ` + "`--env`" + `, or the current environment.
+
+### Related Commands
+
+* ` + "`ks image set` " + `— Set the image of a component
+* ` + "`ks param list` " + `— ` + paramShortDesc["list"] + `
+
+### Syntax
+`
+	imageListExample = `
+# List the images of every component in the current environment.
+ks image list
+
+# List the images of the guestbook component in the 'prod' environment as JSON.
+ks image list --env prod -c guestbook -o json`
+)
+
+func newImageListCmd(a app.App) *cobra.Command {
+	imageListCmd := &cobra.Command{
+		Use:     "list [--env <env-name>] [-c <component-name>]",
+		Short:   "List the images of components and the parameters which set them",
+		Long:    imageListLong,
+		Example: imageListExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return errors.New("'image list' takes no arguments")
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:            a,
+				actions.OptionComponentNames: viper.GetStringSlice(vImageListComponent),
+				actions.OptionEnvName:        viper.GetString(vImageListEnv),
+				actions.OptionOutput:         viper.GetString(vImageListOutput),
+			}
+
+			if err := extractJsonnetFlags(a, "image-list"); err != nil {
+				return errors.Wrap(err, "handle jsonnet flags")
+			}
+
+			return runAction(actionImageList, m)
+		},
+	}
+	bindJsonnetFlags(imageListCmd, "image-list")
+
+	imageListCmd.Flags().StringSliceP(flagComponent, shortComponent, nil, "Name of a specific component (multiple -c flags accepted)")
+	viper.BindPFlag(vImageListComponent, imageListCmd.Flags().Lookup(flagComponent))
+
+	imageListCmd.Flags().String(flagEnv, "", "Environment to render the components in")
+	viper.BindPFlag(vImageListEnv, imageListCmd.Flags().Lookup(flagEnv))
+
+	imageListCmd.Flags().StringP(flagOutput, shortOutput, "", "Output format. Valid options: table|json|yaml")
+	viper.BindPFlag(vImageListOutput, imageListCmd.Flags().Lookup(flagOutput))
+
+	return imageListCmd
+}
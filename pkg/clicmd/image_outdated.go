@@ -0,0 +1,98 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vImageOutdatedComponent = "image-outdated-component"
+	vImageOutdatedEnv       = "image-outdated-env"
+	vImageOutdatedOutput    = "image-outdated-output"
+)
+
+var (
+	imageOutdatedLong = `
+The ` + "`outdated`" + ` command renders the components of an environment, and lists the
+images they deploy which have newer tags in their registries, with the latest
+tag and the parameter which sets the image.
+
+Only tags with the same shape as the deployed tag are compared, so an image
+tagged ` + "`1.25-alpine`" + ` is compared with ` + "`1.26-alpine`" + `, but not ` + "`1.26`" + ` or
+` + "`latest`" + `. Images which are only pinned to a digest are not compared.
+Registries are accessed with the credentials in the docker client config,
+` + "`~/.docker/config.json`" + `.
+
+The environment is the one given with ` + "`--env`" + `, or the current environment.
+
+### Related Commands
+
+* ` + "`ks image list` " + `— List the images of components and the parameters which set them
+* ` + "`ks image set` " + `— Set the image of a component
+
+### Syntax
+`
+	imageOutdatedExample = `
+# List the outdated images of the current environment.
+ks image outdated
+
+# List the outdated images of the guestbook component in the 'prod'
+# environment as JSON.
+ks image outdated --env prod -c guestbook -o json`
+)
+
+func newImageOutdatedCmd(a app.App) *cobra.Command {
+	imageOutdatedCmd := &cobra.Command{
+		Use:     "outdated [--env <env-name>] [-c <component-name>]",
+		Short:   "List images which have newer tags in their registries",
+		Long:    imageOutdatedLong,
+		Example: imageOutdatedExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return errors.New("'image outdated' takes no arguments")
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:            a,
+				actions.OptionComponentNames: viper.GetStringSlice(vImageOutdatedComponent),
+				actions.OptionEnvName:        viper.GetString(vImageOutdatedEnv),
+				actions.OptionOutput:         viper.GetString(vImageOutdatedOutput),
+			}
+
+			if err := extractJsonnetFlags(a, "image-outdated"); err != nil {
+				return errors.Wrap(err, "handle jsonnet flags")
+			}
+
+			return runAction(actionImageOutdated, m)
+		},
+	}
+	bindJsonnetFlags(imageOutdatedCmd, "image-outdated")
+
+	imageOutdatedCmd.Flags().StringSliceP(flagComponent, shortComponent, nil, "Name of a specific component (multiple -c flags accepted)")
+	viper.BindPFlag(vImageOutdatedComponent, imageOutdatedCmd.Flags().Lookup(flagComponent))
+
+	imageOutdatedCmd.Flags().String(flagEnv, "", "Environment to render the components in")
+	viper.BindPFlag(vImageOutdatedEnv, imageOutdatedCmd.Flags().Lookup(flagEnv))
+
+	addCmdOutput(imageOutdatedCmd, vImageOutdatedOutput)
+
+	return imageOutdatedCmd
+}
@@ -0,0 +1,95 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vImagePinComponent = "image-pin-component"
+	vImagePinEnv       = "image-pin-env"
+)
+
+var (
+	imagePinLong = `
+The ` + "`pin`" + ` command resolves the tags of components' images to digests in their
+registries, and sets the parameters which hold the images to the digests. Pinned
+images do not change when a tag is pushed again, so every environment runs the
+image which was tested.
+
+Only images held whole by a parameter are pinned. Images whose parameter only
+holds the tag, and images hard coded in a component, are skipped with a warning.
+Registries are accessed with the credentials in the docker client config,
+` + "`~/.docker/config.json`" + `.
+
+If an environment is given with ` + "`--env`" + `, the parameters are overridden in that
+environment. Otherwise the components' parameters are set, and the current
+environment is used to find their images.
+
+### Related Commands
+
+* ` + "`ks image set` " + `— Set the image of a component
+* ` + "`ks image outdated` " + `— List images which have newer tags in their registries
+
+### Syntax
+`
+	imagePinExample = `
+# Pin the images of every component.
+ks image pin
+
+# Pin the images of the guestbook component in the 'prod' environment.
+ks image pin -c guestbook --env prod`
+)
+
+func newImagePinCmd(a app.App) *cobra.Command {
+	imagePinCmd := &cobra.Command{
+		Use:     "pin [--env <env-name>] [-c <component-name>]",
+		Short:   "Pin the images of components to the digests of their tags",
+		Long:    imagePinLong,
+		Example: imagePinExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return errors.New("'image pin' takes no arguments")
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:            a,
+				actions.OptionComponentNames: viper.GetStringSlice(vImagePinComponent),
+				actions.OptionEnvName:        viper.GetString(vImagePinEnv),
+			}
+
+			if err := extractJsonnetFlags(a, "image-pin"); err != nil {
+				return errors.Wrap(err, "handle jsonnet flags")
+			}
+
+			return runAction(actionImagePin, m)
+		},
+	}
+	bindJsonnetFlags(imagePinCmd, "image-pin")
+
+	imagePinCmd.Flags().StringSliceP(flagComponent, shortComponent, nil, "Name of a specific component (multiple -c flags accepted)")
+	viper.BindPFlag(vImagePinComponent, imagePinCmd.Flags().Lookup(flagComponent))
+
+	imagePinCmd.Flags().String(flagEnv, "", "Environment to pin the images in")
+	viper.BindPFlag(vImagePinEnv, imagePinCmd.Flags().Lookup(flagEnv))
+
+	return imagePinCmd
+}
@@ -0,0 +1,105 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vImageSetEnv = "image-set-env"
+	vImageSetPin = "image-set-pin"
+)
+
+var (
+	imageSetLong = `
+The ` + "`set`" + ` command sets the image of a component's containers which use the
+same repository as the new image, by setting the component parameters which
+hold them. If a parameter holds the whole image, it is set to the new image.
+If it only holds the tag, it is set to the new tag.
+
+If an environment is given with ` + "`--env`" + `, the parameters are overridden in that
+environment. Otherwise the component's parameters are set, and the current
+environment is used to find its images.
+
+With ` + "`--pin`" + `, the image's tag is resolved to a digest in its registry, and the
+image is set to the digest. Registries are accessed with the credentials in the
+docker client config, ` + "`~/.docker/config.json`" + `.
+
+It is an error if the component does not use an image from the repository, or
+the image is hard coded in the component.
+
+### Related Commands
+
+* ` + "`ks image list` " + `— List the images of components and the parameters which set them
+* ` + "`ks image pin` " + `— Pin the images of components to the digests of their tags
+* ` + "`ks param set` " + `— ` + paramShortDesc["set"] + `
+
+### Syntax
+`
+	imageSetExample = `
+# Set the image of the guestbook component to nginx:1.25.
+ks image set guestbook nginx:1.25
+
+# Pin the image of the guestbook component to a digest in the 'prod'
+# environment.
+ks image set guestbook nginx@sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31 --env prod
+
+# Set the image of the guestbook component to the digest nginx:1.25 currently
+# refers to.
+ks image set guestbook nginx:1.25 --pin`
+)
+
+func newImageSetCmd(a app.App) *cobra.Command {
+	imageSetCmd := &cobra.Command{
+		Use:     "set <component-name> <image> [--env <env-name>] [--pin]",
+		Short:   "Set the image of a component",
+		Long:    imageSetLong,
+		Example: imageSetExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return errors.New("'image set' requires a component name and an image")
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:           a,
+				actions.OptionComponentName: args[0],
+				actions.OptionImage:         args[1],
+				actions.OptionEnvName:       viper.GetString(vImageSetEnv),
+				actions.OptionResolveImage:  viper.GetBool(vImageSetPin),
+			}
+
+			if err := extractJsonnetFlags(a, "image-set"); err != nil {
+				return errors.Wrap(err, "handle jsonnet flags")
+			}
+
+			return runAction(actionImageSet, m)
+		},
+	}
+	bindJsonnetFlags(imageSetCmd, "image-set")
+
+	imageSetCmd.Flags().String(flagEnv, "", "Environment to set the image in")
+	viper.BindPFlag(vImageSetEnv, imageSetCmd.Flags().Lookup(flagEnv))
+
+	imageSetCmd.Flags().Bool(flagPin, false, "Resolve the image's tag to a digest in its registry")
+	viper.BindPFlag(vImageSetPin, imageSetCmd.Flags().Lookup(flagPin))
+
+	return imageSetCmd
+}
@@ -0,0 +1,123 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/actions"
+)
+
+func Test_imageCmd(t *testing.T) {
+	cases := []cmdTestCase{
+		{
+			name:   "list",
+			args:   []string{"image", "list"},
+			action: actionImageList,
+			expected: map[string]interface{}{
+				actions.OptionApp:            nil,
+				actions.OptionComponentNames: make([]string, 0),
+				actions.OptionEnvName:        "",
+				actions.OptionOutput:         "",
+			},
+		},
+		{
+			name:   "list components in an environment",
+			args:   []string{"image", "list", "--env", "prod", "-c", "guestbook", "-o", "json"},
+			action: actionImageList,
+			expected: map[string]interface{}{
+				actions.OptionApp:            nil,
+				actions.OptionComponentNames: []string{"guestbook"},
+				actions.OptionEnvName:        "prod",
+				actions.OptionOutput:         "json",
+			},
+		},
+		{
+			name:  "list with arguments",
+			args:  []string{"image", "list", "guestbook"},
+			isErr: true,
+		},
+		{
+			name:   "set",
+			args:   []string{"image", "set", "guestbook", "nginx:1.25"},
+			action: actionImageSet,
+			expected: map[string]interface{}{
+				actions.OptionApp:           nil,
+				actions.OptionComponentName: "guestbook",
+				actions.OptionImage:         "nginx:1.25",
+				actions.OptionEnvName:       "",
+				actions.OptionResolveImage:  false,
+			},
+		},
+		{
+			name:   "set in an environment",
+			args:   []string{"image", "set", "guestbook", "nginx:1.25", "--env", "prod"},
+			action: actionImageSet,
+			expected: map[string]interface{}{
+				actions.OptionApp:           nil,
+				actions.OptionComponentName: "guestbook",
+				actions.OptionImage:         "nginx:1.25",
+				actions.OptionEnvName:       "prod",
+				actions.OptionResolveImage:  false,
+			},
+		},
+		{
+			name:   "set a pinned image",
+			args:   []string{"image", "set", "guestbook", "nginx:1.25", "--pin"},
+			action: actionImageSet,
+			expected: map[string]interface{}{
+				actions.OptionApp:           nil,
+				actions.OptionComponentName: "guestbook",
+				actions.OptionImage:         "nginx:1.25",
+				actions.OptionEnvName:       "",
+				actions.OptionResolveImage:  true,
+			},
+		},
+		{
+			name:  "set without an image",
+			args:  []string{"image", "set", "guestbook"},
+			isErr: true,
+		},
+		{
+			name:   "pin",
+			args:   []string{"image", "pin", "-c", "guestbook", "--env", "prod"},
+			action: actionImagePin,
+			expected: map[string]interface{}{
+				actions.OptionApp:            nil,
+				actions.OptionComponentNames: []string{"guestbook"},
+				actions.OptionEnvName:        "prod",
+			},
+		},
+		{
+			name:  "pin with arguments",
+			args:  []string{"image", "pin", "guestbook"},
+			isErr: true,
+		},
+		{
+			name:   "outdated",
+			args:   []string{"image", "outdated", "-o", "yaml"},
+			action: actionImageOutdated,
+			expected: map[string]interface{}{
+				actions.OptionApp:            nil,
+				actions.OptionComponentNames: make([]string, 0),
+				actions.OptionEnvName:        "",
+				actions.OptionOutput:         "yaml",
+			},
+		},
+	}
+
+	runTestCmd(t, cases)
+}
@@ -35,6 +35,10 @@ const (
 	vInitDir                   = "init-dir"
 	vInitSkipDefaultRegistries = "init-skip-default-registries"
 	vInitEnvironment           = "init-environment"
+	vInitFromCluster           = "init-from-cluster"
+	vInitFromContext           = "init-from-context"
+	vInitImport                = "init-import"
+	vInitImportSelector        = "init-import-selector"
 )
 
 var (
@@ -69,6 +73,13 @@ including server configuration and an OpenAPI specification of a specific Kubern
 build. By default, this is generated using cluster information specified by the
 current context, in the file pointed to by` + " `$KUBECONFIG`" + `.
 
+With` + " `--from-cluster`" + `, an environment is created for each context in the
+kubeconfig (or each context given with` + " `--from-context`" + `) instead of a single
+default environment. Each environment is named after its context, and its
+` + "`ksonnet-lib`" + ` is generated for the Kubernetes version of its cluster. Objects
+of the kinds given with` + " `--import`" + ` are imported as YAML components from the
+namespace of the first environment.
+
 ### Related Commands
 
 * ` + "`ks generate` " + `— ` + protoShortDesc["use"] + `
@@ -99,6 +110,16 @@ ks init app-name --api-spec=version:v1.7.1
 # specific build of Kubernetes to generate 'ksonnet-lib'.
 ks init app-name --api-spec=file:swagger.json
 
+# Initialize a ksonnet application with an environment for each context in
+# the current kubeconfig file ($KUBECONFIG).
+ks init app-name --from-cluster
+
+# Initialize a ksonnet application with environments for the 'dev' context and
+# the 'dc-west' namespace of the 'prod' context, importing the deployments and
+# services labeled 'app=guestbook' in the 'dev' context's namespace as components.
+ks init app-name --from-cluster --from-context=dev --from-context=prod=dc-west \
+  --import=deployments --import=services --import-selector=app=guestbook
+
 # Initialize a ksonnet application, outputting the application directory into
 # the specified 'custom-location'.
 ks init app-name --dir=custom-location`
@@ -127,16 +148,21 @@ func newInitCmd(fs afero.Fs, wd string) *cobra.Command {
 				return err
 			}
 
-			clientConfig := client.NewDefaultClientConfig(nil)
-
-			server, namespace, err := resolveEnvFlags(flags, clientConfig)
-			if err != nil {
-				return err
-			}
+			fromCluster := viper.GetBool(vInitFromCluster)
 
+			var server, namespace string
 			specFlag := viper.GetString(vInitAPISpec)
-			if specFlag == "" {
-				specFlag = clientConfig.GetAPISpec()
+			if !fromCluster {
+				// Environments created from a cluster resolve their
+				// destinations and API specs from their contexts.
+				server, namespace, err = resolveEnvFlags(flags, clientConfig)
+				if err != nil {
+					return err
+				}
+
+				if specFlag == "" {
+					specFlag = clientConfig.GetAPISpec()
+				}
 			}
 
 			m := map[string]interface{}{
@@ -149,6 +175,11 @@ func newInitCmd(fs afero.Fs, wd string) *cobra.Command {
 				actions.OptionNamespace:             namespace,
 				actions.OptionSkipDefaultRegistries: viper.GetBool(vInitSkipDefaultRegistries),
 				actions.OptionTLSSkipVerify:         viper.GetBool(flagTLSSkipVerify),
+				actions.OptionFromCluster:           fromCluster,
+				actions.OptionContexts:              viper.GetStringSlice(vInitFromContext),
+				actions.OptionImportKinds:           viper.GetStringSlice(vInitImport),
+				actions.OptionImportSelector:        viper.GetString(vInitImportSelector),
+				actions.OptionClientConfig:          clientConfig,
 			}
 
 			return runAction(actionInit, m)
@@ -171,6 +202,18 @@ func newInitCmd(fs afero.Fs, wd string) *cobra.Command {
 	initCmd.Flags().String(flagEnv, "", "Name of initial environment to create")
 	viper.BindPFlag(vInitEnvironment, initCmd.Flag(flagEnv))
 
+	initCmd.Flags().Bool(flagFromCluster, false, "Create an environment for each kubeconfig context")
+	viper.BindPFlag(vInitFromCluster, initCmd.Flag(flagFromCluster))
+
+	initCmd.Flags().StringSlice(flagFromContext, nil, "Kubeconfig context to create an environment for, as <context>[=<namespace>] (multiple accepted; requires --from-cluster)")
+	viper.BindPFlag(vInitFromContext, initCmd.Flag(flagFromContext))
+
+	initCmd.Flags().StringSlice(flagImport, nil, "Kind of object to import as components from the cluster (multiple accepted; requires --from-cluster)")
+	viper.BindPFlag(vInitImport, initCmd.Flag(flagImport))
+
+	initCmd.Flags().String(flagImportSelector, "", "Label selector for the objects to import")
+	viper.BindPFlag(vInitImportSelector, initCmd.Flag(flagImportSelector))
+
 	return initCmd
 }
 
@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/stretchr/testify/mock"
 )
 
 func Test_initCmd(t *testing.T) {
@@ -42,6 +43,11 @@ func Test_initCmd(t *testing.T) {
 				actions.OptionNamespace:             "new-namespace",
 				actions.OptionSkipDefaultRegistries: false,
 				actions.OptionTLSSkipVerify:         false,
+				actions.OptionFromCluster:           false,
+				actions.OptionContexts:              []string{},
+				actions.OptionImportKinds:           []string{},
+				actions.OptionImportSelector:        "",
+				actions.OptionClientConfig:          mock.AnythingOfType("*client.Config"),
 			},
 		},
 		{
@@ -63,6 +69,38 @@ func Test_initCmd(t *testing.T) {
 				actions.OptionNamespace:             "new-namespace",
 				actions.OptionSkipDefaultRegistries: false,
 				actions.OptionTLSSkipVerify:         false,
+				actions.OptionFromCluster:           false,
+				actions.OptionContexts:              []string{},
+				actions.OptionImportKinds:           []string{},
+				actions.OptionImportSelector:        "",
+				actions.OptionClientConfig:          mock.AnythingOfType("*client.Config"),
+			},
+		},
+		{
+			name: "from cluster",
+			args: []string{"init", "app",
+				"--from-cluster",
+				"--from-context", "dev",
+				"--from-context", "prod=dc-west",
+				"--import", "deployments,services",
+				"--import-selector", "app=guestbook",
+			},
+			action: actionInit,
+			expected: map[string]interface{}{
+				actions.OptionFs:                    nil,
+				actions.OptionName:                  "app",
+				actions.OptionEnvName:               "",
+				actions.OptionRootPath:              "/app",
+				actions.OptionServer:                "",
+				actions.OptionSpecFlag:              "",
+				actions.OptionNamespace:             "",
+				actions.OptionSkipDefaultRegistries: false,
+				actions.OptionTLSSkipVerify:         false,
+				actions.OptionFromCluster:           true,
+				actions.OptionContexts:              []string{"dev", "prod=dc-west"},
+				actions.OptionImportKinds:           []string{"deployments", "services"},
+				actions.OptionImportSelector:        "app=guestbook",
+				actions.OptionClientConfig:          mock.AnythingOfType("*client.Config"),
 			},
 		},
 		{
@@ -0,0 +1,96 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vInventoryOutput = "inventory-output"
+)
+
+var (
+	inventoryLong = `
+The ` + "`inventory`" + ` command reports exactly what an environment deploys, for
+compliance audits. It renders the environment, and lists:
+
+* Every object, with its component and the container images it runs
+* Every component, with its source file, the prototype it was generated from,
+  and the packages it imports
+* Every package the components import, with the version the environment uses
+
+The prototype of a component is recorded when it is generated by
+` + "`ks prototype use`" + ` or ` + "`ks generate`" + `. Components written by hand, or
+generated by earlier versions of ksonnet, have no prototype.
+
+The environment is the one given as an argument, or the current environment.
+Use ` + "`-o json`" + ` or ` + "`-o yaml`" + ` for the full report.
+
+### Related Commands
+
+* ` + "`ks image list` " + `— List the images of components and the parameters which set them
+* ` + "`ks show` " + `— ` + showShortDesc + `
+
+### Syntax
+`
+	inventoryExample = `
+# Summarize what the current environment deploys.
+ks inventory
+
+# Write the full inventory of the 'prod' environment as JSON.
+ks inventory prod -o json`
+)
+
+func newInventoryCmd(a app.App) *cobra.Command {
+	inventoryCmd := &cobra.Command{
+		Use:     "inventory [<env-name>]",
+		Short:   "Report the objects, images, prototypes, and packages an environment deploys",
+		Long:    inventoryLong,
+		Example: inventoryExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				return errors.New("'inventory' takes at most one environment")
+			}
+
+			var envName string
+			if len(args) == 1 {
+				envName = args[0]
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:     a,
+				actions.OptionEnvName: envName,
+				actions.OptionOutput:  viper.GetString(vInventoryOutput),
+			}
+
+			if err := extractJsonnetFlags(a, "inventory"); err != nil {
+				return errors.Wrap(err, "handle jsonnet flags")
+			}
+
+			return runAction(actionInventory, m)
+		},
+	}
+	bindJsonnetFlags(inventoryCmd, "inventory")
+
+	addCmdOutput(inventoryCmd, vInventoryOutput)
+
+	return inventoryCmd
+}
@@ -0,0 +1,35 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/spf13/cobra"
+)
+
+func newJbCmd(a app.App) *cobra.Command {
+	jbCmd := &cobra.Command{
+		Use:   "jb",
+		Short: "Manage jsonnet-bundler dependencies",
+		Long: `Manage jsonnet libraries declared in a jsonnet-bundler ` + "`jsonnetfile.json`" + `
+in the app root. Libraries are installed in the app's ` + "`vendor/`" + ` directory,
+which components can import from.`,
+	}
+
+	jbCmd.AddCommand(newJbSyncCmd(a))
+
+	return jbCmd
+}
@@ -0,0 +1,69 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	jbSyncLong = `
+The ` + "`sync`" + ` command installs the jsonnet libraries declared in the app's
+` + "`jsonnetfile.json`" + `, and the libraries they depend on, in the app's ` + "`vendor/`" + `
+directory. It uses the same file format as jsonnet-bundler, so libraries such
+as kube-prometheus can be used in components:
+
+    local kp = import "kube-prometheus/kube-prometheus.libsonnet";
+
+The installed version of each library is written to ` + "`jsonnetfile.lock.json`" + `.
+Libraries in the lock file are installed at their locked version; remove a
+library from the lock file to update it. Git libraries are fetched with ` + "`git`" + `.
+
+### Related Commands
+
+* ` + "`ks pkg install` " + `— ` + pkgShortDesc["install"] + `
+
+### Syntax
+`
+	jbSyncExample = `
+# Install the libraries declared in jsonnetfile.json.
+ks jb sync`
+)
+
+func newJbSyncCmd(a app.App) *cobra.Command {
+	jbSyncCmd := &cobra.Command{
+		Use:     "sync",
+		Short:   "Install the libraries declared in jsonnetfile.json",
+		Long:    jbSyncLong,
+		Example: jbSyncExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return errors.New("'jb sync' takes no arguments")
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp: a,
+			}
+
+			return runAction(actionJbSync, m)
+		},
+	}
+
+	return jbSyncCmd
+}
@@ -0,0 +1,96 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vLintEnv      = "lint-env"
+	vLintOutput   = "lint-output"
+	vLintWrite    = "lint-write"
+	lintShortDesc = "Check the formatting of jsonnet files and lint them"
+)
+
+var (
+	lintLong = `
+The ` + "`lint`" + ` command checks that the jsonnet files in the app's ` + "`components/`" + `,
+` + "`lib/`" + `, and ` + "`vendor/`" + ` directories are formatted with ` + "`jsonnetfmt`" + `, and lints them
+with ` + "`jsonnet-lint`" + ` to find problems such as unused and unknown variables. The
+formatting of vendored parts is not checked, since they belong to their
+packages. Generated ksonnet-lib files are skipped.
+
+Imports are resolved in the same paths as components. The ` + "`k.libsonnet`" + ` of the
+environment given with ` + "`--env`" + `, or of the current environment, is used.
+
+With the ` + "`--write`" + ` flag, files which are not formatted are reformatted in
+place. The command fails if any problems are found, so it can be run in CI.
+
+The tools are found in $KS_JSONNETFMT and $KS_JSONNET_LINT, or the path. Both
+are part of go-jsonnet (https://github.com/google/go-jsonnet).
+
+### Related Commands
+
+* ` + "`ks validate` " + `— ` + valShortDesc + `
+
+### Syntax
+`
+	lintExample = `
+# Check the formatting of the app's jsonnet, and lint it.
+ks lint
+
+# Reformat files which are not formatted, and lint them.
+ks lint --write
+
+# Write problems as JSON.
+ks lint -o json`
+)
+
+func newLintCmd(a app.App) *cobra.Command {
+	lintCmd := &cobra.Command{
+		Use:     "lint",
+		Short:   lintShortDesc,
+		Long:    lintLong,
+		Example: lintExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return errors.New("'lint' takes no arguments")
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:     a,
+				actions.OptionEnvName: viper.GetString(vLintEnv),
+				actions.OptionWrite:   viper.GetBool(vLintWrite),
+				actions.OptionOutput:  viper.GetString(vLintOutput),
+			}
+
+			return runAction(actionLint, m)
+		},
+	}
+
+	addCmdOutput(lintCmd, vLintOutput)
+	lintCmd.Flags().String(flagEnv, "", "Environment whose ksonnet-lib is used to resolve imports")
+	viper.BindPFlag(vLintEnv, lintCmd.Flags().Lookup(flagEnv))
+	lintCmd.Flags().Bool("write", false, "Reformat files which are not formatted")
+	viper.BindPFlag(vLintWrite, lintCmd.Flags().Lookup("write"))
+
+	return lintCmd
+}
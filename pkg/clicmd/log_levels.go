@@ -0,0 +1,52 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/log"
+	"github.com/sirupsen/logrus"
+)
+
+// setLogLevels sets the log levels of subsystems from app.yaml,
+// $KS_LOG_LEVEL, and --log-level, in increasing order of precedence.
+func setLogLevels(a app.App, flagLevels []string) error {
+	var specs []string
+	if a != nil {
+		// Commands like init run without an app.yaml.
+		appLevels, err := a.LogLevels()
+		if err != nil {
+			logrus.WithError(err).Debug("reading log levels from app configuration")
+		}
+		specs = append(specs, appLevels...)
+	}
+
+	if env := os.Getenv(log.EnvLevels); env != "" {
+		specs = append(specs, strings.Split(env, ",")...)
+	}
+	specs = append(specs, flagLevels...)
+
+	levels, err := log.ParseLevels(specs)
+	if err != nil {
+		return err
+	}
+
+	log.SetLevels(levels)
+	return nil
+}
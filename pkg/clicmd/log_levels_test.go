@@ -0,0 +1,98 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_setLogLevels(t *testing.T) {
+	cases := []struct {
+		name      string
+		app       []string
+		env       string
+		flag      []string
+		logged    []string
+		notLogged []string
+		isErr     bool
+	}{
+		{
+			name:      "app configuration",
+			app:       []string{"registry=debug"},
+			logged:    []string{"registry debug"},
+			notLogged: []string{"evaluation debug"},
+		},
+		{
+			name:      "environment overrides app configuration",
+			app:       []string{"registry=debug"},
+			env:       "registry=info,evaluation=debug",
+			logged:    []string{"evaluation debug"},
+			notLogged: []string{"registry debug"},
+		},
+		{
+			name:      "flag overrides environment",
+			env:       "registry=info",
+			flag:      []string{"registry=debug", "cluster=error"},
+			logged:    []string{"registry debug"},
+			notLogged: []string{"cluster warning"},
+		},
+		{
+			name:  "invalid level",
+			flag:  []string{"registry=loud"},
+			isErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			old := os.Getenv(log.EnvLevels)
+			defer os.Setenv(log.EnvLevels, old)
+			os.Setenv(log.EnvLevels, tc.env)
+
+			a := &amocks.App{}
+			a.On("LogLevels").Return(tc.app, nil)
+
+			var buf bytes.Buffer
+			log.Init(0, &buf)
+			defer log.Init(0, &bytes.Buffer{})
+
+			err := setLogLevels(a, tc.flag)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			log.For(log.SubsystemRegistry).Debug("registry debug")
+			log.For(log.SubsystemEvaluation).Debug("evaluation debug")
+			log.For(log.SubsystemCluster).Warn("cluster warning")
+
+			for _, s := range tc.logged {
+				assert.Contains(t, buf.String(), s)
+			}
+			for _, s := range tc.notLogged {
+				assert.NotContains(t, buf.String(), s)
+			}
+		})
+	}
+}
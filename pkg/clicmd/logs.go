@@ -0,0 +1,118 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vLogsContainer = "logs-container"
+	vLogsEnv       = "logs-env"
+	vLogsFollow    = "logs-follow"
+	vLogsSince     = "logs-since"
+	vLogsTail      = "logs-tail"
+)
+
+var (
+	logsShortDesc = "Show the logs of a component's pods"
+	logsLong      = `
+The ` + "`logs`" + ` command shows the logs of the pods of a component, without having
+to look up their names. The component is rendered for the environment, and the
+pods are found with the selector of its workload, e.g. a Deployment,
+StatefulSet, DaemonSet, or Job, in the environment's namespace.
+
+If the workload has more than one pod, each line is prefixed with the name of
+the pod it came from. The environment is the one given with ` + "`--env`" + `, or the
+current environment.
+
+### Related Commands
+
+* ` + "`ks port-forward` " + `— ` + portForwardShortDesc + `
+* ` + "`ks status` " + `— ` + statusShortDesc + `
+
+### Syntax
+`
+	logsExample = `
+# Show the logs of the pods of the guestbook component in the current
+# environment.
+ks logs guestbook
+
+# Stream the last 100 lines of the 'web' container of the guestbook component
+# in the 'dev' environment.
+ks logs guestbook --env dev -c web -f --tail 100
+
+# Show the logs of the last hour.
+ks logs guestbook --since 1h`
+)
+
+func newLogsCmd(a app.App) *cobra.Command {
+	logsClientConfig := client.NewDefaultClientConfig(a)
+
+	logsCmd := &cobra.Command{
+		Use:     "logs <component-name> [--env <env-name>] [-c <container>] [-f]",
+		Short:   logsShortDesc,
+		Long:    logsLong,
+		Example: logsExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("'logs' requires a component name")
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:           a,
+				actions.OptionClientConfig:  logsClientConfig,
+				actions.OptionComponentName: args[0],
+				actions.OptionContainer:     viper.GetString(vLogsContainer),
+				actions.OptionEnvName:       viper.GetString(vLogsEnv),
+				actions.OptionFollow:        viper.GetBool(vLogsFollow),
+				actions.OptionSince:         viper.GetDuration(vLogsSince),
+				actions.OptionTail:          viper.GetInt64(vLogsTail),
+			}
+
+			if err := extractJsonnetFlags(a, "logs"); err != nil {
+				return errors.Wrap(err, "handle jsonnet flags")
+			}
+
+			return runAction(actionLogs, m)
+		},
+	}
+
+	logsClientConfig.BindClientGoFlags(logsCmd)
+	bindJsonnetFlags(logsCmd, "logs")
+
+	logsCmd.Flags().StringP(flagContainer, shortContainer, "", "Container to show logs for. Defaults to the only container of each pod")
+	viper.BindPFlag(vLogsContainer, logsCmd.Flags().Lookup(flagContainer))
+
+	logsCmd.Flags().String(flagEnv, "", "Environment the component is deployed to")
+	viper.BindPFlag(vLogsEnv, logsCmd.Flags().Lookup(flagEnv))
+
+	logsCmd.Flags().BoolP(flagFollow, shortFollow, false, "Stream the logs until interrupted")
+	viper.BindPFlag(vLogsFollow, logsCmd.Flags().Lookup(flagFollow))
+
+	logsCmd.Flags().Duration(flagSince, 0, "Only show logs newer than a duration, e.g. 5s, 2m, or 3h")
+	viper.BindPFlag(vLogsSince, logsCmd.Flags().Lookup(flagSince))
+
+	logsCmd.Flags().Int64(flagTail, -1, "Number of recent lines of each pod to show. All lines are shown if it is negative")
+	viper.BindPFlag(vLogsTail, logsCmd.Flags().Lookup(flagTail))
+
+	return logsCmd
+}
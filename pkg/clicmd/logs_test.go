@@ -0,0 +1,65 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/actions"
+)
+
+func Test_logsCmd(t *testing.T) {
+	cases := []cmdTestCase{
+		{
+			name:   "with a component",
+			args:   []string{"logs", "guestbook"},
+			action: actionLogs,
+			expected: map[string]interface{}{
+				actions.OptionApp:           nil,
+				actions.OptionClientConfig:  nil,
+				actions.OptionComponentName: "guestbook",
+				actions.OptionContainer:     "",
+				actions.OptionEnvName:       "",
+				actions.OptionFollow:        false,
+				actions.OptionSince:         time.Duration(0),
+				actions.OptionTail:          int64(-1),
+			},
+		},
+		{
+			name:   "with options",
+			args:   []string{"logs", "guestbook", "--env", "dev", "-c", "web", "-f", "--since", "1h", "--tail", "100"},
+			action: actionLogs,
+			expected: map[string]interface{}{
+				actions.OptionApp:           nil,
+				actions.OptionClientConfig:  nil,
+				actions.OptionComponentName: "guestbook",
+				actions.OptionContainer:     "web",
+				actions.OptionEnvName:       "dev",
+				actions.OptionFollow:        true,
+				actions.OptionSince:         time.Hour,
+				actions.OptionTail:          int64(100),
+			},
+		},
+		{
+			name:  "without a component",
+			args:  []string{"logs"},
+			isErr: true,
+		},
+	}
+
+	runTestCmd(t, cases)
+}
@@ -28,6 +28,7 @@ func newModuleCmd(a app.App) *cobra.Command {
 	}
 
 	moduleCmd.AddCommand(newModuleCreateCmd(a))
+	moduleCmd.AddCommand(newModuleDescribeCmd(a))
 	moduleCmd.AddCommand(newModuleListCmd(a))
 
 	return moduleCmd
@@ -0,0 +1,63 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vModuleDescribeOutput = "module-describe-output"
+)
+
+var (
+	moduleDescribeExample = `
+# Describe the 'db' module.
+ks module describe db
+
+# Describe the root module as JSON.
+ks module describe / -o json`
+)
+
+func newModuleDescribeCmd(a app.App) *cobra.Command {
+	moduleDescribeCmd := &cobra.Command{
+		Use:     "describe <name>",
+		Short:   "Describe a module",
+		Long:    `Describe a module, its manifest, and the environments targeting it`,
+		Example: moduleDescribeExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("module describe <module name>")
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:    a,
+				actions.OptionModule: args[0],
+				actions.OptionOutput: viper.GetString(vModuleDescribeOutput),
+			}
+
+			return runAction(actionModuleDescribe, m)
+		},
+	}
+
+	addCmdOutput(moduleDescribeCmd, vModuleDescribeOutput)
+
+	return moduleDescribeCmd
+}
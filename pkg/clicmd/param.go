@@ -22,10 +22,11 @@ import (
 
 var (
 	paramShortDesc = map[string]string{
-		"delete": "Delete component or environment parameters",
-		"set":    "Change component or environment parameters (e.g. replica count, name)",
-		"list":   "List known component parameters",
-		"diff":   "Display differences between the component parameters of two environments",
+		"delete":  "Delete component or environment parameters",
+		"set":     "Change component or environment parameters (e.g. replica count, name)",
+		"list":    "List known component parameters",
+		"diff":    "Display differences between the component parameters of two environments",
+		"history": "Show who changed environment parameters, when, and why",
 	}
 	paramLong = `
 Parameters are customizable fields that are used inside ksonnet *component*
@@ -67,6 +68,7 @@ func newParamCmd(a app.App) *cobra.Command {
 
 	paramCmd.AddCommand(newParamDeleteCmd(a))
 	paramCmd.AddCommand(newParamDiffCmd(a))
+	paramCmd.AddCommand(newParamHistoryCmd(a))
 	paramCmd.AddCommand(newParamListCmd(a))
 	paramCmd.AddCommand(newParamSetCmd(a))
 
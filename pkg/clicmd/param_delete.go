@@ -24,10 +24,31 @@ import (
 )
 
 var (
-	vParamDeleteEnv = "param-delete-env"
-	paramDeleteLong = `
+	vParamDeleteEnv      = "param-delete-env"
+	vParamDeleteIfExists = "param-delete-if-exists"
+	vParamDeleteAllEnvs  = "param-delete-all-envs"
+	vParamDeletePath     = "param-delete-path"
+	vParamDeleteDryRun   = "param-delete-dry-run"
+	vParamDeleteMessage  = "param-delete-message"
+	paramDeleteLong      = `
 The ` + "`delete`" + ` command deletes component or environment parameters.
 
+Deleting a parameter from a component that does not exist, or deleting a
+parameter that is not set, is an error which suggests the closest matching
+names. Use ` + "`--if-exists`" + ` to ignore these errors, which is useful when
+scripting.
+
+Several parameters can be deleted at once with repeated ` + "`--path`" + ` flags, and
+` + "`--all-envs`" + ` deletes them from a component and from the component's
+overrides in every environment. If any deletion fails, no ` + "`params.libsonnet`" + `
+file is changed.
+
+With ` + "`--dry-run`" + `, the edits to ` + "`params.libsonnet`" + ` files are printed as a
+unified diff and nothing is written.
+
+Deletions from environments are recorded in the environment's parameter
+history. Use ` + "`--message`" + ` to record why a parameter was deleted.
+
 ### Related Commands
 
 * ` + "`ks param set` " + `— ` + paramShortDesc["set"] + `
@@ -41,7 +62,13 @@ The ` + "`delete`" + ` command deletes component or environment parameters.
 ks param delete guestbook replicas
 
 # Delete 'guestbook' component replicate in 'dev' environment
-ks param delete guestbook replicas --env=dev`
+ks param delete guestbook replicas --env=dev
+
+# Delete 'guestbook' component replica parameter, if it is set
+ks param delete guestbook replicas --if-exists
+
+# Delete deprecated 'guestbook' parameters from the component and all environments
+ks param delete guestbook --path=replicas --path=image --all-envs`
 )
 
 func newParamDeleteCmd(a app.App) *cobra.Command {
@@ -54,21 +81,32 @@ func newParamDeleteCmd(a app.App) *cobra.Command {
 			var name string
 			var path string
 
-			switch len(args) {
-			default:
-				return errors.New("invalid arguments for 'param delete'")
-			case 2:
+			paths := viper.GetStringSlice(vParamDeletePath)
+
+			switch {
+			case len(paths) > 0 && len(args) <= 1:
+				if len(args) == 1 {
+					name = args[0]
+				}
+			case len(paths) == 0 && len(args) == 2:
 				name = args[0]
 				path = args[1]
-			case 1:
+			case len(paths) == 0 && len(args) == 1:
 				path = args[0]
+			default:
+				return errors.New("invalid arguments for 'param delete'")
 			}
 
 			m := map[string]interface{}{
-				actions.OptionApp:     a,
-				actions.OptionName:    name,
-				actions.OptionPath:    path,
-				actions.OptionEnvName: viper.GetString(vParamDeleteEnv),
+				actions.OptionApp:      a,
+				actions.OptionName:     name,
+				actions.OptionPath:     path,
+				actions.OptionPaths:    paths,
+				actions.OptionEnvName:  viper.GetString(vParamDeleteEnv),
+				actions.OptionAllEnvs:  viper.GetBool(vParamDeleteAllEnvs),
+				actions.OptionIfExists: viper.GetBool(vParamDeleteIfExists),
+				actions.OptionDryRun:   viper.GetBool(vParamDeleteDryRun),
+				actions.OptionMessage:  viper.GetString(vParamDeleteMessage),
 			}
 
 			return runAction(actionParamDelete, m)
@@ -78,5 +116,20 @@ func newParamDeleteCmd(a app.App) *cobra.Command {
 	paramDeleteCmd.Flags().String(flagEnv, "", "Specify environment to delete parameter from")
 	viper.BindPFlag(vParamDeleteEnv, paramDeleteCmd.Flags().Lookup(flagEnv))
 
+	paramDeleteCmd.Flags().Bool(flagIfExists, false, "Do not fail if the component or parameter does not exist")
+	viper.BindPFlag(vParamDeleteIfExists, paramDeleteCmd.Flags().Lookup(flagIfExists))
+
+	paramDeleteCmd.Flags().StringSlice(flagPath, nil, "Parameter to delete (multiple --path flags accepted)")
+	viper.BindPFlag(vParamDeletePath, paramDeleteCmd.Flags().Lookup(flagPath))
+
+	paramDeleteCmd.Flags().Bool(flagAllEnvs, false, "Also delete the parameters from every environment")
+	viper.BindPFlag(vParamDeleteAllEnvs, paramDeleteCmd.Flags().Lookup(flagAllEnvs))
+
+	paramDeleteCmd.Flags().Bool(flagDryRun, false, "Print the file edits as a unified diff without writing them")
+	viper.BindPFlag(vParamDeleteDryRun, paramDeleteCmd.Flags().Lookup(flagDryRun))
+
+	paramDeleteCmd.Flags().String(flagMessage, "", "Describe why an environment parameter was deleted in its history")
+	viper.BindPFlag(vParamDeleteMessage, paramDeleteCmd.Flags().Lookup(flagMessage))
+
 	return paramDeleteCmd
 }
@@ -28,10 +28,15 @@ func Test_paramDeleteCmd(t *testing.T) {
 			args:   []string{"param", "delete", "component-name", "param-name"},
 			action: actionParamDelete,
 			expected: map[string]interface{}{
-				actions.OptionApp:     nil,
-				actions.OptionName:    "component-name",
-				actions.OptionPath:    "param-name",
-				actions.OptionEnvName: "",
+				actions.OptionApp:      nil,
+				actions.OptionName:     "component-name",
+				actions.OptionPath:     "param-name",
+				actions.OptionPaths:    []string{},
+				actions.OptionEnvName:  "",
+				actions.OptionAllEnvs:  false,
+				actions.OptionIfExists: false,
+				actions.OptionDryRun:   false,
+				actions.OptionMessage:  "",
 			},
 		},
 		{
@@ -39,12 +44,54 @@ func Test_paramDeleteCmd(t *testing.T) {
 			args:   []string{"param", "delete", "param-name", "--env", "default"},
 			action: actionParamDelete,
 			expected: map[string]interface{}{
-				actions.OptionApp:     nil,
-				actions.OptionName:    "",
-				actions.OptionPath:    "param-name",
-				actions.OptionEnvName: "default",
+				actions.OptionApp:      nil,
+				actions.OptionName:     "",
+				actions.OptionPath:     "param-name",
+				actions.OptionPaths:    []string{},
+				actions.OptionEnvName:  "default",
+				actions.OptionAllEnvs:  false,
+				actions.OptionIfExists: false,
+				actions.OptionDryRun:   false,
+				actions.OptionMessage:  "",
 			},
 		},
+		{
+			name:   "if exists",
+			args:   []string{"param", "delete", "component-name", "param-name", "--if-exists"},
+			action: actionParamDelete,
+			expected: map[string]interface{}{
+				actions.OptionApp:      nil,
+				actions.OptionName:     "component-name",
+				actions.OptionPath:     "param-name",
+				actions.OptionPaths:    []string{},
+				actions.OptionEnvName:  "",
+				actions.OptionAllEnvs:  false,
+				actions.OptionIfExists: true,
+				actions.OptionDryRun:   false,
+				actions.OptionMessage:  "",
+			},
+		},
+		{
+			name:   "multiple paths in all environments",
+			args:   []string{"param", "delete", "component-name", "--path", "replicas", "--path", "image", "--all-envs"},
+			action: actionParamDelete,
+			expected: map[string]interface{}{
+				actions.OptionApp:      nil,
+				actions.OptionName:     "component-name",
+				actions.OptionPath:     "",
+				actions.OptionPaths:    []string{"replicas", "image"},
+				actions.OptionEnvName:  "",
+				actions.OptionAllEnvs:  true,
+				actions.OptionIfExists: false,
+				actions.OptionDryRun:   false,
+				actions.OptionMessage:  "",
+			},
+		},
+		{
+			name:  "paths and positional param",
+			args:  []string{"param", "delete", "component-name", "replicas", "--path", "image"},
+			isErr: true,
+		},
 		{
 			name:  "invalid args",
 			args:  []string{"param", "delete"},
@@ -0,0 +1,95 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vParamHistoryEnv    = "param-history-env"
+	vParamHistoryOutput = "param-history-output"
+)
+
+var (
+	paramHistoryLong = `
+The ` + "`history`" + ` command shows the recorded changes to an environment's
+parameters: who changed each parameter, when, and why.
+
+Changes made with ` + "`ks param set`" + ` and ` + "`ks param delete`" + ` are recorded in
+` + "`environments/<env-name>/params.history.yaml`" + `. When a component is given and the
+app is in a git repository, parameters without recorded changes, e.g. parameters
+edited by hand, are attributed to the commit which last changed them using
+` + "`git blame`" + `.
+
+### Related Commands
+
+* ` + "`ks param set` " + `— ` + paramShortDesc["set"] + `
+* ` + "`ks param delete` " + `— ` + paramShortDesc["delete"] + `
+
+### Syntax
+`
+	paramHistoryExample = `
+# Show the changes to the 'guestbook' component's parameters in the 'prod' environment
+ks param history guestbook --env=prod
+
+# Show the changes to all parameters in the 'prod' environment as JSON
+ks param history --env=prod -o json`
+)
+
+func newParamHistoryCmd(a app.App) *cobra.Command {
+	paramHistoryCmd := &cobra.Command{
+		Use:     "history [<component-name>] --env <env-name>",
+		Short:   paramShortDesc["history"],
+		Long:    paramHistoryLong,
+		Example: paramHistoryExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				return errors.New("'param history' takes at most one argument, the name of the component")
+			}
+
+			component := ""
+			if len(args) == 1 {
+				component = args[0]
+			}
+
+			envName := viper.GetString(vParamHistoryEnv)
+			if envName == "" {
+				return errors.New("'param history' requires an environment")
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:           a,
+				actions.OptionComponentName: component,
+				actions.OptionEnvName:       envName,
+				actions.OptionOutput:        viper.GetString(vParamHistoryOutput),
+			}
+
+			return runAction(actionParamHistory, m)
+		},
+	}
+
+	addCmdOutput(paramHistoryCmd, vParamHistoryOutput)
+
+	paramHistoryCmd.Flags().String(flagEnv, "", "Specify environment to show parameter history for")
+	viper.BindPFlag(vParamHistoryEnv, paramHistoryCmd.Flags().Lookup(flagEnv))
+
+	return paramHistoryCmd
+}
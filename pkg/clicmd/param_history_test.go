@@ -0,0 +1,61 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/actions"
+)
+
+func Test_paramHistoryCmd(t *testing.T) {
+	cases := []cmdTestCase{
+		{
+			name:   "with a component",
+			args:   []string{"param", "history", "guestbook", "--env", "prod"},
+			action: actionParamHistory,
+			expected: map[string]interface{}{
+				actions.OptionApp:           nil,
+				actions.OptionComponentName: "guestbook",
+				actions.OptionEnvName:       "prod",
+				actions.OptionOutput:        "",
+			},
+		},
+		{
+			name:   "all components",
+			args:   []string{"param", "history", "--env", "prod", "-o", "json"},
+			action: actionParamHistory,
+			expected: map[string]interface{}{
+				actions.OptionApp:           nil,
+				actions.OptionComponentName: "",
+				actions.OptionEnvName:       "prod",
+				actions.OptionOutput:        "json",
+			},
+		},
+		{
+			name:  "without an environment",
+			args:  []string{"param", "history", "guestbook", "--env", ""},
+			isErr: true,
+		},
+		{
+			name:  "too many arguments",
+			args:  []string{"param", "history", "guestbook", "redis", "--env", "prod"},
+			isErr: true,
+		},
+	}
+
+	runTestCmd(t, cases)
+}
@@ -27,11 +27,19 @@ var (
 	vParamSetEnv          = "param-set-env"
 	vParamSetAsString     = "param-set-as-string"
 	vParamSetResolveImage = "param-set-resolve-image"
+	vParamSetDryRun       = "param-set-dry-run"
+	vParamSetMessage      = "param-set-message"
 
 	paramSetLong = `
 The ` + "`set`" + ` command sets component or environment parameters such as replica count
 or name. Parameters are set individually, one at a time. All of these changes are
-reflected in the ` + "`params.libsonnet`" + ` files.
+reflected in the ` + "`params.libsonnet`" + ` files, which are then formatted as by
+` + "`ks fmt`" + `. Use ` + "`--dry-run`" + ` to print the edits as a unified diff instead of
+writing them.
+
+Changes to environment parameters are recorded in the environment's
+` + "`params.history.yaml`" + ` with their author and time. Use ` + "`--message`" + ` to record
+why a parameter was changed, and ` + "`ks param history`" + ` to view the changes.
 
 For more details on how parameters are organized, see ` + "`ks param --help`" + `.
 
@@ -43,6 +51,7 @@ for greater customization of environment parameters, we suggest modifying the
 ### Related Commands
 
 * ` + "`ks param diff` " + `— ` + paramShortDesc["diff"] + `
+* ` + "`ks param history` " + `— ` + paramShortDesc["history"] + `
 * ` + "`ks apply` " + `— ` + applyShortDesc + `
 
 ### Syntax
@@ -53,7 +62,11 @@ ks param set guestbook replicas 4
 
 # Update the replica count of the 'guestbook' component to 2, but only for the
 # 'dev' environment
-ks param set guestbook replicas 2 --env=dev`
+ks param set guestbook replicas 2 --env=dev
+
+# Update the replica count of the 'guestbook' component in the 'prod'
+# environment, recording why it was changed
+ks param set guestbook replicas 6 --env=prod --message="handle holiday traffic"`
 )
 
 func newParamSetCmd(a app.App) *cobra.Command {
@@ -87,6 +100,8 @@ func newParamSetCmd(a app.App) *cobra.Command {
 				actions.OptionEnvName:      viper.GetString(vParamSetEnv),
 				actions.OptionAsString:     viper.GetBool(vParamSetAsString),
 				actions.OptionResolveImage: viper.GetBool(vParamSetResolveImage),
+				actions.OptionDryRun:       viper.GetBool(vParamSetDryRun),
+				actions.OptionMessage:      viper.GetString(vParamSetMessage),
 			}
 
 			return runAction(actionParamSet, m)
@@ -102,5 +117,11 @@ func newParamSetCmd(a app.App) *cobra.Command {
 	paramSetCmd.Flags().Bool(flagResolveImage, false, "Resolve Docker image tag to reference")
 	viper.BindPFlag(vParamSetResolveImage, paramSetCmd.Flags().Lookup(flagResolveImage))
 
+	paramSetCmd.Flags().Bool(flagDryRun, false, "Print the file edits as a unified diff without writing them")
+	viper.BindPFlag(vParamSetDryRun, paramSetCmd.Flags().Lookup(flagDryRun))
+
+	paramSetCmd.Flags().String(flagMessage, "", "Describe why an environment parameter was changed in its history")
+	viper.BindPFlag(vParamSetMessage, paramSetCmd.Flags().Lookup(flagMessage))
+
 	return paramSetCmd
 }
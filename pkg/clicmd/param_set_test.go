@@ -35,6 +35,8 @@ func Test_paramSetCmd(t *testing.T) {
 				actions.OptionEnvName:      "",
 				actions.OptionAsString:     false,
 				actions.OptionResolveImage: false,
+				actions.OptionDryRun:       false,
+				actions.OptionMessage:      "",
 			},
 		},
 		{
@@ -49,6 +51,40 @@ func Test_paramSetCmd(t *testing.T) {
 				actions.OptionEnvName:      "",
 				actions.OptionAsString:     false,
 				actions.OptionResolveImage: true,
+				actions.OptionDryRun:       false,
+				actions.OptionMessage:      "",
+			},
+		},
+		{
+			name:   "dry run",
+			args:   []string{"param", "set", "component-name", "param-name", "param-value", "--dry-run"},
+			action: actionParamSet,
+			expected: map[string]interface{}{
+				actions.OptionApp:          nil,
+				actions.OptionName:         "component-name",
+				actions.OptionPath:         "param-name",
+				actions.OptionValue:        "param-value",
+				actions.OptionEnvName:      "",
+				actions.OptionAsString:     false,
+				actions.OptionResolveImage: false,
+				actions.OptionDryRun:       true,
+				actions.OptionMessage:      "",
+			},
+		},
+		{
+			name:   "with message",
+			args:   []string{"param", "set", "component-name", "param-name", "param-value", "--env", "prod", "--message", "scale up"},
+			action: actionParamSet,
+			expected: map[string]interface{}{
+				actions.OptionApp:          nil,
+				actions.OptionName:         "component-name",
+				actions.OptionPath:         "param-name",
+				actions.OptionValue:        "param-value",
+				actions.OptionEnvName:      "prod",
+				actions.OptionAsString:     false,
+				actions.OptionResolveImage: false,
+				actions.OptionDryRun:       false,
+				actions.OptionMessage:      "scale up",
 			},
 		},
 
@@ -64,6 +100,8 @@ func Test_paramSetCmd(t *testing.T) {
 				actions.OptionEnvName:      "default",
 				actions.OptionAsString:     false,
 				actions.OptionResolveImage: false,
+				actions.OptionDryRun:       false,
+				actions.OptionMessage:      "",
 			},
 		},
 		{
@@ -84,6 +122,8 @@ func Test_paramSetCmd(t *testing.T) {
 				actions.OptionEnvName:      "",
 				actions.OptionAsString:     true,
 				actions.OptionResolveImage: false,
+				actions.OptionDryRun:       false,
+				actions.OptionMessage:      "",
 			},
 		},
 	}
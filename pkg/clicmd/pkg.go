@@ -29,6 +29,7 @@ var (
 		"install":  "Install a package (e.g. extra prototypes) for the current ksonnet app",
 		"remove":   "Remove a package from the app or environment scope",
 		"describe": "Describe a ksonnet package and its contents",
+		"diff":     "Compare two versions of a package",
 		"list":     "List all packages known (downloaded or not) for the current ksonnet app",
 	}
 	pkgLong = `
@@ -69,6 +70,7 @@ func newPkgCmd(a app.App) *cobra.Command {
 	pkgCmd.AddCommand(newPkgListCmd(a))
 	pkgCmd.AddCommand(newPkgInstallCmd(a))
 	pkgCmd.AddCommand(newPkgDescribeCmd(a))
+	pkgCmd.AddCommand(newPkgDiffCmd(a))
 	pkgCmd.AddCommand(newPkgRemoveCmd(a))
 
 	return pkgCmd
@@ -24,6 +24,10 @@ import (
 	"github.com/spf13/viper"
 )
 
+const (
+	vPkgDescribeOutput = "pkg-describe-output"
+)
+
 var (
 	pkgDescribeLong = `
 The ` + "`describe`" + ` command outputs documentation for a package that is available
@@ -57,6 +61,7 @@ func newPkgDescribeCmd(a app.App) *cobra.Command {
 			m := map[string]interface{}{
 				actions.OptionApp:           a,
 				actions.OptionPackageName:   args[0],
+				actions.OptionOutput:        viper.GetString(vPkgDescribeOutput),
 				actions.OptionTLSSkipVerify: viper.GetBool(flagTLSSkipVerify),
 			}
 
@@ -64,5 +69,7 @@ func newPkgDescribeCmd(a app.App) *cobra.Command {
 		},
 	}
 
+	addCmdOutput(pkgDescribeCmd, vPkgDescribeOutput)
+
 	return pkgDescribeCmd
 }
@@ -30,6 +30,7 @@ func Test_pkgDescribeCmd(t *testing.T) {
 			expected: map[string]interface{}{
 				actions.OptionApp:           nil,
 				actions.OptionPackageName:   "package-name",
+				actions.OptionOutput:        "",
 				actions.OptionTLSSkipVerify: false,
 			},
 		},
@@ -0,0 +1,86 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"fmt"
+
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vPkgDiffOutput = "pkg-diff-output"
+)
+
+var (
+	pkgDiffLong = `
+The ` + "`diff`" + ` command fetches two versions of a package from its registry and
+shows what changed between them, without installing either version. Use it to
+review an upgrade before changing the version an app is pinned to.
+
+The output includes:
+
+1. The files which were added, removed or modified
+2. The prototypes which were added, removed or modified, and the parameters
+   which changed in each of them (` + "`+`" + ` added, ` + "`-`" + ` removed, ` + "`~`" + ` modified)
+3. A unified diff of each changed file
+
+### Related Commands
+
+* ` + "`ks pkg describe` " + `— ` + pkgShortDesc["describe"] + `
+* ` + "`ks pkg install` " + `— ` + pkgShortDesc["install"] + `
+
+### Syntax
+`
+	pkgDiffExample = `
+# Compare two tagged versions of the 'redis' package.
+ks pkg diff incubator/redis 0.1.0 0.2.0
+
+# Compare two commits of a package in the app's default registry.
+ks pkg diff redis 40285d8 5a6bb1c`
+)
+
+func newPkgDiffCmd(a app.App) *cobra.Command {
+	pkgDiffCmd := &cobra.Command{
+		Use:     "diff [<registry-name>/]<package-name> <from-version> <to-version>",
+		Short:   pkgShortDesc["diff"],
+		Long:    pkgDiffLong,
+		Example: pkgDiffExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 3 {
+				return fmt.Errorf("Command 'pkg diff' requires a package name and two versions\n\n%s", cmd.UsageString())
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:           a,
+				actions.OptionPkgName:       args[0],
+				actions.OptionFromVersion:   args[1],
+				actions.OptionToVersion:     args[2],
+				actions.OptionOutput:        viper.GetString(vPkgDiffOutput),
+				actions.OptionTLSSkipVerify: viper.GetBool(flagTLSSkipVerify),
+			}
+
+			return runAction(actionPkgDiff, m)
+		},
+	}
+
+	addCmdOutput(pkgDiffCmd, vPkgDiffOutput)
+
+	return pkgDiffCmd
+}
@@ -26,9 +26,11 @@ import (
 )
 
 var (
-	vPkgInstallName  = "pkg-install-name"
-	vPkgInstallEnv   = "pkg-install-env"
-	vPkgInstallForce = "pkg-install-force"
+	vPkgInstallName     = "pkg-install-name"
+	vPkgInstallEnv      = "pkg-install-env"
+	vPkgInstallForce    = "pkg-install-force"
+	vPkgInstallDryRun   = "pkg-install-dry-run"
+	vPkgInstallRegistry = "pkg-install-registry"
 
 	pkgInstallLong = `
 The ` + "`install`" + ` command caches a ksonnet library locally, and makes it available
@@ -39,6 +41,28 @@ The library itself needs to be located in a registry (e.g. Github repo). By defa
 ksonnet knows about two registries: *incubator* and *stable*, which are the release
 channels for official ksonnet libraries.
 
+The registry can be left out of the package name. ksonnet then uses the
+` + "`defaultRegistry`" + ` set in ` + "`app.yaml`" + ` if it contains the package, or the only
+registry which contains it. If more than one registry has the package, qualify
+the name or pick a registry with ` + "`--registry`" + `.
+
+With ` + "`--env`" + `, the package version is recorded in the selected environments
+instead of globally, and only those environments import it. Other environments keep
+using the globally installed version, so a library upgrade can be staged one
+environment at a time.
+
+When a package is upgraded for the app, the params of components generated from
+its prototypes are rewritten with the migrations the new version declares in
+` + "`parts.yaml`" + `, so parameters it renamed or converted keep working.
+
+With ` + "`--dry-run`" + `, the files the package would add to ` + "`vendor/`" + ` and the
+changes to ` + "`app.yaml`" + ` are printed as a unified diff instead of being written.
+
+Files downloaded from GitHub registries can be kept in a parts cache shared by
+several apps, or restored between CI runs. Set ` + "`$KS_PARTS_CACHE`" + ` or
+` + "`partsCache`" + ` in ` + "`app.yaml`" + ` to the cache directory. Files are stored by
+their content, so each file is downloaded and stored once.
+
 ### Related Commands
 
 * ` + "`ks pkg list` " + `— ` + pkgShortDesc["list"] + `
@@ -62,6 +86,15 @@ ks pkg install incubator/nginx@master
 # In a ksonnet source file, this can be referenced as:
 #   local nginx = import "incubator/nginx/nginx.libsonnet";
 ks pkg install --env stage incubator/nginx@40285d8a14f1ac5787e405e1023cf0c07f6aa28c
+
+# Stage an nginx upgrade in the stage and canary environments only.
+ks pkg install --env stage --env canary incubator/nginx@1.1.0
+
+# Install redis from the default registry, or the only registry which has it.
+ks pkg install redis
+
+# Install redis from the stable registry.
+ks pkg install --registry stable redis
 `
 )
 
@@ -82,9 +115,11 @@ func newPkgInstallCmd(a app.App) *cobra.Command {
 				actions.OptionApp:           a,
 				actions.OptionPkgName:       args[0],
 				actions.OptionName:          viper.GetString(vPkgInstallName),
-				actions.OptionEnvName:       viper.GetString(vPkgInstallEnv),
+				actions.OptionEnvNames:      viper.GetStringSlice(vPkgInstallEnv),
 				actions.OptionForce:         viper.GetBool(vPkgInstallForce),
 				actions.OptionTLSSkipVerify: viper.GetBool(flagTLSSkipVerify),
+				actions.OptionDryRun:        viper.GetBool(vPkgInstallDryRun),
+				actions.OptionRegistry:      viper.GetString(vPkgInstallRegistry),
 			}
 
 			return runAction(actionPkgInstall, m)
@@ -94,11 +129,17 @@ func newPkgInstallCmd(a app.App) *cobra.Command {
 	pkgInstallCmd.Flags().String(flagName, "", "Name to give the dependency, to use within the ksonnet app")
 	viper.BindPFlag(vPkgInstallName, pkgInstallCmd.Flags().Lookup(flagName))
 
-	pkgInstallCmd.Flags().String(flagEnv, "", "Environment to install package into (optional)")
+	pkgInstallCmd.Flags().StringSlice(flagEnv, nil, "Environments to install package into (optional, multiple --env flags accepted)")
 	viper.BindPFlag(vPkgInstallEnv, pkgInstallCmd.Flags().Lookup(flagEnv))
 
+	pkgInstallCmd.Flags().String(flagRegistry, "", "Registry to install the package from")
+	viper.BindPFlag(vPkgInstallRegistry, pkgInstallCmd.Flags().Lookup(flagRegistry))
+
 	pkgInstallCmd.Flags().Bool(flagForce, false, "Force installation")
 	viper.BindPFlag(vPkgInstallForce, pkgInstallCmd.Flags().Lookup(flagForce))
 
+	pkgInstallCmd.Flags().Bool(flagDryRun, false, "Print the file edits as a unified diff without writing them")
+	viper.BindPFlag(vPkgInstallDryRun, pkgInstallCmd.Flags().Lookup(flagDryRun))
+
 	return pkgInstallCmd
 }
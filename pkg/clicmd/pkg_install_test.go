@@ -31,9 +31,11 @@ func Test_pkgInstallCmd(t *testing.T) {
 				actions.OptionApp:           nil,
 				actions.OptionPkgName:       "package-name",
 				actions.OptionName:          "",
-				actions.OptionEnvName:       "",
+				actions.OptionEnvNames:      []string{},
 				actions.OptionForce:         false,
 				actions.OptionTLSSkipVerify: false,
+				actions.OptionDryRun:        false,
+				actions.OptionRegistry:      "",
 			},
 		},
 		{
@@ -44,9 +46,26 @@ func Test_pkgInstallCmd(t *testing.T) {
 				actions.OptionApp:           nil,
 				actions.OptionPkgName:       "package-name",
 				actions.OptionName:          "",
-				actions.OptionEnvName:       "production",
+				actions.OptionEnvNames:      []string{"production"},
 				actions.OptionForce:         false,
 				actions.OptionTLSSkipVerify: false,
+				actions.OptionDryRun:        false,
+				actions.OptionRegistry:      "",
+			},
+		},
+		{
+			name:   "with multiple env flags",
+			args:   []string{"pkg", "install", "--env", "stage", "--env", "canary", "package-name"},
+			action: actionPkgInstall,
+			expected: map[string]interface{}{
+				actions.OptionApp:           nil,
+				actions.OptionPkgName:       "package-name",
+				actions.OptionName:          "",
+				actions.OptionEnvNames:      []string{"stage", "canary"},
+				actions.OptionForce:         false,
+				actions.OptionTLSSkipVerify: false,
+				actions.OptionDryRun:        false,
+				actions.OptionRegistry:      "",
 			},
 		},
 		{
@@ -57,9 +76,26 @@ func Test_pkgInstallCmd(t *testing.T) {
 				actions.OptionApp:           nil,
 				actions.OptionPkgName:       "package-name",
 				actions.OptionName:          "",
-				actions.OptionEnvName:       "",
+				actions.OptionEnvNames:      []string{},
 				actions.OptionForce:         true,
 				actions.OptionTLSSkipVerify: false,
+				actions.OptionDryRun:        false,
+				actions.OptionRegistry:      "",
+			},
+		},
+		{
+			name:   "with registry flag",
+			args:   []string{"pkg", "install", "--registry", "stable", "redis"},
+			action: actionPkgInstall,
+			expected: map[string]interface{}{
+				actions.OptionApp:           nil,
+				actions.OptionPkgName:       "redis",
+				actions.OptionName:          "",
+				actions.OptionEnvNames:      []string{},
+				actions.OptionForce:         false,
+				actions.OptionTLSSkipVerify: false,
+				actions.OptionDryRun:        false,
+				actions.OptionRegistry:      "stable",
 			},
 		},
 		{
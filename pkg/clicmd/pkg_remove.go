@@ -27,11 +27,13 @@ import (
 
 var (
     vPkgRemoveEnv   = "pkg-remove-env"
+    vPkgRemoveYes   = "pkg-remove-yes"
 
     pkgRemoveLong = `
 The ` + "`remove`" + ` command removes a reference to a ksonnet library.  The reference can either be
 global or scoped to an environment. If the last reference to a library version is removed, the cached
-files will be removed as well.
+files will be removed as well. The removal must be confirmed at a prompt, or with
+` + "`--yes`" + ` when not running in a terminal.
 
 ### Syntax
 `
@@ -59,6 +61,7 @@ func newPkgRemoveCmd(a app.App) *cobra.Command {
 
             m := map[string]interface{}{
                 actions.OptionApp:           a,
+                actions.OptionAssumeYes:     viper.GetBool(vPkgRemoveYes),
                 actions.OptionPkgName:       args[0],
                 actions.OptionEnvName:       viper.GetString(vPkgRemoveEnv),
                 actions.OptionTLSSkipVerify: viper.GetBool(flagTLSSkipVerify),
@@ -71,5 +74,7 @@ func newPkgRemoveCmd(a app.App) *cobra.Command {
     pkgRemoveCmd.Flags().String(flagEnv, "", "Environment to remove package from (optional)")
     viper.BindPFlag(vPkgRemoveEnv, pkgRemoveCmd.Flags().Lookup(flagEnv))
 
+    addCmdAssumeYes(pkgRemoveCmd, vPkgRemoveYes)
+
     return pkgRemoveCmd
 }
@@ -29,6 +29,7 @@ func Test_pkgRemoveCmd(t *testing.T) {
 			action: actionPkgRemove,
 			expected: map[string]interface{}{
 				actions.OptionApp:           nil,
+				actions.OptionAssumeYes:     false,
 				actions.OptionPkgName:       "package-name",
 				actions.OptionEnvName:       "",
 				actions.OptionTLSSkipVerify: false,
@@ -40,6 +41,7 @@ func Test_pkgRemoveCmd(t *testing.T) {
 			action: actionPkgRemove,
 			expected: map[string]interface{}{
 				actions.OptionApp:           nil,
+				actions.OptionAssumeYes:     false,
 				actions.OptionPkgName:       "package-name",
 				actions.OptionEnvName:       "production",
 				actions.OptionTLSSkipVerify: false,
@@ -0,0 +1,75 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/plugin"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+// addPluginCmds adds a command for each plugin. Built-in commands take
+// precedence over plugins with the same name.
+func addPluginCmds(rootCmd *cobra.Command, fs afero.Fs, a app.App) {
+	plugins, err := plugin.List(fs)
+	if err != nil {
+		log.WithError(err).Debug("listing plugins")
+		return
+	}
+
+	reserved := map[string]bool{"help": true}
+	for _, cmd := range rootCmd.Commands() {
+		reserved[cmd.Name()] = true
+		for _, alias := range cmd.Aliases {
+			reserved[alias] = true
+		}
+	}
+
+	for _, p := range plugins {
+		if reserved[p.Config.Name] {
+			log.Debugf("plugin %s is hidden by the built-in command with the same name", p.Config.Name)
+			continue
+		}
+
+		rootCmd.AddCommand(newPluginCmd(fs, a, p))
+	}
+}
+
+func newPluginCmd(fs afero.Fs, a app.App, p plugin.Plugin) *cobra.Command {
+	short := p.Config.Description
+	if short == "" {
+		short = "Run the " + p.Config.Name + " plugin"
+	}
+
+	return &cobra.Command{
+		Use:                p.Config.Name + " [args]",
+		Short:              short,
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m := map[string]interface{}{
+				actions.OptionApp:       a,
+				actions.OptionFs:        fs,
+				actions.OptionName:      p.Config.Name,
+				actions.OptionArguments: args,
+			}
+
+			return runAction(actionPluginRun, m)
+		},
+	}
+}
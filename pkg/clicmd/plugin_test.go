@@ -0,0 +1,57 @@
+// Copyright 2017 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_addPluginCmds(t *testing.T) {
+	ogHome := os.Getenv("HOME")
+	os.Setenv("HOME", "/home/app")
+	defer os.Setenv("HOME", ogHome)
+
+	ogPath := os.Getenv("PATH")
+	os.Setenv("PATH", "/bin")
+	defer os.Setenv("PATH", ogPath)
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/bin/ks-hello", []byte("#!/bin/sh"), 0755))
+	require.NoError(t, afero.WriteFile(fs, "/bin/ks-show", []byte("#!/bin/sh"), 0755))
+
+	rootCmd := &cobra.Command{Use: "ks"}
+	rootCmd.AddCommand(&cobra.Command{Use: "show"})
+
+	addPluginCmds(rootCmd, fs, nil)
+
+	var names []string
+	for _, cmd := range rootCmd.Commands() {
+		names = append(names, cmd.Name())
+	}
+
+	assert.Equal(t, []string{"hello", "show"}, names)
+
+	cmd, _, err := rootCmd.Find([]string{"hello"})
+	require.NoError(t, err)
+	assert.True(t, cmd.DisableFlagParsing)
+	assert.Equal(t, "Run the hello plugin", cmd.Short)
+}
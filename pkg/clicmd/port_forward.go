@@ -0,0 +1,109 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/cluster"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vPortForwardAddress = "port-forward-address"
+	vPortForwardEnv     = "port-forward-env"
+)
+
+var (
+	portForwardShortDesc = "Forward local ports to a component's pod"
+	portForwardLong      = `
+The ` + "`port-forward`" + ` command forwards local ports to a running pod of a
+component, without having to look up its name. The component is rendered for
+the environment, and the pod is found with the selector of its Service, or if
+it does not have one, of its workload, in the environment's namespace.
+
+Ports are given as ` + "`local:remote`" + `, or as a single port which is used for
+both. If the component has a Service, remote ports are ports of the Service,
+and are forwarded to the pod ports they target. Otherwise they are ports of the
+pod. If no ports are given, every port of the Service, or of the pod's
+containers, is forwarded to the same local port.
+
+Ports are forwarded until ks is interrupted. The environment is the one given
+with ` + "`--env`" + `, or the current environment.
+
+### Related Commands
+
+* ` + "`ks logs` " + `— ` + logsShortDesc + `
+
+### Syntax
+`
+	portForwardExample = `
+# Forward every port of the guestbook component's service in the current
+# environment.
+ks port-forward guestbook
+
+# Forward local port 8080 to port 80 of the guestbook component's service in
+# the 'dev' environment.
+ks port-forward guestbook 8080:80 --env dev
+
+# Listen on every interface.
+ks port-forward guestbook 8080:80 --address 0.0.0.0`
+)
+
+func newPortForwardCmd(a app.App) *cobra.Command {
+	portForwardClientConfig := client.NewDefaultClientConfig(a)
+
+	portForwardCmd := &cobra.Command{
+		Use:     "port-forward <component-name> [[local:]remote...] [--env <env-name>]",
+		Short:   portForwardShortDesc,
+		Long:    portForwardLong,
+		Example: portForwardExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return errors.New("'port-forward' requires a component name")
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:           a,
+				actions.OptionClientConfig:  portForwardClientConfig,
+				actions.OptionComponentName: args[0],
+				actions.OptionPorts:         args[1:],
+				actions.OptionAddress:       viper.GetString(vPortForwardAddress),
+				actions.OptionEnvName:       viper.GetString(vPortForwardEnv),
+			}
+
+			if err := extractJsonnetFlags(a, "port-forward"); err != nil {
+				return errors.Wrap(err, "handle jsonnet flags")
+			}
+
+			return runAction(actionPortForward, m)
+		},
+	}
+
+	portForwardClientConfig.BindClientGoFlags(portForwardCmd)
+	bindJsonnetFlags(portForwardCmd, "port-forward")
+
+	portForwardCmd.Flags().String(flagAddress, cluster.DefaultPortForwardAddress, "Address to listen on")
+	viper.BindPFlag(vPortForwardAddress, portForwardCmd.Flags().Lookup(flagAddress))
+
+	portForwardCmd.Flags().String(flagEnv, "", "Environment the component is deployed to")
+	viper.BindPFlag(vPortForwardEnv, portForwardCmd.Flags().Lookup(flagEnv))
+
+	return portForwardCmd
+}
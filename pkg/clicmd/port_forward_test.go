@@ -0,0 +1,60 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/actions"
+)
+
+func Test_portForwardCmd(t *testing.T) {
+	cases := []cmdTestCase{
+		{
+			name:   "with a component",
+			args:   []string{"port-forward", "guestbook"},
+			action: actionPortForward,
+			expected: map[string]interface{}{
+				actions.OptionApp:           nil,
+				actions.OptionClientConfig:  nil,
+				actions.OptionComponentName: "guestbook",
+				actions.OptionPorts:         []string{},
+				actions.OptionAddress:       "localhost",
+				actions.OptionEnvName:       "",
+			},
+		},
+		{
+			name:   "with ports",
+			args:   []string{"port-forward", "guestbook", "8080:80", "9090", "--env", "dev", "--address", "0.0.0.0"},
+			action: actionPortForward,
+			expected: map[string]interface{}{
+				actions.OptionApp:           nil,
+				actions.OptionClientConfig:  nil,
+				actions.OptionComponentName: "guestbook",
+				actions.OptionPorts:         []string{"8080:80", "9090"},
+				actions.OptionAddress:       "0.0.0.0",
+				actions.OptionEnvName:       "dev",
+			},
+		},
+		{
+			name:  "without a component",
+			args:  []string{"port-forward"},
+			isErr: true,
+		},
+	}
+
+	runTestCmd(t, cases)
+}
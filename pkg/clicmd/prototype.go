@@ -22,7 +22,9 @@ import (
 
 var (
 	protoShortDesc = map[string]string{
+		"lint":     "Check the directives of prototype files",
 		"list":     "List all locally available ksonnet prototypes",
+		"create":   "Create a prototype from existing Kubernetes manifests",
 		"describe": "See more info about a prototype's output and usage",
 		"preview":  "Preview a prototype's output without creating a component (stdout)",
 		"search":   "Search for a prototype",
@@ -53,7 +55,9 @@ func newPrototypeCmd(a app.App) *cobra.Command {
 		Long:  protoLong,
 	}
 
+	prototypeCmd.AddCommand(newPrototypeCreateCmd(a))
 	prototypeCmd.AddCommand(newPrototypeDescribeCmd(a))
+	prototypeCmd.AddCommand(newPrototypeLintCmd(a))
 	prototypeCmd.AddCommand(newPrototypeListCmd(a))
 	prototypeCmd.AddCommand(newPrototypePreviewCmd(a))
 	prototypeCmd.AddCommand(newPrototypeSearchCmd(a))
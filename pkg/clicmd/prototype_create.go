@@ -0,0 +1,97 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"fmt"
+
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vPrototypeCreateFilename = "prototype-create-filename"
+	vPrototypeCreateForce    = "prototype-create-force"
+	vPrototypeCreatePart     = "prototype-create-part"
+)
+
+var (
+	prototypeCreateLong = `
+The ` + "`create`" + ` command generates a prototype from existing Kubernetes manifests, to
+bootstrap a library of prototypes. The manifests are converted to the prototype's
+Jsonnet template, and common values become parameters:
+
+* The name of the first object is the required ` + "`name`" + ` parameter
+* Replicas, the labels of the first object, and container images are optional
+  parameters, with their values in the manifests as defaults
+
+The prototype is written to the ` + "`prototypes/`" + ` directory of the part (package) in
+` + "`--part`" + `. The part's ` + "`parts.yaml`" + ` is created if it does not exist, and if the
+directory containing the part is a file system registry, the part is added to
+its ` + "`registry.yaml`" + `.
+
+### Related Commands
+
+* ` + "`ks prototype lint` " + `— ` + protoShortDesc["lint"] + `
+* ` + "`ks prototype preview` " + `— ` + protoShortDesc["preview"] + `
+* ` + "`ks registry add` " + `— ` + regShortDesc["add"] + `
+
+### Syntax
+`
+	prototypeCreateExample = `
+# Create the prototype 'io.example.guestbook' in the part 'registry/guestbook'
+# from the manifests in 'guestbook.yaml'.
+ks prototype create io.example.guestbook -f guestbook.yaml --part registry/guestbook
+
+# Install the part from the file system registry 'registry'.
+ks registry add local ./registry
+ks pkg install local/guestbook`
+)
+
+func newPrototypeCreateCmd(a app.App) *cobra.Command {
+	prototypeCreateCmd := &cobra.Command{
+		Use:     "create <prototype-name> -f <manifest> --part <dir>",
+		Short:   protoShortDesc["create"],
+		Long:    prototypeCreateLong,
+		Example: prototypeCreateExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("Command 'prototype create' requires a prototype name\n\n%s", cmd.UsageString())
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:   a,
+				actions.OptionName:  args[0],
+				actions.OptionPath:  viper.GetString(vPrototypeCreateFilename),
+				actions.OptionPart:  viper.GetString(vPrototypeCreatePart),
+				actions.OptionForce: viper.GetBool(vPrototypeCreateForce),
+			}
+
+			return runAction(actionPrototypeCreate, m)
+		},
+	}
+
+	prototypeCreateCmd.Flags().StringP(flagFilename, shortFilename, "", "Manifest to create the prototype from")
+	viper.BindPFlag(vPrototypeCreateFilename, prototypeCreateCmd.Flags().Lookup(flagFilename))
+	prototypeCreateCmd.Flags().String(flagPart, "", "Directory of the part to create the prototype in")
+	viper.BindPFlag(vPrototypeCreatePart, prototypeCreateCmd.Flags().Lookup(flagPart))
+	prototypeCreateCmd.Flags().Bool(flagForce, false, "Overwrite an existing prototype")
+	viper.BindPFlag(vPrototypeCreateForce, prototypeCreateCmd.Flags().Lookup(flagForce))
+
+	return prototypeCreateCmd
+}
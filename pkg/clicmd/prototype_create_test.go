@@ -0,0 +1,58 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/actions"
+)
+
+func Test_prototypeCreateCmd(t *testing.T) {
+	cases := []cmdTestCase{
+		{
+			name:   "in general",
+			args:   []string{"prototype", "create", "io.example.web", "-f", "web.yaml", "--part", "registry/web"},
+			action: actionPrototypeCreate,
+			expected: map[string]interface{}{
+				actions.OptionApp:   nil,
+				actions.OptionName:  "io.example.web",
+				actions.OptionPath:  "web.yaml",
+				actions.OptionPart:  "registry/web",
+				actions.OptionForce: false,
+			},
+		},
+		{
+			name:   "force",
+			args:   []string{"prototype", "create", "io.example.web", "-f", "web.yaml", "--part", "registry/web", "--force"},
+			action: actionPrototypeCreate,
+			expected: map[string]interface{}{
+				actions.OptionApp:   nil,
+				actions.OptionName:  "io.example.web",
+				actions.OptionPath:  "web.yaml",
+				actions.OptionPart:  "registry/web",
+				actions.OptionForce: true,
+			},
+		},
+		{
+			name:  "no name",
+			args:  []string{"prototype", "create", "-f", "web.yaml", "--part", "registry/web"},
+			isErr: true,
+		},
+	}
+
+	runTestCmd(t, cases)
+}
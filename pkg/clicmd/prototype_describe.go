@@ -24,6 +24,10 @@ import (
 	"github.com/spf13/viper"
 )
 
+const (
+	vPrototypeDescribeOutput = "prototype-describe-output"
+)
+
 var (
 	prototypeDescribeLong = `
 This command outputs documentation, examples, and other information for
@@ -60,6 +64,7 @@ func newPrototypeDescribeCmd(a app.App) *cobra.Command {
 			m := map[string]interface{}{
 				actions.OptionApp:           a,
 				actions.OptionQuery:         args[0],
+				actions.OptionOutput:        viper.GetString(vPrototypeDescribeOutput),
 				actions.OptionTLSSkipVerify: viper.GetBool(flagTLSSkipVerify),
 			}
 
@@ -67,5 +72,7 @@ func newPrototypeDescribeCmd(a app.App) *cobra.Command {
 		},
 	}
 
+	addCmdOutput(prototypeDescribeCmd, vPrototypeDescribeOutput)
+
 	return prototypeDescribeCmd
 }
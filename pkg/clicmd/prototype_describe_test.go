@@ -30,6 +30,7 @@ func Test_prototypeDescribeCmd(t *testing.T) {
 			expected: map[string]interface{}{
 				actions.OptionApp:           nil,
 				actions.OptionQuery:         "name",
+				actions.OptionOutput:        "",
 				actions.OptionTLSSkipVerify: false,
 			},
 		},
@@ -0,0 +1,83 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"fmt"
+
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vPrototypeLintOutput = "prototype-lint-output"
+)
+
+var (
+	prototypeLintLong = `
+The ` + "`lint`" + ` command checks the directives of prototype files, such as
+` + "`@param`" + ` and ` + "`@optionalParam`" + `, while you author them. It reports:
+
+* Missing ` + "`@name`" + ` and ` + "`@shortDescription`" + ` directives
+* Directives which can't be parsed, or have an unknown parameter type
+* Parameters which are declared more than once, or conflict with a flag
+* Defaults of optional parameters which do not match their type
+* Parameters which are declared, but not used by the template
+* Parameters which are used by the template, but not declared
+
+Directories are searched for ` + "`.jsonnet`" + ` prototype files.
+
+### Related Commands
+
+* ` + "`ks prototype preview` " + `— ` + protoShortDesc["preview"] + `
+
+### Syntax
+`
+	prototypeLintExample = `
+# Lint a prototype.
+ks prototype lint prototypes/redis.jsonnet
+
+# Lint the prototypes of a package.
+ks prototype lint incubator/redis/prototypes`
+)
+
+func newPrototypeLintCmd(a app.App) *cobra.Command {
+	prototypeLintCmd := &cobra.Command{
+		Use:     "lint <path>...",
+		Short:   protoShortDesc["lint"],
+		Long:    prototypeLintLong,
+		Example: prototypeLintExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("Command 'prototype lint' requires a prototype file or directory\n\n%s", cmd.UsageString())
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:       a,
+				actions.OptionArguments: args,
+				actions.OptionOutput:    viper.GetString(vPrototypeLintOutput),
+			}
+
+			return runAction(actionPrototypeLint, m)
+		},
+	}
+
+	addCmdOutput(prototypeLintCmd, vPrototypeLintOutput)
+
+	return prototypeLintCmd
+}
@@ -29,12 +29,16 @@ This ` + "`preview`" + ` command expands a prototype with CLI flag parameters, a
 emits the resulting manifest to stdout. This allows you to see the potential
 output of a ` + "`ks generate`" + ` command without actually creating a new component file.
 
-The output is formatted in Jsonnet. To see YAML or JSON equivalents, first create
-a component with ` + "`ks generate`" + ` and then use ` + "`ks show`" + `.
+Parameters can be given as flags named after them, or as ` + "`--param <name>=<value>`" + `.
+
+The output is formatted in Jsonnet. Use ` + "`--format json`" + ` or ` + "`--format yaml`" + ` to
+render the prototype's manifests in the current environment, or the environment
+given with ` + "`--env`" + `.
 
 ### Related Commands
 
 * ` + "`ks generate` " + `— ` + protoShortDesc["use"] + `
+* ` + "`ks prototype lint` " + `— ` + protoShortDesc["lint"] + `
 
 ### Syntax
 `
@@ -58,6 +62,14 @@ Where 'ks-values' is a jsonnet file with the contents:
 	image: "nginx",
 	port: 80,
 }
+
+# Render the manifests of prototype 'io.ksonnet.pkg.single-port-deployment'
+# as YAML in the 'dev' environment.
+ks prototype preview single-port-deployment \
+  --param name=nginx                        \
+  --param image=nginx                       \
+  --env dev                                 \
+  --format yaml
 `
 )
 
@@ -30,8 +30,14 @@ const (
 
 var (
 	prototypeSearchLong = `
-The ` + "`prototype search`" + ` command allows you to search for specific prototypes by name.
-Specifically, it matches any prototypes with names that contain the string <name-substring>.
+The ` + "`prototype search`" + ` command searches the system prototypes, and the prototypes
+of the packages installed from all registries, for a query. A prototype matches
+if each word of the query matches its name, its description, or the name of one
+of its parameters. Words match regardless of case, as a prefix, as a substring,
+or, in names, as an abbreviation (e.g. ` + "`spdep`" + ` matches ` + "`single-port-deployment`" + `).
+
+Results are ranked from the best match to the worst; matches in names rank above
+matches in parameters and descriptions. Use ` + "`-o json`" + ` for machine-readable output.
 
 ### Related Commands
 
@@ -41,19 +47,22 @@ Specifically, it matches any prototypes with names that contain the string <name
 ### Syntax
 `
 	prototypeSearchExample = `
-# Search for prototypes with names that contain the string 'service'.
-ks prototype search service`
+# Search for prototypes which match 'service'.
+ks prototype search service
+
+# Search for deployment prototypes with an 'image' parameter, as JSON.
+ks prototype search "deployment image" -o json`
 )
 
 func newPrototypeSearchCmd(a app.App) *cobra.Command {
 	prototypeSearchCmd := &cobra.Command{
-		Use:     "search <name-substring>",
+		Use:     "search <query>",
 		Short:   protoShortDesc["search"],
 		Long:    prototypeSearchLong,
 		Example: prototypeSearchExample,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) != 1 {
-				return fmt.Errorf("Command 'prototype search' requires a prototype name\n\n%s", cmd.UsageString())
+				return fmt.Errorf("Command 'prototype search' requires a query\n\n%s", cmd.UsageString())
 			}
 
 			m := map[string]interface{}{
@@ -51,6 +51,19 @@ expand prototypes into Jsonnet files.
 command line flags, such as ` + " `--image` " + `in the example above. Note that
 different prototypes support their own unique flags.
 
+4. With ` + "`--interactive`" + `, you are prompted for the parameters which are not
+given as flags, with their types and defaults. The values of parameters can also
+be read from a YAML or JSON ` + "`--answers-file`" + `, which maps parameter names to
+values, for automation. Flags take precedence over the answers file.
+
+5. A prototype can be used from a registry without installing its package, by
+naming it` + " `<registry>/<package>[@version]/<prototype>`" + `. The version of the
+package is pinned in a comment at the top of the component, and` + " `--install`" + `
+installs the package after the component is generated.
+
+6. The prototype a Jsonnet or YAML component was generated from is recorded in a
+comment at the top of the component, and reported by` + " `ks inventory`" + `.
+
 ### Related Commands
 
 * ` + "`ks show` " + `— ` + showShortDesc + `
@@ -81,6 +94,30 @@ ks prototype use deployment nginx-depl \
 # 'nginx' image with values from 'ks-value'.
 ks prototype use single-port-deployment nginx-depl \
   --values-file=ks-value
+
+# Instantiate prototype 'io.ksonnet.pkg.single-port-deployment', prompting for
+# its parameters.
+ks prototype use single-port-deployment nginx-depl --interactive
+
+# Instantiate prototype 'io.ksonnet.pkg.single-port-deployment' with the
+# parameters in 'answers.yaml', e.g. 'image: nginx'.
+ks prototype use single-port-deployment nginx-depl \
+  --answers-file=answers.yaml
+
+# Instantiate the built-in prototype 'io.ksonnet.pkg.job' as a one-off task.
+# The finished Job is garbage collected after an hour.
+ks prototype use io.ksonnet.pkg.job migrate-db \
+  --image=migrations:1.2 \
+  --command='["./migrate", "up"]' \
+  --ttlSecondsAfterFinished=3600
+
+# Instantiate prototype 'io.ksonnet.pkg.redis-stateless' from the 'redis'
+# package in the 'incubator' registry, without installing the package.
+ks prototype use incubator/redis/redis-stateless redis
+
+# Instantiate the same prototype from version 0.1.0 of the package, and
+# install the package.
+ks prototype use incubator/redis@0.1.0/redis-stateless redis --install
 `
 )
 
@@ -26,6 +26,7 @@ var (
 		"describe": "Describe a ksonnet registry and the packages it contains",
 		"add":      "Add a registry to the current ksonnet app",
 		"set":      "Set configuration options for registry",
+		"stats":    "Show usage statistics recorded for registries",
 	}
 	registryLong = `
 A ksonnet registry is basically a repository for *packages*. (Registry here is
@@ -57,6 +58,7 @@ func newRegistryCmd(a app.App) *cobra.Command {
 	registryCmd.AddCommand(newRegistryDescribeCmd(a))
 	registryCmd.AddCommand(newRegistryListCmd(a))
 	registryCmd.AddCommand(newRegistrySetCmd(a))
+	registryCmd.AddCommand(newRegistryStatsCmd(a))
 
 	return registryCmd
 }
@@ -27,6 +27,7 @@ import (
 
 const (
 	vRegistryAddOverride = "registry-add-override"
+	vRegistryAddDryRun   = "registry-add-dry-run"
 )
 
 var (
@@ -43,13 +44,30 @@ There are three supported registry protocols: **github**, **fs**, and **Helm**.
 GitHub registries expect a path in a GitHub repository, and filesystem based
 registries expect a path on the local filesystem.
 
+The protocol is detected from the URI. URIs beginning with ` + "`github`" + ` are GitHub
+registries, paths beginning with ` + "`/`" + ` or ` + "`.`" + ` (or ` + "`file://`" + ` URLs) are filesystem
+registries, and other ` + "`http://`" + ` or ` + "`https://`" + ` URLs are Helm chart repositories.
+The URI is validated against the detected protocol. Common mistakes, such as git
+clone URLs, raw file URLs, or GitHub paths without a branch, are reported with a
+suggested URI, which can be accepted at the prompt when running in a terminal.
+GitLab repositories and tarballs are not supported.
+
 During creation, all registries must specify a unique name and URI where the
 registry lives. GitHub registries can specify a commit, tag, or branch to follow as part of the URI.
+GitHub URIs which point at a repository without a branch follow the repository's
+default branch, e.g. ` + "`main`" + `.
+To version a GitHub registry with releases, use a URI pointing at a release, e.g.
+` + "`github.com/org/example/releases/tag/v1.2.3`" + `, optionally followed by the path to the
+registry in the tagged tree. The registry's ` + "`registry.yaml`" + ` is read from the release's
+assets if it has one, and parts are read from the tagged tree.
 
 Registries can be overridden with ` + "`--override`" + `.  Overridden registries
 are stored in ` + "`app.override.yaml`" + ` and can be safely ignored using your
 SCM configuration.
 
+Use ` + "`--dry-run`" + ` to print the change to ` + "`app.yaml`" + ` (or ` + "`app.override.yaml`" + `) as a
+unified diff without writing it.
+
 ### Related Commands
 
 * ` + "`ks registry list` " + `— ` + regShortDesc["list"] + `
@@ -63,6 +81,10 @@ ks registry add databases github.com/example
 # 'github.com/org/example/tree/0.0.1/registry' (0.0.1 is the branch name)
 ks registry add databases github.com/org/example/tree/0.0.1/registry
 
+# Add a registry with the name 'databases' at the release 'v1.2.3' of the
+# repository 'github.com/org/example'
+ks registry add databases github.com/org/example/releases/tag/v1.2.3
+
 # Add a registry with a Helm Charts Repository uri
 ks registry add helm-stable https://kubernetes-charts.storage.googleapis.com`
 )
@@ -84,6 +106,7 @@ func newRegistryAddCmd(a app.App) *cobra.Command {
 				actions.OptionURI:           args[1],
 				actions.OptionOverride:      viper.GetBool(vRegistryAddOverride),
 				actions.OptionTLSSkipVerify: viper.GetBool(flagTLSSkipVerify),
+				actions.OptionDryRun:        viper.GetBool(vRegistryAddDryRun),
 			}
 
 			return runAction(actionRegistryAdd, m)
@@ -93,5 +116,8 @@ func newRegistryAddCmd(a app.App) *cobra.Command {
 	registryAddCmd.Flags().BoolP(flagOverride, shortOverride, false, "Store in override configuration")
 	viper.BindPFlag(vRegistryAddOverride, registryAddCmd.Flags().Lookup(flagOverride))
 
+	registryAddCmd.Flags().Bool(flagDryRun, false, "Print the file edits as a unified diff without writing them")
+	viper.BindPFlag(vRegistryAddDryRun, registryAddCmd.Flags().Lookup(flagDryRun))
+
 	return registryAddCmd
 }
@@ -34,6 +34,7 @@ func Test_registryAddCmd(t *testing.T) {
 				actions.OptionOverride:      false,
 				actions.OptionVersion:       "",
 				actions.OptionTLSSkipVerify: false,
+				actions.OptionDryRun:        false,
 			},
 		},
 		{
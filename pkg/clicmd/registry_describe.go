@@ -24,6 +24,10 @@ import (
 	"github.com/spf13/viper"
 )
 
+const (
+	vRegistryDescribeOutput = "registry-describe-output"
+)
+
 var (
 	registryDescribeLong = `
 The ` + "`describe`" + ` command outputs documentation for the ksonnet registry identified
@@ -54,6 +58,7 @@ func newRegistryDescribeCmd(a app.App) *cobra.Command {
 			m := map[string]interface{}{
 				actions.OptionApp:           a,
 				actions.OptionName:          args[0],
+				actions.OptionOutput:        viper.GetString(vRegistryDescribeOutput),
 				actions.OptionTLSSkipVerify: viper.GetBool(flagTLSSkipVerify),
 			}
 
@@ -61,5 +66,7 @@ func newRegistryDescribeCmd(a app.App) *cobra.Command {
 		},
 	}
 
+	addCmdOutput(registryDescribeCmd, vRegistryDescribeOutput)
+
 	return registryDescribeCmd
 }
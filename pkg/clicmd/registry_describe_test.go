@@ -30,6 +30,7 @@ func Test_envRegistryDescribe(t *testing.T) {
 			expected: map[string]interface{}{
 				actions.OptionApp:           nil,
 				actions.OptionName:          "name",
+				actions.OptionOutput:        "",
 				actions.OptionTLSSkipVerify: false,
 			},
 		},
@@ -26,6 +26,7 @@ import (
 
 const (
 	vRegistryListOutput = "registry-list-output"
+	vRegistryListRemote = "registry-list-remote"
 )
 
 var (
@@ -37,6 +38,10 @@ table includes the following info:
 2. Protocol (e.g. ` + "`github`" + `)
 3. Registry URI
 
+With ` + "`--remote`" + `, each registry is contacted to show the refspec it tracks,
+the version of its cached registry spec, and the latest version available for
+the refspec. Registries whose caches are out of date are marked as stale.
+
 ### Related Commands
 
 * ` + "`ks registry describe` " + `— ` + regShortDesc["describe"] + `
@@ -58,6 +63,7 @@ func newRegistryListCmd(a app.App) *cobra.Command {
 			m := map[string]interface{}{
 				actions.OptionApp:           a,
 				actions.OptionOutput:        viper.GetString(vRegistryListOutput),
+				actions.OptionRemote:        viper.GetBool(vRegistryListRemote),
 				actions.OptionTLSSkipVerify: viper.GetBool(flagTLSSkipVerify),
 			}
 
@@ -66,5 +72,8 @@ func newRegistryListCmd(a app.App) *cobra.Command {
 	}
 
 	addCmdOutput(registryListCmd, vRegistryListOutput)
+	registryListCmd.Flags().Bool(flagRemote, false, "Contact registries to show their latest versions and stale caches")
+	viper.BindPFlag(vRegistryListRemote, registryListCmd.Flags().Lookup(flagRemote))
+
 	return registryListCmd
 }
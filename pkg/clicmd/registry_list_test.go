@@ -30,6 +30,7 @@ func Test_registryListCmd(t *testing.T) {
 			expected: map[string]interface{}{
 				actions.OptionApp:           nil,
 				actions.OptionOutput:        "",
+				actions.OptionRemote:        false,
 				actions.OptionTLSSkipVerify: false,
 			},
 		},
@@ -40,6 +41,18 @@ func Test_registryListCmd(t *testing.T) {
 			expected: map[string]interface{}{
 				actions.OptionApp:           nil,
 				actions.OptionOutput:        "json",
+				actions.OptionRemote:        false,
+				actions.OptionTLSSkipVerify: false,
+			},
+		},
+		{
+			name:   "with remote flag",
+			args:   []string{"registry", "list", "--remote"},
+			action: actionRegistryList,
+			expected: map[string]interface{}{
+				actions.OptionApp:           nil,
+				actions.OptionOutput:        "",
+				actions.OptionRemote:        true,
 				actions.OptionTLSSkipVerify: false,
 			},
 		},
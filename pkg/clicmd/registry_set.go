@@ -23,7 +23,8 @@ import (
 )
 
 const (
-	vRegistrySetURI = "registry-set-uri"
+	vRegistrySetURI     = "registry-set-uri"
+	vRegistrySetDefault = "registry-set-default"
 )
 
 var (
@@ -32,10 +33,15 @@ The ` + "`set`" + ` command sets configuration parameters on a configured regist
 The following parameters can be set:
 
 * --uri: The uri a registry points to. For GitHub-based registries, this can be used to select a specific branch.
+* --default: Make the registry the app's default registry. Packages installed without a registry
+  prefix, e.g. ` + "`ks pkg install redis`" + `, are looked up in the default registry first.
 `
 	registrySetExample = `
 	# Set the incubator registry to the experimental branch:
 	ks registry set incubator --uri https://github.com/ksonnet/parts/tree/experimental/incubator
+
+	# Make incubator the default registry:
+	ks registry set incubator --default
 `
 )
 
@@ -50,9 +56,10 @@ func newRegistrySetCmd(a app.App) *cobra.Command {
 			registryName := args[0] // len(args) was verified
 
 			m := map[string]interface{}{
-				actions.OptionApp:  a,
-				actions.OptionName: registryName,
-				actions.OptionURI:  viper.GetString(vRegistrySetURI),
+				actions.OptionApp:     a,
+				actions.OptionName:    registryName,
+				actions.OptionURI:     viper.GetString(vRegistrySetURI),
+				actions.OptionDefault: viper.GetBool(vRegistrySetDefault),
 			}
 
 			return runAction(actionRegistrySet, m)
@@ -63,5 +70,8 @@ func newRegistrySetCmd(a app.App) *cobra.Command {
 	registrySetCmd.Flags().String(flagURI, "", "URI to configure the registry")
 	viper.BindPFlag(vRegistrySetURI, registrySetCmd.Flags().Lookup(flagURI))
 
+	registrySetCmd.Flags().Bool(flagDefault, false, "Make this the app's default registry")
+	viper.BindPFlag(vRegistrySetDefault, registrySetCmd.Flags().Lookup(flagDefault))
+
 	return registrySetCmd
 }
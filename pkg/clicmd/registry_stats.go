@@ -0,0 +1,93 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"fmt"
+
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vRegistryStatsOutput  = "registry-stats-output"
+	vRegistryStatsEnable  = "registry-stats-enable"
+	vRegistryStatsDisable = "registry-stats-disable"
+)
+
+var (
+	registryStatsLong = `
+The ` + "`stats`" + ` command displays the statistics recorded for each registry:
+
+1. The number of times the registry was fetched, and the average time it took
+2. The fraction of fetches served from the registry cache
+3. The number of HTTP requests made to the registry
+4. The requests used from the registry's rate limit, and when the limit resets
+
+Stats are not recorded until they are enabled with ` + "`--enable`" + `. They are
+stored in the app's ` + "`.ksonnet`" + ` directory and never leave your machine.
+` + "`--disable`" + ` stops recording stats and removes the ones which were recorded.
+
+### Related Commands
+
+* ` + "`ks registry list` " + `— ` + regShortDesc["list"] + `
+
+### Syntax
+`
+	registryStatsExample = `
+# Start recording registry stats
+ks registry stats --enable
+
+# Show the recorded stats
+ks registry stats
+
+# Stop recording registry stats, and remove them
+ks registry stats --disable
+`
+)
+
+func newRegistryStatsCmd(a app.App) *cobra.Command {
+	registryStatsCmd := &cobra.Command{
+		Use:     "stats",
+		Short:   regShortDesc["stats"],
+		Long:    registryStatsLong,
+		Example: registryStatsExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf("Command 'registry stats' does not take arguments")
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:     a,
+				actions.OptionOutput:  viper.GetString(vRegistryStatsOutput),
+				actions.OptionEnable:  viper.GetBool(vRegistryStatsEnable),
+				actions.OptionDisable: viper.GetBool(vRegistryStatsDisable),
+			}
+
+			return runAction(actionRegistryStats, m)
+		},
+	}
+
+	addCmdOutput(registryStatsCmd, vRegistryStatsOutput)
+	registryStatsCmd.Flags().Bool(flagEnable, false, "Start recording registry stats")
+	viper.BindPFlag(vRegistryStatsEnable, registryStatsCmd.Flags().Lookup(flagEnable))
+	registryStatsCmd.Flags().Bool(flagDisable, false, "Stop recording registry stats, and remove them")
+	viper.BindPFlag(vRegistryStatsDisable, registryStatsCmd.Flags().Lookup(flagDisable))
+
+	return registryStatsCmd
+}
@@ -0,0 +1,67 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/actions"
+)
+
+func Test_registryStatsCmd(t *testing.T) {
+	cases := []cmdTestCase{
+		{
+			name:   "in general",
+			args:   []string{"registry", "stats"},
+			action: actionRegistryStats,
+			expected: map[string]interface{}{
+				actions.OptionApp:     nil,
+				actions.OptionOutput:  "",
+				actions.OptionEnable:  false,
+				actions.OptionDisable: false,
+			},
+		},
+		{
+			name:   "enable",
+			args:   []string{"registry", "stats", "--enable"},
+			action: actionRegistryStats,
+			expected: map[string]interface{}{
+				actions.OptionApp:     nil,
+				actions.OptionOutput:  "",
+				actions.OptionEnable:  true,
+				actions.OptionDisable: false,
+			},
+		},
+		{
+			name:   "disable",
+			args:   []string{"registry", "stats", "--disable"},
+			action: actionRegistryStats,
+			expected: map[string]interface{}{
+				actions.OptionApp:     nil,
+				actions.OptionOutput:  "",
+				actions.OptionEnable:  false,
+				actions.OptionDisable: true,
+			},
+		},
+		{
+			name:  "invalid arguments",
+			args:  []string{"registry", "stats", "invalid"},
+			isErr: true,
+		},
+	}
+
+	runTestCmd(t, cases)
+}
@@ -0,0 +1,123 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/env"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vRenderForArgoCDEnv = "render-for-argocd-env"
+
+	// argoCDEnvPrefix is the prefix Argo CD adds to the environment variables
+	// of an application which are passed to config management plugins.
+	argoCDEnvPrefix = "ARGOCD_ENV_"
+)
+
+var (
+	renderForArgoCDLong = `
+The ` + "`render-for-argocd`" + ` command is the entrypoint for an Argo CD config
+management plugin. It renders the manifests of an environment as YAML, and
+follows the plugin contract:
+
+* Nothing but manifests is written to stdout. Logs are written to stderr.
+* The output is deterministic; objects are sorted by namespace, kind, and name.
+* If any manifest can not be rendered, nothing is written to stdout, and the
+  exit code is not zero.
+
+Environment variables of the Argo CD application, which Argo CD passes to the
+plugin with an ` + "`ARGOCD_ENV_`" + ` prefix, are external variables of the same name
+without the prefix, e.g. ` + "`ARGOCD_ENV_IMAGE_TAG`" + ` is ` + "`std.extVar('IMAGE_TAG')`" + `.
+Variables set with ` + "`--ext-str`" + ` take precedence.
+
+See docs/argocd.md for the plugin's configuration.
+
+### Related Commands
+
+* ` + "`ks show` " + `— ` + showShortDesc + `
+
+### Syntax
+`
+	renderForArgoCDExample = `
+# Render the 'prod' environment for Argo CD.
+ks render-for-argocd --env prod
+
+# Render the 'prod' environment with the image tag set by the Argo CD
+# application's plugin environment.
+ARGOCD_ENV_IMAGE_TAG=v1.2.3 ks render-for-argocd --env prod`
+)
+
+func newRenderForArgoCDCmd(a app.App) *cobra.Command {
+	renderForArgoCDCmd := &cobra.Command{
+		Use:     "render-for-argocd --env <env-name>",
+		Short:   "Render an environment's manifests for an Argo CD config management plugin",
+		Long:    renderForArgoCDLong,
+		Example: renderForArgoCDExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return errors.New("'render-for-argocd' takes no arguments; set the environment with --env")
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:     a,
+				actions.OptionEnvName: viper.GetString(vRenderForArgoCDEnv),
+			}
+
+			for k, v := range argoCDExtVars(os.Environ()) {
+				env.AddExtVar(k, v)
+			}
+
+			if err := extractJsonnetFlags(a, "render-for-argocd"); err != nil {
+				return errors.Wrap(err, "handle jsonnet flags")
+			}
+
+			return runAction(actionRenderForArgoCD, m)
+		},
+	}
+	bindJsonnetFlags(renderForArgoCDCmd, "render-for-argocd")
+
+	renderForArgoCDCmd.Flags().String(flagEnv, "", "Environment to render")
+	viper.BindPFlag(vRenderForArgoCDEnv, renderForArgoCDCmd.Flags().Lookup(flagEnv))
+
+	return renderForArgoCDCmd
+}
+
+// argoCDExtVars returns the external variables set by Argo CD in environ.
+func argoCDExtVars(environ []string) map[string]string {
+	vars := make(map[string]string)
+	for _, kv := range environ {
+		if !strings.HasPrefix(kv, argoCDEnvPrefix) {
+			continue
+		}
+
+		parts := strings.SplitN(strings.TrimPrefix(kv, argoCDEnvPrefix), "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			continue
+		}
+
+		vars[parts[0]] = parts[1]
+	}
+
+	return vars
+}
@@ -0,0 +1,68 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_renderForArgoCDCmd(t *testing.T) {
+	cases := []cmdTestCase{
+		{
+			name:   "with an env",
+			args:   []string{"render-for-argocd", "--env", "prod"},
+			action: actionRenderForArgoCD,
+			expected: map[string]interface{}{
+				actions.OptionApp:     nil,
+				actions.OptionEnvName: "prod",
+			},
+		},
+		{
+			name:  "with arguments",
+			args:  []string{"render-for-argocd", "prod"},
+			isErr: true,
+		},
+		{
+			name:  "invalid jsonnet flag",
+			args:  []string{"render-for-argocd", "--env", "prod", "--ext-str", "foo"},
+			isErr: true,
+		},
+	}
+
+	runTestCmd(t, cases)
+}
+
+func Test_argoCDExtVars(t *testing.T) {
+	environ := []string{
+		"PATH=/usr/bin",
+		"ARGOCD_APP_NAME=guestbook",
+		"ARGOCD_ENV_IMAGE_TAG=v1.2.3",
+		"ARGOCD_ENV_REPLICAS=3",
+		"ARGOCD_ENV_QUERY=a=b",
+		"ARGOCD_ENV_=ignored",
+	}
+
+	expected := map[string]string{
+		"IMAGE_TAG": "v1.2.3",
+		"REPLICAS":  "3",
+		"QUERY":     "a=b",
+	}
+
+	assert.Equal(t, expected, argoCDExtVars(environ))
+}
@@ -16,14 +16,14 @@
 package clicmd
 
 import (
-	"fmt"
-	"os"
 	"path/filepath"
 
 	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/bundle"
 	"github.com/ksonnet/ksonnet/pkg/log"
 	"github.com/ksonnet/ksonnet/pkg/plugin"
 	"github.com/ksonnet/ksonnet/pkg/util/strings"
+	"github.com/ksonnet/ksonnet/pkg/util/trace"
 	"github.com/pkg/errors"
 	"github.com/shomron/pflag"
 	"github.com/spf13/afero"
@@ -43,44 +43,15 @@ application configuration to remote clusters.
 	`
 )
 
-func runPlugin(fs afero.Fs, p plugin.Plugin, args []string) error {
-	env := []string{
-		fmt.Sprintf("KS_PLUGIN_DIR=%s", p.RootDir),
-		fmt.Sprintf("KS_PLUGIN_NAME=%s", p.Config.Name),
-		fmt.Sprintf("HOME=%s", os.Getenv("HOME")),
-	}
-
-	root, err := appRoot()
-	if err != nil {
-		return err
-	}
-
-	appConfig := filepath.Join(root, "app.yaml")
-	exists, err := afero.Exists(fs, appConfig)
-	if err != nil {
-		return err
-	}
-
-	if exists {
-		env = append(env, fmt.Sprintf("KS_APP_DIR=%s", root))
-		// TODO: make kube context or something similar available to the plugin
-	}
-
-	cmd := p.BuildRunCmd(env, args)
-	return cmd.Run()
-}
-
 // addEnvCmdFlags adds the flags that are common to the family of commands
 // whose form is `[<env>|-f <file-name>]`, e.g., `apply` and `delete`.
 func addEnvCmdFlags(cmd *cobra.Command) {
 	cmd.PersistentFlags().StringSliceP(flagComponent, shortComponent, nil, "Name of a specific component (multiple -c flags accepted, allows YAML, JSON, and Jsonnet)")
 }
 
-func appRoot() (string, error) {
-	return os.Getwd()
-}
-
 type earlyParseArgs struct {
+	appDir        string
+	bundle        string
 	command       string
 	help          bool
 	tlsSkipVerify bool
@@ -95,6 +66,8 @@ func parseCommand(args []string) (earlyParseArgs, error) {
 	fset.ParseErrorsWhitelist.UnknownFlags = true
 	fset.BoolVarP(&parsed.help, "help", "h", false, "") // Needed to avoid pflag.ErrHelp
 	fset.BoolVar(&parsed.tlsSkipVerify, flagTLSSkipVerify, false, "")
+	fset.StringVar(&parsed.appDir, flagAppDir, "", "")
+	fset.StringVar(&parsed.bundle, flagBundle, "", "")
 	if err := fset.Parse(args); err != nil {
 		return earlyParseArgs{}, err
 	}
@@ -140,13 +113,50 @@ func NewRoot(appFs afero.Fs, wd string, args []string) (*cobra.Command, error) {
 	}
 	httpClient := app.NewHTTPClient(parsed.tlsSkipVerify)
 
-	cmds := []string{"init", "version", "help"}
+	// The app is discovered by walking up from the app directory, which
+	// defaults to the working directory.
+	appDir := wd
+	if parsed.appDir != "" {
+		appDir = parsed.appDir
+		if !filepath.IsAbs(appDir) {
+			appDir = filepath.Join(wd, appDir)
+		}
+	}
+
+	// Commands run from a bundle use the app it is extracted to.
+	if parsed.bundle != "" {
+		if parsed.appDir != "" {
+			return nil, errors.Errorf("--%s and --%s can not both be specified", flagAppDir, flagBundle)
+		}
+
+		bundlePath := parsed.bundle
+		if !filepath.IsAbs(bundlePath) {
+			bundlePath = filepath.Join(wd, bundlePath)
+		}
+
+		if appDir, err = bundle.Open(appFs, bundlePath); err != nil {
+			return nil, err
+		}
+	}
+
+	cmds := []string{"completion", "init", "serve", "version", "help"}
 	switch {
 	// Commands that do not require a ksonnet application
 	case strings.InSlice(parsed.command, cmds), parsed.help:
 		a, err = app.Load(appFs, httpClient, wd, true)
+	case parsed.command == completeCmdName:
+		// Completion runs outside of an app, with nothing to complete.
+		if a, err = app.Load(appFs, httpClient, appDir, false); err != nil {
+			a, err = nil, nil
+		}
 	case len(args) > 0:
-		a, err = app.Load(appFs, httpClient, wd, false)
+		a, err = app.Load(appFs, httpClient, appDir, false)
+		if err != nil {
+			// Plugins can be run outside of an app.
+			if _, findErr := plugin.Find(appFs, parsed.command); findErr == nil {
+				a, err = nil, nil
+			}
+		}
 	default:
 		// noop
 	}
@@ -155,8 +165,11 @@ func NewRoot(appFs afero.Fs, wd string, args []string) (*cobra.Command, error) {
 		return nil, err
 	}
 
-	if err := checkUpgrade(a, parsed.command); err != nil {
-		return nil, errors.Wrap(err, "checking if app needs upgrade")
+	// Plugins and completion may run without an app.
+	if a != nil {
+		if err := checkUpgrade(a, parsed.command); err != nil {
+			return nil, errors.Wrap(err, "checking if app needs upgrade")
+		}
 	}
 
 	rootCmd := &cobra.Command{
@@ -174,32 +187,22 @@ func NewRoot(appFs afero.Fs, wd string, args []string) (*cobra.Command, error) {
 
 			log.Init(verbosity, cmd.OutOrStderr())
 
-			return nil
-		},
-		Args: func(cmd *cobra.Command, args []string) error {
-			if len(args) == 0 {
-				return cobra.NoArgs(cmd, args)
-			}
-
-			pluginName := args[0]
-			_, err := plugin.Find(appFs, pluginName)
+			logLevels, err := flags.GetStringSlice(flagLogLevel)
 			if err != nil {
-				return cobra.NoArgs(cmd, args)
+				return err
 			}
-
-			return nil
-		},
-		RunE: func(cmd *cobra.Command, args []string) error {
-			if len(args) == 0 {
-				return cmd.Help()
+			if err := setLogLevels(a, logLevels); err != nil {
+				return err
 			}
-			pluginName, args := args[0], args[1:]
-			p, err := plugin.Find(appFs, pluginName)
-			if err != nil {
+
+			if err := startEvents(cmd.OutOrStderr()); err != nil {
 				return err
 			}
 
-			return runPlugin(appFs, p, args)
+			return startTrace(appFs, cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
 		},
 	}
 
@@ -207,26 +210,65 @@ func NewRoot(appFs afero.Fs, wd string, args []string) (*cobra.Command, error) {
 
 	rootCmd.PersistentFlags().CountP(flagVerbose, "v", "Increase verbosity. May be given multiple times.")
 	rootCmd.PersistentFlags().Set("logtostderr", "true")
+	rootCmd.PersistentFlags().String(flagAppDir, "", "Directory of the ksonnet application. Defaults to the nearest directory containing app.yaml, searching up from the current directory")
+	rootCmd.PersistentFlags().String(flagBundle, "", "Bundle written by ks bundle to run the command from, instead of an app directory")
+	rootCmd.PersistentFlags().StringSlice(flagLogLevel, nil, "Log level of a subsystem, e.g. registry=debug. A level on its own sets the level of other logs. Subsystems: cluster, evaluation, registry")
+	rootCmd.PersistentFlags().String(flagLogFormat, logFormatText, "Format of log output. Valid options: text|json")
+	viper.BindPFlag(flagLogFormat, rootCmd.PersistentFlags().Lookup(flagLogFormat))
 	rootCmd.PersistentFlags().Bool(flagTLSSkipVerify, false, "Skip verification of TLS server certificates")
 	viper.BindPFlag(flagTLSSkipVerify, rootCmd.PersistentFlags().Lookup(flagTLSSkipVerify))
-
+	rootCmd.PersistentFlags().String(flagTraceEndpoint, "", "Export a trace of the command's operations to an OpenTelemetry collector with OTLP/HTTP, e.g. http://localhost:4318. Defaults to $"+envOTLPEndpoint)
+	viper.BindPFlag(flagTraceEndpoint, rootCmd.PersistentFlags().Lookup(flagTraceEndpoint))
+	rootCmd.PersistentFlags().String(flagTraceFile, "", "Write a trace of the command's operations to a file, for Chrome tracing or Jaeger")
+	viper.BindPFlag(flagTraceFile, rootCmd.PersistentFlags().Lookup(flagTraceFile))
+	rootCmd.PersistentFlags().String(flagTraceFormat, trace.FormatChrome, "Format of the trace file. Valid options: chrome|jaeger")
+	viper.BindPFlag(flagTraceFormat, rootCmd.PersistentFlags().Lookup(flagTraceFormat))
+	rootCmd.PersistentFlags().String(flagMetricsFile, "", "Write Prometheus metrics of the command's operation durations to a file")
+	viper.BindPFlag(flagMetricsFile, rootCmd.PersistentFlags().Lookup(flagMetricsFile))
+
+	rootCmd.AddCommand(newAPICmd(a))
 	rootCmd.AddCommand(newApplyCmd(a))
+	rootCmd.AddCommand(newBundleCmd(a))
+	rootCmd.AddCommand(newCompleteCmd(a))
+	rootCmd.AddCommand(newCompletionCmd())
 	rootCmd.AddCommand(newComponentCmd(a))
 	rootCmd.AddCommand(newDeleteCmd(a))
+	rootCmd.AddCommand(newDevCmd(a))
 	rootCmd.AddCommand(newDiffCmd(a))
+	rootCmd.AddCommand(newDoctorCmd(a))
 	rootCmd.AddCommand(newEnvCmd(a))
+	rootCmd.AddCommand(newEvalCmd(a))
+	rootCmd.AddCommand(newExplainCmd(a))
+	rootCmd.AddCommand(newExportCmd(a))
+	rootCmd.AddCommand(newFmtCmd(a))
 	rootCmd.AddCommand(newGenerateCmd(a))
+	rootCmd.AddCommand(newGraphCmd(a))
+	rootCmd.AddCommand(newImageCmd(a))
 	rootCmd.AddCommand(newImportCmd(a))
 	rootCmd.AddCommand(newInitCmd(appFs, wd))
+	rootCmd.AddCommand(newInventoryCmd(a))
+	rootCmd.AddCommand(newJbCmd(a))
+	rootCmd.AddCommand(newLintCmd(a))
+	rootCmd.AddCommand(newLogsCmd(a))
 	rootCmd.AddCommand(newModuleCmd(a))
 	rootCmd.AddCommand(newParamCmd(a))
 	rootCmd.AddCommand(newPkgCmd(a))
+	rootCmd.AddCommand(newPortForwardCmd(a))
 	rootCmd.AddCommand(newPrototypeCmd(a))
 	rootCmd.AddCommand(newRegistryCmd(a))
+	rootCmd.AddCommand(newRenderForArgoCDCmd(a))
+	rootCmd.AddCommand(newServeCmd())
 	rootCmd.AddCommand(newShowCmd(a))
+	rootCmd.AddCommand(newSnapshotCmd(a))
+	rootCmd.AddCommand(newStatusCmd(a))
+	rootCmd.AddCommand(newTestCmd(a))
+	rootCmd.AddCommand(newToolCmd(a))
+	rootCmd.AddCommand(newUICmd(a))
 	rootCmd.AddCommand(newValidateCmd(a))
 	rootCmd.AddCommand(newUpgradeCmd(a))
 	rootCmd.AddCommand(newVersionCmd())
 
+	addPluginCmds(rootCmd, appFs, a)
+
 	return rootCmd, nil
 }
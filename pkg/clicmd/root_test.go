@@ -16,8 +16,12 @@
 package clicmd
 
 import (
+	"bytes"
 	"testing"
 
+	"github.com/ksonnet/ksonnet/pkg/bundle"
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -71,6 +75,22 @@ func Test_parseCommand(t *testing.T) {
 				tlsSkipVerify: true,
 			},
 		},
+		{
+			name: "app-dir",
+			args: []string{"--app-dir", "/app", "whatever"},
+			expected: earlyParseArgs{
+				appDir:  "/app",
+				command: "whatever",
+			},
+		},
+		{
+			name: "bundle",
+			args: []string{"show", "--bundle", "app.tgz", "prod"},
+			expected: earlyParseArgs{
+				bundle:  "app.tgz",
+				command: "show",
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -80,3 +100,106 @@ func Test_parseCommand(t *testing.T) {
 
 	}
 }
+
+func TestNewRoot_app_dir(t *testing.T) {
+	tests := []struct {
+		name  string
+		wd    string
+		args  []string
+		isErr bool
+	}{
+		{
+			name: "app root",
+			wd:   "/app",
+			args: []string{"env", "list"},
+		},
+		{
+			name: "app subdirectory",
+			wd:   "/app/components/nested",
+			args: []string{"env", "list"},
+		},
+		{
+			name: "explicit app dir",
+			wd:   "/other",
+			args: []string{"--app-dir", "/app", "env", "list"},
+		},
+		{
+			name: "relative app dir",
+			wd:   "/",
+			args: []string{"env", "list", "--app-dir", "app/components"},
+		},
+		{
+			name:  "outside of app",
+			wd:    "/other",
+			args:  []string{"env", "list"},
+			isErr: true,
+		},
+		{
+			name:  "app dir outside of app",
+			wd:    "/app",
+			args:  []string{"--app-dir", "/other", "env", "list"},
+			isErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			test.StageFile(t, fs, "app.yaml", "/app/app.yaml")
+
+			_, err := NewRoot(fs, tc.wd, tc.args)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestNewRoot_bundle(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		isErr bool
+	}{
+		{
+			name: "bundle",
+			args: []string{"--bundle", "/app.tgz", "env", "list"},
+		},
+		{
+			name: "relative bundle",
+			args: []string{"env", "list", "--bundle", "../app.tgz"},
+		},
+		{
+			name:  "missing bundle",
+			args:  []string{"--bundle", "/missing.tgz", "env", "list"},
+			isErr: true,
+		},
+		{
+			name:  "bundle and app dir",
+			args:  []string{"--bundle", "/app.tgz", "--app-dir", "/app", "env", "list"},
+			isErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			test.StageFile(t, fs, "app.yaml", "/app/app.yaml")
+
+			var buf bytes.Buffer
+			require.NoError(t, bundle.Write(fs, "/app", &buf))
+			require.NoError(t, afero.WriteFile(fs, "/app.tgz", buf.Bytes(), 0644))
+
+			_, err := NewRoot(fs, "/other", tc.args)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ksonnet/ksonnet/pkg/server"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vServeAddr            = "serve-addr"
+	vServeAllowGitRemotes = "serve-allow-git-remotes"
+	vServeRoot            = "serve-root"
+	vServeToken           = "serve-token"
+
+	// envServeToken is the environment variable the server's token is read
+	// from if --token is not set.
+	envServeToken = "KS_SERVE_TOKEN"
+
+	serveShortDesc = "Serve renders, diffs, applies, and statuses of apps over HTTP"
+	serveLong      = `
+The ` + "`serve`" + ` command runs a long-running server, so CI systems and UIs can
+drive ksonnet without running ` + "`ks`" + ` for each operation. Each operation is a
+POST with a JSON body:
+
+* ` + "`/v1/render`" + ` — the objects of an environment.
+* ` + "`/v1/diff`" + ` — the differences between an environment and its cluster.
+* ` + "`/v1/apply`" + ` — apply an environment to its cluster.
+* ` + "`/v1/status`" + ` — the live status of an environment's objects.
+
+The body names the app, which is either a directory in ` + "`--root`" + `, or a git
+repository which is checked out for the request. Git apps are only served
+from the remotes allowed with ` + "`--allow-git-remote`" + `. Their local command
+hooks are not run, jsonnet can not be imported from URLs, and generators can
+not read files:
+
+    {"app": {"dir": "guestbook"}, "env": "prod", "components": ["redis"]}
+    {"app": {"git": {"remote": "https://github.com/org/apps", "ref": "v1", "subdir": "guestbook"}}, "env": "prod"}
+
+Diffs and statuses accept an ` + "`output`" + ` format, and applies a ` + "`dryRun`" + `
+boolean. Responses are JSON, with the ` + "`objects`" + `, ` + "`output`" + `, ` + "`differences`" + `,
+or ` + "`error`" + ` of the operation. Operations are run one at a time.
+
+The API is only served as JSON over HTTP; there is no gRPC API. Clients which
+need gRPC can put a gateway in front of the server.
+
+Clusters are reached with the server's kubeconfig. The server listens on
+localhost by default. Requests must have an ` + "`Authorization: Bearer <token>`" + `
+header if a token is set with ` + "`--token`" + ` or ` + "`$KS_SERVE_TOKEN`" + `; set one before
+exposing the server outside of localhost.
+
+### Related Commands
+
+* ` + "`ks apply` " + `— ` + applyShortDesc + `
+* ` + "`ks diff` " + `— ` + diffShortDesc + `
+* ` + "`ks status` " + `— ` + statusShortDesc + `
+
+### Syntax
+`
+	serveExample = `# Serve the apps in /srv/apps on localhost port 8080.
+ks serve --root /srv/apps --addr 127.0.0.1:8080
+
+# Serve on all interfaces, requiring a token, and allow apps from a git remote.
+KS_SERVE_TOKEN=secret ks serve --addr :8080 --allow-git-remote https://github.com/org/apps
+
+# Render the 'prod' environment of the 'guestbook' app.
+curl -H 'Authorization: Bearer secret' -d '{"app": {"dir": "guestbook"}, "env": "prod"}' http://localhost:8080/v1/render`
+)
+
+func newServeCmd() *cobra.Command {
+	serveCmd := &cobra.Command{
+		Use:     "serve [--addr <addr>] [--root <dir>] [--token <token>] [--allow-git-remote <remote>]",
+		Short:   serveShortDesc,
+		Long:    serveLong,
+		Example: serveExample,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := viper.GetString(vServeRoot)
+			if root == "" {
+				wd, err := os.Getwd()
+				if err != nil {
+					return err
+				}
+				root = wd
+			}
+
+			token := viper.GetString(vServeToken)
+			if token == "" {
+				token = os.Getenv(envServeToken)
+			}
+
+			s := server.New(root,
+				server.WithToken(token),
+				server.WithGitRemotes(viper.GetStringSlice(vServeAllowGitRemotes)...))
+
+			return s.Serve(viper.GetString(vServeAddr), interruptCh())
+		},
+	}
+
+	serveCmd.Flags().String(flagAddr, "127.0.0.1:8080", "Address to listen on")
+	viper.BindPFlag(vServeAddr, serveCmd.Flags().Lookup(flagAddr))
+
+	serveCmd.Flags().String(flagRoot, "", "Directory apps are found in. Defaults to the current directory")
+	viper.BindPFlag(vServeRoot, serveCmd.Flags().Lookup(flagRoot))
+
+	serveCmd.Flags().String(flagToken, "", "Bearer token requests must have. Defaults to $"+envServeToken)
+	viper.BindPFlag(vServeToken, serveCmd.Flags().Lookup(flagToken))
+
+	serveCmd.Flags().StringSlice(flagAllowGitRemote, nil, "Git remote apps may be checked out from. Git apps are not served if none are allowed")
+	viper.BindPFlag(vServeAllowGitRemotes, serveCmd.Flags().Lookup(flagAllowGitRemote))
+
+	return serveCmd
+}
+
+// interruptCh returns a channel which is closed on SIGINT or SIGTERM.
+func interruptCh() <-chan struct{} {
+	stopCh := make(chan struct{})
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-sigCh
+		signal.Stop(sigCh)
+		close(stopCh)
+	}()
+
+	return stopCh
+}
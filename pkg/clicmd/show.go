@@ -24,9 +24,11 @@ import (
 )
 
 const (
-	showShortDesc  = "Show expanded manifests for a specific environment."
-	vShowComponent = "show-components"
-	vShowFormat    = "show-format"
+	showShortDesc           = "Show expanded manifests for a specific environment."
+	vShowComponent          = "show-components"
+	vShowFormat             = "show-format"
+	vShowSecrets            = "show-secrets"
+	vShowVerifyReproducible = "show-verify-reproducible"
 )
 
 var (
@@ -44,6 +46,17 @@ This is the YAML version of what gets deployed to your cluster with
 When a component IS specified via the ` + "`-c`" + ` flag, this command only expands the
 manifest for that particular component.
 
+The data of Secrets, and the fields selected by the environment's ` + "`redact`" + ` rules
+in ` + "`app.yaml`" + `, are redacted, so they don't leak into terminals or CI logs. Each
+value is replaced with a hash, which is the same for equal values within a
+command. Use ` + "`--show-secrets`" + ` to show them.
+
+With ` + "`--verify-reproducible`" + `, the environment is rendered twice, the second time
+with a different timezone and locale, and the command fails, showing the
+differences, if the renderings differ. Nondeterministic output, such as
+timestamps or the order of map keys leaking into lists, causes spurious diffs in
+GitOps repositories.
+
 ### Related Commands
 
 * ` + "`ks validate` " + `— ` + valShortDesc + `
@@ -61,6 +74,9 @@ ks show prod -c redis -o json
 
 # Show multiple components from the 'dev' environment, in YAML
 ks show dev -c redis -c nginx-server
+
+# Fail if the 'prod' environment does not render the same way twice
+ks show prod --verify-reproducible
 `
 )
 
@@ -77,10 +93,12 @@ func newShowCmd(a app.App) *cobra.Command {
 			}
 
 			m := map[string]interface{}{
-				actions.OptionApp:            a,
-				actions.OptionComponentNames: viper.GetStringSlice(vShowComponent),
-				actions.OptionEnvName:        envName,
-				actions.OptionFormat:         viper.GetString(vShowFormat),
+				actions.OptionApp:                a,
+				actions.OptionComponentNames:     viper.GetStringSlice(vShowComponent),
+				actions.OptionEnvName:            envName,
+				actions.OptionFormat:             viper.GetString(vShowFormat),
+				actions.OptionShowSecrets:        viper.GetBool(vShowSecrets),
+				actions.OptionVerifyReproducible: viper.GetBool(vShowVerifyReproducible),
 			}
 
 			if err := extractJsonnetFlags(a, "show"); err != nil {
@@ -98,5 +116,11 @@ func newShowCmd(a app.App) *cobra.Command {
 	showCmd.Flags().StringP(flagFormat, shortFormat, "yaml", "Output format.  Supported values are: json, yaml")
 	viper.BindPFlag(vShowFormat, showCmd.Flags().Lookup(flagFormat))
 
+	showCmd.Flags().Bool(flagShowSecrets, false, "Show the data of Secrets, and the fields selected by redact rules, instead of redacting them")
+	viper.BindPFlag(vShowSecrets, showCmd.Flags().Lookup(flagShowSecrets))
+
+	showCmd.Flags().Bool(flagVerifyReproducible, false, "Render the environment twice, in different timezones and locales, and fail if the renderings differ")
+	viper.BindPFlag(vShowVerifyReproducible, showCmd.Flags().Lookup(flagVerifyReproducible))
+
 	return showCmd
 }
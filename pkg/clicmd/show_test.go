@@ -28,10 +28,38 @@ func Test_showCmd(t *testing.T) {
 			args:   []string{"show", "default"},
 			action: actionShow,
 			expected: map[string]interface{}{
-				actions.OptionApp:            nil,
-				actions.OptionEnvName:        "default",
-				actions.OptionComponentNames: make([]string, 0),
-				actions.OptionFormat:         "yaml",
+				actions.OptionApp:                nil,
+				actions.OptionEnvName:            "default",
+				actions.OptionComponentNames:     make([]string, 0),
+				actions.OptionFormat:             "yaml",
+				actions.OptionShowSecrets:        false,
+				actions.OptionVerifyReproducible: false,
+			},
+		},
+		{
+			name:   "show secrets",
+			args:   []string{"show", "default", "--show-secrets"},
+			action: actionShow,
+			expected: map[string]interface{}{
+				actions.OptionApp:                nil,
+				actions.OptionEnvName:            "default",
+				actions.OptionComponentNames:     make([]string, 0),
+				actions.OptionFormat:             "yaml",
+				actions.OptionShowSecrets:        true,
+				actions.OptionVerifyReproducible: false,
+			},
+		},
+		{
+			name:   "verify reproducible",
+			args:   []string{"show", "default", "--verify-reproducible"},
+			action: actionShow,
+			expected: map[string]interface{}{
+				actions.OptionApp:                nil,
+				actions.OptionEnvName:            "default",
+				actions.OptionComponentNames:     make([]string, 0),
+				actions.OptionFormat:             "yaml",
+				actions.OptionShowSecrets:        false,
+				actions.OptionVerifyReproducible: true,
 			},
 		},
 		{
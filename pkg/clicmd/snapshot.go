@@ -0,0 +1,37 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/spf13/cobra"
+)
+
+func newSnapshotCmd(a app.App) *cobra.Command {
+	snapshotCmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Record and verify snapshots of an environment's manifests",
+		Long: `Record the manifests an environment renders to in the app's ` + "`snapshots/`" + `
+directory, and verify the current rendering matches them. Checking snapshots
+in to source control guards refactors of shared libraries against unintended
+changes to manifests.`,
+	}
+
+	snapshotCmd.AddCommand(newSnapshotRecordCmd(a))
+	snapshotCmd.AddCommand(newSnapshotVerifyCmd(a))
+
+	return snapshotCmd
+}
@@ -0,0 +1,74 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	snapshotRecordLong = `
+The ` + "`record`" + ` command renders the manifests of an environment, and writes each
+object to its own YAML file in ` + "`snapshots/<env-name>/<component>/`" + `. Any
+previous snapshot of the environment is replaced.
+
+### Related Commands
+
+* ` + "`ks snapshot verify` " + `— Verify an environment's manifests match its snapshot
+* ` + "`ks show` " + `— ` + showShortDesc + `
+
+### Syntax
+`
+	snapshotRecordExample = `
+# Record the manifests of the 'dev' environment.
+ks snapshot record dev`
+)
+
+func newSnapshotRecordCmd(a app.App) *cobra.Command {
+	snapshotRecordCmd := &cobra.Command{
+		Use:     "record [<env-name>]",
+		Short:   "Record a snapshot of an environment's manifests",
+		Long:    snapshotRecordLong,
+		Example: snapshotRecordExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				return errors.New("'snapshot record' takes at most one environment")
+			}
+
+			var envName string
+			if len(args) == 1 {
+				envName = args[0]
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:     a,
+				actions.OptionEnvName: envName,
+			}
+
+			if err := extractJsonnetFlags(a, "snapshot-record"); err != nil {
+				return errors.Wrap(err, "handle jsonnet flags")
+			}
+
+			return runAction(actionSnapshotRecord, m)
+		},
+	}
+	bindJsonnetFlags(snapshotRecordCmd, "snapshot-record")
+
+	return snapshotRecordCmd
+}
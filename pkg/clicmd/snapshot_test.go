@@ -0,0 +1,66 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/actions"
+)
+
+func Test_snapshotCmd(t *testing.T) {
+	cases := []cmdTestCase{
+		{
+			name:   "record",
+			args:   []string{"snapshot", "record", "dev"},
+			action: actionSnapshotRecord,
+			expected: map[string]interface{}{
+				actions.OptionApp:     nil,
+				actions.OptionEnvName: "dev",
+			},
+		},
+		{
+			name:   "record in the current environment",
+			args:   []string{"snapshot", "record"},
+			action: actionSnapshotRecord,
+			expected: map[string]interface{}{
+				actions.OptionApp:     nil,
+				actions.OptionEnvName: "",
+			},
+		},
+		{
+			name:  "record with too many arguments",
+			args:  []string{"snapshot", "record", "dev", "prod"},
+			isErr: true,
+		},
+		{
+			name:   "verify",
+			args:   []string{"snapshot", "verify", "dev"},
+			action: actionSnapshotVerify,
+			expected: map[string]interface{}{
+				actions.OptionApp:     nil,
+				actions.OptionEnvName: "dev",
+			},
+		},
+		{
+			name:  "verify with an invalid jsonnet flag",
+			args:  []string{"snapshot", "verify", "dev", "--ext-str", "foo"},
+			isErr: true,
+		},
+	}
+
+	runTestCmd(t, cases)
+}
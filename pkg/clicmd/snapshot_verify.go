@@ -0,0 +1,75 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	snapshotVerifyLong = `
+The ` + "`verify`" + ` command renders the manifests of an environment, and compares
+them with the environment's snapshot. It lists the objects which were added,
+removed, or changed since the snapshot was recorded, with a diff of each
+change, and fails if there are any, so it can be run in CI.
+
+### Related Commands
+
+* ` + "`ks snapshot record` " + `— Record a snapshot of an environment's manifests
+* ` + "`ks diff` " + `— ` + diffShortDesc + `
+
+### Syntax
+`
+	snapshotVerifyExample = `
+# Verify the manifests of the 'dev' environment match its snapshot.
+ks snapshot verify dev`
+)
+
+func newSnapshotVerifyCmd(a app.App) *cobra.Command {
+	snapshotVerifyCmd := &cobra.Command{
+		Use:     "verify [<env-name>]",
+		Short:   "Verify an environment's manifests match its snapshot",
+		Long:    snapshotVerifyLong,
+		Example: snapshotVerifyExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				return errors.New("'snapshot verify' takes at most one environment")
+			}
+
+			var envName string
+			if len(args) == 1 {
+				envName = args[0]
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:     a,
+				actions.OptionEnvName: envName,
+			}
+
+			if err := extractJsonnetFlags(a, "snapshot-verify"); err != nil {
+				return errors.Wrap(err, "handle jsonnet flags")
+			}
+
+			return runAction(actionSnapshotVerify, m)
+		},
+	}
+	bindJsonnetFlags(snapshotVerifyCmd, "snapshot-verify")
+
+	return snapshotVerifyCmd
+}
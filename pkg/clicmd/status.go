@@ -0,0 +1,105 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vStatusComponent      = "status-components"
+	vStatusMaxUnavailable = "status-max-unavailable-clusters"
+	vStatusOutput         = "status-output"
+
+	statusShortDesc = "Show the live status of the resources an environment manages"
+	statusLong      = `
+The ` + "`status`" + ` command lists every resource that an environment manages, i.e.
+every resource rendered from the app's components for ` + "`<env-name>`" + `, with its
+live state in the environment's cluster:
+
+* ` + "`exists`" + ` — the resource exists in the cluster.
+* ` + "`ready`" + ` — the resource exists and is ready. Deployments, StatefulSets,
+  DaemonSets, Jobs, and CustomResourceDefinitions are checked the same way as
+  ` + "`ks apply --wait`" + `; other resources are ready once they exist.
+* ` + "`drifted`" + ` — the resource was last applied from a manifest which differs
+  from the current one, or was not applied by ksonnet. Run ` + "`ks diff`" + ` to see
+  the changes.
+
+For environments with multiple destinations, each cluster is listed separately.
+
+### Related Commands
+
+* ` + "`ks apply` " + `— ` + applyShortDesc + `
+* ` + "`ks diff` " + `— ` + diffShortDesc + `
+
+### Syntax
+`
+	statusExample = `# Show the status of all resources in the 'dev' environment.
+ks status dev
+
+# Show the status of the resources of the 'redis' component as JSON.
+ks status dev -c redis -o json`
+)
+
+func newStatusCmd(a app.App) *cobra.Command {
+	statusClientConfig := client.NewDefaultClientConfig(a)
+
+	statusCmd := &cobra.Command{
+		Use:     "status [env-name] [-c <component-name>]",
+		Short:   statusShortDesc,
+		Long:    statusLong,
+		Example: statusExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var envName string
+			if len(args) == 1 {
+				envName = args[0]
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:                    a,
+				actions.OptionClientConfig:           statusClientConfig,
+				actions.OptionComponentNames:         viper.GetStringSlice(vStatusComponent),
+				actions.OptionEnvName:                envName,
+				actions.OptionMaxUnavailableClusters: viper.GetInt(vStatusMaxUnavailable),
+				actions.OptionOutput:                 viper.GetString(vStatusOutput),
+			}
+
+			if err := extractJsonnetFlags(a, "status"); err != nil {
+				return errors.Wrap(err, "handle jsonnet flags")
+			}
+
+			return runAction(actionStatus, m)
+		},
+	}
+
+	statusClientConfig.BindClientGoFlags(statusCmd)
+	bindJsonnetFlags(statusCmd, "status")
+
+	statusCmd.Flags().StringSliceP(flagComponent, shortComponent, nil, "Name of a specific component (multiple -c flags accepted, allows YAML, JSON, and Jsonnet)")
+	viper.BindPFlag(vStatusComponent, statusCmd.Flags().Lookup(flagComponent))
+
+	statusCmd.Flags().Int(flagMaxUnavailable, 0, "Number of clusters of a multi-cluster environment which may fail before the remaining clusters are skipped and the command fails")
+	viper.BindPFlag(vStatusMaxUnavailable, statusCmd.Flags().Lookup(flagMaxUnavailable))
+
+	addCmdOutput(statusCmd, vStatusOutput)
+
+	return statusCmd
+}
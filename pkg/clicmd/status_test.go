@@ -0,0 +1,60 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/actions"
+)
+
+func Test_statusCmd(t *testing.T) {
+	cases := []cmdTestCase{
+		{
+			name:   "with no options",
+			args:   []string{"status", "default"},
+			action: actionStatus,
+			expected: map[string]interface{}{
+				actions.OptionApp:                    nil,
+				actions.OptionEnvName:                "default",
+				actions.OptionComponentNames:         make([]string, 0),
+				actions.OptionClientConfig:           nil,
+				actions.OptionMaxUnavailableClusters: 0,
+				actions.OptionOutput:                 "",
+			},
+		},
+		{
+			name:   "with components and json output",
+			args:   []string{"status", "default", "-c", "redis", "-o", "json"},
+			action: actionStatus,
+			expected: map[string]interface{}{
+				actions.OptionApp:                    nil,
+				actions.OptionEnvName:                "default",
+				actions.OptionComponentNames:         []string{"redis"},
+				actions.OptionClientConfig:           nil,
+				actions.OptionMaxUnavailableClusters: 0,
+				actions.OptionOutput:                 "json",
+			},
+		},
+		{
+			name:  "invalid jsonnet flag",
+			args:  []string{"status", "default", "--ext-str", "foo"},
+			isErr: true,
+		},
+	}
+
+	runTestCmd(t, cases)
+}
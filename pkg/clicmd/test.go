@@ -0,0 +1,109 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vTestEnv    = "test-env"
+	vTestJUnit  = "test-junit"
+	vTestUpdate = "test-update"
+)
+
+var (
+	testLong = `
+The ` + "`test`" + ` command runs the ` + "`*_test.jsonnet`" + ` files in the app's ` + "`tests/`" + ` directory
+against the objects components render to. Test files are evaluated like
+components, so they can use the environment's parameters, and evaluate to a
+suite of tests:
+
+    {
+      environments: ["prod"],   // optional; defaults to every environment
+      components: ["guestbook-ui"],   // optional; defaults to every component
+      tests: [
+        { name: "deployment exists",
+          exists: { kind: "Deployment", name: "guestbook-ui" } },
+        { name: "replicas",
+          equals: { kind: "Deployment", name: "guestbook-ui",
+                    path: "spec.replicas", value: 3 } },
+        { name: "service",
+          snapshot: { kind: "Service", name: "guestbook-ui" } },
+      ],
+    }
+
+Snapshot tests compare objects with YAML files in ` + "`tests/__snapshots__/`" + `. Run
+with ` + "`--update`" + ` to write the snapshots after reviewing changes.
+
+Tests run in every environment unless one is given with ` + "`--env`" + `. The command
+fails if any test fails. ` + "`--junit`" + ` writes the results as JUnit XML for CI.
+
+### Related Commands
+
+* ` + "`ks show` " + `— ` + showShortDesc + `
+* ` + "`ks eval` " + `— Evaluate jsonnet with the app's import paths and parameters
+
+### Syntax
+`
+	testExample = `
+# Run the tests in every environment.
+ks test
+
+# Run the tests in the 'prod' environment, and write a JUnit report.
+ks test --env prod --junit report.xml
+
+# Update snapshots.
+ks test --update`
+)
+
+func newTestCmd(a app.App) *cobra.Command {
+	testCmd := &cobra.Command{
+		Use:     "test",
+		Short:   "Run the tests of the app's components",
+		Long:    testLong,
+		Example: testExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return errors.New("'test' takes no arguments")
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:     a,
+				actions.OptionEnvName: viper.GetString(vTestEnv),
+				actions.OptionUpdate:  viper.GetBool(vTestUpdate),
+				actions.OptionJUnit:   viper.GetString(vTestJUnit),
+			}
+
+			return runAction(actionTest, m)
+		},
+	}
+
+	testCmd.Flags().String(flagEnv, "", "Environment to run tests in")
+	viper.BindPFlag(vTestEnv, testCmd.Flags().Lookup(flagEnv))
+
+	testCmd.Flags().Bool("update", false, "Write snapshots instead of comparing objects with them")
+	viper.BindPFlag(vTestUpdate, testCmd.Flags().Lookup("update"))
+
+	testCmd.Flags().String("junit", "", "Write results as JUnit XML to a file")
+	viper.BindPFlag(vTestJUnit, testCmd.Flags().Lookup("junit"))
+
+	return testCmd
+}
@@ -0,0 +1,34 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/spf13/cobra"
+)
+
+func newToolCmd(a app.App) *cobra.Command {
+	toolCmd := &cobra.Command{
+		Use:   "tool",
+		Short: "Integrate the app with editors and other tools",
+		Long: `Write configuration for tools which work on the app outside of ksonnet, such
+as jsonnet language servers.`,
+	}
+
+	toolCmd.AddCommand(newToolJsonnetPathsCmd(a))
+
+	return toolCmd
+}
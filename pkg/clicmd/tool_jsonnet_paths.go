@@ -0,0 +1,102 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	vToolJsonnetPathsEnv    = "tool-jsonnet-paths-env"
+	vToolJsonnetPathsFormat = "tool-jsonnet-paths-format"
+	vToolJsonnetPathsWrite  = "tool-jsonnet-paths-write"
+)
+
+var (
+	toolJsonnetPathsLong = `
+The ` + "`jsonnet-paths`" + ` command writes the library paths components of an
+environment are evaluated with: the environment directories, ` + "`vendor/`" + `,
+` + "`lib/`" + `, the environment's ksonnet-lib, the environment's versioned
+packages, and the components of its targets. With these paths, jsonnet language
+servers resolve imports the way ksonnet does.
+
+Versioned packages are copied to ` + "`.ksonnet/packages/<env>`" + ` without their
+versions, as they are during evaluation. The copies are updated when packages
+are installed or removed.
+
+Formats:
+
+* ` + "`text`" + ` — one path per line. Later paths take precedence, as with ` + "`-J`" + `
+* ` + "`json`" + ` — a JSON array in the same order
+* ` + "`env`" + ` — a ` + "`JSONNET_PATH`" + ` assignment
+* ` + "`vscode`" + ` — VS Code settings for the jsonnet extension and jsonnet-language-server
+
+With ` + "`--write`" + `, the paths are saved to ` + "`.vscode/settings.json`" + ` in the app.
+Other settings in the file are kept.
+
+### Related Commands
+
+* ` + "`ks eval` " + `— Evaluate jsonnet with the app's import paths and parameters
+* ` + "`ks pkg install` " + `— ` + pkgShortDesc["install"] + `
+
+### Syntax
+`
+	toolJsonnetPathsExample = `
+# Configure VS Code for the current environment.
+ks tool jsonnet-paths --write
+
+# Run jsonnet-language-server with the paths of the 'prod' environment.
+env $(ks tool jsonnet-paths --env prod -o env) jsonnet-language-server`
+)
+
+func newToolJsonnetPathsCmd(a app.App) *cobra.Command {
+	toolJsonnetPathsCmd := &cobra.Command{
+		Use:     "jsonnet-paths",
+		Short:   "Write the app's jsonnet library paths for language servers",
+		Long:    toolJsonnetPathsLong,
+		Example: toolJsonnetPathsExample,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return errors.New("'tool jsonnet-paths' does not take any arguments")
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:           a,
+				actions.OptionEnvName:       viper.GetString(vToolJsonnetPathsEnv),
+				actions.OptionFormat:        viper.GetString(vToolJsonnetPathsFormat),
+				actions.OptionWrite:         viper.GetBool(vToolJsonnetPathsWrite),
+				actions.OptionTLSSkipVerify: viper.GetBool(flagTLSSkipVerify),
+			}
+
+			return runAction(actionToolJsonnetPaths, m)
+		},
+	}
+
+	toolJsonnetPathsCmd.Flags().String(flagEnv, "", "Environment to write paths for")
+	viper.BindPFlag(vToolJsonnetPathsEnv, toolJsonnetPathsCmd.Flags().Lookup(flagEnv))
+
+	toolJsonnetPathsCmd.Flags().StringP(flagFormat, shortFormat, "text", "Output format. Supported values are: text, json, env, vscode")
+	viper.BindPFlag(vToolJsonnetPathsFormat, toolJsonnetPathsCmd.Flags().Lookup(flagFormat))
+
+	toolJsonnetPathsCmd.Flags().Bool("write", false, "Save the paths to the app's VS Code settings")
+	viper.BindPFlag(vToolJsonnetPathsWrite, toolJsonnetPathsCmd.Flags().Lookup("write"))
+
+	return toolJsonnetPathsCmd
+}
@@ -0,0 +1,70 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/actions"
+)
+
+func Test_toolJsonnetPathsCmd(t *testing.T) {
+	cases := []cmdTestCase{
+		{
+			name:   "in general",
+			args:   []string{"tool", "jsonnet-paths"},
+			action: actionToolJsonnetPaths,
+			expected: map[string]interface{}{
+				actions.OptionApp:           nil,
+				actions.OptionEnvName:       "",
+				actions.OptionFormat:        "text",
+				actions.OptionWrite:         false,
+				actions.OptionTLSSkipVerify: false,
+			},
+		},
+		{
+			name:   "with env and format",
+			args:   []string{"tool", "jsonnet-paths", "--env", "prod", "-o", "env"},
+			action: actionToolJsonnetPaths,
+			expected: map[string]interface{}{
+				actions.OptionApp:           nil,
+				actions.OptionEnvName:       "prod",
+				actions.OptionFormat:        "env",
+				actions.OptionWrite:         false,
+				actions.OptionTLSSkipVerify: false,
+			},
+		},
+		{
+			name:   "write",
+			args:   []string{"tool", "jsonnet-paths", "--write"},
+			action: actionToolJsonnetPaths,
+			expected: map[string]interface{}{
+				actions.OptionApp:           nil,
+				actions.OptionEnvName:       "",
+				actions.OptionFormat:        "text",
+				actions.OptionWrite:         true,
+				actions.OptionTLSSkipVerify: false,
+			},
+		},
+		{
+			name:  "invalid arguments",
+			args:  []string{"tool", "jsonnet-paths", "invalid"},
+			isErr: true,
+		},
+	}
+
+	runTestCmd(t, cases)
+}
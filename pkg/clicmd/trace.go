@@ -0,0 +1,119 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/util/trace"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	// envOTLPEndpoint is the environment variable the collector traces are
+	// exported to is read from if --trace-endpoint is not set.
+	envOTLPEndpoint = "OTEL_EXPORTER_OTLP_ENDPOINT"
+
+	traceExportTimeout = 10 * time.Second
+)
+
+// commandTrace is the trace of the running command.
+type commandTrace struct {
+	fs            afero.Fs
+	tracer        *trace.Tracer
+	span          *trace.Span
+	traceEndpoint string
+	traceFile     string
+	traceFormat   string
+	metricsFile   string
+}
+
+var currentTrace *commandTrace
+
+// startTrace enables tracing if a trace export, or a trace or metrics file,
+// was requested, and starts the span of the command.
+func startTrace(fs afero.Fs, cmd *cobra.Command) error {
+	currentTrace = nil
+	trace.Disable()
+
+	ct := &commandTrace{
+		fs:            fs,
+		traceEndpoint: viper.GetString(flagTraceEndpoint),
+		traceFile:     viper.GetString(flagTraceFile),
+		traceFormat:   viper.GetString(flagTraceFormat),
+		metricsFile:   viper.GetString(flagMetricsFile),
+	}
+
+	if ct.traceEndpoint == "" {
+		ct.traceEndpoint = os.Getenv(envOTLPEndpoint)
+	}
+
+	if ct.traceEndpoint == "" && ct.traceFile == "" && ct.metricsFile == "" {
+		return nil
+	}
+
+	switch ct.traceFormat {
+	case "", trace.FormatChrome, trace.FormatJaeger:
+	default:
+		return errors.Errorf("invalid trace format %q; valid formats are %s and %s",
+			ct.traceFormat, trace.FormatChrome, trace.FormatJaeger)
+	}
+
+	ct.tracer = trace.Enable()
+	ct.span = ct.tracer.Start(cmd.CommandPath())
+	currentTrace = ct
+
+	return nil
+}
+
+// finishTrace finishes the span of the command, recording err, writes the
+// trace and metrics files, and exports the trace.
+func finishTrace(err error) error {
+	ct := currentTrace
+	if ct == nil {
+		return nil
+	}
+	currentTrace = nil
+	trace.Disable()
+
+	ct.span.Finish(err)
+
+	if ct.traceFile != "" {
+		if err := ct.tracer.WriteFile(ct.fs, ct.traceFile, ct.traceFormat); err != nil {
+			return errors.Wrapf(err, "writing trace to %s", ct.traceFile)
+		}
+	}
+
+	if ct.metricsFile != "" {
+		if err := ct.tracer.WriteMetricsFile(ct.fs, ct.metricsFile); err != nil {
+			return errors.Wrapf(err, "writing metrics to %s", ct.metricsFile)
+		}
+	}
+
+	if ct.traceEndpoint != "" {
+		client := &http.Client{Timeout: traceExportTimeout}
+		if err := ct.tracer.Export(client, ct.traceEndpoint); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
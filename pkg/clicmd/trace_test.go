@@ -0,0 +1,116 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/ksonnet/ksonnet/pkg/util/trace"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_trace(t *testing.T) {
+	cases := []struct {
+		name     string
+		args     []string
+		files    []string
+		contents []string
+		isErr    bool
+	}{
+		{
+			name:     "chrome trace and metrics",
+			args:     []string{"env", "list", "--trace-file", "/trace.json", "--metrics-file", "/metrics.prom"},
+			files:    []string{"/trace.json", "/metrics.prom"},
+			contents: []string{`"traceEvents"`, `ks_operation_duration_seconds_count{operation="ks env list",status="ok"} 1`},
+		},
+		{
+			name:     "jaeger trace",
+			args:     []string{"env", "list", "--trace-file", "/trace.json", "--trace-format", "jaeger"},
+			files:    []string{"/trace.json"},
+			contents: []string{`"operationName": "ks env list"`},
+		},
+		{
+			name:  "invalid trace format",
+			args:  []string{"env", "list", "--trace-file", "/trace.json", "--trace-format", "zipkin"},
+			isErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer trace.Disable()
+
+			s := stubCmdOverride{}
+
+			withCmd(actionEnvList, s.override, func() {
+				fs := afero.NewMemMapFs()
+				test.StageFile(t, fs, "app.yaml", "/app/app.yaml")
+
+				root, err := NewRoot(fs, "/app", tc.args)
+				require.NoError(t, err)
+
+				err = root.Execute()
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+
+				var written string
+				for _, name := range tc.files {
+					b, err := afero.ReadFile(fs, name)
+					require.NoError(t, err)
+					written += string(b)
+				}
+
+				for _, content := range tc.contents {
+					assert.Contains(t, written, content)
+				}
+			})
+		})
+	}
+}
+
+func Test_trace_endpoint(t *testing.T) {
+	defer trace.Disable()
+
+	var exported string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		exported = string(b)
+	}))
+	defer server.Close()
+
+	s := stubCmdOverride{}
+
+	withCmd(actionEnvList, s.override, func() {
+		fs := afero.NewMemMapFs()
+		test.StageFile(t, fs, "app.yaml", "/app/app.yaml")
+
+		root, err := NewRoot(fs, "/app", []string{"env", "list", "--trace-endpoint", server.URL})
+		require.NoError(t, err)
+
+		require.NoError(t, root.Execute())
+		assert.Contains(t, exported, `"name":"ks env list"`)
+	})
+}
@@ -0,0 +1,85 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package clicmd
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	uiShortDesc = "Browse and edit an app interactively"
+)
+
+var (
+	uiLong = `
+The ` + "`ui`" + ` command starts an interactive shell for day-two operations. It
+lists the app's environments and components, and the parameters of the
+selected environment. Parameters are edited with the same rules as
+` + "`ks param set`" + `, and the selected environment can be compared with its cluster,
+or checked for the live status of its resources.
+
+Type ` + "`help`" + ` in the shell for its commands, and ` + "`quit`" + ` to exit. The
+environment defaults to the current environment.
+
+### Related Commands
+
+* ` + "`ks param set` " + `— ` + paramShortDesc["set"] + `
+* ` + "`ks diff` " + `— ` + diffShortDesc + `
+* ` + "`ks status` " + `— ` + statusShortDesc + `
+
+### Syntax
+`
+	uiExample = `# Browse the app, starting in the 'dev' environment.
+ks ui dev
+
+# In the shell, set the replicas of the 'guestbook' component in 'dev', and
+# compare the environment with its cluster.
+ks (dev)> set guestbook replicas 3
+ks (dev)> diff`
+)
+
+func newUICmd(a app.App) *cobra.Command {
+	uiClientConfig := client.NewDefaultClientConfig(a)
+
+	uiCmd := &cobra.Command{
+		Use:     "ui [env-name]",
+		Short:   uiShortDesc,
+		Long:    uiLong,
+		Example: uiExample,
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var envName string
+			if len(args) == 1 {
+				envName = args[0]
+			}
+
+			m := map[string]interface{}{
+				actions.OptionApp:          a,
+				actions.OptionClientConfig: uiClientConfig,
+				actions.OptionEnvName:      envName,
+			}
+
+			return runAction(actionUI, m)
+		},
+	}
+
+	uiClientConfig.BindClientGoFlags(uiCmd)
+
+	return uiCmd
+}
@@ -28,6 +28,9 @@ import (
 
 const (
 	vValidateComponent = "validate-component"
+	vValidateOffline   = "validate-offline"
+	vValidateOutput    = "validate-output"
+	vValidateStrict    = "validate-strict"
 	valShortDesc       = "Check generated component manifests against the server's API"
 )
 
@@ -38,6 +41,26 @@ server APIs Kubernetes specification. Note that this command actually communicat
 *with* the server for the specified ` + "`<env-name>`" + `, so it only works if your
 $KUBECONFIG specifies a valid kubeconfig file.
 
+With the ` + "`--offline`" + ` flag, manifests are validated against the OpenAPI schema
+for the environment's Kubernetes version without contacting the server. The
+schema is downloaded once and cached in the app's ` + "`lib/`" + ` directory, so
+offline validation works in CI without cluster access.
+
+With the ` + "`--strict`" + ` flag, fields which are not described by the schema are
+rejected as well as type errors. This catches typos such as ` + "`replica:`" + `, which
+some versions of the API server silently drop.
+
+Custom resources are validated against the schemas of their
+CustomResourceDefinitions. The definitions are fetched from the server, read from
+YAML or JSON files in the app's ` + "`schemas/`" + ` directory, or taken from the
+manifests being validated. Custom resources without a schema are skipped.
+
+Manifests are also checked against the Rego policies listed under the
+environment's ` + "`policies`" + ` in ` + "`app.yaml`" + `. Policies follow the conftest
+convention of ` + "`deny`" + ` and ` + "`warn`" + ` rules in ` + "`package main`" + `, and are
+evaluated with the Open Policy Agent binary found in $KS_OPA or the path.
+Violations of ` + "`deny`" + ` rules fail validation unless the policy's level is ` + "`warn`" + `.
+
 When NO component is specified (no ` + "`-c`" + ` flag), this command checks all of
 the files in the ` + "`components/`" + ` directory. This is the same as what would
 get deployed to your cluster with ` + "`ks apply <env-name>`" + `.
@@ -62,6 +85,16 @@ ksonnet validate dev
 # by the 'prod' environment
 # NOTE: Make sure your current $KUBECONFIG matches the 'prod' cluster info
 ksonnet validate prod -c redis
+
+# Validate all resources in the 'dev' environment against the cached OpenAPI
+# schema for its Kubernetes version, without contacting the server.
+ksonnet validate dev --offline
+
+# Validate the 'dev' environment, rejecting fields which are not in the schema.
+ksonnet validate dev --strict
+
+# Validate the 'prod' environment and print policy violations as JSON.
+ksonnet validate prod -o json
 `
 )
 
@@ -85,6 +118,9 @@ func newValidateCmd(a app.App) *cobra.Command {
 				actions.OptionModule:         "",
 				actions.OptionComponentNames: viper.GetStringSlice(vValidateComponent),
 				actions.OptionClientConfig:   validateClientConfig,
+				actions.OptionOffline:        viper.GetBool(vValidateOffline),
+				actions.OptionOutput:         viper.GetString(vValidateOutput),
+				actions.OptionStrict:         viper.GetBool(vValidateStrict),
 			}
 
 			if err := extractJsonnetFlags(a, "validate"); err != nil {
@@ -101,5 +137,13 @@ func newValidateCmd(a app.App) *cobra.Command {
 
 	viper.BindPFlag(vValidateComponent, validateCmd.Flag(flagComponent))
 
+	validateCmd.Flags().Bool(flagOffline, false, "Validate against the cached OpenAPI schema without contacting the server")
+	viper.BindPFlag(vValidateOffline, validateCmd.Flags().Lookup(flagOffline))
+
+	validateCmd.Flags().Bool(flagStrict, false, "Reject fields which are not described by the schema")
+	viper.BindPFlag(vValidateStrict, validateCmd.Flags().Lookup(flagStrict))
+
+	addCmdOutput(validateCmd, vValidateOutput)
+
 	return validateCmd
 }
@@ -33,6 +33,24 @@ func Test_validateCmd(t *testing.T) {
 				actions.OptionModule:         "",
 				actions.OptionComponentNames: make([]string, 0),
 				actions.OptionClientConfig:   nil,
+				actions.OptionOffline:        false,
+				actions.OptionOutput:         "",
+				actions.OptionStrict:         false,
+			},
+		},
+		{
+			name:   "strict",
+			args:   []string{"validate", "env-name", "--strict"},
+			action: actionValidate,
+			expected: map[string]interface{}{
+				actions.OptionApp:            nil,
+				actions.OptionEnvName:        "env-name",
+				actions.OptionModule:         "",
+				actions.OptionComponentNames: make([]string, 0),
+				actions.OptionClientConfig:   nil,
+				actions.OptionOffline:        false,
+				actions.OptionOutput:         "",
+				actions.OptionStrict:         true,
 			},
 		},
 	}
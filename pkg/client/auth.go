@@ -0,0 +1,80 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package client
+
+import (
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/rest"
+)
+
+var (
+	// execAPIVersions are the supported ExecCredential versions. client-go
+	// runs v1alpha1 plugins, and ksonnet runs v1beta1 plugins.
+	execAPIVersions = map[string]bool{
+		execAPIVersionV1alpha1: true,
+		execAPIVersionV1beta1:  true,
+	}
+
+	// execPluginHints describe how to install well known exec credential plugins.
+	execPluginHints = map[string]string{
+		"aws":                    "Install the AWS CLI: https://aws.amazon.com/cli/",
+		"aws-iam-authenticator":  "Install it from https://github.com/kubernetes-sigs/aws-iam-authenticator",
+		"gke-gcloud-auth-plugin": "Install it with `gcloud components install gke-gcloud-auth-plugin`",
+		"kubelogin":              "Install it from https://github.com/Azure/kubelogin",
+	}
+
+	lookPath = exec.LookPath
+)
+
+// validateAuth returns a descriptive error if the credential plugin of a
+// client config can not be used. Without this, a missing plugin only surfaces
+// as an opaque transport error on the first request.
+func validateAuth(conf *rest.Config) error {
+	if conf == nil || conf.ExecProvider == nil {
+		return nil
+	}
+
+	command := conf.ExecProvider.Command
+	if command == "" {
+		return errors.New("kubeconfig exec credential plugin does not specify a command")
+	}
+
+	if !execAPIVersions[conf.ExecProvider.APIVersion] {
+		var versions []string
+		for v := range execAPIVersions {
+			versions = append(versions, v)
+		}
+		sort.Strings(versions)
+
+		return errors.Errorf("kubeconfig exec credential plugin %q uses apiVersion %q; supported versions are: %s",
+			command, conf.ExecProvider.APIVersion, strings.Join(versions, ", "))
+	}
+
+	if _, err := lookPath(command); err != nil {
+		msg := "kubeconfig uses the exec credential plugin %q, which was not found"
+		if hint, ok := execPluginHints[filepath.Base(command)]; ok {
+			return errors.Errorf(msg+". %s", command, hint)
+		}
+		return errors.Errorf(msg+": %v", command, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package client
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	restclient "k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func Test_validateAuth(t *testing.T) {
+	cases := []struct {
+		name     string
+		provider *clientcmdapi.ExecConfig
+		found    bool
+		errMsg   string
+	}{
+		{
+			name: "without exec plugin",
+		},
+		{
+			name: "installed plugin",
+			provider: &clientcmdapi.ExecConfig{
+				Command:    "aws-iam-authenticator",
+				APIVersion: "client.authentication.k8s.io/v1alpha1",
+			},
+			found: true,
+		},
+		{
+			name: "missing well known plugin",
+			provider: &clientcmdapi.ExecConfig{
+				Command:    "/usr/local/bin/gke-gcloud-auth-plugin",
+				APIVersion: "client.authentication.k8s.io/v1alpha1",
+			},
+			errMsg: `kubeconfig uses the exec credential plugin "/usr/local/bin/gke-gcloud-auth-plugin", which was not found. Install it with ` + "`gcloud components install gke-gcloud-auth-plugin`",
+		},
+		{
+			name: "missing plugin",
+			provider: &clientcmdapi.ExecConfig{
+				Command:    "custom-auth",
+				APIVersion: "client.authentication.k8s.io/v1alpha1",
+			},
+			errMsg: `kubeconfig uses the exec credential plugin "custom-auth", which was not found: exec: "custom-auth": executable file not found in $PATH`,
+		},
+		{
+			name: "unsupported api version",
+			provider: &clientcmdapi.ExecConfig{
+				Command:    "aws-iam-authenticator",
+				APIVersion: "client.authentication.k8s.io/v1",
+			},
+			found:  true,
+			errMsg: `kubeconfig exec credential plugin "aws-iam-authenticator" uses apiVersion "client.authentication.k8s.io/v1"; supported versions are: client.authentication.k8s.io/v1alpha1, client.authentication.k8s.io/v1beta1`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func(fn func(string) (string, error)) { lookPath = fn }(lookPath)
+			lookPath = func(file string) (string, error) {
+				if tc.found {
+					return file, nil
+				}
+				return "", &exec.Error{Name: file, Err: exec.ErrNotFound}
+			}
+
+			err := validateAuth(&restclient.Config{ExecProvider: tc.provider})
+			if tc.errMsg != "" {
+				require.Error(t, err)
+				assert.Equal(t, tc.errMsg, err.Error())
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
@@ -29,7 +29,9 @@ import (
 	"github.com/spf13/cobra"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
 
 const (
@@ -48,6 +50,9 @@ type Config struct {
 	Config clientcmd.ClientConfig
 
 	discoveryClient func() (discovery.DiscoveryInterface, error)
+
+	// destination is used instead of the environment's destination if set.
+	destination *app.EnvironmentDestinationSpec
 }
 
 func defaultDiscoveryClient(config clientcmd.ClientConfig) func() (discovery.DiscoveryInterface, error) {
@@ -64,7 +69,7 @@ func defaultDiscoveryClient(config clientcmd.ClientConfig) func() (discovery.Dis
 
 // NewClientConfig initializes a new client.Config with the provided loading rules and overrides.
 func NewClientConfig(a app.App, overrides clientcmd.ConfigOverrides, loadingRules clientcmd.ClientConfigLoadingRules) *Config {
-	config := clientcmd.NewInteractiveDeferredLoadingClientConfig(&loadingRules, &overrides, os.Stdin)
+	config := newExecClientConfig(clientcmd.NewInteractiveDeferredLoadingClientConfig(&loadingRules, &overrides, os.Stdin))
 	return &Config{
 		Overrides:       &overrides,
 		LoadingRules:    &loadingRules,
@@ -82,6 +87,106 @@ func NewDefaultClientConfig(a app.App) *Config {
 	return NewClientConfig(a, overrides, loadingRules)
 }
 
+// ForDestination returns a copy of the Config which targets destination
+// instead of the environment's destination. The cluster selected by the
+// destination takes precedence over cluster flags.
+func (c *Config) ForDestination(destination app.EnvironmentDestinationSpec) *Config {
+	var overrides clientcmd.ConfigOverrides
+	if c.Overrides != nil {
+		overrides = *c.Overrides
+	}
+	overrides.CurrentContext = destination.Context
+	overrides.Context.Cluster = ""
+	overrides.ClusterInfo.Server = ""
+
+	loadingRules := *clientcmd.NewDefaultClientConfigLoadingRules()
+	if c.LoadingRules != nil {
+		loadingRules = *c.LoadingRules
+	}
+
+	config := newExecClientConfig(clientcmd.NewInteractiveDeferredLoadingClientConfig(&loadingRules, &overrides, os.Stdin))
+	return &Config{
+		Overrides:       &overrides,
+		LoadingRules:    &loadingRules,
+		Config:          config,
+		discoveryClient: defaultDiscoveryClient(config),
+		destination:     &destination,
+	}
+}
+
+// EnvironmentDestination returns the destination the Config targets in env:
+// the destination given to ForDestination, or the environment's primary
+// destination.
+func (c *Config) EnvironmentDestination(env *app.EnvironmentConfig) app.EnvironmentDestinationSpec {
+	if c != nil && c.destination != nil {
+		return *c.destination
+	}
+
+	return env.PrimaryDestination()
+}
+
+// WithRateLimits returns a copy of the Config whose clients send at most qps
+// queries per second to the cluster, with bursts of up to burst queries. Zero
+// values keep client-go's defaults.
+func (c *Config) WithRateLimits(qps float32, burst int) *Config {
+	if qps == 0 && burst == 0 {
+		return c
+	}
+
+	config := &rateLimitedClientConfig{
+		config: c.Config,
+		qps:    qps,
+		burst:  burst,
+	}
+
+	return &Config{
+		Overrides:       c.Overrides,
+		LoadingRules:    c.LoadingRules,
+		Config:          config,
+		discoveryClient: defaultDiscoveryClient(config),
+		destination:     c.destination,
+	}
+}
+
+// rateLimitedClientConfig sets rate limits on the REST configs of a
+// ClientConfig.
+type rateLimitedClientConfig struct {
+	config clientcmd.ClientConfig
+
+	qps   float32
+	burst int
+}
+
+var _ clientcmd.ClientConfig = (*rateLimitedClientConfig)(nil)
+
+func (c *rateLimitedClientConfig) RawConfig() (clientcmdapi.Config, error) {
+	return c.config.RawConfig()
+}
+
+func (c *rateLimitedClientConfig) Namespace() (string, bool, error) {
+	return c.config.Namespace()
+}
+
+func (c *rateLimitedClientConfig) ConfigAccess() clientcmd.ConfigAccess {
+	return c.config.ConfigAccess()
+}
+
+func (c *rateLimitedClientConfig) ClientConfig() (*rest.Config, error) {
+	conf, err := c.config.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.qps != 0 {
+		conf.QPS = c.qps
+	}
+	if c.burst != 0 {
+		conf.Burst = c.burst
+	}
+
+	return conf, nil
+}
+
 // InitClient initializes a new ClientConfig given the specified environment
 // spec and returns the ClientPool, DiscoveryInterface, and namespace.
 func InitClient(a app.App, env string) (dynamic.ClientPool, discovery.DiscoveryInterface, string, error) {
@@ -129,6 +234,10 @@ func (c *Config) RestClient(a app.App, envName *string) (dynamic.ClientPool, dis
 		return nil, nil, "", err
 	}
 
+	if err = validateAuth(conf); err != nil {
+		return nil, nil, "", err
+	}
+
 	disco, err := discovery.NewDiscoveryClientForConfig(conf)
 	if err != nil {
 		return nil, nil, "", err
@@ -223,6 +332,20 @@ func (c *Config) overrideCluster(a app.App, envName string) error {
 	}
 
 	destination := env.Destination
+	if c.destination != nil {
+		destination = c.destination
+	}
+	if destination == nil {
+		return errors.Errorf("environment '%s' does not have a destination", envName)
+	}
+
+	if destination.Context != "" {
+		// The context selects the cluster, so it does not need to be located.
+		if c.Overrides.Context.Namespace == "" {
+			c.Overrides.Context.Namespace = destination.Namespace
+		}
+		return nil
+	}
 
 	server, err := str.NormalizeURL(destination.Server)
 	if err != nil {
@@ -80,6 +80,24 @@ func TestConfig_GetAPISpec(t *testing.T) {
 
 }
 
+func TestConfig_WithRateLimits(t *testing.T) {
+	c := &Config{Config: &clientConfig{}}
+
+	require.Equal(t, c, c.WithRateLimits(0, 0))
+
+	limited := c.WithRateLimits(20, 40)
+	rc, err := limited.Config.ClientConfig()
+	require.NoError(t, err)
+	require.Equal(t, float32(20), rc.QPS)
+	require.Equal(t, 40, rc.Burst)
+
+	limited = c.WithRateLimits(0, 40)
+	rc, err = limited.Config.ClientConfig()
+	require.NoError(t, err)
+	require.Equal(t, float32(0), rc.QPS)
+	require.Equal(t, 40, rc.Burst)
+}
+
 type clientConfig struct {
 }
 
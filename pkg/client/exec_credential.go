@@ -0,0 +1,246 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	execAPIVersionV1alpha1 = "client.authentication.k8s.io/v1alpha1"
+	execAPIVersionV1beta1  = "client.authentication.k8s.io/v1beta1"
+
+	// envExecInfo passes the ExecCredential request to exec credential plugins.
+	envExecInfo = "KUBERNETES_EXEC_INFO"
+)
+
+// execCredential is an ExecCredential request or response.
+type execCredential struct {
+	APIVersion string                `json:"apiVersion"`
+	Kind       string                `json:"kind"`
+	Spec       execCredentialSpec    `json:"spec"`
+	Status     *execCredentialStatus `json:"status,omitempty"`
+}
+
+type execCredentialSpec struct {
+	Interactive bool `json:"interactive"`
+}
+
+type execCredentialStatus struct {
+	ExpirationTimestamp   *time.Time `json:"expirationTimestamp,omitempty"`
+	Token                 string     `json:"token,omitempty"`
+	ClientCertificateData string     `json:"clientCertificateData,omitempty"`
+}
+
+// execClientConfig is a ClientConfig whose REST configs can use exec
+// credential plugins which client-go does not support.
+type execClientConfig struct {
+	config clientcmd.ClientConfig
+}
+
+var _ clientcmd.ClientConfig = (*execClientConfig)(nil)
+
+// newExecClientConfig wraps a ClientConfig so its REST configs can use exec
+// credential plugins which client-go does not support.
+func newExecClientConfig(config clientcmd.ClientConfig) clientcmd.ClientConfig {
+	return &execClientConfig{config: config}
+}
+
+func (c *execClientConfig) RawConfig() (clientcmdapi.Config, error) {
+	return c.config.RawConfig()
+}
+
+func (c *execClientConfig) Namespace() (string, bool, error) {
+	return c.config.Namespace()
+}
+
+func (c *execClientConfig) ConfigAccess() clientcmd.ConfigAccess {
+	return c.config.ConfigAccess()
+}
+
+func (c *execClientConfig) ClientConfig() (*rest.Config, error) {
+	conf, err := c.config.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = configureExecProvider(conf); err != nil {
+		return nil, err
+	}
+
+	return conf, nil
+}
+
+// configureExecProvider runs v1beta1 exec credential plugins in place of
+// client-go, which only supports v1alpha1, and sends their tokens with each
+// request.
+func configureExecProvider(conf *rest.Config) error {
+	if conf.ExecProvider == nil || conf.ExecProvider.APIVersion != execAPIVersionV1beta1 {
+		return nil
+	}
+
+	if err := validateAuth(conf); err != nil {
+		return err
+	}
+
+	source := &execTokenSource{provider: conf.ExecProvider}
+	conf.ExecProvider = nil
+
+	wrap := conf.WrapTransport
+	conf.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+		if wrap != nil {
+			rt = wrap(rt)
+		}
+		return &execRoundTripper{source: source, rt: rt}
+	}
+
+	return nil
+}
+
+// execTokenSource runs an exec credential plugin, and caches its token until
+// it expires or is rejected.
+type execTokenSource struct {
+	provider *clientcmdapi.ExecConfig
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+func (s *execTokenSource) Token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && (s.expiry.IsZero() || time.Now().Before(s.expiry)) {
+		return s.token, nil
+	}
+
+	status, err := s.run()
+	if err != nil {
+		return "", err
+	}
+
+	s.token = status.Token
+	s.expiry = time.Time{}
+	if status.ExpirationTimestamp != nil {
+		s.expiry = *status.ExpirationTimestamp
+	}
+
+	return s.token, nil
+}
+
+// invalidate drops a token the cluster rejected, so the plugin is run again.
+func (s *execTokenSource) invalidate(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == token {
+		s.token = ""
+	}
+}
+
+func (s *execTokenSource) run() (*execCredentialStatus, error) {
+	info, err := json.Marshal(execCredential{
+		APIVersion: execAPIVersionV1beta1,
+		Kind:       "ExecCredential",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(s.provider.Command, s.provider.Args...)
+	cmd.Env = os.Environ()
+	for _, env := range s.provider.Env {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", env.Name, env.Value))
+	}
+	cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", envExecInfo, info))
+	cmd.Stderr = os.Stderr
+
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+
+	if err = cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err, "running exec credential plugin %q", s.provider.Command)
+	}
+
+	var cred execCredential
+	if err = json.Unmarshal(stdout.Bytes(), &cred); err != nil {
+		return nil, errors.Wrapf(err, "decoding credentials of exec credential plugin %q", s.provider.Command)
+	}
+
+	switch {
+	case cred.APIVersion != execAPIVersionV1beta1 || cred.Kind != "ExecCredential":
+		return nil, errors.Errorf("exec credential plugin %q returned %s %s; expected %s ExecCredential",
+			s.provider.Command, cred.APIVersion, cred.Kind, execAPIVersionV1beta1)
+	case cred.Status == nil || (cred.Status.Token == "" && cred.Status.ClientCertificateData == ""):
+		return nil, errors.Errorf("exec credential plugin %q did not return credentials", s.provider.Command)
+	case cred.Status.Token == "":
+		return nil, errors.Errorf("exec credential plugin %q returned a client certificate; only tokens are supported with %s",
+			s.provider.Command, execAPIVersionV1beta1)
+	}
+
+	return cred.Status, nil
+}
+
+// execRoundTripper authenticates requests with the token of an exec
+// credential plugin.
+type execRoundTripper struct {
+	source *execTokenSource
+	rt     http.RoundTripper
+}
+
+func (rt *execRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Authorization") != "" {
+		return rt.rt.RoundTrip(req)
+	}
+
+	token, err := rt.source.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	// Requests must not be modified by round trippers.
+	authReq := new(http.Request)
+	*authReq = *req
+	authReq.Header = make(http.Header, len(req.Header)+1)
+	for k, v := range req.Header {
+		authReq.Header[k] = v
+	}
+	authReq.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := rt.rt.RoundTrip(authReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		rt.source.invalidate(token)
+	}
+
+	return resp, nil
+}
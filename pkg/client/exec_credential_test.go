@@ -0,0 +1,164 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package client
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	restclient "k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// writeExecPlugin writes an exec credential plugin which prints output.
+func writeExecPlugin(t *testing.T, dir, output string) string {
+	path := filepath.Join(dir, "auth-plugin")
+	script := "#!/bin/sh\ncat <<EOF\n" + output + "\nEOF\n"
+	require.NoError(t, ioutil.WriteFile(path, []byte(script), 0755))
+	return path
+}
+
+func Test_configureExecProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exec-credential")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// The plugin returns the token it is given, and the request it received.
+	plugin := writeExecPlugin(t, dir,
+		`{"apiVersion": "client.authentication.k8s.io/v1beta1", "kind": "ExecCredential", "status": {"token": "$TOKEN-$(echo $KUBERNETES_EXEC_INFO | grep -c v1beta1)"}}`)
+
+	var authorization []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = append(authorization, r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	conf := &restclient.Config{
+		Host: server.URL,
+		ExecProvider: &clientcmdapi.ExecConfig{
+			Command:    plugin,
+			APIVersion: execAPIVersionV1beta1,
+			Env:        []clientcmdapi.ExecEnvVar{{Name: "TOKEN", Value: "s3cret"}},
+		},
+	}
+	require.NoError(t, configureExecProvider(conf))
+	require.Nil(t, conf.ExecProvider)
+
+	rt, err := restclient.TransportFor(conf)
+	require.NoError(t, err)
+	c := &http.Client{Transport: rt}
+
+	for i := 0; i < 2; i++ {
+		resp, err := c.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	assert.Equal(t, []string{"Bearer s3cret-1", "Bearer s3cret-1"}, authorization)
+}
+
+func Test_configureExecProvider_v1alpha1(t *testing.T) {
+	provider := &clientcmdapi.ExecConfig{
+		Command:    "aws-iam-authenticator",
+		APIVersion: execAPIVersionV1alpha1,
+	}
+	conf := &restclient.Config{ExecProvider: provider}
+
+	// client-go runs v1alpha1 plugins.
+	require.NoError(t, configureExecProvider(conf))
+	assert.Equal(t, provider, conf.ExecProvider)
+	assert.Nil(t, conf.WrapTransport)
+}
+
+func Test_execTokenSource_Token(t *testing.T) {
+	cases := []struct {
+		name     string
+		output   string
+		expected string
+		errMsg   string
+	}{
+		{
+			name:     "token",
+			output:   `{"apiVersion": "client.authentication.k8s.io/v1beta1", "kind": "ExecCredential", "status": {"token": "s3cret"}}`,
+			expected: "s3cret",
+		},
+		{
+			name:   "wrong version",
+			output: `{"apiVersion": "client.authentication.k8s.io/v1alpha1", "kind": "ExecCredential", "status": {"token": "s3cret"}}`,
+			errMsg: "returned client.authentication.k8s.io/v1alpha1 ExecCredential; expected client.authentication.k8s.io/v1beta1 ExecCredential",
+		},
+		{
+			name:   "no credentials",
+			output: `{"apiVersion": "client.authentication.k8s.io/v1beta1", "kind": "ExecCredential"}`,
+			errMsg: "did not return credentials",
+		},
+		{
+			name:   "client certificate",
+			output: `{"apiVersion": "client.authentication.k8s.io/v1beta1", "kind": "ExecCredential", "status": {"clientCertificateData": "cert"}}`,
+			errMsg: "returned a client certificate",
+		},
+		{
+			name:   "invalid output",
+			output: `not json`,
+			errMsg: "decoding credentials",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "exec-credential")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			source := &execTokenSource{provider: &clientcmdapi.ExecConfig{
+				Command:    writeExecPlugin(t, dir, tc.output),
+				APIVersion: execAPIVersionV1beta1,
+			}}
+
+			token, err := source.Token()
+			if tc.errMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, token)
+		})
+	}
+}
+
+func Test_execTokenSource_invalidate(t *testing.T) {
+	source := &execTokenSource{
+		provider: &clientcmdapi.ExecConfig{Command: "/nonexistent", APIVersion: execAPIVersionV1beta1},
+		token:    "s3cret",
+	}
+
+	token, err := source.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", token)
+
+	// A rejected token is not reused, so the plugin is run again.
+	source.invalidate("s3cret")
+	_, err = source.Token()
+	require.Error(t, err)
+}
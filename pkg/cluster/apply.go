@@ -16,22 +16,33 @@
 package cluster
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"regexp"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/client"
 	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/ksonnet/ksonnet/pkg/pipeline"
+	"github.com/ksonnet/ksonnet/pkg/policy"
+	"github.com/ksonnet/ksonnet/pkg/util/event"
+	"github.com/ksonnet/ksonnet/pkg/util/trace"
 	"github.com/ksonnet/ksonnet/utils"
 	"github.com/pkg/errors"
-	log "github.com/sirupsen/logrus"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	cmdutil "k8s.io/kubernetes/pkg/kubectl/cmd/util"
 )
 
@@ -47,6 +58,8 @@ const (
 )
 
 var (
+	reInvalidLabelValueChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
 	errApplyConflict = errors.Errorf("apply conflict detected; retried %d times", applyConflictRetryCount)
 )
 
@@ -59,7 +72,36 @@ type ApplyConfig struct {
 	DryRun         bool
 	EnvName        string
 	GcTag          string
+	GcLabels       bool
+	GcAppLabel     string
+	GcEnvLabel     string
+	ServerDryRun   bool
 	SkipGc         bool
+	Wait           bool
+	WaitTimeout    time.Duration
+	// Parallelism, QPS, Burst, and BatchSize override the environment's
+	// apply settings when they are set.
+	Parallelism int
+	QPS         float32
+	Burst       int
+	BatchSize   int
+	// Objects are pre-rendered objects to apply. If they are set, the app's
+	// components are not evaluated.
+	Objects []*unstructured.Unstructured
+	// WithDependencies and WithDependents add the components ComponentNames
+	// depend on, and the components which depend on them, to the apply.
+	WithDependencies bool
+	WithDependents   bool
+	// ConfirmGc is called with the objects garbage collection is about to
+	// delete, before any are deleted. Nothing is deleted if it returns an
+	// error. It is not called for dry runs.
+	ConfirmGc func(objects []string) error
+	// Out is where policy violations and the output of command hooks are
+	// written. It defaults to stdout.
+	Out io.Writer
+	// DisableCommandHooks refuses to apply environments with local command
+	// hooks, for apps which are not trusted to run commands.
+	DisableCommandHooks bool
 }
 
 // ApplyOpts are options for configuring Apply.
@@ -70,31 +112,73 @@ type Apply struct {
 	ApplyConfig
 
 	// these make it easier to test Apply.
-	findObjectsFn         findObjectsFn
-	resourceClientFactory resourceClientFactoryFn
-	clientOpts            *Clients
-	objectInfo            ObjectInfo
-	ksonnetObjectFactory  func() ksonnetObject
-	upserterFactory       func() Upserter
-	conflictTimeout       time.Duration
+	findObjectsFn          findObjectsFn
+	resourceClientFactory  resourceClientFactoryFn
+	clientOpts             *Clients
+	objectInfo             ObjectInfo
+	ksonnetObjectFactory   func() ksonnetObject
+	upserterFactory        func() Upserter
+	conflictTimeout        time.Duration
+	waitInterval           time.Duration
+	serverDryRunnerFactory func(Clients) (ServerDryRunner, error)
+	policyCheckFn          func(app.App, string, []*unstructured.Unstructured) (*policy.Result, error)
+	webhookReplayFn        func(app.App, string, []*unstructured.Unstructured) (*policy.Result, error)
+	namespaceClientFactory namespaceClientFactoryFn
+	out                    io.Writer
+	result                 *ApplyResult
+
+	// gcLabelSet caches gcLabels, since objects are labeled concurrently.
+	gcLabelSet map[string]string
 }
 
 // RunApply runs apply against a cluster given a configuration.
 func RunApply(config ApplyConfig, opts ...ApplyOpts) error {
+	_, err := RunApplyWithResult(config, opts...)
+	return err
+}
+
+// RunApplyWithResult runs apply against a cluster given a configuration, and
+// returns what happened to each object. If the apply fails, the result lists
+// the objects which were handled before the failure.
+func RunApplyWithResult(config ApplyConfig, opts ...ApplyOpts) (*ApplyResult, error) {
+	result := &ApplyResult{
+		Environment: config.EnvName,
+		DryRun:      config.DryRun,
+		Objects:     []ObjectResult{},
+	}
+
+	span := trace.Start("cluster.apply", "env", config.EnvName)
+	err := span.Finish(runApply(config, result, opts...))
+	return result, err
+}
+
+func runApply(config ApplyConfig, result *ApplyResult, opts ...ApplyOpts) error {
 	if config.ClientConfig == nil {
 		return errors.New("ksonnet client config is required")
 	}
 
 	a := &Apply{
-		ApplyConfig:           config,
-		findObjectsFn:         findObjects,
-		resourceClientFactory: resourceClientFactory,
-		objectInfo:            &objectInfo{},
-		ksonnetObjectFactory: func() ksonnetObject {
-			factory := cmdutil.NewFactory(config.ClientConfig.Config)
-			return newDefaultKsonnetObject(factory)
-		},
-		conflictTimeout: 1 * time.Second,
+		ApplyConfig:            config,
+		findObjectsFn:          findObjects,
+		resourceClientFactory:  resourceClientFactory,
+		objectInfo:             &objectInfo{},
+		conflictTimeout:        1 * time.Second,
+		waitInterval:           defaultWaitInterval,
+		serverDryRunnerFactory: NewServerDryRunner,
+		policyCheckFn:          policy.Check,
+		webhookReplayFn:        policy.ReplayWebhooks,
+		namespaceClientFactory: newNamespaceClient,
+		out:                    os.Stdout,
+		result:                 result,
+	}
+
+	a.ksonnetObjectFactory = func() ksonnetObject {
+		factory := cmdutil.NewFactory(a.ClientConfig.Config)
+		return newDefaultKsonnetObject(factory)
+	}
+
+	if config.Out != nil {
+		a.out = config.Out
 	}
 
 	for _, opt := range opts {
@@ -102,6 +186,14 @@ func RunApply(config ApplyConfig, opts ...ApplyOpts) error {
 	}
 
 	if a.clientOpts == nil {
+		env, err := a.App.Environment(a.EnvName)
+		if err != nil {
+			return err
+		}
+
+		settings := a.settings(env)
+		a.ClientConfig = a.ClientConfig.WithRateLimits(settings.QPS, settings.Burst)
+
 		co, err := GenClients(a.App, a.ClientConfig, a.EnvName)
 		if err != nil {
 			return err
@@ -110,7 +202,7 @@ func RunApply(config ApplyConfig, opts ...ApplyOpts) error {
 		a.clientOpts = &co
 	}
 
-	if a.upserterFactory == nil {
+	if a.upserterFactory == nil && !a.ServerDryRun {
 		u, err := newDefaultUpserter(a.ApplyConfig, a.objectInfo, *a.clientOpts, a.resourceClientFactory)
 		if err != nil {
 			return errors.Wrap(err, "creating upserter")
@@ -123,54 +215,230 @@ func RunApply(config ApplyConfig, opts ...ApplyOpts) error {
 	return a.Apply()
 }
 
+// objects returns copies of the pre-rendered objects, if there are any, or
+// the objects evaluated from the app's components.
+func (a *Apply) objects() ([]*unstructured.Unstructured, error) {
+	if a.Objects == nil {
+		if len(a.ComponentNames) == 0 || !(a.WithDependencies || a.WithDependents) {
+			return a.findObjectsFn(a.App, a.EnvName, a.ComponentNames)
+		}
+
+		return a.dependencyObjects()
+	}
+
+	// objects are modified as they are applied, and the same pre-rendered
+	// objects are applied to each cluster of an environment.
+	objects := make([]*unstructured.Unstructured, 0, len(a.Objects))
+	for _, obj := range a.Objects {
+		objects = append(objects, obj.DeepCopy())
+	}
+
+	return objects, nil
+}
+
+// dependencyObjects returns the objects of the selected components along
+// with those of their dependencies or dependents. The selected components are
+// updated, so hooks of the added components are run as well.
+func (a *Apply) dependencyObjects() ([]*unstructured.Unstructured, error) {
+	objects, err := a.findObjectsFn(a.App, a.EnvName, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	deps := pipeline.ComponentDependencies(objects)
+	names := deps.Expand(a.ComponentNames, a.WithDependencies, a.WithDependents)
+	if len(names) > len(a.ComponentNames) {
+		log.Infof("applying components %s", strings.Join(names, ", "))
+	}
+	a.ComponentNames = names
+
+	var selected []*unstructured.Unstructured
+	for _, obj := range objects {
+		if stringListContains(names, obj.GetLabels()[metadata.LabelComponent]) {
+			selected = append(selected, obj)
+		}
+	}
+
+	return selected, nil
+}
+
 // Apply applies against a cluster.
 func (a *Apply) Apply() error {
-	apiObjects, err := a.findObjectsFn(a.App, a.EnvName, a.ComponentNames)
+	apiObjects, err := a.objects()
 	if err != nil {
 		return errors.Wrap(err, "find objects")
 	}
 
+	if err = a.checkPolicies(apiObjects); err != nil {
+		return err
+	}
+
 	sort.Sort(utils.DependencyOrder(apiObjects))
 
-	seenUids := sets.NewString()
+	if a.GcLabels {
+		if _, err = a.gcLabels(); err != nil {
+			return err
+		}
+	}
 
-	for _, obj := range apiObjects {
-		var uid string
-		uid, err = a.handleObject(obj)
-		if err != nil {
-			return errors.Wrap(err, "handle object")
+	if a.ServerDryRun {
+		return a.serverDryRun(apiObjects)
+	}
+
+	env, err := a.App.Environment(a.EnvName)
+	if err != nil {
+		return err
+	}
+
+	if env.Namespaces != nil {
+		if err = a.reconcileNamespaces(env.Namespaces, apiObjects); err != nil {
+			return err
 		}
+	}
 
-		// Some objects appear under multiple kinds
-		// (eg: Deployment is both extensions/v1beta1
-		// and apps/v1beta1).  UID is the only stable
-		// identifier that links these two views of
-		// the same object.
-		seenUids.Insert(uid)
+	apiObjects, hs, err := extractHooks(env, a.ComponentNames, apiObjects)
+	if err != nil {
+		return errors.Wrap(err, "find hooks")
 	}
 
-	if a.GcTag != "" && !a.SkipGc {
+	if a.DisableCommandHooks {
+		if err = hs.checkNoCommands(); err != nil {
+			return err
+		}
+	}
+
+	if err = a.runHooks(hs.forPhase(HookPreApply)); err != nil {
+		return err
+	}
+
+	settings := a.settings(env)
+	applied, seenUids, err := a.applyObjects(apiObjects, settings.Parallelism, settings.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	if (a.GcTag != "" || a.GcLabels) && !a.SkipGc {
 		if err = a.runGc(seenUids); err != nil {
 			return errors.Wrap(err, "run gc")
 		}
 	}
 
-	return nil
+	if a.Wait && !a.DryRun {
+		if err = a.wait(applied); err != nil {
+			return errors.Wrap(err, "wait for objects")
+		}
+	}
+
+	return a.runHooks(hs.forPhase(HookPostApply))
+}
+
+// reconcileNamespaces applies the environment's namespace policy before any
+// object, including hooks, is applied.
+func (a *Apply) reconcileNamespaces(policy *app.NamespacePolicyConfig, objects []*unstructured.Unstructured) error {
+	nc, err := a.namespaceClientFactory(*a.clientOpts)
+	if err != nil {
+		return err
+	}
+
+	defaultNamespace := a.clientOpts.namespace
+	if defaultNamespace == "" {
+		defaultNamespace = metav1.NamespaceDefault
+	}
+
+	r := &namespaceReconciler{
+		policy:           policy,
+		defaultNamespace: defaultNamespace,
+		client:           nc,
+		namespaced:       discoveryNamespaced(a.clientOpts.discovery),
+		dryRun:           a.DryRun,
+	}
+
+	return errors.Wrap(r.Reconcile(objects), "namespace policy")
 }
 
-func (a *Apply) handleObject(obj *unstructured.Unstructured) (string, error) {
+// settings returns the environment's apply settings, overridden by the
+// settings in the ApplyConfig.
+func (a *Apply) settings(env *app.EnvironmentConfig) app.ApplySettingsConfig {
+	var settings app.ApplySettingsConfig
+	if env.Apply != nil {
+		settings = *env.Apply
+	}
+
+	if a.Parallelism != 0 {
+		settings.Parallelism = a.Parallelism
+	}
+	if a.QPS != 0 {
+		settings.QPS = a.QPS
+	}
+	if a.Burst != 0 {
+		settings.Burst = a.Burst
+	}
+	if a.BatchSize != 0 {
+		settings.BatchSize = a.BatchSize
+	}
+
+	if settings.Parallelism < 1 {
+		settings.Parallelism = 1
+	}
+
+	return settings
+}
+
+func (a *Apply) handleObject(obj *unstructured.Unstructured, parent *trace.Span) (*unstructured.Unstructured, string, string, error) {
+	span := parent.StartChild("cluster.apply.object", "kind", obj.GetKind(), "name", obj.GetName())
+	mergedObject, uid, action, err := a.applyObject(obj)
+	if err == nil {
+		event.Publish(event.ResourceApplied{
+			Env:       a.EnvName,
+			Kind:      obj.GetKind(),
+			Namespace: obj.GetNamespace(),
+			Name:      obj.GetName(),
+			Action:    action,
+			DryRun:    a.DryRun,
+		})
+	}
+	return mergedObject, uid, action, span.Finish(err)
+}
+
+func (a *Apply) applyObject(obj *unstructured.Unstructured) (*unstructured.Unstructured, string, string, error) {
+	if err := a.setupGCLabels(obj); err != nil {
+		return nil, "", "", errors.Wrap(err, "labeling object for garbage collection")
+	}
+
 	if err := a.preprocessObject(obj); err != nil {
-		return "", errors.Wrap(err, "preprocessing object before apply")
+		return nil, "", "", errors.Wrap(err, "preprocessing object before apply")
 	}
 
 	mergedObject, err := a.patchFromCluster(obj)
 	if err != nil {
-		return "", errors.Wrap(err, "patching object from cluster")
+		return nil, "", "", errors.Wrap(err, "patching object from cluster")
 	}
 
 	a.setupGC(mergedObject)
 
-	return a.upsert(mergedObject)
+	uid, action, err := a.upsert(mergedObject)
+	return mergedObject, uid, action, err
+}
+
+// wait waits for applied objects to become ready and logs a summary.
+func (a *Apply) wait(objects []*unstructured.Unstructured) error {
+	w := newWaiter(*a.clientOpts, a.resourceClientFactory, a.WaitTimeout)
+	w.interval = a.waitInterval
+
+	log.Infof("waiting up to %s for %d object(s) to become ready", w.timeout, len(objects))
+	span := trace.Start("cluster.wait", "objects", strconv.Itoa(len(objects)))
+	summary, err := w.Wait(objects)
+	span.Finish(err)
+
+	for _, r := range summary {
+		if r.Ready {
+			log.Infof("%s is ready (%s)", r, r.Elapsed.Round(time.Second))
+			continue
+		}
+		log.Warnf("%s is not ready", r)
+	}
+
+	return err
 }
 
 // preprocessObject preprocesses an object for it is applied to the cluster.
@@ -189,20 +457,19 @@ func (a *Apply) patchFromCluster(obj *unstructured.Unstructured) (*unstructured.
 	return a.ksonnetObjectFactory().MergeFromCluster(*a.clientOpts, obj)
 }
 
-func (a *Apply) upsert(obj *unstructured.Unstructured) (string, error) {
+func (a *Apply) upsert(obj *unstructured.Unstructured) (string, string, error) {
 	if a.DryRun {
-		log.Info("upserting object", a.dryRunText())
-		return "12345", nil
+		return "12345", updateAction(obj, obj, true), nil
 	}
 
 	u := a.upserterFactory()
 
 	for i := applyConflictRetryCount; i > 0; i-- {
-		uid, err := u.Upsert(obj)
+		uid, action, err := u.Upsert(obj)
 		if err != nil {
 			cause := errors.Cause(err)
 			if !kerrors.IsConflict(cause) {
-				return "", err
+				return "", "", err
 			}
 			// In order for the next try to work, update the resource version on the object
 			updatedObj, err := a.getUpdatedObject(obj)
@@ -214,10 +481,10 @@ func (a *Apply) upsert(obj *unstructured.Unstructured) (string, error) {
 			continue
 		}
 
-		return uid, nil
+		return uid, action, nil
 	}
 
-	return "", errApplyConflict
+	return "", "", errApplyConflict
 }
 
 func (a *Apply) getUpdatedObject(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
@@ -235,7 +502,76 @@ func (a *Apply) setupGC(obj *unstructured.Unstructured) {
 	}
 }
 
+// gcLabels are the labels which identify the objects in an environment. The
+// application is identified by the name in app.yaml, since its directory name
+// is not unique, e.g. apps checked out to temporary directories.
+func (a *Apply) gcLabels() (map[string]string, error) {
+	if a.gcLabelSet != nil {
+		return a.gcLabelSet, nil
+	}
+
+	name, err := a.App.Name()
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		return nil, errors.New("app.yaml does not set the app's name, which is required to garbage collect by label")
+	}
+
+	appLabel, envLabel := a.GcAppLabel, a.GcEnvLabel
+	if appLabel == "" {
+		appLabel = metadata.LabelApplication
+	}
+	if envLabel == "" {
+		envLabel = metadata.LabelEnvironment
+	}
+
+	a.gcLabelSet = map[string]string{
+		appLabel: labelValue(name),
+		envLabel: labelValue(a.EnvName),
+	}
+
+	return a.gcLabelSet, nil
+}
+
+// setupGCLabels labels an object with its application and environment, and
+// records it in the kubectl last-applied annotation, so the environment can be
+// pruned with `kubectl apply --prune -l <labels>` as well as by ksonnet.
+func (a *Apply) setupGCLabels(obj *unstructured.Unstructured) error {
+	if !a.GcLabels {
+		return nil
+	}
+
+	gcLabels, err := a.gcLabels()
+	if err != nil {
+		return err
+	}
+
+	for k, v := range gcLabels {
+		SetMetaDataLabel(obj, k, v)
+	}
+
+	lastApplied := obj.DeepCopy()
+	annotations := lastApplied.GetAnnotations()
+	delete(annotations, metadata.AnnotationLastApplied)
+	delete(annotations, metadata.AnnotationManaged)
+	lastApplied.SetAnnotations(annotations)
+
+	b, err := json.Marshal(lastApplied.Object)
+	if err != nil {
+		return err
+	}
+
+	SetMetaDataAnnotation(obj, metadata.AnnotationLastApplied, string(b))
+	return nil
+}
+
 func (a *Apply) runGc(seenUids sets.String) error {
+	span := trace.Start("cluster.gc")
+	return span.Finish(a.gc(seenUids))
+}
+
+func (a *Apply) gc(seenUids sets.String) error {
 	co := a.clientOpts
 
 	version, err := utils.FetchVersion(co.discovery)
@@ -243,7 +579,20 @@ func (a *Apply) runGc(seenUids sets.String) error {
 		return err
 	}
 
-	err = walkObjects(*co, metav1.ListOptions{}, func(o runtime.Object) error {
+	listOpts := metav1.ListOptions{}
+	if a.GcLabels {
+		var gcLabels map[string]string
+		if gcLabels, err = a.gcLabels(); err != nil {
+			return err
+		}
+		listOpts.LabelSelector = labels.SelectorFromSet(gcLabels).String()
+	}
+
+	// Objects are collected before any is deleted, so the deletions can be
+	// confirmed at once.
+	var garbage []runtime.Object
+	var descs []string
+	err = walkObjects(*co, listOpts, func(o runtime.Object) error {
 		var metav1Object metav1.Object
 		metav1Object, err = meta.Accessor(o)
 		if err != nil {
@@ -254,13 +603,8 @@ func (a *Apply) runGc(seenUids sets.String) error {
 			utils.ResourceNameFor(co.discovery, o), utils.FqName(metav1Object), gvk.GroupVersion())
 		log.Debugf("Considering %v for gc", desc)
 		if eligibleForGc(metav1Object, a.GcTag) && !seenUids.Has(string(metav1Object.GetUID())) {
-			log.Info("Garbage collecting ", desc, a.dryRunText())
-			if !a.DryRun {
-				err = gcDelete(*co, a.resourceClientFactory, &version, o)
-				if err != nil {
-					return err
-				}
-			}
+			garbage = append(garbage, o)
+			descs = append(descs, desc)
 		}
 		return nil
 	})
@@ -268,9 +612,68 @@ func (a *Apply) runGc(seenUids sets.String) error {
 		return err
 	}
 
+	if len(garbage) > 0 && !a.DryRun && a.ConfirmGc != nil {
+		if err = a.ConfirmGc(descs); err != nil {
+			return err
+		}
+	}
+
+	for i, o := range garbage {
+		log.Info("Garbage collecting ", descs[i], a.dryRunText())
+		if !a.DryRun {
+			if err = gcDelete(*co, a.resourceClientFactory, &version, o); err != nil {
+				return err
+			}
+		}
+		if obj, ok := o.(*unstructured.Unstructured); ok {
+			a.result.add(obj, ApplyDeleted, nil)
+		}
+	}
+
 	return nil
 }
 
+// labelValue converts a name to a valid label value, e.g. an environment
+// named us-west/dev is labeled us-west.dev.
+func labelValue(name string) string {
+	value := reInvalidLabelValueChars.ReplaceAllString(name, ".")
+	if len(value) > validation.LabelValueMaxLength {
+		value = value[:validation.LabelValueMaxLength]
+	}
+
+	return strings.Trim(value, "-_.")
+}
+
+// checkPolicies checks objects against the environment's policies, and
+// replays them against the environment's webhooks. Nothing is applied if a
+// policy or webhook denies an object.
+func (a *Apply) checkPolicies(objects []*unstructured.Unstructured) error {
+	span := trace.Start("policy.check")
+	result, err := a.policyCheckFn(a.App, a.EnvName, objects)
+	span.Finish(err)
+	if err != nil {
+		return errors.Wrap(err, "check policies")
+	}
+
+	span = trace.Start("policy.webhooks")
+	replayed, err := a.webhookReplayFn(a.App, a.EnvName, objects)
+	span.Finish(err)
+	if err != nil {
+		return errors.Wrap(err, "replay webhooks")
+	}
+	result.Violations = append(result.Violations, replayed.Violations...)
+
+	if len(result.Violations) == 0 {
+		return nil
+	}
+
+	if err = result.Render(a.out, ""); err != nil {
+		return err
+	}
+
+	return result.Err()
+}
+
 func (a *Apply) dryRunText() string {
 	text := ""
 	if a.DryRun {
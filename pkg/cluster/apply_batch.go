@@ -0,0 +1,138 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/ksonnet/ksonnet/pkg/util/trace"
+	"github.com/ksonnet/ksonnet/utils"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// appliedObject is the result of applying an object.
+type appliedObject struct {
+	object *unstructured.Unstructured
+	uid    string
+	action string
+	err    error
+}
+
+// applyObjects applies objects, which are sorted in dependency order, and
+// returns the merged objects and their UIDs. The outcome of each object is
+// recorded in the apply result. Each dependency tier is applied
+// in batches of up to batchSize objects, and up to parallelism objects of a
+// batch are applied concurrently. A batch is finished before the next one is
+// started, so objects never race ahead of the objects they depend on.
+func (a *Apply) applyObjects(objects []*unstructured.Unstructured, parallelism, batchSize int) ([]*unstructured.Unstructured, sets.String, error) {
+	seenUids := sets.NewString()
+	applied := make([]*unstructured.Unstructured, 0, len(objects))
+
+	batches := applyBatches(objects, batchSize)
+	for i, batch := range batches {
+		if len(batches) > 1 {
+			log.Debugf("applying batch %d of %d (%d objects)", i+1, len(batches), len(batch))
+		}
+
+		span := trace.Start("cluster.apply.batch", "objects", strconv.Itoa(len(batch)))
+		results := a.applyBatch(batch, parallelism, span)
+
+		for i, r := range results {
+			a.result.add(batch[i], r.action, r.err)
+			if r.err != nil {
+				return nil, nil, span.Finish(errors.Wrap(r.err, "handle object"))
+			}
+			applied = append(applied, r.object)
+
+			// Some objects appear under multiple kinds
+			// (eg: Deployment is both extensions/v1beta1
+			// and apps/v1beta1).  UID is the only stable
+			// identifier that links these two views of
+			// the same object.
+			seenUids.Insert(r.uid)
+		}
+		span.Finish(nil)
+	}
+
+	return applied, seenUids, nil
+}
+
+// applyBatch applies a batch of objects with up to parallelism objects in
+// flight. Results are returned in the order of the objects. When objects are
+// applied one at a time, the batch stops at the first failure. The spans of
+// the objects are children of span, since they may be applied concurrently.
+func (a *Apply) applyBatch(objects []*unstructured.Unstructured, parallelism int, span *trace.Span) []appliedObject {
+	results := make([]appliedObject, len(objects))
+
+	if parallelism <= 1 {
+		for i, obj := range objects {
+			r := &results[i]
+			r.object, r.uid, r.action, r.err = a.handleObject(obj, span)
+			if r.err != nil {
+				return results[:i+1]
+			}
+		}
+		return results
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, parallelism)
+	for i, obj := range objects {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(r *appliedObject, obj *unstructured.Unstructured) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			r.object, r.uid, r.action, r.err = a.handleObject(obj, span)
+		}(&results[i], obj)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+// applyBatches splits objects, which are sorted in dependency order, into
+// batches which hold objects of a single dependency tier. A batchSize of zero
+// puts each tier in one batch.
+func applyBatches(objects []*unstructured.Unstructured, batchSize int) [][]*unstructured.Unstructured {
+	var batches [][]*unstructured.Unstructured
+	var batch []*unstructured.Unstructured
+	tier := 0
+
+	for _, obj := range objects {
+		objTier := utils.DependencyTier(obj)
+		full := batchSize > 0 && len(batch) >= batchSize
+		if len(batch) > 0 && (objTier != tier || full) {
+			batches = append(batches, batch)
+			batch = nil
+		}
+
+		tier = objTier
+		batch = append(batch, obj)
+	}
+
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+
+	return batches
+}
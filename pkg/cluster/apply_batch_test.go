@@ -0,0 +1,240 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/util/trace"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type passthroughKsonnetObject struct{}
+
+var _ ksonnetObject = (*passthroughKsonnetObject)(nil)
+
+func (ko *passthroughKsonnetObject) MergeFromCluster(co Clients, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	return obj, nil
+}
+
+// trackingUpserter records how many objects are upserted at the same time.
+type trackingUpserter struct {
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+	upserted    []string
+	failName    string
+}
+
+var _ Upserter = (*trackingUpserter)(nil)
+
+func (u *trackingUpserter) Upsert(obj *unstructured.Unstructured) (string, string, error) {
+	u.mu.Lock()
+	u.inFlight++
+	if u.inFlight > u.maxInFlight {
+		u.maxInFlight = u.inFlight
+	}
+	u.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.inFlight--
+	u.upserted = append(u.upserted, obj.GetName())
+
+	if obj.GetName() == u.failName {
+		return "", "", errors.New("upsert failed")
+	}
+
+	return "uid-" + obj.GetName(), ApplyConfigured, nil
+}
+
+func newTieredObject(kind, name string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       kind,
+		"metadata":   map[string]interface{}{"name": name},
+	}}
+}
+
+func tieredObjects() []*unstructured.Unstructured {
+	return []*unstructured.Unstructured{
+		newTieredObject("Namespace", "ns"),
+		newTieredObject("ConfigMap", "cm1"),
+		newTieredObject("ConfigMap", "cm2"),
+		newTieredObject("ConfigMap", "cm3"),
+		newTieredObject("Service", "svc"),
+		newTieredObject("Pod", "pod"),
+	}
+}
+
+func objectNames(objects []*unstructured.Unstructured) []string {
+	var names []string
+	for _, obj := range objects {
+		names = append(names, obj.GetName())
+	}
+	return names
+}
+
+func Test_applyBatches(t *testing.T) {
+	cases := []struct {
+		name      string
+		batchSize int
+		expected  [][]string
+	}{
+		{
+			name: "one batch per tier",
+			expected: [][]string{
+				{"ns"},
+				{"cm1", "cm2", "cm3", "svc"},
+				{"pod"},
+			},
+		},
+		{
+			name:      "batches within a tier",
+			batchSize: 3,
+			expected: [][]string{
+				{"ns"},
+				{"cm1", "cm2", "cm3"},
+				{"svc"},
+				{"pod"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got [][]string
+			for _, batch := range applyBatches(tieredObjects(), tc.batchSize) {
+				got = append(got, objectNames(batch))
+			}
+
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func newBatchApply(u *trackingUpserter) *Apply {
+	return &Apply{
+		clientOpts: &Clients{},
+		ksonnetObjectFactory: func() ksonnetObject {
+			return &passthroughKsonnetObject{}
+		},
+		upserterFactory: func() Upserter {
+			return u
+		},
+	}
+}
+
+func Test_Apply_applyObjects(t *testing.T) {
+	cases := []struct {
+		name        string
+		parallelism int
+		batchSize   int
+		maxInFlight int
+	}{
+		{
+			name:        "serially",
+			parallelism: 1,
+			maxInFlight: 1,
+		},
+		{
+			name:        "concurrently",
+			parallelism: 4,
+			maxInFlight: 4,
+		},
+		{
+			name:        "concurrently in batches",
+			parallelism: 4,
+			batchSize:   2,
+			maxInFlight: 2,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tracer := trace.Enable()
+			defer trace.Disable()
+
+			u := &trackingUpserter{}
+			a := newBatchApply(u)
+
+			objects := tieredObjects()
+			applied, seenUids, err := a.applyObjects(objects, tc.parallelism, tc.batchSize)
+			require.NoError(t, err)
+
+			// objects applied concurrently are children of their batch.
+			names := make(map[uint64]string)
+			for _, span := range tracer.Spans() {
+				names[span.ID] = span.Name
+			}
+			for _, span := range tracer.Spans() {
+				if span.Name == "cluster.apply.object" {
+					assert.Equal(t, "cluster.apply.batch", names[span.ParentID])
+				}
+			}
+
+			assert.Equal(t, objectNames(objects), objectNames(applied))
+			assert.Equal(t, len(objects), seenUids.Len())
+			assert.True(t, seenUids.Has("uid-cm2"))
+			assert.Equal(t, tc.maxInFlight, u.maxInFlight)
+
+			// tiers are applied in order, whatever the parallelism.
+			assert.Equal(t, "ns", u.upserted[0])
+			assert.Equal(t, "pod", u.upserted[len(u.upserted)-1])
+		})
+	}
+}
+
+func Test_Apply_applyObjects_error(t *testing.T) {
+	u := &trackingUpserter{failName: "cm2"}
+	a := newBatchApply(u)
+
+	_, _, err := a.applyObjects(tieredObjects(), 4, 0)
+	require.Error(t, err)
+
+	// objects in later tiers are not applied after a failure.
+	assert.NotContains(t, u.upserted, "pod")
+}
+
+func Test_Apply_settings(t *testing.T) {
+	env := &app.EnvironmentConfig{
+		Apply: &app.ApplySettingsConfig{
+			Parallelism: 8,
+			QPS:         20,
+			Burst:       40,
+		},
+	}
+
+	a := &Apply{ApplyConfig: ApplyConfig{Parallelism: 2, BatchSize: 50}}
+	expected := app.ApplySettingsConfig{
+		Parallelism: 2,
+		QPS:         20,
+		Burst:       40,
+		BatchSize:   50,
+	}
+	assert.Equal(t, expected, a.settings(env))
+
+	a = &Apply{}
+	assert.Equal(t, app.ApplySettingsConfig{Parallelism: 1}, a.settings(&app.EnvironmentConfig{}))
+}
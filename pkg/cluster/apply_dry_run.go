@@ -0,0 +1,240 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/util/table"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// DryRunAccepted means the server would persist an object as submitted.
+	DryRunAccepted = "accepted"
+	// DryRunMutated means the server would persist an object with changes made
+	// by defaulting or mutating admission.
+	DryRunMutated = "mutated"
+	// DryRunRejected means the server, e.g. validation or admission, refused an object.
+	DryRunRejected = "rejected"
+
+	// maxMutationsShown is the number of mutated fields listed per object.
+	maxMutationsShown = 5
+)
+
+// DryRunResult is the outcome of a server dry-run for a single object.
+type DryRunResult struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Status    string
+	// Mutations are the paths of fields which the server would add, change,
+	// or remove.
+	Mutations []string
+	// Reason is the reason the server rejected the object.
+	Reason string
+}
+
+// Details describes the mutations or rejection reason of a result.
+func (r DryRunResult) Details() string {
+	switch r.Status {
+	case DryRunRejected:
+		return r.Reason
+	case DryRunMutated:
+		shown := r.Mutations
+		more := ""
+		if len(shown) > maxMutationsShown {
+			more = fmt.Sprintf(", ... (%d more)", len(shown)-maxMutationsShown)
+			shown = shown[:maxMutationsShown]
+		}
+		return strings.Join(shown, ", ") + more
+	default:
+		return ""
+	}
+}
+
+// serverDryRun submits objects with dryRun=All and reports whether the server
+// accepts, mutates, or rejects each of them. The cluster is not changed.
+func (a *Apply) serverDryRun(objects []*unstructured.Unstructured) error {
+	dryRunner, err := a.serverDryRunnerFactory(*a.clientOpts)
+	if err != nil {
+		return err
+	}
+
+	var results []DryRunResult
+	rejected := 0
+	for _, obj := range objects {
+		if err = a.setupGCLabels(obj); err != nil {
+			return err
+		}
+		a.setupGC(obj)
+
+		result := DryRunResult{
+			Kind:      obj.GetKind(),
+			Namespace: obj.GetNamespace(),
+			Name:      obj.GetName(),
+		}
+
+		persisted, err := dryRunner.DryRun(obj)
+		switch {
+		case err != nil:
+			result.Status = DryRunRejected
+			result.Reason = rejectionReason(err)
+			rejected++
+		default:
+			StripServerFields(persisted)
+			result.Mutations = mutatedFields("", submittedFields(obj, persisted.GetNamespace()), persisted.Object)
+			result.Status = DryRunAccepted
+			if len(result.Mutations) > 0 {
+				result.Status = DryRunMutated
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	if err = renderDryRunResults(a.out, results); err != nil {
+		return err
+	}
+
+	if rejected > 0 {
+		return errors.Errorf("server dry-run rejected %d object(s)", rejected)
+	}
+
+	return nil
+}
+
+// submittedFields is the object as submitted, without the fields which would
+// be stripped from the server's response.
+func submittedFields(obj *unstructured.Unstructured, namespace string) map[string]interface{} {
+	submitted := obj.DeepCopy()
+	StripServerFields(submitted)
+	if submitted.GetNamespace() == "" && namespace != "" {
+		// The namespace is defaulted by ksonnet, not the server.
+		submitted.SetNamespace(namespace)
+	}
+
+	return submitted.Object
+}
+
+func rejectionReason(err error) string {
+	cause := errors.Cause(err)
+	if status, ok := cause.(kerrors.APIStatus); ok {
+		s := status.Status()
+		if s.Reason != "" {
+			return fmt.Sprintf("%s: %s", s.Reason, s.Message)
+		}
+		return s.Message
+	}
+
+	return cause.Error()
+}
+
+// mutatedFields returns the paths of the fields which differ between the
+// submitted and persisted versions of an object.
+func mutatedFields(path string, submitted, persisted interface{}) []string {
+	s, sOk := submitted.(map[string]interface{})
+	p, pOk := persisted.(map[string]interface{})
+	if sOk && pOk {
+		keys := make(map[string]bool)
+		for k := range s {
+			keys[k] = true
+		}
+		for k := range p {
+			keys[k] = true
+		}
+
+		var sorted []string
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+
+		var fields []string
+		for _, k := range sorted {
+			childPath := k
+			if path != "" {
+				childPath = path + "." + k
+			}
+			fields = append(fields, mutatedFields(childPath, s[k], p[k])...)
+		}
+		return fields
+	}
+
+	sl, sOk := submitted.([]interface{})
+	pl, pOk := persisted.([]interface{})
+	if sOk && pOk && len(sl) == len(pl) {
+		var fields []string
+		for i := range sl {
+			fields = append(fields, mutatedFields(fmt.Sprintf("%s[%d]", path, i), sl[i], pl[i])...)
+		}
+		return fields
+	}
+
+	if scalarEqual(submitted, persisted) {
+		return nil
+	}
+
+	return []string{path}
+}
+
+// scalarEqual compares values, treating numbers of different types as equal
+// if their values are equal.
+func scalarEqual(a, b interface{}) bool {
+	af, aOk := numberValue(a)
+	bf, bOk := numberValue(b)
+	if aOk && bOk {
+		return af == bf
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+func numberValue(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
+
+func renderDryRunResults(w io.Writer, results []DryRunResult) error {
+	t := table.New("serverDryRun", w)
+	t.SetHeader([]string{"KIND", "NAME", "RESULT", "DETAILS"})
+
+	for _, r := range results {
+		name := r.Name
+		if r.Namespace != "" {
+			name = r.Namespace + "/" + r.Name
+		}
+		t.Append([]string{r.Kind, name, r.Status, r.Details()})
+	}
+
+	return t.Render()
+}
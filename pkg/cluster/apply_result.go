@@ -0,0 +1,93 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// ApplyCreated means an object did not exist, and was created.
+	ApplyCreated = "created"
+	// ApplyConfigured means an existing object was updated.
+	ApplyConfigured = "configured"
+	// ApplyUnchanged means an existing object already matched its manifest.
+	ApplyUnchanged = "unchanged"
+	// ApplyDeleted means an object was garbage collected.
+	ApplyDeleted = "deleted"
+	// ApplyFailed means an object could not be applied.
+	ApplyFailed = "failed"
+)
+
+// ObjectResult is the outcome of applying a single object.
+type ObjectResult struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	Component  string `json:"component,omitempty"`
+	Action     string `json:"action"`
+	// Message is the reason an object failed, as reported by the server
+	// when it rejected the object.
+	Message string `json:"message,omitempty"`
+}
+
+// ApplyResult is the outcome of applying an environment to a cluster. Objects
+// are listed in the order they were applied, followed by the objects which
+// were garbage collected. If the apply fails, objects after the failure are
+// not listed.
+type ApplyResult struct {
+	Environment string         `json:"environment"`
+	Cluster     string         `json:"cluster,omitempty"`
+	DryRun      bool           `json:"dryRun,omitempty"`
+	Objects     []ObjectResult `json:"objects"`
+}
+
+// Failed returns the results of the objects which failed.
+func (r *ApplyResult) Failed() []ObjectResult {
+	var failed []ObjectResult
+	for _, o := range r.Objects {
+		if o.Action == ApplyFailed {
+			failed = append(failed, o)
+		}
+	}
+
+	return failed
+}
+
+// add records the outcome of applying obj. err is the reason obj failed.
+func (r *ApplyResult) add(obj *unstructured.Unstructured, action string, err error) {
+	if r == nil {
+		return
+	}
+
+	result := ObjectResult{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+		Component:  obj.GetLabels()[metadata.LabelComponent],
+		Action:     action,
+	}
+
+	if err != nil {
+		result.Action = ApplyFailed
+		result.Message = rejectionReason(err)
+	}
+
+	r.Objects = append(r.Objects, result)
+}
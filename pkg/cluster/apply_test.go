@@ -16,19 +16,25 @@
 package cluster
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/ksonnet/ksonnet/pkg/app"
 	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
 	"github.com/ksonnet/ksonnet/pkg/client"
 	"github.com/ksonnet/ksonnet/pkg/cluster/mocks"
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/ksonnet/ksonnet/pkg/policy"
 	"github.com/ksonnet/ksonnet/pkg/util/test"
 	"github.com/pkg/errors"
 	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -51,6 +57,7 @@ func (e *conflictError) Error() string {
 
 func Test_Apply(t *testing.T) {
 	test.WithApp(t, "/app", func(a *amocks.App, fs afero.Fs) {
+		a.On("Environment", mock.Anything).Return(&app.EnvironmentConfig{}, nil)
 		applyConfig := ApplyConfig{
 			App:          a,
 			ClientConfig: &client.Config{},
@@ -87,6 +94,7 @@ func Test_Apply(t *testing.T) {
 
 func Test_Apply_dry_run(t *testing.T) {
 	test.WithApp(t, "/app", func(a *amocks.App, fs afero.Fs) {
+		a.On("Environment", mock.Anything).Return(&app.EnvironmentConfig{}, nil)
 		applyConfig := ApplyConfig{
 			App:          a,
 			ClientConfig: &client.Config{},
@@ -122,8 +130,186 @@ func Test_Apply_dry_run(t *testing.T) {
 	})
 }
 
+func Test_RunApplyWithResult(t *testing.T) {
+	cases := []struct {
+		name     string
+		upserter *fakeUpserter
+		isErr    bool
+		expected []ObjectResult
+	}{
+		{
+			name:     "applied",
+			upserter: &fakeUpserter{upsertID: "12345", upsertAction: ApplyCreated},
+			expected: []ObjectResult{
+				{APIVersion: "apps/v1beta1", Kind: "Deployment", Name: "guiroot", Action: ApplyCreated},
+			},
+		},
+		{
+			name:     "failed",
+			upserter: &fakeUpserter{upsertErr: errors.New("rejected")},
+			isErr:    true,
+			expected: []ObjectResult{
+				{APIVersion: "apps/v1beta1", Kind: "Deployment", Name: "guiroot", Action: ApplyFailed, Message: "rejected"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			test.WithApp(t, "/app", func(a *amocks.App, fs afero.Fs) {
+				a.On("Environment", mock.Anything).Return(&app.EnvironmentConfig{}, nil)
+				applyConfig := ApplyConfig{
+					App:          a,
+					ClientConfig: &client.Config{},
+					EnvName:      "default",
+				}
+
+				setupApp := func(apply *Apply) {
+					obj := &unstructured.Unstructured{Object: genObject()}
+
+					apply.clientOpts = &Clients{}
+					apply.findObjectsFn = func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+						return []*unstructured.Unstructured{obj}, nil
+					}
+					apply.ksonnetObjectFactory = func() ksonnetObject {
+						return &fakeKsonnetObject{obj: obj}
+					}
+					apply.upserterFactory = func() Upserter {
+						return tc.upserter
+					}
+				}
+
+				result, err := RunApplyWithResult(applyConfig, setupApp)
+				if tc.isErr {
+					require.Error(t, err)
+				} else {
+					require.NoError(t, err)
+				}
+
+				require.Equal(t, "default", result.Environment)
+				require.Equal(t, tc.expected, result.Objects)
+			})
+		})
+	}
+}
+
+func Test_Apply_objects(t *testing.T) {
+	test.WithApp(t, "/app", func(a *amocks.App, fs afero.Fs) {
+		a.On("Environment", mock.Anything).Return(&app.EnvironmentConfig{}, nil)
+
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name": "config",
+			},
+		}}
+		applyConfig := ApplyConfig{
+			App:          a,
+			ClientConfig: &client.Config{},
+			Objects:      []*unstructured.Unstructured{obj},
+		}
+
+		var applied *unstructured.Unstructured
+		setupApp := func(apply *Apply) {
+			apply.clientOpts = &Clients{}
+
+			apply.findObjectsFn = func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+				return nil, errors.New("components should not be evaluated")
+			}
+
+			apply.ksonnetObjectFactory = func() ksonnetObject {
+				return &fakeKsonnetObject{
+					obj: obj,
+				}
+			}
+
+			apply.upserterFactory = func() Upserter {
+				return &fakeUpserter{
+					upsertID: "12345",
+				}
+			}
+
+			apply.policyCheckFn = func(_ app.App, _ string, objects []*unstructured.Unstructured) (*policy.Result, error) {
+				require.Len(t, objects, 1)
+				applied = objects[0]
+				return &policy.Result{}, nil
+			}
+		}
+
+		err := RunApply(applyConfig, setupApp)
+		require.NoError(t, err)
+
+		require.NotNil(t, applied)
+		assert.Equal(t, "config", applied.GetName())
+		assert.False(t, obj == applied, "expected a copy of the pre-rendered object")
+		assert.Empty(t, obj.GetAnnotations(), "pre-rendered object was modified")
+	})
+}
+
+func Test_Apply_objects_with_dependencies(t *testing.T) {
+	object := func(kind, name, namespace, componentName string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("v1")
+		obj.SetKind(kind)
+		obj.SetName(name)
+		obj.SetNamespace(namespace)
+		obj.SetLabels(map[string]string{metadata.LabelComponent: componentName})
+		return obj
+	}
+
+	cases := []struct {
+		name         string
+		dependencies bool
+		dependents   bool
+		expected     []string
+	}{
+		{name: "dependencies", dependencies: true, expected: []string{"namespace", "web"}},
+		{name: "dependents", dependents: true, expected: []string{"web", "worker"}},
+		{name: "both", dependencies: true, dependents: true, expected: []string{"namespace", "web", "worker"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			worker := object("ConfigMap", "worker", "web", "worker")
+			worker.SetAnnotations(map[string]string{metadata.AnnotationDependsOn: "web"})
+
+			objects := []*unstructured.Unstructured{
+				object("Namespace", "web", "", "namespace"),
+				object("Service", "web", "web", "web"),
+				worker,
+				object("ConfigMap", "other", "", "other"),
+			}
+
+			a := &Apply{
+				ApplyConfig: ApplyConfig{
+					ComponentNames:   []string{"web"},
+					WithDependencies: tc.dependencies,
+					WithDependents:   tc.dependents,
+				},
+				findObjectsFn: func(_ app.App, _ string, componentNames []string) ([]*unstructured.Unstructured, error) {
+					assert.Empty(t, componentNames)
+					return objects, nil
+				},
+			}
+
+			got, err := a.objects()
+			require.NoError(t, err)
+
+			var names []string
+			for _, obj := range got {
+				names = append(names, obj.GetLabels()[metadata.LabelComponent])
+			}
+
+			assert.Equal(t, tc.expected, names)
+			assert.Equal(t, tc.expected, a.ComponentNames)
+		})
+	}
+}
+
 func Test_Apply_retry_on_conflict(t *testing.T) {
 	test.WithApp(t, "/app", func(a *amocks.App, fs afero.Fs) {
+		a.On("Environment", mock.Anything).Return(&app.EnvironmentConfig{}, nil)
 		applyConfig := ApplyConfig{
 			App:          a,
 			ClientConfig: &client.Config{},
@@ -201,3 +387,209 @@ func genObject() map[string]interface{} {
 		},
 	}
 }
+
+type fakeServerDryRunner struct {
+	results map[string]*unstructured.Unstructured
+	errs    map[string]error
+}
+
+func (d *fakeServerDryRunner) DryRun(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	if err, ok := d.errs[obj.GetName()]; ok {
+		return nil, err
+	}
+
+	return d.results[obj.GetName()], nil
+}
+
+func Test_Apply_server_dry_run(t *testing.T) {
+	test.WithApp(t, "/app", func(a *amocks.App, fs afero.Fs) {
+		a.On("Environment", mock.Anything).Return(&app.EnvironmentConfig{}, nil)
+
+		var buf bytes.Buffer
+		applyConfig := ApplyConfig{
+			App:          a,
+			ClientConfig: &client.Config{},
+			ServerDryRun: true,
+			Out:          &buf,
+		}
+
+		newObj := func(name string, spec map[string]interface{}) *unstructured.Unstructured {
+			return &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Service",
+				"metadata":   map[string]interface{}{"name": name, "namespace": "default"},
+				"spec":       spec,
+			}}
+		}
+
+		mutated := newObj("mutated", map[string]interface{}{"port": int64(80), "type": "ClusterIP"})
+		mutated.SetUID("12345")
+
+		dryRunner := &fakeServerDryRunner{
+			results: map[string]*unstructured.Unstructured{
+				"accepted": newObj("accepted", map[string]interface{}{"port": int64(80)}),
+				"mutated":  mutated,
+			},
+			errs: map[string]error{
+				"rejected": kerrors.NewForbidden(schema.GroupResource{Resource: "services"}, "rejected", errors.New("denied by policy")),
+			},
+		}
+
+		setupApp := func(apply *Apply) {
+			apply.clientOpts = &Clients{}
+			apply.findObjectsFn = func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+				return []*unstructured.Unstructured{
+					newObj("accepted", map[string]interface{}{"port": float64(80)}),
+					newObj("mutated", map[string]interface{}{"port": float64(80)}),
+					newObj("rejected", map[string]interface{}{"port": float64(80)}),
+				}, nil
+			}
+			apply.serverDryRunnerFactory = func(Clients) (ServerDryRunner, error) {
+				return dryRunner, nil
+			}
+			apply.upserterFactory = func() Upserter {
+				return &fakeUpserter{upsertErr: errors.New("upsert should not run")}
+			}
+		}
+
+		err := RunApply(applyConfig, setupApp)
+		require.Error(t, err)
+
+		output := buf.String()
+		assert.Regexp(t, `Service\s+default/accepted\s+accepted`, output)
+		assert.Regexp(t, `Service\s+default/mutated\s+mutated\s+spec.type`, output)
+		assert.Regexp(t, `Service\s+default/rejected\s+rejected\s+Forbidden: .*denied by policy`, output)
+	})
+}
+
+func Test_mutatedFields(t *testing.T) {
+	submitted := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas":   float64(1),
+			"containers": []interface{}{map[string]interface{}{"image": "app:1"}},
+			"removed":    "x",
+		},
+	}
+	persisted := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas":   int64(1),
+			"containers": []interface{}{map[string]interface{}{"image": "app:1", "imagePullPolicy": "IfNotPresent"}},
+			"added":      true,
+		},
+	}
+
+	expected := []string{"spec.added", "spec.containers[0].imagePullPolicy", "spec.removed"}
+	assert.Equal(t, expected, mutatedFields("", submitted, persisted))
+}
+
+func Test_Apply_setupGCLabels(t *testing.T) {
+	// The app is labeled with its name, not its directory.
+	test.WithApp(t, "/tmp/ks-serve-123", func(a *amocks.App, fs afero.Fs) {
+		a.On("Name").Return("guestbook", nil)
+
+		apply := &Apply{
+			ApplyConfig: ApplyConfig{
+				App:      a,
+				EnvName:  "us-west/dev",
+				GcLabels: true,
+			},
+		}
+
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "guestbook"},
+		}}
+		obj.SetLabels(map[string]string{"app.kubernetes.io/part-of": "shop"})
+		SetMetaDataAnnotation(obj, metadata.AnnotationManaged, "{}")
+
+		err := apply.setupGCLabels(obj)
+		require.NoError(t, err)
+
+		labels := obj.GetLabels()
+		assert.Equal(t, "guestbook", labels[metadata.LabelApplication])
+		assert.Equal(t, "shop", labels["app.kubernetes.io/part-of"])
+		assert.Equal(t, "us-west.dev", labels[metadata.LabelEnvironment])
+
+		lastApplied := obj.GetAnnotations()[metadata.AnnotationLastApplied]
+		require.NotEmpty(t, lastApplied)
+		assert.Contains(t, lastApplied, metadata.LabelEnvironment)
+		assert.NotContains(t, lastApplied, metadata.AnnotationManaged)
+	})
+}
+
+func Test_Apply_setupGCLabels_unnamed_app(t *testing.T) {
+	test.WithApp(t, "/guestbook", func(a *amocks.App, fs afero.Fs) {
+		a.On("Name").Return("", nil)
+
+		apply := &Apply{
+			ApplyConfig: ApplyConfig{
+				App:      a,
+				EnvName:  "dev",
+				GcLabels: true,
+			},
+		}
+
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "guestbook"},
+		}}
+
+		err := apply.setupGCLabels(obj)
+		require.Error(t, err)
+	})
+}
+
+func Test_labelValue(t *testing.T) {
+	cases := []struct {
+		name     string
+		expected string
+	}{
+		{name: "dev", expected: "dev"},
+		{name: "us-west/dev", expected: "us-west.dev"},
+		{name: "/app/", expected: "app"},
+		{name: strings.Repeat("a", 70), expected: strings.Repeat("a", 63)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, labelValue(tc.name))
+		})
+	}
+}
+
+func Test_Apply_checkPolicies(t *testing.T) {
+	test.WithApp(t, "/guestbook", func(a *amocks.App, fs afero.Fs) {
+		var buf bytes.Buffer
+		apply := &Apply{
+			ApplyConfig: ApplyConfig{
+				App:     a,
+				EnvName: "dev",
+			},
+			out: &buf,
+			policyCheckFn: func(app.App, string, []*unstructured.Unstructured) (*policy.Result, error) {
+				return &policy.Result{
+					Violations: []policy.Violation{
+						{Policy: "policies/labels.rego", Level: policy.LevelWarn, Kind: "Service", Name: "guestbook", Message: "missing team label"},
+						{Policy: "policies/images.rego", Level: policy.LevelDeny, Kind: "Deployment", Name: "guestbook", Message: "image uses latest tag"},
+					},
+				}, nil
+			},
+			webhookReplayFn: func(app.App, string, []*unstructured.Unstructured) (*policy.Result, error) {
+				return &policy.Result{
+					Violations: []policy.Violation{
+						{Policy: "pod-security", Level: policy.LevelDeny, Kind: "Deployment", Name: "guestbook", Message: "privileged containers are not allowed"},
+					},
+				}, nil
+			},
+		}
+
+		err := apply.checkPolicies(nil)
+		require.Error(t, err)
+		assert.Equal(t, "2 policy violation(s) denied", err.Error())
+		assert.Contains(t, buf.String(), "image uses latest tag")
+		assert.Contains(t, buf.String(), "missing team label")
+		assert.Contains(t, buf.String(), "privileged containers are not allowed")
+	})
+}
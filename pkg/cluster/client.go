@@ -26,6 +26,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
 
 	// client go auth plugins
 	_ "k8s.io/client-go/plugin/pkg/client/auth/azure"
@@ -47,6 +48,7 @@ type Clients struct {
 	clientPool dynamic.ClientPool
 	discovery  discovery.DiscoveryInterface
 	namespace  string
+	config     *rest.Config
 }
 
 type resourceClientOpt func(*resourceClient)
@@ -116,9 +118,15 @@ func GenClients(a app.App, clientConfig *client.Config, envName string) (Clients
 		return Clients{}, err
 	}
 
+	config, err := clientConfig.Config.ClientConfig()
+	if err != nil {
+		return Clients{}, err
+	}
+
 	return Clients{
 		clientPool: clientPool,
 		discovery:  discovery,
 		namespace:  namespace,
+		config:     config,
 	}, nil
 }
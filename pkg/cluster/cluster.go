@@ -23,7 +23,6 @@ import (
 	"github.com/ksonnet/ksonnet/pkg/metadata"
 	"github.com/ksonnet/ksonnet/pkg/pipeline"
 	"github.com/ksonnet/ksonnet/utils"
-	log "github.com/sirupsen/logrus"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -161,6 +160,7 @@ func eligibleForGc(obj metav1.Object, gcTag string) bool {
 		strategy = metadata.GcStrategyAuto
 	}
 
-	return a[metadata.AnnotationGcTag] == gcTag &&
+	// Without a tag, the objects to consider are selected by label.
+	return (gcTag == "" || a[metadata.AnnotationGcTag] == gcTag) &&
 		strategy == metadata.GcStrategyAuto
 }
@@ -18,14 +18,29 @@ package cluster
 import (
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/redact"
+	"github.com/ksonnet/ksonnet/pkg/util/trace"
 	"github.com/ksonnet/ksonnet/utils"
 	"github.com/pkg/errors"
-	log "github.com/sirupsen/logrus"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+var (
+	// systemNamespaces are never pruned.
+	systemNamespaces = map[string]bool{
+		metav1.NamespaceDefault: true,
+		metav1.NamespaceSystem:  true,
+		metav1.NamespacePublic:  true,
+		"kube-node-lease":       true,
+	}
 )
 
 // DeleteConfig is configuration for Delete.
@@ -35,6 +50,13 @@ type DeleteConfig struct {
 	ComponentNames []string
 	EnvName        string
 	GracePeriod    int64
+	// PruneNamespaces deletes the namespaces which contained the deleted
+	// objects, and the environment namespace, once they are empty.
+	PruneNamespaces bool
+	// Wait waits for deleted objects to be removed, i.e. for their finalizers
+	// to complete, before deleting the objects they depend on.
+	Wait        bool
+	WaitTimeout time.Duration
 }
 
 // DeleteOpts is an option for configuring Delete.
@@ -49,6 +71,8 @@ type Delete struct {
 	genClientOptsFn       genClientOptsFn
 	objectInfo            ObjectInfo
 	resourceClientFactory resourceClientFactoryFn
+	namespaceEmptyFn      func(Clients, string) (bool, error)
+	waitInterval          time.Duration
 }
 
 // RunDelete runs delete against a cluster for a given configuration.
@@ -59,16 +83,21 @@ func RunDelete(config DeleteConfig, opts ...DeleteOpts) error {
 		genClientOptsFn:       GenClients,
 		resourceClientFactory: resourceClientFactory,
 		objectInfo:            &objectInfo{},
+		namespaceEmptyFn:      namespaceEmpty,
+		waitInterval:          defaultWaitInterval,
 	}
 
 	for _, opt := range opts {
 		opt(d)
 	}
 
-	return d.Delete()
+	span := trace.Start("cluster.delete", "env", config.EnvName)
+	return span.Finish(d.Delete())
 }
 
-// Delete deletes objects from a cluster.
+// Delete deletes objects from a cluster. Objects are deleted in reverse
+// dependency order, e.g. workloads and custom resources before the
+// CustomResourceDefinitions and Namespaces they depend on.
 func (d *Delete) Delete() error {
 	apiObjects, err := d.findObjectsFn(d.App, d.EnvName, d.ComponentNames)
 	if err != nil {
@@ -84,7 +113,7 @@ func (d *Delete) Delete() error {
 	if err != nil {
 		return err
 	}
-	sort.Sort(sort.Reverse(utils.DependencyOrder(apiObjects)))
+	sort.Stable(sort.Reverse(utils.DependencyOrder(apiObjects)))
 
 	deleteOpts := metav1.DeleteOptions{}
 	if version.Compare(1, 6) < 0 {
@@ -100,7 +129,20 @@ func (d *Delete) Delete() error {
 		deleteOpts.GracePeriodSeconds = &d.GracePeriod
 	}
 
-	for _, obj := range apiObjects {
+	w := newWaiter(co, d.resourceClientFactory, d.WaitTimeout)
+	w.interval = d.waitInterval
+
+	var pending []*unstructured.Unstructured
+	for i, obj := range apiObjects {
+		// With --wait, each dependency tier is removed before the next one is
+		// deleted, so e.g. custom resources can finalize before their CRD goes.
+		if d.Wait && i > 0 && utils.DependencyOrder(apiObjects).Less(i, i-1) {
+			if err = d.waitDeleted(w, pending); err != nil {
+				return err
+			}
+			pending = nil
+		}
+
 		desc := fmt.Sprintf("%s %s", d.objectInfo.ResourceName(co.discovery, obj), utils.FqName(obj))
 		log.Info("Deleting ", desc)
 
@@ -114,9 +156,180 @@ func (d *Delete) Delete() error {
 			return fmt.Errorf("Error deleting %s: %s", desc, err)
 		}
 
-		log.Debugf("Deleted object: ", obj)
+		log.Debugf("Deleted object: %v", redact.Object(obj))
+		pending = append(pending, obj)
+	}
+
+	if d.Wait {
+		if err = d.waitDeleted(w, pending); err != nil {
+			return err
+		}
+	}
+
+	if d.PruneNamespaces {
+		return d.pruneNamespaces(co, w, apiObjects, &deleteOpts)
 	}
 
 	return nil
+}
+
+func (d *Delete) waitDeleted(w *waiter, objects []*unstructured.Unstructured) error {
+	if len(objects) == 0 {
+		return nil
+	}
+
+	log.Infof("waiting up to %s for %d object(s) to be deleted", w.timeout, len(objects))
+	return errors.Wrap(w.WaitDeleted(objects), "wait for deletion")
+}
+
+// pruneNamespaces deletes the namespaces of the deleted objects and the
+// environment namespace if nothing else lives in them.
+func (d *Delete) pruneNamespaces(co Clients, w *waiter, deleted []*unstructured.Unstructured, deleteOpts *metav1.DeleteOptions) error {
+	candidates := map[string]bool{}
+	if co.namespace != "" {
+		candidates[co.namespace] = true
+	}
+	for _, obj := range deleted {
+		if obj.GetNamespace() != "" {
+			candidates[obj.GetNamespace()] = true
+		}
+	}
+
+	var names []string
+	for name := range candidates {
+		if !systemNamespaces[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var pruned []*unstructured.Unstructured
+	for _, name := range names {
+		if isComponentNamespace(deleted, name) {
+			// Namespaces defined by components were already deleted.
+			continue
+		}
+
+		empty, err := d.namespaceEmptyFn(co, name)
+		if err != nil {
+			log.Warnf("Unable to determine if namespace %s is empty, skipping: %v", name, err)
+			continue
+		}
+		if !empty {
+			log.Infof("Namespace %s is not empty, skipping", name)
+			continue
+		}
+
+		ns := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Namespace",
+			"metadata":   map[string]interface{}{"name": name},
+		}}
+
+		log.Info("Deleting empty namespace ", name)
+
+		client, err := d.resourceClientFactory(co, ns)
+		if err != nil {
+			return err
+		}
+
+		err = client.Delete(deleteOpts)
+		if err != nil && !kerrors.IsNotFound(err) {
+			return fmt.Errorf("Error deleting namespace %s: %s", name, err)
+		}
+
+		pruned = append(pruned, ns)
+	}
+
+	if d.Wait {
+		return d.waitDeleted(w, pruned)
+	}
+
+	return nil
+}
+
+func isComponentNamespace(objects []*unstructured.Unstructured, name string) bool {
+	for _, obj := range objects {
+		if obj.GetKind() == "Namespace" && obj.GetName() == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// namespaceEmpty reports if a namespace contains no objects other than the
+// ones Kubernetes creates in every namespace.
+func namespaceEmpty(clients Clients, namespace string) (bool, error) {
+	if clients.discovery == nil {
+		return false, errors.New("nil discovery client")
+	}
+	if clients.clientPool == nil {
+		return false, errors.New("nil client pool")
+	}
+
+	resources, err := clients.discovery.ServerPreferredNamespacedResources()
+	if err != nil {
+		return false, errors.Wrap(err, "ServerPreferredNamespacedResources")
+	}
+
+	filtered := discovery.FilteredBy(
+		discovery.ResourcePredicateFunc(
+			func(groupVersion string, r *metav1.APIResource) bool {
+				return !unmanagedKinds[r.Kind] && r.Kind != "Event" &&
+					discovery.SupportsAllVerbs{Verbs: []string{"list"}}.Match(groupVersion, r)
+			},
+		),
+		resources,
+	)
+
+	for _, lst := range filtered {
+		gv, err := schema.ParseGroupVersion(lst.GroupVersion)
+		if err != nil {
+			return false, errors.Wrapf(err, "parsing GroupVersion: %s", lst.GroupVersion)
+		}
+
+		for _, resource := range lst.APIResources {
+			gvk := gv.WithKind(resource.Kind)
+			dynamic, err := clients.clientPool.ClientForGroupVersionKind(gvk)
+			if err != nil {
+				return false, errors.Wrapf(err, "creating client for resource: %s", gvk)
+			}
+
+			obj, err := dynamic.Resource(&resource, namespace).List(metav1.ListOptions{})
+			if err != nil {
+				return false, errors.Wrapf(err, "listing %s", gvk)
+			}
+
+			ul, ok := obj.(*unstructured.UnstructuredList)
+			if !ok {
+				continue
+			}
+
+			for i := range ul.Items {
+				if !isDefaultNamespaceObject(&ul.Items[i]) {
+					log.Debugf("namespace %s contains %s %s", namespace, ul.Items[i].GetKind(), ul.Items[i].GetName())
+					return false, nil
+				}
+			}
+		}
+	}
+
+	return true, nil
+}
+
+// isDefaultNamespaceObject reports if an object is created by Kubernetes
+// in every namespace.
+func isDefaultNamespaceObject(obj *unstructured.Unstructured) bool {
+	switch obj.GetKind() {
+	case "ServiceAccount":
+		return obj.GetName() == "default"
+	case "Secret":
+		t, _, _ := unstructured.NestedString(obj.Object, "type")
+		return t == "kubernetes.io/service-account-token"
+	case "ConfigMap":
+		return obj.GetName() == "kube-root-ca.crt"
+	}
 
+	return false
 }
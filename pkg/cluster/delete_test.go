@@ -0,0 +1,195 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/cluster/mocks"
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+func newDeleteObject(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata":   map[string]interface{}{"name": name},
+	}}
+	if namespace != "" {
+		obj.SetNamespace(namespace)
+	}
+
+	return obj
+}
+
+// fakeDeleteCluster records calls to resource clients. Objects are removed
+// from the cluster after they are fetched once following their deletion.
+type fakeDeleteCluster struct {
+	calls   []string
+	deleted map[string]bool
+}
+
+func (c *fakeDeleteCluster) factory(_ Clients, o runtime.Object) (ResourceClient, error) {
+	obj := o.(*unstructured.Unstructured)
+	key := obj.GetKind() + "/" + obj.GetName()
+
+	rc := &mocks.ResourceClient{}
+	rc.On("Delete", mock.Anything).Return(func(*metav1.DeleteOptions) error {
+		c.calls = append(c.calls, "delete "+key)
+		return nil
+	})
+	rc.On("Get", mock.Anything).Return(
+		func(metav1.GetOptions) *unstructured.Unstructured {
+			c.calls = append(c.calls, "get "+key)
+			return obj
+		},
+		func(metav1.GetOptions) error {
+			if c.deleted[key] {
+				return &notFoundError{}
+			}
+			c.deleted[key] = true
+			return nil
+		},
+	)
+
+	return rc, nil
+}
+
+func Test_Delete(t *testing.T) {
+	cases := []struct {
+		name            string
+		wait            bool
+		pruneNamespaces bool
+		emptyNamespaces map[string]bool
+		expected        []string
+	}{
+		{
+			name: "reverse dependency order",
+			expected: []string{
+				"delete Deployment/app",
+				"delete Certificate/cert",
+				"delete CustomResourceDefinition/certificates.example.com",
+				"delete Namespace/certs",
+			},
+		},
+		{
+			name: "wait between dependency tiers",
+			wait: true,
+			expected: []string{
+				"delete Deployment/app",
+				"get Deployment/app",
+				"get Deployment/app",
+				"delete Certificate/cert",
+				"get Certificate/cert",
+				"get Certificate/cert",
+				"delete CustomResourceDefinition/certificates.example.com",
+				"get CustomResourceDefinition/certificates.example.com",
+				"get CustomResourceDefinition/certificates.example.com",
+				"delete Namespace/certs",
+				"get Namespace/certs",
+				"get Namespace/certs",
+			},
+		},
+		{
+			name:            "prune empty namespaces",
+			pruneNamespaces: true,
+			emptyNamespaces: map[string]bool{"default": true, "web": true, "env": false},
+			expected: []string{
+				"delete Deployment/app",
+				"delete Certificate/cert",
+				"delete CustomResourceDefinition/certificates.example.com",
+				"delete Namespace/certs",
+				"delete Namespace/web",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			test.WithApp(t, "/app", func(a *amocks.App, fs afero.Fs) {
+				objects := []*unstructured.Unstructured{
+					newDeleteObject("v1", "Namespace", "", "certs"),
+					newDeleteObject("apps/v1", "Deployment", "web", "app"),
+					newDeleteObject("apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "", "certificates.example.com"),
+					newDeleteObject("example.com/v1", "Certificate", "certs", "cert"),
+				}
+
+				cluster := &fakeDeleteCluster{deleted: make(map[string]bool)}
+
+				disco := &fakediscovery.FakeDiscovery{Fake: &ktesting.Fake{}}
+				disco.FakedServerVersion = &version.Info{Major: "1", Minor: "10"}
+
+				objectInfo := &mocks.ObjectInfo{}
+				objectInfo.On("ResourceName", mock.Anything, mock.Anything).Return("resource")
+
+				config := DeleteConfig{
+					App:             a,
+					ClientConfig:    &client.Config{},
+					EnvName:         "default",
+					GracePeriod:     -1,
+					PruneNamespaces: tc.pruneNamespaces,
+					Wait:            tc.wait,
+					WaitTimeout:     time.Second,
+				}
+
+				setup := func(d *Delete) {
+					d.findObjectsFn = func(app.App, string, []string) ([]*unstructured.Unstructured, error) {
+						return objects, nil
+					}
+					d.genClientOptsFn = func(app.App, *client.Config, string) (Clients, error) {
+						return Clients{discovery: disco, namespace: "env"}, nil
+					}
+					d.objectInfo = objectInfo
+					d.resourceClientFactory = cluster.factory
+					d.namespaceEmptyFn = func(_ Clients, namespace string) (bool, error) {
+						return tc.emptyNamespaces[namespace], nil
+					}
+					d.waitInterval = time.Millisecond
+				}
+
+				err := RunDelete(config, setup)
+				require.NoError(t, err)
+
+				assert.Equal(t, tc.expected, cluster.calls)
+			})
+		})
+	}
+}
+
+func Test_isDefaultNamespaceObject(t *testing.T) {
+	token := newDeleteObject("v1", "Secret", "ns", "default-token-abcde")
+	token.Object["type"] = "kubernetes.io/service-account-token"
+
+	assert.True(t, isDefaultNamespaceObject(newDeleteObject("v1", "ServiceAccount", "ns", "default")))
+	assert.True(t, isDefaultNamespaceObject(token))
+	assert.True(t, isDefaultNamespaceObject(newDeleteObject("v1", "ConfigMap", "ns", "kube-root-ca.crt")))
+	assert.False(t, isDefaultNamespaceObject(newDeleteObject("v1", "ServiceAccount", "ns", "app")))
+	assert.False(t, isDefaultNamespaceObject(newDeleteObject("v1", "Secret", "ns", "app")))
+}
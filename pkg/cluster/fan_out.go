@@ -0,0 +1,139 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"io"
+	"os"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/util/table"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ClusterSucceeded means an operation succeeded against a cluster.
+	ClusterSucceeded = "succeeded"
+	// ClusterFailed means an operation failed against a cluster.
+	ClusterFailed = "failed"
+	// ClusterSkipped means an operation was not attempted against a cluster
+	// because too many clusters had already failed.
+	ClusterSkipped = "skipped"
+)
+
+// ClusterResult is the outcome of an operation against one destination of an
+// environment.
+type ClusterResult struct {
+	Destination string
+	Status      string
+	Err         error
+}
+
+// FanOutConfig is configuration for FanOut.
+type FanOutConfig struct {
+	App            app.App
+	ClientConfig   *client.Config
+	EnvName        string
+	MaxUnavailable int
+	Out            io.Writer
+}
+
+// FanOutFn is an operation run against a single destination.
+type FanOutFn func(destination string, clientConfig *client.Config) error
+
+// FanOut runs fn against each cluster destination of an environment, in
+// order. Environments with a single destination run fn once with the
+// configured client. Once more than MaxUnavailable destinations have failed,
+// the remaining destinations are skipped so a bad change is not rolled out
+// everywhere, and an error is returned. Otherwise failures are reported in
+// the per-cluster status, but do not fail the operation.
+func FanOut(config FanOutConfig, fn FanOutFn) error {
+	env, err := config.App.Environment(config.EnvName)
+	if err != nil {
+		return err
+	}
+
+	destinations := env.ClusterDestinations()
+	if len(env.Destinations) == 0 {
+		name := ""
+		if len(destinations) > 0 {
+			name = destinations[0].String()
+		}
+		return fn(name, config.ClientConfig)
+	}
+
+	if config.MaxUnavailable < 0 {
+		return errors.Errorf("max unavailable clusters must not be negative; got %d", config.MaxUnavailable)
+	}
+
+	results := make([]ClusterResult, 0, len(destinations))
+	failed := 0
+	for _, destination := range destinations {
+		result := ClusterResult{Destination: destination.String()}
+
+		switch {
+		case failed > config.MaxUnavailable:
+			result.Status = ClusterSkipped
+		default:
+			log.Infof("Running against cluster %s", result.Destination)
+			result.Err = fn(result.Destination, config.ClientConfig.ForDestination(destination))
+			result.Status = ClusterSucceeded
+			if result.Err != nil {
+				log.WithError(result.Err).Errorf("Cluster %s failed", result.Destination)
+				result.Status = ClusterFailed
+				failed++
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	out := config.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	if err = renderClusterResults(out, results); err != nil {
+		return err
+	}
+
+	if failed > config.MaxUnavailable {
+		return errors.Errorf("%d of %d cluster(s) failed; at most %d may be unavailable",
+			failed, len(destinations), config.MaxUnavailable)
+	}
+
+	if failed > 0 {
+		log.Warnf("%d of %d cluster(s) failed, within the limit of %d unavailable cluster(s)",
+			failed, len(destinations), config.MaxUnavailable)
+	}
+
+	return nil
+}
+
+func renderClusterResults(w io.Writer, results []ClusterResult) error {
+	t := table.New("clusterResults", w)
+	t.SetHeader([]string{"CLUSTER", "STATUS", "ERROR"})
+
+	for _, r := range results {
+		msg := ""
+		if r.Err != nil {
+			msg = r.Err.Error()
+		}
+		t.Append([]string{r.Destination, r.Status, msg})
+	}
+
+	return t.Render()
+}
@@ -0,0 +1,123 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_FanOut(t *testing.T) {
+	cases := []struct {
+		name           string
+		destinations   []*app.EnvironmentDestinationSpec
+		failing        map[string]bool
+		maxUnavailable int
+		expected       []string
+		isErr          bool
+	}{
+		{
+			name:     "single destination",
+			expected: []string{"https://primary"},
+		},
+		{
+			name: "all clusters succeed",
+			destinations: []*app.EnvironmentDestinationSpec{
+				{Name: "east", Context: "east"},
+				{Context: "west"},
+			},
+			expected: []string{"east", "west"},
+		},
+		{
+			name: "failures within the limit",
+			destinations: []*app.EnvironmentDestinationSpec{
+				{Context: "east"},
+				{Context: "west"},
+			},
+			failing:        map[string]bool{"east": true},
+			maxUnavailable: 1,
+			expected:       []string{"east", "west"},
+		},
+		{
+			name: "too many failures skip remaining clusters",
+			destinations: []*app.EnvironmentDestinationSpec{
+				{Context: "east"},
+				{Context: "west"},
+			},
+			failing:  map[string]bool{"east": true},
+			expected: []string{"east"},
+			isErr:    true,
+		},
+		{
+			name: "negative limit",
+			destinations: []*app.EnvironmentDestinationSpec{
+				{Context: "east"},
+			},
+			maxUnavailable: -1,
+			isErr:          true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			test.WithApp(t, "/app", func(a *amocks.App, fs afero.Fs) {
+				a.On("Environment", "prod").Return(&app.EnvironmentConfig{
+					Destination:  &app.EnvironmentDestinationSpec{Server: "https://primary"},
+					Destinations: tc.destinations,
+				}, nil)
+
+				var buf bytes.Buffer
+				config := FanOutConfig{
+					App:            a,
+					ClientConfig:   &client.Config{},
+					EnvName:        "prod",
+					MaxUnavailable: tc.maxUnavailable,
+					Out:            &buf,
+				}
+
+				var called []string
+				err := FanOut(config, func(destination string, _ *client.Config) error {
+					called = append(called, destination)
+					if tc.failing[destination] {
+						return errors.New("unreachable")
+					}
+					return nil
+				})
+
+				if tc.isErr {
+					require.Error(t, err)
+				} else {
+					require.NoError(t, err)
+				}
+
+				assert.Equal(t, tc.expected, called)
+
+				if len(tc.destinations) > 1 && len(tc.failing) > 0 {
+					assert.Regexp(t, `east\s+failed\s+unreachable`, buf.String())
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,310 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/ksonnet/ksonnet/pkg/util/trace"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// HookPreApply hooks run before objects are applied.
+	HookPreApply = "pre-apply"
+	// HookPostApply hooks run after objects are applied and, with --wait,
+	// are ready.
+	HookPostApply = "post-apply"
+
+	// HookFailurePolicyFail stops the apply when a hook fails.
+	HookFailurePolicyFail = "fail"
+	// HookFailurePolicyIgnore logs a hook failure and continues the apply.
+	HookFailurePolicyIgnore = "ignore"
+
+	// DefaultHookTimeout is the default amount of time a hook may run.
+	DefaultHookTimeout = 5 * time.Minute
+)
+
+// hook is a local command or a Job which is run before or after objects are applied.
+type hook struct {
+	name          string
+	phase         string
+	weight        int
+	timeout       time.Duration
+	failurePolicy string
+
+	// command is set for local command hooks.
+	command []string
+	// object is set for Job hooks.
+	object *unstructured.Unstructured
+}
+
+func newHook(name, phase string, weight int, timeout, failurePolicy string) (*hook, error) {
+	h := &hook{
+		name:          name,
+		phase:         phase,
+		weight:        weight,
+		timeout:       DefaultHookTimeout,
+		failurePolicy: HookFailurePolicyFail,
+	}
+
+	switch phase {
+	case HookPreApply, HookPostApply:
+	default:
+		return nil, errors.Errorf("hook %s has invalid phase %q; valid phases are %s and %s",
+			name, phase, HookPreApply, HookPostApply)
+	}
+
+	switch failurePolicy {
+	case "":
+	case HookFailurePolicyFail, HookFailurePolicyIgnore:
+		h.failurePolicy = failurePolicy
+	default:
+		return nil, errors.Errorf("hook %s has invalid failure policy %q; valid policies are %s and %s",
+			name, failurePolicy, HookFailurePolicyFail, HookFailurePolicyIgnore)
+	}
+
+	if timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil {
+			return nil, errors.Wrapf(err, "hook %s has invalid timeout", name)
+		}
+		h.timeout = d
+	}
+
+	return h, nil
+}
+
+func (h *hook) String() string {
+	if h.object != nil {
+		return fmt.Sprintf("%s hook Job %s", h.phase, h.name)
+	}
+
+	return fmt.Sprintf("%s hook %s", h.phase, h.name)
+}
+
+type hooks []*hook
+
+// forPhase returns the hooks of a phase in the order they run: by weight,
+// then by name.
+func (hs hooks) forPhase(phase string) hooks {
+	var selected hooks
+	for _, h := range hs {
+		if h.phase == phase {
+			selected = append(selected, h)
+		}
+	}
+
+	sort.SliceStable(selected, func(i, j int) bool {
+		if selected[i].weight != selected[j].weight {
+			return selected[i].weight < selected[j].weight
+		}
+		return selected[i].name < selected[j].name
+	})
+
+	return selected
+}
+
+// checkNoCommands returns an error if any of the hooks is a local command.
+func (hs hooks) checkNoCommands() error {
+	for _, h := range hs {
+		if h.command != nil {
+			return errors.Errorf("%s runs a local command, which is not allowed for this app", h)
+		}
+	}
+
+	return nil
+}
+
+// extractHooks returns the hooks of an environment, and separates the Jobs
+// annotated as hooks from the objects to apply.
+func extractHooks(env *app.EnvironmentConfig, componentNames []string, objects []*unstructured.Unstructured) ([]*unstructured.Unstructured, hooks, error) {
+	var hs hooks
+
+	if env != nil {
+		for _, hc := range env.Hooks {
+			if hc == nil || !hookSelected(hc, componentNames) {
+				continue
+			}
+
+			if len(hc.Command) == 0 {
+				return nil, nil, errors.Errorf("hook %s does not specify a command", hc.Name)
+			}
+
+			h, err := newHook(hc.Name, hc.Phase, hc.Weight, hc.Timeout, hc.FailurePolicy)
+			if err != nil {
+				return nil, nil, err
+			}
+			h.command = hc.Command
+
+			hs = append(hs, h)
+		}
+	}
+
+	var remaining []*unstructured.Unstructured
+	for _, obj := range objects {
+		annotations := obj.GetAnnotations()
+		phase, ok := annotations[metadata.AnnotationHook]
+		if !ok {
+			remaining = append(remaining, obj)
+			continue
+		}
+
+		if obj.GetKind() != "Job" {
+			return nil, nil, errors.Errorf("%s %s is annotated with %s, but only Jobs can be hooks",
+				obj.GetKind(), obj.GetName(), metadata.AnnotationHook)
+		}
+
+		weight := 0
+		if s := annotations[metadata.AnnotationHookWeight]; s != "" {
+			var err error
+			if weight, err = strconv.Atoi(s); err != nil {
+				return nil, nil, errors.Errorf("hook Job %s has invalid weight %q", obj.GetName(), s)
+			}
+		}
+
+		h, err := newHook(obj.GetName(), phase, weight,
+			annotations[metadata.AnnotationHookTimeout], annotations[metadata.AnnotationHookFailurePolicy])
+		if err != nil {
+			return nil, nil, err
+		}
+		h.object = obj
+
+		hs = append(hs, h)
+	}
+
+	return remaining, hs, nil
+}
+
+// hookSelected reports if a hook runs when the named components are applied.
+// All components are applied if none are named.
+func hookSelected(hc *app.HookConfig, componentNames []string) bool {
+	if len(hc.Components) == 0 || len(componentNames) == 0 {
+		return true
+	}
+
+	for _, c := range hc.Components {
+		for _, name := range componentNames {
+			if c == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// runHooks runs hooks in order. It stops at the first failed hook unless the
+// hook's failure policy is ignore.
+func (a *Apply) runHooks(hs hooks) error {
+	for _, h := range hs {
+		if a.DryRun {
+			log.Infof("running %s%s", h, a.dryRunText())
+			continue
+		}
+
+		log.Infof("running %s", h)
+		span := trace.Start("cluster.hook", "name", h.name, "phase", h.phase)
+		err := span.Finish(a.runHook(h))
+		if err == nil {
+			continue
+		}
+
+		if h.failurePolicy == HookFailurePolicyIgnore {
+			log.WithError(err).Warnf("%s failed; ignoring", h)
+			continue
+		}
+
+		return errors.Wrapf(err, "%s failed", h)
+	}
+
+	return nil
+}
+
+func (a *Apply) runHook(h *hook) error {
+	if h.object != nil {
+		return a.runJobHook(h)
+	}
+
+	return a.runCommandHook(h)
+}
+
+// runCommandHook runs a local command in the app root. The command can read
+// the app root and environment name from $KS_APP_DIR and $KS_ENV.
+func (a *Apply) runCommandHook(h *hook) error {
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, h.command[0], h.command[1:]...)
+	cmd.Dir = a.App.Root()
+	cmd.Env = append(os.Environ(), "KS_APP_DIR="+a.App.Root(), "KS_ENV="+a.EnvName)
+	cmd.Stdout = a.out
+	cmd.Stderr = os.Stderr
+
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return errors.Errorf("timed out after %s", h.timeout)
+	}
+
+	return err
+}
+
+// runJobHook creates a hook Job and waits for it to complete. Jobs can not be
+// updated, so a Job left by a previous apply is deleted first.
+func (a *Apply) runJobHook(h *hook) error {
+	obj := h.object.DeepCopy()
+
+	w := newWaiter(*a.clientOpts, a.resourceClientFactory, h.timeout)
+	w.interval = a.waitInterval
+
+	rc, err := a.resourceClientFactory(*a.clientOpts, obj)
+	if err != nil {
+		return err
+	}
+
+	background := metav1.DeletePropagationBackground
+	err = rc.Delete(&metav1.DeleteOptions{PropagationPolicy: &background})
+	switch {
+	case err == nil:
+		if err = w.WaitDeleted([]*unstructured.Unstructured{obj}); err != nil {
+			return err
+		}
+	case !kerrors.IsNotFound(errors.Cause(err)):
+		return errors.Wrap(err, "deleting previous Job")
+	}
+
+	if err = a.preprocessObject(obj); err != nil {
+		return err
+	}
+
+	if _, _, err = a.upsert(obj); err != nil {
+		return errors.Wrap(err, "creating Job")
+	}
+
+	_, err = w.Wait([]*unstructured.Unstructured{obj})
+	return err
+}
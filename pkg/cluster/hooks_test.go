@@ -0,0 +1,257 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/cluster/mocks"
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func newHookJob(name string, annotations map[string]string) *unstructured.Unstructured {
+	obj := newDeleteObject("batch/v1", "Job", "default", name)
+	obj.SetAnnotations(annotations)
+	return obj
+}
+
+func Test_extractHooks(t *testing.T) {
+	env := &app.EnvironmentConfig{
+		Hooks: []*app.HookConfig{
+			{Name: "notify", Phase: HookPostApply, Command: []string{"notify"}},
+			{Name: "backup", Phase: HookPreApply, Command: []string{"backup"}, Weight: 10, Timeout: "1m"},
+			{Name: "migrate", Phase: HookPreApply, Command: []string{"migrate"}, Components: []string{"db"}},
+		},
+	}
+
+	deployment := newDeleteObject("apps/v1", "Deployment", "default", "web")
+	job := newHookJob("schema", map[string]string{
+		metadata.AnnotationHook:              HookPreApply,
+		metadata.AnnotationHookWeight:        "-1",
+		metadata.AnnotationHookFailurePolicy: HookFailurePolicyIgnore,
+	})
+
+	objects, hs, err := extractHooks(env, []string{"web"}, []*unstructured.Unstructured{deployment, job})
+	require.NoError(t, err)
+	assert.Equal(t, []*unstructured.Unstructured{deployment}, objects)
+
+	pre := hs.forPhase(HookPreApply)
+	require.Len(t, pre, 2)
+	assert.Equal(t, "schema", pre[0].name)
+	assert.Equal(t, job, pre[0].object)
+	assert.Equal(t, HookFailurePolicyIgnore, pre[0].failurePolicy)
+	assert.Equal(t, DefaultHookTimeout, pre[0].timeout)
+	assert.Equal(t, "backup", pre[1].name)
+	assert.Equal(t, []string{"backup"}, pre[1].command)
+	assert.Equal(t, time.Minute, pre[1].timeout)
+	assert.Equal(t, HookFailurePolicyFail, pre[1].failurePolicy)
+
+	post := hs.forPhase(HookPostApply)
+	require.Len(t, post, 1)
+	assert.Equal(t, "notify", post[0].name)
+}
+
+func Test_hooks_checkNoCommands(t *testing.T) {
+	job := newHookJob("schema", map[string]string{metadata.AnnotationHook: HookPreApply})
+	_, hs, err := extractHooks(nil, nil, []*unstructured.Unstructured{job})
+	require.NoError(t, err)
+	require.NoError(t, hs.checkNoCommands())
+
+	env := &app.EnvironmentConfig{
+		Hooks: []*app.HookConfig{{Name: "backup", Phase: HookPreApply, Command: []string{"backup"}}},
+	}
+	_, hs, err = extractHooks(env, nil, []*unstructured.Unstructured{job})
+	require.NoError(t, err)
+	require.Error(t, hs.checkNoCommands())
+}
+
+func Test_extractHooks_invalid(t *testing.T) {
+	cases := []struct {
+		name    string
+		env     *app.EnvironmentConfig
+		objects []*unstructured.Unstructured
+	}{
+		{
+			name: "missing command",
+			env:  &app.EnvironmentConfig{Hooks: []*app.HookConfig{{Name: "a", Phase: HookPreApply}}},
+		},
+		{
+			name: "invalid phase",
+			env:  &app.EnvironmentConfig{Hooks: []*app.HookConfig{{Name: "a", Phase: "pre-delete", Command: []string{"a"}}}},
+		},
+		{
+			name: "invalid timeout",
+			env:  &app.EnvironmentConfig{Hooks: []*app.HookConfig{{Name: "a", Phase: HookPreApply, Command: []string{"a"}, Timeout: "soon"}}},
+		},
+		{
+			name: "invalid failure policy",
+			objects: []*unstructured.Unstructured{newHookJob("a", map[string]string{
+				metadata.AnnotationHook:              HookPostApply,
+				metadata.AnnotationHookFailurePolicy: "retry",
+			})},
+		},
+		{
+			name: "invalid weight",
+			objects: []*unstructured.Unstructured{newHookJob("a", map[string]string{
+				metadata.AnnotationHook:       HookPostApply,
+				metadata.AnnotationHookWeight: "first",
+			})},
+		},
+		{
+			name: "not a job",
+			objects: []*unstructured.Unstructured{func() *unstructured.Unstructured {
+				obj := newDeleteObject("v1", "Pod", "default", "a")
+				obj.SetAnnotations(map[string]string{metadata.AnnotationHook: HookPreApply})
+				return obj
+			}()},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := extractHooks(tc.env, nil, tc.objects)
+			require.Error(t, err)
+		})
+	}
+}
+
+func Test_Apply_runHooks(t *testing.T) {
+	cases := []struct {
+		name          string
+		command       []string
+		timeout       time.Duration
+		failurePolicy string
+		dryRun        bool
+		isErr         bool
+		expected      string
+	}{
+		{
+			name:     "succeeds",
+			command:  []string{"sh", "-c", `echo "$KS_ENV"`},
+			expected: "dev\n",
+		},
+		{
+			name:    "fails",
+			command: []string{"sh", "-c", "exit 1"},
+			isErr:   true,
+		},
+		{
+			name:          "failure ignored",
+			command:       []string{"sh", "-c", "exit 1"},
+			failurePolicy: HookFailurePolicyIgnore,
+		},
+		{
+			name:    "times out",
+			command: []string{"sleep", "5"},
+			timeout: 50 * time.Millisecond,
+			isErr:   true,
+		},
+		{
+			name:    "dry run",
+			command: []string{"sh", "-c", "exit 1"},
+			dryRun:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			test.WithApp(t, os.TempDir(), func(a *amocks.App, fs afero.Fs) {
+				var buf bytes.Buffer
+				apply := &Apply{
+					ApplyConfig: ApplyConfig{
+						App:     a,
+						EnvName: "dev",
+						DryRun:  tc.dryRun,
+					},
+					out: &buf,
+				}
+
+				h, err := newHook("hook", HookPreApply, 0, "", tc.failurePolicy)
+				require.NoError(t, err)
+				h.command = tc.command
+				if tc.timeout > 0 {
+					h.timeout = tc.timeout
+				}
+
+				err = apply.runHooks(hooks{h})
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, buf.String())
+			})
+		})
+	}
+}
+
+func Test_Apply_runJobHook(t *testing.T) {
+	test.WithApp(t, "/app", func(a *amocks.App, fs afero.Fs) {
+		var calls []string
+
+		factory := func(_ Clients, o runtime.Object) (ResourceClient, error) {
+			rc := &mocks.ResourceClient{}
+			rc.On("Delete", mock.Anything).Return(func(*metav1.DeleteOptions) error {
+				calls = append(calls, "delete")
+				return &notFoundError{}
+			})
+			rc.On("Get", mock.Anything).Return(
+				func(metav1.GetOptions) *unstructured.Unstructured {
+					calls = append(calls, "get")
+					job := newHookJob("schema", nil)
+					job.Object["status"] = map[string]interface{}{"succeeded": int64(1)}
+					return job
+				},
+				nil,
+			)
+			return rc, nil
+		}
+
+		upserter := &fakeUpserter{upsertID: "12345"}
+
+		apply := &Apply{
+			ApplyConfig: ApplyConfig{
+				App:     a,
+				EnvName: "dev",
+			},
+			clientOpts:            &Clients{},
+			resourceClientFactory: factory,
+			upserterFactory:       func() Upserter { return upserter },
+			waitInterval:          time.Millisecond,
+		}
+
+		h, err := newHook("schema", HookPreApply, 0, "", "")
+		require.NoError(t, err)
+		h.object = newHookJob("schema", map[string]string{metadata.AnnotationHook: HookPreApply})
+
+		require.NoError(t, apply.runJobHook(h))
+		assert.Equal(t, []string{"delete", "get"}, calls)
+	})
+}
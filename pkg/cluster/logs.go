@@ -0,0 +1,201 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// LogsConfig is configuration for Logs.
+type LogsConfig struct {
+	App           app.App
+	ClientConfig  *client.Config
+	ComponentName string
+	EnvName       string
+	// Container is the container to show logs for. It defaults to the only
+	// container of each pod.
+	Container string
+	Follow    bool
+	// Since only shows logs newer than a duration, if it is set.
+	Since time.Duration
+	// Tail is the number of recent lines to show. All lines are shown if it
+	// is negative.
+	Tail int64
+	Out  io.Writer
+}
+
+// LogsOpts is an option for configuring Logs.
+type LogsOpts func(*Logs)
+
+// Logs shows the logs of the pods of a component's workload.
+type Logs struct {
+	LogsConfig
+
+	// these make it easier to test Logs.
+	findObjectsFn    findObjectsFn
+	genClientOptsFn  genClientOptsFn
+	podClientFactory podClientFactoryFn
+}
+
+// RunLogs shows the logs of the pods of a component's workload.
+func RunLogs(config LogsConfig, opts ...LogsOpts) error {
+	l := &Logs{
+		LogsConfig:       config,
+		findObjectsFn:    findObjects,
+		genClientOptsFn:  GenClients,
+		podClientFactory: newPodClient,
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l.Logs()
+}
+
+// Logs writes the logs of each pod. Lines are prefixed with the pod's name
+// if the workload has more than one pod. Logs of pods are streamed
+// concurrently if they are followed.
+func (l *Logs) Logs() error {
+	objects, err := l.findObjectsFn(l.App, l.EnvName, []string{l.ComponentName})
+	if err != nil {
+		return errors.Wrap(err, "find objects")
+	}
+
+	target, err := componentTarget(l.ComponentName, objects, false)
+	if err != nil {
+		return err
+	}
+
+	co, err := l.genClientOptsFn(l.App, l.ClientConfig, l.EnvName)
+	if err != nil {
+		return err
+	}
+
+	pc, err := l.podClientFactory(co)
+	if err != nil {
+		return err
+	}
+
+	namespace := target.GetNamespace()
+	if namespace == "" {
+		namespace = co.namespace
+	}
+
+	pods, err := targetPods(pc, namespace, target)
+	if err != nil {
+		return err
+	}
+
+	out := &lockedWriter{w: l.Out}
+
+	if !l.Follow {
+		for _, pod := range pods {
+			if err := l.podLogs(pc, pod, len(pods) > 1, out); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	errCh := make(chan error, len(pods))
+	for _, pod := range pods {
+		wg.Add(1)
+		go func(pod corev1.Pod) {
+			defer wg.Done()
+			errCh <- l.podLogs(pc, pod, len(pods) > 1, out)
+		}(pod)
+	}
+
+	wg.Wait()
+	close(errCh)
+
+	for err := range errCh {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (l *Logs) podLogs(pc podClient, pod corev1.Pod, prefix bool, out io.Writer) error {
+	opts := &corev1.PodLogOptions{
+		Container: l.Container,
+		Follow:    l.Follow,
+	}
+	if l.Tail >= 0 {
+		opts.TailLines = &l.Tail
+	}
+	if l.Since > 0 {
+		seconds := int64(l.Since.Seconds())
+		opts.SinceSeconds = &seconds
+	}
+
+	r, err := pc.Logs(pod.Namespace, pod.Name, opts)
+	if err != nil {
+		return errors.Wrapf(err, "retrieving logs of pod %s", pod.Name)
+	}
+	defer r.Close()
+
+	// Lines are read without a length limit, since containers can log long
+	// lines, e.g. JSON documents.
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if line != "" {
+			line = strings.TrimSuffix(line, "\n")
+			if prefix {
+				line = fmt.Sprintf("[%s] %s", pod.Name, line)
+			}
+			if _, writeErr := fmt.Fprintln(out, line); writeErr != nil {
+				return writeErr
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "reading logs of pod %s", pod.Name)
+		}
+	}
+}
+
+// lockedWriter serializes writes, so lines of concurrent streams are not
+// interleaved.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (lw *lockedWriter) Write(p []byte) (int, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	return lw.w.Write(p)
+}
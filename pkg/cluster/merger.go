@@ -24,7 +24,6 @@ import (
 
 	"github.com/jonboulle/clockwork"
 	"github.com/pkg/errors"
-	"github.com/sirupsen/logrus"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -156,7 +155,7 @@ func (p *defaultObjectMerger) Merge(namespace string, obj *unstructured.Unstruct
 
 	patchBytes, patchedObject, err := patcher.patch(info.Object, modified, info.Source, info.Namespace, info.Name, os.Stderr)
 	if err != nil {
-		logrus.Debug("applying patch:\n%s\nto:\n%v\nfor:\n", patchBytes, info)
+		log.Debugf("applying patch:\n%s\nto:\n%v\nfor:\n", patchBytes, info)
 		return nil, errors.Wrap(err, "path object")
 	}
 
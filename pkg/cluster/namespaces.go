@@ -0,0 +1,234 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/discovery"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+const (
+	// NamespacePolicyCreate creates the namespaces of objects which do not
+	// exist before the objects are applied.
+	NamespacePolicyCreate = "create"
+	// NamespacePolicyRequire fails an apply before any object is applied
+	// if the namespaces of objects do not exist.
+	NamespacePolicyRequire = "require"
+	// NamespacePolicyFail fails an apply before any object is applied if
+	// objects use namespaces other than the environment's.
+	NamespacePolicyFail = "fail"
+)
+
+// namespaceClient checks for and creates namespaces.
+type namespaceClient interface {
+	Exists(name string) (bool, error)
+	Create(name string, labels map[string]string) error
+}
+
+type namespaceClientFactoryFn func(Clients) (namespaceClient, error)
+
+// kubeNamespaceClient is a namespaceClient backed by the Kubernetes core API.
+type kubeNamespaceClient struct {
+	c corev1client.CoreV1Interface
+}
+
+var _ namespaceClient = (*kubeNamespaceClient)(nil)
+
+func newNamespaceClient(clients Clients) (namespaceClient, error) {
+	c, err := corev1client.NewForConfig(clients.config)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating core client")
+	}
+
+	return &kubeNamespaceClient{c: c}, nil
+}
+
+func (k *kubeNamespaceClient) Exists(name string) (bool, error) {
+	_, err := k.c.Namespaces().Get(name, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		return true, nil
+	case kerrors.IsNotFound(err):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+func (k *kubeNamespaceClient) Create(name string, labels map[string]string) error {
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+	}
+
+	_, err := k.c.Namespaces().Create(ns)
+	if kerrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+// namespaceReconciler applies an environment's namespace policy to the
+// objects it is about to apply.
+type namespaceReconciler struct {
+	policy *app.NamespacePolicyConfig
+	// defaultNamespace is the namespace of namespaced objects which do not
+	// set one.
+	defaultNamespace string
+	client           namespaceClient
+	// namespaced returns true if objects of an object's kind are namespaced.
+	namespaced func(obj *unstructured.Unstructured) bool
+	dryRun     bool
+}
+
+// validateNamespacePolicy returns an error if a namespace policy is invalid.
+func validateNamespacePolicy(policy *app.NamespacePolicyConfig) error {
+	switch policy.Policy {
+	case NamespacePolicyCreate, NamespacePolicyRequire, NamespacePolicyFail:
+		return nil
+	default:
+		return errors.Errorf("invalid namespace policy %q; valid policies are %s, %s, and %s",
+			policy.Policy, NamespacePolicyCreate, NamespacePolicyRequire, NamespacePolicyFail)
+	}
+}
+
+// Reconcile checks the namespaces objects use against the policy, and
+// creates the ones which are missing if the policy is create. Namespaces
+// the objects include are created with them, so they are not checked.
+func (r *namespaceReconciler) Reconcile(objects []*unstructured.Unstructured) error {
+	if err := validateNamespacePolicy(r.policy); err != nil {
+		return err
+	}
+
+	used, rendered := r.namespaces(objects)
+
+	if r.policy.Policy == NamespacePolicyFail {
+		var other []string
+		for _, ns := range used {
+			if ns != r.defaultNamespace && !rendered[ns] {
+				other = append(other, ns)
+			}
+		}
+
+		if len(other) > 0 {
+			return errors.Errorf("objects use namespaces other than the environment's namespace %s: %s",
+				r.defaultNamespace, strings.Join(other, ", "))
+		}
+		return nil
+	}
+
+	var missing []string
+	for _, ns := range used {
+		if rendered[ns] {
+			continue
+		}
+
+		exists, err := r.client.Exists(ns)
+		if err != nil {
+			return errors.Wrapf(err, "checking namespace %s", ns)
+		}
+		if !exists {
+			missing = append(missing, ns)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	if r.policy.Policy == NamespacePolicyRequire {
+		return errors.Errorf("namespaces do not exist: %s", strings.Join(missing, ", "))
+	}
+
+	for _, ns := range missing {
+		if r.dryRun {
+			log.Infof("creating namespace %s (dry-run)", ns)
+			continue
+		}
+
+		log.Infof("creating namespace %s", ns)
+		if err := r.client.Create(ns, r.policy.Labels); err != nil {
+			return errors.Wrapf(err, "creating namespace %s", ns)
+		}
+	}
+
+	return nil
+}
+
+// namespaces returns the sorted namespaces of namespaced objects, and the
+// names of the Namespace objects.
+func (r *namespaceReconciler) namespaces(objects []*unstructured.Unstructured) ([]string, map[string]bool) {
+	seen := make(map[string]bool)
+	rendered := make(map[string]bool)
+
+	for _, obj := range objects {
+		if obj.GetKind() == "Namespace" && obj.GroupVersionKind().Group == "" {
+			rendered[obj.GetName()] = true
+			continue
+		}
+
+		ns := obj.GetNamespace()
+		if ns == "" {
+			if !r.namespaced(obj) {
+				continue
+			}
+			ns = r.defaultNamespace
+		}
+
+		seen[ns] = true
+	}
+
+	var used []string
+	for ns := range seen {
+		used = append(used, ns)
+	}
+	sort.Strings(used)
+
+	return used, rendered
+}
+
+// discoveryNamespaced returns a function which looks up whether objects are
+// namespaced. Kinds the server does not know are assumed to be namespaced,
+// since most kinds are.
+func discoveryNamespaced(disco discovery.DiscoveryInterface) func(obj *unstructured.Unstructured) bool {
+	return func(obj *unstructured.Unstructured) bool {
+		gvk := obj.GroupVersionKind()
+
+		resources, err := disco.ServerResourcesForGroupVersion(gvk.GroupVersion().String())
+		if err != nil {
+			return true
+		}
+
+		for _, r := range resources.APIResources {
+			if r.Kind == gvk.Kind && !strings.Contains(r.Name, "/") {
+				return r.Namespaced
+			}
+		}
+
+		return true
+	}
+}
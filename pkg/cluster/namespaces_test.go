@@ -0,0 +1,254 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+type fakeNamespaceClient struct {
+	existing map[string]bool
+	created  map[string]map[string]string
+	err      error
+}
+
+var _ namespaceClient = (*fakeNamespaceClient)(nil)
+
+func newFakeNamespaceClient(existing ...string) *fakeNamespaceClient {
+	c := &fakeNamespaceClient{
+		existing: make(map[string]bool),
+		created:  make(map[string]map[string]string),
+	}
+	for _, ns := range existing {
+		c.existing[ns] = true
+	}
+	return c
+}
+
+func (c *fakeNamespaceClient) Exists(name string) (bool, error) {
+	return c.existing[name], c.err
+}
+
+func (c *fakeNamespaceClient) Create(name string, labels map[string]string) error {
+	c.created[name] = labels
+	c.existing[name] = true
+	return nil
+}
+
+func newNamespacedObject(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata":   map[string]interface{}{"name": name},
+	}}
+	if namespace != "" {
+		obj.SetNamespace(namespace)
+	}
+	return obj
+}
+
+func namespaceObjects() []*unstructured.Unstructured {
+	return []*unstructured.Unstructured{
+		newNamespacedObject("v1", "Namespace", "", "monitoring"),
+		newNamespacedObject("apps/v1", "Deployment", "", "guestbook"),
+		newNamespacedObject("v1", "Service", "web", "guestbook"),
+		newNamespacedObject("apps/v1", "Deployment", "monitoring", "prometheus"),
+		newNamespacedObject("rbac.authorization.k8s.io/v1", "ClusterRole", "", "prometheus"),
+	}
+}
+
+func clusterScoped(obj *unstructured.Unstructured) bool {
+	return obj.GetKind() != "ClusterRole"
+}
+
+func Test_namespaceReconciler_Reconcile(t *testing.T) {
+	labels := map[string]string{"team": "guestbook"}
+
+	cases := []struct {
+		name     string
+		policy   string
+		existing []string
+		dryRun   bool
+		created  map[string]map[string]string
+		isErr    bool
+	}{
+		{
+			name:     "create",
+			policy:   NamespacePolicyCreate,
+			existing: []string{"default"},
+			created:  map[string]map[string]string{"web": labels},
+		},
+		{
+			name:     "create when every namespace exists",
+			policy:   NamespacePolicyCreate,
+			existing: []string{"default", "web"},
+			created:  map[string]map[string]string{},
+		},
+		{
+			name:     "create with dry run",
+			policy:   NamespacePolicyCreate,
+			existing: []string{"default"},
+			dryRun:   true,
+			created:  map[string]map[string]string{},
+		},
+		{
+			name:     "require",
+			policy:   NamespacePolicyRequire,
+			existing: []string{"default", "web"},
+			created:  map[string]map[string]string{},
+		},
+		{
+			name:     "require a missing namespace",
+			policy:   NamespacePolicyRequire,
+			existing: []string{"default"},
+			isErr:    true,
+		},
+		{
+			name:   "fail with another namespace",
+			policy: NamespacePolicyFail,
+			isErr:  true,
+		},
+		{
+			name:   "invalid policy",
+			policy: "ignore",
+			isErr:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newFakeNamespaceClient(tc.existing...)
+
+			r := &namespaceReconciler{
+				policy:           &app.NamespacePolicyConfig{Policy: tc.policy, Labels: labels},
+				defaultNamespace: "default",
+				client:           c,
+				namespaced:       clusterScoped,
+				dryRun:           tc.dryRun,
+			}
+
+			err := r.Reconcile(namespaceObjects())
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.created, c.created)
+		})
+	}
+}
+
+func Test_namespaceReconciler_Reconcile_fail(t *testing.T) {
+	r := &namespaceReconciler{
+		policy:           &app.NamespacePolicyConfig{Policy: NamespacePolicyFail},
+		defaultNamespace: "default",
+		namespaced:       clusterScoped,
+	}
+
+	// Objects in the environment's namespace, and in namespaces the
+	// environment creates, are allowed.
+	objects := namespaceObjects()
+	objects = append(objects[:2], objects[3:]...)
+
+	require.NoError(t, r.Reconcile(objects))
+}
+
+func Test_namespaceReconciler_Reconcile_client_error(t *testing.T) {
+	c := newFakeNamespaceClient()
+	c.err = errors.New("forbidden")
+
+	r := &namespaceReconciler{
+		policy:           &app.NamespacePolicyConfig{Policy: NamespacePolicyRequire},
+		defaultNamespace: "default",
+		client:           c,
+		namespaced:       clusterScoped,
+	}
+
+	require.Error(t, r.Reconcile(namespaceObjects()))
+}
+
+func Test_discoveryNamespaced(t *testing.T) {
+	disco := &fakediscovery.FakeDiscovery{Fake: &ktesting.Fake{}}
+	disco.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "rbac.authorization.k8s.io/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "clusterroles", Kind: "ClusterRole", Namespaced: false},
+				{Name: "roles", Kind: "Role", Namespaced: true},
+			},
+		},
+	}
+
+	namespaced := discoveryNamespaced(disco)
+
+	assert.False(t, namespaced(newNamespacedObject("rbac.authorization.k8s.io/v1", "ClusterRole", "", "admin")))
+	assert.True(t, namespaced(newNamespacedObject("rbac.authorization.k8s.io/v1", "Role", "", "admin")))
+	assert.True(t, namespaced(newNamespacedObject("example.com/v1", "Widget", "", "widget")))
+}
+
+func Test_Apply_namespace_policy(t *testing.T) {
+	test.WithApp(t, "/app", func(a *amocks.App, fs afero.Fs) {
+		env := &app.EnvironmentConfig{
+			Namespaces: &app.NamespacePolicyConfig{Policy: NamespacePolicyRequire},
+		}
+		a.On("Environment", mock.Anything).Return(env, nil)
+
+		applyConfig := ApplyConfig{
+			App:          a,
+			ClientConfig: &client.Config{},
+		}
+
+		upserter := &fakeUpserter{upsertID: "12345"}
+
+		setupApp := func(apply *Apply) {
+			obj := &unstructured.Unstructured{Object: genObject()}
+			obj.SetNamespace("missing")
+
+			apply.clientOpts = &Clients{namespace: "default"}
+
+			apply.findObjectsFn = func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+				return []*unstructured.Unstructured{obj}, nil
+			}
+
+			apply.namespaceClientFactory = func(Clients) (namespaceClient, error) {
+				return newFakeNamespaceClient("default"), nil
+			}
+
+			apply.upserterFactory = func() Upserter {
+				return upserter
+			}
+		}
+
+		err := RunApply(applyConfig, setupApp)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "namespaces do not exist: missing")
+	})
+}
@@ -20,7 +20,7 @@ import (
 
 	clustermetadata "github.com/ksonnet/ksonnet/pkg/metadata"
 	"github.com/pkg/errors"
-	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -58,7 +58,7 @@ var unmanagedKinds = map[string]bool{
 
 // DefaultResourceInfo fetches objects from the cluster.
 func fetchManagedObjects(namespace string, clients Clients, components []string) ([]*unstructured.Unstructured, error) {
-	log := log.WithFields(log.Fields{
+	log := log.WithFields(logrus.Fields{
 		"action":    "fetchManagedObjects",
 		"namespace": namespace,
 	})
@@ -207,3 +207,19 @@ func CollectObjects(namespace string, clients Clients, components []string) ([]*
 
 	return objects, nil
 }
+
+// CollectLiveObjects collects objects in a cluster namespace as they are stored
+// by the server, with server managed fields removed.
+func CollectLiveObjects(namespace string, clients Clients, components []string) ([]*unstructured.Unstructured, error) {
+	objects, err := fetchManagedObjects(namespace, clients, components)
+	if err != nil {
+		return nil, err
+	}
+	objects = filterManagedObjects(objects)
+
+	for _, obj := range objects {
+		StripServerFields(obj)
+	}
+
+	return objects, nil
+}
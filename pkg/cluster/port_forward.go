@@ -0,0 +1,386 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/transport/spdy"
+)
+
+const (
+	// DefaultPortForwardAddress is the default address local ports listen on.
+	DefaultPortForwardAddress = "localhost"
+
+	// portForwardProtocol is the streaming protocol of the port forward API.
+	portForwardProtocol = "portforward.k8s.io"
+)
+
+// ForwardedPort is a local port which is forwarded to a port of a pod.
+type ForwardedPort struct {
+	Local  int
+	Remote int
+}
+
+// PortForwardConfig is configuration for PortForward.
+type PortForwardConfig struct {
+	App           app.App
+	ClientConfig  *client.Config
+	ComponentName string
+	EnvName       string
+	// Ports are ports to forward, as local:remote or port. Remote ports are
+	// ports of the component's service, if it has one, or of its pods. If
+	// there are no ports, every port of the service or pods is forwarded.
+	Ports []string
+	// Address is the address local ports listen on.
+	Address string
+	Out     io.Writer
+	// StopCh stops forwarding when it is closed.
+	StopCh <-chan struct{}
+}
+
+// PortForwardOpts is an option for configuring PortForward.
+type PortForwardOpts func(*PortForward)
+
+type forwardFn func(clients Clients, pod corev1.Pod, address string, ports []ForwardedPort, out io.Writer, stopCh <-chan struct{}) error
+
+// PortForward forwards local ports to a pod of a component's service or
+// workload.
+type PortForward struct {
+	PortForwardConfig
+
+	// these make it easier to test PortForward.
+	findObjectsFn    findObjectsFn
+	genClientOptsFn  genClientOptsFn
+	podClientFactory podClientFactoryFn
+	forwardFn        forwardFn
+}
+
+// RunPortForward forwards local ports to a pod of a component until
+// config.StopCh is closed.
+func RunPortForward(config PortForwardConfig, opts ...PortForwardOpts) error {
+	pf := &PortForward{
+		PortForwardConfig: config,
+		findObjectsFn:     findObjects,
+		genClientOptsFn:   GenClients,
+		podClientFactory:  newPodClient,
+		forwardFn:         forwardPorts,
+	}
+
+	for _, opt := range opts {
+		opt(pf)
+	}
+
+	return pf.PortForward()
+}
+
+// PortForward resolves the pod and ports to forward to, and forwards them.
+func (pf *PortForward) PortForward() error {
+	objects, err := pf.findObjectsFn(pf.App, pf.EnvName, []string{pf.ComponentName})
+	if err != nil {
+		return errors.Wrap(err, "find objects")
+	}
+
+	target, err := componentTarget(pf.ComponentName, objects, true)
+	if err != nil {
+		return err
+	}
+
+	co, err := pf.genClientOptsFn(pf.App, pf.ClientConfig, pf.EnvName)
+	if err != nil {
+		return err
+	}
+
+	pc, err := pf.podClientFactory(co)
+	if err != nil {
+		return err
+	}
+
+	namespace := target.GetNamespace()
+	if namespace == "" {
+		namespace = co.namespace
+	}
+
+	pods, err := targetPods(pc, namespace, target)
+	if err != nil {
+		return err
+	}
+
+	pod, err := runningPod(pods)
+	if err != nil {
+		return err
+	}
+
+	ports, err := forwardedPorts(target, pod, pf.Ports)
+	if err != nil {
+		return err
+	}
+
+	address := pf.Address
+	if address == "" {
+		address = DefaultPortForwardAddress
+	}
+
+	return pf.forwardFn(co, pod, address, ports, pf.Out, pf.StopCh)
+}
+
+// runningPod returns the first running pod.
+func runningPod(pods []corev1.Pod) (corev1.Pod, error) {
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodRunning && pod.DeletionTimestamp == nil {
+			return pod, nil
+		}
+	}
+
+	return corev1.Pod{}, errors.Errorf("none of the %d pod(s) are running", len(pods))
+}
+
+// forwardedPorts resolves the ports to forward to a pod. Ports of a service
+// are mapped to the ports of the pod they target.
+func forwardedPorts(target *unstructured.Unstructured, pod corev1.Pod, specs []string) ([]ForwardedPort, error) {
+	var sps []servicePort
+	if target.GetKind() == "Service" {
+		var err error
+		if sps, err = servicePorts(target); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(specs) == 0 {
+		if target.GetKind() == "Service" {
+			for _, sp := range sps {
+				specs = append(specs, strconv.Itoa(sp.port))
+			}
+		} else {
+			for _, c := range pod.Spec.Containers {
+				for _, p := range c.Ports {
+					specs = append(specs, strconv.Itoa(int(p.ContainerPort)))
+				}
+			}
+		}
+	}
+
+	if len(specs) == 0 {
+		return nil, errors.Errorf("%s %s does not expose any ports; specify the ports to forward", target.GetKind(), target.GetName())
+	}
+
+	var ports []ForwardedPort
+	for _, spec := range specs {
+		local, remote, err := parsePortSpec(spec)
+		if err != nil {
+			return nil, err
+		}
+
+		if target.GetKind() == "Service" {
+			if remote, err = podPort(sps, pod, remote); err != nil {
+				return nil, err
+			}
+		}
+
+		ports = append(ports, ForwardedPort{Local: local, Remote: remote})
+	}
+
+	return ports, nil
+}
+
+// parsePortSpec parses a port spec of the form local:remote or port.
+func parsePortSpec(spec string) (int, int, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) > 2 {
+		return 0, 0, errors.Errorf("invalid port %q; ports are local:remote or port", spec)
+	}
+
+	var ports []int
+	for _, part := range parts {
+		port, err := strconv.Atoi(part)
+		if err != nil || port <= 0 || port > 65535 {
+			return 0, 0, errors.Errorf("invalid port %q; ports are local:remote or port", spec)
+		}
+		ports = append(ports, port)
+	}
+
+	return ports[0], ports[len(ports)-1], nil
+}
+
+// podPort returns the port of a pod which a service port targets.
+func podPort(sps []servicePort, pod corev1.Pod, port int) (int, error) {
+	for _, sp := range sps {
+		if sp.port != port {
+			continue
+		}
+
+		if sp.targetPort.Type == intstr.String {
+			return containerPort(pod, sp.targetPort.StrVal)
+		}
+
+		return int(sp.targetPort.IntVal), nil
+	}
+
+	return 0, errors.Errorf("service does not have port %d", port)
+}
+
+// forwardPorts forwards local ports to a pod with the port forward API until
+// stopCh is closed. It is a forwardFn.
+func forwardPorts(clients Clients, pod corev1.Pod, address string, ports []ForwardedPort, out io.Writer, stopCh <-chan struct{}) error {
+	c, err := corev1client.NewForConfig(clients.config)
+	if err != nil {
+		return errors.Wrap(err, "creating core client")
+	}
+
+	transport, upgrader, err := spdy.RoundTripperFor(clients.config)
+	if err != nil {
+		return errors.Wrap(err, "creating port forward transport")
+	}
+
+	u := c.RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("portforward").
+		URL()
+
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, u)
+	conn, _, err := dialer.Dial(portForwardProtocol)
+	if err != nil {
+		return errors.Wrapf(err, "connecting to pod %s", pod.Name)
+	}
+	defer conn.Close()
+
+	f := &forwarder{conn: conn, pod: pod.Name}
+
+	var listeners []net.Listener
+	defer func() {
+		for _, l := range listeners {
+			l.Close()
+		}
+	}()
+
+	for _, port := range ports {
+		l, err := net.Listen("tcp", net.JoinHostPort(address, strconv.Itoa(port.Local)))
+		if err != nil {
+			return errors.Wrapf(err, "listening on port %d", port.Local)
+		}
+		listeners = append(listeners, l)
+
+		fmt.Fprintf(out, "Forwarding from %s -> %d\n", l.Addr(), port.Remote)
+		go f.serve(l, port.Remote)
+	}
+
+	select {
+	case <-stopCh:
+		return nil
+	case <-conn.CloseChan():
+		return errors.Errorf("lost connection to pod %s", pod.Name)
+	}
+}
+
+// forwarder forwards connections to ports of a pod over a streaming
+// connection.
+type forwarder struct {
+	conn      httpstream.Connection
+	pod       string
+	requestID int64
+}
+
+func (f *forwarder) serve(l net.Listener, remote int) {
+	for {
+		local, err := l.Accept()
+		if err != nil {
+			// The listener is closed when forwarding stops.
+			return
+		}
+
+		go func() {
+			if err := f.handle(local, remote); err != nil {
+				log.WithError(err).Errorf("forwarding to port %d of pod %s", remote, f.pod)
+			}
+		}()
+	}
+}
+
+// handle copies a local connection to and from a port of the pod. Each
+// connection uses a pair of error and data streams with the same request ID.
+func (f *forwarder) handle(local net.Conn, remote int) error {
+	defer local.Close()
+
+	requestID := atomic.AddInt64(&f.requestID, 1)
+
+	errorStream, err := f.conn.CreateStream(streamHeaders(corev1.StreamTypeError, remote, requestID))
+	if err != nil {
+		return errors.Wrap(err, "creating error stream")
+	}
+	// Nothing is written to the error stream.
+	errorStream.Close()
+
+	errCh := make(chan error, 1)
+	go func() {
+		b, err := ioutil.ReadAll(errorStream)
+		switch {
+		case err != nil:
+			errCh <- errors.Wrap(err, "reading error stream")
+		case len(b) > 0:
+			errCh <- errors.New(string(b))
+		}
+		close(errCh)
+	}()
+
+	dataStream, err := f.conn.CreateStream(streamHeaders(corev1.StreamTypeData, remote, requestID))
+	if err != nil {
+		return errors.Wrap(err, "creating data stream")
+	}
+
+	remoteDone := make(chan struct{})
+	go func() {
+		defer close(remoteDone)
+		io.Copy(local, dataStream)
+	}()
+	go func() {
+		io.Copy(dataStream, local)
+		// Tell the pod nothing more will be sent.
+		dataStream.Close()
+	}()
+
+	// The local connection is closed once the pod is done sending, which
+	// stops copying from it.
+	<-remoteDone
+
+	return <-errCh
+}
+
+// streamHeaders returns the headers of a port forward stream.
+func streamHeaders(streamType string, port int, requestID int64) http.Header {
+	headers := http.Header{}
+	headers.Set(corev1.StreamType, streamType)
+	headers.Set(corev1.PortHeader, strconv.Itoa(port))
+	headers.Set(corev1.PortForwardRequestIDHeader, strconv.FormatInt(requestID, 10))
+
+	return headers
+}
@@ -0,0 +1,177 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+)
+
+func TestPortForward(t *testing.T) {
+	pc := &fakePodClient{
+		pods: []corev1.Pod{
+			newPod("guestbook-a", map[string]string{"app": "guestbook"}, corev1.PodPending),
+			newPod("guestbook-b", map[string]string{"app": "guestbook"}, corev1.PodRunning),
+		},
+	}
+
+	findObjects := func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+		return guestbookObjects(), nil
+	}
+
+	cases := []struct {
+		name     string
+		ports    []string
+		expected []ForwardedPort
+		isErr    bool
+	}{
+		{
+			name:     "service ports",
+			expected: []ForwardedPort{{Local: 80, Remote: 8080}, {Local: 9090, Remote: 9090}},
+		},
+		{
+			name:     "local and service port",
+			ports:    []string{"8000:80"},
+			expected: []ForwardedPort{{Local: 8000, Remote: 8080}},
+		},
+		{
+			name:  "port the service does not have",
+			ports: []string{"8080"},
+			isErr: true,
+		},
+		{
+			name:  "invalid port",
+			ports: []string{"a:b:c"},
+			isErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := PortForwardConfig{
+				ComponentName: "guestbook",
+				EnvName:       "default",
+				Ports:         tc.ports,
+			}
+
+			var forwarded []ForwardedPort
+			err := RunPortForward(config, func(pf *PortForward) {
+				pf.findObjectsFn, pf.genClientOptsFn, pf.podClientFactory = withPods(findObjects, pc)
+				pf.forwardFn = func(clients Clients, pod corev1.Pod, address string, ports []ForwardedPort, out io.Writer, stopCh <-chan struct{}) error {
+					assert.Equal(t, "guestbook-b", pod.Name)
+					assert.Equal(t, DefaultPortForwardAddress, address)
+					forwarded = ports
+					return nil
+				}
+			})
+
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, forwarded)
+		})
+	}
+}
+
+func Test_forwardedPorts_workload(t *testing.T) {
+	deployment := guestbookObjects()[2]
+	pod := newPod("guestbook", nil, corev1.PodRunning)
+
+	ports, err := forwardedPorts(deployment, pod, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []ForwardedPort{{Local: 8080, Remote: 8080}}, ports)
+
+	ports, err = forwardedPorts(deployment, pod, []string{"9000:8080"})
+	require.NoError(t, err)
+	assert.Equal(t, []ForwardedPort{{Local: 9000, Remote: 8080}}, ports)
+}
+
+func Test_runningPod(t *testing.T) {
+	_, err := runningPod([]corev1.Pod{newPod("guestbook", nil, corev1.PodPending)})
+	require.Error(t, err)
+}
+
+// fakeStream is a stream whose reads come from a buffer, and whose writes are
+// recorded.
+type fakeStream struct {
+	headers http.Header
+	in      io.Reader
+	written bytes.Buffer
+}
+
+func (s *fakeStream) Read(p []byte) (int, error)  { return s.in.Read(p) }
+func (s *fakeStream) Write(p []byte) (int, error) { return s.written.Write(p) }
+func (s *fakeStream) Close() error                { return nil }
+func (s *fakeStream) Reset() error                { return nil }
+func (s *fakeStream) Headers() http.Header        { return s.headers }
+func (s *fakeStream) Identifier() uint32          { return 0 }
+
+// fakeConnection creates streams which reply with a canned response.
+type fakeConnection struct {
+	reply   string
+	streams []*fakeStream
+}
+
+func (c *fakeConnection) CreateStream(headers http.Header) (httpstream.Stream, error) {
+	s := &fakeStream{headers: headers, in: bytes.NewReader(nil)}
+	if headers.Get(corev1.StreamType) == corev1.StreamTypeData {
+		s.in = bytes.NewBufferString(c.reply)
+	}
+	c.streams = append(c.streams, s)
+	return s, nil
+}
+
+func (c *fakeConnection) Close() error                         { return nil }
+func (c *fakeConnection) CloseChan() <-chan bool               { return nil }
+func (c *fakeConnection) SetIdleTimeout(timeout time.Duration) {}
+
+func Test_forwarder_handle(t *testing.T) {
+	conn := &fakeConnection{reply: "pong"}
+	f := &forwarder{conn: conn, pod: "guestbook"}
+
+	local, client := net.Pipe()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- f.handle(local, 8080)
+	}()
+
+	reply, err := ioutil.ReadAll(client)
+	require.NoError(t, err)
+	assert.Equal(t, "pong", string(reply))
+	require.NoError(t, <-errCh)
+
+	require.Len(t, conn.streams, 2)
+	assert.Equal(t, corev1.StreamTypeError, conn.streams[0].headers.Get(corev1.StreamType))
+	assert.Equal(t, corev1.StreamTypeData, conn.streams[1].headers.Get(corev1.StreamType))
+	for _, s := range conn.streams {
+		assert.Equal(t, "8080", s.headers.Get(corev1.PortHeader))
+		assert.Equal(t, "1", s.headers.Get(corev1.PortForwardRequestIDHeader))
+	}
+}
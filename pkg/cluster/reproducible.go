@@ -0,0 +1,175 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	godiff "github.com/shazow/go-diff"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var (
+	// reproducibleZone is the timezone an app is rendered in again. It is
+	// far enough from any other timezone to change the date of timestamps.
+	reproducibleZone = time.FixedZone("UTC+14", 14*60*60)
+	// reproducibleTZ is the name of reproducibleZone, for commands run while
+	// rendering.
+	reproducibleTZ = "Pacific/Kiritimati"
+	// reproducibleLocale is the locale an app is rendered in again. Turkish
+	// has unusual case mappings, so it changes the output of tools which
+	// change case by locale.
+	reproducibleLocale = "tr_TR.UTF-8"
+)
+
+// verifyReproducible renders the objects of the environment again, in a
+// different timezone and locale, and returns an error describing the objects
+// which differ from objects.
+func (s *Show) verifyReproducible(objects []*unstructured.Unstructured) error {
+	var again []*unstructured.Unstructured
+	err := s.elsewhereFn(func() error {
+		var err error
+		again, err = s.findObjectsFn(s.App, s.EnvName, s.ComponentNames)
+		return err
+	})
+	if err != nil {
+		return errors.Wrap(err, "rendering again")
+	}
+
+	report, n, err := compareRenderings(objects, again)
+	if err != nil {
+		return err
+	}
+
+	if n > 0 {
+		return errors.Errorf("rendering environment %s is not reproducible; %d object(s) differ between renderings:\n%s",
+			s.EnvName, n, report)
+	}
+
+	return nil
+}
+
+// compareRenderings returns a report of the objects which differ between two
+// renderings, and how many there are.
+func compareRenderings(first, second []*unstructured.Unstructured) (string, int, error) {
+	a, err := renderedByID(first)
+	if err != nil {
+		return "", 0, err
+	}
+
+	b, err := renderedByID(second)
+	if err != nil {
+		return "", 0, err
+	}
+
+	ids := make(map[string]bool)
+	for id := range a {
+		ids[id] = true
+	}
+	for id := range b {
+		ids[id] = true
+	}
+
+	var sorted []string
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Strings(sorted)
+
+	var buf bytes.Buffer
+	n := 0
+	for _, id := range sorted {
+		x, inFirst := a[id]
+		y, inSecond := b[id]
+
+		switch {
+		case !inSecond:
+			fmt.Fprintf(&buf, "%s: only in the first rendering\n", id)
+		case !inFirst:
+			fmt.Fprintf(&buf, "%s: only in the second rendering\n", id)
+		case !bytes.Equal(x, y):
+			fmt.Fprintf(&buf, "%s:\n", id)
+			if err := godiff.DefaultDiffer().Diff(&buf, bytes.NewReader(x), bytes.NewReader(y)); err != nil {
+				return "", 0, err
+			}
+		default:
+			continue
+		}
+
+		n++
+	}
+
+	return buf.String(), n, nil
+}
+
+// renderedByID returns the YAML of objects by their ID.
+func renderedByID(objects []*unstructured.Unstructured) (map[string][]byte, error) {
+	m := make(map[string][]byte)
+	for _, obj := range objects {
+		id := objectID(obj)
+
+		b, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return nil, err
+		}
+
+		// Objects rendered more than once are compared together.
+		m[id] = append(m[id], b...)
+	}
+
+	return m, nil
+}
+
+func objectID(obj *unstructured.Unstructured) string {
+	name := obj.GetName()
+	if ns := obj.GetNamespace(); ns != "" {
+		name = ns + "/" + name
+	}
+
+	return strings.Join([]string{obj.GetAPIVersion(), obj.GetKind(), name}, " ")
+}
+
+// elsewhere runs fn with the local timezone, and the timezone and locale of
+// the environment, changed.
+func elsewhere(fn func() error) error {
+	vars := map[string]string{
+		"TZ":     reproducibleTZ,
+		"LANG":   reproducibleLocale,
+		"LC_ALL": reproducibleLocale,
+	}
+
+	for k, v := range vars {
+		if old, ok := os.LookupEnv(k); ok {
+			defer os.Setenv(k, old)
+		} else {
+			defer os.Unsetenv(k)
+		}
+		os.Setenv(k, v)
+	}
+
+	local := time.Local
+	time.Local = reproducibleZone
+	defer func() { time.Local = local }()
+
+	return fn()
+}
@@ -0,0 +1,163 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/redact"
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func reproducibleObject(kind, name string, spec interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       kind,
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": "default",
+		},
+		"spec": spec,
+	}}
+}
+
+func TestShow_verifyReproducible(t *testing.T) {
+	cases := []struct {
+		name     string
+		second   []*unstructured.Unstructured
+		expected string
+		isErr    bool
+	}{
+		{
+			name:     "reproducible",
+			second:   []*unstructured.Unstructured{reproducibleObject("ConfigMap", "config", "a")},
+			expected: "---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n  namespace: default\nspec: a\n",
+		},
+		{
+			name:   "changed",
+			second: []*unstructured.Unstructured{reproducibleObject("ConfigMap", "config", "b")},
+			isErr:  true,
+		},
+		{
+			name: "added",
+			second: []*unstructured.Unstructured{
+				reproducibleObject("ConfigMap", "config", "a"),
+				reproducibleObject("ConfigMap", "other", "a"),
+			},
+			isErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			test.WithApp(t, "/", func(appMock *mocks.App, fs afero.Fs) {
+				var buf bytes.Buffer
+
+				config := ShowConfig{
+					App:                appMock,
+					EnvName:            "default",
+					Out:                &buf,
+					Format:             "yaml",
+					VerifyReproducible: true,
+				}
+
+				renderings := [][]*unstructured.Unstructured{
+					{reproducibleObject("ConfigMap", "config", "a")},
+					tc.second,
+				}
+
+				elsewhereCalled := false
+				opt := func(s *Show) {
+					s.findObjectsFn = func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+						objects := renderings[0]
+						renderings = renderings[1:]
+						return objects, nil
+					}
+					s.redactorFn = func(a app.App, envName string) (*redact.Redactor, error) {
+						return redact.New(nil)
+					}
+					s.elsewhereFn = func(fn func() error) error {
+						elsewhereCalled = true
+						return fn()
+					}
+				}
+
+				err := RunShow(config, opt)
+				assert.True(t, elsewhereCalled)
+				assert.Empty(t, renderings)
+
+				if tc.isErr {
+					require.Error(t, err)
+					assert.Empty(t, buf.String())
+					return
+				}
+
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, buf.String())
+			})
+		})
+	}
+}
+
+func Test_compareRenderings(t *testing.T) {
+	first := []*unstructured.Unstructured{
+		reproducibleObject("ConfigMap", "same", "a"),
+		reproducibleObject("ConfigMap", "changed", "a"),
+		reproducibleObject("ConfigMap", "removed", "a"),
+	}
+	second := []*unstructured.Unstructured{
+		reproducibleObject("ConfigMap", "same", "a"),
+		reproducibleObject("ConfigMap", "changed", "b"),
+		reproducibleObject("ConfigMap", "added", "a"),
+	}
+
+	report, n, err := compareRenderings(first, second)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, n)
+	assert.Contains(t, report, "v1 ConfigMap default/added: only in the second rendering\n")
+	assert.Contains(t, report, "v1 ConfigMap default/removed: only in the first rendering\n")
+	assert.Contains(t, report, "v1 ConfigMap default/changed:\n")
+	assert.Contains(t, report, "-spec: a\n+spec: b\n")
+	assert.NotContains(t, report, "default/same")
+}
+
+func Test_elsewhere(t *testing.T) {
+	tz, hadTZ := os.LookupEnv("TZ")
+	local := time.Local
+
+	err := elsewhere(func() error {
+		assert.Equal(t, reproducibleTZ, os.Getenv("TZ"))
+		assert.Equal(t, reproducibleLocale, os.Getenv("LC_ALL"))
+		assert.Equal(t, reproducibleZone, time.Local)
+		return nil
+	})
+	require.NoError(t, err)
+
+	after, hasTZ := os.LookupEnv("TZ")
+	assert.Equal(t, hadTZ, hasTZ)
+	assert.Equal(t, tz, after)
+	assert.Equal(t, local, time.Local)
+}
@@ -0,0 +1,193 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"encoding/json"
+
+	clustermetadata "github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// dryRunAll is the value of the dryRun query parameter which asks the
+	// API server to run all request stages without persisting the result.
+	dryRunAll = "All"
+)
+
+// ServerDryRunner predicts how the API server will persist an object.
+type ServerDryRunner interface {
+	// DryRun submits an object to the server without persisting it, and returns
+	// the object as the server would have stored it.
+	DryRun(obj *unstructured.Unstructured) (*unstructured.Unstructured, error)
+}
+
+type restClientFactoryFn func(config *rest.Config, gv schema.GroupVersion) (rest.Interface, error)
+
+type defaultServerDryRunner struct {
+	clients           Clients
+	restClientFactory restClientFactoryFn
+}
+
+var _ ServerDryRunner = (*defaultServerDryRunner)(nil)
+
+// NewServerDryRunner creates a ServerDryRunner for a set of clients.
+func NewServerDryRunner(clients Clients) (ServerDryRunner, error) {
+	if clients.config == nil {
+		return nil, errors.New("server dry-run requires a rest config")
+	}
+
+	return &defaultServerDryRunner{
+		clients:           clients,
+		restClientFactory: dryRunRESTClient,
+	}, nil
+}
+
+// DryRun patches an existing object, or creates a missing object, with dryRun=All.
+func (d *defaultServerDryRunner) DryRun(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	gvk := obj.GroupVersionKind()
+
+	resource, err := serverResource(d.clients, gvk)
+	if err != nil {
+		return nil, err
+	}
+
+	rc, err := d.restClientFactory(d.clients.config, gvk.GroupVersion())
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating rest client for %s", gvk)
+	}
+
+	namespace := obj.GetNamespace()
+	if namespace == "" {
+		namespace = d.clients.namespace
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("server dry-run of %s %s", gvk, obj.GetName())
+
+	result := &unstructured.Unstructured{}
+	err = rc.Patch(types.MergePatchType).
+		NamespaceIfScoped(namespace, resource.Namespaced).
+		Resource(resource.Name).
+		Name(obj.GetName()).
+		Param("dryRun", dryRunAll).
+		Body(data).
+		Do().
+		Into(result)
+	if err == nil {
+		return result, nil
+	} else if !kerrors.IsNotFound(err) {
+		return nil, errors.Wrapf(err, "dry-run patch of %s %s", gvk.Kind, obj.GetName())
+	}
+
+	err = rc.Post().
+		NamespaceIfScoped(namespace, resource.Namespaced).
+		Resource(resource.Name).
+		Param("dryRun", dryRunAll).
+		Body(data).
+		Do().
+		Into(result)
+	if err != nil {
+		return nil, errors.Wrapf(err, "dry-run create of %s %s", gvk.Kind, obj.GetName())
+	}
+
+	return result, nil
+}
+
+// ServerDryRunObjects runs a server dry-run for each object and returns the
+// predicted objects with server managed fields removed.
+func ServerDryRunObjects(dryRunner ServerDryRunner, objects []*unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	var predicted []*unstructured.Unstructured
+	for _, obj := range objects {
+		result, err := dryRunner.DryRun(obj)
+		if err != nil {
+			return nil, err
+		}
+
+		StripServerFields(result)
+		predicted = append(predicted, result)
+	}
+
+	return predicted, nil
+}
+
+// StripServerFields removes fields that are set by the server and change
+// without user intervention, so they do not show up as differences.
+func StripServerFields(obj *unstructured.Unstructured) {
+	for _, field := range []string{"resourceVersion", "uid", "selfLink", "creationTimestamp", "generation", "managedFields"} {
+		unstructured.RemoveNestedField(obj.Object, "metadata", field)
+	}
+	unstructured.RemoveNestedField(obj.Object, "status")
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		return
+	}
+
+	delete(annotations, clustermetadata.AnnotationManaged)
+	delete(annotations, clustermetadata.AnnotationLastApplied)
+	if len(annotations) == 0 {
+		unstructured.RemoveNestedField(obj.Object, "metadata", "annotations")
+		return
+	}
+	obj.SetAnnotations(annotations)
+}
+
+func serverResource(clients Clients, gvk schema.GroupVersionKind) (*metav1.APIResource, error) {
+	if clients.discovery == nil {
+		return nil, errors.New("nil discovery client")
+	}
+
+	resources, err := clients.discovery.ServerResourcesForGroupVersion(gvk.GroupVersion().String())
+	if err != nil {
+		return nil, errors.Wrapf(err, "finding resources for %s", gvk.GroupVersion())
+	}
+
+	for _, r := range resources.APIResources {
+		if r.Kind == gvk.Kind {
+			r := r
+			return &r, nil
+		}
+	}
+
+	return nil, errors.Errorf("server is unable to handle %s", gvk)
+}
+
+func dryRunRESTClient(config *rest.Config, gv schema.GroupVersion) (rest.Interface, error) {
+	conf := *config
+	conf.ContentConfig = dynamic.ContentConfig()
+	conf.GroupVersion = &gv
+	conf.APIPath = "/apis"
+	if gv.Group == "" {
+		conf.APIPath = "/api"
+	}
+	if conf.UserAgent == "" {
+		conf.UserAgent = rest.DefaultKubernetesUserAgent()
+	}
+
+	return rest.RESTClientFor(&conf)
+}
@@ -0,0 +1,169 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/rest"
+	ktesting "k8s.io/client-go/testing"
+)
+
+func Test_defaultServerDryRunner_DryRun(t *testing.T) {
+	cases := []struct {
+		name         string
+		patchStatus  int
+		patchReason  metav1.StatusReason
+		expectedVerb []string
+		isErr        bool
+	}{
+		{
+			name:         "existing object is patched",
+			patchStatus:  http.StatusOK,
+			expectedVerb: []string{"PATCH"},
+		},
+		{
+			name:         "missing object is created",
+			patchStatus:  http.StatusNotFound,
+			patchReason:  metav1.StatusReasonNotFound,
+			expectedVerb: []string{"PATCH", "POST"},
+		},
+		{
+			name:         "admission rejected",
+			patchStatus:  http.StatusForbidden,
+			patchReason:  metav1.StatusReasonForbidden,
+			expectedVerb: []string{"PATCH"},
+			isErr:        true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var verbs []string
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				verbs = append(verbs, r.Method)
+				assert.Equal(t, "All", r.URL.Query().Get("dryRun"))
+
+				w.Header().Set("Content-Type", "application/json")
+				if r.Method == "PATCH" && tc.patchStatus != http.StatusOK {
+					w.WriteHeader(tc.patchStatus)
+					json.NewEncoder(w).Encode(metav1.Status{
+						TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"},
+						Status:   metav1.StatusFailure,
+						Code:     int32(tc.patchStatus),
+						Reason:   tc.patchReason,
+						Message:  http.StatusText(tc.patchStatus),
+					})
+					return
+				}
+
+				if r.Method == "PATCH" {
+					assert.Equal(t, "/api/v1/namespaces/default/configmaps/cm", r.URL.Path)
+				} else {
+					assert.Equal(t, "/api/v1/namespaces/default/configmaps", r.URL.Path)
+				}
+
+				body, err := ioutil.ReadAll(r.Body)
+				require.NoError(t, err)
+
+				var m map[string]interface{}
+				require.NoError(t, json.Unmarshal(body, &m))
+				m["metadata"].(map[string]interface{})["uid"] = "12345"
+				json.NewEncoder(w).Encode(m)
+			}))
+			defer srv.Close()
+
+			disco := &fakediscovery.FakeDiscovery{Fake: &ktesting.Fake{}}
+			disco.Resources = []*metav1.APIResourceList{
+				{
+					GroupVersion: "v1",
+					APIResources: []metav1.APIResource{
+						{Name: "configmaps", Kind: "ConfigMap", Namespaced: true},
+					},
+				},
+			}
+
+			clients := Clients{
+				discovery: disco,
+				namespace: "default",
+				config:    &rest.Config{Host: srv.URL},
+			}
+
+			dryRunner, err := NewServerDryRunner(clients)
+			require.NoError(t, err)
+
+			obj := &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]interface{}{"name": "cm"},
+			}}
+
+			result, err := dryRunner.DryRun(obj)
+			assert.Equal(t, tc.expectedVerb, verbs)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, "12345", string(result.GetUID()))
+		})
+	}
+}
+
+func Test_NewServerDryRunner_requires_config(t *testing.T) {
+	_, err := NewServerDryRunner(Clients{})
+	require.Error(t, err)
+}
+
+func TestStripServerFields(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":              "cm",
+			"uid":               "12345",
+			"resourceVersion":   "1",
+			"creationTimestamp": "2018-01-01T00:00:00Z",
+			"annotations": map[string]interface{}{
+				"ksonnet.io/managed": "{}",
+			},
+		},
+		"status": map[string]interface{}{},
+	}}
+
+	StripServerFields(obj)
+
+	expected := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name": "cm",
+		},
+	}
+
+	assert.Equal(t, expected, obj.Object)
+}
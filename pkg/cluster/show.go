@@ -22,6 +22,7 @@ import (
 
 	"github.com/ghodss/yaml"
 	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/redact"
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
@@ -32,7 +33,13 @@ type ShowConfig struct {
 	ComponentNames []string
 	EnvName        string
 	Format         string
-	Out            io.Writer
+	// ShowSecrets shows the data of Secrets, and the fields selected by the
+	// environment's redact rules, instead of redacting them.
+	ShowSecrets bool
+	// VerifyReproducible renders the environment again, in a different
+	// timezone and locale, and returns an error if the objects differ.
+	VerifyReproducible bool
+	Out                io.Writer
 }
 
 // ShowOpts is an option for configuring Show.
@@ -44,6 +51,8 @@ type Show struct {
 
 	// these make it easier to test Show.
 	findObjectsFn findObjectsFn
+	redactorFn    func(a app.App, envName string) (*redact.Redactor, error)
+	elsewhereFn   func(fn func() error) error
 }
 
 // RunShow shows objects for a given configuration.
@@ -51,6 +60,8 @@ func RunShow(config ShowConfig, opts ...ShowOpts) error {
 	s := &Show{
 		ShowConfig:    config,
 		findObjectsFn: findObjects,
+		redactorFn:    defaultRedactor,
+		elsewhereFn:   elsewhere,
 	}
 
 	for _, opt := range opts {
@@ -67,10 +78,24 @@ func (s *Show) Show() error {
 		return errors.Wrap(err, "find objects")
 	}
 
+	if s.VerifyReproducible {
+		if err := s.verifyReproducible(apiObjects); err != nil {
+			return err
+		}
+	}
+
 	sorted := make([]*unstructured.Unstructured, len(apiObjects))
 	copy(sorted, apiObjects)
 	UnstructuredSlice(sorted).Sort()
 
+	if !s.ShowSecrets {
+		redactor, err := s.redactorFn(s.App, s.EnvName)
+		if err != nil {
+			return err
+		}
+		sorted = redactor.Objects(sorted)
+	}
+
 	switch s.Format {
 	case "yaml":
 		return s.showYAML(sorted)
@@ -81,6 +106,10 @@ func (s *Show) Show() error {
 	}
 }
 
+func defaultRedactor(a app.App, envName string) (*redact.Redactor, error) {
+	return redact.ForEnvironment(a, envName)
+}
+
 func (s *Show) showYAML(apiObjects []*unstructured.Unstructured) error {
 	return ShowYAML(s.Out, apiObjects)
 }
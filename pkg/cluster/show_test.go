@@ -21,6 +21,7 @@ import (
 
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/redact"
 	"github.com/ksonnet/ksonnet/pkg/util/test"
 	"github.com/pkg/errors"
 	"github.com/spf13/afero"
@@ -37,6 +38,16 @@ func TestShow(t *testing.T) {
 		}, nil
 	}
 
+	secretObjects := func() ([]*unstructured.Unstructured, error) {
+		return []*unstructured.Unstructured{
+			{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Secret",
+				"data":       map[string]interface{}{"password": "c2VjcmV0"},
+			}},
+		}, nil
+	}
+
 	errObjects := func() ([]*unstructured.Unstructured, error) {
 		return nil, errors.New("fail")
 	}
@@ -46,6 +57,7 @@ func TestShow(t *testing.T) {
 		format      string
 		expected    string
 		findObjects func() ([]*unstructured.Unstructured, error)
+		showSecrets bool
 		isErr       bool
 	}{
 		{
@@ -60,6 +72,19 @@ func TestShow(t *testing.T) {
 			expected:    "{\n  \"apiVersion\": \"v1\",\n  \"items\": [\n    {\n      \"kind\": \"a\"\n    },\n    {\n      \"kind\": \"b\"\n    }\n  ],\n  \"kind\": \"List\"\n}\n",
 			findObjects: dummyObjects,
 		},
+		{
+			name:        "redact secrets",
+			format:      "yaml",
+			expected:    "---\napiVersion: v1\ndata:\n  password: <redacted:e07a3d55>\nkind: Secret\n",
+			findObjects: secretObjects,
+		},
+		{
+			name:        "show secrets",
+			format:      "yaml",
+			expected:    "---\napiVersion: v1\ndata:\n  password: c2VjcmV0\nkind: Secret\n",
+			findObjects: secretObjects,
+			showSecrets: true,
+		},
 		{
 			name:        "unknown format",
 			format:      "xml",
@@ -79,10 +104,11 @@ func TestShow(t *testing.T) {
 				var buf bytes.Buffer
 
 				config := ShowConfig{
-					App:     appMock,
-					EnvName: "default",
-					Out:     &buf,
-					Format:  tc.format,
+					App:         appMock,
+					EnvName:     "default",
+					Out:         &buf,
+					Format:      tc.format,
+					ShowSecrets: tc.showSecrets,
 				}
 
 				fn := func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
@@ -92,6 +118,9 @@ func TestShow(t *testing.T) {
 
 				findOpt := func(s *Show) {
 					s.findObjectsFn = fn
+					s.redactorFn = func(a app.App, envName string) (*redact.Redactor, error) {
+						return redact.New(nil, redact.Key([]byte("key")))
+					}
 				}
 
 				err := RunShow(config, findOpt)
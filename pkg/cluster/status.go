@@ -0,0 +1,216 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/ksonnet/ksonnet/utils"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ResourceStatus is the live status of an object managed by an environment.
+type ResourceStatus struct {
+	Component string
+	Kind      string
+	Namespace string
+	Name      string
+	// Exists is true if the object exists in the cluster.
+	Exists bool
+	// Ready is true if the object exists and is ready. Objects of kinds
+	// without readiness rules are ready once they exist.
+	Ready bool
+	// Drifted is true if the object in the cluster was last applied from a
+	// manifest which differs from the current one, or was not applied by ksonnet.
+	Drifted bool
+	// Message explains why an object is not ready.
+	Message string
+}
+
+// StatusConfig is configuration for Status.
+type StatusConfig struct {
+	App            app.App
+	ClientConfig   *client.Config
+	ComponentNames []string
+	EnvName        string
+}
+
+// StatusOpts is an option for configuring Status.
+type StatusOpts func(*Status)
+
+// Status reports the live status of the objects managed by an environment.
+type Status struct {
+	StatusConfig
+
+	// these make it easier to test Status.
+	findObjectsFn         findObjectsFn
+	genClientOptsFn       genClientOptsFn
+	resourceClientFactory resourceClientFactoryFn
+}
+
+// RunStatus returns the status of the objects managed by an environment.
+func RunStatus(config StatusConfig, opts ...StatusOpts) ([]ResourceStatus, error) {
+	s := &Status{
+		StatusConfig:          config,
+		findObjectsFn:         findObjects,
+		genClientOptsFn:       GenClients,
+		resourceClientFactory: resourceClientFactory,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s.Status()
+}
+
+// Status returns the status of each object rendered for the environment, in
+// the order they are applied.
+func (s *Status) Status() ([]ResourceStatus, error) {
+	apiObjects, err := s.findObjectsFn(s.App, s.EnvName, s.ComponentNames)
+	if err != nil {
+		return nil, errors.Wrap(err, "find objects")
+	}
+
+	co, err := s.genClientOptsFn(s.App, s.ClientConfig, s.EnvName)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Sort(utils.DependencyOrder(apiObjects))
+
+	statuses := make([]ResourceStatus, 0, len(apiObjects))
+	for _, obj := range apiObjects {
+		status, err := s.objectStatus(co, obj)
+		if err != nil {
+			return nil, err
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+func (s *Status) objectStatus(co Clients, obj *unstructured.Unstructured) (ResourceStatus, error) {
+	status := ResourceStatus{
+		Component: obj.GetLabels()[metadata.LabelComponent],
+		Kind:      obj.GetKind(),
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+	}
+
+	rc, err := s.resourceClientFactory(co, obj)
+	if err != nil {
+		return status, err
+	}
+
+	live, err := rc.Get(metav1.GetOptions{})
+	if kerrors.IsNotFound(errors.Cause(err)) {
+		status.Message = "not found"
+		return status, nil
+	} else if err != nil {
+		return status, errors.Wrapf(err, "getting %s %s", obj.GetKind(), obj.GetName())
+	}
+
+	status.Exists = true
+	if status.Namespace == "" {
+		status.Namespace = live.GetNamespace()
+	}
+
+	status.Ready = true
+	if readyFn, ok := readinessChecks[obj.GetKind()]; ok {
+		status.Ready, err = readyFn(live)
+		if err != nil {
+			status.Ready = false
+			status.Message = err.Error()
+		} else if !status.Ready {
+			status.Message = "not ready"
+		}
+	}
+
+	status.Drifted, err = drifted(obj, live)
+	if err != nil {
+		return status, errors.Wrapf(err, "comparing %s %s", obj.GetKind(), obj.GetName())
+	}
+
+	return status, nil
+}
+
+// drifted reports if the live object was last applied from a manifest other
+// than the rendered one. The manifest ksonnet last applied is kept in the
+// object's managed annotation.
+func drifted(rendered, live *unstructured.Unstructured) (bool, error) {
+	data, ok := live.GetAnnotations()[metadata.AnnotationManaged]
+	if !ok {
+		return true, nil
+	}
+
+	var annotation managedAnnotation
+	if err := json.Unmarshal([]byte(data), &annotation); err != nil {
+		return false, errors.Wrap(err, "decoding ksonnet managed annotation")
+	}
+
+	pristine, err := annotation.Decode()
+	if err != nil {
+		return false, err
+	}
+
+	return !reflect.DeepEqual(driftFields(rendered.Object), driftFields(pristine)), nil
+}
+
+// driftFields returns a copy of an object without the metadata which ksonnet
+// sets when it applies the object.
+func driftFields(m map[string]interface{}) map[string]interface{} {
+	// Round trip through JSON so numbers compare the same way in rendered
+	// and decoded objects.
+	b, err := json.Marshal(m)
+	if err != nil {
+		return m
+	}
+
+	var obj map[string]interface{}
+	if err = json.Unmarshal(b, &obj); err != nil {
+		return m
+	}
+
+	removeMetadata(obj, "labels",
+		metadata.LabelDeployManager, metadata.LabelApplication, metadata.LabelEnvironment)
+	removeMetadata(obj, "annotations",
+		metadata.AnnotationManaged, metadata.AnnotationLastApplied, metadata.AnnotationGcTag)
+
+	return obj
+}
+
+// removeMetadata removes keys from an object's labels or annotations. The
+// field is removed once it is empty.
+func removeMetadata(obj map[string]interface{}, field string, keys ...string) {
+	for _, k := range keys {
+		unstructured.RemoveNestedField(obj, "metadata", field, k)
+	}
+
+	if m, ok, _ := unstructured.NestedMap(obj, "metadata", field); ok && len(m) == 0 {
+		unstructured.RemoveNestedField(obj, "metadata", field)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/cluster/mocks"
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// applied returns a copy of an object as it exists in the cluster after
+// ksonnet applied a manifest.
+func applied(t *testing.T, obj, manifest *unstructured.Unstructured) *unstructured.Unstructured {
+	pristine := manifest.DeepCopy()
+	require.NoError(t, newDefaultAnnotationApplier().SetOriginalConfiguration(pristine))
+
+	live := obj.DeepCopy()
+	SetMetaDataAnnotation(live, metadata.AnnotationManaged, pristine.GetAnnotations()[metadata.AnnotationManaged])
+	SetMetaDataLabel(live, metadata.LabelDeployManager, appKsonnet)
+	return live
+}
+
+func TestStatus(t *testing.T) {
+	test.WithApp(t, "/app", func(a *amocks.App, fs afero.Fs) {
+		service := newDeleteObject("v1", "Service", "default", "guestbook")
+		service.SetLabels(map[string]string{metadata.LabelComponent: "guestbook"})
+
+		deployment := newDeleteObject("apps/v1", "Deployment", "default", "guestbook")
+		deployment.SetLabels(map[string]string{metadata.LabelComponent: "guestbook"})
+		deployment.Object["spec"] = map[string]interface{}{"replicas": int64(2)}
+
+		redis := newDeleteObject("apps/v1", "Deployment", "default", "redis")
+
+		oldDeployment := deployment.DeepCopy()
+		oldDeployment.Object["spec"] = map[string]interface{}{"replicas": int64(1)}
+
+		liveDeployment := applied(t, deployment, oldDeployment)
+		liveDeployment.SetGeneration(1)
+		liveDeployment.Object["status"] = map[string]interface{}{
+			"observedGeneration": int64(1),
+			"updatedReplicas":    int64(1),
+			"availableReplicas":  int64(1),
+		}
+
+		live := map[string]*unstructured.Unstructured{
+			"Service/guestbook":    applied(t, service, service),
+			"Deployment/guestbook": liveDeployment,
+		}
+
+		factory := func(_ Clients, o runtime.Object) (ResourceClient, error) {
+			obj := o.(*unstructured.Unstructured)
+			key := obj.GetKind() + "/" + obj.GetName()
+
+			rc := &mocks.ResourceClient{}
+			rc.On("Get", mock.Anything).Return(
+				func(metav1.GetOptions) *unstructured.Unstructured {
+					return live[key]
+				},
+				func(metav1.GetOptions) error {
+					if _, ok := live[key]; !ok {
+						return &notFoundError{}
+					}
+					return nil
+				},
+			)
+			return rc, nil
+		}
+
+		setup := func(s *Status) {
+			s.findObjectsFn = func(app.App, string, []string) ([]*unstructured.Unstructured, error) {
+				return []*unstructured.Unstructured{deployment, service, redis}, nil
+			}
+			s.genClientOptsFn = func(app.App, *client.Config, string) (Clients, error) {
+				return Clients{}, nil
+			}
+			s.resourceClientFactory = factory
+		}
+
+		config := StatusConfig{
+			App:          a,
+			ClientConfig: &client.Config{},
+			EnvName:      "default",
+		}
+
+		statuses, err := RunStatus(config, setup)
+		require.NoError(t, err)
+
+		expected := []ResourceStatus{
+			{Component: "guestbook", Kind: "Service", Namespace: "default", Name: "guestbook", Exists: true, Ready: true},
+			{Component: "guestbook", Kind: "Deployment", Namespace: "default", Name: "guestbook", Exists: true, Drifted: true, Message: "not ready"},
+			{Kind: "Deployment", Namespace: "default", Name: "redis", Message: "not found"},
+		}
+		assert.Equal(t, expected, statuses)
+	})
+}
+
+func Test_drifted(t *testing.T) {
+	manifest := newDeleteObject("v1", "ConfigMap", "default", "config")
+	manifest.Object["data"] = map[string]interface{}{"key": "value"}
+
+	changed := manifest.DeepCopy()
+	changed.Object["data"] = map[string]interface{}{"key": "other"}
+
+	labeled := manifest.DeepCopy()
+	SetMetaDataLabel(labeled, metadata.LabelEnvironment, "default")
+
+	cases := []struct {
+		name     string
+		live     *unstructured.Unstructured
+		expected bool
+	}{
+		{name: "in sync", live: applied(t, manifest, manifest)},
+		{name: "in sync with garbage collection labels", live: applied(t, manifest, labeled)},
+		{name: "changed manifest", live: applied(t, manifest, changed), expected: true},
+		{name: "not applied by ksonnet", live: manifest, expected: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := drifted(manifest, tc.live)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
@@ -18,8 +18,8 @@ package cluster
 import (
 	"encoding/json"
 
+	"github.com/ksonnet/ksonnet/pkg/redact"
 	"github.com/pkg/errors"
-	log "github.com/sirupsen/logrus"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
@@ -28,8 +28,9 @@ import (
 
 // Upserter updates or creates objects.
 type Upserter interface {
-	// Upsert updates or creates an object.
-	Upsert(*unstructured.Unstructured) (string, error)
+	// Upsert updates or creates an object. It returns the UID of the object,
+	// and whether it was created, configured, or unchanged.
+	Upsert(*unstructured.Unstructured) (string, string, error)
 }
 
 // defaultUpserter is the default implementation for updating or creating objects.
@@ -69,34 +70,51 @@ func newDefaultUpserter(ac ApplyConfig, oi ObjectInfo, co Clients, rfc resourceC
 }
 
 // Upsert updates or creates an object.
-func (u *defaultUpserter) Upsert(obj *unstructured.Unstructured) (string, error) {
-	log.Info("Applying ", u.objectDescriber.Describe(obj), u.dryRunText())
-
+func (u *defaultUpserter) Upsert(obj *unstructured.Unstructured) (string, string, error) {
 	rc, err := u.resourceClientFactory(u.clientOpts, obj)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
 	patchedObject, err := u.updateObject(rc, obj)
 	if err == nil {
-		log.Debug("Updated object: ", kdiff.ObjectDiff(obj, patchedObject))
-		return string(patchedObject.GetUID()), nil
+		log.Debug("Updated object: ", kdiff.ObjectDiff(redact.Object(obj), redact.Object(patchedObject)))
+		return string(patchedObject.GetUID()), updateAction(obj, patchedObject, u.DryRun), nil
 	} else if !kerrors.IsNotFound(err) {
-		return "", errors.Wrap(err, "patching existing object")
+		return "", "", errors.Wrap(err, "patching existing object")
 	}
 
 	if !u.Create {
-		return "", errors.New("not creating non-existent object")
+		return "", "", errors.New("not creating non-existent object")
 	}
 
-	log.Info("Creating non-existent ", u.objectDescriber.Describe(obj), u.dryRunText())
 	newObj, err := u.createObject(u.clientOpts, rc, obj)
 	if err != nil {
-		return "", errors.Wrap(err, "creating object")
+		return "", "", errors.Wrap(err, "creating object")
 	}
 
-	log.Debug("Created object: ", kdiff.ObjectDiff(obj, newObj))
-	return string(newObj.GetUID()), nil
+	log.Debug("Created object: ", kdiff.ObjectDiff(redact.Object(obj), redact.Object(newObj)))
+	return string(newObj.GetUID()), ApplyCreated, nil
+}
+
+// updateAction returns whether an update configured or did not change an
+// object. obj has been merged with the object in the cluster, so it has the
+// resource version it was patched from; the resource version only changes
+// when the patch changes the object. In a dry-run, objects which were not
+// found in the cluster have no resource version, and would be created.
+func updateAction(obj, patched *unstructured.Unstructured, dryRun bool) string {
+	version := obj.GetResourceVersion()
+
+	switch {
+	case dryRun && version == "":
+		return ApplyCreated
+	case dryRun:
+		return ApplyConfigured
+	case version != "" && version == patched.GetResourceVersion():
+		return ApplyUnchanged
+	default:
+		return ApplyConfigured
+	}
 }
 
 // updateObject attempts to update an object in the cluster.
@@ -116,7 +134,7 @@ func (u *defaultUpserter) updateObject(rc ResourceClient, obj *unstructured.Unst
 // createObject attempts to create an object in the cluster.
 func (u *defaultUpserter) createObject(co Clients, rc ResourceClient, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
 	newObj, err := rc.Create()
-	log.Debugf("Create(%s) returned (%v, %v)", obj.GetName(), newObj, err)
+	log.Debugf("Create(%s) returned (%v, %v)", obj.GetName(), redact.Object(newObj), err)
 
 	if err != nil {
 		return nil, errors.Wrap(err, "creating object")
@@ -124,12 +142,3 @@ func (u *defaultUpserter) createObject(co Clients, rc ResourceClient, obj *unstr
 
 	return newObj, nil
 }
-
-func (u *defaultUpserter) dryRunText() string {
-	text := ""
-	if u.DryRun {
-		text = " (dry-run)"
-	}
-
-	return text
-}
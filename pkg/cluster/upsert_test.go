@@ -35,8 +35,10 @@ func Test_defaultUpserter_Upsert(t *testing.T) {
 		name               string
 		applyConfig        ApplyConfig
 		initResourceClient func(*testing.T, *unstructured.Unstructured) *mocks.ResourceClient
+		resourceVersion    string
 		isErr              bool
 		expectedID         string
+		expectedAction     string
 	}{
 		{
 			name: "patch existing object",
@@ -53,7 +55,48 @@ func Test_defaultUpserter_Upsert(t *testing.T) {
 
 				return rc
 			},
-			expectedID: "12345",
+			expectedID:     "12345",
+			expectedAction: ApplyConfigured,
+		},
+		{
+			name: "patch changed object",
+			applyConfig: ApplyConfig{
+				Create: true,
+			},
+			resourceVersion: "1",
+			initResourceClient: func(t *testing.T, obj *unstructured.Unstructured) *mocks.ResourceClient {
+				rc := &mocks.ResourceClient{}
+
+				newObject := &unstructured.Unstructured{Object: genObject()}
+				newObject.SetUID(types.UID("12345"))
+				newObject.SetResourceVersion("2")
+
+				rc.On("Patch", types.MergePatchType, mock.AnythingOfType("[]uint8")).Return(newObject, nil)
+
+				return rc
+			},
+			expectedID:     "12345",
+			expectedAction: ApplyConfigured,
+		},
+		{
+			name: "patch unchanged object",
+			applyConfig: ApplyConfig{
+				Create: true,
+			},
+			resourceVersion: "1",
+			initResourceClient: func(t *testing.T, obj *unstructured.Unstructured) *mocks.ResourceClient {
+				rc := &mocks.ResourceClient{}
+
+				newObject := &unstructured.Unstructured{Object: genObject()}
+				newObject.SetUID(types.UID("12345"))
+				newObject.SetResourceVersion("1")
+
+				rc.On("Patch", types.MergePatchType, mock.AnythingOfType("[]uint8")).Return(newObject, nil)
+
+				return rc
+			},
+			expectedID:     "12345",
+			expectedAction: ApplyUnchanged,
 		},
 		{
 			name: "create new object",
@@ -73,7 +116,8 @@ func Test_defaultUpserter_Upsert(t *testing.T) {
 
 				return rc
 			},
-			expectedID: "12345",
+			expectedID:     "12345",
+			expectedAction: ApplyCreated,
 		},
 		{
 			name: "dry run create",
@@ -85,6 +129,20 @@ func Test_defaultUpserter_Upsert(t *testing.T) {
 				rc := &mocks.ResourceClient{}
 				return rc
 			},
+			expectedAction: ApplyCreated,
+		},
+		{
+			name: "dry run update",
+			applyConfig: ApplyConfig{
+				Create: true,
+				DryRun: true,
+			},
+			resourceVersion: "1",
+			initResourceClient: func(t *testing.T, obj *unstructured.Unstructured) *mocks.ResourceClient {
+				rc := &mocks.ResourceClient{}
+				return rc
+			},
+			expectedAction: ApplyConfigured,
 		},
 		{
 			name: "patch error other than not found",
@@ -147,6 +205,7 @@ func Test_defaultUpserter_Upsert(t *testing.T) {
 			obj := &unstructured.Unstructured{
 				Object: genObject(),
 			}
+			obj.SetResourceVersion(tc.resourceVersion)
 
 			oi := &fakeObjectInfo{resourceName: "name"}
 
@@ -160,7 +219,7 @@ func Test_defaultUpserter_Upsert(t *testing.T) {
 			u, err := newDefaultUpserter(tc.applyConfig, oi, co, rfc)
 			require.NoError(t, err)
 
-			id, err := u.Upsert(obj)
+			id, action, err := u.Upsert(obj)
 
 			if tc.isErr {
 				require.Error(t, err)
@@ -170,17 +229,19 @@ func Test_defaultUpserter_Upsert(t *testing.T) {
 			require.NoError(t, err)
 
 			require.Equal(t, tc.expectedID, id)
+			require.Equal(t, tc.expectedAction, action)
 		})
 	}
 }
 
 type fakeUpserter struct {
-	upsertID  string
-	upsertErr error
+	upsertID     string
+	upsertAction string
+	upsertErr    error
 }
 
 var _ Upserter = (*fakeUpserter)(nil)
 
-func (u *fakeUpserter) Upsert(*unstructured.Unstructured) (string, error) {
-	return u.upsertID, u.upsertErr
+func (u *fakeUpserter) Upsert(*unstructured.Unstructured) (string, string, error) {
+	return u.upsertID, u.upsertAction, u.upsertErr
 }
@@ -0,0 +1,320 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// DefaultWaitTimeout is the default amount of time to wait for applied
+	// objects to become ready.
+	DefaultWaitTimeout = 5 * time.Minute
+
+	// defaultWaitInterval is the time between readiness checks.
+	defaultWaitInterval = 2 * time.Second
+)
+
+// readinessFn reports if an object is ready. It returns an error if the
+// object can never become ready (e.g. a failed Job).
+type readinessFn func(obj *unstructured.Unstructured) (bool, error)
+
+// readinessChecks are the readiness rules for kinds that ksonnet knows how to
+// wait for. Objects of other kinds are considered ready once applied.
+var readinessChecks = map[string]readinessFn{
+	"CustomResourceDefinition": crdReady,
+	"DaemonSet":                daemonSetReady,
+	"Deployment":               deploymentReady,
+	"Job":                      jobReady,
+	"StatefulSet":              statefulSetReady,
+}
+
+// WaitResult is the outcome of waiting for a single object.
+type WaitResult struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Ready     bool
+	Elapsed   time.Duration
+}
+
+func (r WaitResult) String() string {
+	name := r.Name
+	if r.Namespace != "" {
+		name = r.Namespace + "/" + r.Name
+	}
+
+	return fmt.Sprintf("%s %s", r.Kind, name)
+}
+
+// WaitSummary summarizes the outcome of waiting for a set of objects.
+type WaitSummary []WaitResult
+
+// Pending returns the results for objects which did not become ready.
+func (s WaitSummary) Pending() WaitSummary {
+	var pending WaitSummary
+	for _, r := range s {
+		if !r.Ready {
+			pending = append(pending, r)
+		}
+	}
+
+	return pending
+}
+
+// waiter waits for objects to become ready, or to be deleted.
+type waiter struct {
+	clients               Clients
+	resourceClientFactory resourceClientFactoryFn
+	interval              time.Duration
+	timeout               time.Duration
+}
+
+func newWaiter(clients Clients, rcFactory resourceClientFactoryFn, timeout time.Duration) *waiter {
+	if timeout <= 0 {
+		timeout = DefaultWaitTimeout
+	}
+
+	return &waiter{
+		clients:               clients,
+		resourceClientFactory: rcFactory,
+		interval:              defaultWaitInterval,
+		timeout:               timeout,
+	}
+}
+
+// Wait polls objects until they are all ready, one of them fails, or the timeout
+// expires. The summary is returned even if an error occurs.
+func (w *waiter) Wait(objects []*unstructured.Unstructured) (WaitSummary, error) {
+	start := time.Now()
+
+	summary := make(WaitSummary, 0, len(objects))
+	var pending []int
+	for _, obj := range objects {
+		summary = append(summary, WaitResult{
+			Kind:      obj.GetKind(),
+			Namespace: obj.GetNamespace(),
+			Name:      obj.GetName(),
+		})
+
+		if _, ok := readinessChecks[obj.GetKind()]; !ok {
+			summary[len(summary)-1].Ready = true
+			continue
+		}
+
+		pending = append(pending, len(summary)-1)
+	}
+
+	err := wait.PollImmediate(w.interval, w.timeout, func() (bool, error) {
+		var remaining []int
+		for _, i := range pending {
+			ready, err := w.isReady(objects[i])
+			if err != nil {
+				return false, errors.Wrapf(err, "waiting for %s", summary[i])
+			}
+
+			if !ready {
+				remaining = append(remaining, i)
+				continue
+			}
+
+			summary[i].Ready = true
+			summary[i].Elapsed = time.Since(start)
+			log.Debugf("%s is ready", summary[i])
+		}
+
+		pending = remaining
+		return len(pending) == 0, nil
+	})
+
+	if err == wait.ErrWaitTimeout {
+		return summary, errors.Errorf("timed out after %s waiting for %d object(s) to become ready",
+			w.timeout, len(pending))
+	}
+
+	return summary, err
+}
+
+// WaitDeleted polls objects until they are all gone from the cluster, i.e. their
+// finalizers have completed, or the timeout expires.
+func (w *waiter) WaitDeleted(objects []*unstructured.Unstructured) error {
+	pending := objects
+
+	err := wait.PollImmediate(w.interval, w.timeout, func() (bool, error) {
+		var remaining []*unstructured.Unstructured
+		for _, obj := range pending {
+			rc, err := w.resourceClientFactory(w.clients, obj)
+			if err != nil {
+				return false, err
+			}
+
+			_, err = rc.Get(metav1.GetOptions{})
+			if kerrors.IsNotFound(err) {
+				log.Debugf("%s %s is deleted", obj.GetKind(), obj.GetName())
+				continue
+			} else if err != nil {
+				return false, errors.Wrapf(err, "waiting for deletion of %s %s", obj.GetKind(), obj.GetName())
+			}
+
+			remaining = append(remaining, obj)
+		}
+
+		pending = remaining
+		return len(pending) == 0, nil
+	})
+
+	if err == wait.ErrWaitTimeout {
+		return errors.Errorf("timed out after %s waiting for %d object(s) to be deleted",
+			w.timeout, len(pending))
+	}
+
+	return err
+}
+
+func (w *waiter) isReady(obj *unstructured.Unstructured) (bool, error) {
+	rc, err := w.resourceClientFactory(w.clients, obj)
+	if err != nil {
+		return false, err
+	}
+
+	current, err := rc.Get(metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+
+	return readinessChecks[obj.GetKind()](current)
+}
+
+// observedCurrent reports if the object's controller has observed its latest generation.
+func observedCurrent(obj *unstructured.Unstructured) bool {
+	observed, found, err := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	if err != nil || !found {
+		return false
+	}
+
+	return observed >= obj.GetGeneration()
+}
+
+// nestedInt64 returns an integer field from an object, or def if it is not set.
+func nestedInt64(obj *unstructured.Unstructured, def int64, fields ...string) int64 {
+	i, found, err := unstructured.NestedInt64(obj.Object, fields...)
+	if err != nil || !found {
+		return def
+	}
+
+	return i
+}
+
+// condition returns the object's status condition with the given type, or nil.
+func condition(obj *unstructured.Unstructured, conditionType string) map[string]interface{} {
+	conditions, _, err := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	if err != nil {
+		return nil
+	}
+
+	for _, c := range conditions {
+		m, ok := c.(map[string]interface{})
+		if ok && m["type"] == conditionType {
+			return m
+		}
+	}
+
+	return nil
+}
+
+// hasCondition reports if the object has a status condition with the given type and status.
+func hasCondition(obj *unstructured.Unstructured, conditionType, status string) bool {
+	c := condition(obj, conditionType)
+	return c != nil && c["status"] == status
+}
+
+// deploymentReady reports if a deployment has rolled out, i.e. all of its
+// replicas are updated and available, and no old replicas are left. A
+// rollout which exceeded its progress deadline fails.
+func deploymentReady(obj *unstructured.Unstructured) (bool, error) {
+	if !observedCurrent(obj) {
+		return false, nil
+	}
+
+	if c := condition(obj, "Progressing"); c != nil && c["reason"] == "ProgressDeadlineExceeded" {
+		return false, errors.Errorf("deployment %s exceeded its progress deadline", obj.GetName())
+	}
+
+	replicas := nestedInt64(obj, 1, "spec", "replicas")
+	total := nestedInt64(obj, 0, "status", "replicas")
+	updated := nestedInt64(obj, 0, "status", "updatedReplicas")
+	available := nestedInt64(obj, 0, "status", "availableReplicas")
+
+	return updated >= replicas && total <= updated && available >= replicas, nil
+}
+
+func statefulSetReady(obj *unstructured.Unstructured) (bool, error) {
+	if !observedCurrent(obj) {
+		return false, nil
+	}
+
+	replicas := nestedInt64(obj, 1, "spec", "replicas")
+	ready := nestedInt64(obj, 0, "status", "readyReplicas")
+	if ready < replicas {
+		return false, nil
+	}
+
+	// Pods of OnDelete stateful sets are only updated when they are deleted,
+	// so the revisions do not converge.
+	strategy, _, _ := unstructured.NestedString(obj.Object, "spec", "updateStrategy", "type")
+	if strategy == "OnDelete" {
+		return true, nil
+	}
+
+	current, _, _ := unstructured.NestedString(obj.Object, "status", "currentRevision")
+	update, _, _ := unstructured.NestedString(obj.Object, "status", "updateRevision")
+
+	return update == "" || current == update, nil
+}
+
+func daemonSetReady(obj *unstructured.Unstructured) (bool, error) {
+	if !observedCurrent(obj) {
+		return false, nil
+	}
+
+	desired := nestedInt64(obj, 0, "status", "desiredNumberScheduled")
+	updated := nestedInt64(obj, 0, "status", "updatedNumberScheduled")
+	available := nestedInt64(obj, 0, "status", "numberAvailable")
+
+	return updated >= desired && available >= desired, nil
+}
+
+func jobReady(obj *unstructured.Unstructured) (bool, error) {
+	if hasCondition(obj, "Failed", "True") {
+		return false, errors.Errorf("job %s failed", obj.GetName())
+	}
+
+	completions := nestedInt64(obj, 1, "spec", "completions")
+	succeeded := nestedInt64(obj, 0, "status", "succeeded")
+
+	return succeeded >= completions, nil
+}
+
+func crdReady(obj *unstructured.Unstructured) (bool, error) {
+	return hasCondition(obj, "Established", "True"), nil
+}
@@ -0,0 +1,278 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/cluster/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func Test_readinessChecks(t *testing.T) {
+	cases := []struct {
+		name    string
+		obj     map[string]interface{}
+		isReady bool
+		isErr   bool
+	}{
+		{
+			name: "deployment rolled out",
+			obj: map[string]interface{}{
+				"kind":     "Deployment",
+				"metadata": map[string]interface{}{"generation": int64(2)},
+				"spec":     map[string]interface{}{"replicas": int64(2)},
+				"status": map[string]interface{}{
+					"observedGeneration": int64(2),
+					"updatedReplicas":    int64(2),
+					"availableReplicas":  int64(2),
+				},
+			},
+			isReady: true,
+		},
+		{
+			name: "deployment generation not observed",
+			obj: map[string]interface{}{
+				"kind":     "Deployment",
+				"metadata": map[string]interface{}{"generation": int64(3)},
+				"spec":     map[string]interface{}{"replicas": int64(2)},
+				"status": map[string]interface{}{
+					"observedGeneration": int64(2),
+					"updatedReplicas":    int64(2),
+					"availableReplicas":  int64(2),
+				},
+			},
+		},
+		{
+			name: "deployment rolling out",
+			obj: map[string]interface{}{
+				"kind": "Deployment",
+				"status": map[string]interface{}{
+					"observedGeneration": int64(1),
+					"updatedReplicas":    int64(1),
+					"availableReplicas":  int64(0),
+				},
+			},
+		},
+		{
+			name: "deployment with old replicas",
+			obj: map[string]interface{}{
+				"kind":     "Deployment",
+				"metadata": map[string]interface{}{"generation": int64(2)},
+				"spec":     map[string]interface{}{"replicas": int64(2)},
+				"status": map[string]interface{}{
+					"observedGeneration": int64(2),
+					"replicas":           int64(3),
+					"updatedReplicas":    int64(2),
+					"availableReplicas":  int64(3),
+				},
+			},
+		},
+		{
+			name: "deployment progress deadline exceeded",
+			obj: map[string]interface{}{
+				"kind":     "Deployment",
+				"metadata": map[string]interface{}{"generation": int64(2)},
+				"spec":     map[string]interface{}{"replicas": int64(2)},
+				"status": map[string]interface{}{
+					"observedGeneration": int64(2),
+					"replicas":           int64(3),
+					"updatedReplicas":    int64(1),
+					"availableReplicas":  int64(2),
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Available", "status": "True"},
+						map[string]interface{}{"type": "Progressing", "status": "False", "reason": "ProgressDeadlineExceeded"},
+					},
+				},
+			},
+			isErr: true,
+		},
+		{
+			name: "statefulset on delete",
+			obj: map[string]interface{}{
+				"kind": "StatefulSet",
+				"spec": map[string]interface{}{
+					"replicas":       int64(1),
+					"updateStrategy": map[string]interface{}{"type": "OnDelete"},
+				},
+				"status": map[string]interface{}{
+					"observedGeneration": int64(1),
+					"readyReplicas":      int64(1),
+					"currentRevision":    "a",
+					"updateRevision":     "b",
+				},
+			},
+			isReady: true,
+		},
+		{
+			name: "statefulset revision mismatch",
+			obj: map[string]interface{}{
+				"kind": "StatefulSet",
+				"spec": map[string]interface{}{"replicas": int64(1)},
+				"status": map[string]interface{}{
+					"observedGeneration": int64(1),
+					"readyReplicas":      int64(1),
+					"currentRevision":    "a",
+					"updateRevision":     "b",
+				},
+			},
+		},
+		{
+			name: "statefulset ready",
+			obj: map[string]interface{}{
+				"kind": "StatefulSet",
+				"spec": map[string]interface{}{"replicas": int64(1)},
+				"status": map[string]interface{}{
+					"observedGeneration": int64(1),
+					"readyReplicas":      int64(1),
+					"currentRevision":    "b",
+					"updateRevision":     "b",
+				},
+			},
+			isReady: true,
+		},
+		{
+			name: "daemonset ready",
+			obj: map[string]interface{}{
+				"kind": "DaemonSet",
+				"status": map[string]interface{}{
+					"observedGeneration":     int64(1),
+					"desiredNumberScheduled": int64(3),
+					"updatedNumberScheduled": int64(3),
+					"numberAvailable":        int64(3),
+				},
+			},
+			isReady: true,
+		},
+		{
+			name: "job complete",
+			obj: map[string]interface{}{
+				"kind":   "Job",
+				"status": map[string]interface{}{"succeeded": int64(1)},
+			},
+			isReady: true,
+		},
+		{
+			name: "job failed",
+			obj: map[string]interface{}{
+				"kind": "Job",
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Failed", "status": "True"},
+					},
+				},
+			},
+			isErr: true,
+		},
+		{
+			name: "crd established",
+			obj: map[string]interface{}{
+				"kind": "CustomResourceDefinition",
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Established", "status": "True"},
+					},
+				},
+			},
+			isReady: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{Object: tc.obj}
+			fn, ok := readinessChecks[obj.GetKind()]
+			require.True(t, ok)
+
+			ready, err := fn(obj)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.isReady, ready)
+		})
+	}
+}
+
+func Test_waiter_Wait(t *testing.T) {
+	deployment := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind":     "Deployment",
+		"metadata": map[string]interface{}{"name": "deploy", "namespace": "default"},
+	}}
+	service := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind":     "Service",
+		"metadata": map[string]interface{}{"name": "svc", "namespace": "default"},
+	}}
+
+	rolledOut := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind": "Deployment",
+		"status": map[string]interface{}{
+			"observedGeneration": int64(1),
+			"updatedReplicas":    int64(1),
+			"availableReplicas":  int64(1),
+		},
+	}}
+
+	rc := &mocks.ResourceClient{}
+	rc.On("Get", mock.Anything).Return(deployment, nil).Once()
+	rc.On("Get", mock.Anything).Return(rolledOut, nil)
+
+	rcFactory := func(Clients, runtime.Object) (ResourceClient, error) {
+		return rc, nil
+	}
+
+	w := newWaiter(Clients{}, rcFactory, time.Second)
+	w.interval = time.Millisecond
+
+	summary, err := w.Wait([]*unstructured.Unstructured{deployment, service})
+	require.NoError(t, err)
+
+	require.Len(t, summary, 2)
+	assert.True(t, summary[0].Ready)
+	assert.True(t, summary[1].Ready)
+	assert.Empty(t, summary.Pending())
+	assert.Equal(t, "Deployment default/deploy", summary[0].String())
+}
+
+func Test_waiter_Wait_timeout(t *testing.T) {
+	deployment := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind":     "Deployment",
+		"metadata": map[string]interface{}{"name": "deploy"},
+	}}
+
+	rc := &mocks.ResourceClient{}
+	rc.On("Get", mock.Anything).Return(deployment, nil)
+
+	rcFactory := func(Clients, runtime.Object) (ResourceClient, error) {
+		return rc, nil
+	}
+
+	w := newWaiter(Clients{}, rcFactory, 10*time.Millisecond)
+	w.interval = time.Millisecond
+
+	summary, err := w.Wait([]*unstructured.Unstructured{deployment})
+	require.Error(t, err)
+
+	pending := summary.Pending()
+	require.Len(t, pending, 1)
+	assert.Equal(t, "deploy", pending[0].Name)
+}
@@ -0,0 +1,226 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// workloadKinds are the kinds of objects which run pods, in the order they are
+// preferred when a component renders more than one.
+var workloadKinds = []string{
+	"Deployment",
+	"StatefulSet",
+	"DaemonSet",
+	"ReplicaSet",
+	"ReplicationController",
+	"Job",
+	"Pod",
+}
+
+// podClient lists pods, and streams their logs.
+type podClient interface {
+	List(namespace string, selector labels.Selector) ([]corev1.Pod, error)
+	Logs(namespace, name string, opts *corev1.PodLogOptions) (io.ReadCloser, error)
+}
+
+type podClientFactoryFn func(Clients) (podClient, error)
+
+// kubePodClient is a podClient backed by the Kubernetes core API.
+type kubePodClient struct {
+	c corev1client.CoreV1Interface
+}
+
+var _ podClient = (*kubePodClient)(nil)
+
+func newPodClient(clients Clients) (podClient, error) {
+	c, err := corev1client.NewForConfig(clients.config)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating core client")
+	}
+
+	return &kubePodClient{c: c}, nil
+}
+
+func (k *kubePodClient) List(namespace string, selector labels.Selector) ([]corev1.Pod, error) {
+	list, err := k.c.Pods(namespace).List(metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+
+	return list.Items, nil
+}
+
+func (k *kubePodClient) Logs(namespace, name string, opts *corev1.PodLogOptions) (io.ReadCloser, error) {
+	return k.c.Pods(namespace).GetLogs(name, opts).Stream()
+}
+
+// componentTarget returns the object of a component which pods are found for.
+// Workloads are preferred, or services if preferService is true.
+func componentTarget(component string, objects []*unstructured.Unstructured, preferService bool) (*unstructured.Unstructured, error) {
+	kinds := workloadKinds
+	if preferService {
+		kinds = append([]string{"Service"}, kinds...)
+	}
+
+	for _, kind := range kinds {
+		for _, obj := range objects {
+			if obj.GetKind() == kind {
+				return obj, nil
+			}
+		}
+	}
+
+	if preferService {
+		return nil, errors.Errorf("component %q does not have a service or workload", component)
+	}
+
+	return nil, errors.Errorf("component %q does not have a workload", component)
+}
+
+// targetPods returns the pods of a workload or service, sorted by name.
+func targetPods(pc podClient, namespace string, target *unstructured.Unstructured) ([]corev1.Pod, error) {
+	selector, err := podSelector(target)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := pc.List(namespace, selector)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing pods of %s %s", target.GetKind(), target.GetName())
+	}
+
+	if target.GetKind() == "Pod" {
+		var matched []corev1.Pod
+		for _, pod := range pods {
+			if pod.Name == target.GetName() {
+				matched = append(matched, pod)
+			}
+		}
+		pods = matched
+	}
+
+	if len(pods) == 0 {
+		return nil, errors.Errorf("%s %s does not have any pods in namespace %q", target.GetKind(), target.GetName(), namespace)
+	}
+
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].Name < pods[j].Name
+	})
+
+	return pods, nil
+}
+
+// podSelector returns the label selector of the pods of a workload or service.
+func podSelector(obj *unstructured.Unstructured) (labels.Selector, error) {
+	switch obj.GetKind() {
+	case "Pod":
+		return labels.SelectorFromSet(obj.GetLabels()), nil
+	case "Service", "ReplicationController":
+		m, _, err := unstructured.NestedStringMap(obj.Object, "spec", "selector")
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading selector of %s %s", obj.GetKind(), obj.GetName())
+		}
+		if len(m) == 0 {
+			return nil, errors.Errorf("%s %s does not have a selector", obj.GetKind(), obj.GetName())
+		}
+		return labels.SelectorFromSet(m), nil
+	}
+
+	raw, ok, err := unstructured.NestedMap(obj.Object, "spec", "selector")
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading selector of %s %s", obj.GetKind(), obj.GetName())
+	}
+
+	if !ok {
+		// Selectors default to the labels of the pod template.
+		m, _, err := unstructured.NestedStringMap(obj.Object, "spec", "template", "metadata", "labels")
+		if err != nil || len(m) == 0 {
+			return nil, errors.Errorf("%s %s does not have a selector", obj.GetKind(), obj.GetName())
+		}
+		return labels.SelectorFromSet(m), nil
+	}
+
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var ls metav1.LabelSelector
+	if err = json.Unmarshal(b, &ls); err != nil {
+		return nil, errors.Wrapf(err, "reading selector of %s %s", obj.GetKind(), obj.GetName())
+	}
+
+	return metav1.LabelSelectorAsSelector(&ls)
+}
+
+// servicePort is a port of a service, and the port of its pods it targets.
+type servicePort struct {
+	port       int
+	targetPort intstr.IntOrString
+}
+
+// servicePorts returns the ports of a service.
+func servicePorts(service *unstructured.Unstructured) ([]servicePort, error) {
+	raw, _, err := unstructured.NestedSlice(service.Object, "spec", "ports")
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading ports of service %s", service.GetName())
+	}
+
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var ports []corev1.ServicePort
+	if err = json.Unmarshal(b, &ports); err != nil {
+		return nil, errors.Wrapf(err, "reading ports of service %s", service.GetName())
+	}
+
+	var sps []servicePort
+	for _, p := range ports {
+		sp := servicePort{port: int(p.Port), targetPort: p.TargetPort}
+		if sp.targetPort.Type == intstr.Int && sp.targetPort.IntVal == 0 {
+			sp.targetPort = intstr.FromInt(sp.port)
+		}
+		sps = append(sps, sp)
+	}
+
+	return sps, nil
+}
+
+// containerPort returns the number of a named port of a pod's containers.
+func containerPort(pod corev1.Pod, name string) (int, error) {
+	for _, c := range pod.Spec.Containers {
+		for _, p := range c.Ports {
+			if p.Name == name {
+				return int(p.ContainerPort), nil
+			}
+		}
+	}
+
+	return 0, errors.Errorf("pod %s does not have a port named %q", pod.Name, name)
+}
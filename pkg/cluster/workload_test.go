@@ -0,0 +1,253 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cluster
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// fakePodClient lists pods which match a selector, and returns canned logs.
+type fakePodClient struct {
+	pods    []corev1.Pod
+	logs    map[string]string
+	logOpts map[string]*corev1.PodLogOptions
+}
+
+var _ podClient = (*fakePodClient)(nil)
+
+func (f *fakePodClient) List(namespace string, selector labels.Selector) ([]corev1.Pod, error) {
+	var pods []corev1.Pod
+	for _, pod := range f.pods {
+		if pod.Namespace == namespace && selector.Matches(labels.Set(pod.Labels)) {
+			pods = append(pods, pod)
+		}
+	}
+
+	return pods, nil
+}
+
+func (f *fakePodClient) Logs(namespace, name string, opts *corev1.PodLogOptions) (io.ReadCloser, error) {
+	logs, ok := f.logs[name]
+	if !ok {
+		return nil, errors.Errorf("pod %s not found", name)
+	}
+
+	if f.logOpts == nil {
+		f.logOpts = make(map[string]*corev1.PodLogOptions)
+	}
+	f.logOpts[name] = opts
+
+	return ioutil.NopCloser(strings.NewReader(logs)), nil
+}
+
+func newPod(name string, labels map[string]string, phase corev1.PodPhase) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "web", Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}}},
+			},
+		},
+		Status: corev1.PodStatus{Phase: phase},
+	}
+}
+
+func guestbookObjects() []*unstructured.Unstructured {
+	service := newDeleteObject("v1", "Service", "", "guestbook")
+	service.Object["spec"] = map[string]interface{}{
+		"selector": map[string]interface{}{"app": "guestbook"},
+		"ports": []interface{}{
+			map[string]interface{}{"port": int64(80), "targetPort": "http"},
+			map[string]interface{}{"port": int64(9090)},
+		},
+	}
+
+	deployment := newDeleteObject("apps/v1", "Deployment", "", "guestbook")
+	deployment.Object["spec"] = map[string]interface{}{
+		"selector": map[string]interface{}{
+			"matchLabels": map[string]interface{}{"app": "guestbook"},
+		},
+	}
+
+	configMap := newDeleteObject("v1", "ConfigMap", "", "guestbook")
+
+	return []*unstructured.Unstructured{configMap, service, deployment}
+}
+
+func withPods(findObjects findObjectsFn, pc podClient) (findObjectsFn, genClientOptsFn, podClientFactoryFn) {
+	genClients := func(a app.App, clientConfig *client.Config, envName string) (Clients, error) {
+		return Clients{namespace: "default"}, nil
+	}
+	factory := func(Clients) (podClient, error) {
+		return pc, nil
+	}
+
+	return findObjects, genClients, factory
+}
+
+func Test_componentTarget(t *testing.T) {
+	objects := guestbookObjects()
+
+	target, err := componentTarget("guestbook", objects, false)
+	require.NoError(t, err)
+	assert.Equal(t, "Deployment", target.GetKind())
+
+	target, err = componentTarget("guestbook", objects, true)
+	require.NoError(t, err)
+	assert.Equal(t, "Service", target.GetKind())
+
+	_, err = componentTarget("guestbook", objects[:1], true)
+	require.Error(t, err)
+}
+
+func Test_podSelector(t *testing.T) {
+	deployment := newDeleteObject("apps/v1", "Deployment", "default", "guestbook")
+	deployment.Object["spec"] = map[string]interface{}{
+		"selector": map[string]interface{}{
+			"matchLabels": map[string]interface{}{"app": "guestbook"},
+			"matchExpressions": []interface{}{
+				map[string]interface{}{"key": "tier", "operator": "In", "values": []interface{}{"web"}},
+			},
+		},
+	}
+
+	templated := newDeleteObject("extensions/v1beta1", "Deployment", "default", "guestbook")
+	templated.Object["spec"] = map[string]interface{}{
+		"template": map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"labels": map[string]interface{}{"app": "guestbook"},
+			},
+		},
+	}
+
+	service := newDeleteObject("v1", "Service", "default", "guestbook")
+	service.Object["spec"] = map[string]interface{}{
+		"selector": map[string]interface{}{"app": "guestbook"},
+	}
+
+	headless := newDeleteObject("v1", "Service", "default", "external")
+
+	cases := []struct {
+		name     string
+		obj      *unstructured.Unstructured
+		expected string
+		isErr    bool
+	}{
+		{name: "label selector", obj: deployment, expected: "app=guestbook,tier in (web)"},
+		{name: "pod template labels", obj: templated, expected: "app=guestbook"},
+		{name: "service", obj: service, expected: "app=guestbook"},
+		{name: "service without a selector", obj: headless, isErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			selector, err := podSelector(tc.obj)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, selector.String())
+		})
+	}
+}
+
+func TestLogs(t *testing.T) {
+	pc := &fakePodClient{
+		pods: []corev1.Pod{
+			newPod("guestbook-b", map[string]string{"app": "guestbook"}, corev1.PodRunning),
+			newPod("guestbook-a", map[string]string{"app": "guestbook"}, corev1.PodRunning),
+			newPod("redis", map[string]string{"app": "redis"}, corev1.PodRunning),
+		},
+		logs: map[string]string{
+			"guestbook-a": "started\nready\n",
+			"guestbook-b": "started\n",
+		},
+	}
+
+	var buf bytes.Buffer
+	config := LogsConfig{
+		ComponentName: "guestbook",
+		EnvName:       "default",
+		Container:     "web",
+		Tail:          10,
+		Out:           &buf,
+	}
+
+	findObjects := func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+		assert.Equal(t, []string{"guestbook"}, componentNames)
+		return guestbookObjects(), nil
+	}
+
+	err := RunLogs(config, func(l *Logs) {
+		l.findObjectsFn, l.genClientOptsFn, l.podClientFactory = withPods(findObjects, pc)
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "[guestbook-a] started\n[guestbook-a] ready\n[guestbook-b] started\n", buf.String())
+	require.Contains(t, pc.logOpts, "guestbook-a")
+	assert.Equal(t, "web", pc.logOpts["guestbook-a"].Container)
+	assert.Equal(t, int64(10), *pc.logOpts["guestbook-a"].TailLines)
+}
+
+func TestLogs_long_lines(t *testing.T) {
+	long := strings.Repeat("x", 128*1024)
+	pc := &fakePodClient{
+		pods: []corev1.Pod{
+			newPod("guestbook-a", map[string]string{"app": "guestbook"}, corev1.PodRunning),
+		},
+		logs: map[string]string{
+			"guestbook-a": long + "\nunterminated",
+		},
+	}
+
+	findObjects := func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+		return guestbookObjects(), nil
+	}
+
+	var buf bytes.Buffer
+	err := RunLogs(LogsConfig{ComponentName: "guestbook", Tail: -1, Out: &buf}, func(l *Logs) {
+		l.findObjectsFn, l.genClientOptsFn, l.podClientFactory = withPods(findObjects, pc)
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, long+"\nunterminated\n", buf.String())
+}
+
+func TestLogs_no_pods(t *testing.T) {
+	findObjects := func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+		return guestbookObjects(), nil
+	}
+
+	err := RunLogs(LogsConfig{ComponentName: "guestbook", Out: ioutil.Discard}, func(l *Logs) {
+		l.findObjectsFn, l.genClientOptsFn, l.podClientFactory = withPods(findObjects, &fakePodClient{})
+	})
+	require.Error(t, err)
+}
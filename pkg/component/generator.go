@@ -0,0 +1,354 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package component
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ghodss/yaml"
+	"github.com/google/go-jsonnet/ast"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	jsonnetutil "github.com/ksonnet/ksonnet/pkg/util/jsonnet"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+const (
+	// TypeGenerator is a component which generates a ConfigMap or Secret from
+	// local files.
+	TypeGenerator = "generator"
+
+	generatorHashLength = 10
+)
+
+var reDataKey = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
+// GeneratorSpec is the contents of a generator component. Files are paths
+// relative to the generator, or `key=path`, and must be in the app. A
+// directory adds each regular file in it, keyed by the file name. Files are
+// not read for untrusted apps. Literals are `key=value`.
+type GeneratorSpec struct {
+	Kind        string   `json:"kind"`
+	Name        string   `json:"name"`
+	Namespace   string   `json:"namespace,omitempty"`
+	Type        string   `json:"type,omitempty"`
+	Files       []string `json:"files,omitempty"`
+	Literals    []string `json:"literals,omitempty"`
+	DisableHash bool     `json:"disableHash,omitempty"`
+}
+
+// Generator is a component which builds a ConfigMap or Secret from local files
+// when it is rendered. The content hash is appended to the object's name, so
+// objects referencing it change, and roll, when the content changes.
+type Generator struct {
+	app    app.App
+	module string
+	source string
+}
+
+var _ Component = (*Generator)(nil)
+
+// NewGenerator creates an instance of Generator.
+func NewGenerator(a app.App, module, source string) *Generator {
+	return &Generator{
+		app:    a,
+		module: module,
+		source: source,
+	}
+}
+
+// Name is the component name.
+func (g *Generator) Name(wantsNameSpaced bool) string {
+	base := filepath.Base(g.source)
+	name := strings.TrimSuffix(base, filepath.Ext(base))
+	if !wantsNameSpaced {
+		return name
+	}
+
+	if g.module == "/" || g.module == "" {
+		return name
+	}
+
+	return strings.Join([]string{g.module, name}, ".")
+}
+
+// Type always returns "generator".
+func (g *Generator) Type() string {
+	return TypeGenerator
+}
+
+// Remove removes the component. The files it reads are left in place.
+func (g *Generator) Remove() error {
+	if err := g.app.Fs().Remove(g.source); err != nil {
+		return errors.Wrapf(err, "removing %q", g.source)
+	}
+
+	return nil
+}
+
+// Params always returns no params. Generators are configured by their files.
+func (g *Generator) Params(envName string) ([]ModuleParameter, error) {
+	return []ModuleParameter{}, nil
+}
+
+// SetParam returns an error. Generators do not have params.
+func (g *Generator) SetParam(path []string, value interface{}) error {
+	return errors.Errorf("generator component %q does not have params", g.Name(true))
+}
+
+// DeleteParam returns an error. Generators do not have params.
+func (g *Generator) DeleteParam(path []string) error {
+	return errors.Errorf("generator component %q does not have params", g.Name(true))
+}
+
+// Summarize returns a summary of the generated object.
+func (g *Generator) Summarize() (Summary, error) {
+	obj, err := g.Object()
+	if err != nil {
+		return Summary{}, err
+	}
+
+	return Summary{
+		ComponentName: g.Name(true),
+		Type:          TypeGenerator,
+		APIVersion:    "v1",
+		Kind:          obj["kind"].(string),
+		Name:          obj["metadata"].(map[string]interface{})["name"].(string),
+	}, nil
+}
+
+// ToNode converts the generated object to a Jsonnet node.
+func (g *Generator) ToNode(envName string) (string, ast.Node, error) {
+	obj, err := g.Object()
+	if err != nil {
+		return "", nil, err
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return "", nil, err
+	}
+
+	node, err := jsonnetutil.Parse(g.source, string(data))
+	if err != nil {
+		return "", nil, err
+	}
+
+	return g.Name(true), node, nil
+}
+
+// Spec reads the generator's spec.
+func (g *Generator) Spec() (*GeneratorSpec, error) {
+	b, err := afero.ReadFile(g.app.Fs(), g.source)
+	if err != nil {
+		return nil, err
+	}
+
+	var spec GeneratorSpec
+	if err = yaml.Unmarshal(b, &spec); err != nil {
+		return nil, errors.Wrapf(err, "parsing generator %s", g.source)
+	}
+
+	switch spec.Kind {
+	case "ConfigMap", "Secret":
+	default:
+		return nil, errors.Errorf("generator %s: kind must be ConfigMap or Secret, not %q", g.source, spec.Kind)
+	}
+
+	if spec.Name == "" {
+		spec.Name = g.Name(false)
+	}
+
+	return &spec, nil
+}
+
+// Object builds the ConfigMap or Secret. The object is annotated with the
+// name from the spec, which is the name other objects use to refer to it.
+func (g *Generator) Object() (map[string]interface{}, error) {
+	spec, err := g.Spec()
+	if err != nil {
+		return nil, err
+	}
+
+	contents, err := g.contents(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make(map[string]interface{})
+	binaryData := make(map[string]interface{})
+	for key, b := range contents {
+		switch {
+		case spec.Kind == "Secret":
+			data[key] = base64.StdEncoding.EncodeToString(b)
+		case utf8.Valid(b):
+			data[key] = string(b)
+		default:
+			binaryData[key] = base64.StdEncoding.EncodeToString(b)
+		}
+	}
+
+	obj := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       spec.Kind,
+		"data":       data,
+	}
+	if len(binaryData) > 0 {
+		obj["binaryData"] = binaryData
+	}
+	if spec.Kind == "Secret" {
+		secretType := spec.Type
+		if secretType == "" {
+			secretType = "Opaque"
+		}
+		obj["type"] = secretType
+	}
+
+	name := spec.Name
+	if !spec.DisableHash {
+		hash, err := contentHash(obj)
+		if err != nil {
+			return nil, err
+		}
+		name = name + "-" + hash
+	}
+
+	objMetadata := map[string]interface{}{
+		"name": name,
+		"annotations": map[string]interface{}{
+			metadata.AnnotationGeneratedFrom: spec.Name,
+		},
+	}
+	if spec.Namespace != "" {
+		objMetadata["namespace"] = spec.Namespace
+	}
+	obj["metadata"] = objMetadata
+
+	return obj, nil
+}
+
+// contents reads the files and literals of a spec, keyed by data key.
+func (g *Generator) contents(spec *GeneratorSpec) (map[string][]byte, error) {
+	fs := g.app.Fs()
+	root := filepath.Clean(g.app.Root())
+	dir := filepath.Dir(g.source)
+	contents := make(map[string][]byte)
+
+	add := func(key string, b []byte) error {
+		if !reDataKey.MatchString(key) {
+			return errors.Errorf("generator %s: %q is not a valid data key", g.source, key)
+		}
+		if _, ok := contents[key]; ok {
+			return errors.Errorf("generator %s: duplicate data key %q", g.source, key)
+		}
+		contents[key] = b
+		return nil
+	}
+
+	// Untrusted apps could link to the host's files.
+	if len(spec.Files) > 0 && app.IsUntrusted(g.app) {
+		return nil, errors.Errorf("generator %s: files are disabled for untrusted apps", g.source)
+	}
+
+	for _, entry := range spec.Files {
+		key, path := "", entry
+		if i := strings.Index(entry, "="); i >= 0 {
+			key, path = entry[:i], entry[i+1:]
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		path = filepath.Clean(path)
+
+		// Files outside of the app could expose the host's files, e.g. when
+		// serving apps checked out from git.
+		if path != root && !strings.HasPrefix(path, root+string(filepath.Separator)) {
+			return nil, errors.Errorf("generator %s: file %q is not in the app", g.source, entry)
+		}
+
+		fi, err := fs.Stat(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "generator %s", g.source)
+		}
+
+		if !fi.IsDir() {
+			if key == "" {
+				key = filepath.Base(path)
+			}
+			b, err := afero.ReadFile(fs, path)
+			if err != nil {
+				return nil, err
+			}
+			if err = add(key, b); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if key != "" {
+			return nil, errors.Errorf("generator %s: directory %q can not be given a key", g.source, entry)
+		}
+
+		fis, err := afero.ReadDir(fs, path)
+		if err != nil {
+			return nil, err
+		}
+		for _, fi := range fis {
+			if !fi.Mode().IsRegular() {
+				continue
+			}
+			b, err := afero.ReadFile(fs, filepath.Join(path, fi.Name()))
+			if err != nil {
+				return nil, err
+			}
+			if err = add(fi.Name(), b); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, literal := range spec.Literals {
+		i := strings.Index(literal, "=")
+		if i < 0 {
+			return nil, errors.Errorf("generator %s: literal %q is not key=value", g.source, literal)
+		}
+		if err := add(literal[:i], []byte(literal[i+1:])); err != nil {
+			return nil, err
+		}
+	}
+
+	return contents, nil
+}
+
+// contentHash hashes the parts of a generated object which are set from its
+// spec. encoding/json sorts map keys, so the hash is stable.
+func contentHash(obj map[string]interface{}) (string, error) {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])[:generatorHashLength], nil
+}
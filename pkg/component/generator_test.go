@@ -0,0 +1,157 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package component
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_Object(t *testing.T) {
+	test.WithApp(t, "/app", func(a *mocks.App, fs afero.Fs) {
+		spec := "kind: ConfigMap\nname: app-config\nfiles:\n- config/app.ini\n- settings=config/extra\n- conf.d\nliterals:\n- LOG_LEVEL=debug\n"
+		files := map[string]string{
+			"/app/components/app-config.generator": spec,
+			"/app/components/config/app.ini":       "a=1\n",
+			"/app/components/config/extra":         "b=2\n",
+			"/app/components/conf.d/one.conf":      "one\n",
+			"/app/components/conf.d/two.conf":      "two\n",
+		}
+		for path, content := range files {
+			require.NoError(t, afero.WriteFile(fs, path, []byte(content), 0644))
+		}
+
+		g := NewGenerator(a, "/", "/app/components/app-config.generator")
+		require.Equal(t, "app-config", g.Name(true))
+		require.Equal(t, TypeGenerator, g.Type())
+
+		obj, err := g.Object()
+		require.NoError(t, err)
+
+		require.Equal(t, map[string]interface{}{
+			"app.ini":   "a=1\n",
+			"settings":  "b=2\n",
+			"one.conf":  "one\n",
+			"two.conf":  "two\n",
+			"LOG_LEVEL": "debug",
+		}, obj["data"])
+
+		objMetadata := obj["metadata"].(map[string]interface{})
+		name := objMetadata["name"].(string)
+		require.Regexp(t, `^app-config-[0-9a-f]{10}$`, name)
+		require.Equal(t, "app-config", objMetadata["annotations"].(map[string]interface{})[metadata.AnnotationGeneratedFrom])
+
+		// Changing content changes the name.
+		require.NoError(t, afero.WriteFile(fs, "/app/components/config/app.ini", []byte("a=2\n"), 0644))
+		obj, err = g.Object()
+		require.NoError(t, err)
+		require.NotEqual(t, name, obj["metadata"].(map[string]interface{})["name"])
+	})
+}
+
+func TestGenerator_Object_secret(t *testing.T) {
+	test.WithApp(t, "/app", func(a *mocks.App, fs afero.Fs) {
+		spec := "kind: Secret\nliterals:\n- token=s3cret\ndisableHash: true\n"
+		require.NoError(t, afero.WriteFile(fs, "/app/components/creds.generator", []byte(spec), 0644))
+
+		g := NewGenerator(a, "/", "/app/components/creds.generator")
+		obj, err := g.Object()
+		require.NoError(t, err)
+
+		require.Equal(t, "Opaque", obj["type"])
+		require.Equal(t, map[string]interface{}{"token": "czNjcmV0"}, obj["data"])
+		require.Equal(t, "creds", obj["metadata"].(map[string]interface{})["name"])
+	})
+}
+
+func TestGenerator_Object_app_paths(t *testing.T) {
+	test.WithApp(t, "/app", func(a *mocks.App, fs afero.Fs) {
+		spec := "kind: ConfigMap\nfiles:\n- ../config/shared.ini\n- /app/config/abs.ini\n"
+		files := map[string]string{
+			"/app/components/gen.generator": spec,
+			"/app/config/shared.ini":        "shared\n",
+			"/app/config/abs.ini":           "abs\n",
+		}
+		for path, content := range files {
+			require.NoError(t, afero.WriteFile(fs, path, []byte(content), 0644))
+		}
+
+		g := NewGenerator(a, "/", "/app/components/gen.generator")
+		obj, err := g.Object()
+		require.NoError(t, err)
+
+		require.Equal(t, map[string]interface{}{
+			"shared.ini": "shared\n",
+			"abs.ini":    "abs\n",
+		}, obj["data"])
+	})
+}
+
+func TestGenerator_Object_untrusted_app(t *testing.T) {
+	test.WithApp(t, "/app", func(a *mocks.App, fs afero.Fs) {
+		spec := "kind: ConfigMap\nfiles:\n- app.ini\nliterals:\n- a=1\n"
+		require.NoError(t, afero.WriteFile(fs, "/app/components/gen.generator", []byte(spec), 0644))
+		require.NoError(t, afero.WriteFile(fs, "/app/components/app.ini", []byte("a=1\n"), 0644))
+
+		g := NewGenerator(app.Untrusted(a), "/", "/app/components/gen.generator")
+		_, err := g.Object()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "files are disabled for untrusted apps")
+
+		// Literals don't read files.
+		spec = "kind: ConfigMap\nliterals:\n- a=1\n"
+		require.NoError(t, afero.WriteFile(fs, "/app/components/gen.generator", []byte(spec), 0644))
+		obj, err := g.Object()
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{"a": "1"}, obj["data"])
+	})
+}
+
+func TestGenerator_Object_invalid(t *testing.T) {
+	cases := []struct {
+		name string
+		spec string
+	}{
+		{name: "unknown kind", spec: "kind: Deployment\n"},
+		{name: "missing file", spec: "kind: ConfigMap\nfiles:\n- missing.txt\n"},
+		{name: "duplicate key", spec: "kind: ConfigMap\nliterals:\n- a=1\n- a=2\n"},
+		{name: "invalid key", spec: "kind: ConfigMap\nliterals:\n- a/b=1\n"},
+		{name: "literal without value", spec: "kind: ConfigMap\nliterals:\n- a\n"},
+		{name: "absolute path outside app", spec: "kind: ConfigMap\nfiles:\n- /etc/passwd\n"},
+		{name: "relative path outside app", spec: "kind: ConfigMap\nfiles:\n- ../../etc/passwd\n"},
+		{name: "keyed path outside app", spec: "kind: ConfigMap\nfiles:\n- passwd=../../etc/passwd\n"},
+		{name: "directory outside app", spec: "kind: ConfigMap\nfiles:\n- ../../etc\n"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			test.WithApp(t, "/app", func(a *mocks.App, fs afero.Fs) {
+				require.NoError(t, afero.WriteFile(fs, "/app/components/gen.generator", []byte(tc.spec), 0644))
+				require.NoError(t, afero.WriteFile(fs, "/etc/passwd", []byte("root:x:0:0\n"), 0644))
+
+				g := NewGenerator(a, "/", "/app/components/gen.generator")
+				_, err := g.Object()
+				require.Error(t, err)
+			})
+		})
+	}
+}
@@ -26,7 +26,6 @@ import (
 	"github.com/google/go-jsonnet/ast"
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/params"
-	"github.com/ksonnet/ksonnet/pkg/util/jsonnet"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
@@ -190,12 +189,23 @@ func (j *Jsonnet) Summarize() (Summary, error) {
 	}, nil
 }
 
-// ToNode converts a Jsonnet component to a Jsonnet node.
+// ToNode converts a Jsonnet component to a Jsonnet node. The node imports
+// the component's source, so evaluation errors refer to its file and lines.
 func (j *Jsonnet) ToNode(envName string) (string, ast.Node, error) {
-	n, err := jsonnet.ImportNodeFromFs(j.source, j.app.Fs())
+	exists, err := afero.Exists(j.app.Fs(), j.source)
 	if err != nil {
 		return "", nil, err
 	}
+	if !exists {
+		return "", nil, errors.Errorf("component source %s does not exist", j.source)
+	}
+
+	n := &ast.Import{
+		File: &ast.LiteralString{
+			Value: j.source,
+			Kind:  ast.StringDouble,
+		},
+	}
 
 	return j.Name(true), n, nil
 }
@@ -218,7 +228,7 @@ func (j *Jsonnet) readModuleParams() (string, error) {
 }
 
 func (j *Jsonnet) writeParams(src string) error {
-	return afero.WriteFile(j.app.Fs(), j.paramsPath, []byte(src), 0644)
+	return writeParams(j.app.Fs(), j.paramsPath, src)
 }
 
 func (j *Jsonnet) log() *log.Entry {
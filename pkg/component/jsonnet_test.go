@@ -19,6 +19,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/google/go-jsonnet/ast"
 	"github.com/ksonnet/ksonnet/pkg/app/mocks"
 	"github.com/ksonnet/ksonnet/pkg/util/test"
 	"github.com/spf13/afero"
@@ -162,6 +163,27 @@ func TestJsonnet_Summarize(t *testing.T) {
 	})
 }
 
+func TestJsonnet_ToNode(t *testing.T) {
+	test.WithApp(t, "/app", func(a *mocks.App, fs afero.Fs) {
+		test.StageFile(t, fs, "guestbook/guestbook-ui.jsonnet", "/app/components/nested/guestbook-ui.jsonnet")
+
+		c := NewJsonnet(a, "nested", "/app/components/nested/guestbook-ui.jsonnet", "/app/components/nested/params.libsonnet")
+
+		name, node, err := c.ToNode("default")
+		require.NoError(t, err)
+
+		require.Equal(t, "nested.guestbook-ui", name)
+
+		imp, ok := node.(*ast.Import)
+		require.True(t, ok)
+		require.Equal(t, "/app/components/nested/guestbook-ui.jsonnet", imp.File.Value)
+
+		missing := NewJsonnet(a, "", "/app/components/missing.jsonnet", "/app/components/params.libsonnet")
+		_, _, err = missing.ToNode("default")
+		require.Error(t, err)
+	})
+}
+
 func TestJsonnet_SetParam(t *testing.T) {
 	test.WithApp(t, "/app", func(a *mocks.App, fs afero.Fs) {
 
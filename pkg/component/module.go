@@ -201,7 +201,7 @@ func (m *FilesystemModule) DeleteParam(path []string) error {
 }
 
 func (m *FilesystemModule) writeParams(src string) error {
-	return afero.WriteFile(m.app.Fs(), m.ParamsPath(), []byte(src), 0644)
+	return writeParams(m.app.Fs(), m.ParamsPath(), src)
 }
 
 // Dir is the absolute directory for a module.
@@ -303,7 +303,17 @@ func (m *FilesystemModule) ResolvedParams(envName string) (string, error) {
 		return "", errors.Wrap(err, "could not update params")
 	}
 
-	return applyGlobals(buf.String())
+	resolved, err := applyGlobals(buf.String())
+	if err != nil {
+		return "", err
+	}
+
+	mm, err := ReadModuleManifest(m.app.Fs(), m.Dir())
+	if err != nil {
+		return "", err
+	}
+
+	return applyModuleDefaults(resolved, mm.Params)
 }
 
 // Params returns the params for a module.
@@ -358,6 +368,12 @@ func ModulesFromEnv(a app.App, env string) ([]Module, error) {
 				return nil, err
 			}
 
+			if env != "" {
+				if err = CheckVisible(a.Fs(), m, env); err != nil {
+					return nil, err
+				}
+			}
+
 			modules = append(modules, m)
 		}
 	}
@@ -428,6 +444,9 @@ func (m *FilesystemModule) Components() ([]Component, error) {
 		case ".jsonnet":
 			component := NewJsonnet(m.app, m.Name(), path, m.ParamsPath())
 			components = append(components, component)
+		case "." + TypeGenerator:
+			component := NewGenerator(m.app, m.Name(), path)
+			components = append(components, component)
 		}
 	}
 
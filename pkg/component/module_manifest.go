@@ -0,0 +1,145 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package component
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+	"github.com/ksonnet/ksonnet/pkg/util/jsonnet"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+// moduleManifestFile is the manifest of a module, in the module's directory.
+const moduleManifestFile = "module.yaml"
+
+// ModuleManifest describes a module. It is optional; a module without one has
+// no description or owners, and may be targeted by every environment.
+type ModuleManifest struct {
+	// Description is what the module is for.
+	Description string `json:"description,omitempty"`
+	// Owners are the people or teams responsible for the module.
+	Owners []string `json:"owners,omitempty"`
+	// Params are defaults for the params of every component in the module.
+	// Component params and globals take precedence.
+	Params map[string]interface{} `json:"params,omitempty"`
+	// Environments are the environments which may target the module. If it is
+	// empty, every environment may.
+	Environments []string `json:"environments,omitempty"`
+}
+
+// ReadModuleManifest reads the manifest in a module directory. If there is
+// none, it returns an empty manifest.
+func ReadModuleManifest(fs afero.Fs, dir string) (*ModuleManifest, error) {
+	path := filepath.Join(dir, moduleManifestFile)
+
+	b, err := afero.ReadFile(fs, path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &ModuleManifest{}, nil
+		}
+		return nil, err
+	}
+
+	var mm ModuleManifest
+	if err = yaml.Unmarshal(b, &mm); err != nil {
+		return nil, errors.Wrapf(err, "parsing %s", path)
+	}
+
+	return &mm, nil
+}
+
+// Visible returns true if an environment may target the module.
+func (mm *ModuleManifest) Visible(envName string) bool {
+	if len(mm.Environments) == 0 {
+		return true
+	}
+
+	for _, name := range mm.Environments {
+		if name == envName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ModuleNotVisibleError is returned when an environment targets a module its
+// manifest does not allow.
+type ModuleNotVisibleError struct {
+	Module       string
+	Environment  string
+	Environments []string
+}
+
+func (e *ModuleNotVisibleError) Error() string {
+	module := e.Module
+	if module == "/" {
+		module = ""
+	}
+
+	return moduleErrorMsg(fmt.Sprintf("%%s may not be targeted by environment %q", e.Environment), module)
+}
+
+// CheckVisible returns a *ModuleNotVisibleError if envName may not target m.
+func CheckVisible(fs afero.Fs, m Module, envName string) error {
+	mm, err := ReadModuleManifest(fs, m.Dir())
+	if err != nil {
+		return err
+	}
+
+	if mm.Visible(envName) {
+		return nil
+	}
+
+	return &ModuleNotVisibleError{
+		Module:       m.Name(),
+		Environment:  envName,
+		Environments: mm.Environments,
+	}
+}
+
+// applyModuleDefaults merges default params under the params of each
+// component in resolved params.
+func applyModuleDefaults(resolved string, defaults map[string]interface{}) (string, error) {
+	if len(defaults) == 0 {
+		return resolved, nil
+	}
+
+	b, err := json.Marshal(defaults)
+	if err != nil {
+		return "", err
+	}
+
+	vm := jsonnet.NewVM()
+	vm.ExtCode("params", resolved)
+	vm.ExtCode("defaults", string(b))
+	return vm.EvaluateSnippet("applyModuleDefaults", snippetMapDefaults)
+}
+
+var snippetMapDefaults = `
+local params = std.extVar("params");
+local defaults = std.extVar("defaults");
+local applyDefaults = function(key, value) std.mergePatch(defaults, value);
+
+{
+	components: std.mapWithKey(applyDefaults, params.components)
+}
+`
@@ -0,0 +1,90 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package component
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadModuleManifest(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	mm, err := ReadModuleManifest(fs, "/app/components/db")
+	require.NoError(t, err)
+	assert.Equal(t, &ModuleManifest{}, mm)
+
+	manifest := "description: Databases\nowners:\n- data-team\nparams:\n  replicas: 3\nenvironments:\n- prod\n"
+	require.NoError(t, afero.WriteFile(fs, "/app/components/db/module.yaml", []byte(manifest), 0644))
+
+	mm, err = ReadModuleManifest(fs, "/app/components/db")
+	require.NoError(t, err)
+
+	expected := &ModuleManifest{
+		Description:  "Databases",
+		Owners:       []string{"data-team"},
+		Params:       map[string]interface{}{"replicas": float64(3)},
+		Environments: []string{"prod"},
+	}
+	assert.Equal(t, expected, mm)
+
+	require.NoError(t, afero.WriteFile(fs, "/app/components/db/module.yaml", []byte("owners: {"), 0644))
+	_, err = ReadModuleManifest(fs, "/app/components/db")
+	require.Error(t, err)
+}
+
+func TestModuleManifest_Visible(t *testing.T) {
+	mm := &ModuleManifest{}
+	assert.True(t, mm.Visible("default"))
+
+	mm.Environments = []string{"prod"}
+	assert.True(t, mm.Visible("prod"))
+	assert.False(t, mm.Visible("default"))
+}
+
+func TestCheckVisible(t *testing.T) {
+	test.WithApp(t, "/app", func(a *mocks.App, fs afero.Fs) {
+		m := NewModule(a, "db")
+		require.NoError(t, CheckVisible(fs, m, "default"))
+
+		require.NoError(t, afero.WriteFile(fs, "/app/components/db/module.yaml", []byte("environments:\n- prod\n"), 0644))
+		require.NoError(t, CheckVisible(fs, m, "prod"))
+
+		err := CheckVisible(fs, m, "default")
+		require.IsType(t, &ModuleNotVisibleError{}, err)
+		assert.Equal(t, `module "db" may not be targeted by environment "default"`, err.Error())
+	})
+}
+
+func Test_applyModuleDefaults(t *testing.T) {
+	resolved := `{"components":{"redis":{"replicas":1},"postgres":{"image":"postgres"}}}`
+	defaults := map[string]interface{}{"replicas": 3, "image": "busybox"}
+
+	got, err := applyModuleDefaults(resolved, defaults)
+	require.NoError(t, err)
+
+	expected := `{"components":{"postgres":{"image":"postgres","replicas":3},"redis":{"image":"busybox","replicas":1}}}`
+	assert.JSONEq(t, expected, got)
+
+	got, err = applyModuleDefaults(resolved, nil)
+	require.NoError(t, err)
+	assert.Equal(t, resolved, got)
+}
@@ -0,0 +1,56 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package component
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// reOriginDirective matches the directives written at the top of a
+	// component generated from a prototype.
+	reOriginDirective = regexp.MustCompile(`^\s*(?://|#)\s*@(prototype|package)\s+(\S+)\s*$`)
+)
+
+// Origin is the prototype, and the package and version of the prototype, a
+// component was generated from.
+type Origin struct {
+	Prototype string
+	Package   string
+}
+
+// ReadOrigin reads the origin directives at the top of a component's text.
+// Fields are blank if the component doesn't record them.
+func ReadOrigin(text string) Origin {
+	var origin Origin
+
+	for _, line := range strings.Split(text, "\n") {
+		match := reOriginDirective.FindStringSubmatch(line)
+		if match == nil {
+			break
+		}
+
+		switch match[1] {
+		case "prototype":
+			origin.Prototype = match[2]
+		case "package":
+			origin.Package = match[2]
+		}
+	}
+
+	return origin
+}
@@ -0,0 +1,62 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package component
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadOrigin(t *testing.T) {
+	cases := []struct {
+		name     string
+		text     string
+		expected Origin
+	}{
+		{
+			name: "jsonnet",
+			text: "// @prototype io.ksonnet.pkg.redis\n// @package incubator/redis@1.0.0\n{}",
+			expected: Origin{
+				Prototype: "io.ksonnet.pkg.redis",
+				Package:   "incubator/redis@1.0.0",
+			},
+		},
+		{
+			name: "yaml",
+			text: "# @prototype io.ksonnet.pkg.redis\n# @package incubator/redis@1.0.0\nkind: Service",
+			expected: Origin{
+				Prototype: "io.ksonnet.pkg.redis",
+				Package:   "incubator/redis@1.0.0",
+			},
+		},
+		{
+			name:     "system prototype",
+			text:     "// @prototype io.ksonnet.pkg.deployed-service\n{}",
+			expected: Origin{Prototype: "io.ksonnet.pkg.deployed-service"},
+		},
+		{
+			name: "directive after the header",
+			text: "{}\n// @package incubator/redis@1.0.0",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ReadOrigin(tc.text))
+		})
+	}
+}
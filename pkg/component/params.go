@@ -18,10 +18,22 @@ package component
 import (
 	"regexp"
 
+	"github.com/ksonnet/ksonnet/pkg/params"
 	"github.com/ksonnet/ksonnet/pkg/util/jsonnet"
 	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
 )
 
+// writeParams formats a params.libsonnet file, and writes it.
+func writeParams(fs afero.Fs, path, src string) error {
+	formatted, err := params.Format(src)
+	if err != nil {
+		return err
+	}
+
+	return afero.WriteFile(fs, path, []byte(formatted), defaultFilePermissions)
+}
+
 func applyGlobals(params string) (string, error) {
 	vm := jsonnet.NewVM()
 
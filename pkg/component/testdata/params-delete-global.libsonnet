@@ -2,12 +2,12 @@
   global: {},
   components: {
     a: {
-      other: 1,
       metadata: {
         labels: {
           locala: 'local',
         },
       },
+      other: 1,
     },
   },
 }
\ No newline at end of file
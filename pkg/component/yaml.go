@@ -277,7 +277,7 @@ func (y *YAML) readModuleParams() (string, error) {
 }
 
 func (y *YAML) writeParams(src string) error {
-	return afero.WriteFile(y.app.Fs(), y.paramsPath, []byte(src), 0644)
+	return writeParams(y.app.Fs(), y.paramsPath, src)
 }
 
 // Summarize generates a summary for a YAML component. For each manifest, it will
@@ -0,0 +1,175 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package dev
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/diff"
+	"github.com/spf13/afero"
+)
+
+// Cycle is the result of re-rendering, diffing, and applying components.
+type Cycle struct {
+	Number int
+	// Files are the files which changed. They are empty for the initial cycle.
+	Files []string
+	// Components are the components which were re-rendered. They are empty if
+	// all components were re-rendered.
+	Components []string
+	Report     *diff.Report
+	Applied    bool
+	Duration   time.Duration
+	Err        error
+}
+
+// Loop re-renders, diffs, and applies an app's components each time its
+// files change.
+type Loop struct {
+	fs       afero.Fs
+	root     string
+	reportFn func(components []string) (*diff.Report, error)
+	applyFn  func(components []string) error
+	nowFn    func() time.Time
+}
+
+// NewLoop creates an instance of Loop for the app in root. reportFn compares
+// the rendered components with the cluster, and applyFn applies them. An empty
+// list of components means all components.
+func NewLoop(fs afero.Fs, root string, reportFn func([]string) (*diff.Report, error), applyFn func([]string) error) *Loop {
+	return &Loop{
+		fs:       fs,
+		root:     root,
+		reportFn: reportFn,
+		applyFn:  applyFn,
+		nowFn:    time.Now,
+	}
+}
+
+// Run runs a cycle for all components, and then a cycle for each batch of
+// changed files, until stopCh or changes is closed. Each cycle is passed to
+// handle. Cycle errors are reported in the cycle so the loop keeps running
+// while the app is being edited; an error returned by handle stops the loop.
+func (l *Loop) Run(stopCh <-chan struct{}, changes <-chan []string, handle func(Cycle) error) error {
+	number := 1
+	if err := handle(l.cycle(number, nil)); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stopCh:
+			return nil
+		case files, ok := <-changes:
+			if !ok {
+				return nil
+			}
+
+			number++
+			if err := handle(l.cycle(number, files)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (l *Loop) cycle(number int, files []string) Cycle {
+	start := l.nowFn()
+
+	c := Cycle{
+		Number:     number,
+		Files:      files,
+		Components: l.components(files),
+	}
+
+	c.Report, c.Err = l.reportFn(c.Components)
+	if c.Err == nil && needsApply(c.Report) {
+		if c.Err = l.applyFn(c.Components); c.Err == nil {
+			c.Applied = true
+		}
+	}
+
+	c.Duration = l.nowFn().Sub(start)
+	return c
+}
+
+// needsApply reports if objects were added or changed. Removed objects are
+// not deleted by the loop, so they alone do not need an apply.
+func needsApply(report *diff.Report) bool {
+	for _, oc := range report.Objects {
+		if oc.Type != diff.ChangeRemoved {
+			return true
+		}
+	}
+
+	return false
+}
+
+// components returns the components which are affected by changed files. If
+// any file other than an existing component changed, such as params, libraries,
+// or environments, all components are affected, and nil is returned.
+func (l *Loop) components(files []string) []string {
+	if len(files) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+
+	for _, file := range files {
+		name, ok := componentName(file)
+		if !ok {
+			return nil
+		}
+
+		// Removed components can no longer be rendered on their own.
+		if exists, err := afero.Exists(l.fs, filepath.Join(l.root, file)); err != nil || !exists {
+			return nil
+		}
+
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// componentName returns the name of the component in a file relative to the
+// root of an app. Components in modules are named module.component.
+func componentName(file string) (string, bool) {
+	parts := strings.Split(filepath.ToSlash(file), "/")
+	if len(parts) < 2 || parts[0] != "components" {
+		return "", false
+	}
+
+	base := parts[len(parts)-1]
+	switch filepath.Ext(base) {
+	case ".jsonnet", ".yaml", ".json":
+	default:
+		return "", false
+	}
+
+	name := strings.TrimSuffix(base, filepath.Ext(base))
+	module := parts[1 : len(parts)-1]
+
+	return strings.Join(append(module, name), "."), true
+}
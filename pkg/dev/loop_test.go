@@ -0,0 +1,152 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package dev
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/diff"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoop_Run(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/app/components/guestbook.jsonnet", []byte("{}"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/app/components/db/redis.jsonnet", []byte("{}"), 0644))
+
+	changed := &diff.Report{Objects: []diff.ObjectChange{{Type: diff.ChangeChanged, Kind: "Service", Name: "guestbook"}}}
+
+	reports := []struct {
+		report *diff.Report
+		err    error
+	}{
+		{report: changed},
+		{report: &diff.Report{Objects: []diff.ObjectChange{{Type: diff.ChangeRemoved, Kind: "Service", Name: "old"}}}},
+		{err: errors.New("render failed")},
+		{report: changed},
+	}
+
+	var reported, applied [][]string
+	reportFn := func(components []string) (*diff.Report, error) {
+		reported = append(reported, components)
+		r := reports[0]
+		reports = reports[1:]
+		return r.report, r.err
+	}
+	applyFn := func(components []string) error {
+		applied = append(applied, components)
+		return nil
+	}
+
+	l := NewLoop(fs, "/app", reportFn, applyFn)
+	l.nowFn = func() time.Time { return time.Time{} }
+
+	changes := make(chan []string, 3)
+	changes <- []string{"components/guestbook.jsonnet"}
+	changes <- []string{"components/params.libsonnet"}
+	changes <- []string{"components/db/redis.jsonnet", "components/guestbook.jsonnet"}
+	close(changes)
+
+	var cycles []Cycle
+	err := l.Run(make(chan struct{}), changes, func(c Cycle) error {
+		cycles = append(cycles, c)
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.Len(t, cycles, 4)
+
+	assert.Equal(t, 1, cycles[0].Number)
+	assert.Nil(t, cycles[0].Components)
+	assert.True(t, cycles[0].Applied)
+
+	assert.Equal(t, []string{"guestbook"}, cycles[1].Components)
+	assert.False(t, cycles[1].Applied)
+	assert.NoError(t, cycles[1].Err)
+
+	assert.Nil(t, cycles[2].Components)
+	assert.False(t, cycles[2].Applied)
+	assert.EqualError(t, cycles[2].Err, "render failed")
+
+	assert.Equal(t, 4, cycles[3].Number)
+	assert.Equal(t, []string{"db.redis", "guestbook"}, cycles[3].Components)
+	assert.True(t, cycles[3].Applied)
+
+	assert.Equal(t, [][]string{nil, {"guestbook"}, nil, {"db.redis", "guestbook"}}, reported)
+	assert.Equal(t, [][]string{nil, {"db.redis", "guestbook"}}, applied)
+}
+
+func TestLoop_Run_handle_error(t *testing.T) {
+	l := NewLoop(afero.NewMemMapFs(), "/app",
+		func([]string) (*diff.Report, error) { return &diff.Report{}, nil },
+		func([]string) error { return nil })
+
+	err := l.Run(make(chan struct{}), make(chan []string), func(Cycle) error {
+		return errors.New("fail")
+	})
+	require.EqualError(t, err, "fail")
+}
+
+func TestLoop_components(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/app/components/guestbook.jsonnet", []byte("{}"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/app/components/db/redis.yaml", []byte("{}"), 0644))
+
+	cases := []struct {
+		name     string
+		files    []string
+		expected []string
+	}{
+		{
+			name:     "component",
+			files:    []string{"components/guestbook.jsonnet"},
+			expected: []string{"guestbook"},
+		},
+		{
+			name:     "component in a module",
+			files:    []string{"components/db/redis.yaml"},
+			expected: []string{"db.redis"},
+		},
+		{
+			name:  "params",
+			files: []string{"components/guestbook.jsonnet", "components/params.libsonnet"},
+		},
+		{
+			name:  "library",
+			files: []string{"lib/util.libsonnet"},
+		},
+		{
+			name:  "environment",
+			files: []string{"environments/dev/main.jsonnet"},
+		},
+		{
+			name:  "removed component",
+			files: []string{"components/removed.jsonnet"},
+		},
+	}
+
+	l := NewLoop(fs, "/app", nil, nil)
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, l.components(tc.files))
+		})
+	}
+}
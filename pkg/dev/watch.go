@@ -0,0 +1,221 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package dev runs a development loop which re-renders, diffs, and applies an
+// app's components each time its files change.
+package dev
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultDebounce is the default period files must stop changing for before
+	// a cycle runs.
+	DefaultDebounce = 500 * time.Millisecond
+)
+
+var (
+	// watchedDirs are the directories of an app which are watched recursively.
+	watchedDirs = []string{"components", "environments", "lib", "vendor"}
+	// watchedFiles are the files in the root of an app which are watched.
+	watchedFiles = []string{"app.yaml"}
+)
+
+// Watcher watches the files of an app, and sends batches of the files which
+// changed once they stop changing.
+type Watcher struct {
+	root     string
+	debounce time.Duration
+	watcher  *fsnotify.Watcher
+}
+
+// NewWatcher creates an instance of Watcher for the app in root. Files are
+// batched until none have changed for the debounce period.
+func NewWatcher(root string, debounce time.Duration) (*Watcher, error) {
+	if debounce <= 0 {
+		return nil, errors.Errorf("debounce period must be positive; got %s", debounce)
+	}
+
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, errors.Wrap(err, "creating file watcher")
+	}
+
+	w := &Watcher{
+		root:     root,
+		debounce: debounce,
+		watcher:  fw,
+	}
+
+	if err = fw.Add(root); err != nil {
+		fw.Close()
+		return nil, errors.Wrapf(err, "watching %s", root)
+	}
+
+	for _, dir := range watchedDirs {
+		if err = w.addTree(filepath.Join(root, dir)); err != nil {
+			fw.Close()
+			return nil, err
+		}
+	}
+
+	return w, nil
+}
+
+// Run sends batches of changed files, relative to the root of the app, to
+// changes until stopCh is closed. The watcher is closed when Run returns.
+func (w *Watcher) Run(stopCh <-chan struct{}, changes chan<- []string) error {
+	defer w.watcher.Close()
+
+	pending := make(map[string]bool)
+	var timer <-chan time.Time
+
+	for {
+		select {
+		case <-stopCh:
+			return nil
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return errors.Wrap(err, "watching files")
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			rel, watched := w.relative(event.Name)
+			if !watched || event.Op == fsnotify.Chmod {
+				continue
+			}
+
+			if event.Op&fsnotify.Create != 0 {
+				if fi, err := os.Stat(event.Name); err == nil && fi.IsDir() {
+					// Files may have been written before the directory was watched.
+					if err = w.addTree(event.Name); err != nil {
+						return err
+					}
+				}
+			}
+
+			log.Debugf("[dev.Watcher] %s", event)
+			pending[rel] = true
+			timer = time.After(w.debounce)
+		case <-timer:
+			timer = nil
+
+			var batch []string
+			for rel := range pending {
+				batch = append(batch, rel)
+			}
+			sort.Strings(batch)
+			pending = make(map[string]bool)
+
+			select {
+			case changes <- batch:
+			case <-stopCh:
+				return nil
+			}
+		}
+	}
+}
+
+// addTree watches a directory and its subdirectories. Hidden directories are
+// skipped.
+func (w *Watcher) addTree(dir string) error {
+	return filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		if !fi.IsDir() {
+			return nil
+		}
+
+		if path != dir && ignored(fi.Name()) {
+			return filepath.SkipDir
+		}
+
+		if err = w.watcher.Add(path); err != nil {
+			return errors.Wrapf(err, "watching %s", path)
+		}
+
+		return nil
+	})
+}
+
+// relative returns the path of a file relative to the root of the app, and
+// whether the file is watched.
+func (w *Watcher) relative(path string) (string, bool) {
+	rel, err := filepath.Rel(w.root, path)
+	if err != nil {
+		return "", false
+	}
+
+	return rel, watched(rel)
+}
+
+// watched reports if a path relative to the root of an app is watched.
+func watched(rel string) bool {
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for _, part := range parts {
+		if ignored(part) {
+			return false
+		}
+	}
+
+	if len(parts) == 1 {
+		return contains(watchedFiles, parts[0])
+	}
+
+	return contains(watchedDirs, parts[0])
+}
+
+// ignored reports if a file is hidden, or is a temporary file written by an
+// editor.
+func ignored(name string) bool {
+	switch {
+	case name == "", name == "4913":
+		return true
+	case strings.HasPrefix(name, "."), strings.HasPrefix(name, "#"):
+		return true
+	case strings.HasSuffix(name, "~"), strings.HasSuffix(name, ".swp"), strings.HasSuffix(name, ".swx"):
+		return true
+	default:
+		return false
+	}
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,97 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package dev
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcher_Run(t *testing.T) {
+	root, err := ioutil.TempDir("", "TestWatcher_Run")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "components"), 0755))
+
+	w, err := NewWatcher(root, 100*time.Millisecond)
+	require.NoError(t, err)
+
+	stopCh := make(chan struct{})
+	changes := make(chan []string)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- w.Run(stopCh, changes)
+	}()
+
+	write := func(rel string) {
+		path := filepath.Join(root, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, []byte("{}"), 0644))
+	}
+
+	write("components/guestbook.jsonnet")
+	write("components/.guestbook.jsonnet.swp")
+	write("app.yaml")
+	write("README.md")
+
+	select {
+	case batch := <-changes:
+		assert.Equal(t, []string{"app.yaml", "components/guestbook.jsonnet"}, batch)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for changes")
+	}
+
+	close(stopCh)
+	require.NoError(t, <-errCh)
+}
+
+func TestNewWatcher_invalid_debounce(t *testing.T) {
+	_, err := NewWatcher("/app", 0)
+	require.Error(t, err)
+}
+
+func Test_watched(t *testing.T) {
+	cases := []struct {
+		rel      string
+		expected bool
+	}{
+		{rel: "app.yaml", expected: true},
+		{rel: "components/guestbook.jsonnet", expected: true},
+		{rel: "components/params.libsonnet", expected: true},
+		{rel: "environments/dev/main.jsonnet", expected: true},
+		{rel: "lib/util.libsonnet", expected: true},
+		{rel: "vendor/incubator/redis/redis.libsonnet", expected: true},
+		{rel: "README.md"},
+		{rel: ".ks_environment"},
+		{rel: "components/.guestbook.jsonnet.swp"},
+		{rel: "components/guestbook.jsonnet~"},
+		{rel: "components/#guestbook.jsonnet#"},
+		{rel: "lib/.git/HEAD"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.rel, func(t *testing.T) {
+			assert.Equal(t, tc.expected, watched(tc.rel))
+		})
+	}
+}
@@ -18,35 +18,240 @@ package diff
 import (
 	"bytes"
 	"io"
+	"os/exec"
+	"strings"
 
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/client"
 	"github.com/ksonnet/ksonnet/pkg/cluster"
 	"github.com/ksonnet/ksonnet/pkg/pipeline"
+	"github.com/ksonnet/ksonnet/pkg/redact"
 	"github.com/pkg/errors"
 	godiff "github.com/shazow/go-diff"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
+const (
+	// StrategyClient compares rendered manifests with the configuration
+	// ksonnet last applied to the cluster.
+	StrategyClient = "client"
+	// StrategyServer compares the result of a server-side dry-run of the
+	// rendered manifests with the live objects in the cluster. Fields
+	// populated by defaulting and admission controllers are present on
+	// both sides, so they do not show up as differences.
+	StrategyServer = "server"
+)
+
+// Strategies are the supported diff strategies.
+var Strategies = []string{StrategyClient, StrategyServer}
+
+// Config is configuration for generating differences.
+type Config struct {
+	App          app.App
+	ClientConfig *client.Config
+	Components   []string
+	// Strategy is the diff strategy. It defaults to StrategyClient.
+	Strategy string
+	// IgnoreServerFields removes fields managed by the server, such as
+	// resourceVersion and managedFields, from objects before they are compared.
+	IgnoreServerFields bool
+	// Objects are pre-rendered objects which are used for local locations
+	// instead of evaluating the app's components.
+	Objects []*unstructured.Unstructured
+	// ShowSecrets compares the data of Secrets, and the fields selected by
+	// the environments' redact rules, instead of redacting them.
+	ShowSecrets bool
+}
+
 // Differ generates the differences between two Locations.
 type Differ struct {
 	App        app.App
 	Config     *client.Config
 	Components []string
 
+	ignoreServerFields bool
+	ignoreRules        []*app.DiffIgnoreConfig
+	redactor           *redact.Redactor
+
 	localGen  yamlGenerator
 	remoteGen yamlGenerator
+	showFn    func(io.Writer, []*unstructured.Unstructured) error
+	runCmdFn  func(*exec.Cmd) error
 }
 
 // DefaultDiff runs diff with default options.
-func DefaultDiff(a app.App, config *client.Config, components []string, l1 *Location, l2 *Location) (io.Reader, error) {
-	differ := New(a, config, components)
+func DefaultDiff(config Config, l1 *Location, l2 *Location) (io.Reader, error) {
+	differ, err := newConfiguredDiffer(config, l1, l2)
+	if err != nil {
+		return nil, err
+	}
+
 	return differ.Diff(l2, l1)
 }
 
+// DefaultReport generates a structured diff report with default options.
+func DefaultReport(config Config, l1 *Location, l2 *Location) (*Report, error) {
+	differ, err := newConfiguredDiffer(config, l1, l2)
+	if err != nil {
+		return nil, err
+	}
+
+	return differ.Report(l2, l1)
+}
+
+func newConfiguredDiffer(config Config, locations ...*Location) (*Differ, error) {
+	var opts []Opt
+	switch config.Strategy {
+	default:
+		return nil, errors.Errorf("unknown diff strategy %q; valid strategies are %s",
+			config.Strategy, strings.Join(Strategies, ", "))
+	case "", StrategyClient:
+	case StrategyServer:
+		opts = append(opts, ServerStrategy())
+	}
+
+	if config.IgnoreServerFields {
+		opts = append(opts, IgnoreServerFields())
+	}
+
+	if config.Objects != nil {
+		opts = append(opts, LocalObjects(config.Objects))
+	}
+
+	rules, err := environmentIgnoreRules(config.App, locations)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) > 0 {
+		opts = append(opts, IgnoreRules(rules))
+	}
+
+	if !config.ShowSecrets {
+		redactor, err := environmentRedactor(config.App, locations)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, Redact(redactor))
+	}
+
+	return New(config.App, config.ClientConfig, config.Components, opts...), nil
+}
+
+// Opt is an option for configuring Differ.
+type Opt func(*Differ)
+
+// ServerStrategy configures Differ to use server-side dry-run results for local
+// locations and live objects for remote locations.
+func ServerStrategy() Opt {
+	return func(d *Differ) {
+		d.localGen = newYamlServer(d.App, d.Config)
+
+		yr := newYamlRemote(d.App, d.Config)
+		yr.collectObjectsFn = cluster.CollectLiveObjects
+		d.remoteGen = yr
+	}
+}
+
+// IgnoreServerFields configures Differ to remove fields managed by the server
+// from objects before they are compared.
+func IgnoreServerFields() Opt {
+	return func(d *Differ) {
+		d.ignoreServerFields = true
+	}
+}
+
+// IgnoreRules configures Differ to leave the objects and fields selected by
+// rules out of the objects which are compared.
+func IgnoreRules(rules []*app.DiffIgnoreConfig) Opt {
+	return func(d *Differ) {
+		d.ignoreRules = append(d.ignoreRules, rules...)
+	}
+}
+
+// environmentIgnoreRules returns the diff ignore rules of the environments of
+// locations.
+func environmentIgnoreRules(a app.App, locations []*Location) ([]*app.DiffIgnoreConfig, error) {
+	var rules []*app.DiffIgnoreConfig
+	seen := make(map[string]bool)
+
+	for _, location := range locations {
+		if err := location.Err(); err != nil {
+			return nil, err
+		}
+
+		name := location.EnvName()
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		env, err := a.Environment(name)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, env.DiffIgnore...)
+	}
+
+	return rules, nil
+}
+
+// Redact configures Differ to redact the values of the objects which are
+// compared. Both locations are redacted with the same Redactor, so changes to
+// redacted values are still found.
+func Redact(redactor *redact.Redactor) Opt {
+	return func(d *Differ) {
+		d.redactor = redactor
+	}
+}
+
+// environmentRedactor returns a Redactor with the redact rules of the
+// environments of locations.
+func environmentRedactor(a app.App, locations []*Location) (*redact.Redactor, error) {
+	var rules []*app.RedactConfig
+	seen := make(map[string]bool)
+
+	for _, location := range locations {
+		name := location.EnvName()
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		env, err := a.Environment(name)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, env.Redact...)
+	}
+
+	return redact.New(rules)
+}
+
+// LocalObjects configures Differ to use pre-rendered objects for local
+// locations instead of evaluating the app's components. It must follow
+// ServerStrategy if both are used.
+func LocalObjects(objects []*unstructured.Unstructured) Opt {
+	return func(d *Differ) {
+		collectObjectsFn := func(app.App, string, []string) ([]*unstructured.Unstructured, error) {
+			collected := make([]*unstructured.Unstructured, 0, len(objects))
+			for _, obj := range objects {
+				collected = append(collected, obj.DeepCopy())
+			}
+			return collected, nil
+		}
+
+		switch gen := d.localGen.(type) {
+		case *yamlLocal:
+			gen.collectObjectsFn = collectObjectsFn
+		case *yamlServer:
+			gen.collectObjectsFn = collectObjectsFn
+		}
+	}
+}
+
 // New creates an instance of Differ.
-func New(a app.App, config *client.Config, components []string) *Differ {
+func New(a app.App, config *client.Config, components []string, opts ...Opt) *Differ {
 	yl := newYamlLocal(a)
 	yr := newYamlRemote(a, config)
 
@@ -56,11 +261,21 @@ func New(a app.App, config *client.Config, components []string) *Differ {
 		Components: components,
 		localGen:   yl,
 		remoteGen:  yr,
+		showFn:     cluster.ShowYAML,
+		runCmdFn:   runCmd,
+	}
+
+	for _, opt := range opts {
+		opt(d)
 	}
 
 	return d
 }
 
+func runCmd(cmd *exec.Cmd) error {
+	return cmd.Run()
+}
+
 // Diff generates the differences between two locations.
 func (d *Differ) Diff(location1, location2 *Location) (io.Reader, error) {
 	logrus.WithFields(logrus.Fields{
@@ -68,12 +283,17 @@ func (d *Differ) Diff(location1, location2 *Location) (io.Reader, error) {
 		"src2": location2.String(),
 	}).Debug("generating diff")
 
-	r1, err := d.toYAML(location1)
+	objects1, objects2, err := d.compared(location1, location2)
+	if err != nil {
+		return nil, err
+	}
+
+	r1, err := showObjects(d.showFn, objects1)
 	if err != nil {
 		return nil, err
 	}
 
-	r2, err := d.toYAML(location2)
+	r2, err := showObjects(d.showFn, objects2)
 	if err != nil {
 		return nil, err
 	}
@@ -86,7 +306,53 @@ func (d *Differ) Diff(location1, location2 *Location) (io.Reader, error) {
 	return &buf, nil
 }
 
-func (d *Differ) toYAML(location *Location) (io.ReadSeeker, error) {
+// compared returns the objects of both locations, without the objects and
+// fields which are ignored, and with their redacted values masked.
+func (d *Differ) compared(location1, location2 *Location) ([]*unstructured.Unstructured, []*unstructured.Unstructured, error) {
+	objects1, err := d.objects(location1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	objects2, err := d.objects(location2)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ig, err := newIgnorer(d.ignoreRules, objects1, objects2)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return d.redactor.Objects(ig.filter(objects1)), d.redactor.Objects(ig.filter(objects2)), nil
+}
+
+func (d *Differ) objects(location *Location) ([]*unstructured.Unstructured, error) {
+	gen, err := d.generator(location)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := gen.Objects(location, d.Components)
+	if err != nil {
+		return nil, err
+	}
+
+	if !d.ignoreServerFields {
+		return objects, nil
+	}
+
+	stripped := make([]*unstructured.Unstructured, 0, len(objects))
+	for _, obj := range objects {
+		obj = obj.DeepCopy()
+		cluster.StripServerFields(obj)
+		stripped = append(stripped, obj)
+	}
+
+	return stripped, nil
+}
+
+func (d *Differ) generator(location *Location) (yamlGenerator, error) {
 	if err := location.Err(); err != nil {
 		return nil, err
 	}
@@ -95,14 +361,15 @@ func (d *Differ) toYAML(location *Location) (io.ReadSeeker, error) {
 	default:
 		return nil, errors.Errorf("unknown destation %q", location.Destination())
 	case "local":
-		return d.localGen.Generate(location, d.Components)
+		return d.localGen, nil
 	case "remote":
-		return d.remoteGen.Generate(location, d.Components)
+		return d.remoteGen, nil
 	}
 }
 
 type yamlGenerator interface {
 	Generate(*Location, []string) (io.ReadSeeker, error)
+	Objects(*Location, []string) ([]*unstructured.Unstructured, error)
 }
 
 type yamlLocal struct {
@@ -125,17 +392,30 @@ func localCollectObjects(a app.App, envName string, componentNames []string) ([]
 }
 
 func (yl *yamlLocal) Generate(location *Location, components []string) (io.ReadSeeker, error) {
-	var buf bytes.Buffer
+	objects, err := yl.Objects(location, components)
+	if err != nil {
+		return nil, err
+	}
+
+	return showObjects(yl.showFn, objects)
+}
 
+func (yl *yamlLocal) Objects(location *Location, components []string) ([]*unstructured.Unstructured, error) {
 	objects, err := yl.collectObjectsFn(yl.app, location.EnvName(), components)
 	if err != nil {
 		return nil, err
-
 	}
 
 	cluster.UnstructuredSlice(objects).Sort()
 
-	if err := yl.showFn(&buf, objects); err != nil {
+	return objects, nil
+}
+
+// showObjects renders objects with showFn.
+func showObjects(showFn func(io.Writer, []*unstructured.Unstructured) error, objects []*unstructured.Unstructured) (io.ReadSeeker, error) {
+	var buf bytes.Buffer
+
+	if err := showFn(&buf, objects); err != nil {
 		return nil, err
 	}
 
@@ -161,8 +441,15 @@ func newYamlRemote(a app.App, config *client.Config) *yamlRemote {
 }
 
 func (yr *yamlRemote) Generate(location *Location, components []string) (io.ReadSeeker, error) {
-	var buf bytes.Buffer
+	objects, err := yr.Objects(location, components)
+	if err != nil {
+		return nil, err
+	}
 
+	return showObjects(yr.showFn, objects)
+}
+
+func (yr *yamlRemote) Objects(location *Location, components []string) ([]*unstructured.Unstructured, error) {
 	environment, err := yr.app.Environment(location.EnvName())
 	if err != nil {
 		return nil, err
@@ -174,16 +461,71 @@ func (yr *yamlRemote) Generate(location *Location, components []string) (io.Read
 		return nil, errors.Wrapf(err, "creating client for environment: %s", location.EnvName())
 	}
 
-	objects, err := yr.collectObjectsFn(environment.Destination.Namespace, clients, components)
+	// Multi-cluster environments are diffed once per destination, each with
+	// its own namespace.
+	namespace := yr.config.EnvironmentDestination(environment).Namespace
+
+	objects, err := yr.collectObjectsFn(namespace, clients, components)
 	if err != nil {
 		return nil, err
 	}
 
 	cluster.UnstructuredSlice(objects).Sort()
 
-	if err := yr.showFn(&buf, objects); err != nil {
+	return objects, nil
+}
+
+type yamlServer struct {
+	app              app.App
+	config           *client.Config
+	genClientsFn     func(a app.App, clientConfig *client.Config, envName string) (cluster.Clients, error)
+	dryRunnerFn      func(cluster.Clients) (cluster.ServerDryRunner, error)
+	collectObjectsFn func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error)
+	showFn           func(io.Writer, []*unstructured.Unstructured) error
+}
+
+func newYamlServer(a app.App, config *client.Config) *yamlServer {
+	return &yamlServer{
+		app:              a,
+		config:           config,
+		genClientsFn:     cluster.GenClients,
+		dryRunnerFn:      cluster.NewServerDryRunner,
+		collectObjectsFn: localCollectObjects,
+		showFn:           cluster.ShowYAML,
+	}
+}
+
+func (ys *yamlServer) Generate(location *Location, components []string) (io.ReadSeeker, error) {
+	objects, err := ys.Objects(location, components)
+	if err != nil {
 		return nil, err
 	}
 
-	return bytes.NewReader(buf.Bytes()), nil
+	return showObjects(ys.showFn, objects)
+}
+
+func (ys *yamlServer) Objects(location *Location, components []string) ([]*unstructured.Unstructured, error) {
+	objects, err := ys.collectObjectsFn(ys.app, location.EnvName(), components)
+	if err != nil {
+		return nil, err
+	}
+
+	clients, err := ys.genClientsFn(ys.app, ys.config, location.EnvName())
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating client for environment: %s", location.EnvName())
+	}
+
+	dryRunner, err := ys.dryRunnerFn(clients)
+	if err != nil {
+		return nil, err
+	}
+
+	predicted, err := cluster.ServerDryRunObjects(dryRunner, objects)
+	if err != nil {
+		return nil, errors.Wrap(err, "server dry-run")
+	}
+
+	cluster.UnstructuredSlice(predicted).Sort()
+
+	return predicted, nil
 }
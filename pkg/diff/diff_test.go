@@ -35,8 +35,13 @@ import (
 )
 
 type fakeYamlGenerator struct {
-	b   []byte
-	err error
+	b       []byte
+	objects []*unstructured.Unstructured
+	err     error
+}
+
+func (fyg *fakeYamlGenerator) Objects(l *Location, components []string) ([]*unstructured.Unstructured, error) {
+	return fyg.objects, fyg.err
 }
 
 func (fyg *fakeYamlGenerator) Generate(l *Location, components []string) (io.ReadSeeker, error) {
@@ -240,6 +245,165 @@ func Test_yamlRemote(t *testing.T) {
 	}
 }
 
+func Test_yamlRemote_destination_namespace(t *testing.T) {
+	test.WithApp(t, "/", func(appMock *mocks.App, fs afero.Fs) {
+		us := &app.EnvironmentDestinationSpec{Name: "us", Namespace: "us-prod"}
+		eu := &app.EnvironmentDestinationSpec{Name: "eu", Namespace: "eu-prod"}
+		appMock.On("Environment", "prod").Return(&app.EnvironmentConfig{
+			Destinations: []*app.EnvironmentDestinationSpec{us, eu},
+		}, nil)
+
+		cases := []struct {
+			name     string
+			config   *client.Config
+			expected string
+		}{
+			{name: "environment", config: &client.Config{}, expected: "us-prod"},
+			{name: "destination", config: (&client.Config{}).ForDestination(*eu), expected: "eu-prod"},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				yr := newYamlRemote(appMock, tc.config)
+				yr.genClientsFn = func(a app.App, clientConfig *client.Config, envName string) (cluster.Clients, error) {
+					return cluster.Clients{}, nil
+				}
+
+				var got string
+				yr.collectObjectsFn = func(namespace string, clients cluster.Clients, components []string) ([]*unstructured.Unstructured, error) {
+					got = namespace
+					return nil, nil
+				}
+
+				_, err := yr.Objects(NewLocation("prod"), nil)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.expected, got)
+			})
+		}
+	})
+}
+
+type fakeServerDryRunner struct {
+	err error
+}
+
+func (f *fakeServerDryRunner) DryRun(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	predicted := obj.DeepCopy()
+	predicted.SetResourceVersion("1")
+	return predicted, nil
+}
+
+func Test_yamlServer(t *testing.T) {
+	cases := []struct {
+		name      string
+		dryRunErr error
+		expected  string
+		isErr     bool
+	}{
+		{
+			name:     "sorted",
+			expected: sortedYAML,
+		},
+		{
+			name:      "dry-run failed",
+			dryRunErr: errors.New("fail"),
+			isErr:     true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			test.WithApp(t, "/", func(appMock *mocks.App, fs afero.Fs) {
+				ys := newYamlServer(appMock, &client.Config{})
+
+				ys.collectObjectsFn = func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+					return genObjects(), nil
+				}
+				ys.genClientsFn = func(a app.App, clientConfig *client.Config, envName string) (cluster.Clients, error) {
+					return cluster.Clients{}, nil
+				}
+				ys.dryRunnerFn = func(cluster.Clients) (cluster.ServerDryRunner, error) {
+					return &fakeServerDryRunner{err: tc.dryRunErr}, nil
+				}
+				ys.showFn = showYAML
+
+				rs, err := ys.Generate(NewLocation("default"), []string{})
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+
+				b, err := ioutil.ReadAll(rs)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.expected, string(b))
+			})
+		})
+	}
+}
+
+func TestDefaultDiff_invalid_strategy(t *testing.T) {
+	test.WithApp(t, "/", func(appMock *mocks.App, fs afero.Fs) {
+		config := Config{
+			App:          appMock,
+			ClientConfig: &client.Config{},
+			Strategy:     "invalid",
+		}
+		_, err := DefaultDiff(config, NewLocation("default"), NewLocation("default"))
+		require.Error(t, err)
+	})
+}
+
+func TestLocalObjects(t *testing.T) {
+	objects := []*unstructured.Unstructured{
+		{Object: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "b"}}},
+		{Object: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "a"}}},
+	}
+
+	cases := []struct {
+		name string
+		opts []Opt
+	}{
+		{
+			name: "client strategy",
+			opts: []Opt{LocalObjects(objects)},
+		},
+		{
+			name: "server strategy",
+			opts: []Opt{ServerStrategy(), LocalObjects(objects)},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			test.WithApp(t, "/", func(appMock *mocks.App, fs afero.Fs) {
+				differ := New(appMock, &client.Config{}, []string{}, tc.opts...)
+
+				var collected []*unstructured.Unstructured
+				var err error
+				switch gen := differ.localGen.(type) {
+				case *yamlLocal:
+					collected, err = gen.collectObjectsFn(appMock, "default", nil)
+				case *yamlServer:
+					collected, err = gen.collectObjectsFn(appMock, "default", nil)
+				default:
+					t.Fatalf("unexpected local generator %T", gen)
+				}
+				require.NoError(t, err)
+
+				require.Equal(t, objects, collected)
+				require.False(t, objects[0] == collected[0], "expected copies of the pre-rendered objects")
+			})
+		})
+	}
+}
+
 func showYAML(out io.Writer, objects []*unstructured.Unstructured) error {
 	for _, obj := range objects {
 		fmt.Fprintln(out, "---")
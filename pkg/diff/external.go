@@ -0,0 +1,143 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package diff
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// EnvDiffProgram is the environment variable which names an external
+	// program used to compare manifests.
+	EnvDiffProgram = "KS_DIFF"
+)
+
+var (
+	reUnsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+// DefaultExternal compares two locations with an external program using default options.
+func DefaultExternal(config Config, program string, l1 *Location, l2 *Location, stdout, stderr io.Writer) (bool, error) {
+	differ, err := newConfiguredDiffer(config, l1, l2)
+	if err != nil {
+		return false, err
+	}
+
+	return differ.External(program, l2, l1, stdout, stderr)
+}
+
+// External writes the objects for two locations to temporary directories, one
+// file per object, and compares the directories with an external program. The
+// program is invoked as `program [args...] <dir1> <dir2>`. Following the
+// convention of diff(1), an exit code of 1 from the program means differences
+// were found, and any other non-zero exit code is an error.
+func (d *Differ) External(program string, location1, location2 *Location, stdout, stderr io.Writer) (bool, error) {
+	args := strings.Fields(program)
+	if len(args) == 0 {
+		return false, errors.New("external diff program is empty")
+	}
+
+	objects1, objects2, err := d.compared(location1, location2)
+	if err != nil {
+		return false, err
+	}
+
+	tmpDir, err := ioutil.TempDir("", "ks-diff")
+	if err != nil {
+		return false, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	objectSets := [][]*unstructured.Unstructured{objects1, objects2}
+
+	var dirs []string
+	for i, location := range []*Location{location1, location2} {
+		dir := filepath.Join(tmpDir, reUnsafeFileChars.ReplaceAllString(location.String(), "_"))
+		if len(dirs) > 0 && dir == dirs[0] {
+			dir += "-2"
+		}
+
+		if err = writeObjects(objectSets[i], dir); err != nil {
+			return false, err
+		}
+		dirs = append(dirs, dir)
+	}
+
+	args = append(args, dirs...)
+
+	logrus.WithField("program", args[0]).Debug("running external diff")
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err = d.runCmdFn(cmd)
+	if err == nil {
+		return false, nil
+	}
+
+	if exitErr, ok := err.(*exec.ExitError); ok && exitCode(exitErr) == 1 {
+		return true, nil
+	}
+
+	return false, errors.Wrapf(err, "running external diff program %q", args[0])
+}
+
+// writeObjects writes objects to dir, one YAML file per object.
+func writeObjects(objects []*unstructured.Unstructured, dir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	for _, obj := range objects {
+		b, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+
+		path := filepath.Join(dir, objectFileName(obj))
+		if err := ioutil.WriteFile(path, b, 0600); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// objectFileName is a file name which identifies an object in both locations.
+func objectFileName(obj *unstructured.Unstructured) string {
+	name := strings.TrimPrefix(strings.Replace(objectKey(obj), "/", ".", -1), ".")
+	return reUnsafeFileChars.ReplaceAllString(name, "_") + ".yaml"
+}
+
+func exitCode(err *exec.ExitError) int {
+	if status, ok := err.Sys().(interface{ ExitStatus() int }); ok {
+		return status.ExitStatus()
+	}
+
+	return -1
+}
@@ -0,0 +1,128 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestDiffer_External(t *testing.T) {
+	test.WithApp(t, "/", func(appMock *mocks.App, fs afero.Fs) {
+		remote := []*unstructured.Unstructured{
+			{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Service",
+				"metadata": map[string]interface{}{
+					"name":            "svc",
+					"namespace":       "default",
+					"resourceVersion": "1",
+				},
+			}},
+		}
+		local := []*unstructured.Unstructured{
+			{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Service",
+				"metadata":   map[string]interface{}{"name": "svc"},
+			}},
+		}
+
+		differ := New(appMock, &client.Config{}, []string{}, IgnoreServerFields())
+		differ.localGen = &fakeYamlGenerator{objects: local}
+		differ.remoteGen = &fakeYamlGenerator{objects: remote}
+
+		var args []string
+		files := make(map[string]string)
+		differ.runCmdFn = func(cmd *exec.Cmd) error {
+			args = cmd.Args
+			for _, dir := range cmd.Args[2:] {
+				b, err := ioutil.ReadFile(filepath.Join(dir, "Service.svc.yaml"))
+				require.NoError(t, err)
+				files[filepath.Base(dir)] = string(b)
+			}
+			return nil
+		}
+
+		var stdout, stderr bytes.Buffer
+		found, err := differ.External("dyff between", NewLocation("remote:default"), NewLocation("local:default"), &stdout, &stderr)
+		require.NoError(t, err)
+		assert.False(t, found)
+
+		require.Len(t, args, 4)
+		assert.Equal(t, []string{"dyff", "between"}, args[:2])
+
+		expected := map[string]string{
+			"remote_default": "apiVersion: v1\nkind: Service\nmetadata:\n  name: svc\n  namespace: default\n",
+			"local_default":  "apiVersion: v1\nkind: Service\nmetadata:\n  name: svc\n",
+		}
+		assert.Equal(t, expected, files)
+	})
+}
+
+func TestDiffer_External_exit_codes(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	cases := []struct {
+		name     string
+		program  string
+		exitCode int
+		found    bool
+		isErr    bool
+	}{
+		{name: "no differences", program: "diff", exitCode: 0},
+		{name: "differences", program: "diff", exitCode: 1, found: true},
+		{name: "error", program: "diff", exitCode: 2, isErr: true},
+		{name: "empty program", program: " ", isErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			test.WithApp(t, "/", func(appMock *mocks.App, fs afero.Fs) {
+				differ := New(appMock, &client.Config{}, []string{})
+				differ.localGen = &fakeYamlGenerator{}
+				differ.remoteGen = &fakeYamlGenerator{}
+
+				differ.runCmdFn = func(cmd *exec.Cmd) error {
+					return exec.Command("sh", "-c", fmt.Sprintf("exit %d", tc.exitCode)).Run()
+				}
+
+				var stdout, stderr bytes.Buffer
+				found, err := differ.External(tc.program, NewLocation("remote:default"), NewLocation("local:default"), &stdout, &stderr)
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+				assert.Equal(t, tc.found, found)
+			})
+		})
+	}
+}
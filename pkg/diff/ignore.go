@@ -0,0 +1,378 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package diff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// segmentType is the type of a segment of a field path.
+type segmentType int
+
+const (
+	// segmentName selects a key of an object, or an index of an array.
+	segmentName segmentType = iota
+	// segmentWildcard selects every member of an object or array.
+	segmentWildcard
+	// segmentFilter selects the members of an array with a field value.
+	segmentFilter
+)
+
+// pathSegment is a segment of a field path.
+type pathSegment struct {
+	typ   segmentType
+	name  string
+	field []string
+	value string
+}
+
+// fieldPath is a path to the fields to ignore in an object.
+type fieldPath []pathSegment
+
+// parseJSONPointer parses a JSON6902 path, e.g. /metadata/annotations/a~1b.
+func parseJSONPointer(s string) (fieldPath, error) {
+	if !strings.HasPrefix(s, "/") || s == "/" {
+		return nil, errors.Errorf("invalid JSON pointer %q; pointers start with /", s)
+	}
+
+	var path fieldPath
+	for _, token := range strings.Split(s[1:], "/") {
+		token = strings.Replace(token, "~1", "/", -1)
+		token = strings.Replace(token, "~0", "~", -1)
+		path = append(path, pathSegment{typ: segmentName, name: token})
+	}
+
+	return path, nil
+}
+
+// parseJSONPath parses a JSONPath expression. Child names (.name or
+// ['name']), indexes ([0]), wildcards (.* or [*]), and equality filters
+// ([?(@.name=="value")]) are supported.
+func parseJSONPath(s string) (fieldPath, error) {
+	invalid := func(reason string) error {
+		return errors.Errorf("invalid JSONPath %q: %s", s, reason)
+	}
+
+	rest := strings.TrimPrefix(s, "$")
+	var path fieldPath
+
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end == -1 {
+				end = len(rest) - 1
+			}
+			name := rest[1 : end+1]
+			rest = rest[end+1:]
+
+			switch name {
+			case "":
+				return nil, invalid("empty name")
+			case "*":
+				path = append(path, pathSegment{typ: segmentWildcard})
+			default:
+				path = append(path, pathSegment{typ: segmentName, name: name})
+			}
+		case '[':
+			end := strings.Index(rest, "]")
+			if strings.HasPrefix(rest, "['") || strings.HasPrefix(rest, "[\"") {
+				end = strings.Index(rest, rest[1:2]+"]")
+				if end != -1 {
+					end++
+				}
+			}
+			if end == -1 {
+				return nil, invalid("unterminated [")
+			}
+			segment, err := parseBracket(rest[1:end])
+			if err != nil {
+				return nil, invalid(err.Error())
+			}
+			path = append(path, segment)
+			rest = rest[end+1:]
+		default:
+			return nil, invalid(fmt.Sprintf("unexpected %q", rest[0]))
+		}
+	}
+
+	if len(path) == 0 {
+		return nil, invalid("no fields selected")
+	}
+
+	return path, nil
+}
+
+// parseBracket parses the contents of a JSONPath bracket.
+func parseBracket(s string) (pathSegment, error) {
+	switch {
+	case s == "*":
+		return pathSegment{typ: segmentWildcard}, nil
+	case len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0]:
+		return pathSegment{typ: segmentName, name: s[1 : len(s)-1]}, nil
+	case strings.HasPrefix(s, "?(@.") && strings.HasSuffix(s, ")"):
+		expr := s[len("?(@.") : len(s)-1]
+		parts := strings.SplitN(expr, "==", 2)
+		if len(parts) != 2 {
+			return pathSegment{}, errors.New("filters must compare a field with ==")
+		}
+		value := strings.TrimSpace(parts[1])
+		if len(value) >= 2 && (value[0] == '\'' || value[0] == '"') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		return pathSegment{
+			typ:   segmentFilter,
+			field: strings.Split(strings.TrimSpace(parts[0]), "."),
+			value: value,
+		}, nil
+	}
+
+	if _, err := strconv.Atoi(s); err != nil {
+		return pathSegment{}, errors.Errorf("unsupported selector [%s]", s)
+	}
+
+	return pathSegment{typ: segmentName, name: s}, nil
+}
+
+// selects returns true if the segment selects a member of an array.
+func (seg pathSegment) selects(i int, v interface{}) bool {
+	switch seg.typ {
+	case segmentWildcard:
+		return true
+	case segmentFilter:
+		value, ok, err := unstructured.NestedFieldCopy(asObject(v), seg.field...)
+		return err == nil && ok && fmt.Sprint(value) == seg.value
+	default:
+		return seg.name == strconv.Itoa(i)
+	}
+}
+
+func asObject(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+// remove removes the fields selected by path from v, and returns the updated
+// value. Objects and arrays which are emptied by the removal are removed too.
+func (path fieldPath) remove(v interface{}) interface{} {
+	if len(path) == 0 {
+		return v
+	}
+
+	seg, rest := path[0], path[1:]
+
+	switch t := v.(type) {
+	case map[string]interface{}:
+		var keys []string
+		switch seg.typ {
+		case segmentName:
+			if _, ok := t[seg.name]; ok {
+				keys = append(keys, seg.name)
+			}
+		case segmentWildcard:
+			for k := range t {
+				keys = append(keys, k)
+			}
+		}
+
+		for _, k := range keys {
+			child, keep := rest.removeChild(t[k])
+			if !keep {
+				delete(t, k)
+				continue
+			}
+			t[k] = child
+		}
+		return t
+	case []interface{}:
+		kept := make([]interface{}, 0, len(t))
+		for i, item := range t {
+			if !seg.selects(i, item) {
+				kept = append(kept, item)
+				continue
+			}
+			if child, keep := rest.removeChild(item); keep {
+				kept = append(kept, child)
+			}
+		}
+		return kept
+	}
+
+	return v
+}
+
+// removeChild removes the fields selected by path from a child value, and
+// returns the updated child, or false if the child itself is removed.
+func (path fieldPath) removeChild(v interface{}) (interface{}, bool) {
+	if len(path) == 0 {
+		return nil, false
+	}
+
+	wasEmpty := isEmpty(v)
+	v = path.remove(v)
+
+	return v, wasEmpty || !isEmpty(v)
+}
+
+func isEmpty(v interface{}) bool {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		return len(t) == 0
+	case []interface{}:
+		return len(t) == 0
+	}
+
+	return false
+}
+
+// ignoreRule is a parsed app.DiffIgnoreConfig.
+type ignoreRule struct {
+	config *app.DiffIgnoreConfig
+	paths  []fieldPath
+}
+
+func newIgnoreRule(config *app.DiffIgnoreConfig) (*ignoreRule, error) {
+	r := &ignoreRule{config: config}
+
+	for _, s := range config.JSONPointers {
+		path, err := parseJSONPointer(s)
+		if err != nil {
+			return nil, err
+		}
+		r.paths = append(r.paths, path)
+	}
+
+	for _, s := range config.JSONPaths {
+		path, err := parseJSONPath(s)
+		if err != nil {
+			return nil, err
+		}
+		r.paths = append(r.paths, path)
+	}
+
+	return r, nil
+}
+
+// matches returns true if the rule applies to obj.
+func (r *ignoreRule) matches(obj *unstructured.Unstructured) bool {
+	c := r.config
+
+	if c.Group != "" {
+		gv, err := schema.ParseGroupVersion(obj.GetAPIVersion())
+		if err != nil || gv.Group != c.Group {
+			return false
+		}
+	}
+
+	return (c.Kind == "" || c.Kind == obj.GetKind()) &&
+		(c.Namespace == "" || c.Namespace == obj.GetNamespace()) &&
+		(c.Name == "" || c.Name == obj.GetName()) &&
+		(c.Component == "" || c.Component == obj.GetLabels()[metadata.LabelComponent])
+}
+
+// ignorer leaves ignored objects and fields out of the objects which are
+// compared.
+type ignorer struct {
+	rules []*ignoreRule
+	// annotated are the paths listed in the diff-ignore annotation of an
+	// object in either location, by object key.
+	annotated map[string][]fieldPath
+}
+
+// newIgnorer creates an ignorer for configured rules, and for the annotations
+// of the objects in both locations. Paths in an annotation apply to the object
+// in both locations, so ignoring a field does not depend on which side was
+// annotated.
+func newIgnorer(configs []*app.DiffIgnoreConfig, objectSets ...[]*unstructured.Unstructured) (*ignorer, error) {
+	ig := &ignorer{annotated: make(map[string][]fieldPath)}
+
+	for i, config := range configs {
+		r, err := newIgnoreRule(config)
+		if err != nil {
+			return nil, errors.Wrapf(err, "diff ignore rule %d", i+1)
+		}
+		ig.rules = append(ig.rules, r)
+	}
+
+	for _, objects := range objectSets {
+		for _, obj := range objects {
+			value := obj.GetAnnotations()[metadata.AnnotationDiffIgnore]
+			if value == "" {
+				continue
+			}
+
+			for _, s := range strings.Split(value, ",") {
+				path, err := parseJSONPointer(strings.TrimSpace(s))
+				if err != nil {
+					return nil, errors.Wrapf(err, "%s annotation of %s", metadata.AnnotationDiffIgnore, objectKey(obj))
+				}
+				key := objectKey(obj)
+				ig.annotated[key] = append(ig.annotated[key], path)
+			}
+		}
+	}
+
+	return ig, nil
+}
+
+// filter returns copies of objects without ignored objects and fields.
+func (ig *ignorer) filter(objects []*unstructured.Unstructured) []*unstructured.Unstructured {
+	filtered := make([]*unstructured.Unstructured, 0, len(objects))
+
+	for _, obj := range objects {
+		paths, ignored := ig.paths(obj)
+		if ignored {
+			continue
+		}
+
+		if len(paths) > 0 {
+			obj = obj.DeepCopy()
+			for _, path := range paths {
+				path.remove(obj.Object)
+			}
+		}
+
+		filtered = append(filtered, obj)
+	}
+
+	return filtered
+}
+
+// paths returns the paths to ignore in obj, or true if the whole object is
+// ignored.
+func (ig *ignorer) paths(obj *unstructured.Unstructured) ([]fieldPath, bool) {
+	paths := append([]fieldPath(nil), ig.annotated[objectKey(obj)]...)
+
+	for _, r := range ig.rules {
+		if !r.matches(obj) {
+			continue
+		}
+		if len(r.paths) == 0 {
+			return nil, true
+		}
+		paths = append(paths, r.paths...)
+	}
+
+	return paths, false
+}
@@ -0,0 +1,276 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package diff
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func ignoreTestObject() map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name": "app",
+			"annotations": map[string]interface{}{
+				"cert-manager.io/issuer": "letsencrypt",
+			},
+			"labels": map[string]interface{}{
+				"ksonnet.io/component": "app",
+			},
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(3),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "app", "image": "app:1"},
+						map[string]interface{}{"name": "istio-proxy", "image": "proxy:1"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func Test_fieldPath_remove(t *testing.T) {
+	cases := []struct {
+		name     string
+		pointer  string
+		jsonPath string
+		expected func(map[string]interface{})
+		isErr    bool
+	}{
+		{
+			name:    "pointer",
+			pointer: "/spec/replicas",
+			expected: func(obj map[string]interface{}) {
+				delete(obj["spec"].(map[string]interface{}), "replicas")
+			},
+		},
+		{
+			name:    "pointer with escaped key",
+			pointer: "/metadata/annotations/cert-manager.io~1issuer",
+			expected: func(obj map[string]interface{}) {
+				delete(obj["metadata"].(map[string]interface{}), "annotations")
+			},
+		},
+		{
+			name:    "pointer with index",
+			pointer: "/spec/template/spec/containers/1/image",
+			expected: func(obj map[string]interface{}) {
+				containers := obj["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})["containers"].([]interface{})
+				delete(containers[1].(map[string]interface{}), "image")
+			},
+		},
+		{
+			name:    "pointer to missing field",
+			pointer: "/status/replicas",
+			expected: func(obj map[string]interface{}) {
+			},
+		},
+		{
+			name:     "JSONPath with quoted key",
+			jsonPath: ".metadata.annotations['cert-manager.io/issuer']",
+			expected: func(obj map[string]interface{}) {
+				delete(obj["metadata"].(map[string]interface{}), "annotations")
+			},
+		},
+		{
+			name:     "JSONPath with filter",
+			jsonPath: `$.spec.template.spec.containers[?(@.name=="istio-proxy")]`,
+			expected: func(obj map[string]interface{}) {
+				spec := obj["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})
+				spec["containers"] = spec["containers"].([]interface{})[:1]
+			},
+		},
+		{
+			name:     "JSONPath with wildcard",
+			jsonPath: ".spec.template.spec.containers[*].image",
+			expected: func(obj map[string]interface{}) {
+				containers := obj["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})["containers"].([]interface{})
+				for _, c := range containers {
+					delete(c.(map[string]interface{}), "image")
+				}
+			},
+		},
+		{
+			name:    "invalid pointer",
+			pointer: "spec/replicas",
+			isErr:   true,
+		},
+		{
+			name:     "invalid JSONPath",
+			jsonPath: ".spec[?(@.name)]",
+			isErr:    true,
+		},
+		{
+			name:     "unterminated JSONPath",
+			jsonPath: ".spec.containers[0",
+			isErr:    true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var path fieldPath
+			var err error
+			if tc.pointer != "" {
+				path, err = parseJSONPointer(tc.pointer)
+			} else {
+				path, err = parseJSONPath(tc.jsonPath)
+			}
+
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			obj := ignoreTestObject()
+			path.remove(obj)
+
+			expected := ignoreTestObject()
+			tc.expected(expected)
+
+			assert.Equal(t, expected, obj)
+		})
+	}
+}
+
+func Test_ignorer_filter(t *testing.T) {
+	hpa := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "autoscaling/v1",
+		"kind":       "HorizontalPodAutoscaler",
+		"metadata":   map[string]interface{}{"name": "app"},
+	}}
+
+	annotated := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata": map[string]interface{}{
+			"name": "app",
+			"annotations": map[string]interface{}{
+				"ksonnet.io/diff-ignore": "/spec/clusterIP, /spec/ports",
+			},
+		},
+		"spec": map[string]interface{}{
+			"clusterIP": "10.0.0.1",
+			"ports":     []interface{}{},
+			"type":      "ClusterIP",
+		},
+	}}
+
+	// the live service is not annotated; the annotation of the local
+	// service applies to it.
+	live := annotated.DeepCopy()
+	live.SetAnnotations(nil)
+
+	rules := []*app.DiffIgnoreConfig{
+		{Kind: "HorizontalPodAutoscaler"},
+		{Group: "apps", Kind: "Deployment", JSONPointers: []string{"/spec/replicas"}},
+		{Component: "app", JSONPaths: []string{".metadata.annotations['cert-manager.io/issuer']"}},
+		{Component: "other", JSONPointers: []string{"/spec/template"}},
+	}
+
+	deployment := &unstructured.Unstructured{Object: ignoreTestObject()}
+
+	ig, err := newIgnorer(rules, []*unstructured.Unstructured{deployment, hpa, annotated}, []*unstructured.Unstructured{live})
+	require.NoError(t, err)
+
+	got := ig.filter([]*unstructured.Unstructured{deployment, hpa, annotated})
+	require.Len(t, got, 2)
+
+	_, ok, _ := unstructured.NestedFieldCopy(got[0].Object, "spec", "replicas")
+	assert.False(t, ok)
+	assert.Empty(t, got[0].GetAnnotations())
+	_, ok, _ = unstructured.NestedFieldCopy(got[0].Object, "spec", "template")
+	assert.True(t, ok)
+
+	spec, _, _ := unstructured.NestedMap(got[1].Object, "spec")
+	assert.Equal(t, map[string]interface{}{"type": "ClusterIP"}, spec)
+
+	got = ig.filter([]*unstructured.Unstructured{live})
+	spec, _, _ = unstructured.NestedMap(got[0].Object, "spec")
+	assert.Equal(t, map[string]interface{}{"type": "ClusterIP"}, spec)
+
+	// the objects which are filtered are not changed.
+	assert.Equal(t, int64(3), deployment.Object["spec"].(map[string]interface{})["replicas"])
+}
+
+func Test_newIgnorer_invalid(t *testing.T) {
+	_, err := newIgnorer([]*app.DiffIgnoreConfig{{JSONPointers: []string{"spec"}}})
+	require.Error(t, err)
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	obj.SetAnnotations(map[string]string{"ksonnet.io/diff-ignore": ".spec"})
+	_, err = newIgnorer(nil, []*unstructured.Unstructured{obj})
+	require.Error(t, err)
+}
+
+func TestDiffer_Report_ignore_rules(t *testing.T) {
+	test.WithApp(t, "/", func(appMock *mocks.App, fs afero.Fs) {
+		remote := &unstructured.Unstructured{Object: ignoreTestObject()}
+
+		local := &unstructured.Unstructured{Object: ignoreTestObject()}
+		local.Object["spec"].(map[string]interface{})["replicas"] = int64(1)
+
+		rules := []*app.DiffIgnoreConfig{
+			{Kind: "Deployment", JSONPointers: []string{"/spec/replicas"}},
+		}
+
+		differ := New(appMock, &client.Config{}, []string{}, IgnoreRules(rules))
+		differ.localGen = &fakeYamlGenerator{objects: []*unstructured.Unstructured{local}}
+		differ.remoteGen = &fakeYamlGenerator{objects: []*unstructured.Unstructured{remote}}
+
+		report, err := differ.Report(NewLocation("remote:default"), NewLocation("local:default"))
+		require.NoError(t, err)
+		assert.False(t, report.HasChanges())
+
+		r, err := differ.Diff(NewLocation("remote:default"), NewLocation("local:default"))
+		require.NoError(t, err)
+
+		b, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+		assert.Empty(t, string(b))
+	})
+}
+
+func Test_environmentIgnoreRules(t *testing.T) {
+	test.WithApp(t, "/", func(appMock *mocks.App, fs afero.Fs) {
+		rule := &app.DiffIgnoreConfig{Kind: "HorizontalPodAutoscaler"}
+		appMock.On("Environment", "default").Return(&app.EnvironmentConfig{
+			DiffIgnore: []*app.DiffIgnoreConfig{rule},
+		}, nil)
+
+		rules, err := environmentIgnoreRules(appMock, []*Location{
+			NewLocation("local:default"),
+			NewLocation("remote:default"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []*app.DiffIgnoreConfig{rule}, rules)
+	})
+}
@@ -0,0 +1,276 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// ChangeAdded is a change which adds an object or field.
+	ChangeAdded = "added"
+	// ChangeRemoved is a change which removes an object or field.
+	ChangeRemoved = "removed"
+	// ChangeChanged is a change which updates an object or field.
+	ChangeChanged = "changed"
+)
+
+var (
+	reSimplePathKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+)
+
+// FieldChange is a change to a single field in an object.
+type FieldChange struct {
+	// Path is the path of the field, e.g. spec.template.spec.containers[0].image.
+	Path string      `json:"path"`
+	Type string      `json:"type"`
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+}
+
+// ObjectChange is a change to an object.
+type ObjectChange struct {
+	APIVersion string        `json:"apiVersion"`
+	Kind       string        `json:"kind"`
+	Namespace  string        `json:"namespace,omitempty"`
+	Name       string        `json:"name"`
+	Type       string        `json:"type"`
+	Fields     []FieldChange `json:"fields,omitempty"`
+}
+
+// Report is a structured set of differences between two locations.
+type Report struct {
+	From    string         `json:"from"`
+	To      string         `json:"to"`
+	Objects []ObjectChange `json:"objects"`
+}
+
+// HasChanges returns true if the report contains differences.
+func (r *Report) HasChanges() bool {
+	return len(r.Objects) > 0
+}
+
+// Report generates a structured report of the differences between two locations.
+func (d *Differ) Report(location1, location2 *Location) (*Report, error) {
+	logrus.WithFields(logrus.Fields{
+		"src1": location1.String(),
+		"src2": location2.String(),
+	}).Debug("generating diff report")
+
+	from, to, err := d.compared(location1, location2)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Report{
+		From:    location1.String(),
+		To:      location2.String(),
+		Objects: compareObjects(from, to),
+	}, nil
+}
+
+// objectKey identifies an object in both locations. The namespace is not part
+// of the key, because rendered manifests often rely on the environment namespace.
+func objectKey(obj *unstructured.Unstructured) string {
+	gv, err := schema.ParseGroupVersion(obj.GetAPIVersion())
+	group := obj.GetAPIVersion()
+	if err == nil {
+		group = gv.Group
+	}
+
+	return fmt.Sprintf("%s/%s/%s", group, obj.GetKind(), obj.GetName())
+}
+
+func compareObjects(from, to []*unstructured.Unstructured) []ObjectChange {
+	fromByKey := make(map[string]*unstructured.Unstructured)
+	for _, obj := range from {
+		fromByKey[objectKey(obj)] = obj
+	}
+
+	toByKey := make(map[string]*unstructured.Unstructured)
+	for _, obj := range to {
+		toByKey[objectKey(obj)] = obj
+	}
+
+	changes := make([]ObjectChange, 0)
+
+	for _, obj := range to {
+		prev, ok := fromByKey[objectKey(obj)]
+		if !ok {
+			changes = append(changes, newObjectChange(obj, ChangeAdded, nil))
+			continue
+		}
+
+		fields := compareValues("", prev.Object, obj.Object)
+		if len(fields) > 0 {
+			changes = append(changes, newObjectChange(obj, ChangeChanged, fields))
+		}
+	}
+
+	for _, obj := range from {
+		if _, ok := toByKey[objectKey(obj)]; !ok {
+			changes = append(changes, newObjectChange(obj, ChangeRemoved, nil))
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Kind != changes[j].Kind {
+			return changes[i].Kind < changes[j].Kind
+		}
+		return changes[i].Name < changes[j].Name
+	})
+
+	return changes
+}
+
+func newObjectChange(obj *unstructured.Unstructured, changeType string, fields []FieldChange) ObjectChange {
+	return ObjectChange{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+		Type:       changeType,
+		Fields:     fields,
+	}
+}
+
+// compareValues returns the field changes required to turn `from` into `to`.
+func compareValues(path string, from, to interface{}) []FieldChange {
+	switch f := from.(type) {
+	case map[string]interface{}:
+		t, ok := to.(map[string]interface{})
+		if !ok {
+			break
+		}
+
+		return compareMaps(path, f, t)
+	case []interface{}:
+		t, ok := to.([]interface{})
+		if !ok {
+			break
+		}
+
+		return compareSlices(path, f, t)
+	}
+
+	if valuesEqual(from, to) {
+		return nil
+	}
+
+	return []FieldChange{{Path: path, Type: ChangeChanged, From: from, To: to}}
+}
+
+func compareMaps(path string, from, to map[string]interface{}) []FieldChange {
+	keys := make(map[string]bool)
+	for k := range from {
+		keys[k] = true
+	}
+	for k := range to {
+		keys[k] = true
+	}
+
+	var sorted []string
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	var changes []FieldChange
+	for _, k := range sorted {
+		childPath := joinPath(path, k)
+		f, inFrom := from[k]
+		t, inTo := to[k]
+
+		switch {
+		case !inFrom:
+			changes = append(changes, FieldChange{Path: childPath, Type: ChangeAdded, To: t})
+		case !inTo:
+			changes = append(changes, FieldChange{Path: childPath, Type: ChangeRemoved, From: f})
+		default:
+			changes = append(changes, compareValues(childPath, f, t)...)
+		}
+	}
+
+	return changes
+}
+
+func compareSlices(path string, from, to []interface{}) []FieldChange {
+	var changes []FieldChange
+	for i := 0; i < len(from) || i < len(to); i++ {
+		childPath := fmt.Sprintf("%s[%d]", path, i)
+
+		switch {
+		case i >= len(from):
+			changes = append(changes, FieldChange{Path: childPath, Type: ChangeAdded, To: to[i]})
+		case i >= len(to):
+			changes = append(changes, FieldChange{Path: childPath, Type: ChangeRemoved, From: from[i]})
+		default:
+			changes = append(changes, compareValues(childPath, from[i], to[i])...)
+		}
+	}
+
+	return changes
+}
+
+// joinPath appends a map key to a field path. Keys which are not simple
+// identifiers (e.g. annotation names) are quoted.
+func joinPath(path, key string) string {
+	if !reSimplePathKey.MatchString(key) {
+		return fmt.Sprintf("%s[%q]", path, key)
+	}
+
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+// valuesEqual compares scalar values. Numbers are compared by value, since
+// rendered manifests and cluster objects decode numbers to different types.
+func valuesEqual(a, b interface{}) bool {
+	af, aIsNum := toFloat(a)
+	bf, bIsNum := toFloat(b)
+	if aIsNum && bIsNum {
+		return af == bf
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package diff
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/redact"
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestDiffer_Report(t *testing.T) {
+	test.WithApp(t, "/", func(appMock *mocks.App, fs afero.Fs) {
+		remote := []*unstructured.Unstructured{
+			{Object: map[string]interface{}{
+				"apiVersion": "apps/v1beta1",
+				"kind":       "Deployment",
+				"metadata": map[string]interface{}{
+					"name":      "app",
+					"namespace": "default",
+					"labels":    map[string]interface{}{"app": "app"},
+				},
+				"spec": map[string]interface{}{
+					"replicas": int64(1),
+					"template": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"containers": []interface{}{
+								map[string]interface{}{"name": "app", "image": "app:1"},
+							},
+						},
+					},
+				},
+			}},
+			{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]interface{}{"name": "old"},
+			}},
+			{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Service",
+				"metadata":   map[string]interface{}{"name": "same"},
+				"spec":       map[string]interface{}{"port": int64(80)},
+			}},
+		}
+
+		local := []*unstructured.Unstructured{
+			{Object: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata": map[string]interface{}{
+					"name":        "app",
+					"namespace":   "default",
+					"annotations": map[string]interface{}{"example.com/owner": "team"},
+				},
+				"spec": map[string]interface{}{
+					"replicas": float64(2),
+					"template": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"containers": []interface{}{
+								map[string]interface{}{"name": "app", "image": "app:2"},
+							},
+						},
+					},
+				},
+			}},
+			{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]interface{}{"name": "new"},
+			}},
+			{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Service",
+				"metadata":   map[string]interface{}{"name": "same"},
+				"spec":       map[string]interface{}{"port": float64(80)},
+			}},
+		}
+
+		differ := New(appMock, &client.Config{}, []string{})
+		differ.localGen = &fakeYamlGenerator{objects: local}
+		differ.remoteGen = &fakeYamlGenerator{objects: remote}
+
+		report, err := differ.Report(NewLocation("remote:default"), NewLocation("local:default"))
+		require.NoError(t, err)
+
+		expected := &Report{
+			From: "remote:default",
+			To:   "local:default",
+			Objects: []ObjectChange{
+				{APIVersion: "v1", Kind: "ConfigMap", Name: "new", Type: ChangeAdded},
+				{APIVersion: "v1", Kind: "ConfigMap", Name: "old", Type: ChangeRemoved},
+				{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Namespace:  "default",
+					Name:       "app",
+					Type:       ChangeChanged,
+					Fields: []FieldChange{
+						{Path: "apiVersion", Type: ChangeChanged, From: "apps/v1beta1", To: "apps/v1"},
+						{Path: `metadata.annotations`, Type: ChangeAdded, To: map[string]interface{}{"example.com/owner": "team"}},
+						{Path: "metadata.labels", Type: ChangeRemoved, From: map[string]interface{}{"app": "app"}},
+						{Path: "spec.replicas", Type: ChangeChanged, From: int64(1), To: float64(2)},
+						{Path: "spec.template.spec.containers[0].image", Type: ChangeChanged, From: "app:1", To: "app:2"},
+					},
+				},
+			},
+		}
+
+		assert.Equal(t, expected, report)
+		assert.True(t, report.HasChanges())
+	})
+}
+
+func TestDiffer_Report_redacted(t *testing.T) {
+	test.WithApp(t, "/", func(appMock *mocks.App, fs afero.Fs) {
+		secret := func(password string) []*unstructured.Unstructured {
+			return []*unstructured.Unstructured{
+				{Object: map[string]interface{}{
+					"apiVersion": "v1",
+					"kind":       "Secret",
+					"metadata":   map[string]interface{}{"name": "db"},
+					"data":       map[string]interface{}{"password": password, "user": "YWRtaW4="},
+				}},
+			}
+		}
+
+		redactor, err := redact.New(nil, redact.Key([]byte("key")))
+		require.NoError(t, err)
+
+		differ := New(appMock, &client.Config{}, []string{}, Redact(redactor))
+		differ.localGen = &fakeYamlGenerator{objects: secret("bmV3")}
+		differ.remoteGen = &fakeYamlGenerator{objects: secret("b2xk")}
+
+		report, err := differ.Report(NewLocation("remote:default"), NewLocation("local:default"))
+		require.NoError(t, err)
+
+		require.Len(t, report.Objects, 1)
+		require.Len(t, report.Objects[0].Fields, 1)
+
+		fc := report.Objects[0].Fields[0]
+		assert.Equal(t, "data.password", fc.Path)
+		assert.Regexp(t, `^<redacted:[0-9a-f]{8}>$`, fc.From)
+		assert.Regexp(t, `^<redacted:[0-9a-f]{8}>$`, fc.To)
+		assert.NotEqual(t, fc.From, fc.To)
+	})
+}
+
+func Test_joinPath(t *testing.T) {
+	assert.Equal(t, "spec", joinPath("", "spec"))
+	assert.Equal(t, "spec.replicas", joinPath("spec", "replicas"))
+	assert.Equal(t, `metadata.annotations["ksonnet.io/managed"]`, joinPath("metadata.annotations", "ksonnet.io/managed"))
+}
@@ -0,0 +1,200 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package diff
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+const (
+	// DefaultWatchInterval is the default interval between drift evaluations.
+	DefaultWatchInterval = time.Minute
+)
+
+// DriftEvent is the result of a single drift evaluation.
+type DriftEvent struct {
+	Time    time.Time `json:"time"`
+	Drifted bool      `json:"drifted"`
+	Error   string    `json:"error,omitempty"`
+	Report  *Report   `json:"report,omitempty"`
+}
+
+// Watcher periodically compares two locations and reports drift between them.
+type Watcher struct {
+	interval time.Duration
+	reportFn func() (*Report, error)
+	nowFn    func() time.Time
+}
+
+// NewWatcher creates an instance of Watcher. reportFn is called once per interval.
+func NewWatcher(interval time.Duration, reportFn func() (*Report, error)) (*Watcher, error) {
+	if interval <= 0 {
+		return nil, errors.Errorf("watch interval must be positive; got %s", interval)
+	}
+
+	return &Watcher{
+		interval: interval,
+		reportFn: reportFn,
+		nowFn:    time.Now,
+	}, nil
+}
+
+// Run evaluates drift immediately, and then once per interval, until stopCh
+// is closed. Each evaluation is passed to handle. Evaluation errors are
+// reported as events so a daemon can ride out transient failures; an error
+// returned by handle stops the watch.
+func (w *Watcher) Run(stopCh <-chan struct{}, handle func(DriftEvent) error) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := handle(w.evaluate()); err != nil {
+			return err
+		}
+
+		// Check for a stop first, since select picks randomly when the
+		// ticker has also fired.
+		select {
+		case <-stopCh:
+			return nil
+		default:
+		}
+
+		select {
+		case <-stopCh:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Watcher) evaluate() DriftEvent {
+	event := DriftEvent{Time: w.nowFn().UTC()}
+
+	report, err := w.reportFn()
+	if err != nil {
+		event.Error = err.Error()
+		return event
+	}
+
+	event.Report = report
+	event.Drifted = report.HasChanges()
+	return event
+}
+
+// DriftMetrics exposes drift events as Prometheus metrics.
+type DriftMetrics struct {
+	registry    *prometheus.Registry
+	drifted     *prometheus.GaugeVec
+	objects     *prometheus.GaugeVec
+	evaluations *prometheus.CounterVec
+	errors      *prometheus.CounterVec
+	lastRun     *prometheus.GaugeVec
+}
+
+// NewDriftMetrics creates an instance of DriftMetrics with its own registry.
+func NewDriftMetrics() *DriftMetrics {
+	labels := []string{"from", "to"}
+
+	m := &DriftMetrics{
+		registry: prometheus.NewRegistry(),
+		drifted: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "ks",
+			Subsystem: "diff",
+			Name:      "drifted",
+			Help:      "1 if the last evaluation found drift, 0 otherwise.",
+		}, labels),
+		objects: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "ks",
+			Subsystem: "diff",
+			Name:      "drifted_objects",
+			Help:      "Number of objects that drifted, by change type.",
+		}, append(labels, "type")),
+		evaluations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "ks",
+			Subsystem: "diff",
+			Name:      "evaluations_total",
+			Help:      "Number of drift evaluations.",
+		}, labels),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "ks",
+			Subsystem: "diff",
+			Name:      "evaluation_errors_total",
+			Help:      "Number of drift evaluations which failed.",
+		}, labels),
+		lastRun: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "ks",
+			Subsystem: "diff",
+			Name:      "last_evaluation_timestamp_seconds",
+			Help:      "Time of the last drift evaluation.",
+		}, labels),
+	}
+
+	m.registry.MustRegister(m.drifted, m.objects, m.evaluations, m.errors, m.lastRun)
+
+	return m
+}
+
+// Observe records a drift event for a pair of locations.
+func (m *DriftMetrics) Observe(from, to string, event DriftEvent) {
+	m.evaluations.WithLabelValues(from, to).Inc()
+	m.lastRun.WithLabelValues(from, to).Set(float64(event.Time.Unix()))
+
+	if event.Error != "" {
+		m.errors.WithLabelValues(from, to).Inc()
+		return
+	}
+
+	drifted := 0.0
+	if event.Drifted {
+		drifted = 1
+	}
+	m.drifted.WithLabelValues(from, to).Set(drifted)
+
+	counts := map[string]int{ChangeAdded: 0, ChangeRemoved: 0, ChangeChanged: 0}
+	if event.Report != nil {
+		for _, oc := range event.Report.Objects {
+			counts[oc.Type]++
+		}
+	}
+	for changeType, count := range counts {
+		m.objects.WithLabelValues(from, to, changeType).Set(float64(count))
+	}
+}
+
+// ServeHTTP writes the metrics in the format negotiated with the client.
+func (m *DriftMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	mfs, err := m.registry.Gather()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	contentType := expfmt.Negotiate(r.Header)
+	w.Header().Set("Content-Type", string(contentType))
+
+	enc := expfmt.NewEncoder(w, contentType)
+	for _, mf := range mfs {
+		if err := enc.Encode(mf); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package diff
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcher_Run(t *testing.T) {
+	results := []struct {
+		report *Report
+		err    error
+	}{
+		{report: &Report{Objects: []ObjectChange{}}},
+		{err: errors.New("connection refused")},
+		{report: &Report{Objects: []ObjectChange{{Kind: "Service", Name: "svc", Type: ChangeChanged}}}},
+	}
+
+	i := 0
+	w, err := NewWatcher(time.Millisecond, func() (*Report, error) {
+		r := results[i]
+		i++
+		return r.report, r.err
+	})
+	require.NoError(t, err)
+
+	now := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	w.nowFn = func() time.Time { return now }
+
+	stopCh := make(chan struct{})
+	var events []DriftEvent
+	err = w.Run(stopCh, func(event DriftEvent) error {
+		events = append(events, event)
+		if len(events) == len(results) {
+			close(stopCh)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	expected := []DriftEvent{
+		{Time: now, Report: results[0].report},
+		{Time: now, Error: "connection refused"},
+		{Time: now, Drifted: true, Report: results[2].report},
+	}
+	assert.Equal(t, expected, events)
+}
+
+func TestWatcher_Run_handler_error(t *testing.T) {
+	w, err := NewWatcher(time.Hour, func() (*Report, error) {
+		return &Report{}, nil
+	})
+	require.NoError(t, err)
+
+	err = w.Run(make(chan struct{}), func(DriftEvent) error {
+		return errors.New("broken pipe")
+	})
+	require.Error(t, err)
+}
+
+func TestNewWatcher_invalid_interval(t *testing.T) {
+	_, err := NewWatcher(0, nil)
+	require.Error(t, err)
+}
+
+func TestDriftMetrics(t *testing.T) {
+	m := NewDriftMetrics()
+
+	m.Observe("local:prod", "remote:prod", DriftEvent{
+		Time:    time.Unix(1514764800, 0),
+		Drifted: true,
+		Report: &Report{Objects: []ObjectChange{
+			{Kind: "Service", Name: "svc", Type: ChangeChanged},
+			{Kind: "ConfigMap", Name: "cm", Type: ChangeAdded},
+		}},
+	})
+	m.Observe("local:prod", "remote:prod", DriftEvent{
+		Time:  time.Unix(1514764860, 0),
+		Error: "connection refused",
+	})
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	b, err := ioutil.ReadAll(rec.Body)
+	require.NoError(t, err)
+	body := string(b)
+
+	for _, line := range []string{
+		`ks_diff_drifted{from="local:prod",to="remote:prod"} 1`,
+		`ks_diff_drifted_objects{from="local:prod",to="remote:prod",type="added"} 1`,
+		`ks_diff_drifted_objects{from="local:prod",to="remote:prod",type="changed"} 1`,
+		`ks_diff_drifted_objects{from="local:prod",to="remote:prod",type="removed"} 0`,
+		`ks_diff_evaluations_total{from="local:prod",to="remote:prod"} 2`,
+		`ks_diff_evaluation_errors_total{from="local:prod",to="remote:prod"} 1`,
+		`ks_diff_last_evaluation_timestamp_seconds{from="local:prod",to="remote:prod"} 1.51476486e+09`,
+	} {
+		assert.Contains(t, body, line)
+	}
+}
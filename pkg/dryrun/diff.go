@@ -0,0 +1,142 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package dryrun
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	godiff "github.com/shazow/go-diff"
+	"github.com/spf13/afero"
+)
+
+// Change is a file which would have been changed.
+type Change struct {
+	Path string
+	// Before is the current content of the file. It is nil if the file would
+	// be created.
+	Before []byte
+	// After is the new content of the file. It is nil if the file would be
+	// removed.
+	After   []byte
+	Created bool
+	Removed bool
+}
+
+// Changes returns the files which differ from the base filesystem, sorted
+// by path.
+func (fs *Fs) Changes() ([]Change, error) {
+	changes := make(map[string]Change)
+
+	root := string(filepath.Separator)
+	if fs.inLayer(root) {
+		err := afero.Walk(fs.layer, root, func(path string, fi os.FileInfo, err error) error {
+			if err != nil || fi.IsDir() {
+				return err
+			}
+
+			after, err := afero.ReadFile(fs.layer, path)
+			if err != nil {
+				return err
+			}
+
+			before, err := afero.ReadFile(fs.base, path)
+			if err != nil {
+				if !os.IsNotExist(err) {
+					return err
+				}
+				changes[path] = Change{Path: path, After: after, Created: true}
+				return nil
+			}
+
+			if !bytes.Equal(before, after) {
+				changes[path] = Change{Path: path, Before: before, After: after}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for removed := range fs.removed {
+		if _, err := fs.base.Stat(removed); err != nil {
+			continue
+		}
+
+		err := afero.Walk(fs.base, removed, func(path string, fi os.FileInfo, err error) error {
+			if err != nil || fi.IsDir() || fs.inLayer(path) {
+				return err
+			}
+
+			before, err := afero.ReadFile(fs.base, path)
+			if err != nil {
+				return err
+			}
+
+			changes[path] = Change{Path: path, Before: before, Removed: true}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var list []Change
+	for _, c := range changes {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Path < list[j].Path
+	})
+
+	return list, nil
+}
+
+// Diff writes a unified diff of the changes to w. Paths are shown relative to
+// root. It returns the number of files which would change.
+func (fs *Fs) Diff(w io.Writer, root string) (int, error) {
+	changes, err := fs.Changes()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, c := range changes {
+		path := c.Path
+		if rel, err := filepath.Rel(root, c.Path); err == nil {
+			path = filepath.ToSlash(rel)
+		}
+
+		from, to := "a/"+path, "b/"+path
+		switch {
+		case c.Created:
+			from = "/dev/null"
+		case c.Removed:
+			to = "/dev/null"
+		}
+
+		fmt.Fprintf(w, "--- %s\n+++ %s\n", from, to)
+		if err := godiff.DefaultDiffer().Diff(w, bytes.NewReader(c.Before), bytes.NewReader(c.After)); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(changes), nil
+}
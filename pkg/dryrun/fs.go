@@ -0,0 +1,356 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package dryrun runs changes to an app against an in-memory overlay of its
+// filesystem, and reports the edits they would have made.
+package dryrun
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+// Fs is a filesystem which reads from a base filesystem, and keeps every
+// change, including removals, in memory. The base filesystem is never
+// written to.
+type Fs struct {
+	base  afero.Fs
+	layer afero.Fs
+	// removed are the paths which were removed. Paths under them are hidden
+	// in the base filesystem.
+	removed map[string]bool
+}
+
+var _ afero.Fs = (*Fs)(nil)
+
+// NewFs creates an instance of Fs over base.
+func NewFs(base afero.Fs) *Fs {
+	return &Fs{
+		base:    base,
+		layer:   afero.NewMemMapFs(),
+		removed: make(map[string]bool),
+	}
+}
+
+// Name is the name of the filesystem.
+func (fs *Fs) Name() string {
+	return "DryRunFs"
+}
+
+// hidden returns true if name, or a directory containing it, was removed.
+func (fs *Fs) hidden(name string) bool {
+	for p := name; ; p = filepath.Dir(p) {
+		if fs.removed[p] {
+			return true
+		}
+		if p == filepath.Dir(p) {
+			return false
+		}
+	}
+}
+
+func (fs *Fs) inLayer(name string) bool {
+	_, err := fs.layer.Stat(name)
+	return err == nil
+}
+
+func (fs *Fs) inBase(name string) bool {
+	if fs.hidden(name) {
+		return false
+	}
+	_, err := fs.base.Stat(name)
+	return err == nil
+}
+
+// Stat returns the file info of name.
+func (fs *Fs) Stat(name string) (os.FileInfo, error) {
+	name = filepath.Clean(name)
+	if fs.inLayer(name) {
+		return fs.layer.Stat(name)
+	}
+	if fs.hidden(name) {
+		return nil, notExist("stat", name)
+	}
+	return fs.base.Stat(name)
+}
+
+// Open opens name for reading.
+func (fs *Fs) Open(name string) (afero.File, error) {
+	return fs.OpenFile(name, os.O_RDONLY, 0)
+}
+
+// OpenFile opens name. Files opened for writing are copied to the overlay
+// first.
+func (fs *Fs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	name = filepath.Clean(name)
+
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_APPEND|os.O_CREATE|os.O_TRUNC) != 0 {
+		if err := fs.copyUp(name, flag&os.O_TRUNC == 0); err != nil {
+			return nil, err
+		}
+		return fs.layer.OpenFile(name, flag, perm)
+	}
+
+	fi, err := fs.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var f afero.File
+	if fs.inLayer(name) {
+		f, err = fs.layer.OpenFile(name, flag, perm)
+	} else {
+		f, err = fs.base.OpenFile(name, flag, perm)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if fi.IsDir() {
+		return &dir{File: f, fs: fs, name: name}, nil
+	}
+
+	return f, nil
+}
+
+// Create creates or truncates name.
+func (fs *Fs) Create(name string) (afero.File, error) {
+	return fs.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+}
+
+// Mkdir creates a directory.
+func (fs *Fs) Mkdir(name string, perm os.FileMode) error {
+	name = filepath.Clean(name)
+	if _, err := fs.Stat(name); err == nil {
+		return &os.PathError{Op: "mkdir", Path: name, Err: os.ErrExist}
+	}
+	if _, err := fs.Stat(filepath.Dir(name)); err != nil {
+		return err
+	}
+	return fs.MkdirAll(name, perm)
+}
+
+// MkdirAll creates a directory and its parents.
+func (fs *Fs) MkdirAll(name string, perm os.FileMode) error {
+	return fs.layer.MkdirAll(filepath.Clean(name), perm)
+}
+
+// Remove removes a file or an empty directory.
+func (fs *Fs) Remove(name string) error {
+	name = filepath.Clean(name)
+
+	fi, err := fs.Stat(name)
+	if err != nil {
+		return err
+	}
+
+	if fi.IsDir() {
+		names, err := afero.ReadDir(fs, name)
+		if err != nil {
+			return err
+		}
+		if len(names) > 0 {
+			return &os.PathError{Op: "remove", Path: name, Err: errors.New("directory not empty")}
+		}
+	}
+
+	return fs.RemoveAll(name)
+}
+
+// RemoveAll removes name and everything it contains.
+func (fs *Fs) RemoveAll(name string) error {
+	name = filepath.Clean(name)
+	fs.removed[name] = true
+	return fs.layer.RemoveAll(name)
+}
+
+// Rename moves oldname to newname.
+func (fs *Fs) Rename(oldname, newname string) error {
+	oldname, newname = filepath.Clean(oldname), filepath.Clean(newname)
+
+	err := afero.Walk(fs, oldname, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(oldname, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(newname, rel)
+
+		if fi.IsDir() {
+			return fs.MkdirAll(target, fi.Mode().Perm())
+		}
+
+		data, err := afero.ReadFile(fs, path)
+		if err != nil {
+			return err
+		}
+		return afero.WriteFile(fs, target, data, fi.Mode().Perm())
+	})
+	if err != nil {
+		return err
+	}
+
+	return fs.RemoveAll(oldname)
+}
+
+// Chmod changes the mode of name.
+func (fs *Fs) Chmod(name string, mode os.FileMode) error {
+	name = filepath.Clean(name)
+	if err := fs.copyUp(name, true); err != nil {
+		return err
+	}
+	return fs.layer.Chmod(name, mode)
+}
+
+// Chtimes changes the access and modification times of name.
+func (fs *Fs) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	name = filepath.Clean(name)
+	if err := fs.copyUp(name, true); err != nil {
+		return err
+	}
+	return fs.layer.Chtimes(name, atime, mtime)
+}
+
+// copyUp prepares name to be written in the overlay. Its directory is
+// created, and if keep is true, an existing base file is copied.
+func (fs *Fs) copyUp(name string, keep bool) error {
+	if err := fs.layer.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+
+	if fs.inLayer(name) || !fs.inBase(name) {
+		return nil
+	}
+
+	fi, err := fs.base.Stat(name)
+	if err != nil {
+		return err
+	}
+
+	if fi.IsDir() {
+		return fs.layer.MkdirAll(name, fi.Mode().Perm())
+	}
+
+	var data []byte
+	if keep {
+		if data, err = afero.ReadFile(fs.base, name); err != nil {
+			return err
+		}
+	}
+
+	return afero.WriteFile(fs.layer, name, data, fi.Mode().Perm())
+}
+
+// readDir lists the entries of a directory in both filesystems.
+func (fs *Fs) readDir(name string) ([]os.FileInfo, error) {
+	entries := make(map[string]os.FileInfo)
+
+	if fs.inBase(name) {
+		fis, err := afero.ReadDir(fs.base, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, fi := range fis {
+			if !fs.hidden(filepath.Join(name, fi.Name())) {
+				entries[fi.Name()] = fi
+			}
+		}
+	}
+
+	if fs.inLayer(name) {
+		fis, err := afero.ReadDir(fs.layer, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, fi := range fis {
+			entries[fi.Name()] = fi
+		}
+	}
+
+	var fis []os.FileInfo
+	for _, fi := range entries {
+		fis = append(fis, fi)
+	}
+	sort.Slice(fis, func(i, j int) bool {
+		return fis[i].Name() < fis[j].Name()
+	})
+
+	return fis, nil
+}
+
+func notExist(op, name string) error {
+	return &os.PathError{Op: op, Path: name, Err: os.ErrNotExist}
+}
+
+// dir is a directory opened from Fs. Its entries are merged from both
+// filesystems.
+type dir struct {
+	afero.File
+
+	fs      *Fs
+	name    string
+	entries []os.FileInfo
+	read    bool
+}
+
+func (d *dir) Readdir(count int) ([]os.FileInfo, error) {
+	if !d.read {
+		entries, err := d.fs.readDir(d.name)
+		if err != nil {
+			return nil, err
+		}
+		d.entries = entries
+		d.read = true
+	}
+
+	if count <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+
+	if count > len(d.entries) {
+		count = len(d.entries)
+	}
+	entries := d.entries[:count]
+	d.entries = d.entries[count:]
+	return entries, nil
+}
+
+func (d *dir) Readdirnames(count int) ([]string, error) {
+	fis, err := d.Readdir(count)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, fi := range fis {
+		names = append(names, fi.Name())
+	}
+	return names, nil
+}
@@ -0,0 +1,171 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package dryrun
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withBase(t *testing.T, fn func(base afero.Fs, fs *Fs)) {
+	base := afero.NewMemMapFs()
+
+	files := map[string]string{
+		"/app/app.yaml":                          "name: app\n",
+		"/app/components/params.libsonnet":       "{}\n",
+		"/app/components/guestbook.jsonnet":      "{}\n",
+		"/app/environments/default/main.jsonnet": "{}\n",
+	}
+	for path, content := range files {
+		require.NoError(t, afero.WriteFile(base, path, []byte(content), 0644))
+	}
+
+	fn(base, NewFs(base))
+}
+
+func readDirNames(t *testing.T, fs afero.Fs, path string) []string {
+	fis, err := afero.ReadDir(fs, path)
+	require.NoError(t, err)
+
+	var names []string
+	for _, fi := range fis {
+		names = append(names, fi.Name())
+	}
+	return names
+}
+
+func TestFs_write(t *testing.T) {
+	withBase(t, func(base afero.Fs, fs *Fs) {
+		require.NoError(t, afero.WriteFile(fs, "/app/app.yaml", []byte("name: updated\n"), 0644))
+		require.NoError(t, afero.WriteFile(fs, "/app/components/new.yaml", []byte("kind: Service\n"), 0644))
+
+		data, err := afero.ReadFile(fs, "/app/app.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, "name: updated\n", string(data))
+
+		data, err = afero.ReadFile(base, "/app/app.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, "name: app\n", string(data), "base was changed")
+
+		exists, err := afero.Exists(base, "/app/components/new.yaml")
+		require.NoError(t, err)
+		assert.False(t, exists, "base was changed")
+
+		expected := []string{"guestbook.jsonnet", "new.yaml", "params.libsonnet"}
+		assert.Equal(t, expected, readDirNames(t, fs, "/app/components"))
+	})
+}
+
+func TestFs_append(t *testing.T) {
+	withBase(t, func(base afero.Fs, fs *Fs) {
+		f, err := fs.OpenFile("/app/app.yaml", os.O_APPEND|os.O_WRONLY, 0644)
+		require.NoError(t, err)
+		_, err = f.WriteString("version: 0.0.1\n")
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		data, err := afero.ReadFile(fs, "/app/app.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, "name: app\nversion: 0.0.1\n", string(data))
+	})
+}
+
+func TestFs_remove(t *testing.T) {
+	withBase(t, func(base afero.Fs, fs *Fs) {
+		require.NoError(t, fs.Remove("/app/components/guestbook.jsonnet"))
+		require.NoError(t, fs.RemoveAll("/app/environments"))
+
+		_, err := fs.Stat("/app/components/guestbook.jsonnet")
+		assert.True(t, os.IsNotExist(err))
+		_, err = fs.Stat("/app/environments/default/main.jsonnet")
+		assert.True(t, os.IsNotExist(err))
+		_, err = fs.Open("/app/environments")
+		assert.True(t, os.IsNotExist(err))
+
+		assert.Equal(t, []string{"params.libsonnet"}, readDirNames(t, fs, "/app/components"))
+		assert.Equal(t, []string{"app.yaml", "components"}, readDirNames(t, fs, "/app"))
+
+		exists, err := afero.Exists(base, "/app/environments/default/main.jsonnet")
+		require.NoError(t, err)
+		assert.True(t, exists, "base was changed")
+
+		err = fs.Remove("/app/components")
+		assert.Error(t, err, "removed a directory which is not empty")
+
+		require.NoError(t, afero.WriteFile(fs, "/app/environments/prod/main.jsonnet", []byte("{}\n"), 0644))
+		assert.Equal(t, []string{"prod"}, readDirNames(t, fs, "/app/environments"))
+	})
+}
+
+func TestFs_rename(t *testing.T) {
+	withBase(t, func(base afero.Fs, fs *Fs) {
+		require.NoError(t, fs.Rename("/app/environments/default", "/app/environments/dev"))
+
+		assert.Equal(t, []string{"dev"}, readDirNames(t, fs, "/app/environments"))
+
+		data, err := afero.ReadFile(fs, "/app/environments/dev/main.jsonnet")
+		require.NoError(t, err)
+		assert.Equal(t, "{}\n", string(data))
+	})
+}
+
+func TestFs_Diff(t *testing.T) {
+	withBase(t, func(base afero.Fs, fs *Fs) {
+		require.NoError(t, afero.WriteFile(fs, "/app/app.yaml", []byte("name: updated\n"), 0644))
+		require.NoError(t, afero.WriteFile(fs, "/app/components/params.libsonnet", []byte("{}\n"), 0644))
+		require.NoError(t, afero.WriteFile(fs, "/app/components/new.yaml", []byte("kind: Service\n"), 0644))
+		require.NoError(t, fs.Remove("/app/components/guestbook.jsonnet"))
+
+		changes, err := fs.Changes()
+		require.NoError(t, err)
+
+		expected := []Change{
+			{
+				Path:   "/app/app.yaml",
+				Before: []byte("name: app\n"),
+				After:  []byte("name: updated\n"),
+			},
+			{
+				Path:    "/app/components/guestbook.jsonnet",
+				Before:  []byte("{}\n"),
+				Removed: true,
+			},
+			{
+				Path:    "/app/components/new.yaml",
+				After:   []byte("kind: Service\n"),
+				Created: true,
+			},
+		}
+		assert.Equal(t, expected, changes)
+
+		var buf bytes.Buffer
+		n, err := fs.Diff(&buf, "/app")
+		require.NoError(t, err)
+		assert.Equal(t, 3, n)
+
+		out := buf.String()
+		assert.Contains(t, out, "--- a/app.yaml\n+++ b/app.yaml\n")
+		assert.Contains(t, out, "-name: app\n+name: updated\n")
+		assert.Contains(t, out, "--- a/components/guestbook.jsonnet\n+++ /dev/null\n")
+		assert.Contains(t, out, "--- /dev/null\n+++ b/components/new.yaml\n")
+		assert.NotContains(t, out, "params.libsonnet")
+	})
+}
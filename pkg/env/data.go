@@ -23,27 +23,35 @@ const (
 )
 
 // DefaultOverrideData generates the contents for an environment's `main.jsonnet`.
+// Code between custom region markers is kept when the file is regenerated.
 var DefaultOverrideData = []byte(`local base = import "base.libsonnet";
+` + CustomBeginMarker + ` imports
 // uncomment if you reference ksonnet-lib
 // local k = import "k.libsonnet";
 // local deployment = k.apps.v1beta2.deployment;
+` + CustomEndMarker + ` imports
 
 base + {
+  ` + CustomBeginMarker + ` overrides
   // Insert user-specified overrides here. For example if a component is named \"nginx-deployment\", you might have something like:\n")
   // "nginx-deployment"+: deployment.mixin.metadata.withLabels({foo: "bar"})
+  ` + CustomEndMarker + ` overrides
 }
 `)
 
 // DefaultParamsData generates the contents for an environment's `params.libsonnet`
+// Code between custom region markers is kept when the file is regenerated.
 var DefaultParamsData = []byte(`local params = std.extVar("__ksonnet/params");
 local globals = import "globals.libsonnet";
 local envParams = params + {
   components +: {
+    ` + CustomBeginMarker + ` components
     // Insert component parameter overrides here. Ex:
     // guestbook +: {
     //   name: "guestbook-dev",
     //   replicas: params.global.replicas,
     // },
+    ` + CustomEndMarker + ` components
   },
 };
 
@@ -117,53 +117,71 @@ func Evaluate(a app.App, envName, components, paramsStr string, opts ...jsonnet.
 }
 
 func evaluateMain(a app.App, envName, snippet, components, paramsStr string, opts ...jsonnet.VMOpt) (string, error) {
-	libPath, err := a.LibPath(envName)
+	vm, cleanup, err := newVM(a, envName, components, paramsStr, opts...)
 	if err != nil {
 		return "", err
 	}
+	defer cleanup()
 
-	appEnv, err := a.Environment(envName)
+	return vm.EvaluateSnippet(envFileName, snippet)
+}
+
+// EvaluateSnippet evaluates a jsonnet snippet with the import paths, external
+// variables, and parameters components are evaluated with in an environment.
+func EvaluateSnippet(a app.App, envName, filename, snippet, paramsStr string, opts ...jsonnet.VMOpt) (string, error) {
+	vm, cleanup, err := newVM(a, envName, "{}", paramsStr, opts...)
 	if err != nil {
 		return "", err
 	}
+	defer cleanup()
 
-	vm := jsonnet.NewVM(opts...)
+	return vm.EvaluateSnippet(filename, snippet)
+}
 
-	vm.AddJPath(componentJPaths...)
-	vm.AddJPath(
-		filepath.Join(a.Root(), envRootName),
-		filepath.Join(a.Root(), envRootName, appEnv.Path),
-		filepath.Join(a.Root(), "vendor"),
-		filepath.Join(a.Root(), "lib"),
-		libPath,
-	)
+// newVM creates a VM for evaluating components in an environment. cleanup
+// removes the packages revendored for the environment.
+func newVM(a app.App, envName, components, paramsStr string, opts ...jsonnet.VMOpt) (vm *jsonnet.VM, cleanup func() error, err error) {
+	appEnv, err := a.Environment(envName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Remote imports wrap the importer set by opts. Untrusted apps can not
+	// make the host fetch URLs.
+	fetch := registry.NewRemoteImports(a, nil).Fetch
+	if app.IsUntrusted(a) {
+		fetch = func(u string) ([]byte, error) {
+			return nil, errors.Errorf("importing %s: remote imports are disabled for untrusted apps", u)
+		}
+	}
+	opts = append(opts[:len(opts):len(opts)], jsonnet.RemoteImporterOpt(fetch))
+
+	vm = jsonnet.NewVM(opts...)
 
 	helmRenderer := helm.NewRenderer(a, envName)
-	vm.AddFunctions(helmRenderer.JsonnetNativeFunc())
+	vm.AddFunctions(helmRenderer.JsonnetNativeFunc(), helmRenderer.HelmTemplateNativeFunc())
 
 	// Re-vendor versioned packages, such that import paths will remain path-agnostic.
 	// TODO Where should packagemanager come from?
 	pm := registry.NewPackageManager(a)
 	revendoredPath, cleanup, err := revendorPackages(a, pm, appEnv)
 	if err != nil {
-		return "", errors.Wrapf(err, "revendoring packages for environment: %v", envName)
+		return nil, nil, errors.Wrapf(err, "revendoring packages for environment: %v", envName)
 	}
-	defer cleanup()
-	vm.AddJPath(revendoredPath) // TODO does precedence matter?
-	// end re-vendor
 
-	if len(appEnv.Targets) == 0 {
-		vm.AddJPath(filepath.Join(a.Root(), "components"))
-	} else {
-		for _, moduleName := range appEnv.Targets {
-			path := filepath.Join(append([]string{a.Root(), "components"}, moduleName)...)
-			vm.AddJPath(path)
-		}
+	jPaths, err := JPaths(a, envName, revendoredPath)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
 	}
 
+	vm.AddJPath(componentJPaths...)
+	vm.AddJPath(jPaths...)
+
 	envCode, err := params.JsonnetEnvObject(a, envName)
 	if err != nil {
-		return "", err
+		cleanup()
+		return nil, nil, err
 	}
 
 	for k, v := range componentExtVars {
@@ -178,7 +196,45 @@ func evaluateMain(a app.App, envName, snippet, components, paramsStr string, opt
 	vm.ExtCode(ComponentsExtCodeKey, components)
 	vm.ExtCode("__ksonnet/params", paramsStr)
 
-	return vm.EvaluateSnippet(envFileName, snippet)
+	return vm, cleanup, nil
+}
+
+// JPaths returns the library paths components in an environment are
+// evaluated with. Later paths take precedence. packagesPath holds the environment's
+// versioned packages without their versions (see VendorPackages). It is
+// skipped if empty.
+func JPaths(a app.App, envName, packagesPath string) ([]string, error) {
+	libPath, err := a.LibPath(envName)
+	if err != nil {
+		return nil, err
+	}
+
+	appEnv, err := a.Environment(envName)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{
+		filepath.Join(a.Root(), envRootName),
+		filepath.Join(a.Root(), envRootName, appEnv.Path),
+		filepath.Join(a.Root(), "vendor"),
+		filepath.Join(a.Root(), "lib"),
+		libPath,
+	}
+
+	if packagesPath != "" {
+		paths = append(paths, packagesPath) // TODO does precedence matter?
+	}
+
+	if len(appEnv.Targets) == 0 {
+		paths = append(paths, filepath.Join(a.Root(), "components"))
+	} else {
+		for _, moduleName := range appEnv.Targets {
+			paths = append(paths, filepath.Join(append([]string{a.Root(), "components"}, moduleName)...))
+		}
+	}
+
+	return paths, nil
 }
 
 // upgradeArray wraps component lists in Kubernetes lists.
@@ -228,9 +284,10 @@ func environmentsCode(a app.App, envName string) (string, error) {
 		return "", err
 	}
 
+	destination := envDetails.PrimaryDestination()
 	dest := map[string]string{
-		"server":    envDetails.Destination.Server,
-		"namespace": envDetails.Destination.Namespace,
+		"server":    destination.Server,
+		"namespace": destination.Namespace,
 	}
 
 	marshalledDestination, err := json.Marshal(&dest)
@@ -279,8 +336,6 @@ func buildPackagePaths(pm registry.PackageManager, e *app.EnvironmentConfig) (ma
 // The caller is responsible for calling the returned cleanup function to release
 // and temporary resources.
 func revendorPackages(a app.App, pm registry.PackageManager, e *app.EnvironmentConfig) (path string, cleanup func() error, err error) {
-	log := log.WithField("action", "env.revendorPackages")
-
 	noop := func() error { return nil }
 
 	if a == nil {
@@ -318,9 +373,50 @@ func revendorPackages(a app.App, pm registry.PackageManager, e *app.EnvironmentC
 	}
 	defer internalCleanFunc()
 
-	// Copy each package to our temp directory destined for import,
-	// removing version information from the path.
-	// This allows our consumers to import the package with a version-agnostic import specifier.
+	if err := copyPackages(fs, pathByPkg, tmpDir); err != nil {
+		return "", noop, err
+	}
+
+	// Signal to our deferred cleanup function that our caller is now
+	// the responsible party for cleaning up the temp directory.
+	shouldCleanup = false
+	callerCleanFunc := func() error {
+		return fs.RemoveAll(tmpDir)
+	}
+	return tmpDir, callerCleanFunc, nil
+}
+
+// VendorPackages copies the versioned packages of an environment to dir
+// without their versions, the way they are imported during evaluation. Any
+// existing contents of dir are replaced.
+func VendorPackages(a app.App, pm registry.PackageManager, envName, dir string) error {
+	e, err := a.Environment(envName)
+	if err != nil {
+		return err
+	}
+
+	pathByPkg, err := buildPackagePaths(pm, e)
+	if err != nil {
+		return err
+	}
+
+	fs := a.Fs()
+	if err := fs.RemoveAll(dir); err != nil {
+		return errors.Wrapf(err, "removing %v", dir)
+	}
+	if err := fs.MkdirAll(dir, app.DefaultFolderPermissions); err != nil {
+		return errors.Wrapf(err, "creating %v", dir)
+	}
+
+	return copyPackages(fs, pathByPkg, dir)
+}
+
+// copyPackages copies each package to dir, removing version information
+// from the path. This allows our consumers to import the package with a
+// version-agnostic import specifier.
+func copyPackages(fs afero.Fs, pathByPkg map[string]string, dir string) error {
+	log := log.WithField("action", "env.copyPackages")
+
 	for k, srcPath := range pathByPkg {
 		if srcPath == "" {
 			log.Warnf("skipping package %v", k)
@@ -333,7 +429,7 @@ func revendorPackages(a app.App, pm registry.PackageManager, e *app.EnvironmentC
 		// Currently we assume #1 and skip revendoring - it can be imported from the legacy path.
 		ok, err := afero.Exists(fs, srcPath)
 		if err != nil {
-			return "", noop, err
+			return err
 		}
 		if !ok {
 			// TODO differentiate between above cases #1 and #2.
@@ -341,18 +437,12 @@ func revendorPackages(a app.App, pm registry.PackageManager, e *app.EnvironmentC
 			continue
 		}
 
-		dstPath := filepath.Join(tmpDir, filepath.FromSlash(k))
+		dstPath := filepath.Join(dir, filepath.FromSlash(k))
 		log.Debugf("preparing package %v->%v", srcPath, dstPath)
 		if err := utilio.CopyRecursive(fs, dstPath, srcPath, app.DefaultFilePermissions, app.DefaultFolderPermissions); err != nil {
-			return "", noop, errors.Wrapf(err, "copying package %v->%v", srcPath, dstPath)
+			return errors.Wrapf(err, "copying package %v->%v", srcPath, dstPath)
 		}
 	}
 
-	// Signal to our deferred cleanup function that our caller is now
-	// the responsible party for cleaning up the temp directory.
-	shouldCleanup = false
-	callerCleanFunc := func() error {
-		return fs.RemoveAll(tmpDir)
-	}
-	return tmpDir, callerCleanFunc, nil
+	return nil
 }
@@ -286,6 +286,53 @@ func TestEvaluate_versionedPackages(t *testing.T) {
 	})
 }
 
+func TestEvaluateSnippet(t *testing.T) {
+	test.WithApp(t, "/app", func(a *mocks.App, fs afero.Fs) {
+		envSpec := &app.EnvironmentConfig{
+			Path: "default",
+			Destination: &app.EnvironmentDestinationSpec{
+				Server:    "http://example.com",
+				Namespace: "default",
+			},
+		}
+		a.On("Environment", "default").Return(envSpec, nil)
+		a.On("Libraries").Return(app.LibraryConfigs{}, nil)
+		a.On("Registries").Return(app.RegistryConfigs{}, nil)
+
+		snippet := `{
+  namespace: std.extVar("__ksonnet/environments").namespace,
+  replicas: std.extVar("__ksonnet/params").components.guestbook.replicas,
+}`
+		paramsStr := `{"components": {"guestbook": {"replicas": 2}}}`
+
+		got, err := EvaluateSnippet(a, "default", "<eval>", snippet, paramsStr)
+		require.NoError(t, err)
+
+		require.JSONEq(t, `{"namespace": "default", "replicas": 2}`, got)
+	})
+}
+
+func TestEvaluateSnippet_untrusted_app(t *testing.T) {
+	test.WithApp(t, "/app", func(a *mocks.App, fs afero.Fs) {
+		envSpec := &app.EnvironmentConfig{
+			Path: "default",
+			Destination: &app.EnvironmentDestinationSpec{
+				Server:    "http://example.com",
+				Namespace: "default",
+			},
+		}
+		a.On("Environment", "default").Return(envSpec, nil)
+		a.On("Libraries").Return(app.LibraryConfigs{}, nil)
+		a.On("Registries").Return(app.RegistryConfigs{}, nil)
+
+		snippet := `import "https://example.com/lib.libsonnet"`
+
+		_, err := EvaluateSnippet(app.Untrusted(a), "default", "<eval>", snippet, "{}")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "remote imports are disabled for untrusted apps")
+	})
+}
+
 func TestMainFile(t *testing.T) {
 	test.WithApp(t, "/app", func(a *mocks.App, fs afero.Fs) {
 		envSpec := &app.EnvironmentConfig{}
@@ -428,3 +475,95 @@ func Test_revendorPackages(t *testing.T) {
 
 	})
 }
+
+func TestJPaths(t *testing.T) {
+	cases := []struct {
+		name         string
+		targets      []string
+		packagesPath string
+		expected     []string
+	}{
+		{
+			name: "no targets",
+			expected: []string{
+				"/app/environments",
+				"/app/environments/default",
+				"/app/vendor",
+				"/app/lib",
+				"/app/lib/v1.8.7",
+				"/app/components",
+			},
+		},
+		{
+			name:         "targets and packages",
+			targets:      []string{"a", "b"},
+			packagesPath: "/app/.ksonnet/packages",
+			expected: []string{
+				"/app/environments",
+				"/app/environments/default",
+				"/app/vendor",
+				"/app/lib",
+				"/app/lib/v1.8.7",
+				"/app/.ksonnet/packages",
+				"/app/components/a",
+				"/app/components/b",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			test.WithApp(t, "/app", func(a *mocks.App, fs afero.Fs) {
+				envSpec := &app.EnvironmentConfig{
+					Path:    "default",
+					Targets: tc.targets,
+				}
+				a.On("Environment", "default").Return(envSpec, nil)
+
+				got, err := JPaths(a, "default", tc.packagesPath)
+				require.NoError(t, err)
+
+				expected := make([]string, len(tc.expected))
+				for i := range tc.expected {
+					expected[i] = filepath.FromSlash(tc.expected[i])
+				}
+				require.Equal(t, expected, got)
+			})
+		})
+	}
+}
+
+func TestVendorPackages(t *testing.T) {
+	r := "incubator"
+	e := &app.EnvironmentConfig{Name: "default"}
+	packages := []pkg.Package{
+		makePackage(r, "nginx", "1.2.3", true),
+		makePackage(r, "mysql", "00112233ff", true),
+	}
+	pm := new(rmocks.PackageManager)
+	pm.On("PackagesForEnv", e).Return(packages, nil)
+
+	test.WithApp(t, "/app", func(a *mocks.App, fs afero.Fs) {
+		a.On("Environment", "default").Return(e, nil)
+
+		for _, p := range packages {
+			test.StageDir(t, fs, filepath.Join("packages", p.Name()), p.Path())
+		}
+
+		dir := filepath.Join("/app", ".ksonnet", "packages")
+		stale := filepath.Join(dir, "incubator", "removed", "parts.yaml")
+		require.NoError(t, fs.MkdirAll(filepath.Dir(stale), app.DefaultFolderPermissions))
+		require.NoError(t, afero.WriteFile(fs, stale, []byte("{}"), app.DefaultFilePermissions))
+
+		err := VendorPackages(a, pm, "default", dir)
+		require.NoError(t, err)
+
+		for _, p := range packages {
+			test.AssertDirectoriesMatch(t, fs, p.Path(), filepath.Join(dir, r, p.Name()))
+		}
+
+		exists, err := afero.Exists(fs, stale)
+		require.NoError(t, err)
+		assert.False(t, exists, "stale package was not removed")
+	})
+}
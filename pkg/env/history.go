@@ -0,0 +1,304 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package env
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	param "github.com/ksonnet/ksonnet/metadata/params"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+const (
+	// historyFileName records changes to an environment's params.
+	historyFileName = "params.history.yaml"
+)
+
+var (
+	// nowFn and gitFn are overridden in tests.
+	nowFn = time.Now
+	gitFn = git
+)
+
+// ParamChange is a change to an environment param.
+type ParamChange struct {
+	// Component is the component the param belongs to. It is empty for
+	// global params.
+	Component string `json:"component,omitempty"`
+	Param     string `json:"param"`
+	// Value is the param's new value. It is empty if the param was deleted.
+	Value   string    `json:"value,omitempty"`
+	Deleted bool      `json:"deleted,omitempty"`
+	Author  string    `json:"author"`
+	Time    time.Time `json:"time"`
+	Message string    `json:"message,omitempty"`
+	// Commit is the git commit a change was found in. It is only set for
+	// changes from git blame.
+	Commit string `json:"commit,omitempty"`
+}
+
+type paramHistory struct {
+	Changes []ParamChange `json:"changes"`
+}
+
+// RecordParamChange appends a change to an environment's param history. The
+// author and time are set if they are empty.
+func RecordParamChange(a app.App, envName string, change ParamChange) error {
+	path, err := Path(a, envName, historyFileName)
+	if err != nil {
+		return err
+	}
+
+	history, err := readHistory(a.Fs(), path)
+	if err != nil {
+		return err
+	}
+
+	if change.Author == "" {
+		change.Author = author(a.Root())
+	}
+	if change.Time.IsZero() {
+		change.Time = nowFn().UTC()
+	}
+
+	history.Changes = append(history.Changes, change)
+
+	data, err := yaml.Marshal(history)
+	if err != nil {
+		return err
+	}
+
+	return afero.WriteFile(a.Fs(), path, data, app.DefaultFilePermissions)
+}
+
+// ParamHistory returns the recorded changes to a component's params in an
+// environment, oldest first. If component is empty, it returns every change.
+func ParamHistory(a app.App, envName, component string) ([]ParamChange, error) {
+	path, err := Path(a, envName, historyFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := readHistory(a.Fs(), path)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []ParamChange
+	for _, change := range history.Changes {
+		if component == "" || change.Component == component {
+			changes = append(changes, change)
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Time.Before(changes[j].Time)
+	})
+
+	return changes, nil
+}
+
+func readHistory(fs afero.Fs, path string) (*paramHistory, error) {
+	var history paramHistory
+
+	data, err := afero.ReadFile(fs, path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &history, nil
+		}
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(data, &history); err != nil {
+		return nil, errors.Wrapf(err, "parsing %s", path)
+	}
+
+	return &history, nil
+}
+
+// BlameParams returns a change for each param a component sets in an
+// environment, attributed to the git commit which last changed the param's
+// line. It returns no changes if git is not available or the environment's
+// params are not committed.
+func BlameParams(a app.App, envName, component string) ([]ParamChange, error) {
+	path, err := Path(a, envName, paramsFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := afero.ReadFile(a.Fs(), path)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := param.EnvironmentParamLines(component, string(text))
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+
+	out, err := gitFn(filepath.Dir(path), "blame", "--porcelain", "--", filepath.Base(path))
+	if err != nil {
+		log.Debugf("skipping git blame of %s: %v", path, err)
+		return nil, nil
+	}
+
+	commits := parseBlame(out)
+
+	all, err := param.GetAllEnvironmentParams(string(text))
+	if err != nil {
+		return nil, err
+	}
+	values := all[component]
+
+	var changes []ParamChange
+	for name, line := range lines {
+		c, ok := commits[line]
+		if !ok {
+			continue
+		}
+
+		// Params are read as jsonnet source, which is a string.
+		value, _ := values[name].(string)
+
+		changes = append(changes, ParamChange{
+			Component: component,
+			Param:     name,
+			Value:     value,
+			Author:    c.author,
+			Time:      c.time,
+			Message:   c.summary,
+			Commit:    c.sha,
+		})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Time.Equal(changes[j].Time) {
+			return changes[i].Param < changes[j].Param
+		}
+		return changes[i].Time.Before(changes[j].Time)
+	})
+
+	return changes, nil
+}
+
+type blameCommit struct {
+	sha     string
+	author  string
+	time    time.Time
+	summary string
+}
+
+// parseBlame parses the output of git blame --porcelain into the commit which
+// last changed each line. Uncommitted lines are left out.
+func parseBlame(out string) map[int]blameCommit {
+	commits := make(map[string]*blameCommit)
+	lines := make(map[int]blameCommit)
+
+	var current *blameCommit
+	var line int
+
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		text := scanner.Text()
+
+		if strings.HasPrefix(text, "\t") {
+			if current != nil && strings.Trim(current.sha, "0") != "" {
+				lines[line] = *current
+			}
+			continue
+		}
+
+		key, value := text, ""
+		if i := strings.Index(text, " "); i != -1 {
+			key, value = text[:i], text[i+1:]
+		}
+
+		switch {
+		case current != nil && key == "author":
+			current.author = value
+		case current != nil && key == "author-mail":
+			current.author += " " + value
+		case current != nil && key == "author-time":
+			if sec, err := strconv.ParseInt(value, 10, 64); err == nil {
+				current.time = time.Unix(sec, 0).UTC()
+			}
+		case current != nil && key == "summary":
+			current.summary = value
+		default:
+			fields := strings.Fields(text)
+			if len(fields) < 3 || len(fields[0]) != 40 {
+				continue
+			}
+
+			sha := fields[0]
+			if commits[sha] == nil {
+				commits[sha] = &blameCommit{sha: sha}
+			}
+			current = commits[sha]
+			line, _ = strconv.Atoi(fields[2])
+		}
+	}
+
+	return lines
+}
+
+// author returns the git user of the app, or the current user if git is not
+// configured.
+func author(root string) string {
+	if name, err := gitFn(root, "config", "user.name"); err == nil && name != "" {
+		if email, err := gitFn(root, "config", "user.email"); err == nil && email != "" {
+			return name + " <" + email + ">"
+		}
+		return name
+	}
+
+	if user := os.Getenv("USER"); user != "" {
+		return user
+	}
+
+	return "unknown"
+}
+
+// git runs a git command in a directory.
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errors.Errorf("git %s: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,187 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package env
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withGit(t *testing.T, git func(dir string, args ...string) (string, error), fn func(*mocks.App)) {
+	ogGitFn, ogNowFn := gitFn, nowFn
+	defer func() {
+		gitFn, nowFn = ogGitFn, ogNowFn
+	}()
+
+	gitFn = git
+	nowFn = func() time.Time {
+		return time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	}
+
+	withEnv(t, func(appMock *mocks.App, fs afero.Fs) {
+		fn(appMock)
+	})
+}
+
+func TestParamHistory(t *testing.T) {
+	git := func(dir string, args ...string) (string, error) {
+		switch strings.Join(args, " ") {
+		case "config user.name":
+			return "Jane", nil
+		case "config user.email":
+			return "jane@example.com", nil
+		}
+		return "", errors.New("unexpected git command")
+	}
+
+	withGit(t, git, func(appMock *mocks.App) {
+		changes := []ParamChange{
+			{Component: "component1", Param: "replicas", Value: "3", Message: "scale up"},
+			{Param: "region", Value: "'us-east-1'"},
+			{Component: "component1", Param: "replicas", Deleted: true, Author: "ops", Time: time.Date(2018, 7, 1, 0, 0, 0, 0, time.UTC)},
+		}
+
+		for _, change := range changes {
+			require.NoError(t, RecordParamChange(appMock, "env1", change))
+		}
+
+		got, err := ParamHistory(appMock, "env1", "component1")
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+
+		assert.Equal(t, "replicas", got[0].Param)
+		assert.Equal(t, "3", got[0].Value)
+		assert.Equal(t, "Jane <jane@example.com>", got[0].Author)
+		assert.Equal(t, "scale up", got[0].Message)
+		assert.Equal(t, time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC), got[0].Time)
+		assert.True(t, got[1].Deleted)
+		assert.Equal(t, "ops", got[1].Author)
+
+		got, err = ParamHistory(appMock, "env1", "")
+		require.NoError(t, err)
+		assert.Len(t, got, 3)
+	})
+}
+
+func TestRecordParamChange_without_git(t *testing.T) {
+	ogGitFn := gitFn
+	defer func() { gitFn = ogGitFn }()
+
+	gitFn = func(dir string, args ...string) (string, error) {
+		return "", errors.New("git was not found")
+	}
+
+	withEnv(t, func(appMock *mocks.App, fs afero.Fs) {
+		require.NoError(t, RecordParamChange(appMock, "env1", ParamChange{Component: "component1", Param: "foo", Value: "'baz'"}))
+
+		got, err := ParamHistory(appMock, "env1", "component1")
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.NotEmpty(t, got[0].Author)
+	})
+}
+
+func TestBlameParams(t *testing.T) {
+	blame := `1111111111111111111111111111111111111111 1 1 4
+author Jane
+author-mail <jane@example.com>
+author-time 1527854400
+author-tz +0000
+summary Add component1 params
+filename params.libsonnet
+	local params = import '../../components/params.libsonnet';
+1111111111111111111111111111111111111111 2 2
+	params + {
+1111111111111111111111111111111111111111 3 3
+	  components +: {
+1111111111111111111111111111111111111111 4 4
+	    component1 +: {
+0000000000000000000000000000000000000000 5 5 1
+author Not Committed Yet
+author-mail <not.committed.yet>
+author-time 1530403200
+author-tz +0000
+summary Version of params.libsonnet from params.libsonnet
+filename params.libsonnet
+	      foo: 'bar',
+`
+
+	ogGitFn := gitFn
+	defer func() { gitFn = ogGitFn }()
+
+	cases := []struct {
+		name     string
+		blame    string
+		expected []ParamChange
+	}{
+		{
+			name:  "uncommitted",
+			blame: blame,
+		},
+		{
+			name:  "committed",
+			blame: strings.Replace(blame, "0000000000000000000000000000000000000000 5 5 1", "2222222222222222222222222222222222222222 5 5 1", 1),
+			expected: []ParamChange{
+				{
+					Component: "component1",
+					Param:     "foo",
+					Value:     `"bar"`,
+					Author:    "Not Committed Yet <not.committed.yet>",
+					Time:      time.Unix(1530403200, 0).UTC(),
+					Message:   "Version of params.libsonnet from params.libsonnet",
+					Commit:    "2222222222222222222222222222222222222222",
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gitFn = func(dir string, args ...string) (string, error) {
+				assert.Equal(t, []string{"blame", "--porcelain", "--", "params.libsonnet"}, args)
+				return tc.blame, nil
+			}
+
+			withEnv(t, func(appMock *mocks.App, fs afero.Fs) {
+				got, err := BlameParams(appMock, "env1", "component1")
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, got)
+			})
+		})
+	}
+}
+
+func TestBlameParams_without_git(t *testing.T) {
+	ogGitFn := gitFn
+	defer func() { gitFn = ogGitFn }()
+
+	gitFn = func(dir string, args ...string) (string, error) {
+		return "", errors.New("not a git repository")
+	}
+
+	withEnv(t, func(appMock *mocks.App, fs afero.Fs) {
+		got, err := BlameParams(appMock, "env1", "component1")
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+}
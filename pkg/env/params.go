@@ -135,6 +135,10 @@ func SetParams(envName, component string, p param.Params, config SetParamsConfig
 		return err
 	}
 
+	if updated, err = params.Format(updated); err != nil {
+		return err
+	}
+
 	err = afero.WriteFile(config.App.Fs(), path, []byte(updated), app.DefaultFilePermissions)
 	if err != nil {
 		return err
@@ -166,6 +170,10 @@ func DeleteParam(a app.App, envName, componentName, paramName string) error {
 		return err
 	}
 
+	if updated, err = params.Format(updated); err != nil {
+		return err
+	}
+
 	err = afero.WriteFile(a.Fs(), path, []byte(updated), app.DefaultFilePermissions)
 	if err != nil {
 		return err
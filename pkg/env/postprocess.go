@@ -0,0 +1,74 @@
+// Copyright 2018 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package env
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/util/jsonnet"
+	"github.com/spf13/afero"
+)
+
+const (
+	// PostProcessFileName is the name of an environment's optional
+	// post-processing file. It evaluates to a function which is passed the
+	// environment's rendered objects, and returns the objects to use instead.
+	PostProcessFileName = "postprocess.libsonnet"
+
+	// PostProcessExtCodeKey is the ExtCode key for the objects passed to
+	// post-processing.
+	PostProcessExtCodeKey = "__ksonnet/objects"
+)
+
+// HasPostProcess returns true if an environment has a post-processing file.
+func HasPostProcess(a app.App, envName string) (bool, error) {
+	path, err := Path(a, envName, PostProcessFileName)
+	if err != nil {
+		return false, err
+	}
+
+	return afero.Exists(a.Fs(), path)
+}
+
+// PostProcess calls the function in an environment's post-processing file with
+// objects, a JSON array of the objects rendered for the environment. It
+// returns the result of the function, which is evaluated with the import paths
+// and parameters of components.
+func PostProcess(a app.App, envName, objects, paramsStr string, opts ...jsonnet.VMOpt) (string, error) {
+	path, err := Path(a, envName, PostProcessFileName)
+	if err != nil {
+		return "", err
+	}
+
+	source, err := afero.ReadFile(a.Fs(), path)
+	if err != nil {
+		return "", err
+	}
+
+	vm, cleanup, err := newVM(a, envName, "{}", paramsStr, opts...)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+
+	vm.ExtCode(PostProcessExtCodeKey, objects)
+
+	// The source stays on its own lines, so evaluation errors have the
+	// file's line numbers. Relative imports are resolved from the file's path.
+	snippet := "local postProcess = (" + string(source) + "\n);\n" +
+		`postProcess(std.extVar("` + PostProcessExtCodeKey + `"))`
+
+	return vm.EvaluateSnippet(path, snippet)
+}
@@ -0,0 +1,85 @@
+// Copyright 2018 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package env
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/util/jsonnet"
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withPostProcessEnv(t *testing.T, fn func(*mocks.App, afero.Fs)) {
+	test.WithApp(t, "/app", func(a *mocks.App, fs afero.Fs) {
+		envSpec := &app.EnvironmentConfig{
+			Path: "default",
+			Destination: &app.EnvironmentDestinationSpec{
+				Server:    "http://example.com",
+				Namespace: "default",
+			},
+		}
+		a.On("Environment", "default").Return(envSpec, nil)
+		a.On("Libraries").Return(app.LibraryConfigs{}, nil)
+		a.On("Registries").Return(app.RegistryConfigs{}, nil)
+
+		fn(a, fs)
+	})
+}
+
+func TestHasPostProcess(t *testing.T) {
+	withPostProcessEnv(t, func(a *mocks.App, fs afero.Fs) {
+		ok, err := HasPostProcess(a, "default")
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		test.StageFile(t, fs, "postprocess/postprocess.libsonnet", "/app/environments/default/postprocess.libsonnet")
+
+		ok, err = HasPostProcess(a, "default")
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+}
+
+func TestPostProcess(t *testing.T) {
+	withPostProcessEnv(t, func(a *mocks.App, fs afero.Fs) {
+		test.StageFile(t, fs, "postprocess/postprocess.libsonnet", "/app/environments/default/postprocess.libsonnet")
+		test.StageFile(t, fs, "postprocess/tolerations.libsonnet", "/app/environments/default/tolerations.libsonnet")
+
+		objects := test.ReadTestData(t, "postprocess/objects.json")
+		params := `{"global": {"dedicated": "ks"}}`
+
+		got, err := PostProcess(a, "default", objects, params, jsonnet.AferoImporterOpt(fs))
+		require.NoError(t, err)
+
+		test.AssertOutput(t, "postprocess/expected.json", got)
+	})
+}
+
+func TestPostProcess_error_lines(t *testing.T) {
+	withPostProcessEnv(t, func(a *mocks.App, fs afero.Fs) {
+		path := "/app/environments/default/postprocess.libsonnet"
+		require.NoError(t, afero.WriteFile(fs, path, []byte("function(objects)\n  error 'invalid'\n"), app.DefaultFilePermissions))
+
+		_, err := PostProcess(a, "default", "[]", "{}")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), path+":2:")
+	})
+}
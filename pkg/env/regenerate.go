@@ -0,0 +1,224 @@
+// Copyright 2018 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package env
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+const (
+	// CustomBeginMarker starts a custom region of a generated environment
+	// file. It is followed by the name of the region, e.g.
+	// `// ks:begin-custom overrides`.
+	CustomBeginMarker = "// ks:begin-custom"
+	// CustomEndMarker ends a custom region.
+	CustomEndMarker = "// ks:end-custom"
+)
+
+// Regenerate rewrites the generated files of an environment, main.jsonnet and
+// params.libsonnet, from their templates. If preserveCustom is set, the
+// custom regions of the existing files replace the regions of the same name
+// in the templates, and files without custom regions are left unchanged.
+func Regenerate(a app.App, name string, preserveCustom bool) error {
+	files := []struct {
+		name     string
+		template []byte
+	}{
+		{name: envFileName, template: DefaultOverrideData},
+		{name: paramsFileName, template: DefaultParamsData},
+	}
+
+	// Files are only written once all of them are regenerated, so an error
+	// leaves the environment unchanged.
+	var paths []string
+	var contents [][]byte
+
+	for _, f := range files {
+		path, err := Path(a, name, f.name)
+		if err != nil {
+			return err
+		}
+
+		data := f.template
+		if preserveCustom {
+			existing, err := afero.ReadFile(a.Fs(), path)
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+
+			regions, err := customRegions(existing)
+			if err != nil {
+				return errors.Wrapf(err, "reading custom regions of %s", path)
+			}
+
+			if len(regions) == 0 && len(existing) > 0 {
+				log.Warnf("%s has no custom regions; leaving it unchanged", path)
+				continue
+			}
+
+			if data, err = mergeCustomRegions(f.template, regions); err != nil {
+				return errors.Wrapf(err, "regenerating %s", path)
+			}
+		}
+
+		paths = append(paths, path)
+		contents = append(contents, data)
+	}
+
+	for i := range paths {
+		log.Infof("Regenerating %s", paths[i])
+		if err := afero.WriteFile(a.Fs(), paths[i], contents[i], app.DefaultFilePermissions); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// customRegions returns the contents of the custom regions of a file by
+// region name. Contents include the lines between the markers, but not the
+// markers.
+func customRegions(data []byte) (map[string]string, error) {
+	regions := make(map[string]string)
+
+	var name string
+	var body bytes.Buffer
+	inRegion := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if marker, ok := parseMarker(line, CustomBeginMarker); ok {
+			if inRegion {
+				return nil, errors.Errorf("custom region %q starts inside custom region %q", marker, name)
+			}
+			if marker == "" {
+				return nil, errors.Errorf("custom region has no name")
+			}
+			if _, ok := regions[marker]; ok {
+				return nil, errors.Errorf("custom region %q is defined more than once", marker)
+			}
+
+			name, inRegion = marker, true
+			body.Reset()
+			continue
+		}
+
+		if marker, ok := parseMarker(line, CustomEndMarker); ok {
+			if !inRegion {
+				return nil, errors.Errorf("custom region end %q has no start", marker)
+			}
+			if marker != "" && marker != name {
+				return nil, errors.Errorf("custom region %q is ended by %q", name, marker)
+			}
+
+			regions[name] = body.String()
+			inRegion = false
+			continue
+		}
+
+		if inRegion {
+			body.WriteString(line)
+			body.WriteByte('\n')
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if inRegion {
+		return nil, errors.Errorf("custom region %q is not ended", name)
+	}
+
+	return regions, nil
+}
+
+// mergeCustomRegions replaces the contents of the custom regions of template
+// with regions. Every region must exist in the template, so custom code is
+// never dropped.
+func mergeCustomRegions(template []byte, regions map[string]string) ([]byte, error) {
+	templateRegions, err := customRegions(template)
+	if err != nil {
+		return nil, err
+	}
+
+	for name := range regions {
+		if _, ok := templateRegions[name]; !ok {
+			return nil, errors.Errorf("custom region %q is not in the template", name)
+		}
+	}
+
+	var buf bytes.Buffer
+	var name string
+	inRegion := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(template))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if marker, ok := parseMarker(line, CustomBeginMarker); ok {
+			name, inRegion = marker, true
+			buf.WriteString(line + "\n")
+			if body, ok := regions[name]; ok {
+				buf.WriteString(body)
+			}
+			continue
+		}
+
+		if _, ok := parseMarker(line, CustomEndMarker); ok {
+			inRegion = false
+		}
+
+		if inRegion {
+			if _, ok := regions[name]; ok {
+				continue
+			}
+		}
+
+		buf.WriteString(line + "\n")
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// parseMarker returns the name following marker if line is a marker line.
+func parseMarker(line, marker string) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, marker) {
+		return "", false
+	}
+
+	rest := trimmed[len(marker):]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return "", false
+	}
+
+	return strings.TrimSpace(rest), true
+}
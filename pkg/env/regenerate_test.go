@@ -0,0 +1,165 @@
+// Copyright 2018 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package env
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegenerate(t *testing.T) {
+	const paramsData = "{ components: { guestbook: { replicas: 2 } } }\n"
+
+	cases := []struct {
+		name           string
+		main           string
+		preserveCustom bool
+		expectedMain   string
+		expectedParams string
+		isErr          bool
+	}{
+		{
+			name:           "without preserving custom regions",
+			main:           "regenerate/main.jsonnet",
+			expectedMain:   string(DefaultOverrideData),
+			expectedParams: string(DefaultParamsData),
+		},
+		{
+			name:           "preserve custom regions",
+			main:           "regenerate/main.jsonnet",
+			preserveCustom: true,
+			expectedMain:   test.ReadTestData(t, "regenerate/main-expected.jsonnet"),
+			// The params file has no custom regions, so it is left alone.
+			expectedParams: paramsData,
+		},
+		{
+			name:           "region which is not in the template",
+			main:           "regenerate/main-unknown-region.jsonnet",
+			preserveCustom: true,
+			isErr:          true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			test.WithApp(t, "/app", func(a *mocks.App, fs afero.Fs) {
+				a.On("Environment", "default").Return(&app.EnvironmentConfig{Path: "default"}, nil)
+
+				mainPath := filepath.Join("/app", "environments", "default", "main.jsonnet")
+				paramsPath := filepath.Join("/app", "environments", "default", "params.libsonnet")
+
+				test.StageFile(t, fs, tc.main, mainPath)
+				require.NoError(t, afero.WriteFile(fs, paramsPath, []byte(paramsData), app.DefaultFilePermissions))
+
+				err := Regenerate(a, "default", tc.preserveCustom)
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+
+				b, err := afero.ReadFile(fs, mainPath)
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedMain, string(b))
+
+				b, err = afero.ReadFile(fs, paramsPath)
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedParams, string(b))
+			})
+		})
+	}
+}
+
+func Test_customRegions(t *testing.T) {
+	cases := []struct {
+		name     string
+		data     string
+		expected map[string]string
+		isErr    bool
+	}{
+		{
+			name:     "no regions",
+			data:     "{}\n",
+			expected: map[string]string{},
+		},
+		{
+			name: "regions",
+			data: "a\n  // ks:begin-custom one\n  x: 1,\n  // ks:end-custom one\n// ks:begin-custom two\n// ks:end-custom\n",
+			expected: map[string]string{
+				"one": "  x: 1,\n",
+				"two": "",
+			},
+		},
+		{
+			name:  "unnamed region",
+			data:  "// ks:begin-custom\n// ks:end-custom\n",
+			isErr: true,
+		},
+		{
+			name:  "nested region",
+			data:  "// ks:begin-custom one\n// ks:begin-custom two\n",
+			isErr: true,
+		},
+		{
+			name:  "duplicate region",
+			data:  "// ks:begin-custom one\n// ks:end-custom\n// ks:begin-custom one\n// ks:end-custom\n",
+			isErr: true,
+		},
+		{
+			name:  "mismatched end",
+			data:  "// ks:begin-custom one\n// ks:end-custom two\n",
+			isErr: true,
+		},
+		{
+			name:  "end without start",
+			data:  "// ks:end-custom one\n",
+			isErr: true,
+		},
+		{
+			name:  "not ended",
+			data:  "// ks:begin-custom one\n",
+			isErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := customRegions([]byte(tc.data))
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func Test_defaultTemplates_have_custom_regions(t *testing.T) {
+	for _, data := range [][]byte{DefaultOverrideData, DefaultParamsData} {
+		regions, err := customRegions(data)
+		require.NoError(t, err)
+		assert.NotEmpty(t, regions)
+	}
+}
@@ -1,7 +1,5 @@
 local params = import '../../components/params.libsonnet';
 
 params + {
-  components+: {
-    component1+: {},
-  },
+  components+: {},
 }
\ No newline at end of file
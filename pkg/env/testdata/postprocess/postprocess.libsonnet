@@ -0,0 +1,9 @@
+local tolerations = import "tolerations.libsonnet";
+local params = std.extVar("__ksonnet/params");
+
+function(objects)
+  [
+    if o.kind == "Deployment" then tolerations.add(o, params.global.dedicated) else o
+    for o in objects
+    if o.kind != "PodDisruptionBudget"
+  ]
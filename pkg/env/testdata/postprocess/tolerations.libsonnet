@@ -0,0 +1,11 @@
+{
+  add(o, value):: o + {
+    spec+: {
+      template+: {
+        spec+: {
+          tolerations+: [{ key: "dedicated", operator: "Equal", value: value, effect: "NoSchedule" }],
+        },
+      },
+    },
+  },
+}
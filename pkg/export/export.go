@@ -0,0 +1,337 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package export writes the manifests of an app's environments to a
+// directory, e.g. for GitOps tools such as Argo CD and Flux to sync.
+package export
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// IndexFileName is the name of the file which lists the exported
+	// environments and their manifests in the GitOps layout.
+	IndexFileName = "index.yaml"
+	// KustomizationFileName is the name of the kustomization written to each
+	// environment's directory.
+	KustomizationFileName = "kustomization.yaml"
+
+	kustomizationAPIVersion = "kustomize.config.k8s.io/v1beta1"
+)
+
+var reUnsafe = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// Environment is an environment and the objects it renders to.
+type Environment struct {
+	Name    string
+	Config  *app.EnvironmentConfig
+	Objects []*unstructured.Unstructured
+}
+
+// Index lists the environments exported in the GitOps layout.
+type Index struct {
+	Environments []IndexEnvironment `json:"environments"`
+}
+
+// IndexEnvironment is an exported environment.
+type IndexEnvironment struct {
+	Name string `json:"name"`
+	// Path is the environment's directory, relative to the index.
+	Path         string                           `json:"path"`
+	Destinations []app.EnvironmentDestinationSpec `json:"destinations,omitempty"`
+	Files        []IndexFile                      `json:"files"`
+}
+
+// IndexFile is a manifest of an exported environment.
+type IndexFile struct {
+	// Path is the manifest's file, relative to the environment's directory.
+	Path       string `json:"path"`
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	Component  string `json:"component,omitempty"`
+}
+
+type kustomization struct {
+	APIVersion string   `json:"apiVersion"`
+	Kind       string   `json:"kind"`
+	Resources  []string `json:"resources"`
+}
+
+// Opt is an option for configuring Exporter.
+type Opt func(*Exporter)
+
+// GitOps configures Exporter to write each environment to its own directory,
+// with one file per object and an index of the environments. If kustomize is
+// true, each directory also contains a kustomization listing its files.
+func GitOps(kustomize bool) Opt {
+	return func(e *Exporter) {
+		e.gitops = true
+		e.kustomize = kustomize
+	}
+}
+
+// Exporter writes the manifests of environments to a directory. By default,
+// each environment is written to a single YAML stream named after it.
+type Exporter struct {
+	fs  afero.Fs
+	dir string
+
+	gitops    bool
+	kustomize bool
+}
+
+// New creates an instance of Exporter which writes to dir.
+func New(fs afero.Fs, dir string, opts ...Opt) *Exporter {
+	e := &Exporter{
+		fs:  fs,
+		dir: dir,
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+// Export writes the manifests of environments, and returns the paths of the
+// files it wrote, relative to the export directory.
+func (e *Exporter) Export(envs []Environment) ([]string, error) {
+	if e.gitops {
+		return e.exportGitOps(envs)
+	}
+
+	var written []string
+	for _, env := range envs {
+		var buf bytes.Buffer
+		for _, o := range env.Objects {
+			b, err := yaml.Marshal(o.Object)
+			if err != nil {
+				return nil, err
+			}
+			buf.WriteString("---\n")
+			buf.Write(b)
+		}
+
+		name := env.Name + ".yaml"
+		if err := e.write(name, buf.Bytes()); err != nil {
+			return nil, err
+		}
+		written = append(written, name)
+	}
+
+	return written, nil
+}
+
+func (e *Exporter) exportGitOps(envs []Environment) ([]string, error) {
+	previous, err := e.readIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	index := &Index{Environments: []IndexEnvironment{}}
+	rendered := make([]map[string][]byte, 0, len(envs))
+
+	for _, env := range envs {
+		ie, files, err := e.render(env)
+		if err != nil {
+			return nil, errors.Wrapf(err, "exporting environment %s", env.Name)
+		}
+
+		index.Environments = append(index.Environments, *ie)
+		rendered = append(rendered, files)
+	}
+
+	// directories are replaced, rather than updated, so objects which are no
+	// longer rendered are pruned by the tools which sync them. They are all
+	// removed before any are written, since environments can be nested.
+	if err = e.removeStale(previous, index); err != nil {
+		return nil, err
+	}
+	for _, ie := range index.Environments {
+		if err = e.fs.RemoveAll(e.path(ie.Path)); err != nil {
+			return nil, err
+		}
+	}
+
+	var written []string
+	for i, ie := range index.Environments {
+		files := rendered[i]
+		for _, name := range sortedKeys(files) {
+			p := path.Join(ie.Path, name)
+			if err = e.write(p, files[name]); err != nil {
+				return nil, err
+			}
+			written = append(written, p)
+		}
+	}
+
+	b, err := yaml.Marshal(index)
+	if err != nil {
+		return nil, err
+	}
+	if err = e.write(IndexFileName, b); err != nil {
+		return nil, err
+	}
+	written = append(written, IndexFileName)
+
+	return written, nil
+}
+
+// render converts the objects of an environment to files, keyed by their
+// path in the environment's directory.
+func (e *Exporter) render(env Environment) (*IndexEnvironment, map[string][]byte, error) {
+	ie := &IndexEnvironment{
+		Name:  env.Name,
+		Path:  env.Name,
+		Files: []IndexFile{},
+	}
+	if env.Config != nil {
+		ie.Destinations = env.Config.ClusterDestinations()
+	}
+
+	files := make(map[string][]byte)
+	for _, o := range env.Objects {
+		name := fileName(o)
+		if _, ok := files[name]; ok {
+			return nil, nil, errors.Errorf("%s %s is rendered more than once", o.GetKind(), o.GetName())
+		}
+
+		b, err := yaml.Marshal(o.Object)
+		if err != nil {
+			return nil, nil, err
+		}
+		files[name] = b
+
+		ie.Files = append(ie.Files, IndexFile{
+			Path:       name,
+			APIVersion: o.GetAPIVersion(),
+			Kind:       o.GetKind(),
+			Namespace:  o.GetNamespace(),
+			Name:       o.GetName(),
+			Component:  o.GetLabels()[metadata.LabelComponent],
+		})
+	}
+
+	sort.Slice(ie.Files, func(i, j int) bool {
+		return ie.Files[i].Path < ie.Files[j].Path
+	})
+
+	if e.kustomize {
+		k := kustomization{
+			APIVersion: kustomizationAPIVersion,
+			Kind:       "Kustomization",
+			Resources:  sortedKeys(files),
+		}
+
+		b, err := yaml.Marshal(k)
+		if err != nil {
+			return nil, nil, err
+		}
+		files[KustomizationFileName] = b
+	}
+
+	return ie, files, nil
+}
+
+// readIndex reads the index of a previous export, if there is one.
+func (e *Exporter) readIndex() (*Index, error) {
+	b, err := afero.ReadFile(e.fs, e.path(IndexFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Index{}, nil
+		}
+		return nil, err
+	}
+
+	var index Index
+	if err = yaml.Unmarshal(b, &index); err != nil {
+		return nil, errors.Wrapf(err, "reading %s", IndexFileName)
+	}
+
+	return &index, nil
+}
+
+// removeStale removes the directories of previously exported environments
+// which are no longer in the app.
+func (e *Exporter) removeStale(previous, current *Index) error {
+	exported := make(map[string]bool)
+	for _, ie := range current.Environments {
+		exported[ie.Path] = true
+	}
+
+	for _, ie := range previous.Environments {
+		p := path.Clean(ie.Path)
+		if exported[p] || p == "." || path.IsAbs(p) || strings.HasPrefix(p, "..") {
+			continue
+		}
+
+		if err := e.fs.RemoveAll(e.path(p)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *Exporter) write(name string, b []byte) error {
+	p := e.path(name)
+	if err := e.fs.MkdirAll(filepath.Dir(p), app.DefaultFolderPermissions); err != nil {
+		return err
+	}
+
+	return afero.WriteFile(e.fs, p, b, app.DefaultFilePermissions)
+}
+
+func (e *Exporter) path(name string) string {
+	return filepath.Join(e.dir, filepath.FromSlash(name))
+}
+
+// fileName returns the stable file name of an object, e.g.
+// `deployment-default-guestbook-ui.yaml`.
+func fileName(o *unstructured.Unstructured) string {
+	parts := []string{strings.ToLower(o.GetKind())}
+	if ns := o.GetNamespace(); ns != "" {
+		parts = append(parts, ns)
+	}
+	parts = append(parts, o.GetName())
+
+	return reUnsafe.ReplaceAllString(strings.Join(parts, "-"), "_") + ".yaml"
+}
+
+func sortedKeys(m map[string][]byte) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
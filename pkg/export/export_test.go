@@ -0,0 +1,167 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package export
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func object(component, kind, namespace, name string) *unstructured.Unstructured {
+	o := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       kind,
+			"metadata": map[string]interface{}{
+				"name": name,
+			},
+		},
+	}
+	if namespace != "" {
+		o.SetNamespace(namespace)
+	}
+	if component != "" {
+		o.SetLabels(map[string]string{metadata.LabelComponent: component})
+	}
+	return o
+}
+
+func environments() []Environment {
+	return []Environment{
+		{
+			Name: "default",
+			Config: &app.EnvironmentConfig{
+				Destination: &app.EnvironmentDestinationSpec{Server: "http://example.com", Namespace: "default"},
+			},
+			Objects: []*unstructured.Unstructured{
+				object("guestbook-ui", "Service", "default", "guestbook-ui"),
+				object("guestbook-ui", "Deployment", "default", "guestbook-ui"),
+			},
+		},
+		{
+			Name: "us-west/prod",
+			Config: &app.EnvironmentConfig{
+				Destination: &app.EnvironmentDestinationSpec{Server: "http://prod.example.com", Namespace: "web"},
+			},
+			Objects: []*unstructured.Unstructured{
+				object("", "Namespace", "", "web"),
+			},
+		},
+	}
+}
+
+func readFile(t *testing.T, fs afero.Fs, path string) string {
+	b, err := afero.ReadFile(fs, path)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestExporter_Export(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	written, err := New(fs, "/export").Export(environments())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"default.yaml", "us-west/prod.yaml"}, written)
+
+	expected := "---\napiVersion: v1\nkind: Namespace\nmetadata:\n  name: web\n"
+	assert.Equal(t, expected, readFile(t, fs, "/export/us-west/prod.yaml"))
+}
+
+func TestExporter_Export_gitops(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/export/default/service-default-old.yaml", []byte("old"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/export/staging/namespace-web.yaml", []byte("old"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/export/README.md", []byte("readme"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/export/index.yaml", []byte(`environments:
+- name: default
+  path: default
+  files: []
+- name: staging
+  path: staging
+  files: []
+- name: escape
+  path: ../outside
+  files: []
+`), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/outside/keep.yaml", []byte("keep"), 0644))
+
+	written, err := New(fs, "/export", GitOps(true)).Export(environments())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"default/deployment-default-guestbook-ui.yaml",
+		"default/kustomization.yaml",
+		"default/service-default-guestbook-ui.yaml",
+		"us-west/prod/kustomization.yaml",
+		"us-west/prod/namespace-web.yaml",
+		"index.yaml",
+	}, written)
+
+	for path, exists := range map[string]bool{
+		"/export/default/service-default-old.yaml": false,
+		"/export/staging":                          false,
+		"/export/README.md":                        true,
+		"/outside/keep.yaml":                       true,
+	} {
+		ok, err := afero.Exists(fs, path)
+		require.NoError(t, err)
+		assert.Equal(t, exists, ok, path)
+	}
+
+	assert.Equal(t, `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- deployment-default-guestbook-ui.yaml
+- service-default-guestbook-ui.yaml
+`, readFile(t, fs, "/export/default/kustomization.yaml"))
+
+	expected, err := ioutil.ReadFile("testdata/index.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), readFile(t, fs, "/export/index.yaml"))
+}
+
+func TestExporter_Export_gitops_plain(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	written, err := New(fs, "/export", GitOps(false)).Export(environments())
+	require.NoError(t, err)
+
+	assert.NotContains(t, written, "default/kustomization.yaml")
+	assert.Contains(t, written, "default/service-default-guestbook-ui.yaml")
+}
+
+func TestExporter_Export_duplicate(t *testing.T) {
+	envs := []Environment{
+		{
+			Name: "default",
+			Objects: []*unstructured.Unstructured{
+				object("a", "Service", "", "web"),
+				object("b", "Service", "", "web"),
+			},
+		},
+	}
+
+	_, err := New(afero.NewMemMapFs(), "/export", GitOps(false)).Export(envs)
+	require.Error(t, err)
+}
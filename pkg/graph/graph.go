@@ -0,0 +1,194 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package graph holds a directed graph of the parts of an app, such as
+// environments, modules, components, and packages, and writes it in DOT or
+// Mermaid syntax.
+package graph
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// NodeKind is the kind of part a node is.
+type NodeKind string
+
+const (
+	// KindEnvironment is an environment.
+	KindEnvironment NodeKind = "environment"
+	// KindModule is a component module.
+	KindModule NodeKind = "module"
+	// KindComponent is a component.
+	KindComponent NodeKind = "component"
+	// KindPrototype is the prototype a component was generated from.
+	KindPrototype NodeKind = "prototype"
+	// KindPackage is an installed package.
+	KindPackage NodeKind = "package"
+	// KindObject is a rendered Kubernetes object.
+	KindObject NodeKind = "object"
+)
+
+const (
+	// FormatDOT is the Graphviz DOT language.
+	FormatDOT = "dot"
+	// FormatMermaid is Mermaid flowchart syntax.
+	FormatMermaid = "mermaid"
+)
+
+// dotShapes are the Graphviz shapes of each kind of node.
+var dotShapes = map[NodeKind]string{
+	KindEnvironment: "box3d",
+	KindModule:      "folder",
+	KindComponent:   "component",
+	KindPrototype:   "note",
+	KindPackage:     "tab",
+	KindObject:      "ellipse",
+}
+
+// Node is a part of an app.
+type Node struct {
+	ID    string
+	Kind  NodeKind
+	Label string
+}
+
+// Edge is a relation between two parts of an app.
+type Edge struct {
+	From  string
+	To    string
+	Label string
+}
+
+// Graph is a directed graph of the parts of an app. Nodes are identified by
+// their kind and name, so adding a node or edge twice has no effect.
+type Graph struct {
+	nodes map[string]Node
+	edges map[Edge]bool
+}
+
+// New creates an empty Graph.
+func New() *Graph {
+	return &Graph{
+		nodes: make(map[string]Node),
+		edges: make(map[Edge]bool),
+	}
+}
+
+// AddNode adds a node and returns its ID.
+func (g *Graph) AddNode(kind NodeKind, name string) string {
+	id := string(kind) + ":" + name
+	if _, ok := g.nodes[id]; !ok {
+		g.nodes[id] = Node{ID: id, Kind: kind, Label: name}
+	}
+	return id
+}
+
+// AddEdge adds an edge between two node IDs.
+func (g *Graph) AddEdge(from, to, label string) {
+	g.edges[Edge{From: from, To: to, Label: label}] = true
+}
+
+// Nodes returns the nodes sorted by ID.
+func (g *Graph) Nodes() []Node {
+	nodes := make([]Node, 0, len(g.nodes))
+	for _, n := range g.nodes {
+		nodes = append(nodes, n)
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].ID < nodes[j].ID
+	})
+
+	return nodes
+}
+
+// Edges returns the edges sorted by their nodes and label.
+func (g *Graph) Edges() []Edge {
+	edges := make([]Edge, 0, len(g.edges))
+	for e := range g.edges {
+		edges = append(edges, e)
+	}
+
+	sort.Slice(edges, func(i, j int) bool {
+		a, b := edges[i], edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Label < b.Label
+	})
+
+	return edges
+}
+
+// Write writes the graph in a format.
+func (g *Graph) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatDOT:
+		return g.WriteDOT(w)
+	case FormatMermaid:
+		return g.WriteMermaid(w)
+	default:
+		return errors.Errorf("unknown graph format %q; use %s or %s", format, FormatDOT, FormatMermaid)
+	}
+}
+
+// WriteDOT writes the graph in the Graphviz DOT language.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	var b strings.Builder
+
+	b.WriteString("digraph app {\n")
+	b.WriteString("  rankdir=LR;\n")
+	for _, n := range g.Nodes() {
+		fmt.Fprintf(&b, "  %q [label=%q, shape=%s];\n", n.ID, n.Label, dotShapes[n.Kind])
+	}
+	for _, e := range g.Edges() {
+		fmt.Fprintf(&b, "  %q -> %q [label=%q];\n", e.From, e.To, e.Label)
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart. Mermaid IDs can not
+// contain most punctuation, so nodes are numbered in ID order.
+func (g *Graph) WriteMermaid(w io.Writer) error {
+	var b strings.Builder
+
+	ids := make(map[string]string)
+	b.WriteString("graph LR\n")
+	for i, n := range g.Nodes() {
+		ids[n.ID] = fmt.Sprintf("n%d", i)
+		fmt.Fprintf(&b, "  %s[\"%s: %s\"]\n", ids[n.ID], n.Kind, mermaidEscape(n.Label))
+	}
+	for _, e := range g.Edges() {
+		fmt.Fprintf(&b, "  %s -->|%s| %s\n", ids[e.From], mermaidEscape(e.Label), ids[e.To])
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "|", "#124;").Replace(s)
+}
@@ -0,0 +1,74 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package graph
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testGraph() *Graph {
+	g := New()
+	env := g.AddNode(KindEnvironment, "prod")
+	module := g.AddNode(KindModule, "/")
+	component := g.AddNode(KindComponent, "guestbook")
+	g.AddEdge(env, module, "targets")
+	g.AddEdge(module, component, "contains")
+	g.AddEdge(module, component, "contains")
+	g.AddEdge(component, g.AddNode(KindPrototype, `say "hi"|bye`), "generated from")
+	return g
+}
+
+func TestGraph_WriteDOT(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, testGraph().Write(&buf, FormatDOT))
+
+	expected := `digraph app {
+  rankdir=LR;
+  "component:guestbook" [label="guestbook", shape=component];
+  "environment:prod" [label="prod", shape=box3d];
+  "module:/" [label="/", shape=folder];
+  "prototype:say \"hi\"|bye" [label="say \"hi\"|bye", shape=note];
+  "component:guestbook" -> "prototype:say \"hi\"|bye" [label="generated from"];
+  "environment:prod" -> "module:/" [label="targets"];
+  "module:/" -> "component:guestbook" [label="contains"];
+}
+`
+	require.Equal(t, expected, buf.String())
+}
+
+func TestGraph_WriteMermaid(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, testGraph().Write(&buf, FormatMermaid))
+
+	expected := `graph LR
+  n0["component: guestbook"]
+  n1["environment: prod"]
+  n2["module: /"]
+  n3["prototype: say #quot;hi#quot;#124;bye"]
+  n0 -->|generated from| n3
+  n1 -->|targets| n2
+  n2 -->|contains| n0
+`
+	require.Equal(t, expected, buf.String())
+}
+
+func TestGraph_Write_unknown_format(t *testing.T) {
+	var buf bytes.Buffer
+	require.Error(t, testGraph().Write(&buf, "svg"))
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	goyaml "github.com/ghodss/yaml"
@@ -64,7 +65,7 @@ func (r *Renderer) namespace() (string, error) {
 		return "", errors.Wrapf(err, "retrieving environment %q", r.envName)
 	}
 
-	return env.Destination.Namespace, nil
+	return env.PrimaryDestination().Namespace, nil
 }
 
 // JsonnetNativeFunc is a jsonnet native function that renders helm charts.
@@ -135,23 +136,100 @@ func (r *Renderer) Render(repoName, chartName, chartVersion, componentName strin
 
 	chartPath := filepath.Join(r.app.Root(), "vendor", repoName, chartName, "helm", chartVersion, chartName)
 
+	return r.renderChart(chartPath, componentName, "", values)
+}
+
+// HelmTemplateNativeFunc is a jsonnet native function that renders a Helm
+// chart from a directory in the app, such as a chart vendored with the app's
+// components.
+func (r *Renderer) HelmTemplateNativeFunc() *jsonnet.NativeFunction {
+	fn := func(input []interface{}) (interface{}, error) {
+		chartPath, ok := input[0].(string)
+		if !ok {
+			return nil, errors.New("invalid Helm chart path")
+		}
+
+		values, ok := input[1].(map[string]interface{})
+		if !ok {
+			return nil, errors.New("invalid Helm chart values")
+		}
+
+		options, ok := input[2].(map[string]interface{})
+		if !ok {
+			return nil, errors.New("invalid Helm template options")
+		}
+
+		releaseName, ok := options["name"].(string)
+		if !ok || releaseName == "" {
+			return nil, errors.New("Helm template options require a release name")
+		}
+
+		namespace, _ := options["namespace"].(string)
+
+		return r.Template(chartPath, releaseName, namespace, values)
+	}
+
+	nf := &jsonnet.NativeFunction{
+		Name:   "helmTemplate",
+		Params: ast.Identifiers{"chart", "values", "options"},
+		Func:   fn,
+	}
+
+	return nf
+}
+
+// Template renders the Helm chart in a directory. Relative chart paths are
+// relative to the app root, and charts outside of the app root can not be
+// rendered. If namespace is blank, the environment's namespace is used.
+func (r *Renderer) Template(chartPath, releaseName, namespace string, values map[string]interface{}) ([]interface{}, error) {
+	logrus.WithFields(logrus.Fields{
+		"chartPath":   chartPath,
+		"releaseName": releaseName,
+		"namespace":   namespace,
+		"values":      values,
+	}).Debug("rendering helm template")
+
+	if r.app == nil {
+		return nil, errors.New("app object is nil")
+	}
+
+	root := filepath.Clean(r.app.Root())
+	if !filepath.IsAbs(chartPath) {
+		chartPath = filepath.Join(root, chartPath)
+	}
+	chartPath = filepath.Clean(chartPath)
+
+	if chartPath != root && !strings.HasPrefix(chartPath, root+string(filepath.Separator)) {
+		return nil, errors.Errorf("Helm chart %s is not in the app", chartPath)
+	}
+
+	return r.renderChart(chartPath, releaseName, namespace, values)
+}
+
+// renderChart renders a Helm chart, and returns the objects in its
+// templates ordered by template name. Empty documents are skipped.
+func (r *Renderer) renderChart(chartPath, releaseName, namespace string, values map[string]interface{}) ([]interface{}, error) {
 	b, err := goyaml.Marshal(values)
 	if err != nil {
 		return nil, err
 	}
 
-	rendered, err := r.renderWithHelm(componentName, string(b), chartPath)
+	rendered, err := r.renderWithHelm(releaseName, namespace, string(b), chartPath)
 	if err != nil {
 		return nil, errors.Wrap(err, "rendering Helm chart")
 	}
 
-	var out []interface{}
-	for name, s := range rendered {
-		if !ksstrings.InSlice(filepath.Ext(name), []string{".yaml", ".yml"}) {
-			continue
+	var names []string
+	for name := range rendered {
+		if ksstrings.InSlice(filepath.Ext(name), []string{".yaml", ".yml"}) {
+			names = append(names, name)
 		}
+	}
+	sort.Strings(names)
 
-		r := strings.NewReader(s)
+	var out []interface{}
+	for _, name := range names {
+		r := strings.NewReader(rendered[name])
 		readers, err := utilyaml.Decode(r)
 		if err != nil {
 			return nil, err
@@ -168,6 +246,11 @@ func (r *Renderer) Render(repoName, chartName, chartVersion, componentName strin
 				return nil, errors.Wrapf(err, "unmarshalling %s", name)
 			}
 
+			// Templates which are disabled by values render as empty documents.
+			if len(m) == 0 {
+				continue
+			}
+
 			out = append(out, m)
 		}
 	}
@@ -175,7 +258,7 @@ func (r *Renderer) Render(repoName, chartName, chartVersion, componentName strin
 	return out, nil
 }
 
-func (r *Renderer) renderWithHelm(componentName, raw, chartPath string) (map[string]string, error) {
+func (r *Renderer) renderWithHelm(releaseName, namespace, raw, chartPath string) (map[string]string, error) {
 	config := &chart.Config{Raw: raw, Values: map[string]*chart.Value{}}
 
 	c, err := chartutil.LoadDir(chartPath)
@@ -201,7 +284,7 @@ func (r *Renderer) renderWithHelm(componentName, raw, chartPath string) (map[str
 		return nil, err
 	}
 
-	options, caps, err := r.extractChartComponents(componentName)
+	options, caps, err := r.extractChartComponents(releaseName, namespace)
 	if err != nil {
 		return nil, err
 	}
@@ -220,15 +303,17 @@ func (r *Renderer) renderWithHelm(componentName, raw, chartPath string) (map[str
 	return rendered, nil
 }
 
-func (r *Renderer) extractChartComponents(componentName string) (*chartutil.ReleaseOptions, *chartutil.Capabilities, error) {
-	clusterNS, err := r.namespace()
-	if err != nil {
-		return nil, nil, err
+func (r *Renderer) extractChartComponents(releaseName, namespace string) (*chartutil.ReleaseOptions, *chartutil.Capabilities, error) {
+	if namespace == "" {
+		var err error
+		if namespace, err = r.namespace(); err != nil {
+			return nil, nil, err
+		}
 	}
 
 	options := &chartutil.ReleaseOptions{
-		Name:      componentName,
-		Namespace: clusterNS,
+		Name:      releaseName,
+		Namespace: namespace,
 	}
 
 	kubeVersion, err := r.k8sVersion()
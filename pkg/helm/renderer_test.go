@@ -139,3 +139,75 @@ func TestRenderer_JsonnetNativeFunc(t *testing.T) {
 		})
 	}
 }
+
+func TestRenderer_HelmTemplateNativeFunc(t *testing.T) {
+	cases := []struct {
+		name    string
+		snippet string
+		isErr   bool
+	}{
+		{
+			name: "relative chart path",
+			snippet: `
+local objects = std.native("helmTemplate")("charts/redis", {}, { name: "cache" });
+[o.metadata.name for o in objects]`,
+		},
+		{
+			name: "with namespace",
+			snippet: `
+local objects = std.native("helmTemplate")("charts/redis", {}, { name: "cache", namespace: "other" });
+[o.metadata.name for o in objects]`,
+		},
+		{
+			name:    "chart outside of app",
+			snippet: `std.native("helmTemplate")("../redis", {}, { name: "cache" })`,
+			isErr:   true,
+		},
+		{
+			name:    "missing release name",
+			snippet: `std.native("helmTemplate")("charts/redis", {}, {})`,
+			isErr:   true,
+		},
+		{
+			name:    "invalid values",
+			snippet: `std.native("helmTemplate")("charts/redis", "values", { name: "cache" })`,
+			isErr:   true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpDir, err := ioutil.TempDir("", "TestRenderer_HelmTemplateNativeFunc")
+			require.NoError(t, err)
+
+			defer os.RemoveAll(tmpDir)
+
+			fs := afero.NewOsFs()
+
+			test.WithAppFs(t, tmpDir, fs, func(a *amocks.App, fs afero.Fs) {
+				test.StageDir(t, fs, "redis/helm/3.4.3/redis", filepath.Join(a.Root(), "charts", "redis"))
+
+				envConfig := &app.EnvironmentConfig{
+					KubernetesVersion: "v1.10.3",
+					Destination: &app.EnvironmentDestinationSpec{
+						Namespace: "default",
+					},
+				}
+				a.On("Environment", "default").Return(envConfig, nil)
+
+				r := NewRenderer(a, "default")
+
+				vm := jsonnet.NewVM()
+				vm.AddFunctions(r.HelmTemplateNativeFunc())
+
+				out, err := vm.EvaluateSnippet("snippet", tc.snippet)
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+				assert.Contains(t, out, `"cache-redis"`)
+			})
+		})
+	}
+}
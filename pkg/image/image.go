@@ -0,0 +1,301 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package image finds the container images used by an app's components, and
+// the parameters they are set by.
+package image
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// ParamImage is a parameter which holds a whole image reference.
+	ParamImage = "image"
+	// ParamTag is a parameter which holds the tag of an image reference.
+	ParamTag = "tag"
+)
+
+// containerFields are the fields which hold lists of containers.
+var containerFields = []string{"containers", "initContainers"}
+
+// Reference is a container image used by a component.
+type Reference struct {
+	Component string `json:"component"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Container string `json:"container"`
+	Image     string `json:"image"`
+	// Param is the component parameter the image is set by. It is empty if
+	// the image is not set by a parameter.
+	Param string `json:"param,omitempty"`
+	// ParamType is ParamImage if the parameter holds the whole image, or
+	// ParamTag if it only holds the tag.
+	ParamType string `json:"paramType,omitempty"`
+}
+
+// Object returns the kind and name of the object the image is used in.
+func (r *Reference) Object() string {
+	return r.Kind + "/" + r.Name
+}
+
+// Find returns the images used by the containers of objects. params are the
+// string parameters of each component, which are matched to the images.
+// References are sorted by component, object, and container.
+func Find(objects []*unstructured.Unstructured, params map[string]map[string]string) []Reference {
+	var refs []Reference
+
+	for _, obj := range objects {
+		component := obj.GetLabels()[metadata.LabelComponent]
+
+		for _, c := range containers(obj.Object) {
+			ref := Reference{
+				Component: component,
+				Kind:      obj.GetKind(),
+				Name:      obj.GetName(),
+				Container: c.name,
+				Image:     c.image,
+			}
+			ref.Param, ref.ParamType = matchParam(c.image, params[component])
+
+			refs = append(refs, ref)
+		}
+	}
+
+	sort.SliceStable(refs, func(i, j int) bool {
+		if refs[i].Component != refs[j].Component {
+			return refs[i].Component < refs[j].Component
+		}
+		if refs[i].Object() != refs[j].Object() {
+			return refs[i].Object() < refs[j].Object()
+		}
+		return refs[i].Container < refs[j].Container
+	})
+
+	return refs
+}
+
+type container struct {
+	name  string
+	image string
+}
+
+// containers returns the containers anywhere in an object, so containers of
+// pods, pod templates, and job templates are all found.
+func containers(m map[string]interface{}) []container {
+	var found []container
+
+	for k, v := range m {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			found = append(found, containers(t)...)
+		case []interface{}:
+			isContainers := false
+			for _, field := range containerFields {
+				if k == field {
+					isContainers = true
+				}
+			}
+
+			for _, item := range t {
+				im, ok := item.(map[string]interface{})
+				if !ok {
+					continue
+				}
+
+				if isContainers {
+					if image, ok := im["image"].(string); ok {
+						name, _ := im["name"].(string)
+						found = append(found, container{name: name, image: image})
+						continue
+					}
+				}
+
+				found = append(found, containers(im)...)
+			}
+		}
+	}
+
+	return found
+}
+
+// matchParam returns the parameter an image is set by. Parameters which hold
+// the whole image are preferred over ones which hold the tag.
+func matchParam(image string, params map[string]string) (string, string) {
+	var keys []string
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if params[k] == image {
+			return k, ParamImage
+		}
+	}
+
+	ref := ParseReference(image)
+	version := ref.Version()
+	if version == "" {
+		return "", ""
+	}
+
+	for _, k := range keys {
+		if params[k] == version {
+			return k, ParamTag
+		}
+	}
+
+	return "", ""
+}
+
+// ParsedReference is an image reference split into its parts.
+type ParsedReference struct {
+	// Repository is the image without its tag or digest, including the
+	// registry.
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseReference splits an image reference into its repository, tag, and
+// digest.
+func ParseReference(image string) ParsedReference {
+	var ref ParsedReference
+
+	if i := strings.Index(image, "@"); i >= 0 {
+		ref.Digest = image[i+1:]
+		image = image[:i]
+	}
+
+	// A colon after the last slash separates the tag. Colons before it are
+	// registry ports.
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		ref.Tag = image[i+1:]
+		image = image[:i]
+	}
+
+	ref.Repository = image
+	return ref
+}
+
+// Version returns the digest of a reference, or its tag if it does not have
+// a digest.
+func (r ParsedReference) Version() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+
+	return r.Tag
+}
+
+// Update is a change to a parameter which sets an image.
+type Update struct {
+	Component string
+	Param     string
+	Value     string
+}
+
+// Updates returns the parameter changes which set the images of a component
+// with the same repository as image to image. It is an error if no image of
+// the component has the repository, or an image is not set by a parameter.
+func Updates(refs []Reference, component, image string) ([]Update, error) {
+	next := ParseReference(image)
+	if next.Version() == "" {
+		return nil, errors.Errorf("image %q does not have a tag or digest", image)
+	}
+
+	var updates []Update
+	seen := make(map[string]bool)
+
+	for _, ref := range refs {
+		if ref.Component != component || ParseReference(ref.Image).Repository != next.Repository {
+			continue
+		}
+
+		if ref.Param == "" {
+			return nil, errors.Errorf("image %s of container %s in %s is not set by a parameter of component %s",
+				ref.Image, ref.Container, ref.Object(), component)
+		}
+
+		if seen[ref.Param] {
+			continue
+		}
+		seen[ref.Param] = true
+
+		value := image
+		if ref.ParamType == ParamTag {
+			if next.Digest != "" {
+				return nil, errors.Errorf("parameter %s of component %s holds the tag of image %s, so it can not be set to a digest",
+					ref.Param, component, ref.Image)
+			}
+			value = next.Tag
+		}
+
+		updates = append(updates, Update{Component: component, Param: ref.Param, Value: value})
+	}
+
+	if len(updates) == 0 {
+		return nil, errors.Errorf("component %s does not use an image from %s", component, next.Repository)
+	}
+
+	return updates, nil
+}
+
+// CanPin returns an error describing why an image can not be pinned to a
+// digest, or nil if it can. Only images held whole by a parameter can be
+// pinned, since a digest can not be set as a tag.
+func CanPin(ref Reference) error {
+	switch {
+	case ref.Param == "":
+		return errors.Errorf("image %s of container %s in %s is not set by a parameter of component %s",
+			ref.Image, ref.Container, ref.Object(), ref.Component)
+	case ref.ParamType == ParamTag:
+		return errors.Errorf("parameter %s of component %s holds the tag of image %s, so it can not be set to a digest",
+			ref.Param, ref.Component, ref.Image)
+	}
+
+	return nil
+}
+
+// Pins returns the parameter changes which pin images to the digests of
+// their tags. resolve returns an image with its tag resolved to a digest.
+// Images which are already pinned, or can not be pinned, are skipped.
+func Pins(refs []Reference, resolve func(image string) (string, error)) ([]Update, error) {
+	var updates []Update
+	seen := make(map[string]bool)
+
+	for _, ref := range refs {
+		key := ref.Component + "/" + ref.Param
+		if CanPin(ref) != nil || ParseReference(ref.Image).Digest != "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		pinned, err := resolve(ref.Image)
+		if err != nil {
+			return nil, errors.Wrapf(err, "resolving digest of %s", ref.Image)
+		}
+
+		updates = append(updates, Update{Component: ref.Component, Param: ref.Param, Value: pinned})
+	}
+
+	return updates, nil
+}
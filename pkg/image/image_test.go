@@ -0,0 +1,225 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package image
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newObject(kind, name, component string, spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       kind,
+			"metadata": map[string]interface{}{
+				"name":   name,
+				"labels": map[string]interface{}{"ksonnet.io/component": component},
+			},
+			"spec": spec,
+		},
+	}
+}
+
+func podSpec(containers ...interface{}) map[string]interface{} {
+	return map[string]interface{}{"containers": containers}
+}
+
+func testObjects() []*unstructured.Unstructured {
+	deployment := newObject("Deployment", "guestbook", "guestbook", map[string]interface{}{
+		"template": map[string]interface{}{
+			"spec": map[string]interface{}{
+				"initContainers": []interface{}{
+					map[string]interface{}{"name": "migrate", "image": "gcr.io/heptio-images/guestbook:0.1"},
+				},
+				"containers": []interface{}{
+					map[string]interface{}{"name": "web", "image": "gcr.io/heptio-images/guestbook:0.1"},
+					map[string]interface{}{"name": "proxy", "image": "envoyproxy/envoy:v1.8.0"},
+				},
+			},
+		},
+	})
+
+	cronJob := newObject("CronJob", "backup", "db.redis", map[string]interface{}{
+		"jobTemplate": map[string]interface{}{
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": podSpec(map[string]interface{}{"name": "backup", "image": "localhost:5000/redis@sha256:abc"}),
+				},
+			},
+		},
+	})
+
+	service := newObject("Service", "guestbook", "guestbook", map[string]interface{}{
+		"ports": []interface{}{map[string]interface{}{"port": int64(80)}},
+	})
+
+	return []*unstructured.Unstructured{service, deployment, cronJob}
+}
+
+func TestFind(t *testing.T) {
+	params := map[string]map[string]string{
+		"guestbook": {
+			"image":         "gcr.io/heptio-images/guestbook:0.1",
+			"name":          "guestbook",
+			"envoyTag":      "v1.8.0",
+			"containerPort": "80",
+		},
+	}
+
+	refs := Find(testObjects(), params)
+
+	expected := []Reference{
+		{Component: "db.redis", Kind: "CronJob", Name: "backup", Container: "backup", Image: "localhost:5000/redis@sha256:abc"},
+		{Component: "guestbook", Kind: "Deployment", Name: "guestbook", Container: "migrate", Image: "gcr.io/heptio-images/guestbook:0.1", Param: "image", ParamType: ParamImage},
+		{Component: "guestbook", Kind: "Deployment", Name: "guestbook", Container: "proxy", Image: "envoyproxy/envoy:v1.8.0", Param: "envoyTag", ParamType: ParamTag},
+		{Component: "guestbook", Kind: "Deployment", Name: "guestbook", Container: "web", Image: "gcr.io/heptio-images/guestbook:0.1", Param: "image", ParamType: ParamImage},
+	}
+
+	assert.Equal(t, expected, refs)
+}
+
+func TestParseReference(t *testing.T) {
+	cases := []struct {
+		image    string
+		expected ParsedReference
+	}{
+		{image: "nginx", expected: ParsedReference{Repository: "nginx"}},
+		{image: "nginx:1.25", expected: ParsedReference{Repository: "nginx", Tag: "1.25"}},
+		{image: "localhost:5000/nginx", expected: ParsedReference{Repository: "localhost:5000/nginx"}},
+		{image: "localhost:5000/team/nginx:1.25", expected: ParsedReference{Repository: "localhost:5000/team/nginx", Tag: "1.25"}},
+		{image: "nginx@sha256:abc", expected: ParsedReference{Repository: "nginx", Digest: "sha256:abc"}},
+		{image: "nginx:1.25@sha256:abc", expected: ParsedReference{Repository: "nginx", Tag: "1.25", Digest: "sha256:abc"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.image, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ParseReference(tc.image))
+		})
+	}
+}
+
+func TestUpdates(t *testing.T) {
+	params := map[string]map[string]string{
+		"guestbook": {
+			"image":    "gcr.io/heptio-images/guestbook:0.1",
+			"envoyTag": "v1.8.0",
+		},
+	}
+	refs := Find(testObjects(), params)
+
+	cases := []struct {
+		name      string
+		component string
+		image     string
+		expected  []Update
+		isErr     bool
+	}{
+		{
+			name:      "whole image",
+			component: "guestbook",
+			image:     "gcr.io/heptio-images/guestbook:0.2",
+			expected:  []Update{{Component: "guestbook", Param: "image", Value: "gcr.io/heptio-images/guestbook:0.2"}},
+		},
+		{
+			name:      "digest",
+			component: "guestbook",
+			image:     "gcr.io/heptio-images/guestbook@sha256:def",
+			expected:  []Update{{Component: "guestbook", Param: "image", Value: "gcr.io/heptio-images/guestbook@sha256:def"}},
+		},
+		{
+			name:      "tag",
+			component: "guestbook",
+			image:     "envoyproxy/envoy:v1.9.0",
+			expected:  []Update{{Component: "guestbook", Param: "envoyTag", Value: "v1.9.0"}},
+		},
+		{
+			name:      "digest of a tag parameter",
+			component: "guestbook",
+			image:     "envoyproxy/envoy@sha256:def",
+			isErr:     true,
+		},
+		{
+			name:      "repository the component does not use",
+			component: "guestbook",
+			image:     "nginx:1.25",
+			isErr:     true,
+		},
+		{
+			name:      "image without a parameter",
+			component: "db.redis",
+			image:     "localhost:5000/redis:5",
+			isErr:     true,
+		},
+		{
+			name:      "image without a version",
+			component: "guestbook",
+			image:     "gcr.io/heptio-images/guestbook",
+			isErr:     true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			updates, err := Updates(refs, tc.component, tc.image)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, updates)
+		})
+	}
+}
+
+func TestPins(t *testing.T) {
+	params := map[string]map[string]string{
+		"guestbook": {
+			"image":    "gcr.io/heptio-images/guestbook:0.1",
+			"envoyTag": "v1.8.0",
+		},
+		"db.redis": {
+			"image": "localhost:5000/redis@sha256:abc",
+		},
+	}
+	refs := Find(testObjects(), params)
+
+	var resolved []string
+	resolve := func(image string) (string, error) {
+		resolved = append(resolved, image)
+		return ParseReference(image).Repository + "@sha256:def", nil
+	}
+
+	updates, err := Pins(refs, resolve)
+	require.NoError(t, err)
+
+	expected := []Update{{Component: "guestbook", Param: "image", Value: "gcr.io/heptio-images/guestbook@sha256:def"}}
+	assert.Equal(t, expected, updates)
+	assert.Equal(t, []string{"gcr.io/heptio-images/guestbook:0.1"}, resolved)
+
+	_, err = Pins(refs, func(string) (string, error) { return "", errors.New("fail") })
+	require.Error(t, err)
+}
+
+func TestCanPin(t *testing.T) {
+	assert.NoError(t, CanPin(Reference{Image: "nginx:1.25", Param: "image", ParamType: ParamImage}))
+	assert.Error(t, CanPin(Reference{Image: "nginx:1.25", Param: "tag", ParamType: ParamTag}))
+	assert.Error(t, CanPin(Reference{Image: "nginx:1.25"}))
+}
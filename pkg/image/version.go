@@ -0,0 +1,89 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package image
+
+import (
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/util/version"
+)
+
+// tagVersion is an image tag which is a version, such as "1.25", "v2.0.1",
+// or "1.25.3-alpine".
+type tagVersion struct {
+	version version.Version
+	// family is the shape of the tag. Only tags of the same family are
+	// compared, so "1.25-alpine" is never updated to "1.26" or "1.26.0".
+	family string
+}
+
+// parseTag parses a tag as a version. ok is false if the tag is not a
+// version.
+func parseTag(tag string) (tv tagVersion, ok bool) {
+	base, variant := tag, ""
+	if i := strings.Index(tag, "-"); i >= 0 {
+		base, variant = tag[:i], tag[i:]
+	}
+
+	prefix := ""
+	if strings.HasPrefix(base, "v") {
+		prefix = "v"
+	}
+
+	parts := strings.Split(strings.TrimPrefix(base, "v"), ".")
+	if len(parts) > 3 {
+		return tv, false
+	}
+	for _, part := range parts {
+		if part == "" || strings.Trim(part, "0123456789") != "" {
+			return tv, false
+		}
+	}
+
+	v, err := version.Make(base)
+	if err != nil {
+		return tv, false
+	}
+
+	tv.version = v
+	tv.family = prefix + strings.Repeat("x.", len(parts)) + variant
+	return tv, true
+}
+
+// Latest returns the latest of tags which is newer than tag and has the same
+// shape, such as "v1.2.3" or "1.2-alpine". ok is false if tag is not a
+// version, or none of tags are newer.
+func Latest(tag string, tags []string) (latest string, ok bool) {
+	current, ok := parseTag(tag)
+	if !ok {
+		return "", false
+	}
+
+	newest := current
+	for _, t := range tags {
+		tv, ok := parseTag(t)
+		if !ok || tv.family != current.family {
+			continue
+		}
+
+		if newest.version.LT(tv.version) {
+			newest = tv
+			latest = t
+		}
+	}
+
+	return latest, latest != ""
+}
@@ -0,0 +1,74 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package image
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatest(t *testing.T) {
+	tags := []string{"latest", "1.24", "1.25", "1.26", "1.26.1", "1.27-alpine", "1.26-alpine", "v2.0", "mainline"}
+
+	cases := []struct {
+		name     string
+		tag      string
+		expected string
+		ok       bool
+	}{
+		{
+			name:     "newer version",
+			tag:      "1.24",
+			expected: "1.26",
+			ok:       true,
+		},
+		{
+			name: "latest version",
+			tag:  "1.26",
+		},
+		{
+			name:     "variant",
+			tag:      "1.25-alpine",
+			expected: "1.27-alpine",
+			ok:       true,
+		},
+		{
+			name: "patch version",
+			tag:  "1.26.1",
+		},
+		{
+			name: "v prefix",
+			tag:  "v2.0",
+		},
+		{
+			name: "not a version",
+			tag:  "latest",
+		},
+		{
+			name: "too many parts",
+			tag:  "1.2.3.4",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			latest, ok := Latest(tc.tag, tags)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, latest)
+		})
+	}
+}
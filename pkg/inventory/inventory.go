@@ -0,0 +1,266 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package inventory reports exactly what an environment deploys: its
+// objects, their images, the prototypes their components were generated
+// from, and the versions of the packages they use.
+package inventory
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/image"
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var (
+	// importRegexp matches Jsonnet imports.
+	importRegexp = regexp.MustCompile(`import(?:str)?\s*["']([^"']+)["']`)
+	// provenanceRegexp matches the comments `prototype use` records the
+	// prototype and package of a component in.
+	provenanceRegexp = regexp.MustCompile(`^(?://|#)\s*@(prototype|package)\s+(\S+)`)
+)
+
+// Report is the inventory of an environment.
+type Report struct {
+	Environment       string      `json:"environment"`
+	KubernetesVersion string      `json:"kubernetesVersion,omitempty"`
+	Resources         []Resource  `json:"resources"`
+	Components        []Component `json:"components"`
+	Packages          []Package   `json:"packages"`
+}
+
+// Resource is an object an environment deploys.
+type Resource struct {
+	APIVersion string   `json:"apiVersion"`
+	Kind       string   `json:"kind"`
+	Namespace  string   `json:"namespace,omitempty"`
+	Name       string   `json:"name"`
+	Component  string   `json:"component"`
+	Images     []string `json:"images,omitempty"`
+}
+
+// Component is a component whose objects an environment deploys.
+type Component struct {
+	Name string `json:"name"`
+	// Source is the path of the component's file, relative to the app.
+	Source string `json:"source,omitempty"`
+	// Prototype is the prototype the component was generated from, if it
+	// was recorded when the component was generated.
+	Prototype string `json:"prototype,omitempty"`
+	// Package is the package the prototype came from, if it was recorded
+	// when the component was generated.
+	Package string `json:"package,omitempty"`
+	// Packages are the installed packages the component imports.
+	Packages []string `json:"packages,omitempty"`
+}
+
+// Package is an installed package components import.
+type Package struct {
+	Registry   string   `json:"registry"`
+	Name       string   `json:"name"`
+	Version    string   `json:"version,omitempty"`
+	Components []string `json:"components"`
+}
+
+// String returns the package as registry/name.
+func (p *Package) String() string {
+	return p.Registry + "/" + p.Name
+}
+
+// Source is the file of a component.
+type Source struct {
+	// Path is the path of the file, relative to the app.
+	Path string
+	Data []byte
+}
+
+// New creates the inventory of an environment from its rendered objects,
+// and the sources of its components keyed by component name. libraries are
+// the app's libraries; the environment's libraries override them.
+func New(env *app.EnvironmentConfig, libraries app.LibraryConfigs, objects []*unstructured.Unstructured, sources map[string]Source) *Report {
+	r := &Report{
+		Environment:       env.Name,
+		KubernetesVersion: env.KubernetesVersion,
+		Resources:         []Resource{},
+		Components:        []Component{},
+		Packages:          []Package{},
+	}
+
+	seen := make(map[string]bool)
+	for _, obj := range objects {
+		res := Resource{
+			APIVersion: obj.GetAPIVersion(),
+			Kind:       obj.GetKind(),
+			Namespace:  obj.GetNamespace(),
+			Name:       obj.GetName(),
+			Component:  obj.GetLabels()[metadata.LabelComponent],
+		}
+
+		for _, ref := range image.Find([]*unstructured.Unstructured{obj}, nil) {
+			res.Images = appendUnique(res.Images, ref.Image)
+		}
+
+		r.Resources = append(r.Resources, res)
+
+		if !seen[res.Component] && res.Component != "" {
+			seen[res.Component] = true
+			r.Components = append(r.Components, Component{Name: res.Component})
+		}
+	}
+
+	sort.SliceStable(r.Resources, func(i, j int) bool {
+		a, b := r.Resources[i], r.Resources[j]
+		if a.Component != b.Component {
+			return a.Component < b.Component
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+
+	sort.Slice(r.Components, func(i, j int) bool {
+		return r.Components[i].Name < r.Components[j].Name
+	})
+
+	libs := EffectiveLibraries(libraries, env.Libraries)
+	packages := make(map[string]*Package)
+
+	for i := range r.Components {
+		c := &r.Components[i]
+
+		src, ok := sources[c.Name]
+		if !ok {
+			continue
+		}
+
+		c.Source = src.Path
+		c.Prototype, c.Package = Provenance(src.Data)
+
+		for _, lib := range ImportedLibraries(src.Data, libs) {
+			p, ok := packages[lib.Registry+"/"+lib.Name]
+			if !ok {
+				p = &Package{Registry: lib.Registry, Name: lib.Name, Version: lib.Version}
+				packages[p.String()] = p
+			}
+
+			p.Components = append(p.Components, c.Name)
+			c.Packages = append(c.Packages, p.String())
+		}
+	}
+
+	for _, p := range packages {
+		r.Packages = append(r.Packages, *p)
+	}
+
+	sort.Slice(r.Packages, func(i, j int) bool {
+		return r.Packages[i].String() < r.Packages[j].String()
+	})
+
+	return r
+}
+
+// EffectiveLibraries returns the libraries an environment uses. Its own
+// libraries take precedence over the app's.
+func EffectiveLibraries(appLibs, envLibs app.LibraryConfigs) []*app.LibraryConfig {
+	m := make(map[string]*app.LibraryConfig)
+	for _, libs := range []app.LibraryConfigs{appLibs, envLibs} {
+		for _, lib := range libs {
+			if lib == nil {
+				continue
+			}
+			m[lib.Registry+"/"+lib.Name] = lib
+		}
+	}
+
+	var out []*app.LibraryConfig
+	for _, lib := range m {
+		out = append(out, lib)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Registry+"/"+out[i].Name < out[j].Registry+"/"+out[j].Name
+	})
+
+	return out
+}
+
+// ImportedLibraries returns the libraries a component's source imports.
+// Packages are vendored at <registry>/<package>, so they are imported by
+// paths with that prefix.
+func ImportedLibraries(data []byte, libs []*app.LibraryConfig) []*app.LibraryConfig {
+	var imported []*app.LibraryConfig
+
+	for _, lib := range libs {
+		prefix := lib.Registry + "/" + lib.Name + "/"
+		for _, m := range importRegexp.FindAllSubmatch(data, -1) {
+			if strings.HasPrefix(string(m[1]), prefix) {
+				imported = append(imported, lib)
+				break
+			}
+		}
+	}
+
+	return imported
+}
+
+// Provenance returns the prototype and package recorded in the leading
+// comments of a component's source.
+func Provenance(data []byte) (prototypeName, packageName string) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "//") && !strings.HasPrefix(line, "#") {
+			break
+		}
+
+		m := provenanceRegexp.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		switch m[1] {
+		case "prototype":
+			prototypeName = m[2]
+		case "package":
+			packageName = m[2]
+		}
+	}
+
+	return prototypeName, packageName
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, item := range list {
+		if item == s {
+			return list
+		}
+	}
+
+	return append(list, s)
+}
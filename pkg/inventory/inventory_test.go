@@ -0,0 +1,177 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package inventory
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newObject(apiVersion, kind, name, component string, containers ...interface{}) *unstructured.Unstructured {
+	obj := map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": "default",
+			"labels":    map[string]interface{}{"ksonnet.io/component": component},
+		},
+	}
+
+	if len(containers) > 0 {
+		obj["spec"] = map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{"containers": containers},
+			},
+		}
+	}
+
+	return &unstructured.Unstructured{Object: obj}
+}
+
+func container(name, image string) map[string]interface{} {
+	return map[string]interface{}{"name": name, "image": image}
+}
+
+func TestNew(t *testing.T) {
+	env := &app.EnvironmentConfig{
+		Name:              "prod",
+		KubernetesVersion: "v1.10.0",
+		Libraries: app.LibraryConfigs{
+			"redis": {Name: "redis", Registry: "incubator", Version: "0.2.0"},
+		},
+	}
+
+	libraries := app.LibraryConfigs{
+		"redis":  {Name: "redis", Registry: "incubator", Version: "0.1.0"},
+		"apache": {Name: "apache", Registry: "incubator", Version: "0.1.0"},
+	}
+
+	objects := []*unstructured.Unstructured{
+		newObject("v1", "Service", "redis", "redis"),
+		newObject("apps/v1", "Deployment", "redis", "redis",
+			container("redis", "redis:5"), container("exporter", "oliver006/redis_exporter:v0.21.1")),
+		newObject("apps/v1", "Deployment", "guestbook", "guestbook", container("web", "gcr.io/heptio-images/ks-guestbook-demo:0.1")),
+	}
+
+	sources := map[string]Source{
+		"redis": {
+			Path: "components/redis.jsonnet",
+			Data: []byte(`// @prototype io.ksonnet.pkg.redis-stateless
+// @package incubator/redis@0.2.0
+local params = std.extVar("__ksonnet/params").components.redis;
+local k = import "k.libsonnet";
+local redis = import "incubator/redis/redis.libsonnet";
+redis.parts.deployment.nonPersistent(params.name)
+`),
+		},
+		"guestbook": {
+			Path: "components/guestbook.yaml",
+			Data: []byte("apiVersion: apps/v1\nkind: Deployment\n"),
+		},
+	}
+
+	r := New(env, libraries, objects, sources)
+
+	expected := &Report{
+		Environment:       "prod",
+		KubernetesVersion: "v1.10.0",
+		Resources: []Resource{
+			{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Namespace:  "default",
+				Name:       "guestbook",
+				Component:  "guestbook",
+				Images:     []string{"gcr.io/heptio-images/ks-guestbook-demo:0.1"},
+			},
+			{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Namespace:  "default",
+				Name:       "redis",
+				Component:  "redis",
+				Images:     []string{"oliver006/redis_exporter:v0.21.1", "redis:5"},
+			},
+			{
+				APIVersion: "v1",
+				Kind:       "Service",
+				Namespace:  "default",
+				Name:       "redis",
+				Component:  "redis",
+			},
+		},
+		Components: []Component{
+			{
+				Name:   "guestbook",
+				Source: "components/guestbook.yaml",
+			},
+			{
+				Name:      "redis",
+				Source:    "components/redis.jsonnet",
+				Prototype: "io.ksonnet.pkg.redis-stateless",
+				Package:   "incubator/redis@0.2.0",
+				Packages:  []string{"incubator/redis"},
+			},
+		},
+		Packages: []Package{
+			{Registry: "incubator", Name: "redis", Version: "0.2.0", Components: []string{"redis"}},
+		},
+	}
+
+	require.Equal(t, expected, r)
+}
+
+func TestProvenance(t *testing.T) {
+	cases := []struct {
+		name      string
+		data      string
+		prototype string
+		pkg       string
+	}{
+		{
+			name:      "jsonnet",
+			data:      "// @prototype io.ksonnet.pkg.single-port-deployment\nlocal env = {};\n",
+			prototype: "io.ksonnet.pkg.single-port-deployment",
+		},
+		{
+			name:      "yaml",
+			data:      "# @prototype io.ksonnet.pkg.nginx\n# @package incubator/nginx@0.1.0\nkind: Service\n",
+			prototype: "io.ksonnet.pkg.nginx",
+			pkg:       "incubator/nginx@0.1.0",
+		},
+		{
+			name: "after the leading comments",
+			data: "local env = {};\n// @prototype io.ksonnet.pkg.nginx\n",
+		},
+		{
+			name: "none",
+			data: "{}",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			prototypeName, packageName := Provenance([]byte(tc.data))
+			assert.Equal(t, tc.prototype, prototypeName)
+			assert.Equal(t, tc.pkg, packageName)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package jb installs jsonnet libraries declared in a jsonnet-bundler
+// jsonnetfile.json into an app's vendor directory.
+package jb
+
+import (
+	"encoding/json"
+	"path"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+const (
+	// JsonnetFileName is the name of the file which declares dependencies.
+	JsonnetFileName = "jsonnetfile.json"
+	// LockFileName is the name of the file which pins the versions of
+	// installed dependencies.
+	LockFileName = "jsonnetfile.lock.json"
+	// VendorDir is the directory dependencies are installed in. It is in the
+	// jsonnet library path of components.
+	VendorDir = "vendor"
+)
+
+// JsonnetFile is a jsonnetfile.json or jsonnetfile.lock.json.
+type JsonnetFile struct {
+	Dependencies []Dependency `json:"dependencies"`
+}
+
+// Dependency is a jsonnet library.
+type Dependency struct {
+	Name    string `json:"name"`
+	Source  Source `json:"source"`
+	Version string `json:"version"`
+}
+
+// Source is the location of a dependency.
+type Source struct {
+	GitSource   *GitSource   `json:"git,omitempty"`
+	LocalSource *LocalSource `json:"local,omitempty"`
+}
+
+// GitSource is a dependency in a directory of a git repository.
+type GitSource struct {
+	Remote string `json:"remote"`
+	Subdir string `json:"subdir"`
+}
+
+// LocalSource is a dependency in a local directory.
+type LocalSource struct {
+	Directory string `json:"directory"`
+}
+
+// InstallName is the name of the directory a dependency is installed in. It
+// defaults to the last element of the dependency's path.
+func (d *Dependency) InstallName() string {
+	if d.Name != "" {
+		return d.Name
+	}
+
+	switch {
+	case d.Source.GitSource != nil:
+		p := d.Source.GitSource.Subdir
+		if strings.Trim(p, "/") == "" {
+			p = strings.TrimSuffix(d.Source.GitSource.Remote, ".git")
+		}
+		return path.Base(strings.TrimRight(p, "/"))
+	case d.Source.LocalSource != nil:
+		return path.Base(strings.TrimRight(d.Source.LocalSource.Directory, "/"))
+	default:
+		return ""
+	}
+}
+
+// sameSource reports if two dependencies come from the same place.
+func (d *Dependency) sameSource(other Dependency) bool {
+	switch {
+	case d.Source.GitSource != nil && other.Source.GitSource != nil:
+		return *d.Source.GitSource == *other.Source.GitSource
+	case d.Source.LocalSource != nil && other.Source.LocalSource != nil:
+		return *d.Source.LocalSource == *other.Source.LocalSource
+	default:
+		return false
+	}
+}
+
+// Read reads a jsonnetfile.json or jsonnetfile.lock.json.
+func Read(fs afero.Fs, filename string) (*JsonnetFile, error) {
+	b, err := afero.ReadFile(fs, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var jf JsonnetFile
+	if err := json.Unmarshal(b, &jf); err != nil {
+		return nil, errors.Wrapf(err, "reading %s", filename)
+	}
+
+	return &jf, nil
+}
+
+// Write writes a jsonnetfile.json or jsonnetfile.lock.json.
+func Write(fs afero.Fs, filename string, jf *JsonnetFile) error {
+	b, err := json.MarshalIndent(jf, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return afero.WriteFile(fs, filename, append(b, '\n'), app.DefaultFilePermissions)
+}
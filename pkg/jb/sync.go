@@ -0,0 +1,268 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package jb
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// GitFetcher checks out a version of a git repository in a directory, and
+// returns the commit it checked out. A blank version is the default branch.
+type GitFetcher func(remote, version, dir string) (string, error)
+
+// SyncerOpt is an option for configuring Syncer.
+type SyncerOpt func(*Syncer)
+
+// Syncer installs the dependencies declared in an app's jsonnetfile.json,
+// and their dependencies, in the app's vendor directory.
+type Syncer struct {
+	fs       afero.Fs
+	root     string
+	reserved map[string]bool
+
+	fetchGit GitFetcher
+	// checkoutFs is the filesystem git checks out to.
+	checkoutFs afero.Fs
+}
+
+// NewSyncer creates an instance of Syncer for the app in root. Dependencies
+// can not be installed in the reserved vendor directories, which belong to
+// registries.
+func NewSyncer(fs afero.Fs, root string, reserved []string, opts ...SyncerOpt) *Syncer {
+	s := &Syncer{
+		fs:         fs,
+		root:       root,
+		reserved:   make(map[string]bool),
+		fetchGit:   FetchGit,
+		checkoutFs: afero.NewOsFs(),
+	}
+
+	for _, name := range reserved {
+		s.reserved[name] = true
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Sync installs dependencies, and writes the installed versions to
+// jsonnetfile.lock.json. Dependencies in the lock file are installed at their
+// locked version. If a dependency is declared more than once, the first
+// declaration is installed, so the app's jsonnetfile.json takes precedence.
+func (s *Syncer) Sync() ([]Dependency, error) {
+	jf, err := Read(s.fs, filepath.Join(s.root, JsonnetFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.Errorf("%s does not exist in the app", JsonnetFileName)
+		}
+		return nil, err
+	}
+
+	locked := make(map[string]Dependency)
+	lock, err := Read(s.fs, filepath.Join(s.root, LockFileName))
+	switch {
+	case err == nil:
+		for _, d := range lock.Dependencies {
+			locked[d.InstallName()] = d
+		}
+	case !os.IsNotExist(err):
+		return nil, err
+	}
+
+	var installed []Dependency
+	seen := make(map[string]bool)
+
+	queue := jf.Dependencies
+	for len(queue) > 0 {
+		d := queue[0]
+		queue = queue[1:]
+
+		name := d.InstallName()
+		if name == "" {
+			return nil, errors.Errorf("dependency in %s does not have a name or source", JsonnetFileName)
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		if s.reserved[name] {
+			return nil, errors.Errorf("dependency %s conflicts with the registry %s in the vendor directory", name, name)
+		}
+
+		if l, ok := locked[name]; ok && d.sameSource(l) {
+			d.Version = l.Version
+		}
+
+		deps, err := s.install(name, &d)
+		if err != nil {
+			return nil, errors.Wrapf(err, "installing %s", name)
+		}
+
+		log.Infof("installed %s %s", name, d.Version)
+
+		installed = append(installed, d)
+		queue = append(queue, deps...)
+	}
+
+	lock = &JsonnetFile{Dependencies: installed}
+	if err := Write(s.fs, filepath.Join(s.root, LockFileName), lock); err != nil {
+		return nil, err
+	}
+
+	return installed, nil
+}
+
+// install installs a dependency, sets its version to the installed version,
+// and returns its dependencies.
+func (s *Syncer) install(name string, d *Dependency) ([]Dependency, error) {
+	dest := filepath.Join(s.root, VendorDir, name)
+	if err := s.fs.RemoveAll(dest); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case d.Source.GitSource != nil:
+		dir, err := afero.TempDir(s.checkoutFs, "", "ks-jb")
+		if err != nil {
+			return nil, err
+		}
+		defer s.checkoutFs.RemoveAll(dir)
+
+		commit, err := s.fetchGit(d.Source.GitSource.Remote, d.Version, dir)
+		if err != nil {
+			return nil, err
+		}
+		d.Version = commit
+
+		src := filepath.Join(dir, filepath.FromSlash(d.Source.GitSource.Subdir))
+		if err := copyDir(s.checkoutFs, src, s.fs, dest); err != nil {
+			return nil, err
+		}
+	case d.Source.LocalSource != nil:
+		src := d.Source.LocalSource.Directory
+		if !filepath.IsAbs(src) {
+			src = filepath.Join(s.root, src)
+		}
+		if err := copyDir(s.fs, src, s.fs, dest); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("dependency does not have a source")
+	}
+
+	jf, err := Read(s.fs, filepath.Join(dest, JsonnetFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return jf.Dependencies, nil
+}
+
+// copyDir copies a directory between filesystems, skipping .git.
+func copyDir(srcFs afero.Fs, src string, destFs afero.Fs, dest string) error {
+	return afero.Walk(srcFs, src, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+
+		if fi.IsDir() {
+			if fi.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return destFs.MkdirAll(target, app.DefaultFolderPermissions)
+		}
+
+		b, err := afero.ReadFile(srcFs, path)
+		if err != nil {
+			return err
+		}
+
+		return afero.WriteFile(destFs, target, b, app.DefaultFilePermissions)
+	})
+}
+
+// FetchGit checks out a version of a git repository in a directory with the git
+// command. It is a GitFetcher.
+func FetchGit(remote, version, dir string) (string, error) {
+	// Remotes and versions are passed to git as arguments, so they can not be
+	// allowed to look like options.
+	if strings.HasPrefix(remote, "-") || strings.HasPrefix(version, "-") {
+		return "", errors.Errorf("invalid git remote %q or version %q", remote, version)
+	}
+
+	git := func(args ...string) (string, error) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+
+		out, err := cmd.Output()
+		if err != nil {
+			if execErr, ok := err.(*exec.Error); ok && execErr.Err == exec.ErrNotFound {
+				return "", errors.New("git was not found; install git to sync git dependencies")
+			}
+			return "", errors.Errorf("git %s: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+		}
+
+		return strings.TrimSpace(string(out)), nil
+	}
+
+	if _, err := git("init", "--quiet"); err != nil {
+		return "", err
+	}
+
+	ref := version
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	// Shallow fetches work for branches, tags, and, on most servers, commits.
+	// Fall back to fetching the whole repository to find other commits.
+	if _, err := git("fetch", "--quiet", "--depth", "1", "--", remote, ref); err == nil {
+		ref = "FETCH_HEAD"
+	} else if _, err := git("fetch", "--quiet", "--", remote, "+refs/heads/*:refs/remotes/origin/*", "+refs/tags/*:refs/tags/*"); err != nil {
+		return "", err
+	}
+
+	if _, err := git("-c", "advice.detachedHead=false", "checkout", "--quiet", ref, "--"); err != nil {
+		return "", err
+	}
+
+	return git("rev-parse", "HEAD")
+}
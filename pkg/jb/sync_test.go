@@ -0,0 +1,246 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package jb
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubGit returns a GitFetcher which checks out files from repositories,
+// which are keyed by remote, and records the versions it fetched.
+func stubGit(checkoutFs afero.Fs, repos map[string]map[string]string, fetched map[string]string) GitFetcher {
+	return func(remote, version, dir string) (string, error) {
+		fetched[remote] = version
+		for name, content := range repos[remote] {
+			path := filepath.Join(dir, name)
+			if err := checkoutFs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return "", err
+			}
+			if err := afero.WriteFile(checkoutFs, path, []byte(content), 0644); err != nil {
+				return "", err
+			}
+		}
+
+		if version == "" {
+			return "abc123", nil
+		}
+		return version, nil
+	}
+}
+
+func TestSyncer_Sync(t *testing.T) {
+	repos := map[string]map[string]string{
+		"https://github.com/coreos/prometheus-operator": {
+			"contrib/kube-prometheus/jsonnet/kube-prometheus/kube-prometheus.libsonnet": "{}",
+			"contrib/kube-prometheus/jsonnet/kube-prometheus/jsonnetfile.json": `{
+  "dependencies": [
+    {
+      "name": "ksonnet",
+      "source": {"git": {"remote": "https://github.com/ksonnet/ksonnet-lib", "subdir": ""}},
+      "version": "master"
+    },
+    {
+      "name": "grafana",
+      "source": {"git": {"remote": "https://github.com/brancz/kubernetes-grafana", "subdir": "grafana"}},
+      "version": "master"
+    }
+  ]
+}`,
+		},
+		"https://github.com/ksonnet/ksonnet-lib": {
+			"ksonnet.beta.3/k.libsonnet": "{}",
+			".git/HEAD":                  "ref: refs/heads/master",
+		},
+	}
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/app/jsonnetfile.json", []byte(`{
+  "dependencies": [
+    {
+      "source": {"git": {"remote": "https://github.com/coreos/prometheus-operator", "subdir": "contrib/kube-prometheus/jsonnet/kube-prometheus"}},
+      "version": "v0.23.0"
+    },
+    {
+      "name": "grafana",
+      "source": {"local": {"directory": "lib/grafana"}}
+    }
+  ]
+}`), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/app/lib/grafana/grafana.libsonnet", []byte("{}"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/app/jsonnetfile.lock.json", []byte(`{
+  "dependencies": [
+    {
+      "name": "ksonnet",
+      "source": {"git": {"remote": "https://github.com/ksonnet/ksonnet-lib", "subdir": ""}},
+      "version": "d03da231d6c8bd74437b74a1e9e8b966f13dffa2"
+    }
+  ]
+}`), 0644))
+
+	checkoutFs := afero.NewMemMapFs()
+	fetched := map[string]string{}
+
+	s := NewSyncer(fs, "/app", []string{"incubator"}, func(s *Syncer) {
+		s.checkoutFs = checkoutFs
+		s.fetchGit = stubGit(checkoutFs, repos, fetched)
+	})
+
+	installed, err := s.Sync()
+	require.NoError(t, err)
+
+	var names []string
+	for _, d := range installed {
+		names = append(names, d.InstallName()+"@"+d.Version)
+	}
+	assert.Equal(t, []string{
+		"kube-prometheus@v0.23.0",
+		"grafana@",
+		"ksonnet@d03da231d6c8bd74437b74a1e9e8b966f13dffa2",
+	}, names)
+
+	// the app's local grafana takes precedence over the transitive dependency.
+	assert.NotContains(t, fetched, "https://github.com/brancz/kubernetes-grafana")
+	assert.Equal(t, "d03da231d6c8bd74437b74a1e9e8b966f13dffa2", fetched["https://github.com/ksonnet/ksonnet-lib"])
+
+	for _, path := range []string{
+		"/app/vendor/kube-prometheus/kube-prometheus.libsonnet",
+		"/app/vendor/ksonnet/ksonnet.beta.3/k.libsonnet",
+		"/app/vendor/grafana/grafana.libsonnet",
+	} {
+		exists, err := afero.Exists(fs, path)
+		require.NoError(t, err)
+		assert.True(t, exists, "expected %s to exist", path)
+	}
+
+	exists, err := afero.DirExists(fs, "/app/vendor/ksonnet/.git")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	lock, err := Read(fs, "/app/jsonnetfile.lock.json")
+	require.NoError(t, err)
+	assert.Equal(t, installed, lock.Dependencies)
+}
+
+func TestSyncer_Sync_errors(t *testing.T) {
+	cases := []struct {
+		name        string
+		jsonnetfile string
+	}{
+		{
+			name: "missing jsonnetfile.json",
+		},
+		{
+			name:        "invalid jsonnetfile.json",
+			jsonnetfile: `{`,
+		},
+		{
+			name:        "dependency without a source",
+			jsonnetfile: `{"dependencies": [{"name": "lib"}]}`,
+		},
+		{
+			name:        "dependency in a registry's vendor directory",
+			jsonnetfile: `{"dependencies": [{"name": "incubator", "source": {"local": {"directory": "lib"}}}]}`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			if tc.jsonnetfile != "" {
+				require.NoError(t, afero.WriteFile(fs, "/app/jsonnetfile.json", []byte(tc.jsonnetfile), 0644))
+			}
+
+			s := NewSyncer(fs, "/app", []string{"incubator"})
+			_, err := s.Sync()
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestFetchGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	remote, err := ioutil.TempDir("", "TestFetchGit")
+	require.NoError(t, err)
+	defer os.RemoveAll(remote)
+
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=ks", "-c", "user.email=ks@example.com"}, args...)...)
+		cmd.Dir = remote
+		out, err := cmd.Output()
+		require.NoError(t, err)
+		return string(out)
+	}
+
+	git("init", "--quiet")
+	require.NoError(t, ioutil.WriteFile(filepath.Join(remote, "lib.libsonnet"), []byte("{ v: 1 }"), 0644))
+	git("add", ".")
+	git("commit", "--quiet", "-m", "v1")
+	first := git("rev-parse", "HEAD")
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(remote, "lib.libsonnet"), []byte("{ v: 2 }"), 0644))
+	git("commit", "--quiet", "-am", "v2")
+
+	cases := []struct {
+		name     string
+		version  string
+		expected string
+	}{
+		{name: "default branch", expected: "{ v: 2 }"},
+		{name: "commit", version: first[:len(first)-1], expected: "{ v: 1 }"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "TestFetchGit")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			commit, err := FetchGit(remote, tc.version, dir)
+			require.NoError(t, err)
+			assert.Len(t, commit, 40)
+
+			b, err := ioutil.ReadFile(filepath.Join(dir, "lib.libsonnet"))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(b))
+		})
+	}
+}
+
+func TestFetchGit_options(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestFetchGit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, err = FetchGit("https://example.com/repo", "--upload-pack=touch pwned; git-upload-pack", dir)
+	require.Error(t, err)
+
+	_, err = FetchGit("--upload-pack=touch pwned", "", dir)
+	require.Error(t, err)
+
+	_, err = os.Stat(filepath.Join(dir, "pwned"))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,128 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package lib
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// APIAdded is a definition which was added to the API.
+	APIAdded = "added"
+	// APIRemoved is a definition which was removed from the API.
+	APIRemoved = "removed"
+	// APIModified is a definition whose properties changed.
+	APIModified = "modified"
+)
+
+// APIChange is a change to a definition between two versions of an OpenAPI
+// spec.
+type APIChange struct {
+	Definition string `json:"definition"`
+	Change     string `json:"change"`
+	// Properties lists the properties of a modified definition which were
+	// added (+) or removed (-).
+	Properties []string `json:"properties,omitempty"`
+}
+
+// apiSpec is the subset of an OpenAPI spec which is compared.
+type apiSpec struct {
+	Definitions map[string]struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+	} `json:"definitions"`
+}
+
+// DiffAPI compares the definitions in two OpenAPI specs. An empty spec has
+// no definitions.
+func DiffAPI(from, to []byte) ([]APIChange, error) {
+	fromSpec, err := parseAPISpec(from)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing current OpenAPI spec")
+	}
+
+	toSpec, err := parseAPISpec(to)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing new OpenAPI spec")
+	}
+
+	var names []string
+	for name := range fromSpec.Definitions {
+		names = append(names, name)
+	}
+	for name := range toSpec.Definitions {
+		if _, ok := fromSpec.Definitions[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := []APIChange{}
+	for _, name := range names {
+		before, inFrom := fromSpec.Definitions[name]
+		after, inTo := toSpec.Definitions[name]
+
+		switch {
+		case !inFrom:
+			changes = append(changes, APIChange{Definition: name, Change: APIAdded})
+		case !inTo:
+			changes = append(changes, APIChange{Definition: name, Change: APIRemoved})
+		default:
+			var properties []string
+			for _, p := range sortedKeys(before.Properties) {
+				if _, ok := after.Properties[p]; !ok {
+					properties = append(properties, "-"+p)
+				}
+			}
+			for _, p := range sortedKeys(after.Properties) {
+				if _, ok := before.Properties[p]; !ok {
+					properties = append(properties, "+"+p)
+				}
+			}
+
+			if len(properties) > 0 {
+				changes = append(changes, APIChange{Definition: name, Change: APIModified, Properties: properties})
+			}
+		}
+	}
+
+	return changes, nil
+}
+
+func parseAPISpec(data []byte) (*apiSpec, error) {
+	var spec apiSpec
+	if len(data) == 0 {
+		return &spec, nil
+	}
+
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, err
+	}
+
+	return &spec, nil
+}
+
+func sortedKeys(m map[string]json.RawMessage) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
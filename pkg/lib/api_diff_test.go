@@ -0,0 +1,84 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package lib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffAPI(t *testing.T) {
+	from := []byte(`{
+  "definitions": {
+    "io.k8s.api.apps.v1.Deployment": {
+      "properties": {"apiVersion": {}, "kind": {}, "spec": {}}
+    },
+    "io.k8s.api.extensions.v1beta1.Deployment": {
+      "properties": {"spec": {}}
+    },
+    "io.k8s.api.core.v1.PodSpec": {
+      "properties": {"containers": {}, "serviceAccount": {}}
+    }
+  }
+}`)
+
+	to := []byte(`{
+  "definitions": {
+    "io.k8s.api.apps.v1.Deployment": {
+      "properties": {"apiVersion": {}, "kind": {}, "spec": {}}
+    },
+    "io.k8s.api.core.v1.PodSpec": {
+      "properties": {"containers": {}, "shareProcessNamespace": {}}
+    },
+    "io.k8s.api.networking.v1.NetworkPolicy": {
+      "properties": {"spec": {}}
+    }
+  }
+}`)
+
+	changes, err := DiffAPI(from, to)
+	require.NoError(t, err)
+
+	expected := []APIChange{
+		{
+			Definition: "io.k8s.api.core.v1.PodSpec",
+			Change:     APIModified,
+			Properties: []string{"-serviceAccount", "+shareProcessNamespace"},
+		},
+		{Definition: "io.k8s.api.extensions.v1beta1.Deployment", Change: APIRemoved},
+		{Definition: "io.k8s.api.networking.v1.NetworkPolicy", Change: APIAdded},
+	}
+	assert.Equal(t, expected, changes)
+}
+
+func TestDiffAPI_empty(t *testing.T) {
+	to := []byte(`{"definitions": {"io.k8s.api.core.v1.Pod": {}}}`)
+
+	changes, err := DiffAPI(nil, to)
+	require.NoError(t, err)
+	assert.Equal(t, []APIChange{{Definition: "io.k8s.api.core.v1.Pod", Change: APIAdded}}, changes)
+
+	changes, err = DiffAPI(to, to)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestDiffAPI_invalid(t *testing.T) {
+	_, err := DiffAPI([]byte("{"), nil)
+	require.Error(t, err)
+}
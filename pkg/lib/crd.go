@@ -0,0 +1,286 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package lib
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/go-openapi/spec"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// CRDLibFilename is the file name of the library generated for custom
+	// resources. It is placed in the app's lib directory.
+	CRDLibFilename = "crds.libsonnet"
+
+	// maxCRDDepth limits how deeply nested objects get helpers.
+	maxCRDDepth = 8
+)
+
+// jsonnetKeywords can't be used as identifiers.
+var jsonnetKeywords = map[string]bool{
+	"assert": true, "else": true, "error": true, "false": true, "for": true,
+	"function": true, "if": true, "import": true, "importstr": true, "in": true,
+	"local": true, "null": true, "self": true, "super": true, "tailstrict": true,
+	"then": true, "true": true,
+}
+
+// GenerateCRDLib generates a library of helpers for custom resources from
+// their OpenAPI schemas. Helpers mirror the ones ksonnet-lib generates for
+// core kinds: a resource is created with
+// `crds[group][version][kind].new(name)`, and its fields are set by adding
+// the objects returned by the functions in its `mixin` object, e.g.
+// `mixin.spec.withReplicas(3)`. The objects don't include the helpers, so
+// property names can't collide with them.
+func GenerateCRDLib(schemas map[schema.GroupVersionKind]*spec.Schema) []byte {
+	var gvks []schema.GroupVersionKind
+	for gvk := range schemas {
+		gvks = append(gvks, gvk)
+	}
+	sort.Slice(gvks, func(i, j int) bool {
+		return gvks[i].String() < gvks[j].String()
+	})
+
+	g := &crdGenerator{}
+	g.line("// This file is generated by `ks env update-crd-lib`. Do not edit it.")
+	g.line("{")
+	g.indent++
+
+	for i := 0; i < len(gvks); {
+		group := gvks[i].Group
+		g.line("%s:: {", jsonnetField(group))
+		g.indent++
+
+		for i < len(gvks) && gvks[i].Group == group {
+			version := gvks[i].Version
+			g.line("%s:: {", jsonnetField(version))
+			g.indent++
+
+			for i < len(gvks) && gvks[i].Group == group && gvks[i].Version == version {
+				g.kind(gvks[i], schemas[gvks[i]])
+				i++
+			}
+
+			g.indent--
+			g.line("},")
+		}
+
+		g.indent--
+		g.line("},")
+	}
+
+	g.indent--
+	g.line("}")
+
+	return g.buf.Bytes()
+}
+
+type crdGenerator struct {
+	buf    bytes.Buffer
+	indent int
+}
+
+func (g *crdGenerator) line(format string, a ...interface{}) {
+	g.buf.WriteString(strings.Repeat("  ", g.indent))
+	fmt.Fprintf(&g.buf, format, a...)
+	g.buf.WriteString("\n")
+}
+
+func (g *crdGenerator) kind(gvk schema.GroupVersionKind, sch *spec.Schema) {
+	g.line("%s:: {", jsonnetField(lowerFirst(gvk.Kind)))
+	g.indent++
+
+	g.line("local apiVersion = { apiVersion: %q },", gvk.GroupVersion().String())
+	g.line("local kind = { kind: %q },", gvk.Kind)
+	g.line("new(name):: apiVersion + kind + self.mixin.metadata.withName(name),")
+
+	var mixins []string
+	for _, name := range sortedProperties(sch) {
+		switch name {
+		case "apiVersion", "kind", "metadata", "status":
+			continue
+		}
+
+		prop := sch.Properties[name]
+		if isObject(&prop) {
+			mixins = append(mixins, name)
+			continue
+		}
+		g.setters(name, &prop, "")
+	}
+
+	g.line("mixin:: {")
+	g.indent++
+
+	g.line("metadata:: {")
+	g.indent++
+	g.line("local __metadataMixin(o) = { metadata+: o },")
+	g.line("withName(name):: __metadataMixin({ name: name }),")
+	g.line("withNamespace(namespace):: __metadataMixin({ namespace: namespace }),")
+	g.line("withLabels(labels):: __metadataMixin({ labels: labels }),")
+	g.line("withLabelsMixin(labels):: __metadataMixin({ labels+: labels }),")
+	g.line("withAnnotations(annotations):: __metadataMixin({ annotations: annotations }),")
+	g.line("withAnnotationsMixin(annotations):: __metadataMixin({ annotations+: annotations }),")
+	g.indent--
+	g.line("},")
+
+	for _, name := range mixins {
+		prop := sch.Properties[name]
+		g.object([]string{name}, &prop, "")
+	}
+
+	g.indent--
+	g.line("},")
+
+	g.indent--
+	g.line("},")
+}
+
+// object generates the helpers for an object property. parentMixin is the
+// local which merges into the parent object; it is empty at the top level.
+func (g *crdGenerator) object(path []string, sch *spec.Schema, parentMixin string) {
+	name := path[len(path)-1]
+	mixin := "__" + identifier(strings.Join(path, "_")) + "Mixin"
+
+	g.line("%s:: {", jsonnetField(name))
+	g.indent++
+
+	if parentMixin == "" {
+		g.line("local %s(o) = { %s+: o },", mixin, jsonnetField(name))
+	} else {
+		g.line("local %s(o) = %s({ %s+: o }),", mixin, parentMixin, jsonnetField(name))
+	}
+
+	var nested []string
+	for _, p := range sortedProperties(sch) {
+		prop := sch.Properties[p]
+		if isObject(&prop) && len(path) < maxCRDDepth {
+			nested = append(nested, p)
+		}
+		g.setters(p, &prop, mixin)
+	}
+
+	for _, p := range nested {
+		prop := sch.Properties[p]
+		g.object(append(append([]string{}, path...), p), &prop, mixin)
+	}
+
+	g.indent--
+	g.line("},")
+}
+
+// setters generates the functions which set a property. Arrays and maps
+// also get a function which appends to them.
+func (g *crdGenerator) setters(name string, sch *spec.Schema, mixin string) {
+	fn := "with" + upperFirst(identifier(name))
+	param := identifier(name)
+	if jsonnetKeywords[param] {
+		param = "value"
+	}
+	field := jsonnetField(name)
+
+	wrap := func(body string) string {
+		if mixin == "" {
+			return body
+		}
+		return fmt.Sprintf("%s(%s)", mixin, body)
+	}
+
+	switch {
+	case sch.Type.Contains("array"):
+		value := fmt.Sprintf("if std.type(%s) == 'array' then %s else [%s]", param, param, param)
+		g.line("%s(%s):: %s,", fn, param, wrap(fmt.Sprintf("{ %s: %s }", field, value)))
+		g.line("%sMixin(%s):: %s,", fn, param, wrap(fmt.Sprintf("{ %s+: %s }", field, value)))
+	case isMap(sch):
+		g.line("%s(%s):: %s,", fn, param, wrap(fmt.Sprintf("{ %s: %s }", field, param)))
+		g.line("%sMixin(%s):: %s,", fn, param, wrap(fmt.Sprintf("{ %s+: %s }", field, param)))
+	default:
+		g.line("%s(%s):: %s,", fn, param, wrap(fmt.Sprintf("{ %s: %s }", field, param)))
+	}
+}
+
+// isObject returns true if a schema is an object with known properties.
+func isObject(sch *spec.Schema) bool {
+	return len(sch.Properties) > 0
+}
+
+// isMap returns true if a schema is an object with arbitrary keys.
+func isMap(sch *spec.Schema) bool {
+	return sch.Type.Contains("object") && len(sch.Properties) == 0
+}
+
+func sortedProperties(sch *spec.Schema) []string {
+	var names []string
+	for name := range sch.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// identifier converts a property name to a camel cased jsonnet identifier.
+func identifier(name string) string {
+	var b strings.Builder
+	upper := false
+	for _, r := range name {
+		switch {
+		case r == '_' || unicode.IsLetter(r) && r < unicode.MaxASCII || unicode.IsDigit(r) && r < unicode.MaxASCII:
+			if upper {
+				r = unicode.ToUpper(r)
+				upper = false
+			}
+			b.WriteRune(r)
+		default:
+			upper = b.Len() > 0
+		}
+	}
+
+	s := b.String()
+	if s == "" || unicode.IsDigit(rune(s[0])) {
+		s = "_" + s
+	}
+
+	return s
+}
+
+// jsonnetField returns a field name, quoted unless it is an identifier.
+func jsonnetField(name string) string {
+	if name != "" && identifier(name) == name && !jsonnetKeywords[name] {
+		return name
+	}
+
+	return fmt.Sprintf("%q", name)
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,139 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package lib
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-openapi/spec"
+	"github.com/google/go-jsonnet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const certificateSchema = `{
+  "properties": {
+    "apiVersion": {"type": "string"},
+    "kind": {"type": "string"},
+    "metadata": {"type": "object"},
+    "spec": {
+      "properties": {
+        "dnsNames": {"type": "array", "items": {"type": "string"}},
+        "duration": {"type": "string"},
+        "issuerRef": {
+          "properties": {
+            "name": {"type": "string"},
+            "kind": {"type": "string"}
+          }
+        },
+        "secret-labels": {"type": "object"},
+        "local": {"type": "boolean"},
+        "spec": {
+          "properties": {
+            "nested": {"type": "string"}
+          }
+        }
+      }
+    },
+    "status": {
+      "properties": {
+        "ready": {"type": "boolean"}
+      }
+    }
+  }
+}`
+
+func TestGenerateCRDLib(t *testing.T) {
+	var sch spec.Schema
+	require.NoError(t, json.Unmarshal([]byte(certificateSchema), &sch))
+
+	gvk := schema.GroupVersionKind{Group: "certmanager.k8s.io", Version: "v1alpha1", Kind: "Certificate"}
+	data := GenerateCRDLib(map[schema.GroupVersionKind]*spec.Schema{gvk: &sch})
+
+	vm := jsonnet.MakeVM()
+	vm.Importer(&jsonnet.MemoryImporter{Data: map[string]jsonnet.Contents{
+		CRDLibFilename: jsonnet.MakeContents(string(data)),
+	}})
+
+	snippet := `
+local crds = import 'crds.libsonnet';
+local certificate = crds['certmanager.k8s.io'].v1alpha1.certificate;
+local spec = certificate.mixin.spec;
+
+certificate.new('cert') +
+certificate.mixin.metadata.withNamespace('default') +
+certificate.mixin.metadata.withLabels({ app: 'web' }) +
+spec.withDnsNames('example.com') +
+spec.withDnsNamesMixin(['www.example.com']) +
+spec.withDuration('24h') +
+spec.withSecretLabels({ a: '1' }) +
+spec.withSecretLabelsMixin({ b: '2' }) +
+spec.withLocal(true) +
+spec.issuerRef.withName('letsencrypt') +
+spec.issuerRef.withKind('ClusterIssuer') +
+spec.spec.withNested('value')
+`
+
+	out, err := vm.EvaluateSnippet("test.jsonnet", snippet)
+	require.NoError(t, err, string(data))
+
+	expected := `{
+  "apiVersion": "certmanager.k8s.io/v1alpha1",
+  "kind": "Certificate",
+  "metadata": {
+    "labels": {"app": "web"},
+    "name": "cert",
+    "namespace": "default"
+  },
+  "spec": {
+    "dnsNames": ["example.com", "www.example.com"],
+    "duration": "24h",
+    "issuerRef": {"kind": "ClusterIssuer", "name": "letsencrypt"},
+    "local": true,
+    "secret-labels": {"a": "1", "b": "2"},
+    "spec": {"nested": "value"}
+  }
+}`
+	assert.JSONEq(t, expected, out)
+}
+
+func Test_identifier(t *testing.T) {
+	cases := []struct {
+		name     string
+		expected string
+	}{
+		{name: "replicas", expected: "replicas"},
+		{name: "secret-labels", expected: "secretLabels"},
+		{name: "x.y_z", expected: "xY_z"},
+		{name: "3scale", expected: "_3scale"},
+		{name: "$ref", expected: "ref"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, identifier(tc.name))
+		})
+	}
+}
+
+func Test_jsonnetField(t *testing.T) {
+	assert.Equal(t, "spec", jsonnetField("spec"))
+	assert.Equal(t, `"secret-labels"`, jsonnetField("secret-labels"))
+	assert.Equal(t, `"local"`, jsonnetField("local"))
+	assert.Equal(t, `"certmanager.k8s.io"`, jsonnetField("certmanager.k8s.io"))
+}
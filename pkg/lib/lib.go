@@ -29,7 +29,9 @@ import (
 )
 
 const (
-	schemaFilename = "swagger.json"
+	// SchemaFilename is the file name of the OpenAPI spec ksonnet-lib is
+	// generated from.
+	SchemaFilename = "swagger.json"
 	k8sLibFilename = "k8s.libsonnet"
 
 	// ExtensionsLibFilename is the file name with the contents of the
@@ -130,7 +132,7 @@ func (m *Manager) GenerateLibData() error {
 	}{
 		{
 			// schema file
-			filepath.Join(genPath, schemaFilename),
+			filepath.Join(genPath, SchemaFilename),
 			kl.Swagger,
 		},
 		{
@@ -0,0 +1,248 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package lint checks the formatting of an app's jsonnet, and lints it for
+// common errors, with the jsonnetfmt and jsonnet-lint tools.
+package lint
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/util/table"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+const (
+	// CheckFormat diagnostics are files which are not formatted.
+	CheckFormat = "fmt"
+	// CheckLint diagnostics are problems found by the linter.
+	CheckLint = "lint"
+)
+
+// Diagnostic is a problem in a file.
+type Diagnostic struct {
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Check   string `json:"check"`
+	Message string `json:"message"`
+}
+
+// Location is the file, line, and column of the diagnostic.
+func (d *Diagnostic) Location() string {
+	loc := d.File
+	if d.Line > 0 {
+		loc += ":" + strconv.Itoa(d.Line)
+		if d.Column > 0 {
+			loc += ":" + strconv.Itoa(d.Column)
+		}
+	}
+
+	return loc
+}
+
+// Result is the result of linting an app.
+type Result struct {
+	Diagnostics []Diagnostic `json:"diagnostics"`
+	// Formatted are the files which were reformatted.
+	Formatted []string `json:"formatted"`
+}
+
+// NewResult creates an instance of Result.
+func NewResult() *Result {
+	return &Result{
+		Diagnostics: []Diagnostic{},
+		Formatted:   []string{},
+	}
+}
+
+// Err returns an error if there are diagnostics.
+func (r *Result) Err() error {
+	if len(r.Diagnostics) > 0 {
+		return errors.Errorf("%d lint problem(s) found", len(r.Diagnostics))
+	}
+
+	return nil
+}
+
+// Render writes the diagnostics as a table, or the result as JSON or YAML.
+func (r *Result) Render(w io.Writer, output string) error {
+	f, err := table.DetectFormat(output)
+	if err != nil {
+		return errors.Wrap(err, "detecting output format")
+	}
+
+	if f != table.FormatTable {
+		return table.Encode(w, f, r)
+	}
+
+	t := table.New("lint", w)
+	t.SetHeader([]string{"location", "check", "message"})
+
+	for _, d := range r.Diagnostics {
+		t.Append([]string{d.Location(), d.Check, d.Message})
+	}
+
+	return t.Render()
+}
+
+// Tools formats and lints jsonnet files.
+type Tools interface {
+	// Format returns the formatted contents of a file.
+	Format(path string) ([]byte, error)
+	// Lint returns the problems in a file. Imports are resolved in jPaths.
+	Lint(path string, jPaths []string) ([]Diagnostic, error)
+}
+
+// Linter lints the jsonnet in an app's components, lib, and vendor
+// directories.
+type Linter struct {
+	fs     afero.Fs
+	root   string
+	jPaths []string
+	tools  Tools
+}
+
+// NewLinter creates an instance of Linter for the app in root.
+func NewLinter(fs afero.Fs, root string, jPaths []string, tools Tools) *Linter {
+	return &Linter{
+		fs:     fs,
+		root:   root,
+		jPaths: jPaths,
+		tools:  tools,
+	}
+}
+
+// Run lints files. The formatting of vendored files is not checked, since
+// they belong to their packages. If write is true, files which are not
+// formatted are reformatted.
+func (l *Linter) Run(write bool) (*Result, error) {
+	result := NewResult()
+
+	for _, dir := range []string{"components", "lib", "vendor"} {
+		paths, err := l.files(filepath.Join(l.root, dir))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, path := range paths {
+			rel, err := filepath.Rel(l.root, path)
+			if err != nil {
+				return nil, err
+			}
+
+			if dir != "vendor" {
+				if err = l.checkFormat(path, rel, write, result); err != nil {
+					return nil, err
+				}
+			}
+
+			diagnostics, err := l.tools.Lint(path, l.jPaths)
+			if err != nil {
+				return nil, errors.Wrapf(err, "linting %s", rel)
+			}
+			for _, d := range diagnostics {
+				if filepath.IsAbs(d.File) {
+					if r, err := filepath.Rel(l.root, d.File); err == nil {
+						d.File = r
+					}
+				}
+				result.Diagnostics = append(result.Diagnostics, d)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (l *Linter) checkFormat(path, rel string, write bool, result *Result) error {
+	formatted, err := l.tools.Format(path)
+	if err != nil {
+		if f, ok := err.(*failure); ok {
+			result.Diagnostics = append(result.Diagnostics, Diagnostic{
+				File:    rel,
+				Check:   CheckFormat,
+				Message: f.Error(),
+			})
+			return nil
+		}
+		return errors.Wrapf(err, "formatting %s", rel)
+	}
+
+	current, err := afero.ReadFile(l.fs, path)
+	if err != nil {
+		return err
+	}
+
+	if bytes.Equal(current, formatted) {
+		return nil
+	}
+
+	if !write {
+		result.Diagnostics = append(result.Diagnostics, Diagnostic{
+			File:    rel,
+			Check:   CheckFormat,
+			Message: "file is not formatted; run `ks lint --write` to format it",
+		})
+		return nil
+	}
+
+	log.Infof("formatting %s", rel)
+	if err = afero.WriteFile(l.fs, path, formatted, app.DefaultFilePermissions); err != nil {
+		return err
+	}
+	result.Formatted = append(result.Formatted, rel)
+
+	return nil
+}
+
+// files returns the jsonnet files in a directory. Generated Kubernetes
+// libraries are skipped.
+func (l *Linter) files(dir string) ([]string, error) {
+	var paths []string
+
+	err := afero.Walk(l.fs, dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		if fi.IsDir() {
+			if fi.Name() == "ksonnet-lib" || (strings.HasPrefix(fi.Name(), ".") && path != dir) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		switch filepath.Ext(path) {
+		case ".jsonnet", ".libsonnet":
+			paths = append(paths, path)
+		}
+
+		return nil
+	})
+
+	return paths, err
+}
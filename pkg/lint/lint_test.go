@@ -0,0 +1,154 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTools formats files by trimming their whitespace, and reports a lint
+// problem for each line containing "unused".
+type fakeTools struct {
+	fs     afero.Fs
+	linted []string
+}
+
+func (ft *fakeTools) Format(path string) ([]byte, error) {
+	b, err := afero.ReadFile(ft.fs, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.Contains(string(b), "{{") {
+		return nil, &failure{output: "unexpected: {"}
+	}
+
+	return []byte(strings.TrimSpace(string(b)) + "\n"), nil
+}
+
+func (ft *fakeTools) Lint(path string, jPaths []string) ([]Diagnostic, error) {
+	ft.linted = append(ft.linted, path)
+
+	b, err := afero.ReadFile(ft.fs, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var diagnostics []Diagnostic
+	for i, line := range strings.Split(string(b), "\n") {
+		if strings.Contains(line, "unused") {
+			diagnostics = append(diagnostics, Diagnostic{File: path, Line: i + 1, Column: 1, Check: CheckLint, Message: "Unused variable: unused"})
+		}
+	}
+
+	return diagnostics, nil
+}
+
+func stageLintApp(t *testing.T) afero.Fs {
+	fs := afero.NewMemMapFs()
+
+	files := map[string]string{
+		"/app/components/formatted.jsonnet":               "{}\n",
+		"/app/components/unformatted.jsonnet":             "  {}  \n\n",
+		"/app/components/params.libsonnet":                "local unused = 1;\n{}\n",
+		"/app/components/invalid.jsonnet":                 "{{\n",
+		"/app/components/README.md":                       "not jsonnet",
+		"/app/lib/ksonnet-lib/v1.10.3/k.libsonnet":        "  {}",
+		"/app/vendor/incubator/redis@1.0/redis.libsonnet": "  local unused = 1; {}",
+	}
+
+	for path, content := range files {
+		require.NoError(t, afero.WriteFile(fs, path, []byte(content), 0644))
+	}
+
+	return fs
+}
+
+func TestLinter_Run(t *testing.T) {
+	fs := stageLintApp(t)
+	tools := &fakeTools{fs: fs}
+
+	l := NewLinter(fs, "/app", []string{"/app/lib", "/app/vendor"}, tools)
+
+	result, err := l.Run(false)
+	require.NoError(t, err)
+
+	expected := []Diagnostic{
+		{File: "components/invalid.jsonnet", Check: CheckFormat, Message: "unexpected: {"},
+		{File: "components/params.libsonnet", Line: 1, Column: 1, Check: CheckLint, Message: "Unused variable: unused"},
+		{File: "components/unformatted.jsonnet", Check: CheckFormat, Message: "file is not formatted; run `ks lint --write` to format it"},
+		{File: "vendor/incubator/redis@1.0/redis.libsonnet", Line: 1, Column: 1, Check: CheckLint, Message: "Unused variable: unused"},
+	}
+	assert.Equal(t, expected, result.Diagnostics)
+	assert.Empty(t, result.Formatted)
+	assert.Error(t, result.Err())
+
+	assert.NotContains(t, tools.linted, "/app/lib/ksonnet-lib/v1.10.3/k.libsonnet")
+
+	b, err := afero.ReadFile(fs, "/app/components/unformatted.jsonnet")
+	require.NoError(t, err)
+	assert.Equal(t, "  {}  \n\n", string(b))
+}
+
+func TestLinter_Run_write(t *testing.T) {
+	fs := stageLintApp(t)
+	tools := &fakeTools{fs: fs}
+
+	l := NewLinter(fs, "/app", nil, tools)
+
+	result, err := l.Run(true)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"components/unformatted.jsonnet"}, result.Formatted)
+	for _, d := range result.Diagnostics {
+		assert.NotEqual(t, "components/unformatted.jsonnet", d.File)
+	}
+
+	b, err := afero.ReadFile(fs, "/app/components/unformatted.jsonnet")
+	require.NoError(t, err)
+	assert.Equal(t, "{}\n", string(b))
+
+	b, err = afero.ReadFile(fs, "/app/vendor/incubator/redis@1.0/redis.libsonnet")
+	require.NoError(t, err)
+	assert.Equal(t, "  local unused = 1; {}", string(b))
+}
+
+func TestResult_Render(t *testing.T) {
+	result := NewResult()
+	result.Diagnostics = append(result.Diagnostics,
+		Diagnostic{File: "components/a.jsonnet", Line: 3, Column: 7, Check: CheckLint, Message: "Unused variable: x"},
+		Diagnostic{File: "components/b.jsonnet", Check: CheckFormat, Message: "file is not formatted"},
+	)
+
+	var buf bytes.Buffer
+	require.NoError(t, result.Render(&buf, ""))
+	assert.Contains(t, buf.String(), "components/a.jsonnet:3:7")
+	assert.Contains(t, buf.String(), "file is not formatted")
+
+	buf.Reset()
+	require.NoError(t, result.Render(&buf, "json"))
+
+	var got Result
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, *result, got)
+}
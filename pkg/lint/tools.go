@@ -0,0 +1,166 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package lint
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// EnvJsonnetfmt is the environment variable which holds the path of the
+	// jsonnetfmt binary.
+	EnvJsonnetfmt = "KS_JSONNETFMT"
+	// EnvJsonnetLint is the environment variable which holds the path of the
+	// jsonnet-lint binary.
+	EnvJsonnetLint = "KS_JSONNET_LINT"
+
+	defaultJsonnetfmt  = "jsonnetfmt"
+	defaultJsonnetLint = "jsonnet-lint"
+)
+
+// lintLine matches problems reported by jsonnet-lint, e.g.
+// `components/guestbook.jsonnet:3:7-10 Unused variable: x`.
+var lintLine = regexp.MustCompile(`^(.+?):(\d+):(\d+)(?:-\S+)?\s+(.+)$`)
+
+// failure is a tool which ran, and reported a problem with a file.
+type failure struct {
+	output string
+}
+
+func (f *failure) Error() string {
+	return f.output
+}
+
+// JsonnetTools formats and lints files with the jsonnetfmt and jsonnet-lint
+// binaries.
+type JsonnetTools struct {
+	fmtProgram  string
+	lintProgram string
+	runFn       func(*exec.Cmd) ([]byte, error)
+}
+
+var _ Tools = (*JsonnetTools)(nil)
+
+// NewJsonnetTools creates an instance of JsonnetTools. The binaries are found
+// in $KS_JSONNETFMT and $KS_JSONNET_LINT, or the path.
+func NewJsonnetTools() *JsonnetTools {
+	fmtProgram := os.Getenv(EnvJsonnetfmt)
+	if fmtProgram == "" {
+		fmtProgram = defaultJsonnetfmt
+	}
+
+	lintProgram := os.Getenv(EnvJsonnetLint)
+	if lintProgram == "" {
+		lintProgram = defaultJsonnetLint
+	}
+
+	return &JsonnetTools{
+		fmtProgram:  fmtProgram,
+		lintProgram: lintProgram,
+		runFn:       runCmd,
+	}
+}
+
+// Format returns the formatted contents of a file.
+func (jt *JsonnetTools) Format(path string) ([]byte, error) {
+	out, err := jt.runFn(exec.Command(jt.fmtProgram, path))
+	if err != nil {
+		return nil, notFound(err, jt.fmtProgram, EnvJsonnetfmt)
+	}
+
+	return out, nil
+}
+
+// Lint returns the problems jsonnet-lint finds in a file.
+func (jt *JsonnetTools) Lint(path string, jPaths []string) ([]Diagnostic, error) {
+	var args []string
+	for _, jPath := range jPaths {
+		args = append(args, "-J", jPath)
+	}
+	args = append(args, path)
+
+	_, err := jt.runFn(exec.Command(jt.lintProgram, args...))
+	if err == nil {
+		return nil, nil
+	}
+
+	f, ok := err.(*failure)
+	if !ok {
+		return nil, notFound(err, jt.lintProgram, EnvJsonnetLint)
+	}
+
+	var diagnostics []Diagnostic
+	for _, line := range strings.Split(f.output, "\n") {
+		m := lintLine.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+
+		lineNo, _ := strconv.Atoi(m[2])
+		column, _ := strconv.Atoi(m[3])
+		diagnostics = append(diagnostics, Diagnostic{
+			File:    m[1],
+			Line:    lineNo,
+			Column:  column,
+			Check:   CheckLint,
+			Message: m[4],
+		})
+	}
+
+	if len(diagnostics) == 0 {
+		diagnostics = append(diagnostics, Diagnostic{
+			File:    path,
+			Check:   CheckLint,
+			Message: f.output,
+		})
+	}
+
+	return diagnostics, nil
+}
+
+// notFound explains how to install a tool which was not found.
+func notFound(err error, program, envVar string) error {
+	if execErr, ok := err.(*exec.Error); ok && execErr.Err == exec.ErrNotFound {
+		return errors.Errorf("%q was not found; install go-jsonnet (https://github.com/google/go-jsonnet) or set $%s", program, envVar)
+	}
+
+	return err
+}
+
+// runCmd runs a command, and returns its output. If the command fails, the
+// error is a failure with its output.
+func runCmd(cmd *exec.Cmd) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			output := strings.TrimSpace(stderr.String() + "\n" + string(out))
+			return nil, &failure{output: output}
+		}
+		return nil, err
+	}
+
+	return out, nil
+}
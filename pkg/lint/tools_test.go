@@ -0,0 +1,100 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package lint
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJsonnetTools_Format(t *testing.T) {
+	jt := &JsonnetTools{
+		fmtProgram: "jsonnetfmt",
+		runFn: func(cmd *exec.Cmd) ([]byte, error) {
+			assert.Equal(t, []string{"jsonnetfmt", "/app/components/a.jsonnet"}, cmd.Args)
+			return []byte("{}\n"), nil
+		},
+	}
+
+	out, err := jt.Format("/app/components/a.jsonnet")
+	require.NoError(t, err)
+	assert.Equal(t, "{}\n", string(out))
+}
+
+func TestJsonnetTools_Lint(t *testing.T) {
+	cases := []struct {
+		name     string
+		output   string
+		err      error
+		expected []Diagnostic
+		isErr    bool
+	}{
+		{
+			name: "no problems",
+		},
+		{
+			name: "problems",
+			err: &failure{output: `/app/components/a.jsonnet:3:7-10 Unused variable: x
+
+local x = 1;
+
+/app/components/a.jsonnet:5:3-8 Unknown variable: y
+
+Problems found!`},
+			expected: []Diagnostic{
+				{File: "/app/components/a.jsonnet", Line: 3, Column: 7, Check: CheckLint, Message: "Unused variable: x"},
+				{File: "/app/components/a.jsonnet", Line: 5, Column: 3, Check: CheckLint, Message: "Unknown variable: y"},
+			},
+		},
+		{
+			name: "unrecognized output",
+			err:  &failure{output: "couldn't open import"},
+			expected: []Diagnostic{
+				{File: "/app/components/a.jsonnet", Check: CheckLint, Message: "couldn't open import"},
+			},
+		},
+		{
+			name:  "missing jsonnet-lint",
+			err:   &exec.Error{Name: "jsonnet-lint", Err: exec.ErrNotFound},
+			isErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			jt := &JsonnetTools{
+				lintProgram: "jsonnet-lint",
+				runFn: func(cmd *exec.Cmd) ([]byte, error) {
+					assert.Equal(t, []string{"jsonnet-lint", "-J", "/app/lib", "-J", "/app/vendor", "/app/components/a.jsonnet"}, cmd.Args)
+					return nil, tc.err
+				},
+			}
+
+			diagnostics, err := jt.Lint("/app/components/a.jsonnet", []string{"/app/lib", "/app/vendor"})
+			if tc.isErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "KS_JSONNET_LINT")
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, diagnostics)
+		})
+	}
+}
@@ -24,18 +24,30 @@ import (
 var (
 	// VerbosityLevel is the current verbosity level.
 	VerbosityLevel = 0
+
+	filter = &levelFilter{}
 )
 
 // Init initializes ksonnet's logger.
 func Init(verbosity int, w io.Writer) {
+	filter.formatter = defaultLogFmt()
+	filter.level = logLevel(verbosity)
+	filter.levels = nil
+
 	logrus.SetOutput(w)
-	logrus.SetFormatter(defaultLogFmt())
-	logrus.SetLevel(logLevel(verbosity))
+	logrus.SetFormatter(filter)
+	logrus.SetLevel(filter.maxLevel())
 	VerbosityLevel = verbosity
 
 	logrus.WithField("verbosity-level", verbosity).Debug("setting log verbosity")
 }
 
+// UseJSON formats log entries as JSON, one entry per line.
+func UseJSON() {
+	filter.formatter = &logrus.JSONFormatter{}
+	logrus.SetFormatter(filter)
+}
+
 func defaultLogFmt() logrus.Formatter {
 	return &logrus.TextFormatter{
 		DisableTimestamp:       true,
@@ -0,0 +1,134 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package log
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// FieldSubsystem is the log field which holds the subsystem of an entry.
+	FieldSubsystem = "subsystem"
+
+	// SubsystemCluster is the subsystem which talks to clusters.
+	SubsystemCluster = "cluster"
+	// SubsystemEvaluation is the subsystem which evaluates components.
+	SubsystemEvaluation = "evaluation"
+	// SubsystemRegistry is the subsystem which fetches registries and packages.
+	SubsystemRegistry = "registry"
+
+	// EnvLevels is the environment variable which holds log levels, in the
+	// form of --log-level.
+	EnvLevels = "KS_LOG_LEVEL"
+)
+
+var subsystems = []string{SubsystemCluster, SubsystemEvaluation, SubsystemRegistry}
+
+// For returns the logger of a subsystem. Its entries are logged at the
+// level set for the subsystem.
+func For(subsystem string) *logrus.Entry {
+	return logrus.WithField(FieldSubsystem, subsystem)
+}
+
+// Levels are the log levels of subsystems. The level of the empty subsystem
+// is the level of every other entry.
+type Levels map[string]logrus.Level
+
+// ParseLevels parses log levels of the form <subsystem>=<level>, e.g.
+// registry=debug. A level on its own, e.g. warn, sets the level of every
+// other entry. A later level for a subsystem overrides an earlier one.
+func ParseLevels(specs []string) (Levels, error) {
+	levels := Levels{}
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		subsystem, name := "", spec
+		if i := strings.Index(spec, "="); i >= 0 {
+			subsystem, name = spec[:i], spec[i+1:]
+			if !isSubsystem(subsystem) {
+				return nil, errors.Errorf("unknown log subsystem %q; valid subsystems are %s",
+					subsystem, strings.Join(subsystems, ", "))
+			}
+		}
+
+		level, err := logrus.ParseLevel(name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "log level %q", spec)
+		}
+		levels[subsystem] = level
+	}
+
+	return levels, nil
+}
+
+// SetLevels sets the log levels of subsystems.
+func SetLevels(levels Levels) {
+	if level, ok := levels[""]; ok {
+		filter.level = level
+	}
+	filter.levels = levels
+
+	logrus.SetLevel(filter.maxLevel())
+}
+
+func isSubsystem(name string) bool {
+	i := sort.SearchStrings(subsystems, name)
+	return i < len(subsystems) && subsystems[i] == name
+}
+
+// levelFilter is a formatter which drops entries below the level of their
+// subsystem. The logger's level is the most verbose of the levels, so every
+// entry which may be logged reaches the filter.
+type levelFilter struct {
+	formatter logrus.Formatter
+	level     logrus.Level
+	levels    Levels
+}
+
+var _ logrus.Formatter = (*levelFilter)(nil)
+
+func (f *levelFilter) Format(entry *logrus.Entry) ([]byte, error) {
+	level := f.level
+	if subsystem, ok := entry.Data[FieldSubsystem].(string); ok {
+		if l, ok := f.levels[subsystem]; ok {
+			level = l
+		}
+	}
+
+	if entry.Level > level {
+		return nil, nil
+	}
+
+	return f.formatter.Format(entry)
+}
+
+func (f *levelFilter) maxLevel() logrus.Level {
+	level := f.level
+	for _, l := range f.levels {
+		if l > level {
+			level = l
+		}
+	}
+
+	return level
+}
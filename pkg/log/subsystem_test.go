@@ -0,0 +1,96 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package log
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLevels(t *testing.T) {
+	cases := []struct {
+		name     string
+		specs    []string
+		expected Levels
+		isErr    bool
+	}{
+		{
+			name:     "subsystems",
+			specs:    []string{"registry=debug", " cluster=warn"},
+			expected: Levels{"registry": logrus.DebugLevel, "cluster": logrus.WarnLevel},
+		},
+		{
+			name:     "default level",
+			specs:    []string{"error", ""},
+			expected: Levels{"": logrus.ErrorLevel},
+		},
+		{
+			name:     "later levels take precedence",
+			specs:    []string{"registry=debug", "registry=info"},
+			expected: Levels{"registry": logrus.InfoLevel},
+		},
+		{
+			name:  "unknown subsystem",
+			specs: []string{"network=debug"},
+			isErr: true,
+		},
+		{
+			name:  "invalid level",
+			specs: []string{"registry=loud"},
+			isErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			levels, err := ParseLevels(tc.specs)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, levels)
+		})
+	}
+}
+
+func TestSetLevels(t *testing.T) {
+	var buf bytes.Buffer
+	Init(0, &buf)
+	defer Init(0, &bytes.Buffer{})
+
+	SetLevels(Levels{SubsystemRegistry: logrus.DebugLevel, SubsystemCluster: logrus.WarnLevel})
+
+	For(SubsystemRegistry).Debug("registry debug")
+	For(SubsystemCluster).Info("cluster info")
+	For(SubsystemCluster).Warn("cluster warning")
+	For(SubsystemEvaluation).Debug("evaluation debug")
+	logrus.Info("info")
+	logrus.Debug("debug")
+
+	output := buf.String()
+	assert.Contains(t, output, "registry debug")
+	assert.NotContains(t, output, "cluster info")
+	assert.Contains(t, output, "cluster warning")
+	assert.NotContains(t, output, "evaluation debug")
+	assert.Contains(t, output, `msg=info`)
+	assert.NotContains(t, output, `msg=debug`)
+}
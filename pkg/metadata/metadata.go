@@ -29,9 +29,61 @@ const (
 	// AnnotationManaged annotation holds the pristine object.
 	AnnotationManaged = "ksonnet.io/managed"
 
+	// AnnotationLastApplied annotation holds the configuration last applied
+	// by kubectl. `kubectl apply --prune` only prunes objects which have it.
+	AnnotationLastApplied = "kubectl.kubernetes.io/last-applied-configuration"
+
+	// AnnotationHook marks a Job as a hook which is run before or after the
+	// other objects are applied. Values are `pre-apply` and `post-apply`.
+	AnnotationHook = "ksonnet.io/hook"
+
+	// AnnotationHookWeight orders hooks within a phase. Lower weights run first.
+	AnnotationHookWeight = "ksonnet.io/hook-weight"
+
+	// AnnotationHookTimeout is how long to wait for a hook Job to complete.
+	AnnotationHookTimeout = "ksonnet.io/hook-timeout"
+
+	// AnnotationHookFailurePolicy is `fail` (default) to stop the apply when a
+	// hook fails, or `ignore` to continue.
+	AnnotationHookFailurePolicy = "ksonnet.io/hook-failure-policy"
+
+	// AnnotationDiffIgnore lists JSON6902 paths of fields, separated by
+	// commas, which are left out when the object is diffed.
+	AnnotationDiffIgnore = "ksonnet.io/diff-ignore"
+
+	// AnnotationGeneratedFrom holds the unhashed name of a ConfigMap or Secret
+	// built by a generator component. References to that name are rewritten
+	// to the generated name.
+	AnnotationGeneratedFrom = "ksonnet.io/generated-from"
+
+	// AnnotationDependsOn lists the components, separated by commas, which
+	// the object's component depends on.
+	AnnotationDependsOn = "ksonnet.io/depends-on"
+
+	// AnnotationSourceComponent holds the path, relative to the app root, of
+	// the component file which rendered an object.
+	AnnotationSourceComponent = "ksonnet.io/source-component"
+
+	// AnnotationSourcePrototype holds the prototype the component which
+	// rendered an object was generated from.
+	AnnotationSourcePrototype = "ksonnet.io/source-prototype"
+
+	// AnnotationSourcePackage holds the package, and its version, of the
+	// prototype the component which rendered an object was generated from.
+	AnnotationSourcePackage = "ksonnet.io/source-package"
+
 	// LabelDeployManager label signifies an object is deployed with ksonnet.
 	LabelDeployManager = "app.kubernetes.io/deploy-manager"
 
+	// LabelApplication is the default label which holds the application an
+	// object belongs to when garbage collecting by label. It is owned by
+	// ksonnet, so labels such as app.kubernetes.io/part-of are left to users.
+	LabelApplication = "ksonnet.io/application"
+
+	// LabelEnvironment is the default label which holds the environment an
+	// object belongs to when garbage collecting by label.
+	LabelEnvironment = "ksonnet.io/environment"
+
 	// LabelComponent label contains the component the component an object is
 	// created from.
 	LabelComponent = "ksonnet.io/component"
@@ -0,0 +1,234 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package openapi
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"path/filepath"
+
+	"github.com/go-openapi/spec"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/validate"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	// SchemaDirName is the directory in an app which contains the
+	// CustomResourceDefinitions used to validate custom resources.
+	SchemaDirName = "schemas"
+)
+
+// CRDSchemas holds the OpenAPI schemas of custom resources.
+type CRDSchemas struct {
+	schemas  map[schema.GroupVersionKind]*spec.Schema
+	validate func(*spec.Schema, interface{}, strfmt.Registry) error
+}
+
+// NewCRDSchemas creates an instance of CRDSchemas.
+func NewCRDSchemas() *CRDSchemas {
+	return &CRDSchemas{
+		schemas:  make(map[schema.GroupVersionKind]*spec.Schema),
+		validate: validate.AgainstSchema,
+	}
+}
+
+// AddCRD adds the schemas of each version of a CustomResourceDefinition.
+// Schemas replace the ones previously added for the same resource.
+func (s *CRDSchemas) AddCRD(crd *unstructured.Unstructured) error {
+	group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+	kind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+	if group == "" || kind == "" {
+		return errors.Errorf("CustomResourceDefinition %s does not specify a group and kind", crd.GetName())
+	}
+
+	// The top level schema applies to all versions in apiextensions.k8s.io/v1beta1.
+	common, hasCommon, _ := unstructured.NestedMap(crd.Object, "spec", "validation", "openAPIV3Schema")
+
+	versionSchemas := make(map[string]map[string]interface{})
+	if version, _, _ := unstructured.NestedString(crd.Object, "spec", "version"); version != "" && hasCommon {
+		versionSchemas[version] = common
+	}
+
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	for _, v := range versions {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name, _, _ := unstructured.NestedString(m, "name")
+		if name == "" {
+			continue
+		}
+
+		if vs, ok, _ := unstructured.NestedMap(m, "schema", "openAPIV3Schema"); ok {
+			versionSchemas[name] = vs
+		} else if hasCommon {
+			versionSchemas[name] = common
+		}
+	}
+
+	for version, raw := range versionSchemas {
+		b, err := json.Marshal(raw)
+		if err != nil {
+			return err
+		}
+
+		var sch spec.Schema
+		if err = json.Unmarshal(b, &sch); err != nil {
+			return errors.Wrapf(err, "parsing schema of %s", crd.GetName())
+		}
+
+		s.schemas[schema.GroupVersionKind{Group: group, Version: version, Kind: kind}] = &sch
+	}
+
+	return nil
+}
+
+// AddDir adds the CustomResourceDefinitions found in the JSON and YAML files
+// of a directory. A missing directory is ignored.
+func (s *CRDSchemas) AddDir(fs afero.Fs, dir string) error {
+	exists, err := afero.DirExists(fs, dir)
+	if err != nil || !exists {
+		return err
+	}
+
+	fis, err := afero.ReadDir(fs, dir)
+	if err != nil {
+		return err
+	}
+
+	for _, fi := range fis {
+		switch filepath.Ext(fi.Name()) {
+		case ".json", ".yaml", ".yml":
+		default:
+			continue
+		}
+
+		if err = s.AddFile(fs, filepath.Join(dir, fi.Name())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AddFile adds the CustomResourceDefinitions found in a JSON or YAML file.
+func (s *CRDSchemas) AddFile(fs afero.Fs, path string) error {
+	b, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return err
+	}
+
+	objects, err := decodeObjects(b)
+	if err != nil {
+		return errors.Wrapf(err, "reading %s", path)
+	}
+
+	for _, obj := range objects {
+		if obj.GetKind() != "CustomResourceDefinition" {
+			continue
+		}
+
+		if err = s.AddCRD(obj); err != nil {
+			return errors.Wrapf(err, "reading %s", path)
+		}
+	}
+
+	return nil
+}
+
+// Schemas returns the schemas of the custom resources, by their group,
+// version and kind.
+func (s *CRDSchemas) Schemas() map[schema.GroupVersionKind]*spec.Schema {
+	schemas := make(map[schema.GroupVersionKind]*spec.Schema, len(s.schemas))
+	for gvk, sch := range s.schemas {
+		schemas[gvk] = sch
+	}
+
+	return schemas
+}
+
+// Validate validates a custom resource against its schema. It returns false
+// if there is no schema for the object.
+func (s *CRDSchemas) Validate(obj *unstructured.Unstructured) ([]error, bool) {
+	return s.validateObject(obj, false)
+}
+
+// ValidateStrict validates a custom resource against its schema, and also
+// rejects fields which are not described by the schema. It returns false if
+// there is no schema for the object.
+func (s *CRDSchemas) ValidateStrict(obj *unstructured.Unstructured) ([]error, bool) {
+	return s.validateObject(obj, true)
+}
+
+func (s *CRDSchemas) validateObject(obj *unstructured.Unstructured, strict bool) ([]error, bool) {
+	sch, ok := s.schemas[obj.GroupVersionKind()]
+	if !ok {
+		return nil, false
+	}
+
+	var errs []error
+	if err := s.validate(sch, obj.Object, strfmt.Default); err != nil {
+		errs = append(errs, err)
+	}
+
+	if strict {
+		errs = append(errs, unknownFields(sch, obj.Object)...)
+	}
+
+	return errs, true
+}
+
+// decodeObjects decodes the objects in a JSON document or YAML stream.
+func decodeObjects(b []byte) ([]*unstructured.Unstructured, error) {
+	decoder := yaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(b)))
+
+	var objects []*unstructured.Unstructured
+	for {
+		doc, err := decoder.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+
+		data, err := yaml.ToJSON(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		obj := &unstructured.Unstructured{}
+		if err = obj.UnmarshalJSON(data); err != nil {
+			return nil, err
+		}
+
+		objects = append(objects, obj)
+	}
+
+	return objects, nil
+}
@@ -0,0 +1,173 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package openapi
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const certificateCRD = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ignored
+---
+apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: certificates.example.com
+spec:
+  group: example.com
+  version: v1
+  names:
+    kind: Certificate
+  validation:
+    openAPIV3Schema:
+      properties:
+        spec:
+          required: [dnsName]
+          properties:
+            dnsName:
+              type: string
+            duration:
+              type: integer
+`
+
+func newCertificate(spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Certificate",
+		"metadata":   map[string]interface{}{"name": "cert"},
+		"spec":       spec,
+	}}
+}
+
+func TestCRDSchemas_AddDir(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/app/schemas/certificates.yaml", []byte(certificateCRD), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/app/schemas/README.md", []byte("docs"), 0644))
+
+	crds := NewCRDSchemas()
+	require.NoError(t, crds.AddDir(fs, "/app/schemas"))
+
+	errs, ok := crds.Validate(newCertificate(map[string]interface{}{"dnsName": "example.com", "duration": int64(90)}))
+	require.True(t, ok)
+	assert.Empty(t, errs)
+
+	errs, ok = crds.Validate(newCertificate(map[string]interface{}{"duration": "90d"}))
+	require.True(t, ok)
+	assert.NotEmpty(t, errs)
+
+	_, ok = crds.Validate(&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v2",
+		"kind":       "Certificate",
+	}})
+	assert.False(t, ok)
+}
+
+func TestCRDSchemas_ValidateStrict(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/certificates.yaml", []byte(certificateCRD), 0644))
+
+	crds := NewCRDSchemas()
+	require.NoError(t, crds.AddFile(fs, "/certificates.yaml"))
+
+	cert := newCertificate(map[string]interface{}{"dnsName": "example.com", "durations": int64(90)})
+
+	errs, ok := crds.Validate(cert)
+	require.True(t, ok)
+	assert.Empty(t, errs)
+
+	errs, ok = crds.ValidateStrict(cert)
+	require.True(t, ok)
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], `unknown field "spec.durations"`)
+}
+
+func TestCRDSchemas_AddDir_missing(t *testing.T) {
+	crds := NewCRDSchemas()
+	require.NoError(t, crds.AddDir(afero.NewMemMapFs(), "/app/schemas"))
+}
+
+func TestCRDSchemas_AddFile(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/certificates.yaml", []byte(certificateCRD), 0644))
+
+	crds := NewCRDSchemas()
+	require.NoError(t, crds.AddFile(fs, "/certificates.yaml"))
+
+	schemas := crds.Schemas()
+	require.Len(t, schemas, 1)
+	sch, ok := schemas[schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Certificate"}]
+	require.True(t, ok)
+	assert.Contains(t, sch.Properties, "spec")
+
+	require.Error(t, crds.AddFile(fs, "/missing.yaml"))
+}
+
+func TestCRDSchemas_AddCRD_versions(t *testing.T) {
+	crd := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": "certificates.example.com"},
+		"spec": map[string]interface{}{
+			"group": "example.com",
+			"names": map[string]interface{}{"kind": "Certificate"},
+			"versions": []interface{}{
+				map[string]interface{}{
+					"name": "v1",
+					"schema": map[string]interface{}{
+						"openAPIV3Schema": map[string]interface{}{
+							"properties": map[string]interface{}{
+								"spec": map[string]interface{}{"type": "object"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}}
+
+	crds := NewCRDSchemas()
+	require.NoError(t, crds.AddCRD(crd))
+
+	errs, ok := crds.Validate(newCertificate(map[string]interface{}{}))
+	require.True(t, ok)
+	assert.Empty(t, errs)
+
+	invalid := newCertificate(nil)
+	invalid.Object["spec"] = "invalid"
+	errs, ok = crds.Validate(invalid)
+	require.True(t, ok)
+	assert.NotEmpty(t, errs)
+}
+
+func TestCRDSchemas_AddCRD_invalid(t *testing.T) {
+	crd := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1beta1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": "invalid"},
+	}}
+
+	crds := NewCRDSchemas()
+	require.Error(t, crds.AddCRD(crd))
+}
@@ -40,10 +40,19 @@ func ValidateAgainstSchema(a app.App, obj *unstructured.Unstructured, envName st
 	return v.run(a, obj, envName)
 }
 
+// ValidateAgainstSchemaStrict validates a document against the schema, and
+// also rejects fields which are not described by the schema.
+func ValidateAgainstSchemaStrict(a app.App, obj *unstructured.Unstructured, envName string) []error {
+	v := newValidateAgainstSchema()
+	v.strict = true
+	return v.run(a, obj, envName)
+}
+
 type validateAgainstSchema struct {
 	definitionName func(*unstructured.Unstructured) (string, error)
 	loadSchema     func(app.App, string, string) (*spec.Schema, error)
 	validate       func(*spec.Schema, interface{}, strfmt.Registry) error
+	strict         bool
 }
 
 func newValidateAgainstSchema() *validateAgainstSchema {
@@ -69,11 +78,16 @@ func (v *validateAgainstSchema) run(a app.App, obj *unstructured.Unstructured, e
 		return []error{err}
 	}
 
+	var errs []error
 	if err := v.validate(schema, obj.Object, strfmt.Default); err != nil {
-		return []error{err}
+		errs = append(errs, err)
+	}
+
+	if v.strict {
+		errs = append(errs, unknownFields(schema, obj.Object)...)
 	}
 
-	return nil
+	return errs
 }
 
 func definitionName(obj *unstructured.Unstructured) (string, error) {
@@ -99,7 +113,7 @@ func definitionName(obj *unstructured.Unstructured) (string, error) {
 			logrus.WithFields(logrus.Fields{
 				"kind":       kind,
 				"apiVersion": parts[0],
-			}).Warn("ksonnet currently does not support CRDs")
+			}).Warn("skipping validation of custom resource without a schema")
 
 			return "", errUnsupportedDefinition
 		}
@@ -65,6 +65,42 @@ func TestValidateAgainstSchema(t *testing.T) {
 	})
 }
 
+func TestValidateAgainstSchema_strict(t *testing.T) {
+	test.WithApp(t, "/", func(a *mocks.App, fs afero.Fs) {
+		obj := &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"spec":       map[string]interface{}{"replica": int64(3)},
+			},
+		}
+
+		deploymentSpec := spec.Schema{}
+		deploymentSpec.Properties = map[string]spec.Schema{"replicas": *spec.Int32Property()}
+		schema := &spec.Schema{}
+		schema.Properties = map[string]spec.Schema{"spec": deploymentSpec}
+
+		v := validateAgainstSchema{
+			definitionName: func(*unstructured.Unstructured) (string, error) {
+				return "name", nil
+			},
+			loadSchema: func(app.App, string, string) (*spec.Schema, error) {
+				return schema, nil
+			},
+			validate: func(*spec.Schema, interface{}, strfmt.Registry) error {
+				return nil
+			},
+		}
+
+		require.Empty(t, v.run(a, obj, "default"))
+
+		v.strict = true
+		errs := v.run(a, obj, "default")
+		require.Len(t, errs, 1)
+		require.EqualError(t, errs[0], `unknown field "spec.replica"`)
+	})
+}
+
 func Test_definitionName(t *testing.T) {
 	cases := []struct {
 		name         string
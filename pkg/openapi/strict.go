@@ -0,0 +1,123 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package openapi
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/go-openapi/spec"
+	"github.com/pkg/errors"
+)
+
+// preserveUnknownFieldsExtension marks a schema whose objects may contain
+// fields it does not describe.
+const preserveUnknownFieldsExtension = "x-kubernetes-preserve-unknown-fields"
+
+// rootFields are accepted at the top level of every object, since the schemas
+// of custom resources often leave them out.
+var rootFields = map[string]bool{
+	"apiVersion": true,
+	"kind":       true,
+	"metadata":   true,
+}
+
+// unknownFields returns an error for each field of an object which is not
+// described by its schema. Schemas which don't list properties, or which allow
+// additional properties, accept any field.
+func unknownFields(sch *spec.Schema, obj map[string]interface{}) []error {
+	var errs []error
+	walkUnknownFields(sch, obj, "", &errs)
+	return errs
+}
+
+func walkUnknownFields(sch *spec.Schema, value interface{}, path string, errs *[]error) {
+	if sch == nil {
+		return
+	}
+
+	if preserve, ok := sch.Extensions.GetBool(preserveUnknownFieldsExtension); ok && preserve {
+		return
+	}
+
+	switch t := value.(type) {
+	case map[string]interface{}:
+		properties := schemaProperties(sch)
+		if len(properties) == 0 {
+			return
+		}
+
+		var additional *spec.Schema
+		if sch.AdditionalProperties != nil {
+			if sch.AdditionalProperties.Schema == nil && sch.AdditionalProperties.Allows {
+				return
+			}
+			additional = sch.AdditionalProperties.Schema
+		}
+
+		var keys []string
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			fieldPath := k
+			if path != "" {
+				fieldPath = path + "." + k
+			}
+
+			prop, ok := properties[k]
+			switch {
+			case ok:
+				walkUnknownFields(&prop, t[k], fieldPath, errs)
+			case additional != nil:
+				walkUnknownFields(additional, t[k], fieldPath, errs)
+			case path == "" && rootFields[k]:
+			default:
+				*errs = append(*errs, errors.Errorf("unknown field %q", fieldPath))
+			}
+		}
+	case []interface{}:
+		if sch.Items == nil || sch.Items.Schema == nil {
+			return
+		}
+
+		for i := range t {
+			walkUnknownFields(sch.Items.Schema, t[i], fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	}
+}
+
+// schemaProperties returns the properties of a schema, including the ones of
+// the schemas it is composed of with allOf.
+func schemaProperties(sch *spec.Schema) map[string]spec.Schema {
+	if len(sch.AllOf) == 0 {
+		return sch.Properties
+	}
+
+	properties := make(map[string]spec.Schema)
+	for k, v := range sch.Properties {
+		properties[k] = v
+	}
+	for i := range sch.AllOf {
+		for k, v := range schemaProperties(&sch.AllOf[i]) {
+			properties[k] = v
+		}
+	}
+
+	return properties
+}
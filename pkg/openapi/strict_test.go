@@ -0,0 +1,112 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package openapi
+
+import (
+	"testing"
+
+	"github.com/go-openapi/spec"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_unknownFields(t *testing.T) {
+	container := spec.Schema{}
+	container.Properties = map[string]spec.Schema{
+		"name":  *spec.StringProperty(),
+		"image": *spec.StringProperty(),
+	}
+
+	preserved := spec.Schema{}
+	preserved.Properties = map[string]spec.Schema{
+		"known": *spec.StringProperty(),
+	}
+	preserved.AddExtension(preserveUnknownFieldsExtension, true)
+
+	composed := spec.Schema{}
+	composed.AllOf = []spec.Schema{
+		{SchemaProps: spec.SchemaProps{Properties: map[string]spec.Schema{"first": *spec.StringProperty()}}},
+		{SchemaProps: spec.SchemaProps{Properties: map[string]spec.Schema{"second": *spec.StringProperty()}}},
+	}
+
+	podSpec := spec.Schema{}
+	podSpec.Properties = map[string]spec.Schema{
+		"containers": *spec.ArrayProperty(&container),
+		"labels":     *spec.MapProperty(spec.StringProperty()),
+		"config":     {},
+		"preserved":  preserved,
+		"composed":   composed,
+	}
+
+	sch := &spec.Schema{}
+	sch.Properties = map[string]spec.Schema{
+		"replicas": *spec.Int32Property(),
+		"spec":     podSpec,
+	}
+
+	cases := []struct {
+		name     string
+		obj      map[string]interface{}
+		expected []string
+	}{
+		{
+			name: "known fields",
+			obj: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Pod",
+				"metadata":   map[string]interface{}{"name": "pod"},
+				"replicas":   int64(1),
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "app", "image": "nginx"},
+					},
+					"labels":    map[string]interface{}{"app": "nginx"},
+					"config":    map[string]interface{}{"anything": true},
+					"preserved": map[string]interface{}{"unknown": "value"},
+					"composed":  map[string]interface{}{"first": "1", "second": "2"},
+				},
+			},
+		},
+		{
+			name: "unknown fields",
+			obj: map[string]interface{}{
+				"replica": int64(1),
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "app"},
+						map[string]interface{}{"name": "sidecar", "imag": "nginx"},
+					},
+					"composed": map[string]interface{}{"third": "3"},
+				},
+			},
+			expected: []string{
+				`unknown field "replica"`,
+				`unknown field "spec.composed.third"`,
+				`unknown field "spec.containers[1].imag"`,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, err := range unknownFields(sch, tc.obj) {
+				got = append(got, err.Error())
+			}
+
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
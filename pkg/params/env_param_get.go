@@ -0,0 +1,91 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package params
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/ksonnet/ksonnet-lib/ksonnet-gen/astext"
+	"github.com/ksonnet/ksonnet/pkg/util/jsonnet"
+	"github.com/pkg/errors"
+)
+
+// EnvParamGet gets the params a component overrides in env params libsonnet
+// files.
+type EnvParamGet struct {
+}
+
+// NewEnvParamGet creates an instance of EnvParamGet.
+func NewEnvParamGet() *EnvParamGet {
+	return &EnvParamGet{}
+}
+
+// Get returns the Jsonnet source of the values of the params a component
+// overrides. It returns an empty map if the component is not overridden.
+func (epg *EnvParamGet) Get(componentName, snippet string) (map[string]string, error) {
+	if componentName == "" {
+		return nil, errors.New("component name was blank")
+	}
+
+	n, err := jsonnet.ParseNode("params.libsonnet", snippet)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := componentParams(n, componentName)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+
+	of, err := findField(obj, "components")
+	if err != nil {
+		return nil, errors.Wrap(errUnsupportedEnvParams, "unable to find components field")
+	}
+
+	componentsObj, ok := of.Expr2.(*astext.Object)
+	if !ok {
+		return nil, errors.Wrap(errUnsupportedEnvParams, "components field is not an object")
+	}
+
+	of, err = findField(componentsObj, componentName)
+	if err != nil {
+		return values, nil
+	}
+
+	componentObj, ok := of.Expr2.(*astext.Object)
+	if !ok {
+		return nil, errors.Wrapf(errUnsupportedEnvParams, "component field %q is not an object", componentName)
+	}
+
+	for _, field := range componentObj.Fields {
+		id, err := jsonnet.FieldID(field)
+		if err != nil {
+			return nil, err
+		}
+
+		var buf bytes.Buffer
+		if err = jsonnetPrinterFn(&buf, field.Expr2); err != nil {
+			return nil, errors.Wrapf(err, "printing param %q", id)
+		}
+
+		values[id] = strings.TrimSpace(buf.String())
+	}
+
+	return values, nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package params
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvParamGet(t *testing.T) {
+	cases := []struct {
+		name          string
+		input         string
+		componentName string
+		expected      map[string]string
+	}{
+		{
+			name:          "overridden",
+			input:         filepath.Join("env", "globals", "unset", "in.libsonnet"),
+			componentName: "guestbook",
+			expected: map[string]string{
+				"name":     `'guestbook-dev'`,
+				"replicas": "params.global.replicas",
+			},
+		},
+		{
+			name:          "not overridden",
+			input:         filepath.Join("env", "globals", "unset", "in.libsonnet"),
+			componentName: "missing",
+			expected:      map[string]string{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			snippet := test.ReadTestData(t, tc.input)
+
+			epg := NewEnvParamGet()
+
+			got, err := epg.Get(tc.componentName, snippet)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
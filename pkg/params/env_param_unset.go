@@ -24,6 +24,10 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// ErrComponentNotOverridden is returned when unsetting a param for a component
+// which has no params in the environment.
+var ErrComponentNotOverridden = errors.New("component has no environment params")
+
 // EnvParamUnset unset param configuration for components
 // from env params libsonnet files.
 type EnvParamUnset struct {
@@ -79,7 +83,7 @@ func (epu *EnvParamUnset) unsetEntry(obj *astext.Object, componentName, paramNam
 
 	of, err = findField(componentsObj, componentName)
 	if err != nil {
-		return errors.Wrapf(errUnsupportedEnvParams, "unable to find component %q field", componentName)
+		return errors.Wrapf(ErrComponentNotOverridden, "unable to find component %q field", componentName)
 	}
 
 	componentObj, ok := of.Expr2.(*astext.Object)
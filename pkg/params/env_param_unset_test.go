@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -58,3 +59,13 @@ func TestEnvParamRemover(t *testing.T) {
 		})
 	}
 }
+
+func TestEnvParamRemover_component_not_overridden(t *testing.T) {
+	snippet := test.ReadTestData(t, filepath.Join("env", "no-globals", "unset", "in.libsonnet"))
+
+	epu := NewEnvParamUnset()
+
+	_, err := epu.Unset("missing", "replicas", snippet)
+	require.Error(t, err)
+	require.Equal(t, ErrComponentNotOverridden, errors.Cause(err))
+}
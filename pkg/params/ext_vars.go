@@ -30,9 +30,10 @@ func JsonnetEnvObject(a app.App, envName string) (string, error) {
 		return "", err
 	}
 
+	destination := envDetails.PrimaryDestination()
 	dest := map[string]string{
-		"server":    envDetails.Destination.Server,
-		"namespace": envDetails.Destination.Namespace,
+		"server":    destination.Server,
+		"namespace": destination.Namespace,
 	}
 
 	marshalledDestination, err := json.Marshal(&dest)
@@ -0,0 +1,44 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package params
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJsonnetEnvObject_only_destinations(t *testing.T) {
+	test.WithApp(t, "/app", func(a *mocks.App, fs afero.Fs) {
+		envConfig := &app.EnvironmentConfig{
+			Destinations: []*app.EnvironmentDestinationSpec{
+				{Name: "us", Namespace: "prod", Server: "http://us.example.com"},
+				{Name: "eu", Namespace: "prod", Server: "http://eu.example.com"},
+			},
+		}
+		a.On("Environment", "prod").Return(envConfig, nil)
+
+		got, err := JsonnetEnvObject(a, "prod")
+		require.NoError(t, err)
+
+		assert.Equal(t, `{"namespace":"prod","server":"http://us.example.com"}`, got)
+	})
+}
@@ -0,0 +1,300 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package params
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/google/go-jsonnet/ast"
+	"github.com/ksonnet/ksonnet-lib/ksonnet-gen/astext"
+	"github.com/ksonnet/ksonnet/pkg/util/jsonnet"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrFormatDropsComments is returned when a params file can not be
+	// formatted without losing comments, e.g. comments in the params of a
+	// component which has no params, since it is removed.
+	ErrFormatDropsComments = errors.New("formatting would drop comments")
+)
+
+// Format normalizes a params.libsonnet file, either a module's params or an
+// environment's, so edits produce small diffs. The params of the
+// `components` and `global` objects are sorted by key, components without
+// params are removed, and the file is printed with consistent quoting.
+// Comments in empty objects, such as the ones in the params ksonnet
+// generates, are kept.
+func Format(src string) (string, error) {
+	n, err := jsonnetParseFn("params.libsonnet", src)
+	if err != nil {
+		return "", errors.Wrap(err, "parse jsonnet")
+	}
+
+	obj, err := componentParams(n, "")
+	if err != nil {
+		return "", err
+	}
+
+	// The parser only keeps comments which precede a field, so comments in
+	// empty objects are collected from the source before it is formatted.
+	emptyComments := make(map[string][]string)
+	collectEmptyObjects(obj, "", func(path string, child *astext.Object) {
+		if comments := commentLines(src, child); len(comments) > 0 {
+			emptyComments[path] = comments
+		}
+	})
+
+	for i := range obj.Fields {
+		id, err := jsonnet.FieldID(obj.Fields[i])
+		if err != nil || (id != "components" && id != "global") {
+			continue
+		}
+
+		child, ok := obj.Fields[i].Expr2.(*astext.Object)
+		if !ok {
+			continue
+		}
+
+		sortFields(child)
+		if id == "components" {
+			removeEmptyFields(child)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err = jsonnetPrinterFn(&buf, n); err != nil {
+		return "", errors.Wrap(err, "print params")
+	}
+
+	formatted, err := restoreEmptyComments(buf.String(), emptyComments)
+	if err != nil {
+		return "", err
+	}
+
+	if !keepsComments(src, formatted) {
+		return "", ErrFormatDropsComments
+	}
+
+	return formatted, nil
+}
+
+// restoreEmptyComments adds comments to the empty objects of formatted, keyed
+// by their paths.
+func restoreEmptyComments(formatted string, emptyComments map[string][]string) (string, error) {
+	if len(emptyComments) == 0 {
+		return formatted, nil
+	}
+
+	n, err := jsonnetParseFn("params.libsonnet", formatted)
+	if err != nil {
+		return "", errors.Wrap(err, "parse formatted params")
+	}
+
+	obj, err := componentParams(n, "")
+	if err != nil {
+		return "", err
+	}
+
+	// Objects are expanded from the last line up, so earlier locations are
+	// still valid.
+	type expansion struct {
+		loc      ast.Location
+		comments []string
+	}
+	var expansions []expansion
+	collectEmptyObjects(obj, "", func(path string, child *astext.Object) {
+		if comments, ok := emptyComments[path]; ok {
+			expansions = append(expansions, expansion{loc: child.Loc().Begin, comments: comments})
+		}
+	})
+	sort.Slice(expansions, func(i, j int) bool {
+		a, b := expansions[i].loc, expansions[j].loc
+		return a.Line > b.Line || (a.Line == b.Line && a.Column > b.Column)
+	})
+
+	lines := strings.Split(formatted, "\n")
+	for _, e := range expansions {
+		i, col := e.loc.Line-1, e.loc.Column-1
+		if i < 0 || i >= len(lines) || col < 0 || col > len(lines[i]) || !strings.HasPrefix(lines[i][col:], "{}") {
+			return "", errors.Errorf("formatted params have no empty object at %d:%d", e.loc.Line, e.loc.Column)
+		}
+
+		line := lines[i]
+		indent := line[:len(line)-len(strings.TrimLeft(line, " "))]
+
+		expanded := []string{line[:col+1]}
+		for _, comment := range e.comments {
+			expanded = append(expanded, indent+"  "+comment)
+		}
+		expanded = append(expanded, indent+line[col+1:])
+
+		lines = append(lines[:i], append(expanded, lines[i+1:]...)...)
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// collectEmptyObjects calls fn with the empty objects nested in obj by named
+// fields, and their dotted paths.
+func collectEmptyObjects(obj *astext.Object, prefix string, fn func(string, *astext.Object)) {
+	for _, field := range obj.Fields {
+		child, ok := field.Expr2.(*astext.Object)
+		if !ok || !isNamedField(field) {
+			continue
+		}
+
+		id, _ := jsonnet.FieldID(field)
+		path := prefix + id
+		if len(child.Fields) == 0 {
+			fn(path, child)
+			continue
+		}
+		collectEmptyObjects(child, path+".", fn)
+	}
+}
+
+// commentLines returns the comment lines of src within an object, without
+// indentation.
+func commentLines(src string, obj *astext.Object) []string {
+	loc := obj.Loc()
+	if loc == nil {
+		return nil
+	}
+
+	var comments []string
+	lines := strings.Split(src, "\n")
+	for i := loc.Begin.Line; i < loc.End.Line-1 && i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(line, "//") || strings.HasPrefix(line, "#") {
+			comments = append(comments, line)
+		}
+	}
+
+	return comments
+}
+
+// keepsComments reports if every comment line in src is in formatted.
+func keepsComments(src, formatted string) bool {
+	comments := make(map[string]int)
+	for _, line := range strings.Split(formatted, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "//") || strings.HasPrefix(line, "#") {
+			comments[line]++
+		}
+	}
+
+	for _, line := range strings.Split(src, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "//") && !strings.HasPrefix(line, "#") {
+			continue
+		}
+		if comments[line] == 0 {
+			return false
+		}
+		comments[line]--
+	}
+
+	return true
+}
+
+// removeEmptyFields removes the fields of an object whose values are empty
+// objects. A comment on the first field stays at the top.
+func removeEmptyFields(obj *astext.Object) {
+	if len(obj.Fields) == 0 {
+		return
+	}
+
+	header := obj.Fields[0].Comment
+
+	var fields []astext.ObjectField
+	for _, field := range obj.Fields {
+		if child, ok := field.Expr2.(*astext.Object); ok && len(child.Fields) == 0 && isNamedField(field) {
+			continue
+		}
+		fields = append(fields, field)
+	}
+
+	if len(fields) > 0 {
+		fields[0].Comment = header
+	}
+	obj.Fields = fields
+}
+
+// sortFields sorts the named fields of an object, and the objects nested in
+// it, by key. Locals, asserts, and computed fields are kept first, in their
+// original order. A comment on the first field describes the object, so it
+// stays at the top.
+func sortFields(obj *astext.Object) {
+	if len(obj.Fields) == 0 {
+		return
+	}
+
+	header := obj.Fields[0].Comment
+	obj.Fields[0].Comment = nil
+
+	var named, other []astext.ObjectField
+	for _, field := range obj.Fields {
+		sortNode(field.Expr2)
+		quoteFieldID(&field)
+
+		if isNamedField(field) {
+			named = append(named, field)
+			continue
+		}
+		other = append(other, field)
+	}
+
+	sort.SliceStable(named, func(i, j int) bool {
+		a, _ := jsonnet.FieldID(named[i])
+		b, _ := jsonnet.FieldID(named[j])
+		return a < b
+	})
+
+	obj.Fields = append(other, named...)
+	obj.Fields[0].Comment = header
+}
+
+// quoteFieldID prefers double quotes for field names which need quoting, as
+// ksonnet does for the fields it creates.
+func quoteFieldID(field *astext.ObjectField) {
+	lit, ok := field.Expr1.(*ast.LiteralString)
+	if ok && lit.Kind == ast.StringSingle && !strings.ContainsRune(lit.Value, '"') {
+		lit.Kind = ast.StringDouble
+	}
+}
+
+func sortNode(node ast.Node) {
+	switch t := node.(type) {
+	case *astext.Object:
+		sortFields(t)
+	case *ast.Array:
+		for _, element := range t.Elements {
+			sortNode(element)
+		}
+	}
+}
+
+func isNamedField(field astext.ObjectField) bool {
+	switch field.Kind {
+	case ast.ObjectFieldID, ast.ObjectFieldStr:
+		_, err := jsonnet.FieldID(field)
+		return err == nil
+	default:
+		return false
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package params
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	cases := []struct {
+		name     string
+		src      string
+		expected string
+		err      error
+		isErr    bool
+	}{
+		{
+			name: "module params",
+			src: `{
+  global: {
+    // User-defined global parameters
+    zeta: "z",
+    alpha: 1,
+  },
+  components: {
+    // Component-level parameters
+    'web-ui': {
+      replicas: 2,
+      "image": "nginx",
+      env: [{b: 1, a: 2}],
+    },
+    empty: {},
+    db: {
+      name: "db",
+    },
+  },
+}`,
+			expected: `{
+  global: {
+    // User-defined global parameters
+    alpha: 1,
+    zeta: 'z',
+  },
+  components: {
+    // Component-level parameters
+    db: {
+      name: 'db',
+    },
+    "web-ui": {
+      env: [{ a: 2, b: 1 }],
+      image: 'nginx',
+      replicas: 2,
+    },
+  },
+}`,
+		},
+		{
+			name: "environment params",
+			src: `local params = import '../../components/params.libsonnet';
+
+params + {
+  components +: {
+    zed +: {
+      foo: "bar",
+    },
+    component1 +: {},
+    alpha +: { b: 1, a: 2 },
+  },
+}
+`,
+			expected: `local params = import '../../components/params.libsonnet';
+
+params + {
+  components+: {
+    alpha+: { a: 2, b: 1 },
+    zed+: {
+      foo: 'bar',
+    },
+  },
+}`,
+		},
+		{
+			name: "comments in empty objects",
+			src: `{
+  global: {
+    // User-defined global parameters, Ex:
+    // replicas: 4,
+  },
+  components: {
+    # Component-level parameters
+  },
+}`,
+			expected: `{
+  global: {
+    // User-defined global parameters, Ex:
+    // replicas: 4,
+  },
+  components: {
+    # Component-level parameters
+  },
+}`,
+		},
+		{
+			name: "comments in empty component params",
+			src: `{
+  global: {},
+  components: {
+    web: {
+      // replicas: 2,
+    },
+    db: { name: "db" },
+  },
+}`,
+			err: ErrFormatDropsComments,
+		},
+		{
+			name:  "invalid jsonnet",
+			src:   "{",
+			isErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Format(tc.src)
+			if tc.err != nil {
+				require.Equal(t, tc.err, err)
+				return
+			}
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected, got)
+
+			again, err := Format(got)
+			require.NoError(t, err)
+			assert.Equal(t, got, again, "formatting is not stable")
+		})
+	}
+}
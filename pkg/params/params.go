@@ -19,6 +19,7 @@ import (
 	"bytes"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/google/go-jsonnet/ast"
@@ -86,12 +87,17 @@ func DeleteFromObject(fieldPath []string, paramsData, key, root string) (string,
 	cur := props
 
 	for i, k := range fieldPath {
+		v, ok := cur[k]
+		if !ok {
+			return "", newPathNotFoundError(fieldPath[:i+1], cur)
+		}
+
 		if i == len(fieldPath)-1 {
 			delete(cur, k)
 		} else {
-			m, ok := cur[k].(map[string]interface{})
+			m, ok := v.(map[string]interface{})
 			if !ok {
-				return "", errors.New("path not found")
+				return "", &PathNotFoundError{Path: fieldPath[:i+2]}
 			}
 
 			cur = m
@@ -106,6 +112,31 @@ func DeleteFromObject(fieldPath []string, paramsData, key, root string) (string,
 	return updateFn(updatePath, paramsData, props)
 }
 
+// PathNotFoundError is returned when a parameter path does not exist.
+type PathNotFoundError struct {
+	// Path is the path up to and including the first missing key.
+	Path []string
+	// Siblings are the keys which exist alongside the missing key.
+	Siblings []string
+}
+
+func newPathNotFoundError(path []string, m map[string]interface{}) *PathNotFoundError {
+	var siblings []string
+	for k := range m {
+		siblings = append(siblings, k)
+	}
+	sort.Strings(siblings)
+
+	return &PathNotFoundError{
+		Path:     append([]string{}, path...),
+		Siblings: siblings,
+	}
+}
+
+func (e *PathNotFoundError) Error() string {
+	return fmt.Sprintf("path %q not found", strings.Join(e.Path, "."))
+}
+
 // update updates a params file with the params for a component.
 func update(path []string, src string, params map[string]interface{}) (string, error) {
 	n, err := jsonnetParseFn("params.libsonnet", src)
@@ -222,6 +222,45 @@ func Test_DeleteFromObject(t *testing.T) {
 	})
 }
 
+func Test_DeleteFromObject_missing_path(t *testing.T) {
+	withParamConfig(t, func() {
+		cases := []struct {
+			name      string
+			fieldPath []string
+			expected  *PathNotFoundError
+		}{
+			{
+				name:      "missing key",
+				fieldPath: []string{"replcas"},
+				expected: &PathNotFoundError{
+					Path:     []string{"replcas"},
+					Siblings: []string{"containerPort", "image", "name", "replicas", "servicePort", "type"},
+				},
+			},
+			{
+				name:      "missing nested key",
+				fieldPath: []string{"replicas", "count"},
+				expected: &PathNotFoundError{
+					Path: []string{"replicas", "count"},
+				},
+			},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				paramsData := test.ReadTestData(t, "params.libsonnet")
+
+				_, err := DeleteFromObject(tc.fieldPath, paramsData, "guestbook-ui", "components")
+				require.Error(t, err)
+
+				pnf, ok := err.(*PathNotFoundError)
+				require.True(t, ok, "unexpected error type %T", err)
+				require.Equal(t, tc.expected, pnf)
+			})
+		}
+	})
+}
+
 func Test_update(t *testing.T) {
 	cases := []struct {
 		name        string
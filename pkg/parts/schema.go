@@ -20,6 +20,7 @@ import (
 
 	"github.com/blang/semver"
 	"github.com/ghodss/yaml"
+	"github.com/ksonnet/ksonnet/pkg/util/version"
 	"github.com/pkg/errors"
 )
 
@@ -43,6 +44,7 @@ type Spec struct {
 	Keywords     []string          `json:"keywords"`
 	QuickStart   *QuickStartSpec   `json:"quickStart"`
 	License      string            `json:"license"`
+	Migrations   MigrationSpecs    `json:"migrations,omitempty"`
 }
 
 func Unmarshal(bytes []byte) (*Spec, error) {
@@ -76,6 +78,12 @@ func (s *Spec) validate() error {
 			DefaultAPIVersion)
 	}
 
+	for _, m := range s.Migrations {
+		if err := m.validate(); err != nil {
+			return errors.Wrapf(err, "Library '%s' has an invalid migration", s.Name)
+		}
+	}
+
 	return nil
 }
 
@@ -104,4 +112,41 @@ type QuickStartSpec struct {
 
 type Specs []*Spec
 
+// MigrationSpec declares how the parameters of a package's prototypes
+// changed in a version, so the params of components generated from an older
+// version can be rewritten when the package is upgraded.
+type MigrationSpec struct {
+	// Version is the package version which changed the parameters.
+	Version string `json:"version"`
+	// Prototype limits the migration to components generated from a
+	// prototype. The migration applies to every prototype if it is empty.
+	Prototype string `json:"prototype,omitempty"`
+	// Rename maps old parameter names to new ones.
+	Rename map[string]string `json:"rename,omitempty"`
+	// Convert maps parameter names to the type their values are converted
+	// to: string, number, or array.
+	Convert map[string]string `json:"convert,omitempty"`
+	// Remove are parameters which no longer exist.
+	Remove []string `json:"remove,omitempty"`
+}
+
+func (m *MigrationSpec) validate() error {
+	if _, err := version.Make(m.Version); err != nil {
+		return errors.Wrapf(err, "invalid version %q", m.Version)
+	}
+
+	for name, t := range m.Convert {
+		switch t {
+		case "string", "number", "array":
+		default:
+			return errors.Errorf("parameter %q can't be converted to %q; must be one of: [string, number, array]", name, t)
+		}
+	}
+
+	return nil
+}
+
+// MigrationSpecs is a slice of MigrationSpec.
+type MigrationSpecs []*MigrationSpec
+
 type PrototypeRefSpecs []string
@@ -62,3 +62,23 @@ func TestApiVersionValidate(t *testing.T) {
 		}
 	}
 }
+
+func TestMigrationValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		migration MigrationSpec
+		err       bool
+	}{
+		{name: "valid", migration: MigrationSpec{Version: "0.2.0", Convert: map[string]string{"port": "number"}}},
+		{name: "invalid version", migration: MigrationSpec{Version: "next"}, err: true},
+		{name: "invalid conversion", migration: MigrationSpec{Version: "0.2.0", Convert: map[string]string{"port": "bool"}}, err: true},
+	}
+
+	for _, test := range tests {
+		spec := &Spec{APIVersion: DefaultAPIVersion, Migrations: MigrationSpecs{&test.migration}}
+		err := spec.validate()
+		if (test.err && err == nil) || (!test.err && err != nil) {
+			t.Errorf("%s: expected error? %t. Value of error: '%v'", test.name, test.err, err)
+		}
+	}
+}
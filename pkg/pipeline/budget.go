@@ -0,0 +1,134 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// levelDeny fails rendering if a check of the objects fails.
+	levelDeny = "deny"
+	// levelWarn logs failed checks of the objects as warnings.
+	levelWarn = "warn"
+)
+
+// checkBudget checks the rendered objects against the environment's budget.
+func (p *Pipeline) checkBudget(objects []*unstructured.Unstructured) error {
+	e, err := p.app.Environment(p.envName)
+	if err != nil {
+		return err
+	}
+
+	if e.Budget == nil {
+		return nil
+	}
+
+	exceeded, err := budgetExceeded(e.Budget, objects)
+	if err != nil {
+		return errors.Wrapf(err, "budget of environment %q", p.envName)
+	}
+
+	if len(exceeded) == 0 {
+		return nil
+	}
+
+	if e.Budget.Level == levelWarn {
+		for _, msg := range exceeded {
+			log.Warnf("environment %q exceeds its budget: %s", p.envName, msg)
+		}
+		return nil
+	}
+
+	return errors.Errorf("environment %q exceeds its budget:\n  %s", p.envName, strings.Join(exceeded, "\n  "))
+}
+
+// budgetExceeded returns a message for each limit of a budget the objects
+// exceed. Sizes are the sizes of the objects' JSON.
+func budgetExceeded(b *app.BudgetConfig, objects []*unstructured.Unstructured) ([]string, error) {
+	switch b.Level {
+	case "", levelDeny, levelWarn:
+	default:
+		return nil, errors.Errorf("invalid level %q; valid levels are deny and warn", b.Level)
+	}
+
+	maxObjectSize, err := parseBudgetSize("maxObjectSize", b.MaxObjectSize)
+	if err != nil {
+		return nil, err
+	}
+
+	maxTotalSize, err := parseBudgetSize("maxTotalSize", b.MaxTotalSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var exceeded []string
+
+	if b.MaxObjects > 0 && len(objects) > b.MaxObjects {
+		exceeded = append(exceeded, fmt.Sprintf("%d objects is more than the maximum of %d", len(objects), b.MaxObjects))
+	}
+
+	var total int64
+	for _, obj := range objects {
+		data, err := json.Marshal(obj.Object)
+		if err != nil {
+			return nil, err
+		}
+
+		size := int64(len(data))
+		total += size
+
+		if maxObjectSize > 0 && size > maxObjectSize {
+			exceeded = append(exceeded, fmt.Sprintf("%s %s is %d bytes, more than the maximum of %s",
+				obj.GetKind(), objectName(obj), size, b.MaxObjectSize))
+		}
+	}
+
+	if maxTotalSize > 0 && total > maxTotalSize {
+		exceeded = append(exceeded, fmt.Sprintf("objects are %d bytes, more than the maximum of %s", total, b.MaxTotalSize))
+	}
+
+	return exceeded, nil
+}
+
+// parseBudgetSize parses a size limit. Limits which are not set are zero.
+func parseBudgetSize(field, value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	q, err := resource.ParseQuantity(value)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid %s %q", field, value)
+	}
+
+	return q.Value(), nil
+}
+
+func objectName(obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() == "" {
+		return obj.GetName()
+	}
+
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
@@ -0,0 +1,139 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	appmocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	cmocks "github.com/ksonnet/ksonnet/pkg/component/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func budgetObjects() []*unstructured.Unstructured {
+	return []*unstructured.Unstructured{
+		{
+			Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]interface{}{"name": "small", "namespace": "default"},
+			},
+		},
+		{
+			Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]interface{}{"name": "large"},
+				"data":       map[string]interface{}{"key": "0123456789012345678901234567890123456789"},
+			},
+		},
+	}
+}
+
+func Test_budgetExceeded(t *testing.T) {
+	cases := []struct {
+		name     string
+		budget   *app.BudgetConfig
+		expected []string
+		isErr    bool
+	}{
+		{
+			name:   "within budget",
+			budget: &app.BudgetConfig{MaxObjects: 2, MaxObjectSize: "1Ki", MaxTotalSize: "1Ki"},
+		},
+		{
+			name:   "no limits",
+			budget: &app.BudgetConfig{},
+		},
+		{
+			name:     "too many objects",
+			budget:   &app.BudgetConfig{MaxObjects: 1},
+			expected: []string{"2 objects is more than the maximum of 1"},
+		},
+		{
+			name:     "object too large",
+			budget:   &app.BudgetConfig{MaxObjectSize: "100"},
+			expected: []string{"ConfigMap large is 124 bytes, more than the maximum of 100"},
+		},
+		{
+			name:     "objects too large",
+			budget:   &app.BudgetConfig{MaxTotalSize: "150"},
+			expected: []string{"objects are 212 bytes, more than the maximum of 150"},
+		},
+		{
+			name:   "invalid size",
+			budget: &app.BudgetConfig{MaxTotalSize: "large"},
+			isErr:  true,
+		},
+		{
+			name:   "invalid level",
+			budget: &app.BudgetConfig{Level: "error"},
+			isErr:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := budgetExceeded(tc.budget, budgetObjects())
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestPipeline_checkBudget(t *testing.T) {
+	cases := []struct {
+		name   string
+		budget *app.BudgetConfig
+		isErr  bool
+	}{
+		{
+			name: "no budget",
+		},
+		{
+			name:   "exceeded",
+			budget: &app.BudgetConfig{MaxObjects: 1},
+			isErr:  true,
+		},
+		{
+			name:   "exceeded with warn level",
+			budget: &app.BudgetConfig{MaxObjects: 1, Level: "warn"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withPipeline(t, func(p *Pipeline, m *cmocks.Manager, a *appmocks.App) {
+				a.On("Environment", "default").Return(&app.EnvironmentConfig{Budget: tc.budget}, nil)
+
+				err := p.checkBudget(budgetObjects())
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+			})
+		})
+	}
+}
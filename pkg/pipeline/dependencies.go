@@ -0,0 +1,157 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package pipeline
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Dependencies is the dependency graph of an environment's components. A
+// component depends on the components listed in the depends-on annotation of
+// its objects, the components which create the namespaces of its objects, and
+// the components which define the custom resources it creates.
+type Dependencies struct {
+	dependsOn map[string]map[string]bool
+}
+
+// ComponentDependencies builds the dependency graph of the components which
+// rendered objects.
+func ComponentDependencies(objects []*unstructured.Unstructured) *Dependencies {
+	d := &Dependencies{dependsOn: make(map[string]map[string]bool)}
+
+	namespaces := make(map[string]string)
+	resources := make(map[schema.GroupKind]string)
+
+	for _, obj := range objects {
+		name := obj.GetLabels()[metadata.LabelComponent]
+		if name == "" {
+			continue
+		}
+
+		d.add(name, "")
+
+		switch obj.GetKind() {
+		case "Namespace":
+			namespaces[obj.GetName()] = name
+		case "CustomResourceDefinition":
+			group, _, _ := unstructured.NestedString(obj.Object, "spec", "group")
+			kind, _, _ := unstructured.NestedString(obj.Object, "spec", "names", "kind")
+			resources[schema.GroupKind{Group: group, Kind: kind}] = name
+		}
+	}
+
+	for _, obj := range objects {
+		name := obj.GetLabels()[metadata.LabelComponent]
+		if name == "" {
+			continue
+		}
+
+		for _, dep := range strings.Split(obj.GetAnnotations()[metadata.AnnotationDependsOn], ",") {
+			d.add(name, strings.TrimSpace(dep))
+		}
+
+		if ns := obj.GetNamespace(); ns != "" {
+			d.add(name, namespaces[ns])
+		}
+
+		d.add(name, resources[obj.GroupVersionKind().GroupKind()])
+	}
+
+	return d
+}
+
+// add records that a component depends on another. Empty and self
+// dependencies are ignored.
+func (d *Dependencies) add(name, dependency string) {
+	if d.dependsOn[name] == nil {
+		d.dependsOn[name] = make(map[string]bool)
+	}
+
+	if dependency != "" && dependency != name {
+		d.dependsOn[name][dependency] = true
+	}
+}
+
+// DependsOn returns the components a component directly depends on.
+func (d *Dependencies) DependsOn(name string) []string {
+	var names []string
+	for dep := range d.dependsOn[name] {
+		names = append(names, dep)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Expand returns components along with, transitively, the components which
+// depend on them if dependents is true, and the components they depend on if
+// dependencies is true. Dependents are expanded first, so their dependencies
+// are included as well.
+func (d *Dependencies) Expand(names []string, dependencies, dependents bool) []string {
+	seen := make(map[string]bool)
+	for _, name := range names {
+		seen[name] = true
+	}
+
+	if dependents {
+		d.walk(seen, func(name string) []string {
+			var out []string
+			for other, deps := range d.dependsOn {
+				if deps[name] {
+					out = append(out, other)
+				}
+			}
+			return out
+		})
+	}
+
+	if dependencies {
+		d.walk(seen, d.DependsOn)
+	}
+
+	var out []string
+	for name := range seen {
+		out = append(out, name)
+	}
+
+	sort.Strings(out)
+	return out
+}
+
+// walk adds the components reachable from seen by next to seen.
+func (d *Dependencies) walk(seen map[string]bool, next func(string) []string) {
+	var queue []string
+	for name := range seen {
+		queue = append(queue, name)
+	}
+
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+
+		for _, other := range next(name) {
+			if !seen[other] {
+				seen[other] = true
+				queue = append(queue, other)
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestComponentDependencies(t *testing.T) {
+	object := func(apiVersion, kind, name, namespace, componentName string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(apiVersion)
+		obj.SetKind(kind)
+		obj.SetName(name)
+		obj.SetNamespace(namespace)
+		obj.SetLabels(map[string]string{metadata.LabelComponent: componentName})
+		return obj
+	}
+
+	crd := object("apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "certificates.certmanager.k8s.io", "", "crds")
+	unstructured.SetNestedField(crd.Object, "certmanager.k8s.io", "spec", "group")
+	unstructured.SetNestedField(crd.Object, "Certificate", "spec", "names", "kind")
+
+	web := object("v1", "Service", "web", "web", "web")
+	web.SetAnnotations(map[string]string{metadata.AnnotationDependsOn: "db, web"})
+
+	objects := []*unstructured.Unstructured{
+		object("v1", "Namespace", "web", "", "namespaces"),
+		crd,
+		object("certmanager.k8s.io/v1alpha1", "Certificate", "web", "web", "certificate"),
+		web,
+		object("v1", "Service", "db", "", "db"),
+	}
+
+	d := ComponentDependencies(objects)
+
+	assert.Equal(t, []string{"crds", "namespaces"}, d.DependsOn("certificate"))
+	assert.Equal(t, []string{"db", "namespaces"}, d.DependsOn("web"))
+	assert.Empty(t, d.DependsOn("db"))
+
+	cases := []struct {
+		name         string
+		names        []string
+		dependencies bool
+		dependents   bool
+		expected     []string
+	}{
+		{
+			name:     "neither",
+			names:    []string{"web"},
+			expected: []string{"web"},
+		},
+		{
+			name:         "dependencies",
+			names:        []string{"certificate"},
+			dependencies: true,
+			expected:     []string{"certificate", "crds", "namespaces"},
+		},
+		{
+			name:       "dependents",
+			names:      []string{"namespaces"},
+			dependents: true,
+			expected:   []string{"certificate", "namespaces", "web"},
+		},
+		{
+			name:         "dependents and their dependencies",
+			names:        []string{"db"},
+			dependencies: true,
+			dependents:   true,
+			expected:     []string{"db", "namespaces", "web"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := d.Expand(tc.names, tc.dependencies, tc.dependents)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
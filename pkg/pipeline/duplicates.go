@@ -0,0 +1,125 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package pipeline
+
+import (
+	"fmt"
+	"strings"
+
+	clustermetadata "github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// objectKey identifies an object in a cluster.
+type objectKey struct {
+	group     string
+	kind      string
+	namespace string
+	name      string
+}
+
+func (k objectKey) String() string {
+	kind := k.kind
+	if k.group != "" {
+		kind = k.kind + "." + k.group
+	}
+
+	if k.namespace == "" {
+		return fmt.Sprintf("%s %s", kind, k.name)
+	}
+
+	return fmt.Sprintf("%s %s/%s", kind, k.namespace, k.name)
+}
+
+// checkDuplicates checks that no object is rendered more than once by the
+// environment's components.
+func (p *Pipeline) checkDuplicates(objects []*unstructured.Unstructured) error {
+	e, err := p.app.Environment(p.envName)
+	if err != nil {
+		return err
+	}
+
+	switch e.DuplicateObjects {
+	case "", levelDeny, levelWarn:
+	default:
+		return errors.Errorf("invalid duplicateObjects %q of environment %q; valid levels are deny and warn",
+			e.DuplicateObjects, p.envName)
+	}
+
+	duplicates := duplicateObjects(objects)
+	if len(duplicates) == 0 {
+		return nil
+	}
+
+	if e.DuplicateObjects == levelWarn {
+		for _, msg := range duplicates {
+			log.Warnf("environment %q renders duplicate objects: %s", p.envName, msg)
+		}
+		return nil
+	}
+
+	return errors.Errorf("environment %q renders duplicate objects:\n  %s", p.envName, strings.Join(duplicates, "\n  "))
+}
+
+// duplicateObjects indexes objects by their group, kind, namespace and name,
+// and returns a message naming the components of each object rendered more
+// than once. Objects without a name are left out.
+func duplicateObjects(objects []*unstructured.Unstructured) []string {
+	index := make(map[objectKey][]string)
+	var keys []objectKey
+
+	for _, obj := range objects {
+		if obj.GetName() == "" {
+			continue
+		}
+
+		key := objectKey{
+			group:     obj.GroupVersionKind().Group,
+			kind:      obj.GetKind(),
+			namespace: obj.GetNamespace(),
+			name:      obj.GetName(),
+		}
+
+		if _, ok := index[key]; !ok {
+			keys = append(keys, key)
+		}
+		index[key] = append(index[key], objectComponent(obj))
+	}
+
+	var duplicates []string
+	for _, key := range keys {
+		components := index[key]
+		if len(components) < 2 {
+			continue
+		}
+
+		quoted := make([]string, len(components))
+		for i := range components {
+			quoted[i] = fmt.Sprintf("%q", components[i])
+		}
+
+		duplicates = append(duplicates, fmt.Sprintf("%s is rendered by components %s",
+			key, strings.Join(quoted, ", ")))
+	}
+
+	return duplicates
+}
+
+// objectComponent returns the component which rendered an object.
+func objectComponent(obj *unstructured.Unstructured) string {
+	return obj.GetLabels()[clustermetadata.LabelComponent]
+}
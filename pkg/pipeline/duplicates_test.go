@@ -0,0 +1,125 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	appmocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	cmocks "github.com/ksonnet/ksonnet/pkg/component/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func renderedObject(component, apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+	metadata := map[string]interface{}{
+		"name":   name,
+		"labels": map[string]interface{}{"ksonnet.io/component": component},
+	}
+	if namespace != "" {
+		metadata["namespace"] = namespace
+	}
+
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata":   metadata,
+	}}
+}
+
+func Test_duplicateObjects(t *testing.T) {
+	cases := []struct {
+		name     string
+		objects  []*unstructured.Unstructured
+		expected []string
+	}{
+		{
+			name: "no duplicates",
+			objects: []*unstructured.Unstructured{
+				renderedObject("web", "apps/v1", "Deployment", "default", "web"),
+				renderedObject("web", "v1", "Service", "default", "web"),
+				renderedObject("web", "extensions/v1beta1", "Ingress", "default", "web"),
+				renderedObject("other", "apps/v1", "Deployment", "other", "web"),
+				renderedObject("other", "v1", "ConfigMap", "", ""),
+				renderedObject("other", "v1", "ConfigMap", "", ""),
+			},
+		},
+		{
+			name: "duplicates",
+			objects: []*unstructured.Unstructured{
+				renderedObject("web", "apps/v1", "Deployment", "default", "web"),
+				renderedObject("web", "v1", "Namespace", "", "guestbook"),
+				renderedObject("web-v2", "apps/v1beta2", "Deployment", "default", "web"),
+				renderedObject("namespaces", "v1", "Namespace", "", "guestbook"),
+				renderedObject("namespaces", "v1", "Namespace", "", "guestbook"),
+			},
+			expected: []string{
+				`Deployment.apps default/web is rendered by components "web", "web-v2"`,
+				`Namespace guestbook is rendered by components "web", "namespaces", "namespaces"`,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, duplicateObjects(tc.objects))
+		})
+	}
+}
+
+func TestPipeline_checkDuplicates(t *testing.T) {
+	objects := []*unstructured.Unstructured{
+		renderedObject("web", "v1", "Service", "default", "web"),
+		renderedObject("web-v2", "v1", "Service", "default", "web"),
+	}
+
+	cases := []struct {
+		name  string
+		level string
+		isErr bool
+	}{
+		{
+			name:  "default level",
+			isErr: true,
+		},
+		{
+			name:  "warn level",
+			level: "warn",
+		},
+		{
+			name:  "invalid level",
+			level: "error",
+			isErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withPipeline(t, func(p *Pipeline, m *cmocks.Manager, a *appmocks.App) {
+				a.On("Environment", "default").Return(&app.EnvironmentConfig{DuplicateObjects: tc.level}, nil)
+
+				err := p.checkDuplicates(objects)
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+			})
+		})
+	}
+}
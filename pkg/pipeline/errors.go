@@ -0,0 +1,206 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package pipeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	gostrings "strings"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/util/jsonnet"
+	"github.com/spf13/afero"
+)
+
+// sourceContext is the number of lines shown around the line of an error.
+const sourceContext = 2
+
+// SourceLine is a line of source code.
+type SourceLine struct {
+	Number int
+	Text   string
+}
+
+// EvaluationError is an error evaluating components, mapped back to the
+// source in the app which caused it.
+type EvaluationError struct {
+	// Err is the jsonnet error.
+	Err *jsonnet.EvalError
+	// Component is the name of the component which failed. It is blank if
+	// the error is not in a component.
+	Component string
+	// File is the path of the failing file, relative to the app root.
+	File   string
+	Line   int
+	Column int
+	// Source is the code around the failing line.
+	Source []SourceLine
+	// Params are the parameters of the component in the environment.
+	Params map[string]interface{}
+}
+
+// Error describes the error, the code which caused it, and the parameters of
+// the component.
+func (e *EvaluationError) Error() string {
+	var buf bytes.Buffer
+
+	if e.Component != "" {
+		fmt.Fprintf(&buf, "evaluating component %q: ", e.Component)
+	}
+	fmt.Fprintf(&buf, "%s\n", e.Err.Msg)
+	fmt.Fprintf(&buf, "  at %s:%d:%d\n", e.File, e.Line, e.Column)
+
+	if len(e.Source) > 0 {
+		buf.WriteString("\n")
+		width := len(fmt.Sprint(e.Source[len(e.Source)-1].Number))
+		for _, l := range e.Source {
+			marker := " "
+			if l.Number == e.Line {
+				marker = ">"
+			}
+			fmt.Fprintf(&buf, "  %s %*d | %s\n", marker, width, l.Number, l.Text)
+		}
+	}
+
+	if len(e.Params) > 0 {
+		buf.WriteString("\n  params:\n")
+
+		var keys []string
+		for k := range e.Params {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			v, err := json.Marshal(e.Params[k])
+			if err != nil {
+				v = []byte(fmt.Sprint(e.Params[k]))
+			}
+			fmt.Fprintf(&buf, "    %s: %s\n", k, v)
+		}
+	}
+
+	if len(e.Err.Frames) > 1 {
+		buf.WriteString("\n  stack trace:\n")
+		for _, f := range e.Err.Frames {
+			fmt.Fprintf(&buf, "    %s:%d:%d\t%s\n", f.File, f.Line, f.Column, f.Name)
+		}
+	}
+
+	return gostrings.TrimSuffix(buf.String(), "\n")
+}
+
+// mapEvaluationError maps a jsonnet error to the source in the app which
+// caused it. Errors in components are preferred to errors in the app's
+// libraries. Errors outside of the app are returned unchanged.
+func mapEvaluationError(a app.App, evalErr *jsonnet.EvalError, paramsData string) error {
+	root := a.Root()
+	componentsDir := filepath.Join(root, "components")
+
+	var frame *jsonnet.Frame
+	for i := range evalErr.Frames {
+		f := &evalErr.Frames[i]
+		if isWithin(componentsDir, f.File) {
+			frame = f
+			break
+		}
+		if frame == nil && isWithin(root, f.File) {
+			frame = f
+		}
+	}
+
+	if frame == nil {
+		return evalErr
+	}
+
+	rel, err := filepath.Rel(root, frame.File)
+	if err != nil {
+		return evalErr
+	}
+
+	e := &EvaluationError{
+		Err:    evalErr,
+		File:   rel,
+		Line:   frame.Line,
+		Column: frame.Column,
+		Source: readSource(a.Fs(), frame.File, frame.Line),
+	}
+
+	if isWithin(componentsDir, frame.File) && filepath.Ext(frame.File) == ".jsonnet" {
+		name := componentName(componentsDir, frame.File)
+		e.Component = name
+
+		var p struct {
+			Components map[string]map[string]interface{} `json:"components"`
+		}
+		if err := json.Unmarshal([]byte(paramsData), &p); err == nil {
+			local := name[gostrings.LastIndex(name, ".")+1:]
+			e.Params = p.Components[local]
+		}
+	}
+
+	return e
+}
+
+// componentName returns the name of the component in path, prefixed by its
+// module.
+func componentName(componentsDir, path string) string {
+	rel, err := filepath.Rel(componentsDir, path)
+	if err != nil {
+		return ""
+	}
+
+	rel = gostrings.TrimSuffix(rel, filepath.Ext(rel))
+	return gostrings.Replace(rel, string(filepath.Separator), ".", -1)
+}
+
+// readSource reads the lines around line in a file.
+func readSource(fs afero.Fs, path string, line int) []SourceLine {
+	b, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil
+	}
+
+	lines := gostrings.Split(string(b), "\n")
+
+	start := line - sourceContext
+	if start < 1 {
+		start = 1
+	}
+	end := line + sourceContext
+	if end > len(lines) {
+		end = len(lines)
+	}
+
+	var source []SourceLine
+	for i := start; i <= end; i++ {
+		source = append(source, SourceLine{Number: i, Text: lines[i-1]})
+	}
+
+	return source
+}
+
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+
+	return rel != ".." && !gostrings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,107 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet-lib/ksonnet-gen/astext"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	appmocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	cmocks "github.com/ksonnet/ksonnet/pkg/component/mocks"
+	"github.com/ksonnet/ksonnet/pkg/util/jsonnet"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const guestbookSource = `local env = std.extVar("__ksonnet/environments");
+local params = std.extVar("__ksonnet/params").components.guestbook;
+
+{
+  apiVersion: "v1",
+  kind: "Service",
+  spec: {
+    replicas: params.replicas,
+  },
+}
+`
+
+func TestPipeline_Objects_evaluation_error(t *testing.T) {
+	withPipeline(t, func(p *Pipeline, m *cmocks.Manager, a *appmocks.App) {
+		fs := afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(fs, "/components/nested/guestbook.jsonnet", []byte(guestbookSource), 0644))
+		a.On("Fs").Return(fs)
+
+		module := &cmocks.Module{}
+		module.On("Name").Return("nested")
+		module.On("Render", "default").Return(&astext.Object{}, map[string]string{}, nil)
+		module.On("ResolvedParams", "default").Return("", nil)
+
+		m.On("Modules", p.app, "default").Return([]component.Module{module}, nil)
+		a.On("Environment", "default").Return(&app.EnvironmentConfig{Path: "default"}, nil)
+
+		paramsData := `{"components": {"guestbook": {"image": "nginx", "port": 80}}}`
+		p.evaluateEnvParamsFn = func(_ app.App, paramsPath, paramData, envName, moduleName string) (string, error) {
+			return paramsData, nil
+		}
+
+		p.evaluateEnvFn = func(_ app.App, envName, input, params string, opts ...jsonnet.VMOpt) (string, error) {
+			vm := jsonnet.NewVM(jsonnet.AferoImporterOpt(fs))
+			vm.ExtCode("__ksonnet/environments", "{}")
+			vm.ExtCode("__ksonnet/params", params)
+			return vm.EvaluateSnippet("main.jsonnet", `{ guestbook: import "/components/nested/guestbook.jsonnet" }`)
+		}
+
+		_, err := p.Objects(nil)
+		require.Error(t, err)
+
+		evalErr, ok := err.(*EvaluationError)
+		require.True(t, ok, "unexpected error type %T", err)
+
+		assert.Equal(t, "nested.guestbook", evalErr.Component)
+		assert.Equal(t, "components/nested/guestbook.jsonnet", evalErr.File)
+		assert.Equal(t, 8, evalErr.Line)
+		assert.Equal(t, []SourceLine{
+			{Number: 6, Text: `  kind: "Service",`},
+			{Number: 7, Text: `  spec: {`},
+			{Number: 8, Text: `    replicas: params.replicas,`},
+			{Number: 9, Text: `  },`},
+			{Number: 10, Text: `}`},
+		}, evalErr.Source)
+		assert.Equal(t, map[string]interface{}{"image": "nginx", "port": float64(80)}, evalErr.Params)
+
+		msg := err.Error()
+		assert.Contains(t, msg, `evaluating component "nested.guestbook": RUNTIME ERROR: Field does not exist: replicas`)
+		assert.Contains(t, msg, "at components/nested/guestbook.jsonnet:8:")
+		assert.Contains(t, msg, ">  8 |     replicas: params.replicas,")
+		assert.Contains(t, msg, `image: "nginx"`)
+	})
+}
+
+func Test_mapEvaluationError_outside_app(t *testing.T) {
+	a := &appmocks.App{}
+	a.On("Root").Return("/app")
+
+	evalErr := &jsonnet.EvalError{
+		Msg:    "RUNTIME ERROR: failed",
+		Frames: []jsonnet.Frame{{File: "<std>", Line: 1, Column: 1}},
+	}
+
+	err := mapEvaluationError(a, evalErr, "{}")
+	assert.Equal(t, evalErr, err)
+}
@@ -0,0 +1,108 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package pipeline
+
+import (
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// generatedNames maps the unhashed names of generated ConfigMaps and Secrets,
+// by kind, to the names they were generated with.
+type generatedNames map[string]map[string]string
+
+// add records the name of object if it was built by a generator component.
+func (n generatedNames) add(object map[string]interface{}) {
+	kind, _ := object["kind"].(string)
+	if kind != "ConfigMap" && kind != "Secret" {
+		return
+	}
+
+	objMetadata, _ := object["metadata"].(map[string]interface{})
+	annotations, _ := objMetadata["annotations"].(map[string]interface{})
+	from, _ := annotations[metadata.AnnotationGeneratedFrom].(string)
+	name, _ := objMetadata["name"].(string)
+	if from == "" || name == "" || from == name {
+		return
+	}
+
+	if n[kind] == nil {
+		n[kind] = make(map[string]string)
+	}
+	n[kind][from] = name
+}
+
+// rewrite replaces references to unhashed names in objects, e.g. in pod volumes,
+// envFrom and env valueFrom, with the generated names.
+func (n generatedNames) rewrite(objects []*unstructured.Unstructured) {
+	if len(n) == 0 {
+		return
+	}
+
+	for _, object := range objects {
+		n.rewriteValue(object.Object)
+	}
+}
+
+func (n generatedNames) rewriteValue(v interface{}) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for key, child := range t {
+			switch key {
+			case "configMap", "configMapRef", "configMapKeyRef":
+				n.rename(child, "ConfigMap", "name")
+			case "secret":
+				n.rename(child, "Secret", "secretName", "name")
+			case "secretRef", "secretKeyRef":
+				n.rename(child, "Secret", "name")
+			case "imagePullSecrets":
+				if list, ok := child.([]interface{}); ok {
+					for _, item := range list {
+						n.rename(item, "Secret", "name")
+					}
+				}
+			}
+
+			n.rewriteValue(child)
+		}
+	case []interface{}:
+		for _, child := range t {
+			n.rewriteValue(child)
+		}
+	}
+}
+
+// rename rewrites the first of fields set in ref, if it names a generated
+// object of kind.
+func (n generatedNames) rename(ref interface{}, kind string, fields ...string) {
+	m, ok := ref.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	for _, field := range fields {
+		name, ok := m[field].(string)
+		if !ok {
+			continue
+		}
+
+		if generated, ok := n[kind][name]; ok {
+			log.Debugf("rewriting reference to %s %q as %q", kind, name, generated)
+			m[field] = generated
+		}
+		return
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func Test_generatedNames(t *testing.T) {
+	names := generatedNames{}
+	names.add(map[string]interface{}{
+		"kind": "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":        "app-config-abc",
+			"annotations": map[string]interface{}{metadata.AnnotationGeneratedFrom: "app-config"},
+		},
+	})
+	names.add(map[string]interface{}{
+		"kind": "Secret",
+		"metadata": map[string]interface{}{
+			"name":        "creds-def",
+			"annotations": map[string]interface{}{metadata.AnnotationGeneratedFrom: "creds"},
+		},
+	})
+	names.add(map[string]interface{}{
+		"kind":     "ConfigMap",
+		"metadata": map[string]interface{}{"name": "other"},
+	})
+
+	deployment := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind": "Deployment",
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"imagePullSecrets": []interface{}{
+						map[string]interface{}{"name": "creds"},
+					},
+					"volumes": []interface{}{
+						map[string]interface{}{"name": "config", "configMap": map[string]interface{}{"name": "app-config"}},
+						map[string]interface{}{"name": "other", "configMap": map[string]interface{}{"name": "other"}},
+						map[string]interface{}{"name": "creds", "secret": map[string]interface{}{"secretName": "creds"}},
+					},
+					"containers": []interface{}{
+						map[string]interface{}{
+							"envFrom": []interface{}{
+								map[string]interface{}{"configMapRef": map[string]interface{}{"name": "app-config"}},
+							},
+							"env": []interface{}{
+								map[string]interface{}{
+									"name":      "TOKEN",
+									"valueFrom": map[string]interface{}{"secretKeyRef": map[string]interface{}{"name": "creds", "key": "token"}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}}
+
+	names.rewrite([]*unstructured.Unstructured{deployment})
+
+	spec := deployment.Object["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})
+	require.Equal(t, "creds-def", spec["imagePullSecrets"].([]interface{})[0].(map[string]interface{})["name"])
+
+	volumes := spec["volumes"].([]interface{})
+	require.Equal(t, "app-config-abc", volumes[0].(map[string]interface{})["configMap"].(map[string]interface{})["name"])
+	require.Equal(t, "other", volumes[1].(map[string]interface{})["configMap"].(map[string]interface{})["name"])
+	require.Equal(t, "creds-def", volumes[2].(map[string]interface{})["secret"].(map[string]interface{})["secretName"])
+
+	container := spec["containers"].([]interface{})[0].(map[string]interface{})
+	envFrom := container["envFrom"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, "app-config-abc", envFrom["configMapRef"].(map[string]interface{})["name"])
+	env := container["env"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, "creds-def", env["valueFrom"].(map[string]interface{})["secretKeyRef"].(map[string]interface{})["name"])
+}
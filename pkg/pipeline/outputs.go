@@ -0,0 +1,174 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package pipeline
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/google/go-jsonnet"
+	"github.com/google/go-jsonnet/ast"
+	"github.com/ksonnet/ksonnet-lib/ksonnet-gen/astext"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	"github.com/pkg/errors"
+)
+
+// outputFunctionName is the name of the native function components use to
+// reference the rendered values of other components, e.g.
+//
+//	local redis = std.native("output")("db.redis");
+//	redis.spec.ports[0].port
+const outputFunctionName = "output"
+
+// outputs resolves references from one component to the rendered value of
+// another. Referenced components are rendered on their own, with the
+// environment's params, and cached for the lifetime of the pipeline.
+type outputs struct {
+	p         *Pipeline
+	resolved  map[string]interface{}
+	resolving map[string]bool
+}
+
+func newOutputs(p *Pipeline) *outputs {
+	return &outputs{
+		p:         p,
+		resolved:  make(map[string]interface{}),
+		resolving: make(map[string]bool),
+	}
+}
+
+// nativeFunction returns the output native function.
+func (o *outputs) nativeFunction() *jsonnet.NativeFunction {
+	fn := func(input []interface{}) (interface{}, error) {
+		name, ok := input[0].(string)
+		if !ok {
+			return nil, errors.New("invalid component name")
+		}
+
+		return o.resolve(name)
+	}
+
+	return &jsonnet.NativeFunction{
+		Name:   outputFunctionName,
+		Params: ast.Identifiers{"component"},
+		Func:   fn,
+	}
+}
+
+// resolve returns the rendered value of a component. A component rendering
+// more than one object resolves to a v1 List of them.
+func (o *outputs) resolve(name string) (interface{}, error) {
+	if v, ok := o.resolved[name]; ok {
+		return v, nil
+	}
+
+	if o.resolving[name] {
+		return nil, errors.Errorf("component %q references its own output", name)
+	}
+	o.resolving[name] = true
+	defer delete(o.resolving, name)
+
+	module, c, err := o.locate(name)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := o.p.renderComponent(module, c)
+	if err != nil {
+		return nil, errors.Wrapf(err, "rendering output of component %q", name)
+	}
+
+	var value interface{}
+	if len(objects) == 1 {
+		value = objects[0].Object
+	} else {
+		items := make([]interface{}, 0, len(objects))
+		for _, object := range objects {
+			items = append(items, object.Object)
+		}
+		value = map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "List",
+			"items":      items,
+		}
+	}
+
+	// Round trip through JSON so numbers are float64, which is the only
+	// number type jsonnet accepts from native functions.
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+	if err = json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+
+	o.resolved[name] = v
+	return v, nil
+}
+
+// locate finds a component, named module.component, in the modules targeted
+// by the pipeline's environment.
+func (o *outputs) locate(name string) (component.Module, component.Component, error) {
+	moduleName := "/"
+	if i := strings.LastIndex(name, "."); i != -1 {
+		moduleName = name[:i]
+	}
+
+	modules, err := o.p.Modules()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, m := range modules {
+		if m.Name() != moduleName {
+			continue
+		}
+
+		components, err := o.p.cm.Components(o.p.app, m.Name())
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, c := range components {
+			if c.Name(true) == name {
+				return m, c, nil
+			}
+		}
+	}
+
+	return nil, nil, errors.Errorf("component %q was not found in environment %q", name, o.p.envName)
+}
+
+// componentObject creates a module object containing a single component.
+func componentObject(envName string, c component.Component) (*astext.Object, map[string]string, error) {
+	name, node, err := c.ToNode(envName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f, err := astext.CreateField(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	f.Hide = ast.ObjectFieldInherit
+	f.Expr2 = node
+
+	object := &astext.Object{Fields: astext.ObjectFields{*f}}
+	return object, map[string]string{c.Name(true): c.Type()}, nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/google/go-jsonnet/ast"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	appmocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	cmocks "github.com/ksonnet/ksonnet/pkg/component/mocks"
+	"github.com/ksonnet/ksonnet/pkg/util/jsonnet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withOutputs(t *testing.T, fn func(p *Pipeline)) {
+	withPipeline(t, func(p *Pipeline, m *cmocks.Manager, a *appmocks.App) {
+		module := &cmocks.Module{}
+		module.On("Name").Return("db")
+		module.On("ResolvedParams", "default").Return(`{"components": {}}`, nil)
+
+		c := &cmocks.Component{}
+		c.On("Name", true).Return("db.redis")
+		c.On("Type").Return("jsonnet")
+		c.On("ToNode", "default").Return("db.redis", &ast.Import{File: &ast.LiteralString{Value: "redis.jsonnet"}}, nil)
+
+		m.On("Modules", p.app, "default").Return([]component.Module{module}, nil)
+		m.On("Components", p.app, "db").Return([]component.Component{c}, nil)
+
+		env := &app.EnvironmentConfig{Path: "default"}
+		a.On("Environment", "default").Return(env, nil)
+
+		p.evaluateEnvParamsFn = func(_ app.App, paramsPath, paramData, envName, moduleName string) (string, error) {
+			return `{"components": {}}`, nil
+		}
+
+		fn(p)
+	})
+}
+
+func Test_outputs_resolve(t *testing.T) {
+	withOutputs(t, func(p *Pipeline) {
+		evaluated := 0
+		p.evaluateEnvFn = func(_ app.App, envName, input, params string, opts ...jsonnet.VMOpt) (string, error) {
+			evaluated++
+			assert.Contains(t, input, "redis.jsonnet")
+			return `{"db.redis": {"apiVersion": "v1", "kind": "Service", "metadata": {"name": "redis"}, "spec": {"ports": [{"port": 6379}]}}}`, nil
+		}
+
+		got, err := p.outputs.resolve("db.redis")
+		require.NoError(t, err)
+
+		service, ok := got.(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, "redis", service["metadata"].(map[string]interface{})["name"])
+		ports := service["spec"].(map[string]interface{})["ports"].([]interface{})
+		assert.Equal(t, float64(6379), ports[0].(map[string]interface{})["port"])
+
+		_, err = p.outputs.resolve("db.redis")
+		require.NoError(t, err)
+		assert.Equal(t, 1, evaluated)
+	})
+}
+
+func Test_outputs_resolve_self_reference(t *testing.T) {
+	withOutputs(t, func(p *Pipeline) {
+		p.evaluateEnvFn = func(_ app.App, envName, input, params string, opts ...jsonnet.VMOpt) (string, error) {
+			_, err := p.outputs.resolve("db.redis")
+			return "", err
+		}
+
+		_, err := p.outputs.resolve("db.redis")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "references its own output")
+	})
+}
+
+func Test_outputs_resolve_not_found(t *testing.T) {
+	withOutputs(t, func(p *Pipeline) {
+		_, err := p.outputs.resolve("web.redis")
+		require.Error(t, err)
+	})
+}
+
+func Test_outputs_nativeFunction(t *testing.T) {
+	withOutputs(t, func(p *Pipeline) {
+		p.evaluateEnvFn = func(_ app.App, envName, input, params string, opts ...jsonnet.VMOpt) (string, error) {
+			return `{"db.redis": {"apiVersion": "v1", "kind": "Service", "metadata": {"name": "redis"}}}`, nil
+		}
+
+		vm := jsonnet.NewVM(p.outputsOpt())
+		got, err := vm.EvaluateSnippet("snippet", `std.native("output")("db.redis").metadata.name`)
+		require.NoError(t, err)
+		assert.Equal(t, "\"redis\"\n", got)
+	})
+}
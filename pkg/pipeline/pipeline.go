@@ -17,14 +17,14 @@ package pipeline
 
 import (
 	"bytes"
+	"crypto/rand"
 	"encoding/json"
-	"fmt"
 	"io"
 	"path/filepath"
 	"regexp"
 	gostrings "strings"
 
-	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus"
 
 	"github.com/ksonnet/ksonnet-lib/ksonnet-gen/astext"
 	"github.com/ksonnet/ksonnet-lib/ksonnet-gen/printer"
@@ -33,9 +33,11 @@ import (
 	"github.com/ksonnet/ksonnet/pkg/env"
 	clustermetadata "github.com/ksonnet/ksonnet/pkg/metadata"
 	"github.com/ksonnet/ksonnet/pkg/params"
+	"github.com/ksonnet/ksonnet/pkg/util/event"
 	"github.com/ksonnet/ksonnet/pkg/util/jsonnet"
 	"github.com/ksonnet/ksonnet/pkg/util/k8s"
 	"github.com/ksonnet/ksonnet/pkg/util/strings"
+	"github.com/ksonnet/ksonnet/pkg/util/trace"
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -59,7 +61,12 @@ type Pipeline struct {
 	buildObjectsFn      func(*Pipeline, []string) ([]*unstructured.Unstructured, error)
 	evaluateEnvFn       func(a app.App, envName, components, paramsStr string, opts ...jsonnet.VMOpt) (string, error)
 	evaluateEnvParamsFn func(a app.App, sourcePath, paramsStr, envName, moduleName string) (string, error)
+	evaluateSnippetFn   func(a app.App, envName, filename, snippet, paramsStr string, opts ...jsonnet.VMOpt) (string, error)
+	postProcessFn       func(a app.App, envName, objects, paramsStr string, opts ...jsonnet.VMOpt) (string, error)
 	stubModuleFn        func(m component.Module) (string, error)
+	outputs             *outputs
+	sourceMap           bool
+	rand                io.Reader
 }
 
 // New creates an instance of Pipeline.
@@ -72,9 +79,14 @@ func New(ksApp app.App, envName string, opts ...Opt) *Pipeline {
 		buildObjectsFn:      buildObjects,
 		evaluateEnvFn:       env.Evaluate,
 		evaluateEnvParamsFn: params.EvaluateEnv,
+		evaluateSnippetFn:   env.EvaluateSnippet,
+		postProcessFn:       env.PostProcess,
 		stubModuleFn:        stubModule,
+		rand:                rand.Reader,
 	}
 
+	p.outputs = newOutputs(p)
+
 	for _, opt := range opts {
 		opt(p)
 	}
@@ -132,7 +144,7 @@ func (p *Pipeline) moduleParams(module component.Module, inherited bool) (string
 		return "", errors.Wrapf(err, "resolve params for %s", module.Name())
 	}
 
-	fmt.Println(paramsStr)
+	log.Debugf("[Pipeline.moduleParams] Resolved params: %v", paramsStr)
 
 	return paramsStr, nil
 }
@@ -187,14 +199,33 @@ func (p *Pipeline) Objects(filter []string) ([]*unstructured.Unstructured, error
 	return p.buildObjectsFn(p, filter)
 }
 
-func (p *Pipeline) moduleObjects(module component.Module, filter []string) ([]*unstructured.Unstructured, error) {
-	doc := &astext.Object{}
+func (p *Pipeline) moduleObjects(module component.Module, filter []string, names generatedNames) ([]*unstructured.Unstructured, error) {
+	span := trace.Start("pipeline.render", "module", module.Name(), "env", p.envName)
+	objects, err := p.renderModule(module, filter, names)
+	return objects, span.Finish(err)
+}
 
+func (p *Pipeline) renderModule(module component.Module, filter []string, names generatedNames) ([]*unstructured.Unstructured, error) {
 	object, componentMap, err := module.Render(p.envName, filter...)
 	if err != nil {
 		return nil, err
 	}
 
+	return p.evaluateModule(module, object, componentMap, filter, names)
+}
+
+// renderComponent renders a single component of a module.
+func (p *Pipeline) renderComponent(module component.Module, c component.Component) ([]*unstructured.Unstructured, error) {
+	object, componentMap, err := componentObject(p.envName, c)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.evaluateModule(module, object, componentMap, []string{c.Name(true)}, generatedNames{})
+}
+
+func (p *Pipeline) evaluateModule(module component.Module, object *astext.Object, componentMap map[string]string, filter []string, names generatedNames) ([]*unstructured.Unstructured, error) {
+	doc := &astext.Object{}
 	doc.Fields = append(doc.Fields, object.Fields...)
 
 	// apply environment parameters
@@ -219,8 +250,13 @@ func (p *Pipeline) moduleObjects(module component.Module, filter []string) ([]*u
 	}
 
 	// evaluate module with jsonnet.
-	evaluated, err := p.evaluateEnvFn(p.app, p.envName, buf.String(), envParamData)
-	if err != nil {
+	event.Publish(event.EvaluationStarted{Module: module.Name(), Env: p.envName})
+	span := trace.Start("jsonnet.evaluate", "module", module.Name())
+	evaluated, err := p.evaluateEnvFn(p.app, p.envName, buf.String(), envParamData, p.outputsOpt())
+	if evalErr, ok := errors.Cause(err).(*jsonnet.EvalError); ok {
+		err = mapEvaluationError(p.app, evalErr, envParamData)
+	}
+	if err = span.Finish(err); err != nil {
 		return nil, err
 	}
 
@@ -233,6 +269,12 @@ func (p *Pipeline) moduleObjects(module component.Module, filter []string) ([]*u
 	ret := make([]runtime.Object, 0, len(m))
 
 	for componentName, v := range m {
+		// Generated names are recorded before filtering, so references to
+		// them are rewritten even when the generator is not being rendered.
+		if object, ok := v.(map[string]interface{}); ok {
+			names.add(object)
+		}
+
 		if len(filter) != 0 && !strings.InSlice(componentName, filter) {
 			continue
 		}
@@ -258,7 +300,7 @@ func (p *Pipeline) moduleObjects(module component.Module, filter []string) ([]*u
 		var patched string
 
 		switch componentType {
-		case "jsonnet":
+		case "jsonnet", component.TypeGenerator:
 			patched = string(data)
 		case "yaml":
 			patched, err = params.PatchJSON(string(data), envParamData, componentName)
@@ -277,6 +319,48 @@ func (p *Pipeline) moduleObjects(module component.Module, filter []string) ([]*u
 	return k8s.FlattenToV1(ret)
 }
 
+// outputsOpt configures a VM so components can reference the outputs of
+// other components.
+func (p *Pipeline) outputsOpt() jsonnet.VMOpt {
+	return jsonnet.NativeFunctionsOpt(p.outputs.nativeFunction())
+}
+
+// rootParams returns the parameters of the root module in the environment.
+func (p *Pipeline) rootParams() (string, error) {
+	module, err := p.cm.Module(p.app, "/")
+	if err != nil {
+		return "", errors.Wrap(err, "load root module")
+	}
+
+	moduleParamData, err := module.ResolvedParams(p.envName)
+	if err != nil {
+		return "", err
+	}
+
+	envParamsPath, err := env.Path(p.app, p.envName, "params.libsonnet")
+	if err != nil {
+		return "", err
+	}
+
+	return p.evaluateEnvParamsFn(p.app, envParamsPath, moduleParamData, p.envName, module.Name())
+}
+
+// EvaluateSnippet evaluates a jsonnet snippet as if it were a component in
+// the root module, with the environment's parameters.
+func (p *Pipeline) EvaluateSnippet(filename, snippet string) (string, error) {
+	envParamData, err := p.rootParams()
+	if err != nil {
+		return "", err
+	}
+
+	evaluated, err := p.evaluateSnippetFn(p.app, p.envName, filename, snippet, envParamData, p.outputsOpt())
+	if evalErr, ok := errors.Cause(err).(*jsonnet.EvalError); ok {
+		return "", mapEvaluationError(p.app, evalErr, envParamData)
+	}
+
+	return evaluated, err
+}
+
 // YAML converts components into YAML.
 func (p *Pipeline) YAML(filter []string) (io.Reader, error) {
 	objects, err := p.Objects(filter)
@@ -331,14 +415,15 @@ func buildObjects(p *Pipeline, filter []string) ([]*unstructured.Unstructured, e
 	}
 
 	var ret []*unstructured.Unstructured
+	names := generatedNames{}
 
 	for _, m := range modules {
-		log.WithFields(log.Fields{
+		log.WithFields(logrus.Fields{
 			"action":      "pipeline",
 			"module-name": m.Name(),
 		}).Debug("building objects")
 
-		objects, err := p.moduleObjects(m, filter)
+		objects, err := p.moduleObjects(m, filter, names)
 		if err != nil {
 			return nil, err
 		}
@@ -346,9 +431,86 @@ func buildObjects(p *Pipeline, filter []string) ([]*unstructured.Unstructured, e
 		ret = append(ret, objects...)
 	}
 
+	names.rewrite(ret)
+
+	ret, err = p.postProcess(ret)
+	if err != nil {
+		return nil, err
+	}
+
+	ret, err = p.transformSecrets(ret)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.checkDuplicates(ret); err != nil {
+		return nil, err
+	}
+
+	if err := p.checkBudget(ret); err != nil {
+		return nil, err
+	}
+
+	if err := p.annotateSources(ret); err != nil {
+		return nil, err
+	}
+
 	return ret, nil
 }
 
+// postProcess passes the objects of the environment through its
+// postprocess.libsonnet, if it has one. The objects it returns replace them.
+func (p *Pipeline) postProcess(objects []*unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	ok, err := env.HasPostProcess(p.app, p.envName)
+	if err != nil || !ok {
+		return objects, err
+	}
+
+	envParamData, err := p.rootParams()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(objects)
+	if err != nil {
+		return nil, err
+	}
+
+	span := trace.Start("jsonnet.postprocess", "env", p.envName)
+	processed, err := p.postProcessFn(p.app, p.envName, string(data), envParamData, p.outputsOpt())
+	if evalErr, ok := errors.Cause(err).(*jsonnet.EvalError); ok {
+		err = mapEvaluationError(p.app, evalErr, envParamData)
+	}
+	if err = span.Finish(err); err != nil {
+		return nil, errors.Wrap(err, "post-processing objects")
+	}
+
+	var items []interface{}
+	if err := json.Unmarshal([]byte(processed), &items); err != nil {
+		return nil, errors.Errorf("%s must return an array of objects", env.PostProcessFileName)
+	}
+
+	ret := make([]runtime.Object, 0, len(items))
+	for i, item := range items {
+		if _, ok := item.(map[string]interface{}); !ok {
+			return nil, errors.Errorf("%s returned an item which is not an object at index %d", env.PostProcessFileName, i)
+		}
+
+		data, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+
+		uns, _, err := unstructured.UnstructuredJSONScheme.Decode(data, nil, nil)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decoding object %d returned by %s", i, env.PostProcessFileName)
+		}
+		ret = append(ret, uns)
+	}
+
+	return k8s.FlattenToV1(ret)
+}
+
 func labelComponents(m map[string]interface{}, name string) {
 	if m["apiVersion"] == "v1" && m["kind"] == "List" {
 		list, ok := m["items"].([]interface{})
@@ -28,6 +28,7 @@ import (
 	"github.com/ksonnet/ksonnet/pkg/metadata"
 	"github.com/ksonnet/ksonnet/pkg/util/jsonnet"
 	"github.com/pkg/errors"
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -177,6 +178,7 @@ func TestPipeline_Objects(t *testing.T) {
 
 		env := &app.EnvironmentConfig{Path: "default"}
 		a.On("Environment", "default").Return(env, nil)
+		a.On("Fs").Return(afero.NewMemMapFs())
 
 		serviceJSON, err := ioutil.ReadFile(filepath.Join("testdata", "components.json"))
 		require.NoError(t, err)
@@ -195,6 +197,96 @@ func TestPipeline_Objects(t *testing.T) {
 	})
 }
 
+func TestPipeline_postProcess(t *testing.T) {
+	deployment := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]interface{}{"name": "guestbook"},
+		},
+	}
+
+	cases := []struct {
+		name      string
+		file      bool
+		processed string
+		expected  []*unstructured.Unstructured
+		isErr     bool
+	}{
+		{
+			name:     "without postprocess.libsonnet",
+			expected: []*unstructured.Unstructured{deployment},
+		},
+		{
+			name:      "lists are flattened",
+			file:      true,
+			processed: `[{"apiVersion": "v1", "kind": "List", "items": [{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "guestbook"}}]}]`,
+			expected: []*unstructured.Unstructured{
+				{
+					Object: map[string]interface{}{
+						"apiVersion": "v1",
+						"kind":       "Service",
+						"metadata":   map[string]interface{}{"name": "guestbook"},
+					},
+				},
+			},
+		},
+		{
+			name:      "not an array",
+			file:      true,
+			processed: `{}`,
+			isErr:     true,
+		},
+		{
+			name:      "item is not an object",
+			file:      true,
+			processed: `[1]`,
+			isErr:     true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withPipeline(t, func(p *Pipeline, m *cmocks.Manager, a *appmocks.App) {
+				module := &cmocks.Module{}
+				module.On("Name").Return("/")
+				module.On("ResolvedParams", "default").Return(`{"components": {}}`, nil)
+				m.On("Module", p.app, "/").Return(module, nil)
+
+				env := &app.EnvironmentConfig{Path: "default"}
+				a.On("Environment", "default").Return(env, nil)
+
+				fs := afero.NewMemMapFs()
+				a.On("Fs").Return(fs)
+
+				if tc.file {
+					require.NoError(t, afero.WriteFile(fs, "/environments/default/postprocess.libsonnet", []byte("function(objects) objects"), 0644))
+				}
+
+				p.evaluateEnvParamsFn = func(_ app.App, paramsPath, paramData, envName, moduleName string) (string, error) {
+					return `{"components": {}}`, nil
+				}
+
+				p.postProcessFn = func(_ app.App, envName, objects, paramsStr string, opts ...jsonnet.VMOpt) (string, error) {
+					assert.Equal(t, "default", envName)
+					assert.Equal(t, `[{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"guestbook"}}]`, objects)
+					assert.Equal(t, `{"components": {}}`, paramsStr)
+					return tc.processed, nil
+				}
+
+				got, err := p.postProcess([]*unstructured.Unstructured{deployment})
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+				require.NoError(t, err)
+
+				require.Equal(t, tc.expected, got)
+			})
+		})
+	}
+}
+
 func TestPipeline_YAML(t *testing.T) {
 	withPipeline(t, func(p *Pipeline, m *cmocks.Manager, a *appmocks.App) {
 		p.buildObjectsFn = func(_ *Pipeline, filter []string) ([]*unstructured.Unstructured, error) {
@@ -304,3 +396,33 @@ func withPipeline(t *testing.T, fn func(p *Pipeline, m *cmocks.Manager, a *appmo
 
 	fn(p, manager, a)
 }
+
+func TestPipeline_EvaluateSnippet(t *testing.T) {
+	withPipeline(t, func(p *Pipeline, m *cmocks.Manager, a *appmocks.App) {
+		module := &cmocks.Module{}
+		module.On("Name").Return("/")
+		module.On("ResolvedParams", "default").Return(`{"components": {}}`, nil)
+		m.On("Module", p.app, "/").Return(module, nil)
+
+		env := &app.EnvironmentConfig{Path: "default"}
+		a.On("Environment", "default").Return(env, nil)
+
+		p.evaluateEnvParamsFn = func(_ app.App, paramsPath, paramData, envName, moduleName string) (string, error) {
+			assert.Equal(t, `{"components": {}}`, paramData)
+			return `{"components": {"guestbook": {}}}`, nil
+		}
+
+		p.evaluateSnippetFn = func(_ app.App, envName, filename, snippet, paramsStr string, opts ...jsonnet.VMOpt) (string, error) {
+			assert.Equal(t, "default", envName)
+			assert.Equal(t, "<eval>", filename)
+			assert.Equal(t, "1 + 1", snippet)
+			assert.Equal(t, `{"components": {"guestbook": {}}}`, paramsStr)
+			return "2", nil
+		}
+
+		got, err := p.EvaluateSnippet("<eval>", "1 + 1")
+		require.NoError(t, err)
+
+		require.Equal(t, "2", got)
+	})
+}
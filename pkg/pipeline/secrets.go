@@ -0,0 +1,350 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package pipeline
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/env"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// transformSealed converts Secrets into SealedSecrets.
+	transformSealed = "sealed"
+	// transformExternal converts Secrets into ExternalSecrets.
+	transformExternal = "external"
+
+	// sealedSecretsCertificate is the default certificate of the sealed
+	// secrets controller, in the environment's directory.
+	sealedSecretsCertificate = "sealed-secrets.pem"
+	// sessionKeyBytes is the size of the AES key a value is sealed with.
+	sessionKeyBytes = 32
+)
+
+// transformSecrets converts the Secrets in objects as the environment's
+// secrets transform configures. Other objects are left as they are.
+func (p *Pipeline) transformSecrets(objects []*unstructured.Unstructured) ([]*unstructured.Unstructured, error) {
+	e, err := p.app.Environment(p.envName)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.Secrets == nil {
+		return objects, nil
+	}
+
+	var convert func(*unstructured.Unstructured) (*unstructured.Unstructured, error)
+
+	switch e.Secrets.Transform {
+	case transformSealed:
+		key, err := p.sealingKey(e.Secrets)
+		if err != nil {
+			return nil, err
+		}
+
+		namespace := "default"
+		if ns := e.PrimaryDestination().Namespace; ns != "" {
+			namespace = ns
+		}
+
+		convert = func(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+			return sealSecret(p.rand, key, namespace, obj)
+		}
+	case transformExternal:
+		if e.Secrets.Store == "" {
+			return nil, errors.Errorf("secrets of environment %q: external secrets require a store", p.envName)
+		}
+
+		convert = func(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+			return externalSecret(e.Secrets, obj)
+		}
+	default:
+		return nil, errors.Errorf("invalid secrets transform %q of environment %q; valid transforms are sealed and external",
+			e.Secrets.Transform, p.envName)
+	}
+
+	ret := make([]*unstructured.Unstructured, 0, len(objects))
+	for _, obj := range objects {
+		if obj.GetAPIVersion() != "v1" || obj.GetKind() != "Secret" {
+			ret = append(ret, obj)
+			continue
+		}
+
+		converted, err := convert(obj)
+		if err != nil {
+			return nil, errors.Wrapf(err, "converting Secret %s", obj.GetName())
+		}
+		ret = append(ret, converted)
+	}
+
+	return ret, nil
+}
+
+// sealingKey reads the public key of the sealed secrets controller from its
+// certificate.
+func (p *Pipeline) sealingKey(config *app.SecretsConfig) (*rsa.PublicKey, error) {
+	path := filepath.Join(p.app.Root(), config.Certificate)
+	if config.Certificate == "" {
+		var err error
+		if path, err = env.Path(p.app, p.envName, sealedSecretsCertificate); err != nil {
+			return nil, err
+		}
+	}
+
+	b, err := afero.ReadFile(p.app.Fs(), path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading sealed secrets certificate")
+	}
+
+	return parsePublicKey(b)
+}
+
+// parsePublicKey parses an RSA public key from a PEM certificate or public
+// key.
+func parsePublicKey(b []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New("sealed secrets certificate is not PEM encoded")
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing sealed secrets certificate")
+		}
+		key = cert.PublicKey
+	case "PUBLIC KEY":
+		var err error
+		if key, err = x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+			return nil, errors.Wrap(err, "parsing sealed secrets public key")
+		}
+	default:
+		return nil, errors.Errorf("unexpected %s in sealed secrets certificate", block.Type)
+	}
+
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("sealed secrets certificate does not hold an RSA key")
+	}
+
+	return rsaKey, nil
+}
+
+// secretData returns the values of a Secret's data and stringData.
+func secretData(obj *unstructured.Unstructured) (map[string][]byte, error) {
+	data := make(map[string][]byte)
+
+	encoded, _, err := unstructured.NestedStringMap(obj.Object, "data")
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range encoded {
+		b, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decoding data %s", k)
+		}
+		data[k] = b
+	}
+
+	stringData, _, err := unstructured.NestedStringMap(obj.Object, "stringData")
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range stringData {
+		data[k] = []byte(v)
+	}
+
+	return data, nil
+}
+
+// secretMetadata returns the metadata of a converted Secret.
+func secretMetadata(obj *unstructured.Unstructured, namespace string) map[string]interface{} {
+	m := map[string]interface{}{
+		"name": obj.GetName(),
+	}
+	if namespace != "" {
+		m["namespace"] = namespace
+	}
+	if labels := obj.GetLabels(); len(labels) > 0 {
+		m["labels"] = stringMap(labels)
+	}
+	if annotations := obj.GetAnnotations(); len(annotations) > 0 {
+		m["annotations"] = stringMap(annotations)
+	}
+
+	return m
+}
+
+func stringMap(m map[string]string) map[string]interface{} {
+	ret := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		ret[k] = v
+	}
+	return ret
+}
+
+// sealSecret converts a Secret into a SealedSecret, which only the sealed
+// secrets controller of the cluster can decrypt. Values are sealed in the
+// strict scope, so they can only be unsealed with the Secret's name and
+// namespace. Secrets without a namespace are sealed for defaultNamespace.
+func sealSecret(rnd io.Reader, key *rsa.PublicKey, defaultNamespace string, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	namespace := obj.GetNamespace()
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+
+	data, err := secretData(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	label := []byte(namespace + "/" + obj.GetName())
+	encryptedData := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		sealed, err := hybridEncrypt(rnd, key, v, label)
+		if err != nil {
+			return nil, errors.Wrapf(err, "sealing data %s", k)
+		}
+		encryptedData[k] = base64.StdEncoding.EncodeToString(sealed)
+	}
+
+	template := map[string]interface{}{
+		"metadata": secretMetadata(obj, namespace),
+	}
+	if secretType, ok := obj.Object["type"]; ok {
+		template["type"] = secretType
+	}
+
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "bitnami.com/v1alpha1",
+		"kind":       "SealedSecret",
+		"metadata":   secretMetadata(obj, namespace),
+		"spec": map[string]interface{}{
+			"encryptedData": encryptedData,
+			"template":      template,
+		},
+	}}, nil
+}
+
+// hybridEncrypt encrypts plaintext as the sealed secrets controller expects:
+// a random AES-GCM session key, encrypted with RSA-OAEP and prefixed by its
+// length, followed by the plaintext encrypted with the session key.
+func hybridEncrypt(rnd io.Reader, key *rsa.PublicKey, plaintext, label []byte) ([]byte, error) {
+	sessionKey := make([]byte, sessionKeyBytes)
+	if _, err := io.ReadFull(rnd, sessionKey); err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaCiphertext, err := rsa.EncryptOAEP(sha256.New(), rnd, key, sessionKey, label)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext := make([]byte, 2)
+	binary.BigEndian.PutUint16(ciphertext, uint16(len(rsaCiphertext)))
+	ciphertext = append(ciphertext, rsaCiphertext...)
+
+	// The session key is only used once, so the nonce can be zero.
+	zeroNonce := make([]byte, aead.NonceSize())
+	return aead.Seal(ciphertext, zeroNonce, plaintext, nil), nil
+}
+
+// externalSecret converts a Secret into an ExternalSecret, which reads the
+// Secret's data from a secret store. Each key of the Secret is read from the
+// property of the same name, of the store's key for the Secret.
+func externalSecret(config *app.SecretsConfig, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	data, err := secretData(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	storeKind := config.StoreKind
+	if storeKind == "" {
+		storeKind = "SecretStore"
+	}
+
+	refreshInterval := config.RefreshInterval
+	if refreshInterval == "" {
+		refreshInterval = "1h"
+	}
+
+	remoteKey := config.KeyPrefix + obj.GetName()
+
+	var keys []string
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var refs []interface{}
+	for _, k := range keys {
+		refs = append(refs, map[string]interface{}{
+			"secretKey": k,
+			"remoteRef": map[string]interface{}{
+				"key":      remoteKey,
+				"property": k,
+			},
+		})
+	}
+
+	target := map[string]interface{}{
+		"name": obj.GetName(),
+	}
+	if secretType, ok := obj.Object["type"]; ok {
+		target["template"] = map[string]interface{}{"type": secretType}
+	}
+
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "external-secrets.io/v1beta1",
+		"kind":       "ExternalSecret",
+		"metadata":   secretMetadata(obj, obj.GetNamespace()),
+		"spec": map[string]interface{}{
+			"refreshInterval": refreshInterval,
+			"secretStoreRef": map[string]interface{}{
+				"name": config.Store,
+				"kind": storeKind,
+			},
+			"target": target,
+			"data":   refs,
+		},
+	}}, nil
+}
@@ -0,0 +1,212 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package pipeline
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	appmocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	cmocks "github.com/ksonnet/ksonnet/pkg/component/mocks"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func secretObject(namespace, name string) *unstructured.Unstructured {
+	obj := renderedObject("db", "v1", "Secret", namespace, name)
+	obj.Object["type"] = "Opaque"
+	obj.Object["data"] = map[string]interface{}{
+		"password": base64.StdEncoding.EncodeToString([]byte("hunter2")),
+	}
+	obj.Object["stringData"] = map[string]interface{}{
+		"user": "admin",
+	}
+	return obj
+}
+
+func unseal(t *testing.T, key *rsa.PrivateKey, label, value string) string {
+	ciphertext, err := base64.StdEncoding.DecodeString(value)
+	require.NoError(t, err)
+
+	n := int(binary.BigEndian.Uint16(ciphertext))
+	sessionKey, err := rsa.DecryptOAEP(sha256.New(), nil, key, ciphertext[2:2+n], []byte(label))
+	require.NoError(t, err)
+
+	block, err := aes.NewCipher(sessionKey)
+	require.NoError(t, err)
+	aead, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+
+	plaintext, err := aead.Open(nil, make([]byte, aead.NonceSize()), ciphertext[2+n:], nil)
+	require.NoError(t, err)
+
+	return string(plaintext)
+}
+
+func TestPipeline_transformSecrets_sealed(t *testing.T) {
+	withPipeline(t, func(p *Pipeline, m *cmocks.Manager, a *appmocks.App) {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		require.NoError(t, err)
+
+		fs := afero.NewMemMapFs()
+		a.On("Fs").Return(fs)
+		a.On("Environment", "default").Return(&app.EnvironmentConfig{
+			Path:        "default",
+			Destination: &app.EnvironmentDestinationSpec{Namespace: "prod"},
+			Secrets:     &app.SecretsConfig{Transform: "sealed"},
+		}, nil)
+
+		require.NoError(t, afero.WriteFile(fs, "/environments/default/sealed-secrets.pem",
+			pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0644))
+
+		objects := []*unstructured.Unstructured{
+			secretObject("", "db"),
+			renderedObject("db", "v1", "Service", "", "db"),
+		}
+
+		got, err := p.transformSecrets(objects)
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+
+		sealed := got[0]
+		assert.Equal(t, "bitnami.com/v1alpha1", sealed.GetAPIVersion())
+		assert.Equal(t, "SealedSecret", sealed.GetKind())
+		assert.Equal(t, "db", sealed.GetName())
+		assert.Equal(t, "prod", sealed.GetNamespace())
+		assert.Equal(t, map[string]string{"ksonnet.io/component": "db"}, sealed.GetLabels())
+
+		secretType, _, err := unstructured.NestedString(sealed.Object, "spec", "template", "type")
+		require.NoError(t, err)
+		assert.Equal(t, "Opaque", secretType)
+
+		encryptedData, _, err := unstructured.NestedStringMap(sealed.Object, "spec", "encryptedData")
+		require.NoError(t, err)
+		require.Len(t, encryptedData, 2)
+		assert.Equal(t, "hunter2", unseal(t, key, "prod/db", encryptedData["password"]))
+		assert.Equal(t, "admin", unseal(t, key, "prod/db", encryptedData["user"]))
+
+		assert.Equal(t, objects[1], got[1])
+	})
+}
+
+func TestPipeline_transformSecrets_external(t *testing.T) {
+	withPipeline(t, func(p *Pipeline, m *cmocks.Manager, a *appmocks.App) {
+		a.On("Environment", "default").Return(&app.EnvironmentConfig{
+			Secrets: &app.SecretsConfig{
+				Transform: "external",
+				Store:     "vault",
+				KeyPrefix: "apps/",
+			},
+		}, nil)
+
+		got, err := p.transformSecrets([]*unstructured.Unstructured{secretObject("prod", "db")})
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+
+		expected := map[string]interface{}{
+			"apiVersion": "external-secrets.io/v1beta1",
+			"kind":       "ExternalSecret",
+			"metadata": map[string]interface{}{
+				"name":      "db",
+				"namespace": "prod",
+				"labels":    map[string]interface{}{"ksonnet.io/component": "db"},
+			},
+			"spec": map[string]interface{}{
+				"refreshInterval": "1h",
+				"secretStoreRef": map[string]interface{}{
+					"name": "vault",
+					"kind": "SecretStore",
+				},
+				"target": map[string]interface{}{
+					"name":     "db",
+					"template": map[string]interface{}{"type": "Opaque"},
+				},
+				"data": []interface{}{
+					map[string]interface{}{
+						"secretKey": "password",
+						"remoteRef": map[string]interface{}{"key": "apps/db", "property": "password"},
+					},
+					map[string]interface{}{
+						"secretKey": "user",
+						"remoteRef": map[string]interface{}{"key": "apps/db", "property": "user"},
+					},
+				},
+			},
+		}
+
+		assert.Equal(t, expected, got[0].Object)
+	})
+}
+
+func TestPipeline_transformSecrets_invalid(t *testing.T) {
+	cases := []struct {
+		name   string
+		config *app.SecretsConfig
+		isErr  bool
+	}{
+		{
+			name: "not configured",
+		},
+		{
+			name:   "unknown transform",
+			config: &app.SecretsConfig{Transform: "vault"},
+			isErr:  true,
+		},
+		{
+			name:   "external without store",
+			config: &app.SecretsConfig{Transform: "external"},
+			isErr:  true,
+		},
+		{
+			name:   "sealed without certificate",
+			config: &app.SecretsConfig{Transform: "sealed", Certificate: "missing.pem"},
+			isErr:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withPipeline(t, func(p *Pipeline, m *cmocks.Manager, a *appmocks.App) {
+				a.On("Fs").Return(afero.NewMemMapFs())
+				a.On("Environment", "default").Return(&app.EnvironmentConfig{Secrets: tc.config}, nil)
+
+				objects := []*unstructured.Unstructured{secretObject("prod", "db")}
+
+				got, err := p.transformSecrets(objects)
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+
+				require.NoError(t, err)
+				assert.Equal(t, objects, got)
+			})
+		})
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package pipeline
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/ksonnet/ksonnet/pkg/component"
+	clustermetadata "github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// SourceMap annotates the objects a pipeline renders with their source,
+// whether or not their environment enables it.
+func SourceMap() Opt {
+	return func(p *Pipeline) {
+		p.sourceMap = true
+	}
+}
+
+// componentSource is the file of a component, and the prototype and package
+// it was generated from.
+type componentSource struct {
+	path   string
+	origin component.Origin
+}
+
+// annotateSources annotates objects with the component, prototype and
+// package which rendered them, if the environment enables its source map.
+// Objects which aren't labeled with a component are left as they are.
+func (p *Pipeline) annotateSources(objects []*unstructured.Unstructured) error {
+	if !p.sourceMap {
+		e, err := p.app.Environment(p.envName)
+		if err != nil {
+			return err
+		}
+
+		if !e.SourceMap {
+			return nil
+		}
+	}
+
+	sources, err := p.componentSources()
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range objects {
+		source, ok := sources[objectComponent(obj)]
+		if !ok {
+			continue
+		}
+
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+
+		annotations[clustermetadata.AnnotationSourceComponent] = source.path
+		if source.origin.Prototype != "" {
+			annotations[clustermetadata.AnnotationSourcePrototype] = source.origin.Prototype
+		}
+		if source.origin.Package != "" {
+			annotations[clustermetadata.AnnotationSourcePackage] = source.origin.Package
+		}
+
+		obj.SetAnnotations(annotations)
+	}
+
+	return nil
+}
+
+// componentSources indexes the sources of the environment's components by
+// their namespaced names.
+func (p *Pipeline) componentSources() (map[string]componentSource, error) {
+	modules, err := p.Modules()
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make(map[string]componentSource)
+	for _, m := range modules {
+		components, err := m.Components()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, c := range components {
+			path := filepath.Join(m.Dir(), c.Name(false)+"."+c.Type())
+
+			rel, err := filepath.Rel(p.app.Root(), path)
+			if err != nil {
+				return nil, err
+			}
+
+			source := componentSource{path: filepath.ToSlash(rel)}
+
+			b, err := afero.ReadFile(p.app.Fs(), path)
+			switch {
+			case err == nil:
+				source.origin = component.ReadOrigin(string(b))
+			case !os.IsNotExist(err):
+				return nil, err
+			}
+
+			sources[c.Name(true)] = source
+		}
+	}
+
+	return sources, nil
+}
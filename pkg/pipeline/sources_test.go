@@ -0,0 +1,123 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	appmocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/component"
+	cmocks "github.com/ksonnet/ksonnet/pkg/component/mocks"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestPipeline_annotateSources(t *testing.T) {
+	cases := []struct {
+		name      string
+		sourceMap bool
+		opt       bool
+		expected  []map[string]string
+	}{
+		{
+			name: "disabled",
+			expected: []map[string]string{
+				nil,
+				nil,
+				nil,
+			},
+		},
+		{
+			name:      "enabled by environment",
+			sourceMap: true,
+			expected: []map[string]string{
+				{
+					"ksonnet.io/source-component": "components/web.jsonnet",
+					"ksonnet.io/source-prototype": "io.ksonnet.pkg.redis",
+					"ksonnet.io/source-package":   "incubator/redis@1.0.0",
+				},
+				{
+					"ksonnet.io/source-component": "components/db.yaml",
+				},
+				nil,
+			},
+		},
+		{
+			name: "enabled by option",
+			opt:  true,
+			expected: []map[string]string{
+				{
+					"ksonnet.io/source-component": "components/web.jsonnet",
+					"ksonnet.io/source-prototype": "io.ksonnet.pkg.redis",
+					"ksonnet.io/source-package":   "incubator/redis@1.0.0",
+				},
+				{
+					"ksonnet.io/source-component": "components/db.yaml",
+				},
+				nil,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withPipeline(t, func(p *Pipeline, m *cmocks.Manager, a *appmocks.App) {
+				fs := afero.NewMemMapFs()
+				a.On("Fs").Return(fs)
+				a.On("Environment", "default").Return(&app.EnvironmentConfig{SourceMap: tc.sourceMap}, nil)
+
+				require.NoError(t, afero.WriteFile(fs, "/components/web.jsonnet",
+					[]byte("// @prototype io.ksonnet.pkg.redis\n// @package incubator/redis@1.0.0\n{}"), 0644))
+				require.NoError(t, afero.WriteFile(fs, "/components/db.yaml", []byte("kind: Service"), 0644))
+
+				web := &cmocks.Component{}
+				web.On("Name", false).Return("web")
+				web.On("Name", true).Return("web")
+				web.On("Type").Return("jsonnet")
+
+				db := &cmocks.Component{}
+				db.On("Name", false).Return("db")
+				db.On("Name", true).Return("db")
+				db.On("Type").Return("yaml")
+
+				module := &cmocks.Module{}
+				module.On("Dir").Return("/components")
+				module.On("Components").Return([]component.Component{web, db}, nil)
+				m.On("Modules", p.app, "default").Return([]component.Module{module}, nil)
+
+				if tc.opt {
+					SourceMap()(p)
+				}
+
+				objects := []*unstructured.Unstructured{
+					renderedObject("web", "apps/v1", "Deployment", "default", "web"),
+					renderedObject("db", "v1", "Service", "default", "db"),
+					renderedObject("", "v1", "ConfigMap", "default", "postprocessed"),
+				}
+
+				err := p.annotateSources(objects)
+				require.NoError(t, err)
+
+				for i := range objects {
+					assert.Equal(t, tc.expected[i], objects[i].GetAnnotations())
+				}
+			})
+		})
+	}
+}
@@ -95,6 +95,11 @@ func (l *Local) Description() string {
 	return l.config.Description
 }
 
+// Migrations returns the parameter migrations declared by the package.
+func (l *Local) Migrations() parts.MigrationSpecs {
+	return l.config.Migrations
+}
+
 // Prototypes returns prototypes for this package. Prototypes are defined in the
 // package's `prototypes` directory.
 func (l *Local) Prototypes() (prototype.Prototypes, error) {
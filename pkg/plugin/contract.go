@@ -0,0 +1,96 @@
+// Copyright 2018 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package plugin
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/ksonnet/ksonnet/pkg/util/event"
+	"github.com/pkg/errors"
+)
+
+const (
+	// APIVersion is the version of the contract between ks and its plugins.
+	APIVersion = "ksonnet.io/plugin/v1"
+
+	// EnvAPIVersion is the environment variable which holds the version of
+	// the contract.
+	EnvAPIVersion = "KS_PLUGIN_API_VERSION"
+	// EnvRequest is the environment variable which holds the path of the
+	// request file.
+	EnvRequest = "KS_PLUGIN_REQUEST"
+)
+
+// Request is the context a plugin is run in. It is written as JSON to the
+// file in $KS_PLUGIN_REQUEST. Fields are only added to a version of the
+// contract; changing or removing them requires a new version.
+type Request struct {
+	// APIVersion is the version of the contract.
+	APIVersion string `json:"apiVersion"`
+	// Plugin is the name of the plugin.
+	Plugin string `json:"plugin"`
+	// Args are the arguments the plugin was run with.
+	Args []string `json:"args"`
+	// App is the app the plugin was run in. It is not set outside of an app.
+	App *AppContext `json:"app,omitempty"`
+	// Events are the events ks emitted while preparing the request, e.g.
+	// while rendering the manifests.
+	Events []event.Record `json:"events,omitempty"`
+}
+
+// AppContext describes the app a plugin was run in.
+type AppContext struct {
+	// Root is the root directory of the app.
+	Root string `json:"root"`
+	// Environment is the current environment of the app.
+	Environment string `json:"environment,omitempty"`
+	// Manifests are the rendered objects of the environment. They are only
+	// rendered for plugins which need them.
+	Manifests []map[string]interface{} `json:"manifests,omitempty"`
+}
+
+// NewRequest creates an instance of Request for the current version of the
+// contract.
+func NewRequest(name string, args []string) *Request {
+	if args == nil {
+		args = []string{}
+	}
+
+	return &Request{
+		APIVersion: APIVersion,
+		Plugin:     name,
+		Args:       args,
+	}
+}
+
+// Write writes the request as JSON.
+func (r *Request) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// CheckAPIVersion returns an error if a plugin uses a version of the contract
+// ks does not support. A blank version is the current version.
+func CheckAPIVersion(version string) error {
+	switch version {
+	case "", APIVersion:
+		return nil
+	default:
+		return errors.Errorf("plugin API version %q is not supported; ks supports %q", version, APIVersion)
+	}
+}
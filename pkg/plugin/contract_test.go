@@ -0,0 +1,79 @@
+// Copyright 2018 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequest_Write(t *testing.T) {
+	req := NewRequest("hello", nil)
+	req.App = &AppContext{
+		Root:        "/app",
+		Environment: "default",
+		Manifests: []map[string]interface{}{
+			{"apiVersion": "v1", "kind": "Service"},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, req.Write(&buf))
+
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+
+	expected := map[string]interface{}{
+		"apiVersion": APIVersion,
+		"plugin":     "hello",
+		"args":       []interface{}{},
+		"app": map[string]interface{}{
+			"root":        "/app",
+			"environment": "default",
+			"manifests": []interface{}{
+				map[string]interface{}{"apiVersion": "v1", "kind": "Service"},
+			},
+		},
+	}
+
+	assert.Equal(t, expected, got)
+}
+
+func TestCheckAPIVersion(t *testing.T) {
+	cases := []struct {
+		version string
+		isErr   bool
+	}{
+		{version: ""},
+		{version: APIVersion},
+		{version: "ksonnet.io/plugin/v2", isErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.version, func(t *testing.T) {
+			err := CheckAPIVersion(tc.version)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
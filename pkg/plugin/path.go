@@ -0,0 +1,72 @@
+// Copyright 2018 The kubecfg authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// BinaryPrefix is the prefix of plugin binaries on the PATH.
+const BinaryPrefix = "ks-"
+
+// listPath lists the plugins which are executables named ks-<name> in the
+// directories of a PATH. If a name is in more than one directory, the first
+// one is used.
+func listPath(fs afero.Fs, path string) ([]Plugin, error) {
+	plugins := make([]Plugin, 0)
+	seen := make(map[string]bool)
+
+	for _, dir := range filepath.SplitList(path) {
+		if dir == "" {
+			continue
+		}
+
+		fis, err := afero.ReadDir(fs, dir)
+		if err != nil {
+			if os.IsNotExist(err) || os.IsPermission(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		for _, fi := range fis {
+			name := strings.TrimSuffix(fi.Name(), ".exe")
+			if !strings.HasPrefix(name, BinaryPrefix) || fi.IsDir() || fi.Mode()&0111 == 0 {
+				continue
+			}
+
+			name = strings.TrimPrefix(name, BinaryPrefix)
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			plugins = append(plugins, Plugin{
+				RootDir: dir,
+				Config: Config{
+					Name:    name,
+					Command: filepath.Join(dir, fi.Name()),
+				},
+			})
+		}
+	}
+
+	return plugins, nil
+}
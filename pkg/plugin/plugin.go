@@ -38,6 +38,12 @@ type Config struct {
 	IgnoreFlags bool `yaml:"ignore_flags,omitempty"`
 	// Command is the command that needs to be called to invoke the plugin.
 	Command string `yaml:"command,omitempty"`
+	// APIVersion is the version of the plugin contract the plugin uses. It
+	// defaults to the current version.
+	APIVersion string `yaml:"api_version,omitempty"`
+	// Manifests is set if the plugin needs the rendered manifests of the
+	// current environment.
+	Manifests bool `yaml:"manifests,omitempty"`
 }
 
 func readConfig(fs afero.Fs, path string) (Config, error) {
@@ -68,6 +74,7 @@ func (p *Plugin) BuildRunCmd(env, args []string) *exec.Cmd {
 	cmd := exec.Command(bin, args...)
 	cmd.Env = env
 
+	cmd.Stdin = os.Stdin
 	cmd.Stderr = os.Stderr
 	cmd.Stdout = os.Stdout
 
@@ -115,8 +122,36 @@ func Find(fs afero.Fs, name string) (Plugin, error) {
 	return Plugin{}, errors.Errorf("%s is not a known plugin", name)
 }
 
-// List plugins
+// List lists the plugins configured in the plugin directory, followed by the
+// ks-<name> executables on the PATH. A configured plugin takes precedence over
+// an executable with the same name.
 func List(fs afero.Fs) ([]Plugin, error) {
+	plugins, err := listConfigured(fs)
+	if err != nil {
+		return nil, err
+	}
+
+	onPath, err := listPath(fs, os.Getenv("PATH"))
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for _, plugin := range plugins {
+		seen[plugin.Config.Name] = true
+	}
+
+	for _, plugin := range onPath {
+		if !seen[plugin.Config.Name] {
+			plugins = append(plugins, plugin)
+		}
+	}
+
+	return plugins, nil
+}
+
+// listConfigured lists the plugins in the plugin directory.
+func listConfigured(fs afero.Fs) ([]Plugin, error) {
 	rootPath, err := pluginDir()
 	if err != nil {
 		return []Plugin{}, err
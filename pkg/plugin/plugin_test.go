@@ -44,6 +44,39 @@ func TestList(t *testing.T) {
 	})
 }
 
+func TestList_path(t *testing.T) {
+	withPluginEnv(t, func(fs afero.Fs) {
+		for path, mode := range map[string]os.FileMode{
+			"/home/app/bin/ks-hello":    0755,
+			"/home/app/bin/ks-deploy":   0755,
+			"/home/app/bin/ks-data":     0644,
+			"/home/app/bin/kubectl":     0755,
+			"/usr/local/bin/ks-deploy":  0755,
+			"/usr/local/bin/ks-release": 0755,
+		} {
+			require.NoError(t, afero.WriteFile(fs, path, []byte("#!/bin/sh"), mode))
+		}
+
+		plugins, err := List(fs)
+		require.NoError(t, err)
+
+		var names, commands []string
+		for _, plugin := range plugins {
+			names = append(names, plugin.Config.Name)
+			commands = append(commands, plugin.Config.Command)
+		}
+
+		// the configured hello plugin takes precedence over ks-hello, and the
+		// first ks-deploy on the PATH is used.
+		assert.Equal(t, []string{"hello", "deploy", "release"}, names)
+		assert.Equal(t, []string{
+			"$KS_PLUGIN_DIR/hello.sh",
+			"/home/app/bin/ks-deploy",
+			"/usr/local/bin/ks-release",
+		}, commands)
+	})
+}
+
 func TestFind(t *testing.T) {
 	cases := []struct {
 		name  string
@@ -82,6 +115,7 @@ func TestBuildRunCmd(t *testing.T) {
 
 	args := []string{"--arg1", "--foo=2", "single"}
 	cmd := plugin.BuildRunCmd(env, args)
+	assert.Equal(t, os.Stdin, cmd.Stdin)
 	assert.Equal(t, os.Stderr, cmd.Stderr)
 	assert.Equal(t, os.Stdout, cmd.Stdout)
 	assert.Equal(t, env, cmd.Env)
@@ -96,6 +130,10 @@ func withPluginEnv(t *testing.T, fn pluginFn) {
 	os.Setenv("HOME", "/home/app")
 	defer os.Setenv("HOME", ogHome)
 
+	ogPath := os.Getenv("PATH")
+	os.Setenv("PATH", "/home/app/bin:/usr/local/bin")
+	defer os.Setenv("PATH", ogPath)
+
 	fs := afero.NewMemMapFs()
 
 	fs.MkdirAll("/home/app/.config/ksonnet/plugins", 0755)
@@ -0,0 +1,197 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package policy checks rendered manifests against policies written in Rego.
+package policy
+
+import (
+	"io"
+	"path/filepath"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/util/table"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// LevelDeny is the level of violations which fail a command.
+	LevelDeny = "deny"
+	// LevelWarn is the level of violations which are only reported.
+	LevelWarn = "warn"
+)
+
+// Violation is a policy violation by an object.
+type Violation struct {
+	Policy    string `json:"policy"`
+	Level     string `json:"level"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Message   string `json:"message"`
+}
+
+// Result is the result of checking objects against policies.
+type Result struct {
+	Violations []Violation `json:"violations"`
+}
+
+// NewResult creates an instance of Result.
+func NewResult() *Result {
+	return &Result{Violations: []Violation{}}
+}
+
+// Denied reports if any violation is at the deny level.
+func (r *Result) Denied() bool {
+	for _, v := range r.Violations {
+		if v.Level == LevelDeny {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Err returns an error if any violation is at the deny level.
+func (r *Result) Err() error {
+	denied := 0
+	for _, v := range r.Violations {
+		if v.Level == LevelDeny {
+			denied++
+		}
+	}
+
+	if denied > 0 {
+		return errors.Errorf("%d policy violation(s) denied", denied)
+	}
+
+	return nil
+}
+
+// Render writes the violations as a table, or the result as JSON or YAML.
+func (r *Result) Render(w io.Writer, output string) error {
+	f, err := table.DetectFormat(output)
+	if err != nil {
+		return errors.Wrap(err, "detecting output format")
+	}
+
+	if f != table.FormatTable {
+		return table.Encode(w, f, r)
+	}
+
+	t := table.New("policyViolations", w)
+	t.SetHeader([]string{"level", "policy", "kind", "name", "message"})
+
+	for _, v := range r.Violations {
+		name := v.Name
+		if v.Namespace != "" {
+			name = v.Namespace + "/" + v.Name
+		}
+		t.Append([]string{v.Level, v.Policy, v.Kind, name, v.Message})
+	}
+
+	return t.Render()
+}
+
+// Evaluator evaluates a policy against an object. It returns the messages of
+// the policy's deny and warn rules.
+type Evaluator interface {
+	Evaluate(path string, obj map[string]interface{}) (deny, warn []string, err error)
+}
+
+// Checker checks objects against policies.
+type Checker struct {
+	root      string
+	evaluator Evaluator
+}
+
+// NewChecker creates an instance of Checker. Policy paths are relative to root.
+func NewChecker(root string, evaluator Evaluator) *Checker {
+	return &Checker{
+		root:      root,
+		evaluator: evaluator,
+	}
+}
+
+// Check checks objects against policies.
+func (c *Checker) Check(policies []*app.PolicyConfig, objects []*unstructured.Unstructured) (*Result, error) {
+	result := NewResult()
+
+	for _, p := range policies {
+		if p == nil {
+			continue
+		}
+
+		switch p.Level {
+		case "", LevelDeny, LevelWarn:
+		default:
+			return nil, errors.Errorf("policy %s has invalid level %q; valid levels are deny and warn", p.Path, p.Level)
+		}
+
+		path := p.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(c.root, path)
+		}
+
+		for _, obj := range objects {
+			deny, warn, err := c.evaluator.Evaluate(path, obj.Object)
+			if err != nil {
+				return nil, errors.Wrapf(err, "evaluating policy %s", p.Path)
+			}
+
+			denyLevel := LevelDeny
+			if p.Level == LevelWarn {
+				denyLevel = LevelWarn
+			}
+
+			for _, msg := range deny {
+				result.Violations = append(result.Violations, newViolation(p.Path, denyLevel, obj, msg))
+			}
+			for _, msg := range warn {
+				result.Violations = append(result.Violations, newViolation(p.Path, LevelWarn, obj, msg))
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func newViolation(policy, level string, obj *unstructured.Unstructured, msg string) Violation {
+	return Violation{
+		Policy:    policy,
+		Level:     level,
+		Kind:      obj.GetKind(),
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Message:   msg,
+	}
+}
+
+// Check checks objects against the policies of an environment with the Rego
+// evaluator.
+func Check(a app.App, envName string, objects []*unstructured.Unstructured) (*Result, error) {
+	env, err := a.Environment(envName)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(env.Policies) == 0 {
+		return NewResult(), nil
+	}
+
+	log.Debugf("checking %d object(s) against %d policy(s)", len(objects), len(env.Policies))
+	return NewChecker(a.Root(), NewRegoEvaluator()).Check(env.Policies, objects)
+}
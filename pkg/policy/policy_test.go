@@ -0,0 +1,135 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// fakeEvaluator denies objects named "bad", and warns about objects without a namespace.
+type fakeEvaluator struct {
+	paths []string
+}
+
+func (e *fakeEvaluator) Evaluate(path string, obj map[string]interface{}) ([]string, []string, error) {
+	e.paths = append(e.paths, path)
+
+	u := &unstructured.Unstructured{Object: obj}
+
+	var deny, warn []string
+	if u.GetName() == "bad" {
+		deny = append(deny, "image uses the latest tag")
+	}
+	if u.GetNamespace() == "" {
+		warn = append(warn, "namespace is not set")
+	}
+
+	return deny, warn, nil
+}
+
+func newObject(namespace, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": name},
+	}}
+	if namespace != "" {
+		obj.SetNamespace(namespace)
+	}
+
+	return obj
+}
+
+func TestChecker_Check(t *testing.T) {
+	objects := []*unstructured.Unstructured{
+		newObject("web", "good"),
+		newObject("", "bad"),
+	}
+
+	cases := []struct {
+		name     string
+		level    string
+		expected []Violation
+		denied   bool
+		isErr    bool
+	}{
+		{
+			name: "deny",
+			expected: []Violation{
+				{Policy: "policies/images.rego", Level: LevelDeny, Kind: "Deployment", Name: "bad", Message: "image uses the latest tag"},
+				{Policy: "policies/images.rego", Level: LevelWarn, Kind: "Deployment", Name: "bad", Message: "namespace is not set"},
+			},
+			denied: true,
+		},
+		{
+			name:  "downgraded to warn",
+			level: LevelWarn,
+			expected: []Violation{
+				{Policy: "policies/images.rego", Level: LevelWarn, Kind: "Deployment", Name: "bad", Message: "image uses the latest tag"},
+				{Policy: "policies/images.rego", Level: LevelWarn, Kind: "Deployment", Name: "bad", Message: "namespace is not set"},
+			},
+		},
+		{
+			name:  "invalid level",
+			level: "block",
+			isErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			evaluator := &fakeEvaluator{}
+			c := NewChecker("/app", evaluator)
+
+			policies := []*app.PolicyConfig{{Path: "policies/images.rego", Level: tc.level}}
+			result, err := c.Check(policies, objects)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected, result.Violations)
+			assert.Equal(t, tc.denied, result.Denied())
+			assert.Equal(t, tc.denied, result.Err() != nil)
+			assert.Equal(t, []string{"/app/policies/images.rego", "/app/policies/images.rego"}, evaluator.paths)
+		})
+	}
+}
+
+func TestResult_Render(t *testing.T) {
+	result := &Result{Violations: []Violation{
+		{Policy: "images.rego", Level: LevelDeny, Kind: "Deployment", Namespace: "web", Name: "app", Message: "image uses the latest tag"},
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, result.Render(&buf, ""))
+	assert.Regexp(t, `deny\s+images.rego\s+Deployment\s+web/app\s+image uses the latest tag`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, result.Render(&buf, "json"))
+
+	var decoded Result
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, result, &decoded)
+}
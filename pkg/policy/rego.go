@@ -0,0 +1,144 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// EnvOPA is the environment variable which holds the path of the opa binary.
+	EnvOPA = "KS_OPA"
+
+	defaultOPA = "opa"
+
+	// regoQuery is the package of the deny and warn rules. Policies follow the
+	// conftest convention: rules in package main which produce messages.
+	regoQuery = "data.main"
+)
+
+// RegoEvaluator evaluates Rego policies with the Open Policy Agent binary.
+type RegoEvaluator struct {
+	program string
+	runFn   func(*exec.Cmd) ([]byte, error)
+}
+
+var _ Evaluator = (*RegoEvaluator)(nil)
+
+// NewRegoEvaluator creates an instance of RegoEvaluator. The opa binary is
+// found in $KS_OPA or the path.
+func NewRegoEvaluator() *RegoEvaluator {
+	program := os.Getenv(EnvOPA)
+	if program == "" {
+		program = defaultOPA
+	}
+
+	return &RegoEvaluator{
+		program: program,
+		runFn:   runCmd,
+	}
+}
+
+// Evaluate evaluates the deny and warn rules of a policy with the object as input.
+func (e *RegoEvaluator) Evaluate(path string, obj map[string]interface{}) ([]string, []string, error) {
+	input, err := json.Marshal(obj)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cmd := exec.Command(e.program, "eval", "--format", "json", "--stdin-input", "--data", path, regoQuery)
+	cmd.Stdin = bytes.NewReader(input)
+
+	out, err := e.runFn(cmd)
+	if err != nil {
+		if execErr, ok := err.(*exec.Error); ok && execErr.Err == exec.ErrNotFound {
+			return nil, nil, errors.Errorf("policy engine %q was not found; install Open Policy Agent (https://www.openpolicyagent.org) or set $%s", e.program, EnvOPA)
+		}
+		return nil, nil, err
+	}
+
+	var resp struct {
+		Result []struct {
+			Expressions []struct {
+				Value map[string]interface{} `json:"value"`
+			} `json:"expressions"`
+		} `json:"result"`
+	}
+	if err = json.Unmarshal(out, &resp); err != nil {
+		return nil, nil, errors.Wrap(err, "parsing opa output")
+	}
+
+	var deny, warn []string
+	for _, r := range resp.Result {
+		for _, expr := range r.Expressions {
+			deny = append(deny, messages(expr.Value["deny"])...)
+			warn = append(warn, messages(expr.Value["warn"])...)
+		}
+	}
+
+	return deny, warn, nil
+}
+
+// messages returns the messages of a rule. Rules produce strings, or objects
+// with a msg field.
+func messages(v interface{}) []string {
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var msgs []string
+	for _, item := range items {
+		switch t := item.(type) {
+		case string:
+			msgs = append(msgs, t)
+		case map[string]interface{}:
+			if msg, ok := t["msg"].(string); ok {
+				msgs = append(msgs, msg)
+				continue
+			}
+			msgs = append(msgs, fmt.Sprintf("%v", t))
+		default:
+			msgs = append(msgs, fmt.Sprintf("%v", t))
+		}
+	}
+
+	sort.Strings(msgs)
+	return msgs
+}
+
+func runCmd(cmd *exec.Cmd) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return nil, errors.Errorf("opa eval failed: %s", strings.TrimSpace(stderr.String()))
+		}
+		return nil, err
+	}
+
+	return out, nil
+}
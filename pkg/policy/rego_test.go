@@ -0,0 +1,63 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package policy
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegoEvaluator_Evaluate(t *testing.T) {
+	e := &RegoEvaluator{
+		program: "opa",
+		runFn: func(cmd *exec.Cmd) ([]byte, error) {
+			assert.Equal(t, []string{"opa", "eval", "--format", "json", "--stdin-input", "--data", "/app/policy.rego", "data.main"}, cmd.Args)
+
+			input, err := ioutil.ReadAll(cmd.Stdin)
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"kind":"Pod"}`, string(input))
+
+			return []byte(`{"result":[{"expressions":[{"value":{
+				"deny":["image uses the latest tag", {"msg": "resources are required"}],
+				"warn":["namespace is not set"],
+				"other":["ignored"]
+			}}]}]}`), nil
+		},
+	}
+
+	deny, warn, err := e.Evaluate("/app/policy.rego", map[string]interface{}{"kind": "Pod"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"image uses the latest tag", "resources are required"}, deny)
+	assert.Equal(t, []string{"namespace is not set"}, warn)
+}
+
+func TestRegoEvaluator_Evaluate_missing_opa(t *testing.T) {
+	e := &RegoEvaluator{
+		program: "opa",
+		runFn: func(cmd *exec.Cmd) ([]byte, error) {
+			return nil, &exec.Error{Name: "opa", Err: exec.ErrNotFound}
+		},
+	}
+
+	_, _, err := e.Evaluate("/app/policy.rego", map[string]interface{}{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "install Open Policy Agent")
+}
@@ -0,0 +1,244 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package policy
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/uuid"
+)
+
+const (
+	// WebhookFailurePolicyFail stops the apply when a webhook can not be
+	// reached.
+	WebhookFailurePolicyFail = "fail"
+	// WebhookFailurePolicyIgnore logs a webhook failure and continues.
+	WebhookFailurePolicyIgnore = "ignore"
+
+	// DefaultWebhookTimeout is the default amount of time to wait for a
+	// webhook.
+	DefaultWebhookTimeout = 10 * time.Second
+)
+
+// WebhookReplayer replays objects against validating admission webhooks, as
+// the API server would send them, without sending them to the API server.
+type WebhookReplayer struct {
+	fs   afero.Fs
+	root string
+}
+
+// NewWebhookReplayer creates an instance of WebhookReplayer. CA bundle paths
+// are relative to root.
+func NewWebhookReplayer(fs afero.Fs, root string) *WebhookReplayer {
+	return &WebhookReplayer{
+		fs:   fs,
+		root: root,
+	}
+}
+
+// Replay sends objects to webhooks. Objects which a webhook denies are
+// reported as violations at the deny level.
+func (r *WebhookReplayer) Replay(webhooks []*app.WebhookConfig, objects []*unstructured.Unstructured) (*Result, error) {
+	result := NewResult()
+
+	for _, w := range webhooks {
+		if w == nil {
+			continue
+		}
+
+		client, err := r.client(w)
+		if err != nil {
+			return nil, errors.Wrapf(err, "webhook %s", w.Name)
+		}
+
+		for _, obj := range objects {
+			if !webhookMatches(w, obj) {
+				continue
+			}
+
+			response, err := review(client, w.URL, obj)
+			if err != nil {
+				if w.FailurePolicy == WebhookFailurePolicyIgnore {
+					log.WithError(err).Warnf("webhook %s failed for %s %s; ignoring", w.Name, obj.GetKind(), obj.GetName())
+					continue
+				}
+				return nil, errors.Wrapf(err, "webhook %s failed for %s %s", w.Name, obj.GetKind(), obj.GetName())
+			}
+
+			if response.Allowed {
+				continue
+			}
+
+			msg := "denied the request"
+			if response.Result != nil && response.Result.Message != "" {
+				msg = response.Result.Message
+			}
+			result.Violations = append(result.Violations, newViolation(w.Name, LevelDeny, obj, msg))
+		}
+	}
+
+	return result, nil
+}
+
+// client creates an HTTP client for a webhook.
+func (r *WebhookReplayer) client(w *app.WebhookConfig) (*http.Client, error) {
+	switch w.FailurePolicy {
+	case "", WebhookFailurePolicyFail, WebhookFailurePolicyIgnore:
+	default:
+		return nil, errors.Errorf("invalid failure policy %q; valid policies are %s and %s",
+			w.FailurePolicy, WebhookFailurePolicyFail, WebhookFailurePolicyIgnore)
+	}
+
+	if w.URL == "" {
+		return nil, errors.New("url is required")
+	}
+
+	timeout := DefaultWebhookTimeout
+	if w.Timeout != "" {
+		d, err := time.ParseDuration(w.Timeout)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing timeout %q", w.Timeout)
+		}
+		timeout = d
+	}
+
+	transport := &http.Transport{Proxy: http.ProxyFromEnvironment}
+
+	if w.CABundle != "" {
+		path := w.CABundle
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(r.root, path)
+		}
+
+		data, err := afero.ReadFile(r.fs, path)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading CA bundle")
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, errors.Errorf("CA bundle %s has no certificates", w.CABundle)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	return &http.Client{Transport: transport, Timeout: timeout}, nil
+}
+
+// webhookMatches reports if a webhook should be sent an object.
+func webhookMatches(w *app.WebhookConfig, obj *unstructured.Unstructured) bool {
+	if len(w.Kinds) == 0 {
+		return true
+	}
+
+	for _, kind := range w.Kinds {
+		if kind == obj.GetKind() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// review sends an object to a webhook as a create request, and returns the
+// webhook's response.
+func review(client *http.Client, url string, obj *unstructured.Unstructured) (*admissionv1beta1.AdmissionResponse, error) {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	gvk := obj.GroupVersionKind()
+	gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+
+	request := admissionv1beta1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: admissionv1beta1.SchemeGroupVersion.String(),
+			Kind:       "AdmissionReview",
+		},
+		Request: &admissionv1beta1.AdmissionRequest{
+			UID:       uuid.NewUUID(),
+			Kind:      metav1.GroupVersionKind{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind},
+			Resource:  metav1.GroupVersionResource{Group: gvr.Group, Version: gvr.Version, Resource: gvr.Resource},
+			Name:      obj.GetName(),
+			Namespace: obj.GetNamespace(),
+			Operation: admissionv1beta1.Create,
+			Object:    runtime.RawExtension{Raw: data},
+		},
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var reviewed admissionv1beta1.AdmissionReview
+	if err := json.Unmarshal(respBody, &reviewed); err != nil {
+		return nil, errors.Wrap(err, "decoding admission review")
+	}
+
+	if reviewed.Response == nil {
+		return nil, errors.New("admission review has no response")
+	}
+
+	return reviewed.Response, nil
+}
+
+// ReplayWebhooks replays objects against the webhooks of an environment.
+func ReplayWebhooks(a app.App, envName string, objects []*unstructured.Unstructured) (*Result, error) {
+	env, err := a.Environment(envName)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(env.Webhooks) == 0 {
+		return NewResult(), nil
+	}
+
+	log.Debugf("replaying %d object(s) against %d webhook(s)", len(objects), len(env.Webhooks))
+	return NewWebhookReplayer(a.Fs(), a.Root()).Replay(env.Webhooks, objects)
+}
@@ -0,0 +1,145 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package policy
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// denyBadWebhook denies objects named "bad", and records the kinds it is sent.
+func denyBadWebhook(kinds *[]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var review admissionv1beta1.AdmissionReview
+		if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		*kinds = append(*kinds, review.Request.Kind.Kind)
+
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(review.Request.Object.Raw); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		review.Response = &admissionv1beta1.AdmissionResponse{
+			UID:     review.Request.UID,
+			Allowed: obj.GetName() != "bad",
+		}
+		if !review.Response.Allowed {
+			review.Response.Result = &metav1.Status{Message: "image uses the latest tag"}
+		}
+
+		json.NewEncoder(w).Encode(review)
+	}
+}
+
+func TestWebhookReplayer_Replay(t *testing.T) {
+	var kinds []string
+	srv := httptest.NewServer(denyBadWebhook(&kinds))
+	defer srv.Close()
+
+	service := newObject("default", "bad")
+	service.SetKind("Service")
+
+	webhooks := []*app.WebhookConfig{
+		{Name: "images", URL: srv.URL, Kinds: []string{"Deployment"}},
+	}
+	objects := []*unstructured.Unstructured{
+		newObject("default", "good"),
+		newObject("default", "bad"),
+		service,
+	}
+
+	r := NewWebhookReplayer(afero.NewMemMapFs(), "/app")
+	result, err := r.Replay(webhooks, objects)
+	require.NoError(t, err)
+
+	expected := []Violation{
+		{Policy: "images", Level: LevelDeny, Kind: "Deployment", Namespace: "default", Name: "bad", Message: "image uses the latest tag"},
+	}
+	assert.Equal(t, expected, result.Violations)
+	assert.Equal(t, []string{"Deployment", "Deployment"}, kinds)
+}
+
+func TestWebhookReplayer_Replay_failure_policy(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	cases := []struct {
+		name          string
+		failurePolicy string
+		timeout       string
+		isErr         bool
+	}{
+		{name: "fail", isErr: true},
+		{name: "ignore", failurePolicy: WebhookFailurePolicyIgnore},
+		{name: "invalid failure policy", failurePolicy: "retry", isErr: true},
+		{name: "invalid timeout", timeout: "soon", isErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			webhooks := []*app.WebhookConfig{
+				{Name: "images", URL: srv.URL, FailurePolicy: tc.failurePolicy, Timeout: tc.timeout},
+			}
+
+			r := NewWebhookReplayer(afero.NewMemMapFs(), "/app")
+			result, err := r.Replay(webhooks, []*unstructured.Unstructured{newObject("default", "good")})
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Empty(t, result.Violations)
+		})
+	}
+}
+
+func TestWebhookReplayer_Replay_ca_bundle(t *testing.T) {
+	var kinds []string
+	srv := httptest.NewTLSServer(denyBadWebhook(&kinds))
+	defer srv.Close()
+
+	fs := afero.NewMemMapFs()
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	require.NoError(t, afero.WriteFile(fs, "/app/certs/ca.pem", ca, 0644))
+
+	objects := []*unstructured.Unstructured{newObject("default", "bad")}
+	r := NewWebhookReplayer(fs, "/app")
+
+	_, err := r.Replay([]*app.WebhookConfig{{Name: "images", URL: srv.URL}}, objects)
+	require.Error(t, err, "webhook certificate should not be trusted without the CA bundle")
+
+	result, err := r.Replay([]*app.WebhookConfig{{Name: "images", URL: srv.URL, CABundle: "certs/ca.pem"}}, objects)
+	require.NoError(t, err)
+	require.Len(t, result.Violations, 1)
+}
@@ -0,0 +1,321 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package prototype
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	utilyaml "github.com/ksonnet/ksonnet/pkg/util/yaml"
+	"github.com/pkg/errors"
+)
+
+// paramPlaceholder marks values which are replaced with parameters in the
+// generated template.
+const paramPlaceholder = "__ksonnet_param__"
+
+var (
+	reParamPlaceholder = regexp.MustCompile(`"` + paramPlaceholder + `([A-Za-z0-9_]+)"`)
+	reIdentifier       = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// Generate generates the source of a prototype from Kubernetes manifests in
+// YAML or JSON. The name of the first object is the required `name`
+// parameter. Container images, replicas, and the labels of the first object
+// are optional parameters, with their values in the manifests as defaults.
+func Generate(name, description string, r io.Reader) (string, error) {
+	objects, err := decodeObjects(r)
+	if err != nil {
+		return "", err
+	}
+	if len(objects) == 0 {
+		return "", errors.New("manifest does not contain any objects")
+	}
+
+	g := &generator{objects: objects}
+	g.parameterize()
+
+	var buf bytes.Buffer
+	var kinds []string
+	for _, object := range objects {
+		kinds = append(kinds, fmt.Sprint(object["kind"]))
+	}
+
+	shortDescription := description
+	if shortDescription == "" {
+		shortDescription = strings.Join(kinds, ", ")
+	}
+
+	fmt.Fprintf(&buf, "%s %s\n", apiVersionTag, "0.1")
+	fmt.Fprintf(&buf, "%s %s\n", nameTag, name)
+	fmt.Fprintf(&buf, "%s %s\n", descriptionTag, shortDescription)
+	fmt.Fprintf(&buf, "%s %s\n", shortDescriptionTag, shortDescription)
+	for _, param := range g.params {
+		if param.Default == nil {
+			fmt.Fprintf(&buf, "%s %s %s %s\n", paramTag, param.Name, param.Type, param.Description)
+			continue
+		}
+		fmt.Fprintf(&buf, "%s %s %s %s %s\n", optParamTag, param.Name, param.Type, *param.Default, param.Description)
+	}
+
+	src := "// " + strings.Replace(strings.TrimSpace(buf.String()), "\n", "\n// ", -1) + "\n"
+
+	var body interface{} = objects
+	if len(objects) == 1 {
+		body = objects[0]
+	}
+
+	b, err := marshalTemplate(body)
+	if err != nil {
+		return "", err
+	}
+
+	return src + b + "\n", nil
+}
+
+// generator replaces values in objects with parameters.
+type generator struct {
+	objects []map[string]interface{}
+	params  ParamSchemas
+}
+
+func (g *generator) parameterize() {
+	first := g.objects[0]
+
+	if name, ok := lookup(first, "metadata", "name").(string); ok && name != "" {
+		if g.replace(name, "name", nameFields()) > 0 {
+			g.addParam("name", String, nil, "Name of the resources")
+		}
+	}
+
+	if replicas, ok := lookup(first, "spec", "replicas").(float64); ok {
+		value := fmt.Sprint(replicas)
+		if g.replace(replicas, "replicas", [][]string{{"spec", "replicas"}}) > 0 {
+			g.addParam("replicas", Number, &value, "Number of replicas")
+		}
+	}
+
+	if labels, ok := lookup(first, "metadata", "labels").(map[string]interface{}); ok && len(labels) > 0 {
+		b, err := json.Marshal(labels)
+		value := string(b)
+		// The defaults of parameters can not contain spaces.
+		if err == nil && !strings.ContainsAny(value, " \t") {
+			if g.replace(labels, "labels", labelFields()) > 0 {
+				g.addParam("labels", Object, &value, "Labels of the resources")
+			}
+		}
+	}
+
+	g.parameterizeImages()
+}
+
+// parameterizeImages adds a parameter for each container image. A single
+// image is named `image`, and multiple images are named after their
+// containers.
+func (g *generator) parameterizeImages() {
+	type image struct {
+		container map[string]interface{}
+		value     string
+	}
+
+	var images []image
+	for _, object := range g.objects {
+		for _, container := range containers(object) {
+			if value, ok := container["image"].(string); ok && !strings.ContainsAny(value, " \t") {
+				images = append(images, image{container: container, value: value})
+			}
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, img := range images {
+		name := "image"
+		if len(images) > 1 {
+			name = lowerCamel(fmt.Sprint(img.container["name"])) + "Image"
+		}
+		if seen[name] || !reIdentifier.MatchString(name) {
+			continue
+		}
+		seen[name] = true
+
+		value := img.value
+		img.container["image"] = paramPlaceholder + name
+		g.addParam(name, String, &value, fmt.Sprintf("Image of the %s container", img.container["name"]))
+	}
+}
+
+func (g *generator) addParam(name string, t ParamType, value *string, description string) {
+	g.params = append(g.params, &ParamSchema{
+		Name:        name,
+		Alias:       &name,
+		Default:     value,
+		Description: description,
+		Type:        t,
+	})
+}
+
+// replace replaces the value at the paths of each object, if it equals
+// value, with a parameter. It returns the number of values replaced.
+func (g *generator) replace(value interface{}, param string, paths [][]string) int {
+	count := 0
+	for _, object := range g.objects {
+		for _, path := range paths {
+			parent, ok := lookup(object, path[:len(path)-1]...).(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			key := path[len(path)-1]
+			if current, ok := parent[key]; ok && reflect.DeepEqual(current, value) {
+				parent[key] = paramPlaceholder + param
+				count++
+			}
+		}
+	}
+
+	return count
+}
+
+// nameFields are the paths of values which are often the name of an object.
+func nameFields() [][]string {
+	return [][]string{
+		{"metadata", "name"},
+		{"spec", "serviceName"},
+		{"spec", "template", "metadata", "name"},
+	}
+}
+
+// labelFields are the paths of values which are often the labels of an
+// object.
+func labelFields() [][]string {
+	return [][]string{
+		{"metadata", "labels"},
+		{"spec", "selector"},
+		{"spec", "selector", "matchLabels"},
+		{"spec", "template", "metadata", "labels"},
+	}
+}
+
+// containers returns the containers of the pod template of an object.
+func containers(object map[string]interface{}) []map[string]interface{} {
+	var result []map[string]interface{}
+	for _, path := range [][]string{
+		{"spec", "containers"},
+		{"spec", "template", "spec", "containers"},
+		{"spec", "jobTemplate", "spec", "template", "spec", "containers"},
+	} {
+		items, ok := lookup(object, path...).([]interface{})
+		if !ok {
+			continue
+		}
+		for _, item := range items {
+			if container, ok := item.(map[string]interface{}); ok {
+				result = append(result, container)
+			}
+		}
+	}
+
+	return result
+}
+
+// lookup returns the value at a path in an object.
+func lookup(object map[string]interface{}, path ...string) interface{} {
+	var current interface{} = object
+	for _, key := range path {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = m[key]
+	}
+
+	return current
+}
+
+// decodeObjects decodes the objects in YAML or JSON documents. The items of
+// lists are decoded as objects.
+func decodeObjects(r io.Reader) ([]map[string]interface{}, error) {
+	docs, err := utilyaml.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []map[string]interface{}
+	for _, doc := range docs {
+		b, err := ioutil.ReadAll(doc)
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(b)) == 0 {
+			continue
+		}
+
+		var object map[string]interface{}
+		if err = yaml.Unmarshal(b, &object); err != nil {
+			return nil, errors.Wrap(err, "decoding manifest")
+		}
+		if object == nil {
+			continue
+		}
+
+		if items, ok := object["items"].([]interface{}); ok && strings.HasSuffix(fmt.Sprint(object["kind"]), "List") {
+			for _, item := range items {
+				if m, ok := item.(map[string]interface{}); ok {
+					objects = append(objects, m)
+				}
+			}
+			continue
+		}
+
+		objects = append(objects, object)
+	}
+
+	return objects, nil
+}
+
+// marshalTemplate marshals a template as JSON, which is valid jsonnet, and
+// references parameters as `params.<name>`.
+func marshalTemplate(v interface{}) (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "   ")
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+
+	return reParamPlaceholder.ReplaceAllString(strings.TrimSpace(buf.String()), "params.$1"), nil
+}
+
+// lowerCamel converts a container name, such as `my-app`, to `myApp`.
+func lowerCamel(s string) string {
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	})
+
+	for i := 1; i < len(parts); i++ {
+		parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+	}
+
+	return strings.Join(parts, "")
+}
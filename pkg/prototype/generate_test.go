@@ -0,0 +1,84 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package prototype
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "generate", "manifest.yaml"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	got, err := Generate("io.example.guestbook", "", f)
+	require.NoError(t, err)
+
+	expected, err := ioutil.ReadFile(filepath.Join("testdata", "generate", "guestbook.jsonnet"))
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), got)
+
+	assert.Empty(t, Lint(got))
+
+	p, err := JsonnetParse(got)
+	require.NoError(t, err)
+	assert.Equal(t, "io.example.guestbook", p.Name)
+	assert.Len(t, p.RequiredParams(), 1)
+	assert.Len(t, p.OptionalParams(), 3)
+}
+
+func TestGenerate_containers(t *testing.T) {
+	manifest := `
+kind: Pod
+metadata:
+  name: web
+  labels:
+    app: my web
+spec:
+  containers:
+  - name: web-server
+    image: nginx
+  - name: log-shipper
+    image: fluentd
+`
+
+	got, err := Generate("io.example.web", "A web server", strings.NewReader(manifest))
+	require.NoError(t, err)
+
+	p, err := JsonnetParse(got)
+	require.NoError(t, err)
+	assert.Equal(t, "A web server", p.Template.ShortDescription)
+
+	var names []string
+	for _, param := range p.Params {
+		names = append(names, param.Name)
+	}
+	// labels with spaces can't be a default.
+	assert.Equal(t, []string{"name", "webServerImage", "logShipperImage"}, names)
+	assert.Empty(t, Lint(got))
+}
+
+func TestGenerate_empty(t *testing.T) {
+	_, err := Generate("io.example.empty", "", strings.NewReader("---\n"))
+	require.Error(t, err)
+}
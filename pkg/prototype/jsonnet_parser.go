@@ -183,7 +183,7 @@ func newDirective(src string) directive {
 
 	if len(parts) != 2 {
 		return func(*Prototype) error {
-			return errors.Errorf("%q is not a valid directive")
+			return errors.Errorf("%q is not a valid directive", src)
 		}
 	}
 
@@ -200,6 +200,8 @@ func newDirective(src string) directive {
 		return paramDirective(parts[1])
 	case "optionalParam":
 		return optParamDirective(parts[1])
+	case "templateEngine":
+		return templateEngineDirective(parts[1])
 	default:
 		return func(*Prototype) error {
 			return errors.Errorf("unknown prototype directive %q", parts[0])
@@ -235,6 +237,18 @@ func shortDescriptionDirective(description string) func(*Prototype) error {
 	}
 }
 
+func templateEngineDirective(engine string) func(*Prototype) error {
+	return func(s *Prototype) error {
+		e, err := parseTemplateEngine(strings.TrimSpace(engine))
+		if err != nil {
+			return err
+		}
+
+		s.Template.Engine = e
+		return nil
+	}
+}
+
 func paramDirective(src string) func(*Prototype) error {
 	return func(s *Prototype) error {
 		split := strings.SplitN(src, " ", 3)
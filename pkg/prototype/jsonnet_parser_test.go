@@ -88,6 +88,15 @@ func Test_newDirective(t *testing.T) {
 			src:   "unknown invalid",
 			isErr: true,
 		},
+		{
+			name: "template engine",
+			src:  "templateEngine go",
+		},
+		{
+			name:  "unknown template engine",
+			src:   "templateEngine mustache",
+			isErr: true,
+		},
 	}
 
 	for _, tc := range cases {
@@ -0,0 +1,172 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package prototype
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// reParamRef matches the parameters a template references, as
+	// `params.name`, `params["name"]`, or `import 'param://name'`.
+	reParamRef = regexp.MustCompile(`params\.([A-Za-z_][A-Za-z0-9_]*)|params\[["']([^"']+)["']\]|param://([^"']+)`)
+
+	// reTemplateAction matches the actions of a Go template, and
+	// reTemplateParamRef the parameters an action references, as `.name`.
+	reTemplateAction   = regexp.MustCompile(`{{.*?}}`)
+	reTemplateParamRef = regexp.MustCompile(`(?:^|[^\w.)\]])\.([A-Za-z_][A-Za-z0-9_]*)`)
+
+	// reservedParamNames are the flags every prototype has, so they can't be
+	// parameter names.
+	reservedParamNames = []string{"values-file", "module", "verbose"}
+)
+
+// Problem is a mistake in the directives of a prototype.
+type Problem struct {
+	// Line is the line of the directive, or 0 if the problem is not with a
+	// single directive.
+	Line    int
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Line == 0 {
+		return p.Message
+	}
+	return fmt.Sprintf("%d: %s", p.Line, p.Message)
+}
+
+// Lint checks the directives of a prototype's source. It reports missing
+// directives, invalid parameter schemas, and parameters which are declared
+// and not used by the template, or used and not declared.
+func Lint(src string) []Problem {
+	p, err := JsonnetParse(src)
+	if err != nil {
+		return []Problem{{Message: err.Error()}}
+	}
+
+	lines := strings.Split(src, "\n")
+	var problems []Problem
+	add := func(line int, format string, args ...interface{}) {
+		problems = append(problems, Problem{Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if p.Name == "" {
+		add(0, "missing %s directive", nameTag)
+	}
+	if p.Template.ShortDescription == "" {
+		add(0, "missing %s directive", shortDescriptionTag)
+	}
+	if len(p.Template.JsonnetBody) == 0 {
+		add(0, "prototype does not have a template")
+	}
+
+	isGoTemplate := p.Template.Engine == GoEngine
+	if isGoTemplate {
+		if _, err := parseGoTemplate(p.Name, strings.Join(p.Template.JsonnetBody, "\n")); err != nil {
+			add(directiveTagLine(lines, templateEngineTag), "%v", err)
+		}
+	}
+
+	// refs are the lines parameters are used on, ignoring comments.
+	refs := map[string][]int{}
+	var refNames []string
+	addRef := func(name string, line int) {
+		if _, ok := refs[name]; !ok {
+			refNames = append(refNames, name)
+		}
+		refs[name] = append(refs[name], line)
+	}
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "//") {
+			continue
+		}
+		for _, m := range reParamRef.FindAllStringSubmatch(line, -1) {
+			addRef(m[1]+m[2]+m[3], i+1)
+		}
+		if !isGoTemplate {
+			continue
+		}
+		for _, action := range reTemplateAction.FindAllString(line, -1) {
+			for _, m := range reTemplateParamRef.FindAllStringSubmatch(action, -1) {
+				addRef(m[1], i+1)
+			}
+		}
+	}
+
+	declared := map[string]bool{}
+	for _, param := range p.Params {
+		line := directiveLine(lines, param)
+
+		if declared[param.Name] {
+			add(line, "parameter %q is declared more than once", param.Name)
+			continue
+		}
+		declared[param.Name] = true
+
+		for _, name := range reservedParamNames {
+			if param.Name == name {
+				add(line, "parameter %q conflicts with the --%s flag", param.Name, name)
+			}
+		}
+
+		if param.Default != nil {
+			if err := param.Validate(*param.Default); err != nil {
+				add(line, "parameter %q: default %v", param.Name, err)
+			}
+		}
+
+		if _, ok := refs[param.Name]; !ok {
+			add(line, "parameter %q is declared, but the template does not use it", param.Name)
+		}
+	}
+
+	for _, name := range refNames {
+		if !declared[name] {
+			add(refs[name][0], "parameter %q is used, but not declared", name)
+		}
+	}
+
+	return problems
+}
+
+// directiveTagLine returns the first line with a directive, or 0 if there is
+// none.
+func directiveTagLine(lines []string, tag string) int {
+	for i, line := range lines {
+		if strings.HasPrefix(commentText(line), tag+" ") {
+			return i + 1
+		}
+	}
+
+	return 0
+}
+
+// directiveLine returns the line which declares a parameter.
+func directiveLine(lines []string, param *ParamSchema) int {
+	for i, line := range lines {
+		text := commentText(line)
+		for _, tag := range []string{paramTag, optParamTag} {
+			if strings.HasPrefix(text, tag+" "+param.Name+" ") {
+				return i + 1
+			}
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,138 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package prototype
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	cases := []struct {
+		name     string
+		src      string
+		expected []Problem
+	}{
+		{
+			name: "valid",
+			src: `// @name io.ksonnet.pkg.valid
+// @shortDescription A valid prototype
+// @param name string Name of the deployment
+// @optionalParam replicas number 1 Number of replicas
+// @optionalParam labels object {} Labels
+{
+  name: params.name, // the name
+  replicas: params["replicas"],
+  labels: import 'param://labels',
+}`,
+		},
+		{
+			name: "missing directives",
+			src:  `{}`,
+			expected: []Problem{
+				{Message: "missing @name directive"},
+				{Message: "missing @shortDescription directive"},
+			},
+		},
+		{
+			name: "invalid parameters",
+			src: `// @name io.ksonnet.pkg.invalid
+// @shortDescription An invalid prototype
+// @param name string Name of the deployment
+// @param name string Name of the deployment
+// @optionalParam replicas number one Number of replicas
+// @optionalParam labels object app Labels
+// @param module string Module
+// @param unused string Unused parameter
+{
+  // params.commented is not a reference.
+  name: params.name,
+  replicas: params.replicas,
+  labels: params.labels,
+  module: params.module,
+  image: params.image,
+}`,
+			expected: []Problem{
+				{Line: 3, Message: `parameter "name" is declared more than once`},
+				{Line: 5, Message: `parameter "replicas": default "one" is not a number`},
+				{Line: 6, Message: `parameter "labels": default "app" is not an object`},
+				{Line: 7, Message: `parameter "module" conflicts with the --module flag`},
+				{Line: 8, Message: `parameter "unused" is declared, but the template does not use it`},
+				{Line: 15, Message: `parameter "image" is used, but not declared`},
+			},
+		},
+		{
+			name: "go template",
+			src: `// @name io.ksonnet.pkg.go-template
+// @shortDescription A prototype with a Go template
+// @templateEngine go
+// @param name string Name of the service
+// @optionalParam ports array [80] Ports of the service
+// @optionalParam ingressHost string example.com Host of the ingress
+{
+  name: {{ .name | dnsLabel | quote }},
+  ports: [{{ range $i, $port := .ports }}{{ if $i }}, {{ end }}{{ $port }}{{ end }}],
+  {{- if .ingressHost }}
+  host: params.ingressHost,
+  {{- end }}
+}`,
+		},
+		{
+			name: "invalid go template",
+			src: `// @name io.ksonnet.pkg.go-template
+// @shortDescription A prototype with a Go template
+// @templateEngine go
+// @param name string Name of the service
+{
+  name: {{ .name }},
+  {{ if .name }}
+}`,
+			expected: []Problem{
+				{Line: 3, Message: `parsing template of prototype "io.ksonnet.pkg.go-template": template: io.ksonnet.pkg.go-template:4: unexpected EOF`},
+			},
+		},
+		{
+			name: "invalid directive",
+			src:  "// @param name\n{}",
+			expected: []Problem{
+				{Message: "param fields must have '<name> <type> <description>, but got:\nname"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Lint(tc.src))
+		})
+	}
+}
+
+func TestLint_system_prototypes(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("system", "*.jsonnet"))
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+
+	for _, path := range paths {
+		b, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+
+		assert.Empty(t, Lint(string(b)), path)
+	}
+}
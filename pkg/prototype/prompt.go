@@ -0,0 +1,156 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package prototype
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/spf13/pflag"
+)
+
+// ReadAnswers reads the answers to a prototype's parameters from a YAML or
+// JSON file, which maps parameter names to values. Objects and arrays are
+// converted to JSON, so they can be used as Jsonnet.
+func ReadAnswers(fs afero.Fs, path string) (map[string]string, error) {
+	b, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading answers file")
+	}
+
+	var raw map[string]interface{}
+	if err = yaml.Unmarshal(b, &raw); err != nil {
+		return nil, errors.Wrapf(err, "parsing answers file %s", path)
+	}
+
+	answers := make(map[string]string)
+	for name, v := range raw {
+		switch t := v.(type) {
+		case string:
+			answers[name] = t
+		case float64:
+			answers[name] = strconv.FormatFloat(t, 'f', -1, 64)
+		case bool:
+			answers[name] = strconv.FormatBool(t)
+		default:
+			b, err := json.Marshal(t)
+			if err != nil {
+				return nil, errors.Wrapf(err, "converting answer %q", name)
+			}
+			answers[name] = string(b)
+		}
+	}
+
+	return answers, nil
+}
+
+// SetAnswers sets the flags of a prototype's parameters to answers. Flags
+// given on the command line take precedence.
+func SetAnswers(p *Prototype, flags *pflag.FlagSet, answers map[string]string) error {
+	var names []string
+	for name := range answers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !p.HasParam(name) {
+			return errors.Errorf("prototype %q does not have a parameter %q", p.Name, name)
+		}
+	}
+
+	for _, param := range p.Params {
+		value, ok := answers[param.Name]
+		if !ok || flags.Changed(param.Name) {
+			continue
+		}
+
+		if err := param.Validate(value); err != nil {
+			return errors.Wrapf(err, "answer for parameter %q", param.Name)
+		}
+
+		if err := flags.Set(param.Name, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Prompt prompts for the parameters of a prototype which were not given as
+// flags. The current value of a flag is its default, and answers are
+// validated against the parameter's type.
+func Prompt(p *Prototype, flags *pflag.FlagSet, in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+
+	params := append(p.RequiredParams(), p.OptionalParams()...)
+	for _, param := range params {
+		if flags.Changed(param.Name) {
+			continue
+		}
+
+		f := flags.Lookup(param.Name)
+		if f == nil {
+			continue
+		}
+		current := f.Value.String()
+
+		for {
+			fmt.Fprintf(out, "%s (%s) %s", param.Name, param.Type, param.Description)
+			if current != "" {
+				fmt.Fprintf(out, " [%s]", current)
+			}
+			fmt.Fprint(out, ": ")
+
+			if !scanner.Scan() {
+				if err := scanner.Err(); err != nil {
+					return err
+				}
+				return errors.Errorf("no answer for parameter %q", param.Name)
+			}
+
+			value := strings.TrimSpace(scanner.Text())
+			if value == "" {
+				value = current
+			}
+
+			if value == "" {
+				fmt.Fprintf(out, "%q is required\n", param.Name)
+				continue
+			}
+
+			if err := param.Validate(value); err != nil {
+				fmt.Fprintf(out, "%v\n", err)
+				continue
+			}
+
+			if err := flags.Set(param.Name, value); err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	return nil
+}
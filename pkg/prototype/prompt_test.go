@@ -0,0 +1,159 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package prototype
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func promptPrototype(t *testing.T) *Prototype {
+	p, err := JsonnetParse(`// @name io.ksonnet.pkg.deployment
+// @shortDescription A deployment
+// @param name string Name of the deployment
+// @param image string Container image
+// @optionalParam replicas number 1 Number of replicas
+// @optionalParam labels object {} Labels
+{}`)
+	require.NoError(t, err)
+
+	return p
+}
+
+func TestReadAnswers(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/answers.yaml", []byte(`
+image: nginx
+replicas: 3
+labels:
+  app: nginx
+`), 0644))
+
+	answers, err := ReadAnswers(fs, "/answers.yaml")
+	require.NoError(t, err)
+
+	expected := map[string]string{
+		"image":    "nginx",
+		"replicas": "3",
+		"labels":   `{"app":"nginx"}`,
+	}
+	assert.Equal(t, expected, answers)
+
+	_, err = ReadAnswers(fs, "/missing.yaml")
+	require.Error(t, err)
+}
+
+func TestSetAnswers(t *testing.T) {
+	cases := []struct {
+		name     string
+		args     []string
+		answers  map[string]string
+		expected map[string]string
+		isErr    bool
+	}{
+		{
+			name:    "flags take precedence",
+			args:    []string{"--image", "redis"},
+			answers: map[string]string{"image": "nginx", "replicas": "3"},
+			expected: map[string]string{
+				"image":    "redis",
+				"replicas": "3",
+			},
+		},
+		{
+			name:    "unknown parameter",
+			answers: map[string]string{"unknown": "value"},
+			isErr:   true,
+		},
+		{
+			name:    "invalid value",
+			answers: map[string]string{"replicas": "three"},
+			isErr:   true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := promptPrototype(t)
+			flags, err := BindFlags(p)
+			require.NoError(t, err)
+			require.NoError(t, flags.Parse(tc.args))
+
+			err = SetAnswers(p, flags, tc.answers)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			for name, value := range tc.expected {
+				got, err := flags.GetString(name)
+				require.NoError(t, err)
+				assert.Equal(t, value, got, name)
+			}
+		})
+	}
+}
+
+func TestPrompt(t *testing.T) {
+	p := promptPrototype(t)
+	flags, err := BindFlags(p)
+	require.NoError(t, err)
+	require.NoError(t, flags.Parse([]string{"--image", "nginx"}))
+
+	in := strings.NewReader(strings.Join([]string{
+		"",       // name is required
+		"nginx",  // name
+		"three",  // replicas must be a number
+		"",       // replicas defaults to 1
+		"{a: 1}", // labels
+	}, "\n"))
+	var out bytes.Buffer
+
+	require.NoError(t, Prompt(p, flags, in, &out))
+
+	expected := map[string]string{
+		"name":     "nginx",
+		"image":    "nginx",
+		"replicas": "1",
+		"labels":   "{a: 1}",
+	}
+	for name, value := range expected {
+		got, err := flags.GetString(name)
+		require.NoError(t, err)
+		assert.Equal(t, value, got, name)
+	}
+
+	expectedOut := `name (string) Name of the deployment: "name" is required
+name (string) Name of the deployment: replicas (number) Number of replicas [1]: "three" is not a number
+replicas (number) Number of replicas [1]: labels (object) Labels [{}]: `
+	assert.Equal(t, expectedOut, out.String())
+}
+
+func TestPrompt_no_answer(t *testing.T) {
+	p := promptPrototype(t)
+	flags, err := BindFlags(p)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	err = Prompt(p, flags, strings.NewReader(""), &out)
+	require.Error(t, err)
+}
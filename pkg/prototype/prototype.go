@@ -39,6 +39,7 @@ const (
 	shortDescriptionTag = "@shortDescription"
 	paramTag            = "@param"
 	optParamTag         = "@optionalParam"
+	templateEngineTag   = "@templateEngine"
 )
 
 // Prototype is the JSON-serializable representation of a prototype
@@ -102,6 +103,17 @@ func (s *Prototype) RequiredParams() ParamSchemas {
 	return reqd
 }
 
+// HasParam reports if a prototype has a parameter.
+func (s *Prototype) HasParam(name string) bool {
+	for _, p := range s.Params {
+		if p.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
 // OptionalParams retrieves all parameters that can optionally be provided to a
 // prototype.
 func (s *Prototype) OptionalParams() ParamSchemas {
@@ -160,6 +172,9 @@ type SnippetSchema struct {
 	JSONBody    []string `json:"jsonBody"`
 	YAMLBody    []string `json:"yamlBody"`
 	JsonnetBody []string `json:"jsonnetBody"`
+
+	// Engine renders the body. Bodies are snippets if it is empty.
+	Engine TemplateEngine `json:"engine,omitempty"`
 }
 
 // Body attempts to retrieve the template body associated with some
@@ -238,6 +253,27 @@ func (ps *ParamSchema) Quote(value string) (string, error) {
 	}
 }
 
+// Validate checks a value can be given to the parameter. Objects and arrays
+// must be Jsonnet object and array literals.
+func (ps *ParamSchema) Validate(value string) error {
+	switch ps.Type {
+	case Number:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+	case Object:
+		if !strings.HasPrefix(strings.TrimSpace(value), "{") {
+			return fmt.Errorf("%q is not an object", value)
+		}
+	case Array:
+		if !strings.HasPrefix(strings.TrimSpace(value), "[") {
+			return fmt.Errorf("%q is not an array", value)
+		}
+	}
+
+	return nil
+}
+
 // ParamSchemas is a slice of `ParamSchema`
 type ParamSchemas []*ParamSchema
 
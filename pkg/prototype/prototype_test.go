@@ -129,7 +129,12 @@ func TestSearch(t *testing.T) {
 		"io.ksonnet.pkg.single-port-service",
 		"io.ksonnet.pkg.namespace",
 		"io.ksonnet.pkg.configMap",
+		"io.ksonnet.pkg.configmap-from-file",
+		"io.ksonnet.pkg.cron-job",
 		"io.ksonnet.pkg.deployed-service",
+		"io.ksonnet.pkg.ingress",
+		"io.ksonnet.pkg.job",
+		"io.ksonnet.pkg.secret-from-env",
 		"io.ksonnet.pkg.single-port-deployment",
 	})
 	assertSearch(t, idx, Prefix, "foo", []string{})
@@ -159,7 +164,12 @@ func TestSearch(t *testing.T) {
 		"io.ksonnet.pkg.single-port-service",
 		"io.ksonnet.pkg.single-port-deployment",
 		"io.ksonnet.pkg.configMap",
+		"io.ksonnet.pkg.configmap-from-file",
+		"io.ksonnet.pkg.cron-job",
+		"io.ksonnet.pkg.ingress",
+		"io.ksonnet.pkg.job",
 		"io.ksonnet.pkg.namespace",
+		"io.ksonnet.pkg.secret-from-env",
 	})
 	assertSearch(t, idx, Substring, "foo", []string{})
 }
@@ -29,21 +29,46 @@ func init() {
 		Content:     string("// @apiVersion 0.1\n// @name io.ksonnet.pkg.configMap\n// @description A simple config map with optional user-specified data.\n// @shortDescription A simple config map with optional user-specified data\n// @param name string Name to give the configMap.\n// @optionalParam data object {} Data for the configMap.\n{\n   \"apiVersion\": \"v1\",\n   \"data\": import 'param://data',\n   \"kind\": \"ConfigMap\",\n   \"metadata\": {\n    \"name\": import 'param://name'\n  }\n}"),
 	}
 	file3 := &embedded.EmbeddedFile{
+		Filename:    "configmap-from-file.jsonnet",
+		FileModTime: time.Unix(1526918148, 0),
+		Content:     string("// @apiVersion 0.1\n// @name io.ksonnet.pkg.configmap-from-file\n// @description A config map holding the contents of a single file, keyed by the file's base name. 'file' is resolved relative to the components directory and is read each time the component is evaluated.\n// @shortDescription Config map with the contents of a file\n// @param name string Name of the config map\n// @param file string Path of the file, relative to the components directory\nlocal file = import 'param://file';\nlocal parts = std.split(file, \"/\");\n{\n   \"apiVersion\": \"v1\",\n   \"kind\": \"ConfigMap\",\n   \"metadata\": {\n      \"name\": import 'param://name'\n   },\n   \"data\": {\n      [parts[std.length(parts) - 1]]: importstr 'param://file'\n   }\n}\n"),
+	}
+	file4 := &embedded.EmbeddedFile{
+		Filename:    "cron-job.jsonnet",
+		FileModTime: time.Unix(1526918148, 0),
+		Content:     string("// @apiVersion 0.1\n// @name io.ksonnet.pkg.cron-job\n// @description A CronJob that runs container 'image' on 'schedule' (cron format). Overlapping runs are forbidden by default, and each finished Job is garbage collected after 'ttlSecondsAfterFinished' seconds (default: 3600).\n// @shortDescription Runs a container on a cron schedule\n// @param name string Name of the cron job\n// @param image string Container image to run\n// @param schedule string Cron schedule, e.g. \"*/5 * * * *\"\n// @optionalParam command array [] Command to run in the container; defaults to the image entrypoint\n// @optionalParam concurrencyPolicy string Forbid How to treat concurrent runs: Allow, Forbid, or Replace\n// @optionalParam ttlSecondsAfterFinished number 3600 Seconds to keep each job after it finishes\n{\n   \"apiVersion\": \"batch/v1beta1\",\n   \"kind\": \"CronJob\",\n   \"metadata\": {\n      \"name\": import 'param://name',\n      \"labels\": {\n         \"app\": import 'param://name'\n      }\n   },\n   \"spec\": {\n      \"schedule\": import 'param://schedule',\n      \"concurrencyPolicy\": import 'param://concurrencyPolicy',\n      \"jobTemplate\": {\n         \"spec\": {\n            \"ttlSecondsAfterFinished\": import 'param://ttlSecondsAfterFinished',\n            \"template\": {\n               \"metadata\": {\n                  \"labels\": {\n                     \"app\": import 'param://name'\n                  }\n               },\n               \"spec\": {\n                  \"restartPolicy\": \"Never\",\n                  \"containers\": [\n                     {\n                        \"image\": import 'param://image',\n                        \"name\": import 'param://name',\n                     } + if std.length(import 'param://command') > 0 then {\n                        \"command\": import 'param://command'\n                     } else {}\n                  ]\n               }\n            }\n         }\n      }\n   }\n}\n"),
+	}
+	file5 := &embedded.EmbeddedFile{
 		Filename:    "deployed-service.jsonnet",
 		FileModTime: time.Unix(1526918148, 0),
 		Content:     string("// @apiVersion 0.1\n// @name io.ksonnet.pkg.deployed-service\n// @description A service that exposes 'servicePort', and directs traffic to 'targetLabelSelector', at 'targetPort'.\n// @shortDescription A deployment exposed with a service\n// @param name string Name of the service and deployment resources\n// @param image string Container image to deploy\n// @optionalParam servicePort number 80 Port for the service to expose.\n// @optionalParam containerPort number 80 Container port for service to target.\n// @optionalParam replicas number 1 Number of replicas\n// @optionalParam type string ClusterIP Type of service to expose\n[\n   {\n      \"apiVersion\": \"v1\",\n      \"kind\": \"Service\",\n      \"metadata\": {\n         \"name\": import 'param://name'\n      },\n      \"spec\": {\n         \"ports\": [\n            {\n               \"port\": import 'param://servicePort',\n               \"targetPort\": import 'param://containerPort'\n            }\n         ],\n         \"selector\": {\n            \"app\": import 'param://name'\n         },\n         \"type\": import 'param://type'\n      }\n   },\n   {\n      \"apiVersion\": \"apps/v1beta2\",\n      \"kind\": \"Deployment\",\n      \"metadata\": {\n         \"name\": import 'param://name'\n      },\n      \"spec\": {\n         \"replicas\": import 'param://replicas',\n         \"selector\": {\n            \"matchLabels\": {\n               \"app\": import 'param://name'\n            },\n         },\n         \"template\": {\n            \"metadata\": {\n               \"labels\": {\n                  \"app\": import 'param://name'\n               }\n            },\n            \"spec\": {\n               \"containers\": [\n                  {\n                     \"image\": import 'param://image',\n                     \"name\": import 'param://name',\n                     \"ports\": [\n                     {\n                        \"containerPort\": import 'param://containerPort'\n                     }\n                     ]\n                  }\n               ]\n            }\n         }\n      }\n   }\n]\n"),
 	}
-	file4 := &embedded.EmbeddedFile{
+	file6 := &embedded.EmbeddedFile{
+		Filename:    "ingress.jsonnet",
+		FileModTime: time.Unix(1526918148, 0),
+		Content:     string("// @apiVersion 0.1\n// @name io.ksonnet.pkg.ingress\n// @description An ingress that routes 'path' (default: /) on 'host' to port 'servicePort' (default: 80) of 'serviceName'.\n// @shortDescription Routes a host and path to a service\n// @param name string Name of the ingress\n// @param host string Host name to route, e.g. app.example.com\n// @param serviceName string Name of the backing service\n// @optionalParam servicePort number 80 Port of the backing service\n// @optionalParam path string / Path prefix to route\n{\n   \"apiVersion\": \"extensions/v1beta1\",\n   \"kind\": \"Ingress\",\n   \"metadata\": {\n      \"name\": import 'param://name'\n   },\n   \"spec\": {\n      \"rules\": [\n         {\n            \"host\": import 'param://host',\n            \"http\": {\n               \"paths\": [\n                  {\n                     \"path\": import 'param://path',\n                     \"backend\": {\n                        \"serviceName\": import 'param://serviceName',\n                        \"servicePort\": import 'param://servicePort'\n                     }\n                  }\n               ]\n            }\n         }\n      ]\n   }\n}\n"),
+	}
+	file7 := &embedded.EmbeddedFile{
+		Filename:    "job.jsonnet",
+		FileModTime: time.Unix(1526918148, 0),
+		Content:     string("// @apiVersion 0.1\n// @name io.ksonnet.pkg.job\n// @description A one-off Job that runs container 'image' to completion. Finished Jobs are garbage collected after 'ttlSecondsAfterFinished' seconds (default: 3600). Labels are automatically populated from 'name'.\n// @shortDescription Runs a container to completion once\n// @param name string Name of the job\n// @param image string Container image to run\n// @optionalParam command array [] Command to run in the container; defaults to the image entrypoint\n// @optionalParam backoffLimit number 6 Number of retries before the job is marked failed\n// @optionalParam ttlSecondsAfterFinished number 3600 Seconds to keep the job after it finishes\n{\n   \"apiVersion\": \"batch/v1\",\n   \"kind\": \"Job\",\n   \"metadata\": {\n      \"name\": import 'param://name',\n      \"labels\": {\n         \"app\": import 'param://name'\n      }\n   },\n   \"spec\": {\n      \"backoffLimit\": import 'param://backoffLimit',\n      \"ttlSecondsAfterFinished\": import 'param://ttlSecondsAfterFinished',\n      \"template\": {\n         \"metadata\": {\n            \"labels\": {\n               \"app\": import 'param://name'\n            }\n         },\n         \"spec\": {\n            \"restartPolicy\": \"Never\",\n            \"containers\": [\n               {\n                  \"image\": import 'param://image',\n                  \"name\": import 'param://name',\n               } + if std.length(import 'param://command') > 0 then {\n                  \"command\": import 'param://command'\n               } else {}\n            ]\n         }\n      }\n   }\n}\n"),
+	}
+	file8 := &embedded.EmbeddedFile{
 		Filename:    "namespace.jsonnet",
 		FileModTime: time.Unix(1526918148, 0),
 		Content:     string("// @apiVersion 0.1\n// @name io.ksonnet.pkg.namespace\n// @description A simple namespace. Labels are automatically populated from the name of the namespace.\n// @shortDescription Namespace with labels automatically populated from the name\n// @param name string Name to give the namespace\n{\n   \"apiVersion\": \"v1\",\n   \"kind\": \"Namespace\",\n   \"metadata\": {\n      \"labels\": {\n         \"name\": import 'param://name'\n      },\n      \"name\": import 'param://name'\n   }\n}\n"),
 	}
-	file5 := &embedded.EmbeddedFile{
+	file9 := &embedded.EmbeddedFile{
+		Filename:    "secret-from-env.jsonnet",
+		FileModTime: time.Unix(1526918148, 0),
+		Content:     string("// @apiVersion 0.1\n// @name io.ksonnet.pkg.secret-from-env\n// @description An opaque secret whose values are read from external variables when the component is evaluated, so they are never written to the app. Pass each key with --ext-str, e.g. `ks apply default --ext-str API_TOKEN=\"$API_TOKEN\"`.\n// @shortDescription Secret with values taken from external variables\n// @param name string Name of the secret\n// @param keys array Names of the external variables to store, e.g. [\"API_TOKEN\"]\n{\n   \"apiVersion\": \"v1\",\n   \"kind\": \"Secret\",\n   \"type\": \"Opaque\",\n   \"metadata\": {\n      \"name\": import 'param://name'\n   },\n   \"data\": {\n      [key]: std.base64(std.extVar(key))\n      for key in import 'param://keys'\n   }\n}\n"),
+	}
+	file10 := &embedded.EmbeddedFile{
 		Filename:    "single-port-deployment.jsonnet",
 		FileModTime: time.Unix(1526918148, 0),
 		Content:     string("// @apiVersion 0.1\n// @name io.ksonnet.pkg.single-port-deployment\n// @description A deployment that replicates container 'image' some number of times (default: 1), and exposes a port (default: 80). Labels are automatically populated from 'name'.\n// @shortDescription Replicates a container n times, exposes a single port\n// @param name string Name of the deployment\n// @param image string Container image to deploy\n// @optionalParam replicas number 1 Number of replicas\n// @optionalParam containerPort number 80 Port to expose\n{\n   \"apiVersion\": \"apps/v1beta1\",\n   \"kind\": \"Deployment\",\n   \"metadata\": {\n      \"name\": import 'param://name'\n   },\n   \"spec\": {\n      \"replicas\": import 'param://replicas',\n      \"template\": {\n         \"metadata\": {\n            \"labels\": {\n               \"app\": import 'param://name'\n            }\n         },\n         \"spec\": {\n            \"containers\": [\n               {\n                  \"image\": import 'param://image',\n                  \"name\": import 'param://name',\n                  \"ports\": [\n                     {\n                        \"containerPort\": import 'param://containerPort'\n                     }\n                  ]\n               }\n            ]\n         }\n      }\n   }\n}"),
 	}
-	file6 := &embedded.EmbeddedFile{
+	file11 := &embedded.EmbeddedFile{
 		Filename:    "single-port-service.jsonnet",
 		FileModTime: time.Unix(1526918148, 0),
 		Content:     string("// @apiVersion 0.1\n// @name io.ksonnet.pkg.single-port-service\n// @description A service that exposes 'servicePort', and directs traffic\n//   to 'targetLabelSelector', at 'targetPort'. Since 'targetLabelSelector' is an\n//   object literal that specifies which labels the service is meant to target, this\n//   will typically look something like:\n//\n//     ks prototype use service --targetLabelSelector \"{app: 'nginx'}\" [...]\n// @shortDescription Service that exposes a single port\n// @param name string Name of the service\n// @param targetLabelSelector object Label for the service to target (e.g., \"{app: 'MyApp'}\"\").\n// @optionalParam servicePort number 80 Port for the service to expose\n// @optionalParam targetPort number 80 Port for the service target\n// @optionalParam protocol string TCP Protocol to use (either TCP or UDP)\n// @optionalParam serviceType string ClusterIP Type of service to expose\n{\n   \"apiVersion\": \"v1\",\n   \"kind\": \"Service\",\n   \"metadata\": {\n      \"name\": import 'param://name'\n   },\n   \"spec\": {\n      \"ports\": [\n         {\n            \"protocol\": import 'param://protocol',\n            \"port\": import 'param://servicePort',\n            \"targetPort\": import 'param://targetPort'\n         }\n      ],\n      \"selector\": import 'param://targetLabelSelector',\n      \"type\": import 'param://serviceType'\n   }\n}"),
@@ -54,11 +79,16 @@ func init() {
 		Filename:   "",
 		DirModTime: time.Unix(1526918148, 0),
 		ChildFiles: []*embedded.EmbeddedFile{
-			file2, // "config-map.jsonnet"
-			file3, // "deployed-service.jsonnet"
-			file4, // "namespace.jsonnet"
-			file5, // "single-port-deployment.jsonnet"
-			file6, // "single-port-service.jsonnet"
+			file2,  // "config-map.jsonnet"
+			file3,  // "configmap-from-file.jsonnet"
+			file4,  // "cron-job.jsonnet"
+			file5,  // "deployed-service.jsonnet"
+			file6,  // "ingress.jsonnet"
+			file7,  // "job.jsonnet"
+			file8,  // "namespace.jsonnet"
+			file9,  // "secret-from-env.jsonnet"
+			file10, // "single-port-deployment.jsonnet"
+			file11, // "single-port-service.jsonnet"
 
 		},
 	}
@@ -75,10 +105,15 @@ func init() {
 		},
 		Files: map[string]*embedded.EmbeddedFile{
 			"config-map.jsonnet":             file2,
-			"deployed-service.jsonnet":       file3,
-			"namespace.jsonnet":              file4,
-			"single-port-deployment.jsonnet": file5,
-			"single-port-service.jsonnet":    file6,
+			"configmap-from-file.jsonnet":    file3,
+			"cron-job.jsonnet":               file4,
+			"deployed-service.jsonnet":       file5,
+			"ingress.jsonnet":                file6,
+			"job.jsonnet":                    file7,
+			"namespace.jsonnet":              file8,
+			"secret-from-env.jsonnet":        file9,
+			"single-port-deployment.jsonnet": file10,
+			"single-port-service.jsonnet":    file11,
 		},
 	})
 }
@@ -0,0 +1,164 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package prototype
+
+import (
+	"sort"
+	"strings"
+)
+
+// Scores of a term matching a field, from the best match to the worst.
+const (
+	scoreExact       = 100
+	scorePrefix      = 75
+	scoreSubstring   = 50
+	scoreSubsequence = 10
+)
+
+// Weights of the fields of a prototype. Names are better matches than
+// descriptions.
+const (
+	weightName        = 3
+	weightParam       = 2
+	weightDescription = 1
+)
+
+// Search ranks the prototypes which match a query. The query is split into
+// terms, and a prototype matches if each term matches its name, descriptions,
+// or parameter names. Terms match case-insensitively, exactly, as a prefix of
+// a part of the field, as a substring, or, in names, as a subsequence, e.g.
+// `spdep` matches `single-port-deployment`. Better matches, in more important
+// fields, are ranked higher.
+func Search(prototypes Prototypes, query string) Prototypes {
+	terms := strings.Fields(strings.ToLower(query))
+
+	type result struct {
+		p     *Prototype
+		score int
+	}
+
+	var results []result
+	for _, p := range prototypes {
+		if score := searchScore(p, terms); score > 0 {
+			results = append(results, result{p: p, score: score})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].score != results[j].score {
+			return results[i].score > results[j].score
+		}
+		return results[i].p.Name < results[j].p.Name
+	})
+
+	ranked := Prototypes{}
+	for _, r := range results {
+		ranked = append(ranked, r.p)
+	}
+
+	return ranked
+}
+
+// searchScore scores a prototype for the terms of a query. It is 0 if a term
+// does not match.
+func searchScore(p *Prototype, terms []string) int {
+	if len(terms) == 0 {
+		return 0
+	}
+
+	total := 0
+	for _, term := range terms {
+		best := weightName * matchScore(p.Name, term)
+
+		for _, param := range p.Params {
+			if score := weightParam * matchScore(param.Name, term); score > best {
+				best = score
+			}
+		}
+
+		for _, description := range []string{p.Template.ShortDescription, p.Template.Description} {
+			for _, word := range strings.Fields(description) {
+				// Subsequences of words in prose are too loose to be matches.
+				score := matchScore(word, term)
+				if score > scoreSubsequence && weightDescription*score > best {
+					best = weightDescription * score
+				}
+			}
+		}
+
+		if best == 0 {
+			return 0
+		}
+		total += best
+	}
+
+	return total
+}
+
+// matchScore scores how well a term matches a field.
+func matchScore(field, term string) int {
+	field = strings.ToLower(field)
+
+	parts := strings.FieldsFunc(field, func(r rune) bool {
+		return r == '.' || r == '-' || r == '_' || r == '/'
+	})
+
+	// The last part of a name, such as `deployment` in
+	// `io.ksonnet.pkg.deployment`, is an exact match.
+	switch {
+	case field == term, len(parts) > 0 && parts[len(parts)-1] == term:
+		return scoreExact
+	case hasPrefix(parts, term):
+		return scorePrefix
+	case strings.Contains(field, term):
+		return scoreSubstring
+	case isSubsequence(field[strings.LastIndex(field, ".")+1:], term):
+		return scoreSubsequence
+	default:
+		return 0
+	}
+}
+
+// hasPrefix reports if a part of a field, which are separated by
+// punctuation, starts with the term.
+func hasPrefix(parts []string, term string) bool {
+	for _, part := range parts {
+		if strings.HasPrefix(part, term) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isSubsequence reports if the characters of the term appear in the field in
+// order, starting with the first character of the field.
+func isSubsequence(field, term string) bool {
+	if term == "" || !strings.HasPrefix(field, term[:1]) {
+		return false
+	}
+
+	runes := []rune(term)
+
+	i := 0
+	for _, r := range field {
+		if i < len(runes) && runes[i] == r {
+			i++
+		}
+	}
+
+	return i == len(runes)
+}
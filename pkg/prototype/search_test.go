@@ -0,0 +1,114 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package prototype
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearch_ranked(t *testing.T) {
+	prototypes := Prototypes{
+		{
+			Name: "io.ksonnet.pkg.single-port-deployment",
+			Params: ParamSchemas{
+				{Name: "image"},
+				{Name: "containerPort"},
+			},
+			Template: SnippetSchema{ShortDescription: "Replicates a container n times, and exposes it"},
+		},
+		{
+			Name: "io.ksonnet.pkg.single-port-service",
+			Params: ParamSchemas{
+				{Name: "targetLabelSelector"},
+			},
+			Template: SnippetSchema{ShortDescription: "Service that exposes a single port"},
+		},
+		{
+			Name:     "io.ksonnet.pkg.redis-stateless",
+			Template: SnippetSchema{ShortDescription: "Redis backed by a deployment"},
+		},
+		{
+			Name:     "io.ksonnet.pkg.configMap",
+			Template: SnippetSchema{ShortDescription: "A simple config map"},
+		},
+	}
+
+	cases := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{
+			name:  "names rank above descriptions",
+			query: "deployment",
+			expected: []string{
+				"io.ksonnet.pkg.single-port-deployment",
+				"io.ksonnet.pkg.redis-stateless",
+			},
+		},
+		{
+			name:  "prefix",
+			query: "single",
+			expected: []string{
+				"io.ksonnet.pkg.single-port-deployment",
+				"io.ksonnet.pkg.single-port-service",
+			},
+		},
+		{
+			name:     "case insensitive substring",
+			query:    "MAP",
+			expected: []string{"io.ksonnet.pkg.configMap"},
+		},
+		{
+			name:     "subsequence",
+			query:    "spdep",
+			expected: []string{"io.ksonnet.pkg.single-port-deployment"},
+		},
+		{
+			name:     "parameter names",
+			query:    "selector",
+			expected: []string{"io.ksonnet.pkg.single-port-service"},
+		},
+		{
+			name:     "every term matches",
+			query:    "single image",
+			expected: []string{"io.ksonnet.pkg.single-port-deployment"},
+		},
+		{
+			name:     "no match",
+			query:    "ingress",
+			expected: []string{},
+		},
+		{
+			name:     "empty query",
+			query:    " ",
+			expected: []string{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			names := []string{}
+			for _, p := range Search(prototypes, tc.query) {
+				names = append(names, p.Name)
+			}
+
+			assert.Equal(t, tc.expected, names)
+		})
+	}
+}
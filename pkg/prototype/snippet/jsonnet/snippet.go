@@ -320,3 +320,25 @@ func replace(jsonnet string, imports []ast.Import) (string, error) {
 	out = reEnv.ReplaceAllString(out, "env.$2")
 	return out, nil
 }
+
+var reImportStrParam = regexp.MustCompile(`importstr\s+['"]param:\/\/(\w+)['"]`)
+
+// InlineImportStr rewrites `importstr 'param://file'` into an importstr of
+// the quoted parameter value, since Jsonnet only accepts literal paths for
+// importstr and the file must be read when the component is evaluated.
+func InlineImportStr(jsonnet string, values map[string]string) (string, error) {
+	var err error
+	out := reImportStrParam.ReplaceAllStringFunc(jsonnet, func(s string) string {
+		name := reImportStrParam.FindStringSubmatch(s)[1]
+		value, ok := values[name]
+		if !ok {
+			err = errors.Errorf("no value for parameter %q used by importstr", name)
+			return s
+		}
+		return "importstr " + value
+	})
+	if err != nil {
+		return "", err
+	}
+	return out, nil
+}
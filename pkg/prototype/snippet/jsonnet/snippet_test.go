@@ -186,3 +186,13 @@ func TestParse(t *testing.T) {
 		}
 	}
 }
+
+func TestInlineImportStr(t *testing.T) {
+	got, err := InlineImportStr(`{data: importstr 'param://file', name: import 'param://name'}`,
+		map[string]string{"file": `"config/app.ini"`})
+	require.NoError(t, err)
+	require.Equal(t, `{data: importstr "config/app.ini", name: import 'param://name'}`, got)
+
+	_, err = InlineImportStr(`importstr 'param://file'`, map[string]string{})
+	require.Error(t, err)
+}
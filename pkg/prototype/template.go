@@ -0,0 +1,207 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package prototype
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/ksonnet/ksonnet/pkg/util/jsonnet"
+	"github.com/pkg/errors"
+)
+
+// TemplateEngine renders the body of a prototype.
+type TemplateEngine string
+
+const (
+	// SnippetEngine substitutes parameters into TextMate snippets and
+	// `import 'param://name'` expressions. Prototypes without a
+	// @templateEngine directive use it.
+	SnippetEngine TemplateEngine = "snippet"
+
+	// GoEngine renders the body as a Go template, with conditionals, loops,
+	// and helper functions, before parameters are substituted.
+	GoEngine TemplateEngine = "go"
+)
+
+func parseTemplateEngine(s string) (TemplateEngine, error) {
+	switch TemplateEngine(s) {
+	case SnippetEngine:
+		return SnippetEngine, nil
+	case GoEngine:
+		return GoEngine, nil
+	default:
+		return "", errors.Errorf("unknown template engine %q; must be one of: [snippet, go]", s)
+	}
+}
+
+var (
+	reDNSLabelInvalid   = regexp.MustCompile(`[^a-z0-9-]+`)
+	reIdentifierInvalid = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+)
+
+// templateFuncs are the helper functions of Go templates.
+var templateFuncs = template.FuncMap{
+	"default":    defaultValue,
+	"empty":      isEmpty,
+	"dnsLabel":   dnsLabel,
+	"identifier": identifier,
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"trim":       strings.TrimSpace,
+	"replace":    func(old, new, s string) string { return strings.Replace(s, old, new, -1) },
+	"split":      func(sep, s string) []string { return strings.Split(s, sep) },
+	"join":       join,
+	"quote":      quote,
+	"toJSON":     toJSON,
+	"indent":     indent,
+}
+
+// RenderTemplate renders the body of a prototype which uses the Go template
+// engine. Parameter values are Jsonnet literals, and are decoded before they
+// are given to the template, so `{{ range .ports }}` iterates over an array
+// parameter. Values which can't be decoded are given as they are.
+func RenderTemplate(name, body string, params map[string]string) (string, error) {
+	t, err := parseGoTemplate(name, body)
+	if err != nil {
+		return "", err
+	}
+
+	data := make(map[string]interface{})
+	vm := jsonnet.NewVM()
+	for k, v := range params {
+		data[k] = v
+
+		out, err := vm.EvaluateSnippet(k, v)
+		if err != nil {
+			continue
+		}
+
+		var decoded interface{}
+		if err = json.Unmarshal([]byte(out), &decoded); err == nil {
+			data[k] = decoded
+		}
+	}
+
+	var buf bytes.Buffer
+	if err = t.Execute(&buf, data); err != nil {
+		return "", errors.Wrapf(err, "rendering prototype %q", name)
+	}
+
+	return buf.String(), nil
+}
+
+func parseGoTemplate(name, body string) (*template.Template, error) {
+	t, err := template.New(name).
+		Option("missingkey=error").
+		Funcs(templateFuncs).
+		Parse(body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing template of prototype %q", name)
+	}
+
+	return t, nil
+}
+
+// defaultValue returns value, or def if value is empty. It is used as
+// `{{ .replicas | default 1 }}`.
+func defaultValue(def, value interface{}) interface{} {
+	if isEmpty(value) {
+		return def
+	}
+	return value
+}
+
+// isEmpty reports whether a value is nil, false, zero, or has no elements.
+func isEmpty(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
+
+// dnsLabel mangles a value into a DNS label, which is what most Kubernetes
+// object names must be.
+func dnsLabel(value interface{}) string {
+	s := reDNSLabelInvalid.ReplaceAllString(strings.ToLower(fmt.Sprint(value)), "-")
+	if len(s) > 63 {
+		s = s[:63]
+	}
+	return strings.Trim(s, "-")
+}
+
+// identifier mangles a value into a Jsonnet identifier.
+func identifier(value interface{}) string {
+	s := reIdentifierInvalid.ReplaceAllString(fmt.Sprint(value), "_")
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		s = "_" + s
+	}
+	return s
+}
+
+// join joins the elements of a list with a separator.
+func join(sep string, value interface{}) string {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return fmt.Sprint(value)
+	}
+
+	var parts []string
+	for i := 0; i < v.Len(); i++ {
+		parts = append(parts, fmt.Sprint(v.Index(i).Interface()))
+	}
+	return strings.Join(parts, sep)
+}
+
+// quote quotes a value as a JSON string, which is also a valid YAML and
+// Jsonnet string.
+func quote(value interface{}) (string, error) {
+	return toJSON(fmt.Sprint(value))
+}
+
+func toJSON(value interface{}) (string, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// indent indents every line of a value by n spaces.
+func indent(n int, value interface{}) string {
+	pad := strings.Repeat(" ", n)
+	return pad + strings.Replace(fmt.Sprint(value), "\n", "\n"+pad, -1)
+}
@@ -0,0 +1,119 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package prototype
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	cases := []struct {
+		name     string
+		body     string
+		params   map[string]string
+		expected string
+		isErr    bool
+	}{
+		{
+			name:     "substitution",
+			body:     `name: {{ .name }}`,
+			params:   map[string]string{"name": `"guestbook"`},
+			expected: `name: guestbook`,
+		},
+		{
+			name: "conditional",
+			body: `{{ if .ingressHost }}host: {{ .ingressHost }}{{ else }}no ingress{{ end }}`,
+			params: map[string]string{
+				"ingressHost": `""`,
+			},
+			expected: `no ingress`,
+		},
+		{
+			name: "loop",
+			body: `{{ range .ports }}- port: {{ . }}
+{{ end }}`,
+			params:   map[string]string{"ports": `[80, 443]`},
+			expected: "- port: 80\n- port: 443\n",
+		},
+		{
+			name: "object",
+			body: `{{ range $k, $v := .labels }}{{ $k }}={{ $v }} {{ end }}`,
+			params: map[string]string{
+				"labels": `{app: "guestbook", tier: "web"}`,
+			},
+			expected: `app=guestbook tier=web `,
+		},
+		{
+			name: "helpers",
+			body: `{{ .name | dnsLabel }} {{ .name | identifier }} {{ .replicas | default 1 }} {{ .ports | join "," }} {{ .name | quote }} {{ .labels | toJSON }}`,
+			params: map[string]string{
+				"name":     `"My_App.v2"`,
+				"replicas": `0`,
+				"ports":    `[80, 443]`,
+				"labels":   `{app: "web"}`,
+			},
+			expected: `my-app-v2 My_App_v2 1 80,443 "My_App.v2" {"app":"web"}`,
+		},
+		{
+			name:     "undecodable value",
+			body:     `{{ .value }}`,
+			params:   map[string]string{"value": `std.missing`},
+			expected: `std.missing`,
+		},
+		{
+			name:  "missing parameter",
+			body:  `{{ .missing }}`,
+			isErr: true,
+		},
+		{
+			name:  "invalid template",
+			body:  `{{ if .name }}`,
+			isErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := RenderTemplate("prototype", tc.body, tc.params)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func Test_dnsLabel(t *testing.T) {
+	cases := []struct {
+		in       interface{}
+		expected string
+	}{
+		{in: "web", expected: "web"},
+		{in: "My App", expected: "my-app"},
+		{in: "-app.v1-", expected: "app-v1"},
+		{in: 8080, expected: "8080"},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.expected, dnsLabel(tc.in))
+	}
+}
@@ -0,0 +1,262 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package redact masks the values of sensitive fields, such as the data of
+// Secrets, in objects which are shown, so they don't leak into terminals or
+// CI logs.
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// secretPointers are the fields of a Secret which are always redacted. The
+// annotations hold copies of the whole Secret.
+var secretPointers = []string{
+	"/data/*",
+	"/stringData/*",
+	"/metadata/annotations/" + escape(metadata.AnnotationLastApplied),
+	"/metadata/annotations/" + escape(metadata.AnnotationManaged),
+}
+
+// logRedactor redacts the data of Secrets in objects which are logged.
+var logRedactor, _ = New(nil)
+
+// Object returns a copy of obj with the data of a Secret redacted, for
+// objects which are logged.
+func Object(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	return logRedactor.Object(obj)
+}
+
+// fieldPath is a path to fields. A * segment selects every member of an
+// object or array.
+type fieldPath []string
+
+// parsePointer parses a JSON6902 path, e.g. /data/a~1b.
+func parsePointer(s string) (fieldPath, error) {
+	if !strings.HasPrefix(s, "/") || s == "/" {
+		return nil, errors.Errorf("invalid JSON pointer %q; pointers start with /", s)
+	}
+
+	var path fieldPath
+	for _, token := range strings.Split(s[1:], "/") {
+		token = strings.Replace(token, "~1", "/", -1)
+		token = strings.Replace(token, "~0", "~", -1)
+		path = append(path, token)
+	}
+
+	return path, nil
+}
+
+// escape escapes a key for use in a JSON pointer.
+func escape(s string) string {
+	s = strings.Replace(s, "~", "~0", -1)
+	return strings.Replace(s, "/", "~1", -1)
+}
+
+// mask replaces the values selected by path in v with their masks, and
+// returns the updated value.
+func (path fieldPath) mask(v interface{}, maskFn func(interface{}) string) interface{} {
+	if len(path) == 0 {
+		return maskFn(v)
+	}
+
+	seg, rest := path[0], path[1:]
+
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			if seg == "*" || seg == k {
+				t[k] = rest.mask(child, maskFn)
+			}
+		}
+	case []interface{}:
+		for i, child := range t {
+			if seg == "*" || seg == strconv.Itoa(i) {
+				t[i] = rest.mask(child, maskFn)
+			}
+		}
+	}
+
+	return v
+}
+
+// rule selects the fields of objects which are redacted.
+type rule struct {
+	group string
+	kind  string
+	paths []fieldPath
+}
+
+func newRule(group, kind string, pointers []string) (rule, error) {
+	r := rule{group: group, kind: kind}
+	for _, p := range pointers {
+		path, err := parsePointer(p)
+		if err != nil {
+			return rule{}, err
+		}
+		r.paths = append(r.paths, path)
+	}
+
+	return r, nil
+}
+
+func (r rule) matches(gvk schema.GroupVersionKind) bool {
+	if r.group != "" && r.group != gvk.Group {
+		return false
+	}
+
+	return r.kind == "" || r.kind == gvk.Kind
+}
+
+// Opt is an option for configuring Redactor.
+type Opt func(*Redactor)
+
+// Key sets the key values are hashed with to create their masks. By default,
+// the key is random.
+func Key(key []byte) Opt {
+	return func(r *Redactor) {
+		r.key = key
+	}
+}
+
+// Redactor masks the data of Secrets, and the fields selected by redact
+// rules. A value is masked with a hash of the value, so the masks of equal
+// values are equal, and changes to values show up in diffs without
+// revealing them.
+type Redactor struct {
+	rules []rule
+	key   []byte
+}
+
+// New creates an instance of Redactor, which redacts the fields selected by
+// configs in addition to the data of Secrets.
+func New(configs []*app.RedactConfig, opts ...Opt) (*Redactor, error) {
+	secrets, err := newRule("", "Secret", secretPointers)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Redactor{rules: []rule{secrets}}
+
+	for i, c := range configs {
+		if c == nil {
+			continue
+		}
+
+		cr, err := newRule(c.Group, c.Kind, c.JSONPointers)
+		if err != nil {
+			return nil, errors.Wrapf(err, "redact rule %d", i+1)
+		}
+		r.rules = append(r.rules, cr)
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if r.key == nil {
+		r.key = make([]byte, 32)
+		if _, err := rand.Read(r.key); err != nil {
+			return nil, errors.Wrap(err, "creating redaction key")
+		}
+	}
+
+	return r, nil
+}
+
+// ForEnvironment creates an instance of Redactor with the redact rules of an
+// environment.
+func ForEnvironment(a app.App, envName string, opts ...Opt) (*Redactor, error) {
+	e, err := a.Environment(envName)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(e.Redact, opts...)
+}
+
+// Objects returns objects with their redacted values masked, as Object
+// does.
+func (r *Redactor) Objects(objects []*unstructured.Unstructured) []*unstructured.Unstructured {
+	if r == nil {
+		return objects
+	}
+
+	redacted := make([]*unstructured.Unstructured, 0, len(objects))
+	for _, obj := range objects {
+		redacted = append(redacted, r.Object(obj))
+	}
+
+	return redacted
+}
+
+// Object returns a copy of obj with its redacted values masked. Objects
+// which no rule applies to, and all objects of a nil Redactor, are returned
+// as they are.
+func (r *Redactor) Object(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	if r == nil || obj == nil {
+		return obj
+	}
+
+	gvk := obj.GroupVersionKind()
+
+	var redacted *unstructured.Unstructured
+	for _, rule := range r.rules {
+		if !rule.matches(gvk) {
+			continue
+		}
+
+		if redacted == nil {
+			redacted = obj.DeepCopy()
+		}
+
+		for _, path := range rule.paths {
+			path.mask(redacted.Object, r.mask)
+		}
+	}
+
+	if redacted == nil {
+		return obj
+	}
+
+	return redacted
+}
+
+// mask returns the mask of a value.
+func (r *Redactor) mask(v interface{}) string {
+	s, ok := v.(string)
+	if !ok {
+		b, _ := json.Marshal(v)
+		s = string(b)
+	}
+
+	h := hmac.New(sha256.New, r.key)
+	h.Write([]byte(s))
+
+	return fmt.Sprintf("<redacted:%x>", h.Sum(nil)[:4])
+}
@@ -0,0 +1,132 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package redact
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func secret(data map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]interface{}{
+			"name": "db",
+			"annotations": map[string]interface{}{
+				"kubectl.kubernetes.io/last-applied-configuration": `{"data":{"password":"c2VjcmV0"}}`,
+				"team": "storage",
+			},
+		},
+		"data": data,
+	}}
+}
+
+func TestRedactor_Object(t *testing.T) {
+	r, err := New([]*app.RedactConfig{
+		{Kind: "Deployment", JSONPointers: []string{"/spec/template/spec/containers/*/env/*/value"}},
+	}, Key([]byte("key")))
+	require.NoError(t, err)
+
+	t.Run("secret", func(t *testing.T) {
+		obj := secret(map[string]interface{}{"password": "c2VjcmV0", "user": "YWRtaW4="})
+
+		got := r.Object(obj)
+
+		data := got.Object["data"].(map[string]interface{})
+		assert.Regexp(t, `^<redacted:[0-9a-f]{8}>$`, data["password"])
+		assert.Regexp(t, `^<redacted:[0-9a-f]{8}>$`, data["user"])
+		assert.NotEqual(t, data["password"], data["user"])
+
+		annotations := got.GetAnnotations()
+		assert.Regexp(t, `^<redacted:[0-9a-f]{8}>$`, annotations["kubectl.kubernetes.io/last-applied-configuration"])
+		assert.Equal(t, "storage", annotations["team"])
+
+		assert.Equal(t, "c2VjcmV0", obj.Object["data"].(map[string]interface{})["password"], "original is unchanged")
+	})
+
+	t.Run("equal values have equal masks", func(t *testing.T) {
+		a := r.Object(secret(map[string]interface{}{"password": "c2VjcmV0"}))
+		b := r.Object(secret(map[string]interface{}{"password": "c2VjcmV0"}))
+		c := r.Object(secret(map[string]interface{}{"password": "b3RoZXI="}))
+
+		assert.Equal(t, a.Object["data"], b.Object["data"])
+		assert.NotEqual(t, a.Object["data"], c.Object["data"])
+	})
+
+	t.Run("rule", func(t *testing.T) {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]interface{}{"name": "web"},
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{
+								"name":  "web",
+								"image": "nginx",
+								"env": []interface{}{
+									map[string]interface{}{"name": "TOKEN", "value": "abc"},
+								},
+							},
+						},
+					},
+				},
+			},
+		}}
+
+		got := r.Object(obj)
+
+		container := got.Object["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})["containers"].([]interface{})[0].(map[string]interface{})
+		env := container["env"].([]interface{})[0].(map[string]interface{})
+		assert.Regexp(t, `^<redacted:[0-9a-f]{8}>$`, env["value"])
+		assert.Equal(t, "TOKEN", env["name"])
+		assert.Equal(t, "nginx", container["image"])
+	})
+
+	t.Run("other kinds", func(t *testing.T) {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "config"},
+			"data":       map[string]interface{}{"password": "secret"},
+		}}
+
+		assert.Equal(t, obj, r.Object(obj))
+	})
+}
+
+func TestRedactor_nil(t *testing.T) {
+	var r *Redactor
+
+	objects := []*unstructured.Unstructured{secret(map[string]interface{}{"password": "c2VjcmV0"})}
+	assert.Equal(t, objects, r.Objects(objects))
+}
+
+func TestNew_invalid_pointer(t *testing.T) {
+	_, err := New([]*app.RedactConfig{{Kind: "ConfigMap", JSONPointers: []string{"data"}}})
+	require.Error(t, err)
+}
+
+func TestObject(t *testing.T) {
+	got := Object(secret(map[string]interface{}{"password": "c2VjcmV0"}))
+	assert.Regexp(t, `^<redacted:[0-9a-f]{8}>$`, got.Object["data"].(map[string]interface{})["password"])
+}
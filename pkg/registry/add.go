@@ -19,41 +19,19 @@ import (
 	"net/http"
 
 	"github.com/ksonnet/ksonnet/pkg/app"
-	"github.com/ksonnet/ksonnet/pkg/helm"
-	"github.com/ksonnet/ksonnet/pkg/util/github"
 	"github.com/pkg/errors"
 )
 
 // Add adds a registry with `name`, `protocol`, and `uri` to
 // the current ksonnet application.
 func Add(a app.App, protocol Protocol, name string, uri string, isOverride bool, httpClient *http.Client) (*Spec, error) {
-	var r Registry
-	var err error
-
 	initSpec := &app.RegistryConfig{
 		Name:     name,
 		Protocol: string(protocol),
 		URI:      uri,
 	}
 
-	switch protocol {
-	case ProtocolGitHub:
-		var ghc = github.NewGitHub(httpClient)
-		r, err = githubFactory(a, initSpec, GitHubClient(ghc))
-	case ProtocolFilesystem:
-		r, err = NewFs(a, initSpec)
-	case ProtocolHelm:
-		var hc *helm.HTTPClient
-		hc, err = helm.NewHTTPClient(initSpec.URI, httpClient)
-		if err != nil {
-			return nil, errors.Wrap(err, "initializing helm HTTP client")
-		}
-		cc := helm.NewCachingClient(hc)
-		r, err = helmFactory(a, initSpec, cc)
-	default:
-		return nil, errors.Errorf("invalid registry protocol %q", protocol)
-	}
-
+	r, err := newRegistry(a, initSpec, httpClient)
 	if err != nil {
 		return nil, errors.Wrap(err, "adding registry")
 	}
@@ -64,8 +64,9 @@ func TestAdd_GitHub(t *testing.T) {
 		appMock.On("AddRegistry", expectedSpec, true).Return(nil)
 
 		ghMock := &mocks.GitHub{}
+		ghMock.On("SetBaseURL", mock.Anything)
 		ghMock.On("ValidateURL", "github.com/foo/bar").Return(nil)
-		ghMock.On("CommitSHA1", mock.Anything, mock.Anything, "master").Return("40285d8a14f1ac5787e405e1023cf0c07f6aa28c", nil)
+		ghMock.On("CommitSHA1", mock.Anything, mock.Anything, "").Return("40285d8a14f1ac5787e405e1023cf0c07f6aa28c", nil)
 
 		registryContent := buildContent(t, registryYAMLFile)
 		ghMock.On(
@@ -17,22 +17,23 @@ package registry
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"path/filepath"
 	"strings"
 
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/pkg"
+	"github.com/ksonnet/ksonnet/pkg/util/trace"
 	"github.com/pkg/errors"
-	log "github.com/sirupsen/logrus"
-	"github.com/spf13/afero"
+	"github.com/sirupsen/logrus"
 )
 
 // CacheDependency vendors registry dependencies.
 // TODO: create unit tests for this once mocks for this package are
 // worked out.
 func CacheDependency(a app.App, checker InstalledChecker, d pkg.Descriptor, customName string, force bool, httpClient *http.Client) (*app.LibraryConfig, error) {
-	logger := log.WithFields(log.Fields{
+	logger := log.WithFields(logrus.Fields{
 		"action":      "registry.CacheDependency",
 		"part":        d.Name,
 		"registry":    d.Registry,
@@ -69,6 +70,23 @@ func CacheDependency(a app.App, checker InstalledChecker, d pkg.Descriptor, cust
 		return nil, errors.Wrapf(err, "resolving package metadata: %v", d)
 	}
 
+	regSpec, err := r.FetchRegistrySpec()
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching registry spec for %s", d.Registry)
+	}
+
+	if err := checkLibraryStatus(regSpec, d, libSpec.Version, force); err != nil {
+		return nil, err
+	}
+
+	// Hold the vendor lock while installing so concurrent ks processes
+	// don't install the same package at the same time.
+	lock, err := lockCache(a, "vendor")
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Release()
+
 	// Check whether this library version is already installed
 	var qualified = d
 	qualified.Version = libSpec.Version
@@ -86,23 +104,46 @@ func CacheDependency(a app.App, checker InstalledChecker, d pkg.Descriptor, cust
 		}, nil
 	}
 
-	// Get all directories and files first, then write to disk. This
-	// protects us from failing with a half-cached dependency because of
-	// a network failure.
+	// Get all directories and files first, staging them on disk, then move
+	// them into the vendor directory. This protects us from failing with a
+	// half-cached dependency because of a network failure, without holding
+	// every file in memory.
+	vendorRoot := a.VendorPath()
+	if err = removeStaleStaging(a.Fs(), vendorRoot); err != nil {
+		return nil, errors.Wrap(err, "removing stale staging directories")
+	}
+
+	install, err := newVendorInstall(a.Fs(), vendorRoot)
+	if err != nil {
+		return nil, err
+	}
+	defer install.cleanup()
+
+	// Interrupting the install skips deferred calls, so clean up explicitly.
+	stopInterrupt := install.onInterrupt(func() {
+		install.cleanup()
+		lock.Release()
+	})
+	defer stopInterrupt()
+
 	directories := []string{}
-	files := map[string][]byte{}
+	files := []string{}
+	span := trace.Start("registry.resolve", "registry", d.Registry, "package", d.Name, "version", d.Version)
 	_, libRef, err := r.ResolveLibrary(
 		d.Name,
 		customName,
 		d.Version,
-		func(relPath string, contents []byte) error {
-			files[relPath] = contents
+		func(relPath string, r io.Reader) error {
+			if err := install.stage(relPath, r); err != nil {
+				return err
+			}
+			files = append(files, relPath)
 			return nil
 		},
 		func(relPath string) error {
 			return nil
 		})
-	if err != nil {
+	if err = span.Finish(err); err != nil {
 		return nil, errors.Wrap(err, "resolve registry library")
 	}
 
@@ -119,23 +160,17 @@ func CacheDependency(a app.App, checker InstalledChecker, d pkg.Descriptor, cust
 		}
 	}
 
-	vendorRoot := a.VendorPath()
-	for path, content := range files {
+	for _, path := range files {
 		vendoredPath := versionAndVendorRelPath(libRef, vendorRoot, path)
 		if vendoredPath == "" {
 			log.Warnf("problem vendoring file: %v", path)
 			continue
 		}
-		dir := filepath.Dir(filepath.FromSlash(vendoredPath))
-
-		log.Debugf("onFile: vendoring file to path: %v", vendoredPath)
-		if err = a.Fs().MkdirAll(dir, app.DefaultFolderPermissions); err != nil {
-			return nil, errors.Wrap(err, "unable to create directory")
-		}
+		install.move(path, filepath.FromSlash(vendoredPath))
+	}
 
-		if err = afero.WriteFile(a.Fs(), vendoredPath, content, app.DefaultFilePermissions); err != nil {
-			return nil, errors.Wrap(err, "unable to create file")
-		}
+	if err = install.commit(); err != nil {
+		return nil, err
 	}
 
 	return libRef, nil
@@ -17,6 +17,7 @@ package registry
 
 import (
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/ksonnet/ksonnet/pkg/app"
@@ -76,6 +77,56 @@ func Test_CacheDependency(t *testing.T) {
 
 			test.AssertExists(t, fs, filepath.Join(a.Root(), "vendor", lib.Registry, lib.Name, "parts.yaml"))
 		}
+
+		// Staged files are moved into place and the staging directory is removed.
+		fis, err := afero.ReadDir(fs, "/app/vendor")
+		require.NoError(t, err)
+		for _, fi := range fis {
+			assert.False(t, strings.HasPrefix(fi.Name(), ".ks-staging-"), "staging directory %s was not removed", fi.Name())
+		}
+
+		test.AssertNotExists(t, fs, filepath.Join(a.Root(), ".ksonnet", "locks", "vendor"))
+	})
+}
+
+func Test_CacheDependency_yanked(t *testing.T) {
+	withApp(t, func(a *amocks.App, fs afero.Fs) {
+		a.On("VendorPath").Return("/app/vendor")
+
+		test.StageDir(t, fs, "incubator", filepath.Join("/work", "incubator"))
+
+		registrySpec := `apiVersion: '0.1'
+kind: ksonnet.io/registry
+libraries:
+  redis:
+    version: master
+    path: redis
+    yanked: true
+    yankMessage: breaks persistence
+`
+		require.NoError(t, afero.WriteFile(fs, "/work/incubator/registry.yaml", []byte(registrySpec), 0644))
+
+		registries := app.RegistryConfigs{
+			"incubator": &app.RegistryConfig{
+				Name:     "incubator",
+				Protocol: string(ProtocolFilesystem),
+				URI:      "/work/incubator",
+			},
+		}
+		a.On("Registries").Return(registries, nil)
+
+		var checker installedChecker
+		d := pkg.Descriptor{Registry: "incubator", Name: "redis"}
+
+		_, err := CacheDependency(a, &checker, d, "", false, nil)
+		require.Error(t, err)
+		_, ok := err.(*YankedError)
+		assert.True(t, ok)
+		test.AssertNotExists(t, fs, filepath.Join(a.Root(), "vendor", "incubator", "redis", "parts.yaml"))
+
+		_, err = CacheDependency(a, &checker, d, "", true, nil)
+		require.NoError(t, err)
+		test.AssertExists(t, fs, filepath.Join(a.Root(), "vendor", "incubator", "redis", "parts.yaml"))
 	})
 }
 
@@ -0,0 +1,130 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package registry
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ksonnet/ksonnet/pkg/pkg"
+)
+
+// YankedError is returned when installing a package version which its
+// registry has yanked.
+type YankedError struct {
+	Package pkg.Descriptor
+	Message string
+}
+
+func (e *YankedError) Error() string {
+	msg := fmt.Sprintf("package %v has been yanked", e.Package)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+
+	return msg + "; force the installation to use it anyway"
+}
+
+// deprecationWarning returns the warning for a deprecated library, or an
+// empty string if the library is not deprecated.
+func deprecationWarning(d pkg.Descriptor, lib *LibraryConfig) string {
+	if lib == nil || !lib.Deprecated {
+		return ""
+	}
+
+	warning := fmt.Sprintf("package %s/%s is deprecated", d.Registry, d.Name)
+	if lib.DeprecationMessage != "" {
+		warning += ": " + lib.DeprecationMessage
+	}
+	if lib.Replacement != "" {
+		warning += fmt.Sprintf(" (use %s instead)", lib.Replacement)
+	}
+
+	return warning
+}
+
+// checkLibraryStatus checks the registry's metadata for a package version
+// before it is installed. Yanked versions return a YankedError unless force
+// is set, and deprecated libraries are logged.
+func checkLibraryStatus(spec *Spec, d pkg.Descriptor, version string, force bool) error {
+	if spec == nil {
+		return nil
+	}
+
+	lib, ok := spec.Libraries[d.Name]
+	if !ok {
+		return nil
+	}
+
+	// A registry entry describes a single version, so a different version
+	// requested explicitly is not yanked.
+	pinned := d.Version != "" && d.Version != lib.Version && version != lib.Version
+	if lib.Yanked && !pinned {
+		qualified := d
+		qualified.Version = version
+		if qualified.Version == "" {
+			qualified.Version = lib.Version
+		}
+
+		if !force {
+			return &YankedError{Package: qualified, Message: lib.YankMessage}
+		}
+		log.Warnf("installing yanked package %v", qualified)
+	}
+
+	if warning := deprecationWarning(d, lib); warning != "" {
+		log.Warn(warning)
+	}
+
+	return nil
+}
+
+// DeprecationWarnings returns warnings for the packages which their
+// registries mark as deprecated, sorted by package. Registries which can't
+// be fetched are skipped.
+func DeprecationWarnings(registries []Registry, packages []pkg.Descriptor) []string {
+	specs := make(map[string]*Spec)
+	for _, r := range registries {
+		spec, err := r.FetchRegistrySpec()
+		if err != nil {
+			log.WithError(err).Debugf("skipping registry %s while checking for deprecated packages", r.Name())
+			continue
+		}
+		specs[r.Name()] = spec
+	}
+
+	seen := make(map[string]bool)
+	var warnings []string
+	for _, d := range packages {
+		spec, ok := specs[d.Registry]
+		if !ok {
+			continue
+		}
+
+		key := d.Registry + "/" + d.Name
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if warning := deprecationWarning(d, spec.Libraries[d.Name]); warning != "" {
+			warnings = append(warnings, warning)
+		}
+	}
+
+	sort.Strings(warnings)
+	return warnings
+}
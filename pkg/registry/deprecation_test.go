@@ -0,0 +1,138 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package registry
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/pkg"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// specRegistry is a Registry which only serves a registry spec.
+type specRegistry struct {
+	Registry
+	name string
+	spec *Spec
+	err  error
+}
+
+func (r *specRegistry) Name() string {
+	return r.name
+}
+
+func (r *specRegistry) FetchRegistrySpec() (*Spec, error) {
+	return r.spec, r.err
+}
+
+func Test_checkLibraryStatus(t *testing.T) {
+	spec := &Spec{
+		Libraries: LibraryConfigs{
+			"redis": &LibraryConfig{
+				Version:     "1.0.0",
+				Yanked:      true,
+				YankMessage: "breaks persistence",
+			},
+			"nginx": &LibraryConfig{
+				Version:     "2.0.0",
+				Deprecated:  true,
+				Replacement: "stable/nginx",
+			},
+		},
+	}
+
+	cases := []struct {
+		name     string
+		d        pkg.Descriptor
+		version  string
+		force    bool
+		isYanked bool
+	}{
+		{
+			name:     "yanked version",
+			d:        pkg.Descriptor{Registry: "incubator", Name: "redis"},
+			version:  "1.0.0",
+			isYanked: true,
+		},
+		{
+			name:    "yanked version forced",
+			d:       pkg.Descriptor{Registry: "incubator", Name: "redis"},
+			version: "1.0.0",
+			force:   true,
+		},
+		{
+			name:    "other version of yanked library",
+			d:       pkg.Descriptor{Registry: "incubator", Name: "redis", Version: "0.9.0"},
+			version: "0.9.0",
+		},
+		{
+			name:    "deprecated library",
+			d:       pkg.Descriptor{Registry: "incubator", Name: "nginx"},
+			version: "2.0.0",
+		},
+		{
+			name:    "unknown library",
+			d:       pkg.Descriptor{Registry: "incubator", Name: "apache"},
+			version: "1.0.0",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkLibraryStatus(spec, tc.d, tc.version, tc.force)
+			if !tc.isYanked {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			yerr, ok := err.(*YankedError)
+			require.True(t, ok)
+			assert.Equal(t, "package incubator/redis@1.0.0 has been yanked: breaks persistence; force the installation to use it anyway", yerr.Error())
+		})
+	}
+}
+
+func TestDeprecationWarnings(t *testing.T) {
+	incubator := &specRegistry{name: "incubator", spec: &Spec{
+		Libraries: LibraryConfigs{
+			"nginx": &LibraryConfig{
+				Deprecated:         true,
+				DeprecationMessage: "no longer maintained",
+				Replacement:        "stable/nginx",
+			},
+			"redis":  &LibraryConfig{Deprecated: true},
+			"apache": &LibraryConfig{},
+		},
+	}}
+	broken := &specRegistry{name: "broken", err: errors.New("unavailable")}
+
+	packages := []pkg.Descriptor{
+		{Registry: "incubator", Name: "redis", Version: "1.0.0"},
+		{Registry: "incubator", Name: "nginx", Version: "1.0.0"},
+		{Registry: "incubator", Name: "nginx", Version: "2.0.0"},
+		{Registry: "incubator", Name: "apache"},
+		{Registry: "broken", Name: "mysql"},
+	}
+
+	expected := []string{
+		"package incubator/nginx is deprecated: no longer maintained (use stable/nginx instead)",
+		"package incubator/redis is deprecated",
+	}
+	assert.Equal(t, expected, DeprecationWarnings([]Registry{incubator, broken}, packages))
+}
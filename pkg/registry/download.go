@@ -0,0 +1,179 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package registry
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+)
+
+const (
+	// DefaultMaxFileSize is the largest file a part can contain.
+	DefaultMaxFileSize int64 = 100 << 20
+
+	// progressThreshold is the file size at which download progress is reported.
+	progressThreshold int64 = 1 << 20
+)
+
+// FileTooLargeError is returned when a part contains a file which is larger
+// than the size limit.
+type FileTooLargeError struct {
+	Path  string
+	Limit int64
+}
+
+func (e *FileTooLargeError) Error() string {
+	return fmt.Sprintf("file %q is larger than the %s limit", e.Path, formatSize(e.Limit))
+}
+
+// ChecksumMismatchError is returned when a downloaded file doesn't match the
+// checksum its registry reported for it.
+type ChecksumMismatchError struct {
+	Path     string
+	Expected string
+	Actual   string
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("file %q has checksum %s; expected %s", e.Path, e.Actual, e.Expected)
+}
+
+// blobHashReader verifies a file against its git blob SHA-1 once it has been
+// read. The mismatch is returned in place of EOF, so a corrupt file is never
+// reported as complete.
+type blobHashReader struct {
+	r        io.Reader
+	path     string
+	expected string
+	h        hash.Hash
+}
+
+func newBlobHashReader(r io.Reader, path string, size int64, expected string) io.Reader {
+	if expected == "" {
+		return r
+	}
+
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", size)
+
+	return &blobHashReader{r: r, path: path, expected: expected, h: h}
+}
+
+func (br *blobHashReader) Read(p []byte) (int, error) {
+	n, err := br.r.Read(p)
+	br.h.Write(p[:n])
+
+	if err == io.EOF {
+		if actual := hex.EncodeToString(br.h.Sum(nil)); actual != br.expected {
+			return n, &ChecksumMismatchError{Path: br.path, Expected: br.expected, Actual: actual}
+		}
+	}
+
+	return n, err
+}
+
+// limitReader reads from r until limit bytes have been read. Unlike
+// io.LimitReader, reading more than limit bytes is an error rather than EOF,
+// so truncated files are never written.
+type limitReader struct {
+	r     io.Reader
+	path  string
+	limit int64
+	n     int64
+}
+
+func newLimitReader(r io.Reader, path string, limit int64) io.Reader {
+	if limit <= 0 {
+		return r
+	}
+
+	return &limitReader{r: r, path: path, limit: limit}
+}
+
+func (lr *limitReader) Read(p []byte) (int, error) {
+	n, err := lr.r.Read(p)
+	lr.n += int64(n)
+	if lr.n > lr.limit {
+		return n, &FileTooLargeError{Path: lr.path, Limit: lr.limit}
+	}
+
+	return n, err
+}
+
+// progressReader logs the progress of reading a file of a known size. It
+// reports each quarter of the file read.
+type progressReader struct {
+	r        io.Reader
+	path     string
+	size     int64
+	n        int64
+	reported int64
+	logFn    func(format string, args ...interface{})
+}
+
+func newProgressReader(r io.Reader, path string, size int64) io.Reader {
+	if size < progressThreshold {
+		return r
+	}
+
+	return &progressReader{r: r, path: path, size: size, logFn: log.Infof}
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	n, err := pr.r.Read(p)
+	pr.n += int64(n)
+
+	quarter := pr.n * 4 / pr.size
+	if quarter > 4 {
+		quarter = 4
+	}
+
+	if quarter > pr.reported {
+		pr.reported = quarter
+		pr.logFn("Downloading %s: %s of %s (%d%%)",
+			pr.path, formatSize(pr.n), formatSize(pr.size), quarter*25)
+	}
+
+	return n, err
+}
+
+// formatSize formats a size in bytes using binary units.
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// checkFileSize returns an error if a file's reported size exceeds the limit.
+func checkFileSize(path string, size, limit int64) error {
+	if limit > 0 && size > limit {
+		return &FileTooLargeError{Path: path, Limit: limit}
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package registry
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_limitReader(t *testing.T) {
+	cases := []struct {
+		name  string
+		limit int64
+		isErr bool
+	}{
+		{name: "under limit", limit: 10},
+		{name: "at limit", limit: 8},
+		{name: "over limit", limit: 4, isErr: true},
+		{name: "no limit", limit: 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := newLimitReader(strings.NewReader("contents"), "file.txt", tc.limit)
+
+			data, err := ioutil.ReadAll(r)
+			if tc.isErr {
+				require.Error(t, err)
+				_, ok := err.(*FileTooLargeError)
+				assert.True(t, ok)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "contents", string(data))
+		})
+	}
+}
+
+func Test_blobHashReader(t *testing.T) {
+	cases := []struct {
+		name     string
+		contents string
+		expected string
+		isErr    bool
+	}{
+		{
+			name:     "matching checksum",
+			contents: "download",
+			expected: "8a58e1ae8de147c52caaff6f2cc51af88604915b",
+		},
+		{
+			name:     "mismatched checksum",
+			contents: "downlaod",
+			expected: "8a58e1ae8de147c52caaff6f2cc51af88604915b",
+			isErr:    true,
+		},
+		{
+			name:     "no checksum",
+			contents: "download",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := newBlobHashReader(strings.NewReader(tc.contents), "file.txt", int64(len(tc.contents)), tc.expected)
+
+			data, err := ioutil.ReadAll(r)
+			if tc.isErr {
+				require.Error(t, err)
+				_, ok := err.(*ChecksumMismatchError)
+				assert.True(t, ok)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.contents, string(data))
+		})
+	}
+}
+
+func Test_progressReader(t *testing.T) {
+	size := 4 * progressThreshold
+	r := newProgressReader(strings.NewReader(strings.Repeat("x", int(size))), "file.txt", size)
+
+	pr, ok := r.(*progressReader)
+	require.True(t, ok)
+
+	var messages []string
+	pr.logFn = func(format string, args ...interface{}) {
+		messages = append(messages, fmt.Sprintf(format, args...))
+	}
+
+	_, err := io.Copy(ioutil.Discard, pr)
+	require.NoError(t, err)
+
+	expected := []string{
+		"Downloading file.txt: 1.0 MiB of 4.0 MiB (25%)",
+		"Downloading file.txt: 2.0 MiB of 4.0 MiB (50%)",
+		"Downloading file.txt: 3.0 MiB of 4.0 MiB (75%)",
+		"Downloading file.txt: 4.0 MiB of 4.0 MiB (100%)",
+	}
+	assert.Equal(t, expected, messages)
+}
+
+func Test_progressReader_small_file(t *testing.T) {
+	r := strings.NewReader("contents")
+	assert.Equal(t, r, newProgressReader(r, "file.txt", 8))
+}
+
+func Test_formatSize(t *testing.T) {
+	cases := []struct {
+		size     int64
+		expected string
+	}{
+		{size: 512, expected: "512 B"},
+		{size: 1536, expected: "1.5 KiB"},
+		{size: DefaultMaxFileSize, expected: "100.0 MiB"},
+		{size: 3 << 30, expected: "3.0 GiB"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.expected, func(t *testing.T) {
+			assert.Equal(t, tc.expected, formatSize(tc.size))
+		})
+	}
+}
@@ -16,6 +16,7 @@
 package registry
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"os"
@@ -85,7 +86,7 @@ func (fs *Fs) RegistrySpecDir() string {
 
 // RegistrySpecFilePath is the path for the registry.yaml
 func (fs *Fs) RegistrySpecFilePath() string {
-	logrus.WithFields(logrus.Fields{
+	log.WithFields(logrus.Fields{
 		"fs-registry-root": fs.root,
 	}).Debug("creating registry config file path")
 
@@ -94,7 +95,7 @@ func (fs *Fs) RegistrySpecFilePath() string {
 
 // FetchRegistrySpec fetches the registry spec.
 func (fs *Fs) FetchRegistrySpec() (*Spec, error) {
-	logrus.WithFields(logrus.Fields{
+	log.WithFields(logrus.Fields{
 		"file-path": fs.RegistrySpecFilePath(),
 	}).Debug("fetching registry spec")
 
@@ -117,6 +118,22 @@ func (fs *Fs) FetchRegistrySpec() (*Spec, error) {
 	return Unmarshal(data)
 }
 
+// Status checks the registry can be read. Filesystem registries aren't
+// cached, so they are always current.
+func (fs *Fs) Status() (*Status, error) {
+	spec, err := fs.FetchRegistrySpec()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Status{Version: spec.Version, Latest: spec.Version}, nil
+}
+
+// Walk calls fn for each part in the registry.
+func (fs *Fs) Walk(ctx context.Context, fn WalkFunc) error {
+	return walk(ctx, fs, fn)
+}
+
 // MakeRegistryConfig returns an app registry ref spec.
 func (fs *Fs) MakeRegistryConfig() *app.RegistryConfig {
 	return fs.spec
@@ -160,11 +177,13 @@ func (fs *Fs) ResolveLibrary(partName, partAlias, libRefSpec string, onFile Reso
 			return onDir(libPath)
 		}
 
-		data, err := afero.ReadFile(fs.app.Fs(), path)
+		f, err := fs.app.Fs().Open(path)
 		if err != nil {
 			return err
 		}
-		return onFile(libPath, data)
+		defer f.Close()
+
+		return onFile(libPath, f)
 	})
 
 	if err != nil {
@@ -16,6 +16,7 @@
 package registry
 
 import (
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -217,10 +218,24 @@ func TestFs_ResolveLibrarySpec(t *testing.T) {
 	})
 }
 
+func TestFs_Status(t *testing.T) {
+	withRFS(t, false, func(rfs *Fs, appMock *mocks.App, fs afero.Fs) {
+		status, err := rfs.Status()
+		require.NoError(t, err)
+
+		spec, err := rfs.FetchRegistrySpec()
+		require.NoError(t, err)
+
+		expected := &Status{Version: spec.Version, Latest: spec.Version}
+		assert.Equal(t, expected, status)
+		assert.False(t, status.IsStale())
+	})
+}
+
 func TestFs_ResolveLibrary(t *testing.T) {
 	withRFS(t, false, func(rfs *Fs, appMock *mocks.App, fs afero.Fs) {
 		var files []string
-		onFile := func(relPath string, contents []byte) error {
+		onFile := func(relPath string, r io.Reader) error {
 			files = append(files, relPath)
 			return nil
 		}
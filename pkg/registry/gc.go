@@ -22,7 +22,6 @@ import (
 
 	"github.com/ksonnet/ksonnet/pkg/pkg"
 	"github.com/pkg/errors"
-	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 )
 
@@ -137,14 +136,14 @@ func removeEmptyParents(fs afero.Fs, path string, root string) error {
 
 		empty, err := isDirEmpty(fs, path)
 		if err != nil {
-			return errors.Wrapf(err, path)
+			return errors.Wrap(err, path)
 		}
 		if !empty {
 			break
 		}
 
 		if err := fs.Remove(path); err != nil {
-			return errors.Wrapf(err, path)
+			return errors.Wrap(err, path)
 		}
 
 		path = filepath.Dir(path)
@@ -20,30 +20,42 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"net/http"
 	"net/url"
 	"path"
 	"path/filepath"
 	"strings"
 	"time"
 
+	gogithub "github.com/google/go-github/github"
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/parts"
+	"github.com/ksonnet/ksonnet/pkg/util/event"
 	"github.com/ksonnet/ksonnet/pkg/util/github"
+	utilio "github.com/ksonnet/ksonnet/pkg/util/io"
+	"github.com/ksonnet/ksonnet/pkg/util/trace"
 	"github.com/pkg/errors"
-	log "github.com/sirupsen/logrus"
-	"github.com/spf13/afero"
 )
 
 const (
-	rawGitHubRoot       = "https://raw.githubusercontent.com"
-	defaultGitHubBranch = "master"
+	rawGitHubRoot = "https://raw.githubusercontent.com"
+	// defaultBranchPlaceholder stands for the repository's default branch in
+	// URIs suggested for paths without a branch. ResolveDefaultBranch replaces
+	// it with the branch.
+	defaultBranchPlaceholder = "<default-branch>"
 )
 
 var (
-	// errInvalidURI is an invalid github uri error.
-	errInvalidURI = fmt.Errorf("Invalid GitHub URI: try navigating in GitHub to the URI of the folder containing the 'yaml', and using that URI instead. Generally, this URI should be of the form 'github.com/{organization}/{repository}/tree/{branch}/[path-to-directory]'")
-
 	githubFactory = func(a app.App, spec *app.RegistryConfig, opts ...GitHubOpt) (*GitHub, error) {
+		pc, err := newPartsCache(a)
+		if err != nil {
+			return nil, err
+		}
+		if pc != nil {
+			opts = append(opts, gitHubPartsCache(pc))
+		}
+
 		return NewGitHub(a, spec, opts...)
 	}
 )
@@ -57,16 +69,34 @@ func GitHubClient(c github.GitHub) GitHubOpt {
 	}
 }
 
+// GitHubMaxFileSize is an option for setting the largest file a part can
+// contain. A size of zero disables the limit.
+func GitHubMaxFileSize(size int64) GitHubOpt {
+	return func(gh *GitHub) {
+		gh.maxFileSize = size
+	}
+}
+
+// gitHubPartsCache is an option for setting the cache files in parts are
+// downloaded to.
+func gitHubPartsCache(pc *partsCache) GitHubOpt {
+	return func(gh *GitHub) {
+		gh.partsCache = pc
+	}
+}
+
 // GitHubOpt is an option for configuring GitHub.
 type GitHubOpt func(*GitHub)
 
 // GitHub is a Github Registry
 type GitHub struct {
-	app      app.App
-	name     string
-	hd       *hubDescriptor
-	ghClient github.GitHub
-	spec     *app.RegistryConfig
+	app         app.App
+	name        string
+	hd          *hubDescriptor
+	ghClient    github.GitHub
+	spec        *app.RegistryConfig
+	maxFileSize int64
+	partsCache  *partsCache
 }
 
 // NewGitHub creates an instance of GitHub.
@@ -76,10 +106,11 @@ func NewGitHub(a app.App, registryRef *app.RegistryConfig, opts ...GitHubOpt) (*
 	}
 
 	gh := &GitHub{
-		app:      a,
-		name:     registryRef.Name,
-		spec:     registryRef,
-		ghClient: github.DefaultClient,
+		app:         a,
+		name:        registryRef.Name,
+		spec:        registryRef,
+		ghClient:    github.DefaultClient,
+		maxFileSize: DefaultMaxFileSize,
 	}
 
 	// Apply functional options
@@ -156,6 +187,37 @@ func (gh *GitHub) resolveLatestSHA() (string, error) {
 	return sha, nil
 }
 
+// Status compares the cached registry spec with the latest commit for the
+// registry's refspec.
+func (gh *GitHub) Status() (*Status, error) {
+	log := log.WithField("action", "GitHub.Status")
+
+	status := &Status{RefSpec: gh.hd.refSpec}
+	if status.RefSpec == "" {
+		// HEAD is the repository's default branch.
+		status.RefSpec = "HEAD"
+	}
+
+	registrySpec, _, err := load(gh.app, registrySpecFilePath(gh.app, gh))
+	if err != nil {
+		log.Warnf("error loading cache for %v (%v)", gh.spec.Name, err)
+	} else if registrySpec != nil {
+		status.Version = registrySpec.Version
+	}
+
+	status.Latest, err = gh.resolveLatestSHA()
+	if err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// Walk calls fn for each part in the registry.
+func (gh *GitHub) Walk(ctx context.Context, fn WalkFunc) error {
+	return walk(ctx, gh, fn)
+}
+
 // updateLibVersions updates the libraries in a registry spec to present the provided version.
 func updateLibVersions(spec *Spec, version string) {
 	if spec == nil {
@@ -171,8 +233,34 @@ func updateLibVersions(spec *Spec, version string) {
 // This inventory may have been previously cached on disk. If the cache is not stale,
 // it will be used. Otherwise, the spec is fetched from the remote repository.
 func (gh *GitHub) FetchRegistrySpec() (*Spec, error) {
+	span := trace.Start("registry.fetch", "registry", gh.name, "protocol", string(ProtocolGitHub))
+	start := time.Now()
+	spec, cached, err := gh.fetchRegistrySpec()
+	if err == nil {
+		recordFetch(gh.app, gh.name, start, cached)
+		event.Publish(event.RegistryFetched{
+			Registry: gh.name,
+			Protocol: string(ProtocolGitHub),
+			Cached:   cached,
+			Duration: time.Since(start),
+		})
+	}
+	return spec, span.Finish(err)
+}
+
+// fetchRegistrySpec fetches the registry spec. It returns true if the spec
+// came from the cache.
+func (gh *GitHub) fetchRegistrySpec() (*Spec, bool, error) {
 	log := log.WithField("action", "GitHub.FetchRegistrySpec")
 
+	// Hold the cache lock while checking and refreshing the cache so
+	// concurrent ks processes don't refresh it at the same time.
+	lock, err := lockCache(gh.app, "registries")
+	if err != nil {
+		return nil, false, err
+	}
+	defer lock.Release()
+
 	// Check local disk cache.
 	registrySpecFile := registrySpecFilePath(gh.app, gh)
 
@@ -194,20 +282,20 @@ func (gh *GitHub) FetchRegistrySpec() (*Spec, error) {
 		errMsg := errors.Wrapf(err, "unable to resolve commit for refspec: %v", gh.hd.refSpec)
 		if registrySpec == nil || cachedVersion == "" {
 			// In this case, we failed both the cache and to fetch from remote
-			return nil, errMsg
+			return nil, false, errMsg
 		}
 
 		log.Warnf("%v", errMsg)
 		log.Warnf("falling back to cached version (%v)", cachedVersion)
-		updateLibVersions(registrySpec, gh.hd.refSpec)
-		return registrySpec, nil
+		updateLibVersions(registrySpec, cachedVersion)
+		return registrySpec, true, nil
 	}
 
 	// Check if cache is still current
 	if exists && cachedVersion == sha {
 		log.Debugf("using cache @%v", sha)
 		updateLibVersions(registrySpec, sha)
-		return registrySpec, nil
+		return registrySpec, true, nil
 	}
 
 	if exists {
@@ -224,16 +312,20 @@ func (gh *GitHub) FetchRegistrySpec() (*Spec, error) {
 		RefSpec: sha,
 	}
 
-	registrySpec, err = gh.fetchRemoteSpec(cs)
+	if gh.hd.release {
+		registrySpec, err = gh.fetchReleaseSpec(cs)
+	} else {
+		registrySpec, err = gh.fetchRemoteSpec(cs)
+	}
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 	updateLibVersions(registrySpec, sha)
 
 	var registrySpecBytes []byte
 	registrySpecBytes, err = registrySpec.Marshal()
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	// NOTE: We call mkdir after getting the registry spec, since a
@@ -242,15 +334,15 @@ func (gh *GitHub) FetchRegistrySpec() (*Spec, error) {
 	registrySpecDir := filepath.Join(registryCacheRoot(gh.app), gh.RegistrySpecDir())
 	err = gh.app.Fs().MkdirAll(registrySpecDir, app.DefaultFolderPermissions)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
-	err = afero.WriteFile(gh.app.Fs(), registrySpecFile, registrySpecBytes, app.DefaultFilePermissions)
+	err = utilio.WriteFileAtomic(gh.app.Fs(), registrySpecFile, registrySpecBytes, app.DefaultFilePermissions)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
-	return registrySpec, nil
+	return registrySpec, false, nil
 }
 
 // fetchRemoteSpec fetches a ksonnet registry spec (registry.yaml) from a remote GitHub repository.
@@ -289,6 +381,32 @@ func (gh *GitHub) fetchRemoteSpec(cs github.ContentSpec) (*Spec, error) {
 	return registrySpec, nil
 }
 
+// fetchReleaseSpec fetches a ksonnet registry spec from the registry.yaml asset
+// of the release for the registry's tag. If the release does not have the
+// asset, the spec is fetched from the tagged tree.
+func (gh *GitHub) fetchReleaseSpec(cs github.ContentSpec) (*Spec, error) {
+	log := log.WithField("action", "GitHub.fetchReleaseSpec")
+	ctx := context.Background()
+
+	data, err := gh.ghClient.ReleaseAsset(ctx, cs.Repo, gh.hd.refSpec, registryYAMLFile)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		log.Debugf("release %s has no %s asset; using tagged tree", gh.hd.refSpec, registryYAMLFile)
+		return gh.fetchRemoteSpec(cs)
+	}
+
+	registrySpec, err := Unmarshal(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing %s asset of release %s", registryYAMLFile, gh.hd.refSpec)
+	}
+
+	registrySpec.Version = cs.RefSpec
+
+	return registrySpec, nil
+}
+
 // MakeRegistryConfig returns an app registry ref spec.
 func (gh *GitHub) MakeRegistryConfig() *app.RegistryConfig {
 	return gh.spec
@@ -335,12 +453,12 @@ func (gh *GitHub) ResolveLibrarySpec(partName, libRefSpec string) (*parts.Spec,
 //   relPath: nested/registry/incubator/registry.yaml
 //   chrootedPath: registry.yaml
 func (gh *GitHub) chrootOnFile(onFile ResolveFile) ResolveFile {
-	return func(relPath string, contents []byte) error {
+	return func(relPath string, r io.Reader) error {
 		chrootedPath, err := gh.rebaseToRoot(relPath)
 		if err != nil {
-			return errors.Wrapf(err, "chrooting path %v relative to registry root %v", relPath, gh.URI)
+			return errors.Wrapf(err, "chrooting path %v relative to registry root %v", relPath, gh.URI())
 		}
-		return onFile(chrootedPath, contents)
+		return onFile(chrootedPath, r)
 	}
 }
 
@@ -354,7 +472,7 @@ func (gh *GitHub) chrootOnDir(onDir ResolveDirectory) ResolveDirectory {
 	return func(relPath string) error {
 		chrootedPath, err := gh.rebaseToRoot(relPath)
 		if err != nil {
-			return errors.Wrapf(err, "chrooting path %v relative to registry root %v", relPath, gh.URI)
+			return errors.Wrapf(err, "chrooting path %v relative to registry root %v", relPath, gh.URI())
 		}
 		return onDir(chrootedPath)
 	}
@@ -440,18 +558,7 @@ func (gh *GitHub) resolveDir(libID, path, version string, onFile ResolveFile, on
 	for _, item := range directory {
 		switch item.GetType() {
 		case "file":
-			itemPath := item.GetPath()
-			file, directory, err := gh.ghClient.Contents(ctx, gh.hd.Repo(), itemPath, version)
-			if err != nil {
-				return err
-			} else if directory != nil {
-				return fmt.Errorf("INTERNAL ERROR: GitHub API reported resource %q of type file, but returned type dir", itemPath)
-			}
-			contents, err := file.GetContent()
-			if err != nil {
-				return err
-			}
-			if err := onFile(itemPath, []byte(contents)); err != nil {
+			if err := gh.resolveFile(ctx, item, version, onFile); err != nil {
 				return err
 			}
 		case "dir":
@@ -471,12 +578,105 @@ func (gh *GitHub) resolveDir(libID, path, version string, onFile ResolveFile, on
 	return nil
 }
 
+// resolveFile streams a file in a part to onFile. Files are downloaded from
+// their raw URL so they are never held in memory; files without one fall
+// back to the contents API. Files are verified against the blob SHA GitHub
+// reports for them. If the app uses a parts cache, files are read from it,
+// and downloaded into it when they are missing.
+func (gh *GitHub) resolveFile(ctx context.Context, item *gogithub.RepositoryContent, version string, onFile ResolveFile) error {
+	itemPath := item.GetPath()
+	size := int64(item.GetSize())
+	if err := checkFileSize(itemPath, size, gh.maxFileSize); err != nil {
+		return err
+	}
+
+	sha := item.GetSHA()
+	if gh.partsCache != nil && isBlobSHA(sha) {
+		rc, err := gh.partsCache.open(sha)
+		if err != nil {
+			return err
+		}
+
+		if rc == nil {
+			if err = gh.cacheFile(ctx, item, version); err != nil {
+				return err
+			}
+			if rc, err = gh.partsCache.open(sha); err != nil {
+				return err
+			} else if rc == nil {
+				return errors.Errorf("file %s was not added to the parts cache", itemPath)
+			}
+		} else {
+			log.Debugf("using cached file %s (%s)", itemPath, sha)
+		}
+		defer rc.Close()
+
+		return onFile(itemPath, rc)
+	}
+
+	rc, err := gh.openFile(ctx, item, version)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	return onFile(itemPath, gh.verifiedReader(rc, item))
+}
+
+// cacheFile downloads a file in a part into the parts cache.
+func (gh *GitHub) cacheFile(ctx context.Context, item *gogithub.RepositoryContent, version string) error {
+	rc, err := gh.openFile(ctx, item, version)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	return gh.partsCache.store(item.GetSHA(), gh.verifiedReader(rc, item))
+}
+
+// openFile opens a file in a part for download.
+func (gh *GitHub) openFile(ctx context.Context, item *gogithub.RepositoryContent, version string) (io.ReadCloser, error) {
+	if downloadURL := item.GetDownloadURL(); downloadURL != "" {
+		return gh.ghClient.Download(ctx, downloadURL)
+	}
+
+	itemPath := item.GetPath()
+	file, directory, err := gh.ghClient.Contents(ctx, gh.hd.Repo(), itemPath, version)
+	if err != nil {
+		return nil, err
+	} else if directory != nil {
+		return nil, fmt.Errorf("INTERNAL ERROR: GitHub API reported resource %q of type file, but returned type dir", itemPath)
+	}
+	contents, err := file.GetContent()
+	if err != nil {
+		return nil, err
+	}
+
+	return ioutil.NopCloser(strings.NewReader(contents)), nil
+}
+
+// verifiedReader verifies a file in a part as it is downloaded.
+func (gh *GitHub) verifiedReader(r io.Reader, item *gogithub.RepositoryContent) io.Reader {
+	itemPath := item.GetPath()
+	size := int64(item.GetSize())
+
+	r = newBlobHashReader(r, itemPath, size, item.GetSHA())
+	r = newLimitReader(r, itemPath, gh.maxFileSize)
+	return newProgressReader(r, itemPath, size)
+}
+
 func (gh *GitHub) registrySpecRawURL() string {
+	// HEAD is the repository's default branch.
+	refSpec := gh.hd.refSpec
+	if refSpec == "" {
+		refSpec = "HEAD"
+	}
+
 	return strings.Join([]string{
 		rawGitHubRoot,
 		gh.hd.org,
 		gh.hd.repo,
-		gh.hd.refSpec,
+		refSpec,
 		gh.hd.regSpecRepoPath}, "/")
 }
 
@@ -487,6 +687,9 @@ type hubDescriptor struct {
 	refSpec         string
 	regRepoPath     string
 	regSpecRepoPath string
+	// release is true if the URI points at a release. The registry spec is
+	// read from the release's registry.yaml asset if it has one.
+	release bool
 }
 
 func (hd *hubDescriptor) Repo() github.Repo {
@@ -497,6 +700,7 @@ func (hd *hubDescriptor) Repo() github.Repo {
 func parseGitHubURI(uri string) (hd *hubDescriptor, err error) {
 	// Normalize URI.
 	uri = strings.TrimSpace(uri)
+	input := uri
 	if strings.HasPrefix(uri, "http://github.") || strings.HasPrefix(uri, "https://github.") || strings.HasPrefix(uri, "http://www.github.") || strings.HasPrefix(uri, "https://www.github.") {
 		// Do nothing.
 	} else if strings.HasPrefix(uri, "github.") || strings.HasPrefix(uri, "www.github.") {
@@ -511,12 +715,12 @@ func parseGitHubURI(uri string) (hd *hubDescriptor, err error) {
 	}
 
 	components := strings.Split(parsed.Path, "/")
-	fmt.Printf("DEBUG: path: %s\n", parsed.Path)
+	log.Debugf("path: %s", parsed.Path)
 
 	hd = &hubDescriptor{}
-	fmt.Printf("DEBUG: host: %s\n", parsed.Host)
+	log.Debugf("host: %s", parsed.Host)
 	isEnterprise := !strings.HasSuffix(parsed.Host, "github.com")
-	fmt.Printf("DEBUG: isEnterprise: %t\n", isEnterprise)
+	log.Debugf("isEnterprise: %t", isEnterprise)
 	baseIndex := -1
 	if isEnterprise {
 		for i, n := range components {
@@ -532,7 +736,7 @@ func parseGitHubURI(uri string) (hd *hubDescriptor, err error) {
 		parsed.Scheme + "://" + parsed.Host + strings.Join(components[:baseIndex], "/") + "/")
 
 		queries := parsed.Query()
-		fmt.Printf("DEBUG: queries: %s\n", queries)
+		log.Debugf("queries: %s", queries)
 		switch len(queries) {
 		case 0:
 			hd.refSpec = ""
@@ -546,7 +750,7 @@ func parseGitHubURI(uri string) (hd *hubDescriptor, err error) {
 		default:
 			return nil, errors.Errorf("Only 'ref' query strings allowed in enterprise registry URI:\n%s", uri)
 		}
-		fmt.Printf("DEBUG: hd.refSpec: %s\n", hd.refSpec)
+		log.Debugf("hd.refSpec: %s", hd.refSpec)
 	} else {
 		if len(parsed.Query()) != 0 {
 			return nil, errors.Errorf("No query strings allowed in registry URI:\n%s", uri)
@@ -555,8 +759,8 @@ func parseGitHubURI(uri string) (hd *hubDescriptor, err error) {
 		hd.baseURL = nil
 		baseIndex = 0
 	}
-	fmt.Printf("DEBUG: baseURL: %d\n", hd.baseURL.String())
-	fmt.Printf("DEBUG: baseIndex: %d\n", baseIndex)
+	log.Debugf("baseURL: %v", hd.baseURL)
+	log.Debugf("baseIndex: %d", baseIndex)
 
 	if len(components) < baseIndex+3 {
 		return nil, errors.Errorf("GitHub URI must point at a repository:\n%s", uri)
@@ -565,9 +769,9 @@ func parseGitHubURI(uri string) (hd *hubDescriptor, err error) {
 	// NOTE: The first component is always blank, because the path
 	// begins like: '/whatever'.
 	hd.org = components[baseIndex+1]
-	fmt.Printf("DEBUG: hd.org: %s\n", hd.org)
+	log.Debugf("hd.org: %s", hd.org)
 	hd.repo = components[baseIndex+2]
-	fmt.Printf("DEBUG: hd.repo: %s\n", hd.repo)
+	log.Debugf("hd.repo: %s", hd.repo)
 
 	//
 	// Parse out `regSpecRepoPath`. There are a few cases:
@@ -594,28 +798,37 @@ func parseGitHubURI(uri string) (hd *hubDescriptor, err error) {
 			// sure that `regRepoPath` does not contain a trailing `/`.
 			if components[len-1] == "" {
 				hd.regRepoPath = strings.Join(components[baseIndex+4:len-1], "/")
-				fmt.Printf("DEBUG: hd.regRepoPath: %s\n", hd.regRepoPath)
+				log.Debugf("hd.regRepoPath: %s", hd.regRepoPath)
 				components[len-1] = registryYAMLFile
 			} else {
 				hd.regRepoPath = strings.Join(components[baseIndex+4:], "/")
-				fmt.Printf("DEBUG: hd.regRepoPath: %s\n", hd.regRepoPath)
+				log.Debugf("hd.regRepoPath: %s", hd.regRepoPath)
 				components = append(components, registryYAMLFile)
 			}
 			hd.regSpecRepoPath = strings.Join(components[baseIndex+4:], "/")
-			fmt.Printf("DEBUG: hd.regSpecRepoPath: %s\n", hd.regSpecRepoPath)
+			log.Debugf("hd.regSpecRepoPath: %s", hd.regSpecRepoPath)
 			return
 		} else {
-			// Else, URI should point at repository root.
-			hd.refSpec = defaultGitHubBranch
+			// Else, URI should point at repository root. The refspec is
+			// the 'ref' query, or the repository's default branch if the
+			// query is not set.
 			hd.regRepoPath = ""
 			hd.regSpecRepoPath = registryYAMLFile
 			return
 		}
 	} else {
-		hd.refSpec = components[baseIndex+4]
-		fmt.Printf("DEBUG: hd.refSpec: %s\n", hd.refSpec)
-
 		if len := len(components); len > baseIndex+4 {
+			//
+			// Case where we're pointing at a release, e.g.,
+			// 'github.com/ksonnet/parts/releases/tag/v1.0.0'.
+			//
+			if components[baseIndex+3] == "releases" {
+				return parseReleasePath(input, hd, components[baseIndex+4:])
+			}
+
+			hd.refSpec = components[baseIndex+4]
+			log.Debugf("hd.refSpec: %s", hd.refSpec)
+
 			//
 			// Case where we're pointing at either a directory inside a GitHub
 			// URL, or an 'app.yaml' inside a GitHub URL.
@@ -637,12 +850,24 @@ func parseGitHubURI(uri string) (hd *hubDescriptor, err error) {
 				// Path to the `yaml` (may or may not exist).
 				hd.regSpecRepoPath = strings.Join(components[baseIndex+5:], "/")
 				return
+			} else if components[baseIndex+3] == "blob" {
+				return nil, &InvalidURIError{
+					URI:        input,
+					Kind:       URIBlobDirectory,
+					Reason:     "'blob' URIs must point at a registry.yaml file; use 'tree' to point at the registry's directory",
+					Suggestion: strings.Replace(input, "/blob/", "/tree/", 1),
+				}
 			} else {
-				return nil, errInvalidURI
+				return nil, missingRefError(input, hd)
 			}
+		} else if len == baseIndex+4 && components[baseIndex+3] == "releases" {
+			return parseReleasePath(input, hd, nil)
+		} else if len == baseIndex+4 && components[baseIndex+3] != "" && components[baseIndex+3] != "tree" {
+			return nil, missingRefError(input, hd)
 		} else {
-			// Else, URI should point at repository root.
-			hd.refSpec = defaultGitHubBranch
+			// Else, URI should point at repository root. The refspec is left
+			// empty so the repository's default branch is used.
+			hd.refSpec = ""
 			hd.regRepoPath = ""
 			hd.regSpecRepoPath = registryYAMLFile
 			return
@@ -650,6 +875,66 @@ func parseGitHubURI(uri string) (hd *hubDescriptor, err error) {
 	}
 }
 
+// parseReleasePath parses the path of a release URI after 'releases', i.e.
+// 'tag/{tag}/[path-to-directory]'. The path to the registry's directory in
+// the tagged tree is optional.
+func parseReleasePath(uri string, hd *hubDescriptor, components []string) (*hubDescriptor, error) {
+	// Drop the blank component left by a trailing '/'.
+	if len := len(components); len > 0 && components[len-1] == "" {
+		components = components[:len-1]
+	}
+
+	if len(components) < 2 || components[0] != "tag" || components[1] == "" {
+		return nil, &InvalidURIError{
+			URI:    uri,
+			Kind:   URIMissingRef,
+			Reason: "release URIs must point at a tag, e.g. 'releases/tag/v1.0.0'",
+		}
+	}
+
+	hd.refSpec = components[1]
+	hd.release = true
+	hd.regRepoPath = strings.Join(components[2:], "/")
+	hd.regSpecRepoPath = path.Join(hd.regRepoPath, registryYAMLFile)
+	log.Debugf("hd.refSpec: %s (release)", hd.refSpec)
+
+	return hd, nil
+}
+
+// missingRefError is returned for a URI which points at a path in a
+// repository without 'tree/{branch}'. It suggests the path in the default
+// branch.
+func missingRefError(uri string, hd *hubDescriptor) error {
+	repo := hd.org + "/" + hd.repo + "/"
+	return &InvalidURIError{
+		URI:        uri,
+		Kind:       URIMissingRef,
+		Reason:     "paths in a repository must begin with 'tree/{branch}'",
+		Suggestion: strings.Replace(uri, repo, repo+"tree/"+defaultBranchPlaceholder+"/", 1),
+	}
+}
+
+// ResolveDefaultBranch replaces the default branch placeholder in a suggested
+// GitHub URI with the repository's default branch. URIs without the
+// placeholder are returned as they are.
+func ResolveDefaultBranch(uri string, httpClient *http.Client) (string, error) {
+	if !strings.Contains(uri, defaultBranchPlaceholder) {
+		return uri, nil
+	}
+
+	hd, err := parseGitHubURI(uri)
+	if err != nil {
+		return "", err
+	}
+
+	branch, err := github.NewGitHub(httpClient).DefaultBranch(context.Background(), hd.Repo())
+	if err != nil {
+		return "", errors.Wrapf(err, "resolving default branch of %s", hd.Repo())
+	}
+
+	return strings.Replace(uri, defaultBranchPlaceholder, branch, 1), nil
+}
+
 // Rebase a path to *registry* root (not repo root)
 // Example:
 //  uri:    github.com/ksonnet/parts/tree/master/long/path/incubator
@@ -754,29 +1039,43 @@ func (gh *GitHub) SetURI(uri string) error {
 // ValidateURI implements registry.Validator. A URI is valid if:
 //   * It is a valid URI (RFC 3986)
 //   * It points to GitHub (Enterprise not supported at this time)
-//   * It points to a valid tree in a GitHub repository
+//   * It points to a valid tree or release in a GitHub repository
 //   * That tree contains a `registry.yaml` file
 //   * It currently exists (a HEAD request is sent over the network)
 func (gh *GitHub) ValidateURI(uri string) (bool, error) {
 	if gh == nil {
 		return false, errors.Errorf("nil receiver")
 	}
-	if err := gh.ghClient.ValidateURL(uri); err != nil {
-		return false, errors.Wrap(err, "validating GitHub registry URL")
-	}
 
-	if _, err := parseGitHubURI(uri); err != nil {
+	hd, err := parseGitHubURI(uri)
+	if err != nil {
 		return false, errors.Wrap(err, "parsing GitHub registry URL")
 	}
 
+	// Release pages do not contain registry.yaml, so check the tag exists.
+	if hd.release {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if _, err := gh.ghClient.CommitSHA1(ctx, hd.Repo(), hd.refSpec); err != nil {
+			return false, errors.Wrapf(err, "resolving release tag %s", hd.refSpec)
+		}
+
+		return true, nil
+	}
+
+	if err := gh.ghClient.ValidateURL(uri); err != nil {
+		return false, errors.Wrap(err, "validating GitHub registry URL")
+	}
+
 	return true, nil
 }
 
 func (gh *GitHub) SetBaseURL(baseURL *url.URL) {
 	if baseURL == nil {
-		fmt.Printf("DEBUG!!! setting registry baseURL: DEFAULT\n")
+		log.Debugf("setting registry baseURL: DEFAULT")
 	} else {
-		fmt.Printf("DEBUG!!! setting registry baseURL: %s\n", baseURL.String())
+		log.Debugf("setting registry baseURL: %s", baseURL.String())
 	} 
 	gh.ghClient.SetBaseURL(baseURL)
 }
@@ -16,6 +16,8 @@
 package registry
 
 import (
+	"context"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -44,6 +46,7 @@ func makeGh(t *testing.T, url, sha1 string) (*GitHub, *mocks.GitHub) {
 	appMock.On("LibPath", mock.AnythingOfType("string")).Return(filepath.Join("/app", "lib", "v1.8.7"), nil)
 
 	ghMock := &mocks.GitHub{}
+	ghMock.On("SetBaseURL", mock.Anything)
 	ghMock.On("ValidateURL", mock.Anything).Return(nil)
 	ghMock.On("CommitSHA1", mock.Anything, ghutil.Repo{Org: "ksonnet", Repo: "parts"}, "master").
 		Return(sha1, nil)
@@ -126,6 +129,7 @@ func TestGitHub_invalid_url(t *testing.T) {
 		validateErr := errors.New("invalid URL")
 
 		ghMock := &mocks.GitHub{}
+		ghMock.On("SetBaseURL", mock.Anything)
 		ghMock.On("ValidateURL", mock.Anything).Return(validateErr)
 		ghMock.On("CommitSHA1", mock.Anything, ghutil.Repo{Org: "ksonnet", Repo: "parts"}, "master").
 			Return("12345", nil)
@@ -225,6 +229,68 @@ func TestGithub_FetchRegistrySpec_nocache(t *testing.T) {
 	assert.Equal(t, expected, spec)
 }
 
+func TestGithub_FetchRegistrySpec_release(t *testing.T) {
+	registryData, err := ioutil.ReadFile(filepath.Join("testdata", "registry.yaml"))
+	require.NoError(t, err)
+
+	cases := []struct {
+		name  string
+		asset []byte
+	}{
+		{name: "with registry.yaml asset", asset: registryData},
+		{name: "without registry.yaml asset"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			test.WithApp(t, "/app", func(a *amocks.App, fs afero.Fs) {
+				repo := ghutil.Repo{Org: "ksonnet", Repo: "parts"}
+				u := "github.com/ksonnet/parts/releases/tag/v1.2.3/incubator"
+
+				ghMock := &mocks.GitHub{}
+				ghMock.On("SetBaseURL", mock.Anything)
+				ghMock.On("CommitSHA1", mock.Anything, repo, "v1.2.3").Return("12345", nil)
+				ghMock.On("ReleaseAsset", mock.Anything, repo, "v1.2.3", "registry.yaml").Return(tc.asset, nil)
+				if tc.asset == nil {
+					file := buildContent(t, "registry.yaml")
+					ghMock.On("Contents", mock.Anything, repo, "incubator/registry.yaml", "12345").Return(file, nil, nil)
+				}
+
+				spec := &app.RegistryConfig{
+					Name:     "incubator",
+					Protocol: string(ProtocolGitHub),
+					URI:      u,
+				}
+
+				g, err := NewGitHub(a, spec, GitHubClient(ghMock))
+				require.NoError(t, err)
+
+				ok, err := g.ValidateURI(u)
+				require.NoError(t, err)
+				require.True(t, ok)
+
+				registrySpec, err := g.FetchRegistrySpec()
+				require.NoError(t, err)
+
+				expected := &Spec{
+					APIVersion: DefaultAPIVersion,
+					Kind:       "ksonnet.io/registry",
+					Version:    "12345",
+					Libraries: LibraryConfigs{
+						"apache": &LibraryConfig{
+							Path:    "apache",
+							Version: "12345",
+						},
+					},
+				}
+
+				assert.Equal(t, expected, registrySpec)
+				ghMock.AssertExpectations(t)
+			})
+		})
+	}
+}
+
 func TestGithub_FetchRegistrySpec_invalid_manifest(t *testing.T) {
 	u := "github.com/ksonnet/parts/tree/master/incubator"
 	g, ghMock := makeGh(t, u, "12345")
@@ -309,6 +375,76 @@ func TestGithub_FetchRegistrySpec_cache_stale(t *testing.T) {
 	assert.Equal(t, expected, spec)
 }
 
+func TestGithub_FetchRegistrySpec_offline(t *testing.T) {
+	test.WithApp(t, "/app", func(a *amocks.App, fs afero.Fs) {
+		ghMock := &mocks.GitHub{}
+		ghMock.On("SetBaseURL", mock.Anything)
+		ghMock.On("CommitSHA1", mock.Anything, ghutil.Repo{Org: "ksonnet", Repo: "parts"}, "").
+			Return("", errors.New("offline"))
+
+		spec := &app.RegistryConfig{
+			Name:     "parts",
+			Protocol: string(ProtocolGitHub),
+			URI:      "github.com/ksonnet/parts",
+		}
+
+		g, err := NewGitHub(a, spec, GitHubClient(ghMock))
+		require.NoError(t, err)
+
+		test.StageFile(t, fs, "registry.yaml", registrySpecFilePath(g.app, g))
+
+		registrySpec, err := g.FetchRegistrySpec()
+		require.NoError(t, err)
+
+		// The cached version is kept, since the URI does not name a branch.
+		require.Contains(t, registrySpec.Libraries, "apache")
+		assert.Equal(t, "40285d8a14f1ac5787e405e1023cf0c07f6aa28c", registrySpec.Libraries["apache"].Version)
+	})
+}
+
+func TestGithub_Status(t *testing.T) {
+	remoteSHA := "40285d8a14f1ac5787e405e1023cf0c07f6aa28c"
+
+	cases := []struct {
+		name     string
+		cache    string
+		expected *Status
+		isStale  bool
+	}{
+		{
+			name:     "not cached",
+			expected: &Status{RefSpec: "master", Latest: remoteSHA},
+		},
+		{
+			name:     "cache current",
+			cache:    "registry.yaml",
+			expected: &Status{RefSpec: "master", Version: remoteSHA, Latest: remoteSHA},
+		},
+		{
+			name:     "cache stale",
+			cache:    "stale-registry.yaml",
+			expected: &Status{RefSpec: "master", Version: "some-stale-sha", Latest: remoteSHA},
+			isStale:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			g, _ := makeGh(t, "github.com/ksonnet/parts/tree/master/incubator", remoteSHA)
+
+			if tc.cache != "" {
+				test.StageFile(t, g.app.Fs(), tc.cache, registrySpecFilePath(g.app, g))
+			}
+
+			status, err := g.Status()
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected, status)
+			assert.Equal(t, tc.isStale, status.IsStale())
+		})
+	}
+}
+
 func TestGithub_FetchRegistrySpec_cache_invalid(t *testing.T) {
 	u := "github.com/ksonnet/parts/tree/master/incubator"
 	remoteSHA := "40285d8a14f1ac5787e405e1023cf0c07f6aa28c"
@@ -445,7 +581,7 @@ func TestGithub_ResolveLibrary(t *testing.T) {
 	mockPartFs(t, repo, ghMock, partName, "54321")
 
 	var files []string
-	onFile := func(relPath string, contents []byte) error {
+	onFile := func(relPath string, r io.Reader) error {
 		files = append(files, relPath)
 		return nil
 	}
@@ -514,13 +650,177 @@ func TestGithub_ResolveLibrary(t *testing.T) {
 	assert.Equal(t, expectedDirs, directories)
 }
 
+func TestGithub_resolveFile(t *testing.T) {
+	downloadURL := "https://raw.githubusercontent.com/ksonnet/parts/54321/incubator/apache/parts.yaml"
+
+	cases := []struct {
+		name        string
+		item        *github.RepositoryContent
+		maxFileSize int64
+		expected    string
+		isErr       bool
+	}{
+		{
+			name: "download",
+			item: &github.RepositoryContent{
+				Path:        github.String("incubator/apache/parts.yaml"),
+				Size:        github.Int(8),
+				DownloadURL: github.String(downloadURL),
+			},
+			maxFileSize: DefaultMaxFileSize,
+			expected:    "download",
+		},
+		{
+			name: "download with checksum",
+			item: &github.RepositoryContent{
+				Path:        github.String("incubator/apache/parts.yaml"),
+				Size:        github.Int(8),
+				SHA:         github.String("8a58e1ae8de147c52caaff6f2cc51af88604915b"),
+				DownloadURL: github.String(downloadURL),
+			},
+			maxFileSize: DefaultMaxFileSize,
+			expected:    "download",
+		},
+		{
+			name: "contents without a download URL",
+			item: &github.RepositoryContent{
+				Path: github.String("incubator/apache/parts.yaml"),
+				Size: github.Int(8),
+			},
+			maxFileSize: DefaultMaxFileSize,
+			expected:    "contents",
+		},
+		{
+			name: "reported size over limit",
+			item: &github.RepositoryContent{
+				Path:        github.String("incubator/apache/parts.yaml"),
+				Size:        github.Int(8),
+				DownloadURL: github.String(downloadURL),
+			},
+			maxFileSize: 4,
+			isErr:       true,
+		},
+		{
+			name: "downloaded size over limit",
+			item: &github.RepositoryContent{
+				Path:        github.String("incubator/apache/parts.yaml"),
+				DownloadURL: github.String(downloadURL),
+			},
+			maxFileSize: 4,
+			isErr:       true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			g, ghMock := makeGh(t, "", "12345")
+			g.maxFileSize = tc.maxFileSize
+
+			repo := ghutil.Repo{Org: "ksonnet", Repo: "parts"}
+			content := &github.RepositoryContent{
+				Type:    github.String("file"),
+				Content: github.String("contents"),
+			}
+			ghMock.On("Contents", mock.Anything, repo, "incubator/apache/parts.yaml", "54321").Return(content, nil, nil)
+			ghMock.On("Download", mock.Anything, downloadURL).Return(ioutil.NopCloser(strings.NewReader("download")), nil)
+
+			var got string
+			onFile := func(relPath string, r io.Reader) error {
+				data, err := ioutil.ReadAll(r)
+				if err != nil {
+					return err
+				}
+				got = string(data)
+				return nil
+			}
+
+			err := g.resolveFile(context.Background(), tc.item, "54321", onFile)
+			if tc.isErr {
+				require.Error(t, err)
+				_, ok := err.(*FileTooLargeError)
+				assert.True(t, ok)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestGithub_resolveFile_partsCache(t *testing.T) {
+	downloadURL := "https://raw.githubusercontent.com/ksonnet/parts/54321/incubator/apache/parts.yaml"
+	sha := "8a58e1ae8de147c52caaff6f2cc51af88604915b"
+
+	g, ghMock := makeGh(t, "", "12345")
+	fs := afero.NewMemMapFs()
+	g.partsCache = &partsCache{fs: fs, root: "/cache"}
+
+	ghMock.On("Download", mock.Anything, downloadURL).Return(ioutil.NopCloser(strings.NewReader("download")), nil).Once()
+
+	item := &github.RepositoryContent{
+		Path:        github.String("incubator/apache/parts.yaml"),
+		Size:        github.Int(8),
+		SHA:         github.String(sha),
+		DownloadURL: github.String(downloadURL),
+	}
+
+	for i := 0; i < 2; i++ {
+		var got string
+		onFile := func(relPath string, r io.Reader) error {
+			data, err := ioutil.ReadAll(r)
+			got = string(data)
+			return err
+		}
+
+		err := g.resolveFile(context.Background(), item, "54321", onFile)
+		require.NoError(t, err)
+		assert.Equal(t, "download", got)
+	}
+
+	ghMock.AssertNumberOfCalls(t, "Download", 1)
+	test.AssertExists(t, fs, filepath.Join("/cache", "blobs", "8a", sha))
+}
+
+func TestGithub_resolveFile_partsCache_checksum_mismatch(t *testing.T) {
+	downloadURL := "https://raw.githubusercontent.com/ksonnet/parts/54321/incubator/apache/parts.yaml"
+	sha := "0000000000000000000000000000000000000000"
+
+	g, ghMock := makeGh(t, "", "12345")
+	fs := afero.NewMemMapFs()
+	g.partsCache = &partsCache{fs: fs, root: "/cache"}
+
+	ghMock.On("Download", mock.Anything, downloadURL).Return(ioutil.NopCloser(strings.NewReader("download")), nil)
+
+	item := &github.RepositoryContent{
+		Path:        github.String("incubator/apache/parts.yaml"),
+		Size:        github.Int(8),
+		SHA:         github.String(sha),
+		DownloadURL: github.String(downloadURL),
+	}
+
+	onFile := func(relPath string, r io.Reader) error {
+		return errors.New("unexpected call")
+	}
+
+	err := g.resolveFile(context.Background(), item, "54321", onFile)
+	require.Error(t, err)
+	_, ok := errors.Cause(err).(*ChecksumMismatchError)
+	assert.True(t, ok)
+
+	test.AssertNotExists(t, fs, filepath.Join("/cache", "blobs", "00", sha))
+}
+
 func Test_parseGitHubURI(t *testing.T) {
 	tests := []struct {
 		// Specification to parse.
 		uri string
 
 		// Optional error to check.
-		targetErr error
+		targetErrKind URIErrorKind
+
+		// Whether the URI points at a release.
+		targetRelease bool
 
 		// Optional results to verify.
 		targetOrg                  string
@@ -534,11 +834,12 @@ func Test_parseGitHubURI(t *testing.T) {
 		// test path parsing.
 		//
 		{
+			// The refspec is left empty to use the default branch.
 			uri: "github.com/exampleOrg1/exampleRepo1",
 
 			targetOrg:                  "exampleOrg1",
 			targetRepo:                 "exampleRepo1",
-			targetRefSpec:              "master",
+			targetRefSpec:              "",
 			targetRegistryRepoPath:     "",
 			targetRegistrySpecRepoPath: "registry.yaml",
 		},
@@ -562,8 +863,8 @@ func Test_parseGitHubURI(t *testing.T) {
 		},
 		{
 			// Fails because `blob` refers to a file, but this refers to a directory.
-			uri:       "github.com/exampleOrg4/exampleRepo4/blob/master",
-			targetErr: errInvalidURI,
+			uri:           "github.com/exampleOrg4/exampleRepo4/blob/master",
+			targetErrKind: URIBlobDirectory,
 		},
 		{
 			uri: "github.com/exampleOrg4/exampleRepo4/tree/exampleBranch2",
@@ -581,8 +882,8 @@ func Test_parseGitHubURI(t *testing.T) {
 		{
 			// Fails because referring to a directory requires a URI with
 			// `tree/{branchName}` prepending the path.
-			uri:       "github.com/exampleOrg6/exampleRepo6/path/to/some/registry",
-			targetErr: errInvalidURI,
+			uri:           "github.com/exampleOrg6/exampleRepo6/path/to/some/registry",
+			targetErrKind: URIMissingRef,
 		},
 		{
 			uri: "github.com/exampleOrg5/exampleRepo5/tree/master/path/to/some/registry",
@@ -604,13 +905,56 @@ func Test_parseGitHubURI(t *testing.T) {
 		},
 		{
 			// Fails because `blob` refers to a file, but this refers to a directory.
-			uri:       "github.com/exampleOrg7/exampleRepo7/blob/master",
-			targetErr: errInvalidURI,
+			uri:           "github.com/exampleOrg7/exampleRepo7/blob/master",
+			targetErrKind: URIBlobDirectory,
 		},
 		{
 			// Fails because `blob` refers to a file, but this refers to a directory.
-			uri:       "github.com/exampleOrg5/exampleRepo5/blob/exampleBranch2",
-			targetErr: errInvalidURI,
+			uri:           "github.com/exampleOrg5/exampleRepo5/blob/exampleBranch2",
+			targetErrKind: URIBlobDirectory,
+		},
+
+		//
+		// Parsing URIs with tags and releases.
+		//
+		{
+			uri: "github.com/exampleOrg8/exampleRepo8/tree/v1.2.3/incubator",
+
+			targetOrg:                  "exampleOrg8",
+			targetRepo:                 "exampleRepo8",
+			targetRefSpec:              "v1.2.3",
+			targetRegistryRepoPath:     "incubator",
+			targetRegistrySpecRepoPath: "incubator/registry.yaml",
+		},
+		{
+			uri: "github.com/exampleOrg8/exampleRepo8/releases/tag/v1.2.3",
+
+			targetOrg:                  "exampleOrg8",
+			targetRepo:                 "exampleRepo8",
+			targetRefSpec:              "v1.2.3",
+			targetRegistryRepoPath:     "",
+			targetRegistrySpecRepoPath: "registry.yaml",
+			targetRelease:              true,
+		},
+		{
+			uri: "github.com/exampleOrg8/exampleRepo8/releases/tag/v1.2.3/path/to/incubator",
+
+			targetOrg:                  "exampleOrg8",
+			targetRepo:                 "exampleRepo8",
+			targetRefSpec:              "v1.2.3",
+			targetRegistryRepoPath:     "path/to/incubator",
+			targetRegistrySpecRepoPath: "path/to/incubator/registry.yaml",
+			targetRelease:              true,
+		},
+		{
+			// Fails because a release URI must name a tag.
+			uri:           "github.com/exampleOrg8/exampleRepo8/releases",
+			targetErrKind: URIMissingRef,
+		},
+		{
+			// Fails because a release URI must name a tag.
+			uri:           "github.com/exampleOrg8/exampleRepo8/releases/latest",
+			targetErrKind: URIMissingRef,
 		},
 	}
 
@@ -624,8 +968,9 @@ func Test_parseGitHubURI(t *testing.T) {
 
 				t.Run(uri, func(t *testing.T) {
 					hd, err := parseGitHubURI(uri)
-					if test.targetErr != nil {
-						require.Equal(t, test.targetErr, err)
+					if test.targetErrKind != "" {
+						require.IsType(t, &InvalidURIError{}, err)
+						require.Equal(t, test.targetErrKind, err.(*InvalidURIError).Kind)
 						return
 					}
 
@@ -636,6 +981,7 @@ func Test_parseGitHubURI(t *testing.T) {
 					assert.Equal(t, test.targetRefSpec, hd.refSpec)
 					assert.Equal(t, test.targetRegistryRepoPath, hd.regRepoPath)
 					assert.Equal(t, test.targetRegistrySpecRepoPath, hd.regSpecRepoPath)
+					assert.Equal(t, test.targetRelease, hd.release)
 				})
 			}
 		}
@@ -703,6 +1049,7 @@ func Test_githubFactory(t *testing.T) {
 		URI:  "github.com/foo/bar",
 	}
 	ghMock := &mocks.GitHub{}
+	ghMock.On("SetBaseURL", mock.Anything)
 	optGh := GitHubClient(ghMock)
 	gh, err := githubFactory(nil, regCfg, optGh)
 	require.NoError(t, err, "github constructor")
@@ -16,18 +16,22 @@
 package registry
 
 import (
+	"context"
 	"bytes"
 	"io/ioutil"
 	"net/url"
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/helm"
 	"github.com/ksonnet/ksonnet/pkg/parts"
 	"github.com/ksonnet/ksonnet/pkg/util/archive"
+	"github.com/ksonnet/ksonnet/pkg/util/event"
 	ksstrings "github.com/ksonnet/ksonnet/pkg/util/strings"
+	"github.com/ksonnet/ksonnet/pkg/util/trace"
 	"github.com/pkg/errors"
 )
 
@@ -83,6 +87,22 @@ func (h *Helm) RegistrySpecFilePath() string {
 // FetchRegistrySpec fetches the registry spec. This method returns an unmarshalled version
 // of registry.yaml
 func (h *Helm) FetchRegistrySpec() (*Spec, error) {
+	span := trace.Start("registry.fetch", "registry", h.Name(), "protocol", string(ProtocolHelm))
+	start := time.Now()
+	spec, err := h.fetchRegistrySpec()
+	if err == nil {
+		// Helm registries aren't cached, so every fetch is a miss.
+		recordFetch(h.app, h.Name(), start, false)
+		event.Publish(event.RegistryFetched{
+			Registry: h.Name(),
+			Protocol: string(ProtocolHelm),
+			Duration: time.Since(start),
+		})
+	}
+	return spec, span.Finish(err)
+}
+
+func (h *Helm) fetchRegistrySpec() (*Spec, error) {
 	spec := &Spec{
 		APIVersion: DefaultAPIVersion,
 		Kind:       DefaultKind,
@@ -109,6 +129,21 @@ func (h *Helm) FetchRegistrySpec() (*Spec, error) {
 	return spec, nil
 }
 
+// Status checks the repository can be reached. Helm registries aren't
+// cached, so they are always current.
+func (h *Helm) Status() (*Status, error) {
+	if _, err := h.repositoryClient.Repository(); err != nil {
+		return nil, errors.Wrap(err, "retrieving repository")
+	}
+
+	return &Status{}, nil
+}
+
+// Walk calls fn for each part in the registry.
+func (h *Helm) Walk(ctx context.Context, fn WalkFunc) error {
+	return walk(ctx, h, fn)
+}
+
 // MakeRegistryConfig returns app registry ref spec.
 func (h *Helm) MakeRegistryConfig() *app.RegistryConfig {
 	return h.spec
@@ -170,7 +205,7 @@ func (h *Helm) ResolveLibrary(partName string, partAlias string, version string,
 				return nil
 			}
 
-			return onFile(name, b)
+			return onFile(name, bytes.NewReader(b))
 		}
 
 		if err = h.unarchiver.Unarchive(r, handler); err != nil {
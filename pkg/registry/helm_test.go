@@ -255,8 +255,10 @@ func TestHelm_ResolveLibrary(t *testing.T) {
 		require.NoError(t, err)
 
 		var foundFile bool
-		fileHandler := func(relPath string, contents []byte) error {
+		fileHandler := func(relPath string, r io.Reader) error {
 			if relPath == "app-a/helm/0.1.0/part/README.md" {
+				contents, err := ioutil.ReadAll(r)
+				require.NoError(t, err)
 				assert.Equal(t, "hello world", string(contents))
 				foundFile = true
 			}
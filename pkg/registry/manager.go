@@ -20,28 +20,13 @@ import (
 	"path/filepath"
 
 	"github.com/ksonnet/ksonnet/pkg/app"
-	"github.com/ksonnet/ksonnet/pkg/helm"
-	"github.com/ksonnet/ksonnet/pkg/util/github"
+	utilio "github.com/ksonnet/ksonnet/pkg/util/io"
 	"github.com/pkg/errors"
 )
 
 // Locate locates a registry given a spec.
 func Locate(a app.App, spec *app.RegistryConfig, httpClient *http.Client) (Registry, error) {
-	switch Protocol(spec.Protocol) {
-	case ProtocolGitHub:
-		var ghc = github.NewGitHub(httpClient)
-		return githubFactory(a, spec, GitHubClient(ghc))
-	case ProtocolFilesystem:
-		return NewFs(a, spec)
-	case ProtocolHelm:
-		client, err := helm.NewHTTPClient(spec.URI, httpClient)
-		if err != nil {
-			return nil, err
-		}
-		return NewHelm(a, spec, helm.NewCachingClient(client), nil)
-	default:
-		return nil, errors.Errorf("invalid registry protocol %q", spec.Protocol)
-	}
+	return newRegistry(a, spec, httpClient)
 }
 
 // registryCacheRoot returns the root path for registry caches
@@ -50,6 +35,18 @@ func registryCacheRoot(a app.App) string {
 	return filepath.Join(a.Root(), ".ksonnet", "registries")
 }
 
+// lockCache acquires the lock guarding a cache which is shared by ks
+// processes, e.g. the registry cache. Callers must release the lock.
+func lockCache(a app.App, name string) (*utilio.Lock, error) {
+	path := filepath.Join(a.Root(), ".ksonnet", "locks", name)
+	lock, err := utilio.AcquireLock(a.Fs(), path, utilio.DefaultLockTimeout)
+	if err != nil {
+		return nil, errors.Wrapf(err, "locking %s cache", name)
+	}
+
+	return lock, nil
+}
+
 // registrySpecFilePath returns the path for provided registry object's cached spec file
 func registrySpecFilePath(a app.App, r Registry) string {
 	path := r.RegistrySpecFilePath()
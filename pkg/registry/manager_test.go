@@ -30,6 +30,7 @@ import (
 func Test_List(t *testing.T) {
 	withApp(t, func(a *mocks.App, fs afero.Fs) {
 		c := &ghmocks.GitHub{}
+		c.On("SetBaseURL", mock.Anything)
 		c.On("ValidateURL", mock.Anything).Return(nil)
 		c.On("CommitSHA1", mock.Anything, github.Repo{Org: "ksonnet", Repo: "parts"}, mock.AnythingOfType("string")).
 			Return("12345", nil)
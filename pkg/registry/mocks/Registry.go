@@ -17,6 +17,7 @@
 package mocks
 
 import app "github.com/ksonnet/ksonnet/pkg/app"
+import context "context"
 import mock "github.com/stretchr/testify/mock"
 import parts "github.com/ksonnet/ksonnet/pkg/parts"
 import registry "github.com/ksonnet/ksonnet/pkg/registry"
@@ -225,6 +226,29 @@ func (_m *Registry) SetURI(uri string) error {
 	return r0
 }
 
+// Status provides a mock function with given fields:
+func (_m *Registry) Status() (*registry.Status, error) {
+	ret := _m.Called()
+
+	var r0 *registry.Status
+	if rf, ok := ret.Get(0).(func() *registry.Status); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*registry.Status)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // URI provides a mock function with given fields:
 func (_m *Registry) URI() string {
 	ret := _m.Called()
@@ -259,3 +283,17 @@ func (_m *Registry) ValidateURI(uri string) (bool, error) {
 
 	return r0, r1
 }
+
+// Walk provides a mock function with given fields: ctx, fn
+func (_m *Registry) Walk(ctx context.Context, fn registry.WalkFunc) error {
+	ret := _m.Called(ctx, fn)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, registry.WalkFunc) error); ok {
+		r0 = rf(ctx, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
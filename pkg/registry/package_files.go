@@ -0,0 +1,91 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package registry
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/pkg"
+	"github.com/pkg/errors"
+)
+
+// ResolvePackageFiles fetches the files of a package from its registry,
+// without installing the package. Files are keyed by their slash separated
+// path relative to the package root. It returns the files, and the version of
+// the package they were fetched from.
+func ResolvePackageFiles(a app.App, d pkg.Descriptor, httpClient *http.Client) (map[string][]byte, string, error) {
+	if a == nil {
+		return nil, "", errors.New("nil app")
+	}
+
+	r, err := resolveRegistry(a, d.Registry, httpClient)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return resolvePackageFiles(r, d)
+}
+
+func resolvePackageFiles(r Registry, d pkg.Descriptor) (map[string][]byte, string, error) {
+	libSpec, err := r.ResolveLibrarySpec(d.Name, d.Version)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "resolving package metadata: %v", d)
+	}
+
+	files := make(map[string][]byte)
+	_, libRef, err := r.ResolveLibrary(
+		d.Name,
+		d.Name,
+		d.Version,
+		func(relPath string, r io.Reader) error {
+			contents, err := ioutil.ReadAll(r)
+			if err != nil {
+				return errors.Wrapf(err, "reading %s", relPath)
+			}
+
+			files[packageRelPath(d.Name, relPath)] = contents
+			return nil
+		},
+		func(relPath string) error {
+			return nil
+		})
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "resolving package %v", d)
+	}
+
+	// Registries which are not versioned by commit use the package's version.
+	version := libSpec.Version
+	if libRef != nil && libRef.Version != "" {
+		version = libRef.Version
+	}
+
+	return files, version, nil
+}
+
+// packageRelPath converts a path relative to the registry root, like
+// `redis/prototypes/redis.jsonnet`, to a path relative to the package root.
+func packageRelPath(name, relPath string) string {
+	relPath = strings.Replace(relPath, "\\", "/", -1)
+	if i := strings.Index(relPath, name+"/"); i == 0 || (i > 0 && relPath[i-1] == '/') {
+		return relPath[i+len(name)+1:]
+	}
+
+	return relPath
+}
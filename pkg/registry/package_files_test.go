@@ -0,0 +1,75 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package registry
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/pkg"
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolvePackageFiles(t *testing.T) {
+	withApp(t, func(a *amocks.App, fs afero.Fs) {
+		test.StageDir(t, fs, "incubator", filepath.Join("/work", "incubator"))
+
+		registries := app.RegistryConfigs{
+			"incubator": &app.RegistryConfig{
+				Name:     "incubator",
+				Protocol: string(ProtocolFilesystem),
+				URI:      "/work/incubator",
+			},
+		}
+		a.On("Registries").Return(registries, nil)
+
+		d := pkg.Descriptor{Registry: "incubator", Name: "apache"}
+		files, _, err := ResolvePackageFiles(a, d, nil)
+		require.NoError(t, err)
+
+		require.Contains(t, files, "parts.yaml")
+		require.Contains(t, files, "prototypes/apache-simple.jsonnet")
+
+		prototypes, err := PackagePrototypes(files)
+		require.NoError(t, err)
+		require.Len(t, prototypes, 1)
+		assert.Equal(t, "io.ksonnet.pkg.apache-simple", prototypes[0].Name)
+	})
+}
+
+func Test_packageRelPath(t *testing.T) {
+	cases := []struct {
+		relPath  string
+		expected string
+	}{
+		{relPath: "redis/parts.yaml", expected: "parts.yaml"},
+		{relPath: "incubator/redis/prototypes/redis.jsonnet", expected: "prototypes/redis.jsonnet"},
+		{relPath: `redis\prototypes\redis.jsonnet`, expected: "prototypes/redis.jsonnet"},
+		{relPath: "other/parts.yaml", expected: "other/parts.yaml"},
+		{relPath: "myredis/parts.yaml", expected: "myredis/parts.yaml"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.relPath, func(t *testing.T) {
+			assert.Equal(t, tc.expected, packageRelPath("redis", tc.relPath))
+		})
+	}
+}
@@ -415,16 +415,19 @@ func (m *packageManager) loadPackage(protocol Protocol, pkgName, registryName, v
 			return nil, errors.Wrap(err, "loading helm package")
 		}
 		return h, nil
-	case ProtocolFilesystem, ProtocolGitHub:
+	default:
+		if _, ok := lookupProtocol(protocol); !ok {
+			return nil, errors.Errorf("package %q - registry uses unknown protocol: %q",
+				fmt.Sprintf("%s/%s", registryName, pkgName), protocol)
+		}
+
+		// Packages from GitHub, filesystem, and custom registries are vendored as-is.
 		l, err := pkg.NewLocal(m.app, pkgName, registryName, version, installChecker)
 		if err != nil {
 			return nil, errors.Wrapf(err, "loading %q package", protocol)
 		}
 
 		return l, nil
-	default:
-		return nil, errors.Errorf("package %q - registry uses unknown protocol: %q",
-			fmt.Sprintf("%s/%s", registryName, pkgName), protocol)
 	}
 }
 
@@ -647,13 +650,15 @@ func (m *packageManager) VendorPath(d pkg.Descriptor) (string, error) {
 			return "", errors.Errorf("could not resolve path for descriptor: %v", d)
 		}
 		return path, nil
-	case ProtocolFilesystem, ProtocolGitHub:
+	default:
+		if _, ok := lookupProtocol(protocol); !ok {
+			return "", errors.Errorf("package %q - registry uses unknown protocol: %q", d, protocol)
+		}
+
 		path := pkg.LocalVendorPath(m.app, d)
 		if path == "" {
 			return "", errors.Errorf("could not resolve path for descriptor: %v", d)
 		}
 		return path, nil
-	default:
-		return "", errors.Errorf("package %q - registry uses unknown protocol: %q", d, protocol)
 	}
 }
@@ -0,0 +1,117 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package registry
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+// partsCache is a content addressed store of package files. It lives outside
+// of apps, so apps, and CI jobs which restore it, share downloads. Files are
+// stored by their git blob SHA, so a file is stored once no matter how many
+// versions or apps use it.
+type partsCache struct {
+	fs   afero.Fs
+	root string
+}
+
+// newPartsCache creates the parts cache configured for an app. It returns nil
+// if the app doesn't use one.
+func newPartsCache(a app.App) (*partsCache, error) {
+	if a == nil {
+		return nil, nil
+	}
+
+	dir, err := a.PartsCache()
+	if err != nil {
+		return nil, errors.Wrap(err, "finding parts cache")
+	}
+	if dir == "" {
+		return nil, nil
+	}
+
+	return &partsCache{fs: a.Fs(), root: dir}, nil
+}
+
+func (pc *partsCache) path(sha string) string {
+	return filepath.Join(pc.root, "blobs", sha[:2], sha)
+}
+
+// open opens a cached file. It returns nil if the file is not cached.
+func (pc *partsCache) open(sha string) (io.ReadCloser, error) {
+	f, err := pc.fs.Open(pc.path(sha))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "opening cached file %s", sha)
+	}
+
+	return f, nil
+}
+
+// store adds the contents of r to the cache. The file is only added once r
+// has been read to the end without an error, so readers which verify what
+// they read keep corrupt downloads out of the cache.
+func (pc *partsCache) store(sha string, r io.Reader) error {
+	path := pc.path(sha)
+	if err := pc.fs.MkdirAll(filepath.Dir(path), app.DefaultFolderPermissions); err != nil {
+		return errors.Wrap(err, "creating parts cache")
+	}
+
+	f, err := afero.TempFile(pc.fs, filepath.Dir(path), sha+".tmp")
+	if err != nil {
+		return errors.Wrap(err, "creating cached file")
+	}
+
+	_, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		pc.fs.Remove(f.Name())
+		return err
+	}
+
+	// Renaming is atomic, so concurrent ks processes never see partial files.
+	if err = pc.fs.Rename(f.Name(), path); err != nil {
+		pc.fs.Remove(f.Name())
+		return errors.Wrap(err, "storing cached file")
+	}
+
+	return nil
+}
+
+// isBlobSHA returns true if sha is a hex encoded git blob SHA.
+func isBlobSHA(sha string) bool {
+	if len(sha) != 40 {
+		return false
+	}
+
+	for _, c := range sha {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,92 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package registry
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newPartsCache(t *testing.T) {
+	withApp(t, func(a *amocks.App, fs afero.Fs) {
+		a.On("PartsCache").Return("", nil).Once()
+		pc, err := newPartsCache(a)
+		require.NoError(t, err)
+		assert.Nil(t, pc)
+
+		a.On("PartsCache").Return("/cache", nil).Once()
+		pc, err = newPartsCache(a)
+		require.NoError(t, err)
+		require.NotNil(t, pc)
+		assert.Equal(t, "/cache", pc.root)
+
+		a.On("PartsCache").Return("", errors.New("fail")).Once()
+		_, err = newPartsCache(a)
+		require.Error(t, err)
+	})
+}
+
+func Test_partsCache(t *testing.T) {
+	sha := "8a58e1ae8de147c52caaff6f2cc51af88604915b"
+	pc := &partsCache{fs: afero.NewMemMapFs(), root: "/cache"}
+
+	rc, err := pc.open(sha)
+	require.NoError(t, err)
+	assert.Nil(t, rc)
+
+	err = pc.store(sha, &failingReader{})
+	require.Error(t, err)
+
+	rc, err = pc.open(sha)
+	require.NoError(t, err)
+	assert.Nil(t, rc)
+
+	err = pc.store(sha, strings.NewReader("download"))
+	require.NoError(t, err)
+
+	rc, err = pc.open(sha)
+	require.NoError(t, err)
+	require.NotNil(t, rc)
+	defer rc.Close()
+
+	data, err := ioutil.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Equal(t, "download", string(data))
+
+	files, err := afero.ReadDir(pc.fs, "/cache/blobs/8a")
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func Test_isBlobSHA(t *testing.T) {
+	assert.True(t, isBlobSHA("8a58e1ae8de147c52caaff6f2cc51af88604915b"))
+	assert.False(t, isBlobSHA(""))
+	assert.False(t, isBlobSHA("8a58e1ae"))
+	assert.False(t, isBlobSHA("../../../../../../../../../../etc/passwd"))
+}
+
+type failingReader struct{}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("fail")
+}
@@ -0,0 +1,148 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package registry
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/helm"
+	"github.com/ksonnet/ksonnet/pkg/util/github"
+	"github.com/pkg/errors"
+)
+
+// Factory creates a registry from its configuration.
+type Factory func(a app.App, spec *app.RegistryConfig, httpClient *http.Client) (Registry, error)
+
+var (
+	protocolsMu sync.RWMutex
+	protocols   = map[Protocol]Factory{}
+)
+
+func init() {
+	RegisterProtocol(ProtocolGitHub, func(a app.App, spec *app.RegistryConfig, httpClient *http.Client) (Registry, error) {
+		ghc := github.NewGitHub(httpClient)
+		gh, err := githubFactory(a, spec, GitHubClient(ghc))
+		if err != nil {
+			return nil, err
+		}
+		return gh, nil
+	})
+
+	RegisterProtocol(ProtocolFilesystem, func(a app.App, spec *app.RegistryConfig, httpClient *http.Client) (Registry, error) {
+		fs, err := NewFs(a, spec)
+		if err != nil {
+			return nil, err
+		}
+		return fs, nil
+	})
+
+	RegisterProtocol(ProtocolHelm, func(a app.App, spec *app.RegistryConfig, httpClient *http.Client) (Registry, error) {
+		hc, err := helm.NewHTTPClient(spec.URI, httpClient)
+		if err != nil {
+			return nil, errors.Wrap(err, "initializing helm HTTP client")
+		}
+		h, err := helmFactory(a, spec, helm.NewCachingClient(hc))
+		if err != nil {
+			return nil, err
+		}
+		return h, nil
+	})
+}
+
+// RegisterProtocol makes a registry protocol available. Registries using
+// the protocol are created with factory. Packages from registries with
+// custom protocols are vendored like GitHub and filesystem packages.
+//
+// A registry URI whose scheme is the protocol's name, e.g.
+// "artifacts://host/path" for a protocol named "artifacts", is detected as
+// using the protocol. RegisterProtocol panics if the protocol is already
+// registered or factory is nil.
+func RegisterProtocol(name Protocol, factory Factory) {
+	protocolsMu.Lock()
+	defer protocolsMu.Unlock()
+
+	if factory == nil {
+		panic("registry: RegisterProtocol factory is nil")
+	}
+	if name == "" || name == ProtocolInvalid {
+		panic("registry: RegisterProtocol invalid protocol " + string(name))
+	}
+	if _, dup := protocols[name]; dup {
+		panic("registry: RegisterProtocol called twice for protocol " + string(name))
+	}
+
+	protocols[name] = factory
+}
+
+// Protocols returns the names of the registered protocols.
+func Protocols() []Protocol {
+	protocolsMu.RLock()
+	defer protocolsMu.RUnlock()
+
+	var names []Protocol
+	for name := range protocols {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return names[i] < names[j]
+	})
+
+	return names
+}
+
+// lookupProtocol returns the factory for a protocol.
+func lookupProtocol(name Protocol) (Factory, bool) {
+	protocolsMu.RLock()
+	defer protocolsMu.RUnlock()
+
+	factory, ok := protocols[name]
+	return factory, ok
+}
+
+// newRegistry creates a registry using its protocol's factory.
+func newRegistry(a app.App, spec *app.RegistryConfig, httpClient *http.Client) (Registry, error) {
+	factory, ok := lookupProtocol(Protocol(spec.Protocol))
+	if !ok {
+		return nil, errors.Errorf("invalid registry protocol %q", spec.Protocol)
+	}
+
+	return factory(a, spec, withStats(a, spec.Name, httpClient))
+}
+
+// detectCustomProtocol detects registry URIs whose scheme is the name of a
+// protocol registered with RegisterProtocol.
+func detectCustomProtocol(uri string) (Protocol, bool) {
+	i := strings.Index(uri, "://")
+	if i <= 0 {
+		return ProtocolInvalid, false
+	}
+
+	name := Protocol(uri[:i])
+	switch name {
+	case ProtocolFilesystem, ProtocolGitHub, ProtocolHelm:
+		return ProtocolInvalid, false
+	}
+
+	if _, ok := lookupProtocol(name); !ok {
+		return ProtocolInvalid, false
+	}
+
+	return name, true
+}
@@ -0,0 +1,110 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package registry
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withProtocol registers a protocol for the duration of a test.
+func withProtocol(t *testing.T, name Protocol, factory Factory, fn func()) {
+	RegisterProtocol(name, factory)
+	defer func() {
+		protocolsMu.Lock()
+		delete(protocols, name)
+		protocolsMu.Unlock()
+	}()
+
+	fn()
+}
+
+func TestRegisterProtocol(t *testing.T) {
+	var located *app.RegistryConfig
+	factory := func(a app.App, spec *app.RegistryConfig, httpClient *http.Client) (Registry, error) {
+		located = spec
+		return nil, errors.New("not implemented")
+	}
+
+	withProtocol(t, "artifacts", factory, func() {
+		assert.Equal(t, []Protocol{"artifacts", ProtocolFilesystem, ProtocolGitHub, ProtocolHelm}, Protocols())
+
+		spec := &app.RegistryConfig{Name: "internal", Protocol: "artifacts", URI: "artifacts://store/registry"}
+		_, err := Locate(nil, spec, nil)
+		require.Error(t, err)
+		assert.Equal(t, spec, located)
+
+		protocol, uri, err := DetectProtocol("artifacts://store/registry")
+		require.NoError(t, err)
+		assert.Equal(t, Protocol("artifacts"), protocol)
+		assert.Equal(t, "artifacts://store/registry", uri)
+
+		assert.Panics(t, func() {
+			RegisterProtocol("artifacts", factory)
+		})
+	})
+
+	_, _, err := DetectProtocol("artifacts://store/registry")
+	require.Error(t, err)
+}
+
+func TestRegisterProtocol_invalid(t *testing.T) {
+	assert.Panics(t, func() {
+		RegisterProtocol("artifacts", nil)
+	})
+
+	assert.Panics(t, func() {
+		RegisterProtocol(ProtocolInvalid, func(app.App, *app.RegistryConfig, *http.Client) (Registry, error) {
+			return nil, nil
+		})
+	})
+}
+
+func TestLocate_unknown_protocol(t *testing.T) {
+	spec := &app.RegistryConfig{Name: "internal", Protocol: "artifacts", URI: "artifacts://store/registry"}
+	_, err := Locate(nil, spec, nil)
+	require.Error(t, err)
+}
+
+func Test_packageManager_custom_protocol(t *testing.T) {
+	withApp(t, func(a *amocks.App, fs afero.Fs) {
+		factory := func(a app.App, spec *app.RegistryConfig, httpClient *http.Client) (Registry, error) {
+			return nil, errors.New("not implemented")
+		}
+
+		a.On("VendorPath").Return("/app/vendor")
+		test.StageFile(t, fs, "apache-part.yaml", "/app/vendor/internal/apache/parts.yaml")
+
+		withProtocol(t, "artifacts", factory, func() {
+			pm := packageManager{app: a}
+
+			_, err := pm.loadPackage("artifacts", "apache", "internal", "", nil)
+			require.NoError(t, err)
+		})
+
+		pm := packageManager{app: a}
+		_, err := pm.loadPackage("artifacts", "apache", "internal", "", nil)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,96 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package registry
+
+import (
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/pkg"
+	"github.com/ksonnet/ksonnet/pkg/prototype"
+	"github.com/pkg/errors"
+)
+
+// ResolvePrototype fetches a prototype from a package in a registry, without
+// installing the package. The prototype is found by its name, or the suffix
+// of its name. It returns the prototype, and the version of the package it
+// was fetched from.
+func ResolvePrototype(a app.App, d pkg.Descriptor, name string, httpClient *http.Client) (*prototype.Prototype, string, error) {
+	if a == nil {
+		return nil, "", errors.New("nil app")
+	}
+
+	r, err := resolveRegistry(a, d.Registry, httpClient)
+	if err != nil {
+		return nil, "", err
+	}
+
+	files, version, err := resolvePackageFiles(r, d)
+	if err != nil {
+		return nil, "", err
+	}
+
+	prototypes, err := PackagePrototypes(files)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var matches prototype.Prototypes
+	for _, p := range prototypes {
+		if p.Name == name || strings.HasSuffix(p.Name, "."+name) {
+			p.Version = version
+			matches = append(matches, p)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, "", errors.Errorf("package %v does not have a prototype %q", d, name)
+	case 1:
+		return matches[0], version, nil
+	default:
+		var names []string
+		for _, p := range matches {
+			names = append(names, p.Name)
+		}
+		return nil, "", errors.Errorf("prototype %q is ambiguous in package %v; it matches %s", name, d, strings.Join(names, ", "))
+	}
+}
+
+// PackagePrototypes parses the prototypes found in a package's files.
+func PackagePrototypes(files map[string][]byte) (prototype.Prototypes, error) {
+	var paths []string
+	for relPath := range files {
+		if path.Ext(relPath) == ".jsonnet" && strings.Contains("/"+relPath, "/prototypes/") {
+			paths = append(paths, relPath)
+		}
+	}
+	sort.Strings(paths)
+
+	var prototypes prototype.Prototypes
+	for _, relPath := range paths {
+		p, err := prototype.DefaultBuilder(string(files[relPath]))
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing prototype %s", relPath)
+		}
+		prototypes = append(prototypes, p)
+	}
+
+	return prototypes, nil
+}
@@ -16,6 +16,8 @@
 package registry
 
 import (
+	"io"
+
 	"github.com/ksonnet/ksonnet/pkg/app"
 	"github.com/ksonnet/ksonnet/pkg/parts"
 )
@@ -41,8 +43,9 @@ const (
 	partsYAMLFile    = "parts.yaml"
 )
 
-// ResolveFile resolves files found when searching a part.
-type ResolveFile func(relPath string, contents []byte) error
+// ResolveFile resolves files found when searching a part. The reader streams
+// the file's contents and is only valid until ResolveFile returns.
+type ResolveFile func(relPath string, r io.Reader) error
 
 // ResolveDirectory resolves directories when searching a part.
 type ResolveDirectory func(relPath string) error
@@ -58,11 +61,31 @@ type Registry interface {
 	URI() string
 	IsOverride() bool
 	CacheRoot(name, relPath string) (string, error)
+	// Status contacts the registry to compare its cached version with the
+	// latest version available.
+	Status() (*Status, error)
 
+	Walker
 	Validator
 	Setter
 }
 
+// Status is the state of a registry's cache compared to its remote.
+type Status struct {
+	// RefSpec is the reference the registry tracks, e.g. a branch or tag.
+	RefSpec string
+	// Version is the version of the cached registry spec. It is empty if
+	// the registry is not cached.
+	Version string
+	// Latest is the latest version available for RefSpec.
+	Latest string
+}
+
+// IsStale is true if the cached version is not the latest version.
+func (s *Status) IsStale() bool {
+	return s.Version != "" && s.Latest != "" && s.Version != s.Latest
+}
+
 // SpecFetcher fetches registry metadata
 type SpecFetcher interface {
 	// FetchRegistrySpec fetches the registry spec (registry.yaml, inventory of packages)
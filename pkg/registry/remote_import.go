@@ -0,0 +1,192 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ghodss/yaml"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/util/jsonnet"
+	"github.com/ksonnet/ksonnet/pkg/util/trace"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+const (
+	// RemoteImportLockFile is the name of the file which pins the contents
+	// of remote imports. It is in the app root.
+	RemoteImportLockFile = "imports.lock"
+)
+
+// remoteImportMu serializes updates of the lock file.
+var remoteImportMu sync.Mutex
+
+// RemoteImportLock pins the contents of remote imports.
+type RemoteImportLock struct {
+	Imports map[string]RemoteImportPin `json:"imports"`
+}
+
+// RemoteImportPin is the checksum of a remote import's contents.
+type RemoteImportPin struct {
+	SHA256 string `json:"sha256"`
+}
+
+// RemoteImports fetches jsonnet imported from http and https URLs. Imports
+// are cached in the app, and pinned in the app's lock file the first time
+// they are fetched. A pinned import is read from the cache, or fetched again
+// if it is not cached, and must match its checksum. Remove an import from
+// the lock file to update it.
+type RemoteImports struct {
+	app        app.App
+	httpClient *http.Client
+}
+
+// NewRemoteImports creates an instance of RemoteImports.
+func NewRemoteImports(a app.App, httpClient *http.Client) *RemoteImports {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &RemoteImports{
+		app:        a,
+		httpClient: httpClient,
+	}
+}
+
+// Fetch returns the contents of a URL. It implements jsonnet.RemoteFetcher.
+func (ri *RemoteImports) Fetch(u string) ([]byte, error) {
+	remoteImportMu.Lock()
+	defer remoteImportMu.Unlock()
+
+	lock, err := ri.readLock()
+	if err != nil {
+		return nil, err
+	}
+
+	pin, pinned := lock.Imports[u]
+	if pinned {
+		data, err := afero.ReadFile(ri.app.Fs(), ri.cachePath(pin.SHA256))
+		switch {
+		case err == nil && checksum(data) == pin.SHA256:
+			return data, nil
+		case err != nil && !os.IsNotExist(err):
+			return nil, err
+		}
+	}
+
+	span := trace.Start("registry.import", "url", u)
+	data, err := ri.download(u)
+	if err = span.Finish(err); err != nil {
+		return nil, err
+	}
+
+	sum := checksum(data)
+	if pinned && sum != pin.SHA256 {
+		return nil, errors.Errorf("%s does not match the sha256 checksum in %s; remove it from %s to update it",
+			u, RemoteImportLockFile, RemoteImportLockFile)
+	}
+
+	if err = ri.app.Fs().MkdirAll(ri.cacheRoot(), app.DefaultFolderPermissions); err != nil {
+		return nil, errors.Wrap(err, "creating remote import cache")
+	}
+	if err = afero.WriteFile(ri.app.Fs(), ri.cachePath(sum), data, app.DefaultFilePermissions); err != nil {
+		return nil, errors.Wrap(err, "caching remote import")
+	}
+
+	if !pinned {
+		log.Infof("pinning %s in %s", u, RemoteImportLockFile)
+		lock.Imports[u] = RemoteImportPin{SHA256: sum}
+		if err = ri.writeLock(lock); err != nil {
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
+
+func (ri *RemoteImports) download(u string) ([]byte, error) {
+	log.Debugf("fetching remote import %s", u)
+
+	resp, err := ri.httpClient.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, jsonnet.ErrRemoteNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, errors.Errorf("fetching %s: %s", u, resp.Status)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+func (ri *RemoteImports) lockPath() string {
+	return filepath.Join(ri.app.Root(), RemoteImportLockFile)
+}
+
+// cacheRoot returns the root path for cached remote imports.
+func (ri *RemoteImports) cacheRoot() string {
+	return filepath.Join(ri.app.Root(), ".ksonnet", "imports")
+}
+
+func (ri *RemoteImports) cachePath(sum string) string {
+	return filepath.Join(ri.cacheRoot(), sum)
+}
+
+func (ri *RemoteImports) readLock() (*RemoteImportLock, error) {
+	lock := &RemoteImportLock{}
+
+	data, err := afero.ReadFile(ri.app.Fs(), ri.lockPath())
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return nil, err
+	default:
+		if err = yaml.Unmarshal(data, lock); err != nil {
+			return nil, errors.Wrapf(err, "reading %s", RemoteImportLockFile)
+		}
+	}
+
+	if lock.Imports == nil {
+		lock.Imports = make(map[string]RemoteImportPin)
+	}
+
+	return lock, nil
+}
+
+func (ri *RemoteImports) writeLock(lock *RemoteImportLock) error {
+	data, err := yaml.Marshal(lock)
+	if err != nil {
+		return err
+	}
+
+	return afero.WriteFile(ri.app.Fs(), ri.lockPath(), data, app.DefaultFilePermissions)
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
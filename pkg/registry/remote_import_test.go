@@ -0,0 +1,75 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package registry
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/util/jsonnet"
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteImports_Fetch(t *testing.T) {
+	contents := "{ a: 1 }"
+	requests := 0
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/lib.libsonnet" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, contents)
+	}))
+	defer ts.Close()
+
+	u := ts.URL + "/lib.libsonnet"
+
+	test.WithApp(t, "/app", func(a *amocks.App, fs afero.Fs) {
+		ri := NewRemoteImports(a, ts.Client())
+
+		data, err := ri.Fetch(u)
+		require.NoError(t, err)
+		assert.Equal(t, "{ a: 1 }", string(data))
+
+		lock, err := ri.readLock()
+		require.NoError(t, err)
+		require.Contains(t, lock.Imports, u)
+		assert.Equal(t, checksum(data), lock.Imports[u].SHA256)
+
+		// pinned imports are read from the cache.
+		data, err = ri.Fetch(u)
+		require.NoError(t, err)
+		assert.Equal(t, "{ a: 1 }", string(data))
+		assert.Equal(t, 1, requests)
+
+		// pinned imports which changed are rejected.
+		require.NoError(t, fs.RemoveAll("/app/.ksonnet/imports"))
+		contents = "{ a: 2 }"
+		_, err = ri.Fetch(u)
+		require.Error(t, err)
+
+		_, err = ri.Fetch(ts.URL + "/missing.libsonnet")
+		assert.Equal(t, jsonnet.ErrRemoteNotFound, err)
+	})
+}
@@ -0,0 +1,126 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package registry
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/pkg"
+	"github.com/pkg/errors"
+)
+
+// AmbiguousPackageError is returned when a package name without a registry
+// is found in more than one registry.
+type AmbiguousPackageError struct {
+	Name       string
+	Registries []string
+}
+
+func (e *AmbiguousPackageError) Error() string {
+	return fmt.Sprintf("package %q is in more than one registry (%s); qualify it with a registry, e.g. %s/%s",
+		e.Name, strings.Join(e.Registries, ", "), e.Registries[0], e.Name)
+}
+
+// namedSpecFetcher is the subset of a registry needed to find packages.
+type namedSpecFetcher interface {
+	Name() string
+	SpecFetcher
+}
+
+// ResolveDescriptor sets the registry for a package descriptor. If
+// registryName is set, it is the registry. Otherwise a descriptor without a
+// registry uses the app's default registry if it contains the package, or
+// the only registry which contains the package.
+func ResolveDescriptor(a app.App, d pkg.Descriptor, registryName string, httpClient *http.Client) (pkg.Descriptor, error) {
+	if a == nil {
+		return d, errors.Errorf("nil app")
+	}
+
+	if registryName != "" || d.Registry != "" {
+		return resolveDescriptor(nil, "", d, registryName)
+	}
+
+	defaultRegistry, err := a.DefaultRegistry()
+	if err != nil {
+		return d, errors.Wrap(err, "retrieving default registry")
+	}
+
+	registries, err := List(a, httpClient)
+	if err != nil {
+		return d, errors.Wrap(err, "listing registries")
+	}
+
+	var fetchers []namedSpecFetcher
+	for _, r := range registries {
+		fetchers = append(fetchers, r)
+	}
+
+	return resolveDescriptor(fetchers, defaultRegistry, d, registryName)
+}
+
+func resolveDescriptor(registries []namedSpecFetcher, defaultRegistry string, d pkg.Descriptor, registryName string) (pkg.Descriptor, error) {
+	if registryName != "" {
+		if d.Registry != "" && d.Registry != registryName {
+			return d, errors.Errorf("package %v is not in registry %s", d, registryName)
+		}
+		d.Registry = registryName
+		return d, nil
+	}
+
+	if d.Registry != "" {
+		return d, nil
+	}
+
+	// The default registry is checked first so other registries are only
+	// contacted when it doesn't have the package.
+	sort.SliceStable(registries, func(i, j int) bool {
+		return registries[i].Name() == defaultRegistry && registries[j].Name() != defaultRegistry
+	})
+
+	var found []string
+	for _, r := range registries {
+		spec, err := r.FetchRegistrySpec()
+		if err != nil {
+			log.WithError(err).Warnf("skipping registry %s while looking for package %s", r.Name(), d.Name)
+			continue
+		}
+
+		if _, ok := spec.Libraries[d.Name]; !ok {
+			continue
+		}
+
+		if r.Name() == defaultRegistry {
+			d.Registry = defaultRegistry
+			return d, nil
+		}
+		found = append(found, r.Name())
+	}
+
+	switch len(found) {
+	case 0:
+		return d, errors.Errorf("package %q was not found in any registry", d.Name)
+	case 1:
+		d.Registry = found[0]
+		return d, nil
+	default:
+		sort.Strings(found)
+		return d, &AmbiguousPackageError{Name: d.Name, Registries: found}
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package registry
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/pkg"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeNamedSpecFetcher struct {
+	name      string
+	libraries []string
+	err       error
+	fetched   bool
+}
+
+func (f *fakeNamedSpecFetcher) Name() string {
+	return f.name
+}
+
+func (f *fakeNamedSpecFetcher) FetchRegistrySpec() (*Spec, error) {
+	f.fetched = true
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	spec := &Spec{Libraries: LibraryConfigs{}}
+	for _, name := range f.libraries {
+		spec.Libraries[name] = &LibraryConfig{}
+	}
+	return spec, nil
+}
+
+func Test_resolveDescriptor(t *testing.T) {
+	cases := []struct {
+		name            string
+		registries      []*fakeNamedSpecFetcher
+		defaultRegistry string
+		d               pkg.Descriptor
+		registryName    string
+		expected        pkg.Descriptor
+		isErr           bool
+		isAmbiguous     bool
+	}{
+		{
+			name:     "qualified name",
+			d:        pkg.Descriptor{Registry: "incubator", Name: "redis"},
+			expected: pkg.Descriptor{Registry: "incubator", Name: "redis"},
+		},
+		{
+			name:         "registry override",
+			d:            pkg.Descriptor{Name: "redis", Version: "1.0"},
+			registryName: "stable",
+			expected:     pkg.Descriptor{Registry: "stable", Name: "redis", Version: "1.0"},
+		},
+		{
+			name:         "registry override matches qualified name",
+			d:            pkg.Descriptor{Registry: "stable", Name: "redis"},
+			registryName: "stable",
+			expected:     pkg.Descriptor{Registry: "stable", Name: "redis"},
+		},
+		{
+			name:         "registry override conflicts with qualified name",
+			d:            pkg.Descriptor{Registry: "incubator", Name: "redis"},
+			registryName: "stable",
+			isErr:        true,
+		},
+		{
+			name: "default registry",
+			registries: []*fakeNamedSpecFetcher{
+				{name: "stable", libraries: []string{"redis"}},
+				{name: "incubator", libraries: []string{"redis"}},
+			},
+			defaultRegistry: "incubator",
+			d:               pkg.Descriptor{Name: "redis"},
+			expected:        pkg.Descriptor{Registry: "incubator", Name: "redis"},
+		},
+		{
+			name: "default registry without package",
+			registries: []*fakeNamedSpecFetcher{
+				{name: "incubator", libraries: []string{"nginx"}},
+				{name: "stable", libraries: []string{"redis"}},
+			},
+			defaultRegistry: "incubator",
+			d:               pkg.Descriptor{Name: "redis"},
+			expected:        pkg.Descriptor{Registry: "stable", Name: "redis"},
+		},
+		{
+			name: "single match",
+			registries: []*fakeNamedSpecFetcher{
+				{name: "incubator", libraries: []string{"redis"}},
+				{name: "broken", err: errors.New("fail")},
+			},
+			d:        pkg.Descriptor{Name: "redis", Version: "1.0"},
+			expected: pkg.Descriptor{Registry: "incubator", Name: "redis", Version: "1.0"},
+		},
+		{
+			name: "ambiguous",
+			registries: []*fakeNamedSpecFetcher{
+				{name: "stable", libraries: []string{"redis"}},
+				{name: "incubator", libraries: []string{"redis"}},
+			},
+			d:           pkg.Descriptor{Name: "redis"},
+			isErr:       true,
+			isAmbiguous: true,
+		},
+		{
+			name: "not found",
+			registries: []*fakeNamedSpecFetcher{
+				{name: "incubator", libraries: []string{"nginx"}},
+			},
+			d:     pkg.Descriptor{Name: "redis"},
+			isErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var registries []namedSpecFetcher
+			for _, r := range tc.registries {
+				registries = append(registries, r)
+			}
+
+			d, err := resolveDescriptor(registries, tc.defaultRegistry, tc.d, tc.registryName)
+			if tc.isErr {
+				require.Error(t, err)
+				_, ok := err.(*AmbiguousPackageError)
+				assert.Equal(t, tc.isAmbiguous, ok)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, d)
+		})
+	}
+}
+
+func Test_resolveDescriptor_default_registry_first(t *testing.T) {
+	stable := &fakeNamedSpecFetcher{name: "stable", libraries: []string{"redis"}}
+	incubator := &fakeNamedSpecFetcher{name: "incubator", libraries: []string{"redis"}}
+
+	d, err := resolveDescriptor([]namedSpecFetcher{stable, incubator}, "incubator", pkg.Descriptor{Name: "redis"}, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, "incubator", d.Registry)
+	assert.False(t, stable.fetched, "registry fetched after the default registry matched")
+}
+
+func TestAmbiguousPackageError(t *testing.T) {
+	err := &AmbiguousPackageError{Name: "redis", Registries: []string{"incubator", "stable"}}
+	expected := `package "redis" is in more than one registry (incubator, stable); qualify it with a registry, e.g. incubator/redis`
+	assert.Equal(t, expected, err.Error())
+}
@@ -192,6 +192,19 @@ type Specs []*Spec
 type LibraryConfig struct {
 	Version string `json:"version"`
 	Path    string `json:"path"`
+
+	// Deprecated marks a library which should no longer be used.
+	Deprecated bool `json:"deprecated,omitempty"`
+	// DeprecationMessage explains why the library is deprecated.
+	DeprecationMessage string `json:"deprecationMessage,omitempty"`
+	// Replacement is the package to use instead of a deprecated library,
+	// e.g. incubator/redis-ha.
+	Replacement string `json:"replacement,omitempty"`
+	// Yanked marks the version of the library as withdrawn. Yanked versions
+	// are only installed when forced.
+	Yanked bool `json:"yanked,omitempty"`
+	// YankMessage explains why the version was yanked.
+	YankMessage string `json:"yankMessage,omitempty"`
 }
 
 // LibraryConfigs maps LibraryConfigs to a name.
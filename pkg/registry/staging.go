@@ -0,0 +1,276 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package registry
+
+import (
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+// stagingPrefix prefixes staging directories in the vendor directory.
+const stagingPrefix = ".ks-staging-"
+
+var (
+	// exitFn exits after an interrupted install is cleaned up.
+	exitFn = os.Exit
+)
+
+// vendorInstall moves staged files into the vendor directory as a unit. If
+// moving any file fails, files which were moved are removed, files they
+// replaced are restored, and directories which were created are removed.
+type vendorInstall struct {
+	fs         afero.Fs
+	vendorRoot string
+	stagingDir string
+	moves      []vendorMove
+
+	// mu is held while files are moved, so an interrupt can't leave the
+	// vendor directory half-updated.
+	mu sync.Mutex
+}
+
+type vendorMove struct {
+	staged   string
+	vendored string
+}
+
+// newVendorInstall creates a staging directory in vendorRoot. The staging
+// directory is in the vendor directory so files can be renamed into place
+// rather than copied.
+func newVendorInstall(fs afero.Fs, vendorRoot string) (*vendorInstall, error) {
+	if err := fs.MkdirAll(vendorRoot, app.DefaultFolderPermissions); err != nil {
+		return nil, errors.Wrap(err, "unable to create vendor directory")
+	}
+
+	stagingDir, err := afero.TempDir(fs, vendorRoot, stagingPrefix)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create staging directory")
+	}
+
+	return &vendorInstall{
+		fs:         fs,
+		vendorRoot: vendorRoot,
+		stagingDir: stagingDir,
+	}, nil
+}
+
+// stage streams a file to the staging directory.
+func (vi *vendorInstall) stage(relPath string, r io.Reader) error {
+	if err := stageFile(vi.fs, vi.stagedPath(relPath), r); err != nil {
+		return errors.Wrapf(err, "staging file %s", relPath)
+	}
+
+	return nil
+}
+
+// move schedules a staged file to be moved to vendoredPath when the install
+// is committed.
+func (vi *vendorInstall) move(relPath, vendoredPath string) {
+	vi.moves = append(vi.moves, vendorMove{staged: vi.stagedPath(relPath), vendored: vendoredPath})
+}
+
+func (vi *vendorInstall) stagedPath(relPath string) string {
+	return filepath.Join(vi.stagingDir, "files", relPath)
+}
+
+// commit moves the staged files into the vendor directory.
+func (vi *vendorInstall) commit() error {
+	vi.mu.Lock()
+	defer vi.mu.Unlock()
+
+	backupDir := filepath.Join(vi.stagingDir, "backup")
+	if err := vi.fs.MkdirAll(backupDir, app.DefaultFolderPermissions); err != nil {
+		return errors.Wrap(err, "unable to create backup directory")
+	}
+
+	var moved []vendorMove
+	backups := map[string]string{}
+	var created []string
+
+	for i, m := range vi.moves {
+		log.Debugf("vendoring file to path: %v", m.vendored)
+
+		err := func() error {
+			dir := filepath.Dir(m.vendored)
+			missing, err := vi.firstMissing(dir)
+			if err != nil {
+				return err
+			}
+			if err = vi.fs.MkdirAll(dir, app.DefaultFolderPermissions); err != nil {
+				return errors.Wrap(err, "unable to create directory")
+			}
+			if missing != "" {
+				created = append(created, missing)
+			}
+
+			// Existing files are moved aside so they can be restored, and
+			// because not every filesystem replaces a file on rename.
+			exists, err := afero.Exists(vi.fs, m.vendored)
+			if err != nil {
+				return err
+			}
+			if exists {
+				backup := filepath.Join(backupDir, strconv.Itoa(i))
+				if err = vi.fs.Rename(m.vendored, backup); err != nil {
+					return errors.Wrap(err, "unable to replace file")
+				}
+				backups[m.vendored] = backup
+			}
+
+			if err = vi.fs.Rename(m.staged, m.vendored); err != nil {
+				return errors.Wrap(err, "unable to create file")
+			}
+			moved = append(moved, m)
+
+			return nil
+		}()
+
+		if err != nil {
+			vi.rollback(moved, backups, created)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rollback undoes the moves made by a failed commit.
+func (vi *vendorInstall) rollback(moved []vendorMove, backups map[string]string, created []string) {
+	log.Warnf("installation failed; rolling back vendored files")
+
+	for i := len(moved) - 1; i >= 0; i-- {
+		vendored := moved[i].vendored
+		if err := vi.fs.Remove(vendored); err != nil && !os.IsNotExist(err) {
+			log.WithError(err).Warnf("removing %s", vendored)
+		}
+	}
+
+	for vendored, backup := range backups {
+		if err := vi.fs.Rename(backup, vendored); err != nil {
+			log.WithError(err).Warnf("restoring %s", vendored)
+		}
+	}
+
+	for i := len(created) - 1; i >= 0; i-- {
+		if err := vi.fs.RemoveAll(created[i]); err != nil {
+			log.WithError(err).Warnf("removing %s", created[i])
+		}
+	}
+}
+
+// firstMissing returns the outermost directory between dir and the vendor
+// root which does not exist, or an empty string if dir exists.
+func (vi *vendorInstall) firstMissing(dir string) (string, error) {
+	var missing string
+	for dir != vi.vendorRoot && strings.HasPrefix(dir, vi.vendorRoot) {
+		exists, err := afero.Exists(vi.fs, dir)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			break
+		}
+		missing = dir
+		dir = filepath.Dir(dir)
+	}
+
+	return missing, nil
+}
+
+// cleanup removes the staging directory.
+func (vi *vendorInstall) cleanup() {
+	if err := vi.fs.RemoveAll(vi.stagingDir); err != nil {
+		log.WithError(err).Warnf("removing staging directory %s", vi.stagingDir)
+	}
+}
+
+// onInterrupt waits for an in progress commit, then runs cleanup and exits
+// if the process is interrupted. The returned function stops waiting.
+func (vi *vendorInstall) onInterrupt(cleanup func()) func() {
+	sigCh := make(chan os.Signal, 1)
+	doneCh := make(chan struct{})
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case <-sigCh:
+			vi.mu.Lock()
+			log.Warnf("installation interrupted; cleaning up")
+			cleanup()
+			exitFn(130)
+		case <-doneCh:
+		}
+	}()
+
+	return func() {
+		signal.Stop(sigCh)
+		close(doneCh)
+	}
+}
+
+// removeStaleStaging removes staging directories left behind by installs
+// which were killed. The caller must hold the vendor lock.
+func removeStaleStaging(fs afero.Fs, vendorRoot string) error {
+	fis, err := afero.ReadDir(fs, vendorRoot)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, fi := range fis {
+		if !fi.IsDir() || !strings.HasPrefix(fi.Name(), stagingPrefix) {
+			continue
+		}
+
+		log.Debugf("removing stale staging directory %s", fi.Name())
+		if err := fs.RemoveAll(filepath.Join(vendorRoot, fi.Name())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// stageFile streams a file to path, creating its parent directories.
+func stageFile(fs afero.Fs, path string, r io.Reader) error {
+	if err := fs.MkdirAll(filepath.Dir(path), app.DefaultFolderPermissions); err != nil {
+		return err
+	}
+
+	f, err := fs.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, app.DefaultFilePermissions)
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
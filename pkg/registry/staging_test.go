@@ -0,0 +1,151 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// renameFailFs fails to rename files to a path.
+type renameFailFs struct {
+	afero.Fs
+	path string
+}
+
+func (fs *renameFailFs) Rename(oldname, newname string) error {
+	if newname == fs.path {
+		return errors.New("rename failed")
+	}
+	return fs.Fs.Rename(oldname, newname)
+}
+
+func stageVendorInstall(t *testing.T, fs afero.Fs) *vendorInstall {
+	vendorRoot := "/app/vendor"
+	require.NoError(t, afero.WriteFile(fs, "/app/vendor/incubator/apache/parts.yaml", []byte("old"), 0644))
+
+	vi, err := newVendorInstall(fs, vendorRoot)
+	require.NoError(t, err)
+
+	files := map[string]string{
+		"apache/parts.yaml":           "/app/vendor/incubator/apache/parts.yaml",
+		"apache/prototypes/a.jsonnet": "/app/vendor/incubator/apache/prototypes/a.jsonnet",
+		"apache/README.md":            "/app/vendor/incubator/apache/README.md",
+	}
+	for _, relPath := range []string{"apache/parts.yaml", "apache/prototypes/a.jsonnet", "apache/README.md"} {
+		require.NoError(t, vi.stage(relPath, strings.NewReader("new")))
+		vi.move(relPath, files[relPath])
+	}
+
+	return vi
+}
+
+func Test_vendorInstall_commit(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	vi := stageVendorInstall(t, fs)
+
+	require.NoError(t, vi.commit())
+	vi.cleanup()
+
+	for _, path := range []string{"parts.yaml", "prototypes/a.jsonnet", "README.md"} {
+		data, err := afero.ReadFile(fs, filepath.Join("/app/vendor/incubator/apache", path))
+		require.NoError(t, err)
+		assert.Equal(t, "new", string(data))
+	}
+
+	exists, err := afero.DirExists(fs, vi.stagingDir)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func Test_vendorInstall_commit_rollback(t *testing.T) {
+	fs := &renameFailFs{
+		Fs:   afero.NewMemMapFs(),
+		path: "/app/vendor/incubator/apache/README.md",
+	}
+	vi := stageVendorInstall(t, fs)
+
+	require.Error(t, vi.commit())
+
+	data, err := afero.ReadFile(fs, "/app/vendor/incubator/apache/parts.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(data))
+
+	exists, err := afero.Exists(fs, "/app/vendor/incubator/apache/prototypes")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	exists, err = afero.Exists(fs, "/app/vendor/incubator/apache/README.md")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func Test_vendorInstall_onInterrupt(t *testing.T) {
+	ogExitFn := exitFn
+	defer func() { exitFn = ogExitFn }()
+
+	exitCh := make(chan int, 1)
+	exitFn = func(code int) {
+		exitCh <- code
+	}
+
+	fs := afero.NewMemMapFs()
+	vi, err := newVendorInstall(fs, "/app/vendor")
+	require.NoError(t, err)
+
+	stop := vi.onInterrupt(vi.cleanup)
+	defer stop()
+
+	p, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+	if err = p.Signal(os.Interrupt); err != nil {
+		t.Skipf("sending interrupt: %v", err)
+	}
+
+	select {
+	case code := <-exitCh:
+		assert.Equal(t, 130, code)
+	case <-time.After(5 * time.Second):
+		t.Fatal("interrupt was not handled")
+	}
+
+	exists, err := afero.DirExists(fs, vi.stagingDir)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func Test_removeStaleStaging(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, fs.MkdirAll("/app/vendor/.ks-staging-123/files", 0755))
+	require.NoError(t, fs.MkdirAll("/app/vendor/incubator/apache", 0755))
+
+	require.NoError(t, removeStaleStaging(fs, "/app/vendor"))
+
+	fis, err := afero.ReadDir(fs, "/app/vendor")
+	require.NoError(t, err)
+	require.Len(t, fis, 1)
+	assert.Equal(t, "incubator", fis[0].Name())
+
+	require.NoError(t, removeStaleStaging(fs, "/missing"))
+}
@@ -0,0 +1,267 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package registry
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	utilio "github.com/ksonnet/ksonnet/pkg/util/io"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+)
+
+// Stats are usage statistics for a registry. They are only recorded once
+// they have been enabled with EnableStats.
+type Stats struct {
+	// Fetches is the number of times the registry spec was fetched.
+	Fetches int64 `json:"fetches"`
+	// FetchTime is the total time spent fetching the registry spec.
+	FetchTime time.Duration `json:"fetchTime"`
+	// CacheHits is the number of fetches served from the registry cache.
+	CacheHits int64 `json:"cacheHits"`
+	// CacheMisses is the number of fetches which refreshed the registry cache.
+	CacheMisses int64 `json:"cacheMisses"`
+	// Requests is the number of HTTP requests made to the registry.
+	Requests int64 `json:"requests"`
+	// RateLimit is the request limit the registry last reported.
+	RateLimit int `json:"rateLimit,omitempty"`
+	// RateRemaining is the number of requests the registry last reported
+	// as remaining before the limit resets.
+	RateRemaining int `json:"rateRemaining,omitempty"`
+	// RateReset is when the registry's rate limit resets.
+	RateReset time.Time `json:"rateReset,omitempty"`
+}
+
+// AverageFetchTime is the average time taken to fetch the registry spec.
+func (s *Stats) AverageFetchTime() time.Duration {
+	if s.Fetches == 0 {
+		return 0
+	}
+
+	return s.FetchTime / time.Duration(s.Fetches)
+}
+
+// CacheHitRate is the fraction of fetches served from the cache. It is
+// false if no fetches have used the cache.
+func (s *Stats) CacheHitRate() (float64, bool) {
+	total := s.CacheHits + s.CacheMisses
+	if total == 0 {
+		return 0, false
+	}
+
+	return float64(s.CacheHits) / float64(total), true
+}
+
+// RateLimitUsed is the number of requests counted against the registry's
+// rate limit at now. It is false if the registry doesn't report a rate limit.
+func (s *Stats) RateLimitUsed(now time.Time) (int, bool) {
+	if s.RateLimit == 0 {
+		return 0, false
+	}
+
+	if !s.RateReset.IsZero() && now.After(s.RateReset) {
+		return 0, true
+	}
+
+	return s.RateLimit - s.RateRemaining, true
+}
+
+// statsPath is the path of the stats store. Stats are enabled when it exists.
+func statsPath(a app.App) string {
+	return filepath.Join(a.Root(), ".ksonnet", "stats", "registries.json")
+}
+
+// StatsEnabled returns true if registry stats are being recorded for an app.
+func StatsEnabled(a app.App) (bool, error) {
+	return afero.Exists(a.Fs(), statsPath(a))
+}
+
+// EnableStats starts recording registry stats for an app. Stats which were
+// already recorded are kept.
+func EnableStats(a app.App) error {
+	enabled, err := StatsEnabled(a)
+	if err != nil || enabled {
+		return err
+	}
+
+	path := statsPath(a)
+	if err := a.Fs().MkdirAll(filepath.Dir(path), app.DefaultFolderPermissions); err != nil {
+		return errors.Wrap(err, "creating stats directory")
+	}
+
+	return writeStats(a, map[string]*Stats{})
+}
+
+// DisableStats stops recording registry stats for an app, and removes the
+// stats which were recorded.
+func DisableStats(a app.App) error {
+	err := a.Fs().Remove(statsPath(a))
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "removing registry stats")
+	}
+
+	return nil
+}
+
+// LoadStats loads the recorded stats for an app's registries, by registry
+// name. It returns nil if stats are not enabled.
+func LoadStats(a app.App) (map[string]*Stats, error) {
+	data, err := afero.ReadFile(a.Fs(), statsPath(a))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "reading registry stats")
+	}
+
+	stats := map[string]*Stats{}
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling registry stats")
+	}
+
+	return stats, nil
+}
+
+func writeStats(a app.App, stats map[string]*Stats) error {
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "marshalling registry stats")
+	}
+
+	return utilio.WriteFileAtomic(a.Fs(), statsPath(a), data, app.DefaultFilePermissions)
+}
+
+// recordStats updates the stats for a registry if stats are enabled.
+// Failures are logged rather than returned, so stats never get in the way of
+// using a registry.
+func recordStats(a app.App, name string, fn func(*Stats)) {
+	if a == nil {
+		return
+	}
+
+	if err := updateStats(a, name, fn); err != nil {
+		log.WithError(err).Debugf("recording stats for registry %s", name)
+	}
+}
+
+func updateStats(a app.App, name string, fn func(*Stats)) error {
+	if enabled, err := StatsEnabled(a); err != nil || !enabled {
+		return err
+	}
+
+	lock, err := lockCache(a, "stats")
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	stats, err := LoadStats(a)
+	if err != nil || stats == nil {
+		return err
+	}
+
+	s, ok := stats[name]
+	if !ok {
+		s = &Stats{}
+		stats[name] = s
+	}
+	fn(s)
+
+	return writeStats(a, stats)
+}
+
+// recordFetch records a fetch of a registry spec which started at start.
+// hit is true if the fetch was served from the registry cache.
+func recordFetch(a app.App, name string, start time.Time, hit bool) {
+	elapsed := time.Since(start)
+	recordStats(a, name, func(s *Stats) {
+		s.Fetches++
+		s.FetchTime += elapsed
+		if hit {
+			s.CacheHits++
+		} else {
+			s.CacheMisses++
+		}
+	})
+}
+
+// statsTransport counts the HTTP requests made to a registry, and records the
+// rate limit reported in their responses.
+type statsTransport struct {
+	app  app.App
+	name string
+	next http.RoundTripper
+}
+
+// withStats returns a copy of httpClient which records stats for a registry,
+// or httpClient if stats are not enabled.
+func withStats(a app.App, name string, httpClient *http.Client) *http.Client {
+	if a == nil || httpClient == nil {
+		return httpClient
+	}
+
+	if enabled, err := StatsEnabled(a); err != nil || !enabled {
+		return httpClient
+	}
+
+	next := httpClient.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	c := *httpClient
+	c.Transport = &statsTransport{app: a, name: name, next: next}
+	return &c
+}
+
+func (t *statsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+
+	recordStats(t.app, t.name, func(s *Stats) {
+		s.Requests++
+		if resp != nil {
+			recordRateLimit(s, resp.Header)
+		}
+	})
+
+	return resp, err
+}
+
+// recordRateLimit records the rate limit reported in GitHub style
+// X-RateLimit headers.
+func recordRateLimit(s *Stats, header http.Header) {
+	limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return
+	}
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+
+	s.RateLimit = limit
+	s.RateRemaining = remaining
+
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		s.RateReset = time.Unix(reset, 0).UTC()
+	}
+}
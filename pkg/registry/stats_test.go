@@ -0,0 +1,145 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package registry
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (fn roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+func statsApp() *amocks.App {
+	a := new(amocks.App)
+	a.On("Fs").Return(afero.NewMemMapFs())
+	a.On("Root").Return("/app")
+	return a
+}
+
+func TestStats_enable_disable(t *testing.T) {
+	a := statsApp()
+
+	stats, err := LoadStats(a)
+	require.NoError(t, err)
+	assert.Nil(t, stats)
+
+	// Nothing is recorded until stats are enabled.
+	recordFetch(a, "incubator", time.Now(), true)
+	exists, err := afero.Exists(a.Fs(), statsPath(a))
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	require.NoError(t, EnableStats(a))
+
+	recordFetch(a, "incubator", time.Now(), true)
+	recordFetch(a, "incubator", time.Now(), false)
+
+	stats, err = LoadStats(a)
+	require.NoError(t, err)
+	require.Contains(t, stats, "incubator")
+	assert.Equal(t, int64(2), stats["incubator"].Fetches)
+	assert.Equal(t, int64(1), stats["incubator"].CacheHits)
+	assert.Equal(t, int64(1), stats["incubator"].CacheMisses)
+
+	// Enabling stats again keeps the recorded stats.
+	require.NoError(t, EnableStats(a))
+	stats, err = LoadStats(a)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), stats["incubator"].Fetches)
+
+	require.NoError(t, DisableStats(a))
+	stats, err = LoadStats(a)
+	require.NoError(t, err)
+	assert.Nil(t, stats)
+
+	require.NoError(t, DisableStats(a))
+}
+
+func Test_withStats(t *testing.T) {
+	a := statsApp()
+
+	next := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		header := http.Header{}
+		header.Set("X-RateLimit-Limit", "5000")
+		header.Set("X-RateLimit-Remaining", "4990")
+		header.Set("X-RateLimit-Reset", "1527856200")
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     header,
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+			Request:    req,
+		}, nil
+	})
+	httpClient := &http.Client{Transport: next}
+
+	assert.Equal(t, httpClient, withStats(a, "incubator", httpClient), "client wrapped while stats are disabled")
+
+	require.NoError(t, EnableStats(a))
+
+	c := withStats(a, "incubator", httpClient)
+	_, ok := c.Transport.(*statsTransport)
+	assert.True(t, ok, "client not wrapped while stats are enabled")
+	_, ok = httpClient.Transport.(*statsTransport)
+	assert.False(t, ok, "original client was modified")
+
+	_, err := c.Get("https://api.github.com/repos/ksonnet/parts")
+	require.NoError(t, err)
+
+	stats, err := LoadStats(a)
+	require.NoError(t, err)
+	require.Contains(t, stats, "incubator")
+
+	s := stats["incubator"]
+	assert.Equal(t, int64(1), s.Requests)
+	assert.Equal(t, 5000, s.RateLimit)
+	assert.Equal(t, 4990, s.RateRemaining)
+
+	used, ok := s.RateLimitUsed(time.Unix(1527856000, 0))
+	assert.True(t, ok)
+	assert.Equal(t, 10, used)
+
+	used, ok = s.RateLimitUsed(time.Unix(1527856300, 0))
+	assert.True(t, ok)
+	assert.Equal(t, 0, used)
+}
+
+func TestStats_summaries(t *testing.T) {
+	var s Stats
+	assert.Equal(t, time.Duration(0), s.AverageFetchTime())
+	_, ok := s.CacheHitRate()
+	assert.False(t, ok)
+	_, ok = s.RateLimitUsed(time.Now())
+	assert.False(t, ok)
+
+	s = Stats{Fetches: 4, FetchTime: time.Second, CacheHits: 1, CacheMisses: 3}
+	assert.Equal(t, 250*time.Millisecond, s.AverageFetchTime())
+	rate, ok := s.CacheHitRate()
+	assert.True(t, ok)
+	assert.Equal(t, 0.25, rate)
+}
@@ -0,0 +1,256 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package registry
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// URIErrorKind classifies why a registry URI is invalid.
+type URIErrorKind string
+
+const (
+	// URIMalformed is a URI which can not be parsed.
+	URIMalformed URIErrorKind = "malformed"
+	// URIUnsupported is a URI for a kind of registry ksonnet can not add,
+	// e.g. a GitLab repository or a tarball.
+	URIUnsupported URIErrorKind = "unsupported"
+	// URIMissingScheme is a Helm chart repository URI without a scheme.
+	URIMissingScheme URIErrorKind = "missing-scheme"
+	// URICloneURL is a git clone URL rather than a repository URI.
+	URICloneURL URIErrorKind = "clone-url"
+	// URIRawContent is a URL to a raw file rather than a repository URI.
+	URIRawContent URIErrorKind = "raw-content"
+	// URIBlobDirectory is a GitHub 'blob' URI which points at a directory.
+	URIBlobDirectory URIErrorKind = "blob-directory"
+	// URIMissingRef is a GitHub URI which points at a path without a branch,
+	// tag, or commit.
+	URIMissingRef URIErrorKind = "missing-ref"
+)
+
+// InvalidURIError is returned when a registry URI is invalid. It suggests a
+// corrected URI for common mistakes.
+type InvalidURIError struct {
+	URI        string
+	Kind       URIErrorKind
+	Reason     string
+	Suggestion string
+}
+
+func (e *InvalidURIError) Error() string {
+	msg := fmt.Sprintf("invalid registry URI %q: %s", e.URI, e.Reason)
+	if e.Suggestion != "" {
+		msg += fmt.Sprintf("; did you mean %q?", e.Suggestion)
+	}
+
+	return msg
+}
+
+// DetectProtocol detects the protocol of a registry URI, and validates the
+// URI against the protocol. It returns the URI in the form the protocol
+// expects.
+func DetectProtocol(uri string) (Protocol, string, error) {
+	uri = strings.TrimSpace(uri)
+	if protocol, ok := detectCustomProtocol(uri); ok {
+		return protocol, uri, nil
+	}
+
+	host := uriHost(uri)
+
+	switch {
+	case uri == "":
+		return ProtocolInvalid, "", &InvalidURIError{URI: uri, Kind: URIMalformed, Reason: "URI is empty"}
+	case strings.HasPrefix(uri, "file://"):
+		u, err := url.Parse(uri)
+		if err != nil {
+			return ProtocolInvalid, "", &InvalidURIError{URI: uri, Kind: URIMalformed, Reason: err.Error()}
+		}
+		return ProtocolFilesystem, u.Path, nil
+	case isTarball(uri):
+		return ProtocolInvalid, "", &InvalidURIError{
+			URI:    uri,
+			Kind:   URIUnsupported,
+			Reason: "tarball registries are not supported; extract the archive and add its directory",
+		}
+	case strings.HasPrefix(uri, "/") || strings.HasPrefix(uri, "."):
+		return ProtocolFilesystem, uri, nil
+	case isCloneURL(uri):
+		return ProtocolInvalid, "", cloneURLError(uri)
+	case host == "raw.githubusercontent.com":
+		return ProtocolInvalid, "", rawContentError(uri)
+	case isGitLab(host):
+		return ProtocolInvalid, "", &InvalidURIError{
+			URI:    uri,
+			Kind:   URIUnsupported,
+			Reason: "GitLab registries are not supported; clone the repository and add the registry's directory",
+		}
+	case strings.HasPrefix(host, "github."):
+		if _, err := parseGitHubURI(uri); err != nil {
+			return ProtocolInvalid, "", err
+		}
+		return ProtocolGitHub, uri, nil
+	}
+
+	return detectHelm(uri)
+}
+
+// detectHelm validates a Helm chart repository URI.
+func detectHelm(uri string) (Protocol, string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return ProtocolInvalid, "", &InvalidURIError{URI: uri, Kind: URIMalformed, Reason: err.Error()}
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+	case "":
+		e := &InvalidURIError{
+			URI:    uri,
+			Kind:   URIMissingScheme,
+			Reason: "Helm chart repository URIs must begin with http:// or https://",
+		}
+
+		// A first segment without a dot is more likely a directory than a host.
+		if first := strings.Split(uri, "/")[0]; strings.Contains(first, ".") {
+			e.Suggestion = "https://" + uri
+		} else {
+			e.Reason = "local registry paths must begin with '/' or '.'"
+			e.Suggestion = "./" + uri
+		}
+		return ProtocolInvalid, "", e
+	default:
+		return ProtocolInvalid, "", &InvalidURIError{
+			URI:    uri,
+			Kind:   URIUnsupported,
+			Reason: fmt.Sprintf("scheme %q is not supported", u.Scheme),
+		}
+	}
+
+	if u.Host == "" {
+		return ProtocolInvalid, "", &InvalidURIError{URI: uri, Kind: URIMalformed, Reason: "URI has no host"}
+	}
+
+	return ProtocolHelm, uri, nil
+}
+
+// uriHost returns the host of a URI which may not have a scheme, without a
+// leading 'www.'.
+func uriHost(uri string) string {
+	if i := strings.Index(uri, "://"); i != -1 {
+		uri = uri[i+3:]
+	}
+	if i := strings.Index(uri, "@"); i != -1 && i < strings.IndexAny(uri+"/", "/:") {
+		uri = uri[i+1:]
+	}
+
+	host := uri
+	if i := strings.IndexAny(host, "/:?"); i != -1 {
+		host = host[:i]
+	}
+
+	return strings.TrimPrefix(strings.ToLower(host), "www.")
+}
+
+// uriPath returns the path of a URI which may not have a scheme.
+func uriPath(uri string) string {
+	if i := strings.Index(uri, "://"); i != -1 {
+		uri = uri[i+3:]
+	}
+	if i := strings.Index(uri, "?"); i != -1 {
+		uri = uri[:i]
+	}
+	if i := strings.IndexAny(uri, "/:"); i != -1 {
+		return strings.Trim(uri[i+1:], "/")
+	}
+
+	return ""
+}
+
+func isTarball(uri string) bool {
+	p := strings.ToLower(strings.SplitN(uri, "?", 2)[0])
+	for _, ext := range []string{".tar", ".tar.gz", ".tgz", ".zip"} {
+		if strings.HasSuffix(p, ext) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isGitLab(host string) bool {
+	return strings.HasPrefix(host, "gitlab.") || strings.Contains(host, ".gitlab.")
+}
+
+func isCloneURL(uri string) bool {
+	return strings.HasPrefix(uri, "git@") ||
+		strings.HasPrefix(uri, "git://") ||
+		strings.HasPrefix(uri, "ssh://") ||
+		strings.HasSuffix(strings.TrimSuffix(uri, "/"), ".git")
+}
+
+// cloneURLError suggests the repository URI for a git clone URL, e.g.
+// 'git@github.com:ksonnet/parts.git' is 'github.com/ksonnet/parts'.
+func cloneURLError(uri string) error {
+	e := &InvalidURIError{
+		URI:    uri,
+		Kind:   URICloneURL,
+		Reason: "git clone URLs are not registry URIs",
+	}
+
+	host := uriHost(uri)
+	switch {
+	case strings.HasPrefix(host, "github."):
+		repoPath := strings.TrimSuffix(uriPath(uri), ".git")
+		e.Suggestion = host + "/" + repoPath
+	case isGitLab(host):
+		e.Kind = URIUnsupported
+		e.Reason = "GitLab registries are not supported; clone the repository and add the registry's directory"
+	}
+
+	return e
+}
+
+// rawContentError suggests the GitHub URI for a raw file URL, e.g.
+// 'raw.githubusercontent.com/ksonnet/parts/master/incubator/registry.yaml'
+// is 'github.com/ksonnet/parts/tree/master/incubator'.
+func rawContentError(uri string) error {
+	e := &InvalidURIError{
+		URI:    uri,
+		Kind:   URIRawContent,
+		Reason: "raw file URLs are not registry URIs",
+	}
+
+	parts := strings.Split(uriPath(uri), "/")
+	if len(parts) < 3 {
+		return e
+	}
+
+	dir := strings.Join(parts[3:], "/")
+	if path.Base(dir) == registryYAMLFile {
+		dir = path.Dir(dir)
+	}
+
+	suggestion := fmt.Sprintf("github.com/%s/%s/tree/%s", parts[0], parts[1], parts[2])
+	if dir != "" && dir != "." {
+		suggestion += "/" + dir
+	}
+	e.Suggestion = suggestion
+
+	return e
+}
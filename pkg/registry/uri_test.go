@@ -0,0 +1,86 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectProtocol(t *testing.T) {
+	cases := []struct {
+		name        string
+		uri         string
+		protocol    Protocol
+		expectedURI string
+		errKind     URIErrorKind
+		suggestion  string
+	}{
+		{name: "github", uri: "github.com/ksonnet/parts/tree/master/incubator", protocol: ProtocolGitHub, expectedURI: "github.com/ksonnet/parts/tree/master/incubator"},
+		{name: "github with scheme", uri: "https://github.com/ksonnet/parts", protocol: ProtocolGitHub, expectedURI: "https://github.com/ksonnet/parts"},
+		{name: "github release", uri: "github.com/ksonnet/parts/releases/tag/v1.2.3", protocol: ProtocolGitHub, expectedURI: "github.com/ksonnet/parts/releases/tag/v1.2.3"},
+		{name: "github enterprise", uri: "https://github.example.com/api/v3/repos/foo/bar?ref=master", protocol: ProtocolGitHub, expectedURI: "https://github.example.com/api/v3/repos/foo/bar?ref=master"},
+		{name: "helm", uri: "https://kubernetes-charts.storage.googleapis.com", protocol: ProtocolHelm, expectedURI: "https://kubernetes-charts.storage.googleapis.com"},
+		{name: "helm index", uri: "https://charts.example.com/index.yaml", protocol: ProtocolHelm, expectedURI: "https://charts.example.com/index.yaml"},
+		{name: "absolute path", uri: "/registry", protocol: ProtocolFilesystem, expectedURI: "/registry"},
+		{name: "relative path", uri: "./registry", protocol: ProtocolFilesystem, expectedURI: "./registry"},
+		{name: "file URL", uri: "file:///registry", protocol: ProtocolFilesystem, expectedURI: "/registry"},
+		{name: "empty", uri: " ", errKind: URIMalformed},
+		{name: "tarball", uri: "https://example.com/registry.tar.gz", errKind: URIUnsupported},
+		{name: "gitlab", uri: "https://gitlab.com/foo/bar", errKind: URIUnsupported},
+		{name: "gitlab clone URL", uri: "git@gitlab.com:foo/bar.git", errKind: URIUnsupported},
+		{name: "ssh clone URL", uri: "git@github.com:ksonnet/parts.git", errKind: URICloneURL, suggestion: "github.com/ksonnet/parts"},
+		{name: "https clone URL", uri: "https://github.com/ksonnet/parts.git", errKind: URICloneURL, suggestion: "github.com/ksonnet/parts"},
+		{name: "raw file URL", uri: "https://raw.githubusercontent.com/ksonnet/parts/master/incubator/registry.yaml", errKind: URIRawContent, suggestion: "github.com/ksonnet/parts/tree/master/incubator"},
+		{name: "github blob directory", uri: "github.com/ksonnet/parts/blob/master/incubator", errKind: URIBlobDirectory, suggestion: "github.com/ksonnet/parts/tree/master/incubator"},
+		{name: "github path without ref", uri: "github.com/ksonnet/parts/incubator", errKind: URIMissingRef, suggestion: "github.com/ksonnet/parts/tree/<default-branch>/incubator"},
+		{name: "github nested path without ref", uri: "github.com/ksonnet/parts/long/path", errKind: URIMissingRef, suggestion: "github.com/ksonnet/parts/tree/<default-branch>/long/path"},
+		{name: "host without scheme", uri: "charts.example.com", errKind: URIMissingScheme, suggestion: "https://charts.example.com"},
+		{name: "path without dot", uri: "registry", errKind: URIMissingScheme, suggestion: "./registry"},
+		{name: "unsupported scheme", uri: "ftp://example.com/registry", errKind: URIUnsupported},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			protocol, uri, err := DetectProtocol(tc.uri)
+			if tc.errKind != "" {
+				require.IsType(t, &InvalidURIError{}, err)
+				uerr := err.(*InvalidURIError)
+				assert.Equal(t, tc.errKind, uerr.Kind)
+				assert.Equal(t, tc.suggestion, uerr.Suggestion)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.protocol, protocol)
+			assert.Equal(t, tc.expectedURI, uri)
+		})
+	}
+}
+
+func TestInvalidURIError(t *testing.T) {
+	err := &InvalidURIError{
+		URI:        "github.com/ksonnet/parts/incubator",
+		Kind:       URIMissingRef,
+		Reason:     "paths in a repository must begin with 'tree/{branch}'",
+		Suggestion: "github.com/ksonnet/parts/tree/master/incubator",
+	}
+
+	expected := `invalid registry URI "github.com/ksonnet/parts/incubator": paths in a repository must begin with 'tree/{branch}'; did you mean "github.com/ksonnet/parts/tree/master/incubator"?`
+	assert.Equal(t, expected, err.Error())
+}
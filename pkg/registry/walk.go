@@ -0,0 +1,106 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// ErrStopWalk can be returned by a WalkFunc to stop walking a registry
+// without Walk returning an error.
+var ErrStopWalk = errors.New("stop walk")
+
+// Entry describes a part found while walking a registry.
+type Entry struct {
+	// Name is the part's name.
+	Name string
+	// Version is the part's version.
+	Version string
+	// Description describes the part.
+	Description string
+	// Prototypes are the names of the prototypes the part contains.
+	Prototypes []string
+}
+
+// WalkFunc is called for each part found while walking a registry. Returning
+// an error stops the walk.
+type WalkFunc func(entry Entry) error
+
+// Walker streams the parts in a registry.
+type Walker interface {
+	// Walk calls fn for each part in the registry in name order. Each part's
+	// metadata is resolved as it is visited rather than up front.
+	Walk(ctx context.Context, fn WalkFunc) error
+}
+
+// walkSource is the subset of a registry needed to walk it.
+type walkSource interface {
+	SpecFetcher
+	LibrarySpecResolver
+}
+
+// walk implements Walker for a registry.
+func walk(ctx context.Context, r walkSource, fn WalkFunc) error {
+	spec, err := r.FetchRegistrySpec()
+	if err != nil {
+		return errors.Wrap(err, "fetching registry spec")
+	}
+
+	var names []string
+	for name := range spec.Libraries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		lib := spec.Libraries[name]
+		var version string
+		if lib != nil {
+			version = lib.Version
+		}
+
+		partSpec, err := r.ResolveLibrarySpec(name, version)
+		if err != nil {
+			return errors.Wrapf(err, "resolving part %s", name)
+		}
+
+		entry := Entry{
+			Name:        name,
+			Version:     version,
+			Description: partSpec.Description,
+			Prototypes:  partSpec.Prototypes,
+		}
+		if entry.Version == "" {
+			entry.Version = partSpec.Version
+		}
+
+		if err = fn(entry); err != nil {
+			if err == ErrStopWalk {
+				return nil
+			}
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/parts"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWalkSource is a registry with parts for walking.
+type fakeWalkSource struct {
+	parts    map[string]*parts.Spec
+	resolved []string
+}
+
+func (f *fakeWalkSource) FetchRegistrySpec() (*Spec, error) {
+	spec := &Spec{Libraries: LibraryConfigs{}}
+	for name := range f.parts {
+		spec.Libraries[name] = &LibraryConfig{Path: name, Version: "12345"}
+	}
+	return spec, nil
+}
+
+func (f *fakeWalkSource) ResolveLibrarySpec(libID, libRefSpec string) (*parts.Spec, error) {
+	f.resolved = append(f.resolved, libID)
+	spec, ok := f.parts[libID]
+	if !ok {
+		return nil, errors.Errorf("part %s not found", libID)
+	}
+	return spec, nil
+}
+
+func newFakeWalkSource() *fakeWalkSource {
+	return &fakeWalkSource{
+		parts: map[string]*parts.Spec{
+			"redis":  {Name: "redis", Description: "redis", Prototypes: []string{"io.ksonnet.pkg.redis"}},
+			"apache": {Name: "apache", Description: "apache", Prototypes: []string{"io.ksonnet.pkg.apache-simple"}},
+			"nginx":  {Name: "nginx", Description: "nginx"},
+		},
+	}
+}
+
+func Test_walk(t *testing.T) {
+	src := newFakeWalkSource()
+
+	var entries []Entry
+	err := walk(context.Background(), src, func(entry Entry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	require.NoError(t, err)
+
+	expected := []Entry{
+		{Name: "apache", Version: "12345", Description: "apache", Prototypes: []string{"io.ksonnet.pkg.apache-simple"}},
+		{Name: "nginx", Version: "12345", Description: "nginx"},
+		{Name: "redis", Version: "12345", Description: "redis", Prototypes: []string{"io.ksonnet.pkg.redis"}},
+	}
+	assert.Equal(t, expected, entries)
+}
+
+func Test_walk_stop(t *testing.T) {
+	src := newFakeWalkSource()
+
+	var names []string
+	err := walk(context.Background(), src, func(entry Entry) error {
+		names = append(names, entry.Name)
+		return ErrStopWalk
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"apache"}, names)
+	// Parts after the walk stopped are never resolved.
+	assert.Equal(t, []string{"apache"}, src.resolved)
+}
+
+func Test_walk_error(t *testing.T) {
+	src := newFakeWalkSource()
+
+	walkErr := errors.New("failed")
+	err := walk(context.Background(), src, func(entry Entry) error {
+		return walkErr
+	})
+	assert.Equal(t, walkErr, err)
+}
+
+func Test_walk_canceled(t *testing.T) {
+	src := newFakeWalkSource()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var names []string
+	err := walk(ctx, src, func(entry Entry) error {
+		names = append(names, entry.Name)
+		cancel()
+		return nil
+	})
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, []string{"apache"}, names)
+}
+
+func TestFs_Walk(t *testing.T) {
+	withRFS(t, false, func(rfs *Fs, appMock *mocks.App, fs afero.Fs) {
+		var entries []Entry
+		err := rfs.Walk(context.Background(), func(entry Entry) error {
+			entries = append(entries, entry)
+			return nil
+		})
+		require.NoError(t, err)
+
+		require.Len(t, entries, 1)
+		assert.Equal(t, "apache", entries[0].Name)
+		assert.Equal(t, "part description", entries[0].Description)
+	})
+}
@@ -0,0 +1,412 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package sdk is a Go API for tools which embed ksonnet. It runs the same
+// actions as the ks commands, configured with typed options.
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/cluster"
+	"github.com/ksonnet/ksonnet/pkg/pipeline"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type actionFn func(map[string]interface{}) error
+
+// Options are options for loading an app.
+type Options struct {
+	// Fs is the filesystem the app is in. It defaults to the OS filesystem.
+	Fs afero.Fs
+	// Dir is the app's root directory, or a directory in it. It defaults to
+	// the working directory.
+	Dir string
+	// TLSSkipVerify skips the verification of registries' TLS certificates.
+	TLSSkipVerify bool
+	// ClientConfig is the configuration for connecting to clusters. It
+	// defaults to the kubeconfig of the environment.
+	ClientConfig *client.Config
+	// Untrusted evaluates the app without remote imports or generator
+	// files. See app.Untrusted.
+	Untrusted bool
+}
+
+// Client runs actions on an app.
+type Client struct {
+	app           app.App
+	clientConfig  *client.Config
+	tlsSkipVerify bool
+
+	objectsFn     func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error)
+	applyFn       actionFn
+	diffFn        actionFn
+	paramDeleteFn actionFn
+	paramSetFn    actionFn
+	showFn        actionFn
+	statusFn      actionFn
+}
+
+// Load loads an app.
+func Load(opts Options) (*Client, error) {
+	fs := opts.Fs
+	if fs == nil {
+		fs = afero.NewOsFs()
+	}
+
+	dir := opts.Dir
+	if dir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		dir = wd
+	}
+
+	a, err := app.Load(fs, app.NewHTTPClient(opts.TLSSkipVerify), dir, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading app")
+	}
+
+	return New(a, opts), nil
+}
+
+// New creates an instance of Client for an app which is already loaded.
+// Options.Fs and Options.Dir are not used.
+func New(a app.App, opts Options) *Client {
+	if opts.Untrusted {
+		a = app.Untrusted(a)
+	}
+
+	clientConfig := opts.ClientConfig
+	if clientConfig == nil {
+		clientConfig = client.NewDefaultClientConfig(a)
+	}
+
+	return &Client{
+		app:           a,
+		clientConfig:  clientConfig,
+		tlsSkipVerify: opts.TLSSkipVerify,
+
+		objectsFn: func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+			return pipeline.New(a, envName).Objects(componentNames)
+		},
+		applyFn:       actions.RunApply,
+		diffFn:        actions.RunDiff,
+		paramDeleteFn: actions.RunParamDelete,
+		paramSetFn:    actions.RunParamSet,
+		showFn:        actions.RunShow,
+		statusFn:      actions.RunStatus,
+	}
+}
+
+// App returns the app.
+func (c *Client) App() app.App {
+	return c.app
+}
+
+// RenderOptions are options for Render.
+type RenderOptions struct {
+	// EnvName is the environment. It defaults to the current environment.
+	EnvName string
+	// ComponentNames limits the objects to these components.
+	ComponentNames []string
+}
+
+// Render renders the objects of an environment, in the order they are
+// applied.
+func (c *Client) Render(opts RenderOptions) ([]*unstructured.Unstructured, error) {
+	envName, err := c.envName(opts.EnvName)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := c.objectsFn(c.app, envName, opts.ComponentNames)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster.UnstructuredSlice(objects).Sort()
+	return objects, nil
+}
+
+// ShowOptions are options for Show.
+type ShowOptions struct {
+	// EnvName is the environment. It defaults to the current environment.
+	EnvName string
+	// ComponentNames limits the objects to these components.
+	ComponentNames []string
+	// Format is yaml or json. It defaults to yaml.
+	Format string
+	// Out is where the objects are written. It defaults to stdout.
+	Out io.Writer
+}
+
+// Show writes the objects of an environment, like `ks show`.
+func (c *Client) Show(opts ShowOptions) error {
+	format := opts.Format
+	if format == "" {
+		format = "yaml"
+	}
+
+	m := map[string]interface{}{
+		actions.OptionApp:            c.app,
+		actions.OptionComponentNames: opts.ComponentNames,
+		actions.OptionEnvName:        opts.EnvName,
+		actions.OptionFormat:         format,
+	}
+	if opts.Out != nil {
+		m[actions.OptionOut] = opts.Out
+	}
+
+	return c.showFn(m)
+}
+
+// DiffOptions are options for Diff.
+type DiffOptions struct {
+	// Src1 is the first location, e.g. local:default.
+	Src1 string
+	// Src2 is the second location. It defaults to the remote of Src1's
+	// environment.
+	Src2 string
+	// ComponentNames limits the objects to these components.
+	ComponentNames []string
+	// Strategy is the diff strategy. It defaults to client.
+	Strategy string
+	// Output is text, semantic, or json. It defaults to text.
+	Output string
+	// Program is an external program used to compare manifests.
+	Program string
+	// MaxUnavailableClusters is the number of clusters of a multi-cluster
+	// environment which may fail.
+	MaxUnavailableClusters int
+	// Out is where the differences are written. It defaults to stdout.
+	Out io.Writer
+}
+
+// Diff compares two locations, like `ks diff`. It returns true if there are
+// differences.
+func (c *Client) Diff(opts DiffOptions) (bool, error) {
+	m := map[string]interface{}{
+		actions.OptionApp:                    c.app,
+		actions.OptionClientConfig:           c.clientConfig,
+		actions.OptionSrc1:                   opts.Src1,
+		actions.OptionComponentNames:         opts.ComponentNames,
+		actions.OptionDiffStrategy:           opts.Strategy,
+		actions.OptionOutput:                 opts.Output,
+		actions.OptionDiffProgram:            opts.Program,
+		actions.OptionMaxUnavailableClusters: opts.MaxUnavailableClusters,
+	}
+	if opts.Src2 != "" {
+		m[actions.OptionSrc2] = opts.Src2
+	}
+	if opts.Out != nil {
+		m[actions.OptionOut] = opts.Out
+	}
+
+	err := c.diffFn(m)
+	if err == actions.ErrDiffFound {
+		return true, nil
+	}
+	if exitErr, ok := err.(*actions.ExitError); ok {
+		return false, exitErr.Err
+	}
+
+	return false, err
+}
+
+// ApplyOptions are options for Apply.
+type ApplyOptions struct {
+	// EnvName is the environment. It defaults to the current environment.
+	EnvName string
+	// ComponentNames limits the objects to these components.
+	ComponentNames []string
+	// SkipCreate only updates objects which exist.
+	SkipCreate bool
+	// DryRun does not change the cluster.
+	DryRun bool
+	// ServerDryRun submits objects with dryRun=All.
+	ServerDryRun bool
+	// GcTag tags objects, and garbage collects tagged objects which are no
+	// longer in the environment.
+	GcTag string
+	// GcLabels labels objects with their application and environment, and
+	// garbage collects objects by those labels.
+	GcLabels bool
+	// SkipGc skips garbage collection.
+	SkipGc bool
+	// Wait waits for objects to become ready.
+	Wait bool
+	// WaitTimeout is how long to wait for objects to become ready. It
+	// defaults to cluster.DefaultWaitTimeout.
+	WaitTimeout time.Duration
+	// MaxUnavailableClusters is the number of clusters of a multi-cluster
+	// environment which may fail.
+	MaxUnavailableClusters int
+	// DisableCommandHooks refuses to run the environment's local command
+	// hooks, for apps which are not trusted to run commands.
+	DisableCommandHooks bool
+}
+
+// Apply applies the objects of an environment to its clusters, like
+// `ks apply`.
+func (c *Client) Apply(opts ApplyOptions) error {
+	return c.applyFn(c.applyOptions(opts))
+}
+
+// ApplyWithResults applies the objects of an environment to its clusters,
+// and returns what happened to each object, one result per cluster. If the
+// apply fails, the results list the objects which were handled before the
+// failure. Server dry runs are not supported.
+func (c *Client) ApplyWithResults(opts ApplyOptions) ([]*cluster.ApplyResult, error) {
+	var buf bytes.Buffer
+
+	m := c.applyOptions(opts)
+	m[actions.OptionOutput] = actions.OutputJSON
+	m[actions.OptionOut] = &buf
+
+	err := c.applyFn(m)
+	if buf.Len() == 0 {
+		return nil, err
+	}
+
+	var results []*cluster.ApplyResult
+	if decodeErr := json.Unmarshal(buf.Bytes(), &results); decodeErr != nil {
+		return nil, errors.Wrap(decodeErr, "decoding apply results")
+	}
+
+	return results, err
+}
+
+func (c *Client) applyOptions(opts ApplyOptions) map[string]interface{} {
+	waitTimeout := opts.WaitTimeout
+	if waitTimeout == 0 {
+		waitTimeout = cluster.DefaultWaitTimeout
+	}
+
+	// Garbage collection can not be confirmed with a prompt, so setting GcTag
+	// or GcLabels confirms it.
+	return map[string]interface{}{
+		actions.OptionApp:                    c.app,
+		actions.OptionAssumeYes:              true,
+		actions.OptionClientConfig:           c.clientConfig,
+		actions.OptionComponentNames:         opts.ComponentNames,
+		actions.OptionCreate:                 !opts.SkipCreate,
+		actions.OptionDisableCommandHooks:    opts.DisableCommandHooks,
+		actions.OptionDryRun:                 opts.DryRun,
+		actions.OptionEnvName:                opts.EnvName,
+		actions.OptionGcTag:                  opts.GcTag,
+		actions.OptionGcLabels:               opts.GcLabels,
+		actions.OptionMaxUnavailableClusters: opts.MaxUnavailableClusters,
+		actions.OptionServerDryRun:           opts.ServerDryRun,
+		actions.OptionSkipGc:                 opts.SkipGc,
+		actions.OptionWait:                   opts.Wait,
+		actions.OptionWaitTimeout:            waitTimeout,
+	}
+}
+
+// StatusOptions are options for Status.
+type StatusOptions struct {
+	// EnvName is the environment. It defaults to the current environment.
+	EnvName string
+	// ComponentNames limits the objects to these components.
+	ComponentNames []string
+	// Output is table or json. It defaults to table.
+	Output string
+	// MaxUnavailableClusters is the number of clusters of a multi-cluster
+	// environment which may fail.
+	MaxUnavailableClusters int
+	// Out is where the statuses are written. It defaults to stdout.
+	Out io.Writer
+}
+
+// Status writes the live status of the objects of an environment, like
+// `ks status`.
+func (c *Client) Status(opts StatusOptions) error {
+	m := map[string]interface{}{
+		actions.OptionApp:                    c.app,
+		actions.OptionClientConfig:           c.clientConfig,
+		actions.OptionComponentNames:         opts.ComponentNames,
+		actions.OptionEnvName:                opts.EnvName,
+		actions.OptionMaxUnavailableClusters: opts.MaxUnavailableClusters,
+		actions.OptionOutput:                 opts.Output,
+	}
+	if opts.Out != nil {
+		m[actions.OptionOut] = opts.Out
+	}
+
+	return c.statusFn(m)
+}
+
+// ParamOptions are options for SetParam and DeleteParam.
+type ParamOptions struct {
+	// Component is the component the parameter belongs to. It is blank for
+	// global parameters of an environment.
+	Component string
+	// Path is the parameter's path, e.g. image or metadata.labels.app.
+	Path string
+	// EnvName sets the parameter for an environment only.
+	EnvName string
+	// Global sets a global parameter of the app. It can not be used with
+	// EnvName.
+	Global bool
+}
+
+// SetParam sets a parameter, like `ks param set`. The value is jsonnet, e.g.
+// "3" or "\"nginx\"".
+func (c *Client) SetParam(opts ParamOptions, value string) error {
+	m := c.paramOptions(opts)
+	m[actions.OptionValue] = value
+
+	return c.paramSetFn(m)
+}
+
+// DeleteParam deletes a parameter, like `ks param delete`.
+func (c *Client) DeleteParam(opts ParamOptions) error {
+	return c.paramDeleteFn(c.paramOptions(opts))
+}
+
+func (c *Client) paramOptions(opts ParamOptions) map[string]interface{} {
+	return map[string]interface{}{
+		actions.OptionApp:     c.app,
+		actions.OptionName:    opts.Component,
+		actions.OptionPath:    opts.Path,
+		actions.OptionEnvName: opts.EnvName,
+		actions.OptionGlobal:  opts.Global,
+	}
+}
+
+// envName returns the name of an environment, defaulting to the current
+// environment.
+func (c *Client) envName(name string) (string, error) {
+	if name == "" {
+		name = c.app.CurrentEnvironment()
+	}
+
+	if name == "" {
+		return "", errors.New("environment is not set; set one in the options or as the current environment")
+	}
+
+	return name, nil
+}
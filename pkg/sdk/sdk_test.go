@@ -0,0 +1,237 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package sdk
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/actions"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	amocks "github.com/ksonnet/ksonnet/pkg/app/mocks"
+	"github.com/ksonnet/ksonnet/pkg/client"
+	"github.com/ksonnet/ksonnet/pkg/cluster"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newTestClient() (*Client, *amocks.App, *client.Config) {
+	appMock := &amocks.App{}
+	clientConfig := &client.Config{}
+
+	return New(appMock, Options{ClientConfig: clientConfig}), appMock, clientConfig
+}
+
+// captureOptions returns an action which records its options.
+func captureOptions(m *map[string]interface{}, err error) actionFn {
+	return func(in map[string]interface{}) error {
+		*m = in
+		return err
+	}
+}
+
+func TestClient_Render(t *testing.T) {
+	c, appMock, _ := newTestClient()
+	appMock.On("CurrentEnvironment").Return("default")
+
+	object := func(kind string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       kind,
+			"metadata":   map[string]interface{}{"name": "guestbook"},
+		}}
+	}
+
+	c.objectsFn = func(a app.App, envName string, componentNames []string) ([]*unstructured.Unstructured, error) {
+		assert.Equal(t, "default", envName)
+		assert.Equal(t, []string{"guestbook"}, componentNames)
+		return []*unstructured.Unstructured{object("Service"), object("Namespace")}, nil
+	}
+
+	objects, err := c.Render(RenderOptions{ComponentNames: []string{"guestbook"}})
+	require.NoError(t, err)
+
+	require.Len(t, objects, 2)
+	assert.Equal(t, "Namespace", objects[0].GetKind())
+	assert.Equal(t, "Service", objects[1].GetKind())
+}
+
+func TestClient_Render_requires_env(t *testing.T) {
+	c, appMock, _ := newTestClient()
+	appMock.On("CurrentEnvironment").Return("")
+
+	_, err := c.Render(RenderOptions{})
+	require.Error(t, err)
+}
+
+func TestClient_Show(t *testing.T) {
+	c, appMock, _ := newTestClient()
+
+	var m map[string]interface{}
+	c.showFn = captureOptions(&m, nil)
+
+	var buf bytes.Buffer
+	require.NoError(t, c.Show(ShowOptions{EnvName: "default", Out: &buf}))
+
+	expected := map[string]interface{}{
+		actions.OptionApp:            appMock,
+		actions.OptionComponentNames: []string(nil),
+		actions.OptionEnvName:        "default",
+		actions.OptionFormat:         "yaml",
+		actions.OptionOut:            &buf,
+	}
+	assert.Equal(t, expected, m)
+}
+
+func TestClient_Diff(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		expected bool
+		isErr    bool
+	}{
+		{name: "clean"},
+		{name: "differences", err: actions.ErrDiffFound, expected: true},
+		{name: "failure", err: &actions.ExitError{Code: actions.DiffExitCodeError, Err: errors.New("failed")}, isErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, appMock, clientConfig := newTestClient()
+
+			var m map[string]interface{}
+			c.diffFn = captureOptions(&m, tc.err)
+
+			found, err := c.Diff(DiffOptions{Src1: "local:default"})
+			if tc.isErr {
+				require.Error(t, err)
+				assert.Equal(t, "failed", err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, found)
+
+			assert.Equal(t, appMock, m[actions.OptionApp])
+			assert.Equal(t, clientConfig, m[actions.OptionClientConfig])
+			assert.Equal(t, "local:default", m[actions.OptionSrc1])
+			assert.NotContains(t, m, actions.OptionSrc2)
+		})
+	}
+}
+
+func TestClient_Apply(t *testing.T) {
+	c, appMock, clientConfig := newTestClient()
+
+	var m map[string]interface{}
+	c.applyFn = captureOptions(&m, nil)
+
+	require.NoError(t, c.Apply(ApplyOptions{EnvName: "default", GcTag: "tag"}))
+
+	expected := map[string]interface{}{
+		actions.OptionApp:                    appMock,
+		actions.OptionAssumeYes:              true,
+		actions.OptionClientConfig:           clientConfig,
+		actions.OptionComponentNames:         []string(nil),
+		actions.OptionCreate:                 true,
+		actions.OptionDisableCommandHooks:    false,
+		actions.OptionDryRun:                 false,
+		actions.OptionEnvName:                "default",
+		actions.OptionGcTag:                  "tag",
+		actions.OptionGcLabels:               false,
+		actions.OptionMaxUnavailableClusters: 0,
+		actions.OptionServerDryRun:           false,
+		actions.OptionSkipGc:                 false,
+		actions.OptionWait:                   false,
+		actions.OptionWaitTimeout:            cluster.DefaultWaitTimeout,
+	}
+	assert.Equal(t, expected, m)
+}
+
+func TestClient_ApplyWithResults(t *testing.T) {
+	c, _, _ := newTestClient()
+
+	var m map[string]interface{}
+	c.applyFn = func(in map[string]interface{}) error {
+		m = in
+		_, err := io.WriteString(in[actions.OptionOut].(io.Writer),
+			`[{"environment":"default","objects":[{"apiVersion":"v1","kind":"Service","name":"guestbook-ui","action":"failed","message":"Invalid: bad port"}]}]`)
+		require.NoError(t, err)
+		return errors.New("apply failed")
+	}
+
+	results, err := c.ApplyWithResults(ApplyOptions{EnvName: "default"})
+	require.Error(t, err)
+
+	assert.Equal(t, actions.OutputJSON, m[actions.OptionOutput])
+
+	expected := []*cluster.ApplyResult{
+		{
+			Environment: "default",
+			Objects: []cluster.ObjectResult{
+				{APIVersion: "v1", Kind: "Service", Name: "guestbook-ui", Action: cluster.ApplyFailed, Message: "Invalid: bad port"},
+			},
+		},
+	}
+	assert.Equal(t, expected, results)
+}
+
+func TestClient_Status(t *testing.T) {
+	c, appMock, clientConfig := newTestClient()
+
+	var m map[string]interface{}
+	c.statusFn = captureOptions(&m, nil)
+
+	var buf bytes.Buffer
+	require.NoError(t, c.Status(StatusOptions{EnvName: "default", Output: "json", Out: &buf}))
+
+	expected := map[string]interface{}{
+		actions.OptionApp:                    appMock,
+		actions.OptionClientConfig:           clientConfig,
+		actions.OptionComponentNames:         []string(nil),
+		actions.OptionEnvName:                "default",
+		actions.OptionMaxUnavailableClusters: 0,
+		actions.OptionOutput:                 "json",
+		actions.OptionOut:                    &buf,
+	}
+	assert.Equal(t, expected, m)
+}
+
+func TestClient_params(t *testing.T) {
+	c, appMock, _ := newTestClient()
+
+	var set, deleted map[string]interface{}
+	c.paramSetFn = captureOptions(&set, nil)
+	c.paramDeleteFn = captureOptions(&deleted, nil)
+
+	opts := ParamOptions{Component: "guestbook", Path: "replicas", EnvName: "default"}
+	require.NoError(t, c.SetParam(opts, "3"))
+	require.NoError(t, c.DeleteParam(opts))
+
+	expected := map[string]interface{}{
+		actions.OptionApp:     appMock,
+		actions.OptionName:    "guestbook",
+		actions.OptionPath:    "replicas",
+		actions.OptionEnvName: "default",
+		actions.OptionGlobal:  false,
+	}
+	assert.Equal(t, expected, deleted)
+
+	expected[actions.OptionValue] = "3"
+	assert.Equal(t, expected, set)
+}
@@ -0,0 +1,392 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package server serves renders, diffs, applies, and statuses of ksonnet apps
+// over HTTP, so tools can drive ksonnet without running ks for each
+// operation.
+package server
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/ksonnet/ksonnet/pkg/jb"
+	"github.com/ksonnet/ksonnet/pkg/sdk"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Request is the body of a request.
+type Request struct {
+	// App is where the app is.
+	App AppSource `json:"app"`
+	// EnvName is the environment. It defaults to the app's current
+	// environment.
+	EnvName string `json:"env,omitempty"`
+	// ComponentNames limits the objects to these components.
+	ComponentNames []string `json:"components,omitempty"`
+	// Output is the format of diffs and statuses.
+	Output string `json:"output,omitempty"`
+	// DryRun applies without changing the cluster.
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// AppSource is the location of an app. Exactly one of its fields is set.
+type AppSource struct {
+	// Dir is the app's directory, relative to the server's root.
+	Dir string `json:"dir,omitempty"`
+	// Git is a git repository the app is checked out from for the request.
+	Git *GitSource `json:"git,omitempty"`
+}
+
+// GitSource is an app in a git repository.
+type GitSource struct {
+	Remote string `json:"remote"`
+	// Ref is a branch, tag, or commit. It defaults to the default branch.
+	Ref string `json:"ref,omitempty"`
+	// Subdir is the app's directory in the repository.
+	Subdir string `json:"subdir,omitempty"`
+}
+
+// Response is the body of a response.
+type Response struct {
+	// Commit is the commit a git app was checked out at.
+	Commit string `json:"commit,omitempty"`
+	// Objects are the rendered objects.
+	Objects []*unstructured.Unstructured `json:"objects,omitempty"`
+	// Differences is set if a diff found differences.
+	Differences bool `json:"differences,omitempty"`
+	// Output is the output of the operation.
+	Output string `json:"output,omitempty"`
+	// Error is the error the operation failed with.
+	Error string `json:"error,omitempty"`
+}
+
+// runner runs operations on an app. It is implemented by sdk.Client.
+type runner interface {
+	Render(sdk.RenderOptions) ([]*unstructured.Unstructured, error)
+	Diff(sdk.DiffOptions) (bool, error)
+	Apply(sdk.ApplyOptions) error
+	Status(sdk.StatusOptions) error
+}
+
+type operation func(r runner, req Request, resp *Response) error
+
+// Opt is an option for configuring Server.
+type Opt func(*Server)
+
+// Server serves operations on apps.
+type Server struct {
+	root string
+
+	// mu serializes operations, since rendering uses process-wide state.
+	mu sync.Mutex
+
+	// token is the bearer token requests must have. Requests are not
+	// authenticated if it is empty.
+	token string
+	// gitRemotes are the remotes git apps can be checked out from. Git apps
+	// are not served if there are none.
+	gitRemotes []string
+
+	loadFn  func(dir string, untrusted bool) (runner, error)
+	fetchFn jb.GitFetcher
+}
+
+// WithToken requires requests to have an "Authorization: Bearer <token>"
+// header.
+func WithToken(token string) Opt {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// WithGitRemotes allows git apps to be checked out from remotes.
+func WithGitRemotes(remotes ...string) Opt {
+	return func(s *Server) {
+		s.gitRemotes = append(s.gitRemotes, remotes...)
+	}
+}
+
+// New creates an instance of Server. Apps in directories are found in root.
+func New(root string, opts ...Opt) *Server {
+	s := &Server{
+		root: filepath.Clean(root),
+		loadFn: func(dir string, untrusted bool) (runner, error) {
+			return sdk.Load(sdk.Options{Dir: dir, Untrusted: untrusted})
+		},
+		fetchFn: jb.FetchGit,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Handler returns the server's HTTP handler.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.Handle("/v1/render", s.handle(render))
+	mux.Handle("/v1/diff", s.handle(diff))
+	mux.Handle("/v1/apply", s.handle(apply))
+	mux.Handle("/v1/status", s.handle(status))
+
+	return mux
+}
+
+// Serve serves on addr until stopCh is closed.
+func (s *Server) Serve(addr string, stopCh <-chan struct{}) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return errors.Wrapf(err, "listening on %s", addr)
+	}
+
+	srv := &http.Server{Handler: s.Handler()}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.Serve(l)
+	}()
+
+	log.Infof("serving apps in %s at http://%s", s.root, l.Addr())
+
+	select {
+	case err = <-errCh:
+		return err
+	case <-stopCh:
+		log.Info("shutting down")
+		return srv.Close()
+	}
+}
+
+func (s *Server) handle(op operation) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeResponse(w, http.StatusMethodNotAllowed, &Response{Error: "only POST is allowed"})
+			return
+		}
+
+		if !s.authorized(r) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeResponse(w, http.StatusUnauthorized, &Response{Error: "unauthorized"})
+			return
+		}
+
+		var req Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeResponse(w, http.StatusBadRequest, &Response{Error: "invalid request: " + err.Error()})
+			return
+		}
+
+		resp := &Response{}
+		dir, cleanup, err := s.checkout(req.App, resp)
+		if err != nil {
+			writeResponse(w, http.StatusBadRequest, &Response{Error: err.Error()})
+			return
+		}
+		defer cleanup()
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		// Apps checked out from git are not trusted to fetch URLs or read
+		// the server's files.
+		rn, err := s.loadFn(dir, req.App.Git != nil)
+		if err != nil {
+			writeResponse(w, http.StatusBadRequest, &Response{Error: err.Error()})
+			return
+		}
+
+		if err := op(rn, req, resp); err != nil {
+			log.WithError(err).WithField("path", r.URL.Path).Info("request failed")
+			resp.Error = err.Error()
+			writeResponse(w, http.StatusUnprocessableEntity, resp)
+			return
+		}
+
+		writeResponse(w, http.StatusOK, resp)
+	})
+}
+
+// authorized reports if a request has the server's token.
+func (s *Server) authorized(r *http.Request) bool {
+	if s.token == "" {
+		return true
+	}
+
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(auth, "Bearer ")), []byte(s.token)) == 1
+}
+
+// checkout returns the directory of an app, and a function which removes it
+// after the request. Git apps are checked out for each request, so requests
+// do not share state.
+func (s *Server) checkout(src AppSource, resp *Response) (string, func(), error) {
+	noop := func() {}
+
+	switch {
+	case src.Git != nil && src.Dir != "":
+		return "", noop, errors.New("app can not have both a dir and a git source")
+	case src.Git != nil:
+		if err := s.checkGitSource(src.Git); err != nil {
+			return "", noop, err
+		}
+
+		tmp, err := ioutil.TempDir("", "ks-serve")
+		if err != nil {
+			return "", noop, err
+		}
+		cleanup := func() { os.RemoveAll(tmp) }
+
+		commit, err := s.fetchFn(src.Git.Remote, src.Git.Ref, tmp)
+		if err != nil {
+			cleanup()
+			return "", noop, errors.Wrapf(err, "checking out %s", src.Git.Remote)
+		}
+		resp.Commit = commit
+
+		dir, err := within(tmp, src.Git.Subdir)
+		if err != nil {
+			cleanup()
+			return "", noop, err
+		}
+
+		return dir, cleanup, nil
+	default:
+		dir, err := within(s.root, src.Dir)
+		return dir, noop, err
+	}
+}
+
+// checkGitSource returns an error if a git app can not be checked out. Only
+// allowed remotes are checked out, and remotes and refs can not be mistaken for
+// git options.
+func (s *Server) checkGitSource(src *GitSource) error {
+	if src.Remote == "" {
+		return errors.New("git app does not have a remote")
+	}
+
+	if len(s.gitRemotes) == 0 {
+		return errors.New("git apps are not enabled on this server")
+	}
+
+	if strings.HasPrefix(src.Remote, "-") || strings.HasPrefix(src.Ref, "-") {
+		return errors.New("git remotes and refs can not start with '-'")
+	}
+
+	for _, remote := range s.gitRemotes {
+		if src.Remote == remote {
+			return nil
+		}
+	}
+
+	return errors.Errorf("git remote %q is not allowed on this server", src.Remote)
+}
+
+// within joins a relative path to root, and returns an error if it is outside
+// of root.
+func within(root, rel string) (string, error) {
+	dir := filepath.Join(root, filepath.FromSlash(rel))
+	if dir != root && !strings.HasPrefix(dir, root+string(filepath.Separator)) {
+		return "", errors.Errorf("%q is outside of the server's root", rel)
+	}
+
+	return dir, nil
+}
+
+func render(r runner, req Request, resp *Response) error {
+	objects, err := r.Render(sdk.RenderOptions{
+		EnvName:        req.EnvName,
+		ComponentNames: req.ComponentNames,
+	})
+	if err != nil {
+		return err
+	}
+
+	resp.Objects = objects
+	return nil
+}
+
+func diff(r runner, req Request, resp *Response) error {
+	if req.EnvName == "" {
+		return errors.New("diff requires an environment")
+	}
+
+	var buf bytes.Buffer
+	found, err := r.Diff(sdk.DiffOptions{
+		Src1:           "local:" + req.EnvName,
+		ComponentNames: req.ComponentNames,
+		Output:         req.Output,
+		Out:            &buf,
+	})
+	resp.Output = buf.String()
+	if err != nil {
+		return err
+	}
+
+	resp.Differences = found
+	return nil
+}
+
+// apply applies an environment. Apps checked out from git are not trusted to
+// run local commands on the server, so their command hooks are refused.
+func apply(r runner, req Request, resp *Response) error {
+	return r.Apply(sdk.ApplyOptions{
+		EnvName:             req.EnvName,
+		ComponentNames:      req.ComponentNames,
+		DryRun:              req.DryRun,
+		DisableCommandHooks: req.App.Git != nil,
+	})
+}
+
+func status(r runner, req Request, resp *Response) error {
+	var buf bytes.Buffer
+	err := r.Status(sdk.StatusOptions{
+		EnvName:        req.EnvName,
+		ComponentNames: req.ComponentNames,
+		Output:         req.Output,
+		Out:            &buf,
+	})
+	resp.Output = buf.String()
+	return err
+}
+
+func writeResponse(w http.ResponseWriter, code int, resp *Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.WithError(err).Debug("writing response")
+	}
+}
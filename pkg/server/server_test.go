@@ -0,0 +1,233 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/sdk"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type fakeRunner struct {
+	err     error
+	applied []sdk.ApplyOptions
+}
+
+func (f *fakeRunner) Render(opts sdk.RenderOptions) ([]*unstructured.Unstructured, error) {
+	o := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata":   map[string]interface{}{"name": opts.EnvName},
+	}}
+	return []*unstructured.Unstructured{o}, f.err
+}
+
+func (f *fakeRunner) Diff(opts sdk.DiffOptions) (bool, error) {
+	fmt.Fprintf(opts.Out, "diff %s", opts.Src1)
+	return true, f.err
+}
+
+func (f *fakeRunner) Apply(opts sdk.ApplyOptions) error {
+	f.applied = append(f.applied, opts)
+	return f.err
+}
+
+func (f *fakeRunner) Status(opts sdk.StatusOptions) error {
+	fmt.Fprintf(opts.Out, "status %s", opts.EnvName)
+	return f.err
+}
+
+func post(t *testing.T, s *Server, path, body string) (int, Response) {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+
+	var resp Response
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	return rec.Code, resp
+}
+
+func TestServer(t *testing.T) {
+	var loaded []string
+	var untrusted []bool
+	var fetched []string
+	r := &fakeRunner{}
+
+	s := New("/srv/apps", WithToken("secret"), WithGitRemotes("https://github.com/ksonnet/guestbook"), func(s *Server) {
+		s.loadFn = func(dir string, isUntrusted bool) (runner, error) {
+			loaded = append(loaded, dir)
+			untrusted = append(untrusted, isUntrusted)
+			return r, nil
+		}
+		s.fetchFn = func(remote, version, dir string) (string, error) {
+			fetched = append(fetched, remote+"@"+version)
+			return "abc123", nil
+		}
+	})
+
+	code, resp := post(t, s, "/v1/render", `{"app": {"dir": "guestbook"}, "env": "default"}`)
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, resp.Objects, 1)
+	assert.Equal(t, "default", resp.Objects[0].GetName())
+
+	code, resp = post(t, s, "/v1/diff", `{"app": {"dir": "guestbook"}, "env": "default"}`)
+	require.Equal(t, http.StatusOK, code)
+	assert.True(t, resp.Differences)
+	assert.Equal(t, "diff local:default", resp.Output)
+
+	code, resp = post(t, s, "/v1/status", `{"app": {"git": {"remote": "https://github.com/ksonnet/guestbook", "ref": "v1", "subdir": "app"}}, "env": "prod"}`)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "status prod", resp.Output)
+	assert.Equal(t, "abc123", resp.Commit)
+
+	code, _ = post(t, s, "/v1/apply", `{"app": {"dir": "guestbook"}, "env": "default", "dryRun": true}`)
+	require.Equal(t, http.StatusOK, code)
+
+	code, _ = post(t, s, "/v1/apply", `{"app": {"git": {"remote": "https://github.com/ksonnet/guestbook"}}, "env": "prod"}`)
+	require.Equal(t, http.StatusOK, code)
+
+	require.Len(t, loaded, 5)
+	assert.Equal(t, "/srv/apps/guestbook", loaded[0])
+	assert.True(t, strings.HasSuffix(loaded[2], "/app"), loaded[2])
+	assert.Equal(t, []bool{false, false, true, false, true}, untrusted)
+	assert.Equal(t, []string{"https://github.com/ksonnet/guestbook@v1", "https://github.com/ksonnet/guestbook@"}, fetched)
+
+	require.Len(t, r.applied, 2)
+	assert.False(t, r.applied[0].DisableCommandHooks)
+	assert.True(t, r.applied[1].DisableCommandHooks)
+}
+
+func TestServer_Serve(t *testing.T) {
+	stopCh := make(chan struct{})
+	close(stopCh)
+
+	s := New("/srv/apps")
+	require.NoError(t, s.Serve("127.0.0.1:0", stopCh))
+}
+
+func TestServer_errors(t *testing.T) {
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		token  string
+		err    error
+		code   int
+	}{
+		{
+			name:  "unauthorized",
+			path:  "/v1/render",
+			body:  `{"app": {"dir": "guestbook"}}`,
+			token: "wrong",
+			code:  http.StatusUnauthorized,
+		},
+		{
+			name:   "method",
+			method: http.MethodGet,
+			path:   "/v1/render",
+			code:   http.StatusMethodNotAllowed,
+		},
+		{
+			name: "invalid json",
+			path: "/v1/render",
+			body: `{`,
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "dir outside of root",
+			path: "/v1/render",
+			body: `{"app": {"dir": "../etc"}}`,
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "dir and git",
+			path: "/v1/render",
+			body: `{"app": {"dir": "guestbook", "git": {"remote": "r"}}}`,
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "git remote not allowed",
+			path: "/v1/render",
+			body: `{"app": {"git": {"remote": "https://example.com/apps"}}}`,
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "git ref is an option",
+			path: "/v1/render",
+			body: `{"app": {"git": {"remote": "https://github.com/ksonnet/guestbook", "ref": "--upload-pack=touch /tmp/x"}}}`,
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "diff without an environment",
+			path: "/v1/diff",
+			body: `{"app": {"dir": "guestbook"}}`,
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "operation failed",
+			path: "/v1/apply",
+			body: `{"app": {"dir": "guestbook"}, "env": "default"}`,
+			err:  errors.New("failed"),
+			code: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := New("/srv/apps", WithToken("secret"), WithGitRemotes("https://github.com/ksonnet/guestbook"), func(s *Server) {
+				s.loadFn = func(dir string, untrusted bool) (runner, error) {
+					return &fakeRunner{err: tc.err}, nil
+				}
+				s.fetchFn = func(remote, version, dir string) (string, error) {
+					return "", errors.New("unexpected fetch")
+				}
+			})
+
+			method := tc.method
+			if method == "" {
+				method = http.MethodPost
+			}
+
+			token := tc.token
+			if token == "" {
+				token = "secret"
+			}
+
+			req := httptest.NewRequest(method, tc.path, bytes.NewBufferString(tc.body))
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.code, rec.Code)
+
+			var resp Response
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+			assert.NotEmpty(t, resp.Error)
+		})
+	}
+}
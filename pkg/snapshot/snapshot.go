@@ -0,0 +1,270 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package snapshot records the manifests an environment renders to, and
+// verifies the current rendering matches them.
+package snapshot
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/pkg/errors"
+	godiff "github.com/shazow/go-diff"
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Dir is the directory in an app which contains snapshots.
+const Dir = "snapshots"
+
+var reUnsafe = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// Snapshot is the snapshot of an environment's manifests. Each object is
+// stored in its own YAML file, in a directory named after its component.
+type Snapshot struct {
+	fs      afero.Fs
+	root    string
+	envName string
+}
+
+// New creates an instance of Snapshot for an environment of the app in root.
+func New(fs afero.Fs, root, envName string) *Snapshot {
+	return &Snapshot{
+		fs:      fs,
+		root:    root,
+		envName: envName,
+	}
+}
+
+// Path returns the directory the snapshot is stored in.
+func (s *Snapshot) Path() string {
+	return filepath.Join(s.root, Dir, s.envName)
+}
+
+// Record replaces the snapshot with objects, and returns the paths of the
+// files it wrote, relative to the app root.
+func (s *Snapshot) Record(objects []*unstructured.Unstructured) ([]string, error) {
+	files, err := s.render(objects)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.fs.RemoveAll(s.Path()); err != nil {
+		return nil, err
+	}
+
+	var written []string
+	for _, name := range sortedKeys(files) {
+		path := filepath.Join(s.Path(), name)
+		if err = s.fs.MkdirAll(filepath.Dir(path), app.DefaultFolderPermissions); err != nil {
+			return nil, err
+		}
+		if err = afero.WriteFile(s.fs, path, files[name], app.DefaultFilePermissions); err != nil {
+			return nil, err
+		}
+
+		written = append(written, s.rel(name))
+	}
+
+	return written, nil
+}
+
+// Verify compares objects with the snapshot.
+func (s *Snapshot) Verify(objects []*unstructured.Unstructured) (*Result, error) {
+	exists, err := afero.DirExists(s.fs, s.Path())
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.Errorf("environment %s does not have a snapshot; run `ks snapshot record %s` to record one", s.envName, s.envName)
+	}
+
+	rendered, err := s.render(objects)
+	if err != nil {
+		return nil, err
+	}
+
+	recorded, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{envName: s.envName}
+
+	for _, name := range sortedKeys(rendered) {
+		expected, ok := recorded[name]
+		if !ok {
+			result.Added = append(result.Added, s.rel(name))
+			continue
+		}
+
+		if bytes.Equal(expected, rendered[name]) {
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err = godiff.DefaultDiffer().Diff(&buf, bytes.NewReader(expected), bytes.NewReader(rendered[name])); err != nil {
+			return nil, err
+		}
+
+		result.Changed = append(result.Changed, Change{Path: s.rel(name), Diff: buf.String()})
+	}
+
+	for _, name := range sortedKeys(recorded) {
+		if _, ok := rendered[name]; !ok {
+			result.Removed = append(result.Removed, s.rel(name))
+		}
+	}
+
+	return result, nil
+}
+
+// render converts objects to the files of a snapshot, keyed by their path in
+// the snapshot directory.
+func (s *Snapshot) render(objects []*unstructured.Unstructured) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+
+	for _, o := range objects {
+		name := fileName(o)
+		if _, ok := files[name]; ok {
+			return nil, errors.Errorf("%s %s is rendered more than once", o.GetKind(), o.GetName())
+		}
+
+		b, err := yaml.Marshal(o.Object)
+		if err != nil {
+			return nil, err
+		}
+
+		files[name] = b
+	}
+
+	return files, nil
+}
+
+// read reads the files of the recorded snapshot.
+func (s *Snapshot) read() (map[string][]byte, error) {
+	files := make(map[string][]byte)
+
+	err := afero.Walk(s.fs, s.Path(), func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() || filepath.Ext(path) != ".yaml" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.Path(), path)
+		if err != nil {
+			return err
+		}
+
+		b, err := afero.ReadFile(s.fs, path)
+		if err != nil {
+			return err
+		}
+
+		files[filepath.ToSlash(rel)] = b
+		return nil
+	})
+
+	return files, err
+}
+
+func (s *Snapshot) rel(name string) string {
+	return filepath.Join(Dir, s.envName, filepath.FromSlash(name))
+}
+
+// fileName returns the path of an object in a snapshot, e.g.
+// `guestbook-ui/deployment-default-guestbook-ui.yaml`.
+func fileName(o *unstructured.Unstructured) string {
+	component := o.GetLabels()[metadata.LabelComponent]
+	if component == "" {
+		component = "_"
+	}
+
+	parts := []string{strings.ToLower(o.GetKind())}
+	if ns := o.GetNamespace(); ns != "" {
+		parts = append(parts, ns)
+	}
+	parts = append(parts, o.GetName())
+
+	name := reUnsafe.ReplaceAllString(strings.Join(parts, "-"), "_")
+	return reUnsafe.ReplaceAllString(component, "_") + "/" + name + ".yaml"
+}
+
+func sortedKeys(m map[string][]byte) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Change is a file of a snapshot which differs from the current rendering.
+type Change struct {
+	Path string
+	Diff string
+}
+
+// Result is the result of verifying a snapshot.
+type Result struct {
+	// Added are objects which are rendered, but not in the snapshot.
+	Added []string
+	// Removed are objects in the snapshot which are no longer rendered.
+	Removed []string
+	// Changed are objects which differ from the snapshot.
+	Changed []Change
+
+	envName string
+}
+
+// Err returns an error if the rendering differs from the snapshot.
+func (r *Result) Err() error {
+	if n := len(r.Added) + len(r.Removed) + len(r.Changed); n > 0 {
+		return errors.Errorf("%d manifest(s) differ from the snapshot of %s; run `ks snapshot record %s` if the changes are expected",
+			n, r.envName, r.envName)
+	}
+
+	return nil
+}
+
+// Render describes the differences between the rendering and the snapshot.
+func (r *Result) Render(w io.Writer) error {
+	for _, path := range r.Added {
+		fmt.Fprintf(w, "added:   %s\n", path)
+	}
+	for _, path := range r.Removed {
+		fmt.Fprintf(w, "removed: %s\n", path)
+	}
+	for _, c := range r.Changed {
+		fmt.Fprintf(w, "changed: %s\n%s", c.Path, c.Diff)
+		if !strings.HasSuffix(c.Diff, "\n") {
+			fmt.Fprintln(w)
+		}
+	}
+
+	return nil
+}
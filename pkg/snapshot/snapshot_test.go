@@ -0,0 +1,133 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package snapshot
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/metadata"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func object(component, kind, namespace, name string, replicas int64) *unstructured.Unstructured {
+	o := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       kind,
+			"metadata": map[string]interface{}{
+				"name": name,
+			},
+			"spec": map[string]interface{}{
+				"replicas": replicas,
+			},
+		},
+	}
+	if namespace != "" {
+		o.SetNamespace(namespace)
+	}
+	if component != "" {
+		o.SetLabels(map[string]string{metadata.LabelComponent: component})
+	}
+	return o
+}
+
+func TestSnapshot_Record(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/app/snapshots/default/old/service-old.yaml", []byte("old"), 0644))
+
+	s := New(fs, "/app", "default")
+
+	written, err := s.Record([]*unstructured.Unstructured{
+		object("guestbook-ui", "Deployment", "default", "guestbook-ui", 1),
+		object("guestbook-ui", "Service", "", "guestbook-ui", 1),
+		object("", "Namespace", "", "web", 1),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"snapshots/default/_/namespace-web.yaml",
+		"snapshots/default/guestbook-ui/deployment-default-guestbook-ui.yaml",
+		"snapshots/default/guestbook-ui/service-guestbook-ui.yaml",
+	}, written)
+
+	exists, err := afero.Exists(fs, "/app/snapshots/default/old/service-old.yaml")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	b, err := afero.ReadFile(fs, "/app/snapshots/default/guestbook-ui/deployment-default-guestbook-ui.yaml")
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "replicas: 1")
+}
+
+func TestSnapshot_Record_duplicate(t *testing.T) {
+	s := New(afero.NewMemMapFs(), "/app", "default")
+
+	_, err := s.Record([]*unstructured.Unstructured{
+		object("a", "Service", "", "web", 1),
+		object("a", "Service", "", "web", 2),
+	})
+	require.Error(t, err)
+}
+
+func TestSnapshot_Verify(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	s := New(fs, "/app", "default")
+
+	_, err := s.Record([]*unstructured.Unstructured{
+		object("guestbook-ui", "Deployment", "default", "guestbook-ui", 1),
+		object("guestbook-ui", "Service", "", "guestbook-ui", 1),
+	})
+	require.NoError(t, err)
+
+	result, err := s.Verify([]*unstructured.Unstructured{
+		object("guestbook-ui", "Deployment", "default", "guestbook-ui", 1),
+		object("guestbook-ui", "Service", "", "guestbook-ui", 1),
+	})
+	require.NoError(t, err)
+	require.NoError(t, result.Err())
+
+	result, err = s.Verify([]*unstructured.Unstructured{
+		object("guestbook-ui", "Deployment", "default", "guestbook-ui", 3),
+		object("redis", "Service", "", "redis", 1),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"snapshots/default/redis/service-redis.yaml"}, result.Added)
+	assert.Equal(t, []string{"snapshots/default/guestbook-ui/service-guestbook-ui.yaml"}, result.Removed)
+	require.Len(t, result.Changed, 1)
+	assert.Equal(t, "snapshots/default/guestbook-ui/deployment-default-guestbook-ui.yaml", result.Changed[0].Path)
+	assert.Contains(t, result.Changed[0].Diff, "-  replicas: 1")
+	assert.Contains(t, result.Changed[0].Diff, "+  replicas: 3")
+
+	assert.EqualError(t, result.Err(), "3 manifest(s) differ from the snapshot of default; run `ks snapshot record default` if the changes are expected")
+
+	var buf bytes.Buffer
+	require.NoError(t, result.Render(&buf))
+	assert.Contains(t, buf.String(), "added:   snapshots/default/redis/service-redis.yaml\n")
+	assert.Contains(t, buf.String(), "removed: snapshots/default/guestbook-ui/service-guestbook-ui.yaml\n")
+	assert.Contains(t, buf.String(), "changed: snapshots/default/guestbook-ui/deployment-default-guestbook-ui.yaml\n")
+}
+
+func TestSnapshot_Verify_not_recorded(t *testing.T) {
+	s := New(afero.NewMemMapFs(), "/app", "default")
+
+	_, err := s.Verify(nil)
+	require.Error(t, err)
+}
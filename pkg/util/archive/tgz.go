@@ -53,7 +53,7 @@ func (t *Tgz) Unarchive(r io.Reader, handler FileHandler) error {
 		name := header.Name
 
 		switch header.Typeflag {
-		case 0:
+		case 0, tar.TypeReg:
 			tf := &File{
 				Name:   name,
 				Reader: tarReader,
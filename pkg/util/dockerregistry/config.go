@@ -0,0 +1,268 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package dockerregistry
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+const (
+	// dockerHubServerURL is the server URL credential helpers store Docker
+	// Hub credentials under.
+	dockerHubServerURL = "https://index.docker.io/v1/"
+)
+
+var (
+	// dockerHubHosts are the hosts Docker Hub credentials are stored under.
+	dockerHubHosts = []string{"index.docker.io", "docker.io", defaultRegistry}
+
+	// errCredentialsNotFound is returned by credential helpers which have no
+	// credentials for a registry.
+	errCredentialsNotFound = errors.New("credentials not found")
+)
+
+// DockerConfig is the part of a docker client config file which holds
+// registry credentials.
+type DockerConfig struct {
+	Auths map[string]DockerAuth `json:"auths"`
+	// CredsStore is the credential helper which stores the credentials of
+	// all registries, e.g. "desktop" for docker-credential-desktop.
+	CredsStore string `json:"credsStore,omitempty"`
+	// CredHelpers are the credential helpers of registries, e.g. "gcloud"
+	// for gcr.io. They take precedence over CredsStore.
+	CredHelpers map[string]string `json:"credHelpers,omitempty"`
+
+	// helperFn gets the credentials of a server from a credential helper.
+	helperFn func(helper, serverURL string) (string, string, error)
+
+	mu sync.Mutex
+	// helperCreds caches the credentials got from credential helpers by host.
+	helperCreds map[string]helperCredentials
+}
+
+type helperCredentials struct {
+	username string
+	password string
+	ok       bool
+}
+
+// DockerAuth is the credentials for a registry.
+type DockerAuth struct {
+	// Auth is the base64 encoding of "username:password".
+	Auth     string `json:"auth,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// DockerConfigPath returns the path of the docker client config file. It is
+// in $DOCKER_CONFIG if it is set, and ~/.docker otherwise.
+func DockerConfigPath() string {
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		dir = filepath.Join(os.Getenv("HOME"), ".docker")
+	}
+
+	return filepath.Join(dir, "config.json")
+}
+
+// LoadDockerConfig loads a docker client config file. If the file does not
+// exist, the config is empty.
+func LoadDockerConfig(fs afero.Fs, path string) (*DockerConfig, error) {
+	config := &DockerConfig{}
+
+	exists, err := afero.Exists(fs, path)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return config, nil
+	}
+
+	data, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, errors.Wrapf(err, "parsing docker config %s", path)
+	}
+
+	return config, nil
+}
+
+// Credentials returns the username and password for a registry host. They
+// are got from the host's credential helper, the config itself, or the
+// config's credential store, in that order. ok is false if the config has no
+// credentials for the host.
+func (c *DockerConfig) Credentials(host string) (username, password string, ok bool) {
+	if c == nil {
+		return "", "", false
+	}
+
+	hosts := []string{host}
+	for _, h := range dockerHubHosts {
+		if h == host {
+			hosts = dockerHubHosts
+		}
+	}
+
+	for key, helper := range c.CredHelpers {
+		for _, h := range hosts {
+			if configHost(key) == h {
+				return c.helperCredentials(helper, host, hosts)
+			}
+		}
+	}
+
+	if username, password, ok = c.authCredentials(hosts); ok {
+		return username, password, true
+	}
+
+	if c.CredsStore != "" {
+		return c.helperCredentials(c.CredsStore, host, hosts)
+	}
+
+	return "", "", false
+}
+
+// authCredentials returns the credentials for any of hosts in the config's
+// auths.
+func (c *DockerConfig) authCredentials(hosts []string) (username, password string, ok bool) {
+	for key, auth := range c.Auths {
+		keyHost := configHost(key)
+		for _, h := range hosts {
+			if keyHost != h {
+				continue
+			}
+
+			if auth.Username != "" || auth.Password != "" {
+				return auth.Username, auth.Password, true
+			}
+
+			decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+			if err != nil {
+				continue
+			}
+
+			parts := strings.SplitN(string(decoded), ":", 2)
+			if len(parts) != 2 {
+				continue
+			}
+
+			return parts[0], parts[1], true
+		}
+	}
+
+	return "", "", false
+}
+
+// helperCredentials returns the credentials for a host from a credential
+// helper. Results are cached, so each helper is run once per host. Helpers
+// which can not be used are reported with a warning, since the registry is
+// then accessed anonymously.
+func (c *DockerConfig) helperCredentials(helper, host string, hosts []string) (string, string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if creds, ok := c.helperCreds[host]; ok {
+		return creds.username, creds.password, creds.ok
+	}
+
+	serverURL := host
+	if len(hosts) > 1 {
+		serverURL = dockerHubServerURL
+	}
+
+	helperFn := c.helperFn
+	if helperFn == nil {
+		helperFn = runCredentialHelper
+	}
+
+	var creds helperCredentials
+	username, password, err := helperFn(helper, serverURL)
+	switch {
+	case err == nil:
+		creds = helperCredentials{username: username, password: password, ok: true}
+	case err == errCredentialsNotFound:
+		log.Debugf("docker credential helper %q has no credentials for %s", helper, serverURL)
+	default:
+		log.WithError(err).Warnf("docker credential helper %q can not be used; %s will be accessed anonymously", helper, host)
+	}
+
+	if c.helperCreds == nil {
+		c.helperCreds = make(map[string]helperCredentials)
+	}
+	c.helperCreds[host] = creds
+
+	return creds.username, creds.password, creds.ok
+}
+
+// runCredentialHelper gets the credentials of a server with
+// `docker-credential-<helper> get`.
+func runCredentialHelper(helper, serverURL string) (string, string, error) {
+	cmd := exec.Command("docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(serverURL)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if _, ok := err.(*exec.Error); ok {
+			return "", "", err
+		}
+
+		msg := strings.TrimSpace(string(out) + " " + stderr.String())
+		if strings.Contains(msg, "credentials not found") {
+			return "", "", errCredentialsNotFound
+		}
+		return "", "", errors.Errorf("%v: %s", err, msg)
+	}
+
+	var creds struct {
+		Username string
+		Secret   string
+	}
+	if err := json.Unmarshal(out, &creds); err != nil {
+		return "", "", errors.Wrap(err, "parsing credential helper output")
+	}
+
+	return creds.Username, creds.Secret, nil
+}
+
+// configHost returns the host of a key in a docker config's auths. Keys may
+// be hosts, or URLs such as "https://index.docker.io/v1/".
+func configHost(key string) string {
+	key = strings.TrimPrefix(key, "https://")
+	key = strings.TrimPrefix(key, "http://")
+
+	if i := strings.Index(key, "/"); i >= 0 {
+		key = key[:i]
+	}
+
+	return key
+}
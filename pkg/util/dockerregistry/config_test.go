@@ -0,0 +1,205 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package dockerregistry
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadDockerConfig(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	config, err := LoadDockerConfig(fs, "/missing/config.json")
+	require.NoError(t, err)
+	assert.Empty(t, config.Auths)
+
+	data := `{"auths": {"https://index.docker.io/v1/": {"auth": "dXNlcjpwYXNz"}}}`
+	require.NoError(t, afero.WriteFile(fs, "/config.json", []byte(data), 0644))
+
+	config, err = LoadDockerConfig(fs, "/config.json")
+	require.NoError(t, err)
+	assert.Equal(t, "dXNlcjpwYXNz", config.Auths["https://index.docker.io/v1/"].Auth)
+
+	require.NoError(t, afero.WriteFile(fs, "/invalid.json", []byte("{"), 0644))
+	_, err = LoadDockerConfig(fs, "/invalid.json")
+	require.Error(t, err)
+}
+
+func TestDockerConfig_Credentials(t *testing.T) {
+	config := &DockerConfig{
+		Auths: map[string]DockerAuth{
+			"https://index.docker.io/v1/": {Auth: "dXNlcjpwYXNz"},
+			"gcr.io":                      {Username: "_json_key", Password: "key"},
+			"quay.io":                     {Auth: "not base64"},
+		},
+	}
+
+	cases := []struct {
+		name     string
+		host     string
+		username string
+		password string
+		ok       bool
+	}{
+		{
+			name:     "docker hub registry",
+			host:     "registry-1.docker.io",
+			username: "user",
+			password: "pass",
+			ok:       true,
+		},
+		{
+			name:     "username and password",
+			host:     "gcr.io",
+			username: "_json_key",
+			password: "key",
+			ok:       true,
+		},
+		{
+			name: "invalid auth",
+			host: "quay.io",
+		},
+		{
+			name: "unknown registry",
+			host: "registry.example.com",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			username, password, ok := config.Credentials(tc.host)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.username, username)
+			assert.Equal(t, tc.password, password)
+		})
+	}
+
+	var empty *DockerConfig
+	_, _, ok := empty.Credentials("gcr.io")
+	assert.False(t, ok)
+}
+
+func TestDockerConfig_Credentials_helpers(t *testing.T) {
+	var calls []string
+	config := &DockerConfig{
+		Auths: map[string]DockerAuth{
+			"quay.io": {Auth: "dXNlcjpwYXNz"},
+		},
+		CredsStore: "desktop",
+		CredHelpers: map[string]string{
+			"gcr.io":           "gcloud",
+			"registry.missing": "missing",
+		},
+		helperFn: func(helper, serverURL string) (string, string, error) {
+			calls = append(calls, helper+" "+serverURL)
+			switch helper {
+			case "gcloud":
+				return "oauth2accesstoken", "token", nil
+			case "missing":
+				return "", "", errors.New("executable file not found")
+			case "desktop":
+				if serverURL == dockerHubServerURL {
+					return "hub", "secret", nil
+				}
+			}
+			return "", "", errCredentialsNotFound
+		},
+	}
+
+	cases := []struct {
+		name     string
+		host     string
+		username string
+		password string
+		ok       bool
+	}{
+		{name: "cred helper", host: "gcr.io", username: "oauth2accesstoken", password: "token", ok: true},
+		{name: "inline auth", host: "quay.io", username: "user", password: "pass", ok: true},
+		{name: "creds store", host: "registry-1.docker.io", username: "hub", password: "secret", ok: true},
+		{name: "not in creds store", host: "registry.example.com"},
+		{name: "unusable helper", host: "registry.missing"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			username, password, ok := config.Credentials(tc.host)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.username, username)
+			assert.Equal(t, tc.password, password)
+		})
+	}
+
+	// helpers are run once per host.
+	config.Credentials("gcr.io")
+	expected := []string{
+		"gcloud gcr.io",
+		"desktop " + dockerHubServerURL,
+		"desktop registry.example.com",
+		"missing registry.missing",
+	}
+	assert.Equal(t, expected, calls)
+}
+
+func TestLoadDockerConfig_helpers(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	data := `{"auths": {"https://index.docker.io/v1/": {}}, "credsStore": "desktop", "credHelpers": {"gcr.io": "gcloud"}}`
+	require.NoError(t, afero.WriteFile(fs, "/config.json", []byte(data), 0644))
+
+	config, err := LoadDockerConfig(fs, "/config.json")
+	require.NoError(t, err)
+	assert.Equal(t, "desktop", config.CredsStore)
+	assert.Equal(t, map[string]string{"gcr.io": "gcloud"}, config.CredHelpers)
+}
+
+func Test_runCredentialHelper(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docker-credential")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	script := `#!/bin/sh
+read server
+if [ "$server" = "gcr.io" ]; then
+  echo '{"ServerURL": "gcr.io", "Username": "oauth2accesstoken", "Secret": "token"}'
+else
+  echo "credentials not found in native keychain"
+  exit 1
+fi
+`
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "docker-credential-fake"), []byte(script), 0755))
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	username, password, err := runCredentialHelper("fake", "gcr.io")
+	require.NoError(t, err)
+	assert.Equal(t, "oauth2accesstoken", username)
+	assert.Equal(t, "token", password)
+
+	_, _, err = runCredentialHelper("fake", "quay.io")
+	assert.Equal(t, errCredentialsNotFound, err)
+
+	_, _, err = runCredentialHelper("missing", "gcr.io")
+	require.Error(t, err)
+	assert.NotEqual(t, errCredentialsNotFound, err)
+}
@@ -33,6 +33,7 @@ const (
 
 var (
 	commaRegexp = regexp.MustCompile(", *")
+	linkRegexp  = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
 )
 
 // imageNotFoundError is an image not found error.
@@ -94,6 +95,61 @@ func (r *Registry) ManifestDigest(reponame, tag string) (string, error) {
 	return digest, nil
 }
 
+// Tags fetches the tags of a reponame. Pages of tags are followed until the
+// registry returns no more.
+func (r *Registry) Tags(reponame string) ([]string, error) {
+	var tags []string
+
+	next := fmt.Sprintf("%s/v2/%s/tags/list", r.URL, reponame)
+	for next != "" {
+		resp, err := r.Client.Get(next)
+		if err != nil {
+			return nil, err
+		}
+
+		page, link, err := r.tagsPage(resp, reponame)
+		if err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, page...)
+		next = link
+	}
+
+	return tags, nil
+}
+
+// tagsPage reads a page of tags, and the URL of the next page if there is
+// one.
+func (r *Registry) tagsPage(resp *http.Response, reponame string) ([]string, string, error) {
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, "", &imageNotFoundError{name: reponame}
+	default:
+		return nil, "", errors.Errorf("request failed with %s", resp.Status)
+	}
+
+	var page struct {
+		Tags []string `json:"tags"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, "", errors.Wrap(err, "decoding tags")
+	}
+
+	next := ""
+	if m := linkRegexp.FindStringSubmatch(resp.Header.Get("Link")); m != nil {
+		next = m[1]
+		if strings.HasPrefix(next, "/") {
+			next = r.URL + next
+		}
+	}
+
+	return page.Tags, next, nil
+}
+
 // stolen from golang 1.8
 func stripPort(hostport string) string {
 	colon := strings.IndexByte(hostport, ':')
@@ -150,10 +206,17 @@ func parseAuthHeader(header http.Header) []*authChallenge {
 
 // NewAuthTransport returns a roundtripper that does bearer/etc authentication
 func NewAuthTransport(inner http.RoundTripper) http.RoundTripper {
+	return NewConfigAuthTransport(inner, nil)
+}
+
+// NewConfigAuthTransport returns a roundtripper that does bearer/etc
+// authentication with the credentials for each registry in a docker config.
+func NewConfigAuthTransport(inner http.RoundTripper, config *DockerConfig) http.RoundTripper {
 	return &authTransport{
 		Transport:  inner,
 		Client:     &http.Client{Transport: inner},
 		tokenCache: map[string]string{},
+		Config:     config,
 	}
 }
 
@@ -164,6 +227,18 @@ type authTransport struct {
 	HostDomain string
 	Username   string
 	Password   string
+	// Config holds credentials for registries. They are used instead of
+	// Username and Password for the registries it has credentials for.
+	Config *DockerConfig
+}
+
+// credentials returns the username and password for a registry host.
+func (t *authTransport) credentials(host string) (string, string) {
+	if username, password, ok := t.Config.Credentials(host); ok {
+		return username, password
+	}
+
+	return t.Username, t.Password
 }
 
 // RoundTrip is required for the http.RoundTripper interface
@@ -172,16 +247,17 @@ func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	resp, err := t.Transport.RoundTrip(req)
 	log.Debugf("<= err=%v resp=%v", err, resp)
 	if err == nil && resp.StatusCode == http.StatusUnauthorized && matchesDomain(req.URL, t.HostDomain) {
+		username, password := t.credentials(req.URL.Host)
 		schemes := parseAuthHeader(resp.Header)
 		for _, scheme := range schemes {
 			if scheme.Scheme == "basic" {
 				log.Debugf("Retrying with basic auth")
-				req.SetBasicAuth(t.Username, t.Password)
+				req.SetBasicAuth(username, password)
 				log.Debugf("=> %v", req)
 				return t.Transport.RoundTrip(req)
 			}
 			if scheme.Scheme == "bearer" {
-				token, err := t.bearerAuth(scheme.Params["realm"], scheme.Params["service"], scheme.Params["scope"])
+				token, err := t.bearerAuth(scheme.Params["realm"], scheme.Params["service"], scheme.Params["scope"], username, password)
 				if err != nil {
 					return resp, err
 				}
@@ -197,8 +273,8 @@ func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	return resp, err
 }
 
-func (t *authTransport) bearerAuth(realm, service, scope string) (string, error) {
-	cacheKey := fmt.Sprintf("%s!%s!%s", realm, service, scope)
+func (t *authTransport) bearerAuth(realm, service, scope, username, password string) (string, error) {
+	cacheKey := fmt.Sprintf("%s!%s!%s!%s", realm, service, scope, username)
 	if token := t.tokenCache[cacheKey]; token != "" {
 		return token, nil
 	}
@@ -220,8 +296,8 @@ func (t *authTransport) bearerAuth(realm, service, scope string) (string, error)
 		return "", err
 	}
 
-	if t.Username != "" || t.Password != "" {
-		req.SetBasicAuth(t.Username, t.Password)
+	if username != "" || password != "" {
+		req.SetBasicAuth(username, password)
 	}
 
 	log.Debugf("Performing oauth request to %s", req.URL)
@@ -17,8 +17,10 @@ package dockerregistry
 
 import (
 	"crypto/tls"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
@@ -53,3 +55,61 @@ func Test_RegistryClient_ManifestDigest(t *testing.T) {
 
 	require.Equal(t, "sha256:abcde", digest)
 }
+
+func Test_RegistryClient_Tags(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/foo/bar/tags/list":
+			if r.URL.Query().Get("last") == "" {
+				w.Header().Set("Link", `</v2/foo/bar/tags/list?last=1.1&n=2>; rel="next"`)
+				fmt.Fprint(w, `{"name": "foo/bar", "tags": ["1.0", "1.1"]}`)
+				return
+			}
+			fmt.Fprint(w, `{"name": "foo/bar", "tags": ["1.2"]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	c := NewRegistryClient(ts.Client(), ts.URL)
+
+	tags, err := c.Tags("foo/bar")
+	require.NoError(t, err)
+	require.Equal(t, []string{"1.0", "1.1", "1.2"}, tags)
+
+	_, err = c.Tags("foo/missing")
+	require.Error(t, err)
+}
+
+func Test_authTransport_credentials(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		assert.Equal(t, "user", username)
+		assert.Equal(t, "pass", password)
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+
+	config := &DockerConfig{
+		Auths: map[string]DockerAuth{
+			u.Host: {Username: "user", Password: "pass"},
+		},
+	}
+
+	client := &http.Client{Transport: NewConfigAuthTransport(http.DefaultTransport, config)}
+
+	resp, err := client.Get(ts.URL + "/v2/")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
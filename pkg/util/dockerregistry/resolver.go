@@ -20,6 +20,8 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
 )
 
 // Resolver is able to resolve docker image names into more specific forms
@@ -71,6 +73,7 @@ func (r *registryResolver) Resolve(n *ImageName) error {
 // ResolverClient is client resolves data from manifests.
 type ResolverClient interface {
 	ManifestV2Digest(image string) (string, error)
+	Tags(image string) ([]string, error)
 }
 
 // DefaultResolverClient resolves digests for a docker image.
@@ -80,12 +83,18 @@ type DefaultResolverClient struct {
 
 var _ ResolverClient = (*DefaultResolverClient)(nil)
 
-// NewDefaultDigester creates an instance of DefaultDigester.
+// NewDefaultDigester creates an instance of DefaultDigester. Registries are
+// authenticated with the credentials in the docker client config.
 func NewDefaultDigester() *DefaultResolverClient {
+	config, err := LoadDockerConfig(afero.NewOsFs(), DockerConfigPath())
+	if err != nil {
+		log.WithError(err).Warn("loading docker config; registries will be accessed anonymously")
+	}
+
 	return &DefaultResolverClient{
 		clientFactory: func() *http.Client {
 			return &http.Client{
-				Transport: NewAuthTransport(http.DefaultTransport),
+				Transport: NewConfigAuthTransport(http.DefaultTransport, config),
 				Timeout:   15 * time.Second,
 			}
 		},
@@ -110,8 +119,30 @@ func (d *DefaultResolverClient) ManifestV2Digest(image string) (string, error) {
 	return n.String(), nil
 }
 
+// Tags returns the tags of a docker image's repository.
+func (d *DefaultResolverClient) Tags(image string) ([]string, error) {
+	n, err := ParseImageName(image)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing image name")
+	}
+
+	c := NewRegistryClient(d.clientFactory(), n.RegistryURL())
+	tags, err := c.Tags(n.RegistryRepoName())
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching tags of %s", n.RegistryRepoName())
+	}
+
+	return tags, nil
+}
+
 // ResolveImage loads the digest reference for a docker image.
 func ResolveImage(image string) (string, error) {
 	d := NewDefaultDigester()
 	return d.ManifestV2Digest(image)
 }
+
+// ImageTags loads the tags of a docker image's repository.
+func ImageTags(image string) ([]string, error) {
+	d := NewDefaultDigester()
+	return d.Tags(image)
+}
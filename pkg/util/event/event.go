@@ -0,0 +1,170 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package event is a bus for the events emitted over the lifecycle of an
+// action. Actions publish typed events instead of logging their progress, and
+// the bus hands them to renderers, e.g. the log or JSON renderers, the ui, and
+// plugins.
+package event
+
+import (
+	"sync"
+	"time"
+)
+
+// Event is an event emitted by an action.
+type Event interface {
+	// Type is the name of the event type.
+	Type() string
+}
+
+// EvaluationStarted is emitted when the jsonnet of a module is evaluated.
+type EvaluationStarted struct {
+	Module string `json:"module"`
+	Env    string `json:"env"`
+}
+
+// Type is the name of the event type.
+func (EvaluationStarted) Type() string { return "EvaluationStarted" }
+
+// ResourceApplied is emitted when an object is applied to a cluster.
+type ResourceApplied struct {
+	Env       string `json:"env"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Action is the result of the apply: created, configured or unchanged.
+	Action string `json:"action"`
+	DryRun bool   `json:"dryRun,omitempty"`
+}
+
+// Type is the name of the event type.
+func (ResourceApplied) Type() string { return "ResourceApplied" }
+
+// RegistryFetched is emitted when the spec of a registry is fetched.
+type RegistryFetched struct {
+	Registry string        `json:"registry"`
+	Protocol string        `json:"protocol"`
+	Cached   bool          `json:"cached"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Type is the name of the event type.
+func (RegistryFetched) Type() string { return "RegistryFetched" }
+
+// Record is an event with the time it was published.
+type Record struct {
+	Type  string    `json:"type"`
+	Time  time.Time `json:"time"`
+	Event Event     `json:"event"`
+}
+
+// Handler handles the events published on a bus.
+type Handler func(Record)
+
+// Bus delivers published events to its subscribers. Events are delivered
+// synchronously, in the order the handlers subscribed.
+type Bus struct {
+	mu       sync.Mutex
+	nextID   int
+	handlers []subscription
+	now      func() time.Time
+}
+
+type subscription struct {
+	id      int
+	handler Handler
+}
+
+// NewBus creates an instance of Bus.
+func NewBus() *Bus {
+	return &Bus{now: time.Now}
+}
+
+// Subscribe subscribes a handler to the bus. The returned function
+// unsubscribes it.
+func (b *Bus) Subscribe(h Handler) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	id := b.nextID
+	b.handlers = append(b.handlers, subscription{id: id, handler: h})
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		for i, s := range b.handlers {
+			if s.id == id {
+				b.handlers = append(b.handlers[:i:i], b.handlers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Publish publishes an event to the subscribers of the bus.
+func (b *Bus) Publish(e Event) {
+	b.mu.Lock()
+	handlers := make([]Handler, 0, len(b.handlers))
+	for _, s := range b.handlers {
+		handlers = append(handlers, s.handler)
+	}
+	r := Record{Type: e.Type(), Time: b.now(), Event: e}
+	b.mu.Unlock()
+
+	for _, h := range handlers {
+		h(r)
+	}
+}
+
+var defaultBus = NewBus()
+
+// Default returns the default bus.
+func Default() *Bus {
+	return defaultBus
+}
+
+// Publish publishes an event to the default bus.
+func Publish(e Event) {
+	defaultBus.Publish(e)
+}
+
+// Subscribe subscribes a handler to the default bus. The returned function
+// unsubscribes it.
+func Subscribe(h Handler) func() {
+	return defaultBus.Subscribe(h)
+}
+
+// Collect subscribes to the default bus and collects the events published
+// until the returned function is called.
+func Collect() func() []Record {
+	var mu sync.Mutex
+	var records []Record
+	unsubscribe := Subscribe(func(r Record) {
+		mu.Lock()
+		defer mu.Unlock()
+		records = append(records, r)
+	})
+
+	return func() []Record {
+		unsubscribe()
+
+		mu.Lock()
+		defer mu.Unlock()
+		return records
+	}
+}
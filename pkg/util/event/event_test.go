@@ -0,0 +1,62 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package event
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBus() *Bus {
+	b := NewBus()
+	b.now = func() time.Time {
+		return time.Unix(1500000000, 0).UTC()
+	}
+
+	return b
+}
+
+func TestBus(t *testing.T) {
+	b := newTestBus()
+
+	var first, second []string
+	unsubscribe := b.Subscribe(func(r Record) {
+		first = append(first, r.Type)
+	})
+	b.Subscribe(func(r Record) {
+		second = append(second, r.Type)
+	})
+
+	b.Publish(EvaluationStarted{Module: "/", Env: "default"})
+	unsubscribe()
+	b.Publish(RegistryFetched{Registry: "incubator"})
+
+	assert.Equal(t, []string{"EvaluationStarted"}, first)
+	assert.Equal(t, []string{"EvaluationStarted", "RegistryFetched"}, second)
+}
+
+func TestCollect(t *testing.T) {
+	collect := Collect()
+	Publish(EvaluationStarted{Module: "/", Env: "default"})
+	records := collect()
+	Publish(EvaluationStarted{Module: "/", Env: "prod"})
+
+	require.Len(t, records, 1)
+	assert.Equal(t, EvaluationStarted{Module: "/", Env: "default"}, records[0].Event)
+}
@@ -0,0 +1,81 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Message describes an event for people.
+func Message(e Event) string {
+	switch e := e.(type) {
+	case EvaluationStarted:
+		return fmt.Sprintf("evaluating module %s for environment %s", e.Module, e.Env)
+	case ResourceApplied:
+		name := e.Name
+		if e.Namespace != "" {
+			name = e.Namespace + "/" + e.Name
+		}
+		dryRun := ""
+		if e.DryRun {
+			dryRun = " (dry-run)"
+		}
+		return fmt.Sprintf("%s %s %s%s", e.Action, e.Kind, name, dryRun)
+	case RegistryFetched:
+		cached := ""
+		if e.Cached {
+			cached = " from cache"
+		}
+		return fmt.Sprintf("fetched registry %s%s in %s", e.Registry, cached, e.Duration)
+	default:
+		return e.Type()
+	}
+}
+
+// NewLogRenderer creates a handler which logs events. Applied resources are
+// logged at the info level, and everything else at the debug level.
+func NewLogRenderer(logger logrus.FieldLogger) Handler {
+	return func(r Record) {
+		entry := logger.WithField("event", r.Type)
+		switch r.Event.(type) {
+		case ResourceApplied:
+			entry.Info(Message(r.Event))
+		default:
+			entry.Debug(Message(r.Event))
+		}
+	}
+}
+
+// NewJSONRenderer creates a handler which writes events to w as JSON, one
+// record per line.
+func NewJSONRenderer(w io.Writer) Handler {
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+
+	return func(r Record) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if err := enc.Encode(r); err != nil {
+			logrus.WithError(err).Debug("writing event")
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package event
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessage(t *testing.T) {
+	cases := []struct {
+		event    Event
+		expected string
+	}{
+		{
+			event:    EvaluationStarted{Module: "/", Env: "default"},
+			expected: "evaluating module / for environment default",
+		},
+		{
+			event:    ResourceApplied{Kind: "Deployment", Namespace: "web", Name: "guestbook", Action: "created", DryRun: true},
+			expected: "created Deployment web/guestbook (dry-run)",
+		},
+		{
+			event:    ResourceApplied{Kind: "Namespace", Name: "web", Action: "unchanged"},
+			expected: "unchanged Namespace web",
+		},
+		{
+			event:    RegistryFetched{Registry: "incubator", Cached: true, Duration: time.Second},
+			expected: "fetched registry incubator from cache in 1s",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.expected, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Message(tc.event))
+		})
+	}
+}
+
+func TestNewJSONRenderer(t *testing.T) {
+	var buf bytes.Buffer
+	b := newTestBus()
+	b.Subscribe(NewJSONRenderer(&buf))
+
+	b.Publish(ResourceApplied{Env: "default", Kind: "Service", Name: "guestbook", Action: "configured"})
+
+	expected := `{"type":"ResourceApplied","time":"2017-07-14T02:40:00Z","event":{"env":"default","kind":"Service","name":"guestbook","action":"configured"}}` + "\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestNewLogRenderer(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.Out = &buf
+	logger.Formatter = &logrus.TextFormatter{DisableTimestamp: true}
+
+	b := newTestBus()
+	b.Subscribe(NewLogRenderer(logger))
+
+	b.Publish(EvaluationStarted{Module: "/", Env: "default"})
+	b.Publish(ResourceApplied{Kind: "Service", Name: "guestbook", Action: "created"})
+
+	expected := `level=info msg="created Service guestbook" event=ResourceApplied` + "\n"
+	assert.Equal(t, expected, buf.String())
+}
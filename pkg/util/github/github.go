@@ -18,6 +18,8 @@ package github
 import (
 	"context"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"os"
@@ -65,6 +67,9 @@ type GitHub interface {
 	ValidateURL(u string) error
 	CommitSHA1(ctx context.Context, repo Repo, refSpec string) (string, error)
 	Contents(ctx context.Context, repo Repo, path, sha1 string) (*github.RepositoryContent, []*github.RepositoryContent, error)
+	ReleaseAsset(ctx context.Context, repo Repo, tag, name string) ([]byte, error)
+	DefaultBranch(ctx context.Context, repo Repo) (string, error)
+	Download(ctx context.Context, downloadURL string) (io.ReadCloser, error)
 }
 
 type httpClient interface {
@@ -98,9 +103,9 @@ func NewGitHub(httpClient *http.Client) GitHub {
 
 func (dg *defaultGitHub) SetBaseURL(baseURL *url.URL) {
 	if baseURL == nil {
-		fmt.Printf("DEBUG!!! setting default baseURL: DEFAULT\n")
+		log.Debugf("setting default baseURL: DEFAULT")
 	} else {
-		fmt.Printf("DEBUG!!! setting default baseURL: %s\n", baseURL.String())
+		log.Debugf("setting default baseURL: %s", baseURL.String())
 	}
 	dg.baseURL = baseURL
 }
@@ -131,10 +136,16 @@ func (dg *defaultGitHub) ValidateURL(urlStr string) error {
 	return nil
 }
 
+// CommitSHA1 returns the SHA1 of the commit a refspec points at. If refSpec is
+// empty, it is the repository's default branch.
 func (dg *defaultGitHub) CommitSHA1(ctx context.Context, repo Repo, refSpec string) (string, error) {
 	log := log.WithField("action", "defaultGitHub.CommitSHA1")
 	if refSpec == "" {
-		refSpec = "master"
+		branch, err := dg.DefaultBranch(ctx, repo)
+		if err != nil {
+			return "", err
+		}
+		refSpec = branch
 	}
 
 	log.Debugf("fetching SHA1 for %s@%s", repo, refSpec)
@@ -142,6 +153,24 @@ func (dg *defaultGitHub) CommitSHA1(ctx context.Context, repo Repo, refSpec stri
 	return sha, err
 }
 
+// DefaultBranch returns the repository's default branch, e.g. main.
+func (dg *defaultGitHub) DefaultBranch(ctx context.Context, repo Repo) (string, error) {
+	log := log.WithField("action", "defaultGitHub.DefaultBranch")
+	log.Debugf("fetching default branch for %s", repo)
+
+	r, _, err := dg.client().Repositories.Get(ctx, repo.Org, repo.Repo)
+	if err != nil {
+		return "", errors.Wrapf(err, "fetching default branch for %s", repo)
+	}
+
+	branch := r.GetDefaultBranch()
+	if branch == "" {
+		return "", errors.Errorf("repository %s does not have a default branch", repo)
+	}
+
+	return branch, nil
+}
+
 func (dg *defaultGitHub) Contents(ctx context.Context, repo Repo, path, ref string) (*github.RepositoryContent, []*github.RepositoryContent, error) {
 	log := log.WithField("action", "defaultGitHub.Contents")
 	log.Debugf("fetching contents for %s/%s@%s", repo, path, ref)
@@ -151,27 +180,105 @@ func (dg *defaultGitHub) Contents(ctx context.Context, repo Repo, path, ref stri
 	return file, dir, err
 }
 
-func (dg *defaultGitHub) client() *github.Client {
-	var httpClient = dg.httpClient
+// ReleaseAsset downloads the asset with a name from the release for a tag. It
+// returns nil if the release does not have the asset.
+func (dg *defaultGitHub) ReleaseAsset(ctx context.Context, repo Repo, tag, name string) ([]byte, error) {
+	log := log.WithField("action", "defaultGitHub.ReleaseAsset")
+	log.Debugf("fetching release asset %s for %s@%s", name, repo, tag)
+
+	client := dg.client()
+
+	release, _, err := client.Repositories.GetReleaseByTag(ctx, repo.Org, repo.Repo, tag)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching release %s for %s", tag, repo)
+	}
+
+	for _, asset := range release.Assets {
+		if asset.GetName() != name {
+			continue
+		}
+
+		rc, redirectURL, err := client.Repositories.DownloadReleaseAsset(ctx, repo.Org, repo.Repo, asset.GetID())
+		if err != nil {
+			return nil, errors.Wrapf(err, "downloading release asset %s", name)
+		}
+
+		if rc == nil {
+			resp, err := dg.httpClient.Get(redirectURL)
+			if err != nil {
+				return nil, errors.Wrapf(err, "downloading release asset %s", name)
+			}
+			if resp.StatusCode != http.StatusOK {
+				resp.Body.Close()
+				return nil, errors.Errorf("downloading release asset %s: %q actual %d; expected %d", name, redirectURL, resp.StatusCode, http.StatusOK)
+			}
+			rc = resp.Body
+		}
+		defer rc.Close()
+
+		return ioutil.ReadAll(rc)
+	}
 
+	return nil, nil
+}
+
+// Download streams the contents of a download URL, e.g. the download_url of
+// a file's contents. Interrupted transfers are resumed with range requests.
+// Callers must close the returned reader.
+func (dg *defaultGitHub) Download(ctx context.Context, downloadURL string) (io.ReadCloser, error) {
+	log := log.WithField("action", "defaultGitHub.Download")
+	log.Debugf("downloading %s", downloadURL)
+
+	req, err := http.NewRequest(http.MethodGet, downloadURL, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "creating request for %q", downloadURL)
+	}
+
+	// Large files can take longer to download than the timeout for API
+	// requests allows, so downloads are only bounded by ctx.
+	c := *dg.httpClient
+	c.Timeout = 0
+	client := dg.authenticated(&c)
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, errors.Wrapf(err, "downloading %q", downloadURL)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, errors.Errorf("%q actual %d; expected %d", downloadURL, resp.StatusCode, http.StatusOK)
+	}
+
+	return newResumingBody(ctx, client, downloadURL, resp), nil
+}
+
+// authenticated wraps an http client with the GITHUB_TOKEN credentials if
+// they are set.
+func (dg *defaultGitHub) authenticated(httpClient *http.Client) *http.Client {
 	ght := os.Getenv("GITHUB_TOKEN")
-	if len(ght) > 0 {
-		// TODO WithTimeout
-		ctx := context.WithValue(context.Background(), oauth2.HTTPClient, dg.httpClient)
-		ts := oauth2.StaticTokenSource(
-			&oauth2.Token{AccessToken: ght},
-		)
-		httpClient = oauth2.NewClient(ctx, ts)
+	if len(ght) == 0 {
+		return httpClient
 	}
 
+	// TODO WithTimeout
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, httpClient)
+	ts := oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: ght},
+	)
+	return oauth2.NewClient(ctx, ts)
+}
+
+func (dg *defaultGitHub) client() *github.Client {
+	httpClient := dg.authenticated(dg.httpClient)
 
 	client := github.NewClient(httpClient)
 	if dg.baseURL != nil {
-		fmt.Printf("DEBUG!!! using baseURL: %s\n", dg.baseURL.String())
+		log.Debugf("using baseURL: %s", dg.baseURL.String())
 		client.BaseURL = dg.baseURL
 		client.UploadURL = nil
 	} else {
-		fmt.Printf("DEBUG!!! using baseURL: DEFAULT\n")
+		log.Debugf("using baseURL: DEFAULT")
 	}
 	return client
 }
@@ -18,9 +18,11 @@ package github
 import (
 	"context"
 	"errors"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -156,3 +158,154 @@ func Test_defaultGitHub_client(t *testing.T) {
 	_, _, _ = github.Repositories.GetCommitSHA1(ctx, "ksonnet", "ksonnet", "master", "")
 	assert.True(t, called, "custom http client not called (with GITHUB_TOKEN)")
 }
+
+func Test_defaultGitHub_ReleaseAsset(t *testing.T) {
+	cases := []struct {
+		name     string
+		asset    string
+		expected []byte
+		isErr    bool
+	}{
+		{
+			name:     "release has asset",
+			asset:    "registry.yaml",
+			expected: []byte("registry"),
+		},
+		{
+			name:  "release does not have asset",
+			asset: "other.yaml",
+		},
+		{
+			name:  "release does not exist",
+			isErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			transport := &mockTransport{
+				roundTrip: func(req *http.Request) (*http.Response, error) {
+					body := ""
+					status := http.StatusOK
+
+					switch req.URL.Path {
+					case "/repos/ksonnet/parts/releases/tags/v1.2.3":
+						if tc.isErr {
+							status = http.StatusNotFound
+							body = `{"message":"Not Found"}`
+							break
+						}
+						body = `{"tag_name":"v1.2.3","assets":[{"id":1,"name":"` + tc.asset + `"}]}`
+					case "/repos/ksonnet/parts/releases/assets/1":
+						body = "registry"
+					default:
+						status = http.StatusNotFound
+					}
+
+					return &http.Response{
+						StatusCode: status,
+						Body:       ioutil.NopCloser(strings.NewReader(body)),
+						Request:    req,
+					}, nil
+				},
+			}
+
+			os.Setenv("GITHUB_TOKEN", "")
+			dg := NewGitHub(&http.Client{Transport: transport})
+
+			data, err := dg.ReleaseAsset(context.Background(), Repo{Org: "ksonnet", Repo: "parts"}, "v1.2.3", "registry.yaml")
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, data)
+		})
+	}
+}
+
+func Test_defaultGitHub_CommitSHA1_default_branch(t *testing.T) {
+	var paths []string
+	transport := &mockTransport{
+		roundTrip: func(req *http.Request) (*http.Response, error) {
+			paths = append(paths, req.URL.Path)
+
+			body := ""
+			switch req.URL.Path {
+			case "/repos/ksonnet/parts":
+				body = `{"name":"parts","default_branch":"main"}`
+			case "/repos/ksonnet/parts/commits/main":
+				body = "12345"
+			}
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(strings.NewReader(body)),
+				Request:    req,
+			}, nil
+		},
+	}
+
+	os.Setenv("GITHUB_TOKEN", "")
+	dg := NewGitHub(&http.Client{Transport: transport})
+
+	sha, err := dg.CommitSHA1(context.Background(), Repo{Org: "ksonnet", Repo: "parts"}, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, "12345", sha)
+	assert.Equal(t, []string{"/repos/ksonnet/parts", "/repos/ksonnet/parts/commits/main"}, paths)
+}
+
+func Test_defaultGitHub_Download(t *testing.T) {
+	cases := []struct {
+		name     string
+		status   int
+		expected string
+		isErr    bool
+	}{
+		{
+			name:     "file exists",
+			status:   http.StatusOK,
+			expected: "contents",
+		},
+		{
+			name:   "file does not exist",
+			status: http.StatusNotFound,
+			isErr:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			transport := &mockTransport{
+				roundTrip: func(req *http.Request) (*http.Response, error) {
+					assert.Equal(t, "/ksonnet/parts/12345/incubator/apache/parts.yaml", req.URL.Path)
+
+					return &http.Response{
+						StatusCode: tc.status,
+						Body:       ioutil.NopCloser(strings.NewReader("contents")),
+						Request:    req,
+					}, nil
+				},
+			}
+
+			os.Setenv("GITHUB_TOKEN", "")
+			dg := NewGitHub(&http.Client{Transport: transport})
+
+			u := "https://raw.githubusercontent.com/ksonnet/parts/12345/incubator/apache/parts.yaml"
+			rc, err := dg.Download(context.Background(), u)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			defer rc.Close()
+
+			data, err := ioutil.ReadAll(rc)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(data))
+		})
+	}
+}
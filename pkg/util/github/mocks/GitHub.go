@@ -19,7 +19,9 @@ package mocks
 import context "context"
 import github "github.com/ksonnet/ksonnet/pkg/util/github"
 import go_githubgithub "github.com/google/go-github/github"
+import io "io"
 import mock "github.com/stretchr/testify/mock"
+import url "net/url"
 
 // GitHub is an autogenerated mock type for the GitHub type
 type GitHub struct {
@@ -79,6 +81,78 @@ func (_m *GitHub) Contents(ctx context.Context, repo github.Repo, path string, s
 	return r0, r1, r2
 }
 
+// DefaultBranch provides a mock function with given fields: ctx, repo
+func (_m *GitHub) DefaultBranch(ctx context.Context, repo github.Repo) (string, error) {
+	ret := _m.Called(ctx, repo)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(context.Context, github.Repo) string); ok {
+		r0 = rf(ctx, repo)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, github.Repo) error); ok {
+		r1 = rf(ctx, repo)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Download provides a mock function with given fields: ctx, downloadURL
+func (_m *GitHub) Download(ctx context.Context, downloadURL string) (io.ReadCloser, error) {
+	ret := _m.Called(ctx, downloadURL)
+
+	var r0 io.ReadCloser
+	if rf, ok := ret.Get(0).(func(context.Context, string) io.ReadCloser); ok {
+		r0 = rf(ctx, downloadURL)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(io.ReadCloser)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, downloadURL)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReleaseAsset provides a mock function with given fields: ctx, repo, tag, name
+func (_m *GitHub) ReleaseAsset(ctx context.Context, repo github.Repo, tag string, name string) ([]byte, error) {
+	ret := _m.Called(ctx, repo, tag, name)
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(context.Context, github.Repo, string, string) []byte); ok {
+		r0 = rf(ctx, repo, tag, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, github.Repo, string, string) error); ok {
+		r1 = rf(ctx, repo, tag, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetBaseURL provides a mock function with given fields: _a0
+func (_m *GitHub) SetBaseURL(_a0 *url.URL) {
+	_m.Called(_a0)
+}
+
 // ValidateURL provides a mock function with given fields: u
 func (_m *GitHub) ValidateURL(u string) error {
 	ret := _m.Called(u)
@@ -0,0 +1,133 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// maxResumeAttempts is the number of times an interrupted download is
+	// resumed before giving up.
+	maxResumeAttempts = 5
+
+	// resumeDelay is the time to wait before the first resume attempt. It
+	// doubles on each attempt.
+	resumeDelay = time.Second
+)
+
+// resumingBody is a download body which resumes the transfer with an HTTP
+// Range request when reading fails part way through.
+type resumingBody struct {
+	ctx      context.Context
+	client   *http.Client
+	url      string
+	etag     string
+	body     io.ReadCloser
+	n        int64
+	attempts int
+}
+
+func newResumingBody(ctx context.Context, client *http.Client, downloadURL string, resp *http.Response) *resumingBody {
+	etag := ""
+	if resp.Header != nil {
+		etag = resp.Header.Get("ETag")
+	}
+
+	return &resumingBody{
+		ctx:    ctx,
+		client: client,
+		url:    downloadURL,
+		etag:   etag,
+		body:   resp.Body,
+	}
+}
+
+func (rb *resumingBody) Read(p []byte) (int, error) {
+	for {
+		n, err := rb.body.Read(p)
+		rb.n += int64(n)
+		if err == nil || err == io.EOF {
+			return n, err
+		}
+
+		if resumeErr := rb.resume(err); resumeErr != nil {
+			return n, resumeErr
+		}
+
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// resume requests the rest of the file after a read error. It returns the
+// original error if the transfer can't be resumed.
+func (rb *resumingBody) resume(cause error) error {
+	if rb.ctx.Err() != nil || rb.attempts >= maxResumeAttempts {
+		return cause
+	}
+
+	delay := resumeDelay << uint(rb.attempts)
+	rb.attempts++
+
+	log.WithError(cause).Infof("download of %s interrupted after %d bytes; resuming (attempt %d of %d)",
+		rb.url, rb.n, rb.attempts, maxResumeAttempts)
+
+	select {
+	case <-rb.ctx.Done():
+		return cause
+	case <-time.After(delay):
+	}
+
+	req, err := http.NewRequest(http.MethodGet, rb.url, nil)
+	if err != nil {
+		return cause
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-", rb.n))
+	if rb.etag != "" {
+		// A file which changed since the download started is sent in full
+		// rather than as a range, so its parts are never mixed.
+		req.Header.Set("If-Range", rb.etag)
+	}
+
+	resp, err := rb.client.Do(req.WithContext(rb.ctx))
+	if err != nil {
+		log.WithError(err).Debugf("resuming download of %s", rb.url)
+		return rb.resume(cause)
+	}
+
+	if resp.StatusCode != http.StatusPartialContent {
+		resp.Body.Close()
+		return errors.Wrapf(cause, "%q can't be resumed: actual %d; expected %d",
+			rb.url, resp.StatusCode, http.StatusPartialContent)
+	}
+
+	rb.body.Close()
+	rb.body = resp.Body
+	return nil
+}
+
+func (rb *resumingBody) Close() error {
+	return rb.body.Close()
+}
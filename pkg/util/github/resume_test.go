@@ -0,0 +1,154 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingReader returns the contents of r, then fails.
+type failingReader struct {
+	r io.Reader
+}
+
+func (fr *failingReader) Read(p []byte) (int, error) {
+	n, err := fr.r.Read(p)
+	if err == io.EOF {
+		return n, io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func withResumeDelay(fn func()) {
+	ogDelay := resumeDelay
+	resumeDelay = 0
+	defer func() { resumeDelay = ogDelay }()
+
+	fn()
+}
+
+func Test_defaultGitHub_Download_resume(t *testing.T) {
+	const contents = "0123456789"
+
+	cases := []struct {
+		name         string
+		resumeStatus int
+		expected     string
+		isErr        bool
+	}{
+		{
+			name:         "range supported",
+			resumeStatus: http.StatusPartialContent,
+			expected:     contents,
+		},
+		{
+			name:         "file changed",
+			resumeStatus: http.StatusOK,
+			isErr:        true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			withResumeDelay(func() {
+				var requests []*http.Request
+				transport := &mockTransport{
+					roundTrip: func(req *http.Request) (*http.Response, error) {
+						requests = append(requests, req)
+
+						if len(requests) == 1 {
+							return &http.Response{
+								StatusCode: http.StatusOK,
+								Header:     http.Header{"Etag": []string{`"abc"`}},
+								Body:       ioutil.NopCloser(&failingReader{r: strings.NewReader(contents[:4])}),
+								Request:    req,
+							}, nil
+						}
+
+						body := contents
+						if tc.resumeStatus == http.StatusPartialContent {
+							var start int
+							_, err := fmt.Sscanf(req.Header.Get("Range"), "bytes=%d-", &start)
+							require.NoError(t, err)
+							body = contents[start:]
+						}
+
+						return &http.Response{
+							StatusCode: tc.resumeStatus,
+							Body:       ioutil.NopCloser(strings.NewReader(body)),
+							Request:    req,
+						}, nil
+					},
+				}
+
+				os.Setenv("GITHUB_TOKEN", "")
+				dg := NewGitHub(&http.Client{Transport: transport})
+
+				rc, err := dg.Download(context.Background(), "https://raw.githubusercontent.com/ksonnet/parts/12345/incubator/apache/parts.yaml")
+				require.NoError(t, err)
+				defer rc.Close()
+
+				data, err := ioutil.ReadAll(rc)
+				require.Len(t, requests, 2)
+				assert.Equal(t, "bytes=4-", requests[1].Header.Get("Range"))
+				assert.Equal(t, `"abc"`, requests[1].Header.Get("If-Range"))
+
+				if tc.isErr {
+					require.Error(t, err)
+					return
+				}
+
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, string(data))
+			})
+		})
+	}
+}
+
+func Test_resumingBody_gives_up(t *testing.T) {
+	withResumeDelay(func() {
+		var calls int
+		transport := &mockTransport{
+			roundTrip: func(req *http.Request) (*http.Response, error) {
+				calls++
+				return nil, errors.New("network is unreachable")
+			},
+		}
+
+		rb := &resumingBody{
+			ctx:    context.Background(),
+			client: &http.Client{Transport: transport},
+			url:    "https://example.com/file",
+			body:   ioutil.NopCloser(&failingReader{r: strings.NewReader("01")}),
+		}
+
+		_, err := ioutil.ReadAll(rb)
+		require.Error(t, err)
+		assert.Equal(t, io.ErrUnexpectedEOF, errors.Cause(err))
+		assert.Equal(t, maxResumeAttempts, calls)
+	})
+}
@@ -0,0 +1,192 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package io
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ksonnet/ksonnet/pkg/app"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+const (
+	// DefaultLockTimeout is how long to wait for another process to release
+	// a lock.
+	DefaultLockTimeout = 5 * time.Minute
+
+	// StaleLockAge is the age after which a lock is assumed to have been
+	// left behind by a process which exited without releasing it. Held locks
+	// are refreshed well within this age.
+	StaleLockAge = 10 * time.Minute
+)
+
+var (
+	lockPollInterval    = 100 * time.Millisecond
+	lockRefreshInterval = StaleLockAge / 4
+)
+
+// Lock is an advisory lock shared by processes. It is held by creating a
+// directory, which is atomic, so only one process can hold it at a time.
+// While held, the directory's modification time is refreshed so other
+// processes do not mistake it for a stale lock.
+type Lock struct {
+	fs   afero.Fs
+	path string
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// AcquireLock acquires the lock at path, waiting up to timeout for another
+// process to release it. Locks which have not been refreshed for
+// StaleLockAge are broken.
+func AcquireLock(fs afero.Fs, path string, timeout time.Duration) (*Lock, error) {
+	if fs == nil {
+		return nil, errors.Errorf("fs required")
+	}
+
+	if err := fs.MkdirAll(filepath.Dir(path), app.DefaultFolderPermissions); err != nil {
+		return nil, errors.Wrapf(err, "creating lock directory for %s", path)
+	}
+
+	deadline := time.Now().Add(timeout)
+	waiting := false
+	for {
+		err := fs.Mkdir(path, app.DefaultFolderPermissions)
+		if err == nil {
+			// The lock's age is used to detect stale locks, and not every
+			// filesystem sets a new directory's modification time.
+			now := time.Now()
+			if err = fs.Chtimes(path, now, now); err != nil {
+				fs.RemoveAll(path)
+				return nil, errors.Wrapf(err, "acquiring lock %s", path)
+			}
+
+			l := &Lock{fs: fs, path: path, done: make(chan struct{})}
+			l.wg.Add(1)
+			go l.refresh()
+			return l, nil
+		}
+
+		fi, statErr := fs.Stat(path)
+		if statErr != nil {
+			if os.IsNotExist(statErr) {
+				// The lock was released between creating and checking it.
+				continue
+			}
+			return nil, errors.Wrapf(err, "acquiring lock %s", path)
+		}
+
+		if time.Now().Sub(fi.ModTime()) > StaleLockAge {
+			log.Warnf("breaking stale lock %s", path)
+			if err = fs.RemoveAll(path); err != nil {
+				return nil, errors.Wrapf(err, "breaking stale lock %s", path)
+			}
+			continue
+		}
+
+		if !time.Now().Before(deadline) {
+			return nil, errors.Errorf("timed out waiting for lock %s; remove it if no other ks process is running", path)
+		}
+
+		if !waiting {
+			log.Infof("waiting for another ks process to release %s", path)
+			waiting = true
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// refresh touches the lock until it is released, so long running holders
+// keep it from being broken as stale.
+func (l *Lock) refresh() {
+	defer l.wg.Done()
+
+	ticker := time.NewTicker(lockRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.done:
+			return
+		case <-ticker.C:
+			now := time.Now()
+			if err := l.fs.Chtimes(l.path, now, now); err != nil {
+				log.WithError(err).Warnf("refreshing lock %s", l.path)
+			}
+		}
+	}
+}
+
+// Release releases the lock. It is safe to call multiple times.
+func (l *Lock) Release() error {
+	if l == nil || l.path == "" {
+		return nil
+	}
+
+	if l.done != nil {
+		close(l.done)
+		l.wg.Wait()
+	}
+
+	err := l.fs.RemoveAll(l.path)
+	l.path = ""
+
+	return err
+}
+
+// WriteFileAtomic writes data to a file by writing a temporary file next to
+// it and renaming it into place, so readers never see a partially written
+// file.
+func WriteFileAtomic(fs afero.Fs, path string, data []byte, perm os.FileMode) error {
+	if fs == nil {
+		return errors.Errorf("fs required")
+	}
+
+	tmp, err := afero.TempFile(fs, filepath.Dir(path), "."+filepath.Base(path)+"-")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		fs.Remove(tmpPath)
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		fs.Remove(tmpPath)
+		return err
+	}
+
+	if err = fs.Chmod(tmpPath, perm); err != nil {
+		fs.Remove(tmpPath)
+		return err
+	}
+
+	if err = fs.Rename(tmpPath, path); err != nil {
+		fs.Remove(tmpPath)
+		return errors.Wrapf(err, "renaming %s to %s", tmpPath, path)
+	}
+
+	return nil
+}
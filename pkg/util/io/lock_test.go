@@ -0,0 +1,182 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package io
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withLockPollInterval(t *testing.T, d time.Duration, fn func()) {
+	ogInterval := lockPollInterval
+	defer func() { lockPollInterval = ogInterval }()
+	lockPollInterval = d
+
+	fn()
+}
+
+func TestAcquireLock(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	path := "/app/.ksonnet/locks/registries"
+
+	lock, err := AcquireLock(fs, path, time.Second)
+	require.NoError(t, err)
+
+	exists, err := afero.DirExists(fs, path)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	require.NoError(t, lock.Release())
+	require.NoError(t, lock.Release())
+
+	exists, err = afero.DirExists(fs, path)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestAcquireLock_timeout(t *testing.T) {
+	withLockPollInterval(t, time.Millisecond, func() {
+		fs := afero.NewMemMapFs()
+		path := "/app/.ksonnet/locks/registries"
+
+		lock, err := AcquireLock(fs, path, time.Second)
+		require.NoError(t, err)
+		defer lock.Release()
+
+		_, err = AcquireLock(fs, path, 10*time.Millisecond)
+		require.Error(t, err)
+	})
+}
+
+func TestAcquireLock_stale(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	path := "/app/.ksonnet/locks/registries"
+
+	require.NoError(t, fs.MkdirAll(path, 0755))
+	old := time.Now().Add(-2 * StaleLockAge)
+	require.NoError(t, fs.Chtimes(path, old, old))
+
+	lock, err := AcquireLock(fs, path, 0)
+	require.NoError(t, err)
+	require.NoError(t, lock.Release())
+}
+
+func TestAcquireLock_refresh(t *testing.T) {
+	ogInterval := lockRefreshInterval
+	defer func() { lockRefreshInterval = ogInterval }()
+	lockRefreshInterval = time.Millisecond
+
+	// MemMapFs does not guard modification times against concurrent access.
+	dir, err := ioutil.TempDir("", "lock")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fs := afero.NewOsFs()
+	path := filepath.Join(dir, "lock")
+
+	lock, err := AcquireLock(fs, path, time.Second)
+	require.NoError(t, err)
+	defer lock.Release()
+
+	// A holder which outlives StaleLockAge keeps the lock fresh.
+	old := time.Now().Add(-2 * StaleLockAge)
+	require.NoError(t, fs.Chtimes(path, old, old))
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		fi, err := fs.Stat(path)
+		require.NoError(t, err)
+		if time.Now().Sub(fi.ModTime()) < StaleLockAge {
+			break
+		}
+		require.True(t, time.Now().Before(deadline), "lock was not refreshed")
+		time.Sleep(time.Millisecond)
+	}
+
+	_, err = AcquireLock(fs, path, 10*time.Millisecond)
+	require.Error(t, err)
+}
+
+func TestAcquireLock_concurrent(t *testing.T) {
+	withLockPollInterval(t, time.Millisecond, func() {
+		dir, err := ioutil.TempDir("", "lock")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		fs := afero.NewOsFs()
+		lockPath := filepath.Join(dir, "lock")
+		counterPath := filepath.Join(dir, "counter")
+		require.NoError(t, afero.WriteFile(fs, counterPath, []byte("0"), 0644))
+
+		// Each writer reads and increments the counter under the lock. Without
+		// the lock, increments would be lost.
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				lock, err := AcquireLock(fs, lockPath, 10*time.Second)
+				if !assert.NoError(t, err) {
+					return
+				}
+				defer lock.Release()
+
+				data, err := afero.ReadFile(fs, counterPath)
+				if !assert.NoError(t, err) {
+					return
+				}
+				n, err := strconv.Atoi(string(data))
+				if !assert.NoError(t, err) {
+					return
+				}
+				assert.NoError(t, WriteFileAtomic(fs, counterPath, []byte(strconv.Itoa(n+1)), 0644))
+			}()
+		}
+		wg.Wait()
+
+		data, err := afero.ReadFile(fs, counterPath)
+		require.NoError(t, err)
+		assert.Equal(t, "10", string(data))
+	})
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	path := "/app/.ksonnet/registries/incubator/registry.yaml"
+	require.NoError(t, fs.MkdirAll(filepath.Dir(path), 0755))
+
+	require.NoError(t, WriteFileAtomic(fs, path, []byte("old"), 0644))
+	require.NoError(t, WriteFileAtomic(fs, path, []byte("new"), 0644))
+
+	data, err := afero.ReadFile(fs, path)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+
+	fis, err := afero.ReadDir(fs, filepath.Dir(path))
+	require.NoError(t, err)
+	require.Len(t, fis, 1)
+	assert.Equal(t, "registry.yaml", fis[0].Name())
+}
@@ -0,0 +1,99 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package jsonnet
+
+import (
+	"github.com/google/go-jsonnet"
+	"github.com/google/go-jsonnet/ast"
+	"github.com/google/go-jsonnet/parser"
+)
+
+// Frame is a location in the stack trace of an evaluation error.
+type Frame struct {
+	File   string
+	Line   int
+	Column int
+	// Name describes the code at the location, e.g. `function <anonymous>`.
+	Name string
+}
+
+// EvalError is an error evaluating jsonnet. It keeps the stack trace of the
+// error, so the error can be mapped back to the source which caused it.
+type EvalError struct {
+	// Msg is the error without a stack trace.
+	Msg string
+	// Frames is the stack trace, starting with the location of the error.
+	Frames []Frame
+
+	formatted string
+}
+
+// Error returns the error formatted by jsonnet, with its stack trace.
+func (e *EvalError) Error() string {
+	return e.formatted
+}
+
+// newEvalError creates an EvalError from an error returned by the jsonnet VM.
+// Errors which are not runtime or static errors have no stack trace.
+func newEvalError(err error, formatted string) *EvalError {
+	e := &EvalError{
+		Msg:       err.Error(),
+		formatted: formatted,
+	}
+
+	switch err := err.(type) {
+	case jsonnet.RuntimeError:
+		// jsonnet orders the trace from the outermost call.
+		for i := len(err.StackTrace) - 1; i >= 0; i-- {
+			frame := err.StackTrace[i]
+			if f, ok := newFrame(frame.Loc, frame.Name); ok {
+				e.Frames = append(e.Frames, f)
+			}
+		}
+	case parser.StaticError:
+		if f, ok := newFrame(err.Loc, ""); ok {
+			e.Frames = append(e.Frames, f)
+		}
+	}
+
+	return e
+}
+
+func newFrame(loc ast.LocationRange, name string) (Frame, bool) {
+	if loc.FileName == "" || !loc.Begin.IsSet() {
+		return Frame{}, false
+	}
+
+	return Frame{
+		File:   loc.FileName,
+		Line:   loc.Begin.Line,
+		Column: loc.Begin.Column,
+		Name:   name,
+	}, true
+}
+
+// errorRecorder records the error jsonnet formats, since the VM only returns
+// the formatted error.
+type errorRecorder struct {
+	jsonnet.ErrorFormatter
+	err error
+}
+
+// Format records an error, and formats it.
+func (r *errorRecorder) Format(err error) string {
+	r.err = err
+	return r.ErrorFormatter.Format(err)
+}
@@ -0,0 +1,76 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package jsonnet
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVM_EvaluateSnippet_errors(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/app/components/guestbook.jsonnet", []byte(`local params = std.extVar("params");
+
+{
+  replicas: params.replicas,
+}
+`), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/app/components/invalid.jsonnet", []byte("{\n  a: ,\n}\n"), 0644))
+
+	cases := []struct {
+		name     string
+		snippet  string
+		msg      string
+		expected []Frame
+	}{
+		{
+			name:    "runtime error",
+			snippet: `(import "/app/components/guestbook.jsonnet").replicas`,
+			msg:     "Field does not exist: replicas",
+			expected: []Frame{
+				{File: "/app/components/guestbook.jsonnet", Line: 4, Column: 13, Name: "object <anonymous>"},
+			},
+		},
+		{
+			name:    "static error",
+			snippet: `import "/app/components/invalid.jsonnet"`,
+			expected: []Frame{
+				{File: "/app/components/invalid.jsonnet", Line: 2, Column: 6},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			vm := NewVM(AferoImporterOpt(fs))
+			vm.ExtCode("params", "{ image: 'nginx' }")
+
+			_, err := vm.EvaluateSnippet("snippet", tc.snippet)
+			require.Error(t, err)
+
+			evalErr, ok := err.(*EvalError)
+			require.True(t, ok, "unexpected error type %T", err)
+
+			assert.Contains(t, evalErr.Msg, tc.msg)
+			require.NotEmpty(t, evalErr.Frames)
+			assert.Equal(t, tc.expected[0], evalErr.Frames[0])
+			assert.Contains(t, evalErr.Error(), "/app/components/")
+		})
+	}
+}
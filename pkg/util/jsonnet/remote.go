@@ -0,0 +1,129 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package jsonnet
+
+import (
+	"net/url"
+	"strings"
+
+	jsonnet "github.com/google/go-jsonnet"
+	"github.com/pkg/errors"
+)
+
+// ErrRemoteNotFound is returned by a RemoteFetcher when a URL does not exist.
+var ErrRemoteNotFound = errors.New("remote import not found")
+
+// RemoteFetcher fetches the contents of an http or https URL.
+type RemoteFetcher func(url string) ([]byte, error)
+
+// RemoteImporter is an Importer which imports http and https URLs with a
+// RemoteFetcher, and delegates all other imports to another Importer.
+//
+// Relative imports in a remote file are resolved relative to its URL first,
+// and then by the other Importer, so remote libraries can import both
+// their own files and libraries from the Jsonnet library paths.
+type RemoteImporter struct {
+	Importer
+
+	fetch    RemoteFetcher
+	contents map[string]*jsonnet.Contents
+}
+
+var _ Importer = (*RemoteImporter)(nil)
+
+// NewRemoteImporter creates an instance of RemoteImporter.
+func NewRemoteImporter(importer Importer, fetch RemoteFetcher) *RemoteImporter {
+	return &RemoteImporter{
+		Importer: importer,
+		fetch:    fetch,
+		contents: make(map[string]*jsonnet.Contents),
+	}
+}
+
+// Import imports a file or URL.
+func (ri *RemoteImporter) Import(importedFrom, importedPath string) (jsonnet.Contents, string, error) {
+	if isRemote(importedPath) {
+		contents, found, err := ri.importURL(importedPath)
+		if err != nil {
+			return jsonnet.MakeContents(""), "", err
+		}
+		if !found {
+			return jsonnet.MakeContents(""), "", errors.Errorf("couldn't open import %#v: not found", importedPath)
+		}
+		return contents, importedPath, nil
+	}
+
+	if isRemote(importedFrom) {
+		base, err := url.Parse(importedFrom)
+		if err != nil {
+			return jsonnet.MakeContents(""), "", err
+		}
+		ref, err := url.Parse(importedPath)
+		if err != nil {
+			return jsonnet.MakeContents(""), "", err
+		}
+
+		resolved := base.ResolveReference(ref).String()
+		contents, found, err := ri.importURL(resolved)
+		if err != nil {
+			return jsonnet.MakeContents(""), "", err
+		}
+		if found {
+			return contents, resolved, nil
+		}
+
+		// Look for the import in the library paths.
+		importedFrom = ""
+	}
+
+	return ri.Importer.Import(importedFrom, importedPath)
+}
+
+// importURL fetches a URL once, and returns the same contents for each
+// import of it, as jsonnet requires.
+func (ri *RemoteImporter) importURL(u string) (jsonnet.Contents, bool, error) {
+	if c, ok := ri.contents[u]; ok {
+		if c == nil {
+			return jsonnet.MakeContents(""), false, nil
+		}
+		return *c, true, nil
+	}
+
+	data, err := ri.fetch(u)
+	if err == ErrRemoteNotFound {
+		ri.contents[u] = nil
+		return jsonnet.MakeContents(""), false, nil
+	}
+	if err != nil {
+		return jsonnet.MakeContents(""), false, errors.Wrapf(err, "importing %s", u)
+	}
+
+	c := jsonnet.MakeContents(string(data))
+	ri.contents[u] = &c
+	return c, true, nil
+}
+
+func isRemote(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// RemoteImporterOpt configures a VM to import http and https URLs with a
+// RemoteFetcher. It wraps the importer configured by earlier options.
+func RemoteImporterOpt(fetch RemoteFetcher) VMOpt {
+	return func(vm *VM) {
+		vm.importer = NewRemoteImporter(vm.importer, fetch)
+	}
+}
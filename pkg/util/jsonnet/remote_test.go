@@ -0,0 +1,81 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package jsonnet
+
+import (
+	"testing"
+
+	"github.com/ksonnet/ksonnet/pkg/util/test"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteImporter(t *testing.T) {
+	remote := map[string]string{
+		"https://example.com/lib/main.libsonnet": `
+local util = import "util.libsonnet";
+local setMap = import "set-map.jsonnet";
+{ value: util.value, setMap: std.objectHasAll(setMap, "a") }`,
+		"https://example.com/lib/util.libsonnet": `{ value: 1 }`,
+	}
+
+	fetched := map[string]int{}
+	fetch := func(u string) ([]byte, error) {
+		fetched[u]++
+		s, ok := remote[u]
+		if !ok {
+			return nil, ErrRemoteNotFound
+		}
+		return []byte(s), nil
+	}
+
+	fs := afero.NewMemMapFs()
+	test.StageFile(t, fs, "set-map.jsonnet", "/lib/set-map.jsonnet")
+
+	vm := NewVM(AferoImporterOpt(fs), RemoteImporterOpt(fetch))
+	vm.AddJPath("/lib")
+
+	out, err := vm.EvaluateSnippet("snippet", `
+local a = import "https://example.com/lib/main.libsonnet";
+local b = import "https://example.com/lib/main.libsonnet";
+if a.setMap then a.value + b.value else 0`)
+	require.NoError(t, err)
+	assert.Equal(t, "2\n", out)
+
+	assert.Equal(t, 1, fetched["https://example.com/lib/main.libsonnet"])
+	assert.Equal(t, 1, fetched["https://example.com/lib/set-map.jsonnet"])
+}
+
+func TestRemoteImporter_errors(t *testing.T) {
+	fetch := func(u string) ([]byte, error) {
+		if u == "https://example.com/missing.libsonnet" {
+			return nil, ErrRemoteNotFound
+		}
+		return nil, errors.New("connection refused")
+	}
+
+	vm := NewVM(AferoImporterOpt(afero.NewMemMapFs()), RemoteImporterOpt(fetch))
+
+	_, err := vm.EvaluateSnippet("snippet", `import "https://example.com/missing.libsonnet"`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	_, err = vm.EvaluateSnippet("snippet", `importstr "https://example.com/down.libsonnet"`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "connection refused")
+}
@@ -17,6 +17,9 @@ package jsonnet
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -24,9 +27,11 @@ import (
 	"strings"
 	"time"
 
+	ghyaml "github.com/ghodss/yaml"
 	"github.com/google/go-jsonnet"
 	"github.com/google/go-jsonnet/ast"
 	"github.com/ksonnet/ksonnet/pkg/log"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/util/yaml"
 )
@@ -75,6 +80,13 @@ func NewVM(opts ...VMOpt) *VM {
 	return vm
 }
 
+// NativeFunctionsOpt configures a VM with additional native functions.
+func NativeFunctionsOpt(fns ...*jsonnet.NativeFunction) VMOpt {
+	return func(vm *VM) {
+		vm.AddFunctions(fns...)
+	}
+}
+
 // AddFunctions adds native functions to the Jsonnet VM.
 func (vm *VM) AddFunctions(fns ...*jsonnet.NativeFunction) {
 	vm.nativeFunctions = append(vm.nativeFunctions, fns...)
@@ -124,6 +136,8 @@ func (vm *VM) EvaluateSnippet(name, snippet string) (string, error) {
 
 	jvm := jsonnet.MakeVM()
 	jvm.ErrorFormatter.SetMaxStackTraceSize(40)
+	recorder := &errorRecorder{ErrorFormatter: jvm.ErrorFormatter}
+	jvm.ErrorFormatter = recorder
 
 	for _, fn := range vm.nativeFunctions {
 		jvm.NativeFunction(fn)
@@ -171,7 +185,12 @@ func (vm *VM) EvaluateSnippet(name, snippet string) (string, error) {
 		logrus.WithFields(fields).Debug("jsonnet evaluate snippet")
 	}()
 
-	return vm.evaluateSnippetFn(jvm, name, snippet)
+	out, err := vm.evaluateSnippetFn(jvm, name, snippet)
+	if err != nil && recorder.err != nil {
+		return "", newEvalError(recorder.err, err.Error())
+	}
+
+	return out, err
 }
 
 func registerNativeFuncs(vm *jsonnet.VM) {
@@ -214,6 +233,133 @@ func registerNativeFuncs(vm *jsonnet.VM) {
 			Params: ast.Identifiers{"regex", "src", "repl"},
 			Func:   regexSubst,
 		})
+
+	vm.NativeFunction(
+		&jsonnet.NativeFunction{
+			Name:   "manifestJsonFromJson",
+			Params: ast.Identifiers{"json", "indent"},
+			Func:   manifestJSONFromJSON,
+		})
+
+	vm.NativeFunction(
+		&jsonnet.NativeFunction{
+			Name:   "manifestYamlFromJson",
+			Params: ast.Identifiers{"json"},
+			Func:   manifestYAMLFromJSON,
+		})
+
+	vm.NativeFunction(
+		&jsonnet.NativeFunction{
+			Name:   "sha256",
+			Params: ast.Identifiers{"str"},
+			Func:   sha256Sum,
+		})
+
+	vm.NativeFunction(
+		&jsonnet.NativeFunction{
+			Name:   "base64Encode",
+			Params: ast.Identifiers{"str"},
+			Func:   base64Encode,
+		})
+
+	vm.NativeFunction(
+		&jsonnet.NativeFunction{
+			Name:   "base64Decode",
+			Params: ast.Identifiers{"str"},
+			Func:   base64Decode,
+		})
+}
+
+// stringArg returns the argument at index i of a native function, which
+// must be a string.
+func stringArg(args []interface{}, i int, name string) (string, error) {
+	s, ok := args[i].(string)
+	if !ok {
+		return "", errors.Errorf("%s must be a string; got %T", name, args[i])
+	}
+
+	return s, nil
+}
+
+func manifestJSONFromJSON(args []interface{}) (interface{}, error) {
+	data, err := stringArg(args, 0, "json")
+	if err != nil {
+		return nil, err
+	}
+
+	indent, ok := args[1].(float64)
+	if !ok {
+		return nil, errors.Errorf("indent must be a number; got %T", args[1])
+	}
+
+	d := json.NewDecoder(strings.NewReader(data))
+	d.UseNumber()
+
+	var obj interface{}
+	if err = d.Decode(&obj); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", strings.Repeat(" ", int(indent)))
+	if err = enc.Encode(obj); err != nil {
+		return nil, err
+	}
+
+	return buf.String(), nil
+}
+
+func manifestYAMLFromJSON(args []interface{}) (interface{}, error) {
+	data, err := stringArg(args, 0, "json")
+	if err != nil {
+		return nil, err
+	}
+
+	var obj interface{}
+	if err = json.Unmarshal([]byte(data), &obj); err != nil {
+		return nil, err
+	}
+
+	b, err := ghyaml.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
+
+func sha256Sum(args []interface{}) (interface{}, error) {
+	s, err := stringArg(args, 0, "str")
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func base64Encode(args []interface{}) (interface{}, error) {
+	s, err := stringArg(args, 0, "str")
+	if err != nil {
+		return nil, err
+	}
+
+	return base64.StdEncoding.EncodeToString([]byte(s)), nil
+}
+
+func base64Decode(args []interface{}) (interface{}, error) {
+	s, err := stringArg(args, 0, "str")
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding base64")
+	}
+
+	return string(b), nil
 }
 
 func regexSubst(data []interface{}) (interface{}, error) {
@@ -307,3 +307,51 @@ func TestRegexSubst(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "\"-W-xxW-\"\n", x)
 }
+
+func TestManifestJsonFromJson(t *testing.T) {
+	vm := NewVM()
+
+	_, err := vm.EvaluateSnippet("failtest", `std.native("manifestJsonFromJson")("barf{", 2)`)
+	require.Error(t, err)
+
+	x, err := vm.EvaluateSnippet("test", `std.native("manifestJsonFromJson")('{"foo": 1.0, "bar": [2]}', 2)`)
+	require.NoError(t, err)
+	assert.Equal(t, `"{\n  \"bar\": [\n    2\n  ],\n  \"foo\": 1.0\n}\n"`+"\n", x)
+}
+
+func TestManifestYamlFromJson(t *testing.T) {
+	vm := NewVM()
+
+	_, err := vm.EvaluateSnippet("failtest", `std.native("manifestYamlFromJson")("barf{")`)
+	require.Error(t, err)
+
+	x, err := vm.EvaluateSnippet("test", `std.native("manifestYamlFromJson")('{"foo": "bar", "list": [1]}')`)
+	require.NoError(t, err)
+	assert.Equal(t, `"foo: bar\nlist:\n- 1\n"`+"\n", x)
+}
+
+func TestSha256(t *testing.T) {
+	vm := NewVM()
+
+	_, err := vm.EvaluateSnippet("failtest", `std.native("sha256")(1)`)
+	require.Error(t, err)
+
+	x, err := vm.EvaluateSnippet("test", `std.native("sha256")("ksonnet")`)
+	require.NoError(t, err)
+	assert.Equal(t, `"32074819ea0d1f49fd5019d0d9586a88c7f687e9893f1e9f4c95fb11d17548da"`+"\n", x)
+}
+
+func TestBase64(t *testing.T) {
+	vm := NewVM()
+
+	_, err := vm.EvaluateSnippet("failtest", `std.native("base64Decode")("!!")`)
+	require.Error(t, err)
+
+	x, err := vm.EvaluateSnippet("test", `std.native("base64Encode")("ksonnet")`)
+	require.NoError(t, err)
+	assert.Equal(t, `"a3Nvbm5ldA=="`+"\n", x)
+
+	x, err = vm.EvaluateSnippet("test", `std.native("base64Decode")(std.native("base64Encode")("ksonnet"))`)
+	require.NoError(t, err)
+	assert.Equal(t, `"ksonnet"`+"\n", x)
+}
@@ -16,9 +16,14 @@
 package k8s
 
 import (
+	"bufio"
+	"bytes"
+	"io"
+
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
 )
 
 // FlattenToV1 expands any List-type objects into their members, and
@@ -39,3 +44,42 @@ func FlattenToV1(objs []runtime.Object) ([]*unstructured.Unstructured, error) {
 	}
 	return ret, nil
 }
+
+// ReadObjects decodes a stream of YAML or JSON documents, such as the output
+// of `ks show`, into objects. Lists are expanded into their members.
+func ReadObjects(r io.Reader) ([]*unstructured.Unstructured, error) {
+	decoder := yaml.NewYAMLReader(bufio.NewReader(r))
+
+	var objs []runtime.Object
+	for {
+		doc, err := decoder.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrap(err, "reading manifests")
+		}
+
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+
+		data, err := yaml.ToJSON(doc)
+		if err != nil {
+			return nil, errors.Wrap(err, "decoding manifest")
+		}
+
+		// documents which only contain comments are null.
+		if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+			continue
+		}
+
+		obj, _, err := unstructured.UnstructuredJSONScheme.Decode(data, nil, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "decoding manifest")
+		}
+
+		objs = append(objs, obj)
+	}
+
+	return FlattenToV1(objs)
+}
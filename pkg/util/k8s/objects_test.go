@@ -0,0 +1,98 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package k8s
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadObjects(t *testing.T) {
+	cases := []struct {
+		name     string
+		in       string
+		expected []string
+		isErr    bool
+	}{
+		{
+			name: "yaml stream",
+			in: `---
+apiVersion: v1
+kind: Service
+metadata:
+  name: guestbook-ui
+---
+# comment only
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: guestbook-ui
+`,
+			expected: []string{"Service/guestbook-ui", "Deployment/guestbook-ui"},
+		},
+		{
+			name: "json stream",
+			in: `{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "a"}}
+---
+{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "b"}}
+`,
+			expected: []string{"Service/a", "Service/b"},
+		},
+		{
+			name: "list",
+			in: `{"apiVersion": "v1", "kind": "List", "items": [
+  {"apiVersion": "v1", "kind": "Service", "metadata": {"name": "a"}},
+  {"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "b"}}
+]}`,
+			expected: []string{"Service/a", "ConfigMap/b"},
+		},
+		{
+			name: "empty",
+			in:   "",
+		},
+		{
+			name:  "missing kind",
+			in:    "apiVersion: v1\nmetadata:\n  name: a\n",
+			isErr: true,
+		},
+		{
+			name:  "invalid yaml",
+			in:    "kind: [",
+			isErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			objects, err := ReadObjects(strings.NewReader(tc.in))
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var got []string
+			for _, obj := range objects {
+				got = append(got, obj.GetKind()+"/"+obj.GetName())
+			}
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
@@ -17,6 +17,7 @@ package strings
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/PuerkitoBio/purell"
@@ -66,3 +67,74 @@ func InSlice(s string, sl []string) bool {
 func Ptr(s string) *string {
 	return &s
 }
+
+// Closest returns the candidates which are within a small edit distance of s,
+// nearest first. It is used to suggest alternatives for a misspelled name.
+func Closest(s string, candidates []string) []string {
+	max := len(s) / 3
+	if max < 2 {
+		max = 2
+	}
+
+	distances := make(map[string]int)
+	var matches []string
+	for _, c := range candidates {
+		if _, ok := distances[c]; ok || c == s {
+			continue
+		}
+
+		d := levenshtein(strings.ToLower(s), strings.ToLower(c))
+		if d > max {
+			continue
+		}
+
+		distances[c] = d
+		matches = append(matches, c)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		di, dj := distances[matches[i]], distances[matches[j]]
+		if di != dj {
+			return di < dj
+		}
+		return matches[i] < matches[j]
+	})
+
+	return matches
+}
+
+// levenshtein returns the edit distance between two strings.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+
+	return prev[len(rb)]
+}
+
+func minInt(first int, rest ...int) int {
+	m := first
+	for _, i := range rest {
+		if i < m {
+			m = i
+		}
+	}
+	return m
+}
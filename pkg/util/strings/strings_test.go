@@ -145,3 +145,36 @@ func TestPtr(t *testing.T) {
 	p := Ptr(s)
 	require.Equal(t, &s, p)
 }
+
+func TestClosest(t *testing.T) {
+	candidates := []string{"replicas", "image", "name", "replica", "servicePort", "containerPort"}
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{
+			input:    "replcas",
+			expected: []string{"replicas", "replica"},
+		},
+		{
+			input:    "Image",
+			expected: []string{"image"},
+		},
+		{
+			input:    "servicePrt",
+			expected: []string{"servicePort"},
+		},
+		{
+			input:    "unrelated",
+			expected: nil,
+		},
+		{
+			input:    "name",
+			expected: nil,
+		},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.expected, Closest(test.input, candidates), test.input)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"io"
 	"strings"
 
+	"github.com/ghodss/yaml"
 	"github.com/pkg/errors"
 )
 
@@ -37,8 +38,13 @@ const (
 	FormatTable Format = iota
 	// FormatJSON prints JSON.
 	FormatJSON
+	// FormatYAML prints YAML.
+	FormatYAML
 )
 
+// Formats are the names of the output formats.
+var Formats = []string{"table", "json", "yaml"}
+
 // DefaultFormat is the default format for output. It is a table.
 const DefaultFormat = FormatTable
 
@@ -47,6 +53,8 @@ func DetectFormat(formatName string) (Format, error) {
 	switch formatName {
 	case "json":
 		return FormatJSON, nil
+	case "yaml":
+		return FormatYAML, nil
 	case "", "table":
 		return FormatTable, nil
 	default:
@@ -108,18 +116,38 @@ func (t *Table) Render() error {
 		return t.renderTable()
 	case FormatTable:
 		return t.renderTable()
+	case FormatJSON, FormatYAML:
+		return t.renderData()
+	}
+}
+
+// Encode writes a value as JSON or YAML. It renders values which are not
+// tables, e.g. the description of a single item, in the structured formats.
+func Encode(w io.Writer, f Format, v interface{}) error {
+	switch f {
 	case FormatJSON:
-		return t.renderJSON()
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "\t")
+		return encoder.Encode(v)
+	case FormatYAML:
+		b, err := yaml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	default:
+		return errors.Errorf("output format %d can not encode values", f)
 	}
 }
 
-// jsonOutput is the structure for printing JSON output.
+// jsonOutput is the structure for printing JSON and YAML output.
 type jsonOutput struct {
 	Kind string              `json:"kind"`
 	Data []map[string]string `json:"data"`
 }
 
-func (t *Table) renderJSON() error {
+func (t *Table) renderData() error {
 	if len(t.header) == 0 {
 		return errors.New("headers aren't defined for output")
 	}
@@ -138,15 +166,12 @@ func (t *Table) renderJSON() error {
 		out = append(out, m)
 	}
 
-	encoder := json.NewEncoder(t.w)
-	encoder.SetIndent("", "\t")
-
 	jo := jsonOutput{
 		Kind: t.Name,
 		Data: out,
 	}
 
-	return encoder.Encode(&jo)
+	return Encode(t.w, t.Format, &jo)
 }
 
 func (t *Table) renderTable() error {
@@ -39,6 +39,11 @@ func TestDetectFormat(t *testing.T) {
 			formatName: "json",
 			expected:   FormatJSON,
 		},
+		{
+			name:       "yaml",
+			formatName: "yaml",
+			expected:   FormatYAML,
+		},
 		{
 			name:       "table",
 			formatName: "table",
@@ -90,6 +95,12 @@ func TestTable(t *testing.T) {
 			rw:     &bytes.Buffer{},
 			output: "output.json",
 		},
+		{
+			name:   "YAML format",
+			format: FormatYAML,
+			rw:     &bytes.Buffer{},
+			output: "output.yaml",
+		},
 		{
 			name:   "unknown format",
 			format: Format(99),
@@ -242,3 +253,44 @@ func TestTable_printf_failure(t *testing.T) {
 	err := table.Render()
 	require.Error(t, err)
 }
+
+func TestEncode(t *testing.T) {
+	v := map[string]interface{}{"name": "default", "namespaces": []string{"dev"}}
+
+	cases := []struct {
+		name     string
+		format   Format
+		expected string
+		isErr    bool
+	}{
+		{
+			name:     "json",
+			format:   FormatJSON,
+			expected: "{\n\t\"name\": \"default\",\n\t\"namespaces\": [\n\t\t\"dev\"\n\t]\n}\n",
+		},
+		{
+			name:     "yaml",
+			format:   FormatYAML,
+			expected: "name: default\nnamespaces:\n- dev\n",
+		},
+		{
+			name:   "table",
+			format: FormatTable,
+			isErr:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := Encode(&buf, tc.format, v)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
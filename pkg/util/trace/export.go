@@ -0,0 +1,237 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package trace
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+	"github.com/spf13/afero"
+)
+
+const (
+	// FormatChrome is the Trace Event format read by chrome://tracing and Perfetto.
+	FormatChrome = "chrome"
+	// FormatJaeger is the JSON format which can be uploaded to the Jaeger UI.
+	FormatJaeger = "jaeger"
+
+	serviceName = "ks"
+)
+
+// Write writes the finished spans in a format.
+func (t *Tracer) Write(w io.Writer, format string) error {
+	var v interface{}
+	switch format {
+	case "", FormatChrome:
+		v = t.chromeTrace()
+	case FormatJaeger:
+		v = t.jaegerTrace()
+	default:
+		return errors.Errorf("invalid trace format %q; valid formats are %s and %s", format, FormatChrome, FormatJaeger)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// WriteFile writes the finished spans to a file in a format.
+func (t *Tracer) WriteFile(fs afero.Fs, path, format string) error {
+	f, err := fs.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return t.Write(f, format)
+}
+
+type chromeEvent struct {
+	Name      string            `json:"name"`
+	Category  string            `json:"cat"`
+	Phase     string            `json:"ph"`
+	Timestamp int64             `json:"ts"`
+	Duration  int64             `json:"dur"`
+	PID       int               `json:"pid"`
+	TID       int               `json:"tid"`
+	Args      map[string]string `json:"args,omitempty"`
+}
+
+func (t *Tracer) chromeTrace() interface{} {
+	events := []chromeEvent{}
+	for _, s := range t.Spans() {
+		events = append(events, chromeEvent{
+			Name:      s.Name,
+			Category:  serviceName,
+			Phase:     "X",
+			Timestamp: s.Start.UnixNano() / 1000,
+			Duration:  int64(s.Duration() / 1000),
+			PID:       1,
+			TID:       1,
+			Args:      spanTags(s),
+		})
+	}
+
+	return map[string]interface{}{
+		"traceEvents":     events,
+		"displayTimeUnit": "ms",
+	}
+}
+
+type jaegerTag struct {
+	Key   string `json:"key"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type jaegerReference struct {
+	RefType string `json:"refType"`
+	TraceID string `json:"traceID"`
+	SpanID  string `json:"spanID"`
+}
+
+type jaegerSpan struct {
+	TraceID       string            `json:"traceID"`
+	SpanID        string            `json:"spanID"`
+	OperationName string            `json:"operationName"`
+	References    []jaegerReference `json:"references"`
+	StartTime     int64             `json:"startTime"`
+	Duration      int64             `json:"duration"`
+	Tags          []jaegerTag       `json:"tags"`
+	ProcessID     string            `json:"processID"`
+}
+
+func (t *Tracer) jaegerTrace() interface{} {
+	spans := t.Spans()
+
+	traceID := ""
+	if len(spans) > 0 {
+		traceID = fmt.Sprintf("%032x", spans[0].Start.UnixNano())
+	}
+
+	jspans := []jaegerSpan{}
+	for _, s := range spans {
+		js := jaegerSpan{
+			TraceID:       traceID,
+			SpanID:        fmt.Sprintf("%016x", s.ID),
+			OperationName: s.Name,
+			References:    []jaegerReference{},
+			StartTime:     s.Start.UnixNano() / 1000,
+			Duration:      int64(s.Duration() / 1000),
+			Tags:          []jaegerTag{},
+			ProcessID:     "p1",
+		}
+
+		if s.ParentID != 0 {
+			js.References = append(js.References, jaegerReference{
+				RefType: "CHILD_OF",
+				TraceID: traceID,
+				SpanID:  fmt.Sprintf("%016x", s.ParentID),
+			})
+		}
+
+		tags := spanTags(s)
+		keys := make([]string, 0, len(tags))
+		for k := range tags {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			js.Tags = append(js.Tags, jaegerTag{Key: k, Type: "string", Value: tags[k]})
+		}
+
+		jspans = append(jspans, js)
+	}
+
+	return map[string]interface{}{
+		"data": []interface{}{
+			map[string]interface{}{
+				"traceID": traceID,
+				"spans":   jspans,
+				"processes": map[string]interface{}{
+					"p1": map[string]interface{}{"serviceName": serviceName, "tags": []jaegerTag{}},
+				},
+			},
+		},
+	}
+}
+
+// spanTags returns the attributes of a span, and its error.
+func spanTags(s *Span) map[string]string {
+	tags := make(map[string]string, len(s.Attributes)+1)
+	for k, v := range s.Attributes {
+		tags[k] = v
+	}
+	if s.Err != nil {
+		tags["error"] = s.Err.Error()
+	}
+
+	return tags
+}
+
+// WriteMetrics writes the durations of the finished spans as Prometheus
+// metrics in the text exposition format, e.g. for the node exporter's
+// textfile collector.
+func (t *Tracer) WriteMetrics(w io.Writer) error {
+	registry := prometheus.NewRegistry()
+
+	durations := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "ks",
+		Name:      "operation_duration_seconds",
+		Help:      "Duration of ks operations.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 4, 8),
+	}, []string{"operation", "status"})
+	registry.MustRegister(durations)
+
+	for _, s := range t.Spans() {
+		status := "ok"
+		if s.Err != nil {
+			status = "error"
+		}
+		durations.WithLabelValues(s.Name, status).Observe(s.Duration().Seconds())
+	}
+
+	mfs, err := registry.Gather()
+	if err != nil {
+		return err
+	}
+
+	enc := expfmt.NewEncoder(w, expfmt.FmtText)
+	for _, mf := range mfs {
+		if err := enc.Encode(mf); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteMetricsFile writes the durations of the finished spans as Prometheus
+// metrics to a file.
+func (t *Tracer) WriteMetricsFile(fs afero.Fs, path string) error {
+	f, err := fs.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return t.WriteMetrics(f)
+}
@@ -0,0 +1,188 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package trace
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// otlpTracesPath is the path OTLP/HTTP collectors receive traces on.
+	otlpTracesPath = "/v1/traces"
+
+	otlpSpanKindInternal = 1
+	otlpStatusCodeOK     = 1
+	otlpStatusCodeError  = 2
+)
+
+// The OTLP/HTTP JSON encoding of a trace export request.
+
+type otlpExportRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// Export sends the finished spans to an OpenTelemetry collector with
+// OTLP/HTTP. endpoint is the collector's base URL, e.g.
+// http://localhost:4318, as in $OTEL_EXPORTER_OTLP_ENDPOINT.
+func (t *Tracer) Export(client *http.Client, endpoint string) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	traceID, err := newTraceID()
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(t.otlpTrace(traceID))
+	if err != nil {
+		return err
+	}
+
+	url := strings.TrimSuffix(endpoint, "/") + otlpTracesPath
+	resp, err := client.Post(url, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return errors.Wrapf(err, "exporting trace to %s", url)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Errorf("exporting trace to %s: %s: %s", url, resp.Status, bytes.TrimSpace(body))
+	}
+
+	return nil
+}
+
+func (t *Tracer) otlpTrace(traceID string) otlpExportRequest {
+	spans := []otlpSpan{}
+	for _, s := range t.Spans() {
+		span := otlpSpan{
+			TraceID:           traceID,
+			SpanID:            fmt.Sprintf("%016x", s.ID),
+			Name:              s.Name,
+			Kind:              otlpSpanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(s.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.End.UnixNano(), 10),
+			Attributes:        []otlpAttribute{},
+			Status:            otlpStatus{Code: otlpStatusCodeOK},
+		}
+
+		if s.ParentID != 0 {
+			span.ParentSpanID = fmt.Sprintf("%016x", s.ParentID)
+		}
+
+		keys := make([]string, 0, len(s.Attributes))
+		for k := range s.Attributes {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			span.Attributes = append(span.Attributes, otlpAttribute{Key: k, Value: otlpValue{StringValue: s.Attributes[k]}})
+		}
+
+		if s.Err != nil {
+			span.Status = otlpStatus{Code: otlpStatusCodeError, Message: s.Err.Error()}
+		}
+
+		spans = append(spans, span)
+	}
+
+	return otlpExportRequest{
+		ResourceSpans: []otlpResourceSpans{
+			{
+				Resource: otlpResource{
+					Attributes: []otlpAttribute{
+						{Key: "service.name", Value: otlpValue{StringValue: serviceName}},
+					},
+				},
+				ScopeSpans: []otlpScopeSpans{
+					{
+						Scope: otlpScope{Name: "github.com/ksonnet/ksonnet"},
+						Spans: spans,
+					},
+				},
+			},
+		},
+	}
+}
+
+// newTraceID returns a random trace ID, so traces of separate commands don't
+// collide in a collector.
+func newTraceID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
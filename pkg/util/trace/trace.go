@@ -0,0 +1,224 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package trace records spans for long running operations, such as fetching
+// registries, evaluating components, and applying objects. Spans can be
+// written as a trace for Chrome tracing or Jaeger, exported to an
+// OpenTelemetry collector, and summarized as Prometheus metrics.
+//
+// OpenTelemetry is not vendored, so spans are recorded here rather than with
+// an OpenTelemetry SDK, and are exported with OTLP/HTTP's JSON encoding.
+//
+// Tracing is disabled until Enable is called. While disabled, Start returns
+// a nil span, and all span methods are no-ops.
+package trace
+
+import (
+	"sync"
+	"time"
+)
+
+var (
+	defaultMu     sync.Mutex
+	defaultTracer *Tracer
+)
+
+// Enable enables tracing with a new default tracer, and returns it.
+func Enable() *Tracer {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+
+	defaultTracer = NewTracer()
+	return defaultTracer
+}
+
+// Disable disables tracing.
+func Disable() {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+
+	defaultTracer = nil
+}
+
+// Default returns the default tracer, or nil if tracing is disabled.
+func Default() *Tracer {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+
+	return defaultTracer
+}
+
+// Start starts a span with the default tracer. Attributes are given as
+// key value pairs. It returns nil if tracing is disabled.
+func Start(name string, attributes ...string) *Span {
+	return Default().Start(name, attributes...)
+}
+
+// Span is a timed operation.
+type Span struct {
+	ID         uint64
+	ParentID   uint64
+	Name       string
+	Start      time.Time
+	End        time.Time
+	Attributes map[string]string
+	Err        error
+
+	tracer *Tracer
+}
+
+// StartChild starts a child of the span. Unlike spans started with Start,
+// the child never becomes the parent of other spans, so spans can be started
+// concurrently with StartChild. It returns nil if the span is nil.
+func (s *Span) StartChild(name string, attributes ...string) *Span {
+	if s == nil {
+		return nil
+	}
+
+	t := s.tracer
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	child := t.newSpan(name, attributes)
+	child.ParentID = s.ID
+	return child
+}
+
+// SetAttribute sets an attribute of the span.
+func (s *Span) SetAttribute(key, value string) {
+	if s == nil {
+		return
+	}
+
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+
+	s.Attributes[key] = value
+}
+
+// Finish ends the span, recording err if the operation failed. It returns err
+// so it can wrap the result of an operation.
+func (s *Span) Finish(err error) error {
+	if s == nil {
+		return err
+	}
+
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+
+	s.Err = err
+	s.tracer.end(s)
+	return err
+}
+
+// Duration is how long the span took. It is zero if the span has not ended.
+func (s *Span) Duration() time.Duration {
+	if s.End.IsZero() {
+		return 0
+	}
+
+	return s.End.Sub(s.Start)
+}
+
+// Tracer records spans. The parent of a span started with Start is the span
+// most recently started with Start, and not yet finished, when it starts.
+type Tracer struct {
+	mu     sync.Mutex
+	nextID uint64
+	spans  []*Span
+	active []*Span
+	now    func() time.Time
+}
+
+// NewTracer creates an instance of Tracer.
+func NewTracer() *Tracer {
+	return &Tracer{now: time.Now}
+}
+
+// Start starts a span. Attributes are given as key value pairs.
+func (t *Tracer) Start(name string, attributes ...string) *Span {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := t.newSpan(name, attributes)
+	if n := len(t.active); n > 0 {
+		s.ParentID = t.active[n-1].ID
+	}
+
+	t.active = append(t.active, s)
+	return s
+}
+
+// newSpan records a new span. t.mu must be held.
+func (t *Tracer) newSpan(name string, attributes []string) *Span {
+	t.nextID++
+	s := &Span{
+		ID:         t.nextID,
+		Name:       name,
+		Start:      t.now(),
+		Attributes: make(map[string]string),
+		tracer:     t,
+	}
+
+	for i := 0; i+1 < len(attributes); i += 2 {
+		s.Attributes[attributes[i]] = attributes[i+1]
+	}
+
+	t.spans = append(t.spans, s)
+	return s
+}
+
+// Spans returns the finished spans, in the order they started.
+func (t *Tracer) Spans() []*Span {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var spans []*Span
+	for _, s := range t.spans {
+		if !s.End.IsZero() {
+			spans = append(spans, s)
+		}
+	}
+
+	return spans
+}
+
+// end ends a span. Spans are expected to end in the reverse order they
+// started, but a span left open is closed along with its parent.
+func (t *Tracer) end(s *Span) {
+	if !s.End.IsZero() {
+		return
+	}
+
+	s.End = t.now()
+
+	for i := len(t.active) - 1; i >= 0; i-- {
+		if t.active[i] != s {
+			continue
+		}
+
+		for _, child := range t.active[i+1:] {
+			if child.End.IsZero() {
+				child.End = s.End
+			}
+		}
+		t.active = t.active[:i]
+		return
+	}
+}
@@ -0,0 +1,258 @@
+// Copyright 2018 The ksonnet authors
+//
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package trace
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestTracer returns a tracer whose clock advances a second each time it is read.
+func newTestTracer() *Tracer {
+	t := NewTracer()
+	now := time.Unix(1500000000, 0)
+	t.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	return t
+}
+
+func TestTracer(t *testing.T) {
+	tracer := newTestTracer()
+
+	apply := tracer.Start("apply", "env", "default")
+	render := tracer.Start("render")
+	require.NoError(t, render.Finish(nil))
+	object := tracer.Start("apply.object", "kind", "Deployment")
+	object.SetAttribute("name", "guestbook")
+	err := object.Finish(errors.New("conflict"))
+	require.Error(t, err)
+	require.NoError(t, apply.Finish(nil))
+
+	spans := tracer.Spans()
+	require.Len(t, spans, 3)
+
+	assert.Equal(t, "apply", spans[0].Name)
+	assert.Equal(t, uint64(0), spans[0].ParentID)
+	assert.Equal(t, map[string]string{"env": "default"}, spans[0].Attributes)
+	assert.Equal(t, 5*time.Second, spans[0].Duration())
+
+	assert.Equal(t, spans[0].ID, spans[1].ParentID)
+	assert.Equal(t, time.Second, spans[1].Duration())
+
+	assert.Equal(t, spans[0].ID, spans[2].ParentID)
+	assert.Equal(t, map[string]string{"kind": "Deployment", "name": "guestbook"}, spans[2].Attributes)
+	assert.EqualError(t, spans[2].Err, "conflict")
+}
+
+func TestTracer_unfinished_child(t *testing.T) {
+	tracer := newTestTracer()
+
+	parent := tracer.Start("parent")
+	tracer.Start("child")
+	parent.Finish(nil)
+	sibling := tracer.Start("sibling")
+	sibling.Finish(nil)
+
+	spans := tracer.Spans()
+	require.Len(t, spans, 3)
+	assert.Equal(t, spans[0].End, spans[1].End)
+	assert.Equal(t, uint64(0), spans[2].ParentID)
+}
+
+func TestSpan_StartChild(t *testing.T) {
+	tracer := newTestTracer()
+
+	batch := tracer.Start("batch")
+	first := batch.StartChild("object", "name", "first")
+	second := batch.StartChild("object", "name", "second")
+	first.Finish(nil)
+	other := tracer.Start("other")
+	other.Finish(nil)
+	second.Finish(nil)
+	batch.Finish(nil)
+
+	spans := tracer.Spans()
+	require.Len(t, spans, 4)
+	assert.Equal(t, batch.ID, first.ParentID)
+	assert.Equal(t, batch.ID, second.ParentID)
+	assert.Equal(t, batch.ID, other.ParentID)
+	assert.Equal(t, 2*time.Second, first.Duration())
+	assert.Equal(t, 4*time.Second, second.Duration())
+	assert.Equal(t, map[string]string{"name": "second"}, second.Attributes)
+
+	var nilSpan *Span
+	assert.Nil(t, nilSpan.StartChild("object"))
+}
+
+func TestStart_disabled(t *testing.T) {
+	Disable()
+
+	span := Start("apply")
+	assert.Nil(t, span)
+
+	span.SetAttribute("key", "value")
+	err := errors.New("failed")
+	assert.Equal(t, err, span.Finish(err))
+}
+
+func TestTracer_Write(t *testing.T) {
+	tracer := newTestTracer()
+	parent := tracer.Start("apply")
+	child := tracer.Start("render", "module", "/")
+	child.Finish(errors.New("failed"))
+	parent.Finish(nil)
+
+	cases := []struct {
+		name   string
+		format string
+		check  func(t *testing.T, v map[string]interface{})
+		isErr  bool
+	}{
+		{
+			name:   "chrome",
+			format: FormatChrome,
+			check: func(t *testing.T, v map[string]interface{}) {
+				events := v["traceEvents"].([]interface{})
+				require.Len(t, events, 2)
+
+				event := events[1].(map[string]interface{})
+				assert.Equal(t, "render", event["name"])
+				assert.Equal(t, "X", event["ph"])
+				assert.Equal(t, float64(1500000002000000), event["ts"])
+				assert.Equal(t, float64(1000000), event["dur"])
+				assert.Equal(t, map[string]interface{}{"module": "/", "error": "failed"}, event["args"])
+			},
+		},
+		{
+			name:   "jaeger",
+			format: FormatJaeger,
+			check: func(t *testing.T, v map[string]interface{}) {
+				traces := v["data"].([]interface{})
+				require.Len(t, traces, 1)
+
+				spans := traces[0].(map[string]interface{})["spans"].([]interface{})
+				require.Len(t, spans, 2)
+
+				span := spans[1].(map[string]interface{})
+				assert.Equal(t, "render", span["operationName"])
+				assert.Equal(t, "0000000000000002", span["spanID"])
+
+				refs := span["references"].([]interface{})
+				require.Len(t, refs, 1)
+				assert.Equal(t, "0000000000000001", refs[0].(map[string]interface{})["spanID"])
+			},
+		},
+		{
+			name:   "invalid",
+			format: "zipkin",
+			isErr:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := tracer.Write(&buf, tc.format)
+			if tc.isErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var v map[string]interface{}
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &v))
+			tc.check(t, v)
+		})
+	}
+}
+
+func TestTracer_WriteMetrics(t *testing.T) {
+	tracer := newTestTracer()
+	tracer.Start("apply").Finish(nil)
+	tracer.Start("apply").Finish(errors.New("failed"))
+
+	var buf bytes.Buffer
+	require.NoError(t, tracer.WriteMetrics(&buf))
+
+	out := buf.String()
+	assert.Contains(t, out, "# TYPE ks_operation_duration_seconds histogram")
+	assert.Contains(t, out, `ks_operation_duration_seconds_count{operation="apply",status="ok"} 1`)
+	assert.Contains(t, out, `ks_operation_duration_seconds_count{operation="apply",status="error"} 1`)
+}
+
+func TestTracer_Export(t *testing.T) {
+	tracer := newTestTracer()
+	parent := tracer.Start("apply")
+	child := tracer.Start("render", "module", "/")
+	child.Finish(errors.New("failed"))
+	parent.Finish(nil)
+
+	var path string
+	var req otlpExportRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+	}))
+	defer server.Close()
+
+	require.NoError(t, tracer.Export(nil, server.URL+"/"))
+	assert.Equal(t, "/v1/traces", path)
+
+	require.Len(t, req.ResourceSpans, 1)
+	assert.Equal(t, "service.name", req.ResourceSpans[0].Resource.Attributes[0].Key)
+	assert.Equal(t, "ks", req.ResourceSpans[0].Resource.Attributes[0].Value.StringValue)
+
+	spans := req.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 2)
+	assert.Len(t, spans[0].TraceID, 32)
+	assert.Equal(t, spans[0].TraceID, spans[1].TraceID)
+
+	span := spans[1]
+	assert.Equal(t, "render", span.Name)
+	assert.Equal(t, "0000000000000002", span.SpanID)
+	assert.Equal(t, "0000000000000001", span.ParentSpanID)
+	assert.Equal(t, "1500000002000000000", span.StartTimeUnixNano)
+	assert.Equal(t, "1500000003000000000", span.EndTimeUnixNano)
+	assert.Equal(t, []otlpAttribute{{Key: "module", Value: otlpValue{StringValue: "/"}}}, span.Attributes)
+	assert.Equal(t, otlpStatus{Code: otlpStatusCodeError, Message: "failed"}, span.Status)
+	assert.Equal(t, otlpStatus{Code: otlpStatusCodeOK}, spans[0].Status)
+}
+
+func TestTracer_Export_rejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad trace", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	tracer := newTestTracer()
+	tracer.Start("apply").Finish(nil)
+
+	err := tracer.Export(nil, server.URL)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "400 Bad Request: bad trace")
+}
@@ -24,22 +24,32 @@ var (
 	gkNamespace    = schema.GroupKind{Group: "", Kind: "Namespace"}
 	gkTpr          = schema.GroupKind{Group: "extensions", Kind: "ThirdPartyResource"}
 	gkStorageClass = schema.GroupKind{Group: "storage.k8s.io", Kind: "StorageClass"}
+	gkCRD          = schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}
 
-	gkPod         = schema.GroupKind{Group: "", Kind: "Pod"}
-	gkJob         = schema.GroupKind{Group: "batch", Kind: "Job"}
-	gkDeployment  = schema.GroupKind{Group: "extensions", Kind: "Deployment"}
-	gkDaemonSet   = schema.GroupKind{Group: "extensions", Kind: "DaemonSet"}
-	gkStatefulSet = schema.GroupKind{Group: "apps", Kind: "StatefulSet"}
+	gkPod                   = schema.GroupKind{Group: "", Kind: "Pod"}
+	gkReplicationController = schema.GroupKind{Group: "", Kind: "ReplicationController"}
+	gkJob                   = schema.GroupKind{Group: "batch", Kind: "Job"}
+	gkCronJob               = schema.GroupKind{Group: "batch", Kind: "CronJob"}
+	gkDeployment            = schema.GroupKind{Group: "extensions", Kind: "Deployment"}
+	gkDaemonSet             = schema.GroupKind{Group: "extensions", Kind: "DaemonSet"}
+	gkReplicaSet            = schema.GroupKind{Group: "extensions", Kind: "ReplicaSet"}
+	gkAppsDeployment        = schema.GroupKind{Group: "apps", Kind: "Deployment"}
+	gkAppsDaemonSet         = schema.GroupKind{Group: "apps", Kind: "DaemonSet"}
+	gkAppsReplicaSet        = schema.GroupKind{Group: "apps", Kind: "ReplicaSet"}
+	gkStatefulSet           = schema.GroupKind{Group: "apps", Kind: "StatefulSet"}
 )
 
 // These kinds all start pods.
 // TODO: expand this list.
 func isPodOrSimilar(gk schema.GroupKind) bool {
-	return gk == gkPod ||
-		gk == gkJob ||
-		gk == gkDeployment ||
-		gk == gkDaemonSet ||
-		gk == gkStatefulSet
+	switch gk {
+	case gkPod, gkReplicationController, gkJob, gkCronJob,
+		gkDeployment, gkDaemonSet, gkReplicaSet,
+		gkAppsDeployment, gkAppsDaemonSet, gkAppsReplicaSet, gkStatefulSet:
+		return true
+	}
+
+	return false
 }
 
 // Arbitrary numbers used to do a simple topological sort of resources.
@@ -48,6 +58,9 @@ func depTier(o schema.ObjectKind) int {
 	gk := o.GroupVersionKind().GroupKind()
 	if gk == gkNamespace || gk == gkTpr || gk == gkStorageClass {
 		return 10
+	} else if gk == gkCRD {
+		// After namespaces, but before the custom resources that it defines.
+		return 20
 	} else if isPodOrSimilar(gk) {
 		return 100
 	} else {
@@ -55,6 +68,12 @@ func depTier(o schema.ObjectKind) int {
 	}
 }
 
+// DependencyTier returns the tier an object is sorted into by
+// DependencyOrder. Objects in lower tiers are sorted first.
+func DependencyTier(o *unstructured.Unstructured) int {
+	return depTier(o.GetObjectKind())
+}
+
 // DependencyOrder is a `sort.Interface` that *best-effort* sorts the
 // objects so that known dependencies appear earlier in the list.  The
 // idea is to prevent *some* of the "crash-restart" loops when
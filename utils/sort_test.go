@@ -52,6 +52,36 @@ func TestDepSort(t *testing.T) {
 	}
 }
 
+func TestDepSort_crds(t *testing.T) {
+	newObj := func(apiVersion, kind string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": apiVersion,
+				"kind":       kind,
+			},
+		}
+	}
+
+	objs := []*unstructured.Unstructured{
+		newObj("apps/v1", "Deployment"),
+		newObj("example.com/v1", "Certificate"),
+		newObj("apiextensions.k8s.io/v1beta1", "CustomResourceDefinition"),
+		newObj("v1", "Namespace"),
+	}
+
+	sort.Stable(DependencyOrder(objs))
+
+	var kinds []string
+	for _, obj := range objs {
+		kinds = append(kinds, obj.GetKind())
+	}
+
+	expected := []string{"Namespace", "CustomResourceDefinition", "Certificate", "Deployment"}
+	if !reflect.DeepEqual(kinds, expected) {
+		t.Errorf("expected order %v, got %v", expected, kinds)
+	}
+}
+
 func TestAlphaSort(t *testing.T) {
 	newObj := func(ns, name, kind string) *unstructured.Unstructured {
 		o := unstructured.Unstructured{}